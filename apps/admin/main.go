@@ -16,6 +16,7 @@ import (
 	"github.com/smallbiznis/railzway/internal/cloudmetrics"
 	"github.com/smallbiznis/railzway/internal/config"
 	"github.com/smallbiznis/railzway/internal/customer"
+	"github.com/smallbiznis/railzway/internal/emaildelivery"
 	"github.com/smallbiznis/railzway/internal/events"
 	"github.com/smallbiznis/railzway/internal/feature"
 	"github.com/smallbiznis/railzway/internal/invoice"
@@ -67,6 +68,7 @@ func main() {
 		billingdashboard.Module,
 		billingoperations.Module,
 		email.Module,
+		emaildelivery.Module,
 		pdf.Module,
 		billingoverview.Module,
 		invoice.Module,
@@ -91,6 +93,7 @@ func main() {
 		fx.Provide(server.NewEngine),
 		fx.Provide(server.NewServer),
 		fx.Invoke(func(s *server.Server) {
+			s.RegisterHealthRoutes()
 			s.RegisterAuthRoutes()
 			s.RegisterAdminRoutes()
 			s.RegisterUIRoutes() // Monolith style: serve the react app
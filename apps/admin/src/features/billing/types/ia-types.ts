@@ -10,6 +10,7 @@ export type SLAStatus = "fresh" | "active" | "aging" | "stale" | "breached" | "r
 
 export type ResolutionType =
   | "payment_received"
+  | "close_as_paid"
   | "issue_fixed"
   | "customer_contacted"
   | "escalated_to_manager"
@@ -73,11 +74,16 @@ export interface MyWorkItem {
 
   // Snapshot metadata (full JSON)
   snapshot_metadata?: Record<string, any>
+
+  // Paid since the claim; the assignment is not released automatically
+  is_now_resolved: boolean
+  suggested_action?: "close_as_paid"
 }
 
 export interface MyWorkResponse {
   items: MyWorkItem[]
   currency: string
+  now_resolved_count: number
 }
 
 // ===== Recently Resolved Types =====
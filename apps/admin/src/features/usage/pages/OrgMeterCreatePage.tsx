@@ -19,6 +19,7 @@ const aggregationOptions = [
   { label: "Sum", value: "SUM" },
   { label: "Count", value: "COUNT" },
   { label: "Max", value: "MAX" },
+  { label: "Last", value: "LAST" },
   { label: "Min", value: "MIN" },
   { label: "Average", value: "AVG" },
 ]
@@ -458,7 +458,7 @@ export default function OrgMeterDetailPage() {
                   <SelectValue placeholder="Select aggregation method" />
                 </SelectTrigger>
                 <SelectContent>
-                  {["SUM", "COUNT", "MAX", "MIN", "AVG"].map((option) => (
+                  {["SUM", "COUNT", "MAX", "LAST", "MIN", "AVG"].map((option) => (
                     <SelectItem key={option} value={option}>
                       {option}
                     </SelectItem>
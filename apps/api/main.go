@@ -52,6 +52,7 @@ func main() {
 		fx.Provide(server.NewEngine),
 		fx.Provide(server.NewServer),
 		fx.Invoke(func(s *server.Server) {
+			s.RegisterHealthRoutes()
 			s.RegisterAPIRoutes()
 		}),
 		fx.Invoke(server.RunHTTP),
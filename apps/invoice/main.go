@@ -29,6 +29,7 @@ func main() {
 		fx.Provide(server.NewEngine),
 		fx.Provide(server.NewServer),
 		fx.Invoke(func(s *server.Server) {
+			s.RegisterHealthRoutes()
 			s.RegisterPublicRoutes()
 			s.RegisterFallback() // If this service also serves a public UI
 		}),
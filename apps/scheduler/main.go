@@ -1,8 +1,6 @@
 package main
 
 import (
-	"context"
-
 	"github.com/bwmarrin/snowflake"
 	"github.com/smallbiznis/railzway/internal/audit"
 	"github.com/smallbiznis/railzway/internal/authorization"
@@ -10,6 +8,7 @@ import (
 	"github.com/smallbiznis/railzway/internal/billingoperations"
 	"github.com/smallbiznis/railzway/internal/clock"
 	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/emaildelivery"
 	"github.com/smallbiznis/railzway/internal/feature"
 	"github.com/smallbiznis/railzway/internal/invoice"
 	"github.com/smallbiznis/railzway/internal/invoicetemplate"
@@ -21,6 +20,7 @@ import (
 	"github.com/smallbiznis/railzway/internal/pricetier"
 	"github.com/smallbiznis/railzway/internal/product"
 	"github.com/smallbiznis/railzway/internal/productfeature"
+	"github.com/smallbiznis/railzway/internal/providers/email"
 	"github.com/smallbiznis/railzway/internal/rating"
 	"github.com/smallbiznis/railzway/internal/scheduler"
 	"github.com/smallbiznis/railzway/internal/subscription"
@@ -56,6 +56,8 @@ func main() {
 		pricetier.Module,
 		invoicetemplate.Module,
 		meter.Module,
+		email.Module,
+		emaildelivery.Module,
 
 		// No server module!
 		fx.Invoke(StartScheduler),
@@ -72,10 +74,5 @@ func RegisterSnowflake() *snowflake.Node {
 }
 
 func StartScheduler(lc fx.Lifecycle, s *scheduler.Scheduler) {
-	lc.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
-			go s.RunForever(context.Background())
-			return nil
-		},
-	})
+	scheduler.Start(lc, s)
 }
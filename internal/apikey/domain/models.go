@@ -5,6 +5,7 @@ import (
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/lib/pq"
+	authscope "github.com/smallbiznis/railzway/internal/auth/scope"
 )
 
 // APIKey stores hashed API credentials scoped to an organization.
@@ -21,7 +22,22 @@ type APIKey struct {
 	LastUsedAt       *time.Time     `gorm:"column:last_used_at"`
 	ExpiresAt        *time.Time     `gorm:"column:expires_at"`
 	RotatedFromKeyID *string        `gorm:"column:rotated_from_key_id;type:text"`
+
+	// RateLimitPerMinute overrides the org-wide usage ingest limit for this
+	// key. RateLimitUnlimited bypasses ingest rate limits entirely. Both are
+	// operator-managed and carried over on rotation.
+	RateLimitPerMinute *int `gorm:"column:rate_limit_per_minute"`
+	RateLimitUnlimited bool `gorm:"column:rate_limit_unlimited;not null;default:false"`
 }
 
 // TableName sets the database table name.
 func (APIKey) TableName() string { return "api_keys" }
+
+// HasScope reports whether key grants scope. Keys minted without scopes
+// predate scoping and keep full access.
+func HasScope(key APIKey, scope authscope.Scope) bool {
+	if len(key.Scopes) == 0 {
+		return true
+	}
+	return authscope.Has(key.Scopes, scope)
+}
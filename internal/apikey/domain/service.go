@@ -11,6 +11,7 @@ type Service interface {
 	Create(ctx context.Context, req CreateRequest) (*SecretResponse, error)
 	Rotate(ctx context.Context, keyID string) (*SecretResponse, error)
 	Revoke(ctx context.Context, keyID string) error
+	GetScopes(ctx context.Context, keyID string) (*ScopesResponse, error)
 }
 
 type CreateRequest struct {
@@ -27,6 +28,17 @@ type Response struct {
 	LastUsedAt       *time.Time `json:"last_used_at"`
 	ExpiresAt        *time.Time `json:"expires_at"`
 	RotatedFromKeyID *string    `json:"rotated_from_key_id"`
+
+	RateLimitPerMinute *int `json:"rate_limit_per_minute"`
+	RateLimitUnlimited bool `json:"rate_limit_unlimited"`
+}
+
+// ScopesResponse lists the scopes granted to one key. FullAccess is set for
+// keys minted without scopes.
+type ScopesResponse struct {
+	KeyID      string   `json:"key_id"`
+	Scopes     []string `json:"scopes"`
+	FullAccess bool     `json:"full_access"`
 }
 
 type SecretResponse struct {
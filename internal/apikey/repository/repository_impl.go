@@ -16,8 +16,8 @@ func Provide() apikeydomain.Repository {
 
 func (r *repo) Insert(ctx context.Context, db *gorm.DB, key *apikeydomain.APIKey) error {
 	return db.WithContext(ctx).Exec(
-		`INSERT INTO api_keys (id, org_id, key_id, name, scopes, key_hash, is_active, created_at, updated_at, last_used_at, expires_at, rotated_from_key_id)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO api_keys (id, org_id, key_id, name, scopes, key_hash, is_active, created_at, updated_at, last_used_at, expires_at, rotated_from_key_id, rate_limit_per_minute, rate_limit_unlimited)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		key.ID,
 		key.OrgID,
 		key.KeyID,
@@ -30,6 +30,8 @@ func (r *repo) Insert(ctx context.Context, db *gorm.DB, key *apikeydomain.APIKey
 		key.LastUsedAt,
 		key.ExpiresAt,
 		key.RotatedFromKeyID,
+		key.RateLimitPerMinute,
+		key.RateLimitUnlimited,
 	).Error
 }
 
@@ -54,7 +56,7 @@ func (r *repo) Update(ctx context.Context, db *gorm.DB, key *apikeydomain.APIKey
 func (r *repo) FindByKeyID(ctx context.Context, db *gorm.DB, orgID snowflake.ID, keyID string) (*apikeydomain.APIKey, error) {
 	var key apikeydomain.APIKey
 	err := db.WithContext(ctx).Raw(
-		`SELECT id, org_id, key_id, name, scopes, key_hash, is_active, created_at, updated_at, last_used_at, expires_at, rotated_from_key_id
+		`SELECT id, org_id, key_id, name, scopes, key_hash, is_active, created_at, updated_at, last_used_at, expires_at, rotated_from_key_id, rate_limit_per_minute, rate_limit_unlimited
 		 FROM api_keys WHERE org_id = ? AND key_id = ?`,
 		orgID,
 		keyID,
@@ -71,7 +73,7 @@ func (r *repo) FindByKeyID(ctx context.Context, db *gorm.DB, orgID snowflake.ID,
 func (r *repo) List(ctx context.Context, db *gorm.DB, orgID snowflake.ID) ([]apikeydomain.APIKey, error) {
 	var keys []apikeydomain.APIKey
 	err := db.WithContext(ctx).Raw(
-		`SELECT id, org_id, key_id, name, scopes, key_hash, is_active, created_at, updated_at, last_used_at, expires_at, rotated_from_key_id
+		`SELECT id, org_id, key_id, name, scopes, key_hash, is_active, created_at, updated_at, last_used_at, expires_at, rotated_from_key_id, rate_limit_per_minute, rate_limit_unlimited
 		 FROM api_keys WHERE org_id = ? ORDER BY created_at DESC`,
 		orgID,
 	).Scan(&keys).Error
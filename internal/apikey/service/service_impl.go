@@ -164,6 +164,9 @@ func (s *Service) Rotate(ctx context.Context, keyID string) (*apikeydomain.Secre
 			CreatedAt:        now,
 			UpdatedAt:        now,
 			RotatedFromKeyID: &rotatedFrom,
+
+			RateLimitPerMinute: current.RateLimitPerMinute,
+			RateLimitUnlimited: current.RateLimitUnlimited,
 		}
 
 		if err := s.repo.Insert(ctx, tx, next); err != nil {
@@ -211,6 +214,33 @@ func (s *Service) Revoke(ctx context.Context, keyID string) error {
 	return s.repo.Update(ctx, s.db, key)
 }
 
+func (s *Service) GetScopes(ctx context.Context, keyID string) (*apikeydomain.ScopesResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, apikeydomain.ErrInvalidOrganization
+	}
+
+	trimmed := strings.TrimSpace(keyID)
+	if trimmed == "" {
+		return nil, apikeydomain.ErrInvalidKeyID
+	}
+
+	key, err := s.repo.FindByKeyID(ctx, s.db, orgID, trimmed)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, apikeydomain.ErrNotFound
+	}
+
+	scopes := authscope.Normalize(key.Scopes)
+	return &apikeydomain.ScopesResponse{
+		KeyID:      key.KeyID,
+		Scopes:     scopes,
+		FullAccess: len(scopes) == 0,
+	}, nil
+}
+
 func (s *Service) toResponse(key *apikeydomain.APIKey) apikeydomain.Response {
 	return apikeydomain.Response{
 		KeyID:            key.KeyID,
@@ -221,6 +251,9 @@ func (s *Service) toResponse(key *apikeydomain.APIKey) apikeydomain.Response {
 		LastUsedAt:       key.LastUsedAt,
 		ExpiresAt:        key.ExpiresAt,
 		RotatedFromKeyID: key.RotatedFromKeyID,
+
+		RateLimitPerMinute: key.RateLimitPerMinute,
+		RateLimitUnlimited: key.RateLimitUnlimited,
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"gorm.io/gorm"
 )
 
 type ListAuditLogRequest struct {
@@ -26,6 +27,9 @@ type ListAuditLogResponse struct {
 
 type Service interface {
 	AuditLog(ctx context.Context, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error
+	// AuditLogTx writes the entry inside tx, so it commits or rolls back
+	// with the change it records.
+	AuditLogTx(ctx context.Context, tx *gorm.DB, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error
 	List(ctx context.Context, req ListAuditLogRequest) (ListAuditLogResponse, error)
 }
 
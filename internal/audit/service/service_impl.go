@@ -42,6 +42,16 @@ func NewService(p Params) auditdomain.Service {
 }
 
 func (s *Service) AuditLog(ctx context.Context, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
+	return s.insert(ctx, s.db, orgID, actorType, actorID, action, targetType, targetID, metadata)
+}
+
+// AuditLogTx is AuditLog inside the caller's transaction, so the entry
+// commits or rolls back with the change it records.
+func (s *Service) AuditLogTx(ctx context.Context, tx *gorm.DB, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
+	return s.insert(ctx, tx, orgID, actorType, actorID, action, targetType, targetID, metadata)
+}
+
+func (s *Service) insert(ctx context.Context, db *gorm.DB, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
 	action = strings.TrimSpace(action)
 	if action == "" {
 		return auditdomain.ErrInvalidAction
@@ -95,7 +105,7 @@ func (s *Service) AuditLog(ctx context.Context, orgID *snowflake.ID, actorType s
 		entry.UserAgent = &userAgent
 	}
 
-	if err := s.repo.Insert(ctx, s.db, &entry); err != nil {
+	if err := s.repo.Insert(ctx, db, &entry); err != nil {
 		s.log.Warn("failed to write audit log", zap.String("action", action), zap.Error(err))
 		return err
 	}
@@ -14,6 +14,7 @@ var ErrInvalidScope = errors.New("invalid_scope")
 const (
 	ScopeSubscriptionView     Scope = "subscription:view"
 	ScopeSubscriptionCreate   Scope = "subscription:create"
+	ScopeSubscriptionUpdate   Scope = "subscription:update"
 	ScopeSubscriptionActivate Scope = "subscription:activate"
 	ScopeSubscriptionPause    Scope = "subscription:pause"
 	ScopeSubscriptionResume   Scope = "subscription:resume"
@@ -30,6 +31,8 @@ const (
 	ScopeInvoiceFinalize Scope = "invoice:finalize"
 	ScopeInvoiceVoid     Scope = "invoice:void"
 
+	ScopeInvoiceRecordPayment Scope = "invoice:record_payment"
+
 	ScopeAPIKeyView   Scope = "api_key:view"
 	ScopeAPIKeyCreate Scope = "api_key:create"
 	ScopeAPIKeyRotate Scope = "api_key:rotate"
@@ -62,6 +65,10 @@ const (
 	ScopeCustomerDelete Scope = "customer:delete"
 
 	ScopePaymentProviderManage Scope = "payment_provider:manage"
+
+	// ScopeReferenceView reads shared reference data such as countries,
+	// timezones and currencies.
+	ScopeReferenceView Scope = "reference:view"
 )
 
 type authzKey struct {
@@ -72,6 +79,7 @@ type authzKey struct {
 var authzScopeMap = map[authzKey]Scope{
 	{normalize(authorization.ObjectSubscription), normalize(authorization.ActionSubscriptionView)}:     ScopeSubscriptionView,
 	{normalize(authorization.ObjectSubscription), normalize(authorization.ActionSubscriptionCreate)}:   ScopeSubscriptionCreate,
+	{normalize(authorization.ObjectSubscription), normalize(authorization.ActionSubscriptionUpdate)}:   ScopeSubscriptionUpdate,
 	{normalize(authorization.ObjectSubscription), normalize(authorization.ActionSubscriptionActivate)}: ScopeSubscriptionActivate,
 	{normalize(authorization.ObjectSubscription), normalize(authorization.ActionSubscriptionPause)}:    ScopeSubscriptionPause,
 	{normalize(authorization.ObjectSubscription), normalize(authorization.ActionSubscriptionResume)}:   ScopeSubscriptionResume,
@@ -83,10 +91,11 @@ var authzScopeMap = map[authzKey]Scope{
 	{normalize(authorization.ObjectBillingCycle), normalize(authorization.ActionBillingCycleClose)}:        ScopeBillingCycleClose,
 	{normalize(authorization.ObjectBillingCycle), normalize(authorization.ActionBillingCycleRate)}:         ScopeBillingCycleRate,
 
-	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceView)}:     ScopeInvoiceView,
-	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceGenerate)}: ScopeInvoiceGenerate,
-	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceFinalize)}: ScopeInvoiceFinalize,
-	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceVoid)}:     ScopeInvoiceVoid,
+	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceView)}:          ScopeInvoiceView,
+	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceGenerate)}:      ScopeInvoiceGenerate,
+	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceFinalize)}:      ScopeInvoiceFinalize,
+	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceVoid)}:          ScopeInvoiceVoid,
+	{normalize(authorization.ObjectInvoice), normalize(authorization.ActionInvoiceRecordPayment)}: ScopeInvoiceRecordPayment,
 
 	{normalize(authorization.ObjectAPIKey), normalize(authorization.ActionAPIKeyView)}:   ScopeAPIKeyView,
 	{normalize(authorization.ObjectAPIKey), normalize(authorization.ActionAPIKeyCreate)}: ScopeAPIKeyCreate,
@@ -122,6 +131,7 @@ var authzScopeMap = map[authzKey]Scope{
 var allScopes = []Scope{
 	ScopeSubscriptionView,
 	ScopeSubscriptionCreate,
+	ScopeSubscriptionUpdate,
 	ScopeSubscriptionActivate,
 	ScopeSubscriptionPause,
 	ScopeSubscriptionResume,
@@ -135,6 +145,7 @@ var allScopes = []Scope{
 	ScopeInvoiceGenerate,
 	ScopeInvoiceFinalize,
 	ScopeInvoiceVoid,
+	ScopeInvoiceRecordPayment,
 	ScopeAPIKeyView,
 	ScopeAPIKeyCreate,
 	ScopeAPIKeyRotate,
@@ -159,6 +170,7 @@ var allScopes = []Scope{
 	ScopeCustomerUpdate,
 	ScopeCustomerDelete,
 	ScopePaymentProviderManage,
+	ScopeReferenceView,
 }
 
 var validScopes = func() map[string]struct{} {
@@ -49,10 +49,17 @@ const (
 	ActionBillingCycleStartClosing = "billing_cycle.start_closing"
 	ActionBillingCycleClose        = "billing_cycle.close"
 	ActionBillingCycleRate         = "billing_cycle.rate"
+	// ActionBillingCycleForceClose overrides the lifecycle guards for a stuck
+	// cycle. It is only granted to people, never to role:system, so API keys
+	// and the scheduler cannot use it.
+	ActionBillingCycleForceClose = "billing_cycle.force_close"
 
 	ActionInvoiceGenerate = "invoice.generate"
 	ActionInvoiceFinalize = "invoice.finalize"
 	ActionInvoiceVoid     = "invoice.void"
+	// ActionInvoiceRecordPayment records a payment taken outside any provider,
+	// such as over the phone, against an invoice.
+	ActionInvoiceRecordPayment = "invoice.record_payment"
 
 	ActionBillingDashboardView  = "billing_dashboard.view"
 	ActionBillingOperationsView = "billing_operations.view"
@@ -347,7 +354,7 @@ func actorSubject(actorType string, actorID *string) string {
 
 func shouldAuditGrant(action string) bool {
 	switch action {
-	case ActionAPIKeyRotate, ActionAPIKeyRevoke, ActionInvoiceVoid:
+	case ActionAPIKeyRotate, ActionAPIKeyRevoke, ActionInvoiceVoid, ActionInvoiceRecordPayment, ActionBillingCycleForceClose:
 		return true
 	default:
 		return false
@@ -365,6 +372,7 @@ func seedPolicies(enforcer *casbin.SyncedEnforcer) error {
 		{"role:admin", ObjectSubscription, ActionSubscriptionPause},
 		{"role:admin", ObjectSubscription, ActionSubscriptionResume},
 		{"role:admin", ObjectInvoice, ActionInvoiceFinalize},
+		{"role:admin", ObjectInvoice, ActionInvoiceRecordPayment},
 		{"role:admin", ObjectBillingDashboard, ActionBillingDashboardView},
 		{"role:admin", ObjectBillingOperations, ActionBillingOperationsView},
 		{"role:admin", ObjectBillingOperations, ActionBillingOperationsAct},
@@ -374,6 +382,7 @@ func seedPolicies(enforcer *casbin.SyncedEnforcer) error {
 		{"role:admin", ObjectAPIKey, ActionAPIKeyView},
 		{"role:admin", ObjectAuditLog, ActionAuditLogView},
 		{"role:admin", ObjectPaymentProvider, ActionPaymentProviderManage},
+		{"role:admin", ObjectBillingCycle, ActionBillingCycleForceClose},
 
 		// Owner permissions
 		{"role:owner", ObjectSubscription, ActionSubscriptionActivate},
@@ -382,6 +391,7 @@ func seedPolicies(enforcer *casbin.SyncedEnforcer) error {
 		{"role:owner", ObjectSubscription, ActionSubscriptionCancel},
 		{"role:owner", ObjectInvoice, ActionInvoiceFinalize},
 		{"role:owner", ObjectInvoice, ActionInvoiceVoid},
+		{"role:owner", ObjectInvoice, ActionInvoiceRecordPayment},
 		{"role:owner", ObjectBillingDashboard, ActionBillingDashboardView},
 		{"role:owner", ObjectBillingOperations, ActionBillingOperationsView},
 		{"role:owner", ObjectBillingOperations, ActionBillingOperationsAct},
@@ -392,6 +402,7 @@ func seedPolicies(enforcer *casbin.SyncedEnforcer) error {
 		{"role:owner", ObjectAPIKey, ActionAPIKeyRevoke},
 		{"role:owner", ObjectAuditLog, ActionAuditLogView},
 		{"role:owner", ObjectPaymentProvider, ActionPaymentProviderManage},
+		{"role:owner", ObjectBillingCycle, ActionBillingCycleForceClose},
 
 		// FinOps permissions
 		{"role:finops", ObjectBillingOperations, ActionBillingOperationsView},
@@ -399,15 +410,18 @@ func seedPolicies(enforcer *casbin.SyncedEnforcer) error {
 		{"role:finops", ObjectBillingDashboard, ActionBillingDashboardView},
 		{"role:finops", ObjectBillingOverview, ActionBillingOverviewView},
 		{"role:finops", ObjectInvoice, "view"},
+		{"role:finops", ObjectInvoice, ActionInvoiceRecordPayment},
 
 		// System permissions (for automated processes and API keys)
 		{"role:system", ObjectSubscription, ActionSubscriptionEnd},
+		{"role:system", ObjectSubscription, ActionSubscriptionResume},
 		{"role:system", ObjectBillingCycle, ActionBillingCycleOpen},
 		{"role:system", ObjectBillingCycle, ActionBillingCycleStartClosing},
 		{"role:system", ObjectBillingCycle, ActionBillingCycleRate},
 		{"role:system", ObjectBillingCycle, ActionBillingCycleClose},
 		{"role:system", ObjectInvoice, ActionInvoiceGenerate},
 		{"role:system", ObjectInvoice, ActionInvoiceFinalize},
+		{"role:system", ObjectInvoice, ActionInvoiceRecordPayment},
 
 		// System CRUD permissions for API operations
 		{"role:system", ObjectCustomer, ActionCustomerView},
@@ -87,6 +87,32 @@ func TestAuthorizeSystem(t *testing.T) {
 	}
 }
 
+func TestAuthorizeForceCloseIsSeparatelyGranted(t *testing.T) {
+	db := setupAuthzTestDB(t)
+	insertMember(t, db, 4, 13, "OWNER")
+	insertMember(t, db, 4, 14, "FINOPS")
+
+	enforcer, err := NewEnforcer(db)
+	if err != nil {
+		t.Fatalf("new enforcer: %v", err)
+	}
+	svc := &ServiceImpl{
+		db:       db,
+		log:      zap.NewNop(),
+		enforcer: enforcer,
+	}
+
+	if err := svc.Authorize(context.Background(), "user:13", "4", ObjectBillingCycle, ActionBillingCycleForceClose); err != nil {
+		t.Fatalf("expected owner allow, got %v", err)
+	}
+	if err := svc.Authorize(context.Background(), "user:14", "4", ObjectBillingCycle, ActionBillingCycleForceClose); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected finops forbidden, got %v", err)
+	}
+	if err := svc.Authorize(context.Background(), "system", "4", ObjectBillingCycle, ActionBillingCycleForceClose); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected system forbidden, got %v", err)
+	}
+}
+
 func setupAuthzTestDB(t *testing.T) *gorm.DB {
 	t.Helper()
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
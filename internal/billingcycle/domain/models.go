@@ -14,6 +14,9 @@ const (
 	BillingCycleStatusOpen    BillingCycleStatus = "OPEN"
 	BillingCycleStatusClosing BillingCycleStatus = "CLOSING"
 	BillingCycleStatusClosed  BillingCycleStatus = "CLOSED"
+	// BillingCycleStatusRatingFailed marks a cycle whose rating exhausted its
+	// retry budget and needs operator attention.
+	BillingCycleStatusRatingFailed BillingCycleStatus = "RATING_FAILED"
 )
 
 // BillingCycle represents a billing period for a subscription.
@@ -27,6 +30,8 @@ type BillingCycle struct {
 	OpenedAt           *time.Time         `gorm:"column:opened_at"`
 	ClosingStartedAt   *time.Time         `gorm:"column:closing_started_at"`
 	RatingCompletedAt  *time.Time         `gorm:"column:rating_completed_at"`
+	RatingAttempts     int                `gorm:"column:rating_attempts;not null;default:0"`
+	NextRatingRetryAt  *time.Time         `gorm:"column:next_rating_retry_at"`
 	InvoicedAt         *time.Time         `gorm:"column:invoiced_at"`
 	InvoiceFinalizedAt *time.Time         `gorm:"column:invoice_finalized_at"`
 	ClosedAt           *time.Time         `gorm:"column:closed_at"`
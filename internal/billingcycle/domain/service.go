@@ -22,4 +22,9 @@ type Service interface {
 var (
 	ErrMultipleOpenCycles = errors.New("multiple_open_cycles")
 	ErrInvalidCyclePeriod = errors.New("invalid_cycle_period")
+
+	ErrBillingCycleNotFound    = errors.New("billing_cycle_not_found")
+	ErrCycleNotForceClosable   = errors.New("billing_cycle_not_force_closable")
+	ErrInvalidForceCloseReason = errors.New("invalid_reason")
+	ErrInvalidForceCloseStatus = errors.New("invalid_status")
 )
@@ -19,6 +19,43 @@ type GetPerformanceRequest struct {
 	From       time.Time `json:"from" form:"from"`
 	To         time.Time `json:"to" form:"to"`
 	Limit      int       `json:"limit" form:"limit"`
+	// ExcludeUserIDs leaves these users out of team performance, e.g. a
+	// manager who also works assignments. Ignored for individual performance.
+	ExcludeUserIDs []string `json:"exclude_user_ids" form:"exclude_user_ids"`
+	// OrderBy and OrderDirection sort team performance by one of the
+	// TeamPerformanceOrderBy fields; the default is user_id ascending, which
+	// also breaks ties. Ignored for individual performance and exports.
+	OrderBy        string `json:"order_by" form:"order_by"`
+	OrderDirection string `json:"order_direction" form:"order_direction"`
+}
+
+// Team performance sort fields and directions.
+const (
+	TeamPerformanceOrderByUserID          = "user_id"
+	TeamPerformanceOrderByAvgScore        = "avg_score"
+	TeamPerformanceOrderByExposureHandled = "exposure_handled"
+	TeamPerformanceOrderByCompletionRatio = "completion_ratio"
+
+	OrderDirectionAsc  = "asc"
+	OrderDirectionDesc = "desc"
+)
+
+// ExportTeamPerformanceRequest selects the team snapshots to export. With
+// IncludeSummary, each user's snapshots are followed by a summary row that
+// aggregates them as GetTeamPerformance does.
+type ExportTeamPerformanceRequest struct {
+	GetPerformanceRequest
+	IncludeSummary bool `json:"include_summary" form:"include_summary"`
+}
+
+// PerformanceHistoryRequest selects an operator's stored score snapshots.
+// From and To bound period_start as [From, To); a zero bound is open. An
+// empty PeriodType returns every period type.
+type PerformanceHistoryRequest struct {
+	PeriodType string    `json:"period_type" form:"period_type"`
+	From       time.Time `json:"from" form:"from"`
+	To         time.Time `json:"to" form:"to"`
+	Limit      int       `json:"limit" form:"limit"`
 }
 
 type PerformanceResponse struct {
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// ARReconciliation compares the ledger's accounts receivable balance with
+// the outstanding amount on invoices, per currency. Amounts are in each
+// currency's minor units.
+type ARReconciliation struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// Threshold is the absolute drift a currency may show before it is
+	// reported.
+	Threshold  int64                      `json:"threshold"`
+	Currencies []ARCurrencyReconciliation `json:"currencies"`
+	// HasDrift is set when any currency is over the threshold.
+	HasDrift bool `json:"has_drift"`
+}
+
+type ARCurrencyReconciliation struct {
+	Currency         string `json:"currency"`
+	CurrencyExponent int    `json:"currency_exponent"`
+	// LedgerBalance is debits less credits on the org's receivable accounts.
+	LedgerBalance int64 `json:"ledger_balance"`
+	// InvoiceOutstanding is the summed outstanding of open finalized
+	// invoices, as the exposure reports compute it.
+	InvoiceOutstanding int64 `json:"invoice_outstanding"`
+	// Drift = LedgerBalance - InvoiceOutstanding.
+	Drift         int64 `json:"drift"`
+	OverThreshold bool  `json:"over_threshold"`
+	// TopInvoices lists the invoices contributing most to the drift, largest
+	// first. Only filled when the currency is over the threshold.
+	TopInvoices []ARInvoiceDrift `json:"top_invoices,omitempty"`
+}
+
+type ARInvoiceDrift struct {
+	InvoiceID     string `json:"invoice_id"`
+	InvoiceNumber string `json:"invoice_number"`
+	Status        string `json:"status"`
+	// LedgerBalance is the receivable charged for the invoice less the
+	// payments and credit notes settled against it.
+	LedgerBalance int64 `json:"ledger_balance"`
+	Outstanding   int64 `json:"outstanding"`
+	Drift         int64 `json:"drift"`
+}
+
+// ARBalanceRow is the ledger receivable balance and invoice outstanding for
+// one currency.
+type ARBalanceRow struct {
+	Currency           string `gorm:"column:currency"`
+	LedgerBalance      int64  `gorm:"column:ledger_balance"`
+	InvoiceOutstanding int64  `gorm:"column:invoice_outstanding"`
+}
+
+type ARInvoiceDriftRow struct {
+	InvoiceID     snowflake.ID `gorm:"column:invoice_id"`
+	InvoiceNumber string       `gorm:"column:invoice_number"`
+	Status        string       `gorm:"column:status"`
+	LedgerBalance int64        `gorm:"column:ledger_balance"`
+	Outstanding   int64        `gorm:"column:outstanding"`
+	Drift         int64        `gorm:"column:drift"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// BreachEvent describes an assignment escalated by the SLA monitor.
+type BreachEvent struct {
+	OrgID        string    `json:"org_id"`
+	AssignmentID string    `json:"assignment_id"`
+	EntityType   string    `json:"entity_type"`
+	EntityID     string    `json:"entity_id"`
+	AssignedTo   string    `json:"assigned_to"`
+	BreachType   string    `json:"breach_type"`
+	MinutesIdle  int       `json:"minutes_idle"`
+	BreachedAt   time.Time `json:"breached_at"`
+}
+
+// BreachNotifier pushes SLA breaches to an external channel such as Slack or
+// a webhook. It is called after the escalation is committed; a failure never
+// undoes the escalation.
+type BreachNotifier interface {
+	NotifyBreach(ctx context.Context, breach BreachEvent) error
+}
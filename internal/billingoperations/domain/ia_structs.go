@@ -1,29 +1,50 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+)
 
 // Inbox View (Unassigned / Needs Action)
 
 type InboxRequest struct {
-	Limit int `json:"limit" form:"limit"`
+	Limit        int    `json:"limit" form:"limit"`
+	RiskCategory string `json:"risk_category" form:"risk_category"`   // optional, one of the RiskCategory* values
+	MinAmountDue int64  `json:"min_amount_due" form:"min_amount_due"` // optional, in minor units
+	Currency     string `json:"currency" form:"currency"`             // optional, defaults to the org currency
 }
 
+// Inbox risk categories.
+const (
+	RiskCategoryOverdue         = "overdue"
+	RiskCategoryHighExposure    = "high_exposure"
+	RiskCategoryBalanceExposure = "balance_exposure"
+)
+
 type InboxItem struct {
-	EntityType   string     `json:"entity_type"` // "invoice" | "customer"
-	EntityID     string     `json:"entity_id"`
-	EntityName   string     `json:"entity_name"`   // invoice_number or customer_name
-	RiskCategory string     `json:"risk_category"` // "overdue" | "failed_payment" | "high_exposure"
-	RiskScore    int        `json:"risk_score"`    // For sorting
-	AmountDue    int64      `json:"amount_due"`
-	Currency     string     `json:"currency"`
-	DaysOverdue  int        `json:"days_overdue,omitempty"`
-	LastAttempt  *time.Time `json:"last_attempt,omitempty"`
-	PublicToken  string     `json:"public_token,omitempty"`
+	EntityType       string     `json:"entity_type"` // "invoice" | "customer"
+	EntityID         string     `json:"entity_id"`
+	EntityName       string     `json:"entity_name"`   // invoice_number or customer_name
+	RiskCategory     string     `json:"risk_category"` // "overdue" | "failed_payment" | "high_exposure" | "balance_exposure"
+	RiskScore        int        `json:"risk_score"`    // For sorting
+	AmountDue        int64      `json:"amount_due"`
+	Currency         string     `json:"currency"`
+	CurrencyExponent int        `json:"currency_exponent"`
+	DaysOverdue      int        `json:"days_overdue,omitempty"`
+	LastAttempt      *time.Time `json:"last_attempt,omitempty"`
+	PublicToken      string     `json:"public_token,omitempty"`
+	// DisputedAmount is the part of AmountDue under a line dispute; it is
+	// left out of RiskScore and should not be dunned.
+	DisputedAmount int64 `json:"disputed_amount,omitempty"`
 }
 
+// InboxResponse lists the items in one currency, so risk scores and
+// thresholds only ever compare amounts in that currency.
 type InboxResponse struct {
-	Items    []InboxItem `json:"items"`
-	Currency string      `json:"currency"`
+	Items            []InboxItem `json:"items"`
+	Currency         string      `json:"currency"`
+	CurrencyExponent int         `json:"currency_exponent"`
 }
 
 // My Work View (Claimed by Me)
@@ -41,6 +62,12 @@ type MyWorkItem struct {
 	CustomerEmail string `json:"customer_email,omitempty"`
 	InvoiceNumber string `json:"invoice_number,omitempty"`
 
+	// Contact channels; phone and preferred channel are omitted when the
+	// customer is flagged do-not-contact.
+	CustomerPhone           string `json:"customer_phone,omitempty"`
+	PreferredContactChannel string `json:"preferred_contact_channel,omitempty"`
+	DoNotContact            bool   `json:"do_not_contact,omitempty"`
+
 	// Snapshot values at claim time (stable)
 	AmountDueAtClaim   int64 `json:"amount_due_at_claim"`
 	DaysOverdueAtClaim int   `json:"days_overdue_at_claim"`
@@ -49,23 +76,44 @@ type MyWorkItem struct {
 	CurrentAmountDue   int64 `json:"current_amount_due,omitempty"`
 	CurrentDaysOverdue int   `json:"current_days_overdue,omitempty"`
 
-	Currency      string     `json:"currency"`
-	ClaimedAt     time.Time  `json:"claimed_at"`
-	AssignmentAge string     `json:"assignment_age"` // "2h 15m"
-	Status        string     `json:"status"`         // "claimed" | "in_progress"
-	LastActionAt  *time.Time `json:"last_action_at,omitempty"`
-	PublicToken   string     `json:"public_token,omitempty"`
+	Currency         string     `json:"currency"`
+	CurrencyExponent int        `json:"currency_exponent"`
+	ClaimedAt        time.Time  `json:"claimed_at"`
+	AssignmentAge    string     `json:"assignment_age"` // "2h 15m"
+	Status           string     `json:"status"`         // "claimed" | "in_progress"
+	LastActionAt     *time.Time `json:"last_action_at,omitempty"`
+	PublicToken      string     `json:"public_token,omitempty"`
+
+	// IsNowResolved is set when the work had an amount due at claim time
+	// and nothing is outstanding any more, e.g. the customer has paid. The
+	// assignment is left as is; SuggestedAction tells the agent to close it.
+	IsNowResolved   bool   `json:"is_now_resolved"`
+	SuggestedAction string `json:"suggested_action,omitempty"`
 }
 
+// SuggestedActionCloseAsPaid asks the agent to resolve work whose balance
+// has been paid since it was claimed. It is also the resolution to send to
+// ResolveAssignment, which checks that nothing is outstanding.
+const SuggestedActionCloseAsPaid = "close_as_paid"
+
 type MyWorkResponse struct {
-	Items    []MyWorkItem `json:"items"`
-	Currency string       `json:"currency"`
+	Items            []MyWorkItem `json:"items"`
+	Currency         string       `json:"currency"`
+	CurrencyExponent int          `json:"currency_exponent"`
+	// NowResolvedCount is how many of the user's items are IsNowResolved,
+	// including those beyond the returned page, so they can be cleared in
+	// one go.
+	NowResolvedCount int `json:"now_resolved_count"`
 }
 
 // Recently Resolved View
 
 type RecentlyResolvedRequest struct {
 	Limit int `json:"limit" form:"limit"`
+	// Detailed adds the full claim snapshot and the entity's state at
+	// resolution to each item. Both are decoded per item, so lists default
+	// to lean.
+	Detailed bool `json:"detailed" form:"detailed"`
 }
 
 type ResolvedItem struct {
@@ -77,10 +125,18 @@ type ResolvedItem struct {
 	ResolvedAt       time.Time `json:"resolved_at"`
 	ResolvedBy       string    `json:"resolved_by"`
 	Reason           string    `json:"reason,omitempty"`
+	HandoffNote      string    `json:"handoff_note,omitempty"` // released work only
 	ClaimedAt        time.Time `json:"claimed_at"`
 	Duration         string    `json:"duration"` // "3h 45m"
 	AmountDueAtClaim int64     `json:"amount_due_at_claim"`
 	Currency         string    `json:"currency"`
+	CurrencyExponent int       `json:"currency_exponent"`
+
+	// Set only for detailed requests. Snapshot is everything captured when
+	// the work was claimed; CurrentState is the entity as it stood when the
+	// work was resolved or released, or nil when that was not recorded.
+	Snapshot     map[string]any `json:"snapshot,omitempty"`
+	CurrentState map[string]any `json:"current_state,omitempty"`
 }
 
 type RecentlyResolvedResponse struct {
@@ -89,7 +145,13 @@ type RecentlyResolvedResponse struct {
 
 // Team View (Manager Only)
 
-type TeamViewRequest struct{}
+type TeamViewRequest struct {
+	// ExcludeUserIDs leaves these users out of the members and the summary.
+	ExcludeUserIDs []string `json:"exclude_user_ids" form:"exclude_user_ids"`
+	// Top lists only the N busiest operators and folds the rest into
+	// TeamViewResponse.Others. Zero uses the configured maximum, if any.
+	Top int `json:"top" form:"top"`
+}
 
 type TeamMemberWorkload struct {
 	UserID             string `json:"user_id"`
@@ -97,6 +159,31 @@ type TeamMemberWorkload struct {
 	AvgAssignmentAge   string `json:"avg_assignment_age"` // "1h 30m"
 	TotalExposureOwned int64  `json:"total_exposure_owned"`
 	EscalationCount    int    `json:"escalation_count"`
+
+	// NeedsRebalancing is set when the operator carries noticeably more than
+	// the team mean; RebalanceReasons says on which measures. It is a flag,
+	// not a score: every operator over the threshold is flagged alike.
+	NeedsRebalancing bool     `json:"needs_rebalancing"`
+	RebalanceReasons []string `json:"rebalance_reasons,omitempty"`
+}
+
+// Reasons an operator needs rebalancing.
+const (
+	RebalanceReasonActiveAssignments = "active_assignments"
+	RebalanceReasonExposure          = "exposure"
+)
+
+// TeamWorkloadBalance describes how evenly work is spread across the team.
+// The imbalance indicators are Gini coefficients: 0 when every operator
+// carries the same load, approaching 1 as it concentrates on one operator.
+type TeamWorkloadBalance struct {
+	ActiveAssignmentsImbalance float64 `json:"active_assignments_imbalance"`
+	ExposureImbalance          float64 `json:"exposure_imbalance"`
+	MeanActiveAssignments      float64 `json:"mean_active_assignments"`
+	MeanExposure               float64 `json:"mean_exposure"`
+	// ThresholdPercent is how far above the mean an operator is flagged.
+	ThresholdPercent    int `json:"threshold_percent"`
+	NeedsRebalanceCount int `json:"needs_rebalance_count"`
 }
 
 type TeamSummary struct {
@@ -106,15 +193,90 @@ type TeamSummary struct {
 	EscalationCount        int    `json:"escalation_count"`
 }
 
+// TeamOthersSummary folds the operators left out of a truncated team view
+// into one row.
+type TeamOthersSummary struct {
+	MemberCount        int    `json:"member_count"`
+	ActiveAssignments  int    `json:"active_assignments"`
+	AvgAssignmentAge   string `json:"avg_assignment_age"`
+	TotalExposureOwned int64  `json:"total_exposure_owned"`
+	EscalationCount    int    `json:"escalation_count"`
+}
+
 type TeamViewResponse struct {
-	Members  []TeamMemberWorkload `json:"members"`
-	Summary  TeamSummary          `json:"summary"`
-	Currency string               `json:"currency"`
+	Members []TeamMemberWorkload `json:"members"`
+	// Truncated is set when Members lists only the busiest operators; the
+	// rest are in Others. Summary always covers the whole team.
+	Truncated        bool               `json:"truncated"`
+	Others           *TeamOthersSummary `json:"others,omitempty"`
+	Summary          TeamSummary        `json:"summary"`
+	Currency         string             `json:"currency"`
+	CurrencyExponent int                `json:"currency_exponent"`
+	// Balance covers the whole team, including members folded into Others.
+	Balance TeamWorkloadBalance `json:"balance"`
+}
+
+// Assignment List (Manager "All Work" Table)
+
+// Assignment list page sizes.
+const (
+	DefaultAssignmentPageSize = 50
+	MaxAssignmentPageSize     = 250
+)
+
+// AssignmentFilter narrows ListAssignments. Empty fields match every
+// assignment. There is deliberately no organization field: the list is
+// always scoped to the organization in the request context.
+type AssignmentFilter struct {
+	AssignedTo string
+	Status     string // one of the AssignmentStatus* values
+	EntityType string // one of the EntityType* values
+	// Breached, when set, keeps only assignments that did (true) or did not
+	// (false) breach an SLA.
+	Breached *bool
+	// AssignedFrom and AssignedUntil bound assigned_at, both inclusive.
+	AssignedFrom  *time.Time
+	AssignedUntil *time.Time
+
+	PageToken string
+	PageSize  int
+}
+
+// AssignmentListItem is one row of the manager assignment list.
+type AssignmentListItem struct {
+	AssignmentID        string     `json:"assignment_id"`
+	EntityType          string     `json:"entity_type"`
+	EntityID            string     `json:"entity_id"`
+	AssignedTo          string     `json:"assigned_to"`
+	AssignedAt          time.Time  `json:"assigned_at"`
+	AssignmentExpiresAt time.Time  `json:"assignment_expires_at"`
+	Status              string     `json:"status"`
+	BreachedAt          *time.Time `json:"breached_at,omitempty"`
+	BreachLevel         string     `json:"breach_level,omitempty"`
+	LastActionAt        *time.Time `json:"last_action_at,omitempty"`
+	ReleasedAt          *time.Time `json:"released_at,omitempty"`
+	ReleasedBy          string     `json:"released_by,omitempty"`
+	ReleaseReason       string     `json:"release_reason,omitempty"`
+	HandedOffAt         *time.Time `json:"handed_off_at,omitempty"`
+	HandedOffBy         string     `json:"handed_off_by,omitempty"`
+	HandoffNote         string     `json:"handoff_note,omitempty"`
+	ResolvedAt          *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy          string     `json:"resolved_by,omitempty"`
+}
+
+type ListAssignmentsResponse struct {
+	pagination.PageInfo
+	Assignments []AssignmentListItem `json:"assignments"`
 }
 
 // Exposure Analysis View
 
-type ExposureAnalysisRequest struct{}
+type ExposureAnalysisRequest struct {
+	// BucketEdges replaces the default 30/60/90 day aging buckets with
+	// buckets ending at these days overdue, ascending. The last bucket is
+	// open-ended, so 15 and 45 give 1-15, 16-45 and 46+ days.
+	BucketEdges []int `json:"bucket_edges" form:"bucket_edges"`
+}
 
 type ExposureBucket struct {
 	Bucket string `json:"bucket"` // "0-30", "31-60", "61-90", "90+"
@@ -128,28 +290,183 @@ type ExposureCategory struct {
 	Count    int    `json:"count"`
 }
 
+// ExposureAnalysisResponse covers invoices in the org currency only;
+// balances in other currencies are left out rather than summed with it.
 type ExposureAnalysisResponse struct {
-	TotalExposure   int64              `json:"total_exposure"`
-	Currency        string             `json:"currency"`
-	ByRiskCategory  []ExposureCategory `json:"by_risk_category"`
-	ByAgingBucket   []ExposureBucket   `json:"by_aging_bucket"`
-	TopHighExposure []InboxItem        `json:"top_high_exposure"` // Reuse InboxItem for list
+	TotalExposure    int64              `json:"total_exposure"`
+	Currency         string             `json:"currency"`
+	CurrencyExponent int                `json:"currency_exponent"`
+	ByRiskCategory   []ExposureCategory `json:"by_risk_category"`
+	ByAgingBucket    []ExposureBucket   `json:"by_aging_bucket"`
+	TopHighExposure  []InboxItem        `json:"top_high_exposure"` // Reuse InboxItem for list
+}
+
+// AR Health Summary
+
+// ARHealthResponse summarises receivables health for [From, To). All amounts
+// are in the org currency's minor units, and invoices and settlements in
+// other currencies are left out.
+type ARHealthResponse struct {
+	Currency         string    `json:"currency"`
+	CurrencyExponent int       `json:"currency_exponent"`
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	PeriodDays       float64   `json:"period_days"` // (To - From) in days
+
+	// BeginningReceivables is the open AR at From: finalized invoices issued
+	// before From, less payments and credit notes posted before From.
+	BeginningReceivables int64 `json:"beginning_receivables"`
+	// EndingReceivables is the open AR at To, computed like
+	// BeginningReceivables with To as the cutoff, so settlements posted
+	// after To stay out of it.
+	EndingReceivables int64 `json:"ending_receivables"`
+	// TotalBilled is the subtotal of invoices finalized in [From, To).
+	TotalBilled int64 `json:"total_billed"`
+	// PaymentsReceived is the AR settled by succeeded payment events received
+	// in [From, To).
+	PaymentsReceived int64 `json:"payments_received"`
+
+	// DSO = EndingReceivables / TotalBilled * PeriodDays.
+	// Nil when nothing was billed in the period.
+	DSO *float64 `json:"dso"`
+	// CEI = PaymentsReceived / (BeginningReceivables + TotalBilled - EndingReceivables) * 100.
+	// Nil when the denominator is not positive.
+	CEI *float64 `json:"cei"`
+}
+
+// SLA Breach Statistics
+//
+// Figures are team-wide; breaches are never broken down per agent.
+
+type SLABreachTypeStats struct {
+	BreachType string `json:"breach_type"` // "initial_response" | "idle_action"
+	Breaches   int    `json:"breaches"`
+	// BreachRate = Breaches / SLAStatsResponse.Claims. Nil when nothing was claimed.
+	BreachRate *float64 `json:"breach_rate"`
+}
+
+type SLABreachPoint struct {
+	Date            time.Time `json:"date"` // UTC day
+	InitialResponse int       `json:"initial_response"`
+	IdleAction      int       `json:"idle_action"`
+}
+
+type SLAStatsResponse struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Claims is the number of claim actions in [From, To), the population
+	// the SLA clock ran against.
+	Claims        int `json:"claims"`
+	TotalBreaches int `json:"total_breaches"`
+	// BreachRate = TotalBreaches / Claims. Nil when nothing was claimed.
+	BreachRate *float64 `json:"breach_rate"`
+	// EscalatedOpen counts assignments breached in [From, To) that are still
+	// escalated and waiting to be picked up again.
+	EscalatedOpen int                  `json:"escalated_open"`
+	ByType        []SLABreachTypeStats `json:"by_type"`
+	Series        []SLABreachPoint     `json:"series"`
 }
 
 // Invoice Payment Details
 
 type PaymentDetail struct {
-	PaymentID   string    `json:"payment_id"`
-	Amount      int64     `json:"amount"`
-	Currency    string    `json:"currency"`
-	OccurredAt  time.Time `json:"occurred_at"`
-	Provider    string    `json:"provider"`         // "stripe"
-	Method      string    `json:"method,omitempty"` // "card", "bank_transfer"
-	CardBrand   string    `json:"card_brand,omitempty"`   // "visa", "mastercard"
-	CardLast4   string    `json:"card_last4,omitempty"`   // "4242"
-	Status      string    `json:"status"`           // "succeeded", "failed"
+	PaymentID        string    `json:"payment_id"`
+	Amount           int64     `json:"amount"`
+	Currency         string    `json:"currency"`
+	CurrencyExponent int       `json:"currency_exponent"`
+	OccurredAt       time.Time `json:"occurred_at"`
+	Provider         string    `json:"provider"`             // "stripe"
+	Method           string    `json:"method,omitempty"`     // "card", "bank_transfer"
+	CardBrand        string    `json:"card_brand,omitempty"` // "visa", "mastercard"
+	CardLast4        string    `json:"card_last4,omitempty"` // "4242"
+	Status           string    `json:"status"`               // "succeeded", "failed"
 }
 
 type InvoicePaymentsResponse struct {
 	Payments []PaymentDetail `json:"payments"`
 }
+
+// Customer Statement
+
+// Customer statement entry types.
+const (
+	StatementEntryInvoice    = "invoice"
+	StatementEntryPayment    = "payment"
+	StatementEntryCreditNote = "credit_note"
+)
+
+// CustomerStatementEntry is one line of a statement of account. Invoices
+// raise the balance by Debit; payments and credit notes lower it by Credit.
+type CustomerStatementEntry struct {
+	Type          string    `json:"type"` // one of the StatementEntry* values
+	ID            string    `json:"id"`   // invoice, payment event or credit note ID
+	InvoiceID     string    `json:"invoice_id,omitempty"`
+	InvoiceNumber string    `json:"invoice_number,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Debit         int64     `json:"debit"`
+	Credit        int64     `json:"credit"`
+	// Balance is the running outstanding balance after this entry.
+	Balance int64 `json:"balance"`
+}
+
+// CustomerStatement is a customer's statement of account for [From, To).
+// All amounts are in the org currency's minor units.
+type CustomerStatement struct {
+	CustomerID       string    `json:"customer_id"`
+	CustomerName     string    `json:"customer_name"`
+	Currency         string    `json:"currency"`
+	CurrencyExponent int       `json:"currency_exponent"`
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+
+	// OpeningBalance is the outstanding balance at From: finalized invoices
+	// less payments and credit notes posted before From.
+	OpeningBalance int64                    `json:"opening_balance"`
+	Entries        []CustomerStatementEntry `json:"entries"`
+	TotalDebits    int64                    `json:"total_debits"`
+	TotalCredits   int64                    `json:"total_credits"`
+	ClosingBalance int64                    `json:"closing_balance"`
+}
+
+// Entity Audit Trail
+
+// Audit trail event sources.
+const (
+	AuditTrailSourceAction     = "action"
+	AuditTrailSourceAssignment = "assignment"
+	AuditTrailSourcePayment    = "payment"
+	AuditTrailSourceAuditLog   = "audit_log"
+)
+
+// Assignment lifecycle events reported in an audit trail.
+const (
+	AssignmentEventAssigned = "assigned"
+	AssignmentEventReleased = "released"
+	AssignmentEventResolved = "resolved"
+	AssignmentEventBreached = "breached"
+)
+
+// AuditTrailEvent is one record in an entity's audit trail.
+type AuditTrailEvent struct {
+	OccurredAt time.Time `json:"occurred_at"`
+	Source     string    `json:"source"` // one of the AuditTrailSource* values
+	ID         string    `json:"id"`     // ID of the source record
+	// Type is the action type, assignment event, payment event type or
+	// audit log action, depending on Source.
+	Type      string         `json:"type"`
+	ActorType string         `json:"actor_type,omitempty"`
+	ActorID   string         `json:"actor_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// EntityAuditTrail is the ordered record of everything that happened to an
+// invoice or customer: billing operation actions, its assignment lifecycle,
+// payment events and audit log entries. Events are ordered by OccurredAt,
+// then by source in the order listed above.
+type EntityAuditTrail struct {
+	EntityType  string            `json:"entity_type"`
+	EntityID    string            `json:"entity_id"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Entity      map[string]any    `json:"entity"` // current snapshot of the entity
+	Events      []AuditTrailEvent `json:"events"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// InvoiceLineDispute is the portion of one invoice line a customer disputes.
+// The disputed amount stays on the invoice balance for accounting but is left
+// out of overdue pressure, so agents do not dun it. A line has at most one
+// dispute; disputing it again replaces the amount and reason.
+type InvoiceLineDispute struct {
+	ID         string    `json:"id"`
+	InvoiceID  string    `json:"invoice_id"`
+	LineID     string    `json:"line_id"`
+	Amount     int64     `json:"amount"`
+	Reason     string    `json:"reason"`
+	DisputedBy string    `json:"disputed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type InvoiceLineDisputeRecord struct {
+	ID            snowflake.ID `gorm:"column:id"`
+	OrgID         snowflake.ID `gorm:"column:org_id"`
+	InvoiceID     snowflake.ID `gorm:"column:invoice_id"`
+	InvoiceItemID snowflake.ID `gorm:"column:invoice_item_id"`
+	Amount        int64        `gorm:"column:amount"`
+	Reason        string       `gorm:"column:reason"`
+	DisputedBy    string       `gorm:"column:disputed_by"`
+	CreatedAt     time.Time    `gorm:"column:created_at"`
+	UpdatedAt     time.Time    `gorm:"column:updated_at"`
+}
+
+// InvoiceLineRow is an invoice line with the state of its invoice.
+type InvoiceLineRow struct {
+	InvoiceItemID snowflake.ID `gorm:"column:invoice_item_id"`
+	InvoiceID     snowflake.ID `gorm:"column:invoice_id"`
+	Amount        int64        `gorm:"column:amount"`
+	InvoiceStatus string       `gorm:"column:invoice_status"`
+	VoidedAt      sql.NullTime `gorm:"column:voided_at"`
+}
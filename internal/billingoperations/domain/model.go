@@ -22,6 +22,17 @@ type BillingActionRecord struct {
 	CreatedAt      time.Time
 }
 
+type BillingSnoozeRecord struct {
+	ID           snowflake.ID
+	OrgID        snowflake.ID
+	EntityType   string
+	EntityID     snowflake.ID
+	SnoozedUntil time.Time
+	Reason       string
+	SnoozedBy    string
+	CreatedAt    time.Time
+}
+
 type BillingAssignmentRecord struct {
 	ID                  snowflake.ID
 	OrgID               snowflake.ID
@@ -40,8 +51,19 @@ type BillingAssignmentRecord struct {
 	BreachLevel         sql.NullString
 	LastActionAt        sql.NullTime
 	SnapshotMetadata    datatypes.JSON
-	CreatedAt           time.Time
-	UpdatedAt           time.Time
+	// SLAPausedUntil holds SLA evaluation off until it passes, for example
+	// while the customer follows an agreed payment plan.
+	SLAPausedUntil sql.NullTime `gorm:"column:sla_paused_until"`
+	// HandedOffAt, HandedOffBy and HandoffNote describe the last time the
+	// assignment was reassigned to its current assignee.
+	HandedOffAt sql.NullTime   `gorm:"column:handed_off_at"`
+	HandedOffBy sql.NullString `gorm:"column:handed_off_by"`
+	HandoffNote sql.NullString `gorm:"column:handoff_note"`
+	// ResolutionSnapshot is the entity as it stood when the assignment was
+	// resolved or released.
+	ResolutionSnapshot datatypes.JSON `gorm:"column:resolution_snapshot"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
 
 func (BillingAssignmentRecord) TableName() string {
@@ -58,6 +80,7 @@ type OverdueInvoiceRow struct {
 	CustomerID          snowflake.ID   `gorm:"column:customer_id"`
 	CustomerName        string         `gorm:"column:customer_name"`
 	AmountDue           int64          `gorm:"column:amount_due"`
+	Currency            string         `gorm:"column:currency"`
 	DueAt               time.Time      `gorm:"column:due_at"`
 	AssignedTo          sql.NullString `gorm:"column:assigned_to"`
 	AssignedAt          sql.NullTime   `gorm:"column:assigned_at"`
@@ -72,10 +95,26 @@ type OverdueInvoiceRow struct {
 	TokenHash           sql.NullString `gorm:"column:token_hash"`
 }
 
+// MissingPublicTokenRow is a finalized unpaid invoice without an active
+// public token.
+type MissingPublicTokenRow struct {
+	InvoiceID      snowflake.ID `gorm:"column:invoice_id"`
+	InvoiceNumber  string       `gorm:"column:invoice_number"`
+	CustomerID     snowflake.ID `gorm:"column:customer_id"`
+	CustomerName   string       `gorm:"column:customer_name"`
+	SubtotalAmount int64        `gorm:"column:subtotal_amount"`
+	Currency       string       `gorm:"column:currency"`
+	DueAt          sql.NullTime `gorm:"column:due_at"`
+	FinalizedAt    sql.NullTime `gorm:"column:finalized_at"`
+}
+
 type OutstandingCustomerRow struct {
 	CustomerID                 snowflake.ID   `gorm:"column:customer_id"`
 	CustomerName               string         `gorm:"column:customer_name"`
+	Currency                   string         `gorm:"column:currency"`
 	Outstanding                int64          `gorm:"column:outstanding"`
+	Pending                    int64          `gorm:"column:pending"`
+	PendingInvoices            int            `gorm:"column:pending_invoices"`
 	OldestOverdueInvoiceID     sql.NullString `gorm:"column:oldest_overdue_invoice_id"`
 	OldestOverdueInvoiceNumber sql.NullString `gorm:"column:oldest_overdue_invoice_number"`
 	OldestOverdueAt            sql.NullTime   `gorm:"column:oldest_overdue_at"`
@@ -114,7 +153,9 @@ type PaymentIssueRow struct {
 type CollectionQueueRow struct {
 	CustomerID            snowflake.ID   `gorm:"column:customer_id"`
 	CustomerName          string         `gorm:"column:customer_name"`
+	Currency              string         `gorm:"column:currency"`
 	Outstanding           int64          `gorm:"column:outstanding"`
+	DisputedAmount        int64          `gorm:"column:disputed_amount"`
 	OldestUnpaidInvoiceID sql.NullString `gorm:"column:oldest_unpaid_invoice_id"`
 	OldestUnpaidInvoice   sql.NullString `gorm:"column:oldest_unpaid_invoice_number"`
 	OldestUnpaidAt        sql.NullTime   `gorm:"column:oldest_unpaid_at"`
@@ -130,6 +171,11 @@ type CollectionQueueRow struct {
 	BreachedAt            sql.NullTime   `gorm:"column:assignment_breached_at"`
 	BreachLevel           sql.NullString `gorm:"column:assignment_breach_level"`
 	TokenHash             sql.NullString `gorm:"column:token_hash"`
+	LastEmailStatus       sql.NullString `gorm:"column:last_email_status"`
+	LastEmailAt           sql.NullTime   `gorm:"column:last_email_at"`
+	// QueuePriority is the aging bucket the queue is ordered by, 3 for the
+	// oldest.
+	QueuePriority int `gorm:"column:queue_priority"`
 }
 
 type FailedPaymentActionRow struct {
@@ -138,6 +184,7 @@ type FailedPaymentActionRow struct {
 	InvoiceID           sql.NullString `gorm:"column:invoice_id"`
 	InvoiceNumber       sql.NullString `gorm:"column:invoice_number"`
 	AmountDue           sql.NullInt64  `gorm:"column:amount_due"`
+	Currency            sql.NullString `gorm:"column:currency"`
 	DueAt               sql.NullTime   `gorm:"column:due_at"`
 	LastAttempt         sql.NullTime   `gorm:"column:last_attempt"`
 	AssignedTo          sql.NullString `gorm:"column:assigned_to"`
@@ -171,6 +218,7 @@ type AssignmentRow struct {
 	BreachedAt          sql.NullTime
 	BreachLevel         sql.NullString
 	LastActionAt        sql.NullTime
+	UpdatedAt           time.Time
 }
 
 type PerformanceMetrics struct {
@@ -221,11 +269,14 @@ type InboxRow struct {
 	EntityName   string         `gorm:"column:entity_name"`
 	RiskCategory string         `gorm:"column:risk_category"`
 	AmountDue    int64          `gorm:"column:amount_due"`
+	Currency     string         `gorm:"column:currency"`
 	DueAt        sql.NullTime   `gorm:"column:due_at"`
 	DaysOverdue  float64        `gorm:"column:days_overdue"`
 	LastAttempt  sql.NullTime   `gorm:"column:last_attempt"`
 	TokenHash    sql.NullString `gorm:"column:token_hash"`
-	RiskScore    int            `gorm:"column:risk_score"`
+	// DisputedAmount is the part of AmountDue under a line dispute.
+	DisputedAmount int64 `gorm:"column:disputed_amount"`
+	RiskScore      int   `gorm:"column:risk_score"`
 }
 
 type MyWorkRow struct {
@@ -239,10 +290,15 @@ type MyWorkRow struct {
 	EntityName         sql.NullString  `gorm:"column:entity_name"`
 	CustomerName       sql.NullString  `gorm:"column:customer_name"`
 	CustomerEmail      sql.NullString  `gorm:"column:customer_email"`
+	CustomerPhone      sql.NullString  `gorm:"column:customer_phone"`
+	ContactChannel     sql.NullString  `gorm:"column:preferred_contact_channel"`
+	DoNotContact       sql.NullBool    `gorm:"column:do_not_contact"`
 	InvoiceNumber      sql.NullString  `gorm:"column:invoice_number"`
 	CurrentAmountDue   sql.NullInt64   `gorm:"column:current_amount_due"`
+	Currency           sql.NullString  `gorm:"column:currency"`
 	CurrentDaysOverdue sql.NullFloat64 `gorm:"column:current_days_overdue"`
 	TokenHash          sql.NullString  `gorm:"column:token_hash"`
+	NowResolvedCount   int             `gorm:"column:now_resolved_count"`
 }
 
 type ResolvedRow struct {
@@ -255,6 +311,8 @@ type ResolvedRow struct {
 	ResolvedBy       sql.NullString `gorm:"column:resolved_by"`
 	ReleaseReason    sql.NullString `gorm:"column:release_reason"`
 	AssignedAt       time.Time      `gorm:"column:assigned_at"`
+	// ResolutionSnapshot is the entity as it stood at resolution.
+	ResolutionSnapshot datatypes.JSON `gorm:"column:resolution_snapshot"`
 }
 
 type TeamRow struct {
@@ -284,6 +342,70 @@ type ExposureStatsRow struct {
 	OverdueCount  int   `gorm:"column:overdue_count"`
 }
 
+// ExposureAgingRow is the overdue outstanding of one custom aging bucket.
+// Bucket indexes the bucket edges the query was run with; the last index
+// is the open-ended bucket past the final edge.
+type ExposureAgingRow struct {
+	Bucket       int   `gorm:"column:bucket"`
+	Amount       int64 `gorm:"column:amount"`
+	InvoiceCount int   `gorm:"column:invoice_count"`
+}
+
+type ARFlowStatsRow struct {
+	BeginningReceivables int64 `gorm:"column:beginning_receivables"`
+	EndingReceivables    int64 `gorm:"column:ending_receivables"`
+	TotalBilled          int64 `gorm:"column:total_billed"`
+	PaymentsReceived     int64 `gorm:"column:payments_received"`
+}
+
+// CustomerStatementHeaderRow carries the customer and the balance brought
+// forward for a statement.
+type CustomerStatementHeaderRow struct {
+	CustomerName   string `gorm:"column:customer_name"`
+	OpeningBalance int64  `gorm:"column:opening_balance"`
+}
+
+// CustomerStatementRow is one invoice, payment or credit note on a customer
+// statement, as debit or credit to the customer's receivable.
+type CustomerStatementRow struct {
+	EntryType     string       `gorm:"column:entry_type"`
+	EntryID       snowflake.ID `gorm:"column:entry_id"`
+	InvoiceID     string       `gorm:"column:invoice_id"`
+	InvoiceNumber string       `gorm:"column:invoice_number"`
+	OccurredAt    time.Time    `gorm:"column:occurred_at"`
+	Debit         int64        `gorm:"column:debit"`
+	Credit        int64        `gorm:"column:credit"`
+}
+
+// EntityPaymentEventRow is a payment event recorded for an invoice or
+// customer.
+type EntityPaymentEventRow struct {
+	ID              snowflake.ID   `gorm:"column:id"`
+	Provider        string         `gorm:"column:provider"`
+	ProviderEventID string         `gorm:"column:provider_event_id"`
+	EventType       string         `gorm:"column:event_type"`
+	Payload         datatypes.JSON `gorm:"column:payload"`
+	ReceivedAt      time.Time      `gorm:"column:received_at"`
+}
+
+// EntityAuditLogRow is an audit log entry about an invoice or customer,
+// either targeting it directly or a billing operation action on it.
+type EntityAuditLogRow struct {
+	ID         snowflake.ID      `gorm:"column:id"`
+	ActorType  string            `gorm:"column:actor_type"`
+	ActorID    string            `gorm:"column:actor_id"`
+	Action     string            `gorm:"column:action"`
+	TargetType string            `gorm:"column:target_type"`
+	TargetID   string            `gorm:"column:target_id"`
+	Metadata   datatypes.JSONMap `gorm:"column:metadata"`
+	CreatedAt  time.Time         `gorm:"column:created_at"`
+}
+
+type SLABreachActionRow struct {
+	Metadata  datatypes.JSONMap `gorm:"column:metadata"`
+	CreatedAt time.Time         `gorm:"column:created_at"`
+}
+
 type TopCustomerExposureRow struct {
 	EntityName  string `gorm:"column:entity_name"`
 	AmountDue   int64  `gorm:"column:amount_due"`
@@ -291,6 +413,16 @@ type TopCustomerExposureRow struct {
 	DaysOverdue int    `gorm:"column:days_overdue"`
 }
 
+// ExposureAnalysisRow is one row of the single-pass exposure query: the org
+// totals, repeated on every row, plus one ranked customer. Listed is false
+// for rows that only carry the totals (deleted customers, or when no top
+// customers were requested).
+type ExposureAnalysisRow struct {
+	ExposureStatsRow
+	TopCustomerExposureRow
+	Listed bool `gorm:"column:listed"`
+}
+
 type BillingAssignmentRow struct {
 	OrgID      snowflake.ID   `gorm:"column:org_id"`
 	EntityID   snowflake.ID   `gorm:"column:entity_id"`
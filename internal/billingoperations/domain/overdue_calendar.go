@@ -0,0 +1,101 @@
+package domain
+
+import "time"
+
+const holidayLayout = "2006-01-02"
+
+// OverdueCalendar decides how days overdue are counted for an organization.
+// The zero value counts every calendar day; with BusinessDaysOnly set,
+// days outside the work week and holidays are skipped. Without WorkDays the
+// work week is Monday to Friday, and without Location days are UTC.
+type OverdueCalendar struct {
+	BusinessDaysOnly bool
+	Holidays         map[string]struct{} // keyed by YYYY-MM-DD
+	WorkDays         map[time.Weekday]struct{}
+	Location         *time.Location
+}
+
+// NewOverdueCalendar builds a calendar from YYYY-MM-DD holiday dates.
+// Malformed dates are ignored.
+func NewOverdueCalendar(businessDaysOnly bool, holidays []string) OverdueCalendar {
+	calendar := OverdueCalendar{BusinessDaysOnly: businessDaysOnly}
+	for _, raw := range holidays {
+		day, err := time.Parse(holidayLayout, raw)
+		if err != nil {
+			continue
+		}
+		if calendar.Holidays == nil {
+			calendar.Holidays = make(map[string]struct{}, len(holidays))
+		}
+		calendar.Holidays[day.Format(holidayLayout)] = struct{}{}
+	}
+	return calendar
+}
+
+// WithWorkCalendar returns c counting business days on workDays in loc, with
+// extra holidays added to its own. Weekdays outside 0..6 and malformed
+// holidays are ignored; a nil loc keeps UTC.
+func (c OverdueCalendar) WithWorkCalendar(loc *time.Location, workDays []int, holidays []string) OverdueCalendar {
+	c.Location = loc
+	c.WorkDays = make(map[time.Weekday]struct{}, len(workDays))
+	for _, day := range workDays {
+		if day < int(time.Sunday) || day > int(time.Saturday) {
+			continue
+		}
+		c.WorkDays[time.Weekday(day)] = struct{}{}
+	}
+
+	merged := make(map[string]struct{}, len(c.Holidays)+len(holidays))
+	for day := range c.Holidays {
+		merged[day] = struct{}{}
+	}
+	for day := range NewOverdueCalendar(false, holidays).Holidays {
+		merged[day] = struct{}{}
+	}
+	c.Holidays = nil
+	if len(merged) > 0 {
+		c.Holidays = merged
+	}
+	return c
+}
+
+// DaysOverdue returns how many whole days have passed since dueAt, never
+// negative. For business-day calendars, each elapsed day counts only when it
+// ends on a work day that is not a holiday.
+func (c OverdueCalendar) DaysOverdue(dueAt, now time.Time) int {
+	days := int(now.Sub(dueAt).Hours() / 24)
+	if days <= 0 {
+		return 0
+	}
+	if !c.BusinessDaysOnly {
+		return days
+	}
+
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	businessDays := 0
+	for i := 1; i <= days; i++ {
+		day := dueAt.In(loc).AddDate(0, 0, i)
+		if c.isBusinessDay(day) {
+			businessDays++
+		}
+	}
+	return businessDays
+}
+
+func (c OverdueCalendar) isBusinessDay(day time.Time) bool {
+	if c.WorkDays != nil {
+		if _, ok := c.WorkDays[day.Weekday()]; !ok {
+			return false
+		}
+	} else {
+		switch day.Weekday() {
+		case time.Saturday, time.Sunday:
+			return false
+		}
+	}
+	_, holiday := c.Holidays[day.Format(holidayLayout)]
+	return !holiday
+}
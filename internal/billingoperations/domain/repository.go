@@ -5,43 +5,204 @@ import (
 	"time"
 
 	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
 	"gorm.io/gorm"
 )
 
+// DueDatePolicy decides the effective due date of invoices that were
+// finalized without one. A nil NetTermsDays leaves them undated, so they never
+// count as overdue; otherwise they are due NetTermsDays after issuance.
+type DueDatePolicy struct {
+	NetTermsDays *int
+}
+
+// ListDefaults holds an organization's default page sizes for billing
+// operations lists, read from its billing preferences. Zero means no
+// override is configured.
+type ListDefaults struct {
+	Inbox      int `gorm:"column:inbox_default_limit"`
+	MyWork     int `gorm:"column:my_work_default_limit"`
+	Overdue    int `gorm:"column:overdue_default_limit"`
+	Collection int `gorm:"column:collection_default_limit"`
+}
+
+// CollectionQueueFilter narrows which unpaid invoices feed the collection
+// queue. Each org sets its own in its billing preferences; the zero value
+// keeps every outstanding invoice.
+type CollectionQueueFilter struct {
+	// OverdueOnly drops invoices that are not yet past their due date, so
+	// customers with only current balances leave the queue.
+	OverdueOnly bool `gorm:"column:overdue_only"`
+	// MaxAgeDays, when positive, drops invoices due (or, if undated, issued)
+	// more than MaxAgeDays before now.
+	MaxAgeDays int `gorm:"column:max_age_days"`
+}
+
+// InboxFilter controls which customers the inbox lists for their exposure.
+type InboxFilter struct {
+	// HighExposureThreshold is the outstanding balance, in minor units, from
+	// which a customer counts as high exposure.
+	HighExposureThreshold int64
+	// IncludeCurrentExposure also lists customers over the threshold with no
+	// overdue invoice, under the balance_exposure risk category. Otherwise a
+	// customer needs at least one overdue invoice to appear.
+	IncludeCurrentExposure bool
+	// RiskCategory, when set, keeps only items of that category.
+	RiskCategory string
+	// MinAmountDue, when positive, drops items owing less.
+	MinAmountDue int64
+	// Currency keeps only items in that currency. The threshold, MinAmountDue
+	// and the amount part of risk scores are in its minor units.
+	Currency string
+}
+
+// AssignmentCursor resumes an assignment list after the last row of the
+// previous page, in (assigned_at, id) descending order.
+type AssignmentCursor struct {
+	AssignedAt time.Time
+	ID         snowflake.ID
+}
+
 type Repository interface {
 	WithTx(tx *gorm.DB) Repository
+	WithDueDatePolicy(policy DueDatePolicy) Repository
 	FetchOrgCurrency(ctx context.Context, orgID snowflake.ID) (string, error)
+	FetchListDefaults(ctx context.Context, orgID snowflake.ID) (ListDefaults, error)
+	FetchOverdueCalendar(ctx context.Context, orgID snowflake.ID) (OverdueCalendar, error)
+	// FetchOrgLocation returns the org's billing timezone, or UTC when it is
+	// unset. An unknown timezone is ErrInvalidTimezone.
+	FetchOrgLocation(ctx context.Context, orgID snowflake.ID) (*time.Location, error)
+	// FetchRequireHandoffNote reports whether the org requires a note when
+	// work is released or reassigned.
+	FetchRequireHandoffNote(ctx context.Context, orgID snowflake.ID) (bool, error)
+	// FetchReleaseOnResolve reports whether resolved work may be claimed
+	// afresh; an org that never set the preference counts as false.
+	FetchReleaseOnResolve(ctx context.Context, orgID snowflake.ID) (bool, error)
+	// FetchCollectionQueueFilter returns the org's collection queue filter,
+	// the zero filter when it has none.
+	FetchCollectionQueueFilter(ctx context.Context, orgID snowflake.ID) (CollectionQueueFilter, error)
+	// FetchTeamViewsIncludeSystemActors reports whether system actors appear
+	// in the org's team workload and performance views.
+	FetchTeamViewsIncludeSystemActors(ctx context.Context, orgID snowflake.ID) (bool, error)
+	// FetchAuditReads reports whether viewing the org's sensitive financial
+	// reports is audited.
+	FetchAuditReads(ctx context.Context, orgID snowflake.ID) (bool, error)
+	// FetchMemberDisplayName returns the display name of an org member, or ""
+	// when the user is not a member or has no name.
+	FetchMemberDisplayName(ctx context.Context, orgID, userID snowflake.ID) (string, error)
+	// ListOrgMemberIDs returns those of userIDs that are members of the org.
+	ListOrgMemberIDs(ctx context.Context, orgID snowflake.ID, userIDs []snowflake.ID) ([]snowflake.ID, error)
 	LoadEntitySnapshot(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID) (map[string]any, error)
-	ListOverdueInvoices(ctx context.Context, orgID snowflake.ID, currency string, now time.Time, limit int) ([]OverdueInvoiceRow, error)
-	ListOutstandingCustomers(ctx context.Context, orgID snowflake.ID, currency string, now time.Time, limit int) ([]OutstandingCustomerRow, error)
-	ListPaymentIssues(ctx context.Context, orgID snowflake.ID, now time.Time, limit int) ([]PaymentIssueRow, error)
-	LoadActionSummary(ctx context.Context, orgID snowflake.ID, currency string, now time.Time) (ActionSummaryRow, error)
-	ListCollectionQueue(ctx context.Context, orgID snowflake.ID, currency string, now time.Time, limit int) ([]CollectionQueueRow, error)
-	ListFailedPaymentActions(ctx context.Context, orgID snowflake.ID, currency string, now time.Time, limit int) ([]FailedPaymentActionRow, error)
+	// ReissuePublicToken revokes the invoice's active public token and stores
+	// tokenHash (the encrypted raw token) as its replacement. It returns
+	// ErrEntityNotFound unless the invoice is finalized and unpaid.
+	ReissuePublicToken(ctx context.Context, orgID, invoiceID, tokenID snowflake.ID, tokenHash string, now time.Time) error
+	// IssuePublicToken stores tokenHash as the invoice's public token unless
+	// it already has an active one, and reports whether it was stored.
+	IssuePublicToken(ctx context.Context, orgID, invoiceID, tokenID snowflake.ID, tokenHash string, now time.Time) (bool, error)
+	// ListMissingPublicTokens lists one page of finalized unpaid invoices
+	// without an active public token, oldest first.
+	ListMissingPublicTokens(ctx context.Context, orgID snowflake.ID, page pagination.Pagination) ([]MissingPublicTokenRow, pagination.PageInfo, error)
+	// ListOverdueInvoices lists one page of overdue invoices, oldest due
+	// first.
+	ListOverdueInvoices(ctx context.Context, orgID snowflake.ID, now time.Time, page pagination.Pagination) ([]OverdueInvoiceRow, pagination.PageInfo, error)
+	// ListOutstandingCustomers lists one page of customers with a balance,
+	// largest outstanding first.
+	ListOutstandingCustomers(ctx context.Context, orgID snowflake.ID, now time.Time, includeDrafts bool, page pagination.Pagination) ([]OutstandingCustomerRow, pagination.PageInfo, error)
+	// ListPaymentIssues lists customers with payment events of eventTypes.
+	// With clearOnSuccess, events followed by a successful payment from the
+	// same customer are treated as resolved and left out.
+	ListPaymentIssues(ctx context.Context, orgID snowflake.ID, eventTypes []string, clearOnSuccess bool, now time.Time, limit int) ([]PaymentIssueRow, error)
+	// LoadActionSummary counts failed payment attempts as ListPaymentIssues
+	// lists them, clearOnSuccess included.
+	LoadActionSummary(ctx context.Context, orgID snowflake.ID, currency string, eventTypes []string, clearOnSuccess bool, now time.Time) (ActionSummaryRow, error)
+	// ListCollectionQueue lists one page of the collection queue, most urgent
+	// first.
+	ListCollectionQueue(ctx context.Context, orgID snowflake.ID, now time.Time, filter CollectionQueueFilter, page pagination.Pagination) ([]CollectionQueueRow, pagination.PageInfo, error)
+	ListFailedPaymentActions(ctx context.Context, orgID snowflake.ID, eventTypes []string, now time.Time, limit int) ([]FailedPaymentActionRow, error)
 	LoadAssignment(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID) (*AssignmentRow, error)
 	LoadAssignmentForUpdate(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID) (*BillingAssignmentRecord, error)
 	ListActiveAssignments(ctx context.Context) ([]BillingAssignmentRecord, error)
+	HasActionSince(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID, actionTypes []string, since time.Time) (bool, error)
 
 	InsertBillingAction(ctx context.Context, record BillingActionRecord) (bool, error)
 	FindActionByIdempotencyKey(ctx context.Context, orgID snowflake.ID, key string) (*BillingActionLookup, error)
 	FindActionByBucket(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID, actionType string, bucket time.Time) (*BillingActionLookup, error)
+	// ArchiveActions removes up to limit actions created before olderThan,
+	// copying them to billing_operation_actions_archive first when archive
+	// is set. The newest action per entity and action type is always kept.
+	ArchiveActions(ctx context.Context, olderThan time.Time, limit int, archive bool, now time.Time) (int, error)
 
-	UpsertAssignment(ctx context.Context, record BillingAssignmentRecord) error
-	UpdateAssignmentStatus(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID, oldStatus, newStatus string, now time.Time) error
+	InsertSnooze(ctx context.Context, record BillingSnoozeRecord) error
+	// FindInvoiceLine returns nil when the line is not on the invoice in the
+	// org.
+	FindInvoiceLine(ctx context.Context, orgID, invoiceID, lineID snowflake.ID) (*InvoiceLineRow, error)
+	// UpsertInvoiceLineDispute records record, replacing the amount, reason
+	// and disputer of an existing dispute on the same line, and returns the
+	// stored dispute.
+	UpsertInvoiceLineDispute(ctx context.Context, record InvoiceLineDisputeRecord) (InvoiceLineDisputeRecord, error)
+	// UpsertAssignment writes record unless the stored assignment changed
+	// since the caller read it: its updated_at must still equal readAt, or,
+	// with a nil readAt, nobody may hold it. A stale write returns
+	// ErrAssignmentModified and can be retried after reloading.
+	UpsertAssignment(ctx context.Context, record BillingAssignmentRecord, readAt *time.Time) error
+	// ReassignAssignment hands the assignment to record.AssignedTo as a new
+	// assignment: the SLA clock, breach and last action start over and the
+	// handoff columns are set from record. Like UpsertAssignment, it returns
+	// ErrAssignmentModified when updated_at no longer equals readAt.
+	ReassignAssignment(ctx context.Context, record BillingAssignmentRecord, readAt time.Time) error
+	PauseAssignmentSLA(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID, until, now time.Time) error
+	// UpdateAssignmentStatus moves the assignment from oldStatus to newStatus
+	// if its updated_at still equals readAt, and returns
+	// ErrAssignmentModified otherwise.
+	UpdateAssignmentStatus(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID, oldStatus, newStatus string, readAt, now time.Time) error
 	EscalateAssignment(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID, breachType string, now time.Time) error
 
 	// IA Methods
-	ListInboxItems(ctx context.Context, orgID snowflake.ID, limit int, now time.Time) ([]InboxRow, error)
+	ListInboxItems(ctx context.Context, orgID snowflake.ID, filter InboxFilter, limit int, now time.Time) ([]InboxRow, error)
 	ListMyWorkItems(ctx context.Context, orgID snowflake.ID, userID string, limit int, now time.Time) ([]MyWorkRow, error)
 	ListRecentlyResolvedItems(ctx context.Context, orgID snowflake.ID, userID string, limit int, since time.Time) ([]ResolvedRow, error)
-	GetTeamViewStats(ctx context.Context, orgID snowflake.ID, now time.Time) ([]TeamRow, error)
+	// ListAssignments returns up to limit of the org's assignments matching
+	// filter, newest first, starting after cursor when it is set. The
+	// filter's page fields are ignored.
+	ListAssignments(ctx context.Context, orgID snowflake.ID, filter AssignmentFilter, cursor *AssignmentCursor, limit int) ([]BillingAssignmentRecord, error)
+	GetTeamViewStats(ctx context.Context, orgID snowflake.ID, excludeUserIDs []string, now time.Time) ([]TeamRow, error)
 	ListInvoicePayments(ctx context.Context, orgID, invoiceID snowflake.ID) ([]PaymentRow, error) // invoiceID snowflake or string? Service uses string for GetInvoicePayments but query passes it as param. Payment events metadata is string. If param is string, fine. Use ID if possible.
+	// ListEntityActions, ListEntityAssignments and ListEntityAuditLogs
+	// return what was recorded on the entity and, for a customer, on its
+	// invoices too.
+	ListEntityActions(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID) ([]BillingActionRecord, error)
+	FindEntityAssignment(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID) (*BillingAssignmentRecord, error)
+	ListEntityAssignments(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID) ([]BillingAssignmentRecord, error)
+	ListEntityPaymentEvents(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID) ([]EntityPaymentEventRow, error)
+	ListEntityAuditLogs(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID) ([]EntityAuditLogRow, error)
 	GetExposureStats(ctx context.Context, orgID snowflake.ID, now time.Time) (ExposureStatsRow, error)
+	// GetExposureAging sums the overdue outstanding into the buckets bounded
+	// by edges, ascending days overdue. Empty buckets have no row.
+	GetExposureAging(ctx context.Context, orgID snowflake.ID, now time.Time, edges []int) ([]ExposureAgingRow, error)
+	GetARFlowStats(ctx context.Context, orgID snowflake.ID, currency string, from, to time.Time) (ARFlowStatsRow, error)
+	// ListARBalances returns, per currency, the ledger receivable balance
+	// and the outstanding of open finalized invoices.
+	ListARBalances(ctx context.Context, orgID snowflake.ID) ([]ARBalanceRow, error)
+	// ListARInvoiceDrift returns up to limit finalized or voided invoices
+	// whose receivable in the ledger differs from their outstanding, by
+	// largest absolute difference.
+	ListARInvoiceDrift(ctx context.Context, orgID snowflake.ID, currency string, limit int) ([]ARInvoiceDriftRow, error)
+	// GetCustomerStatement returns a nil header when the customer does not
+	// exist in the org.
+	GetCustomerStatement(ctx context.Context, orgID, customerID snowflake.ID, currency string, from, to time.Time) (*CustomerStatementHeaderRow, []CustomerStatementRow, error)
+	ListSLABreachActions(ctx context.Context, orgID snowflake.ID, from, to time.Time) ([]SLABreachActionRow, error)
+	CountActionsByType(ctx context.Context, orgID snowflake.ID, actionType string, from, to time.Time) (int64, error)
+	CountEscalatedAssignments(ctx context.Context, orgID snowflake.ID, from, to time.Time) (int64, error)
 	ListTopHighExposure(ctx context.Context, orgID snowflake.ID, now time.Time) ([]TopCustomerExposureRow, error)
+	GetExposureAnalysis(ctx context.Context, orgID snowflake.ID, now time.Time, topLimit int) (ExposureStatsRow, []TopCustomerExposureRow, error)
 	ListBillingAssignmentsForPerformance(ctx context.Context, orgID snowflake.ID, userID string, start, end time.Time) ([]BillingAssignmentRow, error)
 
 	// FinOps methods
 	FindSnapshotsByUser(ctx context.Context, orgID snowflake.ID, userID string, periodType string, start, end time.Time) ([]FinOpsScoreSnapshot, error)
 	FindSnapshotsByUserWithLimit(ctx context.Context, orgID snowflake.ID, userID string, periodType string, start, end time.Time, limit int) ([]FinOpsScoreSnapshot, error)
-	FindSnapshotsByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time) ([]FinOpsScoreSnapshot, error)
+	FindSnapshotsByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time, excludeUserIDs []string) ([]FinOpsScoreSnapshot, error)
+	// StreamSnapshotsByOrg calls fn for each snapshot FindSnapshotsByOrg
+	// would return, without loading them all at once.
+	StreamSnapshotsByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time, excludeUserIDs []string, fn func(FinOpsScoreSnapshot) error) error
 }
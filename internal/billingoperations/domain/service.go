@@ -3,33 +3,71 @@ package domain
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"time"
+
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
 )
 
 type OverdueInvoice struct {
-	InvoiceID     string      `json:"invoice_id"`
-	InvoiceNumber string      `json:"invoice_number"`
-	CustomerID    string      `json:"customer_id"`
-	CustomerName  string      `json:"customer_name"`
-	AmountDue     int64       `json:"amount_due"`
-	Currency      string      `json:"currency"`
-	DueAt         time.Time   `json:"due_at"`
-	DaysOverdue   int         `json:"days_overdue"`
-	PublicToken   string      `json:"public_token,omitempty"`
-	Assignment    *Assignment `json:"assignment,omitempty"`
+	InvoiceID        string      `json:"invoice_id"`
+	InvoiceNumber    string      `json:"invoice_number"`
+	CustomerID       string      `json:"customer_id"`
+	CustomerName     string      `json:"customer_name"`
+	AmountDue        int64       `json:"amount_due"`
+	Currency         string      `json:"currency"`
+	CurrencyExponent int         `json:"currency_exponent"`
+	DueAt            time.Time   `json:"due_at"`
+	DaysOverdue      int         `json:"days_overdue"`
+	PublicToken      string      `json:"public_token,omitempty"`
+	Assignment       *Assignment `json:"assignment,omitempty"`
 }
 
 type OverdueInvoicesResponse struct {
-	Currency string           `json:"currency"`
-	Invoices []OverdueInvoice `json:"invoices"`
-	HasData  bool             `json:"has_data"`
+	pagination.PageInfo
+	Currency         string           `json:"currency"`
+	CurrencyExponent int              `json:"currency_exponent"`
+	Invoices         []OverdueInvoice `json:"invoices"`
+	HasData          bool             `json:"has_data"`
+}
+
+// MissingPublicToken is a finalized unpaid invoice that has no active public
+// token, so no pay link can be sent for it.
+type MissingPublicToken struct {
+	InvoiceID        string     `json:"invoice_id"`
+	InvoiceNumber    string     `json:"invoice_number"`
+	CustomerID       string     `json:"customer_id"`
+	CustomerName     string     `json:"customer_name"`
+	TotalAmount      int64      `json:"total_amount"`
+	Currency         string     `json:"currency"`
+	CurrencyExponent int        `json:"currency_exponent"`
+	DueAt            *time.Time `json:"due_at,omitempty"`
+	FinalizedAt      *time.Time `json:"finalized_at,omitempty"`
+}
+
+type MissingPublicTokensResponse struct {
+	pagination.PageInfo
+	Invoices []MissingPublicToken `json:"invoices"`
+}
+
+type ReissuePublicTokenResponse struct {
+	InvoiceID   string `json:"invoice_id"`
+	PublicToken string `json:"public_token"`
 }
 
+// OutstandingCustomer is a customer's receivables overview. PendingBalance
+// sums draft invoices that have not been finalized yet; it is only populated
+// when drafts are requested and never counts toward OutstandingBalance.
 type OutstandingCustomer struct {
 	CustomerID             string      `json:"customer_id"`
 	CustomerName           string      `json:"customer_name"`
 	OutstandingBalance     int64       `json:"outstanding_balance"`
+	PendingBalance         int64       `json:"pending_balance,omitempty"`
+	PendingInvoices        int         `json:"pending_invoices,omitempty"`
 	Currency               string      `json:"currency"`
+	CurrencyExponent       int         `json:"currency_exponent"`
 	OldestOverdueInvoiceID string      `json:"oldest_overdue_invoice_id,omitempty"`
 	OldestOverdueInvoice   string      `json:"oldest_overdue_invoice,omitempty"`
 	OldestOverdueAt        *time.Time  `json:"oldest_overdue_at,omitempty"`
@@ -41,9 +79,11 @@ type OutstandingCustomer struct {
 }
 
 type OutstandingCustomersResponse struct {
-	Currency  string                `json:"currency"`
-	Customers []OutstandingCustomer `json:"customers"`
-	HasData   bool                  `json:"has_data"`
+	pagination.PageInfo
+	Currency         string                `json:"currency"`
+	CurrencyExponent int                   `json:"currency_exponent"`
+	Customers        []OutstandingCustomer `json:"customers"`
+	HasData          bool                  `json:"has_data"`
 }
 
 type PaymentIssue struct {
@@ -61,6 +101,8 @@ type PaymentIssuesResponse struct {
 	HasData bool           `json:"has_data"`
 }
 
+// ActionSummary counts invoices in Currency, the org currency, only; invoices
+// in other currencies appear in the collection queue but not in the totals.
 type ActionSummary struct {
 	CustomersWithOutstanding int    `json:"customers_with_outstanding"`
 	OverdueInvoices          int    `json:"overdue_invoices"`
@@ -77,6 +119,7 @@ type CriticalAction struct {
 	CustomerName        string      `json:"customer_name"`
 	AmountDue           int64       `json:"amount_due"`
 	Currency            string      `json:"currency"`
+	CurrencyExponent    int         `json:"currency_exponent"`
 	DueAt               *time.Time  `json:"due_at,omitempty"`
 	DaysOverdue         int         `json:"days_overdue"`
 	LastAttempt         *time.Time  `json:"last_attempt,omitempty"`
@@ -90,7 +133,9 @@ type CollectionQueueEntry struct {
 	CustomerID            string      `json:"customer_id"`
 	CustomerName          string      `json:"customer_name"`
 	OutstandingBalance    int64       `json:"outstanding_balance"`
+	DisputedAmount        int64       `json:"disputed_amount"`
 	Currency              string      `json:"currency"`
+	CurrencyExponent      int         `json:"currency_exponent"`
 	OldestUnpaidInvoiceID string      `json:"oldest_unpaid_invoice_id,omitempty"`
 	OldestUnpaidInvoice   string      `json:"oldest_unpaid_invoice,omitempty"`
 	OldestUnpaidAt        *time.Time  `json:"oldest_unpaid_at,omitempty"`
@@ -102,15 +147,20 @@ type CollectionQueueEntry struct {
 	AssignmentExpiresAt   *time.Time  `json:"assignment_expires_at,omitempty"`
 	PublicToken           string      `json:"public_token,omitempty"`
 	Assignment            *Assignment `json:"assignment,omitempty"`
+	// LastEmailBounced reports whether the most recent email sent to the
+	// customer bounced, so collectors know to find another contact first.
+	LastEmailBounced   bool       `json:"last_email_bounced"`
+	LastEmailBouncedAt *time.Time `json:"last_email_bounced_at,omitempty"`
 }
 
 type BillingOperationsResponse struct {
-	Currency        string                 `json:"currency"`
-	Summary         ActionSummary          `json:"summary"`
-	CriticalActions []CriticalAction       `json:"critical_actions"`
-	CollectionQueue []CollectionQueueEntry `json:"collection_queue"`
-	PaymentIssues   []PaymentIssue         `json:"payment_issues"`
-	GeneratedAt     time.Time              `json:"generated_at"`
+	Currency         string                 `json:"currency"`
+	CurrencyExponent int                    `json:"currency_exponent"`
+	Summary          ActionSummary          `json:"summary"`
+	CriticalActions  []CriticalAction       `json:"critical_actions"`
+	CollectionQueue  []CollectionQueueEntry `json:"collection_queue"`
+	PaymentIssues    []PaymentIssue         `json:"payment_issues"`
+	GeneratedAt      time.Time              `json:"generated_at"`
 }
 
 type RecordActionRequest struct {
@@ -127,6 +177,31 @@ type RecordActionResponse struct {
 	RecordedAt time.Time `json:"recorded_at"`
 }
 
+// MaxRecordActionsBatchSize bounds the actions recorded in one batch.
+const MaxRecordActionsBatchSize = 100
+
+// RecordActionResult is the outcome of one action in a batch, in request
+// order. Status is ActionStatusError when Err is set; the server reports
+// Err as ErrorCode.
+type RecordActionResult struct {
+	Index      int        `json:"index"`
+	EntityType string     `json:"entity_type"`
+	EntityID   string     `json:"entity_id"`
+	ActionType string     `json:"action_type"`
+	ActionID   string     `json:"action_id,omitempty"`
+	Status     string     `json:"status"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+	ErrorCode  string     `json:"error_code,omitempty"`
+	Err        error      `json:"-"`
+}
+
+type RecordActionsBatchResponse struct {
+	Results    []RecordActionResult `json:"results"`
+	Recorded   int                  `json:"recorded"`
+	Duplicates int                  `json:"duplicates"`
+	Failed     int                  `json:"failed"`
+}
+
 type ClaimAssignmentRequest struct {
 	EntityType           string `json:"entity_type"`
 	EntityID             string `json:"entity_id"`
@@ -146,10 +221,44 @@ type ReleaseAssignmentRequest struct {
 	ReleasedBy string `json:"released_by"`
 }
 
+// ReassignAssignmentRequest hands an active assignment over to AssignTo.
+// Reason is the handoff note left for the new assignee.
+type ReassignAssignmentRequest struct {
+	EntityType   string `json:"entity_type"`
+	EntityID     string `json:"entity_id"`
+	AssignTo     string `json:"assign_to"`
+	Reason       string `json:"reason"`
+	ReassignedBy string `json:"reassigned_by"`
+}
+
+// Auto-assignment strategies. Round robin deals inbox items to assignees in
+// turn; least loaded always picks the assignee holding the fewest active
+// assignments.
+const (
+	AutoAssignStrategyRoundRobin  = "round_robin"
+	AutoAssignStrategyLeastLoaded = "least_loaded"
+)
+
+// AutoAssignRequest distributes the top inbox items across Assignees. No
+// assignee is given work once they hold MaxPerAgent active assignments.
+type AutoAssignRequest struct {
+	Strategy    string   `json:"strategy"`
+	Assignees   []string `json:"assignees"`
+	MaxPerAgent int      `json:"max_per_agent"`
+}
+
+// AutoAssignResponse lists the claims made by an auto-assignment run.
+// Skipped counts inbox items that were claimed by someone else while the
+// run was in progress.
+type AutoAssignResponse struct {
+	Assigned []Assignment `json:"assigned"`
+	Skipped  int          `json:"skipped"`
+}
+
 type ResolveAssignmentRequest struct {
 	EntityType string `json:"entity_type"`
 	EntityID   string `json:"entity_id"`
-	Resolution string `json:"resolution"` // e.g., "payment_received", "issue_fixed"
+	Resolution string `json:"resolution"` // e.g., "payment_received", "issue_fixed", "close_as_paid"
 	ResolvedBy string `json:"resolved_by"`
 }
 
@@ -164,10 +273,16 @@ type Assignment struct {
 	ReleasedAt          *time.Time `json:"released_at,omitempty"`
 	ReleasedBy          string     `json:"released_by,omitempty"`
 	ReleaseReason       string     `json:"release_reason,omitempty"`
+	HandedOffAt         *time.Time `json:"handed_off_at,omitempty"`
+	HandedOffBy         string     `json:"handed_off_by,omitempty"`
+	HandoffNote         string     `json:"handoff_note,omitempty"`
 	BreachedAt          *time.Time `json:"breached_at,omitempty"`
 	BreachLevel         string     `json:"breach_level,omitempty"`
 	SLAStatus           string     `json:"sla_status"`
 	TimeSinceAssigned   string     `json:"time_since_assigned"`
+	// SnapshotMetadata is the entity state captured at claim time, such as
+	// the amount due. Only the single-entity lookup fills it in.
+	SnapshotMetadata map[string]any `json:"snapshot_metadata,omitempty"`
 }
 
 type RecordFollowUpRequest struct {
@@ -181,17 +296,22 @@ const (
 )
 
 const (
+	ActionTypeContact      = "contact"
 	ActionTypeFollowUp     = "follow_up"
 	ActionTypeRetryPayment = "retry_payment"
 	ActionTypeMarkReviewed = "mark_reviewed"
 	ActionTypeClaim        = "claim"
 	ActionTypeRelease      = "released"
+	ActionTypeReassign     = "reassign"
 	ActionTypeResolve      = "resolve"
+	ActionTypeSnooze       = "snooze"
+	ActionTypeDisputeLine  = "dispute_line"
 )
 
 const (
 	ActionStatusRecorded  = "recorded"
 	ActionStatusDuplicate = "duplicate"
+	ActionStatusError     = "error"
 )
 
 const (
@@ -214,6 +334,51 @@ const (
 	AssignmentStatusEscalated = "escalated"
 )
 
+// HasActiveAssignment reports whether an assignment row still holds the
+// entity. A released row leaves the entity unassigned, exactly as if no
+// row existed, wherever assignments are loaded or listed.
+func HasActiveAssignment(assignedTo, status string) bool {
+	return assignedTo != "" && status != AssignmentStatusReleased
+}
+
+const (
+	SLABreachInitialResponse = "initial_response"
+	SLABreachIdleAction      = "idle_action"
+	SLABreachFirstContact    = "first_contact"
+)
+
+// CustomerContactActionTypes are the actions that reach the customer. Only
+// these stop the first contact SLA; internal actions such as mark_reviewed
+// do not.
+var CustomerContactActionTypes = []string{ActionTypeContact, ActionTypeFollowUp}
+
+const ActionTypeSLABreached = "sla_breached"
+
+// ActionTypeSLAPaused records that an assignment's SLAs were paused, for
+// example while a customer-confirmed payment plan runs.
+const ActionTypeSLAPaused = "sla_paused"
+
+// System actors act on billing operations without being operators. They are
+// excluded from team workload and performance views by default.
+const (
+	SystemActorID     = "system"
+	SLAMonitorActorID = "sla_monitor"
+	SchedulerActorID  = "scheduler"
+	// AutoAssignerActorID records claims made by inbox auto-assignment.
+	AutoAssignerActorID = "auto_assigner"
+)
+
+// IsSystemActor reports whether id identifies a system pseudo-user rather
+// than a human operator.
+func IsSystemActor(id string) bool {
+	switch strings.ToLower(strings.TrimSpace(id)) {
+	case SystemActorID, SLAMonitorActorID, SchedulerActorID, AutoAssignerActorID:
+		return true
+	default:
+		return strings.HasPrefix(strings.ToLower(id), SystemActorID+":")
+	}
+}
+
 const (
 	CriticalCategoryOverdueInvoice = "overdue_invoice"
 	CriticalCategoryFailedPayment  = "failed_payment"
@@ -221,35 +386,85 @@ const (
 
 
 type Service interface {
-	ListOverdueInvoices(ctx context.Context, limit int) (OverdueInvoicesResponse, error)
-	ListOutstandingCustomers(ctx context.Context, limit int) (OutstandingCustomersResponse, error)
+	// ListOverdueInvoices and ListOutstandingCustomers return one page of
+	// limit rows, resuming after pageToken when it is set.
+	ListOverdueInvoices(ctx context.Context, limit int, pageToken string) (OverdueInvoicesResponse, error)
+	ListOutstandingCustomers(ctx context.Context, limit int, includeDrafts bool, pageToken string) (OutstandingCustomersResponse, error)
 	ListPaymentIssues(ctx context.Context, limit int) (PaymentIssuesResponse, error)
+	// ListMissingPublicTokens returns one page of finalized unpaid invoices
+	// without an active public token, so they can be backfilled.
+	ListMissingPublicTokens(ctx context.Context, limit int, pageToken string) (MissingPublicTokensResponse, error)
+	// EnsurePublicTokens issues a public token for up to limit invoices
+	// listed by ListMissingPublicTokens and returns how many were issued.
+	EnsurePublicTokens(ctx context.Context, limit int) (int, error)
+	// ReissuePublicToken replaces the public link of a finalized unpaid
+	// invoice and returns the new raw token.
+	ReissuePublicToken(ctx context.Context, invoiceID string) (ReissuePublicTokenResponse, error)
 	GetOperations(ctx context.Context, limit int) (BillingOperationsResponse, error)
 	RecordAction(ctx context.Context, req RecordActionRequest) (RecordActionResponse, error)
+	RecordActionsBatch(ctx context.Context, actions []RecordActionRequest) (RecordActionsBatchResponse, error)
 	ClaimAssignment(ctx context.Context, req ClaimAssignmentRequest) (AssignmentResponse, error)
 	ReleaseAssignment(ctx context.Context, req ReleaseAssignmentRequest) error
+	ReassignAssignment(ctx context.Context, req ReassignAssignmentRequest) (AssignmentResponse, error)
+	// AutoAssignInbox claims the top inbox items on behalf of the given
+	// assignees, who must be members of the org, within their capacity.
+	AutoAssignInbox(ctx context.Context, strategy string, assignees []string, maxPerAgent int) (AutoAssignResponse, error)
 	ResolveAssignment(ctx context.Context, req ResolveAssignmentRequest) error
+	SnoozeEntity(ctx context.Context, entityType, entityID string, until time.Time, reason string) error
+	// PauseSLA holds SLA evaluation of the entity's active assignment off
+	// until the given time, at most 90 days ahead.
+	PauseSLA(ctx context.Context, entityType, entityID string, until time.Time) error
 	EvaluateSLAs(ctx context.Context) error
+	// ArchiveOldActions moves actions created before olderThan out of the
+	// live actions table and returns how many were moved.
+	ArchiveOldActions(ctx context.Context, olderThan time.Time) (int, error)
 	CalculatePerformance(ctx context.Context, userID string, start, end time.Time) (FinOpsScoreSnapshot, error)
-	GetPerformanceHistory(ctx context.Context, userID string, limit int) ([]FinOpsScoreSnapshot, error)
+	GetPerformanceHistory(ctx context.Context, userID string, req PerformanceHistoryRequest) ([]FinOpsScoreSnapshot, error)
 	AggregateDailyPerformance(ctx context.Context) error
+	// AggregateWeeklyPerformance and AggregateMonthlyPerformance roll daily
+	// snapshots up into the latest fully scored week or month.
+	AggregateWeeklyPerformance(ctx context.Context) error
+	AggregateMonthlyPerformance(ctx context.Context) error
+	AggregatePerformanceForDay(ctx context.Context, day time.Time) error
 
 	// API Methods (Read-Only from Snapshots)
 	GetMyPerformance(ctx context.Context, userID string, req GetPerformanceRequest) (*PerformanceResponse, error)
 	GetTeamPerformance(ctx context.Context, req GetPerformanceRequest) (*TeamPerformanceResponse, error)
+	// ExportTeamPerformance writes the team's snapshots for the period to w
+	// as CSV.
+	ExportTeamPerformance(ctx context.Context, req ExportTeamPerformanceRequest, w io.Writer) error
 
 	// IA Methods (Task-Centric Views)
 	GetInbox(ctx context.Context, req InboxRequest) (InboxResponse, error)
 	GetMyWork(ctx context.Context, userID string, req MyWorkRequest) (MyWorkResponse, error)
 	GetRecentlyResolved(ctx context.Context, userID string, req RecentlyResolvedRequest) (RecentlyResolvedResponse, error)
 	GetTeamView(ctx context.Context, req TeamViewRequest) (TeamViewResponse, error)
+	ListAssignments(ctx context.Context, filter AssignmentFilter) (ListAssignmentsResponse, error)
+	// GetAssignmentForEntity returns the entity's current assignment, or nil
+	// when nobody holds it.
+	GetAssignmentForEntity(ctx context.Context, entityType, entityID string) (*Assignment, error)
 	GetExposureAnalysis(ctx context.Context, req ExposureAnalysisRequest) (ExposureAnalysisResponse, error)
+	GetARHealth(ctx context.Context, from, to time.Time) (ARHealthResponse, error)
+	// ReconcileAR compares the org's ledger receivable balance with invoice
+	// outstanding per currency and reports drift over the configured
+	// threshold.
+	ReconcileAR(ctx context.Context) (ARReconciliation, error)
+	GetSLAStats(ctx context.Context, from, to time.Time) (SLAStatsResponse, error)
 
 	// Follow-Up Email (opens user's email client)
 	RecordFollowUp(ctx context.Context, req RecordFollowUpRequest) error
 
 	// Invoice Payment Details
 	GetInvoicePayments(ctx context.Context, invoiceID string) (InvoicePaymentsResponse, error)
+
+	// Customer Statement of Account
+	GetCustomerStatement(ctx context.Context, customerID string, from, to time.Time) (CustomerStatement, error)
+
+	// Invoice Line Disputes
+	DisputeInvoiceLine(ctx context.Context, invoiceID, lineID string, amount int64, reason string) (InvoiceLineDispute, error)
+
+	// Entity Audit Trail (disputes and legal requests)
+	ExportEntityAuditTrail(ctx context.Context, entityType, entityID string) (EntityAuditTrail, error)
 }
 
 var (
@@ -261,4 +476,83 @@ var (
 	ErrInvalidIdempotencyKey = errors.New("invalid_idempotency_key")
 	ErrInvalidAssignmentTTL  = errors.New("invalid_assignment_ttl")
 	ErrAssignmentConflict    = errors.New("assignment_conflict")
+	ErrInvalidPeriod         = errors.New("invalid_period")
+	ErrInvalidExcludeUsers   = errors.New("invalid_exclude_user_ids")
+	ErrInvalidRiskCategory   = errors.New("invalid_risk_category")
+	ErrInvalidMinAmountDue   = errors.New("invalid_min_amount_due")
+	ErrInvalidCurrency       = errors.New("invalid_currency")
+	ErrInvalidTimezone       = errors.New("invalid_timezone")
+	ErrInvalidSnoozeUntil    = errors.New("invalid_snooze_until")
+	ErrIncompletePeriod      = errors.New("incomplete_period")
+	ErrInvalidMetadata       = errors.New("invalid_metadata")
+	ErrMetadataTooLarge      = errors.New("metadata_too_large")
+	ErrInvalidCustomerID     = errors.New("invalid_customer_id")
+	ErrCustomerNotFound      = errors.New("customer_not_found")
+	ErrEntityNotFound        = errors.New("entity_not_found")
+	ErrInvalidTop            = errors.New("invalid_top")
+	ErrTeamViewTimeout       = errors.New("team_view_timeout")
+	ErrQueryTimeout          = errors.New("query_timeout")
+	ErrInvalidStatus         = errors.New("invalid_status")
+	ErrInvalidPageToken      = errors.New("invalid_page_token")
+	ErrPublicTokenKeyMissing = errors.New("public_token_key_missing")
+	ErrHandoffNoteRequired   = errors.New("handoff_note_required")
+	ErrAssignmentNotFound    = errors.New("assignment_not_found")
+	ErrInvalidSLAPauseUntil  = errors.New("invalid_sla_pause_until")
+	ErrAssignmentModified    = errors.New("assignment_modified")
+	ErrInvalidBucketEdges    = errors.New("invalid_bucket_edges")
+	ErrInvalidStrategy       = errors.New("invalid_auto_assign_strategy")
+	ErrInvalidMaxPerAgent    = errors.New("invalid_max_per_agent")
+	ErrInvalidLineID         = errors.New("invalid_line_id")
+	ErrInvalidDisputeAmount  = errors.New("invalid_dispute_amount")
+	ErrDisputeReasonRequired = errors.New("dispute_reason_required")
+	ErrInvoiceNotDisputable  = errors.New("invoice_not_disputable")
+	ErrInvoiceLineNotFound   = errors.New("invoice_line_not_found")
+	ErrActionNotRecorded     = errors.New("action_not_recorded")
+	ErrInvalidBatchSize      = errors.New("invalid_batch_size")
+	ErrInvalidOrderBy        = errors.New("invalid_order_by")
+	ErrInvalidOrderDirection = errors.New("invalid_order_direction")
+	ErrFeatureDisabled       = errors.New("feature_disabled")
+	ErrEntityNotPaid         = errors.New("entity_not_paid")
 )
+
+// MetadataTooLargeError is returned when caller-supplied action metadata
+// serializes to more than the configured limit. It matches
+// ErrMetadataTooLarge under errors.Is.
+type MetadataTooLargeError struct {
+	Size  int
+	Limit int
+}
+
+func (e *MetadataTooLargeError) Error() string {
+	return fmt.Sprintf("%s: %d bytes exceeds limit of %d", ErrMetadataTooLarge, e.Size, e.Limit)
+}
+
+func (e *MetadataTooLargeError) Is(target error) bool {
+	return target == ErrMetadataTooLarge
+}
+
+// AssignmentConflictAnonymous stands in for the assignee when conflict
+// details are configured to hide who holds the assignment.
+const AssignmentConflictAnonymous = "another operator"
+
+// AssignmentConflictError is returned when another operator already holds
+// the entity, with enough detail to show "Already claimed by Alice 20m ago".
+// AssignedTo is empty and AssigneeName is AssignmentConflictAnonymous when
+// the assignee is hidden. It matches ErrAssignmentConflict under errors.Is.
+type AssignmentConflictError struct {
+	AssignedTo          string    `json:"assigned_to,omitempty"`
+	AssigneeName        string    `json:"assignee_name"`
+	AssignedAt          time.Time `json:"assigned_at"`
+	AssignmentExpiresAt time.Time `json:"assignment_expires_at"`
+	Status              string    `json:"status"`
+	SLAStatus           string    `json:"sla_status"`
+	TimeSinceAssigned   string    `json:"time_since_assigned"`
+}
+
+func (e *AssignmentConflictError) Error() string {
+	return fmt.Sprintf("%s: held by %s since %s", ErrAssignmentConflict, e.AssigneeName, e.AssignedAt.Format(time.RFC3339))
+}
+
+func (e *AssignmentConflictError) Is(target error) bool {
+	return target == ErrAssignmentConflict
+}
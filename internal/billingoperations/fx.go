@@ -7,5 +7,6 @@ import (
 )
 
 var Module = fx.Module("billingoperations.service",
-	fx.Provide(service.NewService, config.NewBillingConfigHolder),
+	fx.Provide(service.NewService, service.NewBreachNotifier, config.NewBillingConfigHolder),
+	fx.Invoke(service.CheckPublicTokenKey),
 )
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestArchiveActions checks that old actions move to the archive while the
+// newest action per entity and action type stays in the live table.
+func TestArchiveActions(t *testing.T) {
+	cutoff := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := cutoff.AddDate(0, -2, 0)
+
+	for _, archive := range []bool{true, false} {
+		name := "archive"
+		if !archive {
+			name = "delete"
+		}
+		t.Run(name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+			if err != nil {
+				t.Fatalf("open db: %v", err)
+			}
+			columns := `id INTEGER PRIMARY KEY, org_id INTEGER, entity_type TEXT, entity_id INTEGER,
+				action_type TEXT, action_bucket DATE, idempotency_key TEXT, metadata TEXT,
+				actor_type TEXT, actor_id TEXT, assignment_id INTEGER, created_at DATETIME`
+			for _, stmt := range []string{
+				`CREATE TABLE billing_operation_actions (` + columns + `)`,
+				`CREATE TABLE billing_operation_actions_archive (` + columns + `, archived_at DATETIME)`,
+			} {
+				if err := db.Exec(stmt).Error; err != nil {
+					t.Fatalf("exec %q: %v", stmt, err)
+				}
+			}
+			insert := func(id, orgID, entityID int64, actionType string, createdAt time.Time) {
+				if err := db.Exec(
+					`INSERT INTO billing_operation_actions (id, org_id, entity_type, entity_id, action_type, action_bucket, metadata, created_at)
+					 VALUES (?, ?, 'invoice', ?, ?, ?, '{}', ?)`,
+					id, orgID, entityID, actionType, createdAt, createdAt,
+				).Error; err != nil {
+					t.Fatalf("insert action %d: %v", id, err)
+				}
+			}
+			insert(1, 1, 10, "contact", old)
+			insert(2, 1, 10, "contact", old.AddDate(0, 0, 1))
+			insert(3, 1, 10, "contact", cutoff.AddDate(0, 0, 1))
+			// Only action of its type for the entity: kept although old.
+			insert(4, 1, 10, "follow_up", old)
+			// Another org's entity with the same id.
+			insert(5, 2, 10, "contact", old)
+			insert(6, 2, 10, "contact", old.AddDate(0, 0, 3))
+
+			repo := NewRepository(db)
+			now := cutoff.Add(time.Hour)
+			moved, err := repo.ArchiveActions(context.Background(), cutoff, 2, archive, now)
+			if err != nil {
+				t.Fatalf("archive: %v", err)
+			}
+			if moved != 2 {
+				t.Fatalf("first batch moved %d, want 2", moved)
+			}
+			moved, err = repo.ArchiveActions(context.Background(), cutoff, 2, archive, now)
+			if err != nil {
+				t.Fatalf("archive: %v", err)
+			}
+			if moved != 1 {
+				t.Fatalf("second batch moved %d, want 1", moved)
+			}
+
+			ids := func(table string) []int64 {
+				var out []int64
+				if err := db.Raw(`SELECT id FROM ` + table + ` ORDER BY id`).Scan(&out).Error; err != nil {
+					t.Fatalf("list %s: %v", table, err)
+				}
+				return out
+			}
+			if got, want := ids("billing_operation_actions"), []int64{3, 4, 6}; !reflect.DeepEqual(got, want) {
+				t.Fatalf("live actions = %v, want %v", got, want)
+			}
+			wantArchived := []int64{1, 2, 5}
+			if !archive {
+				wantArchived = nil
+			}
+			if got := ids("billing_operation_actions_archive"); !reflect.DeepEqual(got, wantArchived) {
+				t.Fatalf("archived actions = %v, want %v", got, wantArchived)
+			}
+		})
+	}
+}
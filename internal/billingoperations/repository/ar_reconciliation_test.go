@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// pgCast matches the Postgres ::text casts the reconciliation queries use.
+var pgCast = regexp.MustCompile(`([a-z_]+\.[a-z_]+)::text`)
+
+// TestARReconciliationQueries runs ListARBalances and ListARInvoiceDrift
+// against sqlite, with the Postgres-only SQL rewritten to its sqlite
+// equivalent, for an org whose receivables are charged per billing cycle and
+// partly settled by a credit note.
+func TestARReconciliationQueries(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, stmt := range []string{
+		`CREATE TABLE organization_billing_preferences (org_id INTEGER, receivable_account_codes TEXT)`,
+		`CREATE TABLE invoices (id INTEGER PRIMARY KEY, org_id INTEGER, billing_cycle_id INTEGER, invoice_number INTEGER, status TEXT, currency TEXT, subtotal_amount INTEGER, voided_at DATETIME, paid_at DATETIME)`,
+		`CREATE TABLE ledger_accounts (id INTEGER PRIMARY KEY, org_id INTEGER, code TEXT)`,
+		`CREATE TABLE ledger_entries (id INTEGER PRIMARY KEY, org_id INTEGER, source_type TEXT, source_id INTEGER, currency TEXT, occurred_at DATETIME)`,
+		`CREATE TABLE ledger_entry_lines (id INTEGER PRIMARY KEY, ledger_entry_id INTEGER, account_id INTEGER, direction TEXT, amount INTEGER)`,
+		`CREATE TABLE payment_events (id INTEGER PRIMARY KEY, org_id INTEGER, payload TEXT)`,
+		`CREATE TABLE payment_allocations (payment_event_id INTEGER, invoice_id INTEGER)`,
+		`CREATE TABLE credit_notes (id INTEGER PRIMARY KEY, org_id INTEGER, invoice_id INTEGER)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("create table: %v", err)
+		}
+	}
+
+	voidedAt := time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+	for _, stmt := range []struct {
+		sql  string
+		args []any
+	}{
+		{`INSERT INTO ledger_accounts VALUES (1, 1, 'accounts_receivable'), (2, 1, 'revenue')`, nil},
+		// 10 is charged through its cycle and half credited; 11 was charged
+		// short; 12 was voided without reversing its charge; 13 is billed in
+		// USD and never reached the ledger.
+		{`INSERT INTO invoices VALUES
+			(10, 1, 100, 1001, 'FINALIZED', 'EUR', 1000, NULL, NULL),
+			(11, 1, 110, 1002, 'FINALIZED', 'EUR', 500, NULL, NULL),
+			(12, 1, 120, 1003, 'VOID', 'EUR', 300, ?, NULL),
+			(13, 1, 130, 1004, 'FINALIZED', 'USD', 700, NULL, NULL),
+			(14, 2, 140, 2001, 'FINALIZED', 'EUR', 900, NULL, NULL)`, []any{voidedAt}},
+		{`INSERT INTO credit_notes VALUES (200, 1, 10)`, nil},
+		{`INSERT INTO ledger_entries VALUES
+			(1, 1, 'billing_cycle', 100, 'EUR', NULL),
+			(2, 1, 'credit_note', 200, 'EUR', NULL),
+			(3, 1, 'billing_cycle', 11, 'EUR', NULL),
+			(4, 1, 'billing_cycle', 120, 'EUR', NULL)`, nil},
+		{`INSERT INTO ledger_entry_lines VALUES
+			(1, 1, 1, 'debit', 1000), (2, 1, 2, 'credit', 1000),
+			(3, 2, 1, 'credit', 500), (4, 2, 2, 'debit', 500),
+			(5, 3, 1, 'debit', 400), (6, 3, 2, 'credit', 400),
+			(7, 4, 1, 'debit', 300), (8, 4, 2, 'credit', 300)`, nil},
+	} {
+		if err := db.Exec(stmt.sql, stmt.args...).Error; err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+
+	if err := db.Callback().Row().Before("gorm:row").Register("test:sqlite", func(tx *gorm.DB) {
+		sql := tx.Statement.SQL.String()
+		sql = strings.ReplaceAll(sql, "#>> '{data,object,metadata,invoice_id}'", "->> '$.data.object.metadata.invoice_id'")
+		sql = pgCast.ReplaceAllString(sql, "CAST($1 AS TEXT)")
+		sql = strings.ReplaceAll(sql, "GREATEST(", "MAX(")
+		tx.Statement.SQL.Reset()
+		tx.Statement.SQL.WriteString(sql)
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	repo := NewRepository(db)
+
+	t.Run("balances", func(t *testing.T) {
+		rows, err := repo.ListARBalances(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("list AR balances: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("expected EUR and USD balances, got %+v", rows)
+		}
+		// Ledger: 1000 - 500 + 400 + 300. Outstanding: 10 owes 500, 11 owes 500.
+		if rows[0].Currency != "EUR" || rows[0].LedgerBalance != 1200 || rows[0].InvoiceOutstanding != 1000 {
+			t.Fatalf("unexpected EUR balance %+v", rows[0])
+		}
+		if rows[1].Currency != "USD" || rows[1].LedgerBalance != 0 || rows[1].InvoiceOutstanding != 700 {
+			t.Fatalf("unexpected USD balance %+v", rows[1])
+		}
+	})
+
+	t.Run("invoice drift", func(t *testing.T) {
+		rows, err := repo.ListARInvoiceDrift(context.Background(), 1, "EUR", 10)
+		if err != nil {
+			t.Fatalf("list AR invoice drift: %v", err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("expected the voided and short-charged invoices, got %+v", rows)
+		}
+		if rows[0].InvoiceID != 12 || rows[0].Status != "VOID" || rows[0].LedgerBalance != 300 || rows[0].Outstanding != 0 || rows[0].Drift != 300 {
+			t.Fatalf("unexpected voided invoice drift %+v", rows[0])
+		}
+		if rows[1].InvoiceID != 11 || rows[1].InvoiceNumber != "1002" || rows[1].LedgerBalance != 400 || rows[1].Outstanding != 500 || rows[1].Drift != -100 {
+			t.Fatalf("unexpected short-charged invoice drift %+v", rows[1])
+		}
+
+		rows, err = repo.ListARInvoiceDrift(context.Background(), 1, "EUR", 1)
+		if err != nil {
+			t.Fatalf("list AR invoice drift: %v", err)
+		}
+		if len(rows) != 1 || rows[0].InvoiceID != 12 {
+			t.Fatalf("expected the limit to keep the largest drift, got %+v", rows)
+		}
+	})
+}
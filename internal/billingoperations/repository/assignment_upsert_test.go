@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"gorm.io/gorm"
+)
+
+func TestUpsertAssignment_RejectsStaleWrites(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	for _, stmt := range []string{
+		`CREATE TABLE billing_operation_assignments (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id BIGINT NOT NULL,
+			assigned_to TEXT NOT NULL,
+			assigned_at TIMESTAMP NOT NULL,
+			assignment_expires_at TIMESTAMP NOT NULL,
+			status TEXT NOT NULL,
+			released_at TIMESTAMP,
+			released_by TEXT,
+			release_reason TEXT,
+			last_action_at TIMESTAMP,
+			snapshot_metadata TEXT,
+			handed_off_at TIMESTAMP,
+			handed_off_by TEXT,
+			handoff_note TEXT,
+			resolved_at TIMESTAMP,
+			resolved_by TEXT,
+			resolution_snapshot TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX ux_billing_assignments_entity ON billing_operation_assignments (org_id, entity_type, entity_id)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+
+	repo := NewRepository(db)
+	ctx := context.Background()
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	const orgID, entityID = 1, 2
+	claim := func(assignee string, at time.Time) billingopsdomain.BillingAssignmentRecord {
+		return billingopsdomain.BillingAssignmentRecord{
+			ID:                  snowflake.ID(at.UnixNano()),
+			OrgID:               orgID,
+			EntityType:          billingopsdomain.EntityTypeInvoice,
+			EntityID:            entityID,
+			AssignedTo:          assignee,
+			AssignedAt:          at,
+			AssignmentExpiresAt: at.Add(time.Hour),
+			Status:              billingopsdomain.AssignmentStatusAssigned,
+			CreatedAt:           at,
+			UpdatedAt:           at,
+		}
+	}
+	load := func() *billingopsdomain.BillingAssignmentRecord {
+		record, err := repo.LoadAssignmentForUpdate(ctx, orgID, billingopsdomain.EntityTypeInvoice, entityID)
+		if err != nil {
+			t.Fatalf("load assignment: %v", err)
+		}
+		if record == nil {
+			t.Fatalf("expected an active assignment")
+		}
+		return record
+	}
+
+	if err := repo.UpsertAssignment(ctx, claim("agent_a", start), nil); err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if err := repo.UpsertAssignment(ctx, claim("agent_b", start.Add(time.Minute)), nil); !errors.Is(err, billingopsdomain.ErrAssignmentModified) {
+		t.Fatalf("expected a claim of a held assignment to be rejected, got %v", err)
+	}
+
+	read := load()
+	if err := repo.UpdateAssignmentStatus(ctx, orgID, billingopsdomain.EntityTypeInvoice, entityID,
+		billingopsdomain.AssignmentStatusAssigned, billingopsdomain.AssignmentStatusInProgress,
+		read.UpdatedAt, start.Add(2*time.Minute)); err != nil {
+		t.Fatalf("status update: %v", err)
+	}
+
+	stale := *read
+	stale.AssignmentExpiresAt = start.Add(3 * time.Hour)
+	stale.UpdatedAt = start.Add(3 * time.Minute)
+	if err := repo.UpsertAssignment(ctx, stale, &read.UpdatedAt); !errors.Is(err, billingopsdomain.ErrAssignmentModified) {
+		t.Fatalf("expected a stale upsert to be rejected, got %v", err)
+	}
+	if err := repo.UpdateAssignmentStatus(ctx, orgID, billingopsdomain.EntityTypeInvoice, entityID,
+		billingopsdomain.AssignmentStatusInProgress, billingopsdomain.AssignmentStatusAssigned,
+		read.UpdatedAt, start.Add(3*time.Minute)); !errors.Is(err, billingopsdomain.ErrAssignmentModified) {
+		t.Fatalf("expected a stale status update to be rejected, got %v", err)
+	}
+
+	fresh := load()
+	if fresh.Status != billingopsdomain.AssignmentStatusInProgress {
+		t.Fatalf("expected status %q to survive, got %q", billingopsdomain.AssignmentStatusInProgress, fresh.Status)
+	}
+	released := *fresh
+	released.Status = billingopsdomain.AssignmentStatusReleased
+	released.UpdatedAt = start.Add(4 * time.Minute)
+	if err := repo.UpsertAssignment(ctx, released, &fresh.UpdatedAt); err != nil {
+		t.Fatalf("release with a fresh read: %v", err)
+	}
+	if err := repo.UpsertAssignment(ctx, claim("agent_b", start.Add(5*time.Minute)), nil); err != nil {
+		t.Fatalf("claim of a released assignment: %v", err)
+	}
+	if got := load().AssignedTo; got != "agent_b" {
+		t.Fatalf("expected agent_b to hold the assignment, got %q", got)
+	}
+}
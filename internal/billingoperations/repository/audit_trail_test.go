@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+// TestEntityAuditTrailQueries checks that an invoice's trail holds what was
+// recorded on it, archived actions included, and that a customer's trail
+// adds what was recorded on its own invoices but not on other customers'.
+func TestEntityAuditTrailQueries(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC().Truncate(time.Second)
+	at := func(minutes int) time.Time { return now.Add(time.Duration(minutes) * time.Minute) }
+	ctx := context.Background()
+
+	acme, globex := seed.id(10), seed.id(20)
+	acmeInvoice, globexInvoice := seed.id(100), seed.id(110)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.customer(globex, "Globex")
+	seed.invoice(acmeInvoice, acme, "EUR", 1000, now)
+	seed.invoice(globexInvoice, globex, "EUR", 1000, now)
+
+	seed.action("billing_operation_actions_archive", seed.id(300), billingopsdomain.EntityTypeInvoice, acmeInvoice, billingopsdomain.ActionTypeClaim, at(1))
+	seed.action("billing_operation_actions", seed.id(310), billingopsdomain.EntityTypeInvoice, acmeInvoice, billingopsdomain.ActionTypeFollowUp, at(2))
+	seed.action("billing_operation_actions", seed.id(320), billingopsdomain.EntityTypeCustomer, acme, billingopsdomain.ActionTypeFollowUp, at(3))
+	seed.action("billing_operation_actions", seed.id(330), billingopsdomain.EntityTypeInvoice, globexInvoice, billingopsdomain.ActionTypeFollowUp, at(4))
+
+	seed.assignment(seed.id(400), billingopsdomain.EntityTypeInvoice, acmeInvoice, "agent", billingopsdomain.AssignmentStatusAssigned, at(1))
+	seed.assignment(seed.id(410), billingopsdomain.EntityTypeCustomer, acme, "agent", billingopsdomain.AssignmentStatusAssigned, at(3))
+	seed.assignment(seed.id(420), billingopsdomain.EntityTypeInvoice, globexInvoice, "agent", billingopsdomain.AssignmentStatusAssigned, at(4))
+
+	seed.auditLog(seed.id(500), "invoice.finalize", "invoice", acmeInvoice.String(), nil, at(0))
+	seed.auditLog(seed.id(510), "billing_operations.action.follow_up", "billing_operation_action", seed.id(310).String(),
+		map[string]any{"entity_type": billingopsdomain.EntityTypeInvoice, "entity_id": acmeInvoice.String()}, at(2))
+	seed.auditLog(seed.id(520), "customer.update", "customer", acme.String(), nil, at(3))
+	seed.auditLog(seed.id(530), "invoice.finalize", "invoice", globexInvoice.String(), nil, at(4))
+
+	repo := NewRepository(tx)
+	cases := []struct {
+		name        string
+		entityType  string
+		entityID    snowflake.ID
+		actions     []snowflake.ID
+		assignments []snowflake.ID
+		auditLogs   []snowflake.ID
+	}{
+		{
+			name:        "invoice",
+			entityType:  billingopsdomain.EntityTypeInvoice,
+			entityID:    acmeInvoice,
+			actions:     []snowflake.ID{seed.id(300), seed.id(310)},
+			assignments: []snowflake.ID{seed.id(400)},
+			auditLogs:   []snowflake.ID{seed.id(500), seed.id(510)},
+		},
+		{
+			name:        "customer",
+			entityType:  billingopsdomain.EntityTypeCustomer,
+			entityID:    acme,
+			actions:     []snowflake.ID{seed.id(300), seed.id(310), seed.id(320)},
+			assignments: []snowflake.ID{seed.id(400), seed.id(410)},
+			auditLogs:   []snowflake.ID{seed.id(500), seed.id(510), seed.id(520)},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actions, err := repo.ListEntityActions(ctx, pgTestOrgID, tc.entityType, tc.entityID)
+			if err != nil {
+				t.Fatalf("list actions: %v", err)
+			}
+			assertIDs(t, "actions", tc.actions, actions, func(r billingopsdomain.BillingActionRecord) snowflake.ID { return r.ID })
+
+			assignments, err := repo.ListEntityAssignments(ctx, pgTestOrgID, tc.entityType, tc.entityID)
+			if err != nil {
+				t.Fatalf("list assignments: %v", err)
+			}
+			assertIDs(t, "assignments", tc.assignments, assignments, func(r billingopsdomain.BillingAssignmentRecord) snowflake.ID { return r.ID })
+
+			auditLogs, err := repo.ListEntityAuditLogs(ctx, pgTestOrgID, tc.entityType, tc.entityID)
+			if err != nil {
+				t.Fatalf("list audit logs: %v", err)
+			}
+			assertIDs(t, "audit logs", tc.auditLogs, auditLogs, func(r billingopsdomain.EntityAuditLogRow) snowflake.ID { return r.ID })
+		})
+	}
+}
+
+// assertIDs fails the test unless rows hold exactly want, in order.
+func assertIDs[T any](t *testing.T, what string, want []snowflake.ID, rows []T, id func(T) snowflake.ID) {
+	t.Helper()
+	got := make([]snowflake.ID, 0, len(rows))
+	for _, row := range rows {
+		got = append(got, id(row))
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%s = %v, want %v", what, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s = %v, want %v", what, got, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+)
+
+// TestListCollectionQueue_Filters seeds one customer per invoice, not yet
+// due, recently overdue and overdue for over a year, and checks which of
+// them each collection queue filter keeps.
+func TestListCollectionQueue_Filters(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	upcoming, recent, stale := seed.id(10), seed.id(20), seed.id(30)
+	seed.org("EUR")
+	seed.customer(upcoming, "Upcoming")
+	seed.customer(recent, "Recent")
+	seed.customer(stale, "Stale")
+	seed.invoice(seed.id(100), upcoming, "EUR", 1000, now.AddDate(0, 0, 10))
+	seed.invoice(seed.id(110), recent, "EUR", 2000, now.AddDate(0, 0, -5))
+	seed.invoice(seed.id(120), stale, "EUR", 3000, now.AddDate(0, 0, -400))
+
+	repo := NewRepository(tx)
+	cases := []struct {
+		name   string
+		filter billingopsdomain.CollectionQueueFilter
+		want   []snowflake.ID
+	}{
+		{name: "no filter", want: []snowflake.ID{upcoming, recent, stale}},
+		{name: "overdue only", filter: billingopsdomain.CollectionQueueFilter{OverdueOnly: true}, want: []snowflake.ID{recent, stale}},
+		{name: "max age", filter: billingopsdomain.CollectionQueueFilter{MaxAgeDays: 365}, want: []snowflake.ID{upcoming, recent}},
+		{name: "both", filter: billingopsdomain.CollectionQueueFilter{OverdueOnly: true, MaxAgeDays: 365}, want: []snowflake.ID{recent}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, _, err := repo.ListCollectionQueue(ctx, pgTestOrgID, now, tc.filter, pagination.Pagination{PageSize: 50})
+			if err != nil {
+				t.Fatalf("list collection queue: %v", err)
+			}
+			got := make(map[snowflake.ID]bool, len(rows))
+			for _, row := range rows {
+				got[row.CustomerID] = true
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("queued customers = %v, want %v", got, tc.want)
+			}
+			for _, id := range tc.want {
+				if !got[id] {
+					t.Fatalf("queued customers = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
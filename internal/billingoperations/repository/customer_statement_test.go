@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"gorm.io/gorm"
+)
+
+// TestGetCustomerStatement_Queries checks the placeholders of both statement
+// queries against their bind variables, with accounts receivable split across
+// two ledger sub-accounts besides accounts_receivable. The Postgres-only SQL is swapped for a stand-in result
+// before it reaches sqlite.
+func TestGetCustomerStatement_Queries(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Exec(`CREATE TABLE organization_billing_preferences (org_id INTEGER, receivable_account_codes TEXT)`).Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO organization_billing_preferences VALUES (1, '["ar_domestic","ar_international"]')`).Error; err != nil {
+		t.Fatalf("insert preferences: %v", err)
+	}
+
+	type captured struct {
+		sql  string
+		vars []any
+	}
+	var queries []captured
+	if err := db.Callback().Row().Before("gorm:row").Register("test:capture", func(tx *gorm.DB) {
+		sql := tx.Statement.SQL.String()
+		if !strings.Contains(sql, "WITH entries AS") {
+			return
+		}
+		queries = append(queries, captured{sql: sql, vars: tx.Statement.Vars})
+		tx.Statement.SQL.Reset()
+		tx.Statement.Vars = nil
+		if strings.Contains(sql, "opening_balance") {
+			tx.Statement.SQL.WriteString(`SELECT 'Acme' AS customer_name, 1500 AS opening_balance`)
+		} else {
+			tx.Statement.SQL.WriteString(`SELECT 1 WHERE 1 = 0`)
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	header, rows, err := NewRepository(db).GetCustomerStatement(context.Background(), 1, 42, "USD", from, to)
+	if err != nil {
+		t.Fatalf("get customer statement: %v", err)
+	}
+	if header == nil || header.CustomerName != "Acme" || header.OpeningBalance != 1500 {
+		t.Fatalf("unexpected header %+v", header)
+	}
+	if len(rows) != 0 {
+		t.Fatalf("expected no rows, got %d", len(rows))
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 statement queries, got %d", len(queries))
+	}
+	for i, q := range queries {
+		if got := strings.Count(q.sql, "?"); got != len(q.vars) {
+			t.Errorf("query %d has %d placeholders but %d vars", i, got, len(q.vars))
+		}
+	}
+	for i, q := range queries {
+		if !strings.Contains(q.sql, "a.code IN (?,?,?)") {
+			t.Errorf("query %d does not match every receivable account: %s", i, q.sql)
+		}
+	}
+	// The opening balance is cut off at from, the entries at to.
+	if got := queries[0].vars[3]; got != from {
+		t.Errorf("header cutoff = %v, want %v", got, from)
+	}
+	if got := queries[1].vars[3]; got != to {
+		t.Errorf("entries cutoff = %v, want %v", got, to)
+	}
+}
+
+// TestGetCustomerStatement_Postgres seeds invoices, a payment and a credit
+// note on both sides of the statement period and checks the balance brought
+// forward and the entries listed, leaving out other customers and
+// currencies.
+func TestGetCustomerStatement_Postgres(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC().Truncate(time.Second)
+	ctx := context.Background()
+
+	acme, globex := seed.id(10), seed.id(20)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.customer(globex, "Globex")
+	// Brought forward: 10000 invoiced, 4000 paid.
+	seed.invoice(seed.id(100), acme, "EUR", 10000, now.AddDate(0, 0, -40))
+	seed.payment(seed.id(200), acme, seed.id(100), "EUR", 4000, now.AddDate(0, 0, -30))
+	// In the period: invoiced 15 days ago, credited 5 days ago.
+	seed.invoice(seed.id(110), acme, "EUR", 5000, now.AddDate(0, 0, 15))
+	seed.creditNote(seed.id(220), acme, seed.id(110), "EUR", 1000, now.AddDate(0, 0, -5))
+	// Not on the statement: another currency and another customer.
+	seed.payment(seed.id(230), acme, seed.id(110), "USD", 700, now.AddDate(0, 0, -3))
+	seed.invoice(seed.id(120), globex, "EUR", 3000, now.AddDate(0, 0, 20))
+
+	header, rows, err := NewRepository(tx).GetCustomerStatement(ctx, pgTestOrgID, acme, "EUR", now.AddDate(0, 0, -20), now)
+	if err != nil {
+		t.Fatalf("get customer statement: %v", err)
+	}
+	if header == nil || header.CustomerName != "Acme" || header.OpeningBalance != 6000 {
+		t.Fatalf("unexpected header %+v", header)
+	}
+
+	type entry struct {
+		entryType     string
+		entryID       snowflake.ID
+		debit, credit int64
+	}
+	want := []entry{
+		{entryType: "invoice", entryID: seed.id(110), debit: 5000},
+		{entryType: string(ledgerdomain.SourceTypeCreditNote), entryID: seed.id(220), credit: 1000},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("statement rows = %+v, want %+v", rows, want)
+	}
+	for i, row := range rows {
+		if got := (entry{row.EntryType, row.EntryID, row.Debit, row.Credit}); got != want[i] {
+			t.Fatalf("statement row %d = %+v, want %+v", i, got, want[i])
+		}
+		if row.InvoiceID != seed.id(110).String() {
+			t.Fatalf("statement row %d is for invoice %q, want %s", i, row.InvoiceID, seed.id(110))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+)
+
+// TestListOverdueInvoices_DueDatePolicy checks that an invoice without a due
+// date is only aged when the due date policy grants net terms, and then
+// falls due that many days after issuance.
+func TestListOverdueInvoices_DueDatePolicy(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC().Truncate(time.Second)
+	ctx := context.Background()
+
+	acme := seed.id(10)
+	dated, undated := seed.id(100), seed.id(110)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.invoice(dated, acme, "EUR", 1000, now.AddDate(0, 0, -5))
+	// Issued 45 days ago without a due date.
+	seed.invoice(undated, acme, "EUR", 2000, now.AddDate(0, 0, -15))
+	seed.exec(`UPDATE invoices SET due_at = NULL WHERE id = ?`, undated)
+	// Net terms are added as calendar days; keep DST out of them.
+	seed.exec(`SET LOCAL TIME ZONE 'UTC'`)
+
+	netTerms := func(days int) billingopsdomain.DueDatePolicy {
+		return billingopsdomain.DueDatePolicy{NetTermsDays: &days}
+	}
+	cases := []struct {
+		name         string
+		policy       billingopsdomain.DueDatePolicy
+		undatedDueAt time.Time
+		undatedIsDue bool
+	}{
+		{name: "ignore"},
+		{name: "net terms elapsed", policy: netTerms(30), undatedDueAt: now.AddDate(0, 0, -15), undatedIsDue: true},
+		{name: "net terms not elapsed", policy: netTerms(60)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewRepository(tx).WithDueDatePolicy(tc.policy)
+			rows, _, err := repo.ListOverdueInvoices(ctx, pgTestOrgID, now, pagination.Pagination{PageSize: 50})
+			if err != nil {
+				t.Fatalf("list overdue invoices: %v", err)
+			}
+			want := 1
+			if tc.undatedIsDue {
+				want = 2
+			}
+			if len(rows) != want {
+				t.Fatalf("got %d overdue invoices, want %d: %+v", len(rows), want, rows)
+			}
+			for _, row := range rows {
+				if row.InvoiceID != undated {
+					continue
+				}
+				if !row.DueAt.Equal(tc.undatedDueAt) {
+					t.Fatalf("undated invoice due at %s, want %s", row.DueAt, tc.undatedDueAt)
+				}
+			}
+		})
+	}
+}
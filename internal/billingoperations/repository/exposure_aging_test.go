@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+// TestGetExposureAging_Edges buckets overdue invoices by custom edges and
+// checks that an edge closes its bucket, that payments are netted and that
+// invoices not yet due or in another currency are left out.
+func TestGetExposureAging_Edges(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC().Truncate(time.Second)
+	ctx := context.Background()
+
+	acme := seed.id(10)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.invoice(seed.id(100), acme, "EUR", 2000, now.AddDate(0, 0, -10))
+	seed.invoice(seed.id(110), acme, "EUR", 1500, now.AddDate(0, 0, -15))
+	seed.invoice(seed.id(120), acme, "EUR", 3000, now.AddDate(0, 0, -30))
+	seed.invoice(seed.id(130), acme, "EUR", 4000, now.AddDate(0, 0, -100))
+	seed.payment(seed.id(200), acme, seed.id(130), "EUR", 1000, now.AddDate(0, 0, -50))
+	seed.invoice(seed.id(140), acme, "EUR", 5000, now.AddDate(0, 0, 5))
+	seed.invoice(seed.id(150), acme, "USD", 6000, now.AddDate(0, 0, -20))
+
+	rows, err := NewRepository(tx).GetExposureAging(ctx, pgTestOrgID, now, []int{15, 45})
+	if err != nil {
+		t.Fatalf("exposure aging: %v", err)
+	}
+	want := []billingopsdomain.ExposureAgingRow{
+		{Bucket: 0, Amount: 3500, InvoiceCount: 2},
+		{Bucket: 1, Amount: 3000, InvoiceCount: 1},
+		{Bucket: 2, Amount: 3000, InvoiceCount: 1},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("aging = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Fatalf("aging = %+v, want %+v", rows, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+// TestGetExposureAnalysis_SinglePass checks that the single-pass query
+// reports the same org totals as GetExposureStats however many top
+// customers it returns, ranks customers by amount due and leaves deleted
+// customers out of the list but not out of the totals.
+func TestGetExposureAnalysis_SinglePass(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC().Truncate(time.Second)
+	ctx := context.Background()
+
+	acme, globex, initech := seed.id(10), seed.id(20), seed.id(30)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.customer(globex, "Globex")
+	seed.customer(initech, "Initech")
+	seed.invoice(seed.id(100), acme, "EUR", 5000, now.AddDate(0, 0, -10))
+	seed.invoice(seed.id(110), acme, "EUR", 3000, now.AddDate(0, 0, 10))
+	seed.invoice(seed.id(120), globex, "EUR", 4000, now.AddDate(0, 0, -45))
+	seed.payment(seed.id(200), globex, seed.id(120), "EUR", 1000, now.AddDate(0, 0, -20))
+	seed.invoice(seed.id(130), initech, "EUR", 2000, now.AddDate(0, 0, -100))
+	seed.invoice(seed.id(140), acme, "USD", 9000, now.AddDate(0, 0, -10))
+	seed.exec(`UPDATE customers SET deleted_at = ? WHERE id = ?`, now, initech)
+
+	repo := NewRepository(tx)
+	want, err := repo.GetExposureStats(ctx, pgTestOrgID, now)
+	if err != nil {
+		t.Fatalf("exposure stats: %v", err)
+	}
+	if want.TotalExposure != 13000 || want.OverdueCount != 3 {
+		t.Fatalf("exposure stats = %+v, want 13000 exposed over 3 overdue invoices", want)
+	}
+
+	cases := []struct {
+		topLimit int
+		top      []billingopsdomain.TopCustomerExposureRow
+	}{
+		{topLimit: 0},
+		{topLimit: 1, top: []billingopsdomain.TopCustomerExposureRow{
+			{EntityName: "Acme", AmountDue: 8000, DaysOverdue: 10},
+		}},
+		{topLimit: 5, top: []billingopsdomain.TopCustomerExposureRow{
+			{EntityName: "Acme", AmountDue: 8000, DaysOverdue: 10},
+			{EntityName: "Globex", AmountDue: 3000, DaysOverdue: 45},
+		}},
+	}
+	for _, tc := range cases {
+		stats, top, err := repo.GetExposureAnalysis(ctx, pgTestOrgID, now, tc.topLimit)
+		if err != nil {
+			t.Fatalf("top %d: exposure analysis: %v", tc.topLimit, err)
+		}
+		if stats != want {
+			t.Fatalf("top %d: totals = %+v, want %+v", tc.topLimit, stats, want)
+		}
+		if len(top) != len(tc.top) {
+			t.Fatalf("top %d: customers = %+v, want %+v", tc.topLimit, top, tc.top)
+		}
+		for i := range tc.top {
+			if top[i] != tc.top[i] {
+				t.Fatalf("top %d: customers = %+v, want %+v", tc.topLimit, top, tc.top)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// BenchmarkExposureAnalysis compares the previous two-query exposure report
+// (GetExposureStats + ListTopHighExposure) with the single-pass
+// GetExposureAnalysis. The queries are Postgres-only, so it runs against a
+// migrated database holding a large org:
+//
+//	BILLINGOPS_BENCH_DSN=postgres://... BILLINGOPS_BENCH_ORG_ID=123 \
+//	  go test ./internal/billingoperations/repository -run '^$' -bench ExposureAnalysis
+func BenchmarkExposureAnalysis(b *testing.B) {
+	dsn := strings.TrimSpace(os.Getenv("BILLINGOPS_BENCH_DSN"))
+	if dsn == "" {
+		b.Skip("BILLINGOPS_BENCH_DSN not set")
+	}
+	orgID, err := snowflake.ParseString(strings.TrimSpace(os.Getenv("BILLINGOPS_BENCH_ORG_ID")))
+	if err != nil {
+		b.Fatalf("BILLINGOPS_BENCH_ORG_ID: %v", err)
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		b.Fatalf("open postgres: %v", err)
+	}
+	repo := NewRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	b.Run("two_queries", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetExposureStats(ctx, orgID, now); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := repo.ListTopHighExposure(ctx, orgID, now); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("single_pass", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := repo.GetExposureAnalysis(ctx, orgID, now, 5); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
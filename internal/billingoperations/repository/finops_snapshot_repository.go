@@ -61,17 +61,22 @@ func (r *FinOpsSnapshotRepository) FindByUserWithLimit(ctx context.Context, orgI
 	return mapRowsToSnapshots(rows), nil
 }
 
-// FindByOrg retrieves snapshots for all users in an org within a period range (for team view).
-// Ordered by UserID, then PeriodStart.
-func (r *FinOpsSnapshotRepository) FindByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time) ([]domain.FinOpsScoreSnapshot, error) {
+// FindByOrg retrieves snapshots for all users in an org within a period range (for team view),
+// leaving out excludeUserIDs. Ordered by UserID, then PeriodStart.
+func (r *FinOpsSnapshotRepository) FindByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time, excludeUserIDs []string) ([]domain.FinOpsScoreSnapshot, error) {
 	if ctxOrgID, ok := orgcontext.OrgIDFromContext(ctx); ok && ctxOrgID != orgID {
 		return nil, domain.ErrInvalidOrganization
 	}
 
-	var rows []domain.FinOpsSnapshotRow
-	if err := r.db.WithContext(ctx).Table("finops_performance_snapshots").
+	query := r.db.WithContext(ctx).Table("finops_performance_snapshots").
 		Where("org_id = ? AND period_type = ? AND period_start >= ? AND period_start < ?",
-			orgID, periodType, start, end).
+			orgID, periodType, start, end)
+	if len(excludeUserIDs) > 0 {
+		query = query.Where("user_id NOT IN ?", excludeUserIDs)
+	}
+
+	var rows []domain.FinOpsSnapshotRow
+	if err := query.
 		Order("user_id ASC, period_start ASC").
 		Find(&rows).Error; err != nil {
 		return nil, err
@@ -80,6 +85,39 @@ func (r *FinOpsSnapshotRepository) FindByOrg(ctx context.Context, orgID snowflak
 	return mapRowsToSnapshots(rows), nil
 }
 
+// StreamByOrg calls fn for each snapshot FindByOrg would return, in the
+// same order, reading one row at a time so a long export never holds the
+// whole team history in memory. It stops at the first error fn returns.
+func (r *FinOpsSnapshotRepository) StreamByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time, excludeUserIDs []string, fn func(domain.FinOpsScoreSnapshot) error) error {
+	if ctxOrgID, ok := orgcontext.OrgIDFromContext(ctx); ok && ctxOrgID != orgID {
+		return domain.ErrInvalidOrganization
+	}
+
+	query := r.db.WithContext(ctx).Table("finops_performance_snapshots").
+		Where("org_id = ? AND period_type = ? AND period_start >= ? AND period_start < ?",
+			orgID, periodType, start, end)
+	if len(excludeUserIDs) > 0 {
+		query = query.Where("user_id NOT IN ?", excludeUserIDs)
+	}
+
+	rows, err := query.Order("user_id ASC, period_start ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row domain.FinOpsSnapshotRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(mapRowsToSnapshots([]domain.FinOpsSnapshotRow{row})[0]); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func mapRowsToSnapshots(rows []domain.FinOpsSnapshotRow) []domain.FinOpsScoreSnapshot {
 	snapshots := make([]domain.FinOpsScoreSnapshot, len(rows))
 	for i, r := range rows {
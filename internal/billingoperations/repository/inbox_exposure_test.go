@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+// TestListInboxItems_HighExposure checks which customers the inbox lists
+// for the exposure threshold: those over it with an overdue invoice, and
+// those over it with nothing overdue only when current exposure is included.
+func TestListInboxItems_HighExposure(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	overdue, current, small := seed.id(10), seed.id(20), seed.id(30)
+	seed.org("EUR")
+	seed.customer(overdue, "Overdue")
+	seed.customer(current, "Current")
+	seed.customer(small, "Small")
+	seed.invoice(seed.id(100), overdue, "EUR", 20000, now.AddDate(0, 0, -5))
+	seed.invoice(seed.id(110), current, "EUR", 30000, now.AddDate(0, 0, 10))
+	seed.invoice(seed.id(120), small, "EUR", 500, now.AddDate(0, 0, -5))
+
+	cases := []struct {
+		name           string
+		includeCurrent bool
+		want           map[string]string
+	}{
+		{
+			name: "overdue required",
+			want: map[string]string{overdue.String(): billingopsdomain.RiskCategoryHighExposure},
+		},
+		{
+			name:           "current exposure included",
+			includeCurrent: true,
+			want: map[string]string{
+				overdue.String(): billingopsdomain.RiskCategoryHighExposure,
+				current.String(): billingopsdomain.RiskCategoryBalanceExposure,
+			},
+		},
+	}
+	repo := NewRepository(tx)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := repo.ListInboxItems(ctx, pgTestOrgID, billingopsdomain.InboxFilter{
+				HighExposureThreshold:  10000,
+				IncludeCurrentExposure: tc.includeCurrent,
+			}, 50, now)
+			if err != nil {
+				t.Fatalf("list inbox: %v", err)
+			}
+			got := map[string]string{}
+			for _, row := range rows {
+				if row.EntityType == billingopsdomain.EntityTypeCustomer {
+					got[row.EntityID] = row.RiskCategory
+				}
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("customers = %v, want %v", got, tc.want)
+			}
+			for id, category := range tc.want {
+				if got[id] != category {
+					t.Fatalf("customers = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"gorm.io/gorm"
+)
+
+var errQueryCaptured = errors.New("query captured")
+
+// inboxQuery builds the inbox query for filter and returns its SQL and bind
+// variables. The query is stopped before it reaches the database, since the
+// Postgres-only SQL cannot run on sqlite.
+func inboxQuery(t *testing.T, filter billingopsdomain.InboxFilter) (string, []any) {
+	t.Helper()
+	return inboxQueryWith(t, filter)
+}
+
+// inboxQueryReleasing is inboxQuery for an org whose release_on_resolve
+// preference is set to releaseOnResolve.
+func inboxQueryReleasing(t *testing.T, filter billingopsdomain.InboxFilter, releaseOnResolve bool) (string, []any) {
+	t.Helper()
+	return inboxQueryWith(t, filter, fmt.Sprintf(`UPDATE organization_billing_preferences SET release_on_resolve = %t`, releaseOnResolve))
+}
+
+// inboxQueryWith is inboxQuery after running setup against the org's
+// billing preferences and calendar.
+func inboxQueryWith(t *testing.T, filter billingopsdomain.InboxFilter, setup ...string) (string, []any) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	var (
+		sql  string
+		vars []any
+	)
+	if err := db.Callback().Row().Before("gorm:row").Register("test:capture", func(tx *gorm.DB) {
+		if strings.Contains(tx.Statement.SQL.String(), "combined") {
+			sql, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+			tx.AddError(errQueryCaptured)
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	stmts := append([]string{
+		`CREATE TABLE organization_billing_preferences (org_id INTEGER, currency TEXT, receivable_account_codes TEXT, release_on_resolve BOOLEAN, overdue_business_days BOOLEAN, overdue_holidays TEXT)`,
+		`CREATE TABLE org_calendars (org_id INTEGER PRIMARY KEY, timezone TEXT, work_days TEXT, holidays TEXT)`,
+		`INSERT INTO organization_billing_preferences (org_id, overdue_business_days) VALUES (1, false)`,
+	}, setup...)
+	for _, stmt := range stmts {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+
+	if _, err := NewRepository(db).ListInboxItems(context.Background(), 1, filter, 25, time.Now()); !errors.Is(err, errQueryCaptured) {
+		t.Fatalf("list inbox items: %v", err)
+	}
+	if sql == "" {
+		t.Fatal("inbox query was not captured")
+	}
+	if got := strings.Count(sql, "?"); got != len(vars) {
+		t.Fatalf("query has %d placeholders but %d vars", got, len(vars))
+	}
+	return sql, vars
+}
+
+func TestListInboxItems_Filters(t *testing.T) {
+	t.Run("no filters queries both sources", func(t *testing.T) {
+		sql, _ := inboxQuery(t, billingopsdomain.InboxFilter{HighExposureThreshold: 100_000})
+		if !strings.Contains(sql, "FROM risky_invoices") || !strings.Contains(sql, "FROM risky_customers") {
+			t.Fatalf("expected both CTEs, got %s", sql)
+		}
+		if strings.Contains(sql, "risk_category = ?") || strings.Contains(sql, "amount_due >= ?") {
+			t.Fatalf("expected no outer filters, got %s", sql)
+		}
+	})
+
+	t.Run("overdue skips the customer CTE", func(t *testing.T) {
+		sql, vars := inboxQuery(t, billingopsdomain.InboxFilter{RiskCategory: billingopsdomain.RiskCategoryOverdue, MinAmountDue: 5_000})
+		if strings.Contains(sql, "risky_customers") {
+			t.Fatalf("expected no customer CTE, got %s", sql)
+		}
+		if !strings.Contains(sql, "WHERE risk_category = ? AND amount_due >= ?") {
+			t.Fatalf("expected outer filters, got %s", sql)
+		}
+		if got := vars[len(vars)-2]; got != int64(5_000) {
+			t.Fatalf("expected min amount bound before the limit, got %v", got)
+		}
+	})
+
+	t.Run("currency keeps one currency's items", func(t *testing.T) {
+		sql, vars := inboxQuery(t, billingopsdomain.InboxFilter{HighExposureThreshold: 100_000, Currency: "EUR"})
+		if !strings.Contains(sql, "WHERE currency = ?") {
+			t.Fatalf("expected a currency filter, got %s", sql)
+		}
+		if got := vars[len(vars)-2]; got != "EUR" {
+			t.Fatalf("expected currency bound before the limit, got %v", got)
+		}
+	})
+
+	t.Run("disputed lines take no part in the risk score", func(t *testing.T) {
+		sql, _ := inboxQuery(t, billingopsdomain.InboxFilter{HighExposureThreshold: 100_000})
+		if got := strings.Count(sql, "FROM invoice_line_disputes"); got != 2 {
+			t.Fatalf("expected disputed amounts in both CTEs, got %d in %s", got, sql)
+		}
+		if !strings.Contains(sql, "GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0) - COALESCE(d.disputed_amount, 0), 0) / 10000") ||
+			!strings.Contains(sql, "((t.outstanding - t.disputed) / 10000)::int AS risk_score") {
+			t.Fatalf("expected risk scores net of disputes, got %s", sql)
+		}
+	})
+
+	t.Run("exposure skips the invoice CTE", func(t *testing.T) {
+		sql, _ := inboxQuery(t, billingopsdomain.InboxFilter{RiskCategory: billingopsdomain.RiskCategoryHighExposure})
+		if strings.Contains(sql, "risky_invoices") {
+			t.Fatalf("expected no invoice CTE, got %s", sql)
+		}
+		if !strings.Contains(sql, "FROM risky_customers") {
+			t.Fatalf("expected customer CTE, got %s", sql)
+		}
+	})
+
+	t.Run("resolved work holds the entity only when resolving is terminal", func(t *testing.T) {
+		filter := billingopsdomain.InboxFilter{HighExposureThreshold: 100_000}
+		unset, _ := inboxQuery(t, filter)
+		released, _ := inboxQueryReleasing(t, filter, true)
+		for _, sql := range []string{unset, released} {
+			if strings.Contains(sql, "'resolved'") {
+				t.Fatalf("expected resolved assignments to be released, got %s", sql)
+			}
+			if got := strings.Count(sql, "boa.status IN ('assigned', 'in_progress')"); got != 2 {
+				t.Fatalf("expected active assignments to hide both sources, got %d in %s", got, sql)
+			}
+		}
+
+		sql, _ := inboxQueryReleasing(t, filter, false)
+		if got := strings.Count(sql, "boa.status IN ('assigned', 'in_progress', 'resolved')"); got != 2 {
+			t.Fatalf("expected resolved assignments to hide both sources, got %d in %s", got, sql)
+		}
+	})
+}
+
+func TestListInboxItems_BusinessDays(t *testing.T) {
+	t.Run("calendar days", func(t *testing.T) {
+		sql, _ := inboxQuery(t, billingopsdomain.InboxFilter{HighExposureThreshold: 100_000})
+		if strings.Contains(sql, "generate_series") {
+			t.Fatalf("expected calendar days, got %s", sql)
+		}
+	})
+
+	t.Run("scores and orders by the org's business days", func(t *testing.T) {
+		sql, vars := inboxQueryWith(t, billingopsdomain.InboxFilter{HighExposureThreshold: 100_000},
+			`UPDATE organization_billing_preferences SET overdue_business_days = true, overdue_holidays = '["2025-06-02"]'`,
+			`INSERT INTO org_calendars VALUES (1, 'Asia/Riyadh', '[0,1,2,3,4]', '[]')`,
+		)
+		// Days overdue and the invoice risk score in the invoice CTE, and
+		// days overdue in the customer CTE.
+		if got := strings.Count(sql, "FROM generate_series("); got != 3 {
+			t.Fatalf("expected business days in 3 places, got %d in %s", got, sql)
+		}
+		if !strings.Contains(sql, "ORDER BY risk_score DESC, days_overdue DESC") {
+			t.Fatalf("expected the page ordered in SQL, got %s", sql)
+		}
+		var zones, holidays int
+		for _, v := range vars {
+			switch v {
+			case "Asia/Riyadh":
+				zones++
+			case "2025-06-02":
+				holidays++
+			}
+		}
+		if zones != 6 || holidays != 3 {
+			t.Fatalf("expected the org's time zone and holidays bound, got %v", vars)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+// TestListInboxItems_ReleasedWork checks that releasing work puts the
+// invoice or customer back in the inbox while it still meets the risk
+// criteria, while work still assigned keeps it out.
+func TestListInboxItems_ReleasedWork(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	acme, globex := seed.id(10), seed.id(20)
+	released, assigned := seed.id(100), seed.id(110)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.customer(globex, "Globex")
+	seed.invoice(released, acme, "EUR", 20000, now.AddDate(0, 0, -10))
+	seed.invoice(assigned, globex, "EUR", 30000, now.AddDate(0, 0, -10))
+	at := now.Add(-time.Hour)
+	seed.assignment(seed.id(200), billingopsdomain.EntityTypeInvoice, released, "agent", billingopsdomain.AssignmentStatusReleased, at)
+	seed.assignment(seed.id(210), billingopsdomain.EntityTypeCustomer, acme, "agent", billingopsdomain.AssignmentStatusReleased, at)
+	seed.assignment(seed.id(220), billingopsdomain.EntityTypeInvoice, assigned, "agent", billingopsdomain.AssignmentStatusAssigned, at)
+	seed.assignment(seed.id(230), billingopsdomain.EntityTypeCustomer, globex, "agent", billingopsdomain.AssignmentStatusAssigned, at)
+
+	rows, err := NewRepository(tx).ListInboxItems(ctx, pgTestOrgID, billingopsdomain.InboxFilter{HighExposureThreshold: 10000}, 50, now)
+	if err != nil {
+		t.Fatalf("list inbox: %v", err)
+	}
+	listed := map[string]bool{}
+	for _, row := range rows {
+		listed[row.EntityType+":"+row.EntityID] = true
+	}
+	want := map[string]bool{
+		billingopsdomain.EntityTypeInvoice + ":" + released.String(): true,
+		billingopsdomain.EntityTypeCustomer + ":" + acme.String():    true,
+		billingopsdomain.EntityTypeInvoice + ":" + assigned.String(): false,
+		billingopsdomain.EntityTypeCustomer + ":" + globex.String():  false,
+	}
+	for entity, wantListed := range want {
+		if listed[entity] != wantListed {
+			t.Fatalf("%s listed = %t, want %t (inbox %v)", entity, listed[entity], wantListed, listed)
+		}
+	}
+}
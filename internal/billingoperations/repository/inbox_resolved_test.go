@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+// TestListInboxItems_ResolvedWork checks when an overdue invoice whose work
+// was resolved shows in the inbox again: while release_on_resolve is unset
+// or true, but not once the org has made resolving terminal. Work still
+// held is never listed.
+func TestListInboxItems_ResolvedWork(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	acme := seed.id(10)
+	resolved, held := seed.id(100), seed.id(110)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.invoice(resolved, acme, "EUR", 2000, now.AddDate(0, 0, -10))
+	seed.invoice(held, acme, "EUR", 3000, now.AddDate(0, 0, -10))
+	seed.assignment(seed.id(200), billingopsdomain.EntityTypeInvoice, resolved, "agent", billingopsdomain.AssignmentStatusResolved, now.Add(-time.Hour))
+	seed.assignment(seed.id(210), billingopsdomain.EntityTypeInvoice, held, "agent", billingopsdomain.AssignmentStatusInProgress, now.Add(-time.Hour))
+
+	cases := []struct {
+		name             string
+		releaseOnResolve any // nil, true or false
+		listed           bool
+	}{
+		{name: "unset", listed: true},
+		{name: "released", releaseOnResolve: true, listed: true},
+		{name: "terminal", releaseOnResolve: false},
+	}
+	repo := NewRepository(tx)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			seed.exec(`UPDATE organization_billing_preferences SET release_on_resolve = ? WHERE org_id = ?`, tc.releaseOnResolve, pgTestOrgID)
+			rows, err := repo.ListInboxItems(ctx, pgTestOrgID, billingopsdomain.InboxFilter{}, 50, now)
+			if err != nil {
+				t.Fatalf("list inbox: %v", err)
+			}
+			listed := map[string]bool{}
+			for _, row := range rows {
+				if row.EntityType == billingopsdomain.EntityTypeInvoice {
+					listed[row.EntityID] = true
+				}
+			}
+			if listed[held.String()] {
+				t.Fatalf("invoice with work in progress listed: %v", listed)
+			}
+			if listed[resolved.String()] != tc.listed {
+				t.Fatalf("resolved invoice listed = %t, want %t", listed[resolved.String()], tc.listed)
+			}
+		})
+	}
+}
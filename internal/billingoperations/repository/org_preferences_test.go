@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"gorm.io/gorm"
+)
+
+// TestFetchOrgPreferences reads the per-org billing operations settings
+// stored in organization_billing_preferences, for an org that set them and
+// for one that has no preferences row.
+func TestFetchOrgPreferences(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, stmt := range []string{
+		`CREATE TABLE organization_billing_preferences (
+			org_id BIGINT PRIMARY KEY,
+			collection_queue_overdue_only BOOLEAN NOT NULL DEFAULT false,
+			collection_queue_max_age_days INTEGER NOT NULL DEFAULT 0,
+			team_views_include_system_actors BOOLEAN NOT NULL DEFAULT false,
+			audit_reads BOOLEAN NOT NULL DEFAULT false,
+			timezone TEXT
+		)`,
+		`INSERT INTO organization_billing_preferences (org_id, collection_queue_overdue_only, collection_queue_max_age_days, team_views_include_system_actors, audit_reads, timezone)
+			VALUES (1, true, 365, true, true, 'America/Los_Angeles')`,
+		`INSERT INTO organization_billing_preferences (org_id, timezone) VALUES (3, 'Mars/Olympus')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	t.Run("collection queue", func(t *testing.T) {
+		filter, err := repo.FetchCollectionQueueFilter(ctx, 1)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		if want := (billingopsdomain.CollectionQueueFilter{OverdueOnly: true, MaxAgeDays: 365}); filter != want {
+			t.Fatalf("filter = %+v, want %+v", filter, want)
+		}
+
+		filter, err = repo.FetchCollectionQueueFilter(ctx, 2)
+		if err != nil {
+			t.Fatalf("fetch without preferences: %v", err)
+		}
+		if filter != (billingopsdomain.CollectionQueueFilter{}) {
+			t.Fatalf("expected the zero filter without preferences, got %+v", filter)
+		}
+	})
+
+	t.Run("team views system actors", func(t *testing.T) {
+		include, err := repo.FetchTeamViewsIncludeSystemActors(ctx, 1)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		if !include {
+			t.Fatal("expected system actors to be included")
+		}
+
+		include, err = repo.FetchTeamViewsIncludeSystemActors(ctx, 2)
+		if err != nil {
+			t.Fatalf("fetch without preferences: %v", err)
+		}
+		if include {
+			t.Fatal("expected system actors to be hidden without preferences")
+		}
+	})
+
+	t.Run("audit reads", func(t *testing.T) {
+		enabled, err := repo.FetchAuditReads(ctx, 1)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		if !enabled {
+			t.Fatal("expected reads to be audited")
+		}
+
+		enabled, err = repo.FetchAuditReads(ctx, 2)
+		if err != nil {
+			t.Fatalf("fetch without preferences: %v", err)
+		}
+		if enabled {
+			t.Fatal("expected reads not to be audited without preferences")
+		}
+	})
+
+	t.Run("org location", func(t *testing.T) {
+		loc, err := repo.FetchOrgLocation(ctx, 1)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		if loc.String() != "America/Los_Angeles" {
+			t.Fatalf("location = %s, want America/Los_Angeles", loc)
+		}
+
+		loc, err = repo.FetchOrgLocation(ctx, 2)
+		if err != nil {
+			t.Fatalf("fetch without preferences: %v", err)
+		}
+		if loc != time.UTC {
+			t.Fatalf("expected UTC without preferences, got %s", loc)
+		}
+
+		if _, err := repo.FetchOrgLocation(ctx, 3); !errors.Is(err, billingopsdomain.ErrInvalidTimezone) {
+			t.Fatalf("expected %v for an unknown timezone, got %v", billingopsdomain.ErrInvalidTimezone, err)
+		}
+	})
+}
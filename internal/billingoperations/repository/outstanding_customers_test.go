@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+)
+
+// TestListOutstandingCustomers_Drafts checks that draft invoices are only
+// reported, as pending, when drafts are included, and that a customer with
+// nothing but drafts is then listed too.
+func TestListOutstandingCustomers_Drafts(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	acme, globex := seed.id(10), seed.id(20)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.customer(globex, "Globex")
+	seed.invoice(seed.id(100), acme, "EUR", 1000, now.AddDate(0, 0, -5))
+	seed.invoice(seed.id(110), acme, "EUR", 500, now.AddDate(0, 0, 30))
+	seed.invoice(seed.id(120), globex, "EUR", 700, now.AddDate(0, 0, 30))
+	seed.exec(`UPDATE invoices SET status = 'DRAFT', finalized_at = NULL WHERE id IN (?, ?)`, seed.id(110), seed.id(120))
+
+	type balance struct {
+		outstanding, pending int64
+		pendingInvoices      int
+	}
+	cases := []struct {
+		name          string
+		includeDrafts bool
+		want          map[snowflake.ID]balance
+	}{
+		{
+			name: "without drafts",
+			want: map[snowflake.ID]balance{acme: {outstanding: 1000}},
+		},
+		{
+			name:          "with drafts",
+			includeDrafts: true,
+			want: map[snowflake.ID]balance{
+				acme:   {outstanding: 1000, pending: 500, pendingInvoices: 1},
+				globex: {pending: 700, pendingInvoices: 1},
+			},
+		},
+	}
+	repo := NewRepository(tx)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, _, err := repo.ListOutstandingCustomers(ctx, pgTestOrgID, now, tc.includeDrafts, pagination.Pagination{PageSize: 50})
+			if err != nil {
+				t.Fatalf("list outstanding customers: %v", err)
+			}
+			got := make(map[snowflake.ID]balance, len(rows))
+			for _, row := range rows {
+				got[row.CustomerID] = balance{outstanding: row.Outstanding, pending: row.Pending, pendingInvoices: row.PendingInvoices}
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("balances = %+v, want %+v", got, tc.want)
+			}
+			for id, want := range tc.want {
+				if got[id] != want {
+					t.Fatalf("balances = %+v, want %+v", got, tc.want)
+				}
+			}
+		})
+	}
+}
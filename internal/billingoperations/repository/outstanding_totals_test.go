@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+)
+
+// TestOutstandingTotalsAgreeAcrossPaths seeds invoices settled in part by a
+// payment and a credit note, one paid in full, and a payment posted in
+// another currency than the invoice it references, then checks that the
+// inbox, the collection queue, the outstanding customers list, the exposure
+// reports and the entity snapshots all report the same outstanding amounts.
+func TestOutstandingTotalsAgreeAcrossPaths(t *testing.T) {
+	tx := openPGTest(t)
+	seed := pgSeed{t: t, tx: tx}
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	acme, globex := seed.id(10), seed.id(20)
+	seed.org("EUR")
+	seed.customer(acme, "Acme")
+	seed.customer(globex, "Globex")
+
+	// Acme owes 6000 of 100 and 4000 of 110. The USD payment references 100
+	// but must not settle a EUR invoice.
+	seed.invoice(seed.id(100), acme, "EUR", 10000, now.AddDate(0, 0, -40))
+	seed.payment(seed.id(200), acme, seed.id(100), "EUR", 4000, now.AddDate(0, 0, -20))
+	seed.payment(seed.id(210), acme, seed.id(100), "USD", 2500, now.AddDate(0, 0, -15))
+	seed.invoice(seed.id(110), acme, "EUR", 5000, now.AddDate(0, 0, -10))
+	seed.creditNote(seed.id(220), acme, seed.id(110), "EUR", 1000, now.AddDate(0, 0, -5))
+	// Globex owes all of 120 and 130, and nothing of 140.
+	seed.invoice(seed.id(120), globex, "EUR", 20000, now.AddDate(0, 0, -5))
+	seed.invoice(seed.id(130), globex, "USD", 7000, now.AddDate(0, 0, -20))
+	seed.invoice(seed.id(140), globex, "EUR", 3000, now.AddDate(0, 0, -3))
+	seed.payment(seed.id(230), globex, seed.id(140), "EUR", 3000, now.AddDate(0, 0, -1))
+
+	want := map[string]int64{"EUR": 30000, "USD": 7000}
+	repo := NewRepository(tx)
+
+	t.Run("inbox", func(t *testing.T) {
+		rows, err := repo.ListInboxItems(ctx, pgTestOrgID, billingopsdomain.InboxFilter{HighExposureThreshold: 1}, 50, now)
+		if err != nil {
+			t.Fatalf("list inbox: %v", err)
+		}
+		invoices, customers := map[string]int64{}, map[string]int64{}
+		for _, row := range rows {
+			switch row.EntityType {
+			case billingopsdomain.EntityTypeInvoice:
+				invoices[row.Currency] += row.AmountDue
+			case billingopsdomain.EntityTypeCustomer:
+				customers[row.Currency] += row.AmountDue
+			}
+		}
+		assertTotals(t, "overdue invoices", invoices, want)
+		assertTotals(t, "customers", customers, want)
+	})
+
+	t.Run("collection queue", func(t *testing.T) {
+		rows, _, err := repo.ListCollectionQueue(ctx, pgTestOrgID, now, billingopsdomain.CollectionQueueFilter{}, pagination.Pagination{PageSize: 50})
+		if err != nil {
+			t.Fatalf("list collection queue: %v", err)
+		}
+		totals := map[string]int64{}
+		for _, row := range rows {
+			totals[row.Currency] += row.Outstanding
+		}
+		assertTotals(t, "collection queue", totals, want)
+	})
+
+	t.Run("outstanding customers", func(t *testing.T) {
+		rows, _, err := repo.ListOutstandingCustomers(ctx, pgTestOrgID, now, false, pagination.Pagination{PageSize: 50})
+		if err != nil {
+			t.Fatalf("list outstanding customers: %v", err)
+		}
+		totals := map[string]int64{}
+		for _, row := range rows {
+			totals[row.Currency] += row.Outstanding
+		}
+		assertTotals(t, "outstanding customers", totals, want)
+	})
+
+	t.Run("exposure", func(t *testing.T) {
+		stats, err := repo.GetExposureStats(ctx, pgTestOrgID, now)
+		if err != nil {
+			t.Fatalf("exposure stats: %v", err)
+		}
+		if stats.TotalExposure != want["EUR"] || stats.OverdueCount != 3 {
+			t.Fatalf("exposure stats = %+v, want %d over 3 overdue invoices", stats, want["EUR"])
+		}
+		analysis, top, err := repo.GetExposureAnalysis(ctx, pgTestOrgID, now, 5)
+		if err != nil {
+			t.Fatalf("exposure analysis: %v", err)
+		}
+		if analysis != stats {
+			t.Fatalf("exposure analysis = %+v, want %+v", analysis, stats)
+		}
+		if len(top) != 2 || top[0].EntityName != "Globex" || top[0].AmountDue != 20000 || top[1].AmountDue != 10000 {
+			t.Fatalf("unexpected top exposure %+v", top)
+		}
+	})
+
+	t.Run("snapshots", func(t *testing.T) {
+		invoice, err := repo.LoadEntitySnapshot(ctx, pgTestOrgID, billingopsdomain.EntityTypeInvoice, seed.id(100))
+		if err != nil {
+			t.Fatalf("invoice snapshot: %v", err)
+		}
+		if invoice["amount_due"] != int64(6000) || invoice["currency"] != "EUR" {
+			t.Fatalf("unexpected invoice snapshot %+v", invoice)
+		}
+		customer, err := repo.LoadEntitySnapshot(ctx, pgTestOrgID, billingopsdomain.EntityTypeCustomer, acme)
+		if err != nil {
+			t.Fatalf("customer snapshot: %v", err)
+		}
+		if customer["outstanding_balance"] != int64(10000) || customer["currency"] != "EUR" {
+			t.Fatalf("unexpected customer snapshot %+v", customer)
+		}
+	})
+}
+
+// assertTotals fails the test when the per-currency totals of a path differ
+// from want.
+func assertTotals(t *testing.T, path string, got, want map[string]int64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s totals = %v, want %v", path, got, want)
+	}
+	for currency, amount := range want {
+		if got[currency] != amount {
+			t.Fatalf("%s totals = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestFetchOverdueCalendar_OrgCalendar checks that business-day overdue counts
+// follow the organization's working calendar: its work week, its time zone
+// and its holidays alongside the billing preference holidays.
+func TestFetchOverdueCalendar_OrgCalendar(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	for _, stmt := range []string{
+		`CREATE TABLE organization_billing_preferences (org_id INTEGER, overdue_business_days BOOLEAN, overdue_holidays TEXT)`,
+		`CREATE TABLE org_calendars (org_id INTEGER PRIMARY KEY, timezone TEXT, work_days TEXT, holidays TEXT)`,
+		`INSERT INTO organization_billing_preferences VALUES (1, true, '["2025-06-04"]'), (2, true, '[]')`,
+		// Sunday to Thursday in Riyadh, with Tuesday 2025-06-03 off.
+		`INSERT INTO org_calendars VALUES (1, 'Asia/Riyadh', '[0,1,2,3,4]', '["2025-06-03"]')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	repo := NewRepository(db)
+
+	// Due Friday 2025-05-30 22:00 UTC, already Saturday in Riyadh.
+	dueAt := time.Date(2025, 5, 30, 22, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 6, 6, 23, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name  string
+		orgID snowflake.ID
+		want  int
+	}{
+		// Sun, Mon and Thu count; Tue and Wed are holidays, Fri and Sat are off.
+		{name: "org calendar", orgID: 1, want: 3},
+		// Without an org calendar the week is Monday to Friday in UTC.
+		{name: "default work week", orgID: 2, want: 5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calendar, err := repo.FetchOverdueCalendar(context.Background(), tc.orgID)
+			if err != nil {
+				t.Fatalf("fetch calendar: %v", err)
+			}
+			if got := calendar.DaysOverdue(dueAt, now); got != tc.want {
+				t.Fatalf("days overdue = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
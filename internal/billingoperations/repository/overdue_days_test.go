@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+// TestOverdueDaysSQL runs the days overdue expression on Postgres and checks
+// it counts the same whole days as OverdueCalendar.DaysOverdue, which the
+// service applies to rows it did not read through SQL.
+func TestOverdueDaysSQL(t *testing.T) {
+	tx := openPGTest(t)
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	// A Monday noon, so the windows below span weekends and holidays.
+	now := time.Date(2025, 6, 16, 12, 0, 0, 0, time.UTC)
+	calendars := map[string]billingopsdomain.OverdueCalendar{
+		"calendar days": {},
+		"business days": billingopsdomain.NewOverdueCalendar(true, nil),
+		"holidays":      billingopsdomain.NewOverdueCalendar(true, []string{"2025-06-06", "2025-06-13"}),
+		"org work week": billingopsdomain.NewOverdueCalendar(true, []string{"2025-06-01"}).WithWorkCalendar(jakarta, []int{0, 1, 2, 3, 4}, []string{"2025-06-10"}),
+		"org time zone": billingopsdomain.NewOverdueCalendar(true, nil).WithWorkCalendar(jakarta, []int{1, 2, 3, 4, 5}, nil),
+		"no work days":  billingopsdomain.NewOverdueCalendar(true, nil).WithWorkCalendar(nil, nil, nil),
+	}
+	dueDates := []time.Time{
+		now.Add(time.Hour),
+		now.Add(-time.Hour),
+		now.AddDate(0, 0, -3),
+		now.AddDate(0, 0, -9).Add(-20 * time.Hour),
+		now.AddDate(0, 0, -30),
+		now.AddDate(0, 0, -45).Add(5 * time.Hour),
+	}
+
+	for name, calendar := range calendars {
+		t.Run(name, func(t *testing.T) {
+			expr, args := overdueDaysSQL(calendar, "d.due_at", now)
+			for _, dueAt := range dueDates {
+				var days float64
+				if err := tx.Raw(
+					"SELECT "+expr+" FROM (SELECT ?::timestamptz AS due_at) d",
+					append(append([]any{}, args...), dueAt)...,
+				).Scan(&days).Error; err != nil {
+					t.Fatalf("overdue days: %v", err)
+				}
+				got := max(int(days), 0)
+				if want := calendar.DaysOverdue(dueAt, now); got != want {
+					t.Fatalf("due %s: SQL counts %d days overdue, want %d", dueAt, got, want)
+				}
+			}
+		})
+	}
+}
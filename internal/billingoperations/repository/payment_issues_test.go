@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestListPaymentIssues_ClearOnSuccess checks that a failed payment followed
+// by a successful payment of the same invoice stops being listed, unless
+// historical failures are kept, and that open disputes are listed until they
+// are closed. sqlite returns MAX over timestamps as text, so the captured
+// query is re-run, with sqlite's JSON path operator, and only the customer
+// names are compared.
+func TestListPaymentIssues_ClearOnSuccess(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	var (
+		query string
+		vars  []any
+	)
+	if err := db.Callback().Row().Before("gorm:row").Register("test:capture", func(tx *gorm.DB) {
+		if query == "" && strings.Contains(tx.Statement.SQL.String(), "last_attempt") {
+			query, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+			tx.AddError(errQueryCaptured)
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	for _, stmt := range []string{
+		`CREATE TABLE customers (id INTEGER PRIMARY KEY, org_id INTEGER, name TEXT, deleted_at DATETIME)`,
+		`CREATE TABLE payment_events (id INTEGER PRIMARY KEY, org_id INTEGER, customer_id INTEGER, event_type TEXT, payload TEXT, received_at DATETIME)`,
+		`CREATE TABLE payment_disputes (id INTEGER PRIMARY KEY, org_id INTEGER, customer_id INTEGER, status TEXT, received_at DATETIME)`,
+		`CREATE TABLE billing_operation_assignments (org_id INTEGER, entity_type TEXT, entity_id INTEGER, assigned_to TEXT, assigned_at DATETIME, assignment_expires_at DATETIME, status TEXT, released_at DATETIME, released_by TEXT, release_reason TEXT, last_action_at DATETIME)`,
+		`INSERT INTO customers (id, org_id, name) VALUES (10, 1, 'Paid Later'), (11, 1, 'Still Failing'), (12, 1, 'Failed Again'), (13, 1, 'Open Dispute'), (14, 1, 'Closed Dispute'), (15, 1, 'Paid Other Invoice')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	events := []struct {
+		id         int
+		customerID int
+		eventType  string
+		invoiceID  string
+		receivedAt time.Time
+	}{
+		// Failed, then paid: resolved.
+		{1, 10, "payment_failed", "100", now.Add(-3 * time.Hour)},
+		{2, 10, "payment_succeeded", "100", now.Add(-time.Hour)},
+		// Failed with no success since.
+		{3, 11, "payment_failed", "", now.Add(-2 * time.Hour)},
+		// Paid, then failed again: still an issue.
+		{4, 12, "payment_failed", "300", now.Add(-5 * time.Hour)},
+		{5, 12, "payment_succeeded", "300", now.Add(-4 * time.Hour)},
+		{6, 12, "payment_failed", "301", now.Add(-30 * time.Minute)},
+		// Paid after the dispute was opened: the dispute stays open.
+		{7, 13, "payment_succeeded", "", now.Add(-10 * time.Minute)},
+		// Paid another invoice: the failed one is still an issue.
+		{8, 15, "payment_failed", "200", now.Add(-100 * time.Minute)},
+		{9, 15, "payment_succeeded", "201", now.Add(-50 * time.Minute)},
+	}
+	for _, e := range events {
+		payload := `{}`
+		if e.invoiceID != "" {
+			payload = `{"data":{"object":{"metadata":{"invoice_id":"` + e.invoiceID + `"}}}}`
+		}
+		if err := db.Exec(`INSERT INTO payment_events (id, org_id, customer_id, event_type, payload, received_at) VALUES (?, 1, ?, ?, ?, ?)`,
+			e.id, e.customerID, e.eventType, payload, e.receivedAt).Error; err != nil {
+			t.Fatalf("insert payment event: %v", err)
+		}
+	}
+	disputes := []struct {
+		id         int
+		customerID int
+		status     string
+		receivedAt time.Time
+	}{
+		{1, 13, "open", now.Add(-20 * time.Minute)},
+		{2, 14, "closed", now.Add(-15 * time.Minute)},
+	}
+	for _, d := range disputes {
+		if err := db.Exec(`INSERT INTO payment_disputes (id, org_id, customer_id, status, received_at) VALUES (?, 1, ?, ?, ?)`,
+			d.id, d.customerID, d.status, d.receivedAt).Error; err != nil {
+			t.Fatalf("insert payment dispute: %v", err)
+		}
+	}
+	repo := NewRepository(db)
+
+	cases := []struct {
+		name           string
+		eventTypes     []string
+		clearOnSuccess bool
+		want           []string
+	}{
+		{name: "clears on success", eventTypes: []string{"payment_failed"}, clearOnSuccess: true, want: []string{"Failed Again", "Paid Other Invoice", "Still Failing"}},
+		{name: "keeps history", eventTypes: []string{"payment_failed"}, clearOnSuccess: false, want: []string{"Failed Again", "Paid Other Invoice", "Still Failing", "Paid Later"}},
+		{name: "lists open disputes", eventTypes: []string{"payment_failed", "dispute.created"}, clearOnSuccess: true, want: []string{"Open Dispute", "Failed Again", "Paid Other Invoice", "Still Failing"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, vars = "", nil
+			if _, err := repo.ListPaymentIssues(context.Background(), 1, tc.eventTypes, tc.clearOnSuccess, now, 10); !errors.Is(err, errQueryCaptured) {
+				t.Fatalf("list payment issues: %v", err)
+			}
+			var rows []struct{ CustomerName string }
+			query = strings.ReplaceAll(query, "#>> '{data,object,metadata,invoice_id}'", "->> '$.data.object.metadata.invoice_id'")
+			if err := db.Raw(query, vars...).Scan(&rows).Error; err != nil {
+				t.Fatalf("run payment issues query: %v", err)
+			}
+			var got []string
+			for _, row := range rows {
+				got = append(got, row.CustomerName)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("customers = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("customers = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
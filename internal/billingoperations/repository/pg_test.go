@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"github.com/smallbiznis/railzway/internal/migration"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Most billing operations queries are Postgres-only (DISTINCT ON, casts,
+// FILTER, generate_series), so the tests that run them need a database:
+//
+//	BILLINGOPS_TEST_DSN=postgres://... go test ./internal/billingoperations/repository
+//
+// The migrations are applied on open, and every test seeds its rows in a
+// transaction rolled back at cleanup, so a scratch database can be reused.
+const pgTestDSNEnv = "BILLINGOPS_TEST_DSN"
+
+// pgTestOrgID is the org the Postgres tests seed. Row IDs are offsets from
+// it, far from the snowflake IDs a reused database already holds.
+const pgTestOrgID = snowflake.ID(7_000_000_000_000_000)
+
+// openPGTest opens BILLINGOPS_TEST_DSN, migrates it and returns a
+// transaction rolled back at cleanup. The test is skipped when the variable
+// is not set.
+func openPGTest(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := strings.TrimSpace(os.Getenv(pgTestDSNEnv))
+	if dsn == "" {
+		t.Skip(pgTestDSNEnv + " not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := migration.RunMigrations(sqlDB); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("begin: %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// pgSeed inserts the fixtures of a Postgres test into tx. Every helper
+// fails the test on error.
+type pgSeed struct {
+	t  *testing.T
+	tx *gorm.DB
+}
+
+func (s pgSeed) exec(sql string, args ...any) {
+	s.t.Helper()
+	if err := s.tx.Exec(sql, args...).Error; err != nil {
+		s.t.Fatalf("seed: %v", err)
+	}
+}
+
+// id returns the row ID n of the seeded org.
+func (s pgSeed) id(n int64) snowflake.ID {
+	return pgTestOrgID + snowflake.ID(n)
+}
+
+// pgTestAccounts are the ledger accounts org seeds, with IDs 1 to 3.
+var pgTestAccounts = []ledgerdomain.LedgerAccountCode{
+	ledgerdomain.AccountCodeAccountsReceivable,
+	ledgerdomain.AccountCodeCash,
+	ledgerdomain.AccountCodeRevenueFlat,
+}
+
+// org seeds pgTestOrgID billed in currency, with the pgTestAccounts ledger
+// accounts.
+func (s pgSeed) org(currency string) {
+	s.t.Helper()
+	s.exec(`INSERT INTO organization_billing_preferences (org_id, currency, timezone) VALUES (?, ?, 'UTC')`, pgTestOrgID, currency)
+	for _, code := range pgTestAccounts {
+		s.exec(`INSERT INTO ledger_accounts (id, org_id, code, name, type) VALUES (?, ?, ?, ?, 'asset')`,
+			s.account(code), pgTestOrgID, string(code), string(code))
+	}
+}
+
+// account returns the ID org seeded the ledger account code with.
+func (s pgSeed) account(code ledgerdomain.LedgerAccountCode) snowflake.ID {
+	return s.id(int64(slices.Index(pgTestAccounts, code) + 1))
+}
+
+func (s pgSeed) customer(id snowflake.ID, name string) {
+	s.t.Helper()
+	s.exec(`INSERT INTO customers (id, org_id, name, email) VALUES (?, ?, ?, ?)`,
+		id, pgTestOrgID, name, fmt.Sprintf("%d@example.com", id))
+}
+
+// invoice seeds a finalized invoice issued 30 days before it falls due. Its
+// billing cycle and subscription share its ID.
+func (s pgSeed) invoice(id, customerID snowflake.ID, currency string, subtotal int64, dueAt time.Time) {
+	s.t.Helper()
+	s.exec(`INSERT INTO invoices (
+			id, org_id, billing_cycle_id, subscription_id, customer_id, invoice_number,
+			status, total_amount, subtotal_amount, currency, issued_at, due_at, finalized_at
+		) VALUES (?, ?, ?, ?, ?, ?, 'FINALIZED', ?, ?, ?, ?, ?, ?)`,
+		id, pgTestOrgID, id, id, customerID, id.String(),
+		subtotal, subtotal, currency, dueAt.AddDate(0, 0, -30), dueAt, dueAt.AddDate(0, 0, -30))
+}
+
+// payment seeds a succeeded payment event for invoiceID, referenced from
+// the provider metadata, and posts it to the ledger in currency.
+func (s pgSeed) payment(id, customerID, invoiceID snowflake.ID, currency string, amount int64, at time.Time) {
+	s.t.Helper()
+	payload, err := json.Marshal(map[string]any{
+		"data": map[string]any{"object": map[string]any{"metadata": map[string]any{"invoice_id": invoiceID.String()}}},
+	})
+	if err != nil {
+		s.t.Fatalf("payload: %v", err)
+	}
+	s.exec(`INSERT INTO payment_events (id, org_id, provider, provider_event_id, event_type, customer_id, payload, received_at, processed_at)
+		VALUES (?, ?, 'stripe', ?, 'payment_succeeded', ?, ?, ?, ?)`,
+		id, pgTestOrgID, id.String(), customerID, string(payload), at, at)
+	s.entry(id, ledgerdomain.SourceTypePayment, currency, amount, ledgerdomain.AccountCodeCash, at)
+}
+
+// creditNote seeds a credit note against invoiceID and posts it to the
+// ledger.
+func (s pgSeed) creditNote(id, customerID, invoiceID snowflake.ID, currency string, amount int64, at time.Time) {
+	s.t.Helper()
+	s.exec(`INSERT INTO credit_notes (id, org_id, invoice_id, customer_id, amount, currency, issued_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		id, pgTestOrgID, invoiceID, customerID, amount, currency, at)
+	s.entry(id, ledgerdomain.SourceTypeCreditNote, currency, amount, ledgerdomain.AccountCodeRevenueFlat, at)
+}
+
+// entry posts amount from accounts receivable to debit for the source with
+// the same ID. Its lines take the next two IDs, so sources posted to the
+// ledger are seeded ten IDs apart.
+func (s pgSeed) entry(id snowflake.ID, sourceType ledgerdomain.LedgerSourceType, currency string, amount int64, debit ledgerdomain.LedgerAccountCode, at time.Time) {
+	s.t.Helper()
+	s.exec(`INSERT INTO ledger_entries (id, org_id, source_type, source_id, currency, occurred_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		id, pgTestOrgID, string(sourceType), id, currency, at)
+	s.exec(`INSERT INTO ledger_entry_lines (id, ledger_entry_id, account_id, direction, currency, amount) VALUES
+		(?, ?, ?, 'debit', ?, ?), (?, ?, ?, 'credit', ?, ?)`,
+		id+1, id, s.account(debit), currency, amount,
+		id+2, id, s.account(ledgerdomain.AccountCodeAccountsReceivable), currency, amount)
+}
+
+// action seeds a billing operation action in table, either
+// billing_operation_actions or its archive.
+func (s pgSeed) action(table string, id snowflake.ID, entityType string, entityID snowflake.ID, actionType string, at time.Time) {
+	s.t.Helper()
+	s.exec(`INSERT INTO `+table+` (id, org_id, entity_type, entity_id, action_type, action_bucket, actor_type, actor_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, 'user', 'agent', ?)`,
+		id, pgTestOrgID, entityType, entityID, actionType, at.Truncate(24*time.Hour), at)
+}
+
+// assignment seeds an assignment of the entity to assignedTo with the
+// given status, expiring a day after it was made.
+func (s pgSeed) assignment(id snowflake.ID, entityType string, entityID snowflake.ID, assignedTo, status string, at time.Time) {
+	s.t.Helper()
+	s.exec(`INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, pgTestOrgID, entityType, entityID, assignedTo, at, at.Add(24*time.Hour), status)
+}
+
+// auditLog seeds an audit log entry on the target with metadata.
+func (s pgSeed) auditLog(id snowflake.ID, action, targetType, targetID string, metadata map[string]any, at time.Time) {
+	s.t.Helper()
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		s.t.Fatalf("metadata: %v", err)
+	}
+	s.exec(`INSERT INTO audit_logs (id, org_id, actor_type, actor_id, action, target_type, target_id, metadata, created_at)
+		VALUES (?, ?, 'user', 'agent', ?, ?, ?, ?, ?)`,
+		id, pgTestOrgID, action, targetType, targetID, string(encoded), at)
+}
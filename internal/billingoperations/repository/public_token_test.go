@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"gorm.io/gorm"
+)
+
+func TestIssuePublicToken_KeepsActiveToken(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	for _, stmt := range []string{
+		`CREATE TABLE invoice_public_tokens (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			invoice_id BIGINT NOT NULL,
+			token_hash TEXT NOT NULL,
+			expires_at TIMESTAMP,
+			revoked_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX ux_invoice_public_tokens_active ON invoice_public_tokens (invoice_id) WHERE revoked_at IS NULL`,
+		`CREATE TABLE invoices (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			status TEXT NOT NULL,
+			voided_at TIMESTAMP,
+			paid_at TIMESTAMP
+		)`,
+		`INSERT INTO invoices (id, org_id, status, paid_at) VALUES
+			(7, 1, 'FINALIZED', NULL),
+			(8, 1, 'FINALIZED', '2025-05-01 00:00:00'),
+			(9, 1, 'DRAFT', NULL)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+
+	repo := NewRepository(db)
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	issued, err := repo.IssuePublicToken(ctx, 1, 7, 100, "first", now)
+	if err != nil || !issued {
+		t.Fatalf("expected the first token to be issued, got %v, %v", issued, err)
+	}
+	issued, err = repo.IssuePublicToken(ctx, 1, 7, 101, "second", now)
+	if err != nil || issued {
+		t.Fatalf("expected the active token to be kept, got %v, %v", issued, err)
+	}
+
+	if err := repo.ReissuePublicToken(ctx, 1, 7, 102, "reissued", now); err != nil {
+		t.Fatalf("reissue: %v", err)
+	}
+	var active []string
+	if err := db.Raw(`SELECT token_hash FROM invoice_public_tokens WHERE invoice_id = 7 AND revoked_at IS NULL`).Scan(&active).Error; err != nil {
+		t.Fatalf("load tokens: %v", err)
+	}
+	if len(active) != 1 || active[0] != "reissued" {
+		t.Fatalf("expected only the reissued token to be active, got %v", active)
+	}
+
+	for _, invoiceID := range []int64{8, 9, 10} {
+		err := repo.ReissuePublicToken(ctx, 1, snowflake.ID(invoiceID), 103, "unpayable", now)
+		if !errors.Is(err, billingopsdomain.ErrEntityNotFound) {
+			t.Fatalf("invoice %d: expected entity not found, got %v", invoiceID, err)
+		}
+	}
+	if err := repo.ReissuePublicToken(ctx, 2, 7, 104, "other org", now); !errors.Is(err, billingopsdomain.ErrEntityNotFound) {
+		t.Fatalf("expected another org's invoice to be rejected, got %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
 	"time"
 
 	"strings"
@@ -9,7 +12,10 @@ import (
 	"github.com/bwmarrin/snowflake"
 	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
 	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	disputedomain "github.com/smallbiznis/railzway/internal/payment/dispute/domain"
 	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+	dbpkg "github.com/smallbiznis/railzway/pkg/db"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -17,10 +23,14 @@ import (
 type RepositoryImpl struct {
 	db *gorm.DB
 	// FinOps repo can be embedded or composed
-	finOpsRepo *FinOpsSnapshotRepository
+	finOpsRepo    *FinOpsSnapshotRepository
+	dueDatePolicy billingopsdomain.DueDatePolicy
 }
 
+// NewRepository returns a repository whose queries are subject to the tenant
+// scope check (see dbpkg.EnforceTenantScope).
 func NewRepository(db *gorm.DB) billingopsdomain.Repository {
+	db = dbpkg.EnforceTenantScope(db)
 	return &RepositoryImpl{
 		db:         db,
 		finOpsRepo: NewFinOpsSnapshotRepository(db),
@@ -28,12 +38,34 @@ func NewRepository(db *gorm.DB) billingopsdomain.Repository {
 }
 
 func (r *RepositoryImpl) WithTx(tx *gorm.DB) billingopsdomain.Repository {
+	tx = dbpkg.EnforceTenantScope(tx)
 	return &RepositoryImpl{
-		db:         tx,
-		finOpsRepo: NewFinOpsSnapshotRepository(tx),
+		db:            tx,
+		finOpsRepo:    NewFinOpsSnapshotRepository(tx),
+		dueDatePolicy: r.dueDatePolicy,
 	}
 }
 
+func (r *RepositoryImpl) WithDueDatePolicy(policy billingopsdomain.DueDatePolicy) billingopsdomain.Repository {
+	clone := *r
+	clone.dueDatePolicy = policy
+	return &clone
+}
+
+// effectiveDueAt returns the SQL expression for the due date of the invoices
+// row aliased as alias, falling back to issuance plus the policy's net terms
+// when due_at is NULL and the policy allows it.
+func (r *RepositoryImpl) effectiveDueAt(alias string) string {
+	if r.dueDatePolicy.NetTermsDays == nil {
+		return alias + ".due_at"
+	}
+	return fmt.Sprintf(
+		"COALESCE(%[1]s.due_at, COALESCE(%[1]s.issued_at, %[1]s.created_at) + interval '%[2]d days')",
+		alias,
+		*r.dueDatePolicy.NetTermsDays,
+	)
+}
+
 func (r *RepositoryImpl) FetchOrgCurrency(ctx context.Context, orgID snowflake.ID) (string, error) {
 	var row struct {
 		Currency string `gorm:"column:currency"`
@@ -51,28 +83,521 @@ func (r *RepositoryImpl) FetchOrgCurrency(ctx context.Context, orgID snowflake.I
 	return currency, nil
 }
 
-func (r *RepositoryImpl) ListOverdueInvoices(
-	ctx context.Context,
-	orgID snowflake.ID,
-	currency string,
-	now time.Time,
-	limit int,
-) ([]billingopsdomain.OverdueInvoiceRow, error) {
-	var rows []billingopsdomain.OverdueInvoiceRow
-	query := `
-		WITH settled AS (
+func (r *RepositoryImpl) FetchMemberDisplayName(ctx context.Context, orgID, userID snowflake.ID) (string, error) {
+	var row struct {
+		DisplayName string `gorm:"column:display_name"`
+	}
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT COALESCE(u.display_name, '') AS display_name
+		 FROM users u
+		 JOIN organization_members om ON om.user_id = u.id
+		 WHERE om.org_id = ? AND u.id = ?
+		 LIMIT 1`,
+		orgID, userID,
+	).Scan(&row).Error; err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(row.DisplayName), nil
+}
+
+func (r *RepositoryImpl) ListOrgMemberIDs(ctx context.Context, orgID snowflake.ID, userIDs []snowflake.ID) ([]snowflake.ID, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var ids []snowflake.ID
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT user_id FROM organization_members WHERE org_id = ? AND user_id IN ?`,
+		orgID, userIDs,
+	).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// receivableAccountCodes returns the ledger account codes that make up the
+// org's accounts receivable, defaulting to the single accounts_receivable
+// account. Every settled and outstanding calculation resolves its codes here
+// so payments posted to any AR sub-account reduce the same balances.
+func (r *RepositoryImpl) receivableAccountCodes(ctx context.Context, orgID snowflake.ID) ([]string, error) {
+	var row struct {
+		Codes datatypes.JSON `gorm:"column:receivable_account_codes"`
+	}
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT receivable_account_codes FROM organization_billing_preferences WHERE org_id = ? LIMIT 1`,
+		orgID,
+	).Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	var codes []string
+	if len(row.Codes) > 0 {
+		if err := json.Unmarshal(row.Codes, &codes); err != nil {
+			return nil, err
+		}
+	}
+	// Payments and credit notes post to accounts_receivable, so it counts
+	// even when a stored list predates it being required.
+	defaultCode := string(ledgerdomain.AccountCodeAccountsReceivable)
+	if !slices.Contains(codes, defaultCode) {
+		codes = append([]string{defaultCode}, codes...)
+	}
+	return codes, nil
+}
+
+// settledAmountQuery nets the receivable lines posted for payments and credit
+// notes against the invoice they reference, either directly or through a
+// payment allocation, per currency. %s takes extra predicates on the ledger
+// entries.
+const settledAmountQuery = `
 			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
+				COALESCE(pe.payload #>> '{data,object,metadata,invoice_id}', pa.invoice_id::text, cn.invoice_id::text) AS invoice_id_text,
+				le.currency AS currency,
 				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
 			FROM ledger_entries le
 			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
+			JOIN ledger_accounts a ON a.id = l.account_id AND a.org_id = le.org_id
+			LEFT JOIN payment_events pe ON pe.id = le.source_id AND pe.org_id = le.org_id
+			LEFT JOIN payment_allocations pa ON pa.payment_event_id = pe.id
+			LEFT JOIN credit_notes cn ON cn.id = le.source_id AND cn.org_id = le.org_id
 			WHERE le.org_id = ?
-			  AND le.currency = ?
-			  AND le.source_type = ?
-			  AND a.code = ?
-			GROUP BY 1
+			  AND le.source_type IN (?, ?)
+			  AND a.code IN ?%s
+			GROUP BY 1, 2`
+
+// settledAmountCTE returns the settled amount per invoice for an org, as a
+// query to use in WITH settled AS (...) or as a derived table joined on
+// invoice_id_text and currency, along with its bind variables in order.
+// Every outstanding balance is computed against it so the inbox, queues,
+// snapshots and exposure reports agree.
+//
+// Reports in the org's currency pass it to keep only that currency's
+// entries. Collections lists pass "" to cover every currency, since an
+// invoice is billed in its subscription's currency; they join settled
+// amounts on the invoice's currency too, so settlements posted in another
+// currency never net against it.
+func settledAmountCTE(orgID snowflake.ID, currency string, arCodes []string) (string, []any) {
+	return settledAmountWhere(orgID, currency, arCodes, "")
+}
+
+// SettledAmountCTE is settledAmountCTE for the org's receivable accounts,
+// for queries outside billing operations that net invoices against what has
+// been settled, so they agree with the inbox and queues.
+func SettledAmountCTE(ctx context.Context, db *gorm.DB, orgID snowflake.ID, currency string) (string, []any, error) {
+	arCodes, err := (&RepositoryImpl{db: dbpkg.EnforceTenantScope(db)}).receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return "", nil, err
+	}
+	query, args := settledAmountCTE(orgID, currency, arCodes)
+	return query, args, nil
+}
+
+// settledAmountWhere builds settledAmountQuery with the currency predicate,
+// when there is one, ahead of extra.
+func settledAmountWhere(orgID snowflake.ID, currency string, arCodes []string, extra string) (string, []any) {
+	args := []any{
+		orgID,
+		string(ledgerdomain.SourceTypePayment), string(ledgerdomain.SourceTypeCreditNote),
+		arCodes,
+	}
+	if currency != "" {
+		extra = "\n\t\t\t  AND le.currency = ?" + extra
+		args = append(args, currency)
+	}
+	return fmt.Sprintf(settledAmountQuery, extra), args
+}
+
+// disputedAmountQuery sums the disputed line amounts per invoice.
+const disputedAmountQuery = `
+			SELECT invoice_id, SUM(amount) AS disputed_amount
+			FROM invoice_line_disputes
+			WHERE org_id = ?
+			GROUP BY invoice_id`
+
+// disputedAmountCTE returns the disputed amount per invoice for an org, as a
+// derived table joined on invoice_id, along with its bind variables.
+// Disputed amounts stay in the outstanding balance; they only take the
+// pressure off collections, so callers cap them at the outstanding amount
+// and subtract them where urgency is scored.
+func disputedAmountCTE(orgID snowflake.ID) (string, []any) {
+	return disputedAmountQuery, []any{orgID}
+}
+
+// settledAmountBeforeCTE is settledAmountCTE restricted to ledger entries
+// that occurred before cutoff.
+func settledAmountBeforeCTE(orgID snowflake.ID, currency string, arCodes []string, cutoff time.Time) (string, []any) {
+	query, args := settledAmountWhere(orgID, currency, arCodes, "\n\t\t\t  AND le.occurred_at < ?")
+	return query, append(args, cutoff)
+}
+
+func (r *RepositoryImpl) FetchListDefaults(ctx context.Context, orgID snowflake.ID) (billingopsdomain.ListDefaults, error) {
+	var row billingopsdomain.ListDefaults
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT
+			COALESCE(inbox_default_limit, 0) AS inbox_default_limit,
+			COALESCE(my_work_default_limit, 0) AS my_work_default_limit,
+			COALESCE(overdue_default_limit, 0) AS overdue_default_limit,
+			COALESCE(collection_default_limit, 0) AS collection_default_limit
+		FROM organization_billing_preferences
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&row).Error; err != nil {
+		return billingopsdomain.ListDefaults{}, err
+	}
+	return row, nil
+}
+
+func (r *RepositoryImpl) FetchRequireHandoffNote(ctx context.Context, orgID snowflake.ID) (bool, error) {
+	var required bool
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT COALESCE(require_handoff_note, false)
+		FROM organization_billing_preferences
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&required).Error; err != nil {
+		return false, err
+	}
+	return required, nil
+}
+
+func (r *RepositoryImpl) FetchReleaseOnResolve(ctx context.Context, orgID snowflake.ID) (bool, error) {
+	var release bool
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT COALESCE(release_on_resolve, false)
+		FROM organization_billing_preferences
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&release).Error; err != nil {
+		return false, err
+	}
+	return release, nil
+}
+
+// resolvedIsTerminal reports whether the org has set release_on_resolve to
+// false. An org that never set it keeps listing resolved entities in the
+// inbox, as before the preference existed.
+func (r *RepositoryImpl) resolvedIsTerminal(ctx context.Context, orgID snowflake.ID) (bool, error) {
+	var terminal bool
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT COALESCE(release_on_resolve = false, false)
+		FROM organization_billing_preferences
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&terminal).Error; err != nil {
+		return false, err
+	}
+	return terminal, nil
+}
+
+func (r *RepositoryImpl) FetchCollectionQueueFilter(ctx context.Context, orgID snowflake.ID) (billingopsdomain.CollectionQueueFilter, error) {
+	var filter billingopsdomain.CollectionQueueFilter
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT
+			COALESCE(collection_queue_overdue_only, false) AS overdue_only,
+			COALESCE(collection_queue_max_age_days, 0) AS max_age_days
+		FROM organization_billing_preferences
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&filter).Error; err != nil {
+		return billingopsdomain.CollectionQueueFilter{}, err
+	}
+	return filter, nil
+}
+
+func (r *RepositoryImpl) FetchTeamViewsIncludeSystemActors(ctx context.Context, orgID snowflake.ID) (bool, error) {
+	var include bool
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT COALESCE(team_views_include_system_actors, false)
+		FROM organization_billing_preferences
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&include).Error; err != nil {
+		return false, err
+	}
+	return include, nil
+}
+
+func (r *RepositoryImpl) FetchAuditReads(ctx context.Context, orgID snowflake.ID) (bool, error) {
+	var enabled bool
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT COALESCE(audit_reads, false)
+		FROM organization_billing_preferences
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&enabled).Error; err != nil {
+		return false, err
+	}
+	return enabled, nil
+}
+
+func (r *RepositoryImpl) FetchOrgLocation(ctx context.Context, orgID snowflake.ID) (*time.Location, error) {
+	var row struct {
+		Timezone string `gorm:"column:timezone"`
+	}
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT timezone FROM organization_billing_preferences WHERE org_id = ? LIMIT 1`,
+		orgID,
+	).Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	timezone := strings.TrimSpace(row.Timezone)
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, billingopsdomain.ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+func (r *RepositoryImpl) FetchOverdueCalendar(ctx context.Context, orgID snowflake.ID) (billingopsdomain.OverdueCalendar, error) {
+	var row struct {
+		BusinessDays bool           `gorm:"column:overdue_business_days"`
+		Holidays     datatypes.JSON `gorm:"column:overdue_holidays"`
+	}
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT overdue_business_days, overdue_holidays
+		FROM organization_billing_preferences
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&row).Error; err != nil {
+		return billingopsdomain.OverdueCalendar{}, err
+	}
+
+	var holidays []string
+	if len(row.Holidays) > 0 {
+		if err := json.Unmarshal(row.Holidays, &holidays); err != nil {
+			return billingopsdomain.OverdueCalendar{}, err
+		}
+	}
+	calendar := billingopsdomain.NewOverdueCalendar(row.BusinessDays, holidays)
+
+	var workRow struct {
+		Timezone string         `gorm:"column:timezone"`
+		WorkDays datatypes.JSON `gorm:"column:work_days"`
+		Holidays datatypes.JSON `gorm:"column:holidays"`
+	}
+	res := r.db.WithContext(ctx).Raw(
+		`SELECT timezone, work_days, holidays
+		FROM org_calendars
+		WHERE org_id = ?
+		LIMIT 1`,
+		orgID,
+	).Scan(&workRow)
+	if res.Error != nil {
+		return billingopsdomain.OverdueCalendar{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return calendar, nil
+	}
+
+	var (
+		workDays     []int
+		workHolidays []string
+	)
+	if len(workRow.WorkDays) > 0 {
+		if err := json.Unmarshal(workRow.WorkDays, &workDays); err != nil {
+			return billingopsdomain.OverdueCalendar{}, err
+		}
+	}
+	if len(workRow.Holidays) > 0 {
+		if err := json.Unmarshal(workRow.Holidays, &workHolidays); err != nil {
+			return billingopsdomain.OverdueCalendar{}, err
+		}
+	}
+	loc, err := time.LoadLocation(workRow.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return calendar.WithWorkCalendar(loc, workDays, workHolidays), nil
+}
+
+// overdueDaysSQL returns a SQL expression for the days from dueAt to now,
+// counted the way calendar counts them, and its bind variables. Calendar
+// days are fractional and negative before the due date. Business days are
+// whole and never negative, and match OverdueCalendar.DaysOverdue: each
+// elapsed day counts when it ends on a work day, in the calendar's time
+// zone, that is not a holiday.
+func overdueDaysSQL(calendar billingopsdomain.OverdueCalendar, dueAt string, now time.Time) (string, []any) {
+	elapsed := fmt.Sprintf("EXTRACT(EPOCH FROM (? - %s)) / 86400", dueAt)
+	if !calendar.BusinessDaysOnly {
+		return elapsed, []any{now}
+	}
+
+	loc := "UTC"
+	if calendar.Location != nil {
+		loc = calendar.Location.String()
+	}
+	workDays := []int{1, 2, 3, 4, 5}
+	if calendar.WorkDays != nil {
+		workDays = make([]int, 0, len(calendar.WorkDays))
+		for day := range calendar.WorkDays {
+			workDays = append(workDays, int(day))
+		}
+		slices.Sort(workDays)
+	}
+
+	day := fmt.Sprintf("((%s) AT TIME ZONE ?) + g.n * INTERVAL '1 day'", dueAt)
+	expr := fmt.Sprintf(
+		"(SELECT COUNT(*) FROM generate_series(1, FLOOR(%s)::int) AS g(n) WHERE EXTRACT(DOW FROM %s)::int IN ?",
+		elapsed, day,
+	)
+	args := []any{now, loc, workDays}
+	if len(calendar.Holidays) > 0 {
+		holidays := make([]string, 0, len(calendar.Holidays))
+		for holiday := range calendar.Holidays {
+			holidays = append(holidays, holiday)
+		}
+		slices.Sort(holidays)
+		expr += fmt.Sprintf(" AND to_char(%s, 'YYYY-MM-DD') NOT IN ?", day)
+		args = append(args, loc, holidays)
+	}
+	return expr + ")", args
+}
+
+func (r *RepositoryImpl) ReissuePublicToken(
+	ctx context.Context,
+	orgID, invoiceID, tokenID snowflake.ID,
+	tokenHash string,
+	now time.Time,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payable int64
+		if err := tx.Raw(
+			`SELECT COUNT(*) FROM invoices
+			 WHERE org_id = ? AND id = ?
+			   AND status = 'FINALIZED'
+			   AND voided_at IS NULL
+			   AND paid_at IS NULL`,
+			orgID, invoiceID,
+		).Scan(&payable).Error; err != nil {
+			return err
+		}
+		if payable == 0 {
+			return billingopsdomain.ErrEntityNotFound
+		}
+		if err := tx.Exec(
+			`UPDATE invoice_public_tokens
+			 SET revoked_at = ?
+			 WHERE org_id = ? AND invoice_id = ? AND revoked_at IS NULL`,
+			now, orgID, invoiceID,
+		).Error; err != nil {
+			return err
+		}
+		return tx.Exec(
+			`INSERT INTO invoice_public_tokens (id, org_id, invoice_id, token_hash, created_at)
+			 VALUES (?, ?, ?, ?, ?)`,
+			tokenID, orgID, invoiceID, tokenHash, now,
+		).Error
+	})
+}
+
+func (r *RepositoryImpl) IssuePublicToken(
+	ctx context.Context,
+	orgID, invoiceID, tokenID snowflake.ID,
+	tokenHash string,
+	now time.Time,
+) (bool, error) {
+	// The partial unique index on active tokens turns a concurrent issue for
+	// the same invoice into a no-op instead of a second link.
+	res := r.db.WithContext(ctx).Exec(
+		`INSERT INTO invoice_public_tokens (id, org_id, invoice_id, token_hash, created_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT DO NOTHING`,
+		tokenID, orgID, invoiceID, tokenHash, now,
+	)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *RepositoryImpl) ListMissingPublicTokens(
+	ctx context.Context,
+	orgID snowflake.ID,
+	page pagination.Pagination,
+) ([]billingopsdomain.MissingPublicTokenRow, pagination.PageInfo, error) {
+	var rows []billingopsdomain.MissingPublicTokenRow
+	query, args, err := pagination.Paginate(`
+		SELECT
+			i.id AS invoice_id,
+			COALESCE(i.invoice_number::text, '') AS invoice_number,
+			c.id AS customer_id,
+			c.name AS customer_name,
+			i.subtotal_amount AS subtotal_amount,
+			i.currency AS currency,
+			i.due_at AS due_at,
+			i.finalized_at AS finalized_at
+		FROM invoices i
+		JOIN customers c ON c.id = i.customer_id AND c.org_id = i.org_id
+		WHERE i.org_id = ?
+		  AND i.status = 'FINALIZED'
+		  AND i.voided_at IS NULL
+		  AND i.paid_at IS NULL
+		  AND c.deleted_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM invoice_public_tokens ipt
+			WHERE ipt.invoice_id = i.id AND ipt.org_id = i.org_id AND ipt.revoked_at IS NULL
+		  )`, []any{orgID}, missingPublicTokensKeyset, page)
+	if err != nil {
+		return nil, pagination.PageInfo{}, billingopsdomain.ErrInvalidPageToken
+	}
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
+	rows, pageInfo := pagination.NextPage(rows, page, func(row billingopsdomain.MissingPublicTokenRow) []any {
+		return []any{row.InvoiceID}
+	})
+	return rows, pageInfo, nil
+}
+
+// Keysets of the paginated collections lists. Each ends in the columns that
+// identify a row, so rows with equal amounts or due dates keep a stable order
+// across pages. Amounts are minor units of the row's currency, so lists that
+// rank by amount group rows by currency first and only rank within one.
+var (
+	overdueInvoicesKeyset = pagination.Keyset{
+		{Column: "due_at"},
+		{Column: "invoice_id"},
+	}
+	outstandingCustomersKeyset = pagination.Keyset{
+		{Column: "currency"},
+		{Column: "outstanding", Desc: true},
+		{Column: "pending", Desc: true},
+		{Column: "customer_id"},
+	}
+	collectionQueueKeyset = pagination.Keyset{
+		{Column: "queue_priority", Desc: true},
+		{Column: "currency"},
+		{Column: "outstanding", Desc: true},
+		{Column: "customer_id"},
+	}
+	missingPublicTokensKeyset = pagination.Keyset{
+		{Column: "invoice_id"},
+	}
+)
+
+func (r *RepositoryImpl) ListOverdueInvoices(
+	ctx context.Context,
+	orgID snowflake.ID,
+	now time.Time,
+	page pagination.Pagination,
+) ([]billingopsdomain.OverdueInvoiceRow, pagination.PageInfo, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
+	var rows []billingopsdomain.OverdueInvoiceRow
+	settled, settledArgs := settledAmountCTE(orgID, "", arCodes)
+	query := fmt.Sprintf(`
+		WITH settled AS (%[2]s
 		)
 		SELECT
 			i.id AS invoice_id,
@@ -80,7 +605,8 @@ func (r *RepositoryImpl) ListOverdueInvoices(
 			c.id AS customer_id,
 			c.name AS customer_name,
 			GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS amount_due,
-			i.due_at AS due_at,
+			i.currency AS currency,
+			%[1]s AS due_at,
 			boa.assigned_to AS assigned_to,
 			boa.assigned_at AS assigned_at,
 			boa.assignment_expires_at AS assignment_expires_at,
@@ -91,9 +617,9 @@ func (r *RepositoryImpl) ListOverdueInvoices(
 			boa.last_action_at AS assignment_last_action_at,
 			ipt.token_hash AS token_hash
 		FROM invoices i
-		JOIN customers c ON c.id = i.customer_id
-		LEFT JOIN settled s ON s.invoice_id_text = i.id::text
-		LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = i.id AND ipt.revoked_at IS NULL
+		JOIN customers c ON c.id = i.customer_id AND c.org_id = i.org_id
+		LEFT JOIN settled s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+		LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = i.id AND ipt.org_id = i.org_id AND ipt.revoked_at IS NULL
 		LEFT JOIN billing_operation_assignments boa
 			ON boa.org_id = ?
 			AND boa.entity_type = ?
@@ -103,80 +629,86 @@ func (r *RepositoryImpl) ListOverdueInvoices(
 		  AND i.status = 'FINALIZED'
 		  AND i.voided_at IS NULL
 		  AND i.paid_at IS NULL
-		  AND i.currency = ?
-		  AND i.due_at IS NOT NULL
-		  AND i.due_at < ?
-		  AND GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) > 0
-		ORDER BY i.due_at ASC
-		LIMIT ?`
+		  AND c.deleted_at IS NULL
+		  AND %[1]s IS NOT NULL
+		  AND %[1]s < ?
+		  AND GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) > 0`, r.effectiveDueAt("i"), settled)
 
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		orgID,
-		currency,
-		string(ledgerdomain.SourceTypePayment),
-		string(ledgerdomain.AccountCodeAccountsReceivable),
+	args := append(settledArgs,
 		orgID,
 		billingopsdomain.EntityTypeInvoice,
 		orgID,
-		currency,
 		now,
-		limit,
-	).Scan(&rows).Error; err != nil {
-		return nil, err
+	)
+	query, args, err = pagination.Paginate(query, args, overdueInvoicesKeyset, page)
+	if err != nil {
+		return nil, pagination.PageInfo{}, billingopsdomain.ErrInvalidPageToken
 	}
-	return rows, nil
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
+	rows, pageInfo := pagination.NextPage(rows, page, func(row billingopsdomain.OverdueInvoiceRow) []any {
+		return []any{row.DueAt, row.InvoiceID}
+	})
+	return rows, pageInfo, nil
 }
 
 func (r *RepositoryImpl) ListOutstandingCustomers(
 	ctx context.Context,
 	orgID snowflake.ID,
-	currency string,
 	now time.Time,
-	limit int,
-) ([]billingopsdomain.OutstandingCustomerRow, error) {
+	includeDrafts bool,
+	page pagination.Pagination,
+) ([]billingopsdomain.OutstandingCustomerRow, pagination.PageInfo, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
 	var rows []billingopsdomain.OutstandingCustomerRow
-	query := `
-		WITH settled AS (
-			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-			FROM ledger_entries le
-			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
-			WHERE le.org_id = ?
-			  AND le.currency = ?
-			  AND le.source_type = ?
-			  AND a.code = ?
-			GROUP BY 1
+	settled, settledArgs := settledAmountCTE(orgID, "", arCodes)
+	// A customer billed in several currencies has one row per currency.
+	query := fmt.Sprintf(`
+		WITH settled AS (%[2]s
 		), invoice_outstanding AS (
 			SELECT
 				i.id AS invoice_id,
 				i.customer_id,
+				i.currency,
 				COALESCE(i.invoice_number::text, '') AS invoice_number,
-				i.due_at,
+				%[1]s AS due_at,
 				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding
 			FROM invoices i
-			LEFT JOIN settled s ON s.invoice_id_text = i.id::text
+			LEFT JOIN settled s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
 			WHERE i.org_id = ?
 			  AND i.status = 'FINALIZED'
 			  AND i.voided_at IS NULL
-			  AND i.currency = ?
 		), totals AS (
-			SELECT customer_id, SUM(outstanding) AS outstanding
+			SELECT customer_id, currency, SUM(outstanding) AS outstanding
 			FROM invoice_outstanding
 			WHERE outstanding > 0
-			GROUP BY customer_id
+			GROUP BY customer_id, currency
+		), pending AS (
+			SELECT customer_id, currency, SUM(subtotal_amount) AS pending, COUNT(*) AS pending_invoices
+			FROM invoices
+			WHERE ?
+			  AND org_id = ?
+			  AND status = 'DRAFT'
+			  AND voided_at IS NULL
+			GROUP BY customer_id, currency
+		), overview AS (
+			SELECT customer_id, currency FROM totals
+			UNION
+			SELECT customer_id, currency FROM pending
 		), oldest_overdue AS (
-			SELECT DISTINCT ON (customer_id)
+			SELECT DISTINCT ON (customer_id, currency)
 				customer_id,
+				currency,
 				invoice_id,
 				invoice_number,
 				due_at
 			FROM invoice_outstanding
 			WHERE outstanding > 0 AND due_at IS NOT NULL AND due_at < ?
-			ORDER BY customer_id, due_at ASC, invoice_id ASC
+			ORDER BY customer_id, currency, due_at ASC, invoice_id ASC
 		), last_payment AS (
 			SELECT customer_id, MAX(received_at) AS last_payment_at
 			FROM payment_events
@@ -186,7 +718,10 @@ func (r *RepositoryImpl) ListOutstandingCustomers(
 		SELECT
 			c.id AS customer_id,
 			c.name AS customer_name,
-			t.outstanding AS outstanding,
+			o.currency AS currency,
+			COALESCE(t.outstanding, 0) AS outstanding,
+			COALESCE(p.pending, 0) AS pending,
+			COALESCE(p.pending_invoices, 0) AS pending_invoices,
 			oo.invoice_id::text AS oldest_overdue_invoice_id,
 			oo.invoice_number AS oldest_overdue_invoice_number,
 			oo.due_at AS oldest_overdue_at,
@@ -200,43 +735,90 @@ func (r *RepositoryImpl) ListOutstandingCustomers(
 			boa.released_by AS assignment_released_by,
 			boa.release_reason AS assignment_release_reason,
 			boa.last_action_at AS assignment_last_action_at
-		FROM totals t
-		JOIN customers c ON c.id = t.customer_id
-		LEFT JOIN oldest_overdue oo ON oo.customer_id = t.customer_id
-		LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = oo.invoice_id AND ipt.revoked_at IS NULL
-		LEFT JOIN last_payment lp ON lp.customer_id = t.customer_id
+		FROM overview o
+		JOIN customers c ON c.id = o.customer_id
+		LEFT JOIN totals t ON t.customer_id = o.customer_id AND t.currency = o.currency
+		LEFT JOIN pending p ON p.customer_id = o.customer_id AND p.currency = o.currency
+		LEFT JOIN oldest_overdue oo ON oo.customer_id = o.customer_id AND oo.currency = o.currency
+		LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = oo.invoice_id AND ipt.org_id = c.org_id AND ipt.revoked_at IS NULL
+		LEFT JOIN last_payment lp ON lp.customer_id = o.customer_id
 		LEFT JOIN billing_operation_assignments boa
 			ON boa.org_id = ?
 			AND boa.entity_type = ?
 			AND boa.entity_id = c.id
 			AND boa.status != 'released'
 		WHERE c.org_id = ?
-		ORDER BY t.outstanding DESC
-		LIMIT ?`
+		  AND c.deleted_at IS NULL`, r.effectiveDueAt("i"), settled)
 
-	if err := r.db.WithContext(ctx).Raw(
-		query,
+	args := append(settledArgs,
 		orgID,
-		currency,
-		string(ledgerdomain.SourceTypePayment),
-		string(ledgerdomain.AccountCodeAccountsReceivable),
+		includeDrafts,
 		orgID,
-		currency,
 		now,
 		orgID,
 		orgID,
 		billingopsdomain.EntityTypeCustomer,
 		orgID,
-		limit,
-	).Scan(&rows).Error; err != nil {
-		return nil, err
+	)
+	query, args, err = pagination.Paginate(query, args, outstandingCustomersKeyset, page)
+	if err != nil {
+		return nil, pagination.PageInfo{}, billingopsdomain.ErrInvalidPageToken
 	}
-	return rows, nil
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
+	rows, pageInfo := pagination.NextPage(rows, page, func(row billingopsdomain.OutstandingCustomerRow) []any {
+		return []any{row.Currency, row.Outstanding, row.Pending, row.CustomerID}
+	})
+	return rows, pageInfo, nil
+}
+
+// paymentIssueEventsQuery lists an org's events that can raise a payment
+// issue, with the invoice from the provider metadata when there is one.
+// Disputes are stored in payment_disputes, not payment_events, so every
+// dispute that is neither withdrawn nor closed is listed as a
+// dispute.created event.
+const paymentIssueEventsQuery = `
+			SELECT org_id, customer_id, event_type, received_at,
+				(payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text
+			FROM payment_events
+			WHERE org_id = ?
+			UNION ALL
+			SELECT org_id, customer_id, ? AS event_type, received_at, NULL AS invoice_id_text
+			FROM payment_disputes
+			WHERE org_id = ? AND status NOT IN ?`
+
+// paymentIssueEventsArgs are the bind variables of paymentIssueEventsQuery.
+func paymentIssueEventsArgs(orgID snowflake.ID) []any {
+	return []any{orgID, disputedomain.EventTypeDisputeCreated, orgID, []string{disputedomain.DisputeStatusWithdrawn, disputedomain.DisputeStatusClosed}}
+}
+
+// paymentIssueClearedFilter leaves out issue events of pe (a
+// paymentIssueEventsQuery) that a later successful payment from the same
+// customer for the same invoice resolved. Failures without an invoice are
+// only resolved by a success without one. An open dispute stays listed
+// until it is closed, whatever is paid after it.
+func paymentIssueClearedFilter(clearOnSuccess bool) (string, []any) {
+	if !clearOnSuccess {
+		return "", nil
+	}
+	return `
+		  AND (pe.event_type = ? OR NOT EXISTS (
+			SELECT 1 FROM payment_events ps
+			WHERE ps.org_id = pe.org_id
+			  AND ps.customer_id = pe.customer_id
+			  AND (ps.payload #>> '{data,object,metadata,invoice_id}') IS NOT DISTINCT FROM pe.invoice_id_text
+			  AND ps.event_type = ?
+			  AND ps.received_at > pe.received_at
+		  ))`, []any{disputedomain.EventTypeDisputeCreated, paymentdomain.EventTypePaymentSucceeded}
 }
 
-func (r *RepositoryImpl) ListPaymentIssues(ctx context.Context, orgID snowflake.ID, now time.Time, limit int) ([]billingopsdomain.PaymentIssueRow, error) {
+func (r *RepositoryImpl) ListPaymentIssues(ctx context.Context, orgID snowflake.ID, eventTypes []string, clearOnSuccess bool, now time.Time, limit int) ([]billingopsdomain.PaymentIssueRow, error) {
 	var rows []billingopsdomain.PaymentIssueRow
-	query := `
+	// Once every issue event of a type is resolved, the customer drops out
+	// of the list for that type.
+	clearedFilter, clearedArgs := paymentIssueClearedFilter(clearOnSuccess)
+	query := fmt.Sprintf(`
 		SELECT
 			pe.customer_id AS customer_id,
 			c.name AS customer_name,
@@ -250,53 +832,50 @@ func (r *RepositoryImpl) ListPaymentIssues(ctx context.Context, orgID snowflake.
 			boa.released_by AS assignment_released_by,
 			boa.release_reason AS assignment_release_reason,
 			boa.last_action_at AS assignment_last_action_at
-		FROM payment_events pe
-		JOIN customers c ON c.id = pe.customer_id
+		FROM (%s
+		) pe
+		JOIN customers c ON c.id = pe.customer_id AND c.org_id = pe.org_id
 		LEFT JOIN billing_operation_assignments boa
 			ON boa.org_id = ?
 			AND boa.entity_type = ?
 			AND boa.entity_id = pe.customer_id
 			AND boa.status != 'released'
 		WHERE pe.org_id = ?
-		  AND pe.event_type = ?
+		  AND pe.event_type IN ?
+		  AND c.deleted_at IS NULL%s
 		GROUP BY pe.customer_id, c.name, pe.event_type, boa.assigned_to, boa.assigned_at, boa.assignment_expires_at, boa.status, boa.released_at, boa.released_by, boa.release_reason, boa.last_action_at
 		ORDER BY last_attempt DESC
-		LIMIT ?`
+		LIMIT ?`, paymentIssueEventsQuery, clearedFilter)
 
-	if err := r.db.WithContext(ctx).Raw(
-		query,
+	args := append(paymentIssueEventsArgs(orgID),
 		orgID,
 		billingopsdomain.EntityTypeCustomer,
 		orgID,
-		paymentdomain.EventTypePaymentFailed,
-		limit,
-	).Scan(&rows).Error; err != nil {
+		eventTypes,
+	)
+	args = append(args, clearedArgs...)
+	args = append(args, limit)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
 }
 
-func (r *RepositoryImpl) LoadActionSummary(ctx context.Context, orgID snowflake.ID, currency string, now time.Time) (billingopsdomain.ActionSummaryRow, error) {
-	var row billingopsdomain.ActionSummaryRow
-	query := `
-		WITH settled AS (
-			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-			FROM ledger_entries le
-			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
-			WHERE le.org_id = ?
-			  AND le.currency = ?
-			  AND le.source_type = ?
-			  AND a.code = ?
-			GROUP BY 1
+func (r *RepositoryImpl) LoadActionSummary(ctx context.Context, orgID snowflake.ID, currency string, eventTypes []string, clearOnSuccess bool, now time.Time) (billingopsdomain.ActionSummaryRow, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return billingopsdomain.ActionSummaryRow{}, err
+	}
+	var row billingopsdomain.ActionSummaryRow
+	settled, settledArgs := settledAmountCTE(orgID, currency, arCodes)
+	clearedFilter, clearedArgs := paymentIssueClearedFilter(clearOnSuccess)
+	query := fmt.Sprintf(`
+		WITH settled AS (%[2]s
 		), invoice_outstanding AS (
 			SELECT
 				i.id AS invoice_id,
 				i.customer_id,
-				i.due_at,
+				%[1]s AS due_at,
 				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding
 			FROM invoices i
 			LEFT JOIN settled s ON s.invoice_id_text = i.id::text
@@ -313,21 +892,22 @@ func (r *RepositoryImpl) LoadActionSummary(ctx context.Context, orgID snowflake.
 		SELECT
 			COALESCE((SELECT COUNT(*) FROM totals), 0) AS customers_with_outstanding,
 			COALESCE((SELECT COUNT(*) FROM invoice_outstanding WHERE outstanding > 0 AND due_at IS NOT NULL AND due_at < ?), 0) AS overdue_invoices,
-			COALESCE((SELECT COUNT(*) FROM payment_events WHERE org_id = ? AND event_type = ?), 0) AS failed_payment_attempts,
-			COALESCE((SELECT SUM(outstanding) FROM totals), 0) AS total_outstanding`
+			COALESCE((SELECT COUNT(*) FROM (%[3]s
+			) pe WHERE org_id = ? AND event_type IN ?%[4]s), 0) AS failed_payment_attempts,
+			COALESCE((SELECT SUM(outstanding) FROM totals), 0) AS total_outstanding`, r.effectiveDueAt("i"), settled, paymentIssueEventsQuery, clearedFilter)
 
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		orgID,
-		currency,
-		string(ledgerdomain.SourceTypePayment),
-		string(ledgerdomain.AccountCodeAccountsReceivable),
+	args := append(settledArgs,
 		orgID,
 		currency,
 		now,
+	)
+	args = append(args, paymentIssueEventsArgs(orgID)...)
+	args = append(args,
 		orgID,
-		paymentdomain.EventTypePaymentFailed,
-	).Scan(&row).Error; err != nil {
+		eventTypes,
+	)
+	args = append(args, clearedArgs...)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&row).Error; err != nil {
 		return billingopsdomain.ActionSummaryRow{}, err
 	}
 	return row, nil
@@ -336,64 +916,80 @@ func (r *RepositoryImpl) LoadActionSummary(ctx context.Context, orgID snowflake.
 func (r *RepositoryImpl) ListCollectionQueue(
 	ctx context.Context,
 	orgID snowflake.ID,
-	currency string,
 	now time.Time,
-	limit int,
-) ([]billingopsdomain.CollectionQueueRow, error) {
+	filter billingopsdomain.CollectionQueueFilter,
+	page pagination.Pagination,
+) ([]billingopsdomain.CollectionQueueRow, pagination.PageInfo, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
 	var rows []billingopsdomain.CollectionQueueRow
-	query := `
-		WITH settled AS (
-			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-			FROM ledger_entries le
-			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
-			WHERE le.org_id = ?
-			  AND le.currency = ?
-			  AND le.source_type = ?
-			  AND a.code = ?
-			GROUP BY 1
+	var maxAgeCutoff *time.Time
+	if filter.MaxAgeDays > 0 {
+		cutoff := now.AddDate(0, 0, -filter.MaxAgeDays)
+		maxAgeCutoff = &cutoff
+	}
+	settled, settledArgs := settledAmountCTE(orgID, "", arCodes)
+	disputed, disputedArgs := disputedAmountCTE(orgID)
+	// A customer billed in several currencies is queued once per currency.
+	query := fmt.Sprintf(`
+		WITH settled AS (%[2]s
+		), disputed AS (%[3]s
 		), invoice_outstanding AS (
 			SELECT
 				i.id AS invoice_id,
 				i.customer_id,
+				i.currency,
 				COALESCE(i.invoice_number::text, '') AS invoice_number,
-				i.due_at,
+				%[1]s AS due_at,
 				COALESCE(i.issued_at, i.created_at) AS issued_at,
-				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding
+				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding,
+				COALESCE(d.disputed_amount, 0) AS disputed
 			FROM invoices i
-			LEFT JOIN settled s ON s.invoice_id_text = i.id::text
+			LEFT JOIN settled s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+			LEFT JOIN disputed d ON d.invoice_id = i.id
 			WHERE i.org_id = ?
 			  AND i.status = 'FINALIZED'
 			  AND i.voided_at IS NULL
-			  AND i.currency = ?
+			  AND (NOT ? OR (%[1]s IS NOT NULL AND %[1]s < ?))
+			  AND (?::timestamptz IS NULL OR COALESCE(%[1]s, i.issued_at, i.created_at) >= ?)
 		), totals AS (
-			SELECT customer_id, SUM(outstanding) AS outstanding
+			SELECT customer_id, currency, SUM(outstanding) AS outstanding, SUM(LEAST(disputed, outstanding)) AS disputed
 			FROM invoice_outstanding
 			WHERE outstanding > 0
-			GROUP BY customer_id
+			GROUP BY customer_id, currency
 		), oldest_unpaid AS (
-			SELECT DISTINCT ON (customer_id)
+			SELECT DISTINCT ON (customer_id, currency)
 				customer_id,
+				currency,
 				invoice_id,
 				invoice_number,
 				due_at,
 				issued_at
 			FROM invoice_outstanding
 			WHERE outstanding > 0
-			ORDER BY customer_id, COALESCE(due_at, issued_at) ASC, invoice_id ASC
+			ORDER BY customer_id, currency, COALESCE(due_at, issued_at) ASC, invoice_id ASC
 		), last_payment AS (
 			SELECT customer_id, MAX(received_at) AS last_payment_at
 			FROM payment_events
 			WHERE org_id = ? AND event_type = 'payment_succeeded'
 			GROUP BY customer_id
+		), last_email AS (
+			SELECT DISTINCT ON (customer_id)
+				customer_id,
+				status,
+				COALESCE(status_at, sent_at) AS status_at
+			FROM email_deliveries
+			WHERE org_id = ? AND customer_id IS NOT NULL
+			ORDER BY customer_id, sent_at DESC, id DESC
 		)
 		SELECT
 			c.id AS customer_id,
 			c.name AS customer_name,
+			t.currency AS currency,
 			t.outstanding AS outstanding,
+			t.disputed AS disputed_amount,
 			ou.invoice_id::text AS oldest_unpaid_invoice_id,
 			ou.invoice_number AS oldest_unpaid_invoice_number,
 			ou.due_at AS oldest_unpaid_at,
@@ -406,72 +1002,69 @@ func (r *RepositoryImpl) ListCollectionQueue(
 			boa.released_by AS assignment_released_by,
 			boa.release_reason AS assignment_release_reason,
 			boa.last_action_at AS assignment_last_action_at,
-			ipt.token_hash AS token_hash
+			ipt.token_hash AS token_hash,
+			le.status AS last_email_status,
+			le.status_at AS last_email_at,
+			CASE
+				WHEN ou.due_at IS NULL THEN 1
+				WHEN ou.due_at <= (?::timestamptz - interval '60 days') THEN 3
+				WHEN ou.due_at <= (?::timestamptz - interval '31 days') THEN 2
+				ELSE 1
+			END AS queue_priority
 		FROM totals t
 		JOIN customers c ON c.id = t.customer_id
-		LEFT JOIN oldest_unpaid ou ON ou.customer_id = t.customer_id
-		LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = ou.invoice_id AND ipt.revoked_at IS NULL
+		LEFT JOIN oldest_unpaid ou ON ou.customer_id = t.customer_id AND ou.currency = t.currency
+		LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = ou.invoice_id AND ipt.org_id = c.org_id AND ipt.revoked_at IS NULL
 		LEFT JOIN last_payment lp ON lp.customer_id = t.customer_id
+		LEFT JOIN last_email le ON le.customer_id = t.customer_id
 		LEFT JOIN billing_operation_assignments boa
 			ON boa.org_id = ?
 			AND boa.entity_type = ?
 			AND boa.entity_id = c.id
 			AND boa.status != 'released'
 		WHERE c.org_id = ?
-		ORDER BY
-			CASE
-				WHEN ou.due_at IS NULL THEN 1
-				WHEN ou.due_at <= (?::timestamptz - interval '60 days') THEN 3
-				WHEN ou.due_at <= (?::timestamptz - interval '31 days') THEN 2
-				ELSE 1
-			END DESC,
-			t.outstanding DESC,
-			c.id ASC
-		LIMIT ?`
+		  AND c.deleted_at IS NULL`, r.effectiveDueAt("i"), settled, disputed)
 
-	if err := r.db.WithContext(ctx).Raw(
-		query,
+	args := append(append(settledArgs, disputedArgs...),
 		orgID,
-		currency,
-		string(ledgerdomain.SourceTypePayment),
-		string(ledgerdomain.AccountCodeAccountsReceivable),
+		filter.OverdueOnly, now,
+		maxAgeCutoff, maxAgeCutoff,
 		orgID,
-		currency,
 		orgID,
+		now,
+		now,
 		orgID,
 		billingopsdomain.EntityTypeCustomer,
 		orgID,
-		now,
-		now,
-		limit,
-	).Scan(&rows).Error; err != nil {
-		return nil, err
+	)
+	query, args, err = pagination.Paginate(query, args, collectionQueueKeyset, page)
+	if err != nil {
+		return nil, pagination.PageInfo{}, billingopsdomain.ErrInvalidPageToken
 	}
-	return rows, nil
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, pagination.PageInfo{}, err
+	}
+	rows, pageInfo := pagination.NextPage(rows, page, func(row billingopsdomain.CollectionQueueRow) []any {
+		return []any{row.QueuePriority, row.Currency, row.Outstanding, row.CustomerID}
+	})
+	return rows, pageInfo, nil
 }
 
 func (r *RepositoryImpl) ListFailedPaymentActions(
 	ctx context.Context,
 	orgID snowflake.ID,
-	currency string,
+	eventTypes []string,
 	now time.Time,
 	limit int,
 ) ([]billingopsdomain.FailedPaymentActionRow, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
 	var rows []billingopsdomain.FailedPaymentActionRow
-	query := `
-		WITH settled AS (
-			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-			FROM ledger_entries le
-			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
-			WHERE le.org_id = ?
-			  AND le.currency = ?
-			  AND le.source_type = ?
-			  AND a.code = ?
-			GROUP BY 1
+	settled, settledArgs := settledAmountCTE(orgID, "", arCodes)
+	query := fmt.Sprintf(`
+		WITH settled AS (%[1]s
 		), failed AS (
 			SELECT
 				pe.customer_id AS customer_id,
@@ -479,9 +1072,10 @@ func (r *RepositoryImpl) ListFailedPaymentActions(
 				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
 				MAX(pe.received_at) AS last_attempt
 			FROM payment_events pe
-			JOIN customers c ON c.id = pe.customer_id
+			JOIN customers c ON c.id = pe.customer_id AND c.org_id = pe.org_id
 			WHERE pe.org_id = ?
-			  AND pe.event_type = ?
+			  AND pe.event_type IN ?
+			  AND c.deleted_at IS NULL
 			GROUP BY pe.customer_id, c.name, invoice_id_text
 		)
 		SELECT
@@ -490,6 +1084,7 @@ func (r *RepositoryImpl) ListFailedPaymentActions(
 			f.invoice_id_text AS invoice_id,
 			COALESCE(i.invoice_number::text, '') AS invoice_number,
 			GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS amount_due,
+			i.currency AS currency,
 			i.due_at AS due_at,
 			f.last_attempt AS last_attempt,
 			boa.assigned_to AS assigned_to,
@@ -507,9 +1102,8 @@ func (r *RepositoryImpl) ListFailedPaymentActions(
 			AND i.org_id = ?
 			AND i.status = 'FINALIZED'
 			AND i.voided_at IS NULL
-			AND i.currency = ?
-		LEFT JOIN settled s ON s.invoice_id_text = i.id::text
-		LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = i.id AND ipt.revoked_at IS NULL
+		LEFT JOIN settled s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+		LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = i.id AND ipt.org_id = i.org_id AND ipt.revoked_at IS NULL
 		LEFT JOIN billing_operation_assignments boa
 			ON boa.org_id = ?
 			AND boa.entity_type = ?
@@ -518,22 +1112,17 @@ func (r *RepositoryImpl) ListFailedPaymentActions(
 			AND boa.status != 'released'
 		WHERE (i.id IS NULL OR GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) > 0)
 		ORDER BY f.last_attempt DESC
-		LIMIT ?`
+		LIMIT ?`, settled)
 
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		orgID,
-		currency,
-		string(ledgerdomain.SourceTypePayment),
-		string(ledgerdomain.AccountCodeAccountsReceivable),
+	args := append(settledArgs,
 		orgID,
-		paymentdomain.EventTypePaymentFailed,
+		eventTypes,
 		orgID,
-		currency,
 		orgID,
 		billingopsdomain.EntityTypeCustomer,
 		limit,
-	).Scan(&rows).Error; err != nil {
+	)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -585,6 +1174,139 @@ func (r *RepositoryImpl) InsertBillingAction(ctx context.Context, record billing
 	return result.RowsAffected > 0, nil
 }
 
+func (r *RepositoryImpl) ArchiveActions(ctx context.Context, olderThan time.Time, limit int, archive bool, now time.Time) (int, error) {
+	// The retention sweep covers every org.
+	db := func() *gorm.DB { return dbpkg.SkipTenantScope(r.db.WithContext(ctx)) }
+
+	var ids []snowflake.ID
+	if err := db().Raw(
+		`SELECT a.id
+		 FROM billing_operation_actions a
+		 WHERE a.created_at < ?
+		   AND EXISTS (
+			SELECT 1 FROM billing_operation_actions n
+			WHERE n.org_id = a.org_id
+			  AND n.entity_type = a.entity_type
+			  AND n.entity_id = a.entity_id
+			  AND n.action_type = a.action_type
+			  AND (n.created_at > a.created_at OR (n.created_at = a.created_at AND n.id > a.id))
+		   )
+		 ORDER BY a.created_at ASC, a.id ASC
+		 LIMIT ?`,
+		olderThan,
+		limit,
+	).Scan(&ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if archive {
+		if err := db().Exec(
+			`INSERT INTO billing_operation_actions_archive (
+				id, org_id, entity_type, entity_id, action_type, action_bucket,
+				idempotency_key, metadata, actor_type, actor_id, assignment_id,
+				created_at, archived_at
+			)
+			SELECT id, org_id, entity_type, entity_id, action_type, action_bucket,
+				idempotency_key, metadata, actor_type, actor_id, assignment_id,
+				created_at, ?
+			FROM billing_operation_actions
+			WHERE id IN ?
+			ON CONFLICT (id) DO NOTHING`,
+			now,
+			ids,
+		).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	result := db().Exec(`DELETE FROM billing_operation_actions WHERE id IN ?`, ids)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (r *RepositoryImpl) InsertSnooze(ctx context.Context, record billingopsdomain.BillingSnoozeRecord) error {
+	if record.ID == 0 {
+		return billingopsdomain.ErrInvalidEntityID
+	}
+
+	var reason any
+	if trimmed := strings.TrimSpace(record.Reason); trimmed != "" {
+		reason = trimmed
+	}
+	return r.db.WithContext(ctx).Exec(
+		`INSERT INTO billing_operation_snoozes (
+			id, org_id, entity_type, entity_id, snoozed_until, reason, snoozed_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.ID,
+		record.OrgID,
+		record.EntityType,
+		record.EntityID,
+		record.SnoozedUntil,
+		reason,
+		strings.TrimSpace(record.SnoozedBy),
+		record.CreatedAt,
+	).Error
+}
+
+func (r *RepositoryImpl) FindInvoiceLine(ctx context.Context, orgID, invoiceID, lineID snowflake.ID) (*billingopsdomain.InvoiceLineRow, error) {
+	var row billingopsdomain.InvoiceLineRow
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT
+			ii.id AS invoice_item_id,
+			ii.invoice_id AS invoice_id,
+			ii.amount AS amount,
+			i.status AS invoice_status,
+			i.voided_at AS voided_at
+		FROM invoice_items ii
+		JOIN invoices i ON i.id = ii.invoice_id AND i.org_id = ii.org_id
+		WHERE ii.org_id = ? AND ii.invoice_id = ? AND ii.id = ?
+		LIMIT 1`,
+		orgID,
+		invoiceID,
+		lineID,
+	).Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	if row.InvoiceItemID == 0 {
+		return nil, nil
+	}
+	return &row, nil
+}
+
+func (r *RepositoryImpl) UpsertInvoiceLineDispute(ctx context.Context, record billingopsdomain.InvoiceLineDisputeRecord) (billingopsdomain.InvoiceLineDisputeRecord, error) {
+	if record.ID == 0 {
+		return billingopsdomain.InvoiceLineDisputeRecord{}, billingopsdomain.ErrInvalidEntityID
+	}
+
+	var stored billingopsdomain.InvoiceLineDisputeRecord
+	err := r.db.WithContext(ctx).Raw(
+		`INSERT INTO invoice_line_disputes (
+			id, org_id, invoice_id, invoice_item_id, amount, reason, disputed_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (org_id, invoice_item_id) DO UPDATE SET
+			amount = excluded.amount,
+			reason = excluded.reason,
+			disputed_by = excluded.disputed_by,
+			updated_at = excluded.updated_at
+		RETURNING id, org_id, invoice_id, invoice_item_id, amount, reason, disputed_by, created_at, updated_at`,
+		record.ID,
+		record.OrgID,
+		record.InvoiceID,
+		record.InvoiceItemID,
+		record.Amount,
+		strings.TrimSpace(record.Reason),
+		strings.TrimSpace(record.DisputedBy),
+		record.CreatedAt,
+		record.UpdatedAt,
+	).Scan(&stored).Error
+	return stored, err
+}
+
 func (r *RepositoryImpl) FindActionByIdempotencyKey(ctx context.Context, orgID snowflake.ID, key string) (*billingopsdomain.BillingActionLookup, error) {
 	var row billingopsdomain.BillingActionLookup
 	if err := r.db.WithContext(ctx).Raw(
@@ -624,7 +1346,8 @@ func (r *RepositoryImpl) LoadAssignment(ctx context.Context, orgID snowflake.ID,
 	var row billingopsdomain.AssignmentRow
 	if err := r.db.WithContext(ctx).Raw(
 		`SELECT assigned_to, assigned_at, assignment_expires_at,
-		        status, released_at, released_by, release_reason, last_action_at
+		        status, released_at, released_by, release_reason, last_action_at,
+		        updated_at
 		 FROM billing_operation_assignments
 		 WHERE org_id = ? AND entity_type = ? AND entity_id = ?
 		 LIMIT 1`,
@@ -634,7 +1357,7 @@ func (r *RepositoryImpl) LoadAssignment(ctx context.Context, orgID snowflake.ID,
 	).Scan(&row).Error; err != nil {
 		return nil, err
 	}
-	if row.AssignedTo == "" {
+	if !billingopsdomain.HasActiveAssignment(row.AssignedTo, row.Status) {
 		return nil, nil
 	}
 	return &row, nil
@@ -650,7 +1373,9 @@ func (r *RepositoryImpl) LoadAssignmentForUpdate(
 	query := `SELECT id, org_id, entity_type, entity_id,
 		        assigned_to, assigned_at, assignment_expires_at,
 		        status, released_at, released_by, release_reason, last_action_at,
-				snapshot_metadata, created_at, updated_at
+				snapshot_metadata, handed_off_at, handed_off_by, handoff_note,
+				resolved_at, resolved_by, resolution_snapshot,
+				created_at, updated_at
 		 FROM billing_operation_assignments
 		 WHERE org_id = ? AND entity_type = ? AND entity_id = ?`
 
@@ -663,7 +1388,8 @@ func (r *RepositoryImpl) LoadAssignmentForUpdate(
 	if err != nil {
 		return nil, err
 	}
-	if row.AssignedTo == "" {
+	// The released row stays locked, so a concurrent claim still waits.
+	if !billingopsdomain.HasActiveAssignment(row.AssignedTo, row.Status) {
 		return nil, nil
 	}
 	return &row, nil
@@ -671,7 +1397,8 @@ func (r *RepositoryImpl) LoadAssignmentForUpdate(
 
 func (r *RepositoryImpl) ListActiveAssignments(ctx context.Context) ([]billingopsdomain.BillingAssignmentRecord, error) {
 	var records []billingopsdomain.BillingAssignmentRecord
-	if err := r.db.WithContext(ctx).Where("status IN ? AND breached_at IS NULL",
+	// The SLA monitor sweeps every org's open assignments.
+	if err := dbpkg.SkipTenantScope(r.db.WithContext(ctx)).Where("status IN ? AND breached_at IS NULL",
 		[]string{billingopsdomain.AssignmentStatusAssigned, billingopsdomain.AssignmentStatusInProgress}).
 		Find(&records).Error; err != nil {
 		return nil, err
@@ -679,18 +1406,51 @@ func (r *RepositoryImpl) ListActiveAssignments(ctx context.Context) ([]billingop
 	return records, nil
 }
 
+func (r *RepositoryImpl) HasActionSince(
+	ctx context.Context,
+	orgID snowflake.ID,
+	entityType string,
+	entityID snowflake.ID,
+	actionTypes []string,
+	since time.Time,
+) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT COUNT(1) FROM billing_operation_actions
+		 WHERE org_id = ? AND entity_type = ? AND entity_id = ? AND action_type IN ? AND created_at >= ?`,
+		orgID,
+		entityType,
+		entityID,
+		actionTypes,
+		since,
+	).Scan(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (r *RepositoryImpl) UpsertAssignment(
 	ctx context.Context,
 	record billingopsdomain.BillingAssignmentRecord,
+	readAt *time.Time,
 ) error {
-	return r.db.WithContext(ctx).Exec(
+	// The conflict update only applies while the stored row is the one the
+	// caller read, so a racing writer cannot be silently overwritten.
+	guard := `(billing_operation_assignments.assigned_to = '' OR billing_operation_assignments.status = ?)`
+	var guardArg any = billingopsdomain.AssignmentStatusReleased
+	if readAt != nil {
+		guard = `billing_operation_assignments.updated_at = ?`
+		guardArg = *readAt
+	}
+	result := r.db.WithContext(ctx).Exec(
 		`INSERT INTO billing_operation_assignments (
 			id, org_id, entity_type, entity_id,
 			assigned_to, assigned_at, assignment_expires_at,
 			status, released_at, released_by, release_reason, last_action_at,
-			snapshot_metadata,
+			snapshot_metadata, handed_off_at, handed_off_by, handoff_note,
+			resolved_at, resolved_by, resolution_snapshot,
 			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT (org_id, entity_type, entity_id) DO UPDATE SET
 			assigned_to = EXCLUDED.assigned_to,
 			assigned_at = EXCLUDED.assigned_at,
@@ -701,7 +1461,14 @@ func (r *RepositoryImpl) UpsertAssignment(
 			release_reason = EXCLUDED.release_reason,
 			last_action_at = EXCLUDED.last_action_at,
 			snapshot_metadata = EXCLUDED.snapshot_metadata,
-			updated_at = EXCLUDED.updated_at`,
+			handed_off_at = EXCLUDED.handed_off_at,
+			handed_off_by = EXCLUDED.handed_off_by,
+			handoff_note = EXCLUDED.handoff_note,
+			resolved_at = EXCLUDED.resolved_at,
+			resolved_by = EXCLUDED.resolved_by,
+			resolution_snapshot = EXCLUDED.resolution_snapshot,
+			updated_at = EXCLUDED.updated_at
+		WHERE `+guard,
 		record.ID,
 		record.OrgID,
 		record.EntityType,
@@ -715,9 +1482,23 @@ func (r *RepositoryImpl) UpsertAssignment(
 		record.ReleaseReason,
 		record.LastActionAt,
 		record.SnapshotMetadata,
+		record.HandedOffAt,
+		record.HandedOffBy,
+		record.HandoffNote,
+		record.ResolvedAt,
+		record.ResolvedBy,
+		record.ResolutionSnapshot,
 		record.CreatedAt,
 		record.UpdatedAt,
-	).Error
+		guardArg,
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return billingopsdomain.ErrAssignmentModified
+	}
+	return nil
 }
 
 func (r *RepositoryImpl) UpdateAssignmentStatus(
@@ -726,16 +1507,66 @@ func (r *RepositoryImpl) UpdateAssignmentStatus(
 	entityType string,
 	entityID snowflake.ID,
 	oldStatus, newStatus string,
-	now time.Time,
+	readAt, now time.Time,
 ) error {
-	return r.db.WithContext(ctx).Exec(
+	result := r.db.WithContext(ctx).Exec(
 		`UPDATE billing_operation_assignments
 		 SET status = ?, last_action_at = ?, updated_at = ?
-		 WHERE org_id = ? AND entity_type = ? AND entity_id = ? 
-		   AND status = ?`,
+		 WHERE org_id = ? AND entity_type = ? AND entity_id = ?
+		   AND status = ? AND updated_at = ?`,
 		newStatus, now, now,
 		orgID, entityType, entityID,
-		oldStatus,
+		oldStatus, readAt,
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return billingopsdomain.ErrAssignmentModified
+	}
+	return nil
+}
+
+func (r *RepositoryImpl) ReassignAssignment(
+	ctx context.Context,
+	record billingopsdomain.BillingAssignmentRecord,
+	readAt time.Time,
+) error {
+	result := r.db.WithContext(ctx).Exec(
+		`UPDATE billing_operation_assignments
+		 SET assigned_to = ?, assigned_at = ?, assignment_expires_at = ?, status = ?,
+		     last_action_at = NULL, breached_at = NULL, breach_level = NULL,
+		     resolved_at = NULL, resolved_by = NULL,
+		     handed_off_at = ?, handed_off_by = ?, handoff_note = ?,
+		     updated_at = ?
+		 WHERE org_id = ? AND entity_type = ? AND entity_id = ? AND updated_at = ?`,
+		record.AssignedTo, record.AssignedAt, record.AssignmentExpiresAt, record.Status,
+		record.HandedOffAt, record.HandedOffBy, record.HandoffNote,
+		record.UpdatedAt,
+		record.OrgID, record.EntityType, record.EntityID, readAt,
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return billingopsdomain.ErrAssignmentModified
+	}
+	return nil
+}
+
+func (r *RepositoryImpl) PauseAssignmentSLA(
+	ctx context.Context,
+	orgID snowflake.ID,
+	entityType string,
+	entityID snowflake.ID,
+	until, now time.Time,
+) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE billing_operation_assignments
+		 SET sla_paused_until = ?, updated_at = ?
+		 WHERE org_id = ? AND entity_type = ? AND entity_id = ?`,
+		until, now,
+		orgID, entityType, entityID,
 	).Error
 }
 
@@ -754,7 +1585,7 @@ func (r *RepositoryImpl) EscalateAssignment(
 			"breached_at":  now,
 			"breach_level": breachType,
 			"resolved_at":  now,
-			"resolved_by":  "system",
+			"resolved_by":  billingopsdomain.SystemActorID,
 			"updated_at":   now,
 		}).Error
 }
@@ -767,8 +1598,12 @@ func (r *RepositoryImpl) FindSnapshotsByUserWithLimit(ctx context.Context, orgID
 	return r.finOpsRepo.FindByUserWithLimit(ctx, orgID, userID, periodType, start, end, limit)
 }
 
-func (r *RepositoryImpl) FindSnapshotsByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time) ([]billingopsdomain.FinOpsScoreSnapshot, error) {
-	return r.finOpsRepo.FindByOrg(ctx, orgID, periodType, start, end)
+func (r *RepositoryImpl) FindSnapshotsByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time, excludeUserIDs []string) ([]billingopsdomain.FinOpsScoreSnapshot, error) {
+	return r.finOpsRepo.FindByOrg(ctx, orgID, periodType, start, end, excludeUserIDs)
+}
+
+func (r *RepositoryImpl) StreamSnapshotsByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time, excludeUserIDs []string, fn func(billingopsdomain.FinOpsScoreSnapshot) error) error {
+	return r.finOpsRepo.StreamByOrg(ctx, orgID, periodType, start, end, excludeUserIDs, fn)
 }
 
 func (r *RepositoryImpl) LoadEntitySnapshot(
@@ -777,29 +1612,40 @@ func (r *RepositoryImpl) LoadEntitySnapshot(
 	entityType string,
 	entityID snowflake.ID,
 ) (map[string]any, error) {
-	now := time.Now().UTC()
-	switch entityType {
-	case billingopsdomain.EntityTypeInvoice:
-		return r.loadInvoiceSnapshot(ctx, orgID, entityID, now)
-	case billingopsdomain.EntityTypeCustomer:
-		return r.loadCustomerSnapshot(ctx, orgID, entityID, now)
-	default:
+	if entityType != billingopsdomain.EntityTypeInvoice && entityType != billingopsdomain.EntityTypeCustomer {
 		return nil, nil
 	}
+	now := time.Now().UTC()
+	calendar, err := r.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if entityType == billingopsdomain.EntityTypeInvoice {
+		return r.loadInvoiceSnapshot(ctx, orgID, entityID, calendar, now)
+	}
+	return r.loadCustomerSnapshot(ctx, orgID, entityID, calendar, now)
 }
 
-func (r *RepositoryImpl) loadInvoiceSnapshot(ctx context.Context, orgID, invoiceID snowflake.ID, now time.Time) (map[string]any, error) {
+func (r *RepositoryImpl) loadInvoiceSnapshot(ctx context.Context, orgID, invoiceID snowflake.ID, calendar billingopsdomain.OverdueCalendar, now time.Time) (map[string]any, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
 	var row struct {
-		InvoiceID     snowflake.ID `gorm:"column:invoice_id"`
-		InvoiceNumber string       `gorm:"column:invoice_number"`
-		Status        string       `gorm:"column:status"`
-		CustomerID    snowflake.ID `gorm:"column:customer_id"`
-		CustomerName  string       `gorm:"column:customer_name"`
-		Currency      string       `gorm:"column:currency"`
-		AmountDue     int64        `gorm:"column:amount_due"`
-		DueAt         *time.Time   `gorm:"column:due_at"`
+		InvoiceID      snowflake.ID `gorm:"column:invoice_id"`
+		InvoiceNumber  string       `gorm:"column:invoice_number"`
+		Status         string       `gorm:"column:status"`
+		CustomerID     snowflake.ID `gorm:"column:customer_id"`
+		CustomerName   string       `gorm:"column:customer_name"`
+		Currency       string       `gorm:"column:currency"`
+		AmountDue      int64        `gorm:"column:amount_due"`
+		DisputedAmount int64        `gorm:"column:disputed_amount"`
+		DueAt          *time.Time   `gorm:"column:due_at"`
 	}
-	query := `
+	settled, settledArgs := settledAmountCTE(orgID, "", arCodes)
+	disputed, disputedArgs := disputedAmountCTE(orgID)
+	query := fmt.Sprintf(`
 		SELECT
 			i.id AS invoice_id,
 			COALESCE(i.invoice_number::text, '') AS invoice_number,
@@ -808,42 +1654,19 @@ func (r *RepositoryImpl) loadInvoiceSnapshot(ctx context.Context, orgID, invoice
 			c.name AS customer_name,
 			i.currency AS currency,
 			i.due_at AS due_at,
-			GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS amount_due
+			GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS amount_due,
+			LEAST(COALESCE(d.disputed_amount, 0), GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0)) AS disputed_amount
 		FROM invoices i
-		JOIN customers c ON c.id = i.customer_id
-		LEFT JOIN (
-			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-			FROM ledger_entries le
-			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
-			WHERE le.org_id = ?
-			  AND le.currency = (SELECT currency FROM invoices WHERE id = ? AND org_id = ?)
-			  AND le.source_type = ?
-			  AND a.code = ?
-			GROUP BY 1
-		) s ON s.invoice_id_text = i.id::text
+		JOIN customers c ON c.id = i.customer_id AND c.org_id = i.org_id
+		LEFT JOIN (%[1]s
+		) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+		LEFT JOIN (%[2]s
+		) d ON d.invoice_id = i.id
 		WHERE i.org_id = ? AND i.id = ?
-		LIMIT 1`
-
-	currency, err := r.FetchOrgCurrency(ctx, orgID)
-	if err != nil {
-		return nil, err
-	}
+		LIMIT 1`, settled, disputed)
 
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		orgID,
-		invoiceID, orgID,
-		orgID,
-		currency,
-		string(ledgerdomain.SourceTypePayment),
-		string(ledgerdomain.AccountCodeAccountsReceivable),
-		orgID,
-		invoiceID,
-	).Scan(&row).Error; err != nil {
+	args := append(append(settledArgs, disputedArgs...), orgID, invoiceID)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&row).Error; err != nil {
 		return nil, err
 	}
 
@@ -852,28 +1675,25 @@ func (r *RepositoryImpl) loadInvoiceSnapshot(ctx context.Context, orgID, invoice
 	}
 
 	snapshot := map[string]any{
-		"invoice_id":     row.InvoiceID.String(),
-		"invoice_number": strings.TrimSpace(row.InvoiceNumber),
-		"status":         row.Status,
-		"customer_id":    row.CustomerID.String(),
-		"customer_name":  row.CustomerName,
-		"amount_due":     row.AmountDue,
-		"currency":       strings.ToUpper(strings.TrimSpace(row.Currency)),
+		"invoice_id":      row.InvoiceID.String(),
+		"invoice_number":  strings.TrimSpace(row.InvoiceNumber),
+		"status":          row.Status,
+		"customer_id":     row.CustomerID.String(),
+		"customer_name":   row.CustomerName,
+		"amount_due":      row.AmountDue,
+		"disputed_amount": row.DisputedAmount,
+		"currency":        strings.ToUpper(strings.TrimSpace(row.Currency)),
 	}
 	if row.DueAt != nil {
 		due := row.DueAt.UTC()
-		daysOverdue := int(now.Sub(due).Hours() / 24)
-		if daysOverdue < 0 {
-			daysOverdue = 0
-		}
 		snapshot["due_at"] = due.Format(time.RFC3339)
-		snapshot["days_overdue"] = daysOverdue
+		snapshot["days_overdue"] = calendar.DaysOverdue(due, now)
 	}
 	return snapshot, nil
 }
 
-func (r *RepositoryImpl) loadCustomerSnapshot(ctx context.Context, orgID, customerID snowflake.ID, now time.Time) (map[string]any, error) {
-	currency, err := r.FetchOrgCurrency(ctx, orgID)
+func (r *RepositoryImpl) loadCustomerSnapshot(ctx context.Context, orgID, customerID snowflake.ID, calendar billingopsdomain.OverdueCalendar, now time.Time) (map[string]any, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -881,6 +1701,7 @@ func (r *RepositoryImpl) loadCustomerSnapshot(ctx context.Context, orgID, custom
 	var row struct {
 		CustomerID            snowflake.ID `gorm:"column:customer_id"`
 		CustomerName          string       `gorm:"column:customer_name"`
+		Currency              *string      `gorm:"column:currency"`
 		Outstanding           int64        `gorm:"column:outstanding"`
 		OldestUnpaidInvoiceID *string      `gorm:"column:oldest_unpaid_invoice_id"`
 		OldestUnpaidInvoice   *string      `gorm:"column:oldest_unpaid_invoice_number"`
@@ -888,42 +1709,34 @@ func (r *RepositoryImpl) loadCustomerSnapshot(ctx context.Context, orgID, custom
 		LastPaymentAt         *time.Time   `gorm:"column:last_payment_at"`
 	}
 
-	query := `
-		WITH settled AS (
-			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-			FROM ledger_entries le
-			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
-			WHERE le.org_id = ?
-			  AND le.currency = ?
-			  AND le.source_type = ?
-			  AND a.code = ?
-			GROUP BY 1
+	// A customer billed in several currencies is captured in the currency
+	// of its oldest unpaid invoice, the balance collections work starts on.
+	settled, settledArgs := settledAmountCTE(orgID, "", arCodes)
+	query := fmt.Sprintf(`
+		WITH settled AS (%[1]s
 		), invoice_outstanding AS (
 			SELECT
 				i.id AS invoice_id,
 				i.customer_id,
+				i.currency,
 				COALESCE(i.invoice_number::text, '') AS invoice_number,
 				i.due_at,
 				COALESCE(i.issued_at, i.created_at) AS issued_at,
 				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding
 			FROM invoices i
-			LEFT JOIN settled s ON s.invoice_id_text = i.id::text
+			LEFT JOIN settled s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
 			WHERE i.org_id = ?
 			  AND i.status = 'FINALIZED'
 			  AND i.voided_at IS NULL
-			  AND i.currency = ?
 		), totals AS (
-			SELECT customer_id, SUM(outstanding) AS outstanding
+			SELECT customer_id, currency, SUM(outstanding) AS outstanding
 			FROM invoice_outstanding
 			WHERE outstanding > 0
-			GROUP BY customer_id
+			GROUP BY customer_id, currency
 		), oldest_unpaid AS (
 			SELECT DISTINCT ON (customer_id)
 				customer_id,
+				currency,
 				invoice_id,
 				invoice_number,
 				due_at,
@@ -940,44 +1753,54 @@ func (r *RepositoryImpl) loadCustomerSnapshot(ctx context.Context, orgID, custom
 		SELECT
 			c.id AS customer_id,
 			c.name AS customer_name,
+			ou.currency AS currency,
 			COALESCE(t.outstanding, 0) AS outstanding,
 			ou.invoice_id::text AS oldest_unpaid_invoice_id,
 			ou.invoice_number AS oldest_unpaid_invoice_number,
 			ou.due_at AS oldest_unpaid_at,
 			lp.last_payment_at AS last_payment_at
 		FROM customers c
-		LEFT JOIN totals t ON t.customer_id = c.id
 		LEFT JOIN oldest_unpaid ou ON ou.customer_id = c.id
+		LEFT JOIN totals t ON t.customer_id = c.id AND t.currency = ou.currency
 		LEFT JOIN last_payment lp ON lp.customer_id = c.id
 		WHERE c.org_id = ? AND c.id = ?
-		LIMIT 1`
+		LIMIT 1`, settled)
 
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		orgID,
-		currency,
-		string(ledgerdomain.SourceTypePayment),
-		string(ledgerdomain.AccountCodeAccountsReceivable),
+	args := append(settledArgs,
 		orgID,
-		currency,
 		orgID,
 		orgID,
 		customerID,
-	).Scan(&row).Error; err != nil {
+	)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&row).Error; err != nil {
 		return nil, err
 	}
 
 	if row.CustomerID == 0 {
 		return nil, gorm.ErrRecordNotFound
 	}
+	orgCurrency, err := r.FetchOrgCurrency(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	currency := ""
+	if row.Currency != nil {
+		currency = strings.ToUpper(strings.TrimSpace(*row.Currency))
+	}
+	if currency == "" {
+		// Nothing is unpaid; report the zero balance in the org's currency.
+		currency = orgCurrency
+	}
+	// Risk thresholds are minor units of the org's currency; a balance in
+	// another currency is graded on its age alone.
+	riskOutstanding := row.Outstanding
+	if currency != orgCurrency {
+		riskOutstanding = 0
+	}
 
 	oldestDays := 0
 	if row.OldestUnpaidAt != nil {
-		due := row.OldestUnpaidAt.UTC()
-		oldestDays = int(now.Sub(due).Hours() / 24)
-		if oldestDays < 0 {
-			oldestDays = 0
-		}
+		oldestDays = calendar.DaysOverdue(row.OldestUnpaidAt.UTC(), now)
 	}
 
 	snapshot := map[string]any{
@@ -987,7 +1810,7 @@ func (r *RepositoryImpl) loadCustomerSnapshot(ctx context.Context, orgID, custom
 		"currency":            currency,
 		"oldest_unpaid_days":  oldestDays,
 		"aging_bucket":        computeAgingBucket(oldestDays),
-		"risk_level":          computeRiskLevel(row.Outstanding, oldestDays),
+		"risk_level":          computeRiskLevel(riskOutstanding, oldestDays),
 	}
 	if row.OldestUnpaidAt != nil {
 		snapshot["oldest_unpaid_at"] = row.OldestUnpaidAt.UTC().Format(time.RFC3339)
@@ -1029,151 +1852,211 @@ func computeRiskLevel(outstanding int64, oldestDays int) string {
 func (r *RepositoryImpl) ListInboxItems(
 	ctx context.Context,
 	orgID snowflake.ID,
+	filter billingopsdomain.InboxFilter,
 	limit int,
 	now time.Time,
 ) ([]billingopsdomain.InboxRow, error) {
-	query := `
-		WITH risky_invoices AS (
-			SELECT
-				'invoice' AS entity_type,
-				i.id::text AS entity_id,
-				COALESCE(i.invoice_number::text, i.id::text) AS entity_name,
-				'overdue' AS risk_category,
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	settled, settledArgs := settledAmountCTE(orgID, "", arCodes)
+	disputed, disputedArgs := disputedAmountCTE(orgID)
+	// Held work is out of the inbox. Resolved work stays out too only when
+	// the org has made resolving terminal.
+	resolvedTerminal, err := r.resolvedIsTerminal(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	holding := "'assigned', 'in_progress'"
+	if resolvedTerminal {
+		holding = "'assigned', 'in_progress', 'resolved'"
+	}
+	// Days overdue follow the org's overdue calendar, so the risk scores
+	// the page is ordered and limited by already skip non-business days.
+	calendar, err := r.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	invoiceDays, invoiceDaysArgs := overdueDaysSQL(calendar, r.effectiveDueAt("i"), now)
+	customerDays, customerDaysArgs := overdueDaysSQL(calendar, "oo.due_at", now)
+
+	riskyInvoices := fmt.Sprintf(`
+			SELECT
+				'invoice' AS entity_type,
+				i.id::text AS entity_id,
+				COALESCE(i.invoice_number::text, i.id::text) AS entity_name,
+				'overdue' AS risk_category,
 				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS amount_due,
-				i.due_at,
-				EXTRACT(EPOCH FROM (? - i.due_at)) / 86400 AS days_overdue,
+				i.currency AS currency,
+				%[1]s AS due_at,
+				%[5]s AS days_overdue,
 				NULL::timestamp AS last_attempt,
 				ipt.token_hash,
-				-- Risk score: higher = more urgent
-				(EXTRACT(EPOCH FROM (? - i.due_at)) / 86400 * 10 + i.subtotal_amount / 10000)::int AS risk_score
+				LEAST(COALESCE(d.disputed_amount, 0), GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0)) AS disputed_amount,
+				-- Risk score: higher = more urgent. Disputed lines add no pressure,
+				-- and never take off more than is still outstanding.
+				((%[5]s) * 10 + GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0) - COALESCE(d.disputed_amount, 0), 0) / 10000)::int AS risk_score
 			FROM invoices i
-			LEFT JOIN (
-				SELECT
-					(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-					SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-				FROM ledger_entries le
-				JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-				JOIN ledger_accounts a ON a.id = l.account_id
-				JOIN payment_events pe ON pe.id = le.source_id
-				WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code = ?
-				GROUP BY 1
-			) s ON s.invoice_id_text = i.id::text
-			LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = i.id AND ipt.revoked_at IS NULL
+			LEFT JOIN (%[2]s
+			) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+			LEFT JOIN (%[3]s
+			) d ON d.invoice_id = i.id
+			LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = i.id AND ipt.org_id = i.org_id AND ipt.revoked_at IS NULL
 			LEFT JOIN billing_operation_assignments boa 
 				ON boa.org_id = ? AND boa.entity_type = 'invoice' AND boa.entity_id = i.id 
-				AND boa.status IN ('assigned', 'in_progress')
+				AND boa.status IN (%[4]s)
+			LEFT JOIN billing_operation_snoozes bos
+				ON bos.org_id = ? AND bos.entity_type = 'invoice' AND bos.entity_id = i.id
+				AND bos.snoozed_until > ?
 			WHERE i.org_id = ?
 				AND i.status = 'FINALIZED'
 				AND i.voided_at IS NULL
 				AND i.paid_at IS NULL
-				AND i.currency = ?
-				AND i.due_at IS NOT NULL
-				AND i.due_at < ?
+				AND %[1]s IS NOT NULL
+				AND %[1]s < ?
 				AND GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) > 0
+				AND NOT EXISTS (
+					SELECT 1 FROM customers dc
+					WHERE dc.id = i.customer_id AND dc.org_id = i.org_id AND dc.deleted_at IS NOT NULL
+				)
 				AND boa.id IS NULL  -- No active assignment
-		),
-		risky_customers AS (
+				AND bos.id IS NULL  -- No active snooze`, r.effectiveDueAt("i"), settled, disputed, holding, invoiceDays)
+	// Customers are scored per currency, so the exposure threshold always
+	// compares amounts in one currency.
+	riskyCustomers := fmt.Sprintf(`
 			SELECT
 				'customer' AS entity_type,
 				c.id::text AS entity_id,
 				c.name AS entity_name,
-				'high_exposure' AS risk_category,
+				CASE WHEN oo.due_at IS NULL THEN 'balance_exposure' ELSE 'high_exposure' END AS risk_category,
 				t.outstanding AS amount_due,
+				t.currency AS currency,
 				oo.due_at,
-				EXTRACT(EPOCH FROM (? - oo.due_at)) / 86400 AS days_overdue,
+				CASE WHEN oo.due_at IS NULL THEN 0 ELSE %[6]s END AS days_overdue,
 				NULL::timestamp AS last_attempt,
 				ipt.token_hash,
-				(t.outstanding / 10000)::int AS risk_score
+				t.disputed AS disputed_amount,
+				((t.outstanding - t.disputed) / 10000)::int AS risk_score
 			FROM (
-				SELECT customer_id, SUM(outstanding) AS outstanding
+				SELECT customer_id, currency, SUM(outstanding) AS outstanding, SUM(LEAST(disputed, outstanding)) AS disputed
 				FROM (
 					SELECT
 						i.customer_id,
-						GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding
+						i.currency,
+						GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding,
+						COALESCE(d.disputed_amount, 0) AS disputed
 					FROM invoices i
-					LEFT JOIN (
-						SELECT
-							(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-							SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-						FROM ledger_entries le
-						JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-						JOIN ledger_accounts a ON a.id = l.account_id
-						JOIN payment_events pe ON pe.id = le.source_id
-						WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code = ?
-						GROUP BY 1
-					) s ON s.invoice_id_text = i.id::text
-					WHERE i.org_id = ? AND i.status = 'FINALIZED' AND i.voided_at IS NULL AND i.currency = ?
+					LEFT JOIN (%[3]s
+					) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+					LEFT JOIN (%[4]s
+					) d ON d.invoice_id = i.id
+					WHERE i.org_id = ? AND i.status = 'FINALIZED' AND i.voided_at IS NULL
 				) inv
 				WHERE outstanding > 0
-				GROUP BY customer_id
+				GROUP BY customer_id, currency
 			) t
 			JOIN customers c ON c.id = t.customer_id
 			LEFT JOIN (
-				SELECT DISTINCT ON (customer_id)
-					customer_id, due_at
+				SELECT DISTINCT ON (customer_id, currency)
+					customer_id, currency, due_at
 				FROM (
 					SELECT
 						i.customer_id,
-						i.due_at
+						i.currency,
+						%[1]s AS due_at
 					FROM invoices i
-					LEFT JOIN (
-						SELECT
-							(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-							SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-						FROM ledger_entries le
-						JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-						JOIN ledger_accounts a ON a.id = l.account_id
-						JOIN payment_events pe ON pe.id = le.source_id
-						WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code = ?
-						GROUP BY 1
-					) s ON s.invoice_id_text = i.id::text
+					LEFT JOIN (%[3]s
+					) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
 					WHERE i.org_id = ?
 						AND i.status = 'FINALIZED'
 						AND i.voided_at IS NULL
-						AND i.currency = ?
 						AND GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) > 0
-						AND i.due_at IS NOT NULL
-						AND i.due_at < ?
+						AND %[1]s IS NOT NULL
+						AND %[1]s < ?
 				) inv
-				ORDER BY customer_id, due_at ASC
-			) oo ON oo.customer_id = t.customer_id
+				ORDER BY customer_id, currency, due_at ASC
+			) oo ON oo.customer_id = t.customer_id AND oo.currency = t.currency
 			LEFT JOIN invoice_public_tokens ipt ON ipt.invoice_id = (
-				SELECT id FROM invoices WHERE customer_id = c.id AND due_at = oo.due_at LIMIT 1
-			) AND ipt.revoked_at IS NULL
+				SELECT id FROM invoices WHERE invoices.org_id = c.org_id AND customer_id = c.id AND currency = t.currency AND %[2]s = oo.due_at LIMIT 1
+			) AND ipt.org_id = c.org_id AND ipt.revoked_at IS NULL
 			LEFT JOIN billing_operation_assignments boa 
 				ON boa.org_id = ? AND boa.entity_type = 'customer' AND boa.entity_id = c.id 
-				AND boa.status IN ('assigned', 'in_progress')
+				AND boa.status IN (%[5]s)
+			LEFT JOIN billing_operation_snoozes bos
+				ON bos.org_id = ? AND bos.entity_type = 'customer' AND bos.entity_id = c.id
+				AND bos.snoozed_until > ?
 			WHERE c.org_id = ?
-				AND t.outstanding >= 100000  -- High exposure threshold
+				AND c.deleted_at IS NULL
+				AND t.outstanding >= ?  -- High exposure threshold
+				AND (oo.due_at IS NOT NULL OR ?)  -- Current-only balances when enabled
 				AND boa.id IS NULL  -- No active assignment
+				AND bos.id IS NULL  -- No active snooze`, r.effectiveDueAt("i"), r.effectiveDueAt("invoices"), settled, disputed, holding, customerDays)
+
+	// A risk category filter only needs the CTE that produces it.
+	var (
+		ctes    []string
+		selects []string
+		args    []any
+	)
+	if filter.RiskCategory == "" || filter.RiskCategory == billingopsdomain.RiskCategoryOverdue {
+		ctes = append(ctes, "risky_invoices AS ("+riskyInvoices+"\n\t\t)")
+		selects = append(selects, "SELECT * FROM risky_invoices")
+		args = append(args, invoiceDaysArgs...)
+		args = append(args, invoiceDaysArgs...)
+		args = append(args, settledArgs...)
+		args = append(args, disputedArgs...)
+		args = append(args, orgID, orgID, now, orgID, now)
+	}
+	if filter.RiskCategory != billingopsdomain.RiskCategoryOverdue {
+		ctes = append(ctes, "risky_customers AS ("+riskyCustomers+"\n\t\t)")
+		selects = append(selects, "SELECT * FROM risky_customers")
+		args = append(args, customerDaysArgs...)
+		args = append(args, settledArgs...)
+		args = append(args, disputedArgs...)
+		args = append(args, orgID)
+		args = append(args, settledArgs...)
+		args = append(args,
+			orgID, now,
+			orgID, orgID, now, orgID,
+			filter.HighExposureThreshold, filter.IncludeCurrentExposure,
 		)
+	}
+
+	var conditions []string
+	if filter.RiskCategory != "" {
+		conditions = append(conditions, "risk_category = ?")
+		args = append(args, filter.RiskCategory)
+	}
+	if filter.MinAmountDue > 0 {
+		conditions = append(conditions, "amount_due >= ?")
+		args = append(args, filter.MinAmountDue)
+	}
+	if filter.Currency != "" {
+		conditions = append(conditions, "currency = ?")
+		args = append(args, filter.Currency)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		WITH %s
 		SELECT * FROM (
-			SELECT * FROM risky_invoices
-			UNION ALL
-			SELECT * FROM risky_customers
+			%s
 		) combined
+		%s
 		ORDER BY risk_score DESC, days_overdue DESC
-		LIMIT ?`
-
-	currency, err := r.FetchOrgCurrency(ctx, orgID)
-	if err != nil {
-		return nil, err
-	}
+		LIMIT ?`,
+		strings.Join(ctes, ",\n\t\t"),
+		strings.Join(selects, "\n\t\t\tUNION ALL\n\t\t\t"),
+		where,
+	)
 
 	var rows []billingopsdomain.InboxRow
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		now, now,
-		orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.AccountCodeAccountsReceivable),
-		orgID, orgID, currency, now,
-		now,
-		orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.AccountCodeAccountsReceivable),
-		orgID, currency,
-		orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.AccountCodeAccountsReceivable),
-		orgID, currency, now,
-		orgID, orgID,
-		limit,
-	).Scan(&rows).Error; err != nil {
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -1186,134 +2069,153 @@ func (r *RepositoryImpl) ListMyWorkItems(
 	limit int,
 	now time.Time,
 ) ([]billingopsdomain.MyWorkRow, error) {
-	query := `
-		SELECT
-			boa.id::text AS assignment_id,
-			boa.entity_type,
-			boa.entity_id::text AS entity_id,
-			boa.snapshot_metadata,
-			boa.assigned_at,
-			boa.status,
-			boa.last_action_at,
-			-- Current state from billing entities (optional)
-			CASE
-				WHEN boa.entity_type = 'invoice' THEN COALESCE(i.invoice_number::text, i.id::text)
-				WHEN boa.entity_type = 'customer' THEN c.name
-			END AS entity_name,
-			CASE
-				WHEN boa.entity_type = 'invoice' THEN c_inv.name
-				WHEN boa.entity_type = 'customer' THEN c.name
-			END AS customer_name,
-			CASE
-				WHEN boa.entity_type = 'invoice' THEN c_inv.email
-				WHEN boa.entity_type = 'customer' THEN c.email
-			END AS customer_email,
-			CASE
-				WHEN boa.entity_type = 'invoice' THEN i.invoice_number::text
-				ELSE NULL
-			END AS invoice_number,
-			CASE
-				WHEN boa.entity_type = 'invoice' THEN GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0)
-				WHEN boa.entity_type = 'customer' THEN t.outstanding
-			END AS current_amount_due,
-			CASE
-				WHEN boa.entity_type = 'invoice' AND i.due_at IS NOT NULL 
-					THEN EXTRACT(EPOCH FROM (? - i.due_at)) / 86400
-				WHEN boa.entity_type = 'customer' AND oo.due_at IS NOT NULL 
-					THEN EXTRACT(EPOCH FROM (? - oo.due_at)) / 86400
-			END AS current_days_overdue,
-			CASE
-				WHEN boa.entity_type = 'invoice' THEN ipt_inv.token_hash
-				WHEN boa.entity_type = 'customer' THEN ipt_cust.token_hash
-			END AS token_hash
-		FROM billing_operation_assignments boa
-		LEFT JOIN invoices i ON boa.entity_type = 'invoice' AND boa.entity_id = i.id
-		LEFT JOIN customers c ON boa.entity_type = 'customer' AND boa.entity_id = c.id
-		LEFT JOIN customers c_inv ON boa.entity_type = 'invoice' AND i.customer_id = c_inv.id
-		LEFT JOIN (
-			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-			FROM ledger_entries le
-			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
-			WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code = ?
-			GROUP BY 1
-		) s ON s.invoice_id_text = i.id::text
-		LEFT JOIN (
-			SELECT customer_id, SUM(outstanding) AS outstanding
-			FROM (
-				SELECT
-					i.customer_id,
-					GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding
-				FROM invoices i
-				LEFT JOIN (
-					SELECT
-						(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-						SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-					FROM ledger_entries le
-					JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-					JOIN ledger_accounts a ON a.id = l.account_id
-					JOIN payment_events pe ON pe.id = le.source_id
-					WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code = ?
-					GROUP BY 1
-				) s ON s.invoice_id_text = i.id::text
-				WHERE i.org_id = ? AND i.status = 'FINALIZED' AND i.voided_at IS NULL AND i.currency = ?
-			) inv
-			WHERE outstanding > 0
-			GROUP BY customer_id
-		) t ON boa.entity_type = 'customer' AND t.customer_id = boa.entity_id
-		LEFT JOIN (
-			SELECT DISTINCT ON (customer_id)
-				customer_id, due_at
-			FROM (
-				SELECT i.customer_id, i.due_at
-				FROM invoices i
-				LEFT JOIN (
-					SELECT
-						(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-						SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-					FROM ledger_entries le
-					JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-					JOIN ledger_accounts a ON a.id = l.account_id
-					JOIN payment_events pe ON pe.id = le.source_id
-					WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code = ?
-					GROUP BY 1
-				) s ON s.invoice_id_text = i.id::text
-				WHERE i.org_id = ? AND i.status = 'FINALIZED' AND i.voided_at IS NULL AND i.currency = ?
-					AND GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) > 0
-					AND i.due_at IS NOT NULL AND i.due_at < ?
-			) inv
-			ORDER BY customer_id, due_at ASC
-		) oo ON boa.entity_type = 'customer' AND oo.customer_id = boa.entity_id
-		LEFT JOIN invoice_public_tokens ipt_inv ON boa.entity_type = 'invoice' AND ipt_inv.invoice_id = i.id AND ipt_inv.revoked_at IS NULL
-		LEFT JOIN invoice_public_tokens ipt_cust ON boa.entity_type = 'customer' AND ipt_cust.invoice_id = (
-			SELECT id FROM invoices WHERE customer_id = c.id AND due_at = oo.due_at LIMIT 1
-		) AND ipt_cust.revoked_at IS NULL
-		WHERE boa.org_id = ?
-			AND boa.assigned_to = ?
-			AND boa.status IN ('assigned', 'in_progress')
-		ORDER BY boa.assigned_at ASC
-		LIMIT ?`
-
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
 	currency, err := r.FetchOrgCurrency(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
-
-	var rows []billingopsdomain.MyWorkRow
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		now, now,
-		orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.AccountCodeAccountsReceivable),
-		orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.AccountCodeAccountsReceivable),
-		orgID, currency,
-		orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.AccountCodeAccountsReceivable),
-		orgID, currency, now,
+	settled, settledArgs := settledAmountCTE(orgID, "", arCodes)
+	calendar, err := r.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	invoiceDays, invoiceDaysArgs := overdueDaysSQL(calendar, r.effectiveDueAt("i"), now)
+	customerDays, customerDaysArgs := overdueDaysSQL(calendar, "oo.due_at", now)
+
+	// Current customer balances are in the currency captured when the work
+	// was claimed, falling back to the org's for older snapshots. Work paid
+	// since it was claimed is counted across all of the user's work, not
+	// just the page returned.
+	query := fmt.Sprintf(`
+		SELECT
+			w.*,
+			COUNT(*) FILTER (
+				WHERE COALESCE((w.snapshot_metadata->>'amount_due')::numeric, 0) > 0
+					AND w.current_amount_due = 0
+			) OVER () AS now_resolved_count
+		FROM (
+			SELECT
+				boa.id::text AS assignment_id,
+				boa.entity_type,
+				boa.entity_id::text AS entity_id,
+				boa.snapshot_metadata,
+				boa.assigned_at,
+				boa.status,
+				boa.last_action_at,
+				-- Current state from billing entities (optional)
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN COALESCE(i.invoice_number::text, i.id::text)
+					WHEN boa.entity_type = 'customer' THEN c.name
+				END AS entity_name,
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN c_inv.name
+					WHEN boa.entity_type = 'customer' THEN c.name
+				END AS customer_name,
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN c_inv.email
+					WHEN boa.entity_type = 'customer' THEN c.email
+				END AS customer_email,
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN c_inv.phone
+					WHEN boa.entity_type = 'customer' THEN c.phone
+				END AS customer_phone,
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN c_inv.preferred_contact_channel
+					WHEN boa.entity_type = 'customer' THEN c.preferred_contact_channel
+				END AS preferred_contact_channel,
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN c_inv.do_not_contact
+					WHEN boa.entity_type = 'customer' THEN c.do_not_contact
+				END AS do_not_contact,
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN i.invoice_number::text
+					ELSE NULL
+				END AS invoice_number,
+				-- t only has customers with something outstanding.
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0)
+					WHEN boa.entity_type = 'customer' AND c.id IS NOT NULL THEN COALESCE(t.outstanding, 0)
+				END AS current_amount_due,
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN i.currency
+					WHEN boa.entity_type = 'customer' THEN COALESCE(boa.snapshot_metadata->>'currency', ?)
+				END AS currency,
+				CASE
+					WHEN boa.entity_type = 'invoice' AND %[1]s IS NOT NULL 
+						THEN %[4]s
+					WHEN boa.entity_type = 'customer' AND oo.due_at IS NOT NULL 
+						THEN %[5]s
+				END AS current_days_overdue,
+				CASE
+					WHEN boa.entity_type = 'invoice' THEN ipt_inv.token_hash
+					WHEN boa.entity_type = 'customer' THEN ipt_cust.token_hash
+				END AS token_hash
+			FROM billing_operation_assignments boa
+			LEFT JOIN invoices i ON boa.entity_type = 'invoice' AND boa.entity_id = i.id AND i.org_id = boa.org_id
+			LEFT JOIN customers c ON boa.entity_type = 'customer' AND boa.entity_id = c.id AND c.org_id = boa.org_id
+			LEFT JOIN customers c_inv ON boa.entity_type = 'invoice' AND i.customer_id = c_inv.id AND c_inv.org_id = boa.org_id
+			LEFT JOIN (%[3]s
+			) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+			LEFT JOIN (
+				SELECT customer_id, currency, SUM(outstanding) AS outstanding
+				FROM (
+					SELECT
+						i.customer_id,
+						i.currency,
+						GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding
+					FROM invoices i
+					LEFT JOIN (%[3]s
+					) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+					WHERE i.org_id = ? AND i.status = 'FINALIZED' AND i.voided_at IS NULL
+				) inv
+				WHERE outstanding > 0
+				GROUP BY customer_id, currency
+			) t ON boa.entity_type = 'customer' AND t.customer_id = boa.entity_id
+				AND t.currency = COALESCE(boa.snapshot_metadata->>'currency', ?)
+			LEFT JOIN (
+				SELECT DISTINCT ON (customer_id, currency)
+					customer_id, currency, due_at
+				FROM (
+					SELECT i.customer_id, i.currency, %[1]s AS due_at
+					FROM invoices i
+					LEFT JOIN (%[3]s
+					) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+					WHERE i.org_id = ? AND i.status = 'FINALIZED' AND i.voided_at IS NULL
+						AND GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) > 0
+						AND %[1]s IS NOT NULL AND %[1]s < ?
+				) inv
+				ORDER BY customer_id, currency, due_at ASC
+			) oo ON boa.entity_type = 'customer' AND oo.customer_id = boa.entity_id
+				AND oo.currency = COALESCE(boa.snapshot_metadata->>'currency', ?)
+			LEFT JOIN invoice_public_tokens ipt_inv ON boa.entity_type = 'invoice' AND ipt_inv.invoice_id = i.id AND ipt_inv.org_id = boa.org_id AND ipt_inv.revoked_at IS NULL
+			LEFT JOIN invoice_public_tokens ipt_cust ON boa.entity_type = 'customer' AND ipt_cust.invoice_id = (
+				SELECT id FROM invoices WHERE invoices.org_id = boa.org_id AND customer_id = c.id AND currency = oo.currency AND %[2]s = oo.due_at LIMIT 1
+			) AND ipt_cust.org_id = boa.org_id AND ipt_cust.revoked_at IS NULL
+			WHERE boa.org_id = ?
+				AND boa.assigned_to = ?
+				AND boa.status IN ('assigned', 'in_progress')
+		) w
+		ORDER BY w.assigned_at ASC
+		LIMIT ?`, r.effectiveDueAt("i"), r.effectiveDueAt("invoices"), settled, invoiceDays, customerDays)
+
+	args := []any{currency}
+	args = append(args, invoiceDaysArgs...)
+	args = append(args, customerDaysArgs...)
+	args = append(args, settledArgs...)
+	args = append(args, settledArgs...)
+	args = append(args, orgID, currency)
+	args = append(args, settledArgs...)
+	args = append(args,
+		orgID, now, currency,
 		orgID, userID,
 		limit,
-	).Scan(&rows).Error; err != nil {
+	)
+
+	var rows []billingopsdomain.MyWorkRow
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -1336,7 +2238,8 @@ func (r *RepositoryImpl) ListRecentlyResolvedItems(
 			boa.resolved_at,
 			boa.resolved_by,
 			boa.release_reason,
-			boa.assigned_at
+			boa.assigned_at,
+			boa.resolution_snapshot
 		FROM billing_operation_assignments boa
 		WHERE boa.org_id = ?
 			AND boa.assigned_to = ?
@@ -1359,9 +2262,17 @@ func (r *RepositoryImpl) ListRecentlyResolvedItems(
 func (r *RepositoryImpl) GetTeamViewStats(
 	ctx context.Context,
 	orgID snowflake.ID,
+	excludeUserIDs []string,
 	now time.Time,
 ) ([]billingopsdomain.TeamRow, error) {
-	query := `
+	args := []any{now, orgID}
+	excludeClause := ""
+	if len(excludeUserIDs) > 0 {
+		excludeClause = "AND boa.assigned_to NOT IN ?"
+		args = append(args, excludeUserIDs)
+	}
+
+	query := fmt.Sprintf(`
 		SELECT
 			boa.assigned_to AS user_id,
 			COUNT(*) AS active_assignments,
@@ -1377,19 +2288,66 @@ func (r *RepositoryImpl) GetTeamViewStats(
 		FROM billing_operation_assignments boa
 		WHERE boa.org_id = ?
 			AND boa.status IN ('assigned', 'in_progress', 'escalated')
+			%s
 		GROUP BY boa.assigned_to
-		ORDER BY boa.assigned_to ASC`
+		ORDER BY boa.assigned_to ASC`, excludeClause)
 
 	var rows []billingopsdomain.TeamRow
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		now, orgID,
-	).Scan(&rows).Error; err != nil {
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
 }
 
+func (r *RepositoryImpl) ListAssignments(
+	ctx context.Context,
+	orgID snowflake.ID,
+	filter billingopsdomain.AssignmentFilter,
+	cursor *billingopsdomain.AssignmentCursor,
+	limit int,
+) ([]billingopsdomain.BillingAssignmentRecord, error) {
+	stmt := r.db.WithContext(ctx).Where("org_id = ?", orgID)
+	if filter.AssignedTo != "" {
+		stmt = stmt.Where("assigned_to = ?", filter.AssignedTo)
+	}
+	if filter.Status != "" {
+		stmt = stmt.Where("status = ?", filter.Status)
+	}
+	if filter.EntityType != "" {
+		stmt = stmt.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.Breached != nil {
+		if *filter.Breached {
+			stmt = stmt.Where("breached_at IS NOT NULL")
+		} else {
+			stmt = stmt.Where("breached_at IS NULL")
+		}
+	}
+	if filter.AssignedFrom != nil {
+		stmt = stmt.Where("assigned_at >= ?", filter.AssignedFrom.UTC())
+	}
+	if filter.AssignedUntil != nil {
+		stmt = stmt.Where("assigned_at <= ?", filter.AssignedUntil.UTC())
+	}
+	if cursor != nil {
+		stmt = stmt.Where("(assigned_at < ? OR (assigned_at = ? AND id < ?))",
+			cursor.AssignedAt,
+			cursor.AssignedAt,
+			cursor.ID,
+		)
+	}
+
+	var records []billingopsdomain.BillingAssignmentRecord
+	if err := stmt.
+		Order("assigned_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
 func (r *RepositoryImpl) ListInvoicePayments(
 	ctx context.Context,
 	orgID, invoiceID snowflake.ID,
@@ -1403,8 +2361,9 @@ func (r *RepositoryImpl) ListInvoicePayments(
 			pe.currency,
 			pe.payload
 		FROM payment_events pe
+		LEFT JOIN payment_allocations pa ON pa.payment_event_id = pe.id
 		WHERE pe.org_id = ?
-		  AND pe.payload -> 'data' -> 'object' -> 'metadata' ->> 'invoice_id' = ?
+		  AND COALESCE(pe.payload -> 'data' -> 'object' -> 'metadata' ->> 'invoice_id', pa.invoice_id::text) = ?
 		ORDER BY pe.received_at DESC`
 
 	var rows []billingopsdomain.PaymentRow
@@ -1414,12 +2373,192 @@ func (r *RepositoryImpl) ListInvoicePayments(
 	return rows, nil
 }
 
+// entityScope returns a predicate on the entity columns typeCol and idCol
+// that matches rows recorded on the entity, and its bind variables. A
+// customer's scope takes in its invoices, so its audit trail covers the
+// work done on them. With asText, idCol holds IDs as text.
+func entityScope(orgID snowflake.ID, entityType string, entityID snowflake.ID, typeCol, idCol string, asText bool) (string, []any) {
+	id, invoiceID := any(entityID), "id"
+	if asText {
+		id, invoiceID = entityID.String(), "id::text"
+	}
+	if entityType != billingopsdomain.EntityTypeCustomer {
+		return fmt.Sprintf("(%s = ? AND %s = ?)", typeCol, idCol), []any{entityType, id}
+	}
+	return fmt.Sprintf(
+		"((%[1]s = ? AND %[2]s = ?) OR (%[1]s = ? AND %[2]s IN (SELECT %[3]s FROM invoices WHERE org_id = ? AND customer_id = ?)))",
+		typeCol, idCol, invoiceID,
+	), []any{billingopsdomain.EntityTypeCustomer, id, billingopsdomain.EntityTypeInvoice, orgID, entityID}
+}
+
+// ListEntityActions returns every billing operation action recorded on the
+// entity, including those moved to the archive, oldest first.
+func (r *RepositoryImpl) ListEntityActions(
+	ctx context.Context,
+	orgID snowflake.ID,
+	entityType string,
+	entityID snowflake.ID,
+) ([]billingopsdomain.BillingActionRecord, error) {
+	scope, scopeArgs := entityScope(orgID, entityType, entityID, "entity_type", "entity_id", false)
+	query := fmt.Sprintf(`
+		SELECT id, org_id, entity_type, entity_id, action_type, action_bucket,
+			idempotency_key, metadata, actor_type, actor_id, created_at
+		FROM billing_operation_actions
+		WHERE org_id = ? AND %[1]s
+		UNION ALL
+		SELECT id, org_id, entity_type, entity_id, action_type, action_bucket,
+			idempotency_key, metadata, actor_type, actor_id, created_at
+		FROM billing_operation_actions_archive
+		WHERE org_id = ? AND %[1]s
+		ORDER BY created_at ASC, id ASC`, scope)
+
+	args := append(append([]any{orgID}, scopeArgs...), orgID)
+	args = append(args, scopeArgs...)
+	var records []billingopsdomain.BillingActionRecord
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// FindEntityAssignment returns the entity's assignment, or nil when it has
+// never been claimed.
+func (r *RepositoryImpl) FindEntityAssignment(
+	ctx context.Context,
+	orgID snowflake.ID,
+	entityType string,
+	entityID snowflake.ID,
+) (*billingopsdomain.BillingAssignmentRecord, error) {
+	var records []billingopsdomain.BillingAssignmentRecord
+	if err := r.db.WithContext(ctx).
+		Where("org_id = ? AND entity_type = ? AND entity_id = ?", orgID, entityType, entityID).
+		Limit(1).
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[0], nil
+}
+
+// ListEntityAssignments returns the assignments of the entity, oldest
+// first.
+func (r *RepositoryImpl) ListEntityAssignments(
+	ctx context.Context,
+	orgID snowflake.ID,
+	entityType string,
+	entityID snowflake.ID,
+) ([]billingopsdomain.BillingAssignmentRecord, error) {
+	scope, scopeArgs := entityScope(orgID, entityType, entityID, "entity_type", "entity_id", false)
+	var records []billingopsdomain.BillingAssignmentRecord
+	if err := r.db.WithContext(ctx).
+		Where("org_id = ? AND "+scope, append([]any{orgID}, scopeArgs...)...).
+		Order("assigned_at ASC, id ASC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ListEntityPaymentEvents returns the payment events for an invoice (matched
+// on the invoice_id metadata or a payment allocation, as in
+// ListInvoicePayments) or a customer, oldest first.
+func (r *RepositoryImpl) ListEntityPaymentEvents(
+	ctx context.Context,
+	orgID snowflake.ID,
+	entityType string,
+	entityID snowflake.ID,
+) ([]billingopsdomain.EntityPaymentEventRow, error) {
+	var match string
+	var arg any
+	switch entityType {
+	case billingopsdomain.EntityTypeInvoice:
+		match, arg = "COALESCE(pe.payload -> 'data' -> 'object' -> 'metadata' ->> 'invoice_id', pa.invoice_id::text) = ?", entityID.String()
+	case billingopsdomain.EntityTypeCustomer:
+		match, arg = "pe.customer_id = ?", entityID
+	default:
+		return nil, billingopsdomain.ErrInvalidEntityType
+	}
+
+	query := `
+		SELECT
+			pe.id,
+			pe.provider,
+			pe.provider_event_id,
+			pe.event_type,
+			pe.payload,
+			pe.received_at
+		FROM payment_events pe
+		LEFT JOIN payment_allocations pa ON pa.payment_event_id = pe.id
+		WHERE pe.org_id = ?
+		  AND ` + match + `
+		ORDER BY pe.received_at ASC, pe.id ASC`
+
+	var rows []billingopsdomain.EntityPaymentEventRow
+	if err := r.db.WithContext(ctx).Raw(query, orgID, arg).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ListEntityAuditLogs returns the audit log entries that target the entity,
+// plus those of billing operation actions taken on it, oldest first.
+func (r *RepositoryImpl) ListEntityAuditLogs(
+	ctx context.Context,
+	orgID snowflake.ID,
+	entityType string,
+	entityID snowflake.ID,
+) ([]billingopsdomain.EntityAuditLogRow, error) {
+	target, targetArgs := entityScope(orgID, entityType, entityID, "target_type", "target_id", true)
+	action, actionArgs := entityScope(orgID, entityType, entityID, "metadata ->> 'entity_type'", "metadata ->> 'entity_id'", true)
+	query := fmt.Sprintf(`
+		SELECT
+			id,
+			actor_type,
+			COALESCE(actor_id, '') AS actor_id,
+			action,
+			target_type,
+			COALESCE(target_id, '') AS target_id,
+			metadata,
+			created_at
+		FROM audit_logs
+		WHERE org_id = ?
+		  AND (
+			%s
+			OR (target_type = 'billing_operation_action' AND %s)
+		  )
+		ORDER BY created_at ASC, id ASC`, target, action)
+
+	args := append(append([]any{orgID}, targetArgs...), actionArgs...)
+	var rows []billingopsdomain.EntityAuditLogRow
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
 func (r *RepositoryImpl) GetExposureStats(
 	ctx context.Context,
 	orgID snowflake.ID,
 	now time.Time,
 ) (billingopsdomain.ExposureStatsRow, error) {
-	query := `
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return billingopsdomain.ExposureStatsRow{}, err
+	}
+	currency, err := r.FetchOrgCurrency(ctx, orgID)
+	if err != nil {
+		return billingopsdomain.ExposureStatsRow{}, err
+	}
+	settled, settledArgs := settledAmountCTE(orgID, currency, arCodes)
+	calendar, err := r.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return billingopsdomain.ExposureStatsRow{}, err
+	}
+	days, daysArgs := overdueDaysSQL(calendar, r.effectiveDueAt("i"), now)
+
+	query := fmt.Sprintf(`
 		SELECT
 			COALESCE(SUM(outstanding), 0) AS total_exposure,
 			COALESCE(SUM(CASE WHEN days_overdue <= 0 THEN outstanding ELSE 0 END), 0) AS current_amount,
@@ -1431,51 +2570,452 @@ func (r *RepositoryImpl) GetExposureStats(
 		FROM (
 			SELECT
 				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding,
-				EXTRACT(EPOCH FROM (? - i.due_at)) / 86400 AS days_overdue
+				%[3]s AS days_overdue
 			FROM invoices i
-			LEFT JOIN (
-				SELECT
-					(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-					SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-				FROM ledger_entries le
-				JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-				JOIN ledger_accounts a ON a.id = l.account_id
-				JOIN payment_events pe ON pe.id = le.source_id
-				WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code = ?
-				GROUP BY 1
+			LEFT JOIN (%[2]s
 			) s ON s.invoice_id_text = i.id::text
 			WHERE i.org_id = ?
 				AND i.status = 'FINALIZED'
 				AND i.voided_at IS NULL
 				AND i.paid_at IS NULL
 				AND i.currency = ?
-				AND i.due_at IS NOT NULL
+				AND %[1]s IS NOT NULL
 		) inv
-		WHERE outstanding > 0`
+		WHERE outstanding > 0`, r.effectiveDueAt("i"), settled, days)
 
+	var stats billingopsdomain.ExposureStatsRow
+	args := append(daysArgs, settledArgs...)
+	args = append(args, orgID, currency)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&stats).Error; err != nil {
+		return billingopsdomain.ExposureStatsRow{}, err
+	}
+	return stats, nil
+}
+
+// GetExposureAging buckets the same invoices as GetExposureStats by days
+// overdue. Bucket i holds invoices at most edges[i] days overdue and past
+// the previous edge; bucket len(edges) holds the rest.
+func (r *RepositoryImpl) GetExposureAging(
+	ctx context.Context,
+	orgID snowflake.ID,
+	now time.Time,
+	edges []int,
+) ([]billingopsdomain.ExposureAgingRow, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
 	currency, err := r.FetchOrgCurrency(ctx, orgID)
 	if err != nil {
-		return billingopsdomain.ExposureStatsRow{}, err
+		return nil, err
+	}
+	settled, settledArgs := settledAmountCTE(orgID, currency, arCodes)
+	calendar, err := r.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return nil, err
 	}
+	days, daysArgs := overdueDaysSQL(calendar, r.effectiveDueAt("i"), now)
+
+	var bucket strings.Builder
+	bucket.WriteString("CASE")
+	bucketArgs := make([]any, 0, len(edges))
+	for i, edge := range edges {
+		fmt.Fprintf(&bucket, " WHEN days_overdue <= ? THEN %d", i)
+		bucketArgs = append(bucketArgs, edge)
+	}
+	fmt.Fprintf(&bucket, " ELSE %d END", len(edges))
 
-	var stats billingopsdomain.ExposureStatsRow
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		now,
-		orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.AccountCodeAccountsReceivable),
-		orgID, currency,
-	).Scan(&stats).Error; err != nil {
-		return billingopsdomain.ExposureStatsRow{}, err
+	query := fmt.Sprintf(`
+		SELECT
+			%[3]s AS bucket,
+			COALESCE(SUM(outstanding), 0) AS amount,
+			COUNT(*) AS invoice_count
+		FROM (
+			SELECT
+				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding,
+				%[4]s AS days_overdue
+			FROM invoices i
+			LEFT JOIN (%[2]s
+			) s ON s.invoice_id_text = i.id::text
+			WHERE i.org_id = ?
+				AND i.status = 'FINALIZED'
+				AND i.voided_at IS NULL
+				AND i.paid_at IS NULL
+				AND i.currency = ?
+				AND %[1]s IS NOT NULL
+		) inv
+		WHERE outstanding > 0 AND days_overdue > 0
+		GROUP BY 1
+		ORDER BY 1`, r.effectiveDueAt("i"), settled, bucket.String(), days)
+
+	var rows []billingopsdomain.ExposureAgingRow
+	args := append(bucketArgs, daysArgs...)
+	args = append(args, settledArgs...)
+	args = append(args, orgID, currency)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetARFlowStats returns the receivables flows used by the AR health summary:
+// open AR as of from and as of to, invoices billed in [from, to), and AR
+// settled by succeeded payments received in [from, to).
+func (r *RepositoryImpl) GetARFlowStats(
+	ctx context.Context,
+	orgID snowflake.ID,
+	currency string,
+	from, to time.Time,
+) (billingopsdomain.ARFlowStatsRow, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return billingopsdomain.ARFlowStatsRow{}, err
+	}
+	beginning, beginningArgs := receivablesAtQuery(orgID, currency, arCodes, from)
+	ending, endingArgs := receivablesAtQuery(orgID, currency, arCodes, to)
+	query := fmt.Sprintf(`
+		SELECT
+			%[1]s AS beginning_receivables,
+			%[2]s AS ending_receivables,
+			(
+				SELECT COALESCE(SUM(i.subtotal_amount), 0)
+				FROM invoices i
+				WHERE i.org_id = ?
+					AND i.status = 'FINALIZED'
+					AND i.voided_at IS NULL
+					AND i.currency = ?
+					AND i.finalized_at >= ? AND i.finalized_at < ?
+			) AS total_billed,
+			(
+				SELECT COALESCE(SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END), 0)
+				FROM ledger_entries le
+				JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
+				JOIN ledger_accounts a ON a.id = l.account_id AND a.org_id = le.org_id
+				JOIN payment_events pe ON pe.id = le.source_id AND pe.org_id = le.org_id
+				WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code IN ?
+					AND pe.event_type = ?
+					AND pe.received_at >= ? AND pe.received_at < ?
+			) AS payments_received`, beginning, ending)
+
+	var stats billingopsdomain.ARFlowStatsRow
+	args := append(append(beginningArgs, endingArgs...),
+		orgID, currency, from, to,
+		orgID, currency, string(ledgerdomain.SourceTypePayment), arCodes,
+		paymentdomain.EventTypePaymentSucceeded,
+		from, to,
+	)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&stats).Error; err != nil {
+		return billingopsdomain.ARFlowStatsRow{}, err
 	}
 	return stats, nil
 }
 
+// receivablesAtQuery returns a scalar subquery for the open AR in currency
+// as of cutoff: invoices finalized and not voided before cutoff, less the
+// payments and credit notes posted before it.
+func receivablesAtQuery(orgID snowflake.ID, currency string, arCodes []string, cutoff time.Time) (string, []any) {
+	settled, args := settledAmountBeforeCTE(orgID, currency, arCodes, cutoff)
+	return fmt.Sprintf(`(
+				SELECT COALESCE(SUM(GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0)), 0)
+				FROM invoices i
+				LEFT JOIN (%s
+				) s ON s.invoice_id_text = i.id::text
+				WHERE i.org_id = ?
+					AND i.status = 'FINALIZED'
+					AND i.currency = ?
+					AND i.finalized_at < ?
+					AND (i.voided_at IS NULL OR i.voided_at >= ?)
+			)`, settled), append(args, orgID, currency, cutoff, cutoff)
+}
+
+// ListARBalances sums the org's receivable ledger lines per currency and
+// sets each currency's invoice outstanding next to it. A currency appears
+// when it has receivable lines or open invoices.
+func (r *RepositoryImpl) ListARBalances(ctx context.Context, orgID snowflake.ID) ([]billingopsdomain.ARBalanceRow, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []billingopsdomain.ARBalanceRow
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT currency, SUM(ledger_balance) AS ledger_balance
+		FROM (
+			SELECT
+				le.currency AS currency,
+				SUM(CASE l.direction WHEN 'debit' THEN l.amount ELSE -l.amount END) AS ledger_balance
+			FROM ledger_entries le
+			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
+			JOIN ledger_accounts a ON a.id = l.account_id AND a.org_id = le.org_id
+			WHERE le.org_id = ? AND a.code IN ?
+			GROUP BY le.currency
+			UNION ALL
+			SELECT DISTINCT i.currency AS currency, 0 AS ledger_balance
+			FROM invoices i
+			WHERE i.org_id = ?
+				AND i.status = 'FINALIZED'
+				AND i.voided_at IS NULL
+				AND i.paid_at IS NULL
+		) b
+		GROUP BY currency
+		ORDER BY currency`,
+		orgID, arCodes, orgID,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		settled, settledArgs := settledAmountCTE(orgID, rows[i].Currency, arCodes)
+		query := fmt.Sprintf(`
+			SELECT COALESCE(SUM(GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0)), 0)
+			FROM invoices i
+			LEFT JOIN (%s
+			) s ON s.invoice_id_text = i.id::text
+			WHERE i.org_id = ?
+				AND i.status = 'FINALIZED'
+				AND i.voided_at IS NULL
+				AND i.paid_at IS NULL
+				AND i.currency = ?`, settled)
+		args := append(settledArgs, orgID, rows[i].Currency)
+		if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows[i].InvoiceOutstanding).Error; err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// ListARInvoiceDrift compares, per finalized or voided invoice, the
+// receivable charged for it (billing cycle entries posted against the invoice
+// or its cycle) less what was settled against it with its outstanding. Voided
+// invoices owe nothing, so any receivable left on them is drift.
+func (r *RepositoryImpl) ListARInvoiceDrift(
+	ctx context.Context,
+	orgID snowflake.ID,
+	currency string,
+	limit int,
+) ([]billingopsdomain.ARInvoiceDriftRow, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	settled, settledArgs := settledAmountCTE(orgID, currency, arCodes)
+
+	query := fmt.Sprintf(`
+		WITH settled AS (%s
+		),
+		charged AS (
+			SELECT
+				i.id AS invoice_id,
+				SUM(CASE l.direction WHEN 'debit' THEN l.amount ELSE -l.amount END) AS charged_amount
+			FROM invoices i
+			JOIN ledger_entries le ON le.org_id = i.org_id
+				AND le.source_type = ?
+				AND le.source_id IN (i.id, i.billing_cycle_id)
+			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
+			JOIN ledger_accounts a ON a.id = l.account_id AND a.org_id = le.org_id
+			WHERE i.org_id = ?
+				AND i.currency = ?
+				AND le.currency = ?
+				AND a.code IN ?
+			GROUP BY i.id
+		)
+		SELECT invoice_id, invoice_number, status, ledger_balance, outstanding, ledger_balance - outstanding AS drift
+		FROM (
+			SELECT
+				i.id AS invoice_id,
+				COALESCE(i.invoice_number::text, '') AS invoice_number,
+				i.status AS status,
+				COALESCE(c.charged_amount, 0) - COALESCE(s.settled_amount, 0) AS ledger_balance,
+				CASE WHEN i.status = 'FINALIZED' AND i.voided_at IS NULL AND i.paid_at IS NULL
+					THEN GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0)
+					ELSE 0
+				END AS outstanding
+			FROM invoices i
+			LEFT JOIN charged c ON c.invoice_id = i.id
+			LEFT JOIN settled s ON s.invoice_id_text = i.id::text
+			WHERE i.org_id = ?
+				AND i.currency = ?
+				AND i.status IN ('FINALIZED', 'VOID')
+		) inv
+		WHERE ledger_balance <> outstanding
+		ORDER BY ABS(ledger_balance - outstanding) DESC, invoice_id
+		LIMIT ?`, settled)
+
+	args := append(settledArgs,
+		string(ledgerdomain.SourceTypeBillingCycle), orgID, currency, currency, arCodes,
+		orgID, currency, limit,
+	)
+	var rows []billingopsdomain.ARInvoiceDriftRow
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// customerStatementEntries lists a customer's statement entries before a
+// cutoff: finalized invoices as debits, and the accounts receivable lines
+// posted for its payments and credit notes as credits (the same lines the
+// settledAmountCTE nets against each invoice).
+const customerStatementEntries = `
+	WITH entries AS (
+		SELECT
+			'invoice' AS entry_type,
+			i.id AS entry_id,
+			i.id::text AS invoice_id,
+			i.finalized_at AS occurred_at,
+			i.subtotal_amount AS debit,
+			0 AS credit
+		FROM invoices i
+		WHERE i.org_id = ?
+			AND i.customer_id = ?
+			AND i.status = 'FINALIZED'
+			AND i.voided_at IS NULL
+			AND i.currency = ?
+			AND i.finalized_at < ?
+		UNION ALL
+		SELECT
+			le.source_type AS entry_type,
+			le.source_id AS entry_id,
+			COALESCE(pe.payload #>> '{data,object,metadata,invoice_id}', pa.invoice_id::text, cn.invoice_id::text, '') AS invoice_id,
+			le.occurred_at AS occurred_at,
+			0 AS debit,
+			SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS credit
+		FROM ledger_entries le
+		JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
+		JOIN ledger_accounts a ON a.id = l.account_id AND a.org_id = le.org_id
+		LEFT JOIN payment_events pe ON pe.id = le.source_id AND pe.org_id = le.org_id
+		LEFT JOIN payment_allocations pa ON pa.payment_event_id = pe.id
+		LEFT JOIN credit_notes cn ON cn.id = le.source_id AND cn.org_id = le.org_id
+		WHERE le.org_id = ? AND le.currency = ? AND le.source_type IN (?, ?) AND a.code IN ?
+			AND COALESCE(pe.customer_id, cn.customer_id) = ?
+			AND le.occurred_at < ?
+		GROUP BY le.id, le.source_type, le.source_id, le.occurred_at, 3
+	)`
+
+// GetCustomerStatement loads the balance brought forward to from and the
+// statement entries in [from, to) for one customer, in two queries over the
+// same entries CTE.
+func (r *RepositoryImpl) GetCustomerStatement(
+	ctx context.Context,
+	orgID, customerID snowflake.ID,
+	currency string,
+	from, to time.Time,
+) (*billingopsdomain.CustomerStatementHeaderRow, []billingopsdomain.CustomerStatementRow, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	entriesArgs := func(cutoff time.Time) []any {
+		return []any{
+			orgID, customerID, currency, cutoff,
+			orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.SourceTypeCreditNote), arCodes,
+			customerID, cutoff,
+		}
+	}
+
+	headerQuery := customerStatementEntries + `
+		SELECT
+			c.name AS customer_name,
+			(SELECT COALESCE(SUM(debit - credit), 0) FROM entries) AS opening_balance
+		FROM customers c
+		WHERE c.org_id = ? AND c.id = ?`
+
+	var header billingopsdomain.CustomerStatementHeaderRow
+	res := r.db.WithContext(ctx).Raw(headerQuery, append(entriesArgs(from), orgID, customerID)...).Scan(&header)
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil, nil
+	}
+
+	rowsQuery := customerStatementEntries + `
+		SELECT
+			e.entry_type,
+			e.entry_id,
+			e.invoice_id,
+			COALESCE(inv.invoice_number, '') AS invoice_number,
+			e.occurred_at,
+			e.debit,
+			e.credit
+		FROM entries e
+		LEFT JOIN invoices inv ON inv.org_id = ? AND inv.id::text = e.invoice_id
+		WHERE e.occurred_at >= ?
+		ORDER BY e.occurred_at ASC, e.entry_id ASC`
+
+	var rows []billingopsdomain.CustomerStatementRow
+	if err := r.db.WithContext(ctx).Raw(rowsQuery, append(entriesArgs(to), orgID, from)...).Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	return &header, rows, nil
+}
+
+func (r *RepositoryImpl) ListSLABreachActions(
+	ctx context.Context,
+	orgID snowflake.ID,
+	from, to time.Time,
+) ([]billingopsdomain.SLABreachActionRow, error) {
+	var rows []billingopsdomain.SLABreachActionRow
+	if err := r.db.WithContext(ctx).Table("billing_operation_actions").
+		Select("metadata, created_at").
+		Where("org_id = ? AND action_type = ? AND created_at >= ? AND created_at < ?", orgID, billingopsdomain.ActionTypeSLABreached, from, to).
+		Order("created_at ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *RepositoryImpl) CountActionsByType(
+	ctx context.Context,
+	orgID snowflake.ID,
+	actionType string,
+	from, to time.Time,
+) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Table("billing_operation_actions").
+		Where("org_id = ? AND action_type = ? AND created_at >= ? AND created_at < ?", orgID, actionType, from, to).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *RepositoryImpl) CountEscalatedAssignments(
+	ctx context.Context,
+	orgID snowflake.ID,
+	from, to time.Time,
+) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Table("billing_operation_assignments").
+		Where("org_id = ? AND status = ? AND breached_at >= ? AND breached_at < ?", orgID, billingopsdomain.AssignmentStatusEscalated, from, to).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *RepositoryImpl) ListTopHighExposure(
 	ctx context.Context,
 	orgID snowflake.ID,
 	now time.Time,
 ) ([]billingopsdomain.TopCustomerExposureRow, error) {
-	query := `
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := r.FetchOrgCurrency(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	settled, settledArgs := settledAmountCTE(orgID, currency, arCodes)
+	calendar, err := r.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	days, daysArgs := overdueDaysSQL(calendar, r.effectiveDueAt("i"), now)
+
+	query := fmt.Sprintf(`
 		SELECT
 			c.name AS entity_name,
 			SUM(outstanding) AS amount_due,
@@ -1485,42 +3025,132 @@ func (r *RepositoryImpl) ListTopHighExposure(
 			SELECT
 				i.customer_id,
 				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding,
-				(EXTRACT(EPOCH FROM (? - i.due_at)) / 86400)::int AS days_overdue
+				(%[3]s)::int AS days_overdue
 			FROM invoices i
-			LEFT JOIN (
-				SELECT
-					(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
-					SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
-				FROM ledger_entries le
-				JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
-				JOIN ledger_accounts a ON a.id = l.account_id
-				JOIN payment_events pe ON pe.id = le.source_id
-				WHERE le.org_id = ? AND le.currency = ? AND le.source_type = ? AND a.code = ?
-				GROUP BY 1
+			LEFT JOIN (%[2]s
 			) s ON s.invoice_id_text = i.id::text
 			WHERE i.org_id = ? AND i.status = 'FINALIZED' AND i.voided_at IS NULL AND i.currency = ?
 		) inv
-		JOIN customers c ON c.id = inv.customer_id
+		JOIN customers c ON c.id = inv.customer_id AND c.org_id = ?
 		WHERE outstanding > 0
+		  AND c.deleted_at IS NULL
 		GROUP BY c.id, c.name
 		ORDER BY amount_due DESC
-		LIMIT 5`
+		LIMIT 5`, r.effectiveDueAt("i"), settled, days)
 
+	var rows []billingopsdomain.TopCustomerExposureRow
+	args := append(daysArgs, settledArgs...)
+	args = append(args, orgID, currency, orgID)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// GetExposureAnalysis computes the exposure totals and the topLimit customers
+// with the highest outstanding balance in one pass over the org's invoices,
+// instead of running GetExposureStats and ListTopHighExposure separately. The
+// totals are window aggregates over every customer, so they match
+// GetExposureStats even when fewer rows are returned.
+func (r *RepositoryImpl) GetExposureAnalysis(
+	ctx context.Context,
+	orgID snowflake.ID,
+	now time.Time,
+	topLimit int,
+) (billingopsdomain.ExposureStatsRow, []billingopsdomain.TopCustomerExposureRow, error) {
+	arCodes, err := r.receivableAccountCodes(ctx, orgID)
+	if err != nil {
+		return billingopsdomain.ExposureStatsRow{}, nil, err
+	}
 	currency, err := r.FetchOrgCurrency(ctx, orgID)
 	if err != nil {
-		return nil, err
+		return billingopsdomain.ExposureStatsRow{}, nil, err
 	}
+	settled, settledArgs := settledAmountCTE(orgID, currency, arCodes)
+	calendar, err := r.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return billingopsdomain.ExposureStatsRow{}, nil, err
+	}
+	days, daysArgs := overdueDaysSQL(calendar, r.effectiveDueAt("i"), now)
 
-	var rows []billingopsdomain.TopCustomerExposureRow
-	if err := r.db.WithContext(ctx).Raw(
-		query,
-		now,
-		orgID, currency, string(ledgerdomain.SourceTypePayment), string(ledgerdomain.AccountCodeAccountsReceivable),
+	query := fmt.Sprintf(`
+		WITH inv AS (
+			SELECT
+				i.customer_id,
+				GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS outstanding,
+				%[3]s AS days_overdue,
+				(i.paid_at IS NULL AND %[1]s IS NOT NULL) AS aged
+			FROM invoices i
+			LEFT JOIN (%[2]s
+			) s ON s.invoice_id_text = i.id::text
+			WHERE i.org_id = ? AND i.status = 'FINALIZED' AND i.voided_at IS NULL AND i.currency = ?
+		),
+		per_customer AS (
+			SELECT
+				customer_id,
+				SUM(outstanding) AS amount_due,
+				MAX(days_overdue::int) AS days_overdue,
+				COALESCE(SUM(outstanding) FILTER (WHERE aged), 0) AS total_exposure,
+				COALESCE(SUM(outstanding) FILTER (WHERE aged AND days_overdue <= 0), 0) AS current_amount,
+				COALESCE(SUM(outstanding) FILTER (WHERE aged AND days_overdue > 0 AND days_overdue <= 30), 0) AS bucket_0_30,
+				COALESCE(SUM(outstanding) FILTER (WHERE aged AND days_overdue > 30 AND days_overdue <= 60), 0) AS bucket_31_60,
+				COALESCE(SUM(outstanding) FILTER (WHERE aged AND days_overdue > 60 AND days_overdue <= 90), 0) AS bucket_61_90,
+				COALESCE(SUM(outstanding) FILTER (WHERE aged AND days_overdue > 90), 0) AS bucket_90_plus,
+				COUNT(*) FILTER (WHERE aged AND days_overdue > 0) AS overdue_count
+			FROM inv
+			WHERE outstanding > 0
+			GROUP BY customer_id
+		),
+		ranked AS (
+			SELECT
+				COALESCE(c.name, '') AS entity_name,
+				pc.amount_due,
+				(pc.amount_due / 10000)::int AS risk_score,
+				pc.days_overdue,
+				(c.id IS NOT NULL AND c.deleted_at IS NULL) AS listable,
+				ROW_NUMBER() OVER (
+					ORDER BY (c.id IS NOT NULL AND c.deleted_at IS NULL) DESC, pc.amount_due DESC
+				) AS top_rank,
+				SUM(pc.total_exposure) OVER () AS total_exposure,
+				SUM(pc.current_amount) OVER () AS current_amount,
+				SUM(pc.bucket_0_30) OVER () AS bucket_0_30,
+				SUM(pc.bucket_31_60) OVER () AS bucket_31_60,
+				SUM(pc.bucket_61_90) OVER () AS bucket_61_90,
+				SUM(pc.bucket_90_plus) OVER () AS bucket_90_plus,
+				SUM(pc.overdue_count) OVER () AS overdue_count
+			FROM per_customer pc
+			LEFT JOIN customers c ON c.id = pc.customer_id AND c.org_id = ?
+		)
+		SELECT
+			entity_name, amount_due, risk_score, days_overdue,
+			total_exposure, current_amount, bucket_0_30, bucket_31_60, bucket_61_90, bucket_90_plus, overdue_count,
+			(listable AND top_rank <= ?) AS listed
+		FROM ranked
+		WHERE top_rank <= GREATEST(?, 1)
+		ORDER BY top_rank`, r.effectiveDueAt("i"), settled, days)
+
+	var rows []billingopsdomain.ExposureAnalysisRow
+	args := append(daysArgs, settledArgs...)
+	args = append(args,
 		orgID, currency,
-	).Scan(&rows).Error; err != nil {
-		return nil, err
+		orgID,
+		topLimit,
+		topLimit,
+	)
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return billingopsdomain.ExposureStatsRow{}, nil, err
 	}
-	return rows, nil
+	if len(rows) == 0 {
+		return billingopsdomain.ExposureStatsRow{}, nil, nil
+	}
+
+	top := make([]billingopsdomain.TopCustomerExposureRow, 0, len(rows))
+	for _, row := range rows {
+		if row.Listed {
+			top = append(top, row.TopCustomerExposureRow)
+		}
+	}
+	return rows[0].ExposureStatsRow, top, nil
 }
 
 func (r *RepositoryImpl) ListBillingAssignmentsForPerformance(
@@ -1537,4 +3167,3 @@ func (r *RepositoryImpl) ListBillingAssignmentsForPerformance(
 	}
 	return assignments, nil
 }
-
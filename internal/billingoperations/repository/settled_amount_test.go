@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"gorm.io/gorm"
+)
+
+// TestSettledAmountCTE_OutstandingPaths checks that every query computing an
+// outstanding balance nets invoices against the same settled amount query,
+// bound to the same org, source types and receivable accounts, so the inbox,
+// collection queue, snapshots and exposure reports report the same totals.
+// Reports are bound to the org's currency; collections lists cover every
+// currency. The Postgres-only SQL is stopped before it reaches sqlite.
+func TestSettledAmountCTE_OutstandingPaths(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	// The org stores two sub-accounts; accounts_receivable is always added.
+	codes := []string{"accounts_receivable", "ar_domestic", "ar_international"}
+
+	// gorm expands the slice bound to a.code IN ? into one placeholder per code.
+	expand := func(settled string, args []any) (string, []any) {
+		var want []any
+		for _, arg := range args {
+			if arg, ok := arg.([]string); ok {
+				for _, code := range arg {
+					want = append(want, code)
+				}
+				continue
+			}
+			want = append(want, arg)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(codes)), ",")
+		return strings.Replace(settled, "a.code IN ?", "a.code IN ("+placeholders+")", 1), want
+	}
+	orgSettled, orgArgs := expand(settledAmountCTE(1, "EUR", codes))
+	allSettled, allArgs := expand(settledAmountCTE(1, "", codes))
+
+	cases := []struct {
+		name          string
+		cutoff        bool
+		allCurrencies bool
+		run           func(billingopsdomain.Repository) error
+	}{
+		{name: "overdue invoices", allCurrencies: true, run: func(r billingopsdomain.Repository) error {
+			_, _, err := r.ListOverdueInvoices(context.Background(), 1, now, pagination.Pagination{PageSize: 25})
+			return err
+		}},
+		{name: "outstanding customers", allCurrencies: true, run: func(r billingopsdomain.Repository) error {
+			_, _, err := r.ListOutstandingCustomers(context.Background(), 1, now, true, pagination.Pagination{PageSize: 25})
+			return err
+		}},
+		{name: "action summary", run: func(r billingopsdomain.Repository) error {
+			_, err := r.LoadActionSummary(context.Background(), 1, "EUR", []string{"payment_failed"}, true, now)
+			return err
+		}},
+		{name: "collection queue", allCurrencies: true, run: func(r billingopsdomain.Repository) error {
+			_, _, err := r.ListCollectionQueue(context.Background(), 1, now, billingopsdomain.CollectionQueueFilter{}, pagination.Pagination{PageSize: 25})
+			return err
+		}},
+		{name: "failed payment actions", allCurrencies: true, run: func(r billingopsdomain.Repository) error {
+			_, err := r.ListFailedPaymentActions(context.Background(), 1, []string{"payment_failed"}, now, 25)
+			return err
+		}},
+		{name: "inbox", allCurrencies: true, run: func(r billingopsdomain.Repository) error {
+			_, err := r.ListInboxItems(context.Background(), 1, billingopsdomain.InboxFilter{HighExposureThreshold: 100_000}, 25, now)
+			return err
+		}},
+		{name: "my work", allCurrencies: true, run: func(r billingopsdomain.Repository) error {
+			_, err := r.ListMyWorkItems(context.Background(), 1, "agent", 25, now)
+			return err
+		}},
+		{name: "invoice snapshot", allCurrencies: true, run: func(r billingopsdomain.Repository) error {
+			_, err := r.LoadEntitySnapshot(context.Background(), 1, billingopsdomain.EntityTypeInvoice, 77)
+			return err
+		}},
+		{name: "customer snapshot", allCurrencies: true, run: func(r billingopsdomain.Repository) error {
+			_, err := r.LoadEntitySnapshot(context.Background(), 1, billingopsdomain.EntityTypeCustomer, 42)
+			return err
+		}},
+		{name: "exposure stats", run: func(r billingopsdomain.Repository) error {
+			_, err := r.GetExposureStats(context.Background(), 1, now)
+			return err
+		}},
+		{name: "top high exposure", run: func(r billingopsdomain.Repository) error {
+			_, err := r.ListTopHighExposure(context.Background(), 1, now)
+			return err
+		}},
+		{name: "exposure analysis", run: func(r billingopsdomain.Repository) error {
+			_, _, err := r.GetExposureAnalysis(context.Background(), 1, now, 5)
+			return err
+		}},
+		{name: "AR flow", cutoff: true, run: func(r billingopsdomain.Repository) error {
+			_, err := r.GetARFlowStats(context.Background(), 1, "EUR", now.AddDate(0, -1, 0), now)
+			return err
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, vars := settledQuery(t, tc.run)
+			if got := strings.Count(sql, "?"); got != len(vars) {
+				t.Fatalf("query has %d placeholders but %d vars", got, len(vars))
+			}
+
+			fragment, wantArgs := orgSettled, orgArgs
+			if tc.allCurrencies {
+				fragment, wantArgs = allSettled, allArgs
+			}
+			if tc.cutoff {
+				fragment = strings.Replace(fragment, "\n\t\t\tGROUP BY 1", "\n\t\t\t  AND le.occurred_at < ?\n\t\t\tGROUP BY 1", 1)
+			}
+			copies := strings.Count(sql, "SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount")
+			if copies == 0 || strings.Count(sql, fragment) != copies {
+				t.Fatalf("expected every settled amount to use the shared query, got %s", sql)
+			}
+			// Settled amounts in every currency only net against invoices
+			// billed in the same one.
+			if tc.allCurrencies && strings.Count(sql, "AND s.currency = i.currency") != copies {
+				t.Fatalf("expected every settled amount to be joined on the invoice currency, got %s", sql)
+			}
+
+			for from := 0; ; {
+				idx := strings.Index(sql[from:], fragment)
+				if idx < 0 {
+					break
+				}
+				pos := strings.Count(sql[:from+idx], "?")
+				if got := vars[pos : pos+len(wantArgs)]; !reflect.DeepEqual(got, wantArgs) {
+					t.Fatalf("settled query at %d bound to %v, want %v", from+idx, got, wantArgs)
+				}
+				from += idx + len(fragment)
+			}
+		})
+	}
+}
+
+// settledQuery runs fn against a repository for an EUR org with two
+// receivable accounts and returns the SQL and bind variables of the query
+// that computes settled amounts.
+func settledQuery(t *testing.T, fn func(billingopsdomain.Repository) error) (string, []any) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Exec(`CREATE TABLE organization_billing_preferences (org_id INTEGER, currency TEXT, receivable_account_codes TEXT, release_on_resolve BOOLEAN, overdue_business_days BOOLEAN, overdue_holidays TEXT)`).Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE org_calendars (org_id INTEGER PRIMARY KEY, timezone TEXT, work_days TEXT, holidays TEXT)`).Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO organization_billing_preferences VALUES (1, 'eur', '["ar_domestic","ar_international"]', false, false, NULL)`).Error; err != nil {
+		t.Fatalf("insert preferences: %v", err)
+	}
+
+	var (
+		sql  string
+		vars []any
+	)
+	if err := db.Callback().Row().Before("gorm:row").Register("test:capture", func(tx *gorm.DB) {
+		if strings.Contains(tx.Statement.SQL.String(), "settled_amount") {
+			sql, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+			tx.AddError(errQueryCaptured)
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	if err := fn(NewRepository(db)); !errors.Is(err, errQueryCaptured) {
+		t.Fatalf("run query: %v", err)
+	}
+	if sql == "" {
+		t.Fatal("settled query was not captured")
+	}
+	return sql, vars
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	dbpkg "github.com/smallbiznis/railzway/pkg/db"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+func TestRepository_TenantScopeCheck(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Exec(`CREATE TABLE billing_operation_assignments (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		assigned_to TEXT NOT NULL,
+		assigned_at TIMESTAMP NOT NULL,
+		assignment_expires_at TIMESTAMP NOT NULL,
+		status TEXT NOT NULL,
+		breached_at TIMESTAMP,
+		breach_level TEXT,
+		resolved_at TIMESTAMP,
+		resolved_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`).Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if err := dbpkg.RegisterTenantScopeCheck(db, dbpkg.TenantScopeCheckStrict, zaptest.NewLogger(t), dbpkg.DefaultTenantTables...); err != nil {
+		t.Fatalf("register tenant scope check: %v", err)
+	}
+
+	repo := NewRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if err := repo.EscalateAssignment(ctx, 1, billingopsdomain.EntityTypeInvoice, 2, "sla", now); err != nil {
+		t.Fatalf("scoped update rejected: %v", err)
+	}
+	if _, err := repo.ListActiveAssignments(ctx); err != nil {
+		t.Fatalf("cross-org sweep rejected: %v", err)
+	}
+
+	// A query on the repository's handle that forgets the org predicate.
+	var count int64
+	err = repo.(*RepositoryImpl).db.WithContext(ctx).
+		Raw(`SELECT COUNT(*) FROM billing_operation_assignments WHERE entity_type = ? AND entity_id = ?`, billingopsdomain.EntityTypeInvoice, 2).
+		Scan(&count).Error
+	if !errors.Is(err, dbpkg.ErrMissingOrgScope) {
+		t.Fatalf("expected ErrMissingOrgScope, got %v", err)
+	}
+
+	// Handles passed in through WithTx are checked as well.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return repo.WithTx(tx).(*RepositoryImpl).db.
+			Exec(`UPDATE billing_operation_assignments SET status = 'released' WHERE entity_id = ?`, 2).Error
+	})
+	if !errors.Is(err, dbpkg.ErrMissingOrgScope) {
+		t.Fatalf("expected ErrMissingOrgScope inside transaction, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+)
+
+// RecordActionsBatch records each action as RecordAction would, in order, and
+// reports a result per action instead of failing the batch. Actions are
+// recorded one after another, so a repeat of an earlier action in the same
+// batch (same entity, type and day, or same idempotency key) comes back as a
+// duplicate of it. Only a cancelled context stops the batch early.
+func (s *Service) RecordActionsBatch(ctx context.Context, actions []domain.RecordActionRequest) (domain.RecordActionsBatchResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.RecordActionsBatchResponse{}, domain.ErrInvalidOrganization
+	}
+	if len(actions) == 0 || len(actions) > domain.MaxRecordActionsBatchSize {
+		return domain.RecordActionsBatchResponse{}, domain.ErrInvalidBatchSize
+	}
+
+	resp := domain.RecordActionsBatchResponse{
+		Results: make([]domain.RecordActionResult, 0, len(actions)),
+	}
+	for i, req := range actions {
+		if err := ctx.Err(); err != nil {
+			return domain.RecordActionsBatchResponse{}, err
+		}
+
+		result := domain.RecordActionResult{
+			Index:      i,
+			EntityType: strings.TrimSpace(req.EntityType),
+			EntityID:   strings.TrimSpace(req.EntityID),
+			ActionType: strings.TrimSpace(req.ActionType),
+		}
+		recorded, err := s.RecordAction(ctx, req)
+		if err != nil {
+			s.log.Warn("failed to record batch action",
+				zap.String("org_id", orgID.String()),
+				zap.Int("index", i),
+				zap.Error(err),
+			)
+			result.Status = domain.ActionStatusError
+			result.Err = err
+			resp.Failed++
+			resp.Results = append(resp.Results, result)
+			continue
+		}
+
+		result.ActionID = recorded.ActionID
+		result.Status = recorded.Status
+		result.RecordedAt = &recorded.RecordedAt
+		if recorded.Status == domain.ActionStatusDuplicate {
+			resp.Duplicates++
+		} else {
+			resp.Recorded++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// batchRepo holds an assignment on every entity and records which ones the
+// batch moved to in progress.
+type batchRepo struct {
+	bucketRepo
+	inProgress []snowflake.ID
+}
+
+func (r *batchRepo) WithTx(*gorm.DB) domain.Repository {
+	return r
+}
+
+func (r *batchRepo) LoadAssignment(context.Context, snowflake.ID, string, snowflake.ID) (*domain.AssignmentRow, error) {
+	return &domain.AssignmentRow{Status: domain.AssignmentStatusAssigned}, nil
+}
+
+func (r *batchRepo) UpdateAssignmentStatus(_ context.Context, _ snowflake.ID, _ string, entityID snowflake.ID, _, _ string, _, _ time.Time) error {
+	r.inProgress = append(r.inProgress, entityID)
+	return nil
+}
+
+func TestRecordActionsBatch(t *testing.T) {
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	newService := func(repo domain.Repository) *Service {
+		return &Service{
+			repo:  repo,
+			db:    newActionTestDB(t),
+			log:   zaptest.NewLogger(t),
+			clock: clock.NewFakeClock(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)),
+			genID: node,
+		}
+	}
+	followUp := func(entityID string) domain.RecordActionRequest {
+		return domain.RecordActionRequest{
+			ActionType: domain.ActionTypeFollowUp,
+			EntityType: domain.EntityTypeCustomer,
+			EntityID:   entityID,
+		}
+	}
+
+	t.Run("reports a result per action", func(t *testing.T) {
+		repo := &batchRepo{bucketRepo: bucketRepo{location: time.UTC}}
+		resp, err := newService(repo).RecordActionsBatch(ctx, []domain.RecordActionRequest{
+			followUp("101"),
+			followUp("102"),
+			followUp("101"),
+			{ActionType: domain.ActionTypeFollowUp, EntityType: "subscription", EntityID: "103"},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 4)
+		assert.Equal(t, 2, resp.Recorded)
+		assert.Equal(t, 1, resp.Duplicates)
+		assert.Equal(t, 1, resp.Failed)
+
+		first, dup := resp.Results[0], resp.Results[2]
+		assert.Equal(t, domain.ActionStatusRecorded, first.Status)
+		assert.Equal(t, domain.ActionStatusDuplicate, dup.Status)
+		assert.Equal(t, first.ActionID, dup.ActionID, "a repeat within the batch collapses onto the first action")
+		assert.Equal(t, 2, dup.Index)
+
+		failed := resp.Results[3]
+		assert.Equal(t, domain.ActionStatusError, failed.Status)
+		assert.ErrorIs(t, failed.Err, domain.ErrInvalidEntityType)
+		assert.Empty(t, failed.ActionID)
+		assert.Nil(t, failed.RecordedAt)
+
+		require.Len(t, repo.inserted, 2)
+		assert.Equal(t, []snowflake.ID{101, 102}, repo.inProgress, "only recorded actions bump the assignment")
+	})
+
+	t.Run("rejects empty and oversized batches", func(t *testing.T) {
+		svc := newService(&batchRepo{bucketRepo: bucketRepo{location: time.UTC}})
+		_, err := svc.RecordActionsBatch(ctx, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidBatchSize)
+
+		_, err = svc.RecordActionsBatch(ctx, make([]domain.RecordActionRequest, domain.MaxRecordActionsBatchSize+1))
+		assert.ErrorIs(t, err, domain.ErrInvalidBatchSize)
+
+		_, err = svc.RecordActionsBatch(context.Background(), []domain.RecordActionRequest{followUp("1")})
+		assert.ErrorIs(t, err, domain.ErrInvalidOrganization)
+	})
+
+	t.Run("a cancelled context stops the batch", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := newService(&batchRepo{bucketRepo: bucketRepo{location: time.UTC}}).RecordActionsBatch(cancelled, []domain.RecordActionRequest{followUp("1")})
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// bucketRepo enforces one action per entity, type and bucket like the
+// unique index on billing_operation_actions.
+type bucketRepo struct {
+	actionRepo
+	location *time.Location
+}
+
+func (r *bucketRepo) WithTx(*gorm.DB) domain.Repository {
+	return r
+}
+
+func (r *bucketRepo) FetchOrgLocation(context.Context, snowflake.ID) (*time.Location, error) {
+	return r.location, nil
+}
+
+func (r *bucketRepo) InsertBillingAction(ctx context.Context, record domain.BillingActionRecord) (bool, error) {
+	if existing, _ := r.FindActionByBucket(ctx, record.OrgID, record.EntityType, record.EntityID, record.ActionType, record.ActionBucket); existing != nil {
+		return false, nil
+	}
+	return r.actionRepo.InsertBillingAction(ctx, record)
+}
+
+func (r *bucketRepo) FindActionByBucket(_ context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID, actionType string, bucket time.Time) (*domain.BillingActionLookup, error) {
+	for _, record := range r.inserted {
+		if record.OrgID == orgID && record.EntityType == entityType && record.EntityID == entityID &&
+			record.ActionType == actionType && record.ActionBucket.Equal(bucket) {
+			return &domain.BillingActionLookup{ID: record.ID}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestRecordAction_BucketsByOrgTimezone(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	clk := clock.NewFakeClock(time.Date(2026, 3, 2, 23, 30, 0, 0, losAngeles))
+	repo := &bucketRepo{location: losAngeles}
+	svc := &Service{repo: repo, db: newActionTestDB(t), log: zaptest.NewLogger(t), clock: clk, genID: node}
+
+	record := func() domain.RecordActionResponse {
+		resp, err := svc.RecordAction(ctx, domain.RecordActionRequest{
+			ActionType: domain.ActionTypeContact,
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   "42",
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// 23:30 and 00:30 local are the same UTC day but different local days.
+	late := record()
+	assert.Equal(t, domain.ActionStatusRecorded, late.Status)
+	clk.Advance(time.Hour)
+	early := record()
+	assert.Equal(t, domain.ActionStatusRecorded, early.Status)
+
+	require.Len(t, repo.inserted, 2)
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), repo.inserted[0].ActionBucket)
+	assert.Equal(t, time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), repo.inserted[1].ActionBucket)
+	assert.Equal(t, "2026-03-03", repo.inserted[1].Metadata["action_bucket"])
+
+	// Later the same local day is a duplicate of the 00:30 action.
+	clk.Advance(20 * time.Hour)
+	again := record()
+	assert.Equal(t, domain.ActionStatusDuplicate, again.Status)
+	assert.Equal(t, early.ActionID, again.ActionID)
+}
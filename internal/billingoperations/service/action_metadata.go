@@ -0,0 +1,35 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+const defaultActionMetadataMaxBytes = 16 * 1024
+
+// actionMetadataLimit returns the maximum serialized size of caller-supplied
+// action metadata, falling back to the default when unset.
+func (s *Service) actionMetadataLimit() int {
+	if s.actionMetadataMaxBytes <= 0 {
+		return defaultActionMetadataMaxBytes
+	}
+	return s.actionMetadataMaxBytes
+}
+
+// validateActionMetadata rejects metadata that cannot be stored as JSON or
+// that would bloat billing_operation_actions rows. Only the caller's map is
+// measured; the before/after snapshots the service adds are bounded already.
+func (s *Service) validateActionMetadata(metadata map[string]any) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return domain.ErrInvalidMetadata
+	}
+	if limit := s.actionMetadataLimit(); len(encoded) > limit {
+		return &domain.MetadataTooLargeError{Size: len(encoded), Limit: limit}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// actionRepo records inserted actions without touching a database.
+type actionRepo struct {
+	domain.Repository
+	inserted []domain.BillingActionRecord
+}
+
+func (r *actionRepo) WithTx(*gorm.DB) domain.Repository {
+	return r
+}
+
+func (r *actionRepo) FetchOrgLocation(context.Context, snowflake.ID) (*time.Location, error) {
+	return time.UTC, nil
+}
+
+func (r *actionRepo) LoadEntitySnapshot(context.Context, snowflake.ID, string, snowflake.ID) (map[string]any, error) {
+	return map[string]any{"status": "overdue"}, nil
+}
+
+func (r *actionRepo) InsertBillingAction(_ context.Context, record domain.BillingActionRecord) (bool, error) {
+	r.inserted = append(r.inserted, record)
+	return true, nil
+}
+
+func (r *actionRepo) LoadAssignment(context.Context, snowflake.ID, string, snowflake.ID) (*domain.AssignmentRow, error) {
+	return nil, nil
+}
+
+func (r *actionRepo) UpdateAssignmentStatus(context.Context, snowflake.ID, string, snowflake.ID, string, string, time.Time, time.Time) error {
+	return nil
+}
+
+// newActionTestDB opens the database RecordAction runs its transaction on.
+// The fake repositories ignore it.
+func newActionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	return db
+}
+
+func TestRecordAction_MetadataLimits(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+
+	newService := func(repo *actionRepo) *Service {
+		return &Service{
+			repo:                   repo,
+			db:                     newActionTestDB(t),
+			log:                    zaptest.NewLogger(t),
+			clock:                  clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+			genID:                  node,
+			actionMetadataMaxBytes: 256,
+		}
+	}
+	request := func(metadata map[string]any) domain.RecordActionRequest {
+		return domain.RecordActionRequest{
+			ActionType: domain.ActionTypeFollowUp,
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   "42",
+			Metadata:   metadata,
+		}
+	}
+
+	t.Run("metadata within the limit is stored", func(t *testing.T) {
+		repo := &actionRepo{}
+		resp, err := newService(repo).RecordAction(ctx, request(map[string]any{"note": "called AP", "attempt": 2}))
+		require.NoError(t, err)
+		assert.Equal(t, domain.ActionStatusRecorded, resp.Status)
+		require.Len(t, repo.inserted, 1)
+		assert.Equal(t, "called AP", repo.inserted[0].Metadata["note"])
+	})
+
+	t.Run("oversized metadata is rejected", func(t *testing.T) {
+		repo := &actionRepo{}
+		_, err := newService(repo).RecordAction(ctx, request(map[string]any{"note": strings.Repeat("x", 300)}))
+		require.ErrorIs(t, err, domain.ErrMetadataTooLarge)
+		var tooLarge *domain.MetadataTooLargeError
+		require.ErrorAs(t, err, &tooLarge)
+		assert.Equal(t, 256, tooLarge.Limit)
+		assert.Greater(t, tooLarge.Size, 256)
+		assert.Empty(t, repo.inserted)
+	})
+
+	t.Run("non-serializable metadata is rejected", func(t *testing.T) {
+		repo := &actionRepo{}
+		_, err := newService(repo).RecordAction(ctx, request(map[string]any{"ratio": math.NaN()}))
+		assert.ErrorIs(t, err, domain.ErrInvalidMetadata)
+		assert.Empty(t, repo.inserted)
+	})
+
+	t.Run("unset limit falls back to the default", func(t *testing.T) {
+		svc := &Service{}
+		assert.NoError(t, svc.validateActionMetadata(map[string]any{"note": strings.Repeat("x", 1024)}))
+		assert.ErrorIs(t, svc.validateActionMetadata(map[string]any{"note": strings.Repeat("x", defaultActionMetadataMaxBytes)}), domain.ErrMetadataTooLarge)
+	})
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// actionArchiveBatchSize bounds how many actions one archive transaction
+// moves.
+const actionArchiveBatchSize = 500
+
+// ArchiveOldActions moves actions created before olderThan to the archive
+// table, or deletes them when archiving is turned off. The newest action per
+// entity and action type stays, so timelines and the per-day idempotency of
+// recent buckets keep working. Batches commit one by one; on error the
+// actions already moved stay moved.
+func (s *Service) ArchiveOldActions(ctx context.Context, olderThan time.Time) (int, error) {
+	total := 0
+	for {
+		var moved int
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			moved, err = s.repo.WithTx(tx).ArchiveActions(ctx, olderThan, actionArchiveBatchSize, s.archiveActions, s.clock.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+		total += moved
+		if moved < actionArchiveBatchSize {
+			return total, nil
+		}
+	}
+}
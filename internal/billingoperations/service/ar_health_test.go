@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestComputeARHealthRatios(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+
+	resp := domain.ARHealthResponse{
+		From:                 from,
+		To:                   to,
+		BeginningReceivables: 50_000,
+		TotalBilled:          100_000,
+		EndingReceivables:    40_000,
+		PaymentsReceived:     99_000,
+	}
+	computeARHealthRatios(&resp)
+
+	assert.Equal(t, 30.0, resp.PeriodDays)
+	require.NotNil(t, resp.DSO)
+	assert.InDelta(t, 12.0, *resp.DSO, 1e-9) // 40k / 100k * 30
+	require.NotNil(t, resp.CEI)
+	assert.InDelta(t, 90.0, *resp.CEI, 1e-9) // 99k / (50k + 100k - 40k) * 100
+}
+
+func TestComputeARHealthRatios_ZeroDenominators(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	resp := domain.ARHealthResponse{
+		From:              from,
+		To:                from.AddDate(0, 0, 7),
+		EndingReceivables: 10_000,
+	}
+	computeARHealthRatios(&resp)
+
+	assert.Nil(t, resp.DSO)
+	assert.Nil(t, resp.CEI)
+}
+
+// arFlowRepo returns AR flows for a past period. It has no exposure stats,
+// whose total is today's AR rather than the AR at the period end.
+type arFlowRepo struct {
+	domain.Repository
+	from, to time.Time
+}
+
+func (r *arFlowRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *arFlowRepo) GetARFlowStats(_ context.Context, _ snowflake.ID, _ string, from, to time.Time) (domain.ARFlowStatsRow, error) {
+	r.from, r.to = from, to
+	return domain.ARFlowStatsRow{
+		BeginningReceivables: 50_000,
+		EndingReceivables:    40_000,
+		TotalBilled:          100_000,
+		PaymentsReceived:     99_000,
+	}, nil
+}
+
+func TestGetARHealth_EndingReceivablesAtPeriodEnd(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+	repo := &arFlowRepo{}
+	svc := &Service{
+		repo:  repo,
+		log:   zaptest.NewLogger(t),
+		clock: clock.NewFakeClock(to.AddDate(0, 6, 0)),
+	}
+
+	resp, err := svc.GetARHealth(orgcontext.WithOrgID(context.Background(), 1), from, to)
+	require.NoError(t, err)
+	assert.Equal(t, from, repo.from)
+	assert.Equal(t, to, repo.to)
+	assert.Equal(t, int64(40_000), resp.EndingReceivables)
+	require.NotNil(t, resp.DSO)
+	assert.InDelta(t, 12.0, *resp.DSO, 1e-9)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+)
+
+// arReconciliationTopInvoices bounds how many contributing invoices are
+// listed for a currency that drifted.
+const arReconciliationTopInvoices = 10
+
+// ReconcileAR compares the org's ledger receivable balance with the summed
+// outstanding of its invoices, per currency. Currencies whose absolute drift
+// is over the threshold are flagged and list the invoices contributing most
+// to it.
+func (s *Service) ReconcileAR(ctx context.Context) (domain.ARReconciliation, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.ARReconciliation{}, domain.ErrInvalidOrganization
+	}
+
+	balances, err := s.repo.ListARBalances(ctx, orgID)
+	if err != nil {
+		return domain.ARReconciliation{}, err
+	}
+
+	report := domain.ARReconciliation{
+		GeneratedAt: s.clock.Now().UTC(),
+		Threshold:   s.arDriftThreshold,
+		Currencies:  make([]domain.ARCurrencyReconciliation, 0, len(balances)),
+	}
+	for _, balance := range balances {
+		item := domain.ARCurrencyReconciliation{
+			Currency:           balance.Currency,
+			CurrencyExponent:   s.currencyExponent(balance.Currency),
+			LedgerBalance:      balance.LedgerBalance,
+			InvoiceOutstanding: balance.InvoiceOutstanding,
+			Drift:              balance.LedgerBalance - balance.InvoiceOutstanding,
+		}
+		item.OverThreshold = absInt64(item.Drift) > s.arDriftThreshold
+		if item.OverThreshold {
+			report.HasDrift = true
+			rows, err := s.repo.ListARInvoiceDrift(ctx, orgID, balance.Currency, arReconciliationTopInvoices)
+			if err != nil {
+				return domain.ARReconciliation{}, err
+			}
+			item.TopInvoices = make([]domain.ARInvoiceDrift, 0, len(rows))
+			for _, row := range rows {
+				item.TopInvoices = append(item.TopInvoices, domain.ARInvoiceDrift{
+					InvoiceID:     row.InvoiceID.String(),
+					InvoiceNumber: row.InvoiceNumber,
+					Status:        row.Status,
+					LedgerBalance: row.LedgerBalance,
+					Outstanding:   row.Outstanding,
+					Drift:         row.Drift,
+				})
+			}
+		}
+		report.Currencies = append(report.Currencies, item)
+	}
+	return report, nil
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
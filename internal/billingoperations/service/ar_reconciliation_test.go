@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type reconciliationRepo struct {
+	domain.Repository
+	balances      []domain.ARBalanceRow
+	drift         map[string][]domain.ARInvoiceDriftRow
+	driftRequests []string
+}
+
+func (r *reconciliationRepo) ListARBalances(context.Context, snowflake.ID) ([]domain.ARBalanceRow, error) {
+	return r.balances, nil
+}
+
+func (r *reconciliationRepo) ListARInvoiceDrift(_ context.Context, _ snowflake.ID, currency string, limit int) ([]domain.ARInvoiceDriftRow, error) {
+	r.driftRequests = append(r.driftRequests, currency)
+	rows := r.drift[currency]
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+func TestReconcileAR(t *testing.T) {
+	repo := &reconciliationRepo{
+		balances: []domain.ARBalanceRow{
+			{Currency: "EUR", LedgerBalance: 5000, InvoiceOutstanding: 4950},
+			{Currency: "IDR", LedgerBalance: 100000, InvoiceOutstanding: 100000},
+			{Currency: "USD", LedgerBalance: 12000, InvoiceOutstanding: 15000},
+		},
+		drift: map[string][]domain.ARInvoiceDriftRow{
+			"USD": {
+				{InvoiceID: 7, InvoiceNumber: "1007", Status: "FINALIZED", LedgerBalance: 0, Outstanding: 2500, Drift: -2500},
+				{InvoiceID: 9, InvoiceNumber: "1009", Status: "FINALIZED", LedgerBalance: 1500, Outstanding: 2000, Drift: -500},
+			},
+		},
+	}
+	now := time.Date(2026, 5, 4, 6, 0, 0, 0, time.UTC)
+	svc := &Service{
+		repo:             repo,
+		log:              zaptest.NewLogger(t),
+		clock:            clock.NewFakeClock(now),
+		arDriftThreshold: 100,
+	}
+
+	report, err := svc.ReconcileAR(orgcontext.WithOrgID(context.Background(), 1))
+	require.NoError(t, err)
+
+	assert.Equal(t, now, report.GeneratedAt)
+	assert.Equal(t, int64(100), report.Threshold)
+	assert.True(t, report.HasDrift)
+	require.Len(t, report.Currencies, 3)
+
+	// Drift within the threshold is reported but not flagged.
+	eur := report.Currencies[0]
+	assert.Equal(t, int64(50), eur.Drift)
+	assert.False(t, eur.OverThreshold)
+	assert.Empty(t, eur.TopInvoices)
+
+	assert.Zero(t, report.Currencies[1].Drift)
+
+	usd := report.Currencies[2]
+	assert.Equal(t, int64(-3000), usd.Drift)
+	assert.True(t, usd.OverThreshold)
+	require.Len(t, usd.TopInvoices, 2)
+	assert.Equal(t, "7", usd.TopInvoices[0].InvoiceID)
+	assert.Equal(t, int64(-2500), usd.TopInvoices[0].Drift)
+
+	// Contributing invoices are only looked up for flagged currencies.
+	assert.Equal(t, []string{"USD"}, repo.driftRequests)
+}
+
+func TestReconcileAR_RequiresOrg(t *testing.T) {
+	svc := &Service{repo: &reconciliationRepo{}, log: zaptest.NewLogger(t), clock: clock.NewFakeClock(time.Now())}
+
+	_, err := svc.ReconcileAR(context.Background())
+	assert.ErrorIs(t, err, domain.ErrInvalidOrganization)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+)
+
+// assignmentStatuses are the statuses ListAssignments can filter on.
+var assignmentStatuses = []string{
+	domain.AssignmentStatusAssigned,
+	domain.AssignmentStatusInProgress,
+	domain.AssignmentStatusReleased,
+	domain.AssignmentStatusResolved,
+	domain.AssignmentStatusEscalated,
+}
+
+// ListAssignments lists the org's assignments, newest first, for the manager
+// "all work" table. The org always comes from the context, so no filter can
+// reach another org's rows.
+func (s *Service) ListAssignments(ctx context.Context, filter domain.AssignmentFilter) (domain.ListAssignmentsResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.ListAssignmentsResponse{}, domain.ErrInvalidOrganization
+	}
+
+	filter.AssignedTo = strings.TrimSpace(filter.AssignedTo)
+	filter.Status = strings.TrimSpace(filter.Status)
+	if filter.Status != "" && !slices.Contains(assignmentStatuses, filter.Status) {
+		return domain.ListAssignmentsResponse{}, domain.ErrInvalidStatus
+	}
+	filter.EntityType = strings.TrimSpace(filter.EntityType)
+	if filter.EntityType != "" && filter.EntityType != domain.EntityTypeInvoice && filter.EntityType != domain.EntityTypeCustomer {
+		return domain.ListAssignmentsResponse{}, domain.ErrInvalidEntityType
+	}
+	if filter.AssignedFrom != nil && filter.AssignedUntil != nil && filter.AssignedFrom.After(*filter.AssignedUntil) {
+		return domain.ListAssignmentsResponse{}, domain.ErrInvalidPeriod
+	}
+
+	cursor, err := decodeAssignmentCursor(filter.PageToken)
+	if err != nil {
+		return domain.ListAssignmentsResponse{}, err
+	}
+
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = domain.DefaultAssignmentPageSize
+	}
+	if pageSize > domain.MaxAssignmentPageSize {
+		pageSize = domain.MaxAssignmentPageSize
+	}
+
+	// One extra row tells whether another page follows.
+	records, err := s.repo.ListAssignments(ctx, orgID, filter, cursor, pageSize+1)
+	if err != nil {
+		return domain.ListAssignmentsResponse{}, err
+	}
+
+	rows := make([]*domain.BillingAssignmentRecord, 0, len(records))
+	for i := range records {
+		rows = append(rows, &records[i])
+	}
+	pageInfo := pagination.BuildCursorPageInfo(rows, int32(pageSize), encodeAssignmentCursor)
+	if len(rows) > pageSize {
+		rows = rows[:pageSize]
+	}
+
+	items := make([]domain.AssignmentListItem, 0, len(rows))
+	for _, record := range rows {
+		items = append(items, domain.AssignmentListItem{
+			AssignmentID:        record.ID.String(),
+			EntityType:          record.EntityType,
+			EntityID:            record.EntityID.String(),
+			AssignedTo:          record.AssignedTo,
+			AssignedAt:          record.AssignedAt.UTC(),
+			AssignmentExpiresAt: record.AssignmentExpiresAt.UTC(),
+			Status:              record.Status,
+			BreachedAt:          timePtr(record.BreachedAt),
+			BreachLevel:         record.BreachLevel.String,
+			LastActionAt:        timePtr(record.LastActionAt),
+			ReleasedAt:          timePtr(record.ReleasedAt),
+			ReleasedBy:          record.ReleasedBy.String,
+			ReleaseReason:       record.ReleaseReason.String,
+			HandedOffAt:         timePtr(record.HandedOffAt),
+			HandedOffBy:         record.HandedOffBy.String,
+			HandoffNote:         record.HandoffNote.String,
+			ResolvedAt:          timePtr(record.ResolvedAt),
+			ResolvedBy:          record.ResolvedBy.String,
+		})
+	}
+
+	resp := domain.ListAssignmentsResponse{Assignments: items}
+	if pageInfo != nil {
+		resp.PageInfo = *pageInfo
+	}
+	return resp, nil
+}
+
+func encodeAssignmentCursor(record *domain.BillingAssignmentRecord) string {
+	token, err := pagination.EncodeCursor(pagination.Cursor{
+		ID:        record.ID.String(),
+		CreatedAt: record.AssignedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+func decodeAssignmentCursor(token string) (*domain.AssignmentCursor, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, nil
+	}
+	decoded, err := pagination.DecodeCursor(token)
+	if err != nil {
+		return nil, domain.ErrInvalidPageToken
+	}
+	assignedAt, err := time.Parse(time.RFC3339Nano, decoded.CreatedAt)
+	if err != nil {
+		return nil, domain.ErrInvalidPageToken
+	}
+	id, err := snowflake.ParseString(strings.TrimSpace(decoded.ID))
+	if err != nil || id == 0 {
+		return nil, domain.ErrInvalidPageToken
+	}
+	return &domain.AssignmentCursor{AssignedAt: assignedAt, ID: id}, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestListAssignments(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE billing_operation_assignments (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		assigned_to TEXT NOT NULL,
+		assigned_at TIMESTAMP NOT NULL,
+		assignment_expires_at TIMESTAMP NOT NULL,
+		status TEXT NOT NULL,
+		released_at TIMESTAMP,
+		released_by TEXT,
+		release_reason TEXT,
+		resolved_at TIMESTAMP,
+		resolved_by TEXT,
+		breached_at TIMESTAMP,
+		breach_level TEXT,
+		last_action_at TIMESTAMP,
+		snapshot_metadata TEXT,
+		sla_paused_until TIMESTAMP,
+		handed_off_at TIMESTAMP,
+		handed_off_by TEXT,
+		handoff_note TEXT,
+		resolution_snapshot TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`).Error)
+
+	node, _ := snowflake.NewNode(1)
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	orgID := node.Generate()
+	otherOrgID := node.Generate()
+
+	insert := func(org snowflake.ID, assignedTo, entityType, status string, hoursAfter int, breached bool) snowflake.ID {
+		assignedAt := base.Add(time.Duration(hoursAfter) * time.Hour)
+		record := domain.BillingAssignmentRecord{
+			ID:                  node.Generate(),
+			OrgID:               org,
+			EntityType:          entityType,
+			EntityID:            node.Generate(),
+			AssignedTo:          assignedTo,
+			AssignedAt:          assignedAt,
+			AssignmentExpiresAt: assignedAt.Add(time.Hour),
+			Status:              status,
+			CreatedAt:           assignedAt,
+			UpdatedAt:           assignedAt,
+		}
+		if breached {
+			record.BreachedAt = sql.NullTime{Time: assignedAt.Add(2 * time.Hour), Valid: true}
+			record.BreachLevel = sql.NullString{String: domain.SLABreachIdleAction, Valid: true}
+		}
+		require.NoError(t, db.Create(&record).Error)
+		return record.ID
+	}
+
+	a1 := insert(orgID, "alice", domain.EntityTypeInvoice, domain.AssignmentStatusAssigned, 0, false)
+	a2 := insert(orgID, "bob", domain.EntityTypeCustomer, domain.AssignmentStatusEscalated, 1, true)
+	a3 := insert(orgID, "alice", domain.EntityTypeInvoice, domain.AssignmentStatusResolved, 2, false)
+	a4 := insert(orgID, "alice", domain.EntityTypeCustomer, domain.AssignmentStatusInProgress, 3, true)
+	insert(otherOrgID, "alice", domain.EntityTypeInvoice, domain.AssignmentStatusAssigned, 4, false)
+
+	svc := NewService(Params{
+		DB:    db,
+		Log:   zap.NewNop(),
+		Clock: clock.NewFakeClock(base.Add(24 * time.Hour)),
+		GenID: node,
+		Cfg:   config.Config{},
+	})
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	ids := func(resp domain.ListAssignmentsResponse) []string {
+		out := make([]string, 0, len(resp.Assignments))
+		for _, item := range resp.Assignments {
+			out = append(out, item.AssignmentID)
+		}
+		return out
+	}
+
+	t.Run("lists the org newest first", func(t *testing.T) {
+		resp, err := svc.ListAssignments(ctx, domain.AssignmentFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{a4.String(), a3.String(), a2.String(), a1.String()}, ids(resp))
+		assert.False(t, resp.HasMore)
+		assert.Equal(t, domain.SLABreachIdleAction, resp.Assignments[0].BreachLevel)
+		assert.NotNil(t, resp.Assignments[0].BreachedAt)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		breached, notBreached := true, false
+		from, until := base.Add(time.Hour), base.Add(2*time.Hour)
+		cases := []struct {
+			name   string
+			filter domain.AssignmentFilter
+			want   []string
+		}{
+			{"assignee", domain.AssignmentFilter{AssignedTo: "alice"}, []string{a4.String(), a3.String(), a1.String()}},
+			{"status", domain.AssignmentFilter{Status: domain.AssignmentStatusEscalated}, []string{a2.String()}},
+			{"entity type", domain.AssignmentFilter{EntityType: domain.EntityTypeCustomer}, []string{a4.String(), a2.String()}},
+			{"breached", domain.AssignmentFilter{Breached: &breached}, []string{a4.String(), a2.String()}},
+			{"not breached", domain.AssignmentFilter{Breached: &notBreached, AssignedTo: "alice"}, []string{a3.String(), a1.String()}},
+			{"date range", domain.AssignmentFilter{AssignedFrom: &from, AssignedUntil: &until}, []string{a3.String(), a2.String()}},
+		}
+		for _, tc := range cases {
+			resp, err := svc.ListAssignments(ctx, tc.filter)
+			require.NoError(t, err, tc.name)
+			assert.Equal(t, tc.want, ids(resp), tc.name)
+		}
+	})
+
+	t.Run("pages through results", func(t *testing.T) {
+		first, err := svc.ListAssignments(ctx, domain.AssignmentFilter{PageSize: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []string{a4.String(), a3.String(), a2.String()}, ids(first))
+		require.True(t, first.HasMore)
+
+		second, err := svc.ListAssignments(ctx, domain.AssignmentFilter{PageSize: 3, PageToken: first.NextPageToken})
+		require.NoError(t, err)
+		assert.Equal(t, []string{a1.String()}, ids(second))
+		assert.False(t, second.HasMore)
+	})
+
+	t.Run("rejects invalid filters", func(t *testing.T) {
+		from, until := base.Add(time.Hour), base
+		_, err := svc.ListAssignments(ctx, domain.AssignmentFilter{Status: "open"})
+		assert.ErrorIs(t, err, domain.ErrInvalidStatus)
+		_, err = svc.ListAssignments(ctx, domain.AssignmentFilter{EntityType: "subscription"})
+		assert.ErrorIs(t, err, domain.ErrInvalidEntityType)
+		_, err = svc.ListAssignments(ctx, domain.AssignmentFilter{AssignedFrom: &from, AssignedUntil: &until})
+		assert.ErrorIs(t, err, domain.ErrInvalidPeriod)
+		_, err = svc.ListAssignments(ctx, domain.AssignmentFilter{PageToken: "not-a-token"})
+		assert.ErrorIs(t, err, domain.ErrInvalidPageToken)
+		_, err = svc.ListAssignments(context.Background(), domain.AssignmentFilter{})
+		assert.ErrorIs(t, err, domain.ErrInvalidOrganization)
+	})
+}
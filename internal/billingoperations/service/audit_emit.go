@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Audit categories. Whether a failed write aborts the operation is set per
+// category by BILLING_OPS_AUDIT_REQUIRED.
+const (
+	// auditCategoryFinancial covers actions recorded against invoices and
+	// customers.
+	auditCategoryFinancial = "financial"
+	// auditCategoryOperational covers assignment lifecycle changes, snoozes,
+	// follow-ups and public link reissues.
+	auditCategoryOperational = "operational"
+	// auditCategoryRead covers audit-on-read of sensitive reports.
+	auditCategoryRead = "read"
+)
+
+// defaultAuditRetryBackoff is the delay before the first retry of a failed
+// audit write; each further retry waits one more step.
+const defaultAuditRetryBackoff = 100 * time.Millisecond
+
+// auditEntry is one audit log write issued by the service.
+type auditEntry struct {
+	category   string
+	actorType  string
+	action     string
+	targetType string
+	targetID   string
+	metadata   map[string]any
+}
+
+// newAuditRequired builds the set of categories whose audit write must
+// succeed, ignoring unknown names.
+func newAuditRequired(categories []string, log *zap.Logger) map[string]bool {
+	required := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		switch category {
+		case auditCategoryFinancial, auditCategoryOperational, auditCategoryRead:
+			required[category] = true
+		case "":
+		default:
+			log.Warn("ignoring unknown audit category", zap.String("category", category))
+		}
+	}
+	return required
+}
+
+// auditIsRequired reports whether a failed write in category must fail the
+// operation. Without configuration only financial entries are required.
+func (s *Service) auditIsRequired(category string) bool {
+	if s.auditRequired == nil {
+		return category == auditCategoryFinancial
+	}
+	return s.auditRequired[category]
+}
+
+// emitAudit writes entry through the audit service, retrying failed writes
+// with a linear backoff. When every attempt fails the entry is logged in
+// full so it can be replayed; the error is returned only for required
+// categories, best-effort categories return nil.
+func (s *Service) emitAudit(ctx context.Context, orgID snowflake.ID, entry auditEntry) error {
+	if s.auditSvc == nil {
+		return nil
+	}
+
+	backoff := s.auditRetryBackoff
+	if backoff <= 0 {
+		backoff = defaultAuditRetryBackoff
+	}
+
+	targetID := entry.targetID
+	attempts := 0
+	var err error
+	for attempt := 0; attempt <= s.auditRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(time.Duration(attempt) * backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		attempts++
+		err = s.auditSvc.AuditLog(ctx, &orgID, entry.actorType, nil,
+			entry.action,
+			entry.targetType,
+			&targetID,
+			entry.metadata,
+		)
+		if err == nil {
+			return nil
+		}
+	}
+
+	required := s.auditIsRequired(entry.category)
+	s.log.Error("audit log write failed",
+		zap.String("org_id", orgID.String()),
+		zap.String("category", entry.category),
+		zap.String("action", entry.action),
+		zap.String("target_type", entry.targetType),
+		zap.String("target_id", targetID),
+		zap.Any("metadata", entry.metadata),
+		zap.Int("attempts", attempts),
+		zap.Bool("required", required),
+		zap.Error(err),
+	)
+	if required {
+		return err
+	}
+	return nil
+}
+
+// emitAuditTx writes entry inside tx, so it commits or rolls back with the
+// change it records. A failed statement aborts the transaction, so there is
+// a single attempt: the error is returned whatever the category, and the
+// caller's retry redoes the change and the entry together.
+func (s *Service) emitAuditTx(ctx context.Context, tx *gorm.DB, orgID snowflake.ID, entry auditEntry) error {
+	if s.auditSvc == nil {
+		return nil
+	}
+
+	targetID := entry.targetID
+	err := s.auditSvc.AuditLogTx(ctx, tx, &orgID, entry.actorType, nil,
+		entry.action,
+		entry.targetType,
+		&targetID,
+		entry.metadata,
+	)
+	if err != nil {
+		s.log.Error("audit log write failed",
+			zap.String("org_id", orgID.String()),
+			zap.String("category", entry.category),
+			zap.String("action", entry.action),
+			zap.String("target_type", entry.targetType),
+			zap.String("target_id", targetID),
+			zap.Any("metadata", entry.metadata),
+			zap.Bool("required", true),
+			zap.Error(err),
+		)
+	}
+	return err
+}
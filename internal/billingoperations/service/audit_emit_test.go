@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+func TestRecordAction_AuditRetry(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	errAuditDown := errors.New("audit store unavailable")
+
+	newService := func(repo *actionRepo, auditSvc *mockAuditSvc) *Service {
+		return &Service{
+			repo:              repo,
+			db:                newActionTestDB(t),
+			log:               zaptest.NewLogger(t),
+			clock:             clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+			genID:             node,
+			auditSvc:          auditSvc,
+			auditRetries:      2,
+			auditRetryBackoff: time.Nanosecond,
+		}
+	}
+	req := domain.RecordActionRequest{
+		ActionType: domain.ActionTypeFollowUp,
+		EntityType: domain.EntityTypeInvoice,
+		EntityID:   "42",
+	}
+
+	t.Run("a required audit is a single attempt that fails the action", func(t *testing.T) {
+		auditSvc := new(mockAuditSvc)
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			"billing_operations.action.follow_up", "billing_operation_action", mock.Anything, mock.Anything).
+			Return(errAuditDown)
+
+		_, err := newService(&actionRepo{}, auditSvc).RecordAction(ctx, req)
+		assert.ErrorIs(t, err, errAuditDown)
+		auditSvc.AssertNumberOfCalls(t, "AuditLog", 1)
+	})
+
+	t.Run("a retried action records the action and its audit", func(t *testing.T) {
+		auditSvc := new(mockAuditSvc)
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			"billing_operations.action.follow_up", "billing_operation_action", mock.Anything, mock.Anything).
+			Return(errAuditDown).Once()
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			"billing_operations.action.follow_up", "billing_operation_action", mock.Anything, mock.Anything).
+			Return(nil).Once()
+
+		svc := newService(&actionRepo{}, auditSvc)
+		_, err := svc.RecordAction(ctx, req)
+		require.ErrorIs(t, err, errAuditDown)
+		resp, err := svc.RecordAction(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ActionStatusRecorded, resp.Status)
+		auditSvc.AssertNumberOfCalls(t, "AuditLog", 2)
+	})
+
+	t.Run("financial audits can be made best-effort", func(t *testing.T) {
+		auditSvc := new(mockAuditSvc)
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(errAuditDown)
+
+		svc := newService(&actionRepo{}, auditSvc)
+		svc.auditRequired = newAuditRequired([]string{auditCategoryRead}, svc.log)
+		_, err := svc.RecordAction(ctx, req)
+		assert.NoError(t, err)
+		auditSvc.AssertNumberOfCalls(t, "AuditLog", 3)
+	})
+}
+
+// txActionRepo writes actions through the transaction it is bound to, so a
+// rollback removes them.
+type txActionRepo struct {
+	actionRepo
+	db *gorm.DB
+}
+
+func (r *txActionRepo) WithTx(tx *gorm.DB) domain.Repository {
+	return &txActionRepo{db: tx}
+}
+
+func (r *txActionRepo) InsertBillingAction(ctx context.Context, record domain.BillingActionRecord) (bool, error) {
+	err := r.db.WithContext(ctx).Exec(
+		`INSERT INTO billing_operation_actions (id, org_id, entity_type, entity_id, action_type) VALUES (?, ?, ?, ?, ?)`,
+		record.ID, record.OrgID, record.EntityType, record.EntityID, record.ActionType,
+	).Error
+	return err == nil, err
+}
+
+func TestRecordAction_AuditFailureRollsBackAction(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+
+	db := newActionTestDB(t)
+	require.NoError(t, db.Exec(`CREATE TABLE billing_operation_actions (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		action_type TEXT NOT NULL
+	)`).Error)
+
+	auditSvc := new(mockAuditSvc)
+	auditSvc.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("audit store unavailable"))
+
+	svc := &Service{
+		repo:     &txActionRepo{db: db},
+		db:       db,
+		log:      zaptest.NewLogger(t),
+		clock:    clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+		genID:    node,
+		auditSvc: auditSvc,
+	}
+	_, err = svc.RecordAction(ctx, domain.RecordActionRequest{
+		ActionType: domain.ActionTypeFollowUp,
+		EntityType: domain.EntityTypeInvoice,
+		EntityID:   "42",
+	})
+	require.Error(t, err)
+
+	var count int64
+	require.NoError(t, db.Table("billing_operation_actions").Count(&count).Error)
+	assert.Zero(t, count, "the action must roll back with its audit entry")
+}
+
+func TestEmitAudit(t *testing.T) {
+	errAuditDown := errors.New("audit store unavailable")
+	failing := func() *mockAuditSvc {
+		auditSvc := new(mockAuditSvc)
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(errAuditDown)
+		return auditSvc
+	}
+	entry := func(category string) auditEntry {
+		return auditEntry{
+			category:   category,
+			action:     "billing_operations.assignment.released",
+			targetType: "billing_operation_assignment",
+			targetID:   "42",
+		}
+	}
+
+	t.Run("operational audits are best-effort by default", func(t *testing.T) {
+		auditSvc := failing()
+		svc := &Service{log: zaptest.NewLogger(t), auditSvc: auditSvc, auditRetries: 1, auditRetryBackoff: time.Nanosecond}
+		assert.NoError(t, svc.emitAudit(context.Background(), 1, entry(auditCategoryOperational)))
+		auditSvc.AssertNumberOfCalls(t, "AuditLog", 2)
+	})
+
+	t.Run("configured categories are required", func(t *testing.T) {
+		svc := &Service{log: zaptest.NewLogger(t), auditSvc: failing(), auditRetryBackoff: time.Nanosecond}
+		svc.auditRequired = newAuditRequired([]string{" Operational ", "bogus"}, svc.log)
+		assert.Equal(t, map[string]bool{auditCategoryOperational: true}, svc.auditRequired)
+		assert.ErrorIs(t, svc.emitAudit(context.Background(), 1, entry(auditCategoryOperational)), errAuditDown)
+		assert.NoError(t, svc.emitAudit(context.Background(), 1, entry(auditCategoryFinancial)))
+	})
+
+	t.Run("a cancelled context stops retrying", func(t *testing.T) {
+		auditSvc := failing()
+		svc := &Service{log: zaptest.NewLogger(t), auditSvc: auditSvc, auditRetries: 5, auditRetryBackoff: time.Hour}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, svc.emitAudit(ctx, 1, entry(auditCategoryFinancial)), errAuditDown)
+		auditSvc.AssertNumberOfCalls(t, "AuditLog", 1)
+	})
+}
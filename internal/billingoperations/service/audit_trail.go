@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"gorm.io/gorm"
+)
+
+// auditTrailSourceOrder breaks ties between events recorded at the same
+// instant: an action comes before the assignment change and the audit log
+// entry it caused.
+var auditTrailSourceOrder = map[string]int{
+	domain.AuditTrailSourceAction:     0,
+	domain.AuditTrailSourceAssignment: 1,
+	domain.AuditTrailSourcePayment:    2,
+	domain.AuditTrailSourceAuditLog:   3,
+}
+
+// ExportEntityAuditTrail collects everything recorded about an invoice or
+// customer into one ordered trail: billing operation actions, archived ones
+// included, the lifecycle of its assignments, payment events and audit log
+// entries. A customer's trail also covers the actions, assignments and audit
+// log entries of its invoices; those events name the invoice in their
+// details.
+func (s *Service) ExportEntityAuditTrail(ctx context.Context, entityType, entityID string) (domain.EntityAuditTrail, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.EntityAuditTrail{}, domain.ErrInvalidOrganization
+	}
+
+	entityType = strings.TrimSpace(entityType)
+	if entityType != domain.EntityTypeInvoice && entityType != domain.EntityTypeCustomer {
+		return domain.EntityAuditTrail{}, domain.ErrInvalidEntityType
+	}
+	parsedID, err := parseSnowflakeID(entityID)
+	if err != nil {
+		return domain.EntityAuditTrail{}, domain.ErrInvalidEntityID
+	}
+
+	snapshot, err := s.repo.LoadEntitySnapshot(ctx, orgID, entityType, parsedID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.EntityAuditTrail{}, domain.ErrEntityNotFound
+		}
+		return domain.EntityAuditTrail{}, err
+	}
+
+	actions, err := s.repo.ListEntityActions(ctx, orgID, entityType, parsedID)
+	if err != nil {
+		return domain.EntityAuditTrail{}, err
+	}
+	assignments, err := s.repo.ListEntityAssignments(ctx, orgID, entityType, parsedID)
+	if err != nil {
+		return domain.EntityAuditTrail{}, err
+	}
+	payments, err := s.repo.ListEntityPaymentEvents(ctx, orgID, entityType, parsedID)
+	if err != nil {
+		return domain.EntityAuditTrail{}, err
+	}
+	auditLogs, err := s.repo.ListEntityAuditLogs(ctx, orgID, entityType, parsedID)
+	if err != nil {
+		return domain.EntityAuditTrail{}, err
+	}
+
+	events := make([]domain.AuditTrailEvent, 0, len(actions)+4*len(assignments)+len(payments)+len(auditLogs))
+	for _, action := range actions {
+		events = append(events, domain.AuditTrailEvent{
+			OccurredAt: action.CreatedAt.UTC(),
+			Source:     domain.AuditTrailSourceAction,
+			ID:         action.ID.String(),
+			Type:       action.ActionType,
+			ActorType:  action.ActorType,
+			ActorID:    action.ActorID,
+			Details:    recordedOn(map[string]any(action.Metadata), entityType, action.EntityType, action.EntityID),
+		})
+	}
+	for _, assignment := range assignments {
+		for _, event := range assignmentTrailEvents(assignment) {
+			event.Details = recordedOn(event.Details, entityType, assignment.EntityType, assignment.EntityID)
+			events = append(events, event)
+		}
+	}
+	for _, payment := range payments {
+		details := map[string]any{
+			"provider":          payment.Provider,
+			"provider_event_id": payment.ProviderEventID,
+		}
+		if len(payment.Payload) > 0 {
+			details["payload"] = json.RawMessage(payment.Payload)
+		}
+		events = append(events, domain.AuditTrailEvent{
+			OccurredAt: payment.ReceivedAt.UTC(),
+			Source:     domain.AuditTrailSourcePayment,
+			ID:         payment.ID.String(),
+			Type:       payment.EventType,
+			Details:    details,
+		})
+	}
+	for _, entry := range auditLogs {
+		events = append(events, domain.AuditTrailEvent{
+			OccurredAt: entry.CreatedAt.UTC(),
+			Source:     domain.AuditTrailSourceAuditLog,
+			ID:         entry.ID.String(),
+			Type:       entry.Action,
+			ActorType:  entry.ActorType,
+			ActorID:    entry.ActorID,
+			Details: map[string]any{
+				"target_type": entry.TargetType,
+				"target_id":   entry.TargetID,
+				"metadata":    map[string]any(entry.Metadata),
+			},
+		})
+	}
+	sortAuditTrail(events)
+
+	if err := s.auditRead(ctx, orgID, reportEntityAuditTrail, map[string]any{
+		"entity_type": entityType,
+		"entity_id":   parsedID.String(),
+	}); err != nil {
+		return domain.EntityAuditTrail{}, err
+	}
+
+	return domain.EntityAuditTrail{
+		EntityType:  entityType,
+		EntityID:    parsedID.String(),
+		GeneratedAt: s.clock.Now().UTC(),
+		Entity:      snapshot,
+		Events:      events,
+	}, nil
+}
+
+// recordedOn adds the invoice an event was recorded on to its details when
+// the event is in its customer's trail.
+func recordedOn(details map[string]any, trailType, entityType string, entityID snowflake.ID) map[string]any {
+	if trailType != domain.EntityTypeCustomer || entityType != domain.EntityTypeInvoice {
+		return details
+	}
+	out := make(map[string]any, len(details)+1)
+	maps.Copy(out, details)
+	out["invoice_id"] = entityID.String()
+	return out
+}
+
+// assignmentTrailEvents expands an assignment row into one event per
+// lifecycle timestamp it carries.
+func assignmentTrailEvents(a domain.BillingAssignmentRecord) []domain.AuditTrailEvent {
+	id := a.ID.String()
+	events := []domain.AuditTrailEvent{{
+		OccurredAt: a.AssignedAt.UTC(),
+		Source:     domain.AuditTrailSourceAssignment,
+		ID:         id,
+		Type:       domain.AssignmentEventAssigned,
+		ActorType:  actorTypeFor(a.AssignedTo),
+		ActorID:    a.AssignedTo,
+		Details:    map[string]any{"expires_at": a.AssignmentExpiresAt.UTC()},
+	}}
+	if a.ReleasedAt.Valid {
+		events = append(events, domain.AuditTrailEvent{
+			OccurredAt: a.ReleasedAt.Time.UTC(),
+			Source:     domain.AuditTrailSourceAssignment,
+			ID:         id,
+			Type:       domain.AssignmentEventReleased,
+			ActorType:  actorTypeFor(a.ReleasedBy.String),
+			ActorID:    a.ReleasedBy.String,
+			Details:    map[string]any{"reason": a.ReleaseReason.String},
+		})
+	}
+	if a.BreachedAt.Valid {
+		events = append(events, domain.AuditTrailEvent{
+			OccurredAt: a.BreachedAt.Time.UTC(),
+			Source:     domain.AuditTrailSourceAssignment,
+			ID:         id,
+			Type:       domain.AssignmentEventBreached,
+			ActorType:  "system",
+			ActorID:    domain.SystemActorID,
+			Details:    map[string]any{"breach_level": a.BreachLevel.String},
+		})
+	}
+	if a.ResolvedAt.Valid {
+		events = append(events, domain.AuditTrailEvent{
+			OccurredAt: a.ResolvedAt.Time.UTC(),
+			Source:     domain.AuditTrailSourceAssignment,
+			ID:         id,
+			Type:       domain.AssignmentEventResolved,
+			ActorType:  actorTypeFor(a.ResolvedBy.String),
+			ActorID:    a.ResolvedBy.String,
+			Details:    map[string]any{"status": a.Status},
+		})
+	}
+	return events
+}
+
+func actorTypeFor(userID string) string {
+	if domain.IsSystemActor(userID) {
+		return "system"
+	}
+	return "user"
+}
+
+// sortAuditTrail orders events by time, then source. Events from the same
+// source at the same instant keep the order the repository returned them in.
+func sortAuditTrail(events []domain.AuditTrailEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if !a.OccurredAt.Equal(b.OccurredAt) {
+			return a.OccurredAt.Before(b.OccurredAt)
+		}
+		return auditTrailSourceOrder[a.Source] < auditTrailSourceOrder[b.Source]
+	})
+}
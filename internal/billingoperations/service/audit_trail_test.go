@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type auditTrailRepo struct {
+	domain.Repository
+	snapshot    map[string]any
+	actions     []domain.BillingActionRecord
+	assignments []domain.BillingAssignmentRecord
+	payments    []domain.EntityPaymentEventRow
+	auditLogs   []domain.EntityAuditLogRow
+}
+
+func (r *auditTrailRepo) LoadEntitySnapshot(context.Context, snowflake.ID, string, snowflake.ID) (map[string]any, error) {
+	if r.snapshot == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.snapshot, nil
+}
+
+func (r *auditTrailRepo) ListEntityActions(context.Context, snowflake.ID, string, snowflake.ID) ([]domain.BillingActionRecord, error) {
+	return r.actions, nil
+}
+
+func (r *auditTrailRepo) FindEntityAssignment(context.Context, snowflake.ID, string, snowflake.ID) (*domain.BillingAssignmentRecord, error) {
+	if len(r.assignments) == 0 {
+		return nil, nil
+	}
+	return &r.assignments[0], nil
+}
+
+func (r *auditTrailRepo) ListEntityAssignments(context.Context, snowflake.ID, string, snowflake.ID) ([]domain.BillingAssignmentRecord, error) {
+	return r.assignments, nil
+}
+
+func (r *auditTrailRepo) ListEntityPaymentEvents(context.Context, snowflake.ID, string, snowflake.ID) ([]domain.EntityPaymentEventRow, error) {
+	return r.payments, nil
+}
+
+func (r *auditTrailRepo) ListEntityAuditLogs(context.Context, snowflake.ID, string, snowflake.ID) ([]domain.EntityAuditLogRow, error) {
+	return r.auditLogs, nil
+}
+
+func TestExportEntityAuditTrail(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return now.Add(time.Duration(minutes) * time.Minute) }
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	newService := func(t *testing.T, repo *auditTrailRepo) *Service {
+		return &Service{
+			repo:  repo,
+			log:   zaptest.NewLogger(t),
+			clock: clock.NewFakeClock(now),
+		}
+	}
+
+	repo := &auditTrailRepo{
+		snapshot: map[string]any{"invoice_id": "77", "status": "FINALIZED"},
+		actions: []domain.BillingActionRecord{
+			{ID: 11, ActionType: domain.ActionTypeClaim, ActorType: "user", ActorID: "agent", CreatedAt: at(10)},
+			{ID: 12, ActionType: domain.ActionTypeFollowUp, ActorType: "user", ActorID: "agent", CreatedAt: at(40), Metadata: datatypes.JSONMap{"note": "called"}},
+		},
+		assignments: []domain.BillingAssignmentRecord{{
+			ID:                  21,
+			EntityType:          domain.EntityTypeInvoice,
+			EntityID:            77,
+			AssignedTo:          "agent",
+			AssignedAt:          at(10),
+			AssignmentExpiresAt: at(70),
+			Status:              domain.AssignmentStatusEscalated,
+			BreachedAt:          sql.NullTime{Time: at(90), Valid: true},
+			BreachLevel:         sql.NullString{String: "idle_action", Valid: true},
+			ResolvedAt:          sql.NullTime{Time: at(90), Valid: true},
+			ResolvedBy:          sql.NullString{String: domain.SystemActorID, Valid: true},
+		}},
+		payments: []domain.EntityPaymentEventRow{
+			{ID: 31, Provider: "stripe", ProviderEventID: "evt_1", EventType: "payment_failed", Payload: datatypes.JSON(`{"id":"evt_1"}`), ReceivedAt: at(5)},
+			{ID: 32, Provider: "stripe", ProviderEventID: "evt_2", EventType: "payment_succeeded", ReceivedAt: at(120)},
+		},
+		auditLogs: []domain.EntityAuditLogRow{
+			{ID: 41, ActorType: "user", ActorID: "agent", Action: "billing_operations.action.claim", TargetType: "billing_operation_action", TargetID: "11", CreatedAt: at(10)},
+			{ID: 42, ActorType: "system", Action: "invoice.finalize", TargetType: "invoice", TargetID: "77", CreatedAt: at(0)},
+		},
+	}
+
+	trail, err := newService(t, repo).ExportEntityAuditTrail(ctx, domain.EntityTypeInvoice, "77")
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.EntityTypeInvoice, trail.EntityType)
+	assert.Equal(t, "77", trail.EntityID)
+	assert.Equal(t, now, trail.GeneratedAt)
+	assert.Equal(t, repo.snapshot, trail.Entity)
+
+	type step struct{ source, id, typ string }
+	got := make([]step, 0, len(trail.Events))
+	for i, event := range trail.Events {
+		got = append(got, step{event.Source, event.ID, event.Type})
+		if i > 0 {
+			assert.False(t, event.OccurredAt.Before(trail.Events[i-1].OccurredAt), "event %d out of order", i)
+		}
+	}
+	assert.Equal(t, []step{
+		{domain.AuditTrailSourceAuditLog, "42", "invoice.finalize"},
+		{domain.AuditTrailSourcePayment, "31", "payment_failed"},
+		{domain.AuditTrailSourceAction, "11", domain.ActionTypeClaim},
+		{domain.AuditTrailSourceAssignment, "21", domain.AssignmentEventAssigned},
+		{domain.AuditTrailSourceAuditLog, "41", "billing_operations.action.claim"},
+		{domain.AuditTrailSourceAction, "12", domain.ActionTypeFollowUp},
+		{domain.AuditTrailSourceAssignment, "21", domain.AssignmentEventBreached},
+		{domain.AuditTrailSourceAssignment, "21", domain.AssignmentEventResolved},
+		{domain.AuditTrailSourcePayment, "32", "payment_succeeded"},
+	}, got)
+
+	followUp := trail.Events[5]
+	assert.Equal(t, "agent", followUp.ActorID)
+	assert.Equal(t, "called", followUp.Details["note"])
+	resolved := trail.Events[7]
+	assert.Equal(t, "system", resolved.ActorType)
+	assert.Equal(t, domain.AssignmentStatusEscalated, resolved.Details["status"])
+	assert.Contains(t, trail.Events[1].Details, "payload")
+
+	t.Run("customer trail names the invoice", func(t *testing.T) {
+		customerRepo := &auditTrailRepo{
+			snapshot: map[string]any{"customer_id": "5"},
+			actions: []domain.BillingActionRecord{
+				{ID: 13, EntityType: domain.EntityTypeCustomer, EntityID: 5, ActionType: domain.ActionTypeFollowUp, CreatedAt: at(1)},
+				{ID: 14, EntityType: domain.EntityTypeInvoice, EntityID: 77, ActionType: domain.ActionTypeFollowUp, CreatedAt: at(2), Metadata: datatypes.JSONMap{"note": "called"}},
+			},
+			assignments: repo.assignments,
+		}
+		trail, err := newService(t, customerRepo).ExportEntityAuditTrail(ctx, domain.EntityTypeCustomer, "5")
+		require.NoError(t, err)
+		require.Len(t, trail.Events, 5)
+		assert.NotContains(t, trail.Events[0].Details, "invoice_id")
+		assert.Equal(t, map[string]any{"note": "called", "invoice_id": "77"}, trail.Events[1].Details)
+		assert.Equal(t, "77", trail.Events[2].Details["invoice_id"])
+		assert.NotContains(t, customerRepo.actions[1].Metadata, "invoice_id")
+	})
+
+	t.Run("unknown entity", func(t *testing.T) {
+		_, err := newService(t, &auditTrailRepo{}).ExportEntityAuditTrail(ctx, domain.EntityTypeCustomer, "77")
+		assert.ErrorIs(t, err, domain.ErrEntityNotFound)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		svc := newService(t, repo)
+		_, err := svc.ExportEntityAuditTrail(ctx, "subscription", "77")
+		assert.ErrorIs(t, err, domain.ErrInvalidEntityType)
+		_, err = svc.ExportEntityAuditTrail(ctx, domain.EntityTypeInvoice, "abc")
+		assert.ErrorIs(t, err, domain.ErrInvalidEntityID)
+	})
+}
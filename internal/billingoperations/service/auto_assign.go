@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+)
+
+// AutoAssignInbox claims the top inbox items, in inbox order, on behalf of
+// assignees. Each assignee only receives work while they hold fewer than
+// maxPerAgent active assignments, counting what they already held. Claims
+// go through the same transaction and snapshot capture as a self-claim and
+// are recorded as taken by the auto assigner; items claimed by someone else
+// during the run are skipped.
+func (s *Service) AutoAssignInbox(ctx context.Context, strategy string, assignees []string, maxPerAgent int) (domain.AutoAssignResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.AutoAssignResponse{}, domain.ErrInvalidOrganization
+	}
+
+	strategy = strings.TrimSpace(strategy)
+	if strategy == "" {
+		strategy = domain.AutoAssignStrategyRoundRobin
+	}
+	if strategy != domain.AutoAssignStrategyRoundRobin && strategy != domain.AutoAssignStrategyLeastLoaded {
+		return domain.AutoAssignResponse{}, domain.ErrInvalidStrategy
+	}
+	if maxPerAgent <= 0 {
+		return domain.AutoAssignResponse{}, domain.ErrInvalidMaxPerAgent
+	}
+
+	agents := make([]string, 0, len(assignees))
+	agentIDs := make([]snowflake.ID, 0, len(assignees))
+	seen := make(map[string]struct{}, len(assignees))
+	for _, assignee := range assignees {
+		assignee = strings.TrimSpace(assignee)
+		if assignee == "" || domain.IsSystemActor(assignee) {
+			return domain.AutoAssignResponse{}, domain.ErrInvalidAssignee
+		}
+		userID, err := parseSnowflakeID(assignee)
+		if err != nil || userID <= 0 {
+			return domain.AutoAssignResponse{}, domain.ErrInvalidAssignee
+		}
+		assignee = userID.String()
+		if _, ok := seen[assignee]; ok {
+			continue
+		}
+		seen[assignee] = struct{}{}
+		agents = append(agents, assignee)
+		agentIDs = append(agentIDs, userID)
+	}
+	if len(agents) == 0 {
+		return domain.AutoAssignResponse{}, domain.ErrInvalidAssignee
+	}
+	// Every assignee must belong to the org, or work would be handed to
+	// someone who cannot see it.
+	members, err := s.repo.ListOrgMemberIDs(ctx, orgID, agentIDs)
+	if err != nil {
+		return domain.AutoAssignResponse{}, err
+	}
+	if len(members) != len(agentIDs) {
+		return domain.AutoAssignResponse{}, domain.ErrInvalidAssignee
+	}
+
+	now := s.clock.Now().UTC()
+	stats, err := s.repo.GetTeamViewStats(ctx, orgID, nil, now)
+	if err != nil {
+		return domain.AutoAssignResponse{}, err
+	}
+	load := make(map[string]int, len(agents))
+	for _, row := range stats {
+		if _, ok := seen[row.UserID]; ok {
+			load[row.UserID] = row.ActiveAssignments
+		}
+	}
+
+	capacity := 0
+	for _, agent := range agents {
+		if free := maxPerAgent - load[agent]; free > 0 {
+			capacity += free
+		}
+	}
+	resp := domain.AutoAssignResponse{Assigned: []domain.Assignment{}}
+	if capacity == 0 {
+		return resp, nil
+	}
+
+	rows, err := s.agingRepo().ListInboxItems(ctx, orgID, s.inboxFilter(domain.InboxRequest{}), capacity, now)
+	if err != nil {
+		return domain.AutoAssignResponse{}, err
+	}
+
+	next := 0
+	for _, row := range rows {
+		agent := pickAutoAssignee(strategy, agents, load, maxPerAgent, &next)
+		if agent == "" {
+			break
+		}
+		entityID, err := parseSnowflakeID(row.EntityID)
+		if err != nil {
+			continue
+		}
+
+		claimed, err := s.claimForAssignee(ctx, orgID, row.EntityType, entityID, agent, defaultAssignmentTTLMinutes, domain.SystemActorID, domain.AutoAssignerActorID)
+		if errors.Is(err, domain.ErrAssignmentConflict) || errors.Is(err, domain.ErrAssignmentModified) {
+			resp.Skipped++
+			continue
+		}
+		if err != nil {
+			return resp, err
+		}
+		load[agent]++
+		resp.Assigned = append(resp.Assigned, claimed.Assignment)
+	}
+
+	s.log.Info("auto-assigned inbox items",
+		zap.String("org_id", orgID.String()),
+		zap.String("strategy", strategy),
+		zap.Int("assigned", len(resp.Assigned)),
+		zap.Int("skipped", resp.Skipped),
+	)
+	return resp, nil
+}
+
+// pickAutoAssignee returns the next assignee with spare capacity, or "" when
+// everyone is full. Round robin continues after the agent picked last time
+// (tracked in next); least loaded takes the agent with the fewest active
+// assignments, earlier agents winning ties.
+func pickAutoAssignee(strategy string, agents []string, load map[string]int, maxPerAgent int, next *int) string {
+	if strategy == domain.AutoAssignStrategyLeastLoaded {
+		picked := ""
+		for _, agent := range agents {
+			if load[agent] >= maxPerAgent {
+				continue
+			}
+			if picked == "" || load[agent] < load[picked] {
+				picked = agent
+			}
+		}
+		return picked
+	}
+
+	for i := 0; i < len(agents); i++ {
+		agent := agents[(*next+i)%len(agents)]
+		if load[agent] < maxPerAgent {
+			*next = (*next + i + 1) % len(agents)
+			return agent
+		}
+	}
+	return ""
+}
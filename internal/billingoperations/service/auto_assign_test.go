@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// autoAssignRepo serves the inbox and team load from memory and leaves the
+// claim transaction to the real repository.
+type autoAssignRepo struct {
+	domain.Repository
+	inbox []domain.InboxRow
+	load  []domain.TeamRow
+}
+
+func (r *autoAssignRepo) WithDueDatePolicy(domain.DueDatePolicy) domain.Repository {
+	return r
+}
+
+func (r *autoAssignRepo) ListInboxItems(_ context.Context, _ snowflake.ID, _ domain.InboxFilter, limit int, _ time.Time) ([]domain.InboxRow, error) {
+	return r.inbox[:min(limit, len(r.inbox))], nil
+}
+
+func (r *autoAssignRepo) GetTeamViewStats(context.Context, snowflake.ID, []string, time.Time) ([]domain.TeamRow, error) {
+	return r.load, nil
+}
+
+func TestAutoAssignInbox(t *testing.T) {
+	node, _ := snowflake.NewNode(1)
+	orgID := node.Generate()
+	// alice and bob are members of the org; carol only claims by hand.
+	const alice, bob, carol = "1001", "1002", "1003"
+	newService := func(t *testing.T, repo *autoAssignRepo) (*Service, *gorm.DB) {
+		db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+		require.NoError(t, err)
+		for _, stmt := range []string{
+			`CREATE TABLE billing_operation_assignments (
+				id BIGINT PRIMARY KEY,
+				org_id BIGINT NOT NULL,
+				entity_type TEXT NOT NULL,
+				entity_id BIGINT NOT NULL,
+				assigned_to TEXT NOT NULL,
+				assigned_at TIMESTAMP NOT NULL,
+				assignment_expires_at TIMESTAMP NOT NULL,
+				status TEXT NOT NULL DEFAULT 'assigned',
+				released_at TIMESTAMP,
+				released_by TEXT,
+				release_reason TEXT,
+				last_action_at TIMESTAMP,
+				snapshot_metadata TEXT,
+				handed_off_at TIMESTAMP,
+				handed_off_by TEXT,
+				handoff_note TEXT,
+				resolved_at TIMESTAMP,
+				resolved_by TEXT,
+				resolution_snapshot TEXT,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE organization_members (org_id BIGINT NOT NULL, user_id BIGINT NOT NULL)`,
+			`CREATE UNIQUE INDEX ux_billing_assignments_entity ON billing_operation_assignments(org_id, entity_type, entity_id)`,
+			`CREATE TABLE billing_operation_actions (
+				id BIGINT PRIMARY KEY,
+				org_id BIGINT NOT NULL,
+				entity_type TEXT NOT NULL,
+				entity_id BIGINT NOT NULL,
+				action_type TEXT NOT NULL,
+				action_bucket TIMESTAMP NOT NULL,
+				idempotency_key TEXT,
+				metadata TEXT,
+				actor_type TEXT,
+				actor_id TEXT,
+				created_at TIMESTAMP NOT NULL
+			)`,
+		} {
+			require.NoError(t, db.Exec(stmt).Error)
+		}
+		for _, member := range []string{alice, bob} {
+			require.NoError(t, db.Exec(`INSERT INTO organization_members (org_id, user_id) VALUES (?, ?)`, orgID, member).Error)
+		}
+
+		svc := NewService(Params{
+			DB:    db,
+			Log:   zap.NewNop(),
+			Clock: clock.NewFakeClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)),
+			GenID: node,
+			Cfg:   config.Config{},
+		}).(*Service)
+		svc.billingCfg = config.NewStaticBillingConfigHolder(config.DefaultBillingConfig())
+		repo.Repository = svc.repo
+		svc.repo = repo
+		return svc, db
+	}
+	inbox := func(n int) []domain.InboxRow {
+		rows := make([]domain.InboxRow, n)
+		for i := range rows {
+			rows[i] = domain.InboxRow{EntityType: domain.EntityTypeInvoice, EntityID: node.Generate().String()}
+		}
+		return rows
+	}
+	assignees := func(resp domain.AutoAssignResponse) []string {
+		var out []string
+		for _, a := range resp.Assigned {
+			out = append(out, a.AssignedTo)
+		}
+		return out
+	}
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	t.Run("round robin stops at each agent's capacity", func(t *testing.T) {
+		repo := &autoAssignRepo{inbox: inbox(5), load: []domain.TeamRow{{UserID: bob, ActiveAssignments: 1}}}
+		svc, db := newService(t, repo)
+
+		resp, err := svc.AutoAssignInbox(ctx, domain.AutoAssignStrategyRoundRobin, []string{alice, bob}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{alice, bob, alice}, assignees(resp))
+		assert.Equal(t, repo.inbox[0].EntityID, resp.Assigned[0].EntityID)
+
+		var actions []domain.BillingActionRecord
+		require.NoError(t, db.Table("billing_operation_actions").Where("action_type = ?", domain.ActionTypeClaim).Find(&actions).Error)
+		require.Len(t, actions, 3)
+		for _, action := range actions {
+			assert.Equal(t, domain.SystemActorID, action.ActorType)
+			assert.Equal(t, domain.AutoAssignerActorID, action.ActorID)
+		}
+	})
+
+	t.Run("least loaded evens out the team", func(t *testing.T) {
+		repo := &autoAssignRepo{inbox: inbox(3), load: []domain.TeamRow{{UserID: alice, ActiveAssignments: 1}}}
+		svc, _ := newService(t, repo)
+
+		resp, err := svc.AutoAssignInbox(ctx, domain.AutoAssignStrategyLeastLoaded, []string{alice, bob}, 5)
+		require.NoError(t, err)
+		assert.Equal(t, []string{bob, alice, bob}, assignees(resp))
+	})
+
+	t.Run("skips items claimed by someone else", func(t *testing.T) {
+		repo := &autoAssignRepo{inbox: inbox(2)}
+		svc, _ := newService(t, repo)
+		_, err := svc.ClaimAssignment(ctx, domain.ClaimAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   repo.inbox[0].EntityID,
+			AssignedTo: carol,
+		})
+		require.NoError(t, err)
+
+		resp, err := svc.AutoAssignInbox(ctx, "", []string{alice}, 5)
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Skipped)
+		require.Len(t, resp.Assigned, 1)
+		assert.Equal(t, repo.inbox[1].EntityID, resp.Assigned[0].EntityID)
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		svc, _ := newService(t, &autoAssignRepo{})
+
+		_, err := svc.AutoAssignInbox(ctx, "random", []string{alice}, 1)
+		assert.ErrorIs(t, err, domain.ErrInvalidStrategy)
+		_, err = svc.AutoAssignInbox(ctx, "", []string{alice}, 0)
+		assert.ErrorIs(t, err, domain.ErrInvalidMaxPerAgent)
+		_, err = svc.AutoAssignInbox(ctx, "", []string{" "}, 1)
+		assert.ErrorIs(t, err, domain.ErrInvalidAssignee)
+		_, err = svc.AutoAssignInbox(ctx, "", []string{domain.AutoAssignerActorID}, 1)
+		assert.ErrorIs(t, err, domain.ErrInvalidAssignee)
+		_, err = svc.AutoAssignInbox(ctx, "", []string{"alice"}, 1)
+		assert.ErrorIs(t, err, domain.ErrInvalidAssignee)
+		_, err = svc.AutoAssignInbox(ctx, "", []string{alice, carol}, 1)
+		assert.ErrorIs(t, err, domain.ErrInvalidAssignee, "carol is not a member of the org")
+		_, err = svc.AutoAssignInbox(context.Background(), "", []string{alice}, 1)
+		assert.ErrorIs(t, err, domain.ErrInvalidOrganization)
+	})
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/config"
+	obstracing "github.com/smallbiznis/railzway/internal/observability/tracing"
+	"go.uber.org/zap"
+)
+
+const breachWebhookTimeout = 10 * time.Second
+
+// WebhookBreachNotifier POSTs each breach as JSON to a fixed URL.
+type WebhookBreachNotifier struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhookBreachNotifier returns a notifier posting to url.
+func NewWebhookBreachNotifier(url string) *WebhookBreachNotifier {
+	return &WebhookBreachNotifier{
+		url: url,
+		httpClient: obstracing.WrapHTTPClient(&http.Client{
+			Timeout: breachWebhookTimeout,
+		}),
+	}
+}
+
+// NewBreachNotifier provides the webhook notifier when
+// BILLING_OPS_BREACH_WEBHOOK_URL is set, and nil otherwise.
+func NewBreachNotifier(cfg config.Config) domain.BreachNotifier {
+	url := strings.TrimSpace(cfg.BillingOpsBreachWebhookURL)
+	if url == "" {
+		return nil
+	}
+	return NewWebhookBreachNotifier(url)
+}
+
+// notifyBreach forwards an escalation to the configured notifier. Failures
+// are logged only; the escalation is already committed.
+func (s *Service) notifyBreach(ctx context.Context, breach domain.BreachEvent) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.NotifyBreach(ctx, breach); err != nil {
+		s.log.Warn("failed to notify sla breach",
+			zap.String("assignment_id", breach.AssignmentID),
+			zap.String("breach_type", breach.BreachType),
+			zap.Error(err))
+	}
+}
+
+func (n *WebhookBreachNotifier) NotifyBreach(ctx context.Context, breach domain.BreachEvent) error {
+	payload, err := json.Marshal(breach)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("breach webhook returned %s", resp.Status)
+	}
+	return nil
+}
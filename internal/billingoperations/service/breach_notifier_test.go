@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/billingoperations/repository"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingBreachNotifier struct {
+	breaches []domain.BreachEvent
+	err      error
+}
+
+func (n *recordingBreachNotifier) NotifyBreach(_ context.Context, breach domain.BreachEvent) error {
+	n.breaches = append(n.breaches, breach)
+	return n.err
+}
+
+func TestWebhookBreachNotifier(t *testing.T) {
+	breach := domain.BreachEvent{
+		OrgID:        "1",
+		AssignmentID: "2",
+		EntityType:   domain.EntityTypeInvoice,
+		EntityID:     "3",
+		AssignedTo:   "agent",
+		BreachType:   domain.SLABreachIdleAction,
+		MinutesIdle:  75,
+		BreachedAt:   time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("posts the breach as json", func(t *testing.T) {
+		var received domain.BreachEvent
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		require.NoError(t, NewWebhookBreachNotifier(srv.URL).NotifyBreach(context.Background(), breach))
+		assert.Equal(t, breach, received)
+	})
+
+	t.Run("non-2xx is an error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		assert.Error(t, NewWebhookBreachNotifier(srv.URL).NotifyBreach(context.Background(), breach))
+	})
+
+	t.Run("disabled without a url", func(t *testing.T) {
+		assert.Nil(t, NewBreachNotifier(config.Config{}))
+	})
+}
+
+func TestEvaluateSLAs_NotifiesBreach(t *testing.T) {
+	db := newSLATestDB(t)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	assignedAt := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	now := assignedAt.Add(2 * time.Hour)
+	require.NoError(t, db.Exec(
+		`INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, last_action_at, created_at, updated_at)
+		 VALUES (10, 1, 'invoice', 20, 'agent', ?, ?, ?, ?, ?, ?)`,
+		assignedAt, assignedAt.Add(24*time.Hour), domain.AssignmentStatusInProgress, now.Add(-75*time.Minute), assignedAt, assignedAt,
+	).Error)
+	require.NoError(t, db.Exec(
+		`INSERT INTO billing_operation_actions (id, org_id, entity_type, entity_id, action_type, action_bucket, metadata, actor_type, actor_id, created_at)
+		 VALUES (30, 1, 'invoice', 20, ?, ?, '{}', 'user', 'agent', ?)`,
+		domain.ActionTypeContact, assignedAt.Add(45*time.Minute), assignedAt.Add(45*time.Minute),
+	).Error)
+
+	// A failing notifier must not undo the escalation.
+	notifier := &recordingBreachNotifier{err: errors.New("webhook down")}
+	svc := &Service{
+		db:         db,
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(now),
+		genID:      node,
+		repo:       repository.NewRepository(db),
+		notifier:   notifier,
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+
+	require.Len(t, notifier.breaches, 1)
+	assert.Equal(t, domain.BreachEvent{
+		OrgID:        "1",
+		AssignmentID: "10",
+		EntityType:   domain.EntityTypeInvoice,
+		EntityID:     "20",
+		AssignedTo:   "agent",
+		BreachType:   domain.SLABreachIdleAction,
+		MinutesIdle:  75,
+		BreachedAt:   now,
+	}, notifier.breaches[0])
+
+	var status string
+	require.NoError(t, db.Raw(`SELECT status FROM billing_operation_assignments WHERE id = 10`).Scan(&status).Error)
+	assert.Equal(t, domain.AssignmentStatusEscalated, status)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type queueInvoice struct {
+	customerID   snowflake.ID
+	customerName string
+	amount       int64
+	dueAt        time.Time
+	lastEmail    string
+	lastEmailAt  time.Time
+}
+
+// collectionQueueRepo backs GetOperations with a fixed set of unpaid invoices
+// and applies the collection queue filter with the same rules as the SQL.
+type collectionQueueRepo struct {
+	domain.Repository
+	invoices []queueInvoice
+	prefs    domain.CollectionQueueFilter
+	filter   domain.CollectionQueueFilter
+}
+
+func (r *collectionQueueRepo) FetchCollectionQueueFilter(context.Context, snowflake.ID) (domain.CollectionQueueFilter, error) {
+	return r.prefs, nil
+}
+
+func (r *collectionQueueRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *collectionQueueRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *collectionQueueRepo) LoadActionSummary(context.Context, snowflake.ID, string, []string, bool, time.Time) (domain.ActionSummaryRow, error) {
+	return domain.ActionSummaryRow{}, nil
+}
+
+func (r *collectionQueueRepo) ListOverdueInvoices(context.Context, snowflake.ID, time.Time, pagination.Pagination) ([]domain.OverdueInvoiceRow, pagination.PageInfo, error) {
+	return nil, pagination.PageInfo{}, nil
+}
+
+func (r *collectionQueueRepo) ListFailedPaymentActions(context.Context, snowflake.ID, []string, time.Time, int) ([]domain.FailedPaymentActionRow, error) {
+	return nil, nil
+}
+
+func (r *collectionQueueRepo) ListPaymentIssues(context.Context, snowflake.ID, []string, bool, time.Time, int) ([]domain.PaymentIssueRow, error) {
+	return nil, nil
+}
+
+func (r *collectionQueueRepo) ListCollectionQueue(_ context.Context, _ snowflake.ID, now time.Time, filter domain.CollectionQueueFilter, _ pagination.Pagination) ([]domain.CollectionQueueRow, pagination.PageInfo, error) {
+	r.filter = filter
+	rows := make([]domain.CollectionQueueRow, 0, len(r.invoices))
+	for _, inv := range r.invoices {
+		if filter.OverdueOnly && !inv.dueAt.Before(now) {
+			continue
+		}
+		if filter.MaxAgeDays > 0 && inv.dueAt.Before(now.AddDate(0, 0, -filter.MaxAgeDays)) {
+			continue
+		}
+		rows = append(rows, domain.CollectionQueueRow{
+			CustomerID:      inv.customerID,
+			CustomerName:    inv.customerName,
+			Outstanding:     inv.amount,
+			OldestUnpaidAt:  sql.NullTime{Time: inv.dueAt, Valid: true},
+			LastEmailStatus: sql.NullString{String: inv.lastEmail, Valid: inv.lastEmail != ""},
+			LastEmailAt:     sql.NullTime{Time: inv.lastEmailAt, Valid: inv.lastEmail != ""},
+		})
+	}
+	return rows, pagination.PageInfo{}, nil
+}
+
+func TestGetOperations_CollectionQueueFilter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	invoices := []queueInvoice{
+		{customerID: 1, customerName: "Current Co", amount: 10_000, dueAt: now.AddDate(0, 0, 10)},
+		{customerID: 2, customerName: "Late Co", amount: 20_000, dueAt: now.AddDate(0, 0, -20)},
+		{customerID: 3, customerName: "Ancient Co", amount: 30_000, dueAt: now.AddDate(0, 0, -400)},
+	}
+
+	cases := []struct {
+		name  string
+		queue domain.CollectionQueueFilter
+		want  []string
+	}{
+		{name: "default includes current balances", want: []string{"Current Co", "Late Co", "Ancient Co"}},
+		{name: "overdue only excludes current balances", queue: domain.CollectionQueueFilter{OverdueOnly: true}, want: []string{"Late Co", "Ancient Co"}},
+		{name: "max age drops stale invoices", queue: domain.CollectionQueueFilter{OverdueOnly: true, MaxAgeDays: 365}, want: []string{"Late Co"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &collectionQueueRepo{invoices: invoices, prefs: tc.queue}
+			svc := &Service{
+				repo:       repo,
+				log:        zaptest.NewLogger(t),
+				clock:      clock.NewFakeClock(now),
+				billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+			}
+
+			resp, err := svc.GetOperations(ctx, 10)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.queue, repo.filter, "the org's filter is applied")
+			names := make([]string, 0, len(resp.CollectionQueue))
+			for _, entry := range resp.CollectionQueue {
+				names = append(names, entry.CustomerName)
+			}
+			assert.Equal(t, tc.want, names)
+		})
+	}
+}
+
+func TestGetOperations_CollectionQueueLastEmailBounced(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	bouncedAt := now.Add(-2 * time.Hour)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	repo := &collectionQueueRepo{invoices: []queueInvoice{
+		{customerID: 1, customerName: "Bounced Co", amount: 30_000, dueAt: now.AddDate(0, 0, -5), lastEmail: "bounced", lastEmailAt: bouncedAt},
+		{customerID: 2, customerName: "Delivered Co", amount: 20_000, dueAt: now.AddDate(0, 0, -5), lastEmail: "delivered", lastEmailAt: bouncedAt},
+		{customerID: 3, customerName: "Unmailed Co", amount: 10_000, dueAt: now.AddDate(0, 0, -5)},
+	}}
+	svc := &Service{
+		repo:       repo,
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(now),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+
+	resp, err := svc.GetOperations(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, resp.CollectionQueue, 3)
+
+	assert.True(t, resp.CollectionQueue[0].LastEmailBounced)
+	require.NotNil(t, resp.CollectionQueue[0].LastEmailBouncedAt)
+	assert.True(t, resp.CollectionQueue[0].LastEmailBouncedAt.Equal(bouncedAt))
+	for _, entry := range resp.CollectionQueue[1:] {
+		assert.False(t, entry.LastEmailBounced, entry.CustomerName)
+		assert.Nil(t, entry.LastEmailBouncedAt, entry.CustomerName)
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/smallbiznis/railzway/pkg/money"
+	"go.uber.org/zap"
+)
+
+// currencyExponent returns the minor-unit exponent clients use to format
+// amounts in currency, the same one invoices are billed and rendered with
+// (money.MinorUnits). Unknown codes fall back to the default exponent and
+// are logged so the table can be extended; an empty currency (an org
+// without one configured) falls back silently.
+func (s *Service) currencyExponent(currency string) int {
+	exponent, ok := money.LookupMinorUnits(currency)
+	if !ok && currency != "" {
+		s.log.Warn("unknown currency, using default exponent",
+			zap.String("currency", currency),
+			zap.Int("exponent", exponent),
+		)
+	}
+	return exponent
+}
+
+// itemCurrency returns the currency of a listed item: the currency of the
+// invoices it was computed from, or orgCurrency for rows that carry none.
+func itemCurrency(rowCurrency, orgCurrency string) string {
+	if currency := strings.ToUpper(strings.TrimSpace(rowCurrency)); currency != "" {
+		return currency
+	}
+	return orgCurrency
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCurrencyExponent(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	svc := &Service{log: zap.New(core)}
+
+	assert.Equal(t, 0, svc.currencyExponent("JPY"))
+	assert.Equal(t, 2, svc.currencyExponent("usd"))
+	assert.Equal(t, 3, svc.currencyExponent("KWD"))
+	assert.Equal(t, 0, svc.currencyExponent("IDR"), "IDR is billed in whole rupiah")
+	assert.Equal(t, 0, logs.Len())
+
+	assert.Equal(t, 2, svc.currencyExponent(""))
+	assert.Equal(t, 0, logs.Len(), "an unset currency is not worth a warning")
+
+	assert.Equal(t, 2, svc.currencyExponent("XYZ"))
+	if assert.Equal(t, 1, logs.Len()) {
+		assert.Equal(t, "XYZ", logs.All()[0].ContextMap()["currency"])
+	}
+}
+
+// mixedCurrencyRepo lists overdue invoices billed in different currencies.
+type mixedCurrencyRepo struct {
+	domain.Repository
+}
+
+func (r *mixedCurrencyRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *mixedCurrencyRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *mixedCurrencyRepo) ListOverdueInvoices(_ context.Context, _ snowflake.ID, now time.Time, _ pagination.Pagination) ([]domain.OverdueInvoiceRow, pagination.PageInfo, error) {
+	dueAt := now.AddDate(0, 0, -10)
+	return []domain.OverdueInvoiceRow{
+		{InvoiceID: 1, AmountDue: 5_000, Currency: "jpy", DueAt: dueAt},
+		{InvoiceID: 2, AmountDue: 1_250, Currency: "KWD", DueAt: dueAt},
+		{InvoiceID: 3, AmountDue: 900, DueAt: dueAt},
+	}, pagination.PageInfo{}, nil
+}
+
+func TestListOverdueInvoices_InvoiceCurrency(t *testing.T) {
+	svc := &Service{
+		repo:       &mixedCurrencyRepo{},
+		log:        zap.NewNop(),
+		clock:      clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+
+	resp, err := svc.ListOverdueInvoices(orgcontext.WithOrgID(context.Background(), 1), 10, "")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", resp.Currency)
+
+	require.Len(t, resp.Invoices, 3)
+	assert.Equal(t, "JPY", resp.Invoices[0].Currency)
+	assert.Equal(t, 0, resp.Invoices[0].CurrencyExponent)
+	assert.Equal(t, "KWD", resp.Invoices[1].Currency)
+	assert.Equal(t, 3, resp.Invoices[1].CurrencyExponent)
+	assert.Equal(t, "USD", resp.Invoices[2].Currency, "rows without a currency fall back to the org's")
+	assert.Equal(t, 2, resp.Invoices[2].CurrencyExponent)
+}
+
+func TestRiskAmount_OtherCurrencyGradedOnAge(t *testing.T) {
+	// 2,000,000 rupiah would read as "high" against thresholds in cents.
+	assert.Equal(t, "low", computeRiskLevel(riskAmount(2_000_000, "IDR", "USD"), 10))
+	assert.Equal(t, "high", computeRiskLevel(riskAmount(2_000_000, "USD", "USD"), 10))
+	assert.Equal(t, "high", computeRiskLevel(riskAmount(2_000_000, "IDR", "USD"), 120))
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+)
+
+// maxStatementDays bounds the date range of a customer statement.
+const maxStatementDays = 366
+
+// GetCustomerStatement builds the statement of account for customerID over
+// [from, to): the balance brought forward at from, every finalized invoice,
+// payment and credit note in the range with the running balance after each,
+// and the closing balance. A zero to defaults to now and a zero from to 30
+// days before to.
+func (s *Service) GetCustomerStatement(ctx context.Context, customerID string, from, to time.Time) (domain.CustomerStatement, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.CustomerStatement{}, domain.ErrInvalidOrganization
+	}
+
+	custID, err := parseSnowflakeID(customerID)
+	if err != nil || custID == 0 {
+		return domain.CustomerStatement{}, domain.ErrInvalidCustomerID
+	}
+
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	to = to.UTC()
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	from = from.UTC()
+	if !from.Before(to) || to.Sub(from) > maxStatementDays*24*time.Hour {
+		return domain.CustomerStatement{}, domain.ErrInvalidPeriod
+	}
+
+	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
+	if err != nil {
+		return domain.CustomerStatement{}, err
+	}
+
+	header, rows, err := s.repo.GetCustomerStatement(ctx, orgID, custID, currency, from, to)
+	if err != nil {
+		return domain.CustomerStatement{}, err
+	}
+	if header == nil {
+		return domain.CustomerStatement{}, domain.ErrCustomerNotFound
+	}
+
+	statement := domain.CustomerStatement{
+		CustomerID:       custID.String(),
+		CustomerName:     header.CustomerName,
+		Currency:         currency,
+		CurrencyExponent: s.currencyExponent(currency),
+		From:             from,
+		To:               to,
+		OpeningBalance:   header.OpeningBalance,
+		Entries:          make([]domain.CustomerStatementEntry, 0, len(rows)),
+	}
+	balance := header.OpeningBalance
+	for _, row := range rows {
+		balance += row.Debit - row.Credit
+		statement.TotalDebits += row.Debit
+		statement.TotalCredits += row.Credit
+		statement.Entries = append(statement.Entries, domain.CustomerStatementEntry{
+			Type:          row.EntryType,
+			ID:            row.EntryID.String(),
+			InvoiceID:     row.InvoiceID,
+			InvoiceNumber: row.InvoiceNumber,
+			OccurredAt:    row.OccurredAt.UTC(),
+			Debit:         row.Debit,
+			Credit:        row.Credit,
+			Balance:       balance,
+		})
+	}
+	statement.ClosingBalance = balance
+
+	if err := s.auditRead(ctx, orgID, reportCustomerStatement, map[string]any{
+		"customer_id": statement.CustomerID,
+		"currency":    currency,
+		"from":        from,
+		"to":          to,
+	}); err != nil {
+		return domain.CustomerStatement{}, err
+	}
+	return statement, nil
+}
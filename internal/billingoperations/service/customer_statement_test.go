@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type statementRepo struct {
+	domain.Repository
+	header *domain.CustomerStatementHeaderRow
+	rows   []domain.CustomerStatementRow
+
+	customerID snowflake.ID
+	from, to   time.Time
+}
+
+func (r *statementRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *statementRepo) GetCustomerStatement(_ context.Context, _, customerID snowflake.ID, _ string, from, to time.Time) (*domain.CustomerStatementHeaderRow, []domain.CustomerStatementRow, error) {
+	r.customerID, r.from, r.to = customerID, from, to
+	return r.header, r.rows, nil
+}
+
+func TestGetCustomerStatement(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	newService := func(t *testing.T, repo *statementRepo) *Service {
+		return &Service{
+			repo:  repo,
+			log:   zaptest.NewLogger(t),
+			clock: clock.NewFakeClock(now),
+		}
+	}
+
+	t.Run("running balance from the opening balance", func(t *testing.T) {
+		repo := &statementRepo{
+			header: &domain.CustomerStatementHeaderRow{CustomerName: "Acme", OpeningBalance: 5_000},
+			rows: []domain.CustomerStatementRow{
+				{EntryType: domain.StatementEntryInvoice, EntryID: 10, InvoiceID: "10", InvoiceNumber: "INV-10", OccurredAt: now.AddDate(0, 0, -20), Debit: 12_000},
+				{EntryType: domain.StatementEntryPayment, EntryID: 20, InvoiceID: "10", InvoiceNumber: "INV-10", OccurredAt: now.AddDate(0, 0, -10), Credit: 10_000},
+				{EntryType: domain.StatementEntryCreditNote, EntryID: 30, InvoiceID: "10", InvoiceNumber: "INV-10", OccurredAt: now.AddDate(0, 0, -5), Credit: 2_000},
+			},
+		}
+		statement, err := newService(t, repo).GetCustomerStatement(ctx, "42", time.Time{}, time.Time{})
+		require.NoError(t, err)
+
+		assert.Equal(t, snowflake.ID(42), repo.customerID)
+		assert.Equal(t, now, repo.to)
+		assert.Equal(t, now.AddDate(0, 0, -30), repo.from)
+
+		assert.Equal(t, "42", statement.CustomerID)
+		assert.Equal(t, "Acme", statement.CustomerName)
+		assert.Equal(t, "USD", statement.Currency)
+		assert.Equal(t, int64(5_000), statement.OpeningBalance)
+		require.Len(t, statement.Entries, 3)
+		assert.Equal(t, []int64{17_000, 7_000, 5_000}, []int64{
+			statement.Entries[0].Balance, statement.Entries[1].Balance, statement.Entries[2].Balance,
+		})
+		assert.Equal(t, "20", statement.Entries[1].ID)
+		assert.Equal(t, "INV-10", statement.Entries[1].InvoiceNumber)
+		assert.Equal(t, int64(12_000), statement.TotalDebits)
+		assert.Equal(t, int64(12_000), statement.TotalCredits)
+		assert.Equal(t, int64(5_000), statement.ClosingBalance)
+	})
+
+	t.Run("no activity keeps the opening balance", func(t *testing.T) {
+		repo := &statementRepo{header: &domain.CustomerStatementHeaderRow{OpeningBalance: 800}}
+		statement, err := newService(t, repo).GetCustomerStatement(ctx, "42", time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.NotNil(t, statement.Entries)
+		assert.Empty(t, statement.Entries)
+		assert.Equal(t, int64(800), statement.ClosingBalance)
+	})
+
+	t.Run("unknown customer", func(t *testing.T) {
+		_, err := newService(t, &statementRepo{}).GetCustomerStatement(ctx, "42", time.Time{}, time.Time{})
+		assert.ErrorIs(t, err, domain.ErrCustomerNotFound)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		svc := newService(t, &statementRepo{})
+		_, err := svc.GetCustomerStatement(ctx, "abc", time.Time{}, time.Time{})
+		assert.ErrorIs(t, err, domain.ErrInvalidCustomerID)
+
+		_, err = svc.GetCustomerStatement(ctx, "42", now, now.AddDate(0, 0, -1))
+		assert.ErrorIs(t, err, domain.ErrInvalidPeriod)
+
+		_, err = svc.GetCustomerStatement(ctx, "42", now.AddDate(-2, 0, 0), now)
+		assert.ErrorIs(t, err, domain.ErrInvalidPeriod)
+
+		_, err = svc.GetCustomerStatement(context.Background(), "42", time.Time{}, time.Time{})
+		assert.ErrorIs(t, err, domain.ErrInvalidOrganization)
+	})
+}
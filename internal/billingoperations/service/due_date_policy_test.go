@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// undatedInvoiceRepo serves a single finalized invoice with no due date and
+// applies the due date policy the way the SQL fallback does.
+type undatedInvoiceRepo struct {
+	domain.Repository
+	policy   domain.DueDatePolicy
+	issuedAt time.Time
+}
+
+func (r *undatedInvoiceRepo) WithDueDatePolicy(policy domain.DueDatePolicy) domain.Repository {
+	clone := *r
+	clone.policy = policy
+	return &clone
+}
+
+func (r *undatedInvoiceRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *undatedInvoiceRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *undatedInvoiceRepo) ListOverdueInvoices(_ context.Context, _ snowflake.ID, now time.Time, _ pagination.Pagination) ([]domain.OverdueInvoiceRow, pagination.PageInfo, error) {
+	if r.policy.NetTermsDays == nil {
+		return nil, pagination.PageInfo{}, nil
+	}
+	dueAt := r.issuedAt.AddDate(0, 0, *r.policy.NetTermsDays)
+	if !dueAt.Before(now) {
+		return nil, pagination.PageInfo{}, nil
+	}
+	return []domain.OverdueInvoiceRow{{
+		InvoiceID:    snowflake.ID(42),
+		CustomerName: "Undated Co",
+		AmountDue:    5_000,
+		DueAt:        dueAt,
+	}}, pagination.PageInfo{}, nil
+}
+
+func TestListOverdueInvoices_MissingDueDatePolicy(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	issuedAt := now.AddDate(0, 0, -45)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	newSvc := func(policy config.MissingDueDatePolicy) *Service {
+		cfg := config.DefaultBillingConfig()
+		cfg.MissingDueDate = policy
+		return &Service{
+			repo:       &undatedInvoiceRepo{issuedAt: issuedAt},
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			billingCfg: config.NewStaticBillingConfigHolder(cfg),
+		}
+	}
+
+	t.Run("ignore keeps undated invoices out of overdue", func(t *testing.T) {
+		svc := newSvc(config.MissingDueDatePolicy{Mode: config.MissingDueDateIgnore, NetTermsDays: 30})
+		resp, err := svc.ListOverdueInvoices(ctx, 10, "")
+		require.NoError(t, err)
+		assert.Empty(t, resp.Invoices)
+	})
+
+	t.Run("net terms ages undated invoices from issuance", func(t *testing.T) {
+		svc := newSvc(config.MissingDueDatePolicy{Mode: config.MissingDueDateNetTerms, NetTermsDays: 30})
+		resp, err := svc.ListOverdueInvoices(ctx, 10, "")
+		require.NoError(t, err)
+		require.Len(t, resp.Invoices, 1)
+		assert.Equal(t, 15, resp.Invoices[0].DaysOverdue)
+	})
+
+	t.Run("net terms not yet elapsed", func(t *testing.T) {
+		svc := newSvc(config.MissingDueDatePolicy{Mode: config.MissingDueDateNetTerms, NetTermsDays: 60})
+		resp, err := svc.ListOverdueInvoices(ctx, 10, "")
+		require.NoError(t, err)
+		assert.Empty(t, resp.Invoices)
+	})
+}
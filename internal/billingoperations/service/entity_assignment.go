@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+)
+
+// GetAssignmentForEntity returns who holds an invoice or customer, for its
+// detail page. The SLA status and time since assigned are computed as in the
+// list views, and the claim snapshot lets the page show what the entity
+// looked like when it was claimed. It returns nil when the entity was never
+// claimed or its assignment was released.
+func (s *Service) GetAssignmentForEntity(ctx context.Context, entityType, entityID string) (*domain.Assignment, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, domain.ErrInvalidOrganization
+	}
+
+	entityType = strings.TrimSpace(entityType)
+	if entityType != domain.EntityTypeInvoice && entityType != domain.EntityTypeCustomer {
+		return nil, domain.ErrInvalidEntityType
+	}
+	parsedID, err := parseSnowflakeID(entityID)
+	if err != nil {
+		return nil, domain.ErrInvalidEntityID
+	}
+
+	record, err := s.repo.FindEntityAssignment(ctx, orgID, entityType, parsedID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, nil
+	}
+
+	fields := assignmentFields(
+		sql.NullString{String: record.AssignedTo, Valid: record.AssignedTo != ""},
+		record.AssignedAt,
+		sql.NullTime{Time: record.AssignmentExpiresAt, Valid: !record.AssignmentExpiresAt.IsZero()},
+		record.Status,
+		record.ReleasedAt,
+		record.ReleasedBy,
+		record.ReleaseReason,
+		record.BreachedAt,
+		record.BreachLevel,
+		record.LastActionAt,
+		s.clock.Now().UTC(),
+	)
+	assignment := activeAssignment(fields)
+	if assignment == nil {
+		return nil, nil
+	}
+	assignment.EntityType = record.EntityType
+	assignment.EntityID = record.EntityID.String()
+	assignment.HandedOffAt = timePtr(record.HandedOffAt)
+	assignment.HandedOffBy = record.HandedOffBy.String
+	assignment.HandoffNote = record.HandoffNote.String
+
+	if len(record.SnapshotMetadata) > 0 {
+		var snapshot map[string]any
+		if err := json.Unmarshal(record.SnapshotMetadata, &snapshot); err != nil {
+			s.log.Warn("failed to unmarshal snapshot metadata", zap.Error(err))
+		} else {
+			assignment.SnapshotMetadata = snapshot
+		}
+	}
+	return assignment, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/datatypes"
+)
+
+func TestGetAssignmentForEntity(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	newService := func(t *testing.T, repo *auditTrailRepo) *Service {
+		return &Service{
+			repo:  repo,
+			log:   zaptest.NewLogger(t),
+			clock: clock.NewFakeClock(now),
+		}
+	}
+
+	t.Run("returns the held assignment with its claim snapshot", func(t *testing.T) {
+		svc := newService(t, &auditTrailRepo{assignments: []domain.BillingAssignmentRecord{{
+			ID:                  10,
+			EntityType:          domain.EntityTypeInvoice,
+			EntityID:            42,
+			AssignedTo:          "7",
+			AssignedAt:          now.Add(-2 * time.Hour),
+			AssignmentExpiresAt: now.Add(time.Hour),
+			Status:              domain.AssignmentStatusInProgress,
+			LastActionAt:        sql.NullTime{Time: now.Add(-45 * time.Minute), Valid: true},
+			SnapshotMetadata:    datatypes.JSON(`{"amount_due": 12000, "currency": "USD"}`),
+		}}})
+
+		assignment, err := svc.GetAssignmentForEntity(ctx, domain.EntityTypeInvoice, "42")
+		require.NoError(t, err)
+		require.NotNil(t, assignment)
+		assert.Equal(t, domain.EntityTypeInvoice, assignment.EntityType)
+		assert.Equal(t, "42", assignment.EntityID)
+		assert.Equal(t, "7", assignment.AssignedTo)
+		assert.Equal(t, domain.AssignmentStatusInProgress, assignment.Status)
+		assert.Equal(t, domain.SLAActive, assignment.SLAStatus)
+		assert.Equal(t, "2h 0m", assignment.TimeSinceAssigned)
+		assert.Equal(t, float64(12000), assignment.SnapshotMetadata["amount_due"])
+		assert.Equal(t, "USD", assignment.SnapshotMetadata["currency"])
+	})
+
+	t.Run("returns nil when the entity was never claimed", func(t *testing.T) {
+		assignment, err := newService(t, &auditTrailRepo{}).GetAssignmentForEntity(ctx, domain.EntityTypeCustomer, "42")
+		require.NoError(t, err)
+		assert.Nil(t, assignment)
+	})
+
+	t.Run("returns nil when the assignment was released", func(t *testing.T) {
+		svc := newService(t, &auditTrailRepo{assignments: []domain.BillingAssignmentRecord{{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   42,
+			AssignedTo: "7",
+			AssignedAt: now.Add(-time.Hour),
+			Status:     domain.AssignmentStatusReleased,
+			ReleasedAt: sql.NullTime{Time: now, Valid: true},
+		}}})
+
+		assignment, err := svc.GetAssignmentForEntity(ctx, domain.EntityTypeInvoice, "42")
+		require.NoError(t, err)
+		assert.Nil(t, assignment)
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		svc := newService(t, &auditTrailRepo{})
+
+		_, err := svc.GetAssignmentForEntity(ctx, "subscription", "42")
+		assert.ErrorIs(t, err, domain.ErrInvalidEntityType)
+
+		_, err = svc.GetAssignmentForEntity(ctx, domain.EntityTypeInvoice, "not-an-id")
+		assert.ErrorIs(t, err, domain.ErrInvalidEntityID)
+
+		_, err = svc.GetAssignmentForEntity(context.Background(), domain.EntityTypeInvoice, "42")
+		assert.ErrorIs(t, err, domain.ErrInvalidOrganization)
+	})
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// exposureRepo records which exposure query the service ran.
+type exposureRepo struct {
+	domain.Repository
+	statsCalls    int
+	analysisCalls int
+	topLimit      int
+	edges         []int
+}
+
+func (r *exposureRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *exposureRepo) GetExposureStats(context.Context, snowflake.ID, time.Time) (domain.ExposureStatsRow, error) {
+	r.statsCalls++
+	return domain.ExposureStatsRow{TotalExposure: 900, Bucket0To30: 900, OverdueCount: 2}, nil
+}
+
+func (r *exposureRepo) GetExposureAnalysis(_ context.Context, _ snowflake.ID, _ time.Time, topLimit int) (domain.ExposureStatsRow, []domain.TopCustomerExposureRow, error) {
+	r.analysisCalls++
+	r.topLimit = topLimit
+	return domain.ExposureStatsRow{TotalExposure: 900, Bucket0To30: 900, OverdueCount: 2},
+		[]domain.TopCustomerExposureRow{{EntityName: "Acme", AmountDue: 600, DaysOverdue: 12}},
+		nil
+}
+
+func (r *exposureRepo) GetExposureAging(_ context.Context, _ snowflake.ID, _ time.Time, edges []int) ([]domain.ExposureAgingRow, error) {
+	r.edges = edges
+	return []domain.ExposureAgingRow{
+		{Bucket: 0, Amount: 300, InvoiceCount: 1},
+		{Bucket: 2, Amount: 600, InvoiceCount: 1},
+	}, nil
+}
+
+func TestGetExposureAnalysis_TopCustomers(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	newService := func(t *testing.T, repo *exposureRepo, cfg config.ExposureAnalysisConfig) *Service {
+		billingCfg := config.DefaultBillingConfig()
+		billingCfg.ExposureAnalysis = cfg
+		return &Service{
+			repo:       repo,
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			billingCfg: config.NewStaticBillingConfigHolder(billingCfg),
+		}
+	}
+
+	t.Run("single pass by default", func(t *testing.T) {
+		repo := &exposureRepo{}
+		resp, err := newService(t, repo, config.ExposureAnalysisConfig{}).GetExposureAnalysis(ctx, domain.ExposureAnalysisRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, repo.analysisCalls)
+		assert.Zero(t, repo.statsCalls)
+		assert.Equal(t, 5, repo.topLimit)
+		assert.Equal(t, int64(900), resp.TotalExposure)
+		require.Len(t, resp.TopHighExposure, 1)
+		assert.Equal(t, "Acme", resp.TopHighExposure[0].EntityName)
+		assert.Equal(t, "USD", resp.TopHighExposure[0].Currency)
+	})
+
+	t.Run("configured limit is passed through", func(t *testing.T) {
+		repo := &exposureRepo{}
+		_, err := newService(t, repo, config.ExposureAnalysisConfig{TopCustomers: 20}).GetExposureAnalysis(ctx, domain.ExposureAnalysisRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 20, repo.topLimit)
+	})
+
+	t.Run("skipped section runs totals only", func(t *testing.T) {
+		repo := &exposureRepo{}
+		resp, err := newService(t, repo, config.ExposureAnalysisConfig{SkipTopCustomers: true}).GetExposureAnalysis(ctx, domain.ExposureAnalysisRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, repo.statsCalls)
+		assert.Zero(t, repo.analysisCalls)
+		assert.Equal(t, int64(900), resp.TotalExposure)
+		assert.Empty(t, resp.TopHighExposure)
+		assert.Equal(t, 2, resp.ByRiskCategory[0].Count)
+	})
+}
+
+func TestGetExposureAnalysis_CustomBuckets(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	newService := func(repo *exposureRepo) *Service {
+		return &Service{
+			repo:       repo,
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+			billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+		}
+	}
+
+	t.Run("labels buckets by their edges", func(t *testing.T) {
+		repo := &exposureRepo{}
+		resp, err := newService(repo).GetExposureAnalysis(ctx, domain.ExposureAnalysisRequest{BucketEdges: []int{15, 45}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{15, 45}, repo.edges)
+		assert.Equal(t, []domain.ExposureBucket{
+			{Bucket: "Current", Amount: 0},
+			{Bucket: "1-15 Days", Amount: 300, Count: 1},
+			{Bucket: "16-45 Days", Amount: 0},
+			{Bucket: "46+ Days", Amount: 600, Count: 1},
+		}, resp.ByAgingBucket)
+	})
+
+	t.Run("default buckets without edges", func(t *testing.T) {
+		repo := &exposureRepo{}
+		resp, err := newService(repo).GetExposureAnalysis(ctx, domain.ExposureAnalysisRequest{})
+		require.NoError(t, err)
+
+		assert.Nil(t, repo.edges)
+		require.Len(t, resp.ByAgingBucket, 5)
+		assert.Equal(t, "1-30 Days", resp.ByAgingBucket[1].Bucket)
+		assert.Equal(t, int64(900), resp.ByAgingBucket[1].Amount)
+	})
+
+	for name, edges := range map[string][]int{
+		"descending":   {45, 15},
+		"duplicate":    {15, 15},
+		"zero":         {0, 30},
+		"out of range": {30, 10000},
+		"too many":     {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &exposureRepo{}
+			_, err := newService(repo).GetExposureAnalysis(ctx, domain.ExposureAnalysisRequest{BucketEdges: edges})
+			assert.ErrorIs(t, err, domain.ErrInvalidBucketEdges)
+			assert.Zero(t, repo.analysisCalls)
+		})
+	}
+}
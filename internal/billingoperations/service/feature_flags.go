@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// featureEnabled reports whether the org has turned flag on. Without a flag
+// service every feature is on.
+func (s *Service) featureEnabled(ctx context.Context, orgID snowflake.ID, flag string) bool {
+	if s.flags == nil {
+		return true
+	}
+	return s.flags.IsEnabled(ctx, orgID, flag)
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticFlags struct {
+	featuredomain.FlagService
+	enabled map[string]bool
+}
+
+func (f staticFlags) IsEnabled(_ context.Context, _ snowflake.ID, flag string) bool {
+	return f.enabled[flag]
+}
+
+func TestSnoozeEntityRequiresFeatureFlag(t *testing.T) {
+	svc := &Service{flags: staticFlags{enabled: map[string]bool{}}}
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	err := svc.SnoozeEntity(ctx, domain.EntityTypeInvoice, "1", time.Now().Add(time.Hour), "waiting on customer")
+	assert.ErrorIs(t, err, domain.ErrFeatureDisabled)
+}
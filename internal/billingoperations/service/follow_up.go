@@ -51,8 +51,8 @@ func (s *Service) RecordFollowUp(ctx context.Context, req billingoperationsdomai
 		return fmt.Errorf("assignment not found: %w", err)
 	}
 
-	// Verify ownership
-	if assignment.AssignedTo != userID {
+	// Verify ownership; a released assignment no longer belongs to anyone.
+	if !billingoperationsdomain.HasActiveAssignment(assignment.AssignedTo, assignment.Status) || assignment.AssignedTo != userID {
 		return fmt.Errorf("assignment_not_owned")
 	}
 
@@ -83,26 +83,27 @@ func (s *Service) RecordFollowUp(ctx context.Context, req billingoperationsdomai
 		Updates(map[string]interface{}{
 			"metadata":       metadata,
 			"last_action_at": now,
+			"updated_at":     now,
 		}).Error; err != nil {
 		s.log.Error("failed to update assignment metadata", zap.Error(err))
 		return err
 	}
 
 	// Create audit log entry
-	if s.auditSvc != nil {
-		targetID := assignment.EntityID.String()
-		_ = s.auditSvc.AuditLog(ctx, &orgID, "", nil,
-			"billing_operations.follow_up_opened",
-			"billing_operation_assignment",
-			&targetID,
-			map[string]any{
-				"assignment_id":   req.AssignmentID,
-				"entity_type":     assignment.EntityType,
-				"entity_id":       assignment.EntityID.String(),
-				"email_provider":  req.EmailProvider,
-				"follow_up_count": followUpCount,
-			},
-		)
+	if err := s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryOperational,
+		action:     "billing_operations.follow_up_opened",
+		targetType: "billing_operation_assignment",
+		targetID:   assignment.EntityID.String(),
+		metadata: map[string]any{
+			"assignment_id":   req.AssignmentID,
+			"entity_type":     assignment.EntityType,
+			"entity_id":       assignment.EntityID.String(),
+			"email_provider":  req.EmailProvider,
+			"follow_up_count": followUpCount,
+		},
+	}); err != nil {
+		return err
 	}
 
 	s.log.Info("follow-up email opened",
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestHandoffNote(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`CREATE TABLE billing_operation_assignments (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id BIGINT NOT NULL,
+			assigned_to TEXT NOT NULL,
+			assigned_at TIMESTAMP NOT NULL,
+			assignment_expires_at TIMESTAMP NOT NULL,
+			status TEXT NOT NULL DEFAULT 'assigned',
+			released_at TIMESTAMP,
+			released_by TEXT,
+			release_reason TEXT,
+			resolved_at TIMESTAMP,
+			resolved_by TEXT,
+			breached_at TIMESTAMP,
+			breach_level TEXT,
+			last_action_at TIMESTAMP,
+			snapshot_metadata TEXT,
+			handed_off_at TIMESTAMP,
+			handed_off_by TEXT,
+			handoff_note TEXT,
+			resolution_snapshot TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX ux_billing_assignments_entity ON billing_operation_assignments(org_id, entity_type, entity_id)`,
+		`CREATE TABLE billing_operation_actions (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id BIGINT NOT NULL,
+			action_type TEXT NOT NULL,
+			action_bucket TIMESTAMP NOT NULL,
+			idempotency_key TEXT,
+			metadata TEXT,
+			actor_type TEXT,
+			actor_id TEXT,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE organization_billing_preferences (
+			org_id BIGINT PRIMARY KEY,
+			require_handoff_note BOOLEAN NOT NULL DEFAULT false
+		)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	node, _ := snowflake.NewNode(1)
+	svc := NewService(Params{
+		DB:    db,
+		Log:   zap.NewNop(),
+		Clock: clock.NewFakeClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)),
+		GenID: node,
+		Cfg:   config.Config{},
+	})
+
+	orgID := node.Generate()
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	ctx = auditcontext.WithActor(ctx, "user", "manager")
+
+	claim := func(assignee string) string {
+		entityID := node.Generate().String()
+		_, err := svc.ClaimAssignment(ctx, domain.ClaimAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+			AssignedTo: assignee,
+		})
+		require.NoError(t, err)
+		return entityID
+	}
+	lastActionMetadata := func(actionType string) map[string]any {
+		var row struct {
+			Metadata datatypes.JSONMap
+		}
+		require.NoError(t, db.Raw(
+			`SELECT metadata FROM billing_operation_actions WHERE action_type = ? ORDER BY id DESC LIMIT 1`,
+			actionType,
+		).Scan(&row).Error)
+		return row.Metadata
+	}
+
+	t.Run("optional by default", func(t *testing.T) {
+		entityID := claim("alice")
+		require.NoError(t, svc.ReleaseAssignment(ctx, domain.ReleaseAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+		}))
+	})
+
+	require.NoError(t, db.Exec(
+		`INSERT INTO organization_billing_preferences (org_id, require_handoff_note) VALUES (?, true)`, orgID,
+	).Error)
+
+	t.Run("release requires a note", func(t *testing.T) {
+		entityID := claim("alice")
+		err := svc.ReleaseAssignment(ctx, domain.ReleaseAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+			Reason:     "   ",
+		})
+		assert.ErrorIs(t, err, domain.ErrHandoffNoteRequired)
+
+		require.NoError(t, svc.ReleaseAssignment(ctx, domain.ReleaseAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+			Reason:     "customer asked to talk to billing lead",
+		}))
+		assert.Equal(t, "customer asked to talk to billing lead", lastActionMetadata(domain.ActionTypeRelease)["handoff_note"])
+	})
+
+	t.Run("reassign requires a note", func(t *testing.T) {
+		entityID := claim("alice")
+		_, err := svc.ReassignAssignment(ctx, domain.ReassignAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+			AssignTo:   "bob",
+		})
+		assert.ErrorIs(t, err, domain.ErrHandoffNoteRequired)
+
+		// Alice let the SLA lapse; the breach must not follow the work to Bob.
+		require.NoError(t, db.Exec(
+			`UPDATE billing_operation_assignments SET breached_at = ?, breach_level = ? WHERE entity_id = ?`,
+			time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC), domain.SLABreachIdleAction, entityID,
+		).Error)
+
+		resp, err := svc.ReassignAssignment(ctx, domain.ReassignAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+			AssignTo:   "bob",
+			Reason:     "alice is out this week",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "bob", resp.Assignment.AssignedTo)
+		assert.Equal(t, "alice is out this week", resp.Assignment.HandoffNote)
+		assert.Equal(t, "manager", resp.Assignment.HandedOffBy)
+		assert.Nil(t, resp.Assignment.ReleasedAt)
+
+		var stored struct {
+			BreachedAt    sql.NullTime
+			BreachLevel   sql.NullString
+			ReleasedAt    sql.NullTime
+			ReleaseReason sql.NullString
+			HandedOffBy   sql.NullString
+			HandoffNote   sql.NullString
+		}
+		require.NoError(t, db.Raw(
+			`SELECT breached_at, breach_level, released_at, release_reason, handed_off_by, handoff_note
+			FROM billing_operation_assignments WHERE entity_id = ?`, entityID,
+		).Scan(&stored).Error)
+		assert.False(t, stored.BreachedAt.Valid)
+		assert.False(t, stored.BreachLevel.Valid)
+		assert.False(t, stored.ReleasedAt.Valid)
+		assert.False(t, stored.ReleaseReason.Valid)
+		assert.Equal(t, "manager", stored.HandedOffBy.String)
+		assert.Equal(t, "alice is out this week", stored.HandoffNote.String)
+
+		metadata := lastActionMetadata(domain.ActionTypeReassign)
+		assert.Equal(t, "alice", metadata["from"])
+		assert.Equal(t, "bob", metadata["to"])
+		assert.Equal(t, "manager", metadata["reassigned_by"])
+		assert.Equal(t, "alice is out this week", metadata["handoff_note"])
+	})
+
+	t.Run("reassign without an active assignment", func(t *testing.T) {
+		_, err := svc.ReassignAssignment(ctx, domain.ReassignAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   node.Generate().String(),
+			AssignTo:   "bob",
+			Reason:     "note",
+		})
+		assert.ErrorIs(t, err, domain.ErrAssignmentNotFound)
+	})
+}
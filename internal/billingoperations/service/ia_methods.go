@@ -3,11 +3,18 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/config"
+	customerdomain "github.com/smallbiznis/railzway/internal/customer/domain"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
 	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/money"
 	"go.uber.org/zap"
 )
 
@@ -19,17 +26,41 @@ func (s *Service) GetInbox(ctx context.Context, req domain.InboxRequest) (domain
 		return domain.InboxResponse{}, domain.ErrInvalidOrganization
 	}
 
-	limit := req.Limit
-	if limit <= 0 {
-		limit = 25
+	switch req.RiskCategory {
+	case "", domain.RiskCategoryOverdue, domain.RiskCategoryHighExposure, domain.RiskCategoryBalanceExposure:
+	default:
+		return domain.InboxResponse{}, domain.ErrInvalidRiskCategory
+	}
+	if req.MinAmountDue < 0 {
+		return domain.InboxResponse{}, domain.ErrInvalidMinAmountDue
 	}
 
-	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
+	limit, err := s.listLimit(ctx, orgID, req.Limit, func(d domain.ListDefaults) int { return d.Inbox }, 25)
 	if err != nil {
 		return domain.InboxResponse{}, err
 	}
 
-	rows, err := s.repo.ListInboxItems(ctx, orgID, limit, s.clock.Now().UTC())
+	// Risk scores and thresholds are in minor units, so the inbox lists one
+	// currency at a time: the requested one, or the org's.
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency != "" {
+		if _, ok := money.LookupMinorUnits(currency); !ok {
+			return domain.InboxResponse{}, domain.ErrInvalidCurrency
+		}
+	} else {
+		currency, err = s.repo.FetchOrgCurrency(ctx, orgID)
+		if err != nil {
+			return domain.InboxResponse{}, err
+		}
+	}
+	now := s.clock.Now().UTC()
+	filter := s.inboxFilter(req)
+	filter.Currency = currency
+	var rows []domain.InboxRow
+	err = s.boundedRead(ctx, obsmetrics.BillingOpsEndpointInbox, func(ctx context.Context, repo domain.Repository) (err error) {
+		rows, err = repo.ListInboxItems(ctx, orgID, filter, limit, now)
+		return err
+	})
 	if err != nil {
 		return domain.InboxResponse{}, err
 	}
@@ -42,23 +73,28 @@ func (s *Service) GetInbox(ctx context.Context, req domain.InboxRequest) (domain
 			lastAttempt = &t
 		}
 
+		rowCurrency := itemCurrency(row.Currency, currency)
 		items = append(items, domain.InboxItem{
-			EntityType:   row.EntityType,
-			EntityID:     row.EntityID,
-			EntityName:   row.EntityName,
-			RiskCategory: row.RiskCategory,
-			RiskScore:    row.RiskScore,
-			AmountDue:    row.AmountDue,
-			Currency:     currency,
-			DaysOverdue:  int(row.DaysOverdue),
-			LastAttempt:  lastAttempt,
-			PublicToken:  decryptToken(s.encKey, row.TokenHash.String),
+			EntityType:       row.EntityType,
+			EntityID:         row.EntityID,
+			EntityName:       row.EntityName,
+			RiskCategory:     row.RiskCategory,
+			RiskScore:        row.RiskScore,
+			AmountDue:        row.AmountDue,
+			Currency:         rowCurrency,
+			CurrencyExponent: s.currencyExponent(rowCurrency),
+			DaysOverdue:      int(row.DaysOverdue),
+			LastAttempt:      lastAttempt,
+			PublicToken:      s.decryptPublicToken(orgID, row.TokenHash.String),
+
+			DisputedAmount: row.DisputedAmount,
 		})
 	}
 
 	return domain.InboxResponse{
-		Items:    items,
-		Currency: currency,
+		Items:            items,
+		Currency:         currency,
+		CurrencyExponent: s.currencyExponent(currency),
 	}, nil
 }
 
@@ -71,9 +107,9 @@ func (s *Service) GetMyWork(ctx context.Context, userID string, req domain.MyWor
 		return domain.MyWorkResponse{}, domain.ErrInvalidOrganization
 	}
 
-	limit := req.Limit
-	if limit <= 0 {
-		limit = 50
+	limit, err := s.listLimit(ctx, orgID, req.Limit, func(d domain.ListDefaults) int { return d.MyWork }, 50)
+	if err != nil {
+		return domain.MyWorkResponse{}, err
 	}
 
 	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
@@ -82,12 +118,21 @@ func (s *Service) GetMyWork(ctx context.Context, userID string, req domain.MyWor
 	}
 
 	now := s.clock.Now().UTC()
-	rows, err := s.repo.ListMyWorkItems(ctx, orgID, userID, limit, now)
+	var rows []domain.MyWorkRow
+	err = s.boundedRead(ctx, obsmetrics.BillingOpsEndpointMyWork, func(ctx context.Context, repo domain.Repository) (err error) {
+		rows, err = repo.ListMyWorkItems(ctx, orgID, userID, limit, now)
+		return err
+	})
 	if err != nil {
 		return domain.MyWorkResponse{}, err
 	}
 
 	items := make([]domain.MyWorkItem, 0, len(rows))
+	// Every row carries the count over all of the user's work.
+	nowResolvedCount := 0
+	if len(rows) > 0 {
+		nowResolvedCount = rows[0].NowResolvedCount
+	}
 	for _, row := range rows {
 		// Parse snapshot metadata
 		var snapshot map[string]interface{}
@@ -144,36 +189,64 @@ func (s *Service) GetMyWork(ctx context.Context, userID string, req domain.MyWor
 			lastActionAt = &t
 		}
 
+		doNotContact := row.DoNotContact.Valid && row.DoNotContact.Bool
+		var phone, channel string
+		if !doNotContact {
+			channel = row.ContactChannel.String
+			if s.includeCustomerPhone {
+				phone = row.CustomerPhone.String
+			}
+		}
+
+		// Paid work stays assigned; the agent closes it explicitly.
+		nowResolved := amountDueAtClaim > 0 && row.CurrentAmountDue.Valid && currentAmountDue == 0
+		var suggestedAction string
+		if nowResolved {
+			suggestedAction = domain.SuggestedActionCloseAsPaid
+		}
+
+		rowCurrency := itemCurrency(row.Currency.String, currency)
 		items = append(items, domain.MyWorkItem{
 			AssignmentID:  row.AssignmentID,
 			EntityType:    row.EntityType,
 			EntityID:      row.EntityID,
 			EntityName:    entityName,
 			CustomerName:  row.CustomerName.String,
-			CustomerEmail: row.CustomerEmail.String,
+			CustomerEmail: customerdomain.NormalizeEmail(row.CustomerEmail.String, s.lowercaseCustomerEmail),
 			InvoiceNumber: row.InvoiceNumber.String,
 
+			CustomerPhone:           phone,
+			PreferredContactChannel: channel,
+			DoNotContact:            doNotContact,
+
 			AmountDueAtClaim:   amountDueAtClaim,
 			DaysOverdueAtClaim: daysOverdueAtClaim,
 			CurrentAmountDue:   currentAmountDue,
 			CurrentDaysOverdue: currentDaysOverdue,
-			Currency:           currency,
+			Currency:           rowCurrency,
+			CurrencyExponent:   s.currencyExponent(rowCurrency),
 			ClaimedAt:          row.AssignedAt.UTC(),
 			AssignmentAge:      assignmentAge,
 			Status:             row.Status,
 			LastActionAt:       lastActionAt,
-			PublicToken:        decryptToken(s.encKey, row.TokenHash.String),
+			PublicToken:        s.decryptPublicToken(orgID, row.TokenHash.String),
+			IsNowResolved:      nowResolved,
+			SuggestedAction:    suggestedAction,
 		})
 	}
 
 	return domain.MyWorkResponse{
-		Items:    items,
-		Currency: currency,
+		Items:            items,
+		Currency:         currency,
+		CurrencyExponent: s.currencyExponent(currency),
+		NowResolvedCount: nowResolvedCount,
 	}, nil
 }
 
 // GetRecentlyResolved returns completed, released, or escalated work
 // Routing Rule: assigned_to = current_user AND status IN (resolved, released, escalated) AND resolved_at > 30 days ago
+// Detailed requests also return the full claim snapshot and the current state
+// of each entity.
 func (s *Service) GetRecentlyResolved(ctx context.Context, userID string, req domain.RecentlyResolvedRequest) (domain.RecentlyResolvedResponse, error) {
 	orgID, ok := orgcontext.OrgIDFromContext(ctx)
 	if !ok || orgID == 0 {
@@ -220,6 +293,8 @@ func (s *Service) GetRecentlyResolved(ctx context.Context, userID string, req do
 		if amt, ok := snapshot["amount_due"].(float64); ok {
 			amountDueAtClaim = int64(amt)
 		}
+		snapshotCurrency, _ := snapshot["currency"].(string)
+		claimCurrency := itemCurrency(snapshotCurrency, currency)
 
 		// Calculate duration
 		duration := row.ResolvedAt.Sub(row.AssignedAt)
@@ -232,7 +307,7 @@ func (s *Service) GetRecentlyResolved(ctx context.Context, userID string, req do
 			durationStr = fmt.Sprintf("%dm", minutes)
 		}
 
-		items = append(items, domain.ResolvedItem{
+		item := domain.ResolvedItem{
 			AssignmentID:     row.AssignmentID,
 			EntityType:       row.EntityType,
 			EntityID:         row.EntityID,
@@ -244,8 +319,17 @@ func (s *Service) GetRecentlyResolved(ctx context.Context, userID string, req do
 			ClaimedAt:        row.AssignedAt.UTC(),
 			Duration:         durationStr,
 			AmountDueAtClaim: amountDueAtClaim,
-			Currency:         currency,
-		})
+			Currency:         claimCurrency,
+			CurrencyExponent: s.currencyExponent(claimCurrency),
+		}
+		if row.Status == domain.AssignmentStatusReleased {
+			item.HandoffNote = row.ReleaseReason.String
+		}
+		if req.Detailed {
+			item.Snapshot = snapshot
+			item.CurrentState = s.resolvedState(row)
+		}
+		items = append(items, item)
 	}
 
 	return domain.RecentlyResolvedResponse{
@@ -253,88 +337,195 @@ func (s *Service) GetRecentlyResolved(ctx context.Context, userID string, req do
 	}, nil
 }
 
+// resolvedState decodes the entity state a row was resolved on. Work
+// finished before resolution snapshots were taken has none.
+func (s *Service) resolvedState(row domain.ResolvedRow) map[string]any {
+	if len(row.ResolutionSnapshot) == 0 {
+		return nil
+	}
+	var state map[string]any
+	if err := json.Unmarshal(row.ResolutionSnapshot, &state); err != nil {
+		s.log.Warn("failed to unmarshal resolution snapshot", zap.Error(err))
+		return nil
+	}
+	return state
+}
+
 // GetTeamView returns operational oversight for managers
 // Routing Rule: Manager role only
 // Explicitly FORBIDDEN: Leaderboards, ranking by score, best/worst labels
+// Workload imbalance is surfaced as "needs rebalancing" flags against the
+// team mean, never as a ranking.
 func (s *Service) GetTeamView(ctx context.Context, req domain.TeamViewRequest) (domain.TeamViewResponse, error) {
 	orgID, ok := orgcontext.OrgIDFromContext(ctx)
 	if !ok || orgID == 0 {
 		return domain.TeamViewResponse{}, domain.ErrInvalidOrganization
 	}
 
-	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
+	excludeUserIDs, err := normalizeExcludeUserIDs(req.ExcludeUserIDs)
 	if err != nil {
 		return domain.TeamViewResponse{}, err
 	}
+	if req.Top < 0 {
+		return domain.TeamViewResponse{}, domain.ErrInvalidTop
+	}
+	teamCfg := s.billingCfg.Get().TeamViews
+	top := teamViewTop(req.Top, teamCfg.MaxMembers)
 
-	rows, err := s.repo.GetTeamViewStats(ctx, orgID, s.clock.Now().UTC())
+	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
 	if err != nil {
 		return domain.TeamViewResponse{}, err
 	}
 
-	var (
-		totalActiveAssignments int
-		totalExposure          int64
-		totalEscalationCount   int
-		weightedAgeMinutes     int
-		totalAssignmentsForAge int
-	)
+	statsCtx, cancel := context.WithTimeout(ctx, teamViewQueryTimeout(teamCfg))
+	defer cancel()
+	rows, err := s.repo.GetTeamViewStats(statsCtx, orgID, excludeUserIDs, s.clock.Now().UTC())
+	if err != nil {
+		if errors.Is(statsCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			s.log.Warn("team view aggregation timed out",
+				zap.String("org_id", orgID.String()),
+				zap.Duration("timeout", teamViewQueryTimeout(teamCfg)),
+			)
+			return domain.TeamViewResponse{}, domain.ErrTeamViewTimeout
+		}
+		return domain.TeamViewResponse{}, err
+	}
 
-	members := make([]domain.TeamMemberWorkload, 0, len(rows))
+	filter := s.newTeamViewsFilter()
+	visible := make([]domain.TeamRow, 0, len(rows))
 	for _, row := range rows {
-		// Update summary aggregates
-		totalActiveAssignments += row.ActiveAssignments
-		totalExposure += row.TotalExposureOwned
-		totalEscalationCount += row.EscalationCount
-		if row.ActiveAssignments > 0 {
-			weightedAgeMinutes += row.AvgAssignmentAgeMinutes * row.ActiveAssignments
-			totalAssignmentsForAge += row.ActiveAssignments
+		hidden, err := filter.hidden(ctx, orgID, row.UserID)
+		if err != nil {
+			return domain.TeamViewResponse{}, err
 		}
-
-		// Format average assignment age
-		hours := row.AvgAssignmentAgeMinutes / 60
-		minutes := row.AvgAssignmentAgeMinutes % 60
-		var avgAge string
-		if hours > 0 {
-			avgAge = fmt.Sprintf("%dh %dm", hours, minutes)
-		} else {
-			avgAge = fmt.Sprintf("%dm", minutes)
+		if !hidden {
+			visible = append(visible, row)
 		}
+	}
+
+	listed, rest := visible, []domain.TeamRow(nil)
+	if top > 0 && len(visible) > top {
+		listed, rest = splitBusiestTeamRows(visible, top)
+	}
 
+	balance := teamWorkloadBalance(visible, teamCfg.RebalanceThresholdPercent)
+	members := make([]domain.TeamMemberWorkload, 0, len(listed))
+	for _, row := range listed {
+		reasons := rebalanceReasons(row, balance)
 		members = append(members, domain.TeamMemberWorkload{
 			UserID:             row.UserID,
 			ActiveAssignments:  row.ActiveAssignments,
-			AvgAssignmentAge:   avgAge,
+			AvgAssignmentAge:   formatAssignmentAge(row.AvgAssignmentAgeMinutes),
 			TotalExposureOwned: row.TotalExposureOwned,
 			EscalationCount:    row.EscalationCount,
+			NeedsRebalancing:   len(reasons) > 0,
+			RebalanceReasons:   reasons,
 		})
 	}
 
-	// Calculate global average age
-	var globalAvgAge string
-	if totalAssignmentsForAge > 0 {
-		avgMins := weightedAgeMinutes / totalAssignmentsForAge
-		h := avgMins / 60
-		m := avgMins % 60
-		if h > 0 {
-			globalAvgAge = fmt.Sprintf("%dh %dm", h, m)
+	total := sumTeamRows(visible)
+	resp := domain.TeamViewResponse{
+		Members: members,
+		Summary: domain.TeamSummary{
+			TotalActiveAssignments: total.ActiveAssignments,
+			TotalExposure:          total.TotalExposureOwned,
+			AvgAssignmentAge:       total.AvgAssignmentAge,
+			EscalationCount:        total.EscalationCount,
+		},
+		Currency:         currency,
+		CurrencyExponent: s.currencyExponent(currency),
+		Balance:          balance,
+	}
+	if len(rest) > 0 {
+		others := sumTeamRows(rest)
+		resp.Truncated = true
+		resp.Others = &others
+	}
+	return resp, nil
+}
+
+// teamViewTop returns how many operators a team view lists: the requested
+// count, capped by the configured maximum, or the maximum when none was
+// requested. Zero lists everyone.
+func teamViewTop(requested, maxMembers int) int {
+	if maxMembers > 0 && (requested == 0 || requested > maxMembers) {
+		return maxMembers
+	}
+	return requested
+}
+
+// teamViewQueryTimeout bounds the team workload aggregation.
+func teamViewQueryTimeout(cfg config.TeamViewsConfig) time.Duration {
+	seconds := cfg.QueryTimeoutSeconds
+	if seconds <= 0 {
+		seconds = config.DefaultBillingConfig().TeamViews.QueryTimeoutSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// splitBusiestTeamRows picks the top operators by active assignments, then
+// exposure owned, and returns them in their original (user ID) order so the
+// view does not read as a ranking. The remaining rows are returned as rest.
+func splitBusiestTeamRows(rows []domain.TeamRow, top int) ([]domain.TeamRow, []domain.TeamRow) {
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := rows[order[a]], rows[order[b]]
+		if ra.ActiveAssignments != rb.ActiveAssignments {
+			return ra.ActiveAssignments > rb.ActiveAssignments
+		}
+		return ra.TotalExposureOwned > rb.TotalExposureOwned
+	})
+	keep := make(map[int]bool, top)
+	for _, idx := range order[:top] {
+		keep[idx] = true
+	}
+
+	listed := make([]domain.TeamRow, 0, top)
+	rest := make([]domain.TeamRow, 0, len(rows)-top)
+	for i, row := range rows {
+		if keep[i] {
+			listed = append(listed, row)
 		} else {
-			globalAvgAge = fmt.Sprintf("%dm", m)
+			rest = append(rest, row)
 		}
-	} else {
-		globalAvgAge = "0m"
 	}
+	return listed, rest
+}
 
-	return domain.TeamViewResponse{
-		Members: members,
-		Summary: domain.TeamSummary{
-			TotalActiveAssignments: totalActiveAssignments,
-			TotalExposure:          totalExposure,
-			AvgAssignmentAge:       globalAvgAge,
-			EscalationCount:        totalEscalationCount,
-		},
-		Currency: currency,
-	}, nil
+// sumTeamRows totals the workload of rows, weighting the average assignment
+// age by each operator's active assignments.
+func sumTeamRows(rows []domain.TeamRow) domain.TeamOthersSummary {
+	var (
+		sum                    domain.TeamOthersSummary
+		weightedAgeMinutes     int
+		totalAssignmentsForAge int
+	)
+	for _, row := range rows {
+		sum.MemberCount++
+		sum.ActiveAssignments += row.ActiveAssignments
+		sum.TotalExposureOwned += row.TotalExposureOwned
+		sum.EscalationCount += row.EscalationCount
+		if row.ActiveAssignments > 0 {
+			weightedAgeMinutes += row.AvgAssignmentAgeMinutes * row.ActiveAssignments
+			totalAssignmentsForAge += row.ActiveAssignments
+		}
+	}
+	sum.AvgAssignmentAge = "0m"
+	if totalAssignmentsForAge > 0 {
+		sum.AvgAssignmentAge = formatAssignmentAge(weightedAgeMinutes / totalAssignmentsForAge)
+	}
+	return sum
+}
+
+// formatAssignmentAge renders minutes as "1h 30m", or "45m" under an hour.
+func formatAssignmentAge(minutes int) string {
+	if h := minutes / 60; h > 0 {
+		return fmt.Sprintf("%dh %dm", h, minutes%60)
+	}
+	return fmt.Sprintf("%dm", minutes%60)
 }
 
 // GetInvoicePayments returns payment events associated with an invoice
@@ -415,15 +606,16 @@ func (s *Service) GetInvoicePayments(ctx context.Context, invoiceID string) (dom
 		}
 
 		payments = append(payments, domain.PaymentDetail{
-			PaymentID:  row.ProviderPaymentID,
-			Amount:     amount,
-			Currency:   row.Currency,
-			OccurredAt: row.ReceivedAt.UTC(),
-			Provider:   row.Provider,
-			Method:     method,
-			CardBrand:  brand,
-			CardLast4:  last4,
-			Status:     status,
+			PaymentID:        row.ProviderPaymentID,
+			Amount:           amount,
+			Currency:         row.Currency,
+			CurrencyExponent: s.currencyExponent(row.Currency),
+			OccurredAt:       row.ReceivedAt.UTC(),
+			Provider:         row.Provider,
+			Method:           method,
+			CardBrand:        brand,
+			CardLast4:        last4,
+			Status:           status,
 		})
 	}
 
@@ -437,19 +629,38 @@ func (s *Service) GetExposureAnalysis(ctx context.Context, req domain.ExposureAn
 		return domain.ExposureAnalysisResponse{}, domain.ErrInvalidOrganization
 	}
 
-	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
-	if err != nil {
+	if err := validateBucketEdges(req.BucketEdges); err != nil {
 		return domain.ExposureAnalysisResponse{}, err
 	}
 
-	now := s.clock.Now().UTC()
-
-	stats, err := s.repo.GetExposureStats(ctx, orgID, now)
+	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
 	if err != nil {
 		return domain.ExposureAnalysisResponse{}, err
 	}
 
-	topRows, err := s.repo.ListTopHighExposure(ctx, orgID, now)
+	now := s.clock.Now().UTC()
+
+	// The top customers come out of the same pass as the totals; with the
+	// section skipped only the cheaper totals query runs. Custom aging
+	// buckets need one more pass, bounded by the same timeout.
+	var (
+		stats     domain.ExposureStatsRow
+		topRows   []domain.TopCustomerExposureRow
+		agingRows []domain.ExposureAgingRow
+	)
+	exposureCfg := s.billingCfg.Get().ExposureAnalysis
+	err = s.boundedRead(ctx, obsmetrics.BillingOpsEndpointExposureAnalysis, func(ctx context.Context, repo domain.Repository) (err error) {
+		if exposureCfg.SkipTopCustomers {
+			stats, err = repo.GetExposureStats(ctx, orgID, now)
+		} else {
+			stats, topRows, err = repo.GetExposureAnalysis(ctx, orgID, now, exposureTopCustomers(exposureCfg))
+		}
+		if err != nil || len(req.BucketEdges) == 0 {
+			return err
+		}
+		agingRows, err = repo.GetExposureAging(ctx, orgID, now, req.BucketEdges)
+		return err
+	})
 	if err != nil {
 		return domain.ExposureAnalysisResponse{}, err
 	}
@@ -475,6 +686,9 @@ func (s *Service) GetExposureAnalysis(ctx context.Context, req domain.ExposureAn
 		{Bucket: "61-90 Days", Amount: stats.Bucket61To90, Count: 0},
 		{Bucket: "90+ Days", Amount: stats.Bucket90Plus, Count: 0},
 	}
+	if len(req.BucketEdges) > 0 {
+		aging = customAgingBuckets(stats.CurrentAmount, req.BucketEdges, agingRows)
+	}
 
 	// For Risk Category, we simplify:
 	// "Overdue" -> Sum of all buckets > 0
@@ -485,11 +699,232 @@ func (s *Service) GetExposureAnalysis(ctx context.Context, req domain.ExposureAn
 		{Category: "Current", Amount: stats.CurrentAmount, Count: 0}, // Count not easily available from agg
 	}
 
+	details := map[string]any{
+		"currency": currency,
+		"as_of":    now,
+	}
+	if len(req.BucketEdges) > 0 {
+		details["bucket_edges"] = req.BucketEdges
+	}
+	if err := s.auditRead(ctx, orgID, reportExposureAnalysis, details); err != nil {
+		return domain.ExposureAnalysisResponse{}, err
+	}
+
 	return domain.ExposureAnalysisResponse{
-		TotalExposure:   stats.TotalExposure,
-		Currency:        currency,
-		ByRiskCategory:  riskCats,
-		ByAgingBucket:   aging,
-		TopHighExposure: topItems,
+		TotalExposure:    stats.TotalExposure,
+		Currency:         currency,
+		CurrencyExponent: s.currencyExponent(currency),
+		ByRiskCategory:   riskCats,
+		ByAgingBucket:    aging,
+		TopHighExposure:  topItems,
 	}, nil
 }
+
+// maxBucketEdges and maxBucketEdgeDays bound custom exposure aging buckets.
+const (
+	maxBucketEdges    = 10
+	maxBucketEdgeDays = 3650
+)
+
+// validateBucketEdges checks custom aging bucket edges: at most
+// maxBucketEdges strictly ascending days between 1 and maxBucketEdgeDays.
+// No edges is valid and keeps the default buckets.
+func validateBucketEdges(edges []int) error {
+	if len(edges) > maxBucketEdges {
+		return domain.ErrInvalidBucketEdges
+	}
+	prev := 0
+	for _, edge := range edges {
+		if edge <= prev || edge > maxBucketEdgeDays {
+			return domain.ErrInvalidBucketEdges
+		}
+		prev = edge
+	}
+	return nil
+}
+
+// customAgingBuckets labels the buckets of edges by their day ranges, after
+// the current bucket, and fills in the amounts GetExposureAging found.
+func customAgingBuckets(current int64, edges []int, rows []domain.ExposureAgingRow) []domain.ExposureBucket {
+	buckets := make([]domain.ExposureBucket, 0, len(edges)+2)
+	buckets = append(buckets, domain.ExposureBucket{Bucket: "Current", Amount: current})
+	from := 1
+	for _, edge := range edges {
+		buckets = append(buckets, domain.ExposureBucket{Bucket: fmt.Sprintf("%d-%d Days", from, edge)})
+		from = edge + 1
+	}
+	buckets = append(buckets, domain.ExposureBucket{Bucket: fmt.Sprintf("%d+ Days", from)})
+	for _, row := range rows {
+		if row.Bucket < 0 || row.Bucket > len(edges) {
+			continue
+		}
+		buckets[row.Bucket+1].Amount = row.Amount
+		buckets[row.Bucket+1].Count = row.InvoiceCount
+	}
+	return buckets
+}
+
+// GetARHealth summarises receivables health for [from, to). A zero to
+// defaults to now and a zero from to 30 days before to.
+func (s *Service) GetARHealth(ctx context.Context, from, to time.Time) (domain.ARHealthResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.ARHealthResponse{}, domain.ErrInvalidOrganization
+	}
+
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	to = to.UTC()
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	from = from.UTC()
+	if !from.Before(to) {
+		return domain.ARHealthResponse{}, domain.ErrInvalidPeriod
+	}
+
+	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
+	if err != nil {
+		return domain.ARHealthResponse{}, err
+	}
+
+	flows, err := s.repo.GetARFlowStats(ctx, orgID, currency, from, to)
+	if err != nil {
+		return domain.ARHealthResponse{}, err
+	}
+
+	resp := domain.ARHealthResponse{
+		Currency:             currency,
+		CurrencyExponent:     s.currencyExponent(currency),
+		From:                 from,
+		To:                   to,
+		BeginningReceivables: flows.BeginningReceivables,
+		EndingReceivables:    flows.EndingReceivables,
+		TotalBilled:          flows.TotalBilled,
+		PaymentsReceived:     flows.PaymentsReceived,
+	}
+	computeARHealthRatios(&resp)
+
+	if err := s.auditRead(ctx, orgID, reportARHealth, map[string]any{
+		"currency": currency,
+		"from":     from,
+		"to":       to,
+	}); err != nil {
+		return domain.ARHealthResponse{}, err
+	}
+	return resp, nil
+}
+
+// computeARHealthRatios fills PeriodDays, DSO and CEI from the balances on
+// resp, leaving a ratio nil when its denominator is not positive.
+func computeARHealthRatios(resp *domain.ARHealthResponse) {
+	resp.PeriodDays = resp.To.Sub(resp.From).Hours() / 24
+
+	if resp.TotalBilled > 0 {
+		dso := float64(resp.EndingReceivables) / float64(resp.TotalBilled) * resp.PeriodDays
+		resp.DSO = &dso
+	}
+
+	collectible := resp.BeginningReceivables + resp.TotalBilled - resp.EndingReceivables
+	if collectible > 0 {
+		cei := float64(resp.PaymentsReceived) / float64(collectible) * 100
+		resp.CEI = &cei
+	}
+}
+
+// maxSLAStatsDays bounds the daily series returned by GetSLAStats.
+const maxSLAStatsDays = 366
+
+// GetSLAStats aggregates SLA breaches for the whole team over [from, to).
+// A zero to defaults to now and a zero from to 30 days before to.
+func (s *Service) GetSLAStats(ctx context.Context, from, to time.Time) (domain.SLAStatsResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.SLAStatsResponse{}, domain.ErrInvalidOrganization
+	}
+
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	to = to.UTC()
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	from = from.UTC()
+	if !from.Before(to) || to.Sub(from) > maxSLAStatsDays*24*time.Hour {
+		return domain.SLAStatsResponse{}, domain.ErrInvalidPeriod
+	}
+
+	breaches, err := s.repo.ListSLABreachActions(ctx, orgID, from, to)
+	if err != nil {
+		return domain.SLAStatsResponse{}, err
+	}
+	claims, err := s.repo.CountActionsByType(ctx, orgID, domain.ActionTypeClaim, from, to)
+	if err != nil {
+		return domain.SLAStatsResponse{}, err
+	}
+	escalated, err := s.repo.CountEscalatedAssignments(ctx, orgID, from, to)
+	if err != nil {
+		return domain.SLAStatsResponse{}, err
+	}
+
+	resp := buildSLAStats(from, to, int(claims), breaches)
+	resp.EscalatedOpen = int(escalated)
+	return resp, nil
+}
+
+// buildSLAStats buckets breach actions by type and UTC day. Breaches recorded
+// without a recognised breach_type count towards the total only.
+func buildSLAStats(from, to time.Time, claims int, breaches []domain.SLABreachActionRow) domain.SLAStatsResponse {
+	startDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	series := make([]domain.SLABreachPoint, 0)
+	for day := startDay; day.Before(to); day = day.AddDate(0, 0, 1) {
+		series = append(series, domain.SLABreachPoint{Date: day})
+	}
+
+	byType := map[string]int{
+		domain.SLABreachInitialResponse: 0,
+		domain.SLABreachIdleAction:      0,
+	}
+	for _, row := range breaches {
+		breachType, _ := row.Metadata["breach_type"].(string)
+		idx := int(row.CreatedAt.UTC().Sub(startDay).Hours() / 24)
+		if idx < 0 || idx >= len(series) {
+			continue
+		}
+		switch breachType {
+		case domain.SLABreachInitialResponse:
+			series[idx].InitialResponse++
+			byType[breachType]++
+		case domain.SLABreachIdleAction:
+			series[idx].IdleAction++
+			byType[breachType]++
+		}
+	}
+
+	resp := domain.SLAStatsResponse{
+		From:          from,
+		To:            to,
+		Claims:        claims,
+		TotalBreaches: len(breaches),
+		BreachRate:    ratioOrNil(len(breaches), claims),
+		Series:        series,
+	}
+	for _, breachType := range []string{domain.SLABreachInitialResponse, domain.SLABreachIdleAction} {
+		resp.ByType = append(resp.ByType, domain.SLABreachTypeStats{
+			BreachType: breachType,
+			Breaches:   byType[breachType],
+			BreachRate: ratioOrNil(byType[breachType], claims),
+		})
+	}
+	return resp
+}
+
+func ratioOrNil(numerator, denominator int) *float64 {
+	if denominator <= 0 {
+		return nil
+	}
+	ratio := float64(numerator) / float64(denominator)
+	return &ratio
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type exposureCustomer struct {
+	id          string
+	outstanding int64
+	oldestDue   *time.Time // oldest overdue due date, nil when fully current
+}
+
+// inboxExposureRepo lists customers as inbox items with the same threshold
+// and overdue rules as the risky_customers CTE.
+type inboxExposureRepo struct {
+	domain.Repository
+	customers []exposureCustomer
+	filter    domain.InboxFilter
+}
+
+func (r *inboxExposureRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *inboxExposureRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *inboxExposureRepo) FetchListDefaults(context.Context, snowflake.ID) (domain.ListDefaults, error) {
+	return domain.ListDefaults{}, nil
+}
+
+func (r *inboxExposureRepo) ListInboxItems(_ context.Context, _ snowflake.ID, filter domain.InboxFilter, _ int, now time.Time) ([]domain.InboxRow, error) {
+	r.filter = filter
+	var rows []domain.InboxRow
+	for _, c := range r.customers {
+		if c.outstanding < filter.HighExposureThreshold {
+			continue
+		}
+		row := domain.InboxRow{
+			EntityType:   domain.EntityTypeCustomer,
+			EntityID:     c.id,
+			RiskCategory: "high_exposure",
+			AmountDue:    c.outstanding,
+			RiskScore:    int(c.outstanding / 10000),
+		}
+		if c.oldestDue == nil {
+			if !filter.IncludeCurrentExposure {
+				continue
+			}
+			row.RiskCategory = "balance_exposure"
+		} else {
+			row.DueAt = sql.NullTime{Time: *c.oldestDue, Valid: true}
+			row.DaysOverdue = now.Sub(*c.oldestDue).Hours() / 24
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func TestGetInbox_HighExposureCustomers(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	overdueSince := now.AddDate(0, 0, -10)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	customers := []exposureCustomer{
+		{id: "overdue", outstanding: 200_000, oldestDue: &overdueSince},
+		{id: "current", outstanding: 500_000},
+		{id: "small", outstanding: 50_000},
+	}
+	newService := func(t *testing.T, repo *inboxExposureRepo, cfg config.InboxConfig) *Service {
+		billingCfg := config.DefaultBillingConfig()
+		billingCfg.Inbox = cfg
+		return &Service{
+			repo:       repo,
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			billingCfg: config.NewStaticBillingConfigHolder(billingCfg),
+		}
+	}
+	categories := func(items []domain.InboxItem) map[string]string {
+		got := make(map[string]string, len(items))
+		for _, item := range items {
+			got[item.EntityID] = item.RiskCategory
+		}
+		return got
+	}
+
+	t.Run("overdue invoice required by default", func(t *testing.T) {
+		repo := &inboxExposureRepo{customers: customers}
+		resp, err := newService(t, repo, config.InboxConfig{}).GetInbox(ctx, domain.InboxRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.InboxFilter{HighExposureThreshold: 100_000, Currency: "USD"}, repo.filter)
+		assert.Equal(t, map[string]string{"overdue": "high_exposure"}, categories(resp.Items))
+		assert.Equal(t, 10, resp.Items[0].DaysOverdue)
+	})
+
+	t.Run("current balances included when enabled", func(t *testing.T) {
+		repo := &inboxExposureRepo{customers: customers}
+		resp, err := newService(t, repo, config.InboxConfig{IncludeCurrentExposure: true}).GetInbox(ctx, domain.InboxRequest{})
+		require.NoError(t, err)
+
+		assert.True(t, repo.filter.IncludeCurrentExposure)
+		assert.Equal(t, map[string]string{
+			"overdue": "high_exposure",
+			"current": "balance_exposure",
+		}, categories(resp.Items))
+		for _, item := range resp.Items {
+			if item.EntityID == "current" {
+				assert.Equal(t, int64(500_000), item.AmountDue)
+				assert.Zero(t, item.DaysOverdue)
+			}
+		}
+	})
+
+	t.Run("configured threshold is passed through", func(t *testing.T) {
+		repo := &inboxExposureRepo{customers: customers}
+		resp, err := newService(t, repo, config.InboxConfig{HighExposureThreshold: 300_000, IncludeCurrentExposure: true}).GetInbox(ctx, domain.InboxRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(300_000), repo.filter.HighExposureThreshold)
+		assert.Equal(t, map[string]string{"current": "balance_exposure"}, categories(resp.Items))
+	})
+}
+
+func TestGetInbox_Filters(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	repo := &inboxExposureRepo{}
+	svc := &Service{
+		repo:       repo,
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+
+	_, err := svc.GetInbox(ctx, domain.InboxRequest{RiskCategory: domain.RiskCategoryHighExposure, MinAmountDue: 50_000})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RiskCategoryHighExposure, repo.filter.RiskCategory)
+	assert.Equal(t, int64(50_000), repo.filter.MinAmountDue)
+
+	resp, err := svc.GetInbox(ctx, domain.InboxRequest{Currency: "eur"})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", repo.filter.Currency)
+	assert.Equal(t, "EUR", resp.Currency)
+
+	_, err = svc.GetInbox(ctx, domain.InboxRequest{Currency: "XXQ"})
+	assert.ErrorIs(t, err, domain.ErrInvalidCurrency)
+
+	_, err = svc.GetInbox(ctx, domain.InboxRequest{RiskCategory: "failed_payment"})
+	assert.ErrorIs(t, err, domain.ErrInvalidRiskCategory)
+
+	_, err = svc.GetInbox(ctx, domain.InboxRequest{MinAmountDue: -1})
+	assert.ErrorIs(t, err, domain.ErrInvalidMinAmountDue)
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/glebarez/sqlite"
@@ -14,6 +16,7 @@ import (
 	"github.com/smallbiznis/railzway/internal/orgcontext"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -28,6 +31,10 @@ func (m *mockAuditSvc) AuditLog(ctx context.Context, orgID *snowflake.ID, actorT
 	return args.Error(0)
 }
 
+func (m *mockAuditSvc) AuditLogTx(ctx context.Context, _ *gorm.DB, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
+	return m.AuditLog(ctx, orgID, actorType, actorID, action, targetType, targetID, metadata)
+}
+
 func (m *mockAuditSvc) List(ctx context.Context, req auditdomain.ListAuditLogRequest) (auditdomain.ListAuditLogResponse, error) {
 	args := m.Called(ctx, req)
 	return args.Get(0).(auditdomain.ListAuditLogResponse), args.Error(1)
@@ -53,6 +60,12 @@ func TestAssignmentLifecycle(t *testing.T) {
 		last_action_at TIMESTAMP,
 
 		snapshot_metadata TEXT,
+		handed_off_at TIMESTAMP,
+		handed_off_by TEXT,
+		handoff_note TEXT,
+		resolved_at TIMESTAMP,
+		resolved_by TEXT,
+		resolution_snapshot TEXT,
 		created_at TIMESTAMP NOT NULL,
 		updated_at TIMESTAMP NOT NULL
 	)`)
@@ -119,8 +132,13 @@ func TestAssignmentLifecycle(t *testing.T) {
 			AssignedTo:           "agent_008",
 			AssignmentTTLMinutes: 60,
 		})
-		assert.Error(t, err)
-		assert.Equal(t, domain.ErrAssignmentConflict, err)
+		assert.ErrorIs(t, err, domain.ErrAssignmentConflict)
+
+		var conflict *domain.AssignmentConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Empty(t, conflict.AssignedTo, "the assignee is hidden unless configured")
+		assert.Equal(t, domain.AssignmentConflictAnonymous, conflict.AssigneeName)
+		assert.Equal(t, domain.SLAFresh, conflict.SLAStatus)
 	})
 
 	t.Run("Release Assignment - Success", func(t *testing.T) {
@@ -185,3 +203,272 @@ func TestAssignmentLifecycle(t *testing.T) {
 		assert.Equal(t, "Escalated", assignment.ReleaseReason.String)
 	})
 }
+
+func TestSnoozeEntity(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_snoozes (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		snoozed_until TIMESTAMP NOT NULL,
+		reason TEXT,
+		snoozed_by TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	)`)
+
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_actions (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		action_type TEXT NOT NULL,
+		action_bucket TIMESTAMP NOT NULL,
+		idempotency_key TEXT,
+		metadata TEXT,
+		actor_type TEXT,
+		actor_id TEXT,
+		created_at TIMESTAMP NOT NULL
+	)`)
+	db.Exec(`CREATE UNIQUE INDEX ux_billing_operation_actions_bucket
+		ON billing_operation_actions(org_id, entity_type, entity_id, action_type, action_bucket)
+		WHERE idempotency_key IS NULL`)
+	db.Exec(`CREATE UNIQUE INDEX ux_billing_operation_actions_idempotency
+		ON billing_operation_actions(org_id, idempotency_key)
+		WHERE idempotency_key IS NOT NULL`)
+
+	node, _ := snowflake.NewNode(1)
+	now := time.Date(2025, 4, 10, 8, 0, 0, 0, time.UTC)
+	mockAudit := new(mockAuditSvc)
+
+	svc := NewService(Params{
+		DB:       db,
+		Log:      zap.NewNop(),
+		Clock:    clock.NewFakeClock(now),
+		GenID:    node,
+		AuditSvc: mockAudit,
+		Cfg:      config.Config{},
+	}).(*Service)
+
+	orgID := node.Generate()
+	entityID := node.Generate()
+	svc.repo = &snoozeRepo{Repository: svc.repo, known: entityID}
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	ctx = auditcontext.WithActor(ctx, "user", "user_123")
+
+	t.Run("Snooze Entity - Unknown Entity", func(t *testing.T) {
+		err := svc.SnoozeEntity(ctx, domain.EntityTypeInvoice, node.Generate().String(), now.Add(time.Hour), "later")
+		assert.ErrorIs(t, err, domain.ErrEntityNotFound)
+
+		var snoozes int64
+		db.Table("billing_operation_snoozes").Count(&snoozes)
+		assert.Zero(t, snoozes)
+	})
+
+	t.Run("Snooze Entity - Rejects Past Until", func(t *testing.T) {
+		err := svc.SnoozeEntity(ctx, domain.EntityTypeInvoice, entityID.String(), now.Add(-time.Hour), "later")
+		assert.Equal(t, domain.ErrInvalidSnoozeUntil, err)
+	})
+
+	t.Run("Snooze Entity - Success", func(t *testing.T) {
+		mockAudit.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "billing_operations.entity.snoozed", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		until := now.Add(72 * time.Hour)
+		err := svc.SnoozeEntity(ctx, domain.EntityTypeInvoice, entityID.String(), until, "Customer promised payment Friday")
+		assert.NoError(t, err)
+
+		var snooze struct {
+			SnoozedUntil time.Time
+			Reason       string
+			SnoozedBy    string
+		}
+		err = db.Table("billing_operation_snoozes").
+			Where("org_id = ? AND entity_type = ? AND entity_id = ?", orgID, domain.EntityTypeInvoice, entityID).
+			Take(&snooze).Error
+		assert.NoError(t, err)
+		assert.True(t, until.Equal(snooze.SnoozedUntil))
+		assert.Equal(t, "Customer promised payment Friday", snooze.Reason)
+		assert.Equal(t, "user_123", snooze.SnoozedBy)
+
+		var actions int64
+		db.Table("billing_operation_actions").
+			Where("org_id = ? AND entity_id = ? AND action_type = ?", orgID, entityID, domain.ActionTypeSnooze).
+			Count(&actions)
+		assert.Equal(t, int64(1), actions)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("Snooze Entity - Same Day Resnooze Is Recorded", func(t *testing.T) {
+		mockAudit.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "billing_operations.entity.snoozed", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := svc.SnoozeEntity(ctx, domain.EntityTypeInvoice, entityID.String(), now.Add(96*time.Hour), "Pushed to Monday")
+		assert.NoError(t, err)
+
+		var actions int64
+		db.Table("billing_operation_actions").
+			Where("org_id = ? AND entity_id = ? AND action_type = ?", orgID, entityID, domain.ActionTypeSnooze).
+			Count(&actions)
+		assert.Equal(t, int64(2), actions)
+		mockAudit.AssertExpectations(t)
+	})
+}
+
+// snoozeRepo is the real repository with entity snapshots stubbed: only the
+// known entity exists.
+type snoozeRepo struct {
+	domain.Repository
+	known snowflake.ID
+}
+
+func (r *snoozeRepo) WithTx(tx *gorm.DB) domain.Repository {
+	return &snoozeRepo{Repository: r.Repository.WithTx(tx), known: r.known}
+}
+
+func (r *snoozeRepo) LoadEntitySnapshot(_ context.Context, _ snowflake.ID, _ string, entityID snowflake.ID) (map[string]any, error) {
+	if entityID != r.known {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return map[string]any{"status": "FINALIZED"}, nil
+}
+
+func TestReleasedAssignmentIsUnassigned(t *testing.T) {
+	db := newSLATestDB(t)
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX ux_billing_assignments_entity ON billing_operation_assignments(org_id, entity_type, entity_id)").Error)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	mockAudit := new(mockAuditSvc)
+	svc := NewService(Params{
+		DB:       db,
+		Log:      zap.NewNop(),
+		Clock:    clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+		GenID:    node,
+		AuditSvc: mockAudit,
+		Cfg:      config.Config{},
+	}).(*Service)
+
+	orgID := node.Generate()
+	entityID := node.Generate()
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	ctx = auditcontext.WithActor(ctx, "user", "agent_a")
+
+	mockAudit.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "billing_operations.assignment.claimed", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	mockAudit.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "billing_operations.assignment.released", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	_, err = svc.ClaimAssignment(ctx, domain.ClaimAssignmentRequest{EntityType: domain.EntityTypeInvoice, EntityID: entityID.String(), AssignedTo: "agent_a"})
+	require.NoError(t, err)
+	require.NoError(t, svc.ReleaseAssignment(ctx, domain.ReleaseAssignmentRequest{EntityType: domain.EntityTypeInvoice, EntityID: entityID.String(), Reason: "handover"}))
+
+	countActions := func(actionType string) int64 {
+		var n int64
+		require.NoError(t, db.Table("billing_operation_actions").Where("action_type = ?", actionType).Count(&n).Error)
+		return n
+	}
+
+	t.Run("loads as no assignment", func(t *testing.T) {
+		row, err := svc.repo.LoadAssignment(ctx, orgID, domain.EntityTypeInvoice, entityID)
+		require.NoError(t, err)
+		assert.Nil(t, row)
+		record, err := svc.repo.LoadAssignmentForUpdate(ctx, orgID, domain.EntityTypeInvoice, entityID)
+		require.NoError(t, err)
+		assert.Nil(t, record)
+	})
+
+	t.Run("resolve and release are no-ops", func(t *testing.T) {
+		require.NoError(t, svc.ResolveAssignment(ctx, domain.ResolveAssignmentRequest{EntityType: domain.EntityTypeInvoice, EntityID: entityID.String(), Resolution: "paid"}))
+		require.NoError(t, svc.ReleaseAssignment(ctx, domain.ReleaseAssignmentRequest{EntityType: domain.EntityTypeInvoice, EntityID: entityID.String(), Reason: "again"}))
+
+		var status string
+		require.NoError(t, db.Raw(`SELECT status FROM billing_operation_assignments WHERE entity_id = ?`, entityID).Scan(&status).Error)
+		assert.Equal(t, domain.AssignmentStatusReleased, status)
+		assert.Zero(t, countActions(domain.ActionTypeResolve))
+		assert.Equal(t, int64(1), countActions(domain.ActionTypeRelease))
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("lists show no assignment", func(t *testing.T) {
+		released := assignmentFields(
+			sql.NullString{String: "agent_a", Valid: true},
+			time.Now(),
+			sql.NullTime{},
+			domain.AssignmentStatusReleased,
+			sql.NullTime{Time: time.Now(), Valid: true},
+			sql.NullString{String: "agent_a", Valid: true},
+			sql.NullString{String: "handover", Valid: true},
+			sql.NullTime{},
+			sql.NullString{},
+			sql.NullTime{},
+			time.Now(),
+		)
+		assert.Nil(t, activeAssignment(released))
+		assert.Nil(t, activeAssignment(domain.Assignment{}))
+		assert.NotNil(t, activeAssignment(domain.Assignment{AssignedTo: "agent_a", Status: domain.AssignmentStatusAssigned}))
+	})
+
+	t.Run("another agent can claim it", func(t *testing.T) {
+		mockAudit.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "billing_operations.assignment.claimed", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		resp, err := svc.ClaimAssignment(ctx, domain.ClaimAssignmentRequest{EntityType: domain.EntityTypeInvoice, EntityID: entityID.String(), AssignedTo: "agent_b"})
+		require.NoError(t, err)
+		assert.Equal(t, "agent_b", resp.Assignment.AssignedTo)
+	})
+}
+
+func TestClaimAssignmentConflictDetails(t *testing.T) {
+	db := newSLATestDB(t)
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX ux_billing_assignments_entity ON billing_operation_assignments(org_id, entity_type, entity_id)").Error)
+	require.NoError(t, db.Exec(`CREATE TABLE users (id BIGINT PRIMARY KEY, display_name TEXT)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE organization_members (org_id BIGINT, user_id BIGINT)`).Error)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	orgID := node.Generate()
+	aliceID := node.Generate()
+	require.NoError(t, db.Exec(`INSERT INTO users (id, display_name) VALUES (?, 'Alice')`, aliceID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO organization_members (org_id, user_id) VALUES (?, ?)`, orgID, aliceID).Error)
+
+	claimedAt := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	clk := clock.NewFakeClock(claimedAt)
+	newSvc := func(show bool) *Service {
+		mockAudit := new(mockAuditSvc)
+		mockAudit.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		return NewService(Params{
+			DB:       db,
+			Log:      zap.NewNop(),
+			Clock:    clk,
+			GenID:    node,
+			AuditSvc: mockAudit,
+			Cfg:      config.Config{BillingOpsConflictShowAssignee: show},
+		}).(*Service)
+	}
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	entityID := node.Generate()
+
+	_, err = newSvc(true).ClaimAssignment(ctx, domain.ClaimAssignmentRequest{EntityType: domain.EntityTypeInvoice, EntityID: entityID.String(), AssignedTo: aliceID.String()})
+	require.NoError(t, err)
+	clk.Advance(20 * time.Minute)
+
+	claimAsBob := func(svc *Service) *domain.AssignmentConflictError {
+		_, err := svc.ClaimAssignment(ctx, domain.ClaimAssignmentRequest{EntityType: domain.EntityTypeInvoice, EntityID: entityID.String(), AssignedTo: "bob"})
+		require.ErrorIs(t, err, domain.ErrAssignmentConflict)
+		var conflict *domain.AssignmentConflictError
+		require.ErrorAs(t, err, &conflict)
+		return conflict
+	}
+
+	t.Run("names the assignee when permitted", func(t *testing.T) {
+		conflict := claimAsBob(newSvc(true))
+		assert.Equal(t, aliceID.String(), conflict.AssignedTo)
+		assert.Equal(t, "Alice", conflict.AssigneeName)
+		assert.True(t, conflict.AssignedAt.Equal(claimedAt))
+		assert.Equal(t, domain.SLAFresh, conflict.SLAStatus)
+		assert.Equal(t, "20m", conflict.TimeSinceAssigned)
+	})
+
+	t.Run("hides the assignee otherwise", func(t *testing.T) {
+		conflict := claimAsBob(newSvc(false))
+		assert.Empty(t, conflict.AssignedTo)
+		assert.Equal(t, domain.AssignmentConflictAnonymous, conflict.AssigneeName)
+		assert.True(t, conflict.AssignedAt.Equal(claimedAt))
+		assert.Equal(t, domain.SLAFresh, conflict.SLAStatus)
+	})
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	auditcontext "github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// DisputeInvoiceLine marks amount of one line of a finalized invoice as
+// disputed. The invoice balance is unchanged; the disputed amount is only
+// taken out of the inbox risk score and collection risk level, and shown
+// separately so agents do not dun it. Disputing a line again replaces its
+// amount and reason.
+func (s *Service) DisputeInvoiceLine(ctx context.Context, invoiceID, lineID string, amount int64, reason string) (domain.InvoiceLineDispute, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.InvoiceLineDispute{}, domain.ErrInvalidOrganization
+	}
+
+	parsedInvoiceID, err := parseSnowflakeID(strings.TrimSpace(invoiceID))
+	if err != nil {
+		return domain.InvoiceLineDispute{}, domain.ErrInvalidEntityID
+	}
+	parsedLineID, err := parseSnowflakeID(strings.TrimSpace(lineID))
+	if err != nil {
+		return domain.InvoiceLineDispute{}, domain.ErrInvalidLineID
+	}
+	if amount <= 0 {
+		return domain.InvoiceLineDispute{}, domain.ErrInvalidDisputeAmount
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return domain.InvoiceLineDispute{}, domain.ErrDisputeReasonRequired
+	}
+
+	_, actorID := auditcontext.ActorFromContext(ctx)
+	disputedBy := strings.TrimSpace(actorID)
+	if disputedBy == "" {
+		return domain.InvoiceLineDispute{}, domain.ErrInvalidAssignee
+	}
+
+	line, err := s.repo.FindInvoiceLine(ctx, orgID, parsedInvoiceID, parsedLineID)
+	if err != nil {
+		return domain.InvoiceLineDispute{}, err
+	}
+	if line == nil {
+		return domain.InvoiceLineDispute{}, domain.ErrInvoiceLineNotFound
+	}
+	if line.InvoiceStatus != string(invoicedomain.InvoiceStatusFinalized) || line.VoidedAt.Valid {
+		return domain.InvoiceLineDispute{}, domain.ErrInvoiceNotDisputable
+	}
+	if amount > line.Amount {
+		return domain.InvoiceLineDispute{}, domain.ErrInvalidDisputeAmount
+	}
+
+	now := s.clock.Now().UTC()
+	var stored domain.InvoiceLineDisputeRecord
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+
+		stored, err = repoTx.UpsertInvoiceLineDispute(ctx, domain.InvoiceLineDisputeRecord{
+			ID:            s.genID.Generate(),
+			OrgID:         orgID,
+			InvoiceID:     parsedInvoiceID,
+			InvoiceItemID: parsedLineID,
+			Amount:        amount,
+			Reason:        reason,
+			DisputedBy:    disputedBy,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Every dispute write is its own action: the key keeps it out of the
+		// once-a-day bucket, so other lines of the invoice, or this line
+		// disputed again, are recorded the same day.
+		actionID := s.genID.Generate()
+		inserted, err := repoTx.InsertBillingAction(ctx, domain.BillingActionRecord{
+			ID:             actionID,
+			OrgID:          orgID,
+			EntityType:     domain.EntityTypeInvoice,
+			EntityID:       parsedInvoiceID,
+			ActionType:     domain.ActionTypeDisputeLine,
+			ActionBucket:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+			IdempotencyKey: domain.ActionTypeDisputeLine + ":" + actionID.String(),
+			Metadata: datatypes.JSONMap{
+				"dispute_id": stored.ID.String(),
+				"line_id":    parsedLineID.String(),
+				"amount":     amount,
+				"reason":     reason,
+			},
+			ActorType: "user",
+			ActorID:   disputedBy,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		if !inserted {
+			return domain.ErrActionNotRecorded
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.InvoiceLineDispute{}, err
+	}
+
+	dispute := domain.InvoiceLineDispute{
+		ID:         stored.ID.String(),
+		InvoiceID:  stored.InvoiceID.String(),
+		LineID:     stored.InvoiceItemID.String(),
+		Amount:     stored.Amount,
+		Reason:     stored.Reason,
+		DisputedBy: stored.DisputedBy,
+		CreatedAt:  stored.CreatedAt.UTC(),
+		UpdatedAt:  stored.UpdatedAt.UTC(),
+	}
+	if err := s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryFinancial,
+		action:     "billing_operations.invoice_line.disputed",
+		targetType: domain.EntityTypeInvoice,
+		targetID:   dispute.InvoiceID,
+		metadata: map[string]any{
+			"dispute_id":  dispute.ID,
+			"line_id":     dispute.LineID,
+			"amount":      dispute.Amount,
+			"reason":      dispute.Reason,
+			"disputed_by": dispute.DisputedBy,
+		},
+	}); err != nil {
+		return domain.InvoiceLineDispute{}, err
+	}
+	return dispute, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	auditcontext "github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestDisputeInvoiceLine(t *testing.T) {
+	node, _ := snowflake.NewNode(1)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	orgID := node.Generate()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`CREATE TABLE invoices (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			status TEXT NOT NULL,
+			voided_at TIMESTAMP
+		)`,
+		`CREATE TABLE invoice_items (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			invoice_id BIGINT NOT NULL,
+			amount BIGINT NOT NULL
+		)`,
+		`CREATE TABLE invoice_line_disputes (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			invoice_id BIGINT NOT NULL,
+			invoice_item_id BIGINT NOT NULL,
+			amount BIGINT NOT NULL,
+			reason TEXT NOT NULL,
+			disputed_by TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX ux_invoice_line_disputes_line ON invoice_line_disputes(org_id, invoice_item_id)`,
+		`CREATE TABLE billing_operation_actions (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id BIGINT NOT NULL,
+			action_type TEXT NOT NULL,
+			action_bucket TIMESTAMP NOT NULL,
+			idempotency_key TEXT,
+			metadata TEXT,
+			actor_type TEXT,
+			actor_id TEXT,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX ux_billing_operation_actions_bucket
+			ON billing_operation_actions(org_id, entity_type, entity_id, action_type, action_bucket)
+			WHERE idempotency_key IS NULL`,
+		`CREATE UNIQUE INDEX ux_billing_operation_actions_idempotency
+			ON billing_operation_actions(org_id, idempotency_key)
+			WHERE idempotency_key IS NOT NULL`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	invoiceID, lineID, otherLineID := node.Generate(), node.Generate(), node.Generate()
+	draftID, draftLineID := node.Generate(), node.Generate()
+	require.NoError(t, db.Exec(`INSERT INTO invoices (id, org_id, status) VALUES (?, ?, 'FINALIZED'), (?, ?, 'DRAFT')`, invoiceID, orgID, draftID, orgID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO invoice_items (id, org_id, invoice_id, amount) VALUES (?, ?, ?, 12000), (?, ?, ?, 3000), (?, ?, ?, 5000)`,
+		lineID, orgID, invoiceID, otherLineID, orgID, invoiceID, draftLineID, orgID, draftID).Error)
+
+	countActions := func() int64 {
+		var count int64
+		require.NoError(t, db.Table("billing_operation_actions").Where("action_type = ?", domain.ActionTypeDisputeLine).Count(&count).Error)
+		return count
+	}
+
+	fakeClock := clock.NewFakeClock(now)
+	svc := NewService(Params{
+		DB:    db,
+		Log:   zap.NewNop(),
+		Clock: fakeClock,
+		GenID: node,
+		Cfg:   config.Config{},
+	}).(*Service)
+	ctx := auditcontext.WithActor(orgcontext.WithOrgID(context.Background(), int64(orgID)), "user", "1001")
+
+	t.Run("records the disputed portion of a line", func(t *testing.T) {
+		dispute, err := svc.DisputeInvoiceLine(ctx, invoiceID.String(), lineID.String(), 4_000, " wrong seat count ")
+		require.NoError(t, err)
+		assert.Equal(t, invoiceID.String(), dispute.InvoiceID)
+		assert.Equal(t, lineID.String(), dispute.LineID)
+		assert.Equal(t, int64(4_000), dispute.Amount)
+		assert.Equal(t, "wrong seat count", dispute.Reason)
+		assert.Equal(t, "1001", dispute.DisputedBy)
+
+		var actions []domain.BillingActionRecord
+		require.NoError(t, db.Table("billing_operation_actions").Where("action_type = ?", domain.ActionTypeDisputeLine).Find(&actions).Error)
+		require.Len(t, actions, 1)
+		assert.Equal(t, invoiceID, actions[0].EntityID)
+
+		// Another line of the same invoice, the same day, is its own action.
+		_, err = svc.DisputeInvoiceLine(ctx, invoiceID.String(), otherLineID.String(), 1_000, "not ordered")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), countActions())
+	})
+
+	t.Run("disputing a line again replaces the dispute", func(t *testing.T) {
+		first, err := svc.DisputeInvoiceLine(ctx, invoiceID.String(), lineID.String(), 4_000, "wrong seat count")
+		require.NoError(t, err)
+		fakeClock.Advance(time.Hour)
+
+		second, err := svc.DisputeInvoiceLine(ctx, invoiceID.String(), lineID.String(), 6_000, "two seats were removed")
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Equal(t, int64(6_000), second.Amount)
+		assert.True(t, second.UpdatedAt.After(second.CreatedAt))
+
+		var count int64
+		require.NoError(t, db.Table("invoice_line_disputes").Where("org_id = ? AND invoice_item_id = ?", orgID, lineID).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+
+		// Each re-dispute is recorded, not collapsed into the day's first.
+		assert.Equal(t, int64(4), countActions())
+	})
+
+	t.Run("rejects invalid disputes", func(t *testing.T) {
+		cases := []struct {
+			name      string
+			invoiceID string
+			lineID    string
+			amount    int64
+			reason    string
+			want      error
+		}{
+			{"bad line id", invoiceID.String(), "line", 100, "reason", domain.ErrInvalidLineID},
+			{"zero amount", invoiceID.String(), lineID.String(), 0, "reason", domain.ErrInvalidDisputeAmount},
+			{"more than the line", invoiceID.String(), lineID.String(), 12_001, "reason", domain.ErrInvalidDisputeAmount},
+			{"missing reason", invoiceID.String(), lineID.String(), 100, " ", domain.ErrDisputeReasonRequired},
+			{"line on another invoice", draftID.String(), lineID.String(), 100, "reason", domain.ErrInvoiceLineNotFound},
+			{"draft invoice", draftID.String(), draftLineID.String(), 100, "reason", domain.ErrInvoiceNotDisputable},
+		}
+		for _, tc := range cases {
+			_, err := svc.DisputeInvoiceLine(ctx, tc.invoiceID, tc.lineID, tc.amount, tc.reason)
+			assert.ErrorIs(t, err, tc.want, tc.name)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// listDefaultsRepo serves an organization's configured list defaults and
+// records the limit each list query was run with.
+type listDefaultsRepo struct {
+	domain.Repository
+	defaults     domain.ListDefaults
+	inboxLimit   int
+	overdueLimit int
+}
+
+func (r *listDefaultsRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *listDefaultsRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *listDefaultsRepo) FetchListDefaults(context.Context, snowflake.ID) (domain.ListDefaults, error) {
+	return r.defaults, nil
+}
+
+func (r *listDefaultsRepo) ListInboxItems(_ context.Context, _ snowflake.ID, _ domain.InboxFilter, limit int, _ time.Time) ([]domain.InboxRow, error) {
+	r.inboxLimit = limit
+	return nil, nil
+}
+
+func (r *listDefaultsRepo) ListOverdueInvoices(_ context.Context, _ snowflake.ID, _ time.Time, page pagination.Pagination) ([]domain.OverdueInvoiceRow, pagination.PageInfo, error) {
+	r.overdueLimit = page.PageSize
+	return nil, pagination.PageInfo{}, nil
+}
+
+func TestListLimits_OrgDefaults(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	cases := []struct {
+		name        string
+		defaults    domain.ListDefaults
+		requested   int
+		wantInbox   int
+		wantOverdue int
+	}{
+		{name: "built-in defaults without org config", wantInbox: 25, wantOverdue: 25},
+		{name: "org defaults apply without a limit", defaults: domain.ListDefaults{Inbox: 80, Overdue: 10}, wantInbox: 80, wantOverdue: 10},
+		{name: "partial org defaults fall back per endpoint", defaults: domain.ListDefaults{Overdue: 40}, wantInbox: 25, wantOverdue: 40},
+		{name: "explicit limit wins over org defaults", defaults: domain.ListDefaults{Inbox: 80, Overdue: 10}, requested: 5, wantInbox: 5, wantOverdue: 5},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &listDefaultsRepo{defaults: tc.defaults}
+			svc := &Service{
+				repo:       repo,
+				log:        zaptest.NewLogger(t),
+				clock:      clock.NewFakeClock(now),
+				billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+			}
+
+			_, err := svc.GetInbox(ctx, domain.InboxRequest{Limit: tc.requested})
+			require.NoError(t, err)
+			_, err = svc.ListOverdueInvoices(ctx, tc.requested, "")
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantInbox, repo.inboxLimit)
+			assert.Equal(t, tc.wantOverdue, repo.overdueLimit)
+		})
+	}
+}
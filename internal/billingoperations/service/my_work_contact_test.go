@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type myWorkContactRepo struct {
+	domain.Repository
+	rows []domain.MyWorkRow
+}
+
+func (r *myWorkContactRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *myWorkContactRepo) ListMyWorkItems(context.Context, snowflake.ID, string, int, time.Time) ([]domain.MyWorkRow, error) {
+	return r.rows, nil
+}
+
+func TestGetMyWork_ContactChannels(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	repo := &myWorkContactRepo{rows: []domain.MyWorkRow{
+		{
+			AssignmentID:   "1",
+			EntityType:     domain.EntityTypeCustomer,
+			EntityID:       "42",
+			AssignedAt:     now.Add(-time.Hour),
+			CustomerEmail:  sql.NullString{String: "ap@acme.com", Valid: true},
+			CustomerPhone:  sql.NullString{String: "+62 21 555 0100", Valid: true},
+			ContactChannel: sql.NullString{String: "phone", Valid: true},
+			DoNotContact:   sql.NullBool{Bool: false, Valid: true},
+		},
+		{
+			AssignmentID:   "2",
+			EntityType:     domain.EntityTypeCustomer,
+			EntityID:       "43",
+			AssignedAt:     now.Add(-time.Hour),
+			CustomerEmail:  sql.NullString{String: "ap@globex.com", Valid: true},
+			CustomerPhone:  sql.NullString{String: "+1 555 0199", Valid: true},
+			ContactChannel: sql.NullString{String: "sms", Valid: true},
+			DoNotContact:   sql.NullBool{Bool: true, Valid: true},
+		},
+		{
+			AssignmentID:  "3",
+			EntityType:    domain.EntityTypeCustomer,
+			EntityID:      "44",
+			AssignedAt:    now.Add(-time.Hour),
+			CustomerEmail: sql.NullString{String: "ap@initech.com", Valid: true},
+		},
+	}}
+	newService := func(includePhone bool) *Service {
+		return &Service{
+			repo:                 repo,
+			log:                  zaptest.NewLogger(t),
+			clock:                clock.NewFakeClock(now),
+			billingCfg:           config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+			includeCustomerPhone: includePhone,
+		}
+	}
+
+	t.Run("contact channels appear when present", func(t *testing.T) {
+		resp, err := newService(true).GetMyWork(ctx, "agent", domain.MyWorkRequest{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, resp.Items, 3)
+
+		assert.Equal(t, "ap@acme.com", resp.Items[0].CustomerEmail)
+		assert.Equal(t, "+62 21 555 0100", resp.Items[0].CustomerPhone)
+		assert.Equal(t, "phone", resp.Items[0].PreferredContactChannel)
+		assert.False(t, resp.Items[0].DoNotContact)
+
+		assert.Empty(t, resp.Items[2].CustomerPhone)
+		assert.Empty(t, resp.Items[2].PreferredContactChannel)
+	})
+
+	t.Run("do-not-contact customers hide their channels", func(t *testing.T) {
+		resp, err := newService(true).GetMyWork(ctx, "agent", domain.MyWorkRequest{Limit: 10})
+		require.NoError(t, err)
+		item := resp.Items[1]
+		assert.True(t, item.DoNotContact)
+		assert.Empty(t, item.CustomerPhone)
+		assert.Empty(t, item.PreferredContactChannel)
+	})
+
+	t.Run("phone numbers can be left out", func(t *testing.T) {
+		resp, err := newService(false).GetMyWork(ctx, "agent", domain.MyWorkRequest{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Items[0].CustomerPhone)
+		assert.Equal(t, "phone", resp.Items[0].PreferredContactChannel)
+	})
+}
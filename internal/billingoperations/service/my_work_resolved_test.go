@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestGetMyWork_FlagsPaidWork(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	row := func(id string, snapshot string, current sql.NullInt64) domain.MyWorkRow {
+		return domain.MyWorkRow{
+			AssignmentID:     id,
+			EntityType:       domain.EntityTypeInvoice,
+			EntityID:         id,
+			AssignedAt:       now.Add(-time.Hour),
+			Status:           domain.AssignmentStatusInProgress,
+			SnapshotMetadata: datatypes.JSON(snapshot),
+			CurrentAmountDue: current,
+		}
+	}
+	rows := []domain.MyWorkRow{
+		row("1", `{"amount_due": 5000}`, sql.NullInt64{Int64: 0, Valid: true}),
+		row("2", `{"amount_due": 5000}`, sql.NullInt64{Int64: 2000, Valid: true}),
+		row("3", `{"amount_due": 5000}`, sql.NullInt64{}),
+		row("4", `{"amount_due": 0}`, sql.NullInt64{Int64: 0, Valid: true}),
+	}
+	// Two more paid items are beyond this page.
+	for i := range rows {
+		rows[i].NowResolvedCount = 3
+	}
+	repo := &myWorkContactRepo{rows: rows}
+	svc := &Service{
+		repo:       repo,
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(now),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+
+	resp, err := svc.GetMyWork(ctx, "agent", domain.MyWorkRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 4)
+
+	assert.True(t, resp.Items[0].IsNowResolved, "paid in full since the claim")
+	assert.Equal(t, domain.SuggestedActionCloseAsPaid, resp.Items[0].SuggestedAction)
+	assert.Equal(t, domain.AssignmentStatusInProgress, resp.Items[0].Status, "paid work is not released")
+
+	assert.False(t, resp.Items[1].IsNowResolved, "partially paid")
+	assert.False(t, resp.Items[2].IsNowResolved, "current balance unknown")
+	assert.False(t, resp.Items[3].IsNowResolved, "nothing was due at claim time")
+	assert.Empty(t, resp.Items[3].SuggestedAction)
+
+	assert.Equal(t, 3, resp.NowResolvedCount, "counted across all of the user's work")
+}
+
+func TestResolveAssignment_CloseAsPaid(t *testing.T) {
+	db := newSLATestDB(t)
+	for _, stmt := range []string{
+		`CREATE UNIQUE INDEX ux_billing_assignments_entity ON billing_operation_assignments(org_id, entity_type, entity_id)`,
+		`CREATE TABLE organization_billing_preferences (org_id BIGINT PRIMARY KEY, release_on_resolve BOOLEAN NOT NULL DEFAULT false)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	svc := NewService(Params{
+		DB:    db,
+		Log:   zap.NewNop(),
+		Clock: clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+		GenID: node,
+		Cfg:   config.Config{},
+	}).(*Service)
+	paid, unpaid := node.Generate(), node.Generate()
+	svc.repo = &closeAsPaidRepo{Repository: svc.repo, amountDue: map[snowflake.ID]int64{paid: 0, unpaid: 1200}}
+
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	ctx = auditcontext.WithActor(ctx, "user", "alice")
+	closeAsPaid := func(entityID snowflake.ID) (string, error) {
+		_, err := svc.ClaimAssignment(ctx, domain.ClaimAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID.String(),
+			AssignedTo: "alice",
+		})
+		require.NoError(t, err)
+		err = svc.ResolveAssignment(ctx, domain.ResolveAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID.String(),
+			Resolution: domain.SuggestedActionCloseAsPaid,
+		})
+		var status string
+		require.NoError(t, db.Raw(
+			`SELECT status FROM billing_operation_assignments WHERE entity_id = ?`, entityID,
+		).Scan(&status).Error)
+		return status, err
+	}
+
+	status, err := closeAsPaid(paid)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AssignmentStatusResolved, status)
+
+	var resolution struct {
+		ResolvedAt         sql.NullTime
+		ResolvedBy         sql.NullString
+		ResolutionSnapshot datatypes.JSON
+	}
+	require.NoError(t, db.Raw(
+		`SELECT resolved_at, resolved_by, resolution_snapshot FROM billing_operation_assignments WHERE entity_id = ?`, paid,
+	).Scan(&resolution).Error)
+	assert.True(t, resolution.ResolvedAt.Valid)
+	assert.Equal(t, "alice", resolution.ResolvedBy.String)
+	assert.JSONEq(t, `{"status":"FINALIZED","amount_due":0}`, string(resolution.ResolutionSnapshot), "the state the work was resolved on")
+
+	status, err = closeAsPaid(unpaid)
+	assert.ErrorIs(t, err, domain.ErrEntityNotPaid)
+	assert.NotEqual(t, domain.AssignmentStatusResolved, status, "work with a balance stays open")
+}
+
+// closeAsPaidRepo is the real repository with the amount due of each invoice
+// stubbed.
+type closeAsPaidRepo struct {
+	domain.Repository
+	amountDue map[snowflake.ID]int64
+}
+
+func (r *closeAsPaidRepo) WithTx(tx *gorm.DB) domain.Repository {
+	return &closeAsPaidRepo{Repository: r.Repository.WithTx(tx), amountDue: r.amountDue}
+}
+
+func (r *closeAsPaidRepo) LoadEntitySnapshot(_ context.Context, _ snowflake.ID, _ string, entityID snowflake.ID) (map[string]any, error) {
+	amountDue, ok := r.amountDue[entityID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return map[string]any{"status": "FINALIZED", "amount_due": amountDue}, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type overviewInvoice struct {
+	customerID   snowflake.ID
+	customerName string
+	status       string
+	amount       int64
+}
+
+// outstandingCustomersRepo aggregates a fixed set of invoices with the same
+// rules as the SQL: finalized invoices are outstanding, drafts are pending
+// and only counted when drafts are requested.
+type outstandingCustomersRepo struct {
+	domain.Repository
+	invoices []overviewInvoice
+}
+
+func (r *outstandingCustomersRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *outstandingCustomersRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *outstandingCustomersRepo) ListOutstandingCustomers(_ context.Context, _ snowflake.ID, _ time.Time, includeDrafts bool, _ pagination.Pagination) ([]domain.OutstandingCustomerRow, pagination.PageInfo, error) {
+	rows := make([]domain.OutstandingCustomerRow, 0, len(r.invoices))
+	index := map[snowflake.ID]int{}
+	for _, inv := range r.invoices {
+		if inv.status == "DRAFT" && !includeDrafts {
+			continue
+		}
+		i, ok := index[inv.customerID]
+		if !ok {
+			i = len(rows)
+			index[inv.customerID] = i
+			rows = append(rows, domain.OutstandingCustomerRow{CustomerID: inv.customerID, CustomerName: inv.customerName})
+		}
+		switch inv.status {
+		case "FINALIZED":
+			rows[i].Outstanding += inv.amount
+		case "DRAFT":
+			rows[i].Pending += inv.amount
+			rows[i].PendingInvoices++
+		}
+	}
+	return rows, pagination.PageInfo{}, nil
+}
+
+func TestListOutstandingCustomers_Drafts(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	invoices := []overviewInvoice{
+		{customerID: 1, customerName: "Finalized Co", status: "FINALIZED", amount: 10_000},
+		{customerID: 2, customerName: "Mixed Co", status: "FINALIZED", amount: 5_000},
+		{customerID: 2, customerName: "Mixed Co", status: "DRAFT", amount: 7_500},
+		{customerID: 3, customerName: "Draft Co", status: "DRAFT", amount: 50_000},
+		{customerID: 3, customerName: "Draft Co", status: "DRAFT", amount: 25_000},
+	}
+	svc := &Service{
+		repo:       &outstandingCustomersRepo{invoices: invoices},
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(now),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+
+	t.Run("drafts excluded by default", func(t *testing.T) {
+		resp, err := svc.ListOutstandingCustomers(ctx, 10, false, "")
+		require.NoError(t, err)
+
+		require.Len(t, resp.Customers, 2)
+		assert.Equal(t, "Finalized Co", resp.Customers[0].CustomerName)
+		assert.Equal(t, int64(10_000), resp.Customers[0].OutstandingBalance)
+		assert.Equal(t, "Mixed Co", resp.Customers[1].CustomerName)
+		assert.Equal(t, int64(5_000), resp.Customers[1].OutstandingBalance)
+		for _, customer := range resp.Customers {
+			assert.Zero(t, customer.PendingBalance)
+			assert.Zero(t, customer.PendingInvoices)
+		}
+	})
+
+	t.Run("drafts reported as pending", func(t *testing.T) {
+		resp, err := svc.ListOutstandingCustomers(ctx, 10, true, "")
+		require.NoError(t, err)
+
+		require.Len(t, resp.Customers, 3)
+		byName := map[string]domain.OutstandingCustomer{}
+		for _, customer := range resp.Customers {
+			byName[customer.CustomerName] = customer
+		}
+
+		assert.Equal(t, int64(10_000), byName["Finalized Co"].OutstandingBalance)
+		assert.Zero(t, byName["Finalized Co"].PendingBalance)
+
+		assert.Equal(t, int64(5_000), byName["Mixed Co"].OutstandingBalance)
+		assert.Equal(t, int64(7_500), byName["Mixed Co"].PendingBalance)
+		assert.Equal(t, 1, byName["Mixed Co"].PendingInvoices)
+
+		assert.Zero(t, byName["Draft Co"].OutstandingBalance)
+		assert.False(t, byName["Draft Co"].HasOverdueOutstanding)
+		assert.Equal(t, int64(75_000), byName["Draft Co"].PendingBalance)
+		assert.Equal(t, 2, byName["Draft Co"].PendingInvoices)
+	})
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// Friday morning; the following Monday is three calendar days later.
+var fridayDue = time.Date(2025, 5, 30, 9, 0, 0, 0, time.UTC)
+
+func TestOverdueCalendar_DaysOverdue(t *testing.T) {
+	monday := fridayDue.AddDate(0, 0, 3).Add(time.Hour)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	cases := []struct {
+		name     string
+		calendar domain.OverdueCalendar
+		now      time.Time
+		want     int
+	}{
+		{name: "calendar days over a weekend", now: monday, want: 3},
+		{name: "business days over a weekend", calendar: domain.NewOverdueCalendar(true, nil), now: monday, want: 1},
+		{name: "business days into the next week", calendar: domain.NewOverdueCalendar(true, nil), now: tuesday, want: 2},
+		{name: "holiday monday is skipped", calendar: domain.NewOverdueCalendar(true, []string{"2025-06-02"}), now: tuesday, want: 1},
+		{name: "holidays ignored for calendar days", calendar: domain.NewOverdueCalendar(false, []string{"2025-06-02"}), now: tuesday, want: 4},
+		{name: "not yet due", calendar: domain.NewOverdueCalendar(true, nil), now: fridayDue.Add(-time.Hour), want: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.calendar.DaysOverdue(fridayDue, tc.now))
+		})
+	}
+}
+
+// overdueCalendarRepo serves one invoice due on a Friday, both as an overdue
+// invoice and as an inbox item scored the way the SQL does: days overdue
+// follow the org's calendar and weigh 10 points each.
+type overdueCalendarRepo struct {
+	domain.Repository
+	calendar domain.OverdueCalendar
+}
+
+func (r *overdueCalendarRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *overdueCalendarRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return r.calendar, nil
+}
+
+func (r *overdueCalendarRepo) FetchListDefaults(context.Context, snowflake.ID) (domain.ListDefaults, error) {
+	return domain.ListDefaults{}, nil
+}
+
+func (r *overdueCalendarRepo) ListOverdueInvoices(context.Context, snowflake.ID, time.Time, pagination.Pagination) ([]domain.OverdueInvoiceRow, pagination.PageInfo, error) {
+	return []domain.OverdueInvoiceRow{{InvoiceID: 7, AmountDue: 5000, DueAt: fridayDue}}, pagination.PageInfo{}, nil
+}
+
+func (r *overdueCalendarRepo) ListInboxItems(_ context.Context, _ snowflake.ID, _ domain.InboxFilter, _ int, now time.Time) ([]domain.InboxRow, error) {
+	days := now.Sub(fridayDue).Hours() / 24
+	if r.calendar.BusinessDaysOnly {
+		days = float64(r.calendar.DaysOverdue(fridayDue, now))
+	}
+	return []domain.InboxRow{{
+		EntityType:  domain.EntityTypeInvoice,
+		EntityID:    "7",
+		AmountDue:   5000,
+		DueAt:       sql.NullTime{Time: fridayDue, Valid: true},
+		DaysOverdue: days,
+		RiskScore:   int(days*10) + 5,
+	}}, nil
+}
+
+func TestOverdueDays_CalendarVsBusinessDays(t *testing.T) {
+	now := fridayDue.AddDate(0, 0, 3)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	cases := []struct {
+		name      string
+		calendar  domain.OverdueCalendar
+		wantDays  int
+		wantScore int
+	}{
+		{name: "calendar days", wantDays: 3, wantScore: 35},
+		{name: "business days", calendar: domain.NewOverdueCalendar(true, nil), wantDays: 1, wantScore: 15},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &Service{
+				repo:       &overdueCalendarRepo{calendar: tc.calendar},
+				log:        zaptest.NewLogger(t),
+				clock:      clock.NewFakeClock(now),
+				billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+			}
+
+			overdue, err := svc.ListOverdueInvoices(ctx, 10, "")
+			require.NoError(t, err)
+			require.Len(t, overdue.Invoices, 1)
+			assert.Equal(t, tc.wantDays, overdue.Invoices[0].DaysOverdue)
+
+			inbox, err := svc.GetInbox(ctx, domain.InboxRequest{})
+			require.NoError(t, err)
+			require.Len(t, inbox.Items, 1)
+			assert.Equal(t, tc.wantDays, inbox.Items[0].DaysOverdue)
+			assert.Equal(t, tc.wantScore, inbox.Items[0].RiskScore)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type paymentIssueEvent struct {
+	customerID snowflake.ID
+	eventType  string
+	receivedAt time.Time
+}
+
+// paymentIssuesRepo filters a fixed set of payment events by event type the
+// same way the SQL does and records the types it was asked for.
+type paymentIssuesRepo struct {
+	domain.Repository
+	events     []paymentIssueEvent
+	eventTypes []string
+}
+
+func (r *paymentIssuesRepo) ListPaymentIssues(_ context.Context, _ snowflake.ID, eventTypes []string, _ bool, _ time.Time, _ int) ([]domain.PaymentIssueRow, error) {
+	r.eventTypes = eventTypes
+	rows := make([]domain.PaymentIssueRow, 0, len(r.events))
+	for _, event := range r.events {
+		for _, eventType := range eventTypes {
+			if event.eventType != eventType {
+				continue
+			}
+			rows = append(rows, domain.PaymentIssueRow{
+				CustomerID:  event.customerID,
+				IssueType:   event.eventType,
+				LastAttempt: sql.NullTime{Time: event.receivedAt, Valid: true},
+			})
+		}
+	}
+	return rows, nil
+}
+
+func TestListPaymentIssues_EventTypes(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	events := []paymentIssueEvent{
+		{customerID: 1, eventType: "payment_failed", receivedAt: now.Add(-time.Hour)},
+		{customerID: 2, eventType: "dispute.created", receivedAt: now.Add(-2 * time.Hour)},
+		{customerID: 3, eventType: "payment_succeeded", receivedAt: now.Add(-3 * time.Hour)},
+	}
+
+	cases := []struct {
+		name       string
+		eventTypes []string
+		wantTypes  []string
+		want       []string
+	}{
+		{name: "default is failed payments only", eventTypes: config.DefaultBillingConfig().PaymentIssues.EventTypes, wantTypes: []string{"payment_failed"}, want: []string{"payment_failed"}},
+		{name: "empty falls back to failed payments", eventTypes: nil, wantTypes: []string{"payment_failed"}, want: []string{"payment_failed"}},
+		{name: "configured types keep the event type", eventTypes: []string{"payment_failed", "dispute.created"}, wantTypes: []string{"payment_failed", "dispute.created"}, want: []string{"payment_failed", "dispute.created"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultBillingConfig()
+			cfg.PaymentIssues.EventTypes = tc.eventTypes
+			repo := &paymentIssuesRepo{events: events}
+			svc := &Service{
+				repo:       repo,
+				log:        zaptest.NewLogger(t),
+				clock:      clock.NewFakeClock(now),
+				billingCfg: config.NewStaticBillingConfigHolder(cfg),
+			}
+
+			resp, err := svc.ListPaymentIssues(ctx, 10)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantTypes, repo.eventTypes)
+			got := make([]string, 0, len(resp.Issues))
+			for _, issue := range resp.Issues {
+				got = append(got, issue.IssueType)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
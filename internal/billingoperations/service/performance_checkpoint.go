@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"go.uber.org/zap"
+)
+
+// scoringTarget is one operator scored by the daily performance aggregation.
+type scoringTarget struct {
+	OrgID      snowflake.ID
+	AssignedTo string
+}
+
+// after reports whether t sorts after the checkpointed org/user.
+func (t scoringTarget) after(cp scoringTarget) bool {
+	if t.OrgID != cp.OrgID {
+		return t.OrgID > cp.OrgID
+	}
+	return t.AssignedTo > cp.AssignedTo
+}
+
+// scoreInCheckpointedBatches scores targets in (org, user) order, in batches
+// of scoringBatchSize. After each batch the last scored target is persisted
+// as the period's checkpoint; a run that finds a checkpoint skips everything
+// up to it. The checkpoint is removed once the run completes, so the next run
+// for the period recomputes every snapshot again.
+func (s *Service) scoreInCheckpointedBatches(ctx context.Context, periodStart time.Time, targets []scoringTarget, score func([]scoringTarget) error) error {
+	sort.Slice(targets, func(i, j int) bool { return targets[j].after(targets[i]) })
+
+	cp, ok, err := s.loadScoringCheckpoint(ctx, periodStart)
+	if err != nil {
+		return err
+	}
+	if ok {
+		resumeAt := sort.Search(len(targets), func(i int) bool { return targets[i].after(cp) })
+		s.log.Info("resuming performance scoring from checkpoint",
+			zap.Time("period_start", periodStart),
+			zap.String("org_id", cp.OrgID.String()),
+			zap.String("user", cp.AssignedTo),
+			zap.Int("skipped", resumeAt),
+		)
+		targets = targets[resumeAt:]
+	}
+
+	for len(targets) > 0 {
+		n := min(s.scoringBatchSize, len(targets))
+		if err := score(targets[:n]); err != nil {
+			return err
+		}
+		if err := s.saveScoringCheckpoint(ctx, periodStart, targets[n-1]); err != nil {
+			return err
+		}
+		targets = targets[n:]
+	}
+	return s.db.WithContext(ctx).Exec(
+		`DELETE FROM finops_scoring_checkpoints WHERE period_type = ? AND period_start = ?`,
+		domain.PeriodTypeDaily, periodStart,
+	).Error
+}
+
+func (s *Service) loadScoringCheckpoint(ctx context.Context, periodStart time.Time) (scoringTarget, bool, error) {
+	var row struct {
+		LastOrgID  snowflake.ID
+		LastUserID string
+	}
+	res := s.db.WithContext(ctx).Raw(
+		`SELECT last_org_id, last_user_id FROM finops_scoring_checkpoints WHERE period_type = ? AND period_start = ?`,
+		domain.PeriodTypeDaily, periodStart,
+	).Scan(&row)
+	if res.Error != nil {
+		return scoringTarget{}, false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return scoringTarget{}, false, nil
+	}
+	return scoringTarget{OrgID: row.LastOrgID, AssignedTo: row.LastUserID}, true, nil
+}
+
+func (s *Service) saveScoringCheckpoint(ctx context.Context, periodStart time.Time, last scoringTarget) error {
+	return s.db.WithContext(ctx).Exec(`
+		INSERT INTO finops_scoring_checkpoints (period_type, period_start, last_org_id, last_user_id, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (period_type, period_start) DO UPDATE
+		SET last_org_id = EXCLUDED.last_org_id, last_user_id = EXCLUDED.last_user_id, updated_at = EXCLUDED.updated_at
+	`, domain.PeriodTypeDaily, periodStart, last.OrgID, last.AssignedTo, s.clock.Now().UTC()).Error
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+)
+
+const (
+	performanceExportRowSnapshot = "snapshot"
+	performanceExportRowSummary  = "summary"
+)
+
+var performanceExportHeader = []string{
+	"Row Type", "User ID", "Period Type", "Period Start", "Period End", "Scoring Version",
+	"Total Score", "Responsiveness", "Completion", "Effectiveness", "Risk",
+	"Avg Response Minutes", "Completion Ratio", "Escalation Ratio", "Exposure Handled",
+	"Total Assigned", "Total Resolved", "Total Escalated",
+}
+
+// ExportTeamPerformance writes every team snapshot in the period as CSV,
+// grouped by user. Rows are written as the repository reads them, so the
+// export is never held in memory; with IncludeSummary only the current
+// user's snapshots are kept, for the summary row that closes their group.
+func (s *Service) ExportTeamPerformance(ctx context.Context, req domain.ExportTeamPerformanceRequest, w io.Writer) error {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.ErrInvalidOrganization
+	}
+
+	excludeUserIDs, err := normalizeExcludeUserIDs(req.ExcludeUserIDs)
+	if err != nil {
+		return err
+	}
+
+	start := req.From
+	end := req.To
+	if end.IsZero() {
+		end = s.clock.Now().UTC()
+	}
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -30)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(performanceExportHeader); err != nil {
+		return err
+	}
+
+	var currentUserID string
+	var userSnapshots []domain.FinOpsScoreSnapshot
+	writeSummary := func() error {
+		if !req.IncludeSummary || len(userSnapshots) == 0 {
+			return nil
+		}
+		summary := summarizeMemberPerformance(currentUserID, userSnapshots)
+		userSnapshots = userSnapshots[:0]
+		return writer.Write([]string{
+			performanceExportRowSummary,
+			summary.UserID,
+			req.PeriodType,
+			formatExportTime(start),
+			formatExportTime(end),
+			"",
+			strconv.Itoa(summary.AvgScore),
+			"", "", "", "",
+			formatExportFloat(summary.MetricsSummary.AvgResponseMinutes),
+			formatExportFloat(summary.MetricsSummary.CompletionRatio),
+			formatExportFloat(summary.MetricsSummary.EscalationRatio),
+			strconv.FormatInt(summary.MetricsSummary.ExposureHandled, 10),
+			"", "", "",
+		})
+	}
+
+	filter := s.newTeamViewsFilter()
+	err = s.repo.StreamSnapshotsByOrg(ctx, orgID, req.PeriodType, start, end, excludeUserIDs, func(snap domain.FinOpsScoreSnapshot) error {
+		hidden, err := filter.hidden(ctx, orgID, snap.UserID)
+		if err != nil {
+			return err
+		}
+		if hidden {
+			return nil
+		}
+		if snap.UserID != currentUserID {
+			if err := writeSummary(); err != nil {
+				return err
+			}
+			currentUserID = snap.UserID
+		}
+		if req.IncludeSummary {
+			userSnapshots = append(userSnapshots, snap)
+		}
+		return writer.Write([]string{
+			performanceExportRowSnapshot,
+			snap.UserID,
+			snap.PeriodType,
+			formatExportTime(snap.PeriodStart),
+			formatExportTime(snap.PeriodEnd),
+			snap.ScoringVersion,
+			strconv.Itoa(snap.Scores.Total),
+			strconv.Itoa(snap.Scores.Responsiveness),
+			strconv.Itoa(snap.Scores.Completion),
+			strconv.Itoa(snap.Scores.Effectiveness),
+			strconv.Itoa(snap.Scores.Risk),
+			formatExportFloat(float64(snap.Metrics.AvgResponseMS) / 60000.0),
+			formatExportFloat(snap.Metrics.CompletionRatio),
+			formatExportFloat(snap.Metrics.EscalationRate),
+			strconv.FormatInt(snap.Metrics.ExposureHandled, 10),
+			strconv.Itoa(snap.Metrics.TotalAssigned),
+			strconv.Itoa(snap.Metrics.TotalResolved),
+			strconv.Itoa(snap.Metrics.TotalEscalated),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeSummary(); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatExportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatExportFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
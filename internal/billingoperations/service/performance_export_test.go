@@ -0,0 +1,117 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func (r *teamViewRepo) StreamSnapshotsByOrg(ctx context.Context, orgID snowflake.ID, periodType string, start, end time.Time, excludeUserIDs []string, fn func(domain.FinOpsScoreSnapshot) error) error {
+	snapshots, err := r.FindSnapshotsByOrg(ctx, orgID, periodType, start, end, excludeUserIDs)
+	if err != nil {
+		return err
+	}
+	for _, snap := range snapshots {
+		if err := fn(snap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExportTeamPerformance(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return time.Date(2025, 5, n, 0, 0, 0, 0, time.UTC) }
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	snapshot := func(userID string, start time.Time, total int, assigned, resolved int) domain.FinOpsScoreSnapshot {
+		return domain.FinOpsScoreSnapshot{
+			UserID:         userID,
+			PeriodType:     domain.PeriodTypeDaily,
+			PeriodStart:    start,
+			PeriodEnd:      start.AddDate(0, 0, 1),
+			ScoringVersion: domain.ScoringVersionV1EqualWeight,
+			Scores:         domain.PerformanceScores{Total: total, Responsiveness: total, Completion: total},
+			Metrics: domain.PerformanceMetrics{
+				AvgResponseMS:   1_800_000,
+				TotalAssigned:   assigned,
+				TotalResolved:   resolved,
+				ExposureHandled: 1_000,
+			},
+		}
+	}
+	repo := &teamViewRepo{snapshots: []domain.FinOpsScoreSnapshot{
+		snapshot("1001", day(1), 80, 4, 2),
+		snapshot("1001", day(2), 60, 4, 4),
+		snapshot("1002", day(1), 90, 2, 2),
+		snapshot(domain.SLAMonitorActorID, day(1), 10, 1, 0),
+	}}
+	svc := &Service{
+		repo:       repo,
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(now),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+	export := func(t *testing.T, req domain.ExportTeamPerformanceRequest) [][]string {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, svc.ExportTeamPerformance(ctx, req, &buf))
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.NotEmpty(t, records)
+		assert.Equal(t, performanceExportHeader, records[0])
+		return records[1:]
+	}
+
+	t.Run("writes one row per snapshot", func(t *testing.T) {
+		rows := export(t, domain.ExportTeamPerformanceRequest{
+			GetPerformanceRequest: domain.GetPerformanceRequest{PeriodType: domain.PeriodTypeDaily},
+		})
+		require.Len(t, rows, 3)
+		assert.Equal(t, []string{
+			"snapshot", "1001", "daily", "2025-05-01T00:00:00Z", "2025-05-02T00:00:00Z", "v1_equal_weight",
+			"80", "80", "80", "0", "0",
+			"30.0000", "0.0000", "0.0000", "1000",
+			"4", "2", "0",
+		}, rows[0])
+		assert.Equal(t, "1002", rows[2][1])
+	})
+
+	t.Run("closes each user with a summary row", func(t *testing.T) {
+		rows := export(t, domain.ExportTeamPerformanceRequest{
+			GetPerformanceRequest: domain.GetPerformanceRequest{PeriodType: domain.PeriodTypeDaily},
+			IncludeSummary:        true,
+		})
+		require.Len(t, rows, 5)
+		var rowTypes []string
+		for _, row := range rows {
+			rowTypes = append(rowTypes, row[0]+":"+row[1])
+		}
+		assert.Equal(t, []string{"snapshot:1001", "snapshot:1001", "summary:1001", "snapshot:1002", "summary:1002"}, rowTypes)
+
+		team, err := svc.GetTeamPerformance(ctx, domain.GetPerformanceRequest{PeriodType: domain.PeriodTypeDaily})
+		require.NoError(t, err)
+		summary := team.Snapshots[0]
+		assert.Equal(t, "70", rows[2][6])
+		assert.Equal(t, formatExportFloat(summary.MetricsSummary.CompletionRatio), rows[2][12])
+		assert.Equal(t, "2000", rows[2][14])
+		assert.Equal(t, "2025-05-02T09:00:00Z", rows[2][3])
+	})
+
+	t.Run("requires an organization", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := svc.ExportTeamPerformance(context.Background(), domain.ExportTeamPerformanceRequest{}, &buf)
+		assert.ErrorIs(t, err, domain.ErrInvalidOrganization)
+		assert.Zero(t, buf.Len())
+	})
+}
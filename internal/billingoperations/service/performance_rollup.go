@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	dbpkg "github.com/smallbiznis/railzway/pkg/db"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AggregateWeeklyPerformance rolls daily snapshots up into the most recent
+// Monday-to-Monday UTC week whose days have all been scored.
+func (s *Service) AggregateWeeklyPerformance(ctx context.Context) error {
+	start, end := performanceRollupPeriod(domain.PeriodTypeWeekly, s.lastScoredDay())
+	return s.aggregatePerformanceRollup(ctx, domain.PeriodTypeWeekly, start, end)
+}
+
+// AggregateMonthlyPerformance rolls daily snapshots up into the most recent
+// calendar month (UTC) whose days have all been scored.
+func (s *Service) AggregateMonthlyPerformance(ctx context.Context) error {
+	start, end := performanceRollupPeriod(domain.PeriodTypeMonthly, s.lastScoredDay())
+	return s.aggregatePerformanceRollup(ctx, domain.PeriodTypeMonthly, start, end)
+}
+
+// lastScoredDay returns the start of the day the daily aggregation scores.
+func (s *Service) lastScoredDay() time.Time {
+	now := s.clock.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.AddDate(0, 0, -s.scoringDayOffsetOrDefault())
+}
+
+// performanceRollupPeriod returns the latest week or month that ends no
+// later than the end of lastScored, so every day in it has a daily
+// snapshot.
+func performanceRollupPeriod(periodType string, lastScored time.Time) (time.Time, time.Time) {
+	boundary := lastScored.AddDate(0, 0, 1)
+	switch periodType {
+	case domain.PeriodTypeMonthly:
+		end := time.Date(boundary.Year(), boundary.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return end.AddDate(0, -1, 0), end
+	default:
+		sinceMonday := (int(boundary.Weekday()) + 6) % 7
+		end := boundary.AddDate(0, 0, -sinceMonday)
+		return end.AddDate(0, 0, -7), end
+	}
+}
+
+// aggregatePerformanceRollup rebuilds the periodType snapshots for
+// [start, end) from the daily snapshots in that range. Periods that have
+// not fully elapsed are refused with ErrIncompletePeriod.
+func (s *Service) aggregatePerformanceRollup(ctx context.Context, periodType string, start, end time.Time) error {
+	now := s.clock.Now().UTC()
+	if end.After(now) {
+		s.log.Warn("refusing to roll up incomplete period",
+			zap.String("period_type", periodType),
+			zap.Time("period_start", start),
+			zap.Time("period_end", end),
+			zap.Time("now", now),
+		)
+		return domain.ErrIncompletePeriod
+	}
+
+	var rows []domain.FinOpsSnapshotRow
+	if err := dbpkg.SkipTenantScope(s.db.WithContext(ctx)).Table("finops_performance_snapshots").
+		Select("org_id, user_id, period_type, period_start, period_end, scoring_version, metrics, scores").
+		Where("period_type = ? AND period_start >= ? AND period_start < ?", domain.PeriodTypeDaily, start, end).
+		Order("org_id, user_id, period_start").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	type rollupKey struct {
+		orgID  snowflake.ID
+		userID string
+	}
+	days := make(map[rollupKey][]domain.PerformanceMetrics)
+	var keys []rollupKey
+	filter := s.newTeamViewsFilter()
+	for _, row := range rows {
+		hidden, err := filter.hidden(ctx, row.OrgID, row.UserID)
+		if err != nil {
+			return err
+		}
+		if hidden {
+			continue
+		}
+		var metrics domain.PerformanceMetrics
+		if err := json.Unmarshal(row.Metrics, &metrics); err != nil {
+			s.log.Warn("skipping unreadable daily snapshot",
+				zap.String("org_id", row.OrgID.String()),
+				zap.String("user", row.UserID),
+				zap.Time("period_start", row.PeriodStart),
+				zap.Error(err),
+			)
+			continue
+		}
+		key := rollupKey{orgID: row.OrgID, userID: row.UserID}
+		if _, ok := days[key]; !ok {
+			keys = append(keys, key)
+		}
+		days[key] = append(days[key], metrics)
+	}
+
+	db := s.db.Session(&gorm.Session{NewDB: true, Context: ctx})
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		metrics := rollupPerformanceMetrics(days[key])
+		snapshot := domain.FinOpsScoreSnapshot{
+			OrgID:          key.orgID.String(),
+			UserID:         key.userID,
+			PeriodType:     periodType,
+			PeriodStart:    start,
+			PeriodEnd:      end,
+			ScoringVersion: domain.ScoringVersionV1EqualWeight,
+			Metrics:        metrics,
+			Scores:         scorePerformance(metrics),
+		}
+		if err := s.upsertPerformanceSnapshot(db, key.orgID, snapshot, now); err != nil {
+			s.log.Error("failed to persist rollup snapshot",
+				zap.String("period_type", periodType),
+				zap.String("user", key.userID),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// rollupPerformanceMetrics combines daily metrics into one period. Counts
+// and exposure are summed, the average response time is weighted by the
+// assignments of the days that recorded one, and the ratios are recomputed
+// from the summed counts.
+func rollupPerformanceMetrics(days []domain.PerformanceMetrics) domain.PerformanceMetrics {
+	var (
+		metrics        domain.PerformanceMetrics
+		responseTotal  int64
+		responseWeight int64
+	)
+	for _, day := range days {
+		metrics.TotalAssigned += day.TotalAssigned
+		metrics.TotalResolved += day.TotalResolved
+		metrics.TotalEscalated += day.TotalEscalated
+		metrics.ExposureHandled += day.ExposureHandled
+		if day.AvgResponseMS > 0 && day.TotalAssigned > 0 {
+			responseTotal += day.AvgResponseMS * int64(day.TotalAssigned)
+			responseWeight += int64(day.TotalAssigned)
+		}
+	}
+	if responseWeight > 0 {
+		metrics.AvgResponseMS = responseTotal / responseWeight
+	}
+	if metrics.TotalAssigned > 0 {
+		metrics.CompletionRatio = float64(metrics.TotalResolved) / float64(metrics.TotalAssigned)
+		metrics.EscalationRate = float64(metrics.TotalEscalated) / float64(metrics.TotalAssigned)
+	}
+	return metrics
+}
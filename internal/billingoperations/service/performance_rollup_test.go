@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestPerformanceRollupPeriod(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+
+	cases := []struct {
+		name       string
+		periodType string
+		lastScored time.Time
+		wantStart  time.Time
+		wantEnd    time.Time
+	}{
+		{name: "sunday completes its week", periodType: domain.PeriodTypeWeekly, lastScored: day(3, 8), wantStart: day(3, 2), wantEnd: day(3, 9)},
+		{name: "mid-week keeps the previous week", periodType: domain.PeriodTypeWeekly, lastScored: day(3, 11), wantStart: day(3, 2), wantEnd: day(3, 9)},
+		{name: "monday starts a new week", periodType: domain.PeriodTypeWeekly, lastScored: day(3, 9), wantStart: day(3, 2), wantEnd: day(3, 9)},
+		{name: "last day completes its month", periodType: domain.PeriodTypeMonthly, lastScored: day(2, 28), wantStart: day(2, 1), wantEnd: day(3, 1)},
+		{name: "mid-month keeps the previous month", periodType: domain.PeriodTypeMonthly, lastScored: day(3, 15), wantStart: day(2, 1), wantEnd: day(3, 1)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end := performanceRollupPeriod(tc.periodType, tc.lastScored)
+			assert.Equal(t, tc.wantStart, start)
+			assert.Equal(t, tc.wantEnd, end)
+		})
+	}
+}
+
+func TestRollupPerformanceMetrics(t *testing.T) {
+	got := rollupPerformanceMetrics([]domain.PerformanceMetrics{
+		{TotalAssigned: 3, TotalResolved: 3, TotalEscalated: 0, ExposureHandled: 5000, AvgResponseMS: 60_000},
+		{TotalAssigned: 1, TotalResolved: 0, TotalEscalated: 1, ExposureHandled: 0, AvgResponseMS: 180_000},
+		// A day without any action has no response time to weigh in.
+		{TotalAssigned: 4, TotalResolved: 1, TotalEscalated: 1},
+	})
+
+	assert.Equal(t, 8, got.TotalAssigned)
+	assert.Equal(t, 4, got.TotalResolved)
+	assert.Equal(t, 2, got.TotalEscalated)
+	assert.Equal(t, int64(5000), got.ExposureHandled)
+	assert.Equal(t, int64(90_000), got.AvgResponseMS)
+	assert.InDelta(t, 0.5, got.CompletionRatio, 1e-9)
+	assert.InDelta(t, 0.25, got.EscalationRate, 1e-9)
+}
+
+func TestAggregateWeeklyPerformance(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	db.Exec(`CREATE TABLE IF NOT EXISTS finops_performance_snapshots (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		user_id TEXT NOT NULL,
+		period_type TEXT NOT NULL,
+		period_start TIMESTAMP NOT NULL,
+		period_end TIMESTAMP NOT NULL,
+		scoring_version TEXT NOT NULL,
+		metrics TEXT NOT NULL,
+		scores TEXT NOT NULL,
+		total_score INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE (org_id, user_id, period_type, period_start)
+	)`)
+
+	// Monday 2026-03-09: yesterday's scoring completed the week of March 2.
+	now := time.Date(2026, 3, 9, 6, 0, 0, 0, time.UTC)
+	weekStart := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	node, _ := snowflake.NewNode(1)
+	svc := &Service{db: db, log: zap.NewNop(), clock: clock.NewFakeClock(now), genID: node}
+	orgID := node.Generate()
+
+	insertDaily := func(userID string, start time.Time, metrics domain.PerformanceMetrics) {
+		raw, _ := json.Marshal(metrics)
+		db.Exec("INSERT INTO finops_performance_snapshots (id, org_id, user_id, period_type, period_start, period_end, scoring_version, metrics, scores, total_score, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			node.Generate(), orgID, userID, domain.PeriodTypeDaily, start, start.Add(24*time.Hour), domain.ScoringVersionV1EqualWeight, string(raw), "{}", 0, now, now)
+	}
+	insertDaily("user_a", weekStart, domain.PerformanceMetrics{TotalAssigned: 2, TotalResolved: 2, ExposureHandled: 8000, AvgResponseMS: 1_800_000})
+	insertDaily("user_a", weekStart.AddDate(0, 0, 6), domain.PerformanceMetrics{TotalAssigned: 2, TotalResolved: 1, TotalEscalated: 1, ExposureHandled: 4000, AvgResponseMS: 1_800_000})
+	// Outside the week: the Sunday before and the Monday after.
+	insertDaily("user_a", weekStart.AddDate(0, 0, -1), domain.PerformanceMetrics{TotalAssigned: 9, TotalResolved: 9})
+	insertDaily("user_a", weekStart.AddDate(0, 0, 7), domain.PerformanceMetrics{TotalAssigned: 9, TotalResolved: 9})
+	insertDaily("user_b", weekStart.AddDate(0, 0, 3), domain.PerformanceMetrics{TotalAssigned: 1})
+
+	weekly := func() map[string]domain.FinOpsSnapshotRow {
+		var rows []domain.FinOpsSnapshotRow
+		db.Table("finops_performance_snapshots").Where("period_type = ?", domain.PeriodTypeWeekly).Scan(&rows)
+		out := make(map[string]domain.FinOpsSnapshotRow, len(rows))
+		for _, r := range rows {
+			out[r.UserID] = r
+		}
+		return out
+	}
+
+	require.NoError(t, svc.AggregateWeeklyPerformance(context.Background()))
+	rows := weekly()
+	require.Len(t, rows, 2)
+
+	a := rows["user_a"]
+	assert.True(t, a.PeriodStart.Equal(weekStart), "got %v", a.PeriodStart)
+	assert.True(t, a.PeriodEnd.Equal(weekStart.AddDate(0, 0, 7)), "got %v", a.PeriodEnd)
+	var metrics domain.PerformanceMetrics
+	require.NoError(t, json.Unmarshal(a.Metrics, &metrics))
+	assert.Equal(t, domain.PerformanceMetrics{
+		AvgResponseMS:   1_800_000,
+		CompletionRatio: 0.75,
+		EscalationRate:  0.25,
+		ExposureHandled: 12000,
+		TotalAssigned:   4,
+		TotalResolved:   3,
+		TotalEscalated:  1,
+	}, metrics)
+	var scores domain.PerformanceScores
+	require.NoError(t, json.Unmarshal(a.Scores, &scores))
+	assert.Equal(t, scorePerformance(metrics), scores)
+
+	// Running again updates the rollup in place.
+	require.NoError(t, svc.AggregateWeeklyPerformance(context.Background()))
+	assert.Len(t, weekly(), 2)
+
+	t.Run("monthly rollup covers the previous month", func(t *testing.T) {
+		insertDaily("user_a", time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), domain.PerformanceMetrics{TotalAssigned: 5, TotalResolved: 5})
+		insertDaily("user_a", time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC), domain.PerformanceMetrics{TotalAssigned: 5, TotalResolved: 0})
+		require.NoError(t, svc.AggregateMonthlyPerformance(context.Background()))
+
+		var rows []domain.FinOpsSnapshotRow
+		db.Table("finops_performance_snapshots").Where("period_type = ?", domain.PeriodTypeMonthly).Scan(&rows)
+		require.Len(t, rows, 1)
+		assert.True(t, rows[0].PeriodStart.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)), "got %v", rows[0].PeriodStart)
+		var metrics domain.PerformanceMetrics
+		require.NoError(t, json.Unmarshal(rows[0].Metrics, &metrics))
+		assert.Equal(t, 10, metrics.TotalAssigned)
+		assert.InDelta(t, 0.5, metrics.CompletionRatio, 1e-9)
+	})
+}
@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,6 +17,7 @@ import (
 	"github.com/smallbiznis/railzway/internal/config"
 	"github.com/smallbiznis/railzway/internal/orgcontext"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
@@ -158,7 +163,8 @@ func TestAggregateDailyPerformance_Immutability(t *testing.T) {
 		scores TEXT NOT NULL,
 		total_score INTEGER NOT NULL,
 		created_at TIMESTAMP NOT NULL,
-		updated_at TIMESTAMP NOT NULL
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE (org_id, user_id, period_type, period_start)
 	)`)
 	// Setup assignments table for active user check
 	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_assignments (
@@ -177,10 +183,11 @@ func TestAggregateDailyPerformance_Immutability(t *testing.T) {
 
 	node, _ := snowflake.NewNode(1)
 	repo := repository.NewRepository(db)
+	fakeClock := clock.NewFakeClock(time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC))
 	svc := &Service{
 		db:         db,
 		log:        zap.NewNop(),
-		clock:      clock.SystemClock{},
+		clock:      fakeClock,
 		genID:      node,
 		billingCfg: &config.BillingConfigHolder{},
 		repo:       repo,
@@ -188,7 +195,7 @@ func TestAggregateDailyPerformance_Immutability(t *testing.T) {
 
 	// Mock clock/time - Aggregate uses "Yesterday" relative to Now
 	// But in test we can insert data for "Yesterday"
-	now := time.Now().UTC()
+	now := fakeClock.Now()
 	yesterdayStart := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
 
 	orgID := node.Generate()
@@ -214,28 +221,498 @@ func TestAggregateDailyPerformance_Immutability(t *testing.T) {
 	db.Table("finops_performance_snapshots").Count(&count)
 	assert.Equal(t, int64(1), count)
 
-	var firstSnap struct {
-		ID        string
-		CreatedAt time.Time
+	type snapshotRow struct {
+		ID             string
+		ScoringVersion string
+		CreatedAt      time.Time
+		UpdatedAt      time.Time
 	}
+	var firstSnap snapshotRow
 	db.Table("finops_performance_snapshots").First(&firstSnap)
 
 	// 2. Second Run (Recompute)
-	// Should Delete and Insert new
-	// Wait a bit or manually update created_at to verify change?
-	// The new insert will have a NEW ID (snowflake generated)
-
+	// Upserts the existing row: same identity, refreshed recompute time.
+	fakeClock.Advance(time.Hour)
 	err = svc.AggregateDailyPerformance(context.Background())
 	assert.NoError(t, err)
 
 	db.Table("finops_performance_snapshots").Count(&count)
-	assert.Equal(t, int64(1), count) // Still 1 record due to delete
+	assert.Equal(t, int64(1), count) // Still 1 record, updated in place
 
-	var secondSnap struct {
-		ID        string
-		CreatedAt time.Time
-	}
+	var secondSnap snapshotRow
 	db.Table("finops_performance_snapshots").First(&secondSnap)
 
-	assert.NotEqual(t, firstSnap.ID, secondSnap.ID, "Snapshot ID should change (Delete+Insert)")
+	assert.Equal(t, firstSnap.ID, secondSnap.ID, "Snapshot should be updated in place")
+	assert.True(t, secondSnap.CreatedAt.Equal(firstSnap.CreatedAt), "created_at should be kept")
+	assert.True(t, secondSnap.UpdatedAt.After(firstSnap.UpdatedAt), "updated_at should record the recompute")
+	assert.Equal(t, domain.ScoringVersionV1EqualWeight, secondSnap.ScoringVersion)
+}
+
+func TestAggregateDailyPerformance_Concurrent(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	sqlDB, _ := db.DB()
+	// SQLite serializes writers; a single connection keeps parallel workers
+	// from tripping over table locks while still exercising the pool.
+	sqlDB.SetMaxOpenConns(1)
+
+	db.Exec(`CREATE TABLE IF NOT EXISTS finops_performance_snapshots (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		user_id TEXT NOT NULL,
+		period_type TEXT NOT NULL,
+		period_start TIMESTAMP NOT NULL,
+		period_end TIMESTAMP NOT NULL,
+		scoring_version TEXT NOT NULL,
+		metrics TEXT NOT NULL,
+		scores TEXT NOT NULL,
+		total_score INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE (org_id, user_id, period_type, period_start)
+	)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_assignments (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT,
+		entity_type TEXT,
+		entity_id BIGINT,
+		assigned_to TEXT,
+		assigned_at TIMESTAMP,
+		assignment_expires_at TIMESTAMP,
+		status TEXT,
+		released_at TIMESTAMP,
+		breached_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP
+	)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_actions (id BIGINT, org_id BIGINT, entity_id BIGINT, action_type TEXT, created_at TIMESTAMP, metadata TEXT)`)
+
+	node, _ := snowflake.NewNode(1)
+	svc := &Service{
+		db:                 db,
+		log:                zap.NewNop(),
+		clock:              clock.SystemClock{},
+		genID:              node,
+		billingCfg:         &config.BillingConfigHolder{},
+		repo:               repository.NewRepository(db),
+		performanceWorkers: 3,
+	}
+
+	now := time.Now().UTC()
+	yesterdayStart := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	orgID := node.Generate()
+
+	const users = 12
+	for i := 0; i < users; i++ {
+		db.Exec("INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			node.Generate(), orgID, "invoice", node.Generate(), fmt.Sprintf("user_%02d", i), yesterdayStart.Add(time.Hour), yesterdayStart.Add(24*time.Hour), domain.AssignmentStatusAssigned, now, now)
+	}
+
+	err := svc.AggregateDailyPerformance(context.Background())
+	assert.NoError(t, err)
+
+	var count int64
+	db.Table("finops_performance_snapshots").Count(&count)
+	assert.Equal(t, int64(users), count)
+
+	var distinctUsers int64
+	db.Table("finops_performance_snapshots").Distinct("user_id").Count(&distinctUsers)
+	assert.Equal(t, int64(users), distinctUsers)
+}
+
+func TestRunBoundedLimitsConcurrency(t *testing.T) {
+	const (
+		workers = 3
+		items   = 20
+	)
+	var (
+		inFlight  int32
+		maxSeen   int32
+		processed int32
+	)
+
+	err := runBounded(context.Background(), workers, items, func(i int) error {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			seen := atomic.LoadInt32(&maxSeen)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxSeen, seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(items), atomic.LoadInt32(&processed))
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxSeen), int32(workers))
+	assert.Greater(t, atomic.LoadInt32(&maxSeen), int32(1), "expected work to run in parallel")
+}
+
+func TestRunBoundedStopsOnCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var processed int32
+	err := runBounded(ctx, 2, 10, func(int) error {
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&processed))
+}
+
+func TestAggregatePerformance_RefusesIncompletePeriod(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+
+	db.Exec(`CREATE TABLE IF NOT EXISTS finops_performance_snapshots (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		user_id TEXT NOT NULL,
+		period_type TEXT NOT NULL,
+		period_start TIMESTAMP NOT NULL,
+		period_end TIMESTAMP NOT NULL,
+		scoring_version TEXT NOT NULL,
+		metrics TEXT NOT NULL,
+		scores TEXT NOT NULL,
+		total_score INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE (org_id, user_id, period_type, period_start)
+	)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_assignments (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT,
+		entity_type TEXT,
+		entity_id BIGINT,
+		assigned_to TEXT,
+		assigned_at TIMESTAMP,
+		assignment_expires_at TIMESTAMP,
+		status TEXT,
+		released_at TIMESTAMP,
+		breached_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP
+	)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_actions (id BIGINT, org_id BIGINT, entity_id BIGINT, action_type TEXT, created_at TIMESTAMP, metadata TEXT)`)
+
+	// Mid-day: today is still in progress.
+	now := time.Date(2026, 3, 10, 13, 30, 0, 0, time.UTC)
+	node, _ := snowflake.NewNode(1)
+	svc := &Service{
+		db:         db,
+		log:        zap.NewNop(),
+		clock:      clock.NewFakeClock(now),
+		genID:      node,
+		billingCfg: &config.BillingConfigHolder{},
+		repo:       repository.NewRepository(db),
+	}
+
+	orgID := node.Generate()
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	for _, day := range []time.Time{today, today.AddDate(0, 0, -1), today.AddDate(0, 0, -2)} {
+		db.Exec("INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			node.Generate(), orgID, "invoice", node.Generate(), "user_grace", day.Add(time.Hour), day.Add(24*time.Hour), domain.AssignmentStatusAssigned, now, now)
+	}
+
+	snapshotStarts := func() []time.Time {
+		var starts []time.Time
+		db.Table("finops_performance_snapshots").Order("period_start ASC").Pluck("period_start", &starts)
+		return starts
+	}
+
+	t.Run("current day is refused", func(t *testing.T) {
+		err := svc.AggregatePerformanceForDay(context.Background(), now)
+		assert.ErrorIs(t, err, domain.ErrIncompletePeriod)
+		assert.Empty(t, snapshotStarts())
+	})
+
+	t.Run("yesterday is scored by default", func(t *testing.T) {
+		err := svc.AggregateDailyPerformance(context.Background())
+		assert.NoError(t, err)
+		starts := snapshotStarts()
+		if assert.Len(t, starts, 1) {
+			assert.True(t, starts[0].Equal(today.AddDate(0, 0, -1)), "got %v", starts[0])
+		}
+	})
+
+	t.Run("configured offset selects an earlier day", func(t *testing.T) {
+		svc.scoringDayOffset = 2
+		err := svc.AggregateDailyPerformance(context.Background())
+		assert.NoError(t, err)
+		starts := snapshotStarts()
+		if assert.Len(t, starts, 2) {
+			assert.True(t, starts[0].Equal(today.AddDate(0, 0, -2)), "got %v", starts[0])
+		}
+	})
+}
+
+// checkpointFixture is a service scoring five users of one org in batches of
+// two, with helpers to inspect the snapshots and checkpoints it writes.
+type checkpointFixture struct {
+	db          *gorm.DB
+	svc         *Service
+	clock       *clock.FakeClock
+	scoredAt    func() map[string]time.Time
+	checkpoints func() int64
+}
+
+const checkpointFixtureUsers = 5
+
+func newCheckpointFixture(t *testing.T) checkpointFixture {
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db.Exec(`CREATE TABLE IF NOT EXISTS finops_performance_snapshots (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		user_id TEXT NOT NULL,
+		period_type TEXT NOT NULL,
+		period_start TIMESTAMP NOT NULL,
+		period_end TIMESTAMP NOT NULL,
+		scoring_version TEXT NOT NULL,
+		metrics TEXT NOT NULL,
+		scores TEXT NOT NULL,
+		total_score INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE (org_id, user_id, period_type, period_start)
+	)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_assignments (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT,
+		entity_type TEXT,
+		entity_id BIGINT,
+		assigned_to TEXT,
+		assigned_at TIMESTAMP,
+		assignment_expires_at TIMESTAMP,
+		status TEXT,
+		released_at TIMESTAMP,
+		breached_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP
+	)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_actions (id BIGINT, org_id BIGINT, entity_id BIGINT, action_type TEXT, created_at TIMESTAMP, metadata TEXT)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS finops_scoring_checkpoints (
+		period_type TEXT NOT NULL,
+		period_start TIMESTAMP NOT NULL,
+		last_org_id BIGINT NOT NULL,
+		last_user_id TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (period_type, period_start)
+	)`)
+
+	now := time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC)
+	yesterdayStart := now.Truncate(24*time.Hour).AddDate(0, 0, -1)
+	node, _ := snowflake.NewNode(1)
+	fakeClock := clock.NewFakeClock(now)
+	svc := &Service{
+		db:                 db,
+		log:                zap.NewNop(),
+		clock:              fakeClock,
+		genID:              node,
+		billingCfg:         &config.BillingConfigHolder{},
+		repo:               repository.NewRepository(db),
+		performanceWorkers: 2,
+		scoringBatchSize:   2,
+	}
+
+	orgID := node.Generate()
+	for i := 0; i < checkpointFixtureUsers; i++ {
+		db.Exec("INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			node.Generate(), orgID, "invoice", node.Generate(), fmt.Sprintf("user_%02d", i), yesterdayStart.Add(time.Hour), yesterdayStart.Add(24*time.Hour), domain.AssignmentStatusAssigned, now, now)
+	}
+
+	// Snapshots are upserted in place, so a rescored user shows up as a
+	// newer updated_at.
+	scoredAt := func() map[string]time.Time {
+		var rows []struct {
+			UserID    string
+			UpdatedAt time.Time
+		}
+		db.Table("finops_performance_snapshots").Select("user_id, updated_at").Scan(&rows)
+		times := make(map[string]time.Time, len(rows))
+		for _, r := range rows {
+			times[r.UserID] = r.UpdatedAt
+		}
+		return times
+	}
+	checkpoints := func() int64 {
+		var n int64
+		db.Table("finops_scoring_checkpoints").Count(&n)
+		return n
+	}
+
+	return checkpointFixture{db: db, svc: svc, clock: fakeClock, scoredAt: scoredAt, checkpoints: checkpoints}
+}
+
+func TestAggregateDailyPerformance_ResumesFromCheckpoint(t *testing.T) {
+	f := newCheckpointFixture(t)
+	db, svc, fakeClock, scoredAt, checkpoints := f.db, f.svc, f.clock, f.scoredAt, f.checkpoints
+	const users = checkpointFixtureUsers
+
+	// Interrupt the run as soon as the first batch is checkpointed.
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, db.Callback().Raw().After("gorm:raw").Register("test:interrupt", func(tx *gorm.DB) {
+		if strings.Contains(tx.Statement.SQL.String(), "INSERT INTO finops_scoring_checkpoints") {
+			cancel()
+		}
+	}))
+	err := svc.AggregateDailyPerformance(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, db.Callback().Raw().Remove("test:interrupt"))
+
+	firstRun := scoredAt()
+	assert.Len(t, firstRun, 2)
+	assert.Contains(t, firstRun, "user_00")
+	assert.Contains(t, firstRun, "user_01")
+	assert.Equal(t, int64(1), checkpoints())
+
+	// The resumed run scores the remaining users only.
+	fakeClock.Advance(time.Minute)
+	require.NoError(t, svc.AggregateDailyPerformance(context.Background()))
+	resumed := scoredAt()
+	assert.Len(t, resumed, users)
+	assert.True(t, firstRun["user_00"].Equal(resumed["user_00"]), "completed user was scored again")
+	assert.True(t, firstRun["user_01"].Equal(resumed["user_01"]), "completed user was scored again")
+	assert.Zero(t, checkpoints())
+
+	// With the run finished, the next run recomputes everyone.
+	fakeClock.Advance(time.Minute)
+	require.NoError(t, svc.AggregateDailyPerformance(context.Background()))
+	recomputed := scoredAt()
+	assert.Len(t, recomputed, users)
+	assert.True(t, recomputed["user_00"].After(resumed["user_00"]))
+}
+
+func TestGetPerformanceHistory_Window(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	db.Exec(`CREATE TABLE IF NOT EXISTS finops_performance_snapshots (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		user_id TEXT NOT NULL,
+		period_type TEXT NOT NULL,
+		period_start TIMESTAMP NOT NULL,
+		period_end TIMESTAMP NOT NULL,
+		scoring_version TEXT NOT NULL,
+		metrics TEXT NOT NULL,
+		scores TEXT NOT NULL,
+		total_score INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE (org_id, user_id, period_type, period_start)
+	)`)
+
+	node, _ := snowflake.NewNode(1)
+	svc := &Service{db: db, log: zap.NewNop()}
+	orgID := node.Generate()
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	insert := func(periodType string, start time.Time, span time.Duration) {
+		db.Exec("INSERT INTO finops_performance_snapshots (id, org_id, user_id, period_type, period_start, period_end, scoring_version, metrics, scores, total_score, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			node.Generate(), orgID, "user_trend", periodType, start, start.Add(span), domain.ScoringVersionV1EqualWeight, "{}", `{"total":80}`, 80, day, day)
+	}
+	for i := 0; i < 10; i++ {
+		insert(domain.PeriodTypeDaily, day.AddDate(0, 0, i), 24*time.Hour)
+	}
+	insert(domain.PeriodTypeWeekly, day.AddDate(0, 0, 2), 7*24*time.Hour)
+
+	starts := func(snapshots []domain.FinOpsScoreSnapshot) []string {
+		out := make([]string, len(snapshots))
+		for i, snap := range snapshots {
+			out[i] = snap.PeriodType + "@" + snap.PeriodStart.UTC().Format("01-02")
+		}
+		return out
+	}
+
+	t.Run("window bounds period_start", func(t *testing.T) {
+		got, err := svc.GetPerformanceHistory(ctx, "user_trend", domain.PerformanceHistoryRequest{
+			From: day.AddDate(0, 0, 2),
+			To:   day.AddDate(0, 0, 5),
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"daily@03-05", "daily@03-04", "daily@03-03", "weekly@03-03"}, starts(got))
+		assert.Equal(t, 80, got[0].Scores.Total)
+	})
+
+	t.Run("period type filter", func(t *testing.T) {
+		got, err := svc.GetPerformanceHistory(ctx, "user_trend", domain.PerformanceHistoryRequest{
+			PeriodType: domain.PeriodTypeDaily,
+			From:       day.AddDate(0, 0, 2),
+			To:         day.AddDate(0, 0, 5),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"daily@03-05", "daily@03-04", "daily@03-03"}, starts(got))
+	})
+
+	t.Run("limit caps the window", func(t *testing.T) {
+		got, err := svc.GetPerformanceHistory(ctx, "user_trend", domain.PerformanceHistoryRequest{
+			PeriodType: domain.PeriodTypeDaily,
+			From:       day,
+			Limit:      2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"daily@03-10", "daily@03-09"}, starts(got))
+	})
+
+	t.Run("empty window returns an empty slice", func(t *testing.T) {
+		got, err := svc.GetPerformanceHistory(ctx, "user_trend", domain.PerformanceHistoryRequest{
+			From: day.AddDate(1, 0, 0),
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, got)
+		assert.Empty(t, got)
+	})
+}
+
+func TestAggregateDailyPerformance_FailedBatchIsNotCheckpointed(t *testing.T) {
+	f := newCheckpointFixture(t)
+
+	// user_02 sits in the second batch; its snapshot write fails once.
+	errUpsert := errors.New("upsert failed")
+	require.NoError(t, f.db.Callback().Raw().Before("gorm:raw").Register("test:fail_user", func(tx *gorm.DB) {
+		if !strings.Contains(tx.Statement.SQL.String(), "INSERT INTO finops_performance_snapshots") {
+			return
+		}
+		for _, v := range tx.Statement.Vars {
+			if v == "user_02" {
+				tx.AddError(errUpsert)
+			}
+		}
+	}))
+	err := f.svc.AggregateDailyPerformance(context.Background())
+	assert.ErrorIs(t, err, errUpsert)
+	require.NoError(t, f.db.Callback().Raw().Remove("test:fail_user"))
+
+	firstRun := f.scoredAt()
+	assert.NotContains(t, firstRun, "user_02")
+	assert.Contains(t, firstRun, "user_03", "a failure does not stop the rest of its batch")
+	assert.NotContains(t, firstRun, "user_04", "later batches are not scored")
+	assert.Equal(t, int64(1), f.checkpoints(), "only the first batch is checkpointed")
+
+	// The retry resumes at the failed batch.
+	f.clock.Advance(time.Minute)
+	require.NoError(t, f.svc.AggregateDailyPerformance(context.Background()))
+	retried := f.scoredAt()
+	assert.Len(t, retried, checkpointFixtureUsers)
+	assert.True(t, firstRun["user_00"].Equal(retried["user_00"]), "checkpointed user was scored again")
+	assert.True(t, retried["user_03"].After(firstRun["user_03"]), "failed batch was not retried")
+	assert.Zero(t, f.checkpoints())
+}
+
+func TestRunBoundedJoinsErrors(t *testing.T) {
+	errOdd := errors.New("odd")
+	var processed int32
+	err := runBounded(context.Background(), 3, 10, func(i int) error {
+		atomic.AddInt32(&processed, 1)
+		if i%2 == 1 {
+			return errOdd
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errOdd)
+	assert.Equal(t, int32(10), atomic.LoadInt32(&processed))
 }
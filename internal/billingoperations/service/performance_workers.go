@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const (
+	defaultPerformanceWorkers = 4
+	defaultScoringDayOffset   = 1
+)
+
+// performanceWorkerCount returns the configured aggregation concurrency,
+// falling back to a small default when unset.
+func (s *Service) performanceWorkerCount() int {
+	if s.performanceWorkers <= 0 {
+		return defaultPerformanceWorkers
+	}
+	return s.performanceWorkers
+}
+
+// scoringDayOffsetOrDefault returns how many days back from today the daily
+// aggregation scores. Today is never a valid target, so values below one
+// fall back to yesterday.
+func (s *Service) scoringDayOffsetOrDefault() int {
+	if s.scoringDayOffset < 1 {
+		return defaultScoringDayOffset
+	}
+	return s.scoringDayOffset
+}
+
+// runBounded calls fn for every index in [0, n) using at most workers
+// goroutines. A failing call does not stop the others; their errors are
+// joined and returned. It stops handing out work once ctx is done and
+// returns the context error in that case.
+func runBounded(ctx context.Context, workers, n int, fn func(i int) error) error {
+	if n <= 0 {
+		return nil
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		fnErrs []error
+	)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(i); err != nil {
+					mu.Lock()
+					fnErrs = append(fnErrs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for i := 0; i < n; i++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return errors.Join(append(fnErrs, err)...)
+}
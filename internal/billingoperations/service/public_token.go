@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/config"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	dbpkg "github.com/smallbiznis/railzway/pkg/db"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	errTokenEncoding  = errors.New("public token is not valid base64")
+	errTokenKey       = errors.New("public token key is unusable")
+	errTokenTruncated = errors.New("public token is shorter than its nonce")
+	errTokenAuth      = errors.New("public token failed authentication")
+)
+
+// tokenDecryptReasons maps decryptToken errors to the metric reason label.
+var tokenDecryptReasons = map[error]string{
+	errTokenEncoding:  obsmetrics.PublicTokenDecryptReasonEncoding,
+	errTokenKey:       obsmetrics.PublicTokenDecryptReasonKey,
+	errTokenTruncated: obsmetrics.PublicTokenDecryptReasonTruncated,
+	errTokenAuth:      obsmetrics.PublicTokenDecryptReasonAuth,
+}
+
+// publicTokenKey derives the public token encryption key from the payment
+// provider config secret. An empty secret yields no key.
+func publicTokenKey(secret string) []byte {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// CheckPublicTokenKey decrypts the most recently issued active public token
+// with the configured secret, so a secret that no longer matches the stored
+// tokens fails at startup rather than blanking every public link at request
+// time. Without a secret or a stored token there is nothing to check.
+func CheckPublicTokenKey(cfg config.Config, db *gorm.DB) error {
+	key := publicTokenKey(cfg.PaymentProviderConfigSecret)
+	if key == nil {
+		return nil
+	}
+	var stored struct {
+		ID        snowflake.ID
+		TokenHash string
+	}
+	if err := dbpkg.SkipTenantScope(db.WithContext(context.Background())).Raw(
+		`SELECT id, token_hash
+		 FROM invoice_public_tokens
+		 WHERE revoked_at IS NULL
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT 1`,
+	).Scan(&stored).Error; err != nil {
+		return fmt.Errorf("public token self-check: load token: %w", err)
+	}
+	if stored.TokenHash == "" {
+		return nil
+	}
+	if _, err := decryptToken(key, stored.TokenHash); err != nil {
+		return fmt.Errorf("public token self-check: token %s does not decrypt with the configured secret: %w", stored.ID, err)
+	}
+	return nil
+}
+
+// decryptPublicToken decrypts a stored token, counting and logging failures.
+// The log carries the failure reason only, never the stored value.
+func (s *Service) decryptPublicToken(orgID snowflake.ID, tokenHash string) string {
+	token, err := decryptToken(s.encKey, tokenHash)
+	if err != nil {
+		obsmetrics.PublicToken().IncDecryptFailure(tokenDecryptReasons[err])
+		s.log.Debug("failed to decrypt public invoice token",
+			zap.String("org_id", orgID.String()),
+			zap.String("reason", tokenDecryptReasons[err]),
+			zap.Error(err),
+		)
+	}
+	return token
+}
+
+// ReissuePublicToken replaces the public link of a finalized unpaid invoice,
+// for example after the encryption secret changed and its stored token no
+// longer decrypts. Collection lists only ever show the stored link, or none.
+func (s *Service) ReissuePublicToken(ctx context.Context, invoiceID string) (domain.ReissuePublicTokenResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.ReissuePublicTokenResponse{}, domain.ErrInvalidOrganization
+	}
+	parsedInvoiceID, err := snowflake.ParseString(strings.TrimSpace(invoiceID))
+	if err != nil || parsedInvoiceID == 0 {
+		return domain.ReissuePublicTokenResponse{}, domain.ErrInvalidEntityID
+	}
+	if len(s.encKey) == 0 {
+		return domain.ReissuePublicTokenResponse{}, domain.ErrPublicTokenKeyMissing
+	}
+
+	token, err := s.reissuePublicToken(ctx, orgID, parsedInvoiceID)
+	if err != nil {
+		return domain.ReissuePublicTokenResponse{}, err
+	}
+	return domain.ReissuePublicTokenResponse{
+		InvoiceID:   parsedInvoiceID.String(),
+		PublicToken: token,
+	}, nil
+}
+
+func (s *Service) reissuePublicToken(ctx context.Context, orgID, invoiceID snowflake.ID) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := encryptToken(s.encKey, token)
+	if err != nil {
+		return "", err
+	}
+
+	tokenID := s.genID.Generate()
+	if err := s.repo.ReissuePublicToken(ctx, orgID, invoiceID, tokenID, encrypted, s.clock.Now().UTC()); err != nil {
+		return "", err
+	}
+
+	if err := s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryOperational,
+		action:     "invoice.public_token.reissued",
+		targetType: "invoice",
+		targetID:   invoiceID.String(),
+		metadata: map[string]any{
+			"token_id": tokenID.String(),
+			"reason":   "requested",
+		},
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ListMissingPublicTokens lists finalized unpaid invoices that have no active
+// public token. Their rows in the collections lists carry no pay link.
+func (s *Service) ListMissingPublicTokens(ctx context.Context, limit int, pageToken string) (domain.MissingPublicTokensResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.MissingPublicTokensResponse{}, domain.ErrInvalidOrganization
+	}
+	if limit <= 0 {
+		limit = 25
+	}
+
+	rows, pageInfo, err := s.repo.ListMissingPublicTokens(ctx, orgID, pagination.Pagination{PageToken: pageToken, PageSize: limit})
+	if err != nil {
+		return domain.MissingPublicTokensResponse{}, err
+	}
+
+	invoices := make([]domain.MissingPublicToken, 0, len(rows))
+	for _, row := range rows {
+		invoiceNumber := strings.TrimSpace(row.InvoiceNumber)
+		if invoiceNumber == "" {
+			invoiceNumber = row.InvoiceID.String()
+		}
+		currency := strings.ToUpper(strings.TrimSpace(row.Currency))
+		invoices = append(invoices, domain.MissingPublicToken{
+			InvoiceID:        row.InvoiceID.String(),
+			InvoiceNumber:    invoiceNumber,
+			CustomerID:       row.CustomerID.String(),
+			CustomerName:     row.CustomerName,
+			TotalAmount:      row.SubtotalAmount,
+			Currency:         currency,
+			CurrencyExponent: s.currencyExponent(currency),
+			DueAt:            timePtr(row.DueAt),
+			FinalizedAt:      timePtr(row.FinalizedAt),
+		})
+	}
+
+	return domain.MissingPublicTokensResponse{
+		PageInfo: pageInfo,
+		Invoices: invoices,
+	}, nil
+}
+
+// EnsurePublicTokens issues a public token for up to limit finalized unpaid
+// invoices that have none, oldest first, and returns how many were issued.
+// An invoice that gained a token concurrently is skipped.
+func (s *Service) EnsurePublicTokens(ctx context.Context, limit int) (int, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return 0, domain.ErrInvalidOrganization
+	}
+	if len(s.encKey) == 0 {
+		return 0, domain.ErrPublicTokenKeyMissing
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, _, err := s.repo.ListMissingPublicTokens(ctx, orgID, pagination.Pagination{PageSize: limit})
+	if err != nil {
+		return 0, err
+	}
+
+	issued := 0
+	for _, row := range rows {
+		ok, err := s.issuePublicToken(ctx, orgID, row.InvoiceID)
+		if err != nil {
+			return issued, err
+		}
+		if ok {
+			issued++
+		}
+	}
+	return issued, nil
+}
+
+func (s *Service) issuePublicToken(ctx context.Context, orgID, invoiceID snowflake.ID) (bool, error) {
+	token, err := generateToken()
+	if err != nil {
+		return false, err
+	}
+	encrypted, err := encryptToken(s.encKey, token)
+	if err != nil {
+		return false, err
+	}
+
+	tokenID := s.genID.Generate()
+	ok, err := s.repo.IssuePublicToken(ctx, orgID, invoiceID, tokenID, encrypted, s.clock.Now().UTC())
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if err := s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryOperational,
+		action:     "invoice.public_token.issued",
+		targetType: "invoice",
+		targetID:   invoiceID.String(),
+		metadata: map[string]any{
+			"token_id": tokenID.String(),
+			"reason":   "missing",
+		},
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func encryptToken(key []byte, text string) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	ciphertext := gcm.Seal(nonce, nonce, []byte(text), nil)
+	return base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// publicTokenRepo serves one overdue invoice with a fixed stored token and
+// records any reissued token.
+type publicTokenRepo struct {
+	domain.Repository
+	invoiceID snowflake.ID
+	tokenHash string
+
+	reissuedFor  snowflake.ID
+	reissuedHash string
+
+	missing []domain.MissingPublicTokenRow
+	// issued maps invoices to the token hashes stored by IssuePublicToken.
+	// Invoices already present are treated as having an active token.
+	issued map[snowflake.ID]string
+}
+
+func (r *publicTokenRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *publicTokenRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *publicTokenRepo) FetchListDefaults(context.Context, snowflake.ID) (domain.ListDefaults, error) {
+	return domain.ListDefaults{}, nil
+}
+
+func (r *publicTokenRepo) ListOverdueInvoices(context.Context, snowflake.ID, time.Time, pagination.Pagination) ([]domain.OverdueInvoiceRow, pagination.PageInfo, error) {
+	row := domain.OverdueInvoiceRow{InvoiceID: r.invoiceID, AmountDue: 1000}
+	row.TokenHash.String, row.TokenHash.Valid = r.tokenHash, true
+	return []domain.OverdueInvoiceRow{row}, pagination.PageInfo{}, nil
+}
+
+func (r *publicTokenRepo) ReissuePublicToken(_ context.Context, _, invoiceID, _ snowflake.ID, tokenHash string, _ time.Time) error {
+	r.reissuedFor = invoiceID
+	r.reissuedHash = tokenHash
+	return nil
+}
+
+func (r *publicTokenRepo) ListMissingPublicTokens(_ context.Context, _ snowflake.ID, page pagination.Pagination) ([]domain.MissingPublicTokenRow, pagination.PageInfo, error) {
+	rows := r.missing
+	if len(rows) > page.PageSize {
+		return rows[:page.PageSize], pagination.PageInfo{HasMore: true}, nil
+	}
+	return rows, pagination.PageInfo{}, nil
+}
+
+func (r *publicTokenRepo) IssuePublicToken(_ context.Context, _, invoiceID, _ snowflake.ID, tokenHash string, _ time.Time) (bool, error) {
+	if _, ok := r.issued[invoiceID]; ok {
+		return false, nil
+	}
+	r.issued[invoiceID] = tokenHash
+	return true, nil
+}
+
+func TestPublicToken_ReadsDoNotReissue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	sum := sha256.Sum256([]byte("secret"))
+	key := sum[:]
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+
+	newService := func(t *testing.T, repo *publicTokenRepo, auditSvc *mockAuditSvc) *Service {
+		return &Service{
+			repo:       repo,
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			genID:      node,
+			auditSvc:   auditSvc,
+			encKey:     key,
+			billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+		}
+	}
+
+	t.Run("valid token is returned as is", func(t *testing.T) {
+		encrypted, err := encryptToken(key, "raw-token")
+		require.NoError(t, err)
+		repo := &publicTokenRepo{invoiceID: 42, tokenHash: encrypted}
+
+		resp, err := newService(t, repo, new(mockAuditSvc)).ListOverdueInvoices(ctx, 10, "")
+		require.NoError(t, err)
+
+		require.Len(t, resp.Invoices, 1)
+		assert.Equal(t, "raw-token", resp.Invoices[0].PublicToken)
+	})
+
+	t.Run("corrupt token is omitted and counted", func(t *testing.T) {
+		repo := &publicTokenRepo{invoiceID: 42, tokenHash: "corrupt"}
+		auditSvc := new(mockAuditSvc)
+		before := decryptFailures(t, obsmetrics.PublicTokenDecryptReasonTruncated)
+
+		resp, err := newService(t, repo, auditSvc).ListOverdueInvoices(ctx, 10, "")
+		require.NoError(t, err)
+
+		require.Len(t, resp.Invoices, 1)
+		assert.Empty(t, resp.Invoices[0].PublicToken)
+		assert.Equal(t, before+1, decryptFailures(t, obsmetrics.PublicTokenDecryptReasonTruncated))
+		assert.Zero(t, repo.reissuedFor)
+		auditSvc.AssertNotCalled(t, "AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestReissuePublicToken(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	sum := sha256.Sum256([]byte("secret"))
+	key := sum[:]
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+
+	newService := func(t *testing.T, repo *publicTokenRepo, auditSvc *mockAuditSvc, key []byte) *Service {
+		return &Service{
+			repo:     repo,
+			log:      zaptest.NewLogger(t),
+			clock:    clock.NewFakeClock(now),
+			genID:    node,
+			auditSvc: auditSvc,
+			encKey:   key,
+		}
+	}
+
+	t.Run("stores and returns a decryptable token", func(t *testing.T) {
+		repo := &publicTokenRepo{}
+		auditSvc := new(mockAuditSvc)
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, "", mock.Anything,
+			"invoice.public_token.reissued", "invoice", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				assert.Equal(t, "42", *args.Get(6).(*string))
+				assert.Equal(t, "requested", args.Get(7).(map[string]any)["reason"])
+			}).
+			Return(nil)
+
+		resp, err := newService(t, repo, auditSvc, key).ReissuePublicToken(ctx, "42")
+		require.NoError(t, err)
+
+		assert.Equal(t, "42", resp.InvoiceID)
+		require.NotEmpty(t, resp.PublicToken)
+		assert.Equal(t, snowflake.ID(42), repo.reissuedFor)
+		decrypted, err := decryptToken(key, repo.reissuedHash)
+		require.NoError(t, err)
+		assert.Equal(t, resp.PublicToken, decrypted)
+		auditSvc.AssertNumberOfCalls(t, "AuditLog", 1)
+	})
+
+	t.Run("rejects an invalid invoice id", func(t *testing.T) {
+		_, err := newService(t, &publicTokenRepo{}, new(mockAuditSvc), key).ReissuePublicToken(ctx, "abc")
+		assert.ErrorIs(t, err, domain.ErrInvalidEntityID)
+	})
+
+	t.Run("requires the encryption key", func(t *testing.T) {
+		repo := &publicTokenRepo{}
+
+		_, err := newService(t, repo, new(mockAuditSvc), nil).ReissuePublicToken(ctx, "42")
+		assert.ErrorIs(t, err, domain.ErrPublicTokenKeyMissing)
+		assert.Zero(t, repo.reissuedFor)
+	})
+}
+
+// decryptFailures reads the public token decrypt failure counter for reason.
+func decryptFailures(t *testing.T, reason string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "railzway_public_token_decrypt_failures_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "reason" && label.GetValue() == reason {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestDecryptToken_FailureReasons(t *testing.T) {
+	sum := sha256.Sum256([]byte("secret"))
+	key := sum[:]
+	otherSum := sha256.Sum256([]byte("rotated"))
+
+	encrypted, err := encryptToken(key, "raw-token")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		key    []byte
+		stored string
+		want   error
+		reason string
+	}{
+		{name: "bad base64", key: key, stored: "not base64!", want: errTokenEncoding, reason: obsmetrics.PublicTokenDecryptReasonEncoding},
+		{name: "short ciphertext", key: key, stored: "corrupt", want: errTokenTruncated, reason: obsmetrics.PublicTokenDecryptReasonTruncated},
+		{name: "rotated key", key: otherSum[:], stored: encrypted, want: errTokenAuth, reason: obsmetrics.PublicTokenDecryptReasonAuth},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decryptToken(tc.key, tc.stored)
+			assert.ErrorIs(t, err, tc.want)
+
+			svc := &Service{encKey: tc.key, log: zaptest.NewLogger(t)}
+			before := decryptFailures(t, tc.reason)
+			assert.Empty(t, svc.decryptPublicToken(1, tc.stored))
+			assert.Equal(t, before+1, decryptFailures(t, tc.reason))
+		})
+	}
+
+	t.Run("missing token is not a failure", func(t *testing.T) {
+		token, err := decryptToken(key, "")
+		assert.NoError(t, err)
+		assert.Empty(t, token)
+	})
+}
+
+func TestCheckPublicTokenKey(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.Exec(`CREATE TABLE invoice_public_tokens (
+		id INTEGER PRIMARY KEY, token_hash TEXT NOT NULL, revoked_at DATETIME, created_at DATETIME NOT NULL
+	)`).Error)
+	withSecret := func(secret string) config.Config {
+		return config.Config{PaymentProviderConfigSecret: secret}
+	}
+
+	t.Run("no stored token", func(t *testing.T) {
+		assert.NoError(t, CheckPublicTokenKey(withSecret("secret"), db))
+	})
+
+	current, err := encryptToken(publicTokenKey("secret"), "current-token")
+	require.NoError(t, err)
+	revoked, err := encryptToken(publicTokenKey("old-secret"), "revoked-token")
+	require.NoError(t, err)
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Exec(`INSERT INTO invoice_public_tokens (id, token_hash, revoked_at, created_at) VALUES (1, ?, NULL, ?), (2, ?, ?, ?)`,
+		current, now, revoked, now, now.Add(time.Hour)).Error)
+
+	t.Run("stored token decrypts", func(t *testing.T) {
+		assert.NoError(t, CheckPublicTokenKey(withSecret("secret"), db))
+	})
+
+	t.Run("changed secret fails", func(t *testing.T) {
+		err := CheckPublicTokenKey(withSecret("other-secret"), db)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errTokenAuth)
+	})
+
+	t.Run("no secret", func(t *testing.T) {
+		assert.NoError(t, CheckPublicTokenKey(config.Config{}, db))
+	})
+}
+
+func TestEnsurePublicTokens(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	sum := sha256.Sum256([]byte("secret"))
+	key := sum[:]
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+
+	newService := func(t *testing.T, repo *publicTokenRepo, auditSvc *mockAuditSvc, key []byte) *Service {
+		return &Service{
+			repo:       repo,
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			genID:      node,
+			auditSvc:   auditSvc,
+			encKey:     key,
+			billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+		}
+	}
+	missing := func() []domain.MissingPublicTokenRow {
+		return []domain.MissingPublicTokenRow{
+			{InvoiceID: 41, InvoiceNumber: "INV-41", Currency: "usd", SubtotalAmount: 1000},
+			{InvoiceID: 42, Currency: "usd", SubtotalAmount: 2000},
+			{InvoiceID: 43, Currency: "usd", SubtotalAmount: 3000},
+		}
+	}
+
+	t.Run("lists invoices without a token", func(t *testing.T) {
+		repo := &publicTokenRepo{missing: missing()}
+
+		resp, err := newService(t, repo, new(mockAuditSvc), key).ListMissingPublicTokens(ctx, 2, "")
+		require.NoError(t, err)
+
+		require.Len(t, resp.Invoices, 2)
+		assert.True(t, resp.HasMore)
+		assert.Equal(t, "INV-41", resp.Invoices[0].InvoiceNumber)
+		assert.Equal(t, "42", resp.Invoices[1].InvoiceNumber)
+		assert.Equal(t, "USD", resp.Invoices[1].Currency)
+		assert.Nil(t, resp.Invoices[1].DueAt)
+	})
+
+	t.Run("issues a decryptable token per invoice", func(t *testing.T) {
+		// Invoice 42 gained a token after it was listed.
+		repo := &publicTokenRepo{missing: missing(), issued: map[snowflake.ID]string{42: "concurrent"}}
+		auditSvc := new(mockAuditSvc)
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, "", mock.Anything,
+			"invoice.public_token.issued", "invoice", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				assert.Equal(t, "missing", args.Get(7).(map[string]any)["reason"])
+			}).
+			Return(nil)
+
+		issued, err := newService(t, repo, auditSvc, key).EnsurePublicTokens(ctx, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, issued)
+		assert.Equal(t, "concurrent", repo.issued[42])
+		for _, invoiceID := range []snowflake.ID{41, 43} {
+			token, err := decryptToken(key, repo.issued[invoiceID])
+			require.NoError(t, err)
+			assert.NotEmpty(t, token)
+		}
+		auditSvc.AssertNumberOfCalls(t, "AuditLog", 2)
+	})
+
+	t.Run("stops at the limit", func(t *testing.T) {
+		repo := &publicTokenRepo{missing: missing(), issued: map[snowflake.ID]string{}}
+		auditSvc := new(mockAuditSvc)
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		issued, err := newService(t, repo, auditSvc, key).EnsurePublicTokens(ctx, 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, issued)
+		assert.Contains(t, repo.issued, snowflake.ID(41))
+	})
+
+	t.Run("requires the encryption key", func(t *testing.T) {
+		repo := &publicTokenRepo{missing: missing(), issued: map[snowflake.ID]string{}}
+
+		_, err := newService(t, repo, new(mockAuditSvc), nil).EnsurePublicTokens(ctx, 10)
+		assert.ErrorIs(t, err, domain.ErrPublicTokenKeyMissing)
+		assert.Empty(t, repo.issued)
+	})
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/config"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// pgQueryCanceled is the SQLSTATE Postgres reports when statement_timeout
+// cancels a query.
+const pgQueryCanceled = "57014"
+
+// boundedRead runs read against the aging repository with endpoint's query
+// timeout. The context is cancelled once the timeout passes; on Postgres the
+// queries also run in a read transaction with a matching statement_timeout,
+// so the server stops them instead of holding the connection. Hitting the
+// timeout returns domain.ErrQueryTimeout and is counted per endpoint.
+func (s *Service) boundedRead(ctx context.Context, endpoint string, read func(ctx context.Context, repo domain.Repository) error) error {
+	timeout := s.queryTimeout(endpoint)
+	readCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var err error
+	if s.db != nil && s.db.Dialector != nil && s.db.Dialector.Name() == "postgres" {
+		err = s.db.WithContext(readCtx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())).Error; err != nil {
+				return err
+			}
+			return read(readCtx, s.agingRepo().WithTx(tx))
+		})
+	} else {
+		err = read(readCtx, s.agingRepo())
+	}
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.Is(readCtx.Err(), context.DeadlineExceeded) || (errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled) {
+		orgID, _ := orgcontext.OrgIDFromContext(ctx)
+		s.log.Warn("billing operations read timed out",
+			zap.String("endpoint", endpoint),
+			zap.String("org_id", orgID.String()),
+			zap.Duration("timeout", timeout),
+			zap.Error(err),
+		)
+		obsmetrics.BillingOps().IncQueryTimeout(endpoint)
+		return domain.ErrQueryTimeout
+	}
+	return err
+}
+
+// queryTimeout returns the configured timeout of an endpoint's read, falling
+// back to the default when unset.
+func (s *Service) queryTimeout(endpoint string) time.Duration {
+	cfg := s.billingCfg.Get().QueryTimeouts
+	defaults := config.DefaultBillingConfig().QueryTimeouts
+
+	var seconds, fallback int
+	switch endpoint {
+	case obsmetrics.BillingOpsEndpointInbox:
+		seconds, fallback = cfg.InboxSeconds, defaults.InboxSeconds
+	case obsmetrics.BillingOpsEndpointMyWork:
+		seconds, fallback = cfg.MyWorkSeconds, defaults.MyWorkSeconds
+	case obsmetrics.BillingOpsEndpointExposureAnalysis:
+		seconds, fallback = cfg.ExposureAnalysisSeconds, defaults.ExposureAnalysisSeconds
+	}
+	if seconds <= 0 {
+		seconds = fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
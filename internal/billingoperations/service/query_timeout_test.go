@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+// slowReadRepo blocks every heavy read until its context is done.
+type slowReadRepo struct {
+	domain.Repository
+}
+
+func (r *slowReadRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *slowReadRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *slowReadRepo) ListInboxItems(ctx context.Context, _ snowflake.ID, _ domain.InboxFilter, _ int, _ time.Time) ([]domain.InboxRow, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (r *slowReadRepo) ListMyWorkItems(ctx context.Context, _ snowflake.ID, _ string, _ int, _ time.Time) ([]domain.MyWorkRow, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (r *slowReadRepo) GetExposureAnalysis(ctx context.Context, _ snowflake.ID, _ time.Time, _ int) (domain.ExposureStatsRow, []domain.TopCustomerExposureRow, error) {
+	<-ctx.Done()
+	return domain.ExposureStatsRow{}, nil, ctx.Err()
+}
+
+func TestBoundedReads_Timeout(t *testing.T) {
+	cfg := config.DefaultBillingConfig()
+	cfg.QueryTimeouts = config.QueryTimeoutsConfig{InboxSeconds: 1, MyWorkSeconds: 1, ExposureAnalysisSeconds: 1}
+	svc := &Service{
+		repo:       &slowReadRepo{},
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+		billingCfg: config.NewStaticBillingConfigHolder(cfg),
+	}
+	reads := map[string]func(ctx context.Context) error{
+		"inbox": func(ctx context.Context) error {
+			_, err := svc.GetInbox(ctx, domain.InboxRequest{Limit: 10})
+			return err
+		},
+		"my work": func(ctx context.Context) error {
+			_, err := svc.GetMyWork(ctx, "agent", domain.MyWorkRequest{Limit: 10})
+			return err
+		},
+		"exposure analysis": func(ctx context.Context) error {
+			_, err := svc.GetExposureAnalysis(ctx, domain.ExposureAnalysisRequest{})
+			return err
+		},
+	}
+
+	for name, read := range reads {
+		t.Run(name+" times out", func(t *testing.T) {
+			t.Parallel()
+			err := read(orgcontext.WithOrgID(context.Background(), 1))
+			assert.ErrorIs(t, err, domain.ErrQueryTimeout)
+		})
+		t.Run(name+" cancelled is not a timeout", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(orgcontext.WithOrgID(context.Background(), 1))
+			cancel()
+			assert.ErrorIs(t, read(ctx), context.Canceled)
+		})
+	}
+}
+
+func TestQueryTimeout_FallsBackToDefault(t *testing.T) {
+	cfg := config.DefaultBillingConfig()
+	cfg.QueryTimeouts = config.QueryTimeoutsConfig{InboxSeconds: 3}
+	svc := &Service{billingCfg: config.NewStaticBillingConfigHolder(cfg)}
+
+	assert.Equal(t, 3*time.Second, svc.queryTimeout(obsmetrics.BillingOpsEndpointInbox))
+	assert.Equal(t, 10*time.Second, svc.queryTimeout(obsmetrics.BillingOpsEndpointMyWork))
+	assert.Equal(t, 20*time.Second, svc.queryTimeout(obsmetrics.BillingOpsEndpointExposureAnalysis))
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// Reports recorded by audit-on-read.
+const (
+	reportExposureAnalysis     = "exposure_analysis"
+	reportARHealth             = "ar_health"
+	reportOutstandingCustomers = "outstanding_customers"
+	reportCustomerStatement    = "customer_statement"
+	reportEntityAuditTrail     = "entity_audit_trail"
+)
+
+// auditRead records that the caller viewed a sensitive financial report when
+// the org has audit-on-read enabled. The actor is resolved from the request
+// context. Failures fail the read only when the read audit category is
+// required.
+func (s *Service) auditRead(ctx context.Context, orgID snowflake.ID, report string, scope map[string]any) error {
+	if s.auditSvc == nil {
+		return nil
+	}
+	enabled, err := s.repo.FetchAuditReads(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	metadata := map[string]any{"report": report}
+	for key, value := range scope {
+		metadata[key] = value
+	}
+
+	return s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryRead,
+		action:     "billing_operations.report_viewed",
+		targetType: "billing_report",
+		targetID:   report,
+		metadata:   metadata,
+	})
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// readAuditRepo returns empty report data so only the audit side effects of
+// the read paths are exercised. auditReads is the org's audit-on-read
+// preference.
+type readAuditRepo struct {
+	domain.Repository
+	auditReads bool
+}
+
+func (r *readAuditRepo) FetchAuditReads(context.Context, snowflake.ID) (bool, error) {
+	return r.auditReads, nil
+}
+
+func (r *readAuditRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *readAuditRepo) FetchOverdueCalendar(context.Context, snowflake.ID) (domain.OverdueCalendar, error) {
+	return domain.OverdueCalendar{}, nil
+}
+
+func (r *readAuditRepo) GetExposureStats(context.Context, snowflake.ID, time.Time) (domain.ExposureStatsRow, error) {
+	return domain.ExposureStatsRow{}, nil
+}
+
+func (r *readAuditRepo) ListTopHighExposure(context.Context, snowflake.ID, time.Time) ([]domain.TopCustomerExposureRow, error) {
+	return nil, nil
+}
+
+func (r *readAuditRepo) GetExposureAnalysis(context.Context, snowflake.ID, time.Time, int) (domain.ExposureStatsRow, []domain.TopCustomerExposureRow, error) {
+	return domain.ExposureStatsRow{}, nil, nil
+}
+
+func (r *readAuditRepo) GetARFlowStats(context.Context, snowflake.ID, string, time.Time, time.Time) (domain.ARFlowStatsRow, error) {
+	return domain.ARFlowStatsRow{}, nil
+}
+
+func (r *readAuditRepo) ListOutstandingCustomers(context.Context, snowflake.ID, time.Time, bool, pagination.Pagination) ([]domain.OutstandingCustomerRow, pagination.PageInfo, error) {
+	return nil, pagination.PageInfo{}, nil
+}
+
+func readSensitiveReports(t *testing.T, svc *Service) {
+	t.Helper()
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	_, err := svc.GetExposureAnalysis(ctx, domain.ExposureAnalysisRequest{})
+	require.NoError(t, err)
+	_, err = svc.GetARHealth(ctx, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	_, err = svc.ListOutstandingCustomers(ctx, 10, false, "")
+	require.NoError(t, err)
+}
+
+func TestAuditRead(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	newService := func(t *testing.T, auditSvc *mockAuditSvc, enabled bool) *Service {
+		return &Service{
+			repo:       &readAuditRepo{auditReads: enabled},
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			auditSvc:   auditSvc,
+			billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+		}
+	}
+
+	t.Run("enabled records each report read", func(t *testing.T) {
+		auditSvc := new(mockAuditSvc)
+		var reports []string
+		auditSvc.On("AuditLog", mock.Anything, mock.Anything, "", mock.Anything,
+			"billing_operations.report_viewed", "billing_report", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				orgID := args.Get(1).(*snowflake.ID)
+				assert.Equal(t, snowflake.ID(1), *orgID)
+				metadata := args.Get(7).(map[string]any)
+				assert.Equal(t, "USD", metadata["currency"])
+				assert.Equal(t, *args.Get(6).(*string), metadata["report"])
+				reports = append(reports, metadata["report"].(string))
+			}).
+			Return(nil)
+
+		readSensitiveReports(t, newService(t, auditSvc, true))
+
+		assert.Equal(t, []string{reportExposureAnalysis, reportARHealth, reportOutstandingCustomers}, reports)
+		auditSvc.AssertNumberOfCalls(t, "AuditLog", 3)
+	})
+
+	t.Run("disabled records nothing", func(t *testing.T) {
+		auditSvc := new(mockAuditSvc)
+
+		readSensitiveReports(t, newService(t, auditSvc, false))
+
+		auditSvc.AssertNotCalled(t, "AuditLog", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
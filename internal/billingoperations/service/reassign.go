@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ReassignAssignment hands an active assignment over to another operator.
+// The assignment restarts for the new assignee, SLA breach included, and the
+// handoff (who, when and the note) is kept on the assignment and in the
+// reassign action.
+func (s *Service) ReassignAssignment(ctx context.Context, req domain.ReassignAssignmentRequest) (domain.AssignmentResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.AssignmentResponse{}, domain.ErrInvalidOrganization
+	}
+
+	entityType := strings.TrimSpace(req.EntityType)
+	if entityType != domain.EntityTypeInvoice && entityType != domain.EntityTypeCustomer {
+		return domain.AssignmentResponse{}, domain.ErrInvalidEntityType
+	}
+
+	entityID, err := parseSnowflakeID(req.EntityID)
+	if err != nil {
+		return domain.AssignmentResponse{}, domain.ErrInvalidEntityID
+	}
+
+	assignTo := strings.TrimSpace(req.AssignTo)
+	if assignTo == "" {
+		return domain.AssignmentResponse{}, domain.ErrInvalidAssignee
+	}
+
+	reassignedBy := strings.TrimSpace(req.ReassignedBy)
+	if reassignedBy == "" {
+		_, actorID := auditcontext.ActorFromContext(ctx)
+		reassignedBy = strings.TrimSpace(actorID)
+	}
+	if reassignedBy == "" {
+		return domain.AssignmentResponse{}, domain.ErrInvalidAssignee
+	}
+
+	note := strings.TrimSpace(req.Reason)
+	if err := s.checkHandoffNote(ctx, orgID, note); err != nil {
+		return domain.AssignmentResponse{}, err
+	}
+
+	now := s.clock.Now().UTC()
+	expiresAt := now.Add(defaultAssignmentTTLMinutes * time.Minute)
+
+	var (
+		result       domain.AssignmentResponse
+		previous     string
+		reassignedID snowflake.ID
+	)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+
+		existing, err := repoTx.LoadAssignmentForUpdate(ctx, orgID, entityType, entityID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return domain.ErrAssignmentNotFound
+		}
+
+		result = domain.AssignmentResponse{
+			Assignment: domain.Assignment{
+				EntityType:          entityType,
+				EntityID:            entityID.String(),
+				Status:              existing.Status,
+				AssignedTo:          existing.AssignedTo,
+				AssignedAt:          existing.AssignedAt,
+				AssignmentExpiresAt: existing.AssignmentExpiresAt,
+				LastActionAt:        timePtr(existing.LastActionAt),
+			},
+			Status: domain.AssignmentStatusAssigned,
+		}
+		if existing.AssignedTo == assignTo {
+			return nil // Already with the new assignee
+		}
+
+		previous = existing.AssignedTo
+		reassignedID = existing.ID
+		record := *existing
+		record.AssignedTo = assignTo
+		record.AssignedAt = now
+		record.AssignmentExpiresAt = expiresAt
+		record.Status = domain.AssignmentStatusAssigned
+		record.HandedOffAt = sql.NullTime{Time: now, Valid: true}
+		record.HandedOffBy = sql.NullString{String: reassignedBy, Valid: true}
+		record.HandoffNote = sql.NullString{String: note, Valid: note != ""}
+		record.UpdatedAt = now
+
+		if err := repoTx.ReassignAssignment(ctx, record, existing.UpdatedAt); err != nil {
+			return err
+		}
+
+		bucket := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if _, err := repoTx.InsertBillingAction(ctx, domain.BillingActionRecord{
+			ID:           s.genID.Generate(),
+			OrgID:        orgID,
+			EntityType:   entityType,
+			EntityID:     entityID,
+			ActionType:   domain.ActionTypeReassign,
+			ActionBucket: bucket,
+			Metadata: datatypes.JSONMap{
+				"assignment_id": record.ID.String(),
+				"from":          previous,
+				"to":            assignTo,
+				"reassigned_by": reassignedBy,
+				"handoff_note":  note,
+			},
+			ActorType: "user",
+			ActorID:   reassignedBy,
+			CreatedAt: now,
+		}); err != nil {
+			return err
+		}
+
+		result.Assignment = domain.Assignment{
+			EntityType:          entityType,
+			EntityID:            entityID.String(),
+			Status:              domain.AssignmentStatusAssigned,
+			AssignedTo:          assignTo,
+			AssignedAt:          now,
+			AssignmentExpiresAt: expiresAt,
+			HandedOffAt:         &now,
+			HandedOffBy:         reassignedBy,
+			HandoffNote:         note,
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.AssignmentResponse{}, err
+	}
+	if reassignedID == 0 {
+		return result, nil
+	}
+
+	if err := s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryOperational,
+		action:     "billing_operations.assignment.reassigned",
+		targetType: "billing_operation_assignment",
+		targetID:   entityID.String(),
+		metadata: map[string]any{
+			"entity_type":   entityType,
+			"entity_id":     entityID.String(),
+			"from":          previous,
+			"to":            assignTo,
+			"reassigned_by": reassignedBy,
+			"handoff_note":  note,
+		},
+	}); err != nil {
+		return domain.AssignmentResponse{}, err
+	}
+	return result, nil
+}
+
+// checkHandoffNote rejects a release or reassign without a note when the
+// org requires one.
+func (s *Service) checkHandoffNote(ctx context.Context, orgID snowflake.ID, note string) error {
+	if note != "" {
+		return nil
+	}
+	required, err := s.repo.FetchRequireHandoffNote(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrHandoffNoteRequired
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type resolvedRepo struct {
+	domain.Repository
+	rows      []domain.ResolvedRow
+	snapshots int
+}
+
+func (r *resolvedRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *resolvedRepo) ListRecentlyResolvedItems(context.Context, snowflake.ID, string, int, time.Time) ([]domain.ResolvedRow, error) {
+	return r.rows, nil
+}
+
+func (r *resolvedRepo) LoadEntitySnapshot(context.Context, snowflake.ID, string, snowflake.ID) (map[string]any, error) {
+	r.snapshots++
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestGetRecentlyResolved_Detailed(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	newRepo := func() *resolvedRepo {
+		return &resolvedRepo{
+			rows: []domain.ResolvedRow{
+				{
+					AssignmentID:       "1",
+					EntityType:         domain.EntityTypeInvoice,
+					EntityID:           "77",
+					SnapshotMetadata:   datatypes.JSON(`{"entity_name":"Acme","amount_due":12000,"invoice_number":"INV-7","customer_id":"42","days_overdue":12}`),
+					Status:             domain.AssignmentStatusResolved,
+					ResolvedAt:         now.Add(-time.Hour),
+					AssignedAt:         now.Add(-4 * time.Hour),
+					ResolutionSnapshot: datatypes.JSON(`{"invoice_number":"INV-7","amount_due":0,"status":"PAID"}`),
+				},
+				{
+					AssignmentID: "2",
+					EntityType:   domain.EntityTypeInvoice,
+					EntityID:     "78",
+					Status:       domain.AssignmentStatusReleased,
+					ResolvedAt:   now.Add(-2 * time.Hour),
+					AssignedAt:   now.Add(-3 * time.Hour),
+				},
+			},
+		}
+	}
+	newService := func(t *testing.T, repo *resolvedRepo) *Service {
+		return &Service{
+			repo:  repo,
+			log:   zaptest.NewLogger(t),
+			clock: clock.NewFakeClock(now),
+		}
+	}
+
+	t.Run("lean by default", func(t *testing.T) {
+		repo := newRepo()
+		resp, err := newService(t, repo).GetRecentlyResolved(ctx, "agent", domain.RecentlyResolvedRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.Items, 2)
+		assert.Equal(t, "Acme", resp.Items[0].EntityName)
+		assert.Equal(t, int64(12000), resp.Items[0].AmountDueAtClaim)
+		assert.Nil(t, resp.Items[0].Snapshot)
+		assert.Nil(t, resp.Items[0].CurrentState)
+		assert.Zero(t, repo.snapshots)
+	})
+
+	t.Run("detailed returns the full snapshot and the state at resolution", func(t *testing.T) {
+		repo := newRepo()
+		resp, err := newService(t, repo).GetRecentlyResolved(ctx, "agent", domain.RecentlyResolvedRequest{Detailed: true})
+		require.NoError(t, err)
+		require.Len(t, resp.Items, 2)
+
+		first := resp.Items[0]
+		assert.Equal(t, map[string]any{
+			"entity_name":    "Acme",
+			"amount_due":     float64(12000),
+			"invoice_number": "INV-7",
+			"customer_id":    "42",
+			"days_overdue":   float64(12),
+		}, first.Snapshot)
+		assert.Equal(t, "PAID", first.CurrentState["status"])
+
+		// Resolved before resolution snapshots were taken.
+		second := resp.Items[1]
+		assert.Empty(t, second.Snapshot)
+		assert.Nil(t, second.CurrentState)
+		assert.Zero(t, repo.snapshots, "detailed items must not load entities one by one")
+	})
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestReleaseOnResolve(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`CREATE TABLE billing_operation_assignments (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id BIGINT NOT NULL,
+			assigned_to TEXT NOT NULL,
+			assigned_at TIMESTAMP NOT NULL,
+			assignment_expires_at TIMESTAMP NOT NULL,
+			status TEXT NOT NULL DEFAULT 'assigned',
+			released_at TIMESTAMP,
+			released_by TEXT,
+			release_reason TEXT,
+			last_action_at TIMESTAMP,
+			snapshot_metadata TEXT,
+			handed_off_at TIMESTAMP,
+			handed_off_by TEXT,
+			handoff_note TEXT,
+			resolved_at TIMESTAMP,
+			resolved_by TEXT,
+			resolution_snapshot TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX ux_billing_assignments_entity ON billing_operation_assignments(org_id, entity_type, entity_id)`,
+		`CREATE TABLE billing_operation_actions (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id BIGINT NOT NULL,
+			action_type TEXT NOT NULL,
+			action_bucket TIMESTAMP NOT NULL,
+			idempotency_key TEXT,
+			metadata TEXT,
+			actor_type TEXT,
+			actor_id TEXT,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE organization_billing_preferences (
+			org_id BIGINT PRIMARY KEY,
+			release_on_resolve BOOLEAN NOT NULL DEFAULT false
+		)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	node, _ := snowflake.NewNode(1)
+	svc := NewService(Params{
+		DB:    db,
+		Log:   zap.NewNop(),
+		Clock: clock.NewFakeClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)),
+		GenID: node,
+		Cfg:   config.Config{},
+	})
+
+	orgID := node.Generate()
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	ctx = auditcontext.WithActor(ctx, "user", "alice")
+
+	// resolved claims an invoice for alice and resolves it while it is still
+	// overdue, then has bob try to pick it up.
+	resolved := func() (string, error) {
+		entityID := node.Generate().String()
+		_, err := svc.ClaimAssignment(ctx, domain.ClaimAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+			AssignedTo: "alice",
+		})
+		require.NoError(t, err)
+		require.NoError(t, svc.ResolveAssignment(ctx, domain.ResolveAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+			Resolution: "promised to pay",
+		}))
+		_, err = svc.ClaimAssignment(ctx, domain.ClaimAssignmentRequest{
+			EntityType: domain.EntityTypeInvoice,
+			EntityID:   entityID,
+			AssignedTo: "bob",
+		})
+		return entityID, err
+	}
+	assignment := func(entityID string) (status, assignedTo string) {
+		var row struct {
+			Status     string
+			AssignedTo string
+		}
+		require.NoError(t, db.Raw(
+			`SELECT status, assigned_to FROM billing_operation_assignments WHERE org_id = ? AND entity_id = ?`,
+			orgID, entityID,
+		).Scan(&row).Error)
+		return row.Status, row.AssignedTo
+	}
+
+	t.Run("resolving is terminal by default", func(t *testing.T) {
+		entityID, err := resolved()
+		assert.ErrorIs(t, err, domain.ErrAssignmentConflict)
+
+		status, assignedTo := assignment(entityID)
+		assert.Equal(t, domain.AssignmentStatusResolved, status)
+		assert.Equal(t, "alice", assignedTo)
+	})
+
+	require.NoError(t, db.Exec(
+		`INSERT INTO organization_billing_preferences (org_id, release_on_resolve) VALUES (?, true)`, orgID,
+	).Error)
+
+	t.Run("released on resolve, still-overdue work can be claimed again", func(t *testing.T) {
+		entityID, err := resolved()
+		require.NoError(t, err)
+
+		status, assignedTo := assignment(entityID)
+		assert.Equal(t, domain.AssignmentStatusAssigned, status)
+		assert.Equal(t, "bob", assignedTo)
+	})
+}
@@ -93,7 +93,7 @@ func TestFinOpsSnapshotRepository(t *testing.T) {
 	})
 
 	t.Run("FindByOrg", func(t *testing.T) {
-		snaps, err := repo.FindByOrg(ctx, orgID, domain.PeriodTypeDaily, start, start.Add(48*time.Hour))
+		snaps, err := repo.FindByOrg(ctx, orgID, domain.PeriodTypeDaily, start, start.Add(48*time.Hour), nil)
 		assert.NoError(t, err)
 		assert.Len(t, snaps, 2) // user_repo_test and user_repo_test_2
 
@@ -102,6 +102,31 @@ func TestFinOpsSnapshotRepository(t *testing.T) {
 		assert.Equal(t, user2, snaps[1].UserID)
 	})
 
+	t.Run("FindByOrg_ExcludeUsers", func(t *testing.T) {
+		snaps, err := repo.FindByOrg(ctx, orgID, domain.PeriodTypeDaily, start, start.Add(48*time.Hour), []string{userID})
+		assert.NoError(t, err)
+		if assert.Len(t, snaps, 1) {
+			assert.Equal(t, user2, snaps[0].UserID)
+		}
+	})
+
+	t.Run("StreamByOrg", func(t *testing.T) {
+		var users []string
+		err := repo.StreamByOrg(ctx, orgID, domain.PeriodTypeDaily, start, start.Add(48*time.Hour), nil, func(snap domain.FinOpsScoreSnapshot) error {
+			users = append(users, snap.UserID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{userID, user2}, users)
+
+		otherCtx := orgcontext.WithOrgID(context.Background(), otherOrgID.Int64())
+		err = repo.StreamByOrg(otherCtx, orgID, domain.PeriodTypeDaily, start, end, nil, func(domain.FinOpsScoreSnapshot) error {
+			t.Fatal("expected no rows for another org's context")
+			return nil
+		})
+		assert.ErrorIs(t, err, domain.ErrInvalidOrganization)
+	})
+
 	t.Run("MapRowsHelper", func(t *testing.T) {
 		// Verify mapping handles JSON correctly via struct
 	})
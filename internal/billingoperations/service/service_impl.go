@@ -4,10 +4,10 @@ import (
 	"context"
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -20,8 +20,13 @@ import (
 	"github.com/smallbiznis/railzway/internal/billingoperations/repository" // Import repository
 	"github.com/smallbiznis/railzway/internal/clock"
 	"github.com/smallbiznis/railzway/internal/config"
+	emaildeliverydomain "github.com/smallbiznis/railzway/internal/emaildelivery/domain"
+	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
 
 	"github.com/smallbiznis/railzway/internal/orgcontext"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+	dbpkg "github.com/smallbiznis/railzway/pkg/db"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"gorm.io/datatypes"
@@ -35,7 +40,9 @@ type Params struct {
 	Log      *zap.Logger
 	Clock    clock.Clock
 	GenID    *snowflake.Node
-	AuditSvc auditdomain.Service `optional:"true"`
+	AuditSvc auditdomain.Service       `optional:"true"`
+	Notifier domain.BreachNotifier     `optional:"true"`
+	Flags    featuredomain.FlagService `optional:"true"`
 	Cfg      config.Config
 
 	BillingConfig *config.BillingConfigHolder
@@ -48,49 +55,208 @@ type Service struct {
 	clock    clock.Clock
 	genID    *snowflake.Node
 	auditSvc auditdomain.Service
+	notifier domain.BreachNotifier
+	flags    featuredomain.FlagService
 	encKey   []byte
 
 	billingCfg   *config.BillingConfigHolder
+
+	performanceWorkers int
+	scoringDayOffset   int
+	scoringBatchSize   int
+
+	actionMetadataMaxBytes int
+	lowercaseCustomerEmail bool
+	includeCustomerPhone   bool
+	showConflictAssignee   bool
+	archiveActions         bool
+	arDriftThreshold       int64
+
+	auditRetries      int
+	auditRetryBackoff time.Duration
+	auditRequired     map[string]bool
 }
 
 func NewService(p Params) domain.Service {
-	repo := repository.NewRepository(p.DB)
+	db := dbpkg.EnforceTenantScope(p.DB)
+	repo := repository.NewRepository(db)
 
-	secret := strings.TrimSpace(p.Cfg.PaymentProviderConfigSecret)
-	var key []byte
-	if secret != "" {
-		sum := sha256.Sum256([]byte(secret))
-		key = sum[:]
-	}
+	key := publicTokenKey(p.Cfg.PaymentProviderConfigSecret)
+	log := p.Log.Named("billingoperations.service")
 
 	return &Service{
 		repo:         repo,
-		db:           p.DB,
-		log:          p.Log.Named("billingoperations.service"),
+		db:           db,
+		log:          log,
 		clock:        p.Clock,
 		genID:        p.GenID,
 		auditSvc:     p.AuditSvc,
+		notifier:     p.Notifier,
+		flags:        p.Flags,
 		encKey:       key,
 		billingCfg:   p.BillingConfig,
+
+		performanceWorkers: p.Cfg.FinOpsPerformanceWorkers,
+		scoringDayOffset:   p.Cfg.FinOpsScoringDayOffset,
+		scoringBatchSize:   p.Cfg.FinOpsScoringBatchSize,
+
+		actionMetadataMaxBytes: p.Cfg.BillingOpsActionMetadataMaxBytes,
+		lowercaseCustomerEmail: p.Cfg.CustomerEmailLowercase,
+		includeCustomerPhone:   p.Cfg.BillingOpsIncludeCustomerPhone,
+		showConflictAssignee:   p.Cfg.BillingOpsConflictShowAssignee,
+		archiveActions:         p.Cfg.BillingOpsArchiveActions,
+		arDriftThreshold:       p.Cfg.BillingOpsARDriftThreshold,
+
+		auditRetries:  p.Cfg.BillingOpsAuditRetries,
+		auditRequired: newAuditRequired(p.Cfg.BillingOpsAuditRequired, log),
 	}
 }
 
-func (s *Service) ListOverdueInvoices(ctx context.Context, limit int) (domain.OverdueInvoicesResponse, error) {
+// teamViewsFilter decides which users are left out of team workload and
+// performance aggregations: system actors, unless their org includes them.
+// Each org's preference is read once, and only when one of its system
+// actors comes up, so a filter can serve a sweep across orgs.
+type teamViewsFilter struct {
+	repo    domain.Repository
+	include map[snowflake.ID]bool
+}
+
+func (s *Service) newTeamViewsFilter() *teamViewsFilter {
+	return &teamViewsFilter{repo: s.repo, include: make(map[snowflake.ID]bool)}
+}
+
+// hidden reports whether userID of orgID is left out of team views.
+func (f *teamViewsFilter) hidden(ctx context.Context, orgID snowflake.ID, userID string) (bool, error) {
+	if !domain.IsSystemActor(userID) {
+		return false, nil
+	}
+	include, ok := f.include[orgID]
+	if !ok {
+		var err error
+		if include, err = f.repo.FetchTeamViewsIncludeSystemActors(ctx, orgID); err != nil {
+			return false, err
+		}
+		f.include[orgID] = include
+	}
+	return !include, nil
+}
+
+// maxExcludeUserIDs bounds how many users a team view request may exclude.
+const maxExcludeUserIDs = 100
+
+// defaultAssignmentTTLMinutes is how long a claimed or reassigned
+// assignment is held before it expires.
+const defaultAssignmentTTLMinutes = 120
+
+// assignmentUpdateAttempts bounds how often a version-checked assignment
+// update is retried after losing a race with another writer.
+const assignmentUpdateAttempts = 3
+
+// normalizeExcludeUserIDs trims and de-duplicates the users a team view
+// request excludes. Blank IDs or more than maxExcludeUserIDs users are
+// rejected with ErrInvalidExcludeUsers.
+func normalizeExcludeUserIDs(ids []string) ([]string, error) {
+	if len(ids) > maxExcludeUserIDs {
+		return nil, domain.ErrInvalidExcludeUsers
+	}
+	seen := make(map[string]struct{}, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return nil, domain.ErrInvalidExcludeUsers
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// agingRepo returns the repository scoped to the configured policy for
+// invoices without a due date, so overdue, collection and inbox views agree.
+func (s *Service) agingRepo() domain.Repository {
+	policy := s.billingCfg.Get().MissingDueDate
+	if policy.Mode != config.MissingDueDateNetTerms {
+		return s.repo
+	}
+	days := policy.NetTermsDays
+	return s.repo.WithDueDatePolicy(domain.DueDatePolicy{NetTermsDays: &days})
+}
+
+// exposureTopCustomers returns how many customers the exposure analysis lists.
+func exposureTopCustomers(cfg config.ExposureAnalysisConfig) int {
+	if cfg.TopCustomers <= 0 {
+		return config.DefaultBillingConfig().ExposureAnalysis.TopCustomers
+	}
+	return min(cfg.TopCustomers, config.MaxExposureTopCustomers)
+}
+
+// inboxFilter maps the configured inbox options and the request's filters
+// onto the repository filter.
+func (s *Service) inboxFilter(req domain.InboxRequest) domain.InboxFilter {
+	cfg := s.billingCfg.Get().Inbox
+	threshold := cfg.HighExposureThreshold
+	if threshold <= 0 {
+		threshold = config.DefaultBillingConfig().Inbox.HighExposureThreshold
+	}
+	return domain.InboxFilter{
+		HighExposureThreshold:  threshold,
+		IncludeCurrentExposure: cfg.IncludeCurrentExposure,
+		RiskCategory:           req.RiskCategory,
+		MinAmountDue:           req.MinAmountDue,
+	}
+}
+
+// listLimit resolves a list endpoint's page size: an explicit request limit
+// wins, then the organization's configured default, then fallback.
+func (s *Service) listLimit(ctx context.Context, orgID snowflake.ID, requested int, configured func(domain.ListDefaults) int, fallback int) (int, error) {
+	if requested > 0 {
+		return requested, nil
+	}
+	defaults, err := s.repo.FetchListDefaults(ctx, orgID)
+	if err != nil {
+		return 0, err
+	}
+	if limit := configured(defaults); limit > 0 {
+		return limit, nil
+	}
+	return fallback, nil
+}
+
+// paymentIssueEventTypes returns the payment event types surfaced as payment
+// issues, falling back to failed payments when none are configured.
+func (s *Service) paymentIssueEventTypes() []string {
+	types := s.billingCfg.Get().PaymentIssues.EventTypes
+	if len(types) == 0 {
+		return []string{paymentdomain.EventTypePaymentFailed}
+	}
+	return types
+}
+
+func (s *Service) ListOverdueInvoices(ctx context.Context, limit int, pageToken string) (domain.OverdueInvoicesResponse, error) {
 	orgID, ok := orgcontext.OrgIDFromContext(ctx)
 	if !ok || orgID == 0 {
 		return domain.OverdueInvoicesResponse{}, domain.ErrInvalidOrganization
 	}
-	if limit <= 0 {
-		limit = 25
+	limit, err := s.listLimit(ctx, orgID, limit, func(d domain.ListDefaults) int { return d.Overdue }, 25)
+	if err != nil {
+		return domain.OverdueInvoicesResponse{}, err
 	}
 
 	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
 	if err != nil {
 		return domain.OverdueInvoicesResponse{}, err
 	}
+	calendar, err := s.repo.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return domain.OverdueInvoicesResponse{}, err
+	}
 
 	now := s.clock.Now().UTC()
-	rows, err := s.repo.ListOverdueInvoices(ctx, orgID, currency, now, limit)
+	rows, pageInfo, err := s.agingRepo().ListOverdueInvoices(ctx, orgID, now, pagination.Pagination{PageToken: pageToken, PageSize: limit})
 	if err != nil {
 		return domain.OverdueInvoicesResponse{}, err
 	}
@@ -102,10 +268,7 @@ func (s *Service) ListOverdueInvoices(ctx context.Context, limit int) (domain.Ov
 			invoiceNumber = row.InvoiceID.String()
 		}
 
-		daysOverdue := int(now.Sub(row.DueAt).Hours() / 24)
-		if daysOverdue < 0 {
-			daysOverdue = 0
-		}
+		daysOverdue := calendar.DaysOverdue(row.DueAt, now)
 
 		assignedToProp := domain.Assignment{}
 		if row.AssignedTo.Valid {
@@ -142,34 +305,35 @@ func (s *Service) ListOverdueInvoices(ctx context.Context, limit int) (domain.Ov
 			)
 		}
 
-		assignmentPtr := &assignedToProp
-		if assignedToProp.AssignedTo == "" && assignedToProp.Status == "" {
-			assignmentPtr = nil
-		}
+		assignmentPtr := activeAssignment(assignedToProp)
 
+		rowCurrency := itemCurrency(row.Currency, currency)
 		invoices = append(invoices, domain.OverdueInvoice{
-			InvoiceID:     row.InvoiceID.String(),
-			InvoiceNumber: invoiceNumber,
-			CustomerID:    row.CustomerID.String(),
-			CustomerName:  row.CustomerName,
-			AmountDue:     row.AmountDue,
-			Currency:      currency,
-			DueAt:         row.DueAt,
-			DaysOverdue:   daysOverdue,
-			PublicToken:   decryptToken(s.encKey, row.TokenHash.String),
-			Assignment:    assignmentPtr,
+			InvoiceID:        row.InvoiceID.String(),
+			InvoiceNumber:    invoiceNumber,
+			CustomerID:       row.CustomerID.String(),
+			CustomerName:     row.CustomerName,
+			AmountDue:        row.AmountDue,
+			Currency:         rowCurrency,
+			CurrencyExponent: s.currencyExponent(rowCurrency),
+			DueAt:            row.DueAt,
+			DaysOverdue:      daysOverdue,
+			PublicToken:      s.decryptPublicToken(orgID, row.TokenHash.String),
+			Assignment:       assignmentPtr,
 		})
 
 	}
 
 	return domain.OverdueInvoicesResponse{
-		Currency: currency,
-		Invoices: invoices,
-		HasData:  len(invoices) > 0,
+		PageInfo:         pageInfo,
+		Currency:         currency,
+		CurrencyExponent: s.currencyExponent(currency),
+		Invoices:         invoices,
+		HasData:          len(invoices) > 0,
 	}, nil
 }
 
-func (s *Service) ListOutstandingCustomers(ctx context.Context, limit int) (domain.OutstandingCustomersResponse, error) {
+func (s *Service) ListOutstandingCustomers(ctx context.Context, limit int, includeDrafts bool, pageToken string) (domain.OutstandingCustomersResponse, error) {
 	orgID, ok := orgcontext.OrgIDFromContext(ctx)
 	if !ok || orgID == 0 {
 		return domain.OutstandingCustomersResponse{}, domain.ErrInvalidOrganization
@@ -182,9 +346,13 @@ func (s *Service) ListOutstandingCustomers(ctx context.Context, limit int) (doma
 	if err != nil {
 		return domain.OutstandingCustomersResponse{}, err
 	}
+	calendar, err := s.repo.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return domain.OutstandingCustomersResponse{}, err
+	}
 
 	now := s.clock.Now().UTC()
-	rows, err := s.repo.ListOutstandingCustomers(ctx, orgID, currency, now, limit)
+	rows, pageInfo, err := s.agingRepo().ListOutstandingCustomers(ctx, orgID, now, includeDrafts, pagination.Pagination{PageToken: pageToken, PageSize: limit})
 	if err != nil {
 		return domain.OutstandingCustomersResponse{}, err
 	}
@@ -209,10 +377,7 @@ func (s *Service) ListOutstandingCustomers(ctx context.Context, limit int) (doma
 		if row.OldestOverdueAt.Valid {
 			due := row.OldestOverdueAt.Time.UTC()
 			oldestOverdueAt = &due
-			oldestOverdueDays = int(now.Sub(due).Hours() / 24)
-			if oldestOverdueDays < 0 {
-				oldestOverdueDays = 0
-			}
+			oldestOverdueDays = calendar.DaysOverdue(due, now)
 		}
 
 		var lastPaymentAt *time.Time
@@ -256,32 +421,43 @@ func (s *Service) ListOutstandingCustomers(ctx context.Context, limit int) (doma
 			)
 		}
 
-		assignmentPtr := &assignedToProp
-		if assignedToProp.AssignedTo == "" && assignedToProp.Status == "" {
-			assignmentPtr = nil
-		}
+		assignmentPtr := activeAssignment(assignedToProp)
 
+		rowCurrency := itemCurrency(row.Currency, currency)
 		customers = append(customers, domain.OutstandingCustomer{
 			CustomerID:             row.CustomerID.String(),
 			CustomerName:           row.CustomerName,
 			OutstandingBalance:     row.Outstanding,
-			Currency:               currency,
+			PendingBalance:         row.Pending,
+			PendingInvoices:        row.PendingInvoices,
+			Currency:               rowCurrency,
+			CurrencyExponent:       s.currencyExponent(rowCurrency),
 			OldestOverdueInvoiceID: oldestOverdueInvoiceID,
 			OldestOverdueInvoice:   oldestOverdueInvoiceNumber,
 			OldestOverdueAt:        oldestOverdueAt,
 			LastPaymentAt:          lastPaymentAt,
 			OldestOverdueDays:      oldestOverdueDays,
 			HasOverdueOutstanding:  oldestOverdueAt != nil,
-			PublicToken:            decryptToken(s.encKey, row.TokenHash.String),
+			PublicToken:            s.decryptPublicToken(orgID, row.TokenHash.String),
 			Assignment:             assignmentPtr,
 		})
 
 	}
 
+	if err := s.auditRead(ctx, orgID, reportOutstandingCustomers, map[string]any{
+		"currency":       currency,
+		"limit":          limit,
+		"include_drafts": includeDrafts,
+	}); err != nil {
+		return domain.OutstandingCustomersResponse{}, err
+	}
+
 	return domain.OutstandingCustomersResponse{
-		Currency:  currency,
-		Customers: customers,
-		HasData:   len(customers) > 0,
+		PageInfo:         pageInfo,
+		Currency:         currency,
+		CurrencyExponent: s.currencyExponent(currency),
+		Customers:        customers,
+		HasData:          len(customers) > 0,
 	}, nil
 }
 
@@ -295,7 +471,7 @@ func (s *Service) ListPaymentIssues(ctx context.Context, limit int) (domain.Paym
 	}
 
 	now := s.clock.Now().UTC()
-	rows, err := s.repo.ListPaymentIssues(ctx, orgID, now, limit)
+	rows, err := s.repo.ListPaymentIssues(ctx, orgID, s.paymentIssueEventTypes(), s.billingCfg.Get().PaymentIssues.ClearOnSuccess, now, limit)
 	if err != nil {
 		return domain.PaymentIssuesResponse{}, err
 	}
@@ -342,10 +518,7 @@ func (s *Service) ListPaymentIssues(ctx context.Context, limit int) (domain.Paym
 			)
 		}
 
-		assignmentPtr := &assignedToProp
-		if assignedToProp.AssignedTo == "" && assignedToProp.Status == "" {
-			assignmentPtr = nil
-		}
+		assignmentPtr := activeAssignment(assignedToProp)
 
 		issues = append(issues, domain.PaymentIssue{
 			CustomerID:          row.CustomerID.String(),
@@ -369,34 +542,43 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 	if !ok || orgID == 0 {
 		return domain.BillingOperationsResponse{}, domain.ErrInvalidOrganization
 	}
-	if limit <= 0 {
-		limit = 25
+	limit, err := s.listLimit(ctx, orgID, limit, func(d domain.ListDefaults) int { return d.Collection }, 25)
+	if err != nil {
+		return domain.BillingOperationsResponse{}, err
 	}
 
 	currency, err := s.repo.FetchOrgCurrency(ctx, orgID)
 	if err != nil {
 		return domain.BillingOperationsResponse{}, err
 	}
+	calendar, err := s.repo.FetchOverdueCalendar(ctx, orgID)
+	if err != nil {
+		return domain.BillingOperationsResponse{}, err
+	}
 
 	now := s.clock.Now().UTC()
-	summary, err := s.repo.LoadActionSummary(ctx, orgID, currency, now)
+	summary, err := s.agingRepo().LoadActionSummary(ctx, orgID, currency, s.paymentIssueEventTypes(), s.billingCfg.Get().PaymentIssues.ClearOnSuccess, now)
 	if err != nil {
 		return domain.BillingOperationsResponse{}, err
 	}
 
-	overdueRows, err := s.repo.ListOverdueInvoices(ctx, orgID, currency, now, limit)
+	overdueRows, _, err := s.agingRepo().ListOverdueInvoices(ctx, orgID, now, pagination.Pagination{PageSize: limit})
+	if err != nil {
+		return domain.BillingOperationsResponse{}, err
+	}
+	failedRows, err := s.repo.ListFailedPaymentActions(ctx, orgID, s.paymentIssueEventTypes(), now, limit)
 	if err != nil {
 		return domain.BillingOperationsResponse{}, err
 	}
-	failedRows, err := s.repo.ListFailedPaymentActions(ctx, orgID, currency, now, limit)
+	queueFilter, err := s.repo.FetchCollectionQueueFilter(ctx, orgID)
 	if err != nil {
 		return domain.BillingOperationsResponse{}, err
 	}
-	queueRows, err := s.repo.ListCollectionQueue(ctx, orgID, currency, now, limit)
+	queueRows, _, err := s.agingRepo().ListCollectionQueue(ctx, orgID, now, queueFilter, pagination.Pagination{PageSize: limit})
 	if err != nil {
 		return domain.BillingOperationsResponse{}, err
 	}
-	paymentRows, err := s.repo.ListPaymentIssues(ctx, orgID, now, limit)
+	paymentRows, err := s.repo.ListPaymentIssues(ctx, orgID, s.paymentIssueEventTypes(), s.billingCfg.Get().PaymentIssues.ClearOnSuccess, now, limit)
 	if err != nil {
 		return domain.BillingOperationsResponse{}, err
 	}
@@ -409,10 +591,7 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 		}
 
 		dueAt := row.DueAt.UTC()
-		daysOverdue := int(now.Sub(dueAt).Hours() / 24)
-		if daysOverdue < 0 {
-			daysOverdue = 0
-		}
+		daysOverdue := calendar.DaysOverdue(dueAt, now)
 
 		assignedToProp := domain.Assignment{}
 		if row.AssignedTo.Valid {
@@ -449,11 +628,9 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 			)
 		}
 
-		assignmentPtr := &assignedToProp
-		if assignedToProp.AssignedTo == "" && assignedToProp.Status == "" {
-			assignmentPtr = nil
-		}
+		assignmentPtr := activeAssignment(assignedToProp)
 
+		rowCurrency := itemCurrency(row.Currency, currency)
 		criticalActions = append(criticalActions, domain.CriticalAction{
 			Category:            domain.CriticalCategoryOverdueInvoice,
 			InvoiceID:           row.InvoiceID.String(),
@@ -461,12 +638,13 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 			CustomerID:          row.CustomerID.String(),
 			CustomerName:        row.CustomerName,
 			AmountDue:           row.AmountDue,
-			Currency:            currency,
+			Currency:            rowCurrency,
+			CurrencyExponent:    s.currencyExponent(rowCurrency),
 			DueAt:               &dueAt,
 			DaysOverdue:         daysOverdue,
 			AssignedTo:          assignedToProp.AssignedTo,
 			AssignmentExpiresAt: &assignedToProp.AssignmentExpiresAt,
-			PublicToken:         decryptToken(s.encKey, row.TokenHash.String),
+			PublicToken:         s.decryptPublicToken(orgID, row.TokenHash.String),
 			Assignment:          assignmentPtr,
 		})
 	}
@@ -490,10 +668,7 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 		if row.DueAt.Valid {
 			due := row.DueAt.Time.UTC()
 			dueAt = &due
-			daysOverdue = int(now.Sub(due).Hours() / 24)
-			if daysOverdue < 0 {
-				daysOverdue = 0
-			}
+			daysOverdue = calendar.DaysOverdue(due, now)
 		}
 
 		var lastAttempt *time.Time
@@ -537,16 +712,14 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 			)
 		}
 
-		assignmentPtr := &assignedToProp
-		if assignedToProp.AssignedTo == "" && assignedToProp.Status == "" {
-			assignmentPtr = nil
-		}
+		assignmentPtr := activeAssignment(assignedToProp)
 
 		amountDue := int64(0)
 		if row.AmountDue.Valid {
 			amountDue = row.AmountDue.Int64
 		}
 
+		rowCurrency := itemCurrency(row.Currency.String, currency)
 		criticalActions = append(criticalActions, domain.CriticalAction{
 			Category:            domain.CriticalCategoryFailedPayment,
 			InvoiceID:           invoiceID,
@@ -554,13 +727,14 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 			CustomerID:          row.CustomerID.String(),
 			CustomerName:        row.CustomerName,
 			AmountDue:           amountDue,
-			Currency:            currency,
+			Currency:            rowCurrency,
+			CurrencyExponent:    s.currencyExponent(rowCurrency),
 			DueAt:               dueAt,
 			DaysOverdue:         daysOverdue,
 			LastAttempt:         lastAttempt,
 			AssignedTo:          assignedToProp.AssignedTo,
 			AssignmentExpiresAt: &assignedToProp.AssignmentExpiresAt,
-			PublicToken:         decryptToken(s.encKey, row.TokenHash.String),
+			PublicToken:         s.decryptPublicToken(orgID, row.TokenHash.String),
 			Assignment:          assignmentPtr,
 		})
 	}
@@ -603,10 +777,7 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 		if row.OldestUnpaidAt.Valid {
 			due := row.OldestUnpaidAt.Time.UTC()
 			oldestUnpaidAt = &due
-			oldestUnpaidDays = int(now.Sub(due).Hours() / 24)
-			if oldestUnpaidDays < 0 {
-				oldestUnpaidDays = 0
-			}
+			oldestUnpaidDays = calendar.DaysOverdue(due, now)
 		}
 
 		var lastPaymentAt *time.Time
@@ -650,27 +821,36 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 			)
 		}
 
-		assignmentPtr := &assignedToProp
-		if assignedToProp.AssignedTo == "" && assignedToProp.Status == "" {
-			assignmentPtr = nil
+		assignmentPtr := activeAssignment(assignedToProp)
+
+		var lastEmailBouncedAt *time.Time
+		lastEmailBounced := row.LastEmailStatus.String == emaildeliverydomain.StatusBounced
+		if lastEmailBounced && row.LastEmailAt.Valid {
+			bouncedAt := row.LastEmailAt.Time.UTC()
+			lastEmailBouncedAt = &bouncedAt
 		}
 
+		rowCurrency := itemCurrency(row.Currency, currency)
 		queue = append(queue, domain.CollectionQueueEntry{
 			CustomerID:            row.CustomerID.String(),
 			CustomerName:          row.CustomerName,
 			OutstandingBalance:    row.Outstanding,
-			Currency:              currency,
+			DisputedAmount:        row.DisputedAmount,
+			Currency:              rowCurrency,
+			CurrencyExponent:      s.currencyExponent(rowCurrency),
 			OldestUnpaidInvoiceID: oldestInvoiceID,
 			OldestUnpaidInvoice:   oldestInvoiceNumber,
 			OldestUnpaidAt:        oldestUnpaidAt,
 			OldestUnpaidDays:      oldestUnpaidDays,
 			LastPaymentAt:         lastPaymentAt,
 			AgingBucket:           computeAgingBucket(oldestUnpaidDays),
-			RiskLevel:             computeRiskLevel(row.Outstanding, oldestUnpaidDays),
+			RiskLevel:             computeRiskLevel(riskAmount(row.Outstanding-row.DisputedAmount, rowCurrency, currency), oldestUnpaidDays),
 			AssignedTo:            assignedToProp.AssignedTo,
 			AssignmentExpiresAt:   &assignedToProp.AssignmentExpiresAt,
-			PublicToken:           decryptToken(s.encKey, row.TokenHash.String),
+			PublicToken:           s.decryptPublicToken(orgID, row.TokenHash.String),
 			Assignment:            assignmentPtr,
+			LastEmailBounced:      lastEmailBounced,
+			LastEmailBouncedAt:    lastEmailBouncedAt,
 		})
 
 	}
@@ -717,10 +897,7 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 			)
 		}
 
-		assignmentPtr := &assignedToProp
-		if assignedToProp.AssignedTo == "" && assignedToProp.Status == "" {
-			assignmentPtr = nil
-		}
+		assignmentPtr := activeAssignment(assignedToProp)
 
 		issues = append(issues, domain.PaymentIssue{
 			CustomerID:          row.CustomerID.String(),
@@ -734,7 +911,8 @@ func (s *Service) GetOperations(ctx context.Context, limit int) (domain.BillingO
 	}
 
 	return domain.BillingOperationsResponse{
-		Currency: currency,
+		Currency:         currency,
+		CurrencyExponent: s.currencyExponent(currency),
 		Summary: domain.ActionSummary{
 			CustomersWithOutstanding: summary.CustomersWithOutstanding,
 			OverdueInvoices:          summary.OverdueInvoices,
@@ -761,7 +939,8 @@ func (s *Service) RecordAction(ctx context.Context, req domain.RecordActionReque
 	}
 
 	actionType := strings.TrimSpace(req.ActionType)
-	if actionType != domain.ActionTypeFollowUp &&
+	if actionType != domain.ActionTypeContact &&
+		actionType != domain.ActionTypeFollowUp &&
 		actionType != domain.ActionTypeRetryPayment &&
 		actionType != domain.ActionTypeMarkReviewed {
 		return domain.RecordActionResponse{}, domain.ErrInvalidActionType
@@ -777,8 +956,16 @@ func (s *Service) RecordAction(ctx context.Context, req domain.RecordActionReque
 		return domain.RecordActionResponse{}, domain.ErrInvalidIdempotencyKey
 	}
 
+	if err := s.validateActionMetadata(req.Metadata); err != nil {
+		return domain.RecordActionResponse{}, err
+	}
+
+	loc, err := s.repo.FetchOrgLocation(ctx, orgID)
+	if err != nil {
+		return domain.RecordActionResponse{}, err
+	}
 	now := s.clock.Now().UTC()
-	bucket := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	bucket := actionBucket(now, loc)
 	actionID := s.genID.Generate()
 
 	beforeSnapshot, err := s.repo.LoadEntitySnapshot(ctx, orgID, entityType, entityID)
@@ -804,71 +991,94 @@ func (s *Service) RecordAction(ctx context.Context, req domain.RecordActionReque
 
 	actorType, actorID := auditcontext.ActorFromContext(ctx)
 
-	inserted, err := s.repo.InsertBillingAction(ctx, domain.BillingActionRecord{
-		ID:             actionID,
-		OrgID:          orgID,
-		EntityType:     entityType,
-		EntityID:       entityID,
-		ActionType:     actionType,
-		ActionBucket:   bucket,
-		IdempotencyKey: idempotencyKey,
-		Metadata:       metadata,
-		ActorType:      actorType,
-		ActorID:        actorID,
-		CreatedAt:      now,
-	})
-	if err != nil {
-		return domain.RecordActionResponse{}, err
-	}
+	// A required audit entry is written in the transaction that records the
+	// action, so a failed write leaves neither and a retry records both.
+	// Best-effort entries are written after commit and cannot undo it.
+	auditRequired := s.auditIsRequired(auditCategoryFinancial)
+	var (
+		inserted         bool
+		actionStatus     string
+		resolvedActionID string
+		audit            auditEntry
+	)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
 
-	actionStatus := domain.ActionStatusRecorded
-	resolvedActionID := actionID.String()
-	if !inserted {
-		actionStatus = domain.ActionStatusDuplicate
-		resolvedActionID = ""
-		if idempotencyKey != "" {
-			existing, err := s.repo.FindActionByIdempotencyKey(ctx, orgID, idempotencyKey)
-			if err == nil && existing != nil {
-				resolvedActionID = existing.ID.String()
-			}
-		} else {
-			existing, err := s.repo.FindActionByBucket(ctx, orgID, entityType, entityID, actionType, bucket)
-			if err == nil && existing != nil {
-				resolvedActionID = existing.ID.String()
+		var err error
+		inserted, err = repoTx.InsertBillingAction(ctx, domain.BillingActionRecord{
+			ID:             actionID,
+			OrgID:          orgID,
+			EntityType:     entityType,
+			EntityID:       entityID,
+			ActionType:     actionType,
+			ActionBucket:   bucket,
+			IdempotencyKey: idempotencyKey,
+			Metadata:       metadata,
+			ActorType:      actorType,
+			ActorID:        actorID,
+			CreatedAt:      now,
+		})
+		if err != nil {
+			return err
+		}
+
+		actionStatus = domain.ActionStatusRecorded
+		resolvedActionID = actionID.String()
+		if !inserted {
+			actionStatus = domain.ActionStatusDuplicate
+			resolvedActionID = ""
+			if idempotencyKey != "" {
+				existing, err := repoTx.FindActionByIdempotencyKey(ctx, orgID, idempotencyKey)
+				if err == nil && existing != nil {
+					resolvedActionID = existing.ID.String()
+				}
+			} else {
+				existing, err := repoTx.FindActionByBucket(ctx, orgID, entityType, entityID, actionType, bucket)
+				if err == nil && existing != nil {
+					resolvedActionID = existing.ID.String()
+				}
 			}
 		}
+
+		targetID := resolvedActionID
+		if targetID == "" {
+			targetID = actionID.String()
+		}
+		audit = auditEntry{
+			category:   auditCategoryFinancial,
+			action:     buildAuditAction(actionType),
+			targetType: "billing_operation_action",
+			targetID:   targetID,
+			metadata: map[string]any{
+				"entity_type":   entityType,
+				"entity_id":     entityID.String(),
+				"action_type":   actionType,
+				"action_bucket": bucket.Format("2006-01-02"),
+				"status":        actionStatus,
+				"before":        beforeSnapshot,
+				"after":         afterSnapshot,
+			},
+		}
+		if auditRequired {
+			return s.emitAuditTx(ctx, tx, orgID, audit)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.RecordActionResponse{}, err
 	}
 
 	// Update assignment status if needed
 	if inserted && actionType != domain.ActionTypeClaim && actionType != domain.ActionTypeRelease {
-		// Fire-and-forget update to assignment
-		// Using a separate goroutine or just ignoring error for now to keep latency low?
-		// Better to do it inline transactionally if we want strong consistency,
-		// but RecordAction isn't transactional with insertBillingAction right now.
-		// Let's do a quick update.
-		if err := s.repo.UpdateAssignmentStatus(
-			ctx, orgID, entityType, entityID,
-			domain.AssignmentStatusAssigned, domain.AssignmentStatusInProgress,
-			now,
-		); err != nil {
+		// The action is already committed, so a failure here is logged
+		// rather than failing the recorded action.
+		if err := s.markAssignmentInProgress(ctx, orgID, entityType, entityID, now); err != nil {
 			s.log.Warn("failed to update assignment status on action", zap.Error(err))
 		}
 	}
 
-	if s.auditSvc != nil {
-		targetID := resolvedActionID
-		if targetID == "" {
-			targetID = actionID.String()
-		}
-		if err := s.auditSvc.AuditLog(ctx, &orgID, "", nil, buildAuditAction(actionType), "billing_operation_action", &targetID, map[string]any{
-			"entity_type":   entityType,
-			"entity_id":     entityID.String(),
-			"action_type":   actionType,
-			"action_bucket": bucket.Format("2006-01-02"),
-			"status":        actionStatus,
-			"before":        beforeSnapshot,
-			"after":         afterSnapshot,
-		}); err != nil {
+	if !auditRequired {
+		if err := s.emitAudit(ctx, orgID, audit); err != nil {
 			return domain.RecordActionResponse{}, err
 		}
 	}
@@ -880,6 +1090,31 @@ func (s *Service) RecordAction(ctx context.Context, req domain.RecordActionReque
 	}, nil
 }
 
+// markAssignmentInProgress moves an assigned entity to in progress once its
+// assignee acts on it. The update is checked against the assignment as
+// read, and retried from a fresh read when a concurrent write got there
+// first.
+func (s *Service) markAssignmentInProgress(ctx context.Context, orgID snowflake.ID, entityType string, entityID snowflake.ID, now time.Time) error {
+	for attempt := 0; attempt < assignmentUpdateAttempts; attempt++ {
+		row, err := s.repo.LoadAssignment(ctx, orgID, entityType, entityID)
+		if err != nil {
+			return err
+		}
+		if row == nil || row.Status != domain.AssignmentStatusAssigned {
+			return nil
+		}
+		err = s.repo.UpdateAssignmentStatus(
+			ctx, orgID, entityType, entityID,
+			domain.AssignmentStatusAssigned, domain.AssignmentStatusInProgress,
+			row.UpdatedAt, now,
+		)
+		if !errors.Is(err, domain.ErrAssignmentModified) {
+			return err
+		}
+	}
+	return domain.ErrAssignmentModified
+}
+
 func (s *Service) ClaimAssignment(
 	ctx context.Context,
 	req domain.ClaimAssignmentRequest,
@@ -911,17 +1146,36 @@ func (s *Service) ClaimAssignment(
 
 	ttlMinutes := req.AssignmentTTLMinutes
 	if ttlMinutes == 0 {
-		ttlMinutes = 120
+		ttlMinutes = defaultAssignmentTTLMinutes
 	}
 	if ttlMinutes < 0 {
 		return domain.AssignmentResponse{}, domain.ErrInvalidAssignmentTTL
 	}
 
+	return s.claimForAssignee(ctx, orgID, entityType, entityID, assignedTo, ttlMinutes, "user", assignedTo)
+}
+
+// claimForAssignee claims the entity for assignedTo, or extends the claim
+// when assignedTo already holds it. The claim action is recorded as taken
+// by actorType/actorID, which is the assignee for self-claims.
+func (s *Service) claimForAssignee(
+	ctx context.Context,
+	orgID snowflake.ID,
+	entityType string,
+	entityID snowflake.ID,
+	assignedTo string,
+	ttlMinutes int,
+	actorType string,
+	actorID string,
+) (domain.AssignmentResponse, error) {
 	now := s.clock.Now().UTC()
 	expiresAt := now.Add(time.Duration(ttlMinutes) * time.Minute)
 
-	var result *domain.AssignmentResponse
-	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	var (
+		result  *domain.AssignmentResponse
+		holding *domain.BillingAssignmentRecord
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		repoTx := s.repo.WithTx(tx)
 
 		existing, err := repoTx.LoadAssignmentForUpdate(
@@ -931,9 +1185,24 @@ func (s *Service) ClaimAssignment(
 			return err
 		}
 
-		if existing != nil && existing.Status != domain.AssignmentStatusReleased {
+		// Orgs that release on resolve let resolved work be claimed afresh;
+		// otherwise a resolved assignment keeps holding the entity.
+		var replacing *time.Time
+		if existing != nil && existing.Status == domain.AssignmentStatusResolved {
+			release, err := repoTx.FetchReleaseOnResolve(ctx, orgID)
+			if err != nil {
+				return err
+			}
+			if release {
+				replacing = &existing.UpdatedAt
+				existing = nil
+			}
+		}
+
+		if existing != nil {
 			// Already assigned
 			if existing.AssignedTo != assignedTo {
+				holding = existing
 				return domain.ErrAssignmentConflict
 			}
 			// Same user, extend expiry
@@ -941,7 +1210,7 @@ func (s *Service) ClaimAssignment(
 			record.AssignmentExpiresAt = expiresAt
 			record.UpdatedAt = now
 
-			if err := repoTx.UpsertAssignment(ctx, record); err != nil {
+			if err := repoTx.UpsertAssignment(ctx, record, &existing.UpdatedAt); err != nil {
 				return err
 			}
 
@@ -962,7 +1231,7 @@ func (s *Service) ClaimAssignment(
 
 		// ✅ claim / insert new
 		// Capture entity snapshot for task stability
-		snapshot, err := s.repo.LoadEntitySnapshot(ctx, orgID, req.EntityType, entityID)
+		snapshot, err := s.repo.LoadEntitySnapshot(ctx, orgID, entityType, entityID)
 		if err != nil {
 			s.log.Warn("failed to load entity snapshot", zap.Error(err))
 			snapshot = make(map[string]interface{})
@@ -988,7 +1257,7 @@ func (s *Service) ClaimAssignment(
 			UpdatedAt:           now,
 		}
 
-		if err := repoTx.UpsertAssignment(ctx, record); err != nil {
+		if err := repoTx.UpsertAssignment(ctx, record, replacing); err != nil {
 			return err
 		}
 
@@ -1019,8 +1288,8 @@ func (s *Service) ClaimAssignment(
 				"assignment_id": record.ID.String(),
 				"expires_at":    expiresAt,
 			},
-			ActorType: "user", // Should ideally come from context
-			ActorID:   assignedTo,
+			ActorType: actorType,
+			ActorID:   actorID,
 			CreatedAt: now,
 		}); err != nil {
 			return err
@@ -1029,6 +1298,9 @@ func (s *Service) ClaimAssignment(
 		return nil
 	})
 
+	if errors.Is(err, domain.ErrAssignmentConflict) && holding != nil {
+		return domain.AssignmentResponse{}, s.assignmentConflict(ctx, orgID, *holding, now)
+	}
 	if err != nil {
 		return domain.AssignmentResponse{}, err
 	}
@@ -1036,24 +1308,81 @@ func (s *Service) ClaimAssignment(
 		return domain.AssignmentResponse{}, fmt.Errorf("internal error: result not set in transaction")
 	}
 
-	if s.auditSvc != nil && result.Status == domain.AssignmentStatusAssigned {
-		targetID := result.Assignment.EntityID
-		_ = s.auditSvc.AuditLog(ctx, &orgID, "", nil,
-			"billing_operations.assignment.claimed",
-			"billing_operation_assignment",
-			&targetID,
-			map[string]any{
+	if result.Status == domain.AssignmentStatusAssigned {
+		entry := auditEntry{
+			category:   auditCategoryOperational,
+			action:     "billing_operations.assignment.claimed",
+			targetType: "billing_operation_assignment",
+			targetID:   result.Assignment.EntityID,
+			metadata: map[string]any{
 				"entity_type": entityType,
 				"entity_id":   entityID.String(),
 				"assigned_to": assignedTo,
 				"expires_at":  expiresAt.Format(time.RFC3339),
 			},
-		)
+		}
+		if actorType != "user" {
+			entry.actorType = actorType
+			entry.metadata["actor_id"] = actorID
+		}
+		if err := s.emitAudit(ctx, orgID, entry); err != nil {
+			return domain.AssignmentResponse{}, err
+		}
 	}
 
 	return *result, nil
 }
 
+// assignmentConflict describes the assignment that blocked a claim. The
+// assignee is only named when BillingOpsConflictShowAssignee allows it; a
+// failed name lookup still reports the assignee id.
+func (s *Service) assignmentConflict(ctx context.Context, orgID snowflake.ID, holding domain.BillingAssignmentRecord, now time.Time) *domain.AssignmentConflictError {
+	fields := assignmentFields(
+		sql.NullString{String: holding.AssignedTo, Valid: true},
+		holding.AssignedAt,
+		sql.NullTime{Time: holding.AssignmentExpiresAt, Valid: !holding.AssignmentExpiresAt.IsZero()},
+		holding.Status,
+		holding.ReleasedAt,
+		holding.ReleasedBy,
+		holding.ReleaseReason,
+		holding.BreachedAt,
+		holding.BreachLevel,
+		holding.LastActionAt,
+		now,
+	)
+	conflict := &domain.AssignmentConflictError{
+		AssigneeName:        domain.AssignmentConflictAnonymous,
+		AssignedAt:          fields.AssignedAt,
+		AssignmentExpiresAt: fields.AssignmentExpiresAt,
+		Status:              fields.Status,
+		SLAStatus:           fields.SLAStatus,
+		TimeSinceAssigned:   fields.TimeSinceAssigned,
+	}
+	if !s.showConflictAssignee {
+		return conflict
+	}
+
+	conflict.AssignedTo = holding.AssignedTo
+	conflict.AssigneeName = holding.AssignedTo
+	userID, err := snowflake.ParseString(holding.AssignedTo)
+	if err != nil {
+		return conflict
+	}
+	name, err := s.repo.FetchMemberDisplayName(ctx, orgID, userID)
+	if err != nil {
+		s.log.Warn("failed to load conflicting assignee name",
+			zap.String("org_id", orgID.String()),
+			zap.String("assigned_to", holding.AssignedTo),
+			zap.Error(err),
+		)
+		return conflict
+	}
+	if name != "" {
+		conflict.AssigneeName = name
+	}
+	return conflict
+}
+
 func (s *Service) ReleaseAssignment(ctx context.Context, req domain.ReleaseAssignmentRequest) error {
 	orgID, ok := orgcontext.OrgIDFromContext(ctx)
 	if !ok || orgID == 0 {
@@ -1079,8 +1408,14 @@ func (s *Service) ReleaseAssignment(ctx context.Context, req domain.ReleaseAssig
 		return domain.ErrInvalidAssignee
 	}
 
+	reason := strings.TrimSpace(req.Reason)
+	if err := s.checkHandoffNote(ctx, orgID, reason); err != nil {
+		return err
+	}
+
 	now := s.clock.Now().UTC()
 
+	released := false
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		repoTx := s.repo.WithTx(tx)
 
@@ -1088,19 +1423,28 @@ func (s *Service) ReleaseAssignment(ctx context.Context, req domain.ReleaseAssig
 		if err != nil {
 			return err
 		}
-		if existing == nil || existing.Status == domain.AssignmentStatusReleased {
-			return nil // Already not assigned or released
+		if existing == nil {
+			return nil // Not assigned, or already released
+		}
+		released = true
+		readAt := existing.UpdatedAt
+
+		snapshot, err := repoTx.LoadEntitySnapshot(ctx, orgID, entityType, entityID)
+		if err != nil {
+			s.log.Warn("failed to load entity snapshot", zap.Error(err))
+			snapshot = make(map[string]interface{})
 		}
 
 		existing.Status = domain.AssignmentStatusReleased
 		existing.ReleasedAt = sql.NullTime{Time: now, Valid: true}
 		existing.ReleasedBy = sql.NullString{String: releasedBy, Valid: true}
-		existing.ReleaseReason = sql.NullString{String: req.Reason, Valid: true}
+		existing.ReleaseReason = sql.NullString{String: reason, Valid: true}
 		existing.ResolvedAt = sql.NullTime{Time: now, Valid: true}
 		existing.ResolvedBy = sql.NullString{String: releasedBy, Valid: true}
+		existing.ResolutionSnapshot = s.resolutionSnapshot(snapshot)
 		existing.UpdatedAt = now
 
-		if err := repoTx.UpsertAssignment(ctx, *existing); err != nil {
+		if err := repoTx.UpsertAssignment(ctx, *existing, &readAt); err != nil {
 			return err
 		}
 
@@ -1108,12 +1452,6 @@ func (s *Service) ReleaseAssignment(ctx context.Context, req domain.ReleaseAssig
 		actionID := s.genID.Generate()
 		bucket := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 
-		snapshot, err := s.repo.LoadEntitySnapshot(ctx, orgID, entityType, entityID)
-		if err != nil {
-			s.log.Warn("failed to load entity snapshot", zap.Error(err))
-			snapshot = make(map[string]interface{})
-		}
-
 		if _, err := repoTx.InsertBillingAction(ctx, domain.BillingActionRecord{
 			ID:           actionID,
 			OrgID:        orgID,
@@ -1124,7 +1462,8 @@ func (s *Service) ReleaseAssignment(ctx context.Context, req domain.ReleaseAssig
 			Metadata: datatypes.JSONMap{
 				"assignment_id": existing.ID.String(),
 				"released_by":   releasedBy,
-				"reason":        req.Reason,
+				"reason":        reason,
+				"handoff_note":  reason,
 				"snapshot":      snapshot,
 			},
 			ActorType: "user",
@@ -1136,26 +1475,22 @@ func (s *Service) ReleaseAssignment(ctx context.Context, req domain.ReleaseAssig
 
 		return nil
 	})
-	if err != nil {
+	if err != nil || !released {
 		return err
 	}
 
-	if s.auditSvc != nil {
-		targetID := entityID.String()
-		_ = s.auditSvc.AuditLog(ctx, &orgID, "", nil,
-			"billing_operations.assignment.released",
-			"billing_operation_assignment",
-			&targetID,
-			map[string]any{
-				"entity_type": entityType,
-				"entity_id":   entityID.String(),
-				"released_by": releasedBy,
-				"reason":      req.Reason,
-			},
-		)
-	}
-
-	return nil
+	return s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryOperational,
+		action:     "billing_operations.assignment.released",
+		targetType: "billing_operation_assignment",
+		targetID:   entityID.String(),
+		metadata: map[string]any{
+			"entity_type": entityType,
+			"entity_id":   entityID.String(),
+			"released_by": releasedBy,
+			"reason":      reason,
+		},
+	})
 }
 
 func (s *Service) ResolveAssignment(ctx context.Context, req domain.ResolveAssignmentRequest) error {
@@ -1185,6 +1520,7 @@ func (s *Service) ResolveAssignment(ctx context.Context, req domain.ResolveAssig
 
 	now := s.clock.Now().UTC()
 
+	resolved := false
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		repoTx := s.repo.WithTx(tx)
 
@@ -1193,20 +1529,44 @@ func (s *Service) ResolveAssignment(ctx context.Context, req domain.ResolveAssig
 			return err
 		}
 		if existing == nil {
-			return nil // No assignment to resolve
+			return nil // No assignment to resolve, or it was released
 		}
 		if existing.Status == domain.AssignmentStatusResolved {
 			return nil // Already resolved
 		}
+		// The entity as it stands now is kept as the state it was resolved
+		// on. Closing as paid needs it; other resolutions get by without.
+		snapshot, err := repoTx.LoadEntitySnapshot(ctx, orgID, entityType, entityID)
+		if req.Resolution == domain.SuggestedActionCloseAsPaid {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrEntityNotFound
+			}
+			if err != nil {
+				return err
+			}
+			amountDue, _ := snapshot["amount_due"].(int64)
+			if entityType == domain.EntityTypeCustomer {
+				amountDue, _ = snapshot["outstanding_balance"].(int64)
+			}
+			if amountDue > 0 {
+				return domain.ErrEntityNotPaid
+			}
+		} else if err != nil {
+			s.log.Warn("failed to load entity snapshot", zap.Error(err))
+			snapshot = make(map[string]interface{})
+		}
+		resolved = true
+		readAt := existing.UpdatedAt
 
 		// Update to resolved status
 		existing.Status = domain.AssignmentStatusResolved
 		existing.ResolvedAt = sql.NullTime{Time: now, Valid: true}
 		existing.ResolvedBy = sql.NullString{String: resolvedBy, Valid: true}
 		existing.ReleaseReason = sql.NullString{String: req.Resolution, Valid: true}
+		existing.ResolutionSnapshot = s.resolutionSnapshot(snapshot)
 		existing.UpdatedAt = now
 
-		if err := repoTx.UpsertAssignment(ctx, *existing); err != nil {
+		if err := repoTx.UpsertAssignment(ctx, *existing, &readAt); err != nil {
 			return err
 		}
 
@@ -1236,29 +1596,35 @@ func (s *Service) ResolveAssignment(ctx context.Context, req domain.ResolveAssig
 		return nil
 	})
 
-	if err != nil {
+	if err != nil || !resolved {
 		return err
 	}
 
-	// Audit log
-	if s.auditSvc != nil {
-		targetID := entityID.String()
-		_ = s.auditSvc.AuditLog(ctx, &orgID, "", nil,
-			"billing_operations.assignment.resolved",
-			"billing_operation_assignment",
-			&targetID,
-			map[string]any{
-				"entity_type": entityType,
-				"entity_id":   entityID.String(),
-				"resolution":  req.Resolution,
-				"resolved_by": resolvedBy,
-			},
-		)
-	}
-
-	return nil
+	return s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryOperational,
+		action:     "billing_operations.assignment.resolved",
+		targetType: "billing_operation_assignment",
+		targetID:   entityID.String(),
+		metadata: map[string]any{
+			"entity_type": entityType,
+			"entity_id":   entityID.String(),
+			"resolution":  req.Resolution,
+			"resolved_by": resolvedBy,
+		},
+	})
 }
 
+// resolutionSnapshot encodes the entity state an assignment is resolved or
+// released on. A state that cannot be encoded is stored empty rather than
+// failing the resolution.
+func (s *Service) resolutionSnapshot(snapshot map[string]any) datatypes.JSON {
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		s.log.Warn("failed to marshal resolution snapshot", zap.Error(err))
+		encoded = []byte("{}")
+	}
+	return datatypes.JSON(encoded)
+}
 
 func timePtr(t sql.NullTime) *time.Time {
 	if t.Valid {
@@ -1276,6 +1642,14 @@ func normalizeIdempotencyKey(key string) string {
 	return strings.TrimSpace(key)
 }
 
+// actionBucket returns the calendar day of now in loc, so "once per day"
+// follows the org's day rather than UTC. The day is kept at UTC midnight
+// because action_bucket is a DATE and must match on insert and lookup.
+func actionBucket(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
 func buildAuditAction(actionType string) string {
 	return "billing_operations.action." + strings.ToLower(actionType)
 }
@@ -1293,6 +1667,17 @@ func computeAgingBucket(days int) string {
 	}
 }
 
+// riskAmount returns the part of amount that counts towards a risk level.
+// The heuristics are tuned in minor units of the org's currency, which are
+// not comparable with another currency's, so balances in other currencies
+// are graded on their age alone.
+func riskAmount(amount int64, currency, orgCurrency string) int64 {
+	if currency != orgCurrency {
+		return 0
+	}
+	return amount
+}
+
 func computeRiskLevel(amount int64, days int) string {
 	// Simple heuristic
 	score := int(amount/10000) + days
@@ -1305,10 +1690,38 @@ func computeRiskLevel(amount int64, days int) string {
 	return "low"
 }
 
+// slaMinutes converts a configured SLA to a duration, falling back to def
+// minutes when unset.
+func slaMinutes(configured, def int) time.Duration {
+	if configured <= 0 {
+		configured = def
+	}
+	return time.Duration(configured) * time.Minute
+}
+
+// slaGracePeriod returns the configured SLA grace period, falling back to
+// def seconds when unset.
+func slaGracePeriod(configured, def int) time.Duration {
+	if configured <= 0 {
+		configured = def
+	}
+	return time.Duration(configured) * time.Second
+}
+
+// EvaluateSLAs escalates active assignments that breached an SLA. AssignedAt
+// and LastActionAt were stamped by the clock of whichever API node handled
+// the claim or action, while now comes from the clock of the node running
+// this sweep, so the configured grace period is subtracted from every
+// elapsed time before it is compared with an SLA. Assignments whose SLAs are
+// paused are skipped; once the pause ends their clocks run from its end.
 func (s *Service) EvaluateSLAs(ctx context.Context) error {
-	const (
-		initialResponseSLA = 30 * time.Minute
-		idleActionSLA      = 60 * time.Minute
+	slaCfg := s.billingCfg.Get().SLA
+	defaults := config.DefaultBillingConfig().SLA
+	var (
+		initialResponseSLA = slaMinutes(slaCfg.InitialResponseMinutes, defaults.InitialResponseMinutes)
+		firstContactSLA    = slaMinutes(slaCfg.FirstContactMinutes, defaults.FirstContactMinutes)
+		idleActionSLA      = slaMinutes(slaCfg.IdleActionMinutes, defaults.IdleActionMinutes)
+		grace              = slaGracePeriod(slaCfg.GracePeriodSeconds, defaults.GracePeriodSeconds)
 	)
 	now := s.clock.Now().UTC()
 
@@ -1320,22 +1733,53 @@ func (s *Service) EvaluateSLAs(ctx context.Context) error {
 	}
 
 	for _, rec := range records {
+		assignedClock, idleClock := rec.AssignedAt, rec.LastActionAt
+		if rec.SLAPausedUntil.Valid {
+			pausedUntil := rec.SLAPausedUntil.Time
+			if now.Before(pausedUntil) {
+				continue
+			}
+			if pausedUntil.After(assignedClock) {
+				assignedClock = pausedUntil
+			}
+			if idleClock.Valid && pausedUntil.After(idleClock.Time) {
+				idleClock.Time = pausedUntil
+			}
+		}
+
 		isBreached := false
 		breachType := ""
+		sinceAssigned := now.Sub(assignedClock) - grace
 
 		// Check Initial Response SLA (assigned -> first action)
 		if rec.Status == domain.AssignmentStatusAssigned {
-			if now.Sub(rec.AssignedAt) > initialResponseSLA {
+			if sinceAssigned > initialResponseSLA {
+				isBreached = true
+				breachType = domain.SLABreachInitialResponse
+			}
+		}
+
+		// Check First Contact SLA (assigned -> first customer contact).
+		// Internal-only activity does not stop this clock.
+		if !isBreached && sinceAssigned > firstContactSLA {
+			contacted, err := s.repo.HasActionSince(ctx, rec.OrgID, rec.EntityType, rec.EntityID, domain.CustomerContactActionTypes, rec.AssignedAt)
+			if err != nil {
+				s.log.Error("failed to check customer contact",
+					zap.String("assignment_id", rec.ID.String()),
+					zap.Error(err))
+				continue
+			}
+			if !contacted {
 				isBreached = true
-				breachType = "initial_response"
+				breachType = domain.SLABreachFirstContact
 			}
 		}
 
 		// Check Idle Action SLA (last_action -> now)
-		if !isBreached && rec.LastActionAt.Valid {
-			if now.Sub(rec.LastActionAt.Time) > idleActionSLA {
+		if !isBreached && idleClock.Valid {
+			if now.Sub(idleClock.Time)-grace > idleActionSLA {
 				isBreached = true
-				breachType = "idle_action"
+				breachType = domain.SLABreachIdleAction
 			}
 		}
 
@@ -1358,10 +1802,10 @@ func (s *Service) EvaluateSLAs(ctx context.Context) error {
 					"breach_type":   breachType,
 					"minutes_idle":  0,
 				}
-				if rec.LastActionAt.Valid {
-					metadata["minutes_idle"] = int(now.Sub(rec.LastActionAt.Time).Minutes())
+				if idleClock.Valid && breachType != domain.SLABreachFirstContact {
+					metadata["minutes_idle"] = int(now.Sub(idleClock.Time).Minutes())
 				} else {
-					metadata["minutes_since_assigned"] = int(now.Sub(rec.AssignedAt).Minutes())
+					metadata["minutes_since_assigned"] = int(now.Sub(assignedClock).Minutes())
 				}
 
 				_, err := repoTx.InsertBillingAction(ctx, domain.BillingActionRecord{
@@ -1369,11 +1813,11 @@ func (s *Service) EvaluateSLAs(ctx context.Context) error {
 					OrgID:        rec.OrgID,
 					EntityType:   rec.EntityType,
 					EntityID:     rec.EntityID,
-					ActionType:   "sla_breached",
+					ActionType:   domain.ActionTypeSLABreached,
 					ActionBucket: bucket,
 					Metadata:     metadata,
-					ActorType:    "system",
-					ActorID:      "sla_monitor",
+					ActorType:    domain.SystemActorID,
+					ActorID:      domain.SLAMonitorActorID,
 					CreatedAt:    now,
 				})
 				return err
@@ -1386,23 +1830,48 @@ func (s *Service) EvaluateSLAs(ctx context.Context) error {
 				continue
 			}
 
-			// Audit Log
-			if s.auditSvc != nil {
-				targetID := rec.EntityID.String()
-				_ = s.auditSvc.AuditLog(ctx, &rec.OrgID, "system", nil,
-					"billing_operations.assignment.escalated",
-					"billing_operation_assignment",
-					&targetID,
-					map[string]any{
-						"breach_type":   breachType,
-						"assignment_id": rec.ID.String(),
-					})
+			// The escalation is already committed; a required audit failure
+			// is logged by emitAudit and must not stop the sweep.
+			_ = s.emitAudit(ctx, rec.OrgID, auditEntry{
+				category:   auditCategoryOperational,
+				actorType:  "system",
+				action:     "billing_operations.assignment.escalated",
+				targetType: "billing_operation_assignment",
+				targetID:   rec.EntityID.String(),
+				metadata: map[string]any{
+					"breach_type":   breachType,
+					"assignment_id": rec.ID.String(),
+				},
+			})
+
+			idleSince := assignedClock
+			if idleClock.Valid {
+				idleSince = idleClock.Time
 			}
+			s.notifyBreach(ctx, domain.BreachEvent{
+				OrgID:        rec.OrgID.String(),
+				AssignmentID: rec.ID.String(),
+				EntityType:   rec.EntityType,
+				EntityID:     rec.EntityID.String(),
+				AssignedTo:   rec.AssignedTo,
+				BreachType:   breachType,
+				MinutesIdle:  int(now.Sub(idleSince).Minutes()),
+				BreachedAt:   now,
+			})
 		}
 	}
 	return nil
 }
 
+// activeAssignment returns a, or nil when it does not hold the entity:
+// nobody was assigned or the assignment was released.
+func activeAssignment(a domain.Assignment) *domain.Assignment {
+	if !domain.HasActiveAssignment(a.AssignedTo, a.Status) {
+		return nil
+	}
+	return &a
+}
+
 func assignmentFields(
 	assignedTo sql.NullString,
 	assignedAt time.Time,
@@ -1498,39 +1967,42 @@ func assignmentFields(
 }
 
 
-func decryptToken(key []byte, ciphertextB64 string) string {
+// decryptToken decrypts a stored public token. It returns "" without an
+// error when there is no key or no token, and one of the errToken* errors
+// when the stored value cannot be decrypted.
+func decryptToken(key []byte, ciphertextB64 string) (string, error) {
 	ciphertextB64 = strings.TrimSpace(ciphertextB64)
 	if len(key) == 0 || ciphertextB64 == "" {
-		return ""
+		return "", nil
 	}
 
 	ciphertext, err := base64.RawStdEncoding.DecodeString(ciphertextB64)
 	if err != nil {
-		return ""
+		return "", errTokenEncoding
 	}
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return ""
+		return "", errTokenKey
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return ""
+		return "", errTokenKey
 	}
 
 	nonceSize := gcm.NonceSize()
 	if len(ciphertext) < nonceSize {
-		return ""
+		return "", errTokenTruncated
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
 	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
-		return ""
+		return "", errTokenAuth
 	}
 
-	return string(plaintext)
+	return string(plaintext), nil
 }
 
 
@@ -1633,10 +2105,27 @@ func (s *Service) CalculatePerformance(ctx context.Context, userID string, start
 	metrics.CompletionRatio = float64(metrics.TotalResolved) / float64(metrics.TotalAssigned)
 	metrics.EscalationRate = float64(metrics.TotalEscalated) / float64(metrics.TotalAssigned)
 
+	scores := scorePerformance(metrics)
+
+	return domain.FinOpsScoreSnapshot{
+		OrgID:          orgID.String(),
+		UserID:         userID,
+		PeriodType:     domain.PeriodTypeDaily,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		ScoringVersion: domain.ScoringVersionV1EqualWeight,
+		Metrics:        metrics,
+		Scores:         scores,
+	}, nil
+}
+
+// scorePerformance turns raw metrics into dimension scores using the
+// equal-weight v1 model.
+func scorePerformance(metrics domain.PerformanceMetrics) domain.PerformanceScores {
 	// Scoring Model
 	// Calculate Score (Simple Equal Weight V1)
 	// scoring_version = "v1_equal_weight"
-	scores := domain.PerformanceScores{}
+	var scores domain.PerformanceScores
 
 	// Normalize metrics 0-100
 	// 1. Responsiveness: < 1h = 100, > 24h = 0
@@ -1680,40 +2169,49 @@ func (s *Service) CalculatePerformance(ctx context.Context, userID string, start
 
 	// Total: Average
 	scores.Total = (scores.Responsiveness + scores.Completion + scores.Risk + scores.Effectiveness) / 4
-
-	return domain.FinOpsScoreSnapshot{
-		OrgID:          orgID.String(),
-		UserID:         userID,
-		PeriodType:     domain.PeriodTypeDaily,
-		PeriodStart:    start,
-		PeriodEnd:      end,
-		ScoringVersion: domain.ScoringVersionV1EqualWeight,
-		Metrics:        metrics,
-		Scores:         scores,
-	}, nil
+	return scores
 }
 
-func (s *Service) GetPerformanceHistory(ctx context.Context, userID string, limit int) ([]domain.FinOpsScoreSnapshot, error) {
+// GetPerformanceHistory returns userID's snapshots within the requested
+// window, most recent first, capped at the limit (30 by default).
+func (s *Service) GetPerformanceHistory(ctx context.Context, userID string, req domain.PerformanceHistoryRequest) ([]domain.FinOpsScoreSnapshot, error) {
 	orgID, ok := orgcontext.OrgIDFromContext(ctx)
 	if !ok || orgID == 0 {
 		return nil, domain.ErrInvalidOrganization
 	}
+	limit := req.Limit
 	if limit <= 0 {
 		limit = 30
 	}
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return []domain.FinOpsScoreSnapshot{}, nil
+	}
 
 	type outputRow struct {
-		OrgID       snowflake.ID
-		UserID      string
-		PeriodStart time.Time
-		PeriodEnd   time.Time
-		Metrics     datatypes.JSON
-		Scores      datatypes.JSON
+		OrgID          snowflake.ID
+		UserID         string
+		PeriodType     string
+		PeriodStart    time.Time
+		PeriodEnd      time.Time
+		ScoringVersion string
+		Metrics        datatypes.JSON
+		Scores         datatypes.JSON
+	}
+
+	query := s.db.WithContext(ctx).Table("finops_performance_snapshots").
+		Where("org_id = ? AND user_id = ?", orgID, userID)
+	if periodType := strings.TrimSpace(req.PeriodType); periodType != "" {
+		query = query.Where("period_type = ?", periodType)
+	}
+	if !req.From.IsZero() {
+		query = query.Where("period_start >= ?", req.From.UTC())
+	}
+	if !req.To.IsZero() {
+		query = query.Where("period_start < ?", req.To.UTC())
 	}
 
 	var rows []outputRow
-	if err := s.db.WithContext(ctx).Table("finops_performance_snapshots").
-		Where("org_id = ? AND user_id = ?", orgID, userID).
+	if err := query.
 		Order("period_start DESC").
 		Limit(limit).
 		Find(&rows).Error; err != nil {
@@ -1728,85 +2226,130 @@ func (s *Service) GetPerformanceHistory(ctx context.Context, userID string, limi
 		_ = json.Unmarshal(r.Scores, &sc)
 
 		snapshots[i] = domain.FinOpsScoreSnapshot{
-			OrgID:       r.OrgID.String(),
-			UserID:      r.UserID,
-			PeriodStart: r.PeriodStart,
-			PeriodEnd:   r.PeriodEnd,
-			Metrics:     m,
-			Scores:      sc,
+			OrgID:          r.OrgID.String(),
+			UserID:         r.UserID,
+			PeriodType:     r.PeriodType,
+			PeriodStart:    r.PeriodStart,
+			PeriodEnd:      r.PeriodEnd,
+			ScoringVersion: r.ScoringVersion,
+			Metrics:        m,
+			Scores:         sc,
 		}
 	}
 	return snapshots, nil
 }
 
+// AggregateDailyPerformance scores the day selected by the configured day
+// offset (yesterday by default).
 func (s *Service) AggregateDailyPerformance(ctx context.Context) error {
-	// 1. Identify period: Yesterday 00:00 to 23:59
 	now := s.clock.Now().UTC()
-	start := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return s.AggregatePerformanceForDay(ctx, today.AddDate(0, 0, -s.scoringDayOffsetOrDefault()))
+}
+
+// AggregatePerformanceForDay recomputes snapshots for the UTC day containing
+// day. Days that have not fully elapsed are refused with ErrIncompletePeriod:
+// snapshots are immutable per period, so scoring partial data would
+// under-report operators.
+func (s *Service) AggregatePerformanceForDay(ctx context.Context, day time.Time) error {
+	// 1. Identify period: 00:00 to 24:00 UTC of the requested day
+	now := s.clock.Now().UTC()
+	day = day.UTC()
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
 	end := start.Add(24 * time.Hour)
+	if end.After(now) {
+		s.log.Warn("refusing to score incomplete period",
+			zap.Time("period_start", start),
+			zap.Time("period_end", end),
+			zap.Time("now", now),
+		)
+		return domain.ErrIncompletePeriod
+	}
 
 	// 2. Find active users in that period
 	// 2. Find active users in that period (Grouped by Org)
-	type UserOrg struct {
-		OrgID      snowflake.ID
-		AssignedTo string
-	}
-	var userOrgs []UserOrg
-	if err := s.db.WithContext(ctx).Table("billing_operation_assignments").
+	var userOrgs []scoringTarget
+	if err := dbpkg.SkipTenantScope(s.db.WithContext(ctx)).Table("billing_operation_assignments").
 		Select("DISTINCT org_id, assigned_to").
 		Where("assigned_at >= ? AND assigned_at < ?", start, end).
 		Scan(&userOrgs).Error; err != nil {
 		return err
 	}
 
+	filter := s.newTeamViewsFilter()
+	active := make([]scoringTarget, 0, len(userOrgs))
 	for _, uo := range userOrgs {
 		if uo.AssignedTo == "" {
 			continue
 		}
-
-		// Create context with OrgID
-		orgCtx := orgcontext.WithOrgID(ctx, uo.OrgID.Int64())
-
-		snapshot, err := s.CalculatePerformance(orgCtx, uo.AssignedTo, start, end)
+		hidden, err := filter.hidden(ctx, uo.OrgID, uo.AssignedTo)
 		if err != nil {
-			s.log.Error("failed to calc performance", zap.Error(err), zap.String("user", uo.AssignedTo))
-			continue
+			return err
+		}
+		if !hidden {
+			active = append(active, uo)
 		}
+	}
+
+	// Users are scored in parallel, bounded by the configured worker count.
+	// Each worker runs in its own DB session and persists its snapshot in
+	// its own transaction, so one failure does not affect the others. A
+	// failed batch is returned before its checkpoint is saved, so the next
+	// run retries it.
+	score := func(batch []scoringTarget) error {
+		return runBounded(ctx, s.performanceWorkerCount(), len(batch), func(i int) error {
+			return s.scorePerformanceSnapshot(ctx, batch[i], start, end, now)
+		})
+	}
+	if s.scoringBatchSize <= 0 {
+		return score(active)
+	}
+	return s.scoreInCheckpointedBatches(ctx, start, active, score)
+}
 
-		// Upsert into snapshots table
-		// ID, OrgID, UserID, Start, End, Metrics, Scores, TotalScore, CreatedAt
+// scorePerformanceSnapshot computes one user's performance for the period
+// and upserts their snapshot.
+func (s *Service) scorePerformanceSnapshot(ctx context.Context, uo scoringTarget, start, end, now time.Time) error {
+	db := s.db.Session(&gorm.Session{NewDB: true, Context: ctx})
 
-		// Map struct to DB model (jsonb fields)
-		// Immutable Snapshot: Delete existing for period, then Insert
-		// "Recompute = delete + insert"
-		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			// 1. Delete existing snapshot for this user/org/period
-			if err := tx.Exec(`
-					DELETE FROM finops_performance_snapshots 
-					WHERE org_id = ? AND user_id = ? AND period_type = ? AND period_start = ?
-				`, uo.OrgID, uo.AssignedTo, snapshot.PeriodType, start).Error; err != nil {
-				return err
-			}
+	// Create context with OrgID
+	orgCtx := orgcontext.WithOrgID(ctx, uo.OrgID.Int64())
 
-			// 2. Insert new snapshot
-			return tx.Exec(`
-					INSERT INTO finops_performance_snapshots 
-					(id, org_id, user_id, period_type, period_start, period_end, scoring_version, metrics, scores, total_score, created_at, updated_at)
-					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-				`, s.genID.Generate(), uo.OrgID, uo.AssignedTo, snapshot.PeriodType, start, end, snapshot.ScoringVersion,
-				datatypes.JSON(toJson(snapshot.Metrics)),
-				datatypes.JSON(toJson(snapshot.Scores)),
-				snapshot.Scores.Total,
-				now, now).Error
-		})
+	snapshot, err := s.CalculatePerformance(orgCtx, uo.AssignedTo, start, end)
+	if err != nil {
+		s.log.Error("failed to calc performance", zap.Error(err), zap.String("user", uo.AssignedTo))
+		return err
+	}
 
-		if err != nil {
-			s.log.Error("failed to persist snapshot", zap.Error(err))
-		}
+	err = s.upsertPerformanceSnapshot(db, uo.OrgID, snapshot, now)
+	if err != nil {
+		s.log.Error("failed to persist snapshot", zap.Error(err), zap.String("user", uo.AssignedTo))
+		return err
 	}
 	return nil
 }
 
+// upsertPerformanceSnapshot stores snapshot for orgID. There is one
+// snapshot per user and period: recomputing updates it in place.
+func (s *Service) upsertPerformanceSnapshot(db *gorm.DB, orgID snowflake.ID, snapshot domain.FinOpsScoreSnapshot, now time.Time) error {
+	return db.Exec(`
+			INSERT INTO finops_performance_snapshots
+			(id, org_id, user_id, period_type, period_start, period_end, scoring_version, metrics, scores, total_score, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (org_id, user_id, period_type, period_start) DO UPDATE SET
+				period_end = EXCLUDED.period_end,
+				scoring_version = EXCLUDED.scoring_version,
+				metrics = EXCLUDED.metrics,
+				scores = EXCLUDED.scores,
+				total_score = EXCLUDED.total_score,
+				updated_at = EXCLUDED.updated_at
+		`, s.genID.Generate(), orgID, snapshot.UserID, snapshot.PeriodType, snapshot.PeriodStart, snapshot.PeriodEnd, snapshot.ScoringVersion,
+		datatypes.JSON(toJson(snapshot.Metrics)),
+		datatypes.JSON(toJson(snapshot.Scores)),
+		snapshot.Scores.Total,
+		now, now).Error
+}
+
 func toJson(v any) []byte {
 	b, _ := json.Marshal(v)
 	return b
@@ -1881,6 +2424,15 @@ func (s *Service) GetTeamPerformance(ctx context.Context, req domain.GetPerforma
 
 	// Note: Role check should be done by handler/middleware. Service assumes authorization.
 
+	excludeUserIDs, err := normalizeExcludeUserIDs(req.ExcludeUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	less, err := teamPerformanceLess(req.OrderBy, req.OrderDirection)
+	if err != nil {
+		return nil, err
+	}
+
 	start := req.From
 	end := req.To
 	now := s.clock.Now().UTC()
@@ -1892,70 +2444,33 @@ func (s *Service) GetTeamPerformance(ctx context.Context, req domain.GetPerforma
 		start = end.AddDate(0, 0, -30)
 	}
 
-	snapshots, err := s.repo.FindSnapshotsByOrg(ctx, snowflake.ID(orgID), req.PeriodType, start, end)
+	snapshots, err := s.repo.FindSnapshotsByOrg(ctx, snowflake.ID(orgID), req.PeriodType, start, end, excludeUserIDs)
 	if err != nil {
 		return nil, err
 	}
 
 	// Group by UserID
+	filter := s.newTeamViewsFilter()
 	grouped := make(map[string][]domain.FinOpsScoreSnapshot)
 	for _, snap := range snapshots {
+		hidden, err := filter.hidden(ctx, snowflake.ID(orgID), snap.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if hidden {
+			continue
+		}
 		grouped[snap.UserID] = append(grouped[snap.UserID], snap)
 	}
 
 	// Aggregate per user
 	teamSummaries := make([]domain.TeamMemberSummary, 0, len(grouped))
 	for uid, snaps := range grouped {
-		var totalScore int
-		var totalAssigned, totalResolved, totalEscalated int
-		var totalExposure int64
-		var weightedResponseMS float64
-		var count int
-
-		for _, s := range snaps {
-			totalScore += s.Scores.Total
-			count++
-			totalAssigned += s.Metrics.TotalAssigned
-			totalResolved += s.Metrics.TotalResolved
-			totalEscalated += s.Metrics.TotalEscalated
-			totalExposure += s.Metrics.ExposureHandled
-			// Weighted average for response time based on volume
-			weightedResponseMS += float64(s.Metrics.AvgResponseMS) * float64(s.Metrics.TotalResolved)
-		}
-
-		avgScore := 0
-		if count > 0 {
-			avgScore = totalScore / count
-		}
-
-		var avgResponseMS int64
-		if totalResolved > 0 {
-			avgResponseMS = int64(weightedResponseMS / float64(totalResolved))
-		}
-
-		var completionRatio, escalationRate float64
-		if totalAssigned > 0 {
-			completionRatio = float64(totalResolved) / float64(totalAssigned)
-			escalationRate = float64(totalEscalated) / float64(totalAssigned)
-		}
-
-		teamSummaries = append(teamSummaries, domain.TeamMemberSummary{
-			UserID:   uid,
-			AvgScore: avgScore,
-			MetricsSummary: domain.APIMetrics{
-				// Aggregated Logic
-				// We can re-calculate avg response minutes from weighted avg MS
-				AvgResponseMinutes: float64(avgResponseMS) / 60000.0,
-				CompletionRatio:    completionRatio,
-				EscalationRatio:    escalationRate,
-				ExposureHandled:    totalExposure,
-			},
-		})
+		teamSummaries = append(teamSummaries, summarizeMemberPerformance(uid, snaps))
 	}
 
-	// Sort by UserID for determinism
 	sort.Slice(teamSummaries, func(i, j int) bool {
-		return teamSummaries[i].UserID < teamSummaries[j].UserID
+		return less(teamSummaries[i], teamSummaries[j])
 	})
 
 	return &domain.TeamPerformanceResponse{
@@ -1964,3 +2479,54 @@ func (s *Service) GetTeamPerformance(ctx context.Context, req domain.GetPerforma
 		Snapshots:  teamSummaries,
 	}, nil
 }
+
+// summarizeMemberPerformance averages one user's snapshots into a team
+// summary: the mean total score, with response time weighted by resolved
+// volume and the ratios recomputed from the summed counts.
+func summarizeMemberPerformance(uid string, snaps []domain.FinOpsScoreSnapshot) domain.TeamMemberSummary {
+	var totalScore int
+	var totalAssigned, totalResolved, totalEscalated int
+	var totalExposure int64
+	var weightedResponseMS float64
+	var count int
+
+	for _, s := range snaps {
+		totalScore += s.Scores.Total
+		count++
+		totalAssigned += s.Metrics.TotalAssigned
+		totalResolved += s.Metrics.TotalResolved
+		totalEscalated += s.Metrics.TotalEscalated
+		totalExposure += s.Metrics.ExposureHandled
+		// Weighted average for response time based on volume
+		weightedResponseMS += float64(s.Metrics.AvgResponseMS) * float64(s.Metrics.TotalResolved)
+	}
+
+	avgScore := 0
+	if count > 0 {
+		avgScore = totalScore / count
+	}
+
+	var avgResponseMS int64
+	if totalResolved > 0 {
+		avgResponseMS = int64(weightedResponseMS / float64(totalResolved))
+	}
+
+	var completionRatio, escalationRate float64
+	if totalAssigned > 0 {
+		completionRatio = float64(totalResolved) / float64(totalAssigned)
+		escalationRate = float64(totalEscalated) / float64(totalAssigned)
+	}
+
+	return domain.TeamMemberSummary{
+		UserID:   uid,
+		AvgScore: avgScore,
+		MetricsSummary: domain.APIMetrics{
+			// Aggregated Logic
+			// We can re-calculate avg response minutes from weighted avg MS
+			AvgResponseMinutes: float64(avgResponseMS) / 60000.0,
+			CompletionRatio:    completionRatio,
+			EscalationRatio:    escalationRate,
+			ExposureHandled:    totalExposure,
+		},
+	}
+}
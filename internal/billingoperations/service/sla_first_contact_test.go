@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/billingoperations/repository"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// newSLATestDB returns a sqlite database with the tables EvaluateSLAs reads
+// and writes.
+func newSLATestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec(`CREATE TABLE billing_operation_actions (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		action_type TEXT NOT NULL,
+		action_bucket TIMESTAMP NOT NULL,
+		idempotency_key TEXT,
+		metadata TEXT,
+		actor_type TEXT,
+		actor_id TEXT,
+		created_at TIMESTAMP NOT NULL
+	)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE billing_operation_assignments (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		assigned_to TEXT NOT NULL,
+		assigned_at TIMESTAMP NOT NULL,
+		assignment_expires_at TIMESTAMP NOT NULL,
+		status TEXT NOT NULL,
+		released_at TIMESTAMP,
+		released_by TEXT,
+		release_reason TEXT,
+		resolved_at TIMESTAMP,
+		resolved_by TEXT,
+		breached_at TIMESTAMP,
+		breach_level TEXT,
+		last_action_at TIMESTAMP,
+		snapshot_metadata TEXT,
+		handed_off_at TIMESTAMP,
+		handed_off_by TEXT,
+		handoff_note TEXT,
+		resolution_snapshot TEXT,
+		sla_paused_until TIMESTAMP,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`).Error)
+	return db
+}
+
+func TestEvaluateSLAs_FirstContact(t *testing.T) {
+	db := newSLATestDB(t)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	orgID := node.Generate()
+	assignedAt := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	now := assignedAt.Add(5 * time.Hour)
+	lastActionAt := now.Add(-10 * time.Minute)
+
+	insertAssignment := func(entityID snowflake.ID) {
+		require.NoError(t, db.Exec(
+			`INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, last_action_at, created_at, updated_at)
+			 VALUES (?, ?, 'invoice', ?, 'agent', ?, ?, ?, ?, ?, ?)`,
+			node.Generate(), orgID, entityID, assignedAt, assignedAt.Add(24*time.Hour), domain.AssignmentStatusInProgress, lastActionAt, assignedAt, assignedAt,
+		).Error)
+	}
+	insertAction := func(entityID snowflake.ID, actionType string, at time.Time) {
+		require.NoError(t, db.Exec(
+			`INSERT INTO billing_operation_actions (id, org_id, entity_type, entity_id, action_type, action_bucket, metadata, actor_type, actor_id, created_at)
+			 VALUES (?, ?, 'invoice', ?, ?, ?, '{}', 'user', 'agent', ?)`,
+			node.Generate(), orgID, entityID, actionType, at, at,
+		).Error)
+	}
+
+	// Only internal review activity since assignment.
+	internalOnly := node.Generate()
+	insertAssignment(internalOnly)
+	insertAction(internalOnly, domain.ActionTypeMarkReviewed, lastActionAt)
+
+	// Customer contacted after assignment.
+	contacted := node.Generate()
+	insertAssignment(contacted)
+	insertAction(contacted, domain.ActionTypeContact, assignedAt.Add(time.Hour))
+	insertAction(contacted, domain.ActionTypeMarkReviewed, lastActionAt)
+
+	// Followed up under a previous assignment only.
+	contactedBefore := node.Generate()
+	insertAssignment(contactedBefore)
+	insertAction(contactedBefore, domain.ActionTypeFollowUp, assignedAt.Add(-time.Hour))
+	insertAction(contactedBefore, domain.ActionTypeMarkReviewed, lastActionAt)
+
+	svc := &Service{
+		db:         db,
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(now),
+		genID:      node,
+		repo:       repository.NewRepository(db),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+
+	breachLevel := func(entityID snowflake.ID) string {
+		var row struct {
+			Status      string
+			BreachLevel *string
+		}
+		require.NoError(t, db.Raw(
+			`SELECT status, breach_level FROM billing_operation_assignments WHERE entity_id = ?`,
+			entityID,
+		).Scan(&row).Error)
+		if row.BreachLevel == nil {
+			return ""
+		}
+		assert.Equal(t, domain.AssignmentStatusEscalated, row.Status)
+		return *row.BreachLevel
+	}
+
+	assert.Equal(t, domain.SLABreachFirstContact, breachLevel(internalOnly))
+	assert.Empty(t, breachLevel(contacted))
+	assert.Equal(t, domain.SLABreachFirstContact, breachLevel(contactedBefore))
+
+	var breaches int64
+	require.NoError(t, db.Raw(
+		`SELECT COUNT(1) FROM billing_operation_actions WHERE action_type = ?`,
+		domain.ActionTypeSLABreached,
+	).Scan(&breaches).Error)
+	assert.Equal(t, int64(2), breaches)
+}
+
+func TestEvaluateSLAs_FirstContactWithinWindow(t *testing.T) {
+	db := newSLATestDB(t)
+
+	assignedAt := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	now := assignedAt.Add(2 * time.Hour)
+	require.NoError(t, db.Exec(
+		`INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, last_action_at, created_at, updated_at)
+		 VALUES (1, 1, 'invoice', 2, 'agent', ?, ?, ?, ?, ?, ?)`,
+		assignedAt, assignedAt.Add(24*time.Hour), domain.AssignmentStatusInProgress, now.Add(-10*time.Minute), assignedAt, assignedAt,
+	).Error)
+
+	cfg := config.DefaultBillingConfig()
+	cfg.SLA.FirstContactMinutes = 180
+	svc := &Service{
+		db:         db,
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(now),
+		repo:       repository.NewRepository(db),
+		billingCfg: config.NewStaticBillingConfigHolder(cfg),
+	}
+	// Inside the first contact window the actions table is never consulted.
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+
+	var status string
+	require.NoError(t, db.Raw(`SELECT status FROM billing_operation_assignments WHERE id = 1`).Scan(&status).Error)
+	assert.Equal(t, domain.AssignmentStatusInProgress, status)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/billingoperations/repository"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEvaluateSLAs_GracePeriod(t *testing.T) {
+	db := newSLATestDB(t)
+
+	assignedAt := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Exec(
+		`INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, created_at, updated_at)
+		 VALUES (1, 1, 'invoice', 2, 'agent', ?, ?, ?, ?, ?)`,
+		assignedAt, assignedAt.Add(24*time.Hour), domain.AssignmentStatusAssigned, assignedAt, assignedAt,
+	).Error)
+
+	cfg := config.DefaultBillingConfig()
+	cfg.SLA.InitialResponseMinutes = 30
+	cfg.SLA.GracePeriodSeconds = 45
+	grace := 45 * time.Second
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	fake := clock.NewFakeClock(assignedAt)
+	svc := &Service{
+		db:         db,
+		log:        zaptest.NewLogger(t),
+		clock:      fake,
+		genID:      node,
+		repo:       repository.NewRepository(db),
+		billingCfg: config.NewStaticBillingConfigHolder(cfg),
+	}
+	status := func() string {
+		var status string
+		require.NoError(t, db.Raw(`SELECT status FROM billing_operation_assignments WHERE id = 1`).Scan(&status).Error)
+		return status
+	}
+
+	// Just past the SLA, but inside the grace period a skewed node may have
+	// added to the elapsed time.
+	fake.Advance(30*time.Minute + time.Second)
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+	assert.Equal(t, domain.AssignmentStatusAssigned, status())
+
+	// Exactly on the boundary is not a breach yet.
+	fake.Advance(grace - time.Second)
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+	assert.Equal(t, domain.AssignmentStatusAssigned, status())
+
+	fake.Advance(time.Second)
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+	assert.Equal(t, domain.AssignmentStatusEscalated, status())
+}
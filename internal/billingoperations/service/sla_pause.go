@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maxSLAPauseDays bounds how far ahead an SLA pause may end.
+const maxSLAPauseDays = 90
+
+// PauseSLA holds SLA evaluation of the entity's active assignment off until
+// the given time, for example while the customer follows an agreed payment
+// plan. Once the pause ends the SLA clocks restart from its end rather than
+// from when the entity was assigned or last acted on. A later pause replaces
+// an earlier one. Pauses end at most maxSLAPauseDays from now.
+func (s *Service) PauseSLA(ctx context.Context, entityType, entityID string, until time.Time) error {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.ErrInvalidOrganization
+	}
+
+	entityType = strings.TrimSpace(entityType)
+	if entityType != domain.EntityTypeInvoice && entityType != domain.EntityTypeCustomer {
+		return domain.ErrInvalidEntityType
+	}
+
+	parsedID, err := parseSnowflakeID(entityID)
+	if err != nil {
+		return domain.ErrInvalidEntityID
+	}
+
+	_, actorID := auditcontext.ActorFromContext(ctx)
+	pausedBy := strings.TrimSpace(actorID)
+	if pausedBy == "" {
+		return domain.ErrInvalidAssignee
+	}
+
+	now := s.clock.Now().UTC()
+	until = until.UTC()
+	if !until.After(now) || until.Sub(now) > maxSLAPauseDays*24*time.Hour {
+		return domain.ErrInvalidSLAPauseUntil
+	}
+
+	var assignmentID string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+
+		existing, err := repoTx.LoadAssignmentForUpdate(ctx, orgID, entityType, parsedID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return domain.ErrAssignmentNotFound
+		}
+		assignmentID = existing.ID.String()
+
+		if err := repoTx.PauseAssignmentSLA(ctx, orgID, entityType, parsedID, until, now); err != nil {
+			return err
+		}
+
+		_, err = repoTx.InsertBillingAction(ctx, domain.BillingActionRecord{
+			ID:           s.genID.Generate(),
+			OrgID:        orgID,
+			EntityType:   entityType,
+			EntityID:     parsedID,
+			ActionType:   domain.ActionTypeSLAPaused,
+			ActionBucket: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+			Metadata: datatypes.JSONMap{
+				"assignment_id":    assignmentID,
+				"sla_paused_until": until.Format(time.RFC3339),
+				"paused_by":        pausedBy,
+			},
+			ActorType: "user",
+			ActorID:   pausedBy,
+			CreatedAt: now,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryOperational,
+		action:     "billing_operations.assignment.sla_paused",
+		targetType: "billing_operation_assignment",
+		targetID:   parsedID.String(),
+		metadata: map[string]any{
+			"entity_type":      entityType,
+			"entity_id":        parsedID.String(),
+			"assignment_id":    assignmentID,
+			"sla_paused_until": until.Format(time.RFC3339),
+			"paused_by":        pausedBy,
+		},
+	})
+}
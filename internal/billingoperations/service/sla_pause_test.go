@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/billingoperations/repository"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPauseSLA(t *testing.T) {
+	db := newSLATestDB(t)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	orgID := node.Generate()
+	entityID := node.Generate()
+	assignedAt := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Exec(
+		`INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, last_action_at, created_at, updated_at)
+		 VALUES (?, ?, 'invoice', ?, 'agent', ?, ?, ?, ?, ?, ?)`,
+		node.Generate(), orgID, entityID, assignedAt, assignedAt.Add(24*time.Hour), domain.AssignmentStatusInProgress, assignedAt, assignedAt, assignedAt,
+	).Error)
+
+	cfg := config.DefaultBillingConfig()
+	cfg.SLA.FirstContactMinutes = 24 * 60
+	cfg.SLA.IdleActionMinutes = 60
+	cfg.SLA.GracePeriodSeconds = 1
+
+	fake := clock.NewFakeClock(assignedAt.Add(10 * time.Minute))
+	svc := &Service{
+		db:         db,
+		log:        zaptest.NewLogger(t),
+		clock:      fake,
+		genID:      node,
+		repo:       repository.NewRepository(db),
+		billingCfg: config.NewStaticBillingConfigHolder(cfg),
+	}
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	ctx = auditcontext.WithActor(ctx, "user", "agent")
+	status := func() string {
+		var status string
+		require.NoError(t, db.Raw(`SELECT status FROM billing_operation_assignments WHERE entity_id = ?`, entityID).Scan(&status).Error)
+		return status
+	}
+
+	t.Run("rejects a pause in the past", func(t *testing.T) {
+		err := svc.PauseSLA(ctx, domain.EntityTypeInvoice, entityID.String(), assignedAt)
+		assert.ErrorIs(t, err, domain.ErrInvalidSLAPauseUntil)
+	})
+
+	t.Run("rejects a pause beyond the cap", func(t *testing.T) {
+		err := svc.PauseSLA(ctx, domain.EntityTypeInvoice, entityID.String(), fake.Now().AddDate(0, 0, maxSLAPauseDays+1))
+		assert.ErrorIs(t, err, domain.ErrInvalidSLAPauseUntil)
+	})
+
+	t.Run("rejects an entity without an assignment", func(t *testing.T) {
+		err := svc.PauseSLA(ctx, domain.EntityTypeInvoice, node.Generate().String(), assignedAt.Add(time.Hour))
+		assert.ErrorIs(t, err, domain.ErrAssignmentNotFound)
+	})
+
+	pausedUntil := assignedAt.Add(3 * time.Hour)
+	require.NoError(t, svc.PauseSLA(ctx, domain.EntityTypeInvoice, entityID.String(), pausedUntil))
+
+	var action struct {
+		ActorID  string
+		Metadata string
+	}
+	require.NoError(t, db.Raw(
+		`SELECT actor_id, metadata FROM billing_operation_actions WHERE entity_id = ? AND action_type = ?`,
+		entityID, domain.ActionTypeSLAPaused,
+	).Scan(&action).Error)
+	assert.Equal(t, "agent", action.ActorID)
+	assert.Contains(t, action.Metadata, pausedUntil.Format(time.RFC3339))
+
+	// Idle for two hours, but the payment plan pause is still running.
+	fake.Advance(110 * time.Minute) // 11:00
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+	assert.Equal(t, domain.AssignmentStatusInProgress, status())
+
+	// Past the pause: the idle clock restarted at its end, not at the last
+	// action before it.
+	fake.Advance(90 * time.Minute) // 12:30
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+	assert.Equal(t, domain.AssignmentStatusInProgress, status())
+
+	fake.Advance(31 * time.Minute) // 13:01
+	require.NoError(t, svc.EvaluateSLAs(context.Background()))
+	assert.Equal(t, domain.AssignmentStatusEscalated, status())
+
+	var breach string
+	require.NoError(t, db.Raw(`SELECT breach_level FROM billing_operation_assignments WHERE entity_id = ?`, entityID).Scan(&breach).Error)
+	assert.Equal(t, domain.SLABreachIdleAction, breach)
+}
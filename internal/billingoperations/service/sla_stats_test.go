@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/billingoperations/repository"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+func TestGetSLAStats(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_actions (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		action_type TEXT NOT NULL,
+		action_bucket TIMESTAMP NOT NULL,
+		idempotency_key TEXT,
+		metadata TEXT,
+		actor_type TEXT,
+		actor_id TEXT,
+		created_at TIMESTAMP NOT NULL
+	)`)
+	db.Exec(`CREATE TABLE IF NOT EXISTS billing_operation_assignments (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		entity_type TEXT NOT NULL,
+		entity_id BIGINT NOT NULL,
+		assigned_to TEXT NOT NULL,
+		assigned_at TIMESTAMP NOT NULL,
+		assignment_expires_at TIMESTAMP NOT NULL,
+		status TEXT NOT NULL DEFAULT 'assigned',
+		breached_at TIMESTAMP,
+		breach_level TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`)
+
+	node, _ := snowflake.NewNode(1)
+	orgID := node.Generate()
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3)
+
+	insertAction := func(actionType string, metadata string, at time.Time) {
+		require.NoError(t, db.Exec(
+			`INSERT INTO billing_operation_actions (id, org_id, entity_type, entity_id, action_type, action_bucket, metadata, actor_type, actor_id, created_at)
+			 VALUES (?, ?, 'invoice', ?, ?, ?, ?, 'user', 'agent', ?)`,
+			node.Generate(), orgID, node.Generate(), actionType, at, metadata, at,
+		).Error)
+	}
+
+	// Four claims in the window, one just outside it.
+	for i := 0; i < 4; i++ {
+		insertAction(domain.ActionTypeClaim, `{}`, from.Add(time.Duration(i)*time.Hour))
+	}
+	insertAction(domain.ActionTypeClaim, `{}`, to.Add(time.Hour))
+
+	insertAction(domain.ActionTypeSLABreached, `{"breach_type":"initial_response"}`, from.Add(2*time.Hour))
+	insertAction(domain.ActionTypeSLABreached, `{"breach_type":"idle_action"}`, from.Add(26*time.Hour))
+	insertAction(domain.ActionTypeSLABreached, `{"breach_type":"initial_response"}`, from.Add(27*time.Hour))
+	insertAction(domain.ActionTypeSLABreached, `{"breach_type":"idle_action"}`, from.AddDate(0, 0, -1))
+
+	require.NoError(t, db.Exec(
+		`INSERT INTO billing_operation_assignments (id, org_id, entity_type, entity_id, assigned_to, assigned_at, assignment_expires_at, status, breached_at, breach_level, created_at, updated_at)
+		 VALUES (?, ?, 'invoice', ?, 'agent', ?, ?, ?, ?, ?, ?, ?)`,
+		node.Generate(), orgID, node.Generate(), from, to, domain.AssignmentStatusEscalated, from.Add(26*time.Hour), domain.SLABreachIdleAction, from, from,
+	).Error)
+
+	svc := &Service{
+		db:    db,
+		log:   zaptest.NewLogger(t),
+		clock: clock.NewFakeClock(to),
+		repo:  repository.NewRepository(db),
+	}
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	resp, err := svc.GetSLAStats(ctx, from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, resp.Claims)
+	assert.Equal(t, 3, resp.TotalBreaches)
+	require.NotNil(t, resp.BreachRate)
+	assert.InDelta(t, 0.75, *resp.BreachRate, 1e-9)
+	assert.Equal(t, 1, resp.EscalatedOpen)
+
+	require.Len(t, resp.ByType, 2)
+	assert.Equal(t, domain.SLABreachInitialResponse, resp.ByType[0].BreachType)
+	assert.Equal(t, 2, resp.ByType[0].Breaches)
+	assert.InDelta(t, 0.5, *resp.ByType[0].BreachRate, 1e-9)
+	assert.Equal(t, domain.SLABreachIdleAction, resp.ByType[1].BreachType)
+	assert.Equal(t, 1, resp.ByType[1].Breaches)
+	assert.InDelta(t, 0.25, *resp.ByType[1].BreachRate, 1e-9)
+
+	require.Len(t, resp.Series, 3)
+	assert.Equal(t, 1, resp.Series[0].InitialResponse)
+	assert.Equal(t, 0, resp.Series[0].IdleAction)
+	assert.Equal(t, 1, resp.Series[1].InitialResponse)
+	assert.Equal(t, 1, resp.Series[1].IdleAction)
+	assert.Equal(t, 0, resp.Series[2].InitialResponse+resp.Series[2].IdleAction)
+}
+
+func TestGetSLAStats_NoClaims(t *testing.T) {
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	resp := buildSLAStats(from, from.AddDate(0, 0, 1), 0, nil)
+
+	assert.Nil(t, resp.BreachRate)
+	for _, stats := range resp.ByType {
+		assert.Nil(t, stats.BreachRate)
+	}
+	assert.Len(t, resp.Series, 1)
+}
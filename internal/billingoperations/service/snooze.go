@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	auditcontext "github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// SnoozeEntity hides an invoice or customer from the inbox until the given
+// time without claiming it. Snoozes expire on their own; once until passes the
+// entity is eligible for the inbox again. Orgs opt in with the
+// billing_operations.snooze feature flag.
+func (s *Service) SnoozeEntity(ctx context.Context, entityType, entityID string, until time.Time, reason string) error {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.ErrInvalidOrganization
+	}
+	if !s.featureEnabled(ctx, orgID, featuredomain.FlagBillingOperationsSnooze) {
+		return domain.ErrFeatureDisabled
+	}
+
+	entityType = strings.TrimSpace(entityType)
+	if entityType != domain.EntityTypeInvoice && entityType != domain.EntityTypeCustomer {
+		return domain.ErrInvalidEntityType
+	}
+
+	parsedID, err := parseSnowflakeID(entityID)
+	if err != nil {
+		return domain.ErrInvalidEntityID
+	}
+
+	_, actorID := auditcontext.ActorFromContext(ctx)
+	snoozedBy := strings.TrimSpace(actorID)
+	if snoozedBy == "" {
+		return domain.ErrInvalidAssignee
+	}
+
+	now := s.clock.Now().UTC()
+	until = until.UTC()
+	if !until.After(now) {
+		return domain.ErrInvalidSnoozeUntil
+	}
+	reason = strings.TrimSpace(reason)
+
+	snoozeID := s.genID.Generate()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+
+		// The snapshot doubles as the existence check: entities outside the
+		// org are not found.
+		snapshot, err := repoTx.LoadEntitySnapshot(ctx, orgID, entityType, parsedID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrEntityNotFound
+			}
+			return err
+		}
+
+		if err := repoTx.InsertSnooze(ctx, domain.BillingSnoozeRecord{
+			ID:           snoozeID,
+			OrgID:        orgID,
+			EntityType:   entityType,
+			EntityID:     parsedID,
+			SnoozedUntil: until,
+			Reason:       reason,
+			SnoozedBy:    snoozedBy,
+			CreatedAt:    now,
+		}); err != nil {
+			return err
+		}
+
+		// Each snooze is its own action, so re-snoozing the same day is
+		// recorded rather than absorbed by the daily bucket.
+		inserted, err := repoTx.InsertBillingAction(ctx, domain.BillingActionRecord{
+			ID:             s.genID.Generate(),
+			OrgID:          orgID,
+			EntityType:     entityType,
+			EntityID:       parsedID,
+			ActionType:     domain.ActionTypeSnooze,
+			ActionBucket:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+			IdempotencyKey: domain.ActionTypeSnooze + ":" + snoozeID.String(),
+			Metadata: datatypes.JSONMap{
+				"snooze_id":     snoozeID.String(),
+				"snoozed_until": until.Format(time.RFC3339),
+				"reason":        reason,
+				"snapshot":      snapshot,
+			},
+			ActorType: "user",
+			ActorID:   snoozedBy,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		if !inserted {
+			return domain.ErrActionNotRecorded
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.emitAudit(ctx, orgID, auditEntry{
+		category:   auditCategoryOperational,
+		action:     "billing_operations.entity.snoozed",
+		targetType: entityType,
+		targetID:   parsedID.String(),
+		metadata: map[string]any{
+			"snooze_id":     snoozeID.String(),
+			"snoozed_until": until.Format(time.RFC3339),
+			"snoozed_by":    snoozedBy,
+			"reason":        reason,
+		},
+	})
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/config"
+)
+
+// teamWorkloadBalance measures how evenly active assignments and exposure
+// are spread over rows. thresholdPercent sets how far above the mean an
+// operator must be to need rebalancing; zero uses the default.
+func teamWorkloadBalance(rows []domain.TeamRow, thresholdPercent int) domain.TeamWorkloadBalance {
+	if thresholdPercent <= 0 {
+		thresholdPercent = config.DefaultBillingConfig().TeamViews.RebalanceThresholdPercent
+	}
+	active := make([]float64, len(rows))
+	exposure := make([]float64, len(rows))
+	for i, row := range rows {
+		active[i] = float64(row.ActiveAssignments)
+		exposure[i] = float64(row.TotalExposureOwned)
+	}
+
+	balance := domain.TeamWorkloadBalance{
+		ActiveAssignmentsImbalance: roundTo(gini(active), 3),
+		ExposureImbalance:          roundTo(gini(exposure), 3),
+		MeanActiveAssignments:      roundTo(mean(active), 2),
+		MeanExposure:               roundTo(mean(exposure), 2),
+		ThresholdPercent:           thresholdPercent,
+	}
+	for _, row := range rows {
+		if len(rebalanceReasons(row, balance)) > 0 {
+			balance.NeedsRebalanceCount++
+		}
+	}
+	return balance
+}
+
+// rebalanceReasons lists the measures on which row is more than the balance
+// threshold above the team mean. Operators are compared with the mean only,
+// never with each other, so equal workloads always get equal flags.
+func rebalanceReasons(row domain.TeamRow, balance domain.TeamWorkloadBalance) []string {
+	factor := 1 + float64(balance.ThresholdPercent)/100
+	var reasons []string
+	if balance.MeanActiveAssignments > 0 && float64(row.ActiveAssignments) > balance.MeanActiveAssignments*factor {
+		reasons = append(reasons, domain.RebalanceReasonActiveAssignments)
+	}
+	if balance.MeanExposure > 0 && float64(row.TotalExposureOwned) > balance.MeanExposure*factor {
+		reasons = append(reasons, domain.RebalanceReasonExposure)
+	}
+	return reasons
+}
+
+// gini returns the Gini coefficient of values: 0 for a perfectly even
+// distribution, approaching 1 as everything concentrates on one value.
+func gini(values []float64) float64 {
+	n := float64(len(values))
+	if n == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var total, weighted float64
+	for i, v := range sorted {
+		total += v
+		weighted += float64(i+1) * v
+	}
+	if total <= 0 {
+		return 0
+	}
+	return 2*weighted/(n*total) - (n+1)/n
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+func roundTo(v float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+)
+
+// teamPerformanceLess returns the ordering for team performance summaries.
+// An empty orderBy sorts by user ID and an empty direction is ascending;
+// user ID ascending breaks ties so the order stays deterministic.
+func teamPerformanceLess(orderBy, direction string) (func(a, b domain.TeamMemberSummary) bool, error) {
+	orderBy = strings.ToLower(strings.TrimSpace(orderBy))
+	direction = strings.ToLower(strings.TrimSpace(direction))
+
+	var compare func(a, b domain.TeamMemberSummary) int
+	switch orderBy {
+	case "", domain.TeamPerformanceOrderByUserID:
+		compare = func(a, b domain.TeamMemberSummary) int { return strings.Compare(a.UserID, b.UserID) }
+	case domain.TeamPerformanceOrderByAvgScore:
+		compare = func(a, b domain.TeamMemberSummary) int { return cmp.Compare(a.AvgScore, b.AvgScore) }
+	case domain.TeamPerformanceOrderByExposureHandled:
+		compare = func(a, b domain.TeamMemberSummary) int {
+			return cmp.Compare(a.MetricsSummary.ExposureHandled, b.MetricsSummary.ExposureHandled)
+		}
+	case domain.TeamPerformanceOrderByCompletionRatio:
+		compare = func(a, b domain.TeamMemberSummary) int {
+			return cmp.Compare(a.MetricsSummary.CompletionRatio, b.MetricsSummary.CompletionRatio)
+		}
+	default:
+		return nil, domain.ErrInvalidOrderBy
+	}
+
+	desc := false
+	switch direction {
+	case "", domain.OrderDirectionAsc:
+	case domain.OrderDirectionDesc:
+		desc = true
+	default:
+		return nil, domain.ErrInvalidOrderDirection
+	}
+
+	return func(a, b domain.TeamMemberSummary) bool {
+		if c := compare(a, b); c != 0 {
+			return c < 0 != desc
+		}
+		return a.UserID < b.UserID
+	}, nil
+}
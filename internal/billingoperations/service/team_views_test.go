@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// teamViewRepo returns fixed team rows and snapshots, including rows owned by
+// the system pseudo-user that escalations resolve as. includeSystemActors is
+// the org's team views preference.
+type teamViewRepo struct {
+	domain.Repository
+	rows                []domain.TeamRow
+	snapshots           []domain.FinOpsScoreSnapshot
+	includeSystemActors bool
+}
+
+func excluded(ids []string, userID string) bool {
+	for _, id := range ids {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *teamViewRepo) FetchOrgCurrency(context.Context, snowflake.ID) (string, error) {
+	return "USD", nil
+}
+
+func (r *teamViewRepo) FetchTeamViewsIncludeSystemActors(context.Context, snowflake.ID) (bool, error) {
+	return r.includeSystemActors, nil
+}
+
+func (r *teamViewRepo) GetTeamViewStats(_ context.Context, _ snowflake.ID, excludeUserIDs []string, _ time.Time) ([]domain.TeamRow, error) {
+	var rows []domain.TeamRow
+	for _, row := range r.rows {
+		if !excluded(excludeUserIDs, row.UserID) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (r *teamViewRepo) FindSnapshotsByOrg(_ context.Context, _ snowflake.ID, _ string, _, _ time.Time, excludeUserIDs []string) ([]domain.FinOpsScoreSnapshot, error) {
+	var snapshots []domain.FinOpsScoreSnapshot
+	for _, snap := range r.snapshots {
+		if !excluded(excludeUserIDs, snap.UserID) {
+			snapshots = append(snapshots, snap)
+		}
+	}
+	return snapshots, nil
+}
+
+func TestTeamViews_SystemActors(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	repo := &teamViewRepo{
+		rows: []domain.TeamRow{
+			{UserID: "1001", ActiveAssignments: 2, AvgAssignmentAgeMinutes: 30, TotalExposureOwned: 5_000},
+			{UserID: domain.SystemActorID, ActiveAssignments: 4, AvgAssignmentAgeMinutes: 600, TotalExposureOwned: 90_000, EscalationCount: 4},
+		},
+		snapshots: []domain.FinOpsScoreSnapshot{
+			{UserID: "1001", Scores: domain.PerformanceScores{Total: 80}},
+			{UserID: domain.SLAMonitorActorID, Scores: domain.PerformanceScores{Total: 10}},
+		},
+	}
+
+	newService := func(include bool) *Service {
+		repo.includeSystemActors = include
+		return &Service{
+			repo:       repo,
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+		}
+	}
+
+	t.Run("system actors are excluded by default", func(t *testing.T) {
+		svc := newService(false)
+
+		view, err := svc.GetTeamView(ctx, domain.TeamViewRequest{})
+		require.NoError(t, err)
+		require.Len(t, view.Members, 1)
+		assert.Equal(t, "1001", view.Members[0].UserID)
+		assert.Equal(t, 2, view.Summary.TotalActiveAssignments)
+		assert.Equal(t, int64(5_000), view.Summary.TotalExposure)
+		assert.Equal(t, 0, view.Summary.EscalationCount)
+
+		perf, err := svc.GetTeamPerformance(ctx, domain.GetPerformanceRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, perf.TeamSize)
+		for _, member := range perf.Snapshots {
+			assert.False(t, domain.IsSystemActor(member.UserID), "phantom member %q", member.UserID)
+		}
+	})
+
+	t.Run("system actors can be opted in", func(t *testing.T) {
+		svc := newService(true)
+
+		view, err := svc.GetTeamView(ctx, domain.TeamViewRequest{})
+		require.NoError(t, err)
+		assert.Len(t, view.Members, 2)
+		assert.Equal(t, 6, view.Summary.TotalActiveAssignments)
+
+		perf, err := svc.GetTeamPerformance(ctx, domain.GetPerformanceRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, perf.TeamSize)
+	})
+}
+
+func TestTeamViews_ExcludeUserIDs(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	repo := &teamViewRepo{
+		rows: []domain.TeamRow{
+			{UserID: "1001", ActiveAssignments: 2, AvgAssignmentAgeMinutes: 30, TotalExposureOwned: 5_000},
+			{UserID: "1002", ActiveAssignments: 3, AvgAssignmentAgeMinutes: 90, TotalExposureOwned: 40_000, EscalationCount: 1},
+		},
+		snapshots: []domain.FinOpsScoreSnapshot{
+			{UserID: "1001", Scores: domain.PerformanceScores{Total: 80}},
+			{UserID: "1002", Scores: domain.PerformanceScores{Total: 40}},
+		},
+	}
+	svc := &Service{
+		repo:       repo,
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(now),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+
+	t.Run("excluded user leaves the team totals", func(t *testing.T) {
+		view, err := svc.GetTeamView(ctx, domain.TeamViewRequest{ExcludeUserIDs: []string{" 1002 ", "1002"}})
+		require.NoError(t, err)
+		require.Len(t, view.Members, 1)
+		assert.Equal(t, "1001", view.Members[0].UserID)
+		assert.Equal(t, 2, view.Summary.TotalActiveAssignments)
+		assert.Equal(t, int64(5_000), view.Summary.TotalExposure)
+		assert.Equal(t, 0, view.Summary.EscalationCount)
+
+		perf, err := svc.GetTeamPerformance(ctx, domain.GetPerformanceRequest{ExcludeUserIDs: []string{"1002"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, perf.TeamSize)
+		assert.Equal(t, 80, perf.Snapshots[0].AvgScore)
+	})
+
+	t.Run("nothing excluded by default", func(t *testing.T) {
+		view, err := svc.GetTeamView(ctx, domain.TeamViewRequest{})
+		require.NoError(t, err)
+		assert.Len(t, view.Members, 2)
+		assert.Equal(t, 5, view.Summary.TotalActiveAssignments)
+	})
+
+	t.Run("blank user ID is rejected", func(t *testing.T) {
+		_, err := svc.GetTeamView(ctx, domain.TeamViewRequest{ExcludeUserIDs: []string{" "}})
+		assert.ErrorIs(t, err, domain.ErrInvalidExcludeUsers)
+
+		_, err = svc.GetTeamPerformance(ctx, domain.GetPerformanceRequest{ExcludeUserIDs: make([]string, maxExcludeUserIDs+1)})
+		assert.ErrorIs(t, err, domain.ErrInvalidExcludeUsers)
+	})
+}
+
+func TestTeamView_Truncation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	repo := &teamViewRepo{
+		rows: []domain.TeamRow{
+			{UserID: "1001", ActiveAssignments: 1, AvgAssignmentAgeMinutes: 30, TotalExposureOwned: 1_000},
+			{UserID: "1002", ActiveAssignments: 6, AvgAssignmentAgeMinutes: 90, TotalExposureOwned: 60_000, EscalationCount: 2},
+			{UserID: "1003", ActiveAssignments: 3, AvgAssignmentAgeMinutes: 150, TotalExposureOwned: 9_000, EscalationCount: 1},
+			{UserID: "1004", ActiveAssignments: 3, AvgAssignmentAgeMinutes: 10, TotalExposureOwned: 20_000},
+		},
+	}
+	newService := func(maxMembers int) *Service {
+		cfg := config.DefaultBillingConfig()
+		cfg.TeamViews.MaxMembers = maxMembers
+		return &Service{
+			repo:       repo,
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			billingCfg: config.NewStaticBillingConfigHolder(cfg),
+		}
+	}
+	userIDs := func(members []domain.TeamMemberWorkload) []string {
+		ids := make([]string, 0, len(members))
+		for _, member := range members {
+			ids = append(ids, member.UserID)
+		}
+		return ids
+	}
+
+	t.Run("everyone is listed by default", func(t *testing.T) {
+		view, err := newService(0).GetTeamView(ctx, domain.TeamViewRequest{})
+		require.NoError(t, err)
+		assert.Len(t, view.Members, 4)
+		assert.False(t, view.Truncated)
+		assert.Nil(t, view.Others)
+	})
+
+	t.Run("top lists the busiest and summarizes the rest", func(t *testing.T) {
+		view, err := newService(0).GetTeamView(ctx, domain.TeamViewRequest{Top: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1002", "1004"}, userIDs(view.Members), "ties broken by exposure, listed in user order")
+		assert.True(t, view.Truncated)
+		require.NotNil(t, view.Others)
+		assert.Equal(t, domain.TeamOthersSummary{
+			MemberCount:        2,
+			ActiveAssignments:  4,
+			AvgAssignmentAge:   "2h 0m",
+			TotalExposureOwned: 10_000,
+			EscalationCount:    1,
+		}, *view.Others)
+
+		assert.Equal(t, 13, view.Summary.TotalActiveAssignments, "summary covers the whole team")
+		assert.Equal(t, int64(90_000), view.Summary.TotalExposure)
+		assert.Equal(t, 3, view.Summary.EscalationCount)
+	})
+
+	t.Run("configured maximum caps the request", func(t *testing.T) {
+		view, err := newService(1).GetTeamView(ctx, domain.TeamViewRequest{Top: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1002"}, userIDs(view.Members))
+		assert.Equal(t, 3, view.Others.MemberCount)
+
+		view, err = newService(1).GetTeamView(ctx, domain.TeamViewRequest{})
+		require.NoError(t, err)
+		assert.Len(t, view.Members, 1)
+		assert.True(t, view.Truncated)
+	})
+
+	t.Run("not truncated when the team fits", func(t *testing.T) {
+		view, err := newService(0).GetTeamView(ctx, domain.TeamViewRequest{Top: 4})
+		require.NoError(t, err)
+		assert.Len(t, view.Members, 4)
+		assert.False(t, view.Truncated)
+	})
+
+	t.Run("negative top is rejected", func(t *testing.T) {
+		_, err := newService(0).GetTeamView(ctx, domain.TeamViewRequest{Top: -1})
+		assert.ErrorIs(t, err, domain.ErrInvalidTop)
+	})
+}
+
+// slowTeamViewRepo blocks the team aggregation until its context ends.
+type slowTeamViewRepo struct {
+	teamViewRepo
+}
+
+func (r *slowTeamViewRepo) GetTeamViewStats(ctx context.Context, _ snowflake.ID, _ []string, _ time.Time) ([]domain.TeamRow, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTeamView_Timeout(t *testing.T) {
+	cfg := config.DefaultBillingConfig()
+	cfg.TeamViews.QueryTimeoutSeconds = 1
+	svc := &Service{
+		repo:       &slowTeamViewRepo{},
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+		billingCfg: config.NewStaticBillingConfigHolder(cfg),
+	}
+
+	t.Run("a slow aggregation times out", func(t *testing.T) {
+		ctx := orgcontext.WithOrgID(context.Background(), 1)
+		_, err := svc.GetTeamView(ctx, domain.TeamViewRequest{})
+		assert.ErrorIs(t, err, domain.ErrTeamViewTimeout)
+	})
+
+	t.Run("a cancelled request is not reported as a timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(orgcontext.WithOrgID(context.Background(), 1))
+		cancel()
+		_, err := svc.GetTeamView(ctx, domain.TeamViewRequest{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestIsSystemActor(t *testing.T) {
+	for _, id := range []string{"system", "SYSTEM", "sla_monitor", "scheduler", "system:dunning"} {
+		assert.True(t, domain.IsSystemActor(id), id)
+	}
+	for _, id := range []string{"", "1001", "systematic", "alice"} {
+		assert.False(t, domain.IsSystemActor(id), id)
+	}
+}
+
+func TestTeamView_WorkloadBalance(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	newService := func(rows []domain.TeamRow) *Service {
+		return &Service{
+			repo:       &teamViewRepo{rows: rows},
+			log:        zaptest.NewLogger(t),
+			clock:      clock.NewFakeClock(now),
+			billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+		}
+	}
+	flags := func(view domain.TeamViewResponse) map[string][]string {
+		out := make(map[string][]string, len(view.Members))
+		for _, member := range view.Members {
+			assert.Equal(t, member.NeedsRebalancing, len(member.RebalanceReasons) > 0)
+			out[member.UserID] = member.RebalanceReasons
+		}
+		return out
+	}
+
+	t.Run("even team needs no rebalancing", func(t *testing.T) {
+		view, err := newService([]domain.TeamRow{
+			{UserID: "1001", ActiveAssignments: 3, TotalExposureOwned: 10_000},
+			{UserID: "1002", ActiveAssignments: 3, TotalExposureOwned: 10_000},
+		}).GetTeamView(ctx, domain.TeamViewRequest{})
+		require.NoError(t, err)
+		assert.Zero(t, view.Balance.ActiveAssignmentsImbalance)
+		assert.Zero(t, view.Balance.ExposureImbalance)
+		assert.Zero(t, view.Balance.NeedsRebalanceCount)
+		assert.Equal(t, 50, view.Balance.ThresholdPercent)
+	})
+
+	t.Run("flags are symmetric and do not rank", func(t *testing.T) {
+		rows := []domain.TeamRow{
+			{UserID: "1001", ActiveAssignments: 1, TotalExposureOwned: 1_000},
+			{UserID: "1002", ActiveAssignments: 8, TotalExposureOwned: 40_000},
+			{UserID: "1003", ActiveAssignments: 1, TotalExposureOwned: 1_000},
+			{UserID: "1004", ActiveAssignments: 8, TotalExposureOwned: 2_000},
+			{UserID: "1005", ActiveAssignments: 2, TotalExposureOwned: 1_000},
+		}
+		view, err := newService(rows).GetTeamView(ctx, domain.TeamViewRequest{})
+		require.NoError(t, err)
+
+		got := flags(view)
+		assert.Equal(t, map[string][]string{
+			"1001": nil,
+			"1002": {domain.RebalanceReasonActiveAssignments, domain.RebalanceReasonExposure},
+			"1003": nil,
+			"1004": {domain.RebalanceReasonActiveAssignments},
+			"1005": nil,
+		}, got, "operators with equal workloads get equal flags")
+		assert.Equal(t, 2, view.Balance.NeedsRebalanceCount)
+		assert.Equal(t, 4.0, view.Balance.MeanActiveAssignments)
+		assert.Equal(t, 0.42, view.Balance.ActiveAssignmentsImbalance)
+		assert.Greater(t, view.Balance.ExposureImbalance, view.Balance.ActiveAssignmentsImbalance)
+
+		var ids []string
+		for _, member := range view.Members {
+			ids = append(ids, member.UserID)
+		}
+		assert.Equal(t, []string{"1001", "1002", "1003", "1004", "1005"}, ids, "members stay in user order, not sorted by load")
+
+		reversed := make([]domain.TeamRow, len(rows))
+		for i, row := range rows {
+			reversed[len(rows)-1-i] = row
+		}
+		view, err = newService(reversed).GetTeamView(ctx, domain.TeamViewRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, got, flags(view), "flags do not depend on the order operators are listed in")
+	})
+
+	t.Run("balance covers members folded into others", func(t *testing.T) {
+		view, err := newService([]domain.TeamRow{
+			{UserID: "1001", ActiveAssignments: 9, TotalExposureOwned: 9_000},
+			{UserID: "1002", ActiveAssignments: 1, TotalExposureOwned: 1_000},
+			{UserID: "1003", ActiveAssignments: 1, TotalExposureOwned: 1_000},
+		}).GetTeamView(ctx, domain.TeamViewRequest{Top: 1})
+		require.NoError(t, err)
+		require.Len(t, view.Members, 1)
+		assert.True(t, view.Members[0].NeedsRebalancing)
+		assert.InDelta(t, 11.0/3, view.Balance.MeanActiveAssignments, 0.01)
+		assert.Equal(t, 1, view.Balance.NeedsRebalanceCount)
+	})
+}
+
+func TestGetTeamPerformance_OrderBy(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	metrics := func(assigned, resolved int, exposure int64) domain.PerformanceMetrics {
+		return domain.PerformanceMetrics{TotalAssigned: assigned, TotalResolved: resolved, ExposureHandled: exposure}
+	}
+	svc := &Service{
+		repo: &teamViewRepo{
+			snapshots: []domain.FinOpsScoreSnapshot{
+				{UserID: "1003", Scores: domain.PerformanceScores{Total: 70}, Metrics: metrics(4, 1, 9_000)},
+				{UserID: "1001", Scores: domain.PerformanceScores{Total: 90}, Metrics: metrics(4, 2, 1_000)},
+				{UserID: "1002", Scores: domain.PerformanceScores{Total: 70}, Metrics: metrics(4, 4, 5_000)},
+			},
+		},
+		log:        zaptest.NewLogger(t),
+		clock:      clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)),
+		billingCfg: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	}
+	order := func(t *testing.T, orderBy, direction string) []string {
+		t.Helper()
+		perf, err := svc.GetTeamPerformance(ctx, domain.GetPerformanceRequest{OrderBy: orderBy, OrderDirection: direction})
+		require.NoError(t, err)
+		ids := make([]string, 0, len(perf.Snapshots))
+		for _, member := range perf.Snapshots {
+			ids = append(ids, member.UserID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"1001", "1002", "1003"}, order(t, "", ""))
+	assert.Equal(t, []string{"1003", "1002", "1001"}, order(t, domain.TeamPerformanceOrderByUserID, domain.OrderDirectionDesc))
+	// Ties on avg score fall back to user_id ascending in both directions.
+	assert.Equal(t, []string{"1002", "1003", "1001"}, order(t, domain.TeamPerformanceOrderByAvgScore, ""))
+	assert.Equal(t, []string{"1001", "1002", "1003"}, order(t, domain.TeamPerformanceOrderByAvgScore, "DESC"))
+	assert.Equal(t, []string{"1003", "1002", "1001"}, order(t, domain.TeamPerformanceOrderByExposureHandled, domain.OrderDirectionDesc))
+	assert.Equal(t, []string{"1003", "1001", "1002"}, order(t, domain.TeamPerformanceOrderByCompletionRatio, domain.OrderDirectionAsc))
+
+	_, err := svc.GetTeamPerformance(ctx, domain.GetPerformanceRequest{OrderBy: "escalation_ratio"})
+	assert.ErrorIs(t, err, domain.ErrInvalidOrderBy)
+	_, err = svc.GetTeamPerformance(ctx, domain.GetPerformanceRequest{OrderDirection: "up"})
+	assert.ErrorIs(t, err, domain.ErrInvalidOrderDirection)
+}
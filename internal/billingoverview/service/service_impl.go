@@ -796,15 +796,17 @@ func (s *Service) loadOutstandingBalance(
 		`
 		WITH settled AS (
 			SELECT
-				(pe.payload #>> '{data,object,metadata,invoice_id}') AS invoice_id_text,
+				COALESCE(pe.payload #>> '{data,object,metadata,invoice_id}', pa.invoice_id::text, cn.invoice_id::text) AS invoice_id_text,
 				SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END) AS settled_amount
 			FROM ledger_entries le
 			JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
 			JOIN ledger_accounts a ON a.id = l.account_id
-			JOIN payment_events pe ON pe.id = le.source_id
+			LEFT JOIN payment_events pe ON pe.id = le.source_id
+			LEFT JOIN payment_allocations pa ON pa.payment_event_id = pe.id
+			LEFT JOIN credit_notes cn ON cn.id = le.source_id
 			WHERE le.org_id = ?
 			  AND le.currency = ?
-			  AND le.source_type = ?
+			  AND le.source_type IN (?, ?)
 			  AND a.code = ?
 			GROUP BY 1
 		)
@@ -834,6 +836,7 @@ func (s *Service) loadOutstandingBalance(
 		orgID,
 		currency,
 		string(ledgerdomain.SourceTypePayment),
+		string(ledgerdomain.SourceTypeCreditNote),
 		string(ledgerdomain.AccountCodeAccountsReceivable),
 		now,
 		orgID,
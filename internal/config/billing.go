@@ -22,6 +22,35 @@ func DefaultBillingConfig() BillingConfig {
 			{Level: "medium", MinOutstanding: 250_000, MinDays: 31},
 			{Level: "low", MinOutstanding: 0, MinDays: 0},
 		},
+		MissingDueDate: MissingDueDatePolicy{
+			Mode:         MissingDueDateIgnore,
+			NetTermsDays: 30,
+		},
+		PaymentIssues: PaymentIssuesConfig{
+			EventTypes:     []string{"payment_failed"},
+			ClearOnSuccess: true,
+		},
+		SLA: SLAConfig{
+			InitialResponseMinutes: 30,
+			FirstContactMinutes:    240,
+			IdleActionMinutes:      60,
+			GracePeriodSeconds:     30,
+		},
+		TeamViews: TeamViewsConfig{
+			QueryTimeoutSeconds:       10,
+			RebalanceThresholdPercent: 50,
+		},
+		ExposureAnalysis: ExposureAnalysisConfig{
+			TopCustomers: 5,
+		},
+		Inbox: InboxConfig{
+			HighExposureThreshold: 100_000,
+		},
+		QueryTimeouts: QueryTimeoutsConfig{
+			InboxSeconds:            10,
+			MyWorkSeconds:           10,
+			ExposureAnalysisSeconds: 20,
+		},
 	}
 }
 
@@ -53,6 +82,25 @@ func NewBillingConfigHolder() (*BillingConfigHolder, error) {
 		defaults := DefaultBillingConfig()
 		v.SetDefault("billing.agingBuckets", defaults.AgingBuckets)
 		v.SetDefault("billing.riskLevels", defaults.RiskLevels)
+		v.SetDefault("billing.missingDueDate.mode", defaults.MissingDueDate.Mode)
+		v.SetDefault("billing.missingDueDate.netTermsDays", defaults.MissingDueDate.NetTermsDays)
+		v.SetDefault("billing.teamViews.queryTimeoutSeconds", defaults.TeamViews.QueryTimeoutSeconds)
+		v.SetDefault("billing.teamViews.maxMembers", defaults.TeamViews.MaxMembers)
+		v.SetDefault("billing.teamViews.rebalanceThresholdPercent", defaults.TeamViews.RebalanceThresholdPercent)
+		v.SetDefault("billing.paymentIssues.eventTypes", defaults.PaymentIssues.EventTypes)
+		v.SetDefault("billing.paymentIssues.clearOnSuccess", defaults.PaymentIssues.ClearOnSuccess)
+		v.SetDefault("billing.sla.initialResponseMinutes", defaults.SLA.InitialResponseMinutes)
+		v.SetDefault("billing.sla.firstContactMinutes", defaults.SLA.FirstContactMinutes)
+		v.SetDefault("billing.sla.idleActionMinutes", defaults.SLA.IdleActionMinutes)
+		v.SetDefault("billing.sla.gracePeriodSeconds", defaults.SLA.GracePeriodSeconds)
+		v.SetDefault("billing.exposureAnalysis.topCustomers", defaults.ExposureAnalysis.TopCustomers)
+		v.SetDefault("billing.exposureAnalysis.skipTopCustomers", defaults.ExposureAnalysis.SkipTopCustomers)
+		v.SetDefault("billing.inbox.highExposureThreshold", defaults.Inbox.HighExposureThreshold)
+		v.SetDefault("billing.inbox.includeCurrentExposure", defaults.Inbox.IncludeCurrentExposure)
+		v.SetDefault("billing.queryTimeouts.inboxSeconds", defaults.QueryTimeouts.InboxSeconds)
+		v.SetDefault("billing.queryTimeouts.myWorkSeconds", defaults.QueryTimeouts.MyWorkSeconds)
+		v.SetDefault("billing.queryTimeouts.exposureAnalysisSeconds", defaults.QueryTimeouts.ExposureAnalysisSeconds)
+		v.SetDefault("billing.manualPayments.allowOverpayment", defaults.ManualPayments.AllowOverpayment)
 	}
 
 	var cfg BillingConfig
@@ -85,8 +133,23 @@ func NewBillingConfigHolder() (*BillingConfigHolder, error) {
 	return holder, nil
 }
 
+// NewStaticBillingConfigHolder returns a holder pinned to cfg, without file
+// loading or hot reload.
+func NewStaticBillingConfigHolder(cfg BillingConfig) *BillingConfigHolder {
+	holder := &BillingConfigHolder{}
+	holder.current.Store(cfg)
+	return holder
+}
+
 func (h *BillingConfigHolder) Get() BillingConfig {
-	return h.current.Load().(BillingConfig)
+	if h == nil {
+		return DefaultBillingConfig()
+	}
+	cfg, ok := h.current.Load().(BillingConfig)
+	if !ok {
+		return DefaultBillingConfig()
+	}
+	return cfg
 }
 
 func validateBillingConfig(cfg BillingConfig) error {
@@ -96,5 +159,53 @@ func validateBillingConfig(cfg BillingConfig) error {
 	if len(cfg.RiskLevels) == 0 {
 		return errors.New("billing.riskLevels cannot be empty")
 	}
+	switch cfg.MissingDueDate.Mode {
+	case "", MissingDueDateIgnore, MissingDueDateNetTerms:
+	default:
+		return errors.New("billing.missingDueDate.mode must be ignore or net_terms")
+	}
+	if cfg.MissingDueDate.NetTermsDays < 0 {
+		return errors.New("billing.missingDueDate.netTermsDays cannot be negative")
+	}
+	if cfg.TeamViews.QueryTimeoutSeconds < 0 {
+		return errors.New("billing.teamViews.queryTimeoutSeconds cannot be negative")
+	}
+	if cfg.TeamViews.MaxMembers < 0 {
+		return errors.New("billing.teamViews.maxMembers cannot be negative")
+	}
+	if cfg.TeamViews.RebalanceThresholdPercent < 0 {
+		return errors.New("billing.teamViews.rebalanceThresholdPercent cannot be negative")
+	}
+	for _, eventType := range cfg.PaymentIssues.EventTypes {
+		if strings.TrimSpace(eventType) == "" {
+			return errors.New("billing.paymentIssues.eventTypes cannot contain empty values")
+		}
+	}
+	if cfg.SLA.InitialResponseMinutes < 0 || cfg.SLA.FirstContactMinutes < 0 || cfg.SLA.IdleActionMinutes < 0 {
+		return errors.New("billing.sla minutes cannot be negative")
+	}
+	if cfg.SLA.GracePeriodSeconds < 0 {
+		return errors.New("billing.sla.gracePeriodSeconds cannot be negative")
+	}
+	if cfg.ExposureAnalysis.TopCustomers < 0 || cfg.ExposureAnalysis.TopCustomers > MaxExposureTopCustomers {
+		return errors.New("billing.exposureAnalysis.topCustomers must be between 0 and 100")
+	}
+	if cfg.Inbox.HighExposureThreshold < 0 {
+		return errors.New("billing.inbox.highExposureThreshold cannot be negative")
+	}
+	if cfg.QueryTimeouts.InboxSeconds < 0 || cfg.QueryTimeouts.MyWorkSeconds < 0 || cfg.QueryTimeouts.ExposureAnalysisSeconds < 0 {
+		return errors.New("billing.queryTimeouts seconds cannot be negative")
+	}
+	for _, rate := range cfg.Tax.Rates {
+		if strings.TrimSpace(rate.Country) == "" {
+			return errors.New("billing.tax.rates country cannot be empty")
+		}
+		if strings.TrimSpace(rate.Code) == "" {
+			return errors.New("billing.tax.rates code cannot be empty")
+		}
+		if rate.Rate < 0 || rate.Rate >= 1 {
+			return errors.New("billing.tax.rates rate must be between 0 and 1")
+		}
+	}
 	return nil
 }
@@ -48,6 +48,10 @@ type Config struct {
 	DBMaxOpenConn     int
 	DBConnMaxLifetime int
 	DBConnMaxIdleTime int
+	// DBTenantScopeCheck sets how queries on tenant tables without an org_id
+	// predicate are handled: "strict" fails them, "warn" logs them and "off"
+	// skips the check. Defaults to strict outside production.
+	DBTenantScopeCheck string
 
 	OAuth2ClientID     string
 	OAuth2ClientSecret string
@@ -55,6 +59,52 @@ type Config struct {
 	RateLimit RateLimitConfig
 	Email     EmailConfig
 	Logger    LoggerConfig
+
+	// FinOpsPerformanceWorkers bounds how many operators the daily
+	// performance aggregation scores in parallel.
+	FinOpsPerformanceWorkers int
+	// FinOpsScoringDayOffset picks which day the daily performance job
+	// scores, counted back from today. 1 scores yesterday; values below 1
+	// are raised to 1 because today has not fully elapsed.
+	FinOpsScoringDayOffset int
+	// FinOpsScoringBatchSize splits the daily performance aggregation into
+	// batches of this many operators and checkpoints after each one, so an
+	// interrupted run resumes where it stopped. 0 scores everyone in one
+	// pass without checkpoints.
+	FinOpsScoringBatchSize int
+	// BillingOpsActionMetadataMaxBytes caps the serialized size of
+	// caller-supplied metadata on recorded billing operation actions.
+	BillingOpsActionMetadataMaxBytes int
+	// CustomerEmailLowercase folds customer emails to lower case on write
+	// and when matching contacts. Surrounding whitespace is always trimmed.
+	CustomerEmailLowercase bool
+	// BillingOpsIncludeCustomerPhone shows customer phone numbers on
+	// billing operations work items. Customers flagged do-not-contact never
+	// show contact channels.
+	BillingOpsIncludeCustomerPhone bool
+	// BillingOpsConflictShowAssignee names the current assignee when a claim
+	// conflicts with another operator's assignment. When false the conflict
+	// only says the entity is held by "another operator".
+	BillingOpsConflictShowAssignee bool
+	// BillingOpsBreachWebhookURL, when set, receives a JSON POST for every
+	// assignment escalated by the SLA monitor.
+	BillingOpsBreachWebhookURL string
+	// BillingOpsAuditRetries is how many times a failed billing operations
+	// audit write is retried before giving up.
+	BillingOpsAuditRetries int
+	// BillingOpsAuditRequired lists the audit categories (financial,
+	// operational, read) whose write must succeed: when it still fails after
+	// the retries the operation returns the error. Other categories are
+	// best-effort and only log the dropped entry.
+	BillingOpsAuditRequired []string
+	// BillingOpsArchiveActions copies billing operations actions past the
+	// retention window to billing_operation_actions_archive before removing
+	// them. When false they are deleted outright.
+	BillingOpsArchiveActions bool
+	// BillingOpsARDriftThreshold is the absolute difference, in minor units,
+	// between the ledger receivable balance and invoice outstanding that a
+	// currency may show before AR reconciliation reports it.
+	BillingOpsARDriftThreshold int64
 }
 
 type EmailConfig struct {
@@ -63,6 +113,10 @@ type EmailConfig struct {
 	SMTPUsername string
 	SMTPPassword string
 	SMTPFrom     string
+
+	// WebhookSecret authenticates the email provider's delivery status
+	// callbacks. Callbacks are rejected while it is empty.
+	WebhookSecret string
 }
 
 type LoggerConfig struct {
@@ -106,10 +160,126 @@ type RateLimitConfig struct {
 }
 
 type BillingConfig struct {
-	AgingBuckets []AgingBucket `mapstructure:"agingBuckets"`
-	RiskLevels   []RiskLevel   `mapstructure:"riskLevels"`
+	AgingBuckets     []AgingBucket          `mapstructure:"agingBuckets"`
+	RiskLevels       []RiskLevel            `mapstructure:"riskLevels"`
+	MissingDueDate   MissingDueDatePolicy   `mapstructure:"missingDueDate"`
+	TeamViews        TeamViewsConfig        `mapstructure:"teamViews"`
+	PaymentIssues    PaymentIssuesConfig    `mapstructure:"paymentIssues"`
+	SLA              SLAConfig              `mapstructure:"sla"`
+	ExposureAnalysis ExposureAnalysisConfig `mapstructure:"exposureAnalysis"`
+	Inbox            InboxConfig            `mapstructure:"inbox"`
+	Tax              TaxConfig              `mapstructure:"tax"`
+	QueryTimeouts    QueryTimeoutsConfig    `mapstructure:"queryTimeouts"`
+	ManualPayments   ManualPaymentsConfig   `mapstructure:"manualPayments"`
+}
+
+const (
+	MissingDueDateIgnore   = "ignore"
+	MissingDueDateNetTerms = "net_terms"
+)
+
+// MissingDueDatePolicy controls how invoices finalized without a due date are
+// aged. With "ignore" they never become overdue; with "net_terms" they are
+// treated as due NetTermsDays after issuance.
+type MissingDueDatePolicy struct {
+	Mode         string `mapstructure:"mode"`
+	NetTermsDays int    `mapstructure:"netTermsDays"`
+}
+
+// TeamViewsConfig controls team workload and performance views; whether
+// system actors appear in them is an org billing preference.
+// QueryTimeoutSeconds bounds the team workload aggregation (0 keeps the
+// default). A positive MaxMembers lists at most that many of the busiest
+// operators and folds the rest into one summary row. Operators more than
+// RebalanceThresholdPercent above the team mean for active assignments or
+// exposure are flagged as needing rebalancing (0 keeps the default).
+type TeamViewsConfig struct {
+	QueryTimeoutSeconds       int `mapstructure:"queryTimeoutSeconds"`
+	MaxMembers                int `mapstructure:"maxMembers"`
+	RebalanceThresholdPercent int `mapstructure:"rebalanceThresholdPercent"`
+}
+
+// PaymentIssuesConfig lists the payment event types surfaced as payment
+// issues, e.g. failed charges, disputes or payments requiring action. An
+// empty list falls back to failed payments only. ClearOnSuccess drops an
+// issue once the customer has a successful payment for the same invoice
+// after it; turn it off to keep listing historical failures.
+type PaymentIssuesConfig struct {
+	EventTypes     []string `mapstructure:"eventTypes"`
+	ClearOnSuccess bool     `mapstructure:"clearOnSuccess"`
+}
+
+// SLAConfig sets the assignment SLAs, in minutes. InitialResponseMinutes
+// bounds assignment to the first action of any kind, FirstContactMinutes
+// assignment to the first action that reaches the customer, and
+// IdleActionMinutes the gap after the last action. GracePeriodSeconds is
+// subtracted from the elapsed time before it is compared with an SLA, so
+// clock skew between the API node that claimed an assignment and the
+// scheduler evaluating it does not breach borderline cases. Zero keeps the
+// default.
+type SLAConfig struct {
+	InitialResponseMinutes int `mapstructure:"initialResponseMinutes"`
+	FirstContactMinutes    int `mapstructure:"firstContactMinutes"`
+	IdleActionMinutes      int `mapstructure:"idleActionMinutes"`
+	GracePeriodSeconds     int `mapstructure:"gracePeriodSeconds"`
+}
+
+// ExposureAnalysisConfig bounds the top customers section of the exposure
+// analysis. TopCustomers caps how many customers are listed (0 keeps the
+// default); SkipTopCustomers drops the section so only the org totals are
+// computed.
+type ExposureAnalysisConfig struct {
+	TopCustomers     int  `mapstructure:"topCustomers"`
+	SkipTopCustomers bool `mapstructure:"skipTopCustomers"`
+}
+
+// InboxConfig controls which customers the billing operations inbox lists
+// as high exposure. HighExposureThreshold is the outstanding balance, in
+// minor units, from which a customer qualifies (0 keeps the default). By
+// default a customer also needs an overdue invoice; IncludeCurrentExposure
+// lists balances that are not yet due as well, under "balance_exposure".
+type InboxConfig struct {
+	HighExposureThreshold  int64 `mapstructure:"highExposureThreshold"`
+	IncludeCurrentExposure bool  `mapstructure:"includeCurrentExposure"`
+}
+
+// QueryTimeoutsConfig bounds the heavy billing operations reads, in seconds.
+// A read that runs longer is cancelled, and on Postgres the statement is
+// stopped by the server too. Zero keeps the default.
+type QueryTimeoutsConfig struct {
+	InboxSeconds            int `mapstructure:"inboxSeconds"`
+	MyWorkSeconds           int `mapstructure:"myWorkSeconds"`
+	ExposureAnalysisSeconds int `mapstructure:"exposureAnalysisSeconds"`
+}
+
+// ManualPaymentsConfig controls payments agents record by hand. A payment
+// above the invoice's amount due is rejected unless AllowOverpayment is
+// set, in which case the excess stays on the customer's account as credit.
+type ManualPaymentsConfig struct {
+	AllowOverpayment bool `mapstructure:"allowOverpayment"`
 }
 
+// TaxConfig holds the flat tax rates applied when invoices are generated.
+// A rate applies to customers whose metadata carries its country and, when
+// set, its region; a region rate wins over the country-wide one. Customers
+// matching no rate are left to the org's tax definition at finalization.
+type TaxConfig struct {
+	Rates []TaxRate `mapstructure:"rates"`
+}
+
+// TaxRate is one flat rate. Rate is a fraction (0.2 for 20%).
+type TaxRate struct {
+	Country string  `mapstructure:"country"`
+	Region  string  `mapstructure:"region"`
+	Code    string  `mapstructure:"code"`
+	Name    string  `mapstructure:"name"`
+	Rate    float64 `mapstructure:"rate"`
+}
+
+// MaxExposureTopCustomers is the largest accepted
+// exposureAnalysis.topCustomers.
+const MaxExposureTopCustomers = 100
+
 type AgingBucket struct {
 	Label   string `mapstructure:"label"`
 	MinDays int    `mapstructure:"minDays"`
@@ -191,6 +361,22 @@ func Load() Config {
 		DBConnMaxLifetime: getenvInt("DB_CONN_MAX_LIFETIME", 300),
 		DBConnMaxIdleTime: getenvInt("DB_CONN_MAX_IDLE_TIME", 60),
 
+		DBTenantScopeCheck: strings.ToLower(strings.TrimSpace(getenv("DB_TENANT_SCOPE_CHECK", defaultTenantScopeCheck(environment)))),
+
+		FinOpsPerformanceWorkers: getenvInt("FINOPS_PERFORMANCE_WORKERS", 4),
+		FinOpsScoringDayOffset:   max(getenvInt("FINOPS_SCORING_DAY_OFFSET", 1), 1),
+		FinOpsScoringBatchSize:   max(getenvInt("FINOPS_SCORING_BATCH_SIZE", 200), 0),
+
+		BillingOpsActionMetadataMaxBytes: getenvInt("BILLING_OPS_ACTION_METADATA_MAX_BYTES", 16*1024),
+		CustomerEmailLowercase:           getenvBool("CUSTOMER_EMAIL_LOWERCASE", true),
+		BillingOpsIncludeCustomerPhone:   getenvBool("BILLING_OPS_INCLUDE_CUSTOMER_PHONE", true),
+		BillingOpsConflictShowAssignee:   getenvBool("BILLING_OPS_CONFLICT_SHOW_ASSIGNEE", true),
+		BillingOpsBreachWebhookURL:       strings.TrimSpace(getenv("BILLING_OPS_BREACH_WEBHOOK_URL", "")),
+		BillingOpsAuditRetries:           max(getenvInt("BILLING_OPS_AUDIT_RETRIES", 2), 0),
+		BillingOpsAuditRequired:          parseList(getenv("BILLING_OPS_AUDIT_REQUIRED", "financial")),
+		BillingOpsArchiveActions:         getenvBool("BILLING_OPS_ARCHIVE_ACTIONS", true),
+		BillingOpsARDriftThreshold:       max(getenvInt64("BILLING_OPS_AR_DRIFT_THRESHOLD", 0), 0),
+
 		// OAuth2 settings
 		OAuth2ClientID:     strings.TrimSpace(getenv("OAUTH2_CLIENT_ID", "")),
 		OAuth2ClientSecret: strings.TrimSpace(getenv("OAUTH2_CLIENT_SECRET", "")),
@@ -214,6 +400,8 @@ func Load() Config {
 			SMTPUsername: getenv("SMTP_USERNAME", ""),
 			SMTPPassword: getenv("SMTP_PASSWORD", ""),
 			SMTPFrom:     getenv("SMTP_FROM", "no-reply@railzway.test"),
+
+			WebhookSecret: getenv("EMAIL_WEBHOOK_SECRET", ""),
 		},
 
 		Logger: LoggerConfig{
@@ -232,6 +420,15 @@ const (
 	ModeStandalone = "standalone"
 )
 
+// defaultTenantScopeCheck fails unscoped tenant queries everywhere except
+// production, where they are only logged.
+func defaultTenantScopeCheck(environment string) string {
+	if environment == "production" {
+		return "warn"
+	}
+	return "strict"
+}
+
 func (c Config) IsCloud() bool {
 	return c.Mode == ModeCloud
 }
@@ -349,6 +546,18 @@ func loadOrCreateInstanceID() string {
 	return newID
 }
 
+// parseList splits a comma-separated value, dropping empty items.
+func parseList(raw string) []string {
+	parts := strings.Split(raw, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 func parseServices(raw string) []string {
 	parts := strings.Split(raw, ",")
 	out := make([]string, 0, len(parts))
@@ -0,0 +1,44 @@
+package domain
+
+import "strings"
+
+// Contact channels a customer can prefer to be reached on.
+const (
+	ContactChannelEmail = "email"
+	ContactChannelPhone = "phone"
+	ContactChannelSMS   = "sms"
+)
+
+// NormalizeContactChannel returns the stored form of a preferred contact
+// channel and whether it is one of the known channels. An empty channel is
+// valid and means no preference.
+func NormalizeContactChannel(channel string) (string, bool) {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	switch channel {
+	case "", ContactChannelEmail, ContactChannelPhone, ContactChannelSMS:
+		return channel, true
+	default:
+		return "", false
+	}
+}
+
+// NormalizePhone trims a phone number and reports whether it looks dialable:
+// digits with optional +, spaces, dots, dashes and parentheses, and at least
+// six digits. An empty phone is valid.
+func NormalizePhone(phone string) (string, bool) {
+	phone = strings.TrimSpace(phone)
+	digits := 0
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' || r == ' ' || r == '.' || r == '-' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	if phone != "" && digits < 6 {
+		return "", false
+	}
+	return phone, true
+}
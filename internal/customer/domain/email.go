@@ -0,0 +1,14 @@
+package domain
+
+import "strings"
+
+// NormalizeEmail returns the form customer emails are stored and matched in:
+// surrounding whitespace is trimmed and, when lowercase is set, the address
+// is folded to lower case so that dedup and contact lookups ignore case.
+func NormalizeEmail(email string, lowercase bool) string {
+	email = strings.TrimSpace(email)
+	if lowercase {
+		email = strings.ToLower(email)
+	}
+	return email
+}
@@ -8,12 +8,16 @@ import (
 )
 
 type Customer struct {
-	ID        snowflake.ID      `gorm:"primaryKey" json:"id"`
-	OrgID     snowflake.ID      `gorm:"not null;index" json:"organization_id"`
-	Name      string            `gorm:"not null" json:"name"`
-	Email     string            `gorm:"not null" json:"email"`
-	Currency  string            `gorm:"column:currency" json:"currency,omitempty"`
-	Metadata  datatypes.JSONMap `gorm:"type:jsonb;not null;default:'{}'" json:"metadata,omitempty"`
-	CreatedAt time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID                      snowflake.ID      `gorm:"primaryKey" json:"id"`
+	OrgID                   snowflake.ID      `gorm:"not null;index" json:"organization_id"`
+	Name                    string            `gorm:"not null" json:"name"`
+	Email                   string            `gorm:"not null" json:"email"`
+	Currency                string            `gorm:"column:currency" json:"currency,omitempty"`
+	Phone                   string            `gorm:"column:phone" json:"phone,omitempty"`
+	PreferredContactChannel string            `gorm:"column:preferred_contact_channel" json:"preferred_contact_channel,omitempty"`
+	DoNotContact            bool              `gorm:"column:do_not_contact;not null;default:false" json:"do_not_contact"`
+	Metadata                datatypes.JSONMap `gorm:"type:jsonb;not null;default:'{}'" json:"metadata,omitempty"`
+	CreatedAt               time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt               time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt               *time.Time        `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
 }
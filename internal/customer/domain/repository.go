@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/smallbiznis/railzway/pkg/db/pagination"
@@ -12,4 +13,7 @@ type Repository interface {
 	Insert(ctx context.Context, db *gorm.DB, customer *Customer) error
 	FindByID(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID) (*Customer, error)
 	List(ctx context.Context, db *gorm.DB, orgID snowflake.ID, filter ListCustomerFilter, page pagination.Pagination) ([]*Customer, error)
+	SetDeletedAt(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID, deletedAt *time.Time, updatedAt time.Time) error
+	UpdateContact(ctx context.Context, db *gorm.DB, customer *Customer) error
+	OutstandingBalance(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID) (int64, error)
 }
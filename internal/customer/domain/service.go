@@ -32,24 +32,44 @@ type ListCustomerResponse struct {
 }
 
 type CreateCustomerRequest struct {
-	Name  string
-	Email string
+	Name                    string
+	Email                   string
+	Phone                   string
+	PreferredContactChannel string
+	DoNotContact            bool
 }
 
 type GetCustomerRequest struct {
 	ID string
 }
 
+// UpdateCustomerContactRequest changes how a customer may be contacted. Nil
+// fields are left as they are.
+type UpdateCustomerContactRequest struct {
+	Phone                   *string
+	PreferredContactChannel *string
+	DoNotContact            *bool
+}
+
 type Service interface {
 	Create(context.Context, CreateCustomerRequest) (Customer, error)
 	List(context.Context, ListCustomerRequest) (ListCustomerResponse, error)
 	GetByID(context.Context, GetCustomerRequest) (Customer, error)
+	SoftDeleteCustomer(ctx context.Context, customerID string, force bool) (Customer, error)
+	RestoreCustomer(ctx context.Context, customerID string) (Customer, error)
+	UpdateContact(ctx context.Context, customerID string, req UpdateCustomerContactRequest) (Customer, error)
 }
 
 var (
-	ErrInvalidOrganization = errors.New("invalid_organization")
-	ErrInvalidName         = errors.New("invalid_name")
-	ErrInvalidEmail        = errors.New("invalid_email")
-	ErrInvalidID           = errors.New("invalid_id")
-	ErrNotFound            = errors.New("not_found")
+	ErrInvalidOrganization   = errors.New("invalid_organization")
+	ErrInvalidName           = errors.New("invalid_name")
+	ErrInvalidEmail          = errors.New("invalid_email")
+	ErrInvalidPhone          = errors.New("invalid_phone")
+	ErrInvalidContactChannel = errors.New("invalid_contact_channel")
+	ErrInvalidID             = errors.New("invalid_id")
+	ErrNotFound              = errors.New("not_found")
+
+	// ErrCustomerHasOutstanding blocks soft deletion of a customer that still
+	// owes money unless the caller forces it.
+	ErrCustomerHasOutstanding = errors.New("customer_has_outstanding")
 )
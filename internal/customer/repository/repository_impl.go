@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/bwmarrin/snowflake"
+	billingopsrepo "github.com/smallbiznis/railzway/internal/billingoperations/repository"
 	"github.com/smallbiznis/railzway/internal/customer/domain"
 	"github.com/smallbiznis/railzway/pkg/db/option"
 	"github.com/smallbiznis/railzway/pkg/db/pagination"
@@ -18,13 +21,16 @@ func Provide() domain.Repository {
 
 func (r *repo) Insert(ctx context.Context, db *gorm.DB, customer *domain.Customer) error {
 	return db.WithContext(ctx).Exec(
-		`INSERT INTO customers (id, org_id, name, email, currency, metadata, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO customers (id, org_id, name, email, currency, phone, preferred_contact_channel, do_not_contact, metadata, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?)`,
 		customer.ID,
 		customer.OrgID,
 		customer.Name,
 		customer.Email,
 		customer.Currency,
+		customer.Phone,
+		customer.PreferredContactChannel,
+		customer.DoNotContact,
 		customer.Metadata,
 		customer.CreatedAt,
 		customer.UpdatedAt,
@@ -34,7 +40,10 @@ func (r *repo) Insert(ctx context.Context, db *gorm.DB, customer *domain.Custome
 func (r *repo) FindByID(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID) (*domain.Customer, error) {
 	var customer domain.Customer
 	err := db.WithContext(ctx).Raw(
-		`SELECT id, org_id, name, email, currency, metadata, created_at, updated_at
+		`SELECT id, org_id, name, email, currency,
+		        COALESCE(phone, '') AS phone,
+		        COALESCE(preferred_contact_channel, '') AS preferred_contact_channel,
+		        do_not_contact, metadata, created_at, updated_at, deleted_at
 		 FROM customers WHERE org_id = ? AND id = ?`,
 		orgID,
 		id,
@@ -52,7 +61,8 @@ func (r *repo) List(ctx context.Context, db *gorm.DB, orgID snowflake.ID, filter
 	var customers []*domain.Customer
 	stmt := db.WithContext(ctx).
 		Model(&domain.Customer{}).
-		Where("org_id = ?", orgID)
+		Where("org_id = ?", orgID).
+		Where("deleted_at IS NULL")
 	if filter.Name != "" {
 		stmt = stmt.Where("name = ?", filter.Name)
 	}
@@ -77,3 +87,60 @@ func (r *repo) List(ctx context.Context, db *gorm.DB, orgID snowflake.ID, filter
 	}
 	return customers, nil
 }
+
+func (r *repo) SetDeletedAt(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID, deletedAt *time.Time, updatedAt time.Time) error {
+	return db.WithContext(ctx).Exec(
+		`UPDATE customers SET deleted_at = ?, updated_at = ? WHERE org_id = ? AND id = ?`,
+		deletedAt,
+		updatedAt,
+		orgID,
+		id,
+	).Error
+}
+
+func (r *repo) UpdateContact(ctx context.Context, db *gorm.DB, customer *domain.Customer) error {
+	return db.WithContext(ctx).Exec(
+		`UPDATE customers
+		 SET phone = NULLIF(?, ''), preferred_contact_channel = NULLIF(?, ''), do_not_contact = ?, updated_at = ?
+		 WHERE org_id = ? AND id = ?`,
+		customer.Phone,
+		customer.PreferredContactChannel,
+		customer.DoNotContact,
+		customer.UpdatedAt,
+		customer.OrgID,
+		customer.ID,
+	).Error
+}
+
+// OutstandingBalance sums what the customer still owes on finalized, unpaid
+// invoices after the payments and credit notes the ledger has settled
+// against them, the same figure billing operations reports.
+func (r *repo) OutstandingBalance(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID) (int64, error) {
+	settled, settledArgs, err := billingopsrepo.SettledAmountCTE(ctx, db, orgID, "")
+	if err != nil {
+		return 0, err
+	}
+
+	var outstanding int64
+	err = db.WithContext(ctx).Raw(
+		fmt.Sprintf(`SELECT COALESCE(SUM(
+			CASE WHEN i.total_amount > COALESCE(s.settled_amount, 0)
+				THEN i.total_amount - COALESCE(s.settled_amount, 0)
+				ELSE 0
+			END
+		 ), 0)
+		 FROM invoices i
+		 LEFT JOIN (%s
+		 ) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+		 WHERE i.org_id = ?
+		   AND i.customer_id = ?
+		   AND i.status = 'FINALIZED'
+		   AND i.voided_at IS NULL
+		   AND i.paid_at IS NULL`, settled),
+		append(settledArgs,
+			orgID,
+			id,
+		)...,
+	).Scan(&outstanding).Error
+	return outstanding, err
+}
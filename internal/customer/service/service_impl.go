@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/config"
 	"github.com/smallbiznis/railzway/internal/customer/domain"
 	"github.com/smallbiznis/railzway/internal/orgcontext"
 	"github.com/smallbiznis/railzway/pkg/db/pagination"
@@ -22,6 +23,7 @@ type Params struct {
 	Log   *zap.Logger
 	GenID *snowflake.Node
 	Repo  domain.Repository
+	Cfg   config.Config
 }
 
 type Service struct {
@@ -29,6 +31,8 @@ type Service struct {
 	log   *zap.Logger
 	genID *snowflake.Node
 	repo  domain.Repository
+
+	lowercaseEmail bool
 }
 
 func New(p Params) domain.Service {
@@ -37,6 +41,8 @@ func New(p Params) domain.Service {
 		log:   p.Log.Named("customer.service"),
 		genID: p.GenID,
 		repo:  p.Repo,
+
+		lowercaseEmail: p.Cfg.CustomerEmailLowercase,
 	}
 }
 
@@ -51,20 +57,36 @@ func (s *Service) Create(ctx context.Context, req domain.CreateCustomerRequest)
 		return domain.Customer{}, domain.ErrInvalidName
 	}
 
-	email := strings.TrimSpace(req.Email)
+	email := domain.NormalizeEmail(req.Email, s.lowercaseEmail)
 	if email == "" || !strings.Contains(email, "@") {
 		return domain.Customer{}, domain.ErrInvalidEmail
 	}
 
+	phone, ok := domain.NormalizePhone(req.Phone)
+	if !ok {
+		return domain.Customer{}, domain.ErrInvalidPhone
+	}
+
+	channel, ok := domain.NormalizeContactChannel(req.PreferredContactChannel)
+	if !ok {
+		return domain.Customer{}, domain.ErrInvalidContactChannel
+	}
+	if (channel == domain.ContactChannelPhone || channel == domain.ContactChannelSMS) && phone == "" {
+		return domain.Customer{}, domain.ErrInvalidContactChannel
+	}
+
 	now := time.Now().UTC()
 	customer := domain.Customer{
-		ID:        s.genID.Generate(),
-		OrgID:     orgID,
-		Name:      name,
-		Email:     email,
-		Metadata:  datatypes.JSONMap{},
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:                      s.genID.Generate(),
+		OrgID:                   orgID,
+		Name:                    name,
+		Email:                   email,
+		Phone:                   phone,
+		PreferredContactChannel: channel,
+		DoNotContact:            req.DoNotContact,
+		Metadata:                datatypes.JSONMap{},
+		CreatedAt:               now,
+		UpdatedAt:               now,
 	}
 
 	if err := s.repo.Insert(ctx, s.db, &customer); err != nil {
@@ -82,7 +104,7 @@ func (s *Service) List(ctx context.Context, req domain.ListCustomerRequest) (dom
 
 	filter := domain.ListCustomerFilter{
 		Name:        strings.TrimSpace(req.Name),
-		Email:       strings.TrimSpace(req.Email),
+		Email:       domain.NormalizeEmail(req.Email, s.lowercaseEmail),
 		Currency:    strings.TrimSpace(req.Currency),
 		CreatedFrom: req.CreatedFrom,
 		CreatedTo:   req.CreatedTo,
@@ -153,6 +175,143 @@ func (s *Service) GetByID(ctx context.Context, req domain.GetCustomerRequest) (d
 	return *item, nil
 }
 
+// SoftDeleteCustomer hides a customer from listings and billing operations
+// queues without removing its billing history. Customers with an outstanding
+// balance are refused with ErrCustomerHasOutstanding unless force is set.
+// Deleting an already deleted customer is a no-op.
+func (s *Service) SoftDeleteCustomer(ctx context.Context, customerID string, force bool) (domain.Customer, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.Customer{}, domain.ErrInvalidOrganization
+	}
+
+	id, err := s.parseID(customerID)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+
+	var customer domain.Customer
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		item, err := s.repo.FindByID(ctx, tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return domain.ErrNotFound
+		}
+		customer = *item
+		if customer.DeletedAt != nil {
+			return nil
+		}
+
+		if !force {
+			outstanding, err := s.repo.OutstandingBalance(ctx, tx, orgID, id)
+			if err != nil {
+				return err
+			}
+			if outstanding > 0 {
+				return domain.ErrCustomerHasOutstanding
+			}
+		}
+
+		now := time.Now().UTC()
+		if err := s.repo.SetDeletedAt(ctx, tx, orgID, id, &now, now); err != nil {
+			return err
+		}
+		customer.DeletedAt = &now
+		customer.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return domain.Customer{}, err
+	}
+
+	return customer, nil
+}
+
+// RestoreCustomer clears a soft deletion. Restoring an active customer is a
+// no-op.
+func (s *Service) RestoreCustomer(ctx context.Context, customerID string) (domain.Customer, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.Customer{}, domain.ErrInvalidOrganization
+	}
+
+	id, err := s.parseID(customerID)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+
+	item, err := s.repo.FindByID(ctx, s.db, orgID, id)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+	if item == nil {
+		return domain.Customer{}, domain.ErrNotFound
+	}
+	if item.DeletedAt == nil {
+		return *item, nil
+	}
+
+	now := time.Now().UTC()
+	if err := s.repo.SetDeletedAt(ctx, s.db, orgID, id, nil, now); err != nil {
+		return domain.Customer{}, err
+	}
+	item.DeletedAt = nil
+	item.UpdatedAt = now
+	return *item, nil
+}
+
+// UpdateContact changes a customer's phone, preferred contact channel and
+// do-not-contact flag, applying the same rules as Create to the result.
+func (s *Service) UpdateContact(ctx context.Context, customerID string, req domain.UpdateCustomerContactRequest) (domain.Customer, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return domain.Customer{}, domain.ErrInvalidOrganization
+	}
+
+	id, err := s.parseID(customerID)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+
+	item, err := s.repo.FindByID(ctx, s.db, orgID, id)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+	if item == nil {
+		return domain.Customer{}, domain.ErrNotFound
+	}
+
+	customer := *item
+	if req.Phone != nil {
+		phone, ok := domain.NormalizePhone(*req.Phone)
+		if !ok {
+			return domain.Customer{}, domain.ErrInvalidPhone
+		}
+		customer.Phone = phone
+	}
+	if req.PreferredContactChannel != nil {
+		channel, ok := domain.NormalizeContactChannel(*req.PreferredContactChannel)
+		if !ok {
+			return domain.Customer{}, domain.ErrInvalidContactChannel
+		}
+		customer.PreferredContactChannel = channel
+	}
+	if (customer.PreferredContactChannel == domain.ContactChannelPhone || customer.PreferredContactChannel == domain.ContactChannelSMS) && customer.Phone == "" {
+		return domain.Customer{}, domain.ErrInvalidContactChannel
+	}
+	if req.DoNotContact != nil {
+		customer.DoNotContact = *req.DoNotContact
+	}
+
+	customer.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateContact(ctx, s.db, &customer); err != nil {
+		return domain.Customer{}, err
+	}
+	return customer, nil
+}
+
 func (s *Service) parseID(value string) (snowflake.ID, error) {
 	id, err := snowflake.ParseString(strings.TrimSpace(value))
 	if err != nil || id == 0 {
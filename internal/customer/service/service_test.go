@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/customer/domain"
+	"github.com/smallbiznis/railzway/internal/customer/repository"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newTestService(t *testing.T, cfg config.Config) domain.Service {
+	t.Helper()
+	svc, _ := newTestServiceWithDB(t, cfg)
+	return svc
+}
+
+func newTestServiceWithDB(t *testing.T, cfg config.Config) (domain.Service, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE customers (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		name TEXT NOT NULL,
+		email TEXT NOT NULL,
+		currency TEXT,
+		phone TEXT,
+		preferred_contact_channel TEXT,
+		do_not_contact BOOLEAN NOT NULL DEFAULT FALSE,
+		metadata TEXT NOT NULL DEFAULT '{}',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		deleted_at DATETIME
+	)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE invoices (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		customer_id BIGINT NOT NULL,
+		status TEXT NOT NULL,
+		currency TEXT NOT NULL DEFAULT 'USD',
+		total_amount BIGINT NOT NULL DEFAULT 0,
+		voided_at DATETIME,
+		paid_at DATETIME
+	)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE credit_notes (
+		id BIGINT PRIMARY KEY,
+		org_id BIGINT NOT NULL,
+		invoice_id BIGINT NOT NULL,
+		amount BIGINT NOT NULL
+	)`).Error)
+	for _, stmt := range []string{
+		`CREATE TABLE payment_events (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, payload TEXT NOT NULL)`,
+		`CREATE TABLE payment_allocations (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, payment_event_id BIGINT NOT NULL, invoice_id BIGINT NOT NULL)`,
+		`CREATE TABLE ledger_accounts (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, code TEXT NOT NULL)`,
+		`CREATE TABLE ledger_entries (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, source_type TEXT NOT NULL, source_id BIGINT NOT NULL, currency TEXT NOT NULL, occurred_at DATETIME)`,
+		`CREATE TABLE ledger_entry_lines (id BIGINT PRIMARY KEY, ledger_entry_id BIGINT NOT NULL, account_id BIGINT NOT NULL, direction TEXT NOT NULL, amount BIGINT NOT NULL)`,
+		`CREATE TABLE organization_billing_preferences (org_id BIGINT PRIMARY KEY, receivable_account_codes TEXT)`,
+		`INSERT INTO ledger_accounts (id, org_id, code) VALUES (1, 1, 'accounts_receivable')`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+	// The settled-amount query's Postgres JSON path and casts are rewritten
+	// to their sqlite forms.
+	sqliteSQL := strings.NewReplacer(
+		"#>> '{data,object,metadata,invoice_id}'", "->> '$.data.object.metadata.invoice_id'",
+		"i.id::text", "CAST(i.id AS TEXT)",
+		"pa.invoice_id::text", "CAST(pa.invoice_id AS TEXT)",
+		"cn.invoice_id::text", "CAST(cn.invoice_id AS TEXT)",
+	)
+	require.NoError(t, db.Callback().Row().Before("gorm:row").Register("sqlite_settled_amount_row", func(d *gorm.DB) {
+		sql := d.Statement.SQL.String()
+		if rewritten := sqliteSQL.Replace(sql); rewritten != sql {
+			d.Statement.SQL.Reset()
+			d.Statement.SQL.WriteString(rewritten)
+		}
+	}))
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	return New(Params{DB: db, Log: zap.NewNop(), GenID: node, Repo: repository.Provide(), Cfg: cfg}), db
+}
+
+func TestCreateCustomer_NormalizesEmail(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+
+	t.Run("mixed case emails collapse to one form", func(t *testing.T) {
+		svc := newTestService(t, config.Config{CustomerEmailLowercase: true})
+
+		first, err := svc.Create(ctx, domain.CreateCustomerRequest{Name: "Acme", Email: "  Billing@Acme.COM "})
+		require.NoError(t, err)
+		assert.Equal(t, "billing@acme.com", first.Email)
+
+		second, err := svc.Create(ctx, domain.CreateCustomerRequest{Name: "Acme AP", Email: "BILLING@acme.com"})
+		require.NoError(t, err)
+		assert.Equal(t, first.Email, second.Email)
+
+		stored, err := svc.GetByID(ctx, domain.GetCustomerRequest{ID: first.ID.String()})
+		require.NoError(t, err)
+		assert.Equal(t, "billing@acme.com", stored.Email)
+
+		list, err := svc.List(ctx, domain.ListCustomerRequest{Email: "Billing@ACME.com"})
+		require.NoError(t, err)
+		assert.Len(t, list.Customers, 2)
+	})
+
+	t.Run("lowercasing can be disabled", func(t *testing.T) {
+		svc := newTestService(t, config.Config{CustomerEmailLowercase: false})
+
+		customer, err := svc.Create(ctx, domain.CreateCustomerRequest{Name: "Acme", Email: " Billing@Acme.com "})
+		require.NoError(t, err)
+		assert.Equal(t, "Billing@Acme.com", customer.Email)
+	})
+}
+
+func TestCreateCustomer_ContactChannels(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	svc := newTestService(t, config.Config{CustomerEmailLowercase: true})
+
+	customer, err := svc.Create(ctx, domain.CreateCustomerRequest{
+		Name:                    "Acme",
+		Email:                   "ap@acme.com",
+		Phone:                   " +62 (21) 555-0100 ",
+		PreferredContactChannel: " SMS ",
+		DoNotContact:            true,
+	})
+	require.NoError(t, err)
+
+	stored, err := svc.GetByID(ctx, domain.GetCustomerRequest{ID: customer.ID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, "+62 (21) 555-0100", stored.Phone)
+	assert.Equal(t, domain.ContactChannelSMS, stored.PreferredContactChannel)
+	assert.True(t, stored.DoNotContact)
+
+	_, err = svc.Create(ctx, domain.CreateCustomerRequest{Name: "Acme", Email: "ap@acme.com", Phone: "call me"})
+	assert.ErrorIs(t, err, domain.ErrInvalidPhone)
+	_, err = svc.Create(ctx, domain.CreateCustomerRequest{Name: "Acme", Email: "ap@acme.com", PreferredContactChannel: "fax"})
+	assert.ErrorIs(t, err, domain.ErrInvalidContactChannel)
+	_, err = svc.Create(ctx, domain.CreateCustomerRequest{Name: "Acme", Email: "ap@acme.com", PreferredContactChannel: "phone"})
+	assert.ErrorIs(t, err, domain.ErrInvalidContactChannel, "a phone preference needs a phone number")
+}
+
+func TestSoftDeleteCustomer(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	svc, db := newTestServiceWithDB(t, config.Config{CustomerEmailLowercase: true})
+
+	customer, err := svc.Create(ctx, domain.CreateCustomerRequest{Name: "Acme", Email: "ap@acme.com"})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(
+		`INSERT INTO invoices (id, org_id, customer_id, status, total_amount) VALUES (?, ?, ?, ?, ?)`,
+		10, 1, customer.ID, "FINALIZED", 5_000,
+	).Error)
+
+	_, err = svc.SoftDeleteCustomer(ctx, customer.ID.String(), false)
+	require.ErrorIs(t, err, domain.ErrCustomerHasOutstanding)
+
+	t.Run("a partial payment leaves a balance", func(t *testing.T) {
+		require.NoError(t, db.Exec(`INSERT INTO payment_events (id, org_id, payload) VALUES (20, 1, '{"data":{"object":{"metadata":{"invoice_id":"10"}}}}')`).Error)
+		postReceivableCredit(t, db, 30, "payment", 20, 3_000)
+
+		_, err := svc.SoftDeleteCustomer(ctx, customer.ID.String(), false)
+		require.ErrorIs(t, err, domain.ErrCustomerHasOutstanding)
+	})
+
+	t.Run("payments and credit notes settle the balance", func(t *testing.T) {
+		require.NoError(t, db.Exec(`INSERT INTO credit_notes (id, org_id, invoice_id, amount) VALUES (21, 1, 10, 2000)`).Error)
+		postReceivableCredit(t, db, 31, "credit_note", 21, 2_000)
+
+		deleted, err := svc.SoftDeleteCustomer(ctx, customer.ID.String(), false)
+		require.NoError(t, err)
+		require.NotNil(t, deleted.DeletedAt)
+
+		list, err := svc.List(ctx, domain.ListCustomerRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, list.Customers)
+
+		stored, err := svc.GetByID(ctx, domain.GetCustomerRequest{ID: customer.ID.String()})
+		require.NoError(t, err)
+		assert.NotNil(t, stored.DeletedAt)
+	})
+
+	t.Run("restore brings the customer back", func(t *testing.T) {
+		restored, err := svc.RestoreCustomer(ctx, customer.ID.String())
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+
+		list, err := svc.List(ctx, domain.ListCustomerRequest{})
+		require.NoError(t, err)
+		assert.Len(t, list.Customers, 1)
+	})
+
+	t.Run("force deletes despite an outstanding balance", func(t *testing.T) {
+		require.NoError(t, db.Exec(
+			`INSERT INTO invoices (id, org_id, customer_id, status, total_amount) VALUES (?, ?, ?, ?, ?)`,
+			11, 1, customer.ID, "FINALIZED", 2_500,
+		).Error)
+
+		_, err := svc.SoftDeleteCustomer(ctx, customer.ID.String(), false)
+		require.ErrorIs(t, err, domain.ErrCustomerHasOutstanding)
+
+		deleted, err := svc.SoftDeleteCustomer(ctx, customer.ID.String(), true)
+		require.NoError(t, err)
+		assert.NotNil(t, deleted.DeletedAt)
+	})
+}
+
+// postReceivableCredit posts a ledger entry crediting accounts receivable
+// for a payment or credit note.
+func postReceivableCredit(t *testing.T, db *gorm.DB, entryID int64, sourceType string, sourceID int64, amount int64) {
+	t.Helper()
+	require.NoError(t, db.Exec(
+		`INSERT INTO ledger_entries (id, org_id, source_type, source_id, currency) VALUES (?, 1, ?, ?, 'USD')`,
+		entryID, sourceType, sourceID,
+	).Error)
+	require.NoError(t, db.Exec(
+		`INSERT INTO ledger_entry_lines (id, ledger_entry_id, account_id, direction, amount) VALUES (?, ?, 1, 'credit', ?)`,
+		entryID, entryID, amount,
+	).Error)
+}
+
+func TestUpdateContact(t *testing.T) {
+	ctx := orgcontext.WithOrgID(context.Background(), 1)
+	svc := newTestService(t, config.Config{})
+
+	customer, err := svc.Create(ctx, domain.CreateCustomerRequest{Name: "Acme", Email: "billing@acme.com", Phone: "+1 555 010 0200"})
+	require.NoError(t, err)
+
+	t.Run("do not contact can be set and cleared", func(t *testing.T) {
+		on, off := true, false
+		updated, err := svc.UpdateContact(ctx, customer.ID.String(), domain.UpdateCustomerContactRequest{DoNotContact: &on})
+		require.NoError(t, err)
+		assert.True(t, updated.DoNotContact)
+		assert.Equal(t, "+1 555 010 0200", updated.Phone)
+
+		stored, err := svc.GetByID(ctx, domain.GetCustomerRequest{ID: customer.ID.String()})
+		require.NoError(t, err)
+		assert.True(t, stored.DoNotContact)
+
+		updated, err = svc.UpdateContact(ctx, customer.ID.String(), domain.UpdateCustomerContactRequest{DoNotContact: &off})
+		require.NoError(t, err)
+		assert.False(t, updated.DoNotContact)
+	})
+
+	t.Run("phone channel needs a phone", func(t *testing.T) {
+		empty, sms := "", domain.ContactChannelSMS
+		_, err := svc.UpdateContact(ctx, customer.ID.String(), domain.UpdateCustomerContactRequest{Phone: &empty, PreferredContactChannel: &sms})
+		assert.ErrorIs(t, err, domain.ErrInvalidContactChannel)
+	})
+
+	t.Run("unknown customer", func(t *testing.T) {
+		on := true
+		_, err := svc.UpdateContact(ctx, "42", domain.UpdateCustomerContactRequest{DoNotContact: &on})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
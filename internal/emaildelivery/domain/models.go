@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// Kinds of customer email whose delivery is tracked.
+const (
+	TypeInvoice  = "invoice"
+	TypeReminder = "reminder"
+)
+
+// Delivery statuses. An email starts as sent when the provider accepts it;
+// the provider's callbacks move it on.
+const (
+	StatusSent       = "sent"
+	StatusDelivered  = "delivered"
+	StatusBounced    = "bounced"
+	StatusComplained = "complained"
+	StatusFailed     = "failed"
+)
+
+// TerminalStatuses are the statuses a delivery keeps once it has them:
+// callbacks arrive out of order, and a late delivered must not hide a
+// bounce.
+var TerminalStatuses = []string{StatusBounced, StatusComplained, StatusFailed}
+
+// EmailDelivery is one customer email handed to the email provider, with the
+// last delivery status the provider reported for it.
+type EmailDelivery struct {
+	ID         snowflake.ID  `gorm:"primaryKey"`
+	OrgID      snowflake.ID  `gorm:"column:org_id;not null"`
+	MessageID  string        `gorm:"column:message_id;not null"`
+	CustomerID *snowflake.ID `gorm:"column:customer_id"`
+	InvoiceID  *snowflake.ID `gorm:"column:invoice_id"`
+	Recipient  string        `gorm:"column:recipient;not null"`
+	Type       string        `gorm:"column:email_type;not null"`
+	Status     string        `gorm:"column:status;not null"`
+	SentAt     time.Time     `gorm:"column:sent_at;not null"`
+	StatusAt   *time.Time    `gorm:"column:status_at"`
+	CreatedAt  time.Time     `gorm:"column:created_at;not null"`
+	UpdatedAt  time.Time     `gorm:"column:updated_at;not null"`
+}
+
+// TableName sets the database table name.
+func (EmailDelivery) TableName() string { return "email_deliveries" }
+
+// NewMessageID builds the Message-ID an outgoing email is sent with, which
+// the provider echoes back in its delivery callbacks.
+func NewMessageID(id snowflake.ID) string {
+	return id.String() + "@railzway"
+}
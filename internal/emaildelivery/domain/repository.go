@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Insert(ctx context.Context, db *gorm.DB, delivery *EmailDelivery) error
+	FindByMessageID(ctx context.Context, db *gorm.DB, messageID string) (*EmailDelivery, error)
+	// UpdateStatus sets the delivery's status unless it already has a
+	// terminal one, and reports whether it changed.
+	UpdateStatus(ctx context.Context, db *gorm.DB, messageID, status string, at time.Time) (bool, error)
+}
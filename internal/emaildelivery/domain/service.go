@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+type RecordSentRequest struct {
+	OrgID      snowflake.ID
+	MessageID  string
+	CustomerID *snowflake.ID
+	InvoiceID  *snowflake.ID
+	Recipient  string
+	Type       string
+}
+
+type Service interface {
+	// RecordSent stores a delivery for an email the provider just accepted.
+	RecordSent(ctx context.Context, req RecordSentRequest) error
+	// RecordDeliveryStatus applies a status from the email provider's
+	// callback to the delivery sent as messageID.
+	RecordDeliveryStatus(ctx context.Context, messageID, status string) error
+}
+
+var (
+	ErrInvalidMessageID = errors.New("invalid_message_id")
+	ErrInvalidStatus    = errors.New("invalid_delivery_status")
+	ErrInvalidType      = errors.New("invalid_email_type")
+	ErrNotFound         = errors.New("email_delivery_not_found")
+)
@@ -0,0 +1,12 @@
+package emaildelivery
+
+import (
+	"github.com/smallbiznis/railzway/internal/emaildelivery/repository"
+	"github.com/smallbiznis/railzway/internal/emaildelivery/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("emaildelivery.service",
+	fx.Provide(repository.Provide),
+	fx.Provide(service.NewService),
+)
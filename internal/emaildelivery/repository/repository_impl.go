@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/smallbiznis/railzway/internal/emaildelivery/domain"
+	"gorm.io/gorm"
+)
+
+type repo struct{}
+
+func Provide() domain.Repository {
+	return &repo{}
+}
+
+func (r *repo) Insert(ctx context.Context, db *gorm.DB, delivery *domain.EmailDelivery) error {
+	return db.WithContext(ctx).Exec(
+		`INSERT INTO email_deliveries (
+			id, org_id, message_id, customer_id, invoice_id, recipient, email_type, status, sent_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		delivery.ID,
+		delivery.OrgID,
+		delivery.MessageID,
+		delivery.CustomerID,
+		delivery.InvoiceID,
+		delivery.Recipient,
+		delivery.Type,
+		delivery.Status,
+		delivery.SentAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	).Error
+}
+
+func (r *repo) FindByMessageID(ctx context.Context, db *gorm.DB, messageID string) (*domain.EmailDelivery, error) {
+	var delivery domain.EmailDelivery
+	err := db.WithContext(ctx).Raw(
+		`SELECT id, org_id, message_id, customer_id, invoice_id, recipient, email_type, status, sent_at, status_at, created_at, updated_at
+		 FROM email_deliveries
+		 WHERE message_id = ?`,
+		messageID,
+	).Scan(&delivery).Error
+	if err != nil {
+		return nil, err
+	}
+	if delivery.ID == 0 {
+		return nil, nil
+	}
+	return &delivery, nil
+}
+
+func (r *repo) UpdateStatus(ctx context.Context, db *gorm.DB, messageID, status string, at time.Time) (bool, error) {
+	result := db.WithContext(ctx).Exec(
+		`UPDATE email_deliveries
+		 SET status = ?, status_at = ?, updated_at = ?
+		 WHERE message_id = ?
+		   AND status NOT IN ?`,
+		status,
+		at,
+		at,
+		messageID,
+		domain.TerminalStatuses,
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/emaildelivery/domain"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Params struct {
+	fx.In
+
+	DB    *gorm.DB
+	Log   *zap.Logger
+	GenID *snowflake.Node
+	Clock clock.Clock
+	Repo  domain.Repository
+}
+
+type Service struct {
+	db    *gorm.DB
+	log   *zap.Logger
+	genID *snowflake.Node
+	clock clock.Clock
+	repo  domain.Repository
+}
+
+func NewService(p Params) domain.Service {
+	return &Service{
+		db:    p.DB,
+		log:   p.Log.Named("emaildelivery.service"),
+		genID: p.GenID,
+		clock: p.Clock,
+		repo:  p.Repo,
+	}
+}
+
+func (s *Service) RecordSent(ctx context.Context, req domain.RecordSentRequest) error {
+	messageID := strings.TrimSpace(req.MessageID)
+	if messageID == "" {
+		return domain.ErrInvalidMessageID
+	}
+	if req.Type != domain.TypeInvoice && req.Type != domain.TypeReminder {
+		return domain.ErrInvalidType
+	}
+
+	now := s.clock.Now().UTC()
+	delivery := &domain.EmailDelivery{
+		ID:         s.genID.Generate(),
+		OrgID:      req.OrgID,
+		MessageID:  messageID,
+		CustomerID: req.CustomerID,
+		InvoiceID:  req.InvoiceID,
+		Recipient:  strings.TrimSpace(req.Recipient),
+		Type:       req.Type,
+		Status:     domain.StatusSent,
+		SentAt:     now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.repo.Insert(ctx, s.db, delivery); err != nil {
+		return err
+	}
+
+	obsmetrics.Email().IncSent(req.Type)
+	return nil
+}
+
+// RecordDeliveryStatus is called for every provider callback, and providers
+// retry them, so a status the delivery already has is not counted again.
+// Terminal statuses are kept: a callback arriving after a bounce, complaint
+// or failure is ignored.
+func (s *Service) RecordDeliveryStatus(ctx context.Context, messageID, status string) error {
+	messageID = strings.Trim(strings.TrimSpace(messageID), "<>")
+	if messageID == "" {
+		return domain.ErrInvalidMessageID
+	}
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch status {
+	case domain.StatusDelivered, domain.StatusBounced, domain.StatusComplained, domain.StatusFailed:
+	default:
+		return domain.ErrInvalidStatus
+	}
+
+	delivery, err := s.repo.FindByMessageID(ctx, s.db, messageID)
+	if err != nil {
+		return err
+	}
+	if delivery == nil {
+		return domain.ErrNotFound
+	}
+	if delivery.Status == status || slices.Contains(domain.TerminalStatuses, delivery.Status) {
+		return nil
+	}
+
+	updated, err := s.repo.UpdateStatus(ctx, s.db, messageID, status, s.clock.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// A terminal status landed since the delivery was read.
+		return nil
+	}
+
+	obsmetrics.Email().IncDeliveryStatus(delivery.Type, status)
+	if status == domain.StatusBounced {
+		s.log.Info("customer email bounced",
+			zap.String("org_id", delivery.OrgID.String()),
+			zap.String("message_id", messageID),
+			zap.String("email_type", delivery.Type),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/emaildelivery/domain"
+	"github.com/smallbiznis/railzway/internal/emaildelivery/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestRecordDeliveryStatus(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.EmailDelivery{}))
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	clk := clock.NewFakeClock(time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC))
+	repo := repository.Provide()
+	svc := NewService(Params{DB: db, Log: zap.NewNop(), GenID: node, Clock: clk, Repo: repo})
+	ctx := context.Background()
+
+	orgID := node.Generate()
+	customerID := node.Generate()
+	messageID := domain.NewMessageID(node.Generate())
+	require.NoError(t, svc.RecordSent(ctx, domain.RecordSentRequest{
+		OrgID:      orgID,
+		MessageID:  messageID,
+		CustomerID: &customerID,
+		Recipient:  "ap@globex.test",
+		Type:       domain.TypeReminder,
+	}))
+
+	sent, err := repo.FindByMessageID(ctx, db, messageID)
+	require.NoError(t, err)
+	require.NotNil(t, sent)
+	assert.Equal(t, domain.StatusSent, sent.Status)
+	assert.Nil(t, sent.StatusAt)
+
+	clk.Advance(time.Hour)
+	require.NoError(t, svc.RecordDeliveryStatus(ctx, "<"+messageID+">", "Bounced"))
+	require.NoError(t, svc.RecordDeliveryStatus(ctx, messageID, domain.StatusBounced))
+
+	bounced, err := repo.FindByMessageID(ctx, db, messageID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusBounced, bounced.Status)
+	require.NotNil(t, bounced.StatusAt)
+	assert.True(t, bounced.StatusAt.Equal(clk.Now()))
+
+	// A delivered callback arriving after the bounce does not hide it.
+	clk.Advance(time.Hour)
+	require.NoError(t, svc.RecordDeliveryStatus(ctx, messageID, domain.StatusDelivered))
+	late, err := repo.FindByMessageID(ctx, db, messageID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusBounced, late.Status)
+	assert.True(t, late.StatusAt.Equal(*bounced.StatusAt))
+
+	updated, err := repo.UpdateStatus(ctx, db, messageID, domain.StatusDelivered, clk.Now())
+	require.NoError(t, err)
+	assert.False(t, updated, "the repository keeps a terminal status too")
+
+	assert.ErrorIs(t, svc.RecordDeliveryStatus(ctx, messageID, "opened"), domain.ErrInvalidStatus)
+	assert.ErrorIs(t, svc.RecordDeliveryStatus(ctx, " ", domain.StatusDelivered), domain.ErrInvalidMessageID)
+	assert.ErrorIs(t, svc.RecordDeliveryStatus(ctx, "unknown@railzway", domain.StatusDelivered), domain.ErrNotFound)
+	assert.ErrorIs(t, svc.RecordSent(ctx, domain.RecordSentRequest{OrgID: orgID, MessageID: "x@railzway", Type: "digest"}), domain.ErrInvalidType)
+}
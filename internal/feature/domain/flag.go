@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// Flags consulted by other modules. Each one turns an optional feature on for
+// an org.
+const (
+	// FlagBillingOperationsSnooze lets operators snooze inbox items.
+	FlagBillingOperationsSnooze = "billing_operations.snooze"
+	// FlagBillingOperationsAutoAssign lets the scheduler auto-assign the
+	// org's inbox to its FinOps members.
+	FlagBillingOperationsAutoAssign = "billing_operations.auto_assign"
+)
+
+// FlagService stores org-scoped feature flags that gate optional behavior.
+// A flag that was never set is off.
+type FlagService interface {
+	// IsEnabled reports whether flag is on for the org. Lookups are served
+	// from a short-lived cache; a failed lookup counts as off.
+	IsEnabled(ctx context.Context, orgID snowflake.ID, flag string) bool
+	ListFlags(ctx context.Context) ([]FlagResponse, error)
+	SetFlag(ctx context.Context, req SetFlagRequest) (*FlagResponse, error)
+}
+
+// OrgFeatureFlag is one flag stored for an organization.
+type OrgFeatureFlag struct {
+	OrgID     snowflake.ID `gorm:"column:org_id;primaryKey"`
+	Flag      string       `gorm:"column:flag;primaryKey"`
+	Enabled   bool         `gorm:"column:enabled;not null"`
+	UpdatedBy *string      `gorm:"column:updated_by"`
+	CreatedAt time.Time    `gorm:"column:created_at;not null"`
+	UpdatedAt time.Time    `gorm:"column:updated_at;not null"`
+}
+
+func (OrgFeatureFlag) TableName() string { return "org_feature_flags" }
+
+type SetFlagRequest struct {
+	Flag      string `json:"flag"`
+	Enabled   bool   `json:"enabled"`
+	UpdatedBy string `json:"-"`
+}
+
+type FlagResponse struct {
+	Flag      string    `json:"flag"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedBy *string   `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var ErrInvalidFlag = errors.New("invalid_flag")
@@ -13,4 +13,7 @@ type Repository interface {
 	List(ctx context.Context, db *gorm.DB, orgID int64, filter ListRequest) ([]Feature, error)
 	ListByIDs(ctx context.Context, db *gorm.DB, orgID int64, ids []snowflake.ID) ([]Feature, error)
 	Update(ctx context.Context, db *gorm.DB, feature *Feature) error
+
+	ListFlags(ctx context.Context, db *gorm.DB, orgID int64) ([]OrgFeatureFlag, error)
+	UpsertFlag(ctx context.Context, db *gorm.DB, flag *OrgFeatureFlag) error
 }
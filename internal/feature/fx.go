@@ -9,4 +9,5 @@ import (
 var Module = fx.Module("feature.service",
 	fx.Provide(repository.Provide),
 	fx.Provide(service.New),
+	fx.Provide(service.NewFlagService),
 )
@@ -118,3 +118,35 @@ func (r *repo) Update(ctx context.Context, db *gorm.DB, feature *domain.Feature)
 		feature.ID,
 	).Error
 }
+
+func (r *repo) ListFlags(ctx context.Context, db *gorm.DB, orgID int64) ([]domain.OrgFeatureFlag, error) {
+	var items []domain.OrgFeatureFlag
+	err := db.WithContext(ctx).Raw(
+		`SELECT org_id, flag, enabled, updated_by, created_at, updated_at
+		 FROM org_feature_flags WHERE org_id = ?
+		 ORDER BY flag ASC`,
+		orgID,
+	).Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *repo) UpsertFlag(ctx context.Context, db *gorm.DB, flag *domain.OrgFeatureFlag) error {
+	if flag == nil {
+		return gorm.ErrInvalidData
+	}
+	return db.WithContext(ctx).Exec(
+		`INSERT INTO org_feature_flags (org_id, flag, enabled, updated_by, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (org_id, flag) DO UPDATE
+		 SET enabled = EXCLUDED.enabled, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`,
+		flag.OrgID,
+		flag.Flag,
+		flag.Enabled,
+		flag.UpdatedBy,
+		flag.CreatedAt,
+		flag.UpdatedAt,
+	).Error
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/feature/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// flagCacheTTL is how long an org's flags are served from memory before
+// they are read again. A change made on another replica shows up within it.
+const flagCacheTTL = 30 * time.Second
+
+var flagNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_.]{0,63}$`)
+
+type FlagParams struct {
+	fx.In
+
+	DB    *gorm.DB
+	Log   *zap.Logger
+	Clock clock.Clock
+	Repo  domain.Repository
+}
+
+type FlagService struct {
+	db    *gorm.DB
+	log   *zap.Logger
+	clock clock.Clock
+	repo  domain.Repository
+
+	mu    sync.Mutex
+	cache map[snowflake.ID]flagCacheEntry
+}
+
+type flagCacheEntry struct {
+	flags     map[string]bool
+	expiresAt time.Time
+}
+
+func NewFlagService(p FlagParams) domain.FlagService {
+	return &FlagService{
+		db:    p.DB,
+		log:   p.Log.Named("feature.flags"),
+		clock: p.Clock,
+		repo:  p.Repo,
+		cache: make(map[snowflake.ID]flagCacheEntry),
+	}
+}
+
+func (s *FlagService) IsEnabled(ctx context.Context, orgID snowflake.ID, flag string) bool {
+	if orgID == 0 {
+		return false
+	}
+	flag = strings.TrimSpace(flag)
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[orgID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.flags[flag]
+	}
+
+	items, err := s.repo.ListFlags(ctx, s.db, int64(orgID))
+	if err != nil {
+		s.log.Warn("failed to load feature flags", zap.String("org_id", orgID.String()), zap.Error(err))
+		return false
+	}
+	flags := make(map[string]bool, len(items))
+	for _, item := range items {
+		flags[item.Flag] = item.Enabled
+	}
+
+	s.mu.Lock()
+	s.cache[orgID] = flagCacheEntry{flags: flags, expiresAt: now.Add(flagCacheTTL)}
+	s.mu.Unlock()
+	return flags[flag]
+}
+
+func (s *FlagService) ListFlags(ctx context.Context) ([]domain.FlagResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, domain.ErrInvalidOrganization
+	}
+
+	items, err := s.repo.ListFlags(ctx, s.db, int64(orgID))
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]domain.FlagResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toFlagResponse(item))
+	}
+	return resp, nil
+}
+
+func (s *FlagService) SetFlag(ctx context.Context, req domain.SetFlagRequest) (*domain.FlagResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, domain.ErrInvalidOrganization
+	}
+
+	flag := strings.TrimSpace(req.Flag)
+	if !flagNamePattern.MatchString(flag) {
+		return nil, domain.ErrInvalidFlag
+	}
+
+	updatedBy := strings.TrimSpace(req.UpdatedBy)
+	if updatedBy == "" {
+		_, actorID := auditcontext.ActorFromContext(ctx)
+		updatedBy = strings.TrimSpace(actorID)
+	}
+	var updatedByPtr *string
+	if updatedBy != "" {
+		updatedByPtr = &updatedBy
+	}
+
+	now := s.clock.Now().UTC()
+	record := domain.OrgFeatureFlag{
+		OrgID:     orgID,
+		Flag:      flag,
+		Enabled:   req.Enabled,
+		UpdatedBy: updatedByPtr,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.UpsertFlag(ctx, s.db, &record); err != nil {
+		return nil, err
+	}
+
+	// Drop the cached flags so this replica sees the change right away.
+	s.mu.Lock()
+	delete(s.cache, orgID)
+	s.mu.Unlock()
+
+	resp := toFlagResponse(record)
+	return &resp, nil
+}
+
+func toFlagResponse(f domain.OrgFeatureFlag) domain.FlagResponse {
+	return domain.FlagResponse{
+		Flag:      f.Flag,
+		Enabled:   f.Enabled,
+		UpdatedBy: f.UpdatedBy,
+		UpdatedAt: f.UpdatedAt,
+	}
+}
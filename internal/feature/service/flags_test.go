@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/feature/domain"
+	"github.com/smallbiznis/railzway/internal/feature/repository"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestFlagService(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE org_feature_flags (
+		org_id BIGINT NOT NULL,
+		flag TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT false,
+		updated_by TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (org_id, flag)
+	)`).Error)
+
+	clk := clock.NewFakeClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	svc := NewFlagService(FlagParams{DB: db, Log: zap.NewNop(), Clock: clk, Repo: repository.Provide()})
+	orgID := snowflake.ID(1)
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	assert.False(t, svc.IsEnabled(ctx, orgID, "billing_ops.snooze"), "unset flags are off")
+
+	resp, err := svc.SetFlag(ctx, domain.SetFlagRequest{Flag: "billing_ops.snooze", Enabled: true, UpdatedBy: "42"})
+	require.NoError(t, err)
+	assert.True(t, resp.Enabled)
+	assert.True(t, svc.IsEnabled(ctx, orgID, "billing_ops.snooze"), "a change on this replica is seen at once")
+	assert.False(t, svc.IsEnabled(ctx, snowflake.ID(2), "billing_ops.snooze"), "flags are per org")
+
+	// A change made elsewhere shows up once the cache expires.
+	require.NoError(t, db.Exec(`UPDATE org_feature_flags SET enabled = false WHERE org_id = ?`, orgID).Error)
+	assert.True(t, svc.IsEnabled(ctx, orgID, "billing_ops.snooze"))
+	clk.Advance(flagCacheTTL)
+	assert.False(t, svc.IsEnabled(ctx, orgID, "billing_ops.snooze"))
+
+	flags, err := svc.ListFlags(ctx)
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, "billing_ops.snooze", flags[0].Flag)
+
+	for _, flag := range []string{"", "Snooze", "has space", "1st"} {
+		_, err := svc.SetFlag(ctx, domain.SetFlagRequest{Flag: flag, Enabled: true})
+		assert.ErrorIs(t, err, domain.ErrInvalidFlag, flag)
+	}
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// CarryForward holds a billing cycle's subtotal that fell below the org's
+// minimum invoice amount. No invoice is generated for the cycle; rating adds
+// the amount to the subscription's next cycle instead and records that
+// cycle in AppliedBillingCycleID.
+type CarryForward struct {
+	ID                    snowflake.ID  `json:"id" gorm:"primaryKey"`
+	OrgID                 snowflake.ID  `json:"org_id" gorm:"not null"`
+	SubscriptionID        snowflake.ID  `json:"subscription_id" gorm:"not null"`
+	SourceBillingCycleID  snowflake.ID  `json:"source_billing_cycle_id" gorm:"not null;uniqueIndex"`
+	Currency              string        `json:"currency" gorm:"type:text;not null"`
+	Amount                int64         `json:"amount" gorm:"not null"`
+	AppliedBillingCycleID *snowflake.ID `json:"applied_billing_cycle_id,omitempty"`
+	AppliedAt             *time.Time    `json:"applied_at,omitempty"`
+	CreatedAt             time.Time     `json:"created_at" gorm:"not null"`
+}
+
+// TableName sets the database table name.
+func (CarryForward) TableName() string { return "invoice_carry_forwards" }
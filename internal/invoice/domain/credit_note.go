@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// CreditNote reduces the outstanding balance of a finalized invoice without
+// voiding it. Each credit note is posted to the ledger as SourceTypeCreditNote.
+type CreditNote struct {
+	ID         snowflake.ID `json:"id" gorm:"primaryKey"`
+	OrgID      snowflake.ID `json:"org_id" gorm:"not null;index"`
+	InvoiceID  snowflake.ID `json:"invoice_id" gorm:"not null;index"`
+	CustomerID snowflake.ID `json:"customer_id" gorm:"not null"`
+	Amount     int64        `json:"amount" gorm:"not null"`
+	Currency   string       `json:"currency" gorm:"type:text;not null"`
+	Reason     *string      `json:"reason,omitempty" gorm:"type:text"`
+	IssuedAt   time.Time    `json:"issued_at" gorm:"not null"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName sets the database table name.
+func (CreditNote) TableName() string { return "credit_notes" }
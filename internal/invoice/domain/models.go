@@ -43,7 +43,9 @@ type Invoice struct {
 	VoidedAt          *time.Time        `gorm:""`
 	RenderedHTML      *string           `gorm:"column:rendered_html;type:text"`
 	RenderedPDFURL    *string           `gorm:"column:rendered_pdf_url;type:text"`
+	TemplateSnapshot  datatypes.JSON    `gorm:"column:template_snapshot;type:jsonb" json:"-"`
 	Metadata          datatypes.JSONMap `gorm:"type:jsonb;not null;default:'{}'"`
+	IdempotencyKey    *string           `gorm:"column:idempotency_key;type:text"`
 	CreatedAt         time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt         time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP"`
 	
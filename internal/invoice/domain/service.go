@@ -38,13 +38,65 @@ type RenderInvoiceResponse struct {
 	IsSnapshot        bool    `json:"is_snapshot"`
 }
 
+// GenerateInvoiceResult is returned by GenerateInvoice. Duplicate is set when
+// the idempotency key or billing cycle already produced an invoice; Invoice
+// is then the existing invoice rather than a new one. When the cycle's
+// subtotal was below the org's minimum invoice amount, Invoice is nil and
+// CarryForward holds the amount moved to the next cycle.
+type GenerateInvoiceResult struct {
+	Invoice      *Invoice
+	CarryForward *CarryForward
+	Duplicate    bool
+}
+
+// MaxIdempotencyKeyLength bounds caller-supplied invoice generation keys.
+const MaxIdempotencyKeyLength = 255
+
+// GenerationIdempotencyKey is the deterministic key used to generate the
+// invoice for a billing cycle. An empty key passed to GenerateInvoice falls
+// back to it.
+func GenerationIdempotencyKey(billingCycleID snowflake.ID) string {
+	return "billing_cycle:" + billingCycleID.String()
+}
+
+// InvoicePreview is the invoice GenerateInvoice would create for a billing
+// cycle, computed from its rating results without persisting anything.
+// Amounts are in the currency's minor units.
+type InvoicePreview struct {
+	BillingCycleID string               `json:"billing_cycle_id"`
+	SubscriptionID string               `json:"subscription_id"`
+	CustomerID     string               `json:"customer_id"`
+	Currency       string               `json:"currency"`
+	SubtotalAmount int64                `json:"subtotal_amount"`
+	TaxAmount      int64                `json:"tax_amount"`
+	TotalAmount    int64                `json:"total_amount"`
+	PeriodStart    time.Time            `json:"period_start"`
+	PeriodEnd      time.Time            `json:"period_end"`
+	Items          []InvoicePreviewItem `json:"items"`
+}
+
+// InvoicePreviewItem is one would-be invoice line.
+type InvoicePreviewItem struct {
+	RatingResultID string              `json:"rating_result_id,omitempty"`
+	LineType       InvoiceItemLineType `json:"line_type"`
+	Description    string              `json:"description"`
+	Quantity       float64             `json:"quantity"`
+	UnitPrice      int64               `json:"unit_price"`
+	Amount         int64               `json:"amount"`
+}
+
 type Service interface {
 	List(context.Context, ListInvoiceRequest) (ListInvoiceResponse, error)
 	GetByID(ctx context.Context, id string) (Invoice, error)
 	RenderInvoice(ctx context.Context, invoiceID string) (RenderInvoiceResponse, error)
-	GenerateInvoice(ctx context.Context, billingCycleID string) (*Invoice, error)
+	// GenerateInvoicePDF renders the invoice as a PDF, with templateID when
+	// it is set and the template resolved for the invoice otherwise.
+	GenerateInvoicePDF(ctx context.Context, invoiceID string, templateID *string) ([]byte, error)
+	GenerateInvoice(ctx context.Context, billingCycleID string, idempotencyKey string) (*GenerateInvoiceResult, error)
+	PreviewInvoice(ctx context.Context, billingCycleID string) (InvoicePreview, error)
 	FinalizeInvoice(ctx context.Context, invoiceID string) error
-	VoidInvoice(ctx context.Context, invoiceID string, reason string) error
+	VoidInvoice(ctx context.Context, invoiceID string, reason string, idempotencyKey string) error
+	IssueCreditNote(ctx context.Context, invoiceID string, amount int64, reason string, idempotencyKey string) (*CreditNote, error)
 }
 
 var (
@@ -61,5 +113,10 @@ var (
 	ErrInvoiceNotDraft         = errors.New("invoice_not_draft")
 	ErrInvoiceNotFinalized     = errors.New("invoice_not_finalized")
 	ErrInvoiceTemplateNotFound = errors.New("invoice_template_not_found")
+	ErrInvalidTemplateID       = errors.New("invalid_invoice_template_id")
 	ErrInvoiceRenderMissing    = errors.New("invoice_render_missing")
+	ErrInvalidCreditNoteAmount = errors.New("invalid_credit_note_amount")
+	ErrCreditNoteExceedsTotal  = errors.New("credit_note_exceeds_invoice_total")
+	ErrInvalidIdempotencyKey   = errors.New("invalid_idempotency_key")
+	ErrIdempotencyKeyConflict  = errors.New("idempotency_key_conflict")
 )
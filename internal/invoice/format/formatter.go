@@ -9,13 +9,23 @@ import (
 )
 
 var (
-	seqPadRe = regexp.MustCompile(`\{SEQ(\d+)\}`)
+	seqPadRe = regexp.MustCompile(`\{SEQ(\d{1,2})\}`)
 )
 
 const DefaultInvoiceNumberTemplate = "INV-{YYYY}{MM}{DD}-{SEQ6}"
 
+// MaxInvoiceNumberTemplateLength and MaxInvoiceNumberPrefixLength bound an
+// organization's invoice number format.
+const (
+	MaxInvoiceNumberTemplateLength = 64
+	MaxInvoiceNumberPrefixLength   = 20
+)
+
 // FormatInvoiceNumber formats a human-readable invoice number
-// based on a template, invoice issue time, and monotonic sequence.
+// based on a template, prefix, invoice issue time, and monotonic sequence.
+//
+// Supported tokens: {PREFIX}, {YYYY}, {YY}, {MM}, {DD}, {SEQ} and {SEQn},
+// where n is the zero-padded width of the sequence.
 //
 // This function is PURE:
 // - No side effects
@@ -23,6 +33,7 @@ const DefaultInvoiceNumberTemplate = "INV-{YYYY}{MM}{DD}-{SEQ6}"
 // - Fully deterministic
 func FormatInvoiceNumber(
 	template string,
+	prefix string,
 	issuedAt time.Time,
 	seq int64,
 ) (string, error) {
@@ -35,7 +46,7 @@ func FormatInvoiceNumber(
 		return "", fmt.Errorf("invalid invoice sequence: %d", seq)
 	}
 
-	out := template
+	out := strings.ReplaceAll(template, "{PREFIX}", prefix)
 
 	// Date tokens
 	out = strings.ReplaceAll(out, "{YYYY}", issuedAt.Format("2006"))
@@ -68,3 +79,23 @@ func FormatInvoiceNumber(
 
 	return out, nil
 }
+
+// ValidateInvoiceNumberFormat checks an organization's invoice number
+// template and prefix. The template must contain a sequence token so that
+// every number it produces is unique within the organization.
+func ValidateInvoiceNumberFormat(template, prefix string) error {
+	if len(template) > MaxInvoiceNumberTemplateLength {
+		return fmt.Errorf("invoice number template exceeds %d characters", MaxInvoiceNumberTemplateLength)
+	}
+	if !strings.Contains(template, "{SEQ}") && !seqPadRe.MatchString(template) {
+		return fmt.Errorf("invoice number template has no sequence token")
+	}
+	if len(prefix) > MaxInvoiceNumberPrefixLength {
+		return fmt.Errorf("invoice number prefix exceeds %d characters", MaxInvoiceNumberPrefixLength)
+	}
+	if strings.ContainsAny(prefix, "{}") {
+		return fmt.Errorf("invoice number prefix contains a token")
+	}
+	_, err := FormatInvoiceNumber(template, prefix, time.Now(), 1)
+	return err
+}
@@ -0,0 +1,55 @@
+package format
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatInvoiceNumber(t *testing.T) {
+	issuedAt := time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		template string
+		prefix   string
+		want     string
+	}{
+		{template: DefaultInvoiceNumberTemplate, want: "INV-20240307-000123"},
+		{template: "{PREFIX}-{YYYY}-{SEQ6}", prefix: "ACME", want: "ACME-2024-000123"},
+		{template: "{PREFIX}{YY}{MM}/{SEQ}", prefix: "AC", want: "AC2403/123"},
+	}
+	for _, tc := range cases {
+		got, err := FormatInvoiceNumber(tc.template, tc.prefix, issuedAt, 123)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.template, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.template, tc.want, got)
+		}
+	}
+}
+
+func TestValidateInvoiceNumberFormat(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		prefix   string
+		valid    bool
+	}{
+		{name: "prefix and year", template: "{PREFIX}-{YYYY}-{SEQ6}", prefix: "ACME", valid: true},
+		{name: "plain sequence", template: "{SEQ}", valid: true},
+		{name: "missing sequence", template: "{PREFIX}-{YYYY}", prefix: "ACME"},
+		{name: "unknown token", template: "{PREFIX}-{QUARTER}-{SEQ4}"},
+		{name: "zero padding width", template: "INV-{SEQ0}"},
+		{name: "oversized padding width", template: "INV-{SEQ100}"},
+		{name: "prefix with token", template: "{PREFIX}-{SEQ4}", prefix: "{YYYY}"},
+		{name: "long prefix", template: "{PREFIX}-{SEQ4}", prefix: "ABCDEFGHIJKLMNOPQRSTUVWXYZ"},
+	}
+	for _, tc := range cases {
+		err := ValidateInvoiceNumberFormat(tc.template, tc.prefix)
+		if tc.valid && err != nil {
+			t.Fatalf("%s: expected valid, got %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("%s: expected an error", tc.name)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/smallbiznis/railzway/pkg/money"
 )
 
 const invoiceHTMLTemplate = `<!doctype html>
@@ -290,12 +292,7 @@ func (r *HTMLRenderer) RenderHTML(input RenderInput) (string, error) {
 }
 
 func formatMoney(amount int64, currency string) string {
-	currency = strings.ToUpper(strings.TrimSpace(currency))
-	if currency == "" {
-		currency = "USD"
-	}
-	value := float64(amount) / 100.0
-	return fmt.Sprintf("%s %.2f", currency, value)
+	return money.Format(amount, currency)
 }
 
 func formatDate(value *time.Time) string {
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
+	"gorm.io/gorm"
+)
+
+// loadMinInvoiceAmount returns the org's minimum invoice subtotal in minor
+// units, or 0 when every cycle is invoiced.
+func (s *Service) loadMinInvoiceAmount(ctx context.Context, tx *gorm.DB, orgID snowflake.ID) (int64, error) {
+	var amount int64
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT COALESCE(MAX(min_invoice_amount), 0)
+		 FROM organization_billing_preferences
+		 WHERE org_id = ?`,
+		orgID,
+	).Scan(&amount).Error; err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+func (s *Service) findCarryForward(ctx context.Context, tx *gorm.DB, orgID, billingCycleID snowflake.ID) (*invoicedomain.CarryForward, error) {
+	var carried invoicedomain.CarryForward
+	err := tx.WithContext(ctx).Raw(
+		`SELECT id, org_id, subscription_id, source_billing_cycle_id, currency, amount,
+		        applied_billing_cycle_id, applied_at, created_at
+		 FROM invoice_carry_forwards
+		 WHERE org_id = ? AND source_billing_cycle_id = ?`,
+		orgID,
+		billingCycleID,
+	).Scan(&carried).Error
+	if err != nil {
+		return nil, err
+	}
+	if carried.ID == 0 {
+		return nil, nil
+	}
+	return &carried, nil
+}
+
+// isFinalCycle reports whether cycle is the last its subscription will have:
+// the subscription has ended or been canceled, or is set to cancel by the
+// end of the cycle. Nothing would bill an amount carried from it.
+func (s *Service) isFinalCycle(ctx context.Context, tx *gorm.DB, cycle billingCycleRow) (bool, error) {
+	var final bool
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT COUNT(1) > 0
+		 FROM subscriptions
+		 WHERE org_id = ? AND id = ?
+		   AND (
+			status IN (?, ?)
+			OR cancel_at_period_end
+			OR (cancel_at IS NOT NULL AND cancel_at <= ?)
+			OR (ended_at IS NOT NULL AND ended_at <= ?)
+		   )`,
+		cycle.OrgID,
+		cycle.SubscriptionID,
+		subscriptiondomain.SubscriptionStatusCanceled,
+		subscriptiondomain.SubscriptionStatusEnded,
+		cycle.PeriodEnd,
+		cycle.PeriodEnd,
+	).Scan(&final).Error; err != nil {
+		return false, err
+	}
+	return final, nil
+}
+
+// carryForwardDraft moves a cycle's draft to the subscription's next cycle
+// instead of invoicing it. The cycle's ledger entry is reversed, since the
+// next cycle's entry recognizes the amount again.
+func (s *Service) carryForwardDraft(ctx context.Context, tx *gorm.DB, cycle billingCycleRow, draft *invoiceDraft) (*invoicedomain.CarryForward, error) {
+	now := time.Now().UTC()
+	carried := &invoicedomain.CarryForward{
+		ID:                   s.genID.Generate(),
+		OrgID:                cycle.OrgID,
+		SubscriptionID:       cycle.SubscriptionID,
+		SourceBillingCycleID: cycle.ID,
+		Currency:             draft.Currency,
+		Amount:               draft.Subtotal,
+		CreatedAt:            now,
+	}
+	if err := tx.WithContext(ctx).Exec(
+		`INSERT INTO invoice_carry_forwards (
+			id, org_id, subscription_id, source_billing_cycle_id, currency, amount, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		carried.ID,
+		carried.OrgID,
+		carried.SubscriptionID,
+		carried.SourceBillingCycleID,
+		carried.Currency,
+		carried.Amount,
+		carried.CreatedAt,
+	).Error; err != nil {
+		return nil, err
+	}
+
+	lines := make([]ledgerdomain.LedgerEntryLine, 0, len(draft.LedgerLines))
+	for _, line := range draft.LedgerLines {
+		direction := ledgerdomain.LedgerEntryDirectionDebit
+		if line.Direction == ledgerdomain.LedgerEntryDirectionDebit {
+			direction = ledgerdomain.LedgerEntryDirectionCredit
+		}
+		lines = append(lines, ledgerdomain.LedgerEntryLine{
+			AccountID: line.AccountID,
+			Direction: direction,
+			Currency:  draft.Currency,
+			Amount:    line.Amount,
+		})
+	}
+	if err := ledgerdomain.ValidateBalanced(lines); err != nil {
+		return nil, fmt.Errorf("ledger entry not balanced: %w", err)
+	}
+	if _, _, err := s.insertLedgerEntryTx(ctx, tx, cycle.OrgID, ledgerdomain.SourceTypeCarryForward, carried.ID, draft.Currency, cycle.PeriodEnd, lines); err != nil {
+		return nil, err
+	}
+	return carried, nil
+}
+
+func (s *Service) emitCarryForwardAudit(ctx context.Context, carried *invoicedomain.CarryForward, minAmount int64) {
+	if s.auditSvc == nil || carried == nil {
+		return
+	}
+	metadata := map[string]any{
+		"carry_forward_id":   carried.ID.String(),
+		"billing_cycle_id":   carried.SourceBillingCycleID.String(),
+		"subscription_id":    carried.SubscriptionID.String(),
+		"currency":           carried.Currency,
+		"carried_amount":     carried.Amount,
+		"min_invoice_amount": minAmount,
+	}
+	targetID := carried.SourceBillingCycleID.String()
+	orgID := carried.OrgID
+	_ = s.auditSvc.AuditLog(ctx, &orgID, "", nil, "invoice.skipped_below_minimum", "billing_cycle", &targetID, metadata)
+}
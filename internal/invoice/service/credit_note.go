@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	billingopsrepo "github.com/smallbiznis/railzway/internal/billingoperations/repository"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	"github.com/smallbiznis/railzway/internal/ledger"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"gorm.io/gorm"
+)
+
+// IssueCreditNote records a partial credit against a finalized invoice and
+// posts it to the ledger in the same transaction.
+//
+// Double-entry logic:
+//
+//	Debit:  Revenue (income decreases)
+//	Credit: Accounts Receivable (asset decreases)
+//
+// The AR credit is picked up by the billing operations settled CTE, so the
+// invoice outstanding drops by amount (floored at zero). A credit note may
+// not exceed what payments and earlier credit notes leave unsettled on the
+// invoice.
+//
+// A repeat with the same idempotencyKey returns the credit note the first
+// call issued instead of crediting the invoice again.
+func (s *Service) IssueCreditNote(ctx context.Context, invoiceID string, amount int64, reason string, idempotencyKey string) (*invoicedomain.CreditNote, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, invoicedomain.ErrInvalidOrganization
+	}
+	id, err := parseID(strings.TrimSpace(invoiceID))
+	if err != nil {
+		return nil, invoicedomain.ErrInvalidInvoiceID
+	}
+	if amount <= 0 {
+		return nil, invoicedomain.ErrInvalidCreditNoteAmount
+	}
+
+	reason = strings.TrimSpace(reason)
+	key := ledger.IdempotencyKey{
+		OrgID:   orgID,
+		Scope:   "invoice.credit_note",
+		Key:     idempotencyKey,
+		Request: fmt.Sprintf("%s|%d|%s", id, amount, reason),
+	}
+
+	var credited *invoicedomain.Invoice
+	note, replayed, err := ledger.WithIdempotency(ctx, s.db, key, func(tx *gorm.DB) (*invoicedomain.CreditNote, error) {
+		invoice, err := s.loadInvoiceForUpdate(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if invoice == nil || invoice.OrgID != orgID {
+			return nil, invoicedomain.ErrInvoiceNotFound
+		}
+		if invoice.Status != invoicedomain.InvoiceStatusFinalized || invoice.VoidedAt != nil {
+			return nil, invoicedomain.ErrInvoiceNotFinalized
+		}
+
+		// Payments and earlier credit notes both settle the invoice, so only
+		// what is still unsettled can be credited.
+		settledQuery, settledArgs, err := billingopsrepo.SettledAmountCTE(ctx, tx, orgID, invoice.Currency)
+		if err != nil {
+			return nil, err
+		}
+		var settled int64
+		if err := tx.WithContext(ctx).Raw(
+			fmt.Sprintf(`SELECT COALESCE(SUM(s.settled_amount), 0)
+			 FROM (%s
+			 ) s
+			 WHERE s.invoice_id_text = ?`, settledQuery),
+			append(settledArgs, invoice.ID.String())...,
+		).Scan(&settled).Error; err != nil {
+			return nil, err
+		}
+		if settled+amount > invoice.TotalAmount {
+			return nil, invoicedomain.ErrCreditNoteExceedsTotal
+		}
+
+		accounts, err := s.loadLedgerAccounts(ctx, tx, orgID, []ledgerdomain.LedgerAccountCode{
+			ledgerdomain.AccountCodeAccountsReceivable,
+			ledgerdomain.AccountCodeRevenueUsage,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ledger accounts: %w", err)
+		}
+		arAccount, ok := accounts[ledgerdomain.AccountCodeAccountsReceivable]
+		if !ok {
+			return nil, fmt.Errorf("accounts_receivable account not found for org %s", orgID)
+		}
+		revenueAccount, ok := accounts[ledgerdomain.AccountCodeRevenueUsage]
+		if !ok {
+			return nil, fmt.Errorf("revenue_usage account not found for org %s", orgID)
+		}
+
+		now := time.Now().UTC()
+		record := &invoicedomain.CreditNote{
+			ID:         s.genID.Generate(),
+			OrgID:      orgID,
+			InvoiceID:  invoice.ID,
+			CustomerID: invoice.CustomerID,
+			Amount:     amount,
+			Currency:   invoice.Currency,
+			IssuedAt:   now,
+			CreatedAt:  now,
+		}
+		if reason != "" {
+			record.Reason = &reason
+		}
+		if err := tx.WithContext(ctx).Create(record).Error; err != nil {
+			return nil, err
+		}
+
+		lines := []ledgerdomain.LedgerEntryLine{
+			{
+				AccountID: revenueAccount.ID,
+				Direction: ledgerdomain.LedgerEntryDirectionDebit,
+				Currency:  invoice.Currency,
+				Amount:    amount,
+			},
+			{
+				AccountID: arAccount.ID,
+				Direction: ledgerdomain.LedgerEntryDirectionCredit,
+				Currency:  invoice.Currency,
+				Amount:    amount,
+			},
+		}
+		if err := ledgerdomain.ValidateBalanced(lines); err != nil {
+			return nil, fmt.Errorf("ledger entry not balanced: %w", err)
+		}
+		if _, _, err := s.insertLedgerEntryTx(ctx, tx, orgID, ledgerdomain.SourceTypeCreditNote, record.ID, invoice.Currency, now, lines); err != nil {
+			return nil, err
+		}
+
+		credited = invoice
+		return record, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		return note, nil
+	}
+
+	metadata := map[string]any{
+		"credit_note_id": note.ID.String(),
+		"amount":         note.Amount,
+	}
+	if note.Reason != nil {
+		metadata["reason"] = *note.Reason
+	}
+	s.emitAudit(ctx, "invoice.credit_note_issued", credited, metadata)
+	return note, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestGenerateInvoice_IdempotencyKey(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&invoicedomain.Invoice{}, &invoicedomain.CarryForward{}))
+	require.NoError(t, db.Exec("CREATE TABLE billing_cycles (id BIGINT, org_id BIGINT, subscription_id BIGINT, period_start DATETIME, period_end DATETIME, status TEXT)").Error)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	svc := NewService(ServiceParam{DB: db, Log: zap.NewNop(), GenID: node})
+
+	orgID := node.Generate()
+	now := time.Now().UTC()
+	seedCycle := func() snowflake.ID {
+		id := node.Generate()
+		require.NoError(t, db.Exec(
+			"INSERT INTO billing_cycles (id, org_id, subscription_id, period_start, period_end, status) VALUES (?, ?, ?, ?, ?, ?)",
+			id, orgID, node.Generate(), now.AddDate(0, -1, 0), now, billingcycledomain.BillingCycleStatusClosed,
+		).Error)
+		return id
+	}
+	seedInvoice := func(cycleID snowflake.ID, key *string) snowflake.ID {
+		id := node.Generate()
+		require.NoError(t, db.Create(&invoicedomain.Invoice{
+			ID:             id,
+			OrgID:          orgID,
+			BillingCycleID: cycleID,
+			SubscriptionID: node.Generate(),
+			CustomerID:     node.Generate(),
+			Status:         invoicedomain.InvoiceStatusDraft,
+			Currency:       "USD",
+			IdempotencyKey: key,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}).Error)
+		return id
+	}
+	ctx := context.Background()
+
+	t.Run("same key returns the existing invoice", func(t *testing.T) {
+		cycleID := seedCycle()
+		key := "manual-run-1"
+		invoiceID := seedInvoice(cycleID, &key)
+
+		result, err := svc.GenerateInvoice(ctx, cycleID.String(), key)
+		require.NoError(t, err)
+		assert.True(t, result.Duplicate)
+		assert.Equal(t, invoiceID, result.Invoice.ID)
+	})
+
+	t.Run("existing cycle invoice is a duplicate under any key", func(t *testing.T) {
+		cycleID := seedCycle()
+		invoiceID := seedInvoice(cycleID, nil)
+
+		result, err := svc.GenerateInvoice(ctx, cycleID.String(), invoicedomain.GenerationIdempotencyKey(cycleID))
+		require.NoError(t, err)
+		assert.True(t, result.Duplicate)
+		assert.Equal(t, invoiceID, result.Invoice.ID)
+	})
+
+	t.Run("key reused for another cycle is rejected", func(t *testing.T) {
+		key := "shared-key"
+		seedInvoice(seedCycle(), &key)
+
+		_, err := svc.GenerateInvoice(ctx, seedCycle().String(), key)
+		assert.ErrorIs(t, err, invoicedomain.ErrIdempotencyKeyConflict)
+	})
+
+	t.Run("oversized key is rejected", func(t *testing.T) {
+		_, err := svc.GenerateInvoice(ctx, seedCycle().String(), strings.Repeat("k", invoicedomain.MaxIdempotencyKeyLength+1))
+		assert.ErrorIs(t, err, invoicedomain.ErrInvalidIdempotencyKey)
+	})
+}
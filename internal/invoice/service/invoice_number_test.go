@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestAssignInvoiceNumber(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.Exec("CREATE TABLE invoice_sequences (org_id BIGINT PRIMARY KEY, next_number BIGINT NOT NULL DEFAULT 1, updated_at DATETIME NOT NULL)").Error)
+	require.NoError(t, db.Exec("CREATE TABLE organization_billing_preferences (org_id BIGINT PRIMARY KEY, invoice_number_format TEXT, invoice_number_prefix TEXT)").Error)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	svc := NewService(ServiceParam{DB: db, Log: zap.NewNop(), GenID: node}).(*Service)
+
+	orgID := node.Generate()
+	issuedAt := time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	assign := func(invoice *invoicedomain.Invoice) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			return svc.assignInvoiceNumber(ctx, tx, invoice, issuedAt)
+		})
+	}
+
+	t.Run("default format starts the org sequence", func(t *testing.T) {
+		invoice := &invoicedomain.Invoice{OrgID: orgID}
+		require.NoError(t, assign(invoice))
+		require.NotNil(t, invoice.InvoiceSeq)
+		assert.Equal(t, int64(1), *invoice.InvoiceSeq)
+		assert.Equal(t, "INV-20240520-000001", invoice.InvoiceNumber)
+	})
+
+	require.NoError(t, db.Exec(
+		"INSERT INTO organization_billing_preferences (org_id, invoice_number_format, invoice_number_prefix) VALUES (?, ?, ?)",
+		orgID, "{PREFIX}-{YYYY}-{SEQ6}", "ACME",
+	).Error)
+
+	t.Run("org format is applied", func(t *testing.T) {
+		invoice := &invoicedomain.Invoice{OrgID: orgID}
+		require.NoError(t, assign(invoice))
+		assert.Equal(t, int64(2), *invoice.InvoiceSeq)
+		assert.Equal(t, "ACME-2024-000002", invoice.InvoiceNumber)
+	})
+
+	t.Run("rolled back finalization leaves no gap", func(t *testing.T) {
+		errAbort := errors.New("abort")
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := svc.assignInvoiceNumber(ctx, tx, &invoicedomain.Invoice{OrgID: orgID}, issuedAt); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		require.ErrorIs(t, err, errAbort)
+
+		invoice := &invoicedomain.Invoice{OrgID: orgID}
+		require.NoError(t, assign(invoice))
+		assert.Equal(t, "ACME-2024-000003", invoice.InvoiceNumber)
+	})
+
+	t.Run("numbered invoice keeps its number", func(t *testing.T) {
+		seq := int64(7)
+		invoice := &invoicedomain.Invoice{OrgID: orgID, InvoiceSeq: &seq, InvoiceNumber: "INV-20240101-000007"}
+		require.NoError(t, assign(invoice))
+		assert.Equal(t, "INV-20240101-000007", invoice.InvoiceNumber)
+	})
+
+	t.Run("sequences are per org", func(t *testing.T) {
+		invoice := &invoicedomain.Invoice{OrgID: node.Generate()}
+		require.NoError(t, assign(invoice))
+		assert.Equal(t, int64(1), *invoice.InvoiceSeq)
+	})
+}
@@ -113,6 +113,50 @@ func (s *Service) postInvoiceToLedger(ctx context.Context, tx *gorm.DB, invoice
 // postLedgerEntryDirect posts ledger entries directly within the current transaction.
 // This ensures atomicity with invoice finalization.
 func (s *Service) postLedgerEntryDirect(ctx context.Context, tx *gorm.DB, invoice *invoicedomain.Invoice, lines []ledgerdomain.LedgerEntryLine) error {
+	entryID, inserted, err := s.insertLedgerEntryTx(
+		ctx,
+		tx,
+		invoice.OrgID,
+		ledgerdomain.SourceTypeBillingCycle,
+		invoice.ID,
+		invoice.Currency,
+		invoice.FinalizedAt.UTC(),
+		lines,
+	)
+	if err != nil {
+		return err
+	}
+
+	// If nothing was inserted, entry already exists (idempotency)
+	if !inserted {
+		s.log.Info("ledger entry already exists for invoice",
+			zap.String("invoice_id", invoice.ID.String()),
+			zap.String("org_id", invoice.OrgID.String()),
+		)
+		return nil
+	}
+
+	s.log.Info("posted invoice to ledger",
+		zap.String("invoice_id", invoice.ID.String()),
+		zap.String("ledger_entry_id", entryID.String()),
+		zap.Int64("total_amount", invoice.TotalAmount),
+	)
+
+	return nil
+}
+
+// insertLedgerEntryTx writes a ledger entry header and its lines using tx.
+// It reports inserted=false when an entry for the same source already exists.
+func (s *Service) insertLedgerEntryTx(
+	ctx context.Context,
+	tx *gorm.DB,
+	orgID snowflake.ID,
+	sourceType ledgerdomain.LedgerSourceType,
+	sourceID snowflake.ID,
+	currency string,
+	occurredAt time.Time,
+	lines []ledgerdomain.LedgerEntryLine,
+) (snowflake.ID, bool, error) {
 	entryID := s.genID.Generate()
 	now := time.Now().UTC()
 
@@ -123,24 +167,18 @@ func (s *Service) postLedgerEntryDirect(ctx context.Context, tx *gorm.DB, invoic
 		) VALUES (?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT (org_id, source_type, source_id) DO NOTHING`,
 		entryID,
-		invoice.OrgID,
-		string(ledgerdomain.SourceTypeBillingCycle),
-		invoice.ID,
-		invoice.Currency,
-		invoice.FinalizedAt.UTC(),
+		orgID,
+		string(sourceType),
+		sourceID,
+		currency,
+		occurredAt,
 		now,
 	)
 	if result.Error != nil {
-		return fmt.Errorf("failed to insert ledger entry: %w", result.Error)
+		return 0, false, fmt.Errorf("failed to insert ledger entry: %w", result.Error)
 	}
-
-	// If no rows affected, entry already exists (idempotency)
 	if result.RowsAffected == 0 {
-		s.log.Info("ledger entry already exists for invoice",
-			zap.String("invoice_id", invoice.ID.String()),
-			zap.String("org_id", invoice.OrgID.String()),
-		)
-		return nil
+		return 0, false, nil
 	}
 
 	// Insert ledger entry lines
@@ -157,17 +195,11 @@ func (s *Service) postLedgerEntryDirect(ctx context.Context, tx *gorm.DB, invoic
 			line.Amount,
 			now,
 		).Error; err != nil {
-			return fmt.Errorf("failed to insert ledger entry line: %w", err)
+			return 0, false, fmt.Errorf("failed to insert ledger entry line: %w", err)
 		}
 	}
 
-	s.log.Info("posted invoice to ledger",
-		zap.String("invoice_id", invoice.ID.String()),
-		zap.String("ledger_entry_id", entryID.String()),
-		zap.Int64("total_amount", invoice.TotalAmount),
-	)
-
-	return nil
+	return entryID, true, nil
 }
 
 // loadLedgerAccounts loads ledger accounts by code for the given organization.
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,7 +10,9 @@ import (
 	"github.com/glebarez/sqlite"
 	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
 	"github.com/smallbiznis/railzway/internal/invoice/render"
+	"github.com/smallbiznis/railzway/internal/ledger"
 	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
 	publicinvoicedomain "github.com/smallbiznis/railzway/internal/publicinvoice/domain"
 	taxdomain "github.com/smallbiznis/railzway/internal/tax/domain"
 	"github.com/stretchr/testify/assert"
@@ -58,6 +61,14 @@ func (m *mockLedgerSvc) CreateEntry(ctx context.Context, orgID snowflake.ID, sou
 	return args.Error(0)
 }
 
+func (m *mockLedgerSvc) CreateEntryTx(ctx context.Context, _ *gorm.DB, orgID snowflake.ID, sourceType string, sourceID snowflake.ID, currency string, occurredAt time.Time, lines []ledgerdomain.LedgerEntryLine) error {
+	return m.CreateEntry(ctx, orgID, sourceType, sourceID, currency, occurredAt, lines)
+}
+
+func (m *mockLedgerSvc) ListLedgerEntries(context.Context, ledgerdomain.ListEntriesFilter) (ledgerdomain.ListEntriesResponse, error) {
+	return ledgerdomain.ListEntriesResponse{}, nil
+}
+
 func TestPostInvoiceToLedger_CorrectPostings(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
 
@@ -260,3 +271,261 @@ func TestFinalizeInvoice_Idempotency_NoOp(t *testing.T) {
 
 func float64Ptr(f float64) *float64  { return &f }
 func timePtr(t time.Time) *time.Time { return &t }
+
+// openCreditNoteTestDB migrates what IssueCreditNote reads and writes. The
+// settled-amount query's Postgres JSON path and casts are rewritten to their
+// sqlite forms.
+func openCreditNoteTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	sqliteSQL := strings.NewReplacer(
+		"#>> '{data,object,metadata,invoice_id}'", "->> '$.data.object.metadata.invoice_id'",
+		"pa.invoice_id::text", "CAST(pa.invoice_id AS TEXT)",
+		"cn.invoice_id::text", "CAST(cn.invoice_id AS TEXT)",
+	)
+	db.Callback().Row().Before("gorm:row").Register("sqlite_settled_amount_row", func(d *gorm.DB) {
+		sql := d.Statement.SQL.String()
+		if rewritten := sqliteSQL.Replace(sql); rewritten != sql {
+			d.Statement.SQL.Reset()
+			d.Statement.SQL.WriteString(rewritten)
+		}
+	})
+	db.AutoMigrate(&invoicedomain.Invoice{}, &invoicedomain.CreditNote{}, &ledgerdomain.LedgerEntry{}, &ledgerdomain.LedgerEntryLine{}, &ledgerdomain.LedgerAccount{})
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS ux_ledger_entries_source ON ledger_entries(org_id, source_type, source_id)")
+	db.Exec("DROP INDEX IF EXISTS ux_ledger_accounts_org_type")
+	db.Exec(`CREATE TABLE payment_events (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, payload TEXT NOT NULL)`)
+	db.Exec(`CREATE TABLE payment_allocations (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, payment_event_id BIGINT NOT NULL, invoice_id BIGINT NOT NULL)`)
+	db.Exec(`CREATE TABLE organization_billing_preferences (org_id BIGINT PRIMARY KEY, receivable_account_codes TEXT)`)
+	return db
+}
+
+func TestIssueCreditNote_PostsLedgerEntryAndCapsAtTotal(t *testing.T) {
+	db := openCreditNoteTestDB(t)
+
+	node, _ := snowflake.NewNode(1)
+	svc := NewService(ServiceParam{
+		DB:    db,
+		Log:   zap.NewNop(),
+		GenID: node,
+	}).(*Service)
+
+	orgID := node.Generate()
+	arAccountID := node.Generate()
+	revAccountID := node.Generate()
+	assert.NoError(t, db.Create(&ledgerdomain.LedgerAccount{ID: arAccountID, OrgID: orgID, Code: ledgerdomain.AccountCodeAccountsReceivable, Name: "AR", Type: ledgerdomain.Assets}).Error)
+	assert.NoError(t, db.Create(&ledgerdomain.LedgerAccount{ID: revAccountID, OrgID: orgID, Code: ledgerdomain.AccountCodeRevenueUsage, Name: "Revenue", Type: ledgerdomain.Income}).Error)
+
+	invoice := &invoicedomain.Invoice{
+		ID:             node.Generate(),
+		OrgID:          orgID,
+		InvoiceNumber:  "INV-1",
+		Status:         invoicedomain.InvoiceStatusFinalized,
+		SubtotalAmount: 10000,
+		TotalAmount:    10000,
+		Currency:       "USD",
+		FinalizedAt:    timePtr(time.Now()),
+	}
+	assert.NoError(t, db.Create(invoice).Error)
+
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	note, err := svc.IssueCreditNote(ctx, invoice.ID.String(), 2500, "service outage", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2500), note.Amount)
+	assert.Equal(t, "service outage", *note.Reason)
+
+	var entry ledgerdomain.LedgerEntry
+	assert.NoError(t, db.First(&entry, "source_type = ? AND source_id = ?", ledgerdomain.SourceTypeCreditNote, note.ID).Error)
+
+	var lines []ledgerdomain.LedgerEntryLine
+	db.Find(&lines, "ledger_entry_id = ?", entry.ID)
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		switch line.AccountID {
+		case arAccountID:
+			assert.Equal(t, ledgerdomain.LedgerEntryDirectionCredit, line.Direction)
+		case revAccountID:
+			assert.Equal(t, ledgerdomain.LedgerEntryDirectionDebit, line.Direction)
+		}
+		assert.Equal(t, int64(2500), line.Amount)
+	}
+
+	_, err = svc.IssueCreditNote(ctx, invoice.ID.String(), 7501, "", "")
+	assert.ErrorIs(t, err, invoicedomain.ErrCreditNoteExceedsTotal)
+
+	_, err = svc.IssueCreditNote(ctx, invoice.ID.String(), 0, "", "")
+	assert.ErrorIs(t, err, invoicedomain.ErrInvalidCreditNoteAmount)
+
+	var count int64
+	db.Model(&invoicedomain.CreditNote{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestIssueCreditNote_IdempotencyKey(t *testing.T) {
+	db := openCreditNoteTestDB(t)
+	db.Exec(`CREATE TABLE idempotency_keys (
+		org_id BIGINT NOT NULL,
+		scope TEXT NOT NULL,
+		idempotency_key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		response TEXT,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		PRIMARY KEY (org_id, scope, idempotency_key)
+	)`)
+
+	node, _ := snowflake.NewNode(1)
+	svc := NewService(ServiceParam{
+		DB:    db,
+		Log:   zap.NewNop(),
+		GenID: node,
+	}).(*Service)
+
+	orgID := node.Generate()
+	assert.NoError(t, db.Create(&ledgerdomain.LedgerAccount{ID: node.Generate(), OrgID: orgID, Code: ledgerdomain.AccountCodeAccountsReceivable, Name: "AR", Type: ledgerdomain.Assets}).Error)
+	assert.NoError(t, db.Create(&ledgerdomain.LedgerAccount{ID: node.Generate(), OrgID: orgID, Code: ledgerdomain.AccountCodeRevenueUsage, Name: "Revenue", Type: ledgerdomain.Income}).Error)
+
+	invoice := &invoicedomain.Invoice{
+		ID:             node.Generate(),
+		OrgID:          orgID,
+		InvoiceNumber:  "INV-1",
+		Status:         invoicedomain.InvoiceStatusFinalized,
+		SubtotalAmount: 10000,
+		TotalAmount:    10000,
+		Currency:       "USD",
+		FinalizedAt:    timePtr(time.Now()),
+	}
+	assert.NoError(t, db.Create(invoice).Error)
+
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	first, err := svc.IssueCreditNote(ctx, invoice.ID.String(), 2500, "service outage", "retry-1")
+	assert.NoError(t, err)
+	repeat, err := svc.IssueCreditNote(ctx, invoice.ID.String(), 2500, "service outage", "retry-1")
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, repeat.ID)
+	assert.Equal(t, first.Amount, repeat.Amount)
+
+	_, err = svc.IssueCreditNote(ctx, invoice.ID.String(), 3000, "service outage", "retry-1")
+	assert.ErrorIs(t, err, ledgerdomain.ErrIdempotencyKeyConflict)
+
+	var notes, entries int64
+	db.Model(&invoicedomain.CreditNote{}).Count(&notes)
+	db.Model(&ledgerdomain.LedgerEntry{}).Count(&entries)
+	assert.Equal(t, int64(1), notes)
+	assert.Equal(t, int64(1), entries)
+
+	// A failed call leaves its key free for the retry.
+	_, err = svc.IssueCreditNote(ctx, invoice.ID.String(), 9000, "", "retry-2")
+	assert.ErrorIs(t, err, invoicedomain.ErrCreditNoteExceedsTotal)
+	_, err = svc.IssueCreditNote(ctx, invoice.ID.String(), 500, "", "retry-2")
+	assert.NoError(t, err)
+
+	purged, err := ledger.PurgeExpiredIdempotencyKeys(ctx, db, time.Now().Add(ledger.IdempotencyKeyTTL+time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+}
+
+func TestIssueCreditNote_CapsAtUnsettledAmount(t *testing.T) {
+	db := openCreditNoteTestDB(t)
+
+	node, _ := snowflake.NewNode(1)
+	svc := NewService(ServiceParam{
+		DB:    db,
+		Log:   zap.NewNop(),
+		GenID: node,
+	}).(*Service)
+
+	orgID := node.Generate()
+	arAccountID := node.Generate()
+	assert.NoError(t, db.Create(&ledgerdomain.LedgerAccount{ID: arAccountID, OrgID: orgID, Code: ledgerdomain.AccountCodeAccountsReceivable, Name: "AR", Type: ledgerdomain.Assets}).Error)
+	assert.NoError(t, db.Create(&ledgerdomain.LedgerAccount{ID: node.Generate(), OrgID: orgID, Code: ledgerdomain.AccountCodeRevenueUsage, Name: "Revenue", Type: ledgerdomain.Income}).Error)
+
+	invoice := &invoicedomain.Invoice{
+		ID:             node.Generate(),
+		OrgID:          orgID,
+		InvoiceNumber:  "INV-1",
+		Status:         invoicedomain.InvoiceStatusFinalized,
+		SubtotalAmount: 10000,
+		TotalAmount:    10000,
+		Currency:       "USD",
+		FinalizedAt:    timePtr(time.Now()),
+	}
+	assert.NoError(t, db.Create(invoice).Error)
+
+	// The customer has already paid 6000 of the invoice.
+	now := time.Now().UTC()
+	eventID := node.Generate()
+	entryID := node.Generate()
+	assert.NoError(t, db.Exec(
+		`INSERT INTO payment_events (id, org_id, payload) VALUES (?, ?, ?)`,
+		eventID, orgID, `{"data":{"object":{"metadata":{"invoice_id":"`+invoice.ID.String()+`"}}}}`,
+	).Error)
+	assert.NoError(t, db.Create(&ledgerdomain.LedgerEntry{ID: entryID, OrgID: orgID, SourceType: ledgerdomain.SourceTypePayment, SourceID: eventID, Currency: "USD", OccurredAt: now, CreatedAt: now}).Error)
+	assert.NoError(t, db.Create(&ledgerdomain.LedgerEntryLine{ID: node.Generate(), LedgerEntryID: entryID, AccountID: arAccountID, Direction: ledgerdomain.LedgerEntryDirectionCredit, Currency: "USD", Amount: 6000, CreatedAt: now}).Error)
+
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	_, err := svc.IssueCreditNote(ctx, invoice.ID.String(), 4001, "", "")
+	assert.ErrorIs(t, err, invoicedomain.ErrCreditNoteExceedsTotal)
+
+	_, err = svc.IssueCreditNote(ctx, invoice.ID.String(), 4000, "", "")
+	assert.NoError(t, err)
+
+	// Paid and credited in full, nothing is left to credit.
+	_, err = svc.IssueCreditNote(ctx, invoice.ID.String(), 1, "", "")
+	assert.ErrorIs(t, err, invoicedomain.ErrCreditNoteExceedsTotal)
+
+	var count int64
+	db.Model(&invoicedomain.CreditNote{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestVoidInvoice_IdempotencyKey(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	db.AutoMigrate(&invoicedomain.Invoice{})
+	db.Exec(`CREATE TABLE idempotency_keys (
+		org_id BIGINT NOT NULL,
+		scope TEXT NOT NULL,
+		idempotency_key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		response TEXT,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		PRIMARY KEY (org_id, scope, idempotency_key)
+	)`)
+
+	node, _ := snowflake.NewNode(1)
+	svc := NewService(ServiceParam{
+		DB:    db,
+		Log:   zap.NewNop(),
+		GenID: node,
+	}).(*Service)
+
+	orgID := node.Generate()
+	invoice := &invoicedomain.Invoice{
+		ID:             node.Generate(),
+		OrgID:          orgID,
+		InvoiceNumber:  "INV-1",
+		Status:         invoicedomain.InvoiceStatusFinalized,
+		SubtotalAmount: 10000,
+		TotalAmount:    10000,
+		Currency:       "USD",
+		FinalizedAt:    timePtr(time.Now()),
+	}
+	assert.NoError(t, db.Create(invoice).Error)
+
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	assert.NoError(t, svc.VoidInvoice(ctx, invoice.ID.String(), "duplicate", "void-1"))
+	assert.NoError(t, svc.VoidInvoice(ctx, invoice.ID.String(), "duplicate", "void-1"))
+	assert.ErrorIs(t, svc.VoidInvoice(ctx, invoice.ID.String(), "other reason", "void-1"), ledgerdomain.ErrIdempotencyKeyConflict)
+
+	// Without a key a repeat fails as before: the invoice is no longer finalized.
+	assert.ErrorIs(t, svc.VoidInvoice(ctx, invoice.ID.String(), "duplicate", ""), invoicedomain.ErrInvoiceNotFinalized)
+
+	var reloaded invoicedomain.Invoice
+	assert.NoError(t, db.First(&reloaded, "id = ?", invoice.ID).Error)
+	assert.Equal(t, invoicedomain.InvoiceStatusVoid, reloaded.Status)
+	assert.NotNil(t, reloaded.VoidedAt)
+}
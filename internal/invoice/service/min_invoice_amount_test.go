@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	auditdomain "github.com/smallbiznis/railzway/internal/audit/domain"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	ratingdomain "github.com/smallbiznis/railzway/internal/rating/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type recordingAuditSvc struct {
+	actions []string
+}
+
+func (a *recordingAuditSvc) AuditLog(ctx context.Context, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+func (a *recordingAuditSvc) AuditLogTx(ctx context.Context, _ *gorm.DB, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
+	return a.AuditLog(ctx, orgID, actorType, actorID, action, targetType, targetID, metadata)
+}
+
+func (a *recordingAuditSvc) List(ctx context.Context, req auditdomain.ListAuditLogRequest) (auditdomain.ListAuditLogResponse, error) {
+	return auditdomain.ListAuditLogResponse{}, nil
+}
+
+// minInvoiceFixture is a closed cycle rated at amount for an org with a
+// minimum invoice amount of 500.
+type minInvoiceFixture struct {
+	db           *gorm.DB
+	svc          invoicedomain.Service
+	audit        *recordingAuditSvc
+	subID        snowflake.ID
+	cycleID      snowflake.ID
+	receivableID snowflake.ID
+	revenueID    snowflake.ID
+}
+
+// setupMinInvoiceCycle seeds the cycle. subscription sets the subscription's
+// status and cancellation columns.
+func setupMinInvoiceCycle(t *testing.T, amount int64, subscription string) minInvoiceFixture {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&ratingdomain.RatingResult{},
+		&invoicedomain.Invoice{},
+		&invoicedomain.InvoiceItem{},
+		&invoicedomain.InvoiceTaxLine{},
+		&invoicedomain.SubscriptionEntitlement{},
+		&invoicedomain.CarryForward{},
+	))
+	for _, stmt := range []string{
+		"CREATE TABLE billing_cycles (id BIGINT, org_id BIGINT, subscription_id BIGINT, period_start DATETIME, period_end DATETIME, status TEXT)",
+		"CREATE TABLE subscriptions (id BIGINT, org_id BIGINT, customer_id BIGINT, status TEXT, cancel_at DATETIME, cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE, ended_at DATETIME)",
+		"CREATE TABLE customers (id BIGINT, org_id BIGINT, metadata TEXT)",
+		"CREATE TABLE prices (id BIGINT, org_id BIGINT, tax_behavior TEXT)",
+		"CREATE TABLE organizations (id BIGINT)",
+		"CREATE TABLE organization_billing_preferences (org_id BIGINT, min_invoice_amount BIGINT)",
+		"CREATE TABLE ledger_entries (id BIGINT, org_id BIGINT, source_type TEXT, source_id BIGINT, currency TEXT, occurred_at DATETIME, created_at DATETIME)",
+		"CREATE UNIQUE INDEX ux_ledger_entries_source ON ledger_entries (org_id, source_type, source_id)",
+		"CREATE TABLE ledger_entry_lines (id BIGINT, ledger_entry_id BIGINT, account_id BIGINT, direction TEXT, currency TEXT, amount BIGINT, created_at DATETIME)",
+		"CREATE TABLE ledger_accounts (id BIGINT, code TEXT, name TEXT)",
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	ignoreOrganizationLock(t, db)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	audit := &recordingAuditSvc{}
+	svc := NewService(ServiceParam{DB: db, Log: zap.NewNop(), GenID: node, AuditSvc: audit})
+
+	orgID := node.Generate()
+	subID := node.Generate()
+	customerID := node.Generate()
+	cycleID := node.Generate()
+	end := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -1, 0)
+
+	require.NoError(t, db.Exec("INSERT INTO organizations (id) VALUES (?)", orgID).Error)
+	require.NoError(t, db.Exec("INSERT INTO organization_billing_preferences (org_id, min_invoice_amount) VALUES (?, 500)", orgID).Error)
+	require.NoError(t, db.Exec(
+		"INSERT INTO billing_cycles (id, org_id, subscription_id, period_start, period_end, status) VALUES (?, ?, ?, ?, ?, ?)",
+		cycleID, orgID, subID, start, end, billingcycledomain.BillingCycleStatusClosed,
+	).Error)
+	require.NoError(t, db.Exec("INSERT INTO subscriptions (id, org_id, customer_id, status) VALUES (?, ?, ?, 'ACTIVE')", subID, orgID, customerID).Error)
+	if subscription != "" {
+		require.NoError(t, db.Exec("UPDATE subscriptions SET "+subscription+" WHERE id = ?", subID).Error)
+	}
+	require.NoError(t, db.Exec(`INSERT INTO customers (id, org_id, metadata) VALUES (?, ?, '{}')`, customerID, orgID).Error)
+
+	priceID := node.Generate()
+	require.NoError(t, db.Exec("INSERT INTO prices (id, org_id, tax_behavior) VALUES (?, ?, 'EXCLUSIVE')", priceID, orgID).Error)
+	ratingID := node.Generate()
+	require.NoError(t, db.Create(&ratingdomain.RatingResult{
+		ID:             ratingID,
+		OrgID:          orgID,
+		SubscriptionID: subID,
+		BillingCycleID: cycleID,
+		PriceID:        priceID,
+		Source:         "usage",
+		Quantity:       1,
+		UnitPrice:      amount,
+		Amount:         amount,
+		Currency:       "USD",
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		Checksum:       ratingID.String(),
+		CreatedAt:      end,
+	}).Error)
+
+	entryID := node.Generate()
+	receivableID := node.Generate()
+	revenueID := node.Generate()
+	require.NoError(t, db.Exec(
+		"INSERT INTO ledger_entries (id, org_id, source_type, source_id, currency, occurred_at) VALUES (?, ?, ?, ?, 'USD', ?)",
+		entryID, orgID, ledgerdomain.SourceTypeBillingCycle, cycleID, end,
+	).Error)
+	require.NoError(t, db.Exec(
+		"INSERT INTO ledger_accounts (id, code, name) VALUES (?, 'accounts_receivable', 'Accounts Receivable'), (?, 'revenue_usage', 'Usage Revenue')",
+		receivableID, revenueID,
+	).Error)
+	require.NoError(t, db.Exec(
+		"INSERT INTO ledger_entry_lines (id, ledger_entry_id, account_id, direction, amount) VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)",
+		node.Generate(), entryID, receivableID, ledgerdomain.LedgerEntryDirectionDebit, amount,
+		node.Generate(), entryID, revenueID, ledgerdomain.LedgerEntryDirectionCredit, amount,
+	).Error)
+
+	return minInvoiceFixture{
+		db:           db,
+		svc:          svc,
+		audit:        audit,
+		subID:        subID,
+		cycleID:      cycleID,
+		receivableID: receivableID,
+		revenueID:    revenueID,
+	}
+}
+
+func TestGenerateInvoice_CarriesForwardBelowMinimum(t *testing.T) {
+	f := setupMinInvoiceCycle(t, 300, "")
+	db, svc, audit := f.db, f.svc, f.audit
+	subID, cycleID := f.subID, f.cycleID
+	receivableID, revenueID := f.receivableID, f.revenueID
+
+	result, err := svc.GenerateInvoice(context.Background(), cycleID.String(), "")
+	require.NoError(t, err)
+	assert.Nil(t, result.Invoice)
+	assert.False(t, result.Duplicate)
+	require.NotNil(t, result.CarryForward)
+	assert.Equal(t, int64(300), result.CarryForward.Amount)
+	assert.Equal(t, "USD", result.CarryForward.Currency)
+	assert.Equal(t, subID, result.CarryForward.SubscriptionID)
+	assert.Nil(t, result.CarryForward.AppliedBillingCycleID)
+	assert.Equal(t, []string{"invoice.skipped_below_minimum"}, audit.actions)
+
+	var invoices int64
+	require.NoError(t, db.Model(&invoicedomain.Invoice{}).Count(&invoices).Error)
+	assert.Zero(t, invoices)
+
+	var reversal []struct {
+		AccountID snowflake.ID
+		Direction string
+		Amount    int64
+	}
+	require.NoError(t, db.Raw(
+		`SELECT l.account_id, l.direction, l.amount
+		 FROM ledger_entry_lines l
+		 JOIN ledger_entries e ON e.id = l.ledger_entry_id
+		 WHERE e.source_type = ? AND e.source_id = ?
+		 ORDER BY l.direction`,
+		ledgerdomain.SourceTypeCarryForward, result.CarryForward.ID,
+	).Scan(&reversal).Error)
+	require.Len(t, reversal, 2)
+	assert.Equal(t, revenueID, reversal[1].AccountID, "revenue is debited back")
+	assert.Equal(t, string(ledgerdomain.LedgerEntryDirectionDebit), reversal[1].Direction)
+	assert.Equal(t, receivableID, reversal[0].AccountID, "receivable is credited back")
+	assert.Equal(t, string(ledgerdomain.LedgerEntryDirectionCredit), reversal[0].Direction)
+
+	again, err := svc.GenerateInvoice(context.Background(), cycleID.String(), "")
+	require.NoError(t, err)
+	assert.True(t, again.Duplicate)
+	require.NotNil(t, again.CarryForward)
+	assert.Equal(t, result.CarryForward.ID, again.CarryForward.ID)
+	assert.Len(t, audit.actions, 1, "a repeated run is not audited again")
+}
+
+func TestGenerateInvoice_InvoicesBelowMinimum(t *testing.T) {
+	cases := []struct {
+		name         string
+		amount       int64
+		subscription string
+	}{
+		{name: "ended subscription", amount: 300, subscription: "status = 'ENDED', ended_at = '2025-06-01 00:00:00'"},
+		{name: "canceled subscription", amount: 300, subscription: "status = 'CANCELED'"},
+		{name: "cancels at period end", amount: 300, subscription: "cancel_at_period_end = TRUE"},
+		{name: "cancels within the cycle", amount: 300, subscription: "cancel_at = '2025-05-20 00:00:00'"},
+		{name: "credit balance", amount: -200},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := setupMinInvoiceCycle(t, tc.amount, tc.subscription)
+
+			result, err := f.svc.GenerateInvoice(context.Background(), f.cycleID.String(), "")
+			require.NoError(t, err)
+			assert.Nil(t, result.CarryForward)
+			require.NotNil(t, result.Invoice)
+			assert.Equal(t, tc.amount, result.Invoice.SubtotalAmount)
+			assert.NotContains(t, f.audit.actions, "invoice.skipped_below_minimum")
+
+			var carried int64
+			require.NoError(t, f.db.Model(&invoicedomain.CarryForward{}).Count(&carried).Error)
+			assert.Zero(t, carried)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	templatedomain "github.com/smallbiznis/railzway/internal/invoicetemplate/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/internal/providers/pdf"
+	"gorm.io/gorm"
+)
+
+const pdfDateLayout = "January 2, 2006"
+
+// GenerateInvoicePDF renders the invoice as a PDF. A templateID forces that
+// template, which must belong to the org, so callers can preview a layout.
+// Otherwise the template is resolved as for HTML rendering, falling back to
+// the built-in layout when the org has no template at all.
+func (s *Service) GenerateInvoicePDF(ctx context.Context, invoiceID string, templateID *string) ([]byte, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, invoicedomain.ErrInvalidOrganization
+	}
+
+	id, err := parseID(strings.TrimSpace(invoiceID))
+	if err != nil {
+		return nil, invoicedomain.ErrInvalidInvoiceID
+	}
+
+	var forced *snowflake.ID
+	if templateID != nil {
+		tmplID, err := parseID(strings.TrimSpace(*templateID))
+		if err != nil {
+			return nil, invoicedomain.ErrInvalidTemplateID
+		}
+		forced = &tmplID
+	}
+
+	item, err := s.invoicerepo.FindOne(ctx, &invoicedomain.Invoice{ID: id, OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, invoicedomain.ErrInvoiceNotFound
+	}
+
+	return s.renderInvoicePDF(ctx, s.db, item, forced)
+}
+
+func (s *Service) renderInvoicePDF(ctx context.Context, db *gorm.DB, invoice *invoicedomain.Invoice, templateID *snowflake.ID) ([]byte, error) {
+	if s.pdfProvider == nil {
+		return nil, errors.New("pdf_provider_not_configured")
+	}
+
+	tmpl, err := s.resolvePDFTemplate(ctx, db, invoice, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.listInvoiceItems(ctx, db, invoice.OrgID, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := s.loadCustomer(ctx, db, invoice.OrgID, invoice.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var orgName string
+	if err := db.WithContext(ctx).Raw(
+		`SELECT name FROM organizations WHERE id = ?`,
+		invoice.OrgID,
+	).Scan(&orgName).Error; err != nil {
+		return nil, err
+	}
+
+	reader, err := s.pdfProvider.GenerateInvoice(ctx, buildInvoicePDFData(invoice, tmpl, orgName, customer, items))
+	if err != nil {
+		return nil, err
+	}
+	if reader == nil {
+		return nil, nil
+	}
+	return io.ReadAll(reader)
+}
+
+// resolvePDFTemplate returns the template to render invoice with, or nil for
+// the built-in layout. A finalized invoice renders with the template
+// snapshot taken at finalization unless a template is forced. A forced
+// templateID that is not the org's is an error; an org without any template
+// is not.
+func (s *Service) resolvePDFTemplate(ctx context.Context, db *gorm.DB, invoice *invoicedomain.Invoice, templateID *snowflake.ID) (*templatedomain.InvoiceTemplate, error) {
+	forced := templateID != nil
+	if !forced && len(invoice.TemplateSnapshot) > 0 {
+		var snapshot templatedomain.InvoiceTemplate
+		if err := json.Unmarshal(invoice.TemplateSnapshot, &snapshot); err != nil {
+			return nil, err
+		}
+		return &snapshot, nil
+	}
+	if !forced {
+		templateID = invoice.InvoiceTemplateID
+	}
+	tmpl, err := s.resolveTemplate(ctx, db, invoice.OrgID, invoice.CustomerID, templateID)
+	if errors.Is(err, invoicedomain.ErrInvoiceTemplateNotFound) && !forced {
+		return nil, nil
+	}
+	return tmpl, err
+}
+
+func buildInvoicePDFData(invoice *invoicedomain.Invoice, tmpl *templatedomain.InvoiceTemplate, orgName string, customer *customerRow, items []invoicedomain.InvoiceItem) pdf.InvoiceData {
+	view := buildTemplateView(tmpl)
+	if view.CompanyName != "" {
+		orgName = view.CompanyName
+	}
+	layout := templatedomain.LayoutDetailed
+	if tmpl != nil && tmpl.Layout != "" {
+		layout = tmpl.Layout
+	}
+
+	number := buildInvoiceView(invoice).Number
+	if number == "" {
+		number = invoice.ID.String()
+	}
+	period := ""
+	if invoice.PeriodStart != nil && invoice.PeriodEnd != nil {
+		period = fmt.Sprintf("%s – %s", pdfDate(invoice.PeriodStart), pdfDate(invoice.PeriodEnd))
+	}
+	total := formatMoney(invoice.TotalAmount, invoice.Currency)
+
+	data := pdf.InvoiceData{
+		Layout:        layout,
+		OrgName:       orgName,
+		InvoiceNumber: number,
+		IssueDate:     pdfDate(invoice.IssuedAt),
+		DueDate:       pdfDate(invoice.DueAt),
+		ServicePeriod: period,
+		TotalDue:      total,
+		Subtotal:      formatMoney(invoice.SubtotalAmount, invoice.Currency),
+		Total:         total,
+		AmountDue:     total,
+		Items:         make([]pdf.InvoiceItem, 0, len(items)),
+	}
+	if customer != nil {
+		data.BillToName = customer.Name
+		data.BillToEmail = customer.Email
+	}
+	for _, item := range items {
+		data.Items = append(data.Items, pdf.InvoiceItem{
+			Description: item.Description,
+			Qty:         int(item.Quantity),
+			UnitPrice:   formatMoney(item.UnitPrice, invoice.Currency),
+			Amount:      formatMoney(item.Amount, invoice.Currency),
+		})
+	}
+	return data
+}
+
+func pdfDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(pdfDateLayout)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	templatedomain "github.com/smallbiznis/railzway/internal/invoicetemplate/domain"
+	templaterepository "github.com/smallbiznis/railzway/internal/invoicetemplate/repository"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/internal/providers/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type recordingPDFProvider struct {
+	pdf.NoOpProvider
+	invoice pdf.InvoiceData
+}
+
+func (p *recordingPDFProvider) GenerateInvoice(ctx context.Context, data interface{}) (io.Reader, error) {
+	p.invoice = data.(pdf.InvoiceData)
+	return bytes.NewReader([]byte("%PDF")), nil
+}
+
+func TestGenerateInvoicePDF_ResolvesTemplate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&invoicedomain.Invoice{},
+		&invoicedomain.InvoiceItem{},
+		&templatedomain.InvoiceTemplate{},
+	))
+	for _, stmt := range []string{
+		"CREATE TABLE customers (id BIGINT, org_id BIGINT, name TEXT, email TEXT, invoice_template_id BIGINT, deleted_at DATETIME)",
+		"CREATE TABLE organizations (id BIGINT, name TEXT)",
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	provider := &recordingPDFProvider{}
+	templateRepo := templaterepository.Provide()
+	svc := NewService(ServiceParam{DB: db, Log: zap.NewNop(), GenID: node, TemplateRepo: templateRepo, PDFProvider: provider})
+
+	orgID := node.Generate()
+	otherOrgID := node.Generate()
+	customerID := node.Generate()
+	invoiceID := node.Generate()
+	issuedAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Exec("INSERT INTO organizations (id, name) VALUES (?, ?)", orgID, "Acme").Error)
+	require.NoError(t, db.Exec("INSERT INTO customers (id, org_id, name, email) VALUES (?, ?, ?, ?)", customerID, orgID, "Globex", "ap@globex.test").Error)
+	require.NoError(t, db.Create(&invoicedomain.Invoice{
+		ID:          invoiceID,
+		OrgID:       orgID,
+		CustomerID:  customerID,
+		Status:      invoicedomain.InvoiceStatusDraft,
+		TotalAmount: 1500,
+		Currency:    "USD",
+		IssuedAt:    &issuedAt,
+		Metadata:    datatypes.JSONMap{},
+	}).Error)
+
+	insertTemplate := func(org snowflake.ID, name, layout string, isDefault bool) snowflake.ID {
+		id := node.Generate()
+		require.NoError(t, templateRepo.Insert(context.Background(), db, &templatedomain.InvoiceTemplate{
+			ID:        id,
+			OrgID:     org,
+			Name:      name,
+			IsDefault: isDefault,
+			Locale:    "en",
+			Currency:  "USD",
+			Layout:    layout,
+			Header:    datatypes.JSONMap{"company_name": name},
+			Footer:    datatypes.JSONMap{},
+			Style:     datatypes.JSONMap{},
+			CreatedAt: issuedAt,
+			UpdatedAt: issuedAt,
+		}))
+		return id
+	}
+
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	generate := func(templateID *string) (pdf.InvoiceData, error) {
+		provider.invoice = pdf.InvoiceData{}
+		_, err := svc.GenerateInvoicePDF(ctx, invoiceID.String(), templateID)
+		return provider.invoice, err
+	}
+
+	t.Run("system default without templates", func(t *testing.T) {
+		data, err := generate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, templatedomain.LayoutDetailed, data.Layout)
+		assert.Equal(t, "Acme", data.OrgName)
+		assert.Equal(t, "Globex", data.BillToName)
+	})
+
+	insertTemplate(orgID, "Org summary", templatedomain.LayoutSummary, true)
+	t.Run("org default", func(t *testing.T) {
+		data, err := generate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, templatedomain.LayoutSummary, data.Layout)
+		assert.Equal(t, "Org summary", data.OrgName)
+	})
+
+	overrideID := insertTemplate(orgID, "Customer detailed", templatedomain.LayoutDetailed, false)
+	found, err := templateRepo.SetCustomerTemplateID(context.Background(), db, orgID, customerID, &overrideID)
+	require.NoError(t, err)
+	require.True(t, found)
+	t.Run("customer override wins over org default", func(t *testing.T) {
+		data, err := generate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Customer detailed", data.OrgName)
+	})
+
+	previewID := insertTemplate(orgID, "Preview", templatedomain.LayoutSummary, false)
+	t.Run("forced template", func(t *testing.T) {
+		raw := previewID.String()
+		data, err := generate(&raw)
+		require.NoError(t, err)
+		assert.Equal(t, "Preview", data.OrgName)
+		assert.Equal(t, templatedomain.LayoutSummary, data.Layout)
+	})
+
+	foreignID := insertTemplate(otherOrgID, "Foreign", templatedomain.LayoutDetailed, true)
+	t.Run("forced template of another org", func(t *testing.T) {
+		raw := foreignID.String()
+		_, err := generate(&raw)
+		assert.ErrorIs(t, err, invoicedomain.ErrInvoiceTemplateNotFound)
+	})
+
+	t.Run("finalized invoice keeps its template snapshot", func(t *testing.T) {
+		snapshot, err := json.Marshal(templatedomain.InvoiceTemplate{
+			ID:     overrideID,
+			OrgID:  orgID,
+			Layout: templatedomain.LayoutSummary,
+			Header: datatypes.JSONMap{"company_name": "Customer detailed at finalization"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(
+			"UPDATE invoices SET status = ?, invoice_template_id = ?, template_snapshot = ? WHERE id = ?",
+			invoicedomain.InvoiceStatusFinalized, overrideID, snapshot, invoiceID,
+		).Error)
+
+		data, err := generate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Customer detailed at finalization", data.OrgName)
+		assert.Equal(t, templatedomain.LayoutSummary, data.Layout)
+
+		raw := previewID.String()
+		data, err = generate(&raw)
+		require.NoError(t, err)
+		assert.Equal(t, "Preview", data.OrgName, "a forced template still previews")
+	})
+
+	t.Run("invalid template id", func(t *testing.T) {
+		raw := "not-an-id"
+		_, err := generate(&raw)
+		assert.ErrorIs(t, err, invoicedomain.ErrInvalidTemplateID)
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+)
+
+// PreviewInvoice returns the invoice GenerateInvoice would create for the
+// billing cycle, computed the same way but without persisting anything or
+// taking locks. It fails with ErrMissingRatingResults until the cycle is
+// rated.
+func (s *Service) PreviewInvoice(ctx context.Context, billingCycleID string) (invoicedomain.InvoicePreview, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return invoicedomain.InvoicePreview{}, invoicedomain.ErrInvalidOrganization
+	}
+
+	cycleID, err := parseID(strings.TrimSpace(billingCycleID))
+	if err != nil {
+		return invoicedomain.InvoicePreview{}, invoicedomain.ErrInvalidBillingCycle
+	}
+
+	var cycle billingCycleRow
+	if err := s.db.WithContext(ctx).Raw(
+		`SELECT id, org_id, subscription_id, period_start, period_end, status
+		 FROM billing_cycles
+		 WHERE id = ? AND org_id = ?`,
+		cycleID,
+		orgID,
+	).Scan(&cycle).Error; err != nil {
+		return invoicedomain.InvoicePreview{}, err
+	}
+	if cycle.ID == 0 {
+		return invoicedomain.InvoicePreview{}, invoicedomain.ErrBillingCycleNotFound
+	}
+	if !cycle.PeriodEnd.After(cycle.PeriodStart) {
+		return invoicedomain.InvoicePreview{}, invoicedomain.ErrInvalidBillingCycle
+	}
+
+	draft, err := s.computeInvoiceDraft(ctx, s.db, cycle)
+	if err != nil {
+		return invoicedomain.InvoicePreview{}, err
+	}
+
+	items := make([]invoicedomain.InvoicePreviewItem, 0, len(draft.Items))
+	for _, item := range draft.Items {
+		previewItem := invoicedomain.InvoicePreviewItem{
+			LineType:    item.LineType,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		}
+		if item.RatingResultID != nil {
+			previewItem.RatingResultID = item.RatingResultID.String()
+		}
+		items = append(items, previewItem)
+	}
+
+	return invoicedomain.InvoicePreview{
+		BillingCycleID: cycle.ID.String(),
+		SubscriptionID: cycle.SubscriptionID.String(),
+		CustomerID:     draft.CustomerID.String(),
+		Currency:       draft.Currency,
+		SubtotalAmount: draft.Subtotal,
+		TaxAmount:      draft.TaxAmount,
+		TotalAmount:    draft.TotalAmount,
+		PeriodStart:    cycle.PeriodStart,
+		PeriodEnd:      cycle.PeriodEnd,
+		Items:          items,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	ratingdomain "github.com/smallbiznis/railzway/internal/rating/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestPreviewInvoice(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&ratingdomain.RatingResult{},
+		&invoicedomain.Invoice{},
+		&invoicedomain.InvoiceItem{},
+		&invoicedomain.SubscriptionEntitlement{},
+	))
+	for _, stmt := range []string{
+		"CREATE TABLE billing_cycles (id BIGINT, org_id BIGINT, subscription_id BIGINT, period_start DATETIME, period_end DATETIME, status TEXT)",
+		"CREATE TABLE subscriptions (id BIGINT, org_id BIGINT, customer_id BIGINT)",
+		"CREATE TABLE ledger_entries (id BIGINT, org_id BIGINT, source_type TEXT, source_id BIGINT, currency TEXT, occurred_at DATETIME)",
+		"CREATE TABLE ledger_entry_lines (id BIGINT, ledger_entry_id BIGINT, account_id BIGINT, direction TEXT, amount BIGINT)",
+		"CREATE TABLE ledger_accounts (id BIGINT, code TEXT, name TEXT)",
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	svc := NewService(ServiceParam{DB: db, Log: zap.NewNop(), GenID: node})
+
+	orgID := node.Generate()
+	subID := node.Generate()
+	customerID := node.Generate()
+	end := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -1, 0)
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	seedCycle := func() snowflake.ID {
+		id := node.Generate()
+		require.NoError(t, db.Exec(
+			"INSERT INTO billing_cycles (id, org_id, subscription_id, period_start, period_end, status) VALUES (?, ?, ?, ?, ?, ?)",
+			id, orgID, subID, start, end, billingcycledomain.BillingCycleStatusClosed,
+		).Error)
+		return id
+	}
+	require.NoError(t, db.Exec("INSERT INTO subscriptions (id, org_id, customer_id) VALUES (?, ?, ?)", subID, orgID, customerID).Error)
+	require.NoError(t, db.Create(&invoicedomain.SubscriptionEntitlement{
+		ID:             node.Generate(),
+		OrgID:          orgID,
+		SubscriptionID: subID,
+		FeatureCode:    "api_calls",
+		FeatureName:    "API Calls",
+		EffectiveFrom:  start.Add(-time.Hour),
+	}).Error)
+
+	t.Run("rated cycle", func(t *testing.T) {
+		cycleID := seedCycle()
+		ratingID := node.Generate()
+		meterID := node.Generate()
+		require.NoError(t, db.Create(&ratingdomain.RatingResult{
+			ID:             ratingID,
+			OrgID:          orgID,
+			SubscriptionID: subID,
+			BillingCycleID: cycleID,
+			MeterID:        &meterID,
+			PriceID:        node.Generate(),
+			FeatureCode:    "api_calls",
+			Source:         "usage",
+			Quantity:       10,
+			UnitPrice:      150,
+			Amount:         1500,
+			Currency:       "USD",
+			PeriodStart:    start,
+			PeriodEnd:      end,
+			Checksum:       "preview",
+			CreatedAt:      end,
+		}).Error)
+		entryID := node.Generate()
+		require.NoError(t, db.Exec(
+			"INSERT INTO ledger_entries (id, org_id, source_type, source_id, currency, occurred_at) VALUES (?, ?, ?, ?, 'USD', ?)",
+			entryID, orgID, ledgerdomain.SourceTypeBillingCycle, cycleID, end,
+		).Error)
+		accountID := node.Generate()
+		require.NoError(t, db.Exec("INSERT INTO ledger_accounts (id, code, name) VALUES (?, 'revenue_usage', 'Usage Revenue')", accountID).Error)
+		require.NoError(t, db.Exec(
+			"INSERT INTO ledger_entry_lines (id, ledger_entry_id, account_id, direction, amount) VALUES (?, ?, ?, ?, 1500), (?, ?, ?, ?, 1500)",
+			node.Generate(), entryID, accountID, ledgerdomain.LedgerEntryDirectionCredit,
+			node.Generate(), entryID, accountID, ledgerdomain.LedgerEntryDirectionDebit,
+		).Error)
+
+		preview, err := svc.PreviewInvoice(ctx, cycleID.String())
+		require.NoError(t, err)
+
+		assert.Equal(t, customerID.String(), preview.CustomerID)
+		assert.Equal(t, "USD", preview.Currency)
+		assert.Equal(t, int64(1500), preview.SubtotalAmount)
+		require.Len(t, preview.Items, 1)
+		item := preview.Items[0]
+		assert.Equal(t, ratingID.String(), item.RatingResultID)
+		assert.Equal(t, invoicedomain.InvoiceItemLineTypeUsage, item.LineType)
+		assert.Contains(t, item.Description, "API Calls")
+		assert.Equal(t, int64(1500), item.Amount)
+
+		var invoices, items int64
+		require.NoError(t, db.Model(&invoicedomain.Invoice{}).Count(&invoices).Error)
+		require.NoError(t, db.Model(&invoicedomain.InvoiceItem{}).Count(&items).Error)
+		assert.Zero(t, invoices)
+		assert.Zero(t, items)
+	})
+
+	t.Run("unrated cycle", func(t *testing.T) {
+		_, err := svc.PreviewInvoice(ctx, seedCycle().String())
+		assert.ErrorIs(t, err, invoicedomain.ErrMissingRatingResults)
+	})
+
+	t.Run("cycle of another org", func(t *testing.T) {
+		otherCtx := orgcontext.WithOrgID(context.Background(), int64(node.Generate()))
+		_, err := svc.PreviewInvoice(otherCtx, seedCycle().String())
+		assert.ErrorIs(t, err, invoicedomain.ErrBillingCycleNotFound)
+	})
+}
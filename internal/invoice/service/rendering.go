@@ -10,7 +10,6 @@ import (
 	"github.com/bwmarrin/snowflake"
 	customerdomain "github.com/smallbiznis/railzway/internal/customer/domain"
 	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
-	invoiceformat "github.com/smallbiznis/railzway/internal/invoice/format"
 	"github.com/smallbiznis/railzway/internal/invoice/render"
 	templatedomain "github.com/smallbiznis/railzway/internal/invoicetemplate/domain"
 	"github.com/smallbiznis/railzway/internal/orgcontext"
@@ -76,7 +75,7 @@ func (s *Service) renderInvoiceHTML(ctx context.Context, db *gorm.DB, invoice *i
 		return "", nil, errors.New("renderer_not_configured")
 	}
 
-	tmpl, err := s.resolveTemplate(ctx, db, invoice.OrgID, invoice.InvoiceTemplateID)
+	tmpl, err := s.resolveTemplate(ctx, db, invoice.OrgID, invoice.CustomerID, invoice.InvoiceTemplateID)
 	if err != nil {
 		return "", nil, err
 	}
@@ -105,7 +104,9 @@ func (s *Service) renderInvoiceHTML(ctx context.Context, db *gorm.DB, invoice *i
 	return html, tmpl, nil
 }
 
-func (s *Service) resolveTemplate(ctx context.Context, db *gorm.DB, orgID snowflake.ID, templateID *snowflake.ID) (*templatedomain.InvoiceTemplate, error) {
+// resolveTemplate returns templateID's template when it is set, else the
+// customer's override, else the org default.
+func (s *Service) resolveTemplate(ctx context.Context, db *gorm.DB, orgID, customerID snowflake.ID, templateID *snowflake.ID) (*templatedomain.InvoiceTemplate, error) {
 	if s.templateRepo == nil {
 		return nil, invoicedomain.ErrInvoiceTemplateNotFound
 	}
@@ -120,6 +121,23 @@ func (s *Service) resolveTemplate(ctx context.Context, db *gorm.DB, orgID snowfl
 		return item, nil
 	}
 
+	if customerID != 0 {
+		override, err := s.templateRepo.FindCustomerTemplateID(ctx, db, orgID, customerID)
+		if err != nil {
+			return nil, err
+		}
+		if override != nil && *override != 0 {
+			item, err := s.templateRepo.FindByID(ctx, db, orgID, *override)
+			if err != nil {
+				return nil, err
+			}
+			// An override the org no longer has falls back to the default.
+			if item != nil {
+				return item, nil
+			}
+		}
+	}
+
 	item, err := s.templateRepo.FindDefault(ctx, db, orgID)
 	if err != nil {
 		return nil, err
@@ -192,20 +210,13 @@ func buildInvoiceView(invoice *invoicedomain.Invoice) render.InvoiceView {
 	if invoice == nil {
 		return render.InvoiceView{}
 	}
+	// Invoices are numbered at finalization; drafts render without a number.
 	number := ""
-	if invoice.InvoiceSeq != nil && invoice.IssuedAt != nil {
-		formatted, err := invoiceformat.FormatInvoiceNumber(
-			invoiceformat.DefaultInvoiceNumberTemplate,
-			*invoice.IssuedAt,
-			*invoice.InvoiceSeq,
-		)
-		if err == nil {
-			number = formatted
-		} else {
+	if invoice.InvoiceSeq != nil {
+		number = invoice.InvoiceNumber
+		if number == "" {
 			number = fmtInvoiceNumber(*invoice.InvoiceSeq)
 		}
-	} else if invoice.InvoiceSeq != nil {
-		number = fmtInvoiceNumber(*invoice.InvoiceSeq)
 	}
 	return render.InvoiceView{
 		ID:             invoice.ID.String(),
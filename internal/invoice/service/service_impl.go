@@ -4,9 +4,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math"
 	"strings"
 	"time"
@@ -14,11 +14,13 @@ import (
 	"github.com/bwmarrin/snowflake"
 	auditdomain "github.com/smallbiznis/railzway/internal/audit/domain"
 	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	emaildeliverydomain "github.com/smallbiznis/railzway/internal/emaildelivery/domain"
 	"github.com/smallbiznis/railzway/internal/events"
 	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
 	invoiceformat "github.com/smallbiznis/railzway/internal/invoice/format"
 	"github.com/smallbiznis/railzway/internal/invoice/render"
 	templatedomain "github.com/smallbiznis/railzway/internal/invoicetemplate/domain"
+	"github.com/smallbiznis/railzway/internal/ledger"
 	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
 	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
 	"github.com/smallbiznis/railzway/internal/orgcontext"
@@ -32,6 +34,7 @@ import (
 	taxservice "github.com/smallbiznis/railzway/internal/tax/service"
 	"github.com/smallbiznis/railzway/pkg/db/option"
 	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/smallbiznis/railzway/pkg/money"
 	"github.com/smallbiznis/railzway/pkg/repository"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -106,10 +109,12 @@ type ServiceParam struct {
 	Renderer       render.Renderer
 	PublicTokenSvc publicinvoicedomain.PublicInvoiceTokenService
 	TaxResolver    taxdomain.TaxResolver
+	TaxCalculator  taxdomain.TaxCalculator `optional:"true"`
 	LedgerSvc      ledgerdomain.Service
 	Outbox         *events.Outbox `optional:"true"`
 	EmailProvider  email.Provider
 	PDFProvider    pdf.Provider
+	DeliverySvc    emaildeliverydomain.Service `optional:"true"`
 }
 
 type Service struct {
@@ -123,10 +128,12 @@ type Service struct {
 	renderer       render.Renderer
 	publicTokenSvc publicinvoicedomain.PublicInvoiceTokenService
 	taxResolver    taxdomain.TaxResolver
+	taxCalculator  taxdomain.TaxCalculator
 	ledgerSvc      ledgerdomain.Service
 	outbox         *events.Outbox
 	emailProvider  email.Provider
 	pdfProvider    pdf.Provider
+	deliverySvc    emaildeliverydomain.Service
 }
 
 func NewService(p ServiceParam) invoicedomain.Service {
@@ -141,10 +148,12 @@ func NewService(p ServiceParam) invoicedomain.Service {
 		renderer:       p.Renderer,
 		publicTokenSvc: p.PublicTokenSvc,
 		taxResolver:    p.TaxResolver,
+		taxCalculator:  p.TaxCalculator,
 		ledgerSvc:      p.LedgerSvc,
 		outbox:         p.Outbox,
 		emailProvider:  p.EmailProvider,
 		pdfProvider:    p.PDFProvider,
+		deliverySvc:    p.DeliverySvc,
 	}
 }
 
@@ -307,13 +316,30 @@ func (s *Service) GetByID(ctx context.Context, id string) (invoicedomain.Invoice
 	return *item, nil
 }
 
-func (s *Service) GenerateInvoice(ctx context.Context, billingCycleID string) (*invoicedomain.Invoice, error) {
+// GenerateInvoice creates the draft invoice for a closed billing cycle.
+//
+// Calls are idempotent on idempotencyKey (falling back to the key derived
+// from the cycle) and on the cycle itself: a repeated or concurrent call
+// returns the existing invoice with Duplicate set instead of creating a
+// second one. Reusing a key for a different cycle is rejected.
+func (s *Service) GenerateInvoice(ctx context.Context, billingCycleID string, idempotencyKey string) (*invoicedomain.GenerateInvoiceResult, error) {
 	cycleID, err := parseID(strings.TrimSpace(billingCycleID))
 	if err != nil {
 		return nil, invoicedomain.ErrInvalidBillingCycle
 	}
 
-	var createdInvoice *invoicedomain.Invoice
+	key := strings.TrimSpace(idempotencyKey)
+	if key == "" {
+		key = invoicedomain.GenerationIdempotencyKey(cycleID)
+	}
+	if len(key) > invoicedomain.MaxIdempotencyKeyLength {
+		return nil, invoicedomain.ErrInvalidIdempotencyKey
+	}
+
+	var (
+		result    *invoicedomain.GenerateInvoiceResult
+		minAmount int64
+	)
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		cycle, err := s.loadBillingCycleForUpdate(ctx, tx, cycleID)
 		if err != nil {
@@ -329,100 +355,108 @@ func (s *Service) GenerateInvoice(ctx context.Context, billingCycleID string) (*
 			return invoicedomain.ErrInvalidBillingCycle
 		}
 
-		existingID, err := s.findInvoiceByBillingCycle(ctx, tx, cycle.ID)
+		existing, err := s.findGeneratedInvoice(ctx, tx, cycle.OrgID, cycle.ID, key)
 		if err != nil {
 			return err
 		}
-		if existingID != 0 {
+		if existing != nil {
+			result = &invoicedomain.GenerateInvoiceResult{Invoice: existing, Duplicate: true}
 			return nil
 		}
-
-		if err := s.lockOrganization(ctx, tx, cycle.OrgID); err != nil {
-			return err
-		}
-
-		rating, err := s.loadRating(ctx, tx, cycle.ID)
+		carried, err := s.findCarryForward(ctx, tx, cycle.OrgID, cycle.ID)
 		if err != nil {
 			return err
 		}
-		if rating == nil {
-			return invoicedomain.ErrMissingRatingResults
+		if carried != nil {
+			result = &invoicedomain.GenerateInvoiceResult{CarryForward: carried, Duplicate: true}
+			return nil
 		}
 
-		subscription, err := s.loadSubscription(ctx, tx, cycle.OrgID, cycle.SubscriptionID)
-		if err != nil {
+		if err := s.lockOrganization(ctx, tx, cycle.OrgID); err != nil {
 			return err
 		}
-		if subscription == nil || subscription.CustomerID == 0 {
-			return invoicedomain.ErrInvalidBillingCycle
-		}
 
-		entry, err := s.loadLedgerEntryForCycle(ctx, tx, cycle.OrgID, cycle.ID)
+		draft, err := s.computeInvoiceDraft(ctx, tx, *cycle)
 		if err != nil {
 			return err
 		}
-		if entry == nil {
-			return invoicedomain.ErrMissingLedgerEntry
-		}
 
-		var subtotal int64
-		lines, err := s.listLedgerEntryLines(ctx, tx, entry.ID)
+		minAmount, err = s.loadMinInvoiceAmount(ctx, tx, cycle.OrgID)
 		if err != nil {
 			return err
 		}
-		if len(lines) == 0 {
-			return invoicedomain.ErrMissingLedgerEntry
-		}
-
-		creditLines := make([]ledgerEntryLineRow, 0, len(lines))
-		for _, line := range lines {
-			if line.Direction != ledgerdomain.LedgerEntryDirectionCredit {
-				continue
+		// A credit balance is never carried, and neither is the final cycle
+		// of a subscription, since no later cycle would bill it.
+		carry := draft.Subtotal >= 0 && draft.Subtotal < minAmount
+		if carry {
+			final, err := s.isFinalCycle(ctx, tx, *cycle)
+			if err != nil {
+				return err
 			}
-			subtotal += line.Amount
-			creditLines = append(creditLines, line)
-		}
-		if len(creditLines) == 0 {
-			return invoicedomain.ErrMissingLedgerEntry
+			carry = !final
 		}
-
-		invoiceNumber, err := s.nextInvoiceNumber(ctx, tx, cycle.OrgID)
-		if err != nil {
-			return err
+		if carry {
+			carried, err := s.carryForwardDraft(ctx, tx, *cycle, draft)
+			if err != nil {
+				return err
+			}
+			result = &invoicedomain.GenerateInvoiceResult{CarryForward: carried}
+			return nil
 		}
 
+		// The invoice number is assigned at finalization; drafts carry a
+		// placeholder so a discarded draft never consumes a sequence number.
 		now := time.Now().UTC()
-		displayNumber, err := invoiceformat.FormatInvoiceNumber(invoiceformat.DefaultInvoiceNumberTemplate, now, invoiceNumber)
-		if err != nil {
-			return err
-		}
 		invoiceID := s.genID.Generate()
 		invoice := invoicedomain.Invoice{
 			ID:             invoiceID,
 			OrgID:          cycle.OrgID,
-			InvoiceSeq:     &invoiceNumber,
-			InvoiceNumber:  displayNumber,
+			InvoiceNumber:  draftInvoiceNumber(invoiceID),
 			BillingCycleID: cycle.ID,
 			SubscriptionID: cycle.SubscriptionID,
-			CustomerID:     subscription.CustomerID,
+			CustomerID:     draft.CustomerID,
 			Status:         invoicedomain.InvoiceStatusDraft,
-			SubtotalAmount: subtotal,
-			Currency:       entry.Currency,
+			SubtotalAmount: draft.Subtotal,
+			TaxAmount:      draft.TaxAmount,
+			TotalAmount:    draft.TotalAmount,
+			Currency:       draft.Currency,
 			PeriodStart:    &cycle.PeriodStart,
 			PeriodEnd:      &cycle.PeriodEnd,
+			IdempotencyKey: &key,
 			CreatedAt:      now,
 			UpdatedAt:      now,
 		}
+		if len(draft.TaxLines) > 0 {
+			invoice.TaxCode = &draft.TaxLines[0].Code
+			invoice.TaxRate = &draft.TaxLines[0].Rate
+		}
 		inserted, err := s.insertInvoice(ctx, tx, invoice)
 		if err != nil {
 			return err
 		}
 		if !inserted {
+			// A concurrent call committed first; hand back its invoice.
+			existing, err := s.findGeneratedInvoice(ctx, tx, cycle.OrgID, cycle.ID, key)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				return invoicedomain.ErrIdempotencyKeyConflict
+			}
+			result = &invoicedomain.GenerateInvoiceResult{Invoice: existing, Duplicate: true}
 			return nil
 		}
-		createdInvoice = &invoice
+		result = &invoicedomain.GenerateInvoiceResult{Invoice: &invoice}
 
-		if err := s.listInvoiceItemPartsFromRating(ctx, tx, *cycle, invoiceID); err != nil {
+		for _, item := range draft.Items {
+			item.ID = s.genID.Generate()
+			item.InvoiceID = invoiceID
+			item.CreatedAt = now
+			if err := s.insertInvoiceItem(ctx, tx, item); err != nil {
+				return err
+			}
+		}
+		if err := s.insertInvoiceTaxLines(ctx, tx, invoice, draft.TaxLines, now); err != nil {
 			return err
 		}
 
@@ -432,11 +466,97 @@ func (s *Service) GenerateInvoice(ctx context.Context, billingCycleID string) (*
 		return nil, err
 	}
 
-	if createdInvoice != nil {
-		s.emitAudit(ctx, "invoice.generate", createdInvoice, nil)
+	if !result.Duplicate {
+		if result.CarryForward != nil {
+			s.emitCarryForwardAudit(ctx, result.CarryForward, minAmount)
+		} else {
+			s.emitAudit(ctx, "invoice.generate", result.Invoice, nil)
+		}
+	}
+
+	return result, nil
+}
+
+// invoiceDraft is the content GenerateInvoice writes for a billing cycle.
+// Items carry neither IDs nor an invoice ID yet. Subtotal excludes tax;
+// TotalAmount adds the exclusive tax on top of it.
+type invoiceDraft struct {
+	CustomerID  snowflake.ID
+	Currency    string
+	Subtotal    int64
+	TaxAmount   int64
+	TotalAmount int64
+	Items       []invoicedomain.InvoiceItem
+	TaxLines    []taxdomain.TaxLine
+	LedgerLines []ledgerEntryLineRow
+}
+
+// computeInvoiceDraft computes the invoice for a cycle from its rating
+// results and ledger entry without writing anything. GenerateInvoice and
+// PreviewInvoice both build on it, so a preview matches the generated
+// invoice.
+func (s *Service) computeInvoiceDraft(ctx context.Context, tx *gorm.DB, cycle billingCycleRow) (*invoiceDraft, error) {
+	rating, err := s.loadRating(ctx, tx, cycle.ID)
+	if err != nil {
+		return nil, err
+	}
+	if rating == nil {
+		return nil, invoicedomain.ErrMissingRatingResults
+	}
+
+	subscription, err := s.loadSubscription(ctx, tx, cycle.OrgID, cycle.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil || subscription.CustomerID == 0 {
+		return nil, invoicedomain.ErrInvalidBillingCycle
+	}
+
+	entry, err := s.loadLedgerEntryForCycle(ctx, tx, cycle.OrgID, cycle.ID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, invoicedomain.ErrMissingLedgerEntry
+	}
+
+	var subtotal int64
+	lines, err := s.listLedgerEntryLines(ctx, tx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, invoicedomain.ErrMissingLedgerEntry
+	}
+
+	creditLines := make([]ledgerEntryLineRow, 0, len(lines))
+	for _, line := range lines {
+		if line.Direction != ledgerdomain.LedgerEntryDirectionCredit {
+			continue
+		}
+		subtotal += line.Amount
+		creditLines = append(creditLines, line)
+	}
+	if len(creditLines) == 0 {
+		return nil, invoicedomain.ErrMissingLedgerEntry
+	}
+
+	items, err := s.buildInvoiceItemsFromRating(ctx, tx, cycle)
+	if err != nil {
+		return nil, err
 	}
 
-	return createdInvoice, nil
+	draft := &invoiceDraft{
+		CustomerID:  subscription.CustomerID,
+		Currency:    entry.Currency,
+		Subtotal:    subtotal,
+		Items:       items,
+		LedgerLines: lines,
+	}
+	if err := s.calculateDraftTax(ctx, tx, cycle, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
 }
 
 func (s *Service) listInvoiceItemPartsFromRating(
@@ -445,11 +565,35 @@ func (s *Service) listInvoiceItemPartsFromRating(
 	cycle billingCycleRow,
 	invoiceID snowflake.ID,
 ) error {
+	items, err := s.buildInvoiceItemsFromRating(ctx, tx, cycle)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, item := range items {
+		item.ID = s.genID.Generate()
+		item.InvoiceID = invoiceID
+		item.CreatedAt = now
+		if err := s.insertInvoiceItem(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildInvoiceItemsFromRating returns the invoice lines for the cycle's
+// rating results, described from the subscription's entitlements.
+func (s *Service) buildInvoiceItemsFromRating(
+	ctx context.Context,
+	tx *gorm.DB,
+	cycle billingCycleRow,
+) ([]invoicedomain.InvoiceItem, error) {
 
 	// 1. Load active entitlements for the cycle
 	entitlements, err := s.listEntitlementsForCycle(ctx, tx, cycle.OrgID, cycle.SubscriptionID, cycle.PeriodStart, cycle.PeriodEnd)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Index entitlements by FeatureCode for joining
 	entitlementMap := make(map[string]invoicedomain.SubscriptionEntitlement)
@@ -488,10 +632,10 @@ func (s *Service) listInvoiceItemPartsFromRating(
 	`).
 		Where("billing_cycle_id = ?", cycle.ID).
 		Scan(&rows).Error; err != nil {
-		return err
+		return nil, err
 	}
 
-	now := time.Now().UTC()
+	items := make([]invoicedomain.InvoiceItem, 0, len(rows))
 	for _, r := range rows {
 		// Strict Join Rule: WAS Must match entitlement
 		// Now: Optional.
@@ -504,16 +648,13 @@ func (s *Service) listInvoiceItemPartsFromRating(
 		}
 
 		invoiceItem := invoicedomain.InvoiceItem{
-			ID:             s.genID.Generate(),
 			OrgID:          r.OrgID,
-			InvoiceID:      invoiceID,
 			RatingResultID: &r.ID,
 			Quantity:       float64(r.Quantity),
 			UnitPrice:      r.UnitPrice,
 			Amount:         r.Amount,
 			Description:    description, // Use snapshot data or fallback
 			LineType:       invoicedomain.InvoiceItemLineTypeUsage,
-			CreatedAt:      now,
 		}
 
 		// Refine Line Type based on Entitlement or Rating?
@@ -522,6 +663,15 @@ func (s *Service) listInvoiceItemPartsFromRating(
 			invoiceItem.LineType = invoicedomain.InvoiceItemLineTypeSubscription
 		}
 
+		// Amounts carried from earlier cycles under the minimum invoice
+		// amount belong to no period of this one.
+		if r.Source == ratingdomain.SourceCarryForward {
+			invoiceItem.LineType = invoicedomain.InvoiceItemLineTypeOneOff
+			invoiceItem.Description = "Balance carried forward from previous period"
+			items = append(items, invoiceItem)
+			continue
+		}
+
 		// Enrich description (e.g. usage dates, rate)
 		part := invoiceItemPart{
 			Type:        invoiceItem.LineType,
@@ -533,12 +683,10 @@ func (s *Service) listInvoiceItemPartsFromRating(
 		}
 		invoiceItem.Description = s.formatInvoiceItemDescription(part, cycle)
 
-		if err := s.insertInvoiceItem(ctx, tx, invoiceItem); err != nil {
-			return err
-		}
+		items = append(items, invoiceItem)
 	}
 
-	return nil
+	return items, nil
 }
 
 func (s *Service) listEntitlementsForCycle(
@@ -640,10 +788,7 @@ func (s *Service) formatInvoiceItemDescription(
 		if p.RateAmount >= 0 {
 			// High precision rate for description
 			c := strings.ToUpper(p.Currency)
-			decimals, ok := currencyDecimals[c]
-			if !ok {
-				decimals = 2
-			}
+			decimals := money.MinorUnits(c)
 			divisor := math.Pow(10, float64(decimals))
 			val := float64(p.RateAmount) / divisor
 
@@ -702,47 +847,27 @@ func (s *Service) FinalizeInvoice(ctx context.Context, invoiceID string) error {
 			return invoicedomain.ErrInvalidSubtotal
 		}
 
-		// Tax is resolved and frozen at finalize-time.
-		taxDef, err := s.taxResolver.ResolveForInvoice(ctx, invoice.OrgID, invoice.CustomerID)
-		if err != nil {
+		now := time.Now().UTC()
+		if err := s.assignInvoiceNumber(ctx, tx, invoice, now); err != nil {
 			return err
 		}
-		invoice.TaxRate = nil
-		invoice.TaxCode = nil
-		invoice.TaxAmount = 0
 
-		now := time.Now().UTC()
 		dueAt := now.AddDate(0, 0, 30)
 
-		if taxDef != nil {
-			switch taxDef.TaxMode {
-			case taxdomain.TaxModeExclusive:
-				invoice.TaxAmount = taxservice.ComputeTaxExclusive(invoice.SubtotalAmount, taxDef.Rate)
-			case taxdomain.TaxModeInclusive:
-				invoice.TaxAmount = taxservice.ComputeTaxInclusive(invoice.SubtotalAmount, taxDef.Rate)
-			default:
-				invoice.TaxAmount = 0
-			}
-			invoice.TaxRate = taxDef.Rate
-			invoice.TaxCode = &taxDef.Code
-
-			// SNAPSHOT: Create InvoiceTaxLine
-			taxLine := invoicedomain.InvoiceTaxLine{
-				ID:        s.genID.Generate(),
-				OrgID:     invoice.OrgID,
-				InvoiceID: invoice.ID,
-				TaxCode:   &taxDef.Code,
-				TaxName:   taxDef.Name,
-				TaxMode:   string(taxDef.TaxMode),
-				TaxRate:   *taxDef.Rate,
-				Amount:    invoice.TaxAmount,
-				CreatedAt: now,
-			}
-			if err := tx.WithContext(ctx).Create(&taxLine).Error; err != nil {
+		// Tax calculated at generation is kept; otherwise it is resolved from
+		// the org's tax definition and frozen at finalize-time.
+		var generatedTax []invoicedomain.InvoiceTaxLine
+		if s.taxCalculator != nil {
+			generatedTax, err = s.listInvoiceTaxLines(ctx, tx, invoice.ID)
+			if err != nil {
 				return err
 			}
 		}
-		invoice.TotalAmount = invoice.SubtotalAmount + invoice.TaxAmount
+		if len(generatedTax) > 0 {
+			applyInvoiceTaxLines(invoice, generatedTax)
+		} else if err := s.applyTaxDefinition(ctx, tx, invoice, now); err != nil {
+			return err
+		}
 
 		// Snapshot rendered output at finalization so future template edits never change history.
 		invoice.Status = invoicedomain.InvoiceStatusFinalized
@@ -752,6 +877,11 @@ func (s *Service) FinalizeInvoice(ctx context.Context, invoiceID string) error {
 		}
 		if tmpl != nil {
 			invoice.InvoiceTemplateID = &tmpl.ID
+			snapshot, err := json.Marshal(tmpl)
+			if err != nil {
+				return err
+			}
+			invoice.TemplateSnapshot = snapshot
 		}
 		invoice.RenderedHTML = &renderedHTML
 		checksum := sha256.Sum256([]byte(renderedHTML))
@@ -763,13 +893,16 @@ func (s *Service) FinalizeInvoice(ctx context.Context, invoiceID string) error {
 
 		if err := tx.WithContext(ctx).Exec(
 			`UPDATE invoices
-			 SET status = ?, finalized_at = ?, issued_at = ?, due_at = ?, invoice_template_id = ?, rendered_html = ?, rendered_pdf_url = ?, tax_rate = ?, tax_code = ?, tax_amount = ?, total_amount = ?, updated_at = ?
+			 SET status = ?, invoice_seq = ?, invoice_number = ?, finalized_at = ?, issued_at = ?, due_at = ?, invoice_template_id = ?, template_snapshot = ?, rendered_html = ?, rendered_pdf_url = ?, tax_rate = ?, tax_code = ?, tax_amount = ?, total_amount = ?, updated_at = ?
 			 WHERE id = ?`,
 			invoice.Status,
+			invoice.InvoiceSeq,
+			invoice.InvoiceNumber,
 			invoice.FinalizedAt,
 			invoice.IssuedAt,
 			invoice.DueAt,
 			invoice.InvoiceTemplateID,
+			invoice.TemplateSnapshot,
 			invoice.RenderedHTML,
 			invoice.RenderedPDFURL,
 			invoice.TaxRate,
@@ -840,23 +973,94 @@ func (s *Service) FinalizeInvoice(ctx context.Context, invoiceID string) error {
 	return nil
 }
 
-func (s *Service) VoidInvoice(ctx context.Context, invoiceID string, reason string) error {
+// applyTaxDefinition resolves the org's tax definition for the invoice and
+// snapshots it as the invoice's tax line.
+func (s *Service) applyTaxDefinition(ctx context.Context, tx *gorm.DB, invoice *invoicedomain.Invoice, now time.Time) error {
+	taxDef, err := s.taxResolver.ResolveForInvoice(ctx, invoice.OrgID, invoice.CustomerID)
+	if err != nil {
+		return err
+	}
+	invoice.TaxRate = nil
+	invoice.TaxCode = nil
+	invoice.TaxAmount = 0
+
+	if taxDef != nil {
+		switch taxDef.TaxMode {
+		case taxdomain.TaxModeExclusive:
+			invoice.TaxAmount = taxservice.ComputeTaxExclusive(invoice.SubtotalAmount, taxDef.Rate)
+		case taxdomain.TaxModeInclusive:
+			invoice.TaxAmount = taxservice.ComputeTaxInclusive(invoice.SubtotalAmount, taxDef.Rate)
+		default:
+			invoice.TaxAmount = 0
+		}
+		invoice.TaxRate = taxDef.Rate
+		invoice.TaxCode = &taxDef.Code
+
+		// SNAPSHOT: Create InvoiceTaxLine
+		taxLine := invoicedomain.InvoiceTaxLine{
+			ID:        s.genID.Generate(),
+			OrgID:     invoice.OrgID,
+			InvoiceID: invoice.ID,
+			TaxCode:   &taxDef.Code,
+			TaxName:   taxDef.Name,
+			TaxMode:   string(taxDef.TaxMode),
+			TaxRate:   *taxDef.Rate,
+			Amount:    invoice.TaxAmount,
+			CreatedAt: now,
+		}
+		if err := tx.WithContext(ctx).Create(&taxLine).Error; err != nil {
+			return err
+		}
+	}
+	invoice.TotalAmount = invoice.SubtotalAmount + invoice.TaxAmount
+	return nil
+}
+
+// applyInvoiceTaxLines totals the tax lines recorded at generation onto the
+// invoice. Inclusive tax is part of the subtotal already.
+func applyInvoiceTaxLines(invoice *invoicedomain.Invoice, lines []invoicedomain.InvoiceTaxLine) {
+	invoice.TaxAmount = 0
+	invoice.TotalAmount = invoice.SubtotalAmount
+	for _, line := range lines {
+		invoice.TaxAmount += line.Amount
+		if line.TaxMode != string(taxdomain.TaxModeInclusive) {
+			invoice.TotalAmount += line.Amount
+		}
+	}
+}
+
+// VoidInvoice voids a finalized invoice. A repeat with the same
+// idempotencyKey succeeds without voiding or auditing again, so a client
+// retry does not fail on the invoice it already voided.
+func (s *Service) VoidInvoice(ctx context.Context, invoiceID string, reason string, idempotencyKey string) error {
 	id, err := parseID(strings.TrimSpace(invoiceID))
 	if err != nil {
 		return invoicedomain.ErrInvalidInvoiceID
 	}
 
+	reason = strings.TrimSpace(reason)
+	orgID, _ := orgcontext.OrgIDFromContext(ctx)
+	if strings.TrimSpace(idempotencyKey) != "" && orgID == 0 {
+		return invoicedomain.ErrInvalidOrganization
+	}
+	key := ledger.IdempotencyKey{
+		OrgID:   orgID,
+		Scope:   "invoice.void",
+		Key:     idempotencyKey,
+		Request: fmt.Sprintf("%s|%s", id, reason),
+	}
+
 	var voidedInvoice *invoicedomain.Invoice
-	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	_, replayed, err := ledger.WithIdempotency(ctx, s.db, key, func(tx *gorm.DB) (bool, error) {
 		invoice, err := s.loadInvoiceForUpdate(ctx, tx, id)
 		if err != nil {
-			return err
+			return false, err
 		}
-		if invoice == nil {
-			return invoicedomain.ErrInvoiceNotFound
+		if invoice == nil || (orgID != 0 && invoice.OrgID != orgID) {
+			return false, invoicedomain.ErrInvoiceNotFound
 		}
 		if invoice.Status != invoicedomain.InvoiceStatusFinalized {
-			return invoicedomain.ErrInvoiceNotFinalized
+			return false, invoicedomain.ErrInvoiceNotFinalized
 		}
 
 		now := time.Now().UTC()
@@ -869,7 +1073,7 @@ func (s *Service) VoidInvoice(ctx context.Context, invoiceID string, reason stri
 			now,
 			id,
 		).Error; err != nil {
-			return err
+			return false, err
 		}
 		voidedInvoice = invoice
 
@@ -883,19 +1087,18 @@ func (s *Service) VoidInvoice(ctx context.Context, invoiceID string, reason stri
 				},
 				DedupeKey: "invoice_voided:" + invoice.ID.String(),
 			}); err != nil {
-				return err
+				return false, err
 			}
 		}
-		return nil
+		return true, nil
 	})
 	if err != nil {
 		return err
 	}
-	if voidedInvoice != nil {
+	if voidedInvoice != nil && !replayed {
 		metadata := map[string]any{
 			"previous_status": string(invoicedomain.InvoiceStatusFinalized),
 		}
-		reason = strings.TrimSpace(reason)
 		if reason != "" {
 			metadata["reason"] = reason
 		}
@@ -939,13 +1142,21 @@ func (s *Service) emitAudit(ctx context.Context, action string, invoice *invoice
 	_ = s.auditSvc.AuditLog(ctx, &orgID, "", nil, action, "invoice", &targetID, metadata)
 }
 
+// loadBillingCycleForUpdate locks the cycle row. The lock blocks rather than
+// skipping so a concurrent GenerateInvoice waits for the first call to commit
+// and then resolves to its invoice as a duplicate.
 func (s *Service) loadBillingCycleForUpdate(ctx context.Context, tx *gorm.DB, id snowflake.ID) (*billingCycleRow, error) {
 	var cycle billingCycleRow
-	err := tx.WithContext(ctx).Raw(
-		`SELECT id, org_id, subscription_id, period_start, period_end, status
+	query := `SELECT id, org_id, subscription_id, period_start, period_end, status
 		 FROM billing_cycles
-		 WHERE id = ?
-		 FOR UPDATE SKIP LOCKED`,
+		 WHERE id = ?`
+
+	if tx.Dialector.Name() != "sqlite" {
+		query += " FOR UPDATE"
+	}
+
+	err := tx.WithContext(ctx).Raw(
+		query,
 		id,
 	).Scan(&cycle).Error
 	if err != nil {
@@ -975,19 +1186,35 @@ func (s *Service) loadSubscription(ctx context.Context, tx *gorm.DB, orgID, subs
 	return &sub, nil
 }
 
-func (s *Service) findInvoiceByBillingCycle(ctx context.Context, tx *gorm.DB, billingCycleID snowflake.ID) (snowflake.ID, error) {
-	var invoiceID snowflake.ID
+// findGeneratedInvoice returns the invoice already generated under key or for
+// the billing cycle, or nil when there is none. A key that produced an invoice
+// for another cycle is reported as ErrIdempotencyKeyConflict.
+func (s *Service) findGeneratedInvoice(ctx context.Context, tx *gorm.DB, orgID, billingCycleID snowflake.ID, key string) (*invoicedomain.Invoice, error) {
+	var invoice invoicedomain.Invoice
 	err := tx.WithContext(ctx).Raw(
-		`SELECT id
+		`SELECT id, org_id, invoice_seq, invoice_number, billing_cycle_id, subscription_id, customer_id,
+		        invoice_template_id, status, subtotal_amount, tax_amount, total_amount, currency,
+		        period_start, period_end, issued_at, due_at, finalized_at, voided_at,
+		        idempotency_key, created_at, updated_at
 		 FROM invoices
-		 WHERE billing_cycle_id = ?
+		 WHERE org_id = ? AND (idempotency_key = ? OR billing_cycle_id = ?)
+		 ORDER BY CASE WHEN idempotency_key = ? THEN 0 ELSE 1 END
 		 LIMIT 1`,
+		orgID,
+		key,
 		billingCycleID,
-	).Scan(&invoiceID).Error
+		key,
+	).Scan(&invoice).Error
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	if invoice.ID == 0 {
+		return nil, nil
 	}
-	return invoiceID, nil
+	if invoice.BillingCycleID != billingCycleID {
+		return nil, invoicedomain.ErrIdempotencyKeyConflict
+	}
+	return &invoice, nil
 }
 
 func (s *Service) loadLedgerEntryForCycle(ctx context.Context, tx *gorm.DB, orgID, billingCycleID snowflake.ID) (*ledgerEntryRow, error) {
@@ -1045,20 +1272,82 @@ func (s *Service) lockOrganization(ctx context.Context, tx *gorm.DB, orgID snowf
 	return nil
 }
 
+// draftInvoiceNumber is the placeholder number of an unfinalized invoice.
+func draftInvoiceNumber(invoiceID snowflake.ID) string {
+	return "DRAFT-" + invoiceID.String()
+}
+
+// assignInvoiceNumber allocates the org's next invoice sequence and formats
+// the invoice number with the org's template. It must run in the finalizing
+// transaction: the sequence row stays locked until commit, and a rollback
+// returns the number, so finalized invoices are numbered without gaps.
+// Invoices that already hold a sequence keep their number.
+func (s *Service) assignInvoiceNumber(ctx context.Context, tx *gorm.DB, invoice *invoicedomain.Invoice, issuedAt time.Time) error {
+	if invoice.InvoiceSeq != nil {
+		return nil
+	}
+
+	template, prefix, err := s.loadInvoiceNumberFormat(ctx, tx, invoice.OrgID)
+	if err != nil {
+		return err
+	}
+	seq, err := s.nextInvoiceNumber(ctx, tx, invoice.OrgID, issuedAt)
+	if err != nil {
+		return err
+	}
+	number, err := invoiceformat.FormatInvoiceNumber(template, prefix, issuedAt, seq)
+	if err != nil {
+		return err
+	}
+
+	invoice.InvoiceSeq = &seq
+	invoice.InvoiceNumber = number
+	return nil
+}
+
+// loadInvoiceNumberFormat returns the org's invoice number template and
+// prefix, falling back to the default template when none is configured.
+func (s *Service) loadInvoiceNumberFormat(ctx context.Context, tx *gorm.DB, orgID snowflake.ID) (string, string, error) {
+	var row struct {
+		InvoiceNumberFormat *string
+		InvoiceNumberPrefix *string
+	}
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT invoice_number_format, invoice_number_prefix
+		 FROM organization_billing_preferences
+		 WHERE org_id = ?`,
+		orgID,
+	).Scan(&row).Error; err != nil {
+		return "", "", err
+	}
+
+	template := invoiceformat.DefaultInvoiceNumberTemplate
+	if row.InvoiceNumberFormat != nil && strings.TrimSpace(*row.InvoiceNumberFormat) != "" {
+		template = *row.InvoiceNumberFormat
+	}
+	prefix := ""
+	if row.InvoiceNumberPrefix != nil {
+		prefix = *row.InvoiceNumberPrefix
+	}
+	return template, prefix, nil
+}
+
 func (s *Service) nextInvoiceNumber(
 	ctx context.Context,
 	tx *gorm.DB,
 	orgID snowflake.ID,
+	now time.Time,
 ) (int64, error) {
 
 	var next int64
 	err := tx.WithContext(ctx).Raw(`
-		UPDATE invoice_sequences
-		SET next_number = next_number + 1,
-		    updated_at = now()
-		WHERE org_id = ?
+		INSERT INTO invoice_sequences (org_id, next_number, updated_at)
+		VALUES (?, 2, ?)
+		ON CONFLICT (org_id) DO UPDATE
+		SET next_number = invoice_sequences.next_number + 1,
+		    updated_at = EXCLUDED.updated_at
 		RETURNING next_number - 1
-	`, orgID).Scan(&next).Error
+	`, orgID, now).Scan(&next).Error
 
 	return next, err
 }
@@ -1075,6 +1364,9 @@ func (s *Service) loadRating(ctx context.Context, tx *gorm.DB, cycleID snowflake
 	if err != nil {
 		return nil, err
 	}
+	if rating.ID == 0 {
+		return nil, nil
+	}
 
 	return &rating, nil
 }
@@ -1166,10 +1458,10 @@ func (s *Service) insertInvoice(ctx context.Context, tx *gorm.DB, invoice invoic
 	result := tx.WithContext(ctx).Exec(
 		`INSERT INTO invoices (
 			id, org_id, invoice_seq, invoice_number, billing_cycle_id, subscription_id, customer_id,
-			invoice_template_id, status, subtotal_amount, total_amount, currency, period_start, period_end,
-			issued_at, due_at, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT (billing_cycle_id) DO NOTHING`,
+			invoice_template_id, status, subtotal_amount, tax_rate, tax_code, tax_amount, total_amount, currency,
+			period_start, period_end, issued_at, due_at, idempotency_key, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
 		invoice.ID,
 		invoice.OrgID,
 		invoice.InvoiceSeq,
@@ -1180,12 +1472,16 @@ func (s *Service) insertInvoice(ctx context.Context, tx *gorm.DB, invoice invoic
 		invoice.InvoiceTemplateID,
 		invoice.Status,
 		invoice.SubtotalAmount,
+		invoice.TaxRate,
+		invoice.TaxCode,
+		invoice.TaxAmount,
 		invoice.TotalAmount,
 		invoice.Currency,
 		invoice.PeriodStart,
 		invoice.PeriodEnd,
 		invoice.IssuedAt,
 		invoice.DueAt,
+		invoice.IdempotencyKey,
 		invoice.CreatedAt,
 		invoice.UpdatedAt,
 	)
@@ -1272,48 +1568,8 @@ func formatQty(v float64) string {
 	return fmt.Sprintf("%.2f", v)
 }
 
-var currencyDecimals = map[string]int{
-	"USD": 2,
-	"EUR": 2,
-	"SGD": 2,
-	"CNY": 2,
-	"IDR": 0,
-}
-
 func formatMoney(amount int64, currency string) string {
-	c := strings.ToUpper(currency)
-	decimals, ok := currencyDecimals[c]
-	if !ok {
-		// fallback: assume 2 decimals
-		decimals = 2
-	}
-
-	sign := ""
-	if amount < 0 {
-		sign = "-"
-		amount = -amount
-	}
-
-	if decimals == 0 {
-		return fmt.Sprintf("%s%s %d", sign, c, amount)
-	}
-
-	divisor := int64(1)
-	for i := 0; i < decimals; i++ {
-		divisor *= 10
-	}
-
-	major := amount / divisor
-	minor := amount % divisor
-
-	return fmt.Sprintf(
-		"%s%s %d.%0*d",
-		sign,
-		c,
-		major,
-		decimals,
-		minor,
-	)
+	return money.Format(amount, currency)
 }
 
 // sendInvoiceNotification generates PDF and sends email
@@ -1334,38 +1590,27 @@ func (s *Service) sendInvoiceNotification(ctx context.Context, invoice *invoiced
 
 	// Fetch Customer
 	type Customer struct {
-		Email string
+		Email        string
+		DoNotContact bool
 	}
 	var cust Customer
-	if err := s.db.WithContext(ctx).Table("customers").Select("email").Where("id = ? AND org_id = ?", invoice.CustomerID, invoice.OrgID).Scan(&cust).Error; err != nil {
+	if err := s.db.WithContext(ctx).Table("customers").Select("email, do_not_contact").Where("id = ? AND org_id = ?", invoice.CustomerID, invoice.OrgID).Scan(&cust).Error; err != nil {
 		s.log.Error("failed to fetch customer for notification", zap.Error(err))
 	}
+	if cust.DoNotContact {
+		s.log.Info("customer is do-not-contact, skipping invoice notification", zap.String("invoice_id", invoice.ID.String()))
+		return nil
+	}
 
 	// Default support email if empty in DB
 	if org.SupportEmail == "" {
 		org.SupportEmail = "support@railzway.com"
 	}
 
-	// 2. Generate PDF (Proof of concept)
-	pdfData := pdf.InvoiceData{
-		InvoiceNumber: invoice.ID.String(),
-		IssueDate:     invoice.IssuedAt.Format("January 2, 2006"),
-		DueDate:       invoice.DueAt.Format("January 2, 2006"),
-		TotalDue:      fmt.Sprintf("$%.2f", float64(invoice.TotalAmount)/100.0), // TODO: Currency formatting
-		Total:         fmt.Sprintf("$%.2f", float64(invoice.TotalAmount)/100.0),
-		OrgName:       org.Name,
-		// Populate other fields as needed
-	}
-
-	pdfReader, err := s.pdfProvider.GenerateInvoice(ctx, pdfData)
-	var pdfBytes []byte
+	// 2. Generate PDF with the invoice's template
+	pdfBytes, err := s.renderInvoicePDF(ctx, s.db, invoice, nil)
 	if err != nil {
 		s.log.Error("failed to generate invoice PDF", zap.Error(err))
-	} else if pdfReader != nil {
-		pdfBytes, err = io.ReadAll(pdfReader)
-		if err != nil {
-			s.log.Error("failed to read PDF content", zap.Error(err))
-		}
 	}
 
 	// 3. Send Email
@@ -1378,13 +1623,14 @@ func (s *Service) sendInvoiceNotification(ctx context.Context, invoice *invoiced
 		OrgContactEmail string
 	}{
 		OrgName:         org.Name,
-		Total:           pdfData.Total, // Reuse formatted total strings
-		DueDate:         pdfData.DueDate,
+		Total:           formatMoney(invoice.TotalAmount, invoice.Currency),
+		DueDate:         pdfDate(invoice.DueAt),
 		PaymentLink:     fmt.Sprintf("http://localhost:5173/%s/%s", invoice.OrgID, tokenHash), // TODO: BaseURL from config
 		InvoiceNumber:   invoice.ID.String(),
 		OrgContactEmail: org.SupportEmail,
 	}
 
+	cust.Email = strings.TrimSpace(cust.Email)
 	to := []string{cust.Email}
 	if cust.Email == "" {
 		// Fallback for dev/demo if customer email is missing
@@ -1392,8 +1638,10 @@ func (s *Service) sendInvoiceNotification(ctx context.Context, invoice *invoiced
 		to = []string{"taufiktriantono4@gmail.com"}
 	}
 
+	messageID := emaildeliverydomain.NewMessageID(s.genID.Generate())
 	msg := email.EmailMessage{
 		To:         to,
+		MessageID:  messageID,
 		SenderName: org.Name,
 		ReplyTo:    org.SupportEmail,
 		Subject:    fmt.Sprintf("New invoice from %s. #%s", org.Name, invoice.InvoiceNumber),
@@ -1414,6 +1662,20 @@ func (s *Service) sendInvoiceNotification(ctx context.Context, invoice *invoiced
 		return err
 	}
 
+	if s.deliverySvc != nil {
+		customerID, invoiceID := invoice.CustomerID, invoice.ID
+		if err := s.deliverySvc.RecordSent(ctx, emaildeliverydomain.RecordSentRequest{
+			OrgID:      invoice.OrgID,
+			MessageID:  messageID,
+			CustomerID: &customerID,
+			InvoiceID:  &invoiceID,
+			Recipient:  to[0],
+			Type:       emaildeliverydomain.TypeInvoice,
+		}); err != nil {
+			s.log.Warn("failed to record invoice email delivery", zap.String("invoice_id", invoice.ID.String()), zap.Error(err))
+		}
+	}
+
 	s.log.Info("invoice notification sent", zap.String("invoice_id", invoice.ID.String()), zap.String("to", to[0]), zap.Bool("has_pdf", len(pdfBytes) > 0))
 	return nil
 }
@@ -26,6 +26,7 @@ func TestSnapshotInvoicing_StrictCompliance(t *testing.T) {
 		&invoicedomain.Invoice{},
 		&invoicedomain.InvoiceItem{},
 		&invoicedomain.SubscriptionEntitlement{}, // The read-model struct
+		&invoicedomain.CarryForward{},
 	)
 	assert.NoError(t, err)
 
@@ -47,6 +48,7 @@ func TestSnapshotInvoicing_StrictCompliance(t *testing.T) {
 	// Let's just mock the DB data for `billing_cycles` table.
 	// The service uses `billing_cycles` table.
 	db.Exec("CREATE TABLE billing_cycles (id BIGINT, org_id BIGINT, subscription_id BIGINT, period_start DATETIME, period_end DATETIME, status TEXT)")
+	db.Exec("CREATE TABLE organization_billing_preferences (org_id BIGINT, min_invoice_amount BIGINT)")
 
 	// 3. Seed Data
 	orgID := node.Generate()
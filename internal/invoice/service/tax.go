@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	taxdomain "github.com/smallbiznis/railzway/internal/tax/domain"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Customer metadata keys the tax context is read from.
+const (
+	customerCountryMetadataKey = "country"
+	customerRegionMetadataKey  = "region"
+)
+
+// calculateDraftTax runs the tax calculator over the draft's lines and
+// records the resulting tax lines and totals on it. Without a calculator the
+// draft is left untaxed and tax is resolved at finalization.
+func (s *Service) calculateDraftTax(ctx context.Context, tx *gorm.DB, cycle billingCycleRow, draft *invoiceDraft) error {
+	draft.TotalAmount = draft.Subtotal
+	if s.taxCalculator == nil {
+		return nil
+	}
+
+	taxCtx, err := s.loadTaxContext(ctx, tx, cycle.OrgID, draft.CustomerID)
+	if err != nil {
+		return err
+	}
+	taxCtx.Currency = draft.Currency
+
+	behaviors, err := s.listRatingTaxBehaviors(ctx, tx, cycle.ID)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]taxdomain.TaxableLine, 0, len(draft.Items))
+	for _, item := range draft.Items {
+		line := taxdomain.TaxableLine{
+			Description: item.Description,
+			Amount:      item.Amount,
+			TaxBehavior: taxdomain.TaxBehaviorExclusive,
+		}
+		if item.RatingResultID != nil {
+			line.Reference = item.RatingResultID.String()
+			if behavior, ok := behaviors[*item.RatingResultID]; ok {
+				line.TaxBehavior = behavior
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	taxLines, err := s.taxCalculator.CalculateTax(ctx, lines, taxCtx)
+	if err != nil {
+		return fmt.Errorf("calculate tax: %w", err)
+	}
+	for _, line := range taxLines {
+		draft.TaxAmount += line.Amount
+		if line.Mode != taxdomain.TaxModeInclusive {
+			draft.TotalAmount += line.Amount
+		}
+	}
+	draft.TaxLines = taxLines
+	return nil
+}
+
+// loadTaxContext reads the customer's country and region from its metadata.
+func (s *Service) loadTaxContext(ctx context.Context, tx *gorm.DB, orgID, customerID snowflake.ID) (taxdomain.TaxContext, error) {
+	var row struct {
+		Metadata datatypes.JSONMap
+	}
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT metadata FROM customers WHERE org_id = ? AND id = ?`,
+		orgID,
+		customerID,
+	).Scan(&row).Error; err != nil {
+		return taxdomain.TaxContext{}, err
+	}
+
+	metadataCode := func(key string) string {
+		value, _ := row.Metadata[key].(string)
+		return strings.ToUpper(strings.TrimSpace(value))
+	}
+	return taxdomain.TaxContext{
+		OrgID:      orgID,
+		CustomerID: customerID,
+		Country:    metadataCode(customerCountryMetadataKey),
+		Region:     metadataCode(customerRegionMetadataKey),
+	}, nil
+}
+
+// listRatingTaxBehaviors returns the tax behavior of the price behind each
+// rating result of the cycle.
+func (s *Service) listRatingTaxBehaviors(ctx context.Context, tx *gorm.DB, billingCycleID snowflake.ID) (map[snowflake.ID]taxdomain.TaxBehavior, error) {
+	var rows []struct {
+		ID          snowflake.ID
+		TaxBehavior string
+	}
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT rr.id, p.tax_behavior
+		 FROM rating_results rr
+		 JOIN prices p ON p.id = rr.price_id AND p.org_id = rr.org_id
+		 WHERE rr.billing_cycle_id = ?`,
+		billingCycleID,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	behaviors := make(map[snowflake.ID]taxdomain.TaxBehavior, len(rows))
+	for _, row := range rows {
+		if taxdomain.TaxBehavior(strings.ToUpper(row.TaxBehavior)) == taxdomain.TaxBehaviorInclusive {
+			behaviors[row.ID] = taxdomain.TaxBehaviorInclusive
+		}
+	}
+	return behaviors, nil
+}
+
+// insertInvoiceTaxLines snapshots the draft's tax lines onto the invoice.
+func (s *Service) insertInvoiceTaxLines(ctx context.Context, tx *gorm.DB, invoice invoicedomain.Invoice, lines []taxdomain.TaxLine, now time.Time) error {
+	for _, line := range lines {
+		code := line.Code
+		taxLine := invoicedomain.InvoiceTaxLine{
+			ID:        s.genID.Generate(),
+			OrgID:     invoice.OrgID,
+			InvoiceID: invoice.ID,
+			TaxCode:   &code,
+			TaxName:   line.Name,
+			TaxMode:   string(line.Mode),
+			TaxRate:   line.Rate,
+			Amount:    line.Amount,
+			CreatedAt: now,
+		}
+		if err := tx.WithContext(ctx).Create(&taxLine).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listInvoiceTaxLines returns the tax lines already recorded for an invoice.
+func (s *Service) listInvoiceTaxLines(ctx context.Context, tx *gorm.DB, invoiceID snowflake.ID) ([]invoicedomain.InvoiceTaxLine, error) {
+	var lines []invoicedomain.InvoiceTaxLine
+	if err := tx.WithContext(ctx).
+		Where("invoice_id = ?", invoiceID).
+		Order("created_at ASC").
+		Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
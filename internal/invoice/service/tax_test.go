@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	ratingdomain "github.com/smallbiznis/railzway/internal/rating/domain"
+	taxdomain "github.com/smallbiznis/railzway/internal/tax/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type recordingTaxCalculator struct {
+	lines    []taxdomain.TaxableLine
+	customer taxdomain.TaxContext
+}
+
+func (c *recordingTaxCalculator) CalculateTax(ctx context.Context, lines []taxdomain.TaxableLine, customer taxdomain.TaxContext) ([]taxdomain.TaxLine, error) {
+	c.lines = lines
+	c.customer = customer
+	return []taxdomain.TaxLine{
+		{Code: taxdomain.TaxCodeEUVATStandard, Name: "VAT", Mode: taxdomain.TaxModeExclusive, Rate: 0.2, TaxableAmount: 1000, Amount: 200},
+		{Code: taxdomain.TaxCodeEUVATStandard, Name: "VAT", Mode: taxdomain.TaxModeInclusive, Rate: 0.2, TaxableAmount: 600, Amount: 100},
+	}, nil
+}
+
+// ignoreOrganizationLock strips FOR UPDATE from the organization lock, which
+// SQLite cannot parse. The lock itself is only meaningful on Postgres.
+func ignoreOrganizationLock(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	require.NoError(t, db.Callback().Row().Before("gorm:row").Register("test:ignore_organization_lock", func(tx *gorm.DB) {
+		sql := tx.Statement.SQL.String()
+		if !strings.Contains(sql, "FROM organizations") || !strings.HasSuffix(sql, "FOR UPDATE") {
+			return
+		}
+		tx.Statement.SQL.Reset()
+		tx.Statement.SQL.WriteString(strings.TrimSuffix(sql, "FOR UPDATE"))
+	}))
+}
+
+func TestGenerateInvoice_CalculatesTax(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&ratingdomain.RatingResult{},
+		&invoicedomain.Invoice{},
+		&invoicedomain.InvoiceItem{},
+		&invoicedomain.InvoiceTaxLine{},
+		&invoicedomain.SubscriptionEntitlement{},
+		&invoicedomain.CarryForward{},
+	))
+	for _, stmt := range []string{
+		"CREATE TABLE billing_cycles (id BIGINT, org_id BIGINT, subscription_id BIGINT, period_start DATETIME, period_end DATETIME, status TEXT)",
+		"CREATE TABLE subscriptions (id BIGINT, org_id BIGINT, customer_id BIGINT)",
+		"CREATE TABLE customers (id BIGINT, org_id BIGINT, metadata TEXT)",
+		"CREATE TABLE prices (id BIGINT, org_id BIGINT, tax_behavior TEXT)",
+		"CREATE TABLE organizations (id BIGINT)",
+		"CREATE TABLE organization_billing_preferences (org_id BIGINT, min_invoice_amount BIGINT)",
+		"CREATE TABLE ledger_entries (id BIGINT, org_id BIGINT, source_type TEXT, source_id BIGINT, currency TEXT, occurred_at DATETIME)",
+		"CREATE TABLE ledger_entry_lines (id BIGINT, ledger_entry_id BIGINT, account_id BIGINT, direction TEXT, amount BIGINT)",
+		"CREATE TABLE ledger_accounts (id BIGINT, code TEXT, name TEXT)",
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	ignoreOrganizationLock(t, db)
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+	calculator := &recordingTaxCalculator{}
+	svc := NewService(ServiceParam{DB: db, Log: zap.NewNop(), GenID: node, TaxCalculator: calculator})
+
+	orgID := node.Generate()
+	subID := node.Generate()
+	customerID := node.Generate()
+	cycleID := node.Generate()
+	end := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -1, 0)
+
+	require.NoError(t, db.Exec("INSERT INTO organizations (id) VALUES (?)", orgID).Error)
+	require.NoError(t, db.Exec(
+		"INSERT INTO billing_cycles (id, org_id, subscription_id, period_start, period_end, status) VALUES (?, ?, ?, ?, ?, ?)",
+		cycleID, orgID, subID, start, end, billingcycledomain.BillingCycleStatusClosed,
+	).Error)
+	require.NoError(t, db.Exec("INSERT INTO subscriptions (id, org_id, customer_id) VALUES (?, ?, ?)", subID, orgID, customerID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO customers (id, org_id, metadata) VALUES (?, ?, '{"country":"de","region":"be"}')`, customerID, orgID).Error)
+
+	exclusivePriceID := node.Generate()
+	inclusivePriceID := node.Generate()
+	require.NoError(t, db.Exec(
+		"INSERT INTO prices (id, org_id, tax_behavior) VALUES (?, ?, 'EXCLUSIVE'), (?, ?, 'INCLUSIVE')",
+		exclusivePriceID, orgID, inclusivePriceID, orgID,
+	).Error)
+
+	rate := func(priceID snowflake.ID, amount int64) snowflake.ID {
+		id := node.Generate()
+		require.NoError(t, db.Create(&ratingdomain.RatingResult{
+			ID:             id,
+			OrgID:          orgID,
+			SubscriptionID: subID,
+			BillingCycleID: cycleID,
+			PriceID:        priceID,
+			Source:         "flat",
+			Quantity:       1,
+			UnitPrice:      amount,
+			Amount:         amount,
+			Currency:       "EUR",
+			PeriodStart:    start,
+			PeriodEnd:      end,
+			Checksum:       id.String(),
+			CreatedAt:      end,
+		}).Error)
+		return id
+	}
+	exclusiveRating := rate(exclusivePriceID, 1000)
+	inclusiveRating := rate(inclusivePriceID, 600)
+
+	entryID := node.Generate()
+	accountID := node.Generate()
+	require.NoError(t, db.Exec(
+		"INSERT INTO ledger_entries (id, org_id, source_type, source_id, currency, occurred_at) VALUES (?, ?, ?, ?, 'EUR', ?)",
+		entryID, orgID, ledgerdomain.SourceTypeBillingCycle, cycleID, end,
+	).Error)
+	require.NoError(t, db.Exec("INSERT INTO ledger_accounts (id, code, name) VALUES (?, 'revenue_flat', 'Flat Revenue')", accountID).Error)
+	require.NoError(t, db.Exec(
+		"INSERT INTO ledger_entry_lines (id, ledger_entry_id, account_id, direction, amount) VALUES (?, ?, ?, ?, 1600), (?, ?, ?, ?, 1600)",
+		node.Generate(), entryID, accountID, ledgerdomain.LedgerEntryDirectionCredit,
+		node.Generate(), entryID, accountID, ledgerdomain.LedgerEntryDirectionDebit,
+	).Error)
+
+	result, err := svc.GenerateInvoice(context.Background(), cycleID.String(), "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "DE", calculator.customer.Country)
+	assert.Equal(t, "BE", calculator.customer.Region)
+	assert.Equal(t, "EUR", calculator.customer.Currency)
+	behaviors := map[string]taxdomain.TaxBehavior{}
+	for _, line := range calculator.lines {
+		behaviors[line.Reference] = line.TaxBehavior
+	}
+	assert.Equal(t, map[string]taxdomain.TaxBehavior{
+		exclusiveRating.String(): taxdomain.TaxBehaviorExclusive,
+		inclusiveRating.String(): taxdomain.TaxBehaviorInclusive,
+	}, behaviors)
+
+	var stored invoicedomain.Invoice
+	require.NoError(t, db.First(&stored, "id = ?", result.Invoice.ID).Error)
+	assert.Equal(t, int64(1600), stored.SubtotalAmount, "subtotal stays tax-free")
+	assert.Equal(t, int64(300), stored.TaxAmount)
+	assert.Equal(t, int64(1800), stored.TotalAmount, "only exclusive tax is added on top")
+	require.NotNil(t, stored.TaxCode)
+	assert.Equal(t, taxdomain.TaxCodeEUVATStandard, *stored.TaxCode)
+
+	var taxLines []invoicedomain.InvoiceTaxLine
+	require.NoError(t, db.Where("invoice_id = ?", stored.ID).Find(&taxLines).Error)
+	assert.Len(t, taxLines, 2)
+
+	applied := stored
+	applyInvoiceTaxLines(&applied, taxLines)
+	assert.Equal(t, stored.TaxAmount, applied.TaxAmount)
+	assert.Equal(t, stored.TotalAmount, applied.TotalAmount)
+}
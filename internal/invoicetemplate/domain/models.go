@@ -15,6 +15,7 @@ type InvoiceTemplate struct {
 	IsDefault          bool              `gorm:"not null;default:false"`
 	Locale             string            `gorm:"type:text;not null;default:'en'"`
 	Currency           string            `gorm:"type:text;not null"`
+	Layout             string            `gorm:"type:text;not null;default:'detailed'"`
 	Header             datatypes.JSONMap `gorm:"type:jsonb"`
 	Footer             datatypes.JSONMap `gorm:"type:jsonb"`
 	Style              datatypes.JSONMap `gorm:"type:jsonb"`
@@ -27,5 +28,17 @@ type InvoiceTemplate struct {
 	UpdatedAt          time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP"`
 }
 
+// Layouts an invoice PDF can be rendered with. Detailed lists every line
+// item; summary shows only the totals.
+const (
+	LayoutDetailed = "detailed"
+	LayoutSummary  = "summary"
+)
+
+// ValidLayout reports whether layout is a known PDF layout.
+func ValidLayout(layout string) bool {
+	return layout == LayoutDetailed || layout == LayoutSummary
+}
+
 // TableName sets the database table name.
 func (InvoiceTemplate) TableName() string { return "invoice_templates" }
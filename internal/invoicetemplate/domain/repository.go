@@ -13,4 +13,10 @@ type Repository interface {
 	FindByID(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID) (*InvoiceTemplate, error)
 	FindDefault(ctx context.Context, db *gorm.DB, orgID snowflake.ID) (*InvoiceTemplate, error)
 	List(ctx context.Context, db *gorm.DB, orgID snowflake.ID, filter ListRequest) ([]InvoiceTemplate, error)
+	// FindCustomerTemplateID returns the customer's template override, or nil
+	// when it has none.
+	FindCustomerTemplateID(ctx context.Context, db *gorm.DB, orgID, customerID snowflake.ID) (*snowflake.ID, error)
+	// SetCustomerTemplateID stores or, with a nil templateID, clears the
+	// customer's override. It reports false when the customer does not exist.
+	SetCustomerTemplateID(ctx context.Context, db *gorm.DB, orgID, customerID snowflake.ID, templateID *snowflake.ID) (bool, error)
 }
@@ -19,6 +19,7 @@ type CreateRequest struct {
 	IsDefault bool           `json:"is_default"`
 	Locale    string         `json:"locale"`
 	Currency  string         `json:"currency"`
+	Layout    string         `json:"layout"`
 	Header    map[string]any `json:"header"`
 	Footer    map[string]any `json:"footer"`
 	Style     map[string]any `json:"style"`
@@ -29,6 +30,7 @@ type UpdateRequest struct {
 	Name     *string        `json:"name"`
 	Locale   *string        `json:"locale"`
 	Currency *string        `json:"currency"`
+	Layout   *string        `json:"layout"`
 	Header   map[string]any `json:"header"`
 	Footer   map[string]any `json:"footer"`
 	Style    map[string]any `json:"style"`
@@ -41,6 +43,7 @@ type Response struct {
 	IsDefault bool           `json:"is_default"`
 	Locale    string         `json:"locale"`
 	Currency  string         `json:"currency"`
+	Layout    string         `json:"layout"`
 	Header    map[string]any `json:"header"`
 	Footer    map[string]any `json:"footer"`
 	Style     map[string]any `json:"style"`
@@ -54,6 +57,10 @@ type Service interface {
 	GetByID(ctx context.Context, id string) (*Response, error)
 	Update(ctx context.Context, req UpdateRequest) (*Response, error)
 	SetDefault(ctx context.Context, id string) (*Response, error)
+	// SetCustomerTemplate makes templateID the template the customer's
+	// invoices render with instead of the org default. A nil templateID
+	// clears the override.
+	SetCustomerTemplate(ctx context.Context, customerID string, templateID *string) error
 }
 
 func ParseID(raw string) (snowflake.ID, error) {
@@ -66,5 +73,8 @@ var (
 	ErrInvalidName         = errors.New("invalid_name")
 	ErrInvalidCurrency     = errors.New("invalid_currency")
 	ErrInvalidLocale       = errors.New("invalid_locale")
+	ErrInvalidLayout       = errors.New("invalid_layout")
+	ErrInvalidCustomerID   = errors.New("invalid_customer_id")
 	ErrNotFound            = errors.New("not_found")
+	ErrCustomerNotFound    = errors.New("customer_not_found")
 )
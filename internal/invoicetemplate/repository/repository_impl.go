@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/bwmarrin/snowflake"
 	templatedomain "github.com/smallbiznis/railzway/internal/invoicetemplate/domain"
@@ -17,14 +19,15 @@ func Provide() templatedomain.Repository {
 func (r *repo) Insert(ctx context.Context, db *gorm.DB, tmpl *templatedomain.InvoiceTemplate) error {
 	return db.WithContext(ctx).Exec(
 		`INSERT INTO invoice_templates (
-			id, org_id, name, is_default, locale, currency, header, footer, style, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, org_id, name, is_default, locale, currency, layout, header, footer, style, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		tmpl.ID,
 		tmpl.OrgID,
 		tmpl.Name,
 		tmpl.IsDefault,
 		tmpl.Locale,
 		tmpl.Currency,
+		tmpl.Layout,
 		tmpl.Header,
 		tmpl.Footer,
 		tmpl.Style,
@@ -36,12 +39,13 @@ func (r *repo) Insert(ctx context.Context, db *gorm.DB, tmpl *templatedomain.Inv
 func (r *repo) Update(ctx context.Context, db *gorm.DB, tmpl *templatedomain.InvoiceTemplate) error {
 	return db.WithContext(ctx).Exec(
 		`UPDATE invoice_templates
-		 SET name = ?, is_default = ?, locale = ?, currency = ?, header = ?, footer = ?, style = ?, updated_at = ?
+		 SET name = ?, is_default = ?, locale = ?, currency = ?, layout = ?, header = ?, footer = ?, style = ?, updated_at = ?
 		 WHERE org_id = ? AND id = ?`,
 		tmpl.Name,
 		tmpl.IsDefault,
 		tmpl.Locale,
 		tmpl.Currency,
+		tmpl.Layout,
 		tmpl.Header,
 		tmpl.Footer,
 		tmpl.Style,
@@ -54,7 +58,7 @@ func (r *repo) Update(ctx context.Context, db *gorm.DB, tmpl *templatedomain.Inv
 func (r *repo) FindByID(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID) (*templatedomain.InvoiceTemplate, error) {
 	var tmpl templatedomain.InvoiceTemplate
 	err := db.WithContext(ctx).Raw(
-		`SELECT id, org_id, name, is_default, locale, currency, header, footer, style, created_at, updated_at
+		`SELECT id, org_id, name, is_default, locale, currency, layout, header, footer, style, created_at, updated_at
 		 FROM invoice_templates
 		 WHERE org_id = ? AND id = ?`,
 		orgID,
@@ -72,7 +76,7 @@ func (r *repo) FindByID(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID
 func (r *repo) FindDefault(ctx context.Context, db *gorm.DB, orgID snowflake.ID) (*templatedomain.InvoiceTemplate, error) {
 	var tmpl templatedomain.InvoiceTemplate
 	err := db.WithContext(ctx).Raw(
-		`SELECT id, org_id, name, is_default, locale, currency, header, footer, style, created_at, updated_at
+		`SELECT id, org_id, name, is_default, locale, currency, layout, header, footer, style, created_at, updated_at
 		 FROM invoice_templates
 		 WHERE org_id = ? AND is_default = TRUE
 		 LIMIT 1`,
@@ -105,3 +109,36 @@ func (r *repo) List(ctx context.Context, db *gorm.DB, orgID snowflake.ID, filter
 	}
 	return items, nil
 }
+
+func (r *repo) FindCustomerTemplateID(ctx context.Context, db *gorm.DB, orgID, customerID snowflake.ID) (*snowflake.ID, error) {
+	var templateID *snowflake.ID
+	err := db.WithContext(ctx).Raw(
+		`SELECT invoice_template_id
+		 FROM customers
+		 WHERE org_id = ? AND id = ?`,
+		orgID,
+		customerID,
+	).Row().Scan(&templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return templateID, nil
+}
+
+func (r *repo) SetCustomerTemplateID(ctx context.Context, db *gorm.DB, orgID, customerID snowflake.ID, templateID *snowflake.ID) (bool, error) {
+	res := db.WithContext(ctx).Exec(
+		`UPDATE customers
+		 SET invoice_template_id = ?
+		 WHERE org_id = ? AND id = ? AND deleted_at IS NULL`,
+		templateID,
+		orgID,
+		customerID,
+	)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
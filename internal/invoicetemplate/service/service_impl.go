@@ -64,6 +64,14 @@ func (s *Service) Create(ctx context.Context, req templatedomain.CreateRequest)
 		locale = "en"
 	}
 
+	layout := strings.ToLower(strings.TrimSpace(req.Layout))
+	if layout == "" {
+		layout = templatedomain.LayoutDetailed
+	}
+	if !templatedomain.ValidLayout(layout) {
+		return nil, templatedomain.ErrInvalidLayout
+	}
+
 	now := time.Now().UTC()
 	tmpl := &templatedomain.InvoiceTemplate{
 		ID:        s.genID.Generate(),
@@ -72,6 +80,7 @@ func (s *Service) Create(ctx context.Context, req templatedomain.CreateRequest)
 		IsDefault: req.IsDefault,
 		Locale:    locale,
 		Currency:  currency,
+		Layout:    layout,
 		Header:    normalizeMap(req.Header),
 		Footer:    normalizeMap(req.Footer),
 		Style:     normalizeMap(req.Style),
@@ -183,6 +192,14 @@ func (s *Service) Update(ctx context.Context, req templatedomain.UpdateRequest)
 		item.Locale = locale
 	}
 
+	if req.Layout != nil {
+		layout := strings.ToLower(strings.TrimSpace(*req.Layout))
+		if !templatedomain.ValidLayout(layout) {
+			return nil, templatedomain.ErrInvalidLayout
+		}
+		item.Layout = layout
+	}
+
 	if req.Header != nil {
 		item.Header = normalizeMap(req.Header)
 	}
@@ -240,6 +257,56 @@ func (s *Service) SetDefault(ctx context.Context, id string) (*templatedomain.Re
 	return s.toResponse(item), nil
 }
 
+func (s *Service) SetCustomerTemplate(ctx context.Context, customerID string, templateID *string) error {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return templatedomain.ErrInvalidOrganization
+	}
+
+	custID, err := templatedomain.ParseID(customerID)
+	if err != nil {
+		return templatedomain.ErrInvalidCustomerID
+	}
+
+	var (
+		tmpl     *templatedomain.InvoiceTemplate
+		override *snowflake.ID
+	)
+	if templateID != nil {
+		id, err := templatedomain.ParseID(*templateID)
+		if err != nil {
+			return templatedomain.ErrInvalidID
+		}
+		tmpl, err = s.repo.FindByID(ctx, s.db, orgID, id)
+		if err != nil {
+			return err
+		}
+		if tmpl == nil {
+			return templatedomain.ErrNotFound
+		}
+		override = &tmpl.ID
+	}
+
+	found, err := s.repo.SetCustomerTemplateID(ctx, s.db, orgID, custID, override)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return templatedomain.ErrCustomerNotFound
+	}
+
+	if s.auditSvc != nil {
+		targetID := custID.String()
+		metadata := map[string]any{"invoice_template_id": nil}
+		if tmpl != nil {
+			metadata["invoice_template_id"] = tmpl.ID.String()
+			metadata["name"] = tmpl.Name
+		}
+		_ = s.auditSvc.AuditLog(ctx, &orgID, "", nil, "invoice_template.customer_override_set", "customer", &targetID, metadata)
+	}
+	return nil
+}
+
 func (s *Service) unsetDefault(ctx context.Context, tx *gorm.DB, orgID snowflake.ID, now time.Time) error {
 	return tx.WithContext(ctx).Exec(
 		`UPDATE invoice_templates
@@ -259,6 +326,7 @@ func (s *Service) emitAudit(ctx context.Context, action string, tmpl *templatedo
 		"is_default": tmpl.IsDefault,
 		"locale":     tmpl.Locale,
 		"currency":   tmpl.Currency,
+		"layout":     tmpl.Layout,
 	}
 	for key, value := range extra {
 		if key == "" {
@@ -283,6 +351,7 @@ func (s *Service) toResponse(tmpl *templatedomain.InvoiceTemplate) *templatedoma
 		IsDefault: tmpl.IsDefault,
 		Locale:    tmpl.Locale,
 		Currency:  tmpl.Currency,
+		Layout:    tmpl.Layout,
 		Header:    map[string]any(tmpl.Header),
 		Footer:    map[string]any(tmpl.Footer),
 		Style:     map[string]any(tmpl.Style),
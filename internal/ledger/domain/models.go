@@ -22,6 +22,7 @@ const (
 	// ======================
 	SourceTypeBillingCycle LedgerSourceType = "billing_cycle" // invoice charge (usage / flat)
 	SourceTypeAdjustment   LedgerSourceType = "adjustment"    // late usage / correction
+	SourceTypeCarryForward LedgerSourceType = "carry_forward" // cycle charge moved to the next cycle
 
 	// ======================
 	// Payments
@@ -35,6 +36,7 @@ const (
 	SourceTypeCreditGrant LedgerSourceType = "credit_grant" // promo / goodwill credit
 	SourceTypeCreditUse   LedgerSourceType = "credit_use"   // credit applied to invoice
 	SourceTypeRefund      LedgerSourceType = "refund"       // money returned to customer
+	SourceTypeCreditNote  LedgerSourceType = "credit_note"  // partial credit against a finalized invoice
 
 	// ======================
 	// Disputes (economic impact only)
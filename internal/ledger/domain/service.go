@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"gorm.io/gorm"
 )
 
-// LedgerService defines the ledger entry writer.
+// LedgerService defines the ledger entry writer and its read surface.
 type LedgerService interface {
 	CreateEntry(
 		ctx context.Context,
@@ -19,6 +21,65 @@ type LedgerService interface {
 		occurredAt time.Time,
 		lines []LedgerEntryLine,
 	) error
+	// CreateEntryTx writes the entry inside tx, so it commits or rolls back
+	// with the caller's own rows.
+	CreateEntryTx(
+		ctx context.Context,
+		tx *gorm.DB,
+		orgID snowflake.ID,
+		sourceType string,
+		sourceID snowflake.ID,
+		currency string,
+		occurredAt time.Time,
+		lines []LedgerEntryLine,
+	) error
+	// ListLedgerEntries pages through the context org's entries, newest
+	// first, each with all of its lines.
+	ListLedgerEntries(ctx context.Context, filter ListEntriesFilter) (ListEntriesResponse, error)
+}
+
+// MaxListEntriesPageSize is the largest number of entries one page returns.
+const MaxListEntriesPageSize = 250
+
+// ListEntriesFilter selects ledger entries. Empty fields do not filter; From
+// and To bound occurred_at as [From, To). AccountCode matches entries with
+// at least one line on that account, but every line of a matching entry is
+// returned so callers can check it balances.
+type ListEntriesFilter struct {
+	SourceType  string
+	SourceID    string
+	AccountCode string
+	Currency    string
+	From        *time.Time
+	To          *time.Time
+	PageToken   string
+	PageSize    int32
+}
+
+// EntryLine is a posting line as returned by ListLedgerEntries.
+type EntryLine struct {
+	ID          string               `json:"id"`
+	AccountID   string               `json:"account_id"`
+	AccountCode LedgerAccountCode    `json:"account_code"`
+	Direction   LedgerEntryDirection `json:"direction"`
+	Currency    string               `json:"currency"`
+	Amount      int64                `json:"amount"`
+}
+
+// Entry is a ledger entry header with its lines.
+type Entry struct {
+	ID         string           `json:"id"`
+	SourceType LedgerSourceType `json:"source_type"`
+	SourceID   string           `json:"source_id"`
+	Currency   string           `json:"currency"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	CreatedAt  time.Time        `json:"created_at"`
+	Lines      []EntryLine      `json:"lines"`
+}
+
+type ListEntriesResponse struct {
+	pagination.PageInfo
+	Entries []Entry `json:"entries"`
 }
 
 // Service is the package alias for LedgerService.
@@ -35,4 +96,9 @@ var (
 	ErrInvalidLineDirection = errors.New("invalid_line_direction")
 	ErrInvalidAccount       = errors.New("invalid_account")
 	ErrUnbalancedEntry      = errors.New("unbalanced_entry")
+	ErrInvalidPeriod        = errors.New("invalid_period")
+	ErrInvalidPageToken     = errors.New("invalid_page_token")
+
+	ErrInvalidIdempotencyKey  = errors.New("invalid_idempotency_key")
+	ErrIdempotencyKeyConflict = errors.New("idempotency_key_conflict")
 )
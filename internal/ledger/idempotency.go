@@ -0,0 +1,160 @@
+package ledger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyTTL is how long a stored result answers repeats of the same
+// key. Expired keys are removed by PurgeExpiredIdempotencyKeys.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// MaxIdempotencyKeyLength bounds caller-supplied idempotency keys.
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyKey identifies one ledger mutation. Scope names the operation
+// (e.g. "invoice.credit_note") so keys from different APIs never collide,
+// and Request fingerprints its parameters: reusing a key with different
+// parameters is rejected instead of silently returning the first result.
+type IdempotencyKey struct {
+	OrgID   snowflake.ID
+	Scope   string
+	Key     string
+	Request string
+}
+
+// WithIdempotency runs fn in a transaction at most once per key. The key is
+// reserved in idempotency_keys inside the same transaction, so a failed fn
+// leaves nothing behind and the client may retry, while a concurrent repeat
+// waits on the reservation and then replays the committed result. replayed
+// reports whether the result came from an earlier call, so callers can skip
+// side effects such as audit entries. An empty Key runs fn without any
+// idempotency check.
+func WithIdempotency[T any](ctx context.Context, db *gorm.DB, key IdempotencyKey, fn func(tx *gorm.DB) (T, error)) (result T, replayed bool, err error) {
+	key.Key = strings.TrimSpace(key.Key)
+	if key.Key == "" {
+		err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var fnErr error
+			result, fnErr = fn(tx)
+			return fnErr
+		})
+		return result, false, err
+	}
+	if key.OrgID == 0 || key.Scope == "" || len(key.Key) > MaxIdempotencyKeyLength {
+		return result, false, ledgerdomain.ErrInvalidIdempotencyKey
+	}
+
+	requestHash := hashIdempotencyRequest(key.Request)
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		reserved, err := reserveIdempotencyKey(ctx, tx, key, requestHash, now)
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			var stored struct {
+				RequestHash string `gorm:"column:request_hash"`
+				Response    []byte `gorm:"column:response"`
+			}
+			if err := tx.WithContext(ctx).Raw(
+				`SELECT request_hash, response
+				 FROM idempotency_keys
+				 WHERE org_id = ? AND scope = ? AND idempotency_key = ?`,
+				key.OrgID,
+				key.Scope,
+				key.Key,
+			).Scan(&stored).Error; err != nil {
+				return err
+			}
+			if stored.RequestHash != requestHash || len(stored.Response) == 0 {
+				return ledgerdomain.ErrIdempotencyKeyConflict
+			}
+			replayed = true
+			return json.Unmarshal(stored.Response, &result)
+		}
+
+		result, err = fn(tx)
+		if err != nil {
+			return err
+		}
+		response, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		return tx.WithContext(ctx).Exec(
+			`UPDATE idempotency_keys
+			 SET response = ?
+			 WHERE org_id = ? AND scope = ? AND idempotency_key = ?`,
+			string(response),
+			key.OrgID,
+			key.Scope,
+			key.Key,
+		).Error
+	})
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+	return result, replayed, nil
+}
+
+// reserveIdempotencyKey claims the key for this call. An expired key left
+// behind by a purge that has not run yet is taken over.
+func reserveIdempotencyKey(ctx context.Context, tx *gorm.DB, key IdempotencyKey, requestHash string, now time.Time) (bool, error) {
+	if err := tx.WithContext(ctx).Exec(
+		`DELETE FROM idempotency_keys
+		 WHERE org_id = ? AND scope = ? AND idempotency_key = ? AND expires_at <= ?`,
+		key.OrgID,
+		key.Scope,
+		key.Key,
+		now,
+	).Error; err != nil {
+		return false, err
+	}
+	result := tx.WithContext(ctx).Exec(
+		`INSERT INTO idempotency_keys (
+			org_id, scope, idempotency_key, request_hash, created_at, expires_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (org_id, scope, idempotency_key) DO NOTHING`,
+		key.OrgID,
+		key.Scope,
+		key.Key,
+		requestHash,
+		now,
+		now.Add(IdempotencyKeyTTL),
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes up to limit keys that expired before
+// now and returns how many it removed.
+func PurgeExpiredIdempotencyKeys(ctx context.Context, db *gorm.DB, now time.Time, limit int) (int64, error) {
+	result := db.WithContext(ctx).Exec(
+		`DELETE FROM idempotency_keys
+		 WHERE (org_id, scope, idempotency_key) IN (
+			SELECT org_id, scope, idempotency_key
+			FROM idempotency_keys
+			WHERE expires_at <= ?
+			LIMIT ?
+		 )`,
+		now,
+		limit,
+	)
+	return result.RowsAffected, result.Error
+}
+
+func hashIdempotencyRequest(request string) string {
+	sum := sha256.Sum256([]byte(request))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+)
+
+const defaultListEntriesPageSize = 50
+
+type entryRow struct {
+	ID         snowflake.ID                  `gorm:"column:id"`
+	SourceType ledgerdomain.LedgerSourceType `gorm:"column:source_type"`
+	SourceID   snowflake.ID                  `gorm:"column:source_id"`
+	Currency   string                        `gorm:"column:currency"`
+	OccurredAt time.Time                     `gorm:"column:occurred_at"`
+	CreatedAt  time.Time                     `gorm:"column:created_at"`
+}
+
+type entryLineRow struct {
+	ID            snowflake.ID                      `gorm:"column:id"`
+	LedgerEntryID snowflake.ID                      `gorm:"column:ledger_entry_id"`
+	AccountID     snowflake.ID                      `gorm:"column:account_id"`
+	AccountCode   *string                           `gorm:"column:account_code"`
+	Direction     ledgerdomain.LedgerEntryDirection `gorm:"column:direction"`
+	Currency      string                            `gorm:"column:currency"`
+	Amount        int64                             `gorm:"column:amount"`
+}
+
+// ListLedgerEntries pages through the org's ledger entries ordered by
+// occurred_at, newest first, with id breaking ties. Page tokens carry the
+// last entry's position, so entries posted while paging never shift pages.
+func (s *Service) ListLedgerEntries(ctx context.Context, filter ledgerdomain.ListEntriesFilter) (ledgerdomain.ListEntriesResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return ledgerdomain.ListEntriesResponse{}, ledgerdomain.ErrInvalidOrganization
+	}
+
+	query := `SELECT e.id, e.source_type, e.source_id, e.currency, e.occurred_at, e.created_at
+		FROM ledger_entries e
+		WHERE e.org_id = ?`
+	args := []any{orgID}
+
+	if sourceType := strings.TrimSpace(filter.SourceType); sourceType != "" {
+		query += ` AND e.source_type = ?`
+		args = append(args, sourceType)
+	}
+	if sourceID := strings.TrimSpace(filter.SourceID); sourceID != "" {
+		id, err := snowflake.ParseString(sourceID)
+		if err != nil || id == 0 {
+			return ledgerdomain.ListEntriesResponse{}, ledgerdomain.ErrInvalidSourceID
+		}
+		query += ` AND e.source_id = ?`
+		args = append(args, id)
+	}
+	if currency := strings.TrimSpace(filter.Currency); currency != "" {
+		query += ` AND e.currency = ?`
+		args = append(args, currency)
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return ledgerdomain.ListEntriesResponse{}, ledgerdomain.ErrInvalidPeriod
+	}
+	if filter.From != nil {
+		query += ` AND e.occurred_at >= ?`
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		query += ` AND e.occurred_at < ?`
+		args = append(args, filter.To.UTC())
+	}
+	if accountCode := strings.TrimSpace(filter.AccountCode); accountCode != "" {
+		query += ` AND EXISTS (
+			SELECT 1
+			FROM ledger_entry_lines l
+			JOIN ledger_accounts a ON a.id = l.account_id AND a.org_id = e.org_id
+			WHERE l.ledger_entry_id = e.id
+			  AND a.code = ?
+		)`
+		args = append(args, accountCode)
+	}
+	if token := strings.TrimSpace(filter.PageToken); token != "" {
+		occurredAt, lastID, err := decodeEntriesPageToken(token)
+		if err != nil {
+			return ledgerdomain.ListEntriesResponse{}, err
+		}
+		query += ` AND (e.occurred_at < ? OR (e.occurred_at = ? AND e.id < ?))`
+		args = append(args, occurredAt, occurredAt, lastID)
+	}
+
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultListEntriesPageSize
+	}
+	if pageSize > ledgerdomain.MaxListEntriesPageSize {
+		pageSize = ledgerdomain.MaxListEntriesPageSize
+	}
+	query += ` ORDER BY e.occurred_at DESC, e.id DESC LIMIT ?`
+	args = append(args, pageSize+1)
+
+	var rows []entryRow
+	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return ledgerdomain.ListEntriesResponse{}, err
+	}
+
+	resp := ledgerdomain.ListEntriesResponse{Entries: []ledgerdomain.Entry{}}
+	if len(rows) > int(pageSize) {
+		rows = rows[:pageSize]
+		last := rows[len(rows)-1]
+		token, err := pagination.EncodeCursor(pagination.Cursor{
+			ID:        last.ID.String(),
+			CreatedAt: last.OccurredAt.UTC().Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			return ledgerdomain.ListEntriesResponse{}, err
+		}
+		resp.NextPageToken = token
+		resp.HasMore = true
+	}
+	if len(rows) == 0 {
+		return resp, nil
+	}
+
+	entryIDs := make([]snowflake.ID, 0, len(rows))
+	for _, row := range rows {
+		entryIDs = append(entryIDs, row.ID)
+	}
+	var lines []entryLineRow
+	if err := s.db.WithContext(ctx).Raw(
+		`SELECT l.id, l.ledger_entry_id, l.account_id, a.code AS account_code,
+		        l.direction, l.currency, l.amount
+		 FROM ledger_entry_lines l
+		 LEFT JOIN ledger_accounts a ON a.id = l.account_id AND a.org_id = ?
+		 WHERE l.ledger_entry_id IN ?
+		 ORDER BY l.ledger_entry_id, l.id`,
+		orgID,
+		entryIDs,
+	).Scan(&lines).Error; err != nil {
+		return ledgerdomain.ListEntriesResponse{}, err
+	}
+	linesByEntry := make(map[snowflake.ID][]ledgerdomain.EntryLine, len(rows))
+	for _, line := range lines {
+		var accountCode ledgerdomain.LedgerAccountCode
+		if line.AccountCode != nil {
+			accountCode = ledgerdomain.LedgerAccountCode(*line.AccountCode)
+		}
+		linesByEntry[line.LedgerEntryID] = append(linesByEntry[line.LedgerEntryID], ledgerdomain.EntryLine{
+			ID:          line.ID.String(),
+			AccountID:   line.AccountID.String(),
+			AccountCode: accountCode,
+			Direction:   line.Direction,
+			Currency:    line.Currency,
+			Amount:      line.Amount,
+		})
+	}
+
+	resp.Entries = make([]ledgerdomain.Entry, 0, len(rows))
+	for _, row := range rows {
+		entryLines := linesByEntry[row.ID]
+		if entryLines == nil {
+			entryLines = []ledgerdomain.EntryLine{}
+		}
+		resp.Entries = append(resp.Entries, ledgerdomain.Entry{
+			ID:         row.ID.String(),
+			SourceType: row.SourceType,
+			SourceID:   row.SourceID.String(),
+			Currency:   row.Currency,
+			OccurredAt: row.OccurredAt.UTC(),
+			CreatedAt:  row.CreatedAt.UTC(),
+			Lines:      entryLines,
+		})
+	}
+	return resp, nil
+}
+
+// decodeEntriesPageToken returns the occurred_at and id of the last entry
+// on the previous page.
+func decodeEntriesPageToken(token string) (time.Time, snowflake.ID, error) {
+	cursor, err := pagination.DecodeCursor(token)
+	if err != nil {
+		return time.Time{}, 0, ledgerdomain.ErrInvalidPageToken
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, cursor.CreatedAt)
+	if err != nil {
+		return time.Time{}, 0, ledgerdomain.ErrInvalidPageToken
+	}
+	id, err := snowflake.ParseString(cursor.ID)
+	if err != nil || id == 0 {
+		return time.Time{}, 0, ledgerdomain.ErrInvalidPageToken
+	}
+	return occurredAt.UTC(), id, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestListLedgerEntries(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE ledger_accounts (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			code TEXT NOT NULL,
+			type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE ledger_entries (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			source_type TEXT NOT NULL,
+			source_id BIGINT NOT NULL,
+			currency TEXT NOT NULL,
+			occurred_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX ux_ledger_entries_source ON ledger_entries(org_id, source_type, source_id)`,
+		`CREATE TABLE ledger_entry_lines (
+			id BIGINT PRIMARY KEY,
+			ledger_entry_id BIGINT NOT NULL,
+			account_id BIGINT NOT NULL,
+			direction TEXT NOT NULL,
+			currency TEXT NOT NULL,
+			amount BIGINT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+
+	node, _ := snowflake.NewNode(1)
+	svc := NewService(Params{DB: db, Log: zap.NewNop(), GenID: node})
+	orgID := node.Generate()
+	otherOrgID := node.Generate()
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	accounts := map[ledgerdomain.LedgerAccountCode]snowflake.ID{}
+	for _, code := range []ledgerdomain.LedgerAccountCode{
+		ledgerdomain.AccountCodeAccountsReceivable,
+		ledgerdomain.AccountCodeRevenueUsage,
+		ledgerdomain.AccountCodeCash,
+	} {
+		accounts[code] = node.Generate()
+		if err := db.Exec(`INSERT INTO ledger_accounts (id, org_id, code, type, name) VALUES (?, ?, ?, 'assets', ?)`,
+			accounts[code], orgID, code, code).Error; err != nil {
+			t.Fatalf("seed account: %v", err)
+		}
+	}
+
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	post := func(org snowflake.ID, sourceType ledgerdomain.LedgerSourceType, sourceID snowflake.ID, currency string, at time.Time, debit, credit ledgerdomain.LedgerAccountCode, amount int64) {
+		t.Helper()
+		if err := svc.CreateEntry(context.Background(), org, string(sourceType), sourceID, currency, at, []ledgerdomain.LedgerEntryLine{
+			{AccountID: accounts[debit], Direction: ledgerdomain.LedgerEntryDirectionDebit, Currency: currency, Amount: amount},
+			{AccountID: accounts[credit], Direction: ledgerdomain.LedgerEntryDirectionCredit, Currency: currency, Amount: amount},
+		}); err != nil {
+			t.Fatalf("post entry: %v", err)
+		}
+	}
+	cycleID := node.Generate()
+	post(orgID, ledgerdomain.SourceTypeBillingCycle, cycleID, "USD", day, ledgerdomain.AccountCodeAccountsReceivable, ledgerdomain.AccountCodeRevenueUsage, 1_000)
+	post(orgID, ledgerdomain.SourceTypePayment, node.Generate(), "USD", day.Add(time.Hour), ledgerdomain.AccountCodeCash, ledgerdomain.AccountCodeAccountsReceivable, 600)
+	post(orgID, ledgerdomain.SourceTypePayment, node.Generate(), "EUR", day.Add(2*time.Hour), ledgerdomain.AccountCodeCash, ledgerdomain.AccountCodeAccountsReceivable, 400)
+	post(otherOrgID, ledgerdomain.SourceTypePayment, node.Generate(), "USD", day.Add(time.Hour), ledgerdomain.AccountCodeCash, ledgerdomain.AccountCodeAccountsReceivable, 999)
+
+	t.Run("returns the org's entries newest first with balanced lines", func(t *testing.T) {
+		resp, err := svc.ListLedgerEntries(ctx, ledgerdomain.ListEntriesFilter{})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(resp.Entries) != 3 || resp.HasMore {
+			t.Fatalf("expected 3 entries on one page, got %+v", resp)
+		}
+		if resp.Entries[0].Currency != "EUR" || resp.Entries[2].SourceID != cycleID.String() {
+			t.Fatalf("unexpected order: %+v", resp.Entries)
+		}
+		for _, entry := range resp.Entries {
+			if len(entry.Lines) != 2 {
+				t.Fatalf("expected 2 lines, got %+v", entry)
+			}
+			var balance int64
+			for _, line := range entry.Lines {
+				if line.AccountCode == "" {
+					t.Fatalf("expected account code on %+v", line)
+				}
+				if line.Direction == ledgerdomain.LedgerEntryDirectionDebit {
+					balance += line.Amount
+				} else {
+					balance -= line.Amount
+				}
+			}
+			if balance != 0 {
+				t.Fatalf("entry %s does not balance", entry.ID)
+			}
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		from, to := day.Add(30*time.Minute), day.Add(2*time.Hour)
+		cases := map[string]struct {
+			filter ledgerdomain.ListEntriesFilter
+			want   int
+		}{
+			"source type":  {ledgerdomain.ListEntriesFilter{SourceType: string(ledgerdomain.SourceTypePayment)}, 2},
+			"source id":    {ledgerdomain.ListEntriesFilter{SourceID: cycleID.String()}, 1},
+			"currency":     {ledgerdomain.ListEntriesFilter{Currency: "USD"}, 2},
+			"account code": {ledgerdomain.ListEntriesFilter{AccountCode: string(ledgerdomain.AccountCodeRevenueUsage)}, 1},
+			"date range":   {ledgerdomain.ListEntriesFilter{From: &from, To: &to}, 1},
+		}
+		for name, tc := range cases {
+			resp, err := svc.ListLedgerEntries(ctx, tc.filter)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if len(resp.Entries) != tc.want {
+				t.Fatalf("%s: expected %d entries, got %d", name, tc.want, len(resp.Entries))
+			}
+		}
+
+		resp, err := svc.ListLedgerEntries(ctx, ledgerdomain.ListEntriesFilter{AccountCode: string(ledgerdomain.AccountCodeRevenueUsage)})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(resp.Entries[0].Lines) != 2 {
+			t.Fatalf("expected every line of a matching entry, got %+v", resp.Entries[0].Lines)
+		}
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		var seen []string
+		filter := ledgerdomain.ListEntriesFilter{PageSize: 2}
+		for page := 0; ; page++ {
+			resp, err := svc.ListLedgerEntries(ctx, filter)
+			if err != nil {
+				t.Fatalf("page %d: %v", page, err)
+			}
+			for _, entry := range resp.Entries {
+				seen = append(seen, entry.ID)
+			}
+			if !resp.HasMore {
+				break
+			}
+			filter.PageToken = resp.NextPageToken
+		}
+		if len(seen) != 3 || seen[0] == seen[2] {
+			t.Fatalf("expected 3 distinct entries over two pages, got %v", seen)
+		}
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		cases := map[string]struct {
+			ctx    context.Context
+			filter ledgerdomain.ListEntriesFilter
+			want   error
+		}{
+			"organization": {context.Background(), ledgerdomain.ListEntriesFilter{}, ledgerdomain.ErrInvalidOrganization},
+			"source id":    {ctx, ledgerdomain.ListEntriesFilter{SourceID: "abc"}, ledgerdomain.ErrInvalidSourceID},
+			"period":       {ctx, ledgerdomain.ListEntriesFilter{From: &day, To: &day}, ledgerdomain.ErrInvalidPeriod},
+			"page token":   {ctx, ledgerdomain.ListEntriesFilter{PageToken: "not-a-token"}, ledgerdomain.ErrInvalidPageToken},
+		}
+		for name, tc := range cases {
+			if _, err := svc.ListLedgerEntries(tc.ctx, tc.filter); !errors.Is(err, tc.want) {
+				t.Fatalf("%s: expected %v, got %v", name, tc.want, err)
+			}
+		}
+	})
+}
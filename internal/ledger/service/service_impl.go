@@ -55,41 +55,92 @@ func (s *Service) CreateEntry(
 	occurredAt time.Time,
 	lines []ledgerdomain.LedgerEntryLine,
 ) error {
+	inserted := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		inserted, err = s.createEntryTx(ctx, tx, orgID, sourceType, sourceID, currency, occurredAt, lines)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if inserted && s.obsMetrics != nil {
+		s.obsMetrics.RecordLedgerEntry(ctx, sourceType)
+	}
+	return nil
+}
+
+// CreateEntryTx is CreateEntry inside the caller's transaction. The entry,
+// its lines, its outbox event and its audit entry commit or roll back with
+// tx. The entry is
+// counted in the ledger metrics as soon as it is written.
+func (s *Service) CreateEntryTx(
+	ctx context.Context,
+	tx *gorm.DB,
+	orgID snowflake.ID,
+	sourceType string,
+	sourceID snowflake.ID,
+	currency string,
+	occurredAt time.Time,
+	lines []ledgerdomain.LedgerEntryLine,
+) error {
+	inserted, err := s.createEntryTx(ctx, tx, orgID, sourceType, sourceID, currency, occurredAt, lines)
+	if err != nil {
+		return err
+	}
+	if inserted && s.obsMetrics != nil {
+		s.obsMetrics.RecordLedgerEntry(ctx, sourceType)
+	}
+	return nil
+}
+
+// createEntryTx validates and writes an entry in tx, and reports whether it
+// was inserted rather than already present for its source.
+func (s *Service) createEntryTx(
+	ctx context.Context,
+	tx *gorm.DB,
+	orgID snowflake.ID,
+	sourceType string,
+	sourceID snowflake.ID,
+	currency string,
+	occurredAt time.Time,
+	lines []ledgerdomain.LedgerEntryLine,
+) (bool, error) {
 	if orgID == 0 {
-		return ledgerdomain.ErrInvalidOrganization
+		return false, ledgerdomain.ErrInvalidOrganization
 	}
 
 	sourceType = strings.TrimSpace(sourceType)
 	if sourceType == "" {
-		return ledgerdomain.ErrInvalidSourceType
+		return false, ledgerdomain.ErrInvalidSourceType
 	}
 	if sourceID == 0 {
-		return ledgerdomain.ErrInvalidSourceID
+		return false, ledgerdomain.ErrInvalidSourceID
 	}
 
 	currency = strings.TrimSpace(currency)
 	if currency == "" {
-		return ledgerdomain.ErrInvalidCurrency
+		return false, ledgerdomain.ErrInvalidCurrency
 	}
 	if occurredAt.IsZero() {
-		return ledgerdomain.ErrInvalidOccurredAt
+		return false, ledgerdomain.ErrInvalidOccurredAt
 	}
 
 	if len(lines) < 2 {
-		return ledgerdomain.ErrInvalidEntryLines
+		return false, ledgerdomain.ErrInvalidEntryLines
 	}
 
 	normalized := make([]ledgerdomain.LedgerEntryLine, 0, len(lines))
 	for _, line := range lines {
 		if line.AccountID == 0 {
-			return ledgerdomain.ErrInvalidAccount
+			return false, ledgerdomain.ErrInvalidAccount
 		}
 		direction, err := normalizeDirection(line.Direction)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if line.Amount < 0 {
-			return ledgerdomain.ErrInvalidLineAmount
+			return false, ledgerdomain.ErrInvalidLineAmount
 		}
 		normalized = append(normalized, ledgerdomain.LedgerEntryLine{
 			AccountID: line.AccountID,
@@ -100,7 +151,7 @@ func (s *Service) CreateEntry(
 	}
 
 	if err := ledgerdomain.ValidateBalanced(normalized); err != nil {
-		return err
+		return false, err
 	}
 
 	auditSvc := s.auditSvc
@@ -108,85 +159,76 @@ func (s *Service) CreateEntry(
 		s.log.Warn("audit service unavailable for ledger entry", zap.String("source_type", string(sourceType)), zap.String("source_id", sourceID.String()))
 	}
 
-	inserted := false
-	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		entryID := s.genID.Generate()
-		now := time.Now().UTC()
-		result := tx.WithContext(ctx).Exec(
-			`INSERT INTO ledger_entries (
-				id, org_id, source_type, source_id, currency, occurred_at, created_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?)
-			ON CONFLICT (org_id, source_type, source_id) DO NOTHING`,
+	entryID := s.genID.Generate()
+	now := time.Now().UTC()
+	result := tx.WithContext(ctx).Exec(
+		`INSERT INTO ledger_entries (
+			id, org_id, source_type, source_id, currency, occurred_at, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (org_id, source_type, source_id) DO NOTHING`,
+		entryID,
+		orgID,
+		sourceType,
+		sourceID,
+		currency,
+		occurredAt.UTC(),
+		now,
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	for _, line := range normalized {
+		if err := tx.WithContext(ctx).Exec(
+			`INSERT INTO ledger_entry_lines (
+				id, ledger_entry_id, account_id, direction, currency, amount, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			s.genID.Generate(),
 			entryID,
-			orgID,
-			sourceType,
-			sourceID,
-			currency,
-			occurredAt.UTC(),
+			line.AccountID,
+			string(line.Direction),
+			line.Currency,
+			line.Amount,
 			now,
-		)
-		if result.Error != nil {
-			return result.Error
-		}
-		if result.RowsAffected == 0 {
-			return nil
-		}
-		inserted = true
-
-		for _, line := range normalized {
-			if err := tx.WithContext(ctx).Exec(
-				`INSERT INTO ledger_entry_lines (
-					id, ledger_entry_id, account_id, direction, currency, amount, created_at
-				) VALUES (?, ?, ?, ?, ?, ?, ?)`,
-				s.genID.Generate(),
-				entryID,
-				line.AccountID,
-				string(line.Direction),
-				line.Currency,
-				line.Amount,
-				now,
-			).Error; err != nil {
-				return err
-			}
-		}
-
-		if s.outbox != nil {
-			payload := map[string]any{
-				"ledger_entry_id": entryID.String(),
-				"source_type":     sourceType,
-				"source_id":       sourceID.String(),
-			}
-			if err := s.outbox.PublishTx(ctx, tx, events.Event{
-				OrgID:     orgID,
-				Type:      events.EventLedgerEntryCreated,
-				Payload:   payload,
-				DedupeKey: "ledger_entry:" + entryID.String(),
-			}); err != nil {
-				return err
-			}
+		).Error; err != nil {
+			return false, err
 		}
+	}
 
-		entryIDStr := entryID.String()
-		metadata := map[string]any{
+	if s.outbox != nil {
+		payload := map[string]any{
+			"ledger_entry_id": entryID.String(),
 			"source_type":     sourceType,
 			"source_id":       sourceID.String(),
-			"ledger_entry_id": entryIDStr,
 		}
-		if auditSvc != nil {
-			if err := auditSvc.AuditLog(ctx, &orgID, "", nil, "ledger.entry_created", "ledger_entry", &entryIDStr, metadata); err != nil {
-				s.log.Warn("failed to write ledger audit log", zap.Error(err))
-			}
+		if err := s.outbox.PublishTx(ctx, tx, events.Event{
+			OrgID:     orgID,
+			Type:      events.EventLedgerEntryCreated,
+			Payload:   payload,
+			DedupeKey: "ledger_entry:" + entryID.String(),
+		}); err != nil {
+			return false, err
 		}
+	}
 
-		return nil
-	})
-	if err != nil {
-		return err
+	entryIDStr := entryID.String()
+	metadata := map[string]any{
+		"source_type":     sourceType,
+		"source_id":       sourceID.String(),
+		"ledger_entry_id": entryIDStr,
 	}
-	if inserted && s.obsMetrics != nil {
-		s.obsMetrics.RecordLedgerEntry(ctx, sourceType)
+	if auditSvc != nil {
+		// A failed statement aborts the transaction, so the audit write
+		// fails the entry rather than being logged and skipped.
+		if err := auditSvc.AuditLogTx(ctx, tx, &orgID, "", nil, "ledger.entry_created", "ledger_entry", &entryIDStr, metadata); err != nil {
+			return false, err
+		}
 	}
-	return nil
+
+	return true, nil
 }
 
 func normalizeDirection(direction ledgerdomain.LedgerEntryDirection) (ledgerdomain.LedgerEntryDirection, error) {
@@ -1,11 +1,29 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/snowflake"
 )
 
+// Aggregation types control how a meter's usage events are reduced to a
+// billable quantity for a rating window. Unrecognized types are rated as SUM.
+const (
+	// AggregationSum bills the total of all recorded values.
+	AggregationSum = "SUM"
+	// AggregationMax bills the highest recorded value, e.g. peak seats.
+	AggregationMax = "MAX"
+	// AggregationLast bills the most recently recorded value, e.g. a final
+	// meter reading.
+	AggregationLast = "LAST"
+)
+
+// NormalizeAggregation trims and upper-cases an aggregation type.
+func NormalizeAggregation(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
 // Meter defines a usage measurement unit.
 type Meter struct {
 	ID          snowflake.ID `json:"id" gorm:"primaryKey"`
@@ -54,7 +54,7 @@ func (s *Service) Create(ctx context.Context, req meterdomain.CreateRequest) (*m
 		return nil, meterdomain.ErrInvalidName
 	}
 
-	aggregation := strings.TrimSpace(req.Aggregation)
+	aggregation := meterdomain.NormalizeAggregation(req.Aggregation)
 	if aggregation == "" {
 		return nil, meterdomain.ErrInvalidAggregation
 	}
@@ -144,7 +144,7 @@ func (s *Service) Update(ctx context.Context, req meterdomain.UpdateRequest) (*m
 	}
 
 	if req.Aggregation != nil {
-		aggregation := strings.TrimSpace(*req.Aggregation)
+		aggregation := meterdomain.NormalizeAggregation(*req.Aggregation)
 		if aggregation == "" {
 			return nil, meterdomain.ErrInvalidAggregation
 		}
@@ -0,0 +1,7 @@
+ALTER TABLE billing_cycles
+    ADD COLUMN IF NOT EXISTS rating_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_rating_retry_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_billing_cycles_rating_retry
+    ON billing_cycles (next_rating_retry_at)
+    WHERE status = 'CLOSING' AND rating_completed_at IS NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS credit_notes (
+  id BIGINT PRIMARY KEY,
+  org_id BIGINT NOT NULL,
+  invoice_id BIGINT NOT NULL,
+  customer_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  currency TEXT NOT NULL,
+  reason TEXT,
+  issued_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_notes_org_id
+  ON credit_notes(org_id);
+
+CREATE INDEX IF NOT EXISTS idx_credit_notes_invoice
+  ON credit_notes(org_id, invoice_id);
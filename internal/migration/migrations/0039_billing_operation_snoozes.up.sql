@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS billing_operation_snoozes (
+  id BIGINT PRIMARY KEY,
+  org_id BIGINT NOT NULL,
+  entity_type TEXT NOT NULL,
+  entity_id BIGINT NOT NULL,
+  snoozed_until TIMESTAMPTZ NOT NULL,
+  reason TEXT,
+  snoozed_by TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_billing_operation_snoozes_entity
+  ON billing_operation_snoozes(org_id, entity_type, entity_id, snoozed_until);
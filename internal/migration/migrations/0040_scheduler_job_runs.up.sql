@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS scheduler_job_runs (
+  id BIGINT PRIMARY KEY,
+  run_id TEXT NOT NULL,
+  job_name TEXT NOT NULL,
+  batch_size INT NOT NULL DEFAULT 0,
+  started_at TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ NOT NULL,
+  processed_count INT NOT NULL DEFAULT 0,
+  error_count INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduler_job_runs_job_started
+  ON scheduler_job_runs(job_name, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_scheduler_job_runs_started
+  ON scheduler_job_runs(started_at DESC);
@@ -0,0 +1,10 @@
+ALTER TABLE api_keys
+  ADD COLUMN IF NOT EXISTS rate_limit_per_minute INT,
+  ADD COLUMN IF NOT EXISTS rate_limit_unlimited BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE api_keys
+  DROP CONSTRAINT IF EXISTS chk_api_keys_rate_limit_per_minute;
+
+ALTER TABLE api_keys
+  ADD CONSTRAINT chk_api_keys_rate_limit_per_minute
+  CHECK (rate_limit_per_minute IS NULL OR rate_limit_per_minute > 0);
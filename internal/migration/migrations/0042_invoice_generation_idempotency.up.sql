@@ -0,0 +1,12 @@
+ALTER TABLE invoices
+  ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+
+-- Backfill with the key the scheduler derives from the billing cycle so that
+-- retries against historical cycles hit the existing invoice.
+UPDATE invoices
+   SET idempotency_key = 'billing_cycle:' || billing_cycle_id::text
+ WHERE idempotency_key IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_invoices_org_idempotency_key
+  ON invoices(org_id, idempotency_key)
+  WHERE idempotency_key IS NOT NULL;
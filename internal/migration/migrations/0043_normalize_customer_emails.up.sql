@@ -0,0 +1,10 @@
+-- Surrounding whitespace is trimmed whatever CUSTOMER_EMAIL_LOWERCASE says.
+-- Case is left alone: folding is a runtime setting and applies to writes
+-- from then on, so stored addresses keep the case they were created with.
+UPDATE customers
+   SET email = TRIM(email),
+       updated_at = CURRENT_TIMESTAMP
+ WHERE email <> TRIM(email);
+
+CREATE INDEX IF NOT EXISTS idx_customers_org_email
+  ON customers(org_id, email);
@@ -0,0 +1,6 @@
+ALTER TABLE customers
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_customers_org_active
+  ON customers(org_id)
+  WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS inbox_default_limit INT,
+  ADD COLUMN IF NOT EXISTS my_work_default_limit INT,
+  ADD COLUMN IF NOT EXISTS overdue_default_limit INT,
+  ADD COLUMN IF NOT EXISTS collection_default_limit INT;
+
+ALTER TABLE organization_billing_preferences
+  DROP CONSTRAINT IF EXISTS chk_org_billing_prefs_default_limits;
+
+ALTER TABLE organization_billing_preferences
+  ADD CONSTRAINT chk_org_billing_prefs_default_limits
+  CHECK (
+    (inbox_default_limit IS NULL OR inbox_default_limit BETWEEN 1 AND 200)
+    AND (my_work_default_limit IS NULL OR my_work_default_limit BETWEEN 1 AND 200)
+    AND (overdue_default_limit IS NULL OR overdue_default_limit BETWEEN 1 AND 200)
+    AND (collection_default_limit IS NULL OR collection_default_limit BETWEEN 1 AND 200)
+  );
@@ -0,0 +1,3 @@
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS overdue_business_days BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS overdue_holidays JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS invoice_reminders_sent (
+  invoice_id BIGINT NOT NULL,
+  offset_days INT NOT NULL,
+  org_id BIGINT NOT NULL,
+  sent_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (invoice_id, offset_days)
+);
+
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS invoice_reminders_opt_out BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,11 @@
+-- Progress of an in-flight performance scoring run. A row exists only while
+-- a run for the period is unfinished; it is removed once every operator of
+-- the period has been scored.
+CREATE TABLE IF NOT EXISTS finops_scoring_checkpoints (
+  period_type TEXT NOT NULL,
+  period_start TIMESTAMPTZ NOT NULL,
+  last_org_id BIGINT NOT NULL,
+  last_user_id TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (period_type, period_start)
+);
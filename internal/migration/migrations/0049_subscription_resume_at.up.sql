@@ -0,0 +1,7 @@
+-- When set on a paused subscription, the scheduler resumes it at this time.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS resume_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_paused_resume_at
+    ON subscriptions (resume_at)
+    WHERE status = 'PAUSED' AND resume_at IS NOT NULL;
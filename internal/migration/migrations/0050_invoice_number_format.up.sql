@@ -0,0 +1,8 @@
+-- Per-org invoice number format. NULL keeps the default template.
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS invoice_number_format TEXT,
+  ADD COLUMN IF NOT EXISTS invoice_number_prefix TEXT;
+
+-- Invoices are numbered at finalization, so drafts have no sequence yet.
+ALTER TABLE invoices
+  ALTER COLUMN invoice_seq DROP NOT NULL;
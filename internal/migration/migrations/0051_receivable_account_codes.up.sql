@@ -0,0 +1,4 @@
+-- Ledger account codes that make up an org's accounts receivable, as a JSON
+-- array of strings. NULL keeps the single accounts_receivable account.
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS receivable_account_codes JSONB;
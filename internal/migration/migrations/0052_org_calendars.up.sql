@@ -0,0 +1,14 @@
+-- Working calendar of an organization, shared by time-aware features such as
+-- business-day overdue counts. Work days are time.Weekday numbers (0 is
+-- Sunday), holidays are YYYY-MM-DD dates in the calendar's timezone. Orgs
+-- without a row work every day, all day, in UTC.
+CREATE TABLE IF NOT EXISTS org_calendars (
+  org_id BIGINT PRIMARY KEY,
+  timezone TEXT NOT NULL DEFAULT 'UTC',
+  work_days JSONB NOT NULL DEFAULT '[0,1,2,3,4,5,6]'::jsonb,
+  work_start TEXT NOT NULL DEFAULT '00:00',
+  work_end TEXT NOT NULL DEFAULT '24:00',
+  holidays JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,4 @@
+ALTER TABLE customers
+  ADD COLUMN IF NOT EXISTS phone TEXT,
+  ADD COLUMN IF NOT EXISTS preferred_contact_channel TEXT,
+  ADD COLUMN IF NOT EXISTS do_not_contact BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,14 @@
+-- Snapshots are recomputed in place with INSERT ... ON CONFLICT, which
+-- relies on the (org_id, user_id, period_type, period_start) identity and
+-- records the scoring version and last recompute time on the row.
+ALTER TABLE finops_performance_snapshots
+  ADD COLUMN IF NOT EXISTS scoring_version TEXT NOT NULL DEFAULT 'v1_equal_weight',
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_finops_snapshots_identity
+ON finops_performance_snapshots (
+    org_id,
+    user_id,
+    period_type,
+    period_start
+);
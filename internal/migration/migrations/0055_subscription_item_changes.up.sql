@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS subscription_item_changes (
+  id BIGINT PRIMARY KEY,
+  org_id BIGINT NOT NULL,
+  subscription_id BIGINT NOT NULL,
+  price_id BIGINT NOT NULL,
+  meter_id BIGINT,
+  quantity SMALLINT NOT NULL,
+  effective_from TIMESTAMPTZ NOT NULL,
+  effective_to TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_item_changes_window ON subscription_item_changes(org_id, subscription_id, effective_from);
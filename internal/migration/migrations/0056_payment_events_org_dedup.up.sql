@@ -0,0 +1,21 @@
+-- Deduplicate payment events per org. Replayed webhooks must not be stored
+-- twice, or settled-amount sums double count them.
+
+-- Drop duplicates left by earlier replays, keeping the processed copy, then
+-- the first one received.
+DELETE FROM payment_events pe
+USING (
+  SELECT id,
+         ROW_NUMBER() OVER (
+           PARTITION BY org_id, provider, provider_event_id
+           ORDER BY processed_at IS NULL, received_at, id
+         ) AS rn
+  FROM payment_events
+) ranked
+WHERE pe.id = ranked.id
+  AND ranked.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_payment_events_org_provider_event_id
+  ON payment_events(org_id, provider, provider_event_id);
+
+DROP INDEX IF EXISTS ux_payment_events_provider_event_id;
@@ -0,0 +1,2 @@
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS require_handoff_note BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,25 @@
+-- Cold storage for billing operations actions past the retention window.
+-- Rows keep their original id; the unique bucket and idempotency indexes
+-- only guard the live table.
+CREATE TABLE IF NOT EXISTS billing_operation_actions_archive (
+  id BIGINT PRIMARY KEY,
+  org_id BIGINT NOT NULL,
+  entity_type TEXT NOT NULL,
+  entity_id BIGINT NOT NULL,
+  action_type TEXT NOT NULL,
+  action_bucket DATE NOT NULL,
+  idempotency_key TEXT,
+  metadata JSONB NOT NULL DEFAULT '{}',
+  actor_type TEXT,
+  actor_id TEXT,
+  assignment_id BIGINT,
+  created_at TIMESTAMPTZ NOT NULL,
+  archived_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_billing_operation_actions_archive_entity
+  ON billing_operation_actions_archive(org_id, entity_type, entity_id);
+
+-- The retention sweep scans live actions by age.
+CREATE INDEX IF NOT EXISTS idx_billing_operation_actions_created_at
+  ON billing_operation_actions(created_at);
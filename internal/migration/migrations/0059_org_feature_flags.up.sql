@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS org_feature_flags (
+  org_id BIGINT NOT NULL,
+  flag TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT false,
+  updated_by TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (org_id, flag)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE billing_operation_assignments
+  ADD COLUMN IF NOT EXISTS sla_paused_until TIMESTAMPTZ;
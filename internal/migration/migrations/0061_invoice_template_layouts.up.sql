@@ -0,0 +1,7 @@
+-- PDF layout per template, and a per-customer template override that wins
+-- over the org default.
+ALTER TABLE invoice_templates
+    ADD COLUMN IF NOT EXISTS layout TEXT NOT NULL DEFAULT 'detailed';
+
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS invoice_template_id BIGINT;
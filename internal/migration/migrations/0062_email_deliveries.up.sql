@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS email_deliveries (
+  id BIGINT PRIMARY KEY,
+  org_id BIGINT NOT NULL,
+  message_id TEXT NOT NULL,
+  customer_id BIGINT,
+  invoice_id BIGINT,
+  recipient TEXT NOT NULL,
+  email_type TEXT NOT NULL,
+  status TEXT NOT NULL,
+  sent_at TIMESTAMPTZ NOT NULL,
+  status_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_email_deliveries_message_id
+  ON email_deliveries (message_id);
+
+CREATE INDEX IF NOT EXISTS idx_email_deliveries_customer_sent
+  ON email_deliveries (org_id, customer_id, sent_at DESC);
@@ -0,0 +1,21 @@
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS min_invoice_amount BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS invoice_carry_forwards (
+  id BIGINT PRIMARY KEY,
+  org_id BIGINT NOT NULL,
+  subscription_id BIGINT NOT NULL,
+  source_billing_cycle_id BIGINT NOT NULL,
+  currency TEXT NOT NULL,
+  amount BIGINT NOT NULL,
+  applied_billing_cycle_id BIGINT,
+  applied_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_invoice_carry_forwards_source_cycle
+  ON invoice_carry_forwards (source_billing_cycle_id);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_carry_forwards_pending
+  ON invoice_carry_forwards (org_id, subscription_id)
+  WHERE applied_billing_cycle_id IS NULL;
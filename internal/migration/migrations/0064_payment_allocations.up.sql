@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS payment_allocations (
+  id BIGINT PRIMARY KEY,
+  org_id BIGINT NOT NULL,
+  payment_event_id BIGINT NOT NULL,
+  invoice_id BIGINT NOT NULL,
+  reference TEXT NOT NULL DEFAULT '',
+  allocated_by TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_payment_allocations_payment_event
+  ON payment_allocations (payment_event_id);
+
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_invoice
+  ON payment_allocations (org_id, invoice_id);
@@ -0,0 +1,20 @@
+-- Disputed portions of invoice lines. The disputed amount stays on the
+-- invoice balance; it is only excluded from collections pressure. A line has
+-- at most one dispute, updated in place when disputed again.
+CREATE TABLE IF NOT EXISTS invoice_line_disputes (
+  id BIGINT PRIMARY KEY,
+  org_id BIGINT NOT NULL,
+  invoice_id BIGINT NOT NULL,
+  invoice_item_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  reason TEXT NOT NULL,
+  disputed_by TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_invoice_line_disputes_line
+  ON invoice_line_disputes(org_id, invoice_item_id);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_line_disputes_invoice
+  ON invoice_line_disputes(org_id, invoice_id);
@@ -0,0 +1,24 @@
+-- Partial indexes for the scheduler's billing cycle work queries. Each one
+-- covers a single lifecycle step, so it stays small as finished cycles pile
+-- up, and is keyed on the queries' ORDER BY (period_end, id) so a batch reads
+-- the oldest rows without a sort. The WHERE clauses mirror the predicates in
+-- internal/scheduler/locks.go.
+CREATE INDEX IF NOT EXISTS idx_billing_cycles_open_due
+    ON billing_cycles (period_end, id)
+    WHERE status = 'OPEN';
+
+CREATE INDEX IF NOT EXISTS idx_billing_cycles_closing_unrated
+    ON billing_cycles (period_end, id)
+    WHERE status = 'CLOSING' AND rating_completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_billing_cycles_closing_rated
+    ON billing_cycles (period_end, id)
+    WHERE status = 'CLOSING' AND rating_completed_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_billing_cycles_closed_uninvoiced
+    ON billing_cycles (period_end, id)
+    WHERE status = 'CLOSED' AND invoiced_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_billing_cycles_closed_unfinalized
+    ON billing_cycles (period_end, id)
+    WHERE status = 'CLOSED' AND invoiced_at IS NOT NULL AND invoice_finalized_at IS NULL;
@@ -0,0 +1,6 @@
+-- When true, resolving billing operations work hands the entity back to the
+-- inbox: it reappears while it still meets the risk criteria and can be
+-- claimed again. When false, resolved is terminal. NULL until an org picks a
+-- behaviour, which keeps what resolving did before the preference existed.
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS release_on_resolve BOOLEAN;
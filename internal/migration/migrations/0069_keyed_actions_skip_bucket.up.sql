@@ -0,0 +1,8 @@
+-- Actions recorded with an idempotency key are deduplicated on the key
+-- alone. The once-a-day bucket only collapses unkeyed actions, so keyed
+-- system actions such as line disputes are all kept.
+DROP INDEX IF EXISTS ux_billing_operation_actions_bucket;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_billing_operation_actions_bucket
+  ON billing_operation_actions(org_id, entity_type, entity_id, action_type, action_bucket)
+  WHERE idempotency_key IS NULL;
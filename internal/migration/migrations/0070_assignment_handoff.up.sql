@@ -0,0 +1,7 @@
+-- The last reassignment of an assignment: when it was handed to the current
+-- assignee, by whom, and the handoff note. Kept apart from the release
+-- columns, which describe work that was given back to the inbox.
+ALTER TABLE billing_operation_assignments
+  ADD COLUMN IF NOT EXISTS handed_off_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS handed_off_by TEXT,
+  ADD COLUMN IF NOT EXISTS handoff_note TEXT;
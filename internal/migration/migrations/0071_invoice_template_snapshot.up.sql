@@ -0,0 +1,5 @@
+-- The invoice template as it was when the invoice was finalized. PDFs of
+-- finalized invoices render from it, so editing a template does not change
+-- invoices already issued with it.
+ALTER TABLE invoices
+  ADD COLUMN IF NOT EXISTS template_snapshot JSONB;
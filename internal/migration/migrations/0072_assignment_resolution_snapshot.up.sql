@@ -0,0 +1,5 @@
+-- The entity as it stood when the assignment was resolved or released, so
+-- reviews of finished work show the state it was closed on rather than the
+-- state now. NULL for assignments finished before this column existed.
+ALTER TABLE billing_operation_assignments
+  ADD COLUMN IF NOT EXISTS resolution_snapshot JSONB;
@@ -0,0 +1,6 @@
+-- Per-org collection queue filters. overdue_only hides balances that are
+-- not yet due; a positive max_age_days hides invoices due more than that
+-- many days ago.
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS collection_queue_overdue_only BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS collection_queue_max_age_days INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- When true, system actors such as the SLA monitor show up as members of the
+-- org's team workload and performance views. They are not operators, so
+-- they are left out by default.
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS team_views_include_system_actors BOOLEAN NOT NULL DEFAULT false;
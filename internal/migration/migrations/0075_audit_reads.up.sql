@@ -0,0 +1,4 @@
+-- When true, viewing a sensitive financial report (exposure, AR health,
+-- customer balances) records an audit entry for the org.
+ALTER TABLE organization_billing_preferences
+  ADD COLUMN IF NOT EXISTS audit_reads BOOLEAN NOT NULL DEFAULT false;
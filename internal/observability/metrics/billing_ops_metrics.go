@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Billing operations reads bounded by a query timeout.
+const (
+	BillingOpsEndpointInbox            = "inbox"
+	BillingOpsEndpointMyWork           = "my_work"
+	BillingOpsEndpointExposureAnalysis = "exposure_analysis"
+)
+
+// BillingOpsMetrics tracks billing operations reads.
+type BillingOpsMetrics struct {
+	queryTimeouts *prometheus.CounterVec
+}
+
+var (
+	billingOpsMetricsOnce sync.Once
+	billingOpsMetrics     *BillingOpsMetrics
+)
+
+// BillingOps returns the singleton billing operations metrics registry.
+func BillingOps() *BillingOpsMetrics {
+	return BillingOpsWithConfig(Config{})
+}
+
+// BillingOpsWithConfig returns the singleton billing operations metrics
+// registry using config labels.
+func BillingOpsWithConfig(cfg Config) *BillingOpsMetrics {
+	billingOpsMetricsOnce.Do(func() {
+		billingOpsMetrics = newBillingOpsMetrics(prometheus.DefaultRegisterer, cfg)
+	})
+	return billingOpsMetrics
+}
+
+// ResetBillingOpsMetricsForTest resets the billing operations metrics
+// singleton for tests.
+func ResetBillingOpsMetricsForTest() {
+	billingOpsMetricsOnce = sync.Once{}
+	billingOpsMetrics = nil
+}
+
+func newBillingOpsMetrics(registerer prometheus.Registerer, cfg Config) *BillingOpsMetrics {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = "railzway"
+	}
+	environment := strings.TrimSpace(cfg.Environment)
+	if environment == "" {
+		environment = "unknown"
+	}
+
+	queryTimeouts := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "railzway_billing_ops_query_timeouts_total",
+			Help: "Billing operations reads cancelled by their query timeout, by endpoint.",
+			ConstLabels: prometheus.Labels{
+				"service": serviceName,
+				"env":     environment,
+			},
+		},
+		[]string{"endpoint"}, // inbox | my_work | exposure_analysis
+	)
+	registerer.MustRegister(queryTimeouts)
+
+	return &BillingOpsMetrics{queryTimeouts: queryTimeouts}
+}
+
+// IncQueryTimeout counts one read of endpoint that hit its query timeout.
+func (m *BillingOpsMetrics) IncQueryTimeout(endpoint string) {
+	if m == nil {
+		return
+	}
+	m.queryTimeouts.WithLabelValues(endpoint).Inc()
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestIncQueryTimeout(t *testing.T) {
+	metrics := newBillingOpsMetrics(prometheus.NewRegistry(), Config{
+		ServiceName: "railzway",
+		Environment: "test",
+	})
+
+	metrics.IncQueryTimeout(BillingOpsEndpointInbox)
+	metrics.IncQueryTimeout(BillingOpsEndpointInbox)
+	metrics.IncQueryTimeout(BillingOpsEndpointExposureAnalysis)
+
+	if got := testutil.ToFloat64(metrics.queryTimeouts.WithLabelValues(BillingOpsEndpointInbox)); got != 2 {
+		t.Fatalf("expected 2 inbox timeouts, got %v", got)
+	}
+	if got := testutil.CollectAndCount(metrics.queryTimeouts); got != 2 {
+		t.Fatalf("expected 2 endpoint series, got %d", got)
+	}
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EmailMetrics tracks outgoing customer emails and the delivery statuses
+// the email provider reports for them. The bounce rate is
+// railzway_email_delivery_status_total{status="bounced"} over
+// railzway_email_sent_total.
+type EmailMetrics struct {
+	sent     *prometheus.CounterVec
+	statuses *prometheus.CounterVec
+}
+
+var (
+	emailMetricsOnce sync.Once
+	emailMetrics     *EmailMetrics
+)
+
+// Email returns the singleton email metrics registry.
+func Email() *EmailMetrics {
+	return EmailWithConfig(Config{})
+}
+
+// EmailWithConfig returns the singleton email metrics registry using config
+// labels.
+func EmailWithConfig(cfg Config) *EmailMetrics {
+	emailMetricsOnce.Do(func() {
+		emailMetrics = newEmailMetrics(prometheus.DefaultRegisterer, cfg)
+	})
+	return emailMetrics
+}
+
+// ResetEmailMetricsForTest resets the email metrics singleton for tests.
+func ResetEmailMetricsForTest() {
+	emailMetricsOnce = sync.Once{}
+	emailMetrics = nil
+}
+
+func newEmailMetrics(registerer prometheus.Registerer, cfg Config) *EmailMetrics {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = "railzway"
+	}
+	environment := strings.TrimSpace(cfg.Environment)
+	if environment == "" {
+		environment = "unknown"
+	}
+	constLabels := prometheus.Labels{
+		"service": serviceName,
+		"env":     environment,
+	}
+
+	sent := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "railzway_email_sent_total",
+		Help:        "Customer emails handed to the email provider, by type.",
+		ConstLabels: constLabels,
+	}, []string{"type"}) // invoice | reminder
+	statuses := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "railzway_email_delivery_status_total",
+		Help:        "Delivery statuses reported by the email provider, by email type.",
+		ConstLabels: constLabels,
+	}, []string{"type", "status"}) // delivered | bounced | complained | failed
+	registerer.MustRegister(sent, statuses)
+
+	return &EmailMetrics{sent: sent, statuses: statuses}
+}
+
+// IncSent counts one email of emailType handed to the provider.
+func (m *EmailMetrics) IncSent(emailType string) {
+	if m == nil {
+		return
+	}
+	m.sent.WithLabelValues(emailType).Inc()
+}
+
+// IncDeliveryStatus counts one status callback for an email of emailType.
+func (m *EmailMetrics) IncDeliveryStatus(emailType, status string) {
+	if m == nil {
+		return
+	}
+	m.statuses.WithLabelValues(emailType, status).Inc()
+}
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEmailMetrics(t *testing.T) {
+	metrics := newEmailMetrics(prometheus.NewRegistry(), Config{
+		ServiceName: "railzway",
+		Environment: "test",
+	})
+
+	metrics.IncSent("invoice")
+	metrics.IncSent("invoice")
+	metrics.IncSent("reminder")
+	metrics.IncDeliveryStatus("invoice", "bounced")
+
+	if got := testutil.ToFloat64(metrics.sent.WithLabelValues("invoice")); got != 2 {
+		t.Fatalf("expected 2 invoice emails sent, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.statuses.WithLabelValues("invoice", "bounced")); got != 1 {
+		t.Fatalf("expected 1 bounced invoice email, got %v", got)
+	}
+}
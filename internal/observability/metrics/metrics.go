@@ -30,6 +30,7 @@ type Config struct {
 type Metrics struct {
 	usageIngest      metric.Int64Counter
 	paymentEvents    metric.Int64Counter
+	paymentDeduped   metric.Int64Counter
 	ledgerEntries    metric.Int64Counter
 	rateLimitAllowed metric.Int64Counter
 	rateLimitDenied  metric.Int64Counter
@@ -89,6 +90,10 @@ func New(cfg Config, provider metric.MeterProvider) (*Metrics, error) {
 	if err != nil {
 		return nil, err
 	}
+	paymentDeduped, err := meter.Int64Counter("railzway_payment_events_deduplicated_total")
+	if err != nil {
+		return nil, err
+	}
 	ledgerEntries, err := meter.Int64Counter("railzway_ledger_entries_total")
 	if err != nil {
 		return nil, err
@@ -105,6 +110,7 @@ func New(cfg Config, provider metric.MeterProvider) (*Metrics, error) {
 	return &Metrics{
 		usageIngest:      usageIngest,
 		paymentEvents:    paymentEvents,
+		paymentDeduped:   paymentDeduped,
 		ledgerEntries:    ledgerEntries,
 		rateLimitAllowed: rateLimitAllowed,
 		rateLimitDenied:  rateLimitDenied,
@@ -132,6 +138,19 @@ func (m *Metrics) RecordPaymentEvent(ctx context.Context, provider, eventType st
 	m.paymentEvents.Add(ctx, 1, metric.WithAttributes(attrs...))
 }
 
+// RecordPaymentEventDeduplicated counts payment events dropped as replays of
+// an event already stored.
+func (m *Metrics) RecordPaymentEventDeduplicated(ctx context.Context, provider, eventType string) {
+	if m == nil {
+		return
+	}
+	attrs := FilterAttributes(
+		attribute.String("provider", strings.TrimSpace(provider)),
+		attribute.String("event_type", strings.TrimSpace(eventType)),
+	)
+	m.paymentDeduped.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
 // RecordLedgerEntry increments ledger entry counts.
 func (m *Metrics) RecordLedgerEntry(ctx context.Context, sourceType string) {
 	if m == nil {
@@ -141,26 +160,30 @@ func (m *Metrics) RecordLedgerEntry(ctx context.Context, sourceType string) {
 	m.ledgerEntries.Add(ctx, 1, metric.WithAttributes(attrs...))
 }
 
-// RecordRateLimitAllowed increments rate limit allow counts.
-func (m *Metrics) RecordRateLimitAllowed(ctx context.Context, orgID, endpoint string) {
+// RecordRateLimitAllowed increments rate limit allow counts, labeled by the
+// API key's rate-limit tier.
+func (m *Metrics) RecordRateLimitAllowed(ctx context.Context, orgID, endpoint, tier string) {
 	if m == nil {
 		return
 	}
 	attrs := FilterAttributes(
 		attribute.String("org_id", strings.TrimSpace(orgID)),
 		attribute.String("endpoint", strings.TrimSpace(endpoint)),
+		attribute.String("tier", strings.TrimSpace(tier)),
 	)
 	m.rateLimitAllowed.Add(ctx, 1, metric.WithAttributes(attrs...))
 }
 
-// RecordRateLimitDenied increments rate limit deny counts.
-func (m *Metrics) RecordRateLimitDenied(ctx context.Context, orgID, endpoint, reason string) {
+// RecordRateLimitDenied increments rate limit deny counts, labeled by the
+// API key's rate-limit tier.
+func (m *Metrics) RecordRateLimitDenied(ctx context.Context, orgID, endpoint, tier, reason string) {
 	if m == nil {
 		return
 	}
 	attrs := FilterAttributes(
 		attribute.String("org_id", strings.TrimSpace(orgID)),
 		attribute.String("endpoint", strings.TrimSpace(endpoint)),
+		attribute.String("tier", strings.TrimSpace(tier)),
 		attribute.String("reason", strings.TrimSpace(reason)),
 	)
 	m.rateLimitDenied.Add(ctx, 1, metric.WithAttributes(attrs...))
@@ -196,6 +219,7 @@ var allowedLabelKeys = map[attribute.Key]struct{}{
 	"event_type":  {},
 	"source_type": {},
 	"reason":      {},
+	"tier":        {},
 }
 
 // FilterAttributes strips disallowed labels to keep metrics low-cardinality.
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reasons a stored public invoice token could not be decrypted.
+const (
+	PublicTokenDecryptReasonEncoding  = "encoding"
+	PublicTokenDecryptReasonKey       = "key"
+	PublicTokenDecryptReasonTruncated = "truncated"
+	PublicTokenDecryptReasonAuth      = "auth"
+)
+
+// PublicTokenMetrics tracks public invoice token handling.
+type PublicTokenMetrics struct {
+	decryptFailures *prometheus.CounterVec
+	missingTokens   *prometheus.GaugeVec
+}
+
+var (
+	publicTokenMetricsOnce sync.Once
+	publicTokenMetrics     *PublicTokenMetrics
+)
+
+// PublicToken returns the singleton public token metrics registry.
+func PublicToken() *PublicTokenMetrics {
+	return PublicTokenWithConfig(Config{})
+}
+
+// PublicTokenWithConfig returns the singleton public token metrics registry
+// using config labels.
+func PublicTokenWithConfig(cfg Config) *PublicTokenMetrics {
+	publicTokenMetricsOnce.Do(func() {
+		publicTokenMetrics = newPublicTokenMetrics(prometheus.DefaultRegisterer, cfg)
+	})
+	return publicTokenMetrics
+}
+
+// ResetPublicTokenMetricsForTest resets the public token metrics singleton
+// for tests.
+func ResetPublicTokenMetricsForTest() {
+	publicTokenMetricsOnce = sync.Once{}
+	publicTokenMetrics = nil
+}
+
+func newPublicTokenMetrics(registerer prometheus.Registerer, cfg Config) *PublicTokenMetrics {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = "railzway"
+	}
+	environment := strings.TrimSpace(cfg.Environment)
+	if environment == "" {
+		environment = "unknown"
+	}
+
+	decryptFailures := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "railzway_public_token_decrypt_failures_total",
+			Help: "Stored public invoice tokens that could not be decrypted, by reason.",
+			ConstLabels: prometheus.Labels{
+				"service": serviceName,
+				"env":     environment,
+			},
+		},
+		[]string{"reason"}, // encoding | key | truncated | auth
+	)
+
+	// Open invoices without a token have no pay link to send.
+	missingTokens := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "railzway_public_token_missing_invoices",
+			Help: "Finalized unpaid invoices without an active public token, by org.",
+			ConstLabels: prometheus.Labels{
+				"service": serviceName,
+				"env":     environment,
+			},
+		},
+		[]string{"org_id"},
+	)
+	registerer.MustRegister(decryptFailures, missingTokens)
+
+	return &PublicTokenMetrics{
+		decryptFailures: decryptFailures,
+		missingTokens:   missingTokens,
+	}
+}
+
+// IncDecryptFailure counts one public token that failed to decrypt.
+func (m *PublicTokenMetrics) IncDecryptFailure(reason string) {
+	if m == nil {
+		return
+	}
+	m.decryptFailures.WithLabelValues(reason).Inc()
+}
+
+// SetMissingPublicTokens replaces the per-org count of finalized unpaid
+// invoices without an active public token. Orgs missing from counts have
+// full coverage and are dropped from the gauge.
+func (m *PublicTokenMetrics) SetMissingPublicTokens(counts map[string]int) {
+	if m == nil || m.missingTokens == nil {
+		return
+	}
+	m.missingTokens.Reset()
+	for orgID, count := range counts {
+		m.missingTokens.WithLabelValues(orgID).Set(float64(count))
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestIncDecryptFailure(t *testing.T) {
+	metrics := newPublicTokenMetrics(prometheus.NewRegistry(), Config{
+		ServiceName: "railzway",
+		Environment: "test",
+	})
+
+	metrics.IncDecryptFailure(PublicTokenDecryptReasonAuth)
+	metrics.IncDecryptFailure(PublicTokenDecryptReasonAuth)
+	metrics.IncDecryptFailure(PublicTokenDecryptReasonEncoding)
+
+	if got := testutil.ToFloat64(metrics.decryptFailures.WithLabelValues(PublicTokenDecryptReasonAuth)); got != 2 {
+		t.Fatalf("expected 2 auth failures, got %v", got)
+	}
+	if got := testutil.CollectAndCount(metrics.decryptFailures); got != 2 {
+		t.Fatalf("expected 2 reason series, got %d", got)
+	}
+}
+
+func TestSetMissingPublicTokens(t *testing.T) {
+	metrics := newPublicTokenMetrics(prometheus.NewRegistry(), Config{
+		ServiceName: "railzway",
+		Environment: "test",
+	})
+
+	metrics.SetMissingPublicTokens(map[string]int{"1": 3, "2": 1})
+	if got := testutil.ToFloat64(metrics.missingTokens.WithLabelValues("1")); got != 3 {
+		t.Fatalf("expected 3 invoices missing a token in org 1, got %v", got)
+	}
+
+	metrics.SetMissingPublicTokens(map[string]int{"2": 4})
+	if got := testutil.ToFloat64(metrics.missingTokens.WithLabelValues("2")); got != 4 {
+		t.Fatalf("expected 4 invoices missing a token in org 2, got %v", got)
+	}
+	if got := testutil.CollectAndCount(metrics.missingTokens); got != 1 {
+		t.Fatalf("expected the covered org to be dropped, got %d series", got)
+	}
+}
@@ -66,16 +66,23 @@ type SchedulerMetrics struct {
 	jobRuns          *prometheus.CounterVec
 	jobDurationV2    *prometheus.HistogramVec
 	jobTimeoutsV2    *prometheus.CounterVec
+	jobInterrupted   *prometheus.CounterVec
+	jobLeader        *prometheus.GaugeVec
+	jobLeaderSkips   *prometheus.CounterVec
 	jobErrorsV2      *prometheus.CounterVec
 	batchProcessedV2 *prometheus.CounterVec
 	batchDeferred    *prometheus.CounterVec
 	runLoopLag       prometheus.Observer
+	finalizePending  prometheus.Gauge
+	openCycleAge     *prometheus.GaugeVec
+	arDrift          *prometheus.GaugeVec
 	jobDuration      *prometheus.HistogramVec
 	jobTimeouts      *prometheus.CounterVec
 	jobErrors        *prometheus.CounterVec
 	batchProcessed   *prometheus.CounterVec
 	cycleTransitions *prometheus.CounterVec
 	cycleErrors      *prometheus.CounterVec
+	cycleForceClose  *prometheus.CounterVec
 	dbLockWait       *prometheus.HistogramVec
 	transitionCounts map[string]map[string]prometheus.Counter
 	cycleErrorCounts map[string]map[string]prometheus.Counter
@@ -140,6 +147,21 @@ func newSchedulerMetrics(registerer prometheus.Registerer, cfg Config) *Schedule
 		Help:        "Scheduler job timeouts that threaten billing batch SLAs.",
 		ConstLabels: constLabels,
 	}, []string{"job"})
+	jobInterrupted := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "railzway_scheduler_job_interrupted_total",
+		Help:        "Scheduler jobs cancelled by shutdown before they finished.",
+		ConstLabels: constLabels,
+	}, []string{"job"})
+	jobLeader := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "railzway_scheduler_job_leader",
+		Help:        "1 while this scheduler node holds the leadership lock of a job.",
+		ConstLabels: constLabels,
+	}, []string{"job", "node"})
+	jobLeaderSkips := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "railzway_scheduler_job_leader_skips_total",
+		Help:        "Scheduler job runs skipped because another node held the job's lock.",
+		ConstLabels: constLabels,
+	}, []string{"job"})
 	jobErrorsV2 := prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name:        "railzway_scheduler_job_errors_total",
 		Help:        "Scheduler job errors by low-cardinality reason.",
@@ -161,6 +183,23 @@ func newSchedulerMetrics(registerer prometheus.Registerer, cfg Config) *Schedule
 		Buckets:     []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
 		ConstLabels: constLabels,
 	})
+	finalizePending := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "railzway_scheduler_invoices_awaiting_finalize",
+		Help:        "Draft invoices held in the scheduler finalize grace window.",
+		ConstLabels: constLabels,
+	})
+
+	// Shows how far behind cycle closing is per org so stalled orgs can alert.
+	openCycleAge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduler_oldest_open_cycle_age_seconds",
+		Help: "Age of the oldest open billing cycle whose period has ended, by org.",
+	}, []string{"org_id"})
+
+	// Flags orgs whose ledger receivables no longer match their invoices.
+	arDrift := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduler_ar_reconciliation_drift",
+		Help: "Ledger AR balance minus invoice outstanding, in minor units, for org currencies over the drift threshold.",
+	}, []string{"org_id", "currency"})
 
 	// Tracks job latency to keep billing batches within SLA windows.
 	jobDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -193,6 +232,11 @@ func newSchedulerMetrics(registerer prometheus.Registerer, cfg Config) *Schedule
 		Name: "billing_cycle_error_total",
 		Help: "Billing cycle errors by stage for faster incident isolation.",
 	}, []string{"stage", "error_type"})
+	// Counts manual overrides of the cycle lifecycle so they can be alerted on.
+	cycleForceClose := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "billing_cycle_force_close_total",
+		Help: "Billing cycles forced out of their lifecycle by an operator.",
+	}, []string{"from", "to"})
 	// Measures lock wait time to detect contention in billing schedulers.
 	dbLockWait := prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "scheduler_db_lock_wait_seconds",
@@ -204,16 +248,23 @@ func newSchedulerMetrics(registerer prometheus.Registerer, cfg Config) *Schedule
 		jobRuns,
 		jobDurationV2,
 		jobTimeoutsV2,
+		jobInterrupted,
+		jobLeader,
+		jobLeaderSkips,
 		jobErrorsV2,
 		batchProcessedV2,
 		batchDeferred,
 		runLoopLag,
+		finalizePending,
+		openCycleAge,
+		arDrift,
 		jobDuration,
 		jobTimeouts,
 		jobErrors,
 		batchProcessed,
 		cycleTransitions,
 		cycleErrors,
+		cycleForceClose,
 		dbLockWait,
 	)
 
@@ -272,16 +323,23 @@ func newSchedulerMetrics(registerer prometheus.Registerer, cfg Config) *Schedule
 		jobRuns:          jobRuns,
 		jobDurationV2:    jobDurationV2,
 		jobTimeoutsV2:    jobTimeoutsV2,
+		jobInterrupted:   jobInterrupted,
+		jobLeader:        jobLeader,
+		jobLeaderSkips:   jobLeaderSkips,
 		jobErrorsV2:      jobErrorsV2,
 		batchProcessedV2: batchProcessedV2,
 		batchDeferred:    batchDeferred,
 		runLoopLag:       runLoopLag,
+		finalizePending:  finalizePending,
+		openCycleAge:     openCycleAge,
+		arDrift:          arDrift,
 		jobDuration:      jobDuration,
 		jobTimeouts:      jobTimeouts,
 		jobErrors:        jobErrors,
 		batchProcessed:   batchProcessed,
 		cycleTransitions: cycleTransitions,
 		cycleErrors:      cycleErrors,
+		cycleForceClose:  cycleForceClose,
 		dbLockWait:       dbLockWait,
 		transitionCounts: transitionCounts,
 		cycleErrorCounts: cycleErrorCounts,
@@ -323,6 +381,35 @@ func (m *SchedulerMetrics) IncJobTimeout(job string) {
 	}
 }
 
+// IncJobInterrupted increments the counter of jobs cancelled by shutdown.
+func (m *SchedulerMetrics) IncJobInterrupted(job string) {
+	if m == nil || m.jobInterrupted == nil {
+		return
+	}
+	m.jobInterrupted.WithLabelValues(job).Inc()
+}
+
+// SetJobLeader records whether node holds the leadership lock of job. A
+// released lock drops the series instead of leaving it at zero.
+func (m *SchedulerMetrics) SetJobLeader(job, node string, held bool) {
+	if m == nil || m.jobLeader == nil {
+		return
+	}
+	if held {
+		m.jobLeader.WithLabelValues(job, node).Set(1)
+		return
+	}
+	m.jobLeader.DeleteLabelValues(job, node)
+}
+
+// IncJobLeaderSkip increments the counter of runs left to another node.
+func (m *SchedulerMetrics) IncJobLeaderSkip(job string) {
+	if m == nil || m.jobLeaderSkips == nil {
+		return
+	}
+	m.jobLeaderSkips.WithLabelValues(job).Inc()
+}
+
 // IncJobError increments the scheduler job error counter with classification.
 func (m *SchedulerMetrics) IncJobError(job string, err error) {
 	if m == nil || err == nil {
@@ -372,6 +459,53 @@ func (m *SchedulerMetrics) ObserveRunLoopLag(duration time.Duration) {
 	m.runLoopLag.Observe(lag.Seconds())
 }
 
+// SetInvoicesAwaitingFinalize records how many drafts are still inside the
+// finalize grace window.
+func (m *SchedulerMetrics) SetInvoicesAwaitingFinalize(count int) {
+	if m == nil || m.finalizePending == nil {
+		return
+	}
+	m.finalizePending.Set(float64(count))
+}
+
+// SetOldestOpenCycleAges replaces the per-org age of the oldest open cycle
+// whose period has ended. Orgs missing from ages have caught up and are
+// dropped from the gauge.
+func (m *SchedulerMetrics) SetOldestOpenCycleAges(ages map[string]time.Duration) {
+	if m == nil || m.openCycleAge == nil {
+		return
+	}
+	m.openCycleAge.Reset()
+	for orgID, age := range ages {
+		if age < 0 {
+			age = 0
+		}
+		m.openCycleAge.WithLabelValues(orgID).Set(age.Seconds())
+	}
+}
+
+// ARDrift is the AR reconciliation drift of one org currency.
+type ARDrift struct {
+	OrgID    string
+	Currency string
+	Amount   int64
+}
+
+// SetARReconciliationDrifts replaces the AR drift of the reconciled orgs.
+// Their currencies missing from drifts are back in balance and are dropped
+// from the gauge; orgs that failed to reconcile keep their last value.
+func (m *SchedulerMetrics) SetARReconciliationDrifts(reconciled []string, drifts []ARDrift) {
+	if m == nil || m.arDrift == nil {
+		return
+	}
+	for _, orgID := range reconciled {
+		m.arDrift.DeletePartialMatch(prometheus.Labels{"org_id": orgID})
+	}
+	for _, drift := range drifts {
+		m.arDrift.WithLabelValues(drift.OrgID, drift.Currency).Set(float64(drift.Amount))
+	}
+}
+
 // IncBillingCycleTransition increments billing cycle transition counters.
 func (m *SchedulerMetrics) IncBillingCycleTransition(from, to string) {
 	if m == nil {
@@ -401,6 +535,15 @@ func (m *SchedulerMetrics) IncBillingCycleError(stage string, err error) {
 	m.cycleErrors.WithLabelValues(stage, errorType).Inc()
 }
 
+// IncBillingCycleForceClose increments the counter of operator overrides
+// that moved a cycle from one status to another.
+func (m *SchedulerMetrics) IncBillingCycleForceClose(from, to string) {
+	if m == nil || m.cycleForceClose == nil {
+		return
+	}
+	m.cycleForceClose.WithLabelValues(from, to).Inc()
+}
+
 // ObserveDBLockWait records lock wait time for SELECT FOR UPDATE work.
 func (m *SchedulerMetrics) ObserveDBLockWait(resource string, duration time.Duration) {
 	if m == nil {
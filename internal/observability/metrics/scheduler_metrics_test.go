@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/prometheus/client_golang/prometheus"
@@ -73,3 +74,75 @@ func TestAddBatchProcessed(t *testing.T) {
 		t.Fatalf("expected processed count 3, got %v", got)
 	}
 }
+
+func TestSetOldestOpenCycleAges(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := newSchedulerMetrics(registry, Config{
+		ServiceName: "railzway",
+		Environment: "test",
+	})
+
+	metrics.SetOldestOpenCycleAges(map[string]time.Duration{
+		"1": 90 * time.Second,
+		"2": 2 * time.Hour,
+	})
+	if got := testutil.ToFloat64(metrics.openCycleAge.WithLabelValues("2")); got != 7200 {
+		t.Fatalf("expected org 2 age 7200, got %v", got)
+	}
+
+	metrics.SetOldestOpenCycleAges(map[string]time.Duration{"1": 3 * time.Minute})
+	if got := testutil.ToFloat64(metrics.openCycleAge.WithLabelValues("1")); got != 180 {
+		t.Fatalf("expected org 1 age 180, got %v", got)
+	}
+	if got := testutil.CollectAndCount(metrics.openCycleAge); got != 1 {
+		t.Fatalf("expected caught-up org to be dropped, got %d series", got)
+	}
+}
+
+func TestSetARReconciliationDrifts(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := newSchedulerMetrics(registry, Config{
+		ServiceName: "railzway",
+		Environment: "test",
+	})
+
+	metrics.SetARReconciliationDrifts([]string{"1", "2"}, []ARDrift{
+		{OrgID: "1", Currency: "USD", Amount: 1500},
+		{OrgID: "1", Currency: "EUR", Amount: 300},
+		{OrgID: "2", Currency: "IDR", Amount: -200},
+	})
+	if got := testutil.ToFloat64(metrics.arDrift.WithLabelValues("2", "IDR")); got != -200 {
+		t.Fatalf("expected org 2 IDR drift -200, got %v", got)
+	}
+
+	// Org 2 failed to reconcile this run, so its last drift stays.
+	metrics.SetARReconciliationDrifts([]string{"1"}, []ARDrift{{OrgID: "1", Currency: "USD", Amount: 900}})
+	if got := testutil.ToFloat64(metrics.arDrift.WithLabelValues("1", "USD")); got != 900 {
+		t.Fatalf("expected org 1 USD drift 900, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.arDrift.WithLabelValues("2", "IDR")); got != -200 {
+		t.Fatalf("expected failed org 2 to keep drift -200, got %v", got)
+	}
+	if got := testutil.CollectAndCount(metrics.arDrift); got != 2 {
+		t.Fatalf("expected reconciled org 1 EUR to be dropped, got %d series", got)
+	}
+}
+
+func TestSetJobLeader(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := newSchedulerMetrics(registry, Config{
+		ServiceName: "railzway",
+		Environment: "test",
+	})
+
+	metrics.SetJobLeader("close_cycles", "node-a", true)
+	metrics.SetJobLeader("rating", "node-a", true)
+	if got := testutil.ToFloat64(metrics.jobLeader.WithLabelValues("close_cycles", "node-a")); got != 1 {
+		t.Fatalf("expected node-a to lead close_cycles, got %v", got)
+	}
+
+	metrics.SetJobLeader("close_cycles", "node-a", false)
+	if got := testutil.CollectAndCount(metrics.jobLeader); got != 1 {
+		t.Fatalf("expected released lock to be dropped, got %d series", got)
+	}
+}
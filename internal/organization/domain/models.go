@@ -60,5 +60,84 @@ type OrganizationBillingPreferences struct {
 	UpdatedAt time.Time    `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
+// MaxListDefaultLimit caps the configurable default page size, matching the
+// largest limit the list endpoints accept.
+const MaxListDefaultLimit = 200
+
+// ListDefaultLimits overrides the default page size of billing operations
+// list endpoints for an organization. A nil value keeps the built-in default.
+type ListDefaultLimits struct {
+	Inbox      *int `json:"inbox,omitempty"`
+	MyWork     *int `json:"my_work,omitempty"`
+	Overdue    *int `json:"overdue,omitempty"`
+	Collection *int `json:"collection,omitempty"`
+}
+
+// CollectionQueueSettings narrows an organization's collection queue.
+// OverdueOnly hides balances that are not yet due; a positive MaxAgeDays
+// hides invoices due more than that many days ago.
+type CollectionQueueSettings struct {
+	OverdueOnly bool `json:"overdue_only"`
+	MaxAgeDays  int  `json:"max_age_days"`
+}
+
+// HolidayDateLayout is the format of OverdueCalendar holidays.
+const HolidayDateLayout = "2006-01-02"
+
+// OverdueCalendar controls how days overdue are counted for an organization.
+// With BusinessDaysOnly set, weekends and Holidays (YYYY-MM-DD, UTC) do not
+// count towards days overdue, risk scores or aging buckets.
+type OverdueCalendar struct {
+	BusinessDaysOnly bool     `json:"business_days_only"`
+	Holidays         []string `json:"holidays"`
+}
+
+// InvoiceNumberFormat controls how an organization's invoices are numbered.
+// Template may use {PREFIX}, {YYYY}, {YY}, {MM}, {DD}, {SEQ} and {SEQn}
+// (sequence zero-padded to n digits), e.g. "{PREFIX}-{YYYY}-{SEQ6}". An empty
+// Template restores the default format.
+type InvoiceNumberFormat struct {
+	Template string `json:"template"`
+	Prefix   string `json:"prefix"`
+}
+
+// MaxReceivableAccountCodes caps how many ledger accounts an organization
+// can split its accounts receivable across.
+const MaxReceivableAccountCodes = 20
+
 // TableName sets the database table name.
 func (OrganizationBillingPreferences) TableName() string { return "organization_billing_preferences" }
+
+// Working hours use HH:MM; WorkHoursEndOfDay closes the working day at
+// midnight.
+const (
+	WorkHoursLayout   = "15:04"
+	WorkHoursEndOfDay = "24:00"
+)
+
+// OrgCalendar is an organization's working calendar, shared by time-aware
+// features. WorkDays are time.Weekday numbers (0 is Sunday), WorkStart and
+// WorkEnd bound the working hours of those days, and Holidays (YYYY-MM-DD)
+// are dates in Timezone that are never worked.
+type OrgCalendar struct {
+	OrgID     snowflake.ID `json:"org_id"`
+	Timezone  string       `json:"timezone"`
+	WorkDays  []int        `json:"work_days"`
+	WorkStart string       `json:"work_start"`
+	WorkEnd   string       `json:"work_end"`
+	Holidays  []string     `json:"holidays"`
+	UpdatedAt *time.Time   `json:"updated_at,omitempty"`
+}
+
+// DefaultOrgCalendar is the calendar of an organization that has not
+// configured one: every day, all day, in UTC, with no holidays.
+func DefaultOrgCalendar(orgID snowflake.ID) OrgCalendar {
+	return OrgCalendar{
+		OrgID:     orgID,
+		Timezone:  "UTC",
+		WorkDays:  []int{0, 1, 2, 3, 4, 5, 6},
+		WorkStart: "00:00",
+		WorkEnd:   WorkHoursEndOfDay,
+		Holidays:  []string{},
+	}
+}
@@ -36,4 +36,23 @@ type Repository interface {
 	GetInvite(ctx context.Context, inviteID snowflake.ID) (*OrganizationInvite, error)
 	UpdateInvite(ctx context.Context, invite OrganizationInvite) error
 	UpsertBillingPreferences(ctx context.Context, prefs OrganizationBillingPreferences) error
+	UpdateListDefaultLimits(ctx context.Context, orgID snowflake.ID, limits ListDefaultLimits, updatedAt time.Time) error
+	UpdateOverdueCalendar(ctx context.Context, orgID snowflake.ID, calendar OverdueCalendar, updatedAt time.Time) error
+	UpdateInvoiceRemindersOptOut(ctx context.Context, orgID snowflake.ID, optOut bool, updatedAt time.Time) error
+	UpdateRequireHandoffNote(ctx context.Context, orgID snowflake.ID, required bool, updatedAt time.Time) error
+	UpdateReleaseOnResolve(ctx context.Context, orgID snowflake.ID, release bool, updatedAt time.Time) error
+	UpdateCollectionQueue(ctx context.Context, orgID snowflake.ID, settings CollectionQueueSettings, updatedAt time.Time) error
+	UpdateTeamViewsIncludeSystemActors(ctx context.Context, orgID snowflake.ID, include bool, updatedAt time.Time) error
+	UpdateAuditReads(ctx context.Context, orgID snowflake.ID, enabled bool, updatedAt time.Time) error
+	UpdateInvoiceNumberFormat(ctx context.Context, orgID snowflake.ID, format InvoiceNumberFormat, updatedAt time.Time) error
+	UpdateReceivableAccountCodes(ctx context.Context, orgID snowflake.ID, codes []string, updatedAt time.Time) error
+	// ListLedgerAccountCodes returns which of codes are ledger accounts of
+	// the organization.
+	ListLedgerAccountCodes(ctx context.Context, orgID snowflake.ID, codes []string) ([]string, error)
+	UpdateMinInvoiceAmount(ctx context.Context, orgID snowflake.ID, amount int64, updatedAt time.Time) error
+	// GetCalendar returns the stored calendar, or nil when the organization
+	// has not configured one.
+	GetCalendar(ctx context.Context, orgID snowflake.ID) (*OrgCalendar, error)
+	UpsertCalendar(ctx context.Context, calendar OrgCalendar, updatedAt time.Time) error
+	DeleteCalendar(ctx context.Context, orgID snowflake.ID) error
 }
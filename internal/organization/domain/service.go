@@ -27,6 +27,12 @@ type Service interface {
 	AcceptInvite(ctx context.Context, userID snowflake.ID, inviteID string) error
 	GetInvite(ctx context.Context, inviteID string) (*PublicInviteInfo, error)
 	SetBillingPreferences(ctx context.Context, userID snowflake.ID, orgID string, req BillingPreferencesRequest) error
+	// GetCalendar returns the organization's working calendar, or
+	// DefaultOrgCalendar when none is configured.
+	GetCalendar(ctx context.Context, orgID string) (*OrgCalendar, error)
+	SetCalendar(ctx context.Context, userID snowflake.ID, orgID string, req OrgCalendarRequest) (*OrgCalendar, error)
+	// DeleteCalendar restores the default calendar.
+	DeleteCalendar(ctx context.Context, userID snowflake.ID, orgID string) error
 }
 
 type PublicInviteInfo struct {
@@ -57,6 +63,64 @@ type InviteRequest struct {
 type BillingPreferencesRequest struct {
 	Currency string
 	Timezone string
+	// ListDefaults replaces the organization's default list limits when set;
+	// nil leaves the stored limits untouched.
+	ListDefaults *ListDefaultLimits
+	// OverdueCalendar replaces the organization's overdue calendar when set;
+	// nil leaves it untouched.
+	OverdueCalendar *OverdueCalendar
+	// InvoiceRemindersOptOut stops the scheduler from emailing due date
+	// reminders for the organization's invoices when true; nil leaves the
+	// stored setting untouched.
+	InvoiceRemindersOptOut *bool
+	// RequireHandoffNote makes releasing or reassigning billing operations
+	// work require a note when true; nil leaves the stored setting
+	// untouched.
+	RequireHandoffNote *bool
+	// ReleaseOnResolve makes resolving billing operations work hand the
+	// entity back to the inbox, to be claimed again while it still meets
+	// the risk criteria, when true; false makes resolving terminal and keeps
+	// resolved work out of the inbox. Until it is set, resolved entities are
+	// listed but not claimable by others. nil leaves the stored setting
+	// untouched.
+	ReleaseOnResolve *bool
+	// InvoiceNumberFormat replaces the organization's invoice number format
+	// when set; nil leaves it untouched.
+	InvoiceNumberFormat *InvoiceNumberFormat
+	// ReceivableAccountCodes replaces the ledger accounts that make up the
+	// organization's accounts receivable when set, e.g. for AR split into
+	// domestic and international sub-accounts. Every code must be a ledger
+	// account of the organization, and accounts_receivable is always
+	// included. An empty list restores the single accounts_receivable
+	// account; nil leaves the setting untouched.
+	ReceivableAccountCodes *[]string
+	// CollectionQueue replaces the organization's collection queue filters
+	// when set; nil leaves them untouched.
+	CollectionQueue *CollectionQueueSettings
+	// TeamViewsIncludeSystemActors shows system actors such as the SLA
+	// monitor as members of the organization's team workload and
+	// performance views when true; false hides them. nil leaves the stored
+	// setting untouched.
+	TeamViewsIncludeSystemActors *bool
+	// AuditReads records an audit entry whenever one of the organization's
+	// sensitive financial reports (exposure, AR health, customer balances)
+	// is viewed when true. nil leaves the stored setting untouched.
+	AuditReads *bool
+	// MinInvoiceAmount, in minor units, replaces the smallest subtotal a
+	// billing cycle is invoiced for when set; smaller amounts are carried to
+	// the subscription's next cycle. Zero invoices every cycle; nil leaves
+	// the setting untouched.
+	MinInvoiceAmount *int64
+}
+
+// OrgCalendarRequest replaces an organization's working calendar. Empty
+// fields take the DefaultOrgCalendar value.
+type OrgCalendarRequest struct {
+	Timezone  string   `json:"timezone"`
+	WorkDays  []int    `json:"work_days"`
+	WorkStart string   `json:"work_start"`
+	WorkEnd   string   `json:"work_end"`
+	Holidays  []string `json:"holidays"`
 }
 
 type OrganizationResponse struct {
@@ -83,5 +147,14 @@ var (
 	ErrInvalidOrganization = errors.New("invalid_organization")
 	ErrInvalidEmail        = errors.New("invalid_email")
 	ErrInvalidRole         = errors.New("invalid_role")
+	ErrInvalidDefaultLimit = errors.New("invalid_default_limit")
+	ErrInvalidHoliday      = errors.New("invalid_holiday")
+	ErrInvalidWorkDays     = errors.New("invalid_work_days")
+	ErrInvalidWorkHours    = errors.New("invalid_work_hours")
 	ErrForbidden           = errors.New("forbidden")
+
+	ErrInvalidInvoiceNumberFormat   = errors.New("invalid_invoice_number_format")
+	ErrInvalidReceivableAccountCode = errors.New("invalid_receivable_account_code")
+	ErrInvalidMinInvoiceAmount      = errors.New("invalid_min_invoice_amount")
+	ErrInvalidCollectionQueue       = errors.New("invalid_collection_queue")
 )
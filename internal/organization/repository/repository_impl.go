@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/smallbiznis/railzway/internal/organization/domain"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -106,6 +109,264 @@ func (r *repository) UpsertBillingPreferences(ctx context.Context, prefs domain.
 	).Error
 }
 
+func (r *repository) UpdateListDefaultLimits(ctx context.Context, orgID snowflake.ID, limits domain.ListDefaultLimits, updatedAt time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET inbox_default_limit = ?,
+		     my_work_default_limit = ?,
+		     overdue_default_limit = ?,
+		     collection_default_limit = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		limits.Inbox,
+		limits.MyWork,
+		limits.Overdue,
+		limits.Collection,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateOverdueCalendar(ctx context.Context, orgID snowflake.ID, calendar domain.OverdueCalendar, updatedAt time.Time) error {
+	holidays := calendar.Holidays
+	if holidays == nil {
+		holidays = []string{}
+	}
+	encoded, err := json.Marshal(holidays)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET overdue_business_days = ?,
+		     overdue_holidays = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		calendar.BusinessDaysOnly,
+		datatypes.JSON(encoded),
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateInvoiceRemindersOptOut(ctx context.Context, orgID snowflake.ID, optOut bool, updatedAt time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET invoice_reminders_opt_out = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		optOut,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateRequireHandoffNote(ctx context.Context, orgID snowflake.ID, required bool, updatedAt time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET require_handoff_note = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		required,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateReleaseOnResolve(ctx context.Context, orgID snowflake.ID, release bool, updatedAt time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET release_on_resolve = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		release,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateCollectionQueue(ctx context.Context, orgID snowflake.ID, settings domain.CollectionQueueSettings, updatedAt time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET collection_queue_overdue_only = ?,
+		     collection_queue_max_age_days = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		settings.OverdueOnly,
+		settings.MaxAgeDays,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateTeamViewsIncludeSystemActors(ctx context.Context, orgID snowflake.ID, include bool, updatedAt time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET team_views_include_system_actors = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		include,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateAuditReads(ctx context.Context, orgID snowflake.ID, enabled bool, updatedAt time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET audit_reads = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		enabled,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateInvoiceNumberFormat(ctx context.Context, orgID snowflake.ID, format domain.InvoiceNumberFormat, updatedAt time.Time) error {
+	var template, prefix *string
+	if format.Template != "" {
+		template = &format.Template
+		prefix = &format.Prefix
+	}
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET invoice_number_format = ?,
+		     invoice_number_prefix = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		template,
+		prefix,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateMinInvoiceAmount(ctx context.Context, orgID snowflake.ID, amount int64, updatedAt time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET min_invoice_amount = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		amount,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) UpdateReceivableAccountCodes(ctx context.Context, orgID snowflake.ID, codes []string, updatedAt time.Time) error {
+	var value any
+	if len(codes) > 0 {
+		encoded, err := json.Marshal(codes)
+		if err != nil {
+			return err
+		}
+		value = datatypes.JSON(encoded)
+	}
+	return r.db.WithContext(ctx).Exec(
+		`UPDATE organization_billing_preferences
+		 SET receivable_account_codes = ?,
+		     updated_at = ?
+		 WHERE org_id = ?`,
+		value,
+		updatedAt,
+		orgID,
+	).Error
+}
+
+func (r *repository) GetCalendar(ctx context.Context, orgID snowflake.ID) (*domain.OrgCalendar, error) {
+	var row struct {
+		OrgID     snowflake.ID   `gorm:"column:org_id"`
+		Timezone  string         `gorm:"column:timezone"`
+		WorkDays  datatypes.JSON `gorm:"column:work_days"`
+		WorkStart string         `gorm:"column:work_start"`
+		WorkEnd   string         `gorm:"column:work_end"`
+		Holidays  datatypes.JSON `gorm:"column:holidays"`
+		UpdatedAt time.Time      `gorm:"column:updated_at"`
+	}
+	res := r.db.WithContext(ctx).Raw(
+		`SELECT org_id, timezone, work_days, work_start, work_end, holidays, updated_at
+		 FROM org_calendars
+		 WHERE org_id = ?
+		 LIMIT 1`,
+		orgID,
+	).Scan(&row)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	calendar := domain.OrgCalendar{
+		OrgID:     row.OrgID,
+		Timezone:  row.Timezone,
+		WorkStart: row.WorkStart,
+		WorkEnd:   row.WorkEnd,
+		UpdatedAt: &row.UpdatedAt,
+	}
+	if err := json.Unmarshal(row.WorkDays, &calendar.WorkDays); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(row.Holidays, &calendar.Holidays); err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+func (r *repository) UpsertCalendar(ctx context.Context, calendar domain.OrgCalendar, updatedAt time.Time) error {
+	workDays, err := json.Marshal(calendar.WorkDays)
+	if err != nil {
+		return err
+	}
+	holidays := calendar.Holidays
+	if holidays == nil {
+		holidays = []string{}
+	}
+	encodedHolidays, err := json.Marshal(holidays)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Exec(
+		`INSERT INTO org_calendars (org_id, timezone, work_days, work_start, work_end, holidays, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (org_id)
+		 DO UPDATE SET timezone = EXCLUDED.timezone,
+		               work_days = EXCLUDED.work_days,
+		               work_start = EXCLUDED.work_start,
+		               work_end = EXCLUDED.work_end,
+		               holidays = EXCLUDED.holidays,
+		               updated_at = EXCLUDED.updated_at`,
+		calendar.OrgID,
+		calendar.Timezone,
+		datatypes.JSON(workDays),
+		calendar.WorkStart,
+		calendar.WorkEnd,
+		datatypes.JSON(encodedHolidays),
+		updatedAt,
+		updatedAt,
+	).Error
+}
+
+func (r *repository) DeleteCalendar(ctx context.Context, orgID snowflake.ID) error {
+	return r.db.WithContext(ctx).Exec(`DELETE FROM org_calendars WHERE org_id = ?`, orgID).Error
+}
+
+func (r *repository) ListLedgerAccountCodes(ctx context.Context, orgID snowflake.ID, codes []string) ([]string, error) {
+	var found []string
+	if len(codes) == 0 {
+		return found, nil
+	}
+	if err := r.db.WithContext(ctx).Raw(
+		`SELECT code FROM ledger_accounts WHERE org_id = ? AND code IN ?`,
+		orgID,
+		codes,
+	).Scan(&found).Error; err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
 func (r *repository) GetInvite(ctx context.Context, inviteID snowflake.ID) (*domain.OrganizationInvite, error) {
 	var invite domain.OrganizationInvite
 	err := r.db.WithContext(ctx).First(&invite, "id = ?", inviteID).Error
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/organization/domain"
+	"github.com/smallbiznis/railzway/internal/organization/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestOrgCalendar(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&domain.Organization{}, &domain.OrganizationMember{}))
+	require.NoError(t, db.Exec(`CREATE TABLE org_calendars (
+		org_id INTEGER PRIMARY KEY,
+		timezone TEXT NOT NULL DEFAULT 'UTC',
+		work_days TEXT NOT NULL,
+		work_start TEXT NOT NULL,
+		work_end TEXT NOT NULL,
+		holidays TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`).Error)
+	require.NoError(t, db.Create(&domain.Organization{ID: 1, Name: "Acme", Slug: "acme", Metadata: map[string]any{}}).Error)
+	require.NoError(t, db.Create(&domain.OrganizationMember{ID: 10, OrgID: 1, UserID: 7, Role: domain.RoleAdmin}).Error)
+
+	ctx := context.Background()
+	svc := NewService(db, repository.NewRepository(db), nil, nil, nil, nil)
+	const admin, outsider = snowflake.ID(7), snowflake.ID(8)
+
+	t.Run("defaults to every day in UTC", func(t *testing.T) {
+		calendar, err := svc.GetCalendar(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.DefaultOrgCalendar(1), *calendar)
+	})
+
+	t.Run("stores and returns the calendar", func(t *testing.T) {
+		stored, err := svc.SetCalendar(ctx, admin, "1", domain.OrgCalendarRequest{
+			Timezone:  "Asia/Jakarta",
+			WorkDays:  []int{5, 1, 2, 3, 4, 1},
+			WorkStart: "09:00",
+			WorkEnd:   "17:30",
+			Holidays:  []string{"2025-12-25", "2025-08-17"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, stored.WorkDays)
+
+		calendar, err := svc.GetCalendar(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, "Asia/Jakarta", calendar.Timezone)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, calendar.WorkDays)
+		assert.Equal(t, "09:00", calendar.WorkStart)
+		assert.Equal(t, "17:30", calendar.WorkEnd)
+		assert.Equal(t, []string{"2025-08-17", "2025-12-25"}, calendar.Holidays)
+		assert.NotNil(t, calendar.UpdatedAt)
+	})
+
+	t.Run("rejects invalid calendars", func(t *testing.T) {
+		cases := []struct {
+			name string
+			req  domain.OrgCalendarRequest
+			want error
+		}{
+			{name: "unknown timezone", req: domain.OrgCalendarRequest{Timezone: "Mars/Olympus"}, want: domain.ErrInvalidTimezone},
+			{name: "weekday out of range", req: domain.OrgCalendarRequest{WorkDays: []int{1, 7}}, want: domain.ErrInvalidWorkDays},
+			{name: "start after end", req: domain.OrgCalendarRequest{WorkStart: "18:00", WorkEnd: "09:00"}, want: domain.ErrInvalidWorkHours},
+			{name: "malformed hours", req: domain.OrgCalendarRequest{WorkStart: "9am"}, want: domain.ErrInvalidWorkHours},
+			{name: "malformed holiday", req: domain.OrgCalendarRequest{Holidays: []string{"25/12/2025"}}, want: domain.ErrInvalidHoliday},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := svc.SetCalendar(ctx, admin, "1", tc.req)
+				assert.ErrorIs(t, err, tc.want)
+			})
+		}
+	})
+
+	t.Run("non-members cannot change the calendar", func(t *testing.T) {
+		_, err := svc.SetCalendar(ctx, outsider, "1", domain.OrgCalendarRequest{})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.ErrorIs(t, svc.DeleteCalendar(ctx, outsider, "1"), domain.ErrForbidden)
+	})
+
+	t.Run("delete restores the default", func(t *testing.T) {
+		require.NoError(t, svc.DeleteCalendar(ctx, admin, "1"))
+		calendar, err := svc.GetCalendar(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.DefaultOrgCalendar(1), *calendar)
+	})
+}
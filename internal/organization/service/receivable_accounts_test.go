@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/organization/domain"
+	"github.com/smallbiznis/railzway/internal/organization/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestReceivableAccountCodes(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.Exec(`CREATE TABLE ledger_accounts (id INTEGER PRIMARY KEY, org_id INTEGER, code TEXT)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO ledger_accounts (id, org_id, code) VALUES
+		(1, 1, 'accounts_receivable'), (2, 1, 'ar_domestic'), (3, 2, 'ar_international')`).Error)
+
+	svc := NewService(db, repository.NewRepository(db), nil, nil, nil, nil).(*service)
+	ctx := context.Background()
+
+	t.Run("always includes accounts_receivable", func(t *testing.T) {
+		codes, err := normalizeReceivableAccountCodes([]string{" ar_domestic ", "ar_domestic"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"accounts_receivable", "ar_domestic"}, codes)
+
+		codes, err = normalizeReceivableAccountCodes([]string{"ar_domestic", "accounts_receivable"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"accounts_receivable", "ar_domestic"}, codes)
+	})
+
+	t.Run("empty resets to the default", func(t *testing.T) {
+		codes, err := normalizeReceivableAccountCodes(nil)
+		require.NoError(t, err)
+		assert.Empty(t, codes)
+	})
+
+	t.Run("accepts the org's ledger accounts", func(t *testing.T) {
+		assert.NoError(t, svc.checkLedgerAccounts(ctx, 1, []string{"accounts_receivable", "ar_domestic"}))
+	})
+
+	t.Run("rejects unknown and other orgs' accounts", func(t *testing.T) {
+		err := svc.checkLedgerAccounts(ctx, 1, []string{"accounts_receivable", "ar_typo"})
+		assert.ErrorIs(t, err, domain.ErrInvalidReceivableAccountCode)
+
+		err = svc.checkLedgerAccounts(ctx, 1, []string{"accounts_receivable", "ar_international"})
+		assert.ErrorIs(t, err, domain.ErrInvalidReceivableAccountCode)
+	})
+}
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"net/mail"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/gosimple/slug"
+	invoiceformat "github.com/smallbiznis/railzway/internal/invoice/format"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
 	"github.com/smallbiznis/railzway/internal/organization/domain"
 	"github.com/smallbiznis/railzway/internal/organization/event"
 	"github.com/smallbiznis/railzway/internal/providers/email"
@@ -459,16 +462,355 @@ func (s *service) SetBillingPreferences(ctx context.Context, userID snowflake.ID
 		return domain.ErrInvalidTimezone
 	}
 
+	if req.ListDefaults != nil {
+		if err := validateListDefaultLimits(*req.ListDefaults); err != nil {
+			return err
+		}
+	}
+	var overdueCalendar *domain.OverdueCalendar
+	if req.OverdueCalendar != nil {
+		calendar, err := normalizeOverdueCalendar(*req.OverdueCalendar)
+		if err != nil {
+			return err
+		}
+		overdueCalendar = &calendar
+	}
+	var invoiceNumberFormat *domain.InvoiceNumberFormat
+	if req.InvoiceNumberFormat != nil {
+		format, err := normalizeInvoiceNumberFormat(*req.InvoiceNumberFormat)
+		if err != nil {
+			return err
+		}
+		invoiceNumberFormat = &format
+	}
+	var receivableAccountCodes []string
+	if req.ReceivableAccountCodes != nil {
+		codes, err := normalizeReceivableAccountCodes(*req.ReceivableAccountCodes)
+		if err != nil {
+			return err
+		}
+		if err := s.checkLedgerAccounts(ctx, org.ID, codes); err != nil {
+			return err
+		}
+		receivableAccountCodes = codes
+	}
+	if req.MinInvoiceAmount != nil && *req.MinInvoiceAmount < 0 {
+		return domain.ErrInvalidMinInvoiceAmount
+	}
+	if req.CollectionQueue != nil && req.CollectionQueue.MaxAgeDays < 0 {
+		return domain.ErrInvalidCollectionQueue
+	}
+
 	now := time.Now().UTC()
-	return s.repo.UpsertBillingPreferences(ctx, domain.OrganizationBillingPreferences{
+	prefs := domain.OrganizationBillingPreferences{
 		OrgID:     org.ID,
 		Currency:  currency,
 		Timezone:  timezone,
 		CreatedAt: now,
 		UpdatedAt: now,
+	}
+	if req.ListDefaults == nil && overdueCalendar == nil && req.InvoiceRemindersOptOut == nil && req.RequireHandoffNote == nil && req.ReleaseOnResolve == nil && req.CollectionQueue == nil && req.TeamViewsIncludeSystemActors == nil && req.AuditReads == nil && invoiceNumberFormat == nil && req.ReceivableAccountCodes == nil && req.MinInvoiceAmount == nil {
+		return s.repo.UpsertBillingPreferences(ctx, prefs)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.UpsertBillingPreferences(ctx, prefs); err != nil {
+			return err
+		}
+		if req.ListDefaults != nil {
+			if err := repo.UpdateListDefaultLimits(ctx, org.ID, *req.ListDefaults, now); err != nil {
+				return err
+			}
+		}
+		if overdueCalendar != nil {
+			if err := repo.UpdateOverdueCalendar(ctx, org.ID, *overdueCalendar, now); err != nil {
+				return err
+			}
+		}
+		if req.InvoiceRemindersOptOut != nil {
+			if err := repo.UpdateInvoiceRemindersOptOut(ctx, org.ID, *req.InvoiceRemindersOptOut, now); err != nil {
+				return err
+			}
+		}
+		if req.RequireHandoffNote != nil {
+			if err := repo.UpdateRequireHandoffNote(ctx, org.ID, *req.RequireHandoffNote, now); err != nil {
+				return err
+			}
+		}
+		if req.ReleaseOnResolve != nil {
+			if err := repo.UpdateReleaseOnResolve(ctx, org.ID, *req.ReleaseOnResolve, now); err != nil {
+				return err
+			}
+		}
+		if req.CollectionQueue != nil {
+			if err := repo.UpdateCollectionQueue(ctx, org.ID, *req.CollectionQueue, now); err != nil {
+				return err
+			}
+		}
+		if req.TeamViewsIncludeSystemActors != nil {
+			if err := repo.UpdateTeamViewsIncludeSystemActors(ctx, org.ID, *req.TeamViewsIncludeSystemActors, now); err != nil {
+				return err
+			}
+		}
+		if req.AuditReads != nil {
+			if err := repo.UpdateAuditReads(ctx, org.ID, *req.AuditReads, now); err != nil {
+				return err
+			}
+		}
+		if invoiceNumberFormat != nil {
+			if err := repo.UpdateInvoiceNumberFormat(ctx, org.ID, *invoiceNumberFormat, now); err != nil {
+				return err
+			}
+		}
+		if req.ReceivableAccountCodes != nil {
+			if err := repo.UpdateReceivableAccountCodes(ctx, org.ID, receivableAccountCodes, now); err != nil {
+				return err
+			}
+		}
+		if req.MinInvoiceAmount != nil {
+			return repo.UpdateMinInvoiceAmount(ctx, org.ID, *req.MinInvoiceAmount, now)
+		}
+		return nil
 	})
 }
 
+func (s *service) GetCalendar(ctx context.Context, orgID string) (*domain.OrgCalendar, error) {
+	parsedOrgID, err := snowflake.ParseString(strings.TrimSpace(orgID))
+	if err != nil || parsedOrgID == 0 {
+		return nil, domain.ErrInvalidOrganization
+	}
+
+	calendar, err := s.repo.GetCalendar(ctx, parsedOrgID)
+	if err != nil {
+		return nil, err
+	}
+	if calendar == nil {
+		fallback := domain.DefaultOrgCalendar(parsedOrgID)
+		return &fallback, nil
+	}
+	return calendar, nil
+}
+
+func (s *service) SetCalendar(ctx context.Context, userID snowflake.ID, orgID string, req domain.OrgCalendarRequest) (*domain.OrgCalendar, error) {
+	org, err := s.memberOrganization(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := normalizeOrgCalendar(org.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if err := s.repo.UpsertCalendar(ctx, calendar, now); err != nil {
+		return nil, err
+	}
+	calendar.UpdatedAt = &now
+	return &calendar, nil
+}
+
+func (s *service) DeleteCalendar(ctx context.Context, userID snowflake.ID, orgID string) error {
+	org, err := s.memberOrganization(ctx, userID, orgID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteCalendar(ctx, org.ID)
+}
+
+// memberOrganization loads orgID and checks that userID belongs to it.
+func (s *service) memberOrganization(ctx context.Context, userID snowflake.ID, orgID string) (*domain.Organization, error) {
+	if userID == 0 {
+		return nil, domain.ErrInvalidUser
+	}
+	parsedOrgID, err := snowflake.ParseString(strings.TrimSpace(orgID))
+	if err != nil || parsedOrgID == 0 {
+		return nil, domain.ErrInvalidOrganization
+	}
+
+	org, err := s.getOrganization(ctx, parsedOrgID)
+	if err != nil {
+		return nil, err
+	}
+	isMember, err := s.repo.IsMember(ctx, parsedOrgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, domain.ErrForbidden
+	}
+	return org, nil
+}
+
+// normalizeOrgCalendar validates a calendar request, filling empty fields
+// from the default calendar. Work days and holidays come back sorted and
+// de-duplicated.
+func normalizeOrgCalendar(orgID snowflake.ID, req domain.OrgCalendarRequest) (domain.OrgCalendar, error) {
+	calendar := domain.DefaultOrgCalendar(orgID)
+
+	if timezone := strings.TrimSpace(req.Timezone); timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return domain.OrgCalendar{}, domain.ErrInvalidTimezone
+		}
+		calendar.Timezone = timezone
+	}
+
+	if req.WorkDays != nil {
+		seen := make(map[int]struct{}, len(req.WorkDays))
+		workDays := make([]int, 0, len(req.WorkDays))
+		for _, day := range req.WorkDays {
+			if day < int(time.Sunday) || day > int(time.Saturday) {
+				return domain.OrgCalendar{}, domain.ErrInvalidWorkDays
+			}
+			if _, ok := seen[day]; ok {
+				continue
+			}
+			seen[day] = struct{}{}
+			workDays = append(workDays, day)
+		}
+		if len(workDays) == 0 {
+			return domain.OrgCalendar{}, domain.ErrInvalidWorkDays
+		}
+		sort.Ints(workDays)
+		calendar.WorkDays = workDays
+	}
+
+	if start := strings.TrimSpace(req.WorkStart); start != "" {
+		calendar.WorkStart = start
+	}
+	if end := strings.TrimSpace(req.WorkEnd); end != "" {
+		calendar.WorkEnd = end
+	}
+	startMinute, ok := workHoursMinute(calendar.WorkStart)
+	if !ok || calendar.WorkStart == domain.WorkHoursEndOfDay {
+		return domain.OrgCalendar{}, domain.ErrInvalidWorkHours
+	}
+	endMinute, ok := workHoursMinute(calendar.WorkEnd)
+	if !ok || endMinute <= startMinute {
+		return domain.OrgCalendar{}, domain.ErrInvalidWorkHours
+	}
+
+	holidays, err := normalizeOverdueCalendar(domain.OverdueCalendar{Holidays: req.Holidays})
+	if err != nil {
+		return domain.OrgCalendar{}, err
+	}
+	calendar.Holidays = holidays.Holidays
+	return calendar, nil
+}
+
+// workHoursMinute parses an HH:MM working hour into minutes since midnight,
+// accepting WorkHoursEndOfDay as the end of the day.
+func workHoursMinute(value string) (int, bool) {
+	if value == domain.WorkHoursEndOfDay {
+		return 24 * 60, true
+	}
+	parsed, err := time.Parse(domain.WorkHoursLayout, value)
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+// normalizeReceivableAccountCodes trims and de-duplicates ledger account
+// codes, keeping their order. An empty list resets to the default account.
+// Payments and credit notes are always posted to accounts_receivable, so a
+// non-empty list starts with it whether or not the caller listed it.
+func normalizeReceivableAccountCodes(codes []string) ([]string, error) {
+	if len(codes) > domain.MaxReceivableAccountCodes {
+		return nil, domain.ErrInvalidReceivableAccountCode
+	}
+	if len(codes) == 0 {
+		return []string{}, nil
+	}
+	defaultCode := string(ledgerdomain.AccountCodeAccountsReceivable)
+	out := []string{defaultCode}
+	seen := map[string]struct{}{defaultCode: {}}
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" || len(code) > 64 {
+			return nil, domain.ErrInvalidReceivableAccountCode
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+		out = append(out, code)
+	}
+	return out, nil
+}
+
+// checkLedgerAccounts rejects receivable account codes that are not ledger
+// accounts of the organization. accounts_receivable is the default and is
+// always accepted.
+func (s *service) checkLedgerAccounts(ctx context.Context, orgID snowflake.ID, codes []string) error {
+	lookup := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if code != string(ledgerdomain.AccountCodeAccountsReceivable) {
+			lookup = append(lookup, code)
+		}
+	}
+	if len(lookup) == 0 {
+		return nil
+	}
+	found, err := s.repo.ListLedgerAccountCodes(ctx, orgID, lookup)
+	if err != nil {
+		return err
+	}
+	if len(found) != len(lookup) {
+		return domain.ErrInvalidReceivableAccountCode
+	}
+	return nil
+}
+
+// normalizeInvoiceNumberFormat trims the template and prefix and checks that
+// the template yields unique numbers. An empty template resets the format,
+// dropping any prefix with it.
+func normalizeInvoiceNumberFormat(format domain.InvoiceNumberFormat) (domain.InvoiceNumberFormat, error) {
+	template := strings.TrimSpace(format.Template)
+	if template == "" {
+		return domain.InvoiceNumberFormat{}, nil
+	}
+	prefix := strings.TrimSpace(format.Prefix)
+	if err := invoiceformat.ValidateInvoiceNumberFormat(template, prefix); err != nil {
+		return domain.InvoiceNumberFormat{}, domain.ErrInvalidInvoiceNumberFormat
+	}
+	return domain.InvoiceNumberFormat{Template: template, Prefix: prefix}, nil
+}
+
+// normalizeOverdueCalendar validates holiday dates and returns them sorted
+// and de-duplicated.
+func normalizeOverdueCalendar(calendar domain.OverdueCalendar) (domain.OverdueCalendar, error) {
+	holidays := make([]string, 0, len(calendar.Holidays))
+	seen := make(map[string]struct{}, len(calendar.Holidays))
+	for _, raw := range calendar.Holidays {
+		day, err := time.Parse(domain.HolidayDateLayout, strings.TrimSpace(raw))
+		if err != nil {
+			return domain.OverdueCalendar{}, domain.ErrInvalidHoliday
+		}
+		key := day.Format(domain.HolidayDateLayout)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		holidays = append(holidays, key)
+	}
+	sort.Strings(holidays)
+	return domain.OverdueCalendar{
+		BusinessDaysOnly: calendar.BusinessDaysOnly,
+		Holidays:         holidays,
+	}, nil
+}
+
+func validateListDefaultLimits(limits domain.ListDefaultLimits) error {
+	for _, limit := range []*int{limits.Inbox, limits.MyWork, limits.Overdue, limits.Collection} {
+		if limit != nil && (*limit <= 0 || *limit > domain.MaxListDefaultLimit) {
+			return domain.ErrInvalidDefaultLimit
+		}
+	}
+	return nil
+}
+
 func (s *service) countryExists(ctx context.Context, code string) (bool, error) {
 	countries, err := s.ref.ListCountries(ctx)
 	if err != nil {
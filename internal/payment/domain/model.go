@@ -42,3 +42,83 @@ type PaymentEvent struct {
 	RawPayload          []byte
 	InvoiceID           *snowflake.ID
 }
+
+// PaymentAllocation ties a payment event that carries no invoice reference
+// to the invoice an agent matched it to. Settled amounts read it alongside
+// the provider's invoice metadata.
+type PaymentAllocation struct {
+	ID             snowflake.ID `json:"id" gorm:"primaryKey"`
+	OrgID          snowflake.ID `json:"org_id" gorm:"not null;index"`
+	PaymentEventID snowflake.ID `json:"payment_event_id" gorm:"not null;uniqueIndex"`
+	InvoiceID      snowflake.ID `json:"invoice_id" gorm:"not null"`
+	Reference      string       `json:"reference" gorm:"type:text;not null"`
+	AllocatedBy    string       `json:"allocated_by" gorm:"type:text;not null"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"not null"`
+}
+
+func (PaymentAllocation) TableName() string { return "payment_allocations" }
+
+// PaymentMatchCandidate is an open invoice whose number appears in a
+// payment's transfer reference.
+type PaymentMatchCandidate struct {
+	InvoiceID     string     `json:"invoice_id"`
+	InvoiceNumber string     `json:"invoice_number"`
+	CustomerID    string     `json:"customer_id"`
+	CustomerName  string     `json:"customer_name"`
+	Currency      string     `json:"currency"`
+	AmountDue     int64      `json:"amount_due"`
+	DueAt         *time.Time `json:"due_at,omitempty"`
+	AmountMatches bool       `json:"amount_matches"`
+}
+
+type ConfirmPaymentMatchRequest struct {
+	PaymentEventID string `json:"payment_event_id"`
+	InvoiceID      string `json:"invoice_id"`
+	Reference      string `json:"reference"`
+	AllocatedBy    string `json:"-"`
+	// IdempotencyKey makes a retried confirmation return the allocation the
+	// first call made.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// ProviderManual is the provider of payment events an agent records by hand.
+const ProviderManual = "manual"
+
+// Methods a manually recorded payment was taken by.
+const (
+	ManualPaymentMethodCard         = "card"
+	ManualPaymentMethodBankTransfer = "bank_transfer"
+	ManualPaymentMethodCash         = "cash"
+	ManualPaymentMethodCheck        = "check"
+	ManualPaymentMethodOther        = "other"
+)
+
+type RecordManualPaymentRequest struct {
+	InvoiceID  string `json:"-"`
+	Amount     int64  `json:"amount"`
+	Method     string `json:"method"`
+	Reference  string `json:"reference"`
+	RecordedBy string `json:"-"`
+	// IdempotencyKey makes a retried request return the payment the first
+	// call recorded.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// ManualPayment is a payment an agent recorded against an invoice. It is
+// stored as a payment_succeeded event of the manual provider, so settled
+// amounts pick it up like any provider payment.
+type ManualPayment struct {
+	PaymentEventID snowflake.ID `json:"payment_event_id"`
+	InvoiceID      snowflake.ID `json:"invoice_id"`
+	CustomerID     snowflake.ID `json:"customer_id"`
+	Amount         int64        `json:"amount"`
+	Currency       string       `json:"currency"`
+	Method         string       `json:"method"`
+	Reference      string       `json:"reference"`
+	RecordedBy     string       `json:"recorded_by"`
+	// AmountDue is what remains open on the invoice after the payment.
+	AmountDue int64 `json:"amount_due"`
+	// Credit is the part of an overpayment left on the customer's account.
+	Credit     int64     `json:"credit"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
@@ -9,7 +9,9 @@ import (
 )
 
 type Repository interface {
-	FindEvent(ctx context.Context, db *gorm.DB, provider string, providerEventID string) (*EventRecord, error)
+	FindEvent(ctx context.Context, db *gorm.DB, orgID snowflake.ID, provider string, providerEventID string) (*EventRecord, error)
+	// InsertEvent stores the event unless the org already has one with the
+	// same provider event ID, and reports whether it was new.
 	InsertEvent(ctx context.Context, db *gorm.DB, event *EventRecord) (bool, error)
 	MarkProcessed(ctx context.Context, db *gorm.DB, id snowflake.ID, processedAt time.Time) error
 }
@@ -10,6 +10,20 @@ type Service interface {
 	IngestWebhook(ctx context.Context, provider string, payload []byte, headers http.Header) error
 }
 
+// ReconciliationService matches payments that arrive without an invoice
+// reference, such as bank transfers, to the invoices they settle.
+type ReconciliationService interface {
+	// MatchPaymentByReference returns the org's open invoices whose number
+	// appears in the transfer reference, best match first.
+	MatchPaymentByReference(ctx context.Context, reference string, amount int64) ([]PaymentMatchCandidate, error)
+	// ConfirmPaymentMatch allocates a payment event to an invoice. Without an
+	// invoice ID the reference must match exactly one open invoice.
+	ConfirmPaymentMatch(ctx context.Context, req ConfirmPaymentMatchRequest) (*PaymentAllocation, error)
+	// RecordManualPayment records a payment taken outside any provider, such
+	// as over the phone, against an open invoice.
+	RecordManualPayment(ctx context.Context, req RecordManualPaymentRequest) (*ManualPayment, error)
+}
+
 var (
 	ErrInvalidProvider       = errors.New("invalid_provider")
 	ErrProviderNotFound      = errors.New("provider_not_found")
@@ -22,4 +36,15 @@ var (
 	ErrInvalidCurrency       = errors.New("invalid_currency")
 	ErrInvalidConfig         = errors.New("invalid_config")
 	ErrEventAlreadyProcessed = errors.New("event_already_processed")
+	ErrInvalidOrganization   = errors.New("invalid_organization")
+	ErrInvalidReference      = errors.New("invalid_payment_reference")
+	ErrPaymentEventNotFound  = errors.New("payment_event_not_found")
+	ErrPaymentAlreadyMatched = errors.New("payment_already_matched")
+	ErrPaymentMatchNotFound  = errors.New("payment_match_not_found")
+	ErrAmbiguousPaymentMatch = errors.New("ambiguous_payment_match")
+	ErrPaymentMatchMismatch  = errors.New("payment_match_mismatch")
+	ErrInvalidPaymentMethod  = errors.New("invalid_payment_method")
+	ErrInvoiceNotFound       = errors.New("invoice_not_found")
+	ErrInvoiceNotPayable     = errors.New("invoice_not_payable")
+	ErrPaymentExceedsDue     = errors.New("payment_exceeds_amount_due")
 )
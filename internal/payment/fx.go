@@ -7,6 +7,7 @@ import (
 	"github.com/smallbiznis/railzway/internal/payment/adapters/stripe"
 	disputerepo "github.com/smallbiznis/railzway/internal/payment/dispute/repository"
 	disputeservice "github.com/smallbiznis/railzway/internal/payment/dispute/service"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
 	"github.com/smallbiznis/railzway/internal/payment/repository"
 	paymentservice "github.com/smallbiznis/railzway/internal/payment/service"
 	"github.com/smallbiznis/railzway/internal/payment/webhook"
@@ -24,6 +25,7 @@ var Module = fx.Module("payment.service",
 		)
 	}),
 	fx.Provide(paymentservice.NewService),
+	fx.Provide(func(svc *paymentservice.Service) paymentdomain.ReconciliationService { return svc }),
 	fx.Provide(disputeservice.NewService),
 	fx.Provide(webhook.NewService),
 )
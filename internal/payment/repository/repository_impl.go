@@ -15,14 +15,15 @@ func Provide() domain.Repository {
 	return &repo{}
 }
 
-func (r *repo) FindEvent(ctx context.Context, db *gorm.DB, provider string, providerEventID string) (*domain.EventRecord, error) {
+func (r *repo) FindEvent(ctx context.Context, db *gorm.DB, orgID snowflake.ID, provider string, providerEventID string) (*domain.EventRecord, error) {
 	var item domain.EventRecord
 	err := db.WithContext(ctx).Raw(
 		`SELECT id, org_id, provider, provider_event_id, event_type, customer_id,
 			payload, received_at, processed_at
 		 FROM payment_events
-		 WHERE provider = ? AND provider_event_id = ?
+		 WHERE org_id = ? AND provider = ? AND provider_event_id = ?
 		 LIMIT 1`,
+		orgID,
 		provider,
 		providerEventID,
 	).Scan(&item).Error
@@ -41,7 +42,7 @@ func (r *repo) InsertEvent(ctx context.Context, db *gorm.DB, event *domain.Event
 			id, org_id, provider, provider_event_id, event_type, customer_id,
 			payload, received_at, processed_at
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT (provider, provider_event_id) DO NOTHING`,
+		ON CONFLICT (org_id, provider, provider_event_id) DO NOTHING`,
 		event.ID,
 		event.OrgID,
 		event.Provider,
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/payment/domain"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestInsertEvent_DeduplicatesPerOrg(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE payment_events (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			provider TEXT NOT NULL,
+			provider_event_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			customer_id BIGINT NOT NULL,
+			payload TEXT NOT NULL,
+			received_at DATETIME NOT NULL,
+			processed_at DATETIME
+		)`,
+		`CREATE UNIQUE INDEX ux_payment_events_org_provider_event_id ON payment_events(org_id, provider, provider_event_id)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+
+	r := Provide()
+	ctx := context.Background()
+	event := func(id, orgID int64) *domain.EventRecord {
+		return &domain.EventRecord{
+			ID:              snowflake.ID(id),
+			OrgID:           snowflake.ID(orgID),
+			Provider:        "stripe",
+			ProviderEventID: "evt_1",
+			EventType:       domain.EventTypePaymentSucceeded,
+			CustomerID:      snowflake.ID(100),
+			Payload:         datatypes.JSON(`{}`),
+			ReceivedAt:      time.Now().UTC(),
+		}
+	}
+
+	cases := []struct {
+		name  string
+		event *domain.EventRecord
+		want  bool
+	}{
+		{"first delivery", event(1, 10), true},
+		{"replay", event(2, 10), false},
+		{"same event id in another org", event(3, 20), true},
+	}
+	for _, tc := range cases {
+		inserted, err := r.InsertEvent(ctx, db, tc.event)
+		if err != nil {
+			t.Fatalf("%s: insert: %v", tc.name, err)
+		}
+		if inserted != tc.want {
+			t.Fatalf("%s: inserted = %v, want %v", tc.name, inserted, tc.want)
+		}
+	}
+
+	stored, err := r.FindEvent(ctx, db, snowflake.ID(10), "stripe", "evt_1")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if stored == nil || stored.ID != snowflake.ID(1) {
+		t.Fatalf("find returned %+v, want the first delivery", stored)
+	}
+
+	var count int64
+	if err := db.Table("payment_events").Count(&count).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("stored %d events, want 2", count)
+	}
+}
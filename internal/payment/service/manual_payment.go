@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/ledger"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var manualPaymentMethods = map[string]struct{}{
+	paymentdomain.ManualPaymentMethodCard:         {},
+	paymentdomain.ManualPaymentMethodBankTransfer: {},
+	paymentdomain.ManualPaymentMethodCash:         {},
+	paymentdomain.ManualPaymentMethodCheck:        {},
+	paymentdomain.ManualPaymentMethodOther:        {},
+}
+
+// RecordManualPayment records a payment taken outside any provider against
+// an open invoice. It is stored as a processed payment_succeeded event of
+// the manual provider carrying the invoice in metadata.invoice_id, posted
+// to the ledger (debit cash, credit accounts receivable), applied to the
+// invoice and audited in one transaction, so settled amounts and overdue
+// lists see it like a provider payment.
+//
+// What is due is the invoice total less what the ledger has settled on it.
+// An amount above that is rejected unless
+// billing.manualPayments.allowOverpayment is set; the excess then stays on
+// accounts receivable as customer credit. A repeat with the same
+// IdempotencyKey returns the payment the first call recorded.
+func (s *Service) RecordManualPayment(ctx context.Context, req paymentdomain.RecordManualPaymentRequest) (*paymentdomain.ManualPayment, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, paymentdomain.ErrInvalidOrganization
+	}
+	invoiceID, err := snowflake.ParseString(strings.TrimSpace(req.InvoiceID))
+	if err != nil || invoiceID == 0 {
+		return nil, paymentdomain.ErrInvoiceNotFound
+	}
+	if req.Amount <= 0 {
+		return nil, paymentdomain.ErrInvalidAmount
+	}
+	method := strings.ToLower(strings.TrimSpace(req.Method))
+	if _, ok := manualPaymentMethods[method]; !ok {
+		return nil, paymentdomain.ErrInvalidPaymentMethod
+	}
+	reference := strings.TrimSpace(req.Reference)
+	recordedBy := strings.TrimSpace(req.RecordedBy)
+	allowOverpayment := s.billingCfg.Get().ManualPayments.AllowOverpayment
+
+	key := ledger.IdempotencyKey{
+		OrgID:   orgID,
+		Scope:   "payment.manual",
+		Key:     req.IdempotencyKey,
+		Request: fmt.Sprintf("%s|%d|%s|%s", invoiceID, req.Amount, method, reference),
+	}
+
+	var (
+		stored *paymentdomain.EventRecord
+		event  *paymentdomain.PaymentEvent
+	)
+	payment, replayed, err := ledger.WithIdempotency(ctx, s.db, key, func(tx *gorm.DB) (*paymentdomain.ManualPayment, error) {
+		var invoice struct {
+			ID             snowflake.ID `gorm:"column:id"`
+			CustomerID     snowflake.ID `gorm:"column:customer_id"`
+			Currency       string       `gorm:"column:currency"`
+			Status         string       `gorm:"column:status"`
+			SubtotalAmount int64        `gorm:"column:subtotal_amount"`
+			PaidAt         *time.Time   `gorm:"column:paid_at"`
+			VoidedAt       *time.Time   `gorm:"column:voided_at"`
+		}
+		if err := tx.WithContext(ctx).Raw(
+			`SELECT id, customer_id, currency, status, subtotal_amount, paid_at, voided_at
+			 FROM invoices
+			 WHERE id = ? AND org_id = ?
+			 FOR UPDATE`,
+			invoiceID,
+			orgID,
+		).Scan(&invoice).Error; err != nil {
+			return nil, err
+		}
+		if invoice.ID == 0 {
+			return nil, paymentdomain.ErrInvoiceNotFound
+		}
+		if invoice.Status != "FINALIZED" || invoice.VoidedAt != nil || invoice.PaidAt != nil {
+			return nil, paymentdomain.ErrInvoiceNotPayable
+		}
+
+		settled, err := s.invoiceSettledAmountTx(ctx, tx, orgID, invoiceID, invoice.Currency)
+		if err != nil {
+			return nil, err
+		}
+		due := invoice.SubtotalAmount - settled
+		if due < 0 {
+			due = 0
+		}
+		var credit int64
+		if req.Amount > due {
+			if !allowOverpayment {
+				return nil, paymentdomain.ErrPaymentExceedsDue
+			}
+			credit = req.Amount - due
+		}
+
+		now := time.Now().UTC()
+		currency := strings.ToUpper(strings.TrimSpace(invoice.Currency))
+		eventID := s.genID.Generate()
+		payload, err := json.Marshal(map[string]any{
+			"data": map[string]any{
+				"object": map[string]any{
+					"amount":   req.Amount,
+					"currency": currency,
+					"metadata": map[string]any{
+						"invoice_id":  invoiceID.String(),
+						"method":      method,
+						"reference":   reference,
+						"recorded_by": recordedBy,
+					},
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		stored = &paymentdomain.EventRecord{
+			ID:              eventID,
+			OrgID:           orgID,
+			Provider:        paymentdomain.ProviderManual,
+			ProviderEventID: eventID.String(),
+			EventType:       paymentdomain.EventTypePaymentSucceeded,
+			CustomerID:      invoice.CustomerID,
+			Payload:         datatypes.JSON(payload),
+			ReceivedAt:      now,
+			ProcessedAt:     &now,
+		}
+		if _, err := s.repo.InsertEvent(ctx, tx, stored); err != nil {
+			return nil, err
+		}
+
+		event = &paymentdomain.PaymentEvent{
+			Provider:        stored.Provider,
+			ProviderEventID: stored.ProviderEventID,
+			Type:            stored.EventType,
+			OrgID:           orgID,
+			CustomerID:      invoice.CustomerID,
+			Amount:          req.Amount,
+			Currency:        currency,
+			OccurredAt:      now,
+			InvoiceID:       &invoiceID,
+		}
+		if err := s.postManualPaymentTx(ctx, tx, stored, event); err != nil {
+			return nil, err
+		}
+		if err := s.updateInvoiceSettlementTx(ctx, tx, orgID, event, false); err != nil {
+			return nil, err
+		}
+		if err := s.writeAuditLogTx(ctx, tx, "payment.recorded_manually", stored, event, map[string]any{
+			"method":      method,
+			"reference":   reference,
+			"recorded_by": recordedBy,
+			"credit":      credit,
+		}); err != nil {
+			return nil, err
+		}
+
+		return &paymentdomain.ManualPayment{
+			PaymentEventID: eventID,
+			InvoiceID:      invoiceID,
+			CustomerID:     invoice.CustomerID,
+			Amount:         req.Amount,
+			Currency:       currency,
+			Method:         method,
+			Reference:      reference,
+			RecordedBy:     recordedBy,
+			AmountDue:      due - req.Amount + credit,
+			Credit:         credit,
+			RecordedAt:     now,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		return payment, nil
+	}
+
+	if s.obsMetrics != nil {
+		s.obsMetrics.RecordPaymentEvent(ctx, stored.Provider, stored.EventType)
+	}
+	return payment, nil
+}
+
+// postManualPaymentTx posts a manual payment to the ledger inside tx, with
+// the same accounts and source as a provider payment, so it is validated and
+// published like any other entry.
+func (s *Service) postManualPaymentTx(ctx context.Context, tx *gorm.DB, stored *paymentdomain.EventRecord, event *paymentdomain.PaymentEvent) error {
+	now := time.Now().UTC()
+	cashID, err := s.ensureLedgerAccount(ctx, tx, stored.OrgID, string(ledgerdomain.AccountCodeCash), string(ledgerdomain.AccountCodeCash), now)
+	if err != nil {
+		return err
+	}
+	arID, err := s.ensureLedgerAccount(ctx, tx, stored.OrgID, string(ledgerdomain.AccountCodeAccountsReceivable), string(ledgerdomain.AccountCodeAccountsReceivable), now)
+	if err != nil {
+		return err
+	}
+
+	lines := []ledgerdomain.LedgerEntryLine{
+		{AccountID: cashID, Direction: ledgerdomain.LedgerEntryDirectionDebit, Currency: event.Currency, Amount: event.Amount},
+		{AccountID: arID, Direction: ledgerdomain.LedgerEntryDirectionCredit, Currency: event.Currency, Amount: event.Amount},
+	}
+	return s.ledgerSvc.CreateEntryTx(
+		ctx,
+		tx,
+		stored.OrgID,
+		string(ledgerdomain.SourceTypePayment),
+		stored.ID,
+		event.Currency,
+		event.OccurredAt,
+		lines,
+	)
+}
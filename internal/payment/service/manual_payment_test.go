@@ -0,0 +1,321 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/smallbiznis/railzway/internal/events"
+	ledgerservice "github.com/smallbiznis/railzway/internal/ledger/service"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+	paymentrepo "github.com/smallbiznis/railzway/internal/payment/repository"
+	paymentservice "github.com/smallbiznis/railzway/internal/payment/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestRecordManualPayment(t *testing.T) {
+	db := setupManualPaymentDB(t)
+
+	node, err := snowflake.NewNode(13)
+	if err != nil {
+		t.Fatalf("new node: %v", err)
+	}
+	ledgerSvc := ledgerservice.NewService(ledgerservice.Params{
+		DB:       db,
+		Log:      zap.NewNop(),
+		GenID:    node,
+		AuditSvc: noopAuditService{},
+		Outbox:   events.NewOutbox(db, node),
+	})
+	newService := func(allowOverpayment bool) *paymentservice.Service {
+		cfg := config.DefaultBillingConfig()
+		cfg.ManualPayments.AllowOverpayment = allowOverpayment
+		return paymentservice.NewService(paymentservice.Params{
+			DB:            db,
+			Log:           zap.NewNop(),
+			GenID:         node,
+			LedgerSvc:     ledgerSvc,
+			AuditSvc:      noopAuditService{},
+			Repo:          paymentrepo.Provide(),
+			BillingConfig: config.NewStaticBillingConfigHolder(cfg),
+		})
+	}
+	svc := newService(false)
+
+	orgID := node.Generate()
+	customerID := node.Generate()
+	if err := seedCustomer(db, orgID, customerID); err != nil {
+		t.Fatalf("seed customer: %v", err)
+	}
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	now := time.Now().UTC()
+
+	seedInvoice := func(status string, subtotal int64) snowflake.ID {
+		t.Helper()
+		id := node.Generate()
+		if err := db.Exec(
+			`INSERT INTO invoices (id, org_id, customer_id, invoice_number, status, currency, subtotal_amount, due_at, metadata)
+			 VALUES (?, ?, ?, ?, ?, 'usd', ?, ?, '{}')`,
+			id, orgID, customerID, "INV-"+id.String(), status, subtotal, now,
+		).Error; err != nil {
+			t.Fatalf("seed invoice: %v", err)
+		}
+		return id
+	}
+	record := func(svc *paymentservice.Service, invoiceID snowflake.ID, amount int64, key string) (*paymentdomain.ManualPayment, error) {
+		return svc.RecordManualPayment(ctx, paymentdomain.RecordManualPaymentRequest{
+			InvoiceID:      invoiceID.String(),
+			Amount:         amount,
+			Method:         " Card ",
+			Reference:      "phone call #42",
+			RecordedBy:     "agent-1",
+			IdempotencyKey: key,
+		})
+	}
+
+	invoiceID := seedInvoice("FINALIZED", 5000)
+
+	t.Run("partial payment settles the invoice amount", func(t *testing.T) {
+		payment, err := record(svc, invoiceID, 2000, "call-1")
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		if payment.Method != paymentdomain.ManualPaymentMethodCard || payment.Currency != "USD" {
+			t.Fatalf("expected a normalized method and currency, got %+v", payment)
+		}
+		if payment.AmountDue != 3000 || payment.Credit != 0 {
+			t.Fatalf("expected 3000 still due, got %+v", payment)
+		}
+
+		var event paymentdomain.EventRecord
+		if err := db.Raw(`SELECT * FROM payment_events WHERE id = ?`, payment.PaymentEventID).Scan(&event).Error; err != nil {
+			t.Fatalf("load event: %v", err)
+		}
+		if event.Provider != paymentdomain.ProviderManual || event.EventType != paymentdomain.EventTypePaymentSucceeded || event.ProcessedAt == nil {
+			t.Fatalf("expected a processed manual payment_succeeded event, got %+v", event)
+		}
+		if !strings.Contains(string(event.Payload), `"invoice_id":"`+invoiceID.String()+`"`) {
+			t.Fatalf("expected metadata.invoice_id in the payload, got %s", event.Payload)
+		}
+
+		assertCount(t, db, `SELECT COUNT(1) FROM ledger_entries WHERE source_type = 'payment' AND source_id = `+payment.PaymentEventID.String(), 1)
+		var credited int64
+		if err := db.Raw(
+			`SELECT COALESCE(SUM(l.amount), 0)
+			 FROM ledger_entry_lines l
+			 JOIN ledger_entries le ON le.id = l.ledger_entry_id
+			 JOIN ledger_accounts a ON a.id = l.account_id
+			 WHERE le.source_id = ? AND a.code = 'accounts_receivable' AND l.direction = 'credit'`,
+			payment.PaymentEventID,
+		).Scan(&credited).Error; err != nil {
+			t.Fatalf("load ledger lines: %v", err)
+		}
+		if credited != 2000 {
+			t.Fatalf("expected 2000 credited to accounts receivable, got %d", credited)
+		}
+		// Rollups follow the outbox, so the entry must be published there too.
+		assertCount(t, db, `SELECT COUNT(1) FROM billing_events WHERE event_type = '`+events.EventLedgerEntryCreated+`' AND payload LIKE '%"source_id":"`+payment.PaymentEventID.String()+`"%'`, 1)
+	})
+
+	t.Run("repeat with the same key replays the payment", func(t *testing.T) {
+		first, err := record(svc, invoiceID, 2000, "call-2")
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		again, err := record(svc, invoiceID, 2000, "call-2")
+		if err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		if again.PaymentEventID != first.PaymentEventID {
+			t.Fatalf("expected the first payment to be replayed, got %s and %s", first.PaymentEventID, again.PaymentEventID)
+		}
+		assertCount(t, db, `SELECT COUNT(1) FROM payment_events WHERE provider = 'manual'`, 2)
+	})
+
+	t.Run("rejects more than the amount due", func(t *testing.T) {
+		if _, err := record(svc, invoiceID, 1001, ""); !errors.Is(err, paymentdomain.ErrPaymentExceedsDue) {
+			t.Fatalf("expected ErrPaymentExceedsDue, got %v", err)
+		}
+	})
+
+	t.Run("overpayment is kept as credit when allowed", func(t *testing.T) {
+		payment, err := record(newService(true), invoiceID, 1500, "")
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		if payment.AmountDue != 0 || payment.Credit != 500 {
+			t.Fatalf("expected 500 left as credit, got %+v", payment)
+		}
+		var paidAt *time.Time
+		if err := db.Raw(`SELECT paid_at FROM invoices WHERE id = ?`, invoiceID).Scan(&paidAt).Error; err != nil {
+			t.Fatalf("load invoice: %v", err)
+		}
+		if paidAt == nil {
+			t.Fatalf("expected the invoice to be paid")
+		}
+		if _, err := record(svc, invoiceID, 100, ""); !errors.Is(err, paymentdomain.ErrInvoiceNotPayable) {
+			t.Fatalf("expected a paid invoice to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("paying what a credit note left due marks the invoice paid", func(t *testing.T) {
+		credited := seedInvoice("FINALIZED", 3000)
+		creditNoteID := node.Generate()
+		if err := db.Exec(
+			`INSERT INTO credit_notes (id, org_id, invoice_id) VALUES (?, ?, ?)`,
+			creditNoteID, orgID, credited,
+		).Error; err != nil {
+			t.Fatalf("seed credit note: %v", err)
+		}
+		seedPaymentLedgerEntry(t, db, node, orgID, creditNoteID, 1000, now)
+		if err := db.Exec(`UPDATE ledger_entries SET source_type = 'credit_note' WHERE source_id = ?`, creditNoteID).Error; err != nil {
+			t.Fatalf("seed credit note entry: %v", err)
+		}
+
+		payment, err := record(svc, credited, 2000, "")
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		if payment.AmountDue != 0 {
+			t.Fatalf("expected nothing left due, got %+v", payment)
+		}
+		var paidAt *time.Time
+		if err := db.Raw(`SELECT paid_at FROM invoices WHERE id = ?`, credited).Scan(&paidAt).Error; err != nil {
+			t.Fatalf("load invoice: %v", err)
+		}
+		if paidAt == nil {
+			t.Fatalf("expected the credit-noted invoice to be paid")
+		}
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		draft := seedInvoice("DRAFT", 1000)
+		if _, err := record(svc, draft, 100, ""); !errors.Is(err, paymentdomain.ErrInvoiceNotPayable) {
+			t.Fatalf("expected a draft invoice to be rejected, got %v", err)
+		}
+		if _, err := record(svc, node.Generate(), 100, ""); !errors.Is(err, paymentdomain.ErrInvoiceNotFound) {
+			t.Fatalf("expected ErrInvoiceNotFound, got %v", err)
+		}
+		open := seedInvoice("FINALIZED", 1000)
+		if _, err := record(svc, open, 0, ""); !errors.Is(err, paymentdomain.ErrInvalidAmount) {
+			t.Fatalf("expected ErrInvalidAmount, got %v", err)
+		}
+		_, err := svc.RecordManualPayment(ctx, paymentdomain.RecordManualPaymentRequest{InvoiceID: open.String(), Amount: 100, Method: "crypto"})
+		if !errors.Is(err, paymentdomain.ErrInvalidPaymentMethod) {
+			t.Fatalf("expected ErrInvalidPaymentMethod, got %v", err)
+		}
+	})
+}
+
+// setupManualPaymentDB extends the reconciliation schema with the unique
+// indexes the inserts conflict on, the outbox and the idempotency key table.
+func setupManualPaymentDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := setupReconciliationDB(t)
+	for _, stmt := range []string{
+		`CREATE UNIQUE INDEX ux_payment_events_provider ON payment_events (org_id, provider, provider_event_id)`,
+		`CREATE UNIQUE INDEX ux_ledger_accounts_code ON ledger_accounts (org_id, code)`,
+		`CREATE UNIQUE INDEX ux_ledger_entries_source ON ledger_entries (org_id, source_type, source_id)`,
+		`ALTER TABLE ledger_entry_lines ADD COLUMN currency TEXT`,
+		`ALTER TABLE invoices ADD COLUMN updated_at DATETIME`,
+		`CREATE TABLE billing_events (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			event_type TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			dedupe_key TEXT,
+			published BOOLEAN NOT NULL DEFAULT false,
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE UNIQUE INDEX ux_billing_event_dedupe ON billing_events (org_id, dedupe_key)`,
+		`CREATE TABLE idempotency_keys (
+			org_id BIGINT NOT NULL,
+			scope TEXT NOT NULL,
+			idempotency_key TEXT NOT NULL,
+			request_hash TEXT NOT NULL,
+			response TEXT,
+			created_at DATETIME NOT NULL,
+			expires_at DATETIME NOT NULL,
+			PRIMARY KEY (org_id, scope, idempotency_key)
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("schema exec failed: %v", err)
+		}
+	}
+	return db
+}
+
+// txAuditService writes audit actions through the transaction it is given
+// and fails the one named in failAction.
+type txAuditService struct {
+	noopAuditService
+	failAction string
+}
+
+func (a txAuditService) AuditLogTx(ctx context.Context, tx *gorm.DB, _ *snowflake.ID, _ string, _ *string, action string, _ string, _ *string, _ map[string]any) error {
+	if action == a.failAction {
+		return errors.New("audit unavailable")
+	}
+	return tx.WithContext(ctx).Exec(`INSERT INTO test_audit_actions (action) VALUES (?)`, action).Error
+}
+
+func TestRecordManualPaymentRollsBackLedgerAudit(t *testing.T) {
+	db := setupManualPaymentDB(t)
+	if err := db.Exec(`CREATE TABLE test_audit_actions (action TEXT NOT NULL)`).Error; err != nil {
+		t.Fatalf("schema exec failed: %v", err)
+	}
+
+	node, err := snowflake.NewNode(14)
+	if err != nil {
+		t.Fatalf("new node: %v", err)
+	}
+	audit := txAuditService{failAction: "payment.recorded_manually"}
+	svc := paymentservice.NewService(paymentservice.Params{
+		DB:    db,
+		Log:   zap.NewNop(),
+		GenID: node,
+		LedgerSvc: ledgerservice.NewService(ledgerservice.Params{
+			DB:       db,
+			Log:      zap.NewNop(),
+			GenID:    node,
+			AuditSvc: audit,
+			Outbox:   events.NewOutbox(db, node),
+		}),
+		AuditSvc:      audit,
+		Repo:          paymentrepo.Provide(),
+		BillingConfig: config.NewStaticBillingConfigHolder(config.DefaultBillingConfig()),
+	})
+
+	orgID := node.Generate()
+	customerID := node.Generate()
+	if err := seedCustomer(db, orgID, customerID); err != nil {
+		t.Fatalf("seed customer: %v", err)
+	}
+	invoiceID := node.Generate()
+	if err := db.Exec(
+		`INSERT INTO invoices (id, org_id, customer_id, invoice_number, status, currency, subtotal_amount, due_at, metadata)
+		 VALUES (?, ?, ?, 'INV-1', 'FINALIZED', 'usd', 1000, ?, '{}')`,
+		invoiceID, orgID, customerID, time.Now().UTC(),
+	).Error; err != nil {
+		t.Fatalf("seed invoice: %v", err)
+	}
+
+	_, err = svc.RecordManualPayment(orgcontext.WithOrgID(context.Background(), int64(orgID)), paymentdomain.RecordManualPaymentRequest{
+		InvoiceID: invoiceID.String(),
+		Amount:    1000,
+		Method:    paymentdomain.ManualPaymentMethodCash,
+	})
+	if err == nil {
+		t.Fatalf("expected the failed audit to fail the payment")
+	}
+	assertCount(t, db, `SELECT COUNT(1) FROM ledger_entries`, 0)
+	assertCount(t, db, `SELECT COUNT(1) FROM test_audit_actions WHERE action = 'ledger.entry_created'`, 0)
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	billingopsrepo "github.com/smallbiznis/railzway/internal/billingoperations/repository"
+	"github.com/smallbiznis/railzway/internal/ledger"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// minReferenceKeyLength keeps a reference made of a few digits from
+	// matching every invoice number that contains them.
+	minReferenceKeyLength = 4
+	// maxPaymentMatchCandidates caps the invoices returned for one reference.
+	maxPaymentMatchCandidates = 20
+)
+
+// invoiceNumberKey strips the separators invoice number templates use, so
+// it compares against a reference normalized by referenceKey.
+const invoiceNumberKey = `UPPER(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(i.invoice_number, '-', ''), ' ', ''), '/', ''), '_', ''), '.', ''))`
+
+// referenceKey upper-cases a transfer reference and drops everything but
+// letters and digits, since banks often rewrite or strip separators.
+func referenceKey(reference string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(reference) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (s *Service) MatchPaymentByReference(ctx context.Context, reference string, amount int64) ([]paymentdomain.PaymentMatchCandidate, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, paymentdomain.ErrInvalidOrganization
+	}
+	if amount <= 0 {
+		return nil, paymentdomain.ErrInvalidAmount
+	}
+	return s.matchPaymentByReference(ctx, s.db, orgID, reference, amount)
+}
+
+type referenceMatchRow struct {
+	ID            snowflake.ID `gorm:"column:id"`
+	InvoiceNumber string       `gorm:"column:invoice_number"`
+	CustomerID    snowflake.ID `gorm:"column:customer_id"`
+	CustomerName  string       `gorm:"column:customer_name"`
+	Currency      string       `gorm:"column:currency"`
+	AmountDue     int64        `gorm:"column:amount_due"`
+	DueAt         *time.Time   `gorm:"column:due_at"`
+}
+
+// matchPaymentByReference loads the open invoices whose number appears in
+// the reference, with what the ledger leaves due on them. Candidates whose
+// amount due equals the payment come first, then longer invoice numbers,
+// which are less likely to match by accident.
+func (s *Service) matchPaymentByReference(ctx context.Context, db *gorm.DB, orgID snowflake.ID, reference string, amount int64) ([]paymentdomain.PaymentMatchCandidate, error) {
+	key := referenceKey(reference)
+	if len(key) < minReferenceKeyLength {
+		return nil, paymentdomain.ErrInvalidReference
+	}
+
+	settled, settledArgs, err := billingopsrepo.SettledAmountCTE(ctx, db, orgID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []referenceMatchRow
+	if err := db.WithContext(ctx).Raw(
+		fmt.Sprintf(`SELECT i.id, i.invoice_number, i.customer_id, COALESCE(c.name, '') AS customer_name,
+		        i.currency, GREATEST(i.subtotal_amount - COALESCE(s.settled_amount, 0), 0) AS amount_due, i.due_at
+		 FROM invoices i
+		 LEFT JOIN customers c ON c.id = i.customer_id
+		 LEFT JOIN (%[2]s
+		 ) s ON s.invoice_id_text = i.id::text AND s.currency = i.currency
+		 WHERE i.org_id = ?
+		   AND i.status = 'FINALIZED'
+		   AND i.voided_at IS NULL
+		   AND i.paid_at IS NULL
+		   AND i.invoice_number IS NOT NULL
+		   AND LENGTH(%[1]s) >= ?
+		   AND ? LIKE '%%' || %[1]s || '%%'`, invoiceNumberKey, settled),
+		append(settledArgs,
+			orgID,
+			minReferenceKeyLength,
+			key,
+		)...,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	candidates := make([]paymentdomain.PaymentMatchCandidate, 0, len(rows))
+	keyLengths := make(map[string]int, len(rows))
+	for _, row := range rows {
+		due := row.AmountDue
+		if due <= 0 {
+			continue
+		}
+		invoiceID := row.ID.String()
+		keyLengths[invoiceID] = len(referenceKey(row.InvoiceNumber))
+		candidates = append(candidates, paymentdomain.PaymentMatchCandidate{
+			InvoiceID:     invoiceID,
+			InvoiceNumber: row.InvoiceNumber,
+			CustomerID:    row.CustomerID.String(),
+			CustomerName:  strings.TrimSpace(row.CustomerName),
+			Currency:      row.Currency,
+			AmountDue:     due,
+			DueAt:         row.DueAt,
+			AmountMatches: due == amount,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.AmountMatches != b.AmountMatches {
+			return a.AmountMatches
+		}
+		if keyLengths[a.InvoiceID] != keyLengths[b.InvoiceID] {
+			return keyLengths[a.InvoiceID] > keyLengths[b.InvoiceID]
+		}
+		return a.InvoiceNumber < b.InvoiceNumber
+	})
+	if len(candidates) > maxPaymentMatchCandidates {
+		candidates = candidates[:maxPaymentMatchCandidates]
+	}
+	return candidates, nil
+}
+
+// ConfirmPaymentMatch allocates an unmatched payment to an invoice, either
+// the one named in the request or the single candidate its reference
+// matches. The allocation and its audit entry commit together, and a repeat
+// with the same IdempotencyKey returns the first allocation.
+func (s *Service) ConfirmPaymentMatch(ctx context.Context, req paymentdomain.ConfirmPaymentMatchRequest) (*paymentdomain.PaymentAllocation, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return nil, paymentdomain.ErrInvalidOrganization
+	}
+	eventID, err := snowflake.ParseString(strings.TrimSpace(req.PaymentEventID))
+	if err != nil || eventID == 0 {
+		return nil, paymentdomain.ErrInvalidEvent
+	}
+
+	reference := strings.TrimSpace(req.Reference)
+	requestedInvoiceID := strings.TrimSpace(req.InvoiceID)
+	key := ledger.IdempotencyKey{
+		OrgID:   orgID,
+		Scope:   "payment.allocation",
+		Key:     req.IdempotencyKey,
+		Request: fmt.Sprintf("%s|%s|%s", eventID, requestedInvoiceID, reference),
+	}
+
+	allocation, _, err := ledger.WithIdempotency(ctx, s.db, key, func(tx *gorm.DB) (*paymentdomain.PaymentAllocation, error) {
+		stored, event, err := s.loadUnmatchedPayment(ctx, tx, orgID, eventID)
+		if err != nil {
+			return nil, err
+		}
+
+		var invoiceID snowflake.ID
+		if requestedInvoiceID != "" {
+			invoiceID, err = snowflake.ParseString(requestedInvoiceID)
+			if err != nil || invoiceID == 0 {
+				return nil, paymentdomain.ErrPaymentMatchNotFound
+			}
+		} else {
+			candidates, err := s.matchPaymentByReference(ctx, tx, orgID, reference, event.Amount)
+			if err != nil {
+				return nil, err
+			}
+			switch len(candidates) {
+			case 0:
+				return nil, paymentdomain.ErrPaymentMatchNotFound
+			case 1:
+				invoiceID, err = snowflake.ParseString(candidates[0].InvoiceID)
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, paymentdomain.ErrAmbiguousPaymentMatch
+			}
+		}
+		event.InvoiceID = &invoiceID
+
+		allocation := &paymentdomain.PaymentAllocation{
+			ID:             s.genID.Generate(),
+			OrgID:          orgID,
+			PaymentEventID: stored.ID,
+			InvoiceID:      invoiceID,
+			Reference:      reference,
+			AllocatedBy:    strings.TrimSpace(req.AllocatedBy),
+			CreatedAt:      time.Now().UTC(),
+		}
+		if err := s.checkAllocatableInvoice(ctx, tx, orgID, invoiceID, event); err != nil {
+			return nil, err
+		}
+		result := tx.WithContext(ctx).Exec(
+			`INSERT INTO payment_allocations (
+				id, org_id, payment_event_id, invoice_id, reference, allocated_by, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (payment_event_id) DO NOTHING`,
+			allocation.ID,
+			allocation.OrgID,
+			allocation.PaymentEventID,
+			allocation.InvoiceID,
+			allocation.Reference,
+			allocation.AllocatedBy,
+			allocation.CreatedAt,
+		)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, paymentdomain.ErrPaymentAlreadyMatched
+		}
+		if err := s.updateInvoiceSettlementTx(ctx, tx, orgID, event, false); err != nil {
+			return nil, err
+		}
+		if err := s.writeAuditLogTx(ctx, tx, "payment.matched", stored, event, map[string]any{
+			"allocation_id": allocation.ID.String(),
+			"reference":     allocation.Reference,
+			"allocated_by":  allocation.AllocatedBy,
+		}); err != nil {
+			return nil, err
+		}
+		return allocation, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allocation, nil
+}
+
+// loadUnmatchedPayment loads a settled payment event that carries no
+// invoice reference of its own, with the amount and currency it posted to
+// accounts receivable.
+func (s *Service) loadUnmatchedPayment(ctx context.Context, db *gorm.DB, orgID, eventID snowflake.ID) (*paymentdomain.EventRecord, *paymentdomain.PaymentEvent, error) {
+	var stored paymentdomain.EventRecord
+	if err := db.WithContext(ctx).Raw(
+		`SELECT id, org_id, provider, provider_event_id, event_type, customer_id, payload, received_at, processed_at
+		 FROM payment_events
+		 WHERE id = ? AND org_id = ?`,
+		eventID,
+		orgID,
+	).Scan(&stored).Error; err != nil {
+		return nil, nil, err
+	}
+	if stored.ID == 0 {
+		return nil, nil, paymentdomain.ErrPaymentEventNotFound
+	}
+	if stored.EventType != paymentdomain.EventTypePaymentSucceeded {
+		return nil, nil, paymentdomain.ErrInvalidEvent
+	}
+	if payloadInvoiceID(stored.Payload) != "" {
+		return nil, nil, paymentdomain.ErrPaymentAlreadyMatched
+	}
+
+	var posted struct {
+		Currency   string    `gorm:"column:currency"`
+		Amount     int64     `gorm:"column:amount"`
+		OccurredAt time.Time `gorm:"column:occurred_at"`
+	}
+	if err := db.WithContext(ctx).Raw(
+		`SELECT le.currency, le.occurred_at, SUM(l.amount) AS amount
+		 FROM ledger_entries le
+		 JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
+		 JOIN ledger_accounts a ON a.id = l.account_id
+		 WHERE le.org_id = ?
+		   AND le.source_type = ?
+		   AND le.source_id = ?
+		   AND a.code = ?
+		   AND l.direction = ?
+		 GROUP BY le.currency, le.occurred_at`,
+		orgID,
+		ledgerdomain.SourceTypePayment,
+		stored.ID,
+		ledgerdomain.AccountCodeAccountsReceivable,
+		ledgerdomain.LedgerEntryDirectionCredit,
+	).Scan(&posted).Error; err != nil {
+		return nil, nil, err
+	}
+	if posted.Amount <= 0 {
+		return nil, nil, paymentdomain.ErrPaymentEventNotFound
+	}
+
+	return &stored, &paymentdomain.PaymentEvent{
+		Provider:        stored.Provider,
+		ProviderEventID: stored.ProviderEventID,
+		Type:            stored.EventType,
+		OrgID:           stored.OrgID,
+		CustomerID:      stored.CustomerID,
+		Amount:          posted.Amount,
+		Currency:        posted.Currency,
+		OccurredAt:      posted.OccurredAt,
+	}, nil
+}
+
+// checkAllocatableInvoice rejects invoices that are not open, or that belong
+// to another customer or currency than the payment. The invoice row stays
+// locked for the rest of tx, as RecordManualPayment locks it, so a match and
+// a manual payment settle the same invoice one after the other.
+func (s *Service) checkAllocatableInvoice(ctx context.Context, tx *gorm.DB, orgID, invoiceID snowflake.ID, event *paymentdomain.PaymentEvent) error {
+	var row struct {
+		ID         snowflake.ID `gorm:"column:id"`
+		CustomerID snowflake.ID `gorm:"column:customer_id"`
+		Currency   string       `gorm:"column:currency"`
+	}
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT id, customer_id, currency
+		 FROM invoices
+		 WHERE id = ? AND org_id = ?
+		   AND status = 'FINALIZED'
+		   AND voided_at IS NULL
+		   AND paid_at IS NULL
+		 FOR UPDATE`,
+		invoiceID,
+		orgID,
+	).Scan(&row).Error; err != nil {
+		return err
+	}
+	if row.ID == 0 {
+		return paymentdomain.ErrPaymentMatchNotFound
+	}
+	if row.CustomerID != event.CustomerID || !strings.EqualFold(row.Currency, event.Currency) {
+		return paymentdomain.ErrPaymentMatchMismatch
+	}
+	return nil
+}
+
+// payloadInvoiceID returns the invoice ID the provider attached to the
+// payment, read from the same path the settled amount queries use.
+func payloadInvoiceID(payload datatypes.JSON) string {
+	var body struct {
+		Data struct {
+			Object struct {
+				Metadata map[string]any `json:"metadata"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return ""
+	}
+	value, ok := body.Data.Object.Metadata["invoice_id"]
+	if !ok || value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(value))
+}
@@ -0,0 +1,317 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+	paymentrepo "github.com/smallbiznis/railzway/internal/payment/repository"
+	paymentservice "github.com/smallbiznis/railzway/internal/payment/service"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMatchPaymentByReference(t *testing.T) {
+	db := setupReconciliationDB(t)
+
+	node, err := snowflake.NewNode(12)
+	if err != nil {
+		t.Fatalf("new node: %v", err)
+	}
+	svc := paymentservice.NewService(paymentservice.Params{
+		DB:       db,
+		Log:      zap.NewNop(),
+		GenID:    node,
+		AuditSvc: noopAuditService{},
+		Repo:     paymentrepo.Provide(),
+	})
+
+	orgID := node.Generate()
+	customerID := node.Generate()
+	otherCustomerID := node.Generate()
+	if err := seedCustomer(db, orgID, customerID); err != nil {
+		t.Fatalf("seed customer: %v", err)
+	}
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	now := time.Now().UTC()
+
+	seedInvoice := func(number, status string, customer snowflake.ID, subtotal int64, metadata string, paid bool) snowflake.ID {
+		t.Helper()
+		id := node.Generate()
+		var paidAt *time.Time
+		if paid {
+			paidAt = &now
+		}
+		if err := db.Exec(
+			`INSERT INTO invoices (id, org_id, customer_id, invoice_number, status, currency, subtotal_amount, due_at, paid_at, metadata)
+			 VALUES (?, ?, ?, ?, ?, 'USD', ?, ?, ?, ?)`,
+			id, orgID, customer, number, status, subtotal, now, paidAt, metadata,
+		).Error; err != nil {
+			t.Fatalf("seed invoice: %v", err)
+		}
+		return id
+	}
+	first := seedInvoice("INV-2025-0001", "FINALIZED", customerID, 1000, `{}`, false)
+	// The ledger settled 1000 of the tenth; its stale metadata is ignored.
+	tenth := seedInvoice("INV-2025-0010", "FINALIZED", customerID, 3000, `{"amount_paid": 2500}`, false)
+	seedInvoice("INV-2025-0011", "FINALIZED", customerID, 2000, `{}`, true)
+	seedInvoice("INV-2025-0012", "DRAFT", customerID, 2000, `{}`, false)
+	other := seedInvoice("INV-2025-0020", "FINALIZED", otherCustomerID, 500, `{}`, false)
+
+	seedPayment := func(payload string, amount int64) snowflake.ID {
+		t.Helper()
+		id := node.Generate()
+		if err := db.Exec(
+			`INSERT INTO payment_events (id, org_id, provider, provider_event_id, event_type, customer_id, payload, received_at, processed_at)
+			 VALUES (?, ?, 'bank', ?, ?, ?, ?, ?, ?)`,
+			id, orgID, id.String(), paymentdomain.EventTypePaymentSucceeded, customerID, payload, now, now,
+		).Error; err != nil {
+			t.Fatalf("seed payment event: %v", err)
+		}
+		seedPaymentLedgerEntry(t, db, node, orgID, id, amount, now)
+		return id
+	}
+
+	seedPayment(`{"data":{"object":{"metadata":{"invoice_id":"`+tenth.String()+`"}}}}`, 1000)
+
+	candidates, err := svc.MatchPaymentByReference(ctx, "SEPA transfer inv2025/0010 thanks", 2000)
+	if err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].InvoiceID != tenth.String() {
+		t.Fatalf("expected only INV-2025-0010, got %+v", candidates)
+	}
+	if candidates[0].AmountDue != 2000 || !candidates[0].AmountMatches {
+		t.Fatalf("expected the partially paid amount due to match, got %+v", candidates[0])
+	}
+	if candidates[0].CustomerName != "Acme Co" {
+		t.Fatalf("expected the customer name, got %q", candidates[0].CustomerName)
+	}
+
+	candidates, err = svc.MatchPaymentByReference(ctx, "INV-2025-0001 INV-2025-0010 INV-2025-0011", 2000)
+	if err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("expected two open invoices, got %+v", candidates)
+	}
+	if candidates[0].InvoiceID != tenth.String() || candidates[1].InvoiceID != first.String() {
+		t.Fatalf("expected the amount match first, got %+v", candidates)
+	}
+
+	if _, err := svc.MatchPaymentByReference(ctx, "-12-", 2000); !errors.Is(err, paymentdomain.ErrInvalidReference) {
+		t.Fatalf("expected a short reference to be rejected, got %v", err)
+	}
+
+	ambiguous := seedPayment(`{"data":{"object":{}}}`, 2000)
+	_, err = svc.ConfirmPaymentMatch(ctx, paymentdomain.ConfirmPaymentMatchRequest{
+		PaymentEventID: ambiguous.String(),
+		Reference:      "INV-2025-0001 / INV-2025-0010",
+	})
+	if !errors.Is(err, paymentdomain.ErrAmbiguousPaymentMatch) {
+		t.Fatalf("expected an ambiguous reference to need an explicit invoice, got %v", err)
+	}
+
+	_, err = svc.ConfirmPaymentMatch(ctx, paymentdomain.ConfirmPaymentMatchRequest{
+		PaymentEventID: ambiguous.String(),
+		InvoiceID:      other.String(),
+	})
+	if !errors.Is(err, paymentdomain.ErrPaymentMatchMismatch) {
+		t.Fatalf("expected another customer's invoice to be rejected, got %v", err)
+	}
+
+	matched := seedPayment(`{"data":{"object":{"metadata":{"invoice_id":"`+first.String()+`"}}}}`, 1000)
+	_, err = svc.ConfirmPaymentMatch(ctx, paymentdomain.ConfirmPaymentMatchRequest{
+		PaymentEventID: matched.String(),
+		InvoiceID:      first.String(),
+	})
+	if !errors.Is(err, paymentdomain.ErrPaymentAlreadyMatched) {
+		t.Fatalf("expected a payment with an invoice reference to be rejected, got %v", err)
+	}
+
+	assertCount(t, db, "SELECT COUNT(1) FROM payment_allocations", 0)
+}
+
+func seedPaymentLedgerEntry(t *testing.T, db *gorm.DB, node *snowflake.Node, orgID, eventID snowflake.ID, amount int64, now time.Time) {
+	t.Helper()
+	var accountID snowflake.ID
+	if err := db.Raw(`SELECT id FROM ledger_accounts WHERE org_id = ? AND code = 'accounts_receivable'`, orgID).Scan(&accountID).Error; err != nil {
+		t.Fatalf("load account: %v", err)
+	}
+	if accountID == 0 {
+		accountID = node.Generate()
+		if err := db.Exec(
+			`INSERT INTO ledger_accounts (id, org_id, code, name, created_at) VALUES (?, ?, 'accounts_receivable', 'accounts_receivable', ?)`,
+			accountID, orgID, now,
+		).Error; err != nil {
+			t.Fatalf("seed account: %v", err)
+		}
+	}
+	entryID := node.Generate()
+	if err := db.Exec(
+		`INSERT INTO ledger_entries (id, org_id, source_type, source_id, currency, occurred_at, created_at) VALUES (?, ?, 'payment', ?, 'USD', ?, ?)`,
+		entryID, orgID, eventID, now, now,
+	).Error; err != nil {
+		t.Fatalf("seed ledger entry: %v", err)
+	}
+	if err := db.Exec(
+		`INSERT INTO ledger_entry_lines (id, ledger_entry_id, account_id, direction, amount, created_at) VALUES (?, ?, ?, 'credit', ?, ?)`,
+		node.Generate(), entryID, accountID, amount, now,
+	).Error; err != nil {
+		t.Fatalf("seed ledger line: %v", err)
+	}
+}
+
+// setupReconciliationDB declares timestamps as DATETIME so the driver scans
+// them into time.Time, which the reconciliation queries read back. sqlite has
+// no row locks, so FOR UPDATE is dropped, and the settled-amount query's
+// Postgres JSON path, casts and GREATEST are rewritten to their sqlite forms.
+func setupReconciliationDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqliteSQL := strings.NewReplacer(
+		"FOR UPDATE", "",
+		"#>> '{data,object,metadata,invoice_id}'", "->> '$.data.object.metadata.invoice_id'",
+		"i.id::text", "CAST(i.id AS TEXT)",
+		"pa.invoice_id::text", "CAST(pa.invoice_id AS TEXT)",
+		"cn.invoice_id::text", "CAST(cn.invoice_id AS TEXT)",
+		"GREATEST(", "MAX(",
+	)
+	db.Callback().Row().Before("gorm:row").Register("sqlite_for_update_row", func(d *gorm.DB) {
+		sql := d.Statement.SQL.String()
+		if rewritten := sqliteSQL.Replace(sql); rewritten != sql {
+			d.Statement.SQL.Reset()
+			d.Statement.SQL.WriteString(rewritten)
+		}
+	})
+	for _, stmt := range []string{
+		`CREATE TABLE payment_events (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			provider TEXT NOT NULL,
+			provider_event_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			customer_id BIGINT NOT NULL,
+			payload TEXT NOT NULL,
+			received_at DATETIME NOT NULL,
+			processed_at DATETIME
+		)`,
+		`CREATE TABLE ledger_accounts (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, code TEXT NOT NULL, name TEXT NOT NULL, created_at DATETIME NOT NULL)`,
+		`CREATE TABLE ledger_entries (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, source_type TEXT NOT NULL, source_id BIGINT NOT NULL, currency TEXT NOT NULL, occurred_at DATETIME NOT NULL, created_at DATETIME NOT NULL)`,
+		`CREATE TABLE ledger_entry_lines (id BIGINT PRIMARY KEY, ledger_entry_id BIGINT NOT NULL, account_id BIGINT NOT NULL, direction TEXT NOT NULL, amount BIGINT NOT NULL, created_at DATETIME NOT NULL)`,
+		`CREATE TABLE customers (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, name TEXT NOT NULL)`,
+		`CREATE TABLE invoices (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			customer_id BIGINT NOT NULL,
+			invoice_number TEXT,
+			status TEXT NOT NULL,
+			currency TEXT NOT NULL,
+			subtotal_amount BIGINT NOT NULL,
+			due_at DATETIME,
+			paid_at DATETIME,
+			voided_at DATETIME,
+			metadata TEXT
+		)`,
+		`CREATE TABLE payment_allocations (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			payment_event_id BIGINT NOT NULL UNIQUE,
+			invoice_id BIGINT NOT NULL,
+			reference TEXT NOT NULL,
+			allocated_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		)`,
+		`CREATE TABLE credit_notes (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			invoice_id BIGINT NOT NULL
+		)`,
+		`CREATE TABLE organization_billing_preferences (org_id BIGINT PRIMARY KEY, receivable_account_codes TEXT)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("schema exec failed: %v", err)
+		}
+	}
+	return db
+}
+
+// TestConfirmPaymentMatchRollsBackWithAudit checks the allocation and its
+// audit entry commit together: a failed audit leaves the payment unmatched,
+// so the retry records both.
+func TestConfirmPaymentMatchRollsBackWithAudit(t *testing.T) {
+	db := setupReconciliationDB(t)
+	for _, stmt := range []string{
+		`ALTER TABLE invoices ADD COLUMN updated_at DATETIME`,
+		`CREATE TABLE test_audit_actions (action TEXT NOT NULL)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("schema exec failed: %v", err)
+		}
+	}
+
+	node, err := snowflake.NewNode(15)
+	if err != nil {
+		t.Fatalf("new node: %v", err)
+	}
+	newService := func(audit txAuditService) *paymentservice.Service {
+		return paymentservice.NewService(paymentservice.Params{
+			DB:       db,
+			Log:      zap.NewNop(),
+			GenID:    node,
+			AuditSvc: audit,
+			Repo:     paymentrepo.Provide(),
+		})
+	}
+
+	orgID := node.Generate()
+	customerID := node.Generate()
+	if err := seedCustomer(db, orgID, customerID); err != nil {
+		t.Fatalf("seed customer: %v", err)
+	}
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	now := time.Now().UTC()
+
+	invoiceID := node.Generate()
+	if err := db.Exec(
+		`INSERT INTO invoices (id, org_id, customer_id, invoice_number, status, currency, subtotal_amount, due_at, metadata)
+		 VALUES (?, ?, ?, 'INV-2025-0001', 'FINALIZED', 'USD', 1000, ?, '{}')`,
+		invoiceID, orgID, customerID, now,
+	).Error; err != nil {
+		t.Fatalf("seed invoice: %v", err)
+	}
+	eventID := node.Generate()
+	if err := db.Exec(
+		`INSERT INTO payment_events (id, org_id, provider, provider_event_id, event_type, customer_id, payload, received_at, processed_at)
+		 VALUES (?, ?, 'bank', ?, ?, ?, '{"data":{"object":{}}}', ?, ?)`,
+		eventID, orgID, eventID.String(), paymentdomain.EventTypePaymentSucceeded, customerID, now, now,
+	).Error; err != nil {
+		t.Fatalf("seed payment event: %v", err)
+	}
+	seedPaymentLedgerEntry(t, db, node, orgID, eventID, 1000, now)
+
+	req := paymentdomain.ConfirmPaymentMatchRequest{
+		PaymentEventID: eventID.String(),
+		InvoiceID:      invoiceID.String(),
+	}
+	if _, err := newService(txAuditService{failAction: "payment.matched"}).ConfirmPaymentMatch(ctx, req); err == nil {
+		t.Fatal("expected the failed audit to fail the match")
+	}
+	assertCount(t, db, "SELECT COUNT(1) FROM payment_allocations", 0)
+
+	if _, err := newService(txAuditService{}).ConfirmPaymentMatch(ctx, req); err != nil {
+		t.Fatalf("confirm match: %v", err)
+	}
+	assertCount(t, db, "SELECT COUNT(1) FROM payment_allocations", 1)
+	assertCount(t, db, "SELECT COUNT(1) FROM test_audit_actions WHERE action = 'payment.matched'", 1)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/bwmarrin/snowflake"
 	auditdomain "github.com/smallbiznis/railzway/internal/audit/domain"
+	"github.com/smallbiznis/railzway/internal/config"
 	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
 	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
 	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
@@ -29,6 +30,8 @@ type Params struct {
 	AuditSvc   auditdomain.Service
 	Repo       paymentdomain.Repository
 	ObsMetrics *obsmetrics.Metrics `optional:"true"`
+	// BillingConfig holds the manual payment settings.
+	BillingConfig *config.BillingConfigHolder `optional:"true"`
 }
 
 type Service struct {
@@ -39,6 +42,7 @@ type Service struct {
 	auditSvc   auditdomain.Service
 	repo       paymentdomain.Repository
 	obsMetrics *obsmetrics.Metrics
+	billingCfg *config.BillingConfigHolder
 }
 
 func NewService(p Params) *Service {
@@ -50,6 +54,7 @@ func NewService(p Params) *Service {
 		auditSvc:   p.AuditSvc,
 		repo:       p.Repo,
 		obsMetrics: p.ObsMetrics,
+		billingCfg: p.BillingConfig,
 	}
 }
 
@@ -86,7 +91,10 @@ func (s *Service) ProcessEvent(ctx context.Context, event *paymentdomain.Payment
 	}
 	stored := &received
 	if !inserted {
-		stored, err = s.loadEvent(ctx, event.Provider, event.ProviderEventID)
+		if s.obsMetrics != nil {
+			s.obsMetrics.RecordPaymentEventDeduplicated(ctx, event.Provider, event.Type)
+		}
+		stored, err = s.loadEvent(ctx, event.OrgID, event.Provider, event.ProviderEventID)
 		if err != nil {
 			return err
 		}
@@ -155,8 +163,8 @@ func (s *Service) insertEvent(ctx context.Context, event *paymentdomain.EventRec
 	return s.repo.InsertEvent(ctx, s.db, event)
 }
 
-func (s *Service) loadEvent(ctx context.Context, provider string, providerEventID string) (*paymentdomain.EventRecord, error) {
-	return s.repo.FindEvent(ctx, s.db, provider, providerEventID)
+func (s *Service) loadEvent(ctx context.Context, orgID snowflake.ID, provider string, providerEventID string) (*paymentdomain.EventRecord, error) {
+	return s.repo.FindEvent(ctx, s.db, orgID, provider, providerEventID)
 }
 
 func (s *Service) markProcessed(ctx context.Context, id snowflake.ID, processedAt time.Time) error {
@@ -287,6 +295,7 @@ func (s *Service) createPaymentLedgerEntry(
 
 	debitID, err := s.ensureLedgerAccount(
 		ctx,
+		s.db,
 		stored.OrgID,
 		string(debitAccount),
 		string(debitAccount),
@@ -298,6 +307,7 @@ func (s *Service) createPaymentLedgerEntry(
 
 	creditID, err := s.ensureLedgerAccount(
 		ctx,
+		s.db,
 		stored.OrgID,
 		string(creditAccount),
 		string(creditAccount),
@@ -323,7 +333,7 @@ func (s *Service) createPaymentLedgerEntry(
 	)
 }
 
-func (s *Service) ensureLedgerAccount(ctx context.Context, orgID snowflake.ID, code string, name string, now time.Time) (snowflake.ID, error) {
+func (s *Service) ensureLedgerAccount(ctx context.Context, db *gorm.DB, orgID snowflake.ID, code string, name string, now time.Time) (snowflake.ID, error) {
 	code = strings.TrimSpace(code)
 	if code == "" {
 		return 0, ledgerdomain.ErrInvalidAccount
@@ -334,7 +344,7 @@ func (s *Service) ensureLedgerAccount(ctx context.Context, orgID snowflake.ID, c
 	}
 
 	var accountID snowflake.ID
-	if err := s.db.WithContext(ctx).Raw(
+	if err := db.WithContext(ctx).Raw(
 		`SELECT id
 		 FROM ledger_accounts
 		 WHERE org_id = ? AND code = ?`,
@@ -348,7 +358,7 @@ func (s *Service) ensureLedgerAccount(ctx context.Context, orgID snowflake.ID, c
 	}
 
 	newID := s.genID.Generate()
-	if err := s.db.WithContext(ctx).Exec(
+	if err := db.WithContext(ctx).Exec(
 		`INSERT INTO ledger_accounts (id, org_id, code, name, created_at)
 		 VALUES (?, ?, ?, ?, ?)
 		 ON CONFLICT (org_id, code) DO NOTHING`,
@@ -361,7 +371,7 @@ func (s *Service) ensureLedgerAccount(ctx context.Context, orgID snowflake.ID, c
 		return 0, err
 	}
 
-	if err := s.db.WithContext(ctx).Raw(
+	if err := db.WithContext(ctx).Raw(
 		`SELECT id
 		 FROM ledger_accounts
 		 WHERE org_id = ? AND code = ?`,
@@ -382,71 +392,118 @@ func (s *Service) updateInvoiceSettlement(ctx context.Context, orgID snowflake.I
 	}
 
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var row struct {
-			ID             snowflake.ID      `gorm:"column:id"`
-			OrgID          snowflake.ID      `gorm:"column:org_id"`
-			SubtotalAmount int64             `gorm:"column:subtotal_amount"`
-			PaidAt         *time.Time        `gorm:"column:paid_at"`
-			Metadata       datatypes.JSONMap `gorm:"column:metadata"`
-		}
-		if err := tx.WithContext(ctx).Raw(
-			`SELECT id, org_id, subtotal_amount, paid_at, metadata
-			 FROM invoices
-			 WHERE id = ? AND org_id = ?
-			 FOR UPDATE`,
-			*event.InvoiceID,
-			orgID,
-		).Scan(&row).Error; err != nil {
-			return err
-		}
-		if row.ID == 0 {
-			return nil
-		}
-		if row.PaidAt != nil && !isRefund {
-			return nil
-		}
+		return s.updateInvoiceSettlementTx(ctx, tx, orgID, event, isRefund)
+	})
+}
 
-		paid := readMetadataAmount(row.Metadata, "amount_paid")
-		if isRefund {
-			paid -= event.Amount
-		} else {
-			paid += event.Amount
-		}
-		if paid < 0 {
-			paid = 0
-		}
-		if row.Metadata == nil {
-			row.Metadata = datatypes.JSONMap{}
-		}
-		applyPaymentMetadata(row.Metadata, event)
-		row.Metadata["amount_paid"] = paid
-		if !isRefund {
-			delete(row.Metadata, "payment_failed_at")
-		}
+func (s *Service) updateInvoiceSettlementTx(ctx context.Context, tx *gorm.DB, orgID snowflake.ID, event *paymentdomain.PaymentEvent, isRefund bool) error {
+	if event == nil || event.InvoiceID == nil || *event.InvoiceID == 0 {
+		return nil
+	}
 
-		now := time.Now().UTC()
-		paidAt := row.PaidAt
-		if row.SubtotalAmount > 0 && paid >= row.SubtotalAmount {
-			if paidAt == nil {
-				paidAt = &now
-			}
-		}
+	var row struct {
+		ID             snowflake.ID      `gorm:"column:id"`
+		OrgID          snowflake.ID      `gorm:"column:org_id"`
+		Currency       string            `gorm:"column:currency"`
+		SubtotalAmount int64             `gorm:"column:subtotal_amount"`
+		PaidAt         *time.Time        `gorm:"column:paid_at"`
+		Metadata       datatypes.JSONMap `gorm:"column:metadata"`
+	}
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT id, org_id, currency, subtotal_amount, paid_at, metadata
+		 FROM invoices
+		 WHERE id = ? AND org_id = ?
+		 FOR UPDATE`,
+		*event.InvoiceID,
+		orgID,
+	).Scan(&row).Error; err != nil {
+		return err
+	}
+	if row.ID == 0 {
+		return nil
+	}
+	if row.PaidAt != nil && !isRefund {
+		return nil
+	}
 
-		if err := tx.WithContext(ctx).Exec(
-			`UPDATE invoices
-			 SET metadata = ?, paid_at = ?, updated_at = ?
-			 WHERE id = ? AND org_id = ?`,
-			row.Metadata,
-			paidAt,
-			now,
-			row.ID,
-			orgID,
-		).Error; err != nil {
-			return err
+	paid := readMetadataAmount(row.Metadata, "amount_paid")
+	if isRefund {
+		paid -= event.Amount
+	} else {
+		paid += event.Amount
+	}
+	if paid < 0 {
+		paid = 0
+	}
+	if row.Metadata == nil {
+		row.Metadata = datatypes.JSONMap{}
+	}
+	applyPaymentMetadata(row.Metadata, event)
+	row.Metadata["amount_paid"] = paid
+	if !isRefund {
+		delete(row.Metadata, "payment_failed_at")
+	}
+
+	// The invoice is paid once the ledger has settled its total, which
+	// counts credit notes as well as payments. Callers post the payment's
+	// ledger entry before applying it here.
+	settled, err := s.invoiceSettledAmountTx(ctx, tx, orgID, row.ID, row.Currency)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	paidAt := row.PaidAt
+	if row.SubtotalAmount > 0 && settled >= row.SubtotalAmount {
+		if paidAt == nil {
+			paidAt = &now
 		}
+	}
 
-		return nil
-	})
+	if err := tx.WithContext(ctx).Exec(
+		`UPDATE invoices
+		 SET metadata = ?, paid_at = ?, updated_at = ?
+		 WHERE id = ? AND org_id = ?`,
+		row.Metadata,
+		paidAt,
+		now,
+		row.ID,
+		orgID,
+	).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// invoiceSettledAmountTx returns what the ledger has settled on an invoice:
+// the receivable lines of payments that reference it, directly or through an
+// allocation, and of its credit notes, net of reversals. It matches the
+// settled amounts the collections lists compute.
+func (s *Service) invoiceSettledAmountTx(ctx context.Context, tx *gorm.DB, orgID, invoiceID snowflake.ID, currency string) (int64, error) {
+	var settled int64
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT COALESCE(SUM(CASE l.direction WHEN 'credit' THEN l.amount ELSE -l.amount END), 0)
+		 FROM ledger_entries le
+		 JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
+		 JOIN ledger_accounts a ON a.id = l.account_id
+		 LEFT JOIN payment_events pe ON pe.id = le.source_id
+		 LEFT JOIN payment_allocations pa ON pa.payment_event_id = pe.id
+		 LEFT JOIN credit_notes cn ON cn.id = le.source_id
+		 WHERE le.org_id = ?
+		   AND UPPER(le.currency) = ?
+		   AND le.source_type IN (?, ?)
+		   AND a.code = ?
+		   AND COALESCE(pe.payload #>> '{data,object,metadata,invoice_id}', pa.invoice_id::text, cn.invoice_id::text) = ?`,
+		orgID,
+		strings.ToUpper(strings.TrimSpace(currency)),
+		string(ledgerdomain.SourceTypePayment), string(ledgerdomain.SourceTypeCreditNote),
+		string(ledgerdomain.AccountCodeAccountsReceivable),
+		invoiceID.String(),
+	).Scan(&settled).Error; err != nil {
+		return 0, err
+	}
+	return settled, nil
 }
 
 func (s *Service) markPaymentFailed(ctx context.Context, orgID snowflake.ID, event *paymentdomain.PaymentEvent) error {
@@ -664,6 +721,36 @@ func (s *Service) writeAuditLog(ctx context.Context, action string, stored *paym
 	if stored == nil || event == nil {
 		return paymentdomain.ErrInvalidEvent
 	}
+
+	targetID := stored.ID.String()
+	orgID := stored.OrgID
+	metadata := s.auditMetadata(ctx, s.db, stored, event, extra)
+	if err := s.auditSvc.AuditLog(ctx, &orgID, "", nil, action, "payment_event", &targetID, metadata); err != nil {
+		s.log.Warn("failed to write payment audit log", zap.String("action", action), zap.Error(err))
+		return nil
+	}
+	return nil
+}
+
+// writeAuditLogTx writes the payment audit entry inside tx. A failed write is
+// returned, so the payment it records rolls back with it and a retry can
+// record both.
+func (s *Service) writeAuditLogTx(ctx context.Context, tx *gorm.DB, action string, stored *paymentdomain.EventRecord, event *paymentdomain.PaymentEvent, extra map[string]any) error {
+	if s.auditSvc == nil {
+		s.log.Warn("audit service unavailable for payment event", zap.String("action", action))
+		return nil
+	}
+	if stored == nil || event == nil {
+		return paymentdomain.ErrInvalidEvent
+	}
+
+	targetID := stored.ID.String()
+	orgID := stored.OrgID
+	metadata := s.auditMetadata(ctx, tx, stored, event, extra)
+	return s.auditSvc.AuditLogTx(ctx, tx, &orgID, "", nil, action, "payment_event", &targetID, metadata)
+}
+
+func (s *Service) auditMetadata(ctx context.Context, db *gorm.DB, stored *paymentdomain.EventRecord, event *paymentdomain.PaymentEvent, extra map[string]any) map[string]any {
 	metadata := map[string]any{
 		"provider":          stored.Provider,
 		"provider_event_id": stored.ProviderEventID,
@@ -678,7 +765,7 @@ func (s *Service) writeAuditLog(ctx context.Context, action string, stored *paym
 	if event.InvoiceID != nil && *event.InvoiceID != 0 {
 		metadata["invoice_id"] = event.InvoiceID.String()
 	}
-	if name := s.loadCustomerName(ctx, stored.OrgID, stored.CustomerID); name != "" {
+	if name := s.loadCustomerName(ctx, db, stored.OrgID, stored.CustomerID); name != "" {
 		metadata["customer_name"] = name
 	}
 	for key, value := range extra {
@@ -687,19 +774,12 @@ func (s *Service) writeAuditLog(ctx context.Context, action string, stored *paym
 		}
 		metadata[key] = value
 	}
-
-	targetID := stored.ID.String()
-	orgID := stored.OrgID
-	if err := s.auditSvc.AuditLog(ctx, &orgID, "", nil, action, "payment_event", &targetID, metadata); err != nil {
-		s.log.Warn("failed to write payment audit log", zap.String("action", action), zap.Error(err))
-		return nil
-	}
-	return nil
+	return metadata
 }
 
-func (s *Service) loadCustomerName(ctx context.Context, orgID snowflake.ID, customerID snowflake.ID) string {
+func (s *Service) loadCustomerName(ctx context.Context, db *gorm.DB, orgID snowflake.ID, customerID snowflake.ID) string {
 	var name string
-	if err := s.db.WithContext(ctx).Raw(
+	if err := db.WithContext(ctx).Raw(
 		`SELECT name
 		 FROM customers
 		 WHERE org_id = ? AND id = ?`,
@@ -45,6 +45,10 @@ func (noopAuditService) AuditLog(ctx context.Context, orgID *snowflake.ID, actor
 	return nil
 }
 
+func (a noopAuditService) AuditLogTx(ctx context.Context, _ *gorm.DB, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
+	return a.AuditLog(ctx, orgID, actorType, actorID, action, targetType, targetID, metadata)
+}
+
 func (noopAuditService) List(ctx context.Context, req auditdomain.ListAuditLogRequest) (auditdomain.ListAuditLogResponse, error) {
 	return auditdomain.ListAuditLogResponse{}, nil
 }
@@ -278,7 +282,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			received_at TIMESTAMPTZ NOT NULL,
 			processed_at TIMESTAMPTZ
 		)`,
-		`CREATE UNIQUE INDEX ux_payment_events_provider_event_id ON payment_events(provider, provider_event_id)`,
+		`CREATE UNIQUE INDEX ux_payment_events_org_provider_event_id ON payment_events(org_id, provider, provider_event_id)`,
 		`CREATE TABLE payment_disputes (
 			id BIGINT PRIMARY KEY,
 			org_id BIGINT NOT NULL,
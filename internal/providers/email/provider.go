@@ -14,6 +14,7 @@ type EmailMessage struct {
 	ReplyTo     string
 	Subject     string
 	HTMLBody    string // For Send()
+	MessageID   string // Optional, sent as the Message-ID header so delivery callbacks can be matched
 	Attachments []Attachment
 }
 
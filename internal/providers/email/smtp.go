@@ -67,6 +67,9 @@ func (p *SMTPProvider) Send(ctx context.Context, msg EmailMessage) error {
 	}
 	body.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(msg.To, ",")))
 	body.WriteString(fmt.Sprintf("Subject: %s\r\n", msg.Subject))
+	if msg.MessageID != "" {
+		body.WriteString(fmt.Sprintf("Message-ID: <%s>\r\n", msg.MessageID))
+	}
 	body.WriteString("MIME-Version: 1.0\r\n")
 	body.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\r\n", boundary))
 	body.WriteString("\r\n")
@@ -16,7 +16,14 @@ import (
 	"github.com/johnfercher/maroto/v2/pkg/props"
 )
 
+// LayoutSummary renders an invoice with its totals only; any other layout
+// lists every line item.
+const LayoutSummary = "summary"
+
 type InvoiceData struct {
+	// Layout picks the page layout, see LayoutSummary.
+	Layout string
+
 	OrgName       string
 	OrgAddress    string
 	OrgEmail      string
@@ -134,26 +141,32 @@ func (p *PDFProvider) GenerateInvoice(ctx context.Context, data interface{}) (io
 		}),
 	)
 
-	// Table Header
-	m.AddRow(10,
-		text.NewCol(6, "Description", props.Text{Style: fontstyle.Bold, Size: 9}),
-		text.NewCol(2, "Qty", props.Text{Style: fontstyle.Bold, Size: 9, Align: align.Right}),
-		text.NewCol(2, "Unit price", props.Text{Style: fontstyle.Bold, Size: 9, Align: align.Right}),
-		text.NewCol(2, "Amount", props.Text{Style: fontstyle.Bold, Size: 9, Align: align.Right}),
-	)
-
-	m.AddRow(1, col.New(12).Add(
-	// Line
-	))
-
-	// Items
-	for _, item := range invoice.Items {
-		m.AddRow(15,
-			text.NewCol(6, item.Description, props.Text{Size: 9}),
-			text.NewCol(2, fmt.Sprintf("%d", item.Qty), props.Text{Size: 9, Align: align.Right}),
-			text.NewCol(2, item.UnitPrice, props.Text{Size: 9, Align: align.Right}),
-			text.NewCol(2, item.Amount, props.Text{Size: 9, Align: align.Right}),
+	if invoice.Layout == LayoutSummary {
+		m.AddRow(10,
+			text.NewCol(12, fmt.Sprintf("%d line items", len(invoice.Items)), props.Text{Size: 9}),
+		)
+	} else {
+		// Table Header
+		m.AddRow(10,
+			text.NewCol(6, "Description", props.Text{Style: fontstyle.Bold, Size: 9}),
+			text.NewCol(2, "Qty", props.Text{Style: fontstyle.Bold, Size: 9, Align: align.Right}),
+			text.NewCol(2, "Unit price", props.Text{Style: fontstyle.Bold, Size: 9, Align: align.Right}),
+			text.NewCol(2, "Amount", props.Text{Style: fontstyle.Bold, Size: 9, Align: align.Right}),
 		)
+
+		m.AddRow(1, col.New(12).Add(
+		// Line
+		))
+
+		// Items
+		for _, item := range invoice.Items {
+			m.AddRow(15,
+				text.NewCol(6, item.Description, props.Text{Size: 9}),
+				text.NewCol(2, fmt.Sprintf("%d", item.Qty), props.Text{Size: 9, Align: align.Right}),
+				text.NewCol(2, item.UnitPrice, props.Text{Size: 9, Align: align.Right}),
+				text.NewCol(2, item.Amount, props.Text{Size: 9, Align: align.Right}),
+			)
+		}
 	}
 
 	// Footer Totals
@@ -153,11 +153,12 @@ func (r *repo) FindInvoiceSettledAmount(
 		JOIN ledger_entry_lines l ON l.ledger_entry_id = le.id
 		JOIN ledger_accounts a ON a.id = l.account_id
 		JOIN payment_events pe ON pe.id = le.source_id
+		LEFT JOIN payment_allocations pa ON pa.payment_event_id = pe.id
 		WHERE le.org_id = ?
 		  AND le.currency = ?
 		  AND le.source_type = ?
 		  AND a.code = ?
-		  AND (pe.payload #>> '{data,object,metadata,invoice_id}') = ?
+		  AND COALESCE(pe.payload #>> '{data,object,metadata,invoice_id}', pa.invoice_id::text) = ?
 		`,
 		orgID,
 		currency,
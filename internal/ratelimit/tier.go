@@ -0,0 +1,42 @@
+package ratelimit
+
+const (
+	TierDefault   = "default"
+	TierCustom    = "custom"
+	TierUnlimited = "unlimited"
+)
+
+// Tier is the rate-limit tier attached to an API key. A zero Tier falls back
+// to the organization-wide usage ingest limits.
+type Tier struct {
+	PerMinute int
+	Unlimited bool
+}
+
+// NewTier builds a tier from the api_keys columns.
+func NewTier(perMinute *int, unlimited bool) Tier {
+	tier := Tier{Unlimited: unlimited}
+	if perMinute != nil && *perMinute > 0 {
+		tier.PerMinute = *perMinute
+	}
+	return tier
+}
+
+// Name returns the low-cardinality label used in metrics.
+func (t Tier) Name() string {
+	switch {
+	case t.Unlimited:
+		return TierUnlimited
+	case t.PerMinute > 0:
+		return TierCustom
+	default:
+		return TierDefault
+	}
+}
+
+// bucket converts a per-minute limit into token bucket parameters. The burst
+// allows a full minute of requests so batch uploads are not penalized for
+// arriving at once.
+func (t Tier) bucket() (float64, int) {
+	return float64(t.PerMinute) / 60, t.PerMinute
+}
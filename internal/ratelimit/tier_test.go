@@ -0,0 +1,43 @@
+package ratelimit
+
+import "testing"
+
+func TestTier(t *testing.T) {
+	perMinute := 600
+	zero := 0
+
+	cases := []struct {
+		name      string
+		tier      Tier
+		want      string
+		wantRate  float64
+		wantBurst int
+	}{
+		{name: "no tier", tier: NewTier(nil, false), want: TierDefault},
+		{name: "non-positive limit", tier: NewTier(&zero, false), want: TierDefault},
+		{name: "custom", tier: NewTier(&perMinute, false), want: TierCustom, wantRate: 10, wantBurst: 600},
+		{name: "unlimited wins", tier: NewTier(&perMinute, true), want: TierUnlimited, wantRate: 10, wantBurst: 600},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.tier.Name(); got != tc.want {
+				t.Fatalf("expected tier %q, got %q", tc.want, got)
+			}
+			rate, burst := tc.tier.bucket()
+			if rate != tc.wantRate || burst != tc.wantBurst {
+				t.Fatalf("expected bucket %v/%d, got %v/%d", tc.wantRate, tc.wantBurst, rate, burst)
+			}
+		})
+	}
+}
+
+func TestAllowAPIKeyBypassesUnlimited(t *testing.T) {
+	// A nil bucket would panic if the limiter tried to consult redis.
+	limiter := &UsageIngestLimiter{enabled: true}
+	perMinute := 60
+
+	result, err := limiter.AllowAPIKey(t.Context(), "1", NewTier(&perMinute, true), 10)
+	if err != nil || !result.Allowed {
+		t.Fatalf("expected unlimited key to be allowed, got %+v, %v", result, err)
+	}
+}
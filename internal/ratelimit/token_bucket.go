@@ -13,6 +13,7 @@ const tokenBucketScript = `
 local rate = tonumber(ARGV[1])
 local burst = tonumber(ARGV[2])
 local ttl = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
 
 local nowData = redis.call("TIME")
 local now = (nowData[1] * 1000) + math.floor(nowData[2] / 1000)
@@ -35,9 +36,9 @@ else
 end
 
 local allowed = 0
-if tokens >= 1 then
+if tokens >= cost then
   allowed = 1
-  tokens = tokens - 1
+  tokens = tokens - cost
 end
 
 redis.call("HMSET", KEYS[1], "tokens", tokens, "ts", ts)
@@ -71,6 +72,20 @@ func NewTokenBucket(client *redis.Client) *TokenBucket {
 }
 
 func (t *TokenBucket) Allow(ctx context.Context, key string, rate float64, burst int) (*RateLimitResult, error) {
+	return t.AllowN(ctx, key, rate, burst, 1)
+}
+
+// AllowN takes n tokens at once, for a request that carries n units of work.
+// It is denied, taking nothing, unless all n tokens are available. n above
+// burst is charged burst, so an oversized request waits for a full bucket
+// and drains it instead of being denied forever.
+func (t *TokenBucket) AllowN(ctx context.Context, key string, rate float64, burst int, n int) (*RateLimitResult, error) {
+	if n < 1 {
+		n = 1
+	}
+	if burst > 0 && n > burst {
+		n = burst
+	}
 	if t == nil || t.client == nil {
 		return &RateLimitResult{Allowed: false}, errors.New("rate limiter not configured")
 	}
@@ -94,6 +109,7 @@ func (t *TokenBucket) Allow(ctx context.Context, key string, rate float64, burst
 		rate,
 		burst,
 		int64(ttl/time.Millisecond),
+		n,
 	).Slice()
 	
 	if err != nil {
@@ -136,8 +152,8 @@ func (t *TokenBucket) Allow(ctx context.Context, key string, rate float64, burst
 	
 	retryAfter := time.Duration(0)
 	if !allowed {
-		// Calculate time to refill 1 token: (1 - tokens) / rate
-		needed := 1.0 - remainingTokens
+		// Calculate time to refill n tokens: (n - tokens) / rate
+		needed := float64(n) - remainingTokens
 		if needed > 0 {
 			seconds := needed / rate
 			retryAfter = time.Duration(seconds * float64(time.Second))
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// TestTokenBucketAllowNAboveBurst runs the bucket script against the Redis
+// at RATELIMIT_TEST_REDIS_ADDR: a batch larger than the bucket must still be
+// admitted once the bucket is full, and then hold back further requests.
+func TestTokenBucketAllowNAboveBurst(t *testing.T) {
+	addr := strings.TrimSpace(os.Getenv("RATELIMIT_TEST_REDIS_ADDR"))
+	if addr == "" {
+		t.Skip("RATELIMIT_TEST_REDIS_ADDR not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := context.Background()
+	key := fmt.Sprintf("test:token_bucket:%d", time.Now().UnixNano())
+	t.Cleanup(func() { client.Del(ctx, key) })
+
+	bucket := NewTokenBucket(client)
+	const rate, burst = 1.0, 30
+
+	// 500 events, the largest batch /usage/batch accepts.
+	result, err := bucket.AllowN(ctx, key, rate, burst, 500)
+	if err != nil {
+		t.Fatalf("AllowN: %v", err)
+	}
+	if !result.Allowed {
+		t.Fatalf("batch above burst denied on a full bucket")
+	}
+	if result.Remaining != 0 {
+		t.Fatalf("remaining = %d, want the bucket drained", result.Remaining)
+	}
+
+	result, err = bucket.AllowN(ctx, key, rate, burst, 500)
+	if err != nil {
+		t.Fatalf("AllowN: %v", err)
+	}
+	if result.Allowed {
+		t.Fatalf("second batch allowed on a drained bucket")
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > burst*time.Second {
+		t.Fatalf("retry after = %s, want within the time to refill %d tokens", result.RetryAfter, burst)
+	}
+
+	result, err = bucket.AllowN(ctx, key, rate, burst, 1)
+	if err != nil {
+		t.Fatalf("AllowN: %v", err)
+	}
+	if result.Allowed {
+		t.Fatalf("single event allowed on a drained bucket")
+	}
+}
@@ -15,6 +15,7 @@ const (
 	keyUsageIngestOrg      = "usage:ingest:org:%s"
 	keyUsageIngestEndpoint = "usage:ingest:endpoint:%s"
 	keyUsageIngestLock     = "usage:ingest:lock:%s:%s:%s"
+	keyUsageIngestAPIKey   = "usage:ingest:apikey:%s"
 )
 
 type UsageIngestLimiter struct {
@@ -69,18 +70,32 @@ func (l *UsageIngestLimiter) Enabled() bool {
 	return l != nil && l.enabled
 }
 
-func (l *UsageIngestLimiter) AllowOrg(ctx context.Context, orgID string) (*RateLimitResult, error) {
+// AllowOrg, AllowEndpoint and AllowAPIKey each charge events tokens, one per
+// usage event in the request, so a batch costs as much as its events sent
+// one by one.
+func (l *UsageIngestLimiter) AllowOrg(ctx context.Context, orgID string, events int) (*RateLimitResult, error) {
 	if !l.Enabled() {
 		return &RateLimitResult{Allowed: true}, nil
 	}
-	return l.bucket.Allow(ctx, fmt.Sprintf(keyUsageIngestOrg, strings.TrimSpace(orgID)), l.orgRate, l.orgBurst)
+	return l.bucket.AllowN(ctx, fmt.Sprintf(keyUsageIngestOrg, strings.TrimSpace(orgID)), l.orgRate, l.orgBurst, events)
 }
 
-func (l *UsageIngestLimiter) AllowEndpoint(ctx context.Context, orgID string) (*RateLimitResult, error) {
+func (l *UsageIngestLimiter) AllowEndpoint(ctx context.Context, orgID string, events int) (*RateLimitResult, error) {
 	if !l.Enabled() {
 		return &RateLimitResult{Allowed: true}, nil
 	}
-	return l.bucket.Allow(ctx, fmt.Sprintf(keyUsageIngestEndpoint, strings.TrimSpace(orgID)), l.endpointRate, l.endpointBurst)
+	return l.bucket.AllowN(ctx, fmt.Sprintf(keyUsageIngestEndpoint, strings.TrimSpace(orgID)), l.endpointRate, l.endpointBurst, events)
+}
+
+// AllowAPIKey applies a key's custom tier in place of the org and endpoint
+// limits. Unlimited keys are always allowed; default-tier keys must go
+// through AllowOrg and AllowEndpoint instead.
+func (l *UsageIngestLimiter) AllowAPIKey(ctx context.Context, apiKeyID string, tier Tier, events int) (*RateLimitResult, error) {
+	if !l.Enabled() || tier.Unlimited || tier.PerMinute <= 0 {
+		return &RateLimitResult{Allowed: true}, nil
+	}
+	rate, burst := tier.bucket()
+	return l.bucket.AllowN(ctx, fmt.Sprintf(keyUsageIngestAPIKey, strings.TrimSpace(apiKeyID)), rate, burst, events)
 }
 
 func (l *UsageIngestLimiter) TryLockCustomerMeter(ctx context.Context, orgID, customerID, meterCode string) (string, bool, error) {
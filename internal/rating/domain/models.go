@@ -29,3 +29,8 @@ type RatingResult struct {
 
 // TableName sets the database table name.
 func (RatingResult) TableName() string { return "rating_results" }
+
+// SourceCarryForward marks a rating result that charges an amount carried
+// from an earlier cycle of the subscription, which was below the org's
+// minimum invoice amount.
+const SourceCarryForward = "carry_forward"
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
+	usagedomain "github.com/smallbiznis/railzway/internal/usage/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestAggregateUsage_ByMeterAggregation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&meterdomain.Meter{}, &usagedomain.UsageEvent{}))
+	// The ingestion dedup index from the usage migrations.
+	require.NoError(t, db.Exec(`CREATE UNIQUE INDEX idx_usage_events_idempotency ON usage_events (org_id, idempotency_key) WHERE idempotency_key IS NOT NULL`).Error)
+
+	node, _ := snowflake.NewNode(1)
+	svc := &Service{db: db, log: zap.NewNop(), genID: node}
+
+	orgID := node.Generate()
+	subID := node.Generate()
+	periodStart := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	newMeter := func(code, aggregation string) snowflake.ID {
+		meter := meterdomain.Meter{
+			ID:          node.Generate(),
+			OrgID:       orgID,
+			Code:        code,
+			Name:        code,
+			Aggregation: aggregation,
+			Unit:        "unit",
+			Active:      true,
+		}
+		require.NoError(t, db.Create(&meter).Error)
+		return meter.ID
+	}
+	ingest := func(meterID snowflake.ID, value float64, at time.Time, status, idempotencyKey string) error {
+		return db.Create(&usagedomain.UsageEvent{
+			ID:             node.Generate(),
+			OrgID:          orgID,
+			CustomerID:     node.Generate(),
+			SubscriptionID: subID,
+			MeterID:        meterID,
+			MeterCode:      "meter",
+			Value:          value,
+			RecordedAt:     at,
+			Status:         status,
+			IdempotencyKey: idempotencyKey,
+		}).Error
+	}
+	record := func(meterID snowflake.ID, value float64, at time.Time, status string) {
+		require.NoError(t, ingest(meterID, value, at, status, node.Generate().String()))
+	}
+	seed := func(meterID snowflake.ID) {
+		record(meterID, 5, periodStart.Add(24*time.Hour), usagedomain.UsageStatusEnriched)
+		record(meterID, 12, periodStart.Add(48*time.Hour), usagedomain.UsageStatusEnriched)
+		record(meterID, 7, periodStart.Add(72*time.Hour), usagedomain.UsageStatusEnriched)
+		// Outside the window or not yet enriched: never counted.
+		record(meterID, 100, periodEnd.Add(time.Hour), usagedomain.UsageStatusEnriched)
+		record(meterID, 50, periodStart.Add(96*time.Hour), usagedomain.UsageStatusAccepted)
+	}
+
+	cases := []struct {
+		aggregation string
+		want        float64
+	}{
+		{aggregation: meterdomain.AggregationSum, want: 24},
+		{aggregation: meterdomain.AggregationMax, want: 12},
+		{aggregation: meterdomain.AggregationLast, want: 7},
+		{aggregation: "last", want: 7},
+		{aggregation: "legacy", want: 24},
+	}
+	for _, tc := range cases {
+		t.Run(tc.aggregation, func(t *testing.T) {
+			meterID := newMeter("meter_"+tc.aggregation, tc.aggregation)
+			seed(meterID)
+
+			qty, err := svc.aggregateUsage(db, orgID, subID, meterID, periodStart, periodEnd)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, qty)
+		})
+	}
+
+	t.Run("empty window", func(t *testing.T) {
+		for _, aggregation := range []string{meterdomain.AggregationMax, meterdomain.AggregationLast} {
+			meterID := newMeter("empty_"+aggregation, aggregation)
+			qty, err := svc.aggregateUsage(db, orgID, subID, meterID, periodStart, periodEnd)
+			require.NoError(t, err)
+			assert.Zero(t, qty)
+		}
+	})
+
+	t.Run("replayed reading is deduplicated", func(t *testing.T) {
+		cases := []struct {
+			aggregation string
+			want        float64
+		}{
+			{aggregation: meterdomain.AggregationSum, want: 33},
+			{aggregation: meterdomain.AggregationMax, want: 12},
+			{aggregation: meterdomain.AggregationLast, want: 9},
+		}
+		for _, tc := range cases {
+			meterID := newMeter("replay_"+tc.aggregation, tc.aggregation)
+			seed(meterID)
+			key := node.Generate().String()
+			at := periodStart.Add(96 * time.Hour)
+			require.NoError(t, ingest(meterID, 9, at, usagedomain.UsageStatusEnriched, key))
+			// A retried ingestion carries the idempotency key of the reading
+			// it repeats and is rejected, so it is never rated twice.
+			require.Error(t, ingest(meterID, 9, at.Add(time.Minute), usagedomain.UsageStatusEnriched, key))
+
+			var stored int64
+			require.NoError(t, db.Model(&usagedomain.UsageEvent{}).Where("meter_id = ? AND idempotency_key = ?", meterID, key).Count(&stored).Error)
+			assert.Equal(t, int64(1), stored)
+
+			qty, err := svc.aggregateUsage(db, orgID, subID, meterID, periodStart, periodEnd)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, qty, tc.aggregation)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	ratingdomain "github.com/smallbiznis/railzway/internal/rating/domain"
+	"gorm.io/gorm"
+)
+
+// applyCarryForwards charges the subscription's amounts carried from earlier
+// cycles on cycle, one rating result each, and records cycle as where they
+// were applied. Amounts already applied to cycle are charged again because
+// rerating replaces the cycle's results.
+func (s *Service) applyCarryForwards(ctx context.Context, tx *gorm.DB, cycle *billingCycleRow, now time.Time) error {
+	var pending []struct {
+		ID       snowflake.ID
+		Currency string
+		Amount   int64
+	}
+	if err := tx.WithContext(ctx).Raw(
+		`SELECT cf.id, cf.currency, cf.amount
+		 FROM invoice_carry_forwards cf
+		 JOIN billing_cycles bc ON bc.id = cf.source_billing_cycle_id
+		 WHERE cf.org_id = ?
+		   AND cf.subscription_id = ?
+		   AND (cf.applied_billing_cycle_id IS NULL OR cf.applied_billing_cycle_id = ?)
+		   AND bc.period_end <= ?
+		 ORDER BY cf.created_at ASC, cf.id ASC`,
+		cycle.OrgID,
+		cycle.SubscriptionID,
+		cycle.ID,
+		cycle.PeriodStart,
+	).Scan(&pending).Error; err != nil {
+		return err
+	}
+
+	for _, carried := range pending {
+		sum := sha256.Sum256([]byte(ratingdomain.SourceCarryForward + "|" + carried.ID.String() + "|" + cycle.ID.String()))
+		if err := s.insertRatingResult(tx, ratingdomain.RatingResult{
+			ID:             s.genID.Generate(),
+			OrgID:          cycle.OrgID,
+			SubscriptionID: cycle.SubscriptionID,
+			BillingCycleID: cycle.ID,
+			Quantity:       1,
+			UnitPrice:      carried.Amount,
+			Amount:         carried.Amount,
+			Currency:       carried.Currency,
+			PeriodStart:    cycle.PeriodStart,
+			PeriodEnd:      cycle.PeriodEnd,
+			Source:         ratingdomain.SourceCarryForward,
+			Checksum:       hex.EncodeToString(sum[:]),
+			CreatedAt:      now,
+		}); err != nil {
+			return err
+		}
+		if err := tx.WithContext(ctx).Exec(
+			`UPDATE invoice_carry_forwards
+			 SET applied_billing_cycle_id = ?,
+			     applied_at = COALESCE(applied_at, ?)
+			 WHERE id = ?`,
+			cycle.ID,
+			now,
+			carried.ID,
+		).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/bwmarrin/snowflake"
 	"github.com/glebarez/sqlite"
 	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
 	pricedomain "github.com/smallbiznis/railzway/internal/price/domain"
 	priceamountdomain "github.com/smallbiznis/railzway/internal/priceamount/domain"
 	ratingdomain "github.com/smallbiznis/railzway/internal/rating/domain"
@@ -29,10 +30,12 @@ func TestRating_Deterministic_Idempotency(t *testing.T) {
 		&ratingdomain.RatingResult{},
 		// &usagedomain.UsageEvent{}, // The service queries usage_events table
 		&subscriptiondomain.SubscriptionItem{},
+		&subscriptiondomain.SubscriptionItemChange{},
 		&subscriptiondomain.Subscription{},
 		&subscriptiondomain.SubscriptionEntitlement{},
 		&billingcycledomain.BillingCycle{},
 		&pricedomain.Price{},
+		&invoicedomain.CarryForward{},
 		// PriceAmount table not strictly needed if we stub repo, but good for consistency
 	)
 	assert.NoError(t, err)
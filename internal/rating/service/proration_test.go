@@ -8,6 +8,8 @@ import (
 	"github.com/bwmarrin/snowflake"
 	"github.com/glebarez/sqlite"
 	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
 	pricedomain "github.com/smallbiznis/railzway/internal/price/domain"
 	priceamountdomain "github.com/smallbiznis/railzway/internal/priceamount/domain"
 	ratingdomain "github.com/smallbiznis/railzway/internal/rating/domain"
@@ -275,6 +277,108 @@ func TestProration_PlanChangeMidCycle(t *testing.T) {
 	assert.NotEqual(t, rowA.Checksum, rowB.Checksum)
 }
 
+// TestProration_QuantityChangeMidCycle validates that an item change
+// recorded halfway through the cycle splits a per-unit fee by quantity
+func TestProration_QuantityChangeMidCycle(t *testing.T) {
+	db, svc, node := setupProrationTest(t)
+
+	orgID := node.Generate()
+	subID := node.Generate()
+	cycleID := node.Generate()
+	productID := node.Generate()
+	priceID := node.Generate()
+
+	cycleStart := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cycleEnd := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	halfway := cycleStart.Add(cycleEnd.Sub(cycleStart) / 2)
+
+	priceAmountStub := svc.(*Service).priceAmountRepo.(*priceAmountStub)
+	priceRepoStub := svc.(*Service).priceRepo.(*priceRepoStub)
+	seedProrationData(t, db, node, priceAmountStub, priceRepoStub, orgID, subID, cycleID, productID, priceID, cycleStart, cycleEnd, cycleStart, nil, 10000)
+	priceRepoStub.Prices[priceID.String()] = pricedomain.Price{
+		ID:           priceID,
+		ProductID:    productID,
+		PricingModel: pricedomain.PerUnit,
+	}
+
+	// Quantity goes from 1 to 2 halfway through the cycle
+	require.NoError(t, db.Create(&[]subscriptiondomain.SubscriptionItemChange{
+		{
+			ID:             node.Generate(),
+			OrgID:          orgID,
+			SubscriptionID: subID,
+			PriceID:        priceID,
+			Quantity:       1,
+			EffectiveFrom:  cycleStart,
+			EffectiveTo:    &halfway,
+		},
+		{
+			ID:             node.Generate(),
+			OrgID:          orgID,
+			SubscriptionID: subID,
+			PriceID:        priceID,
+			Quantity:       2,
+			EffectiveFrom:  halfway,
+		},
+	}).Error)
+
+	err := svc.RunRating(context.Background(), cycleID.String())
+	require.NoError(t, err)
+
+	var results []ratingdomain.RatingResult
+	db.Where("billing_cycle_id = ?", cycleID).Order("period_start").Find(&results)
+	require.Len(t, results, 2, "Item change MUST split the cycle")
+
+	assert.Equal(t, cycleStart, results[0].PeriodStart)
+	assert.Equal(t, halfway, results[0].PeriodEnd)
+	assert.InDelta(t, 0.5, results[0].Quantity, 0.0001)
+	assert.Equal(t, int64(5000), results[0].Amount)
+
+	assert.Equal(t, halfway, results[1].PeriodStart)
+	assert.Equal(t, cycleEnd, results[1].PeriodEnd)
+	assert.InDelta(t, 1.0, results[1].Quantity, 0.0001)
+	assert.Equal(t, int64(10000), results[1].Amount)
+
+	// Time-weighted total: 1.5 x the monthly fee
+	assert.Equal(t, int64(15000), results[0].Amount+results[1].Amount)
+}
+
+// TestProration_FlatFeeIgnoresQuantity validates that a flat price is
+// charged once per window, whatever the item quantity
+func TestProration_FlatFeeIgnoresQuantity(t *testing.T) {
+	db, svc, node := setupProrationTest(t)
+
+	orgID := node.Generate()
+	subID := node.Generate()
+	cycleID := node.Generate()
+	productID := node.Generate()
+	priceID := node.Generate()
+
+	cycleStart := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cycleEnd := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	priceAmountStub := svc.(*Service).priceAmountRepo.(*priceAmountStub)
+	priceRepoStub := svc.(*Service).priceRepo.(*priceRepoStub)
+	seedProrationData(t, db, node, priceAmountStub, priceRepoStub, orgID, subID, cycleID, productID, priceID, cycleStart, cycleEnd, cycleStart, nil, 10000)
+	priceRepoStub.Prices[priceID.String()] = pricedomain.Price{
+		ID:           priceID,
+		ProductID:    productID,
+		PricingModel: pricedomain.Flat,
+	}
+	require.NoError(t, db.Model(&subscriptiondomain.SubscriptionItem{}).
+		Where("subscription_id = ?", subID).
+		Update("quantity", 3).Error)
+
+	err := svc.RunRating(context.Background(), cycleID.String())
+	require.NoError(t, err)
+
+	var results []ratingdomain.RatingResult
+	db.Where("billing_cycle_id = ?", cycleID).Find(&results)
+	require.Len(t, results, 1)
+	assert.InDelta(t, 1.0, results[0].Quantity, 0.0001)
+	assert.Equal(t, int64(10000), results[0].Amount)
+}
+
 // TestProration_MeteredUsageWithWindow validates that metered usage
 // respects the effective window (not full cycle)
 func TestProration_MeteredUsageWithWindow(t *testing.T) {
@@ -388,11 +492,14 @@ func setupProrationTest(t *testing.T) (*gorm.DB, ratingdomain.Service, *snowflak
 	err = db.AutoMigrate(
 		&ratingdomain.RatingResult{},
 		&subscriptiondomain.SubscriptionItem{},
+		&subscriptiondomain.SubscriptionItemChange{},
 		&subscriptiondomain.Subscription{},
 		&subscriptiondomain.SubscriptionEntitlement{},
 		&billingcycledomain.BillingCycle{},
 		&pricedomain.Price{},
+		&invoicedomain.CarryForward{},
 		&usagedomain.UsageEvent{},
+		&meterdomain.Meter{},
 	)
 	require.NoError(t, err)
 
@@ -12,6 +12,7 @@ import (
 
 	"github.com/bwmarrin/snowflake"
 	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
 	pricedomain "github.com/smallbiznis/railzway/internal/price/domain"
 	priceamountdomain "github.com/smallbiznis/railzway/internal/priceamount/domain"
 	ratingdomain "github.com/smallbiznis/railzway/internal/rating/domain"
@@ -86,6 +87,15 @@ func (s *Service) RunRating(ctx context.Context, billingCycleID string) error {
 	if err != nil {
 		return err
 	}
+	// Recorded item changes replace the current items for cycles they
+	// overlap, so every version is charged for its own window.
+	changes, err := s.listItemChanges(ctx, cycle.OrgID, cycle.SubscriptionID, cycle.PeriodStart, cycle.PeriodEnd)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		items = changes
+	}
 	if len(items) == 0 {
 		return ratingdomain.ErrNoSubscriptionItems
 	}
@@ -122,6 +132,7 @@ func (s *Service) RunRating(ctx context.Context, billingCycleID string) error {
 			// 1. Billing Cycle [Start, End]
 			// 2. Subscription [StartAt, EndAt/CanceledAt]
 			// 3. Entitlement [EffectiveFrom, EffectiveTo] (Plan Change)
+			// 4. Item version [EffectiveFrom, EffectiveTo] (Item Change)
 
 			start := cycle.PeriodStart
 			if subscription.StartAt.After(start) {
@@ -130,6 +141,9 @@ func (s *Service) RunRating(ctx context.Context, billingCycleID string) error {
 			if ent != nil && ent.EffectiveFrom.After(start) {
 				start = ent.EffectiveFrom
 			}
+			if item.EffectiveFrom != nil && item.EffectiveFrom.After(start) {
+				start = *item.EffectiveFrom
+			}
 
 			end := cycle.PeriodEnd
 			if subscription.EndedAt != nil && subscription.EndedAt.Before(end) {
@@ -141,6 +155,9 @@ func (s *Service) RunRating(ctx context.Context, billingCycleID string) error {
 			if ent != nil && ent.EffectiveTo != nil && ent.EffectiveTo.Before(end) {
 				end = *ent.EffectiveTo
 			}
+			if item.EffectiveTo != nil && item.EffectiveTo.Before(end) {
+				end = *item.EffectiveTo
+			}
 
 			if !end.After(start) {
 				// Item not active in this window intersection
@@ -163,13 +180,13 @@ func (s *Service) RunRating(ctx context.Context, billingCycleID string) error {
 			// Pass 'start' and 'end' as the RATING WINDOW for this item
 
 			if item.MeterID == nil {
-				if err := s.rateFlatItem(ctx, tx, cycle, item, featureCode, start, end, prorationFactor, now); err != nil {
+				if err := s.rateFlatItem(ctx, tx, cycle, item, subscription.Currency(), featureCode, start, end, prorationFactor, now); err != nil {
 					return err
 				}
 				continue
 			}
 
-			windows, err := s.buildPriceWindows(ctx, tx, cycle.OrgID, item.PriceID, item.MeterID, start, end)
+			windows, err := s.buildPriceWindows(ctx, tx, cycle.OrgID, item.PriceID, item.MeterID, subscription.Currency(), start, end)
 			if err != nil {
 				return err
 			}
@@ -194,7 +211,7 @@ func (s *Service) RunRating(ctx context.Context, billingCycleID string) error {
 			}
 		}
 
-		return nil
+		return s.applyCarryForwards(ctx, tx, cycle, now)
 	})
 }
 
@@ -224,7 +241,7 @@ func (s *Service) resolveEntitlementWithWindow(
 	// Metered Usage
 	if item.MeterID != nil {
 		for _, ent := range entitlements {
-			if ent.MeterID != nil && *ent.MeterID == *item.MeterID {
+			if ent.MeterID != nil && *ent.MeterID == *item.MeterID && item.overlaps(ent) {
 				return ent.FeatureCode, &ent, nil
 			}
 		}
@@ -246,7 +263,7 @@ func (s *Service) resolveEntitlementWithWindow(
 	}
 
 	for _, ent := range entitlements {
-		if ent.ProductID == price.ProductID {
+		if ent.ProductID == price.ProductID && item.overlaps(ent) {
 			return ent.FeatureCode, &ent, nil
 		}
 	}
@@ -264,12 +281,39 @@ type billingCycleRow struct {
 	Status         billingcycledomain.BillingCycleStatus
 }
 
+// subscriptionItemRow is a current subscription item, or a recorded item
+// version when EffectiveFrom is set.
 type subscriptionItemRow struct {
 	ID             snowflake.ID
 	OrgID          snowflake.ID
 	SubscriptionID snowflake.ID
 	PriceID        snowflake.ID
 	MeterID        *snowflake.ID
+	Quantity       int8
+	EffectiveFrom  *time.Time
+	EffectiveTo    *time.Time
+}
+
+// overlaps reports whether the entitlement is effective during the item's
+// window. Current items have no window and match any entitlement.
+func (item subscriptionItemRow) overlaps(ent subscriptiondomain.SubscriptionEntitlement) bool {
+	if item.EffectiveTo != nil && !ent.EffectiveFrom.Before(*item.EffectiveTo) {
+		return false
+	}
+	if item.EffectiveFrom != nil && ent.EffectiveTo != nil && !ent.EffectiveTo.After(*item.EffectiveFrom) {
+		return false
+	}
+	return true
+}
+
+// units is the billed quantity of a flat item. Only per-unit prices are
+// charged by quantity; a flat fee is charged once whatever the quantity.
+// Items saved before quantity was enforced count as one.
+func (item subscriptionItemRow) units(model pricedomain.PricingModel) float64 {
+	if model != pricedomain.PerUnit || item.Quantity < 1 {
+		return 1
+	}
+	return float64(item.Quantity)
 }
 
 func (s *Service) loadBillingCycle(ctx context.Context, id snowflake.ID) (*billingCycleRow, error) {
@@ -292,7 +336,7 @@ func (s *Service) loadBillingCycle(ctx context.Context, id snowflake.ID) (*billi
 func (s *Service) listSubscriptionItems(ctx context.Context, orgID, subscriptionID snowflake.ID) ([]subscriptionItemRow, error) {
 	var items []subscriptionItemRow
 	err := s.db.WithContext(ctx).Raw(
-		`SELECT id, org_id, subscription_id, price_id, meter_id
+		`SELECT id, org_id, subscription_id, price_id, meter_id, quantity
 		 FROM subscription_items
 		 WHERE org_id = ? AND subscription_id = ?`,
 		orgID,
@@ -304,6 +348,27 @@ func (s *Service) listSubscriptionItems(ctx context.Context, orgID, subscription
 	return items, nil
 }
 
+// listItemChanges returns the item versions effective during [start, end).
+func (s *Service) listItemChanges(ctx context.Context, orgID, subscriptionID snowflake.ID, start, end time.Time) ([]subscriptionItemRow, error) {
+	var items []subscriptionItemRow
+	err := s.db.WithContext(ctx).Raw(
+		`SELECT id, org_id, subscription_id, price_id, meter_id, quantity, effective_from, effective_to
+		 FROM subscription_item_changes
+		 WHERE org_id = ? AND subscription_id = ?
+		 AND effective_from < ?
+		 AND (effective_to IS NULL OR effective_to > ?)
+		 ORDER BY effective_from ASC, id ASC`,
+		orgID,
+		subscriptionID,
+		end,
+		start,
+	).Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 func (s *Service) loadSubscription(ctx context.Context, orgID, subscriptionID snowflake.ID) (*subscriptiondomain.Subscription, error) {
 	var sub subscriptiondomain.Subscription
 	err := s.db.WithContext(ctx).Model(&subscriptiondomain.Subscription{}).
@@ -318,26 +383,61 @@ func (s *Service) loadSubscription(ctx context.Context, orgID, subscriptionID sn
 	return &sub, nil
 }
 
+// aggregateUsage reduces the enriched usage events of a meter inside
+// [periodStart, periodEnd) to a billable quantity using the meter's
+// aggregation type. Duplicate ingestion is rejected upstream by the
+// idempotency key index, so replays cannot inflate any aggregate.
 func (s *Service) aggregateUsage(tx *gorm.DB, orgID, subscriptionID, meterID snowflake.ID, periodStart, periodEnd time.Time) (float64, error) {
-	var quantity float64
-	err := tx.Raw(
-		`SELECT COALESCE(SUM(value), 0)
-		 FROM usage_events
+	aggregation, err := s.meterAggregation(tx, orgID, meterID)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := `FROM usage_events
 		 WHERE org_id = ? AND subscription_id = ? AND meter_id = ?
-		 AND recorded_at >= ? AND recorded_at < ? AND status = ?`,
+		 AND recorded_at >= ? AND recorded_at < ? AND status = ?`
+	args := []any{
 		orgID,
 		subscriptionID,
 		meterID,
 		periodStart,
 		periodEnd,
 		usagedomain.UsageStatusEnriched,
-	).Scan(&quantity).Error
-	if err != nil {
+	}
+
+	var query string
+	switch aggregation {
+	case meterdomain.AggregationMax:
+		query = `SELECT COALESCE(MAX(value), 0) ` + filter
+	case meterdomain.AggregationLast:
+		query = `SELECT COALESCE((SELECT value ` + filter + `
+		 ORDER BY recorded_at DESC, id DESC
+		 LIMIT 1), 0)`
+	default:
+		query = `SELECT COALESCE(SUM(value), 0) ` + filter
+	}
+
+	var quantity float64
+	if err := tx.Raw(query, args...).Scan(&quantity).Error; err != nil {
 		return 0, err
 	}
 	return quantity, nil
 }
 
+// meterAggregation returns the normalized aggregation type of a meter. An
+// empty result (missing meter) is rated as SUM by the caller.
+func (s *Service) meterAggregation(tx *gorm.DB, orgID, meterID snowflake.ID) (string, error) {
+	var raw string
+	if err := tx.Raw(
+		`SELECT aggregation FROM meters WHERE org_id = ? AND id = ?`,
+		orgID,
+		meterID,
+	).Scan(&raw).Error; err != nil {
+		return "", err
+	}
+	return meterdomain.NormalizeAggregation(raw), nil
+}
+
 type priceWindow struct {
 	Start  time.Time
 	End    time.Time
@@ -349,13 +449,14 @@ func (s *Service) rateFlatItem(
 	tx *gorm.DB,
 	cycle *billingCycleRow,
 	item subscriptionItemRow,
+	currency string,
 	featureCode string,
 	periodStart, periodEnd time.Time,
 	prorationFactor float64,
 	now time.Time,
 ) error {
 	// Resolve Base Price Amount at start of window
-	priceAmount, err := s.resolvePriceAmountAt(ctx, tx, cycle.OrgID, item.PriceID, nil, periodStart)
+	priceAmount, err := s.resolvePriceAmountAt(ctx, tx, cycle.OrgID, item.PriceID, nil, currency, periodStart)
 	if err != nil {
 		return err
 	}
@@ -367,8 +468,20 @@ func (s *Service) rateFlatItem(
 	// Only apply if factor < 1.0 (to avoid rounding errors on full periods perhaps? or consistent application?)
 	// Strict: Always apply factor.
 
+	price, err := s.priceRepo.FindOne(ctx, &pricedomain.Price{
+		ID:    item.PriceID,
+		OrgID: item.OrgID,
+	})
+	if err != nil {
+		return err
+	}
+	if price == nil {
+		return ratingdomain.ErrMissingPriceAmount
+	}
+
 	baseAmount := float64(priceAmount.UnitAmountCents)
-	proratedAmount := baseAmount * prorationFactor
+	quantity := prorationFactor * item.units(price.PricingModel)
+	proratedAmount := baseAmount * quantity
 	finalAmount := int64(math.Floor(proratedAmount + 0.5)) // Round to nearest cent
 
 	window := priceWindow{
@@ -400,7 +513,7 @@ func (s *Service) rateFlatItem(
 
 		Source: "flat_rate",
 
-		Quantity: prorationFactor, // Store factor as quantity for visibility? Or 1?
+		Quantity: quantity, // Proration factor, times the item quantity for per-unit prices
 		// User Prompt: "Persist proration-adjusted values into: rating_results.quantity, rating_results.amount"
 		// If I set Quantity = Factor, and UnitPrice = Base, then Amount = Factor * Base.
 		// That works perfectly for explaining the calculation!
@@ -422,17 +535,18 @@ func (s *Service) buildPriceWindows(
 	tx *gorm.DB,
 	orgID, priceID snowflake.ID,
 	meterID *snowflake.ID,
+	currency string,
 	periodStart, periodEnd time.Time,
 ) ([]priceWindow, error) {
 	boundaries := []time.Time{periodStart, periodEnd}
 
-	specific, err := s.priceAmountRepo.ListOverlapping(ctx, tx, orgID, priceID, meterID, "", periodStart, periodEnd)
+	specific, err := s.priceAmountRepo.ListOverlapping(ctx, tx, orgID, priceID, meterID, currency, periodStart, periodEnd)
 	if err != nil {
 		return nil, err
 	}
 	boundaries = appendEffectiveBoundaries(boundaries, specific, periodStart, periodEnd)
 
-	defaults, err := s.priceAmountRepo.ListOverlapping(ctx, tx, orgID, priceID, nil, "", periodStart, periodEnd)
+	defaults, err := s.priceAmountRepo.ListOverlapping(ctx, tx, orgID, priceID, nil, currency, periodStart, periodEnd)
 	if err != nil {
 		return nil, err
 	}
@@ -448,7 +562,7 @@ func (s *Service) buildPriceWindows(
 		}
 
 		// Resolve price by usage time to keep rating historically correct.
-		amount, err := s.resolvePriceAmountAt(ctx, tx, orgID, priceID, meterID, start)
+		amount, err := s.resolvePriceAmountAt(ctx, tx, orgID, priceID, meterID, currency, start)
 		if err != nil {
 			return nil, err
 		}
@@ -466,21 +580,25 @@ func (s *Service) buildPriceWindows(
 	return windows, nil
 }
 
+// resolvePriceAmountAt finds the amount of a price in effect at a time. An
+// empty currency, for subscriptions billed in their organization's currency,
+// accepts an amount in any currency.
 func (s *Service) resolvePriceAmountAt(
 	ctx context.Context,
 	tx *gorm.DB,
 	orgID, priceID snowflake.ID,
 	meterID *snowflake.ID,
+	currency string,
 	at time.Time,
 ) (*priceamountdomain.PriceAmount, error) {
-	amount, err := s.priceAmountRepo.FindEffectiveAt(ctx, tx, orgID, priceID, meterID, "", at)
+	amount, err := s.priceAmountRepo.FindEffectiveAt(ctx, tx, orgID, priceID, meterID, currency, at)
 	if err != nil {
 		return nil, err
 	}
 	if amount != nil || meterID == nil {
 		return amount, nil
 	}
-	return s.priceAmountRepo.FindEffectiveAt(ctx, tx, orgID, priceID, nil, "", at)
+	return s.priceAmountRepo.FindEffectiveAt(ctx, tx, orgID, priceID, nil, currency, at)
 }
 
 func (s *Service) insertRatingWindow(
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/bwmarrin/snowflake"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+)
+
+// ARReconciliationJob reconciles every org's ledger receivables with its
+// invoice outstanding. Currencies over the drift threshold are logged with
+// their top contributing invoices and published on the drift gauge; an org
+// that fails to reconcile is logged and skipped, keeping its last drift.
+func (s *Scheduler) ARReconciliationJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "ar_reconciliation", s.cfg.BatchSize)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+
+	var orgIDs []snowflake.ID
+	if err := s.db.WithContext(ctx).Table("organizations").Order("id").Pluck("id", &orgIDs).Error; err != nil {
+		s.logSchedulerError(ctx, run, "ar_reconciliation.list_orgs_failed", "ar_reconciliation", 0, err)
+		return err
+	}
+
+	reconciled := make([]string, 0, len(orgIDs))
+	drifts := make([]obsmetrics.ARDrift, 0)
+	for _, orgID := range orgIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		report, err := s.billingOperationsSvc.ReconcileAR(orgcontext.WithOrgID(ctx, int64(orgID)))
+		if err != nil {
+			s.logSchedulerError(ctx, run, "ar_reconciliation.failed", "ar_reconciliation", orgID, err)
+			continue
+		}
+		run.AddProcessed(1)
+		reconciled = append(reconciled, orgID.String())
+
+		for _, currency := range report.Currencies {
+			if !currency.OverThreshold {
+				continue
+			}
+			invoiceIDs := make([]string, 0, len(currency.TopInvoices))
+			for _, invoice := range currency.TopInvoices {
+				invoiceIDs = append(invoiceIDs, invoice.InvoiceID)
+			}
+			s.logger(ctx).Warn("ar_reconciliation.drift_detected",
+				zap.String("org_id", orgID.String()),
+				zap.String("currency", currency.Currency),
+				zap.Int64("ledger_balance", currency.LedgerBalance),
+				zap.Int64("invoice_outstanding", currency.InvoiceOutstanding),
+				zap.Int64("drift", currency.Drift),
+				zap.Int64("threshold", report.Threshold),
+				zap.Strings("top_invoice_ids", invoiceIDs),
+			)
+			drifts = append(drifts, obsmetrics.ARDrift{
+				OrgID:    orgID.String(),
+				Currency: currency.Currency,
+				Amount:   currency.Drift,
+			})
+		}
+	}
+	obsmetrics.Scheduler().SetARReconciliationDrifts(reconciled, drifts)
+	return nil
+}
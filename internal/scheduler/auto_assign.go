@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/bwmarrin/snowflake"
+	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
+	organizationdomain "github.com/smallbiznis/railzway/internal/organization/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+)
+
+// AutoAssignJob distributes the top inbox items of every org that turned on
+// the billing_operations.auto_assign feature flag across its FinOps members.
+// Orgs without FinOps members are skipped; an org that fails to auto-assign
+// is logged and skipped.
+func (s *Scheduler) AutoAssignJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "auto_assign", s.cfg.BatchSize)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+
+	var members []struct {
+		OrgID  snowflake.ID `gorm:"column:org_id"`
+		UserID snowflake.ID `gorm:"column:user_id"`
+	}
+	if err := s.db.WithContext(ctx).Raw(
+		`SELECT org_id, user_id
+		 FROM organization_members
+		 WHERE role = ?
+		 ORDER BY org_id, user_id`,
+		organizationdomain.RoleFinOps,
+	).Scan(&members).Error; err != nil {
+		s.logSchedulerError(ctx, run, "auto_assign.list_members_failed", "auto_assign", 0, err)
+		return err
+	}
+
+	for i := 0; i < len(members); {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		orgID := members[i].OrgID
+		var assignees []string
+		for ; i < len(members) && members[i].OrgID == orgID; i++ {
+			assignees = append(assignees, members[i].UserID.String())
+		}
+		if s.flags != nil && !s.flags.IsEnabled(ctx, orgID, featuredomain.FlagBillingOperationsAutoAssign) {
+			continue
+		}
+
+		resp, err := s.billingOperationsSvc.AutoAssignInbox(
+			orgcontext.WithOrgID(ctx, int64(orgID)),
+			s.cfg.AutoAssignStrategy,
+			assignees,
+			s.cfg.AutoAssignMaxPerAgent,
+		)
+		if err != nil {
+			s.logSchedulerError(ctx, run, "auto_assign.failed", "auto_assign", orgID, err)
+			continue
+		}
+		run.AddProcessed(len(resp.Assigned))
+		if len(resp.Assigned) > 0 || resp.Skipped > 0 {
+			s.logger(ctx).Info("auto_assign.org_assigned",
+				zap.String("org_id", orgID.String()),
+				zap.Int("assigned", len(resp.Assigned)),
+				zap.Int("skipped", resp.Skipped),
+			)
+		}
+	}
+	return nil
+}
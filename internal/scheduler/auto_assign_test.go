@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type autoAssignRecorder struct {
+	billingopsdomain.Service
+	orgs []snowflake.ID
+}
+
+func (r *autoAssignRecorder) AutoAssignInbox(ctx context.Context, _ string, _ []string, _ int) (billingopsdomain.AutoAssignResponse, error) {
+	orgID, _ := orgcontext.OrgIDFromContext(ctx)
+	r.orgs = append(r.orgs, orgID)
+	return billingopsdomain.AutoAssignResponse{}, nil
+}
+
+type staticFlags struct {
+	featuredomain.FlagService
+	enabled map[snowflake.ID]bool
+}
+
+func (f staticFlags) IsEnabled(_ context.Context, orgID snowflake.ID, flag string) bool {
+	return flag == featuredomain.FlagBillingOperationsAutoAssign && f.enabled[orgID]
+}
+
+func TestAutoAssignJob_OnlyFlaggedOrgs(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	for _, stmt := range []string{
+		`CREATE TABLE organization_members (org_id BIGINT NOT NULL, user_id BIGINT NOT NULL, role TEXT NOT NULL)`,
+		`INSERT INTO organization_members (org_id, user_id, role) VALUES (1, 10, 'FINOPS'), (2, 20, 'FINOPS')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	node, err := snowflake.NewNode(1)
+	if err != nil {
+		t.Fatalf("snowflake node: %v", err)
+	}
+
+	recorder := &autoAssignRecorder{}
+	s := &Scheduler{
+		db:                   db,
+		log:                  zap.NewNop(),
+		cfg:                  DefaultConfig(),
+		genID:                node,
+		billingOperationsSvc: recorder,
+		flags:                staticFlags{enabled: map[snowflake.ID]bool{2: true}},
+	}
+	if err := s.AutoAssignJob(context.Background()); err != nil {
+		t.Fatalf("auto assign job: %v", err)
+	}
+	if len(recorder.orgs) != 1 || recorder.orgs[0] != 2 {
+		t.Fatalf("auto-assigned orgs = %v, want only org 2", recorder.orgs)
+	}
+}
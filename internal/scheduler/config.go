@@ -1,24 +1,76 @@
 package scheduler
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Config controls scheduler intervals and batch sizes.
 type Config struct {
-	RunInterval         time.Duration
-	BatchSize           int
-	RecoveryThreshold   time.Duration
-	FinalizeInvoices    bool
+	RunInterval       time.Duration
+	BatchSize         int
+	RecoveryThreshold time.Duration
+	FinalizeInvoices  bool
+	// FinalizeAfter keeps freshly generated drafts open for manual review
+	// before the scheduler finalizes them. Zero finalizes immediately.
+	FinalizeAfter       time.Duration
 	MaxCloseBatchSize   int
 	MaxRatingBatchSize  int
 	MaxInvoiceBatchSize int
+	MaxRatingAttempts   int
 	EnabledJobs         []string
+	// MaxConcurrentJobs bounds how many jobs of a RunOnce pass run at the
+	// same time. One keeps the jobs strictly sequential.
+	MaxConcurrentJobs int
+	// InvoiceReminderOffsets are the days relative to an invoice's due date
+	// at which the customer is reminded; negative offsets fire before it.
+	InvoiceReminderOffsets []int
+	// InvoiceReminderQuietStart and InvoiceReminderQuietEnd are the local
+	// hours (in the org's timezone) during which no reminders are sent. The
+	// window may wrap midnight; equal values disable it.
+	InvoiceReminderQuietStart int
+	InvoiceReminderQuietEnd   int
+	// ActionRetentionEnabled turns on the action_retention job, which moves
+	// billing operations actions older than ActionRetention out of the live
+	// table. Off by default.
+	ActionRetentionEnabled bool
+	ActionRetention        time.Duration
+	// JobRunRetention is how long the job_run_cleanup job keeps scheduler
+	// job run history.
+	JobRunRetention time.Duration
+	// ARReconciliationEnabled turns on the ar_reconciliation job, which
+	// compares each org's ledger receivables with its invoice outstanding.
+	// Off by default.
+	ARReconciliationEnabled bool
+	// AutoAssignEnabled turns on the auto_assign job, which deals each org's
+	// top inbox items to its FinOps members using AutoAssignStrategy, up to
+	// AutoAssignMaxPerAgent active assignments each. Off by default.
+	AutoAssignEnabled     bool
+	AutoAssignStrategy    string
+	AutoAssignMaxPerAgent int
+	// EnsurePublicTokensEnabled turns on the ensure_public_tokens job, which
+	// issues a public token to finalized unpaid invoices that have none.
+	// Off by default.
+	EnsurePublicTokensEnabled bool
+	// ShutdownTimeout is how long jobs already running may keep going after
+	// the scheduler is asked to stop before they are cancelled. Keep it below
+	// the app's stop timeout so the drain finishes before the process exits.
+	ShutdownTimeout time.Duration
+	// NodeID names this replica in the job leadership metric. Defaults to
+	// the host name.
+	NodeID string
+	// JobSchedules gives jobs their own cadence under RunForever, keyed by
+	// job name. Jobs without an entry run every RunInterval.
+	JobSchedules map[string]JobSchedule
 }
 
-func ProvideConfig() Config {
+// ProvideConfig reads the scheduler config from the environment. Most values
+// that do not parse keep their default; a malformed SCHEDULER_JOB_SCHEDULES
+// is an error, since it would silently put every job back on RunInterval.
+func ProvideConfig() (Config, error) {
 	cfg := DefaultConfig()
 	if jobs := os.Getenv("ENABLED_JOBS"); jobs != "" {
 		cfg.EnabledJobs = strings.Split(jobs, ",")
@@ -26,7 +78,123 @@ func ProvideConfig() Config {
 			cfg.EnabledJobs[i] = strings.TrimSpace(cfg.EnabledJobs[i])
 		}
 	}
-	return cfg
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_MAX_RATING_ATTEMPTS")); raw != "" {
+		if attempts, err := strconv.Atoi(raw); err == nil && attempts > 0 {
+			cfg.MaxRatingAttempts = attempts
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_MAX_CONCURRENT_JOBS")); raw != "" {
+		if limit, err := strconv.Atoi(raw); err == nil && limit > 0 {
+			cfg.MaxConcurrentJobs = limit
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_FINALIZE_AFTER")); raw != "" {
+		if delay, err := time.ParseDuration(raw); err == nil && delay >= 0 {
+			cfg.FinalizeAfter = delay
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_SHUTDOWN_TIMEOUT")); raw != "" {
+		if timeout, err := time.ParseDuration(raw); err == nil && timeout > 0 {
+			cfg.ShutdownTimeout = timeout
+		}
+	}
+	if nodeID := strings.TrimSpace(os.Getenv("SCHEDULER_NODE_ID")); nodeID != "" {
+		cfg.NodeID = nodeID
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_INVOICE_REMINDER_OFFSETS")); raw != "" {
+		if offsets, ok := parseReminderOffsets(raw); ok {
+			cfg.InvoiceReminderOffsets = offsets
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_INVOICE_REMINDER_QUIET_HOURS")); raw != "" {
+		if start, end, ok := parseQuietHours(raw); ok {
+			cfg.InvoiceReminderQuietStart = start
+			cfg.InvoiceReminderQuietEnd = end
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_ACTION_RETENTION_ENABLED")); raw != "" {
+		if enabled, err := strconv.ParseBool(raw); err == nil {
+			cfg.ActionRetentionEnabled = enabled
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_ACTION_RETENTION_DAYS")); raw != "" {
+		if days, err := strconv.Atoi(raw); err == nil && days > 0 {
+			cfg.ActionRetention = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_JOB_RUN_RETENTION_DAYS")); raw != "" {
+		if days, err := strconv.Atoi(raw); err == nil && days > 0 {
+			cfg.JobRunRetention = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_AR_RECONCILIATION_ENABLED")); raw != "" {
+		if enabled, err := strconv.ParseBool(raw); err == nil {
+			cfg.ARReconciliationEnabled = enabled
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_AUTO_ASSIGN_ENABLED")); raw != "" {
+		if enabled, err := strconv.ParseBool(raw); err == nil {
+			cfg.AutoAssignEnabled = enabled
+		}
+	}
+	if strategy := strings.TrimSpace(os.Getenv("SCHEDULER_AUTO_ASSIGN_STRATEGY")); strategy != "" {
+		cfg.AutoAssignStrategy = strategy
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_AUTO_ASSIGN_MAX_PER_AGENT")); raw != "" {
+		if limit, err := strconv.Atoi(raw); err == nil && limit > 0 {
+			cfg.AutoAssignMaxPerAgent = limit
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_ENSURE_PUBLIC_TOKENS_ENABLED")); raw != "" {
+		if enabled, err := strconv.ParseBool(raw); err == nil {
+			cfg.EnsurePublicTokensEnabled = enabled
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SCHEDULER_JOB_SCHEDULES")); raw != "" {
+		schedules, ok := parseJobSchedules(raw)
+		if !ok {
+			return Config{}, fmt.Errorf("%w: malformed SCHEDULER_JOB_SCHEDULES %q", ErrInvalidConfig, raw)
+		}
+		cfg.JobSchedules = schedules
+	}
+	return cfg, nil
+}
+
+// parseReminderOffsets parses a comma-separated list of day offsets such as
+// "-3,0,7".
+func parseReminderOffsets(raw string) ([]int, bool) {
+	parts := strings.Split(raw, ",")
+	offsets := make([]int, 0, len(parts))
+	seen := make(map[int]struct{}, len(parts))
+	for _, part := range parts {
+		offset, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, false
+		}
+		if _, ok := seen[offset]; ok {
+			continue
+		}
+		seen[offset] = struct{}{}
+		offsets = append(offsets, offset)
+	}
+	return offsets, true
+}
+
+// parseQuietHours parses an "start-end" hour range such as "21-8".
+func parseQuietHours(raw string) (int, int, bool) {
+	startRaw, endRaw, ok := strings.Cut(raw, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(startRaw))
+	if err != nil || start < 0 || start > 23 {
+		return 0, 0, false
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endRaw))
+	if err != nil || end < 0 || end > 23 {
+		return 0, 0, false
+	}
+	return start, end, true
 }
 
 func DefaultConfig() Config {
@@ -38,6 +206,19 @@ func DefaultConfig() Config {
 		MaxCloseBatchSize:   50,
 		MaxRatingBatchSize:  25,
 		MaxInvoiceBatchSize: 25,
+		MaxRatingAttempts:   5,
+		MaxConcurrentJobs:   1,
+
+		InvoiceReminderOffsets:    []int{-3, 0, 7},
+		InvoiceReminderQuietStart: 21,
+		InvoiceReminderQuietEnd:   8,
+
+		ActionRetention: 365 * 24 * time.Hour,
+		JobRunRetention: 30 * 24 * time.Hour,
+		ShutdownTimeout: 10 * time.Second,
+
+		AutoAssignStrategy:    "round_robin",
+		AutoAssignMaxPerAgent: 10,
 	}
 }
 
@@ -61,5 +242,42 @@ func (c Config) withDefaults() Config {
 	if c.MaxInvoiceBatchSize <= 0 {
 		c.MaxInvoiceBatchSize = defaults.MaxInvoiceBatchSize
 	}
+	if c.MaxRatingAttempts <= 0 {
+		c.MaxRatingAttempts = defaults.MaxRatingAttempts
+	}
+	if c.MaxConcurrentJobs <= 0 {
+		c.MaxConcurrentJobs = defaults.MaxConcurrentJobs
+	}
+	if c.FinalizeAfter < 0 {
+		c.FinalizeAfter = 0
+	}
+	if c.InvoiceReminderOffsets == nil {
+		c.InvoiceReminderOffsets = defaults.InvoiceReminderOffsets
+	}
+	if c.ActionRetention <= 0 {
+		c.ActionRetention = defaults.ActionRetention
+	}
+	if c.JobRunRetention <= 0 {
+		c.JobRunRetention = defaults.JobRunRetention
+	}
+	if c.ShutdownTimeout <= 0 {
+		c.ShutdownTimeout = defaults.ShutdownTimeout
+	}
+	if c.AutoAssignStrategy == "" {
+		c.AutoAssignStrategy = defaults.AutoAssignStrategy
+	}
+	if c.AutoAssignMaxPerAgent <= 0 {
+		c.AutoAssignMaxPerAgent = defaults.AutoAssignMaxPerAgent
+	}
+	if c.NodeID == "" {
+		c.NodeID = defaultNodeID()
+	}
 	return c
 }
+
+func defaultNodeID() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "unknown"
+}
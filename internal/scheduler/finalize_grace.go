@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/authorization"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"go.uber.org/zap"
+)
+
+// inFinalizeGraceWindow reports whether a draft created at createdAt is still
+// too fresh to be finalized by the scheduler.
+func (s *Scheduler) inFinalizeGraceWindow(createdAt time.Time, now time.Time) bool {
+	if s.cfg.FinalizeAfter <= 0 || createdAt.IsZero() {
+		return false
+	}
+	return createdAt.After(now.Add(-s.cfg.FinalizeAfter))
+}
+
+// finalizeDraftInvoice finalizes a scheduler-generated draft and stamps the
+// cycle as finalized. Failures are logged and recorded on the cycle.
+func (s *Scheduler) finalizeDraftInvoice(ctx, cycleCtx context.Context, run *jobRun, cycle WorkBillingCycle, invoiceID snowflake.ID, now time.Time) error {
+	if err := s.authorizeSystem(ctx, cycle.OrgID, authorization.ObjectInvoice, authorization.ActionInvoiceFinalize); err != nil {
+		s.logSchedulerError(ctx, run, "scheduler.authorize.failed", "invoice", cycle.OrgID, err,
+			zap.String("cycle_id", idString(cycle.ID)),
+			zap.String("invoice_id", idString(invoiceID)),
+			zap.String("subscription_id", idString(cycle.SubscriptionID)),
+		)
+		return err
+	}
+	if err := s.invoiceSvc.FinalizeInvoice(cycleCtx, invoiceID.String()); err != nil {
+		s.logSchedulerError(ctx, run, "invoice.finalize.failed", "invoice", cycle.OrgID, err,
+			zap.String("cycle_id", idString(cycle.ID)),
+			zap.String("invoice_id", idString(invoiceID)),
+			zap.String("subscription_id", idString(cycle.SubscriptionID)),
+		)
+		_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageInvoice, err)
+		return err
+	}
+	s.logInvoiceFinalized(ctx, cycle, invoiceID)
+	if err := s.markCycleInvoiceFinalized(ctx, cycle.ID, now); err != nil {
+		s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "invoice", cycle.OrgID, err,
+			zap.String("cycle_id", idString(cycle.ID)),
+			zap.String("invoice_id", idString(invoiceID)),
+			zap.String("subscription_id", idString(cycle.SubscriptionID)),
+		)
+		_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageInvoice, err)
+		return err
+	}
+	return nil
+}
+
+// finalizeGracedDrafts finalizes drafts on invoiced cycles whose grace window
+// has elapsed. Drafts still inside the window are left untouched and counted
+// so operators can see how many invoices are waiting.
+func (s *Scheduler) finalizeGracedDrafts(ctx context.Context, run *jobRun, now time.Time) error {
+	cutoff := now.Add(-s.cfg.FinalizeAfter)
+
+	cycles, err := s.fetchBillingCyclesForWork(ctx,
+		cyclesGracedDrafts,
+		[]any{billingcycledomain.BillingCycleStatusClosed, invoicedomain.InvoiceStatusDraft, cutoff},
+		s.cfg.MaxInvoiceBatchSize,
+	)
+	if err != nil {
+		s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "invoice", 0, err)
+		return err
+	}
+
+	var jobErr error
+	for _, cycle := range cycles {
+		s.logCycleClaimed(ctx, "invoice", cycle)
+
+		var invoiceID snowflake.ID
+		if err := s.db.WithContext(ctx).Raw(
+			`SELECT id FROM invoices WHERE billing_cycle_id = ? AND status = ?`,
+			cycle.ID,
+			invoicedomain.InvoiceStatusDraft,
+		).Scan(&invoiceID).Error; err != nil {
+			jobErr = errors.Join(jobErr, err)
+			s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "invoice", cycle.OrgID, err,
+				zap.String("cycle_id", idString(cycle.ID)),
+			)
+			continue
+		}
+		if invoiceID == 0 {
+			continue
+		}
+
+		cycleCtx := s.withAuditContext(ctx, cycle.SubscriptionID.String(), cycle.ID.String())
+		if err := s.finalizeDraftInvoice(ctx, cycleCtx, run, cycle, invoiceID, now); err != nil {
+			jobErr = errors.Join(jobErr, err)
+		}
+	}
+
+	var waiting int64
+	if err := s.db.WithContext(ctx).Raw(
+		`SELECT COUNT(*)
+		 FROM invoices i
+		 JOIN billing_cycles bc ON bc.id = i.billing_cycle_id
+		 WHERE bc.status = ?
+		   AND bc.invoiced_at IS NOT NULL
+		   AND bc.invoice_finalized_at IS NULL
+		   AND i.status = ?
+		   AND i.created_at > ?`,
+		billingcycledomain.BillingCycleStatusClosed,
+		invoicedomain.InvoiceStatusDraft,
+		cutoff,
+	).Scan(&waiting).Error; err != nil {
+		s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "invoice", 0, err)
+		return errors.Join(jobErr, err)
+	}
+	obsmetrics.Scheduler().SetInvoicesAwaitingFinalize(int(waiting))
+
+	return jobErr
+}
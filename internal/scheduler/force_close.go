@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	auditcontext "github.com/smallbiznis/railzway/internal/auditcontext"
+	"github.com/smallbiznis/railzway/internal/authorization"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const auditActionBillingCycleForceClosed = "billing_cycle.force_closed"
+
+// ForceCloseCycleRequest describes an operator override of a stuck cycle.
+type ForceCloseCycleRequest struct {
+	OrgID   snowflake.ID
+	CycleID snowflake.ID
+	// Actor is the authorization subject asking for the override, e.g.
+	// "user:42".
+	Actor string
+	// Status is the status the cycle is forced into: CLOSED (the default)
+	// hands it to invoicing, RATING_FAILED parks it for follow-up.
+	Status billingcycledomain.BillingCycleStatus
+	Reason string
+}
+
+// ForceCloseCycleResult reports the transition a force close applied.
+type ForceCloseCycleResult struct {
+	CycleID        string                                `json:"cycle_id"`
+	PreviousStatus billingcycledomain.BillingCycleStatus `json:"previous_status"`
+	Status         billingcycledomain.BillingCycleStatus `json:"status"`
+	Reason         string                                `json:"reason"`
+	ForcedAt       time.Time                             `json:"forced_at"`
+}
+
+// ForceCloseCycle moves a cycle that the scheduler cannot advance straight to
+// CLOSED or RATING_FAILED, skipping the rating and status guards of the
+// normal lifecycle. The actor must hold ActionBillingCycleForceClose in the
+// cycle's org and give a reason; every override is audited and counted.
+func (s *Scheduler) ForceCloseCycle(ctx context.Context, req ForceCloseCycleRequest) (*ForceCloseCycleResult, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, billingcycledomain.ErrInvalidForceCloseReason
+	}
+	status := req.Status
+	if status == "" {
+		status = billingcycledomain.BillingCycleStatusClosed
+	}
+	if status != billingcycledomain.BillingCycleStatusClosed && status != billingcycledomain.BillingCycleStatusRatingFailed {
+		return nil, billingcycledomain.ErrInvalidForceCloseStatus
+	}
+	if s.authzSvc == nil {
+		return nil, authorization.ErrForbidden
+	}
+	if err := s.authzSvc.Authorize(ctx, req.Actor, req.OrgID.String(), authorization.ObjectBillingCycle, authorization.ActionBillingCycleForceClose); err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now().UTC()
+	var cycle *WorkBillingCycle
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		cycle, err = s.lockCycleForUpdate(ctx, tx, req.CycleID)
+		if err != nil {
+			return err
+		}
+		if cycle == nil || cycle.OrgID != req.OrgID {
+			return billingcycledomain.ErrBillingCycleNotFound
+		}
+		if cycle.Status == billingcycledomain.BillingCycleStatusClosed || cycle.Status == status {
+			return billingcycledomain.ErrCycleNotForceClosable
+		}
+
+		if status == billingcycledomain.BillingCycleStatusClosed {
+			return tx.WithContext(ctx).Exec(
+				`UPDATE billing_cycles
+				 SET status = ?, closing_started_at = COALESCE(closing_started_at, ?),
+				     closed_at = COALESCE(closed_at, ?),
+				     next_rating_retry_at = NULL,
+				     last_error = NULL,
+				     last_error_at = NULL,
+				     updated_at = ?
+				 WHERE id = ? AND status = ?`,
+				status,
+				now,
+				now,
+				now,
+				cycle.ID,
+				cycle.Status,
+			).Error
+		}
+		return tx.WithContext(ctx).Exec(
+			`UPDATE billing_cycles
+			 SET status = ?, closing_started_at = COALESCE(closing_started_at, ?),
+			     next_rating_retry_at = NULL,
+			     last_error = ?,
+			     last_error_at = ?,
+			     updated_at = ?
+			 WHERE id = ? AND status = ?`,
+			status,
+			now,
+			"force closed: "+reason,
+			now,
+			now,
+			cycle.ID,
+			cycle.Status,
+		).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	obsmetrics.Scheduler().IncBillingCycleForceClose(string(cycle.Status), string(status))
+	s.log.Warn("billing cycle force closed",
+		zap.String("org_id", cycle.OrgID.String()),
+		zap.String("billing_cycle_id", cycle.ID.String()),
+		zap.String("previous_status", string(cycle.Status)),
+		zap.String("status", string(status)),
+		zap.String("actor", req.Actor),
+		zap.String("reason", reason),
+	)
+
+	if s.auditSvc != nil {
+		auditCtx := auditcontext.WithSubscriptionID(ctx, cycle.SubscriptionID.String())
+		auditCtx = auditcontext.WithBillingCycleID(auditCtx, cycle.ID.String())
+		orgID := cycle.OrgID
+		targetID := cycle.ID.String()
+		if err := s.auditSvc.AuditLog(auditCtx, &orgID, "", nil, auditActionBillingCycleForceClosed, "billing_cycle", &targetID, map[string]any{
+			"forced":           true,
+			"reason":           reason,
+			"requested_by":     req.Actor,
+			"previous_status":  string(cycle.Status),
+			"status":           string(status),
+			"rating_completed": cycle.RatingCompletedAt != nil,
+			"period_start":     cycle.PeriodStart,
+			"period_end":       cycle.PeriodEnd,
+		}); err != nil {
+			s.log.Error("failed to audit billing cycle force close",
+				zap.String("billing_cycle_id", cycle.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return &ForceCloseCycleResult{
+		CycleID:        cycle.ID.String(),
+		PreviousStatus: cycle.Status,
+		Status:         status,
+		Reason:         reason,
+		ForcedAt:       now,
+	}, nil
+}
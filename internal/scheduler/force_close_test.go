@@ -0,0 +1,233 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus"
+	auditdomain "github.com/smallbiznis/railzway/internal/audit/domain"
+	"github.com/smallbiznis/railzway/internal/authorization"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type forceCloseAuthz struct {
+	allowed map[string]bool
+	actions []string
+}
+
+func (a *forceCloseAuthz) Authorize(_ context.Context, actor, _, _, action string) error {
+	a.actions = append(a.actions, action)
+	if !a.allowed[actor] {
+		return authorization.ErrForbidden
+	}
+	return nil
+}
+
+type forceCloseAudit struct {
+	mockAuditSvc
+	actions  []string
+	metadata []map[string]any
+}
+
+func (a *forceCloseAudit) AuditLog(_ context.Context, _ *snowflake.ID, _ string, _ *string, action string, _ string, _ *string, metadata map[string]any) error {
+	a.actions = append(a.actions, action)
+	a.metadata = append(a.metadata, metadata)
+	return nil
+}
+
+func (a *forceCloseAudit) AuditLogTx(ctx context.Context, _ *gorm.DB, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
+	return a.AuditLog(ctx, orgID, actorType, actorID, action, targetType, targetID, metadata)
+}
+
+var _ auditdomain.Service = (*forceCloseAudit)(nil)
+
+func newForceCloseTestScheduler(t *testing.T, now time.Time) (*Scheduler, *forceCloseAuthz, *forceCloseAudit) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	// sqlite has no row locks; drop the FOR UPDATE of lockCycleForUpdate.
+	db.Callback().Row().Before("gorm:row").Register("sqlite_for_update_row", func(d *gorm.DB) {
+		sql := d.Statement.SQL.String()
+		if strings.Contains(sql, "FOR UPDATE") {
+			d.Statement.SQL.Reset()
+			d.Statement.SQL.WriteString(strings.ReplaceAll(sql, "FOR UPDATE", ""))
+		}
+	})
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Exec(`
+		CREATE TABLE billing_cycles (
+			id INTEGER PRIMARY KEY,
+			org_id INTEGER,
+			subscription_id INTEGER,
+			period_start DATETIME,
+			period_end DATETIME,
+			status TEXT,
+			closing_started_at DATETIME,
+			rating_completed_at DATETIME,
+			rating_attempts INTEGER NOT NULL DEFAULT 0,
+			next_rating_retry_at DATETIME,
+			invoiced_at DATETIME,
+			invoice_finalized_at DATETIME,
+			closed_at DATETIME,
+			last_error TEXT,
+			last_error_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error; err != nil {
+		t.Fatalf("create billing_cycles table: %v", err)
+	}
+
+	authz := &forceCloseAuthz{allowed: map[string]bool{"user:10": true}}
+	audit := &forceCloseAudit{}
+	return &Scheduler{
+		db:       db,
+		log:      zap.NewNop(),
+		clock:    clock.NewFakeClock(now),
+		authzSvc: authz,
+		auditSvc: audit,
+	}, authz, audit
+}
+
+func insertForceCloseCycle(t *testing.T, db *gorm.DB, id, orgID int64, status billingcycledomain.BillingCycleStatus) {
+	t.Helper()
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.Exec(
+		`INSERT INTO billing_cycles (id, org_id, subscription_id, period_start, period_end, status, closing_started_at, rating_attempts, next_rating_retry_at, last_error)
+		 VALUES (?, ?, 7, ?, ?, ?, ?, 3, ?, 'rating failed')`,
+		id, orgID, start, start.AddDate(0, 1, 0), status, start.AddDate(0, 1, 0), start.AddDate(0, 1, 1),
+	).Error; err != nil {
+		t.Fatalf("insert billing cycle: %v", err)
+	}
+}
+
+func TestForceCloseCycle(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	restore := swapPrometheusRegistry(registry)
+	defer restore()
+
+	now := time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	t.Run("closes a stuck closing cycle", func(t *testing.T) {
+		s, authz, audit := newForceCloseTestScheduler(t, now)
+		insertForceCloseCycle(t, s.db, 1, 100, billingcycledomain.BillingCycleStatusClosing)
+
+		result, err := s.ForceCloseCycle(ctx, ForceCloseCycleRequest{
+			OrgID:   100,
+			CycleID: 1,
+			Actor:   "user:10",
+			Reason:  "  no usage was ever recorded  ",
+		})
+		if err != nil {
+			t.Fatalf("ForceCloseCycle: %v", err)
+		}
+		if result.PreviousStatus != billingcycledomain.BillingCycleStatusClosing || result.Status != billingcycledomain.BillingCycleStatusClosed {
+			t.Fatalf("unexpected transition: %+v", result)
+		}
+		if len(authz.actions) != 1 || authz.actions[0] != authorization.ActionBillingCycleForceClose {
+			t.Fatalf("expected the force close action to be authorized, got %v", authz.actions)
+		}
+
+		var row struct {
+			Status            string
+			ClosedAt          *time.Time
+			NextRatingRetryAt *time.Time
+			LastError         *string
+		}
+		if err := s.db.Raw(`SELECT status, closed_at, next_rating_retry_at, last_error FROM billing_cycles WHERE id = 1`).Scan(&row).Error; err != nil {
+			t.Fatalf("load cycle: %v", err)
+		}
+		if row.Status != string(billingcycledomain.BillingCycleStatusClosed) || row.ClosedAt == nil || !row.ClosedAt.Equal(now) {
+			t.Fatalf("expected cycle closed at %v, got %+v", now, row)
+		}
+		if row.NextRatingRetryAt != nil || row.LastError != nil {
+			t.Fatalf("expected retry and error state cleared, got %+v", row)
+		}
+
+		if len(audit.actions) != 1 || audit.actions[0] != "billing_cycle.force_closed" {
+			t.Fatalf("expected one force close audit entry, got %v", audit.actions)
+		}
+		meta := audit.metadata[0]
+		if meta["reason"] != "no usage was ever recorded" || meta["previous_status"] != "CLOSING" || meta["requested_by"] != "user:10" || meta["forced"] != true {
+			t.Fatalf("unexpected audit metadata: %v", meta)
+		}
+		labels := map[string]string{"from": "CLOSING", "to": "CLOSED"}
+		if got := getCounterValue(t, registry, "billing_cycle_force_close_total", labels); got != 1 {
+			t.Fatalf("expected force close count 1, got %v", got)
+		}
+	})
+
+	t.Run("parks a cycle as rating failed", func(t *testing.T) {
+		s, _, _ := newForceCloseTestScheduler(t, now)
+		insertForceCloseCycle(t, s.db, 2, 100, billingcycledomain.BillingCycleStatusOpen)
+
+		if _, err := s.ForceCloseCycle(ctx, ForceCloseCycleRequest{
+			OrgID:   100,
+			CycleID: 2,
+			Actor:   "user:10",
+			Status:  billingcycledomain.BillingCycleStatusRatingFailed,
+			Reason:  "subscription migrated",
+		}); err != nil {
+			t.Fatalf("ForceCloseCycle: %v", err)
+		}
+
+		var row struct {
+			Status    string
+			ClosedAt  *time.Time
+			LastError *string
+		}
+		if err := s.db.Raw(`SELECT status, closed_at, last_error FROM billing_cycles WHERE id = 2`).Scan(&row).Error; err != nil {
+			t.Fatalf("load cycle: %v", err)
+		}
+		if row.Status != string(billingcycledomain.BillingCycleStatusRatingFailed) || row.ClosedAt != nil {
+			t.Fatalf("expected cycle parked as rating failed, got %+v", row)
+		}
+		if row.LastError == nil || *row.LastError != "force closed: subscription migrated" {
+			t.Fatalf("expected the reason recorded as last error, got %v", row.LastError)
+		}
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		s, authz, audit := newForceCloseTestScheduler(t, now)
+		insertForceCloseCycle(t, s.db, 3, 100, billingcycledomain.BillingCycleStatusClosed)
+		insertForceCloseCycle(t, s.db, 4, 200, billingcycledomain.BillingCycleStatusClosing)
+
+		cases := []struct {
+			name string
+			req  ForceCloseCycleRequest
+			want error
+		}{
+			{"missing reason", ForceCloseCycleRequest{OrgID: 100, CycleID: 3, Actor: "user:10", Reason: " "}, billingcycledomain.ErrInvalidForceCloseReason},
+			{"unsupported status", ForceCloseCycleRequest{OrgID: 100, CycleID: 3, Actor: "user:10", Status: billingcycledomain.BillingCycleStatusOpen, Reason: "x"}, billingcycledomain.ErrInvalidForceCloseStatus},
+			{"not granted", ForceCloseCycleRequest{OrgID: 100, CycleID: 3, Actor: "system", Reason: "x"}, authorization.ErrForbidden},
+			{"already closed", ForceCloseCycleRequest{OrgID: 100, CycleID: 3, Actor: "user:10", Reason: "x"}, billingcycledomain.ErrCycleNotForceClosable},
+			{"other org", ForceCloseCycleRequest{OrgID: 100, CycleID: 4, Actor: "user:10", Reason: "x"}, billingcycledomain.ErrBillingCycleNotFound},
+		}
+		for _, tc := range cases {
+			if _, err := s.ForceCloseCycle(ctx, tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+			}
+		}
+		if len(audit.actions) != 0 {
+			t.Fatalf("expected no audit entries for rejected overrides, got %v", audit.actions)
+		}
+		if len(authz.actions) != 3 {
+			t.Fatalf("expected authorization only after validation, got %d checks", len(authz.actions))
+		}
+	})
+}
@@ -17,21 +17,32 @@ func NewScheduler(lc fx.Lifecycle, cfg config.Config, sched *Scheduler) {
 	if cfg.IsCloud() {
 		return
 	}
+	Start(lc, sched)
+}
 
-	lc.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
-			ctx, cancel := context.WithCancel(context.Background())
-
-			go sched.RunForever(ctx)
-
-			lc.Append(fx.Hook{
-				OnStop: func(context.Context) error {
-					cancel()
-					return nil
-				},
-			})
+// Start runs the scheduler loop for the lifetime of the app. On stop it
+// stops new jobs from starting and waits for the running ones to drain,
+// bounded by the stop context.
+func Start(lc fx.Lifecycle, sched *Scheduler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
 
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				sched.RunForever(ctx)
+			}()
 			return nil
 		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
 	})
 }
@@ -11,6 +11,9 @@ import (
 
 var (
 	ErrSubscriptionNotActive   = errors.New("subscription_not_active")
+	ErrSubscriptionPaused      = errors.New("subscription_paused")
+	ErrSubscriptionNotPausable = errors.New("subscription_not_pausable")
+	ErrSubscriptionNotPaused   = errors.New("subscription_not_paused")
 	ErrMissingActivation       = errors.New("subscription_missing_activation")
 	ErrInvalidBillingCycleType = errors.New("invalid_billing_cycle_type")
 	ErrCycleNotOpen            = errors.New("billing_cycle_not_open")
@@ -18,6 +21,9 @@ var (
 )
 
 func EnsureSubscriptionCanOpenBillingCycle(status subscriptiondomain.SubscriptionStatus, activatedAt *time.Time, cycleType string) error {
+	if status == subscriptiondomain.SubscriptionStatusPaused {
+		return ErrSubscriptionPaused
+	}
 	if status != subscriptiondomain.SubscriptionStatusActive {
 		return ErrSubscriptionNotActive
 	}
@@ -30,6 +36,24 @@ func EnsureSubscriptionCanOpenBillingCycle(status subscriptiondomain.Subscriptio
 	return nil
 }
 
+// EnsureSubscriptionCanPause allows pausing active subscriptions. A paused
+// subscription may be paused again to move its resume date.
+func EnsureSubscriptionCanPause(status subscriptiondomain.SubscriptionStatus) error {
+	switch status {
+	case subscriptiondomain.SubscriptionStatusActive, subscriptiondomain.SubscriptionStatusPaused:
+		return nil
+	default:
+		return ErrSubscriptionNotPausable
+	}
+}
+
+func EnsureSubscriptionCanResume(status subscriptiondomain.SubscriptionStatus) error {
+	if status != subscriptiondomain.SubscriptionStatusPaused {
+		return ErrSubscriptionNotPaused
+	}
+	return nil
+}
+
 func EnsureBillingCycleCanClose(status billingcycledomain.BillingCycleStatus, periodEnd time.Time, now time.Time) error {
 	if status != billingcycledomain.BillingCycleStatusOpen {
 		return ErrCycleNotOpen
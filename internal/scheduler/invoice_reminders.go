@@ -0,0 +1,265 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	emaildeliverydomain "github.com/smallbiznis/railzway/internal/emaildelivery/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	"github.com/smallbiznis/railzway/internal/providers/email"
+	"github.com/smallbiznis/railzway/pkg/money"
+	"go.uber.org/zap"
+)
+
+// invoiceReminderWindow bounds how long after its scheduled time a reminder
+// may still go out, so enabling the job or adding an offset does not mail
+// every invoice that is already past it.
+const invoiceReminderWindow = 24 * time.Hour
+
+type invoiceReminderCandidate struct {
+	InvoiceID     snowflake.ID
+	OrgID         snowflake.ID
+	CustomerID    snowflake.ID
+	InvoiceNumber string
+	TotalAmount   int64
+	Currency      string
+	DueAt         time.Time
+	CustomerEmail string
+	OrgName       string
+	SupportEmail  string
+}
+
+// InvoiceRemindersJob emails customers about finalized, unpaid invoices at
+// each configured offset from the due date. Customers flagged do-not-contact
+// are never reminded. Every (invoice, offset) pair is
+// claimed in invoice_reminders_sent before sending, so a reminder goes out at
+// most once even with several scheduler replicas.
+func (s *Scheduler) InvoiceRemindersJob(ctx context.Context) error {
+	if s.email == nil {
+		return nil
+	}
+	run := jobRunFromContext(ctx)
+	now := s.clock.Now().UTC()
+
+	quiet, err := s.quietReminderTimezones(ctx, now)
+	if err != nil {
+		s.logSchedulerError(ctx, run, "scheduler.invoice_reminders.list_failed", "invoice_reminders", 0, err)
+		return err
+	}
+
+	var jobErr error
+	for _, offset := range s.cfg.InvoiceReminderOffsets {
+		candidates, err := s.listInvoiceReminderCandidates(ctx, offset, now, quiet)
+		if err != nil {
+			s.logSchedulerError(ctx, run, "scheduler.invoice_reminders.list_failed", "invoice_reminders", 0, err, zap.Int("offset_days", offset))
+			jobErr = errors.Join(jobErr, err)
+			continue
+		}
+		for _, candidate := range candidates {
+			sent, err := s.sendInvoiceReminder(ctx, candidate, offset, now)
+			if err != nil {
+				s.logSchedulerError(ctx, run, "scheduler.invoice_reminders.send_failed", "invoice_reminders", candidate.OrgID, err,
+					zap.String("invoice_id", candidate.InvoiceID.String()),
+					zap.Int("offset_days", offset),
+				)
+				jobErr = errors.Join(jobErr, err)
+				continue
+			}
+			if sent && run != nil {
+				run.AddProcessed(1)
+			}
+		}
+	}
+	return jobErr
+}
+
+// listInvoiceReminderCandidates returns unpaid invoices whose reminder for
+// offset fell due within the last invoiceReminderWindow and has not been sent.
+// Orgs whose timezone is in quiet, as returned by quietReminderTimezones, are
+// left out before the batch limit, so they do not crowd out orgs that can be
+// mailed now.
+func (s *Scheduler) listInvoiceReminderCandidates(ctx context.Context, offset int, now time.Time, quiet []string) ([]invoiceReminderCandidate, error) {
+	dueBy := now.AddDate(0, 0, -offset)
+	args := []any{
+		invoicedomain.InvoiceStatusFinalized,
+		dueBy,
+		dueBy.Add(-invoiceReminderWindow),
+		offset,
+	}
+	quietFilter := ""
+	if len(quiet) > 0 {
+		quietFilter = "AND COALESCE(p.timezone, '') NOT IN ?"
+		args = append(args, quiet)
+	}
+	args = append(args, s.cfg.BatchSize)
+
+	var rows []invoiceReminderCandidate
+	err := s.db.WithContext(ctx).Raw(
+		`SELECT i.id AS invoice_id, i.org_id, i.customer_id, i.invoice_number, i.total_amount, i.currency, i.due_at,
+		        c.email AS customer_email, o.name AS org_name, o.support_email
+		 FROM invoices i
+		 JOIN customers c ON c.id = i.customer_id AND c.org_id = i.org_id AND c.deleted_at IS NULL
+		 JOIN organizations o ON o.id = i.org_id
+		 LEFT JOIN organization_billing_preferences p ON p.org_id = i.org_id
+		 WHERE i.status = ?
+		   AND i.paid_at IS NULL
+		   AND i.voided_at IS NULL
+		   AND i.due_at IS NOT NULL
+		   AND i.due_at <= ?
+		   AND i.due_at > ?
+		   AND COALESCE(p.invoice_reminders_opt_out, false) = false
+		   AND c.email <> ''
+		   AND c.do_not_contact = false
+		   AND NOT EXISTS (
+		     SELECT 1 FROM invoice_reminders_sent r
+		     WHERE r.invoice_id = i.id AND r.offset_days = ?
+		   )
+		   `+quietFilter+`
+		 ORDER BY i.due_at ASC
+		 LIMIT ?`,
+		args...,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+// quietReminderTimezones returns the org timezones, as stored, for which now
+// falls in the configured quiet window. The empty timezone of orgs without
+// billing preferences is included when UTC is quiet.
+func (s *Scheduler) quietReminderTimezones(ctx context.Context, now time.Time) ([]string, error) {
+	if s.cfg.InvoiceReminderQuietStart == s.cfg.InvoiceReminderQuietEnd {
+		return nil, nil
+	}
+	var timezones []string
+	if err := s.db.WithContext(ctx).Raw(
+		`SELECT DISTINCT COALESCE(timezone, '') FROM organization_billing_preferences`,
+	).Scan(&timezones).Error; err != nil {
+		return nil, err
+	}
+	if !slices.Contains(timezones, "") {
+		timezones = append(timezones, "")
+	}
+
+	var quiet []string
+	for _, timezone := range timezones {
+		if s.inReminderQuietHours(now, timezone) {
+			quiet = append(quiet, timezone)
+		}
+	}
+	return quiet, nil
+}
+
+// sendInvoiceReminder claims the reminder and sends it. It reports false when
+// another run already claimed it. A failed send releases the claim so the
+// next run retries.
+func (s *Scheduler) sendInvoiceReminder(ctx context.Context, candidate invoiceReminderCandidate, offset int, now time.Time) (bool, error) {
+	claim := s.db.WithContext(ctx).Exec(
+		`INSERT INTO invoice_reminders_sent (invoice_id, offset_days, org_id, sent_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT DO NOTHING`,
+		candidate.InvoiceID,
+		offset,
+		candidate.OrgID,
+		now,
+	)
+	if claim.Error != nil {
+		return false, claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		return false, nil
+	}
+
+	total := money.Format(candidate.TotalAmount, candidate.Currency)
+	data := struct {
+		OrgName         string
+		Total           string
+		DueDate         string
+		DueStatus       string
+		InvoiceNumber   string
+		OrgContactEmail string
+	}{
+		OrgName:         candidate.OrgName,
+		Total:           total,
+		DueDate:         candidate.DueAt.Format("January 2, 2006"),
+		DueStatus:       reminderDueStatus(offset),
+		InvoiceNumber:   candidate.InvoiceNumber,
+		OrgContactEmail: strings.TrimSpace(candidate.SupportEmail),
+	}
+	var messageID string
+	if s.emailDeliverySvc != nil {
+		messageID = emaildeliverydomain.NewMessageID(s.genID.Generate())
+	}
+	msg := email.EmailMessage{
+		To:         []string{strings.TrimSpace(candidate.CustomerEmail)},
+		MessageID:  messageID,
+		SenderName: candidate.OrgName,
+		ReplyTo:    data.OrgContactEmail,
+		Subject:    fmt.Sprintf("Reminder: invoice #%s from %s %s", candidate.InvoiceNumber, candidate.OrgName, data.DueStatus),
+	}
+
+	if err := s.email.SendTemplate(ctx, msg, "invoice_reminder", data); err != nil {
+		if releaseErr := s.db.WithContext(ctx).Exec(
+			`DELETE FROM invoice_reminders_sent WHERE invoice_id = ? AND offset_days = ?`,
+			candidate.InvoiceID,
+			offset,
+		).Error; releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
+		return false, err
+	}
+
+	if s.emailDeliverySvc != nil {
+		if err := s.emailDeliverySvc.RecordSent(ctx, emaildeliverydomain.RecordSentRequest{
+			OrgID:      candidate.OrgID,
+			MessageID:  messageID,
+			CustomerID: &candidate.CustomerID,
+			InvoiceID:  &candidate.InvoiceID,
+			Recipient:  msg.To[0],
+			Type:       emaildeliverydomain.TypeReminder,
+		}); err != nil {
+			s.log.Warn("failed to record reminder email delivery",
+				zap.String("invoice_id", candidate.InvoiceID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+	return true, nil
+}
+
+// reminderDueStatus describes the invoice's due date relative to an offset.
+func reminderDueStatus(offset int) string {
+	switch {
+	case offset < -1:
+		return fmt.Sprintf("is due in %d days", -offset)
+	case offset == -1:
+		return "is due tomorrow"
+	case offset == 0:
+		return "is due today"
+	case offset == 1:
+		return "is 1 day overdue"
+	default:
+		return fmt.Sprintf("is %d days overdue", offset)
+	}
+}
+
+// inReminderQuietHours reports whether now falls in the configured quiet
+// window in the org's timezone. Orgs without a valid timezone use UTC.
+func (s *Scheduler) inReminderQuietHours(now time.Time, timezone string) bool {
+	start, end := s.cfg.InvoiceReminderQuietStart, s.cfg.InvoiceReminderQuietEnd
+	if start == end {
+		return false
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(timezone))
+	if err != nil {
+		loc = time.UTC
+	}
+	hour := now.In(loc).Hour()
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
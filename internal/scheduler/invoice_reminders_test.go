@@ -0,0 +1,255 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/providers/email"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type recordingEmailProvider struct {
+	sent []email.EmailMessage
+	err  error
+}
+
+func (p *recordingEmailProvider) Send(ctx context.Context, msg email.EmailMessage) error {
+	return p.SendTemplate(ctx, msg, "", nil)
+}
+
+func (p *recordingEmailProvider) SendTemplate(_ context.Context, msg email.EmailMessage, _ string, _ interface{}) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+func newInvoiceReminderTestScheduler(t *testing.T, now time.Time, provider email.Provider) *Scheduler {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	for _, stmt := range []string{
+		`CREATE TABLE invoices (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			customer_id BIGINT NOT NULL,
+			invoice_number TEXT NOT NULL,
+			status TEXT NOT NULL,
+			total_amount BIGINT NOT NULL,
+			currency TEXT NOT NULL,
+			due_at DATETIME,
+			paid_at DATETIME,
+			voided_at DATETIME
+		)`,
+		`CREATE TABLE customers (
+			id BIGINT PRIMARY KEY,
+			org_id BIGINT NOT NULL,
+			email TEXT NOT NULL,
+			do_not_contact BOOLEAN NOT NULL DEFAULT false,
+			deleted_at DATETIME
+		)`,
+		`CREATE TABLE organizations (
+			id BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			support_email TEXT
+		)`,
+		`CREATE TABLE organization_billing_preferences (
+			org_id BIGINT PRIMARY KEY,
+			timezone TEXT NOT NULL,
+			invoice_reminders_opt_out BOOLEAN NOT NULL DEFAULT false
+		)`,
+		`CREATE TABLE invoice_reminders_sent (
+			invoice_id BIGINT NOT NULL,
+			offset_days INT NOT NULL,
+			org_id BIGINT NOT NULL,
+			sent_at DATETIME NOT NULL,
+			PRIMARY KEY (invoice_id, offset_days)
+		)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("create table: %v", err)
+		}
+	}
+
+	return &Scheduler{
+		db:    db,
+		log:   zap.NewNop(),
+		cfg:   DefaultConfig(),
+		clock: clock.NewFakeClock(now),
+		email: provider,
+	}
+}
+
+func seedReminderInvoice(t *testing.T, s *Scheduler, orgID, invoiceID int64, dueAt time.Time) {
+	t.Helper()
+	if err := s.db.Exec(
+		`INSERT INTO customers (id, org_id, email) VALUES (?, ?, ?)`,
+		invoiceID+1000, orgID, "billing@customer.test",
+	).Error; err != nil {
+		t.Fatalf("insert customer: %v", err)
+	}
+	if err := s.db.Exec(
+		`INSERT INTO invoices (id, org_id, customer_id, invoice_number, status, total_amount, currency, due_at)
+		 VALUES (?, ?, ?, ?, 'FINALIZED', 12500, 'USD', ?)`,
+		invoiceID, orgID, invoiceID+1000, "INV-1", dueAt,
+	).Error; err != nil {
+		t.Fatalf("insert invoice: %v", err)
+	}
+}
+
+func seedReminderOrg(t *testing.T, s *Scheduler, orgID int64, timezone string, optOut bool) {
+	t.Helper()
+	if err := s.db.Exec(`INSERT INTO organizations (id, name, support_email) VALUES (?, 'Acme', 'help@acme.test')`, orgID).Error; err != nil {
+		t.Fatalf("insert org: %v", err)
+	}
+	if err := s.db.Exec(
+		`INSERT INTO organization_billing_preferences (org_id, timezone, invoice_reminders_opt_out) VALUES (?, ?, ?)`,
+		orgID, timezone, optOut,
+	).Error; err != nil {
+		t.Fatalf("insert billing preferences: %v", err)
+	}
+}
+
+func TestInvoiceRemindersJob_SendsEachOffsetOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	provider := &recordingEmailProvider{}
+	s := newInvoiceReminderTestScheduler(t, now, provider)
+	seedReminderOrg(t, s, 1, "UTC", false)
+	// Due in three days: only the -3 reminder is due.
+	seedReminderInvoice(t, s, 1, 10, now.AddDate(0, 0, 3).Add(-time.Hour))
+
+	for i := 0; i < 2; i++ {
+		if err := s.InvoiceRemindersJob(context.Background()); err != nil {
+			t.Fatalf("InvoiceRemindersJob: %v", err)
+		}
+	}
+
+	if len(provider.sent) != 1 {
+		t.Fatalf("expected 1 reminder, got %d", len(provider.sent))
+	}
+	msg := provider.sent[0]
+	if len(msg.To) != 1 || msg.To[0] != "billing@customer.test" || msg.ReplyTo != "help@acme.test" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	var offset int
+	if err := s.db.Raw(`SELECT offset_days FROM invoice_reminders_sent WHERE invoice_id = 10`).Scan(&offset).Error; err != nil {
+		t.Fatalf("read reminders sent: %v", err)
+	}
+	if offset != -3 {
+		t.Fatalf("expected offset -3 recorded, got %d", offset)
+	}
+}
+
+func TestInvoiceRemindersJob_Skips(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("opted out org", func(t *testing.T) {
+		provider := &recordingEmailProvider{}
+		s := newInvoiceReminderTestScheduler(t, now, provider)
+		seedReminderOrg(t, s, 1, "UTC", true)
+		seedReminderInvoice(t, s, 1, 10, now.Add(-time.Hour))
+
+		if err := s.InvoiceRemindersJob(context.Background()); err != nil {
+			t.Fatalf("InvoiceRemindersJob: %v", err)
+		}
+		if len(provider.sent) != 0 {
+			t.Fatalf("expected no reminders, got %d", len(provider.sent))
+		}
+	})
+
+	t.Run("do not contact customer", func(t *testing.T) {
+		provider := &recordingEmailProvider{}
+		s := newInvoiceReminderTestScheduler(t, now, provider)
+		seedReminderOrg(t, s, 1, "UTC", false)
+		seedReminderInvoice(t, s, 1, 10, now.Add(-time.Hour))
+		if err := s.db.Exec(`UPDATE customers SET do_not_contact = true WHERE id = ?`, 1010).Error; err != nil {
+			t.Fatalf("flag customer: %v", err)
+		}
+
+		if err := s.InvoiceRemindersJob(context.Background()); err != nil {
+			t.Fatalf("InvoiceRemindersJob: %v", err)
+		}
+		if len(provider.sent) != 0 {
+			t.Fatalf("expected no reminders, got %d", len(provider.sent))
+		}
+	})
+
+	t.Run("quiet hours in org timezone", func(t *testing.T) {
+		provider := &recordingEmailProvider{}
+		s := newInvoiceReminderTestScheduler(t, now, provider)
+		// 12:00 UTC is 21:00 in Tokyo.
+		seedReminderOrg(t, s, 1, "Asia/Tokyo", false)
+		seedReminderInvoice(t, s, 1, 10, now.Add(-time.Hour))
+
+		if err := s.InvoiceRemindersJob(context.Background()); err != nil {
+			t.Fatalf("InvoiceRemindersJob: %v", err)
+		}
+		if len(provider.sent) != 0 {
+			t.Fatalf("expected no reminders, got %d", len(provider.sent))
+		}
+	})
+
+	t.Run("quiet orgs do not fill the batch", func(t *testing.T) {
+		provider := &recordingEmailProvider{}
+		s := newInvoiceReminderTestScheduler(t, now, provider)
+		s.cfg.BatchSize = 1
+		seedReminderOrg(t, s, 1, "Asia/Tokyo", false)
+		seedReminderOrg(t, s, 2, "UTC", false)
+		// The quiet org's invoice is due first, so it would take the
+		// only slot if quiet hours were applied after the limit.
+		seedReminderInvoice(t, s, 1, 10, now.Add(-2*time.Hour))
+		seedReminderInvoice(t, s, 2, 20, now.Add(-time.Hour))
+
+		if err := s.InvoiceRemindersJob(context.Background()); err != nil {
+			t.Fatalf("InvoiceRemindersJob: %v", err)
+		}
+		var sent []int64
+		if err := s.db.Raw(`SELECT invoice_id FROM invoice_reminders_sent`).Scan(&sent).Error; err != nil {
+			t.Fatalf("read reminders sent: %v", err)
+		}
+		if len(sent) != 1 || sent[0] != 20 {
+			t.Fatalf("expected only invoice 20 reminded, got %v", sent)
+		}
+	})
+
+	t.Run("failed send is retried", func(t *testing.T) {
+		provider := &recordingEmailProvider{err: errors.New("smtp down")}
+		s := newInvoiceReminderTestScheduler(t, now, provider)
+		seedReminderOrg(t, s, 1, "UTC", false)
+		seedReminderInvoice(t, s, 1, 10, now.Add(-time.Hour))
+
+		if err := s.InvoiceRemindersJob(context.Background()); err == nil {
+			t.Fatal("expected send error")
+		}
+		provider.err = nil
+		if err := s.InvoiceRemindersJob(context.Background()); err != nil {
+			t.Fatalf("InvoiceRemindersJob: %v", err)
+		}
+		if len(provider.sent) != 1 {
+			t.Fatalf("expected 1 reminder after retry, got %d", len(provider.sent))
+		}
+	})
+}
+
+func TestParseQuietHours(t *testing.T) {
+	start, end, ok := parseQuietHours("21-8")
+	if !ok || start != 21 || end != 8 {
+		t.Fatalf("unexpected parse: %d %d %v", start, end, ok)
+	}
+	if _, _, ok := parseQuietHours("25-8"); ok {
+		t.Fatal("expected out of range hour to be rejected")
+	}
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errJobSkipped is returned by a job that did not run this pass because
+// another replica holds its lock. Its dependents are skipped too, so they
+// never run ahead of the replica still working on the dependency.
+var errJobSkipped = errors.New("job skipped")
+
+// scheduledJob is one entry of a RunOnce pass. DependsOn names jobs of the
+// same pass that must finish first; disabled or unknown dependencies are
+// treated as finished. A failed dependency does not skip its dependents,
+// matching the sequential runner, but a skipped one does.
+type scheduledJob struct {
+	Name      string
+	Enabled   bool
+	DependsOn []string
+	Run       func(context.Context) error
+}
+
+// jobState tracks one enabled job of a concurrent pass. skipped is set before
+// done is closed, so dependents read it once done is closed.
+type jobState struct {
+	done    chan struct{}
+	skipped bool
+}
+
+// runJobs runs the enabled jobs and joins their errors in declaration order.
+// With MaxConcurrentJobs of 1 they run one after another; otherwise up to
+// MaxConcurrentJobs run at once, each starting only after its dependencies
+// finished. Jobs waiting on a dependency do not hold a slot. Once the
+// scheduler is stopping, jobs that have not started yet are skipped.
+func (s *Scheduler) runJobs(parent context.Context, jobs []scheduledJob) error {
+	if s.cfg.MaxConcurrentJobs <= 1 {
+		var err error
+		skipped := make(map[string]bool, len(jobs))
+		for _, job := range jobs {
+			if stopping(parent) {
+				break
+			}
+			if !job.Enabled {
+				continue
+			}
+			if dependencySkipped(job, func(dep string) bool { return skipped[dep] }) {
+				skipped[job.Name] = true
+				continue
+			}
+			runErr := job.Run(parent)
+			if errors.Is(runErr, errJobSkipped) {
+				skipped[job.Name] = true
+				continue
+			}
+			err = errors.Join(err, runErr)
+		}
+		return err
+	}
+
+	states := make(map[string]*jobState, len(jobs))
+	for _, job := range jobs {
+		if job.Enabled {
+			states[job.Name] = &jobState{done: make(chan struct{})}
+		}
+	}
+
+	slots := make(chan struct{}, s.cfg.MaxConcurrentJobs)
+	errs := make([]error, len(jobs))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		if !job.Enabled {
+			continue
+		}
+		state := states[job.Name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(state.done)
+
+			for _, dep := range job.DependsOn {
+				if depState, ok := states[dep]; ok {
+					<-depState.done
+				}
+			}
+			if dependencySkipped(job, func(dep string) bool {
+				depState, ok := states[dep]
+				return ok && depState.skipped
+			}) {
+				state.skipped = true
+				return
+			}
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if stopping(parent) {
+				return
+			}
+			err := job.Run(parent)
+			if errors.Is(err, errJobSkipped) {
+				state.skipped = true
+				return
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// dependencySkipped reports whether any of job's dependencies was skipped.
+func dependencySkipped(job scheduledJob, skipped func(dep string) bool) bool {
+	for _, dep := range job.DependsOn {
+		if skipped(dep) {
+			return true
+		}
+	}
+	return false
+}
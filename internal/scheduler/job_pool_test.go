@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunJobs_BoundsConcurrencyAndKeepsDependencies(t *testing.T) {
+	s := &Scheduler{cfg: Config{MaxConcurrentJobs: 2}}
+
+	var (
+		mu       sync.Mutex
+		finished []string
+		running  atomic.Int32
+		peak     atomic.Int32
+	)
+	job := func(name string, deps ...string) scheduledJob {
+		return scheduledJob{Name: name, Enabled: true, DependsOn: deps, Run: func(context.Context) error {
+			if n := running.Add(1); n > peak.Load() {
+				peak.Store(n)
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+
+			mu.Lock()
+			defer mu.Unlock()
+			finished = append(finished, name)
+			if name == "rating" {
+				return errors.New("rating failed")
+			}
+			return nil
+		}}
+	}
+
+	err := s.runJobs(context.Background(), []scheduledJob{
+		job("ensure_cycles"),
+		job("close_cycles", "ensure_cycles"),
+		job("rating", "close_cycles"),
+		job("invoice", "rating", "disabled"),
+		{Name: "disabled", DependsOn: nil, Run: func(context.Context) error {
+			t.Error("disabled job ran")
+			return nil
+		}},
+		job("lag_probe"),
+		job("sla_evaluation"),
+		job("finops_scoring"),
+	})
+	if err == nil || err.Error() != "rating failed" {
+		t.Fatalf("expected joined rating error, got %v", err)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Fatalf("expected at most 2 concurrent jobs, got %d", got)
+	}
+	if len(finished) != 7 {
+		t.Fatalf("expected 7 jobs to run, got %v", finished)
+	}
+
+	position := make(map[string]int, len(finished))
+	for i, name := range finished {
+		position[name] = i
+	}
+	chain := []string{"ensure_cycles", "close_cycles", "rating", "invoice"}
+	for i := 1; i < len(chain); i++ {
+		if position[chain[i-1]] > position[chain[i]] {
+			t.Fatalf("%s ran before %s: %v", chain[i], chain[i-1], finished)
+		}
+	}
+}
+
+func TestRunJobs_SequentialByDefault(t *testing.T) {
+	s := &Scheduler{cfg: Config{}.withDefaults()}
+
+	var order []string
+	job := func(name string) scheduledJob {
+		return scheduledJob{Name: name, Enabled: true, Run: func(context.Context) error {
+			order = append(order, name)
+			return errors.New(name)
+		}}
+	}
+	err := s.runJobs(context.Background(), []scheduledJob{job("a"), job("b"), job("c")})
+
+	if len(order) != 3 || order[0] != "a" || order[1] != "b" || order[2] != "c" {
+		t.Fatalf("expected jobs in order, got %v", order)
+	}
+	if err == nil || err.Error() != "a\nb\nc" {
+		t.Fatalf("expected errors joined in order, got %v", err)
+	}
+}
+
+func TestRunJobs_SkippedDependencyBlocksDependents(t *testing.T) {
+	for _, maxConcurrent := range []int{1, 4} {
+		s := &Scheduler{cfg: Config{MaxConcurrentJobs: maxConcurrent}}
+
+		var (
+			mu  sync.Mutex
+			ran []string
+		)
+		job := func(name string, result error, deps ...string) scheduledJob {
+			return scheduledJob{Name: name, Enabled: true, DependsOn: deps, Run: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, name)
+				return result
+			}}
+		}
+
+		err := s.runJobs(context.Background(), []scheduledJob{
+			job("close_cycles", nil),
+			job("rating", errJobSkipped, "close_cycles"),
+			job("close_after_rating", nil, "rating"),
+			job("invoice", nil, "close_after_rating"),
+			job("lag_probe", nil),
+		})
+		if err != nil {
+			t.Fatalf("max %d: expected skipped jobs not to report an error, got %v", maxConcurrent, err)
+		}
+		if len(ran) != 3 {
+			t.Fatalf("max %d: expected close_cycles, rating and lag_probe only, got %v", maxConcurrent, ran)
+		}
+		for _, name := range ran {
+			if name == "close_after_rating" || name == "invoice" {
+				t.Fatalf("max %d: %s ran after its dependency was skipped: %v", maxConcurrent, name, ran)
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultJobRunLimit = 50
+	maxJobRunLimit     = 500
+)
+
+// JobRun is a persisted record of a single scheduler job execution.
+type JobRun struct {
+	RunID          string    `json:"run_id"`
+	JobName        string    `json:"job_name"`
+	BatchSize      int       `json:"batch_size"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	DurationMS     int64     `json:"duration_ms"`
+	ProcessedCount int       `json:"processed_count"`
+	ErrorCount     int       `json:"error_count"`
+}
+
+// JobRunStats aggregates the most recent runs of a single job.
+//
+// ErrorRate is the share of runs that logged at least one error.
+type JobRunStats struct {
+	JobName         string  `json:"job_name"`
+	Runs            int     `json:"runs"`
+	FailedRuns      int     `json:"failed_runs"`
+	AvgDurationMS   float64 `json:"avg_duration_ms"`
+	ErrorRate       float64 `json:"error_rate"`
+	TotalProcessed  int     `json:"total_processed"`
+	TotalErrorCount int     `json:"total_error_count"`
+}
+
+type jobRunRow struct {
+	RunID          string
+	JobName        string
+	BatchSize      int
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	ProcessedCount int
+	ErrorCount     int
+}
+
+// recordJobRun persists a finished run. It is best effort: job history must
+// never fail or block the job itself.
+func (s *Scheduler) recordJobRun(ctx context.Context, run *jobRun, finishedAt time.Time) {
+	if run == nil || s.db == nil {
+		return
+	}
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+
+	if err := s.db.WithContext(writeCtx).Exec(
+		`INSERT INTO scheduler_job_runs (
+			id, run_id, job_name, batch_size, started_at, finished_at,
+			processed_count, error_count, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.genID.Generate(),
+		run.runID,
+		run.job,
+		run.batchSize,
+		run.startedAt.UTC(),
+		finishedAt.UTC(),
+		run.processedCount,
+		run.errorCount,
+		finishedAt.UTC(),
+	).Error; err != nil {
+		s.logger(ctx).Warn("scheduler.job_run.record_failed",
+			zap.String("job", run.job),
+			zap.String("run_id", run.runID),
+			zap.Error(err),
+		)
+	}
+}
+
+// jobRunCleanupBatchSize bounds how many job runs one delete removes.
+const jobRunCleanupBatchSize = 1000
+
+// purgeJobRuns deletes up to limit job runs that started before cutoff and
+// returns how many were deleted.
+func (s *Scheduler) purgeJobRuns(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	result := s.db.WithContext(ctx).Exec(
+		`DELETE FROM scheduler_job_runs
+		 WHERE id IN (
+			SELECT id
+			FROM scheduler_job_runs
+			WHERE started_at < ?
+			LIMIT ?
+		 )`,
+		cutoff.UTC(),
+		limit,
+	)
+	return result.RowsAffected, result.Error
+}
+
+// JobRunCleanupJob deletes job run history older than JobRunRetention.
+// Every job records a run per tick, so the table grows without it.
+func (s *Scheduler) JobRunCleanupJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "job_run_cleanup", jobRunCleanupBatchSize)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+
+	cutoff := s.clock.Now().UTC().Add(-s.cfg.JobRunRetention)
+	for {
+		deleted, err := s.purgeJobRuns(ctx, cutoff, jobRunCleanupBatchSize)
+		run.AddProcessed(int(deleted))
+		if err != nil {
+			s.logSchedulerError(ctx, run, "job_run_cleanup.failed", "job_run_cleanup", 0, err)
+			return err
+		}
+		if deleted < jobRunCleanupBatchSize {
+			return nil
+		}
+	}
+}
+
+// ListJobRuns returns recent runs ordered by start time, newest first. An
+// empty jobName lists runs across all jobs.
+func (s *Scheduler) ListJobRuns(ctx context.Context, jobName string, limit int) ([]JobRun, error) {
+	limit = normalizeJobRunLimit(limit)
+	query := s.db.WithContext(ctx).
+		Table("scheduler_job_runs").
+		Select("run_id, job_name, batch_size, started_at, finished_at, processed_count, error_count")
+	if name := strings.TrimSpace(jobName); name != "" {
+		query = query.Where("job_name = ?", name)
+	}
+
+	var rows []jobRunRow
+	if err := query.Order("started_at DESC").Order("id DESC").Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	runs := make([]JobRun, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, JobRun{
+			RunID:          row.RunID,
+			JobName:        row.JobName,
+			BatchSize:      row.BatchSize,
+			StartedAt:      row.StartedAt.UTC(),
+			FinishedAt:     row.FinishedAt.UTC(),
+			DurationMS:     row.FinishedAt.Sub(row.StartedAt).Milliseconds(),
+			ProcessedCount: row.ProcessedCount,
+			ErrorCount:     row.ErrorCount,
+		})
+	}
+	return runs, nil
+}
+
+// GetJobRunStats aggregates the last lastN runs for each job. An empty
+// jobName returns stats for every job that has recorded history.
+func (s *Scheduler) GetJobRunStats(ctx context.Context, jobName string, lastN int) ([]JobRunStats, error) {
+	var jobs []string
+	if name := strings.TrimSpace(jobName); name != "" {
+		jobs = []string{name}
+	} else if err := s.db.WithContext(ctx).
+		Table("scheduler_job_runs").
+		Distinct("job_name").
+		Order("job_name ASC").
+		Pluck("job_name", &jobs).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make([]JobRunStats, 0, len(jobs))
+	for _, job := range jobs {
+		runs, err := s.ListJobRuns(ctx, job, lastN)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, summarizeJobRuns(job, runs))
+	}
+	return stats, nil
+}
+
+func summarizeJobRuns(jobName string, runs []JobRun) JobRunStats {
+	stats := JobRunStats{JobName: jobName, Runs: len(runs)}
+	if len(runs) == 0 {
+		return stats
+	}
+
+	var totalDuration int64
+	for _, run := range runs {
+		totalDuration += run.DurationMS
+		stats.TotalProcessed += run.ProcessedCount
+		stats.TotalErrorCount += run.ErrorCount
+		if run.ErrorCount > 0 {
+			stats.FailedRuns++
+		}
+	}
+	stats.AvgDurationMS = float64(totalDuration) / float64(len(runs))
+	stats.ErrorRate = float64(stats.FailedRuns) / float64(len(runs))
+	return stats
+}
+
+func normalizeJobRunLimit(limit int) int {
+	if limit <= 0 {
+		return defaultJobRunLimit
+	}
+	if limit > maxJobRunLimit {
+		return maxJobRunLimit
+	}
+	return limit
+}
@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newJobRunTestScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.Exec(`
+		CREATE TABLE scheduler_job_runs (
+			id INTEGER PRIMARY KEY,
+			run_id TEXT NOT NULL,
+			job_name TEXT NOT NULL,
+			batch_size INTEGER NOT NULL DEFAULT 0,
+			started_at DATETIME NOT NULL,
+			finished_at DATETIME NOT NULL,
+			processed_count INTEGER NOT NULL DEFAULT 0,
+			error_count INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL
+		)
+	`).Error; err != nil {
+		t.Fatalf("create scheduler_job_runs table: %v", err)
+	}
+	node, err := snowflake.NewNode(1)
+	if err != nil {
+		t.Fatalf("snowflake node: %v", err)
+	}
+	return &Scheduler{db: db, genID: node, log: zap.NewNop()}
+}
+
+func TestListJobRunsAndStats(t *testing.T) {
+	s := newJobRunTestScheduler(t)
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	record := func(job string, offset time.Duration, duration time.Duration, processed, errs int) {
+		run := &jobRun{
+			job:            job,
+			runID:          s.genID.Generate().String(),
+			batchSize:      25,
+			startedAt:      base.Add(offset),
+			processedCount: processed,
+			errorCount:     errs,
+		}
+		s.recordJobRun(ctx, run, run.startedAt.Add(duration))
+	}
+	record("invoice", 0, 2*time.Second, 10, 0)
+	record("invoice", time.Minute, 4*time.Second, 5, 2)
+	record("invoice", 2*time.Minute, 6*time.Second, 0, 0)
+	record("rating", time.Minute, time.Second, 3, 0)
+
+	runs, err := s.ListJobRuns(ctx, "invoice", 2)
+	if err != nil {
+		t.Fatalf("ListJobRuns: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	if !runs[0].StartedAt.Equal(base.Add(2*time.Minute)) || !runs[1].StartedAt.Equal(base.Add(time.Minute)) {
+		t.Fatalf("expected runs newest first, got %v then %v", runs[0].StartedAt, runs[1].StartedAt)
+	}
+	if runs[1].DurationMS != 4000 || runs[1].ErrorCount != 2 || runs[1].ProcessedCount != 5 {
+		t.Fatalf("unexpected run payload: %+v", runs[1])
+	}
+
+	all, err := s.ListJobRuns(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("ListJobRuns all: %v", err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("expected 4 runs across jobs, got %d", len(all))
+	}
+
+	stats, err := s.GetJobRunStats(ctx, "", 3)
+	if err != nil {
+		t.Fatalf("GetJobRunStats: %v", err)
+	}
+	if len(stats) != 2 || stats[0].JobName != "invoice" || stats[1].JobName != "rating" {
+		t.Fatalf("expected stats for invoice and rating, got %+v", stats)
+	}
+	invoice := stats[0]
+	if invoice.Runs != 3 || invoice.FailedRuns != 1 || invoice.TotalProcessed != 15 || invoice.TotalErrorCount != 2 {
+		t.Fatalf("unexpected invoice stats: %+v", invoice)
+	}
+	if invoice.AvgDurationMS != 4000 {
+		t.Fatalf("expected avg duration 4000ms, got %v", invoice.AvgDurationMS)
+	}
+	if invoice.ErrorRate < 0.333 || invoice.ErrorRate > 0.334 {
+		t.Fatalf("expected error rate 1/3, got %v", invoice.ErrorRate)
+	}
+}
+
+func TestSummarizeJobRunsEmpty(t *testing.T) {
+	stats := summarizeJobRuns("invoice", nil)
+	if stats.Runs != 0 || stats.ErrorRate != 0 || stats.AvgDurationMS != 0 {
+		t.Fatalf("expected zero stats, got %+v", stats)
+	}
+}
+
+func TestPurgeJobRuns(t *testing.T) {
+	s := newJobRunTestScheduler(t)
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		run := &jobRun{job: "invoice", runID: s.genID.Generate().String(), startedAt: base.Add(time.Duration(i) * 24 * time.Hour)}
+		s.recordJobRun(ctx, run, run.startedAt.Add(time.Second))
+	}
+
+	deleted, err := s.purgeJobRuns(ctx, base.Add(3*24*time.Hour), 2)
+	if err != nil {
+		t.Fatalf("purgeJobRuns: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected the batch limit of 2 deleted, got %d", deleted)
+	}
+	deleted, err = s.purgeJobRuns(ctx, base.Add(3*24*time.Hour), 2)
+	if err != nil {
+		t.Fatalf("purgeJobRuns: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected the last old run deleted, got %d", deleted)
+	}
+
+	runs, err := s.ListJobRuns(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("ListJobRuns: %v", err)
+	}
+	if len(runs) != 2 || runs[1].StartedAt.Before(base.Add(3*24*time.Hour)) {
+		t.Fatalf("expected only the runs inside the retention window, got %+v", runs)
+	}
+}
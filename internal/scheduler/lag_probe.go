@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+)
+
+// lagProbeTimeout bounds the probe read on its own, well inside the job
+// timeout, so a slow billing_cycles scan is given up on instead of holding
+// the job slot. The gauges keep their last values until the next run.
+const lagProbeTimeout = 5 * time.Second
+
+// LagProbeJob publishes, per org, how long the oldest open cycle whose period
+// has ended has been waiting to close. It runs one grouped read and takes no
+// locks, so it stays cheap next to the cycle jobs.
+func (s *Scheduler) LagProbeJob(ctx context.Context) error {
+	ages, err := s.oldestOpenCycleAges(ctx, s.clock.Now().UTC())
+	if err != nil {
+		s.logSchedulerError(ctx, jobRunFromContext(ctx), "scheduler.lag_probe.failed", "lag_probe", 0, err)
+		return err
+	}
+	obsmetrics.Scheduler().SetOldestOpenCycleAges(ages)
+	return nil
+}
+
+// oldestOpenCycleAges returns now - min(period_end) of ended open cycles,
+// keyed by org ID. The read is bounded by lagProbeTimeout.
+func (s *Scheduler) oldestOpenCycleAges(ctx context.Context, now time.Time) (map[string]time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, lagProbeTimeout)
+	defer cancel()
+
+	var rows []struct {
+		OrgID           snowflake.ID
+		OldestPeriodEnd time.Time
+	}
+	if err := s.db.WithContext(ctx).Raw(
+		`SELECT org_id, MIN(period_end) AS oldest_period_end
+		 FROM billing_cycles
+		 WHERE status = ? AND period_end <= ?
+		 GROUP BY org_id`,
+		billingcycledomain.BillingCycleStatusOpen,
+		now,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ages := make(map[string]time.Duration, len(rows))
+	for _, row := range rows {
+		ages[row.OrgID.String()] = now.Sub(row.OldestPeriodEnd)
+	}
+	return ages, nil
+}
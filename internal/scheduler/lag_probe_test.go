@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestOldestOpenCycleAges runs the lag probe query against SCHEDULER_PG_DSN,
+// with its cycles seeded in a transaction that is rolled back. Only open
+// cycles whose period has ended count, and an org without one is left out.
+func TestOldestOpenCycleAges(t *testing.T) {
+	dsn := strings.TrimSpace(os.Getenv("SCHEDULER_PG_DSN"))
+	if dsn == "" {
+		t.Skip("SCHEDULER_PG_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("begin: %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+
+	now := time.Now().UTC().Truncate(time.Second)
+	const lagging, current = -2, -3
+	cycles := []struct {
+		id, orgID int64
+		periodEnd time.Time
+		status    billingcycledomain.BillingCycleStatus
+	}{
+		{-1, lagging, now.Add(-3 * time.Hour), billingcycledomain.BillingCycleStatusOpen},
+		{-2, lagging, now.Add(-time.Hour), billingcycledomain.BillingCycleStatusOpen},
+		{-3, lagging, now.Add(-10 * time.Hour), billingcycledomain.BillingCycleStatusClosed},
+		{-4, lagging, now.Add(time.Hour), billingcycledomain.BillingCycleStatusOpen},
+		{-5, current, now.Add(time.Hour), billingcycledomain.BillingCycleStatusOpen},
+	}
+	for _, c := range cycles {
+		// Each cycle gets its own subscription: one may only have one open cycle.
+		if err := tx.Exec(
+			`INSERT INTO billing_cycles (id, org_id, subscription_id, period_start, period_end, status)
+			 VALUES (?, ?, ?, ?, ?, ?)`,
+			c.id, c.orgID, c.id, c.periodEnd.AddDate(0, -1, 0), c.periodEnd, c.status,
+		).Error; err != nil {
+			t.Fatalf("seed billing cycle: %v", err)
+		}
+	}
+
+	s := &Scheduler{db: tx, log: zap.NewNop()}
+	ages, err := s.oldestOpenCycleAges(context.Background(), now)
+	if err != nil {
+		t.Fatalf("oldest open cycle ages: %v", err)
+	}
+	if got := ages[snowflake.ID(lagging).String()]; got != 3*time.Hour {
+		t.Fatalf("lagging org age = %s, want 3h", got)
+	}
+	if got, ok := ages[snowflake.ID(current).String()]; ok {
+		t.Fatalf("expected no age for an org without ended open cycles, got %s", got)
+	}
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"hash/fnv"
+	"time"
+)
+
+// jobLockReleaseTimeout bounds the unlock query, which runs even when the
+// job's context is already done.
+const jobLockReleaseTimeout = 5 * time.Second
+
+// jobLock is a Postgres session advisory lock on a job name, held on a
+// dedicated connection for the duration of one run. Session locks belong to
+// the connection, so the lock is released on the same one it was taken on.
+type jobLock struct {
+	conn *sql.Conn
+	key  int64
+}
+
+// advisoryLocksAvailable reports whether jobs can be guarded by advisory
+// locks. Other databases (sqlite in tests) run every job on every node.
+func (s *Scheduler) advisoryLocksAvailable() bool {
+	return s.db != nil && s.db.Dialector != nil && s.db.Dialector.Name() == "postgres"
+}
+
+// acquireJobLock tries to take the leadership lock of job without waiting.
+// It reports false when another node holds it. Without advisory locks it
+// reports true with a nil lock, so the job runs unguarded.
+func (s *Scheduler) acquireJobLock(ctx context.Context, job string) (*jobLock, bool, error) {
+	if !s.advisoryLocksAvailable() {
+		return nil, true, nil
+	}
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := jobLockKey(job)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		return nil, false, errors.Join(err, conn.Close())
+	}
+	if !acquired {
+		return nil, false, conn.Close()
+	}
+	return &jobLock{conn: conn, key: key}, true, nil
+}
+
+// release unlocks and returns the connection to the pool. If the unlock
+// fails the connection is discarded instead, which ends the session and the
+// lock with it.
+func (l *jobLock) release(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jobLockReleaseTimeout)
+	defer cancel()
+
+	var released bool
+	err := l.conn.QueryRowContext(ctx, `SELECT pg_advisory_unlock($1)`, l.key).Scan(&released)
+	if err != nil || !released {
+		_ = l.conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	return errors.Join(err, l.conn.Close())
+}
+
+// jobLockKey maps a job name to its advisory lock key. The prefix keeps the
+// keys apart from locks other parts of the system may take.
+func jobLockKey(job string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("railzway.scheduler." + job))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestRunJob_RunsWithoutAdvisoryLocks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	node, err := snowflake.NewNode(1)
+	if err != nil {
+		t.Fatalf("snowflake node: %v", err)
+	}
+	s := &Scheduler{db: db, log: zap.NewNop(), genID: node, clock: clock.NewFakeClock(time.Time{}), cfg: Config{}.withDefaults()}
+	if s.advisoryLocksAvailable() {
+		t.Fatal("expected advisory locks to be unavailable on sqlite")
+	}
+
+	ran := false
+	err = s.runJob(context.Background(), "invoice", 0, time.Minute, func(context.Context) error {
+		ran = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("run job: %v", err)
+	}
+	if !ran {
+		t.Fatal("expected the job to run without a leadership lock")
+	}
+}
+
+func TestJobLockKey(t *testing.T) {
+	if jobLockKey("rating") != jobLockKey("rating") {
+		t.Fatal("expected a stable key per job")
+	}
+	if jobLockKey("rating") == jobLockKey("invoice") {
+		t.Fatal("expected different jobs to get different keys")
+	}
+}
@@ -19,6 +19,7 @@ type WorkSubscription struct {
 	OrgID            snowflake.ID
 	Status           subscriptiondomain.SubscriptionStatus
 	ActivatedAt      *time.Time
+	ResumedAt        *time.Time
 	BillingCycleType string
 }
 
@@ -31,6 +32,8 @@ type WorkBillingCycle struct {
 	Status             billingcycledomain.BillingCycleStatus
 	ClosingStartedAt   *time.Time
 	RatingCompletedAt  *time.Time
+	RatingAttempts     int
+	NextRatingRetryAt  *time.Time
 	InvoicedAt         *time.Time
 	InvoiceFinalizedAt *time.Time
 	ClosedAt           *time.Time
@@ -61,7 +64,7 @@ func (s *Scheduler) fetchSubscriptionsForWork(ctx context.Context, tx *gorm.DB,
 	schedMetrics := obsmetrics.Scheduler()
 	lockStart := time.Now()
 	err := tx.WithContext(ctx).Raw(
-		`SELECT id, org_id, status, activated_at, billing_cycle_type
+		`SELECT id, org_id, status, activated_at, resumed_at, billing_cycle_type
 		 FROM subscriptions
 		 WHERE status = ?
 		 ORDER BY id
@@ -77,16 +80,44 @@ func (s *Scheduler) fetchSubscriptionsForWork(ctx context.Context, tx *gorm.DB,
 	return subscriptions, nil
 }
 
-func (s *Scheduler) fetchBillingCyclesForWork(ctx context.Context, where string, args []any, limit int) ([]WorkBillingCycle, error) {
-	if limit <= 0 {
-		limit = s.cfg.BatchSize
-	}
-	var cycles []WorkBillingCycle
-	schedMetrics := obsmetrics.Scheduler()
-	query := fmt.Sprintf(
+// Billing cycle work predicates passed to fetchBillingCyclesForWork. Each is
+// served by a partial index on (period_end, id) whose WHERE clause it implies
+// (migration 0067); keep the two in sync when changing either.
+const (
+	// Open cycles whose period has ended. Args: OPEN, now.
+	cyclesDueForClose = `status = ? AND period_end <= ?`
+	// Closing cycles awaiting rating whose retry backoff has passed. Args:
+	// CLOSING, now.
+	cyclesPendingRating = `status = ? AND rating_completed_at IS NULL AND (next_rating_retry_at IS NULL OR next_rating_retry_at <= ?)`
+	// Closing cycles stuck in rating since before the cutoff. Args: CLOSING,
+	// cutoff, now.
+	cyclesStuckInRating = `status = ? AND rating_completed_at IS NULL AND closing_started_at IS NOT NULL AND closing_started_at <= ?
+		AND (next_rating_retry_at IS NULL OR next_rating_retry_at <= ?)`
+	// Closing cycles that finished rating. Args: CLOSING.
+	cyclesRated = `status = ? AND rating_completed_at IS NOT NULL`
+	// Closed cycles without an invoice. Args: CLOSED.
+	cyclesPendingInvoice = `status = ? AND invoiced_at IS NULL`
+	// Closed cycles still without an invoice since before the cutoff. Args:
+	// CLOSED, cutoff.
+	cyclesStuckInInvoicing = `status = ? AND invoiced_at IS NULL AND closed_at IS NOT NULL AND closed_at <= ?`
+	// Invoiced cycles whose draft has outlived the finalize grace period.
+	// Args: CLOSED, DRAFT, cutoff.
+	cyclesGracedDrafts = `status = ? AND invoiced_at IS NOT NULL AND invoice_finalized_at IS NULL
+		AND EXISTS (
+			SELECT 1 FROM invoices i
+			WHERE i.billing_cycle_id = billing_cycles.id
+			  AND i.status = ?
+			  AND i.created_at <= ?
+		)`
+)
+
+// billingCyclesForWorkQuery selects and locks the oldest cycles matching
+// where. Its args are the predicate's args followed by the limit.
+func billingCyclesForWorkQuery(where string) string {
+	return fmt.Sprintf(
 		`SELECT id, org_id, subscription_id, period_start, period_end, status,
-		        closing_started_at, rating_completed_at, invoiced_at,
-		        invoice_finalized_at, closed_at
+		        closing_started_at, rating_completed_at, rating_attempts,
+		        next_rating_retry_at, invoiced_at, invoice_finalized_at, closed_at
 		 FROM billing_cycles
 		 WHERE %s
 		 ORDER BY period_end ASC, id ASC
@@ -94,6 +125,15 @@ func (s *Scheduler) fetchBillingCyclesForWork(ctx context.Context, where string,
 		 LIMIT ?`,
 		where,
 	)
+}
+
+func (s *Scheduler) fetchBillingCyclesForWork(ctx context.Context, where string, args []any, limit int) ([]WorkBillingCycle, error) {
+	if limit <= 0 {
+		limit = s.cfg.BatchSize
+	}
+	var cycles []WorkBillingCycle
+	schedMetrics := obsmetrics.Scheduler()
+	query := billingCyclesForWorkQuery(where)
 	args = append(args, limit)
 	lockStart := time.Now()
 	if err := s.db.WithContext(ctx).Raw(query, args...).Scan(&cycles).Error; err != nil {
@@ -114,7 +154,7 @@ func (s *Scheduler) fetchSubscriptionsNeedingCycle(ctx context.Context, tx *gorm
 	// PostgreSQL: FOR UPDATE OF s SKIP LOCKED
 	// MySQL/SQLite: FOR UPDATE SKIP LOCKED works (or striped by test)
 	err := tx.WithContext(ctx).Raw(
-		`SELECT s.id, s.org_id, s.status, s.activated_at, s.billing_cycle_type
+		`SELECT s.id, s.org_id, s.status, s.activated_at, s.resumed_at, s.billing_cycle_type
 		 FROM subscriptions s
 		 WHERE s.status = ?
 		   AND NOT EXISTS (
@@ -181,8 +221,8 @@ func (s *Scheduler) findLastCycle(ctx context.Context, tx *gorm.DB, orgID, subsc
 	var cycle WorkBillingCycle
 	err := tx.WithContext(ctx).Raw(
 		`SELECT id, org_id, subscription_id, period_start, period_end, status,
-		        closing_started_at, rating_completed_at, invoiced_at,
-		        invoice_finalized_at, closed_at
+		        closing_started_at, rating_completed_at, rating_attempts,
+		        next_rating_retry_at, invoiced_at, invoice_finalized_at, closed_at
 		 FROM billing_cycles
 		 WHERE org_id = ? AND subscription_id = ?
 		 ORDER BY period_end DESC
@@ -232,8 +272,8 @@ func (s *Scheduler) lockCycleForUpdate(
 	lockStart := time.Now()
 	err := tx.WithContext(ctx).Raw(
 		`SELECT id, org_id, subscription_id, period_start, period_end, status,
-		        closing_started_at, rating_completed_at, invoiced_at,
-		        invoice_finalized_at, closed_at
+		        closing_started_at, rating_completed_at, rating_attempts,
+		        next_rating_retry_at, invoiced_at, invoice_finalized_at, closed_at
 		 FROM billing_cycles
 		 WHERE id = ?
 		 FOR UPDATE`,
@@ -310,6 +350,7 @@ func (s *Scheduler) markRatingCompleted(ctx context.Context, cycleID snowflake.I
 		return tx.WithContext(ctx).Exec(
 			`UPDATE billing_cycles
 			 SET rating_completed_at = COALESCE(rating_completed_at, ?),
+			     next_rating_retry_at = NULL,
 			     last_error = NULL,
 			     last_error_at = NULL,
 			     updated_at = ?
@@ -322,6 +363,99 @@ func (s *Scheduler) markRatingCompleted(ctx context.Context, cycleID snowflake.I
 	})
 }
 
+// ratingRetryBackoff is the wait applied after each failed rating attempt.
+// Attempts beyond the schedule reuse the last (capped) delay.
+var ratingRetryBackoff = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+}
+
+func ratingRetryDelay(attempts int) time.Duration {
+	if attempts <= 0 {
+		return ratingRetryBackoff[0]
+	}
+	if attempts > len(ratingRetryBackoff) {
+		return ratingRetryBackoff[len(ratingRetryBackoff)-1]
+	}
+	return ratingRetryBackoff[attempts-1]
+}
+
+type ratingFailure struct {
+	Attempts    int
+	NextRetryAt *time.Time
+	Exhausted   bool
+}
+
+// recordRatingFailure bumps the attempt counter for a closing cycle and either
+// schedules the next retry or, once MaxRatingAttempts is reached, parks the
+// cycle in RATING_FAILED. It returns nil when the cycle is no longer closing.
+func (s *Scheduler) recordRatingFailure(ctx context.Context, cycleID snowflake.ID, now time.Time, cause error) (*ratingFailure, error) {
+	var outcome *ratingFailure
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		cycle, err := s.lockCycleForUpdate(ctx, tx, cycleID)
+		if err != nil {
+			return err
+		}
+		if cycle == nil || cycle.Status != billingcycledomain.BillingCycleStatusClosing || cycle.RatingCompletedAt != nil {
+			return nil
+		}
+
+		attempts := cycle.RatingAttempts + 1
+		message := ""
+		if cause != nil {
+			message = cause.Error()
+		}
+
+		if attempts >= s.cfg.MaxRatingAttempts {
+			if err := tx.WithContext(ctx).Exec(
+				`UPDATE billing_cycles
+				 SET status = ?, rating_attempts = ?, next_rating_retry_at = NULL,
+				     last_error = ?, last_error_at = ?, updated_at = ?
+				 WHERE id = ? AND status = ?`,
+				billingcycledomain.BillingCycleStatusRatingFailed,
+				attempts,
+				message,
+				now,
+				now,
+				cycleID,
+				billingcycledomain.BillingCycleStatusClosing,
+			).Error; err != nil {
+				return err
+			}
+			obsmetrics.Scheduler().IncBillingCycleTransition(
+				string(billingcycledomain.BillingCycleStatusClosing),
+				string(billingcycledomain.BillingCycleStatusRatingFailed),
+			)
+			outcome = &ratingFailure{Attempts: attempts, Exhausted: true}
+			return nil
+		}
+
+		nextRetryAt := now.Add(ratingRetryDelay(attempts))
+		if err := tx.WithContext(ctx).Exec(
+			`UPDATE billing_cycles
+			 SET rating_attempts = ?, next_rating_retry_at = ?,
+			     last_error = ?, last_error_at = ?, updated_at = ?
+			 WHERE id = ? AND status = ?`,
+			attempts,
+			nextRetryAt,
+			message,
+			now,
+			now,
+			cycleID,
+			billingcycledomain.BillingCycleStatusClosing,
+		).Error; err != nil {
+			return err
+		}
+		outcome = &ratingFailure{Attempts: attempts, NextRetryAt: &nextRetryAt}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outcome, nil
+}
+
 func (s *Scheduler) markCycleClosed(ctx context.Context, cycleID snowflake.ID, now time.Time) (bool, error) {
 	updated := false
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -387,6 +521,16 @@ func (s *Scheduler) markCycleInvoiced(ctx context.Context, cycleID snowflake.ID,
 	})
 }
 
+// markCycleCarriedForward completes a cycle whose amount was carried to the
+// next cycle. It gets no invoice, so it is marked both invoiced and
+// finalized.
+func (s *Scheduler) markCycleCarriedForward(ctx context.Context, cycleID snowflake.ID, now time.Time) error {
+	if err := s.markCycleInvoiced(ctx, cycleID, now); err != nil {
+		return err
+	}
+	return s.markCycleInvoiceFinalized(ctx, cycleID, now)
+}
+
 func (s *Scheduler) markCycleInvoiceFinalized(ctx context.Context, cycleID snowflake.ID, now time.Time) error {
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		cycle, err := s.lockCycleForUpdate(ctx, tx, cycleID)
@@ -456,11 +600,12 @@ func (s *Scheduler) canEndSubscription(ctx context.Context, orgID, subscriptionI
 	if err := s.db.WithContext(ctx).Raw(
 		`SELECT COUNT(1)
 		 FROM billing_cycles
-		 WHERE org_id = ? AND subscription_id = ? AND status IN (?, ?)`,
+		 WHERE org_id = ? AND subscription_id = ? AND status IN (?, ?, ?)`,
 		orgID,
 		subscriptionID,
 		billingcycledomain.BillingCycleStatusOpen,
 		billingcycledomain.BillingCycleStatusClosing,
+		billingcycledomain.BillingCycleStatusRatingFailed,
 	).Scan(&openCount).Error; err != nil {
 		return false, err
 	}
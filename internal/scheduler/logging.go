@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/bwmarrin/snowflake"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
 	obscontext "github.com/smallbiznis/railzway/internal/observability/context"
 	obslogger "github.com/smallbiznis/railzway/internal/observability/logger"
 	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
@@ -94,10 +95,12 @@ func (s *Scheduler) logJobFinish(ctx context.Context, run *jobRun) {
 	if run == nil {
 		return
 	}
+	finishedAt := time.Now()
+	s.recordJobRun(ctx, run, finishedAt)
 	fields := []zap.Field{
 		zap.String("job", run.job),
 		zap.String("run_id", run.runID),
-		zap.Int64("duration_ms", time.Since(run.startedAt).Milliseconds()),
+		zap.Int64("duration_ms", finishedAt.Sub(run.startedAt).Milliseconds()),
 		zap.Int("processed_count", run.processedCount),
 		zap.Int("error_count", run.errorCount),
 	}
@@ -150,6 +153,17 @@ func (s *Scheduler) logInvoiceGenerated(ctx context.Context, cycle WorkBillingCy
 	)
 }
 
+func (s *Scheduler) logInvoiceCarriedForward(ctx context.Context, cycle WorkBillingCycle, carried *invoicedomain.CarryForward) {
+	ctx = s.withLogContext(ctx, cycle.OrgID)
+	s.logger(ctx).Info("invoice.skipped_below_minimum",
+		zap.String("cycle_id", idString(cycle.ID)),
+		zap.String("org_id", idString(cycle.OrgID)),
+		zap.String("carry_forward_id", idString(carried.ID)),
+		zap.Int64("carried_amount", carried.Amount),
+		zap.String("currency", carried.Currency),
+	)
+}
+
 func (s *Scheduler) logInvoiceFinalized(ctx context.Context, cycle WorkBillingCycle, invoiceID snowflake.ID) {
 	ctx = s.withLogContext(ctx, cycle.OrgID)
 	s.logger(ctx).Info("invoice.finalized",
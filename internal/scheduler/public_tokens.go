@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bwmarrin/snowflake"
+	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"go.uber.org/zap"
+)
+
+// PublicTokenProbeJob publishes, per org, how many finalized unpaid invoices
+// have no active public token and so no pay link. It runs one grouped read
+// and takes no locks.
+func (s *Scheduler) PublicTokenProbeJob(ctx context.Context) error {
+	counts, err := s.missingPublicTokenCounts(ctx)
+	if err != nil {
+		s.logSchedulerError(ctx, jobRunFromContext(ctx), "scheduler.public_token_probe.failed", "public_token_probe", 0, err)
+		return err
+	}
+
+	gauge := make(map[string]int, len(counts))
+	for orgID, count := range counts {
+		gauge[orgID.String()] = count
+	}
+	obsmetrics.PublicToken().SetMissingPublicTokens(gauge)
+	return nil
+}
+
+// EnsurePublicTokensJob issues public tokens for finalized unpaid invoices
+// that have none, up to BatchSize per org. An org that fails is logged and
+// skipped; a missing encryption key stops the run, since no org can be
+// served.
+func (s *Scheduler) EnsurePublicTokensJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "ensure_public_tokens", s.cfg.BatchSize)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+
+	counts, err := s.missingPublicTokenCounts(ctx)
+	if err != nil {
+		s.logSchedulerError(ctx, run, "ensure_public_tokens.list_orgs_failed", "ensure_public_tokens", 0, err)
+		return err
+	}
+
+	for orgID := range counts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		issued, err := s.billingOperationsSvc.EnsurePublicTokens(orgcontext.WithOrgID(ctx, int64(orgID)), s.cfg.BatchSize)
+		run.AddProcessed(issued)
+		if errors.Is(err, billingopsdomain.ErrPublicTokenKeyMissing) {
+			s.logSchedulerError(ctx, run, "ensure_public_tokens.key_missing", "ensure_public_tokens", 0, err)
+			return err
+		}
+		if err != nil {
+			s.logSchedulerError(ctx, run, "ensure_public_tokens.failed", "ensure_public_tokens", orgID, err)
+			continue
+		}
+		if issued > 0 {
+			s.logger(ctx).Info("ensure_public_tokens.org_issued",
+				zap.String("org_id", orgID.String()),
+				zap.Int("issued", issued),
+			)
+		}
+	}
+	return nil
+}
+
+// missingPublicTokenCounts counts, per org, the finalized unpaid invoices of
+// live customers without an active public token. It matches the invoices
+// listed by the missing public tokens report.
+func (s *Scheduler) missingPublicTokenCounts(ctx context.Context) (map[snowflake.ID]int, error) {
+	var rows []struct {
+		OrgID snowflake.ID
+		Total int
+	}
+	if err := s.db.WithContext(ctx).Raw(
+		`SELECT i.org_id, COUNT(*) AS total
+		 FROM invoices i
+		 JOIN customers c ON c.id = i.customer_id
+		 WHERE i.status = 'FINALIZED'
+		   AND i.voided_at IS NULL
+		   AND i.paid_at IS NULL
+		   AND c.deleted_at IS NULL
+		   AND NOT EXISTS (
+			SELECT 1 FROM invoice_public_tokens ipt
+			WHERE ipt.invoice_id = i.id AND ipt.revoked_at IS NULL
+		   )
+		 GROUP BY i.org_id`,
+	).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[snowflake.ID]int, len(rows))
+	for _, row := range rows {
+		counts[row.OrgID] = row.Total
+	}
+	return counts, nil
+}
@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// failingRatingSvc fails every rating and records the cycles it was asked
+// to rate.
+type failingRatingSvc struct {
+	rated []string
+}
+
+func (r *failingRatingSvc) RunRating(_ context.Context, cycleID string) error {
+	r.rated = append(r.rated, cycleID)
+	return errors.New("usage store unavailable")
+}
+
+func newRatingRetryTestScheduler(t *testing.T, now time.Time) (*Scheduler, *clock.FakeClock, *failingRatingSvc, *forceCloseAudit) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	// sqlite has no row locks; drop the FOR UPDATE [SKIP LOCKED] clauses.
+	db.Callback().Row().Before("gorm:row").Register("sqlite_for_update_row", func(d *gorm.DB) {
+		sql := d.Statement.SQL.String()
+		if strings.Contains(sql, "FOR UPDATE") {
+			sql = strings.ReplaceAll(sql, "FOR UPDATE SKIP LOCKED", "")
+			d.Statement.SQL.Reset()
+			d.Statement.SQL.WriteString(strings.ReplaceAll(sql, "FOR UPDATE", ""))
+		}
+	})
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Exec(`
+		CREATE TABLE billing_cycles (
+			id INTEGER PRIMARY KEY,
+			org_id INTEGER,
+			subscription_id INTEGER,
+			period_start DATETIME,
+			period_end DATETIME,
+			status TEXT,
+			closing_started_at DATETIME,
+			rating_completed_at DATETIME,
+			rating_attempts INTEGER NOT NULL DEFAULT 0,
+			next_rating_retry_at DATETIME,
+			invoiced_at DATETIME,
+			invoice_finalized_at DATETIME,
+			closed_at DATETIME,
+			last_error TEXT,
+			last_error_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error; err != nil {
+		t.Fatalf("create billing_cycles table: %v", err)
+	}
+
+	node, _ := snowflake.NewNode(1)
+	fakeClock := clock.NewFakeClock(now)
+	rating := &failingRatingSvc{}
+	audit := &forceCloseAudit{}
+	return &Scheduler{
+		db:        db,
+		log:       zap.NewNop(),
+		cfg:       Config{MaxRatingBatchSize: 10, MaxRatingAttempts: 2}.withDefaults(),
+		genID:     node,
+		clock:     fakeClock,
+		ratingSvc: rating,
+		authzSvc:  &mockAuthzSvc{},
+		auditSvc:  audit,
+	}, fakeClock, rating, audit
+}
+
+func insertClosingCycle(t *testing.T, db *gorm.DB, id int64, attempts int, nextRetryAt *time.Time) {
+	t.Helper()
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.Exec(
+		`INSERT INTO billing_cycles (id, org_id, subscription_id, period_start, period_end, status, closing_started_at, rating_attempts, next_rating_retry_at)
+		 VALUES (?, 100, ?, ?, ?, ?, ?, ?, ?)`,
+		id, id, start, start.AddDate(0, 1, 0), billingcycledomain.BillingCycleStatusClosing, start.AddDate(0, 1, 0), attempts, nextRetryAt,
+	).Error; err != nil {
+		t.Fatalf("insert billing cycle: %v", err)
+	}
+}
+
+type ratingRetryState struct {
+	Status            string
+	RatingAttempts    int
+	NextRatingRetryAt *time.Time
+	LastError         *string
+}
+
+func loadRatingRetryState(t *testing.T, db *gorm.DB, id int64) ratingRetryState {
+	t.Helper()
+	var row ratingRetryState
+	if err := db.Raw(`SELECT status, rating_attempts, next_rating_retry_at, last_error FROM billing_cycles WHERE id = ?`, id).Scan(&row).Error; err != nil {
+		t.Fatalf("load cycle: %v", err)
+	}
+	return row
+}
+
+// TestRatingJob_RetryBackoff runs RatingJob against cycles whose rating
+// keeps failing: a cycle still backing off is skipped, a failure schedules
+// the next attempt, and the last allowed attempt parks the cycle as
+// RATING_FAILED.
+func TestRatingJob_RetryBackoff(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	restore := swapPrometheusRegistry(registry)
+	defer restore()
+
+	now := time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+	s, fakeClock, rating, audit := newRatingRetryTestScheduler(t, now)
+	backingOff := now.Add(10 * time.Minute)
+	insertClosingCycle(t, s.db, 1, 1, &backingOff)
+	insertClosingCycle(t, s.db, 2, 0, nil)
+	insertClosingCycle(t, s.db, 3, 1, &now)
+
+	if err := s.RatingJob(context.Background()); err == nil {
+		t.Fatal("expected the failed ratings to be reported")
+	}
+	if got := strings.Join(rating.rated, ","); got != "2,3" {
+		t.Fatalf("expected cycles 2 and 3 rated, got %s", got)
+	}
+
+	skipped := loadRatingRetryState(t, s.db, 1)
+	if skipped.RatingAttempts != 1 || skipped.NextRatingRetryAt == nil || !skipped.NextRatingRetryAt.Equal(backingOff) {
+		t.Fatalf("expected the backing off cycle untouched, got %+v", skipped)
+	}
+
+	retrying := loadRatingRetryState(t, s.db, 2)
+	if retrying.Status != string(billingcycledomain.BillingCycleStatusClosing) || retrying.RatingAttempts != 1 {
+		t.Fatalf("expected cycle 2 still closing after one attempt, got %+v", retrying)
+	}
+	if retrying.NextRatingRetryAt == nil || !retrying.NextRatingRetryAt.Equal(now.Add(ratingRetryDelay(1))) {
+		t.Fatalf("expected cycle 2 retried after %s, got %+v", ratingRetryDelay(1), retrying)
+	}
+	if retrying.LastError == nil || *retrying.LastError != "usage store unavailable" {
+		t.Fatalf("expected the rating error recorded, got %+v", retrying)
+	}
+
+	exhausted := loadRatingRetryState(t, s.db, 3)
+	if exhausted.Status != string(billingcycledomain.BillingCycleStatusRatingFailed) || exhausted.RatingAttempts != 2 || exhausted.NextRatingRetryAt != nil {
+		t.Fatalf("expected cycle 3 parked as rating failed, got %+v", exhausted)
+	}
+	failed := 0
+	for _, action := range audit.actions {
+		if action == "billing_cycle.rating_failed" {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("expected one rating_failed audit entry, got %v", audit.actions)
+	}
+
+	// Once its backoff has passed cycle 2 is retried and, out of attempts,
+	// parked too. Cycle 1 is still backing off and the parked cycle 3 is
+	// left alone.
+	rating.rated = nil
+	fakeClock.Advance(2 * time.Minute)
+	if err := s.RatingJob(context.Background()); err == nil {
+		t.Fatal("expected the failed rating to be reported")
+	}
+	if got := strings.Join(rating.rated, ","); got != "2" {
+		t.Fatalf("expected only cycle 2 rated, got %s", got)
+	}
+	if got := loadRatingRetryState(t, s.db, 2); got.Status != string(billingcycledomain.BillingCycleStatusRatingFailed) || got.RatingAttempts != 2 {
+		t.Fatalf("expected cycle 2 parked as rating failed, got %+v", got)
+	}
+}
@@ -27,8 +27,8 @@ func (s *Scheduler) RecoverySweepJob(ctx context.Context) error {
 	for {
 		cycles, err := s.fetchBillingCyclesForWork(
 			ctx,
-			`status = ? AND rating_completed_at IS NULL AND closing_started_at IS NOT NULL AND closing_started_at <= ?`,
-			[]any{billingcycledomain.BillingCycleStatusClosing, cutoff},
+			cyclesStuckInRating,
+			[]any{billingcycledomain.BillingCycleStatusClosing, cutoff, now},
 			s.cfg.MaxRatingBatchSize,
 		)
 		if err != nil {
@@ -70,7 +70,11 @@ func (s *Scheduler) RecoverySweepJob(ctx context.Context) error {
 					zap.String("cycle_id", idString(cycle.ID)),
 					zap.String("subscription_id", idString(cycle.SubscriptionID)),
 				)
-				_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageRecoveryRating, err)
+				metadata := map[string]any{
+					"recovery": true,
+					"error":    err.Error(),
+				}
+				s.handleRatingFailure(ctx, cycleCtx, run, "recovery_sweep", obsmetrics.CycleStageRecoveryRating, cycle, now, err, metadata)
 				s.emitAuditEvent(cycleCtx, auditEvent{
 					OrgID:          cycle.OrgID,
 					Action:         "rating.failed",
@@ -78,10 +82,7 @@ func (s *Scheduler) RecoverySweepJob(ctx context.Context) error {
 					TargetID:       cycle.ID.String(),
 					SubscriptionID: cycle.SubscriptionID.String(),
 					BillingCycleID: cycle.ID.String(),
-					Metadata: map[string]any{
-						"recovery": true,
-						"error":    err.Error(),
-					},
+					Metadata:       metadata,
 				})
 				continue
 			}
@@ -138,7 +139,7 @@ func (s *Scheduler) RecoverySweepJob(ctx context.Context) error {
 	for {
 		cycles, err := s.fetchBillingCyclesForWork(
 			ctx,
-			`status = ? AND rating_completed_at IS NOT NULL`,
+			cyclesRated,
 			[]any{billingcycledomain.BillingCycleStatusClosing},
 			s.cfg.MaxCloseBatchSize,
 		)
@@ -240,7 +241,7 @@ func (s *Scheduler) RecoverySweepJob(ctx context.Context) error {
 	for {
 		cycles, err := s.fetchBillingCyclesForWork(
 			ctx,
-			`status = ? AND invoiced_at IS NULL AND closed_at IS NOT NULL AND closed_at <= ?`,
+			cyclesStuckInInvoicing,
 			[]any{billingcycledomain.BillingCycleStatusClosed, cutoff},
 			s.cfg.MaxInvoiceBatchSize,
 		)
@@ -263,7 +264,7 @@ func (s *Scheduler) RecoverySweepJob(ctx context.Context) error {
 				continue
 			}
 			cycleCtx := s.withAuditContext(ctx, cycle.SubscriptionID.String(), cycle.ID.String())
-			invoice, err := s.invoiceSvc.GenerateInvoice(cycleCtx, cycle.ID.String())
+			generated, err := s.invoiceSvc.GenerateInvoice(cycleCtx, cycle.ID.String(), invoicedomain.GenerationIdempotencyKey(cycle.ID))
 			if err != nil {
 				jobErr = errors.Join(jobErr, err)
 				s.logSchedulerError(ctx, run, "invoice.generate.failed", "recovery_sweep", cycle.OrgID, err,
@@ -274,16 +275,34 @@ func (s *Scheduler) RecoverySweepJob(ctx context.Context) error {
 				continue
 			}
 
+			if generated != nil && generated.CarryForward != nil {
+				if !generated.Duplicate {
+					s.logInvoiceCarriedForward(ctx, cycle, generated.CarryForward)
+				}
+				if err := s.markCycleCarriedForward(ctx, cycle.ID, now); err != nil {
+					jobErr = errors.Join(jobErr, err)
+					s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "recovery_sweep", cycle.OrgID, err,
+						zap.String("cycle_id", idString(cycle.ID)),
+						zap.String("subscription_id", idString(cycle.SubscriptionID)),
+					)
+					_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageRecoveryInvoice, err)
+				}
+				continue
+			}
+
 			// invoice, err := s.loadInvoiceByCycle(ctx, cycle.ID)
 			// if err != nil {
 			// 	jobErr = errors.Join(jobErr, err)
 			// 	_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageRecoveryInvoice, err)
 			// 	continue
 			// }
-			if invoice == nil {
+			if generated == nil || generated.Invoice == nil {
 				continue
 			}
-			s.logInvoiceGenerated(ctx, cycle, invoice.ID)
+			invoice := generated.Invoice
+			if !generated.Duplicate {
+				s.logInvoiceGenerated(ctx, cycle, invoice.ID)
+			}
 
 			if err := s.markCycleInvoiced(ctx, cycle.ID, now); err != nil {
 				jobErr = errors.Join(jobErr, err)
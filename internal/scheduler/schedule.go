@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JobSchedule overrides how often a job runs under RunForever. Interval runs
+// the job every Interval; Cron runs it at the times matched by a five-field
+// cron expression (minute hour day-of-month month day-of-week) in UTC. Cron
+// also accepts @hourly, @daily, @weekly, @monthly and "@every <duration>".
+type JobSchedule struct {
+	Interval time.Duration
+	Cron     string
+}
+
+// schedule returns the first time a job is due strictly after a given time.
+type schedule interface {
+	next(after time.Time) time.Time
+	// runAtStart reports whether the job is due as soon as the loop starts.
+	runAtStart() bool
+}
+
+type intervalSchedule time.Duration
+
+func (i intervalSchedule) next(after time.Time) time.Time {
+	return after.Add(time.Duration(i))
+}
+
+func (intervalSchedule) runAtStart() bool { return true }
+
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// compile turns the configured schedule into one the run loop evaluates.
+func (j JobSchedule) compile() (schedule, error) {
+	expr := strings.TrimSpace(j.Cron)
+	if expr == "" {
+		if j.Interval <= 0 {
+			return nil, fmt.Errorf("%w: schedule needs an interval or a cron expression", ErrInvalidConfig)
+		}
+		return intervalSchedule(j.Interval), nil
+	}
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("%w: invalid interval %q", ErrInvalidConfig, rest)
+		}
+		return intervalSchedule(interval), nil
+	}
+	if alias, ok := cronAliases[expr]; ok {
+		expr = alias
+	}
+	return parseCron(expr)
+}
+
+// compileJobSchedules compiles the per-job overrides, keyed by job name.
+func compileJobSchedules(schedules map[string]JobSchedule) (map[string]schedule, error) {
+	compiled := make(map[string]schedule, len(schedules))
+	for name, jobSchedule := range schedules {
+		sched, err := jobSchedule.compile()
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", name, err)
+		}
+		compiled[name] = sched
+	}
+	return compiled, nil
+}
+
+// parseJobSchedules parses a semicolon-separated list of job=schedule
+// entries such as "sla_evaluation=1m;finops_scoring=0 2 * * *". A schedule
+// that parses as a duration is an interval; anything else is a cron
+// expression.
+func parseJobSchedules(raw string) (map[string]JobSchedule, bool) {
+	schedules := make(map[string]JobSchedule)
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		name, spec = strings.TrimSpace(name), strings.TrimSpace(spec)
+		if !ok || name == "" || spec == "" {
+			return nil, false
+		}
+		jobSchedule := JobSchedule{Cron: spec}
+		if interval, err := time.ParseDuration(spec); err == nil {
+			jobSchedule = JobSchedule{Interval: interval}
+		}
+		if _, err := jobSchedule.compile(); err != nil {
+			return nil, false
+		}
+		schedules[name] = jobSchedule
+	}
+	return schedules, true
+}
+
+type cronField uint64
+
+func (f cronField) has(value int) bool { return f&(1<<uint(value)) != 0 }
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow cronField
+	// domAny and dowAny record a "*" day field: when both day fields are
+	// restricted a day matches either of them, as in standard cron.
+	domAny, dowAny bool
+}
+
+func (cronSchedule) runAtStart() bool { return false }
+
+func parseCron(expr string) (schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: cron expression %q needs five fields", ErrInvalidConfig, expr)
+	}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var parsed [5]cronField
+	for i, field := range fields {
+		bits, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: cron expression %q: %v", ErrInvalidConfig, expr, err)
+		}
+		parsed[i] = bits
+	}
+	// Sunday is both 0 and 7.
+	if parsed[4].has(7) {
+		parsed[4] |= 1
+	}
+	sched := cronSchedule{
+		minute: parsed[0],
+		hour:   parsed[1],
+		dom:    parsed[2],
+		month:  parsed[3],
+		dow:    parsed[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+	if sched.next(time.Unix(0, 0)).IsZero() {
+		return nil, fmt.Errorf("%w: cron expression %q never matches", ErrInvalidConfig, expr)
+	}
+	return sched, nil
+}
+
+// parseCronField parses a comma-separated list of "*", "n", "a-b", each
+// optionally followed by "/step".
+func parseCronField(field string, min, max int) (cronField, error) {
+	var bits cronField
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step = parsed
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			start, end, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(start); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(end); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+		for value := lo; value <= hi; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom.has(t.Day())
+	dowMatch := c.dow.has(int(t.Weekday()))
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// next walks forward a month, day, hour or minute at a time until every
+// field matches. Expressions that never match, such as 30 February, give
+// the zero time after five years of search.
+func (c cronSchedule) next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !c.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !c.hour.has(t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
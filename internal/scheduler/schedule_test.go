@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobSchedule_CronNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("parse %q: %v", value, err)
+		}
+		return parsed
+	}
+	cases := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"0 2 * * *", "2025-06-01T01:30:00Z", "2025-06-01T02:00:00Z"},
+		{"0 2 * * *", "2025-06-01T02:00:00Z", "2025-06-02T02:00:00Z"},
+		{"*/15 * * * *", "2025-06-01T10:07:30Z", "2025-06-01T10:15:00Z"},
+		{"30 9 * * 1-5", "2025-06-06T10:00:00Z", "2025-06-09T09:30:00Z"},
+		{"0 0 1 * *", "2025-01-31T12:00:00Z", "2025-02-01T00:00:00Z"},
+		{"0 0 29 2 *", "2025-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"0 12 13 * 5", "2025-06-01T00:00:00Z", "2025-06-06T12:00:00Z"},
+		{"@daily", "2025-06-01T23:59:00Z", "2025-06-02T00:00:00Z"},
+		{"0 0 * * 7", "2025-06-02T00:00:00Z", "2025-06-08T00:00:00Z"},
+	}
+	for _, tc := range cases {
+		sched, err := JobSchedule{Cron: tc.expr}.compile()
+		if err != nil {
+			t.Fatalf("compile %q: %v", tc.expr, err)
+		}
+		if got := sched.next(at(tc.after)); !got.Equal(at(tc.want)) {
+			t.Errorf("%q after %s: expected %s, got %s", tc.expr, tc.after, tc.want, got)
+		}
+		if sched.runAtStart() {
+			t.Errorf("%q: expected a cron schedule to wait for its first match", tc.expr)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "0 24 * * *", "5-1 * * * *", "*/0 * * * *", "0 0 30 2 *", "@every -1m"} {
+		if _, err := (JobSchedule{Cron: expr}).compile(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%q: expected ErrInvalidConfig, got %v", expr, err)
+		}
+	}
+}
+
+func TestParseJobSchedules(t *testing.T) {
+	schedules, ok := parseJobSchedules(" sla_evaluation=1m ; finops_scoring=0 2 * * *;rollup_rebuild=@every 10m")
+	if !ok {
+		t.Fatalf("expected schedules to parse")
+	}
+	if got := schedules["sla_evaluation"]; got.Interval != time.Minute || got.Cron != "" {
+		t.Fatalf("expected a one minute interval, got %+v", got)
+	}
+	if got := schedules["finops_scoring"]; got.Cron != "0 2 * * *" {
+		t.Fatalf("expected a cron schedule, got %+v", got)
+	}
+	if got := schedules["rollup_rebuild"]; got.Cron != "@every 10m" {
+		t.Fatalf("expected an @every schedule, got %+v", got)
+	}
+
+	for _, raw := range []string{"sla_evaluation", "=1m", "finops_scoring=0 2 * *", "sla_evaluation=-1m"} {
+		if _, ok := parseJobSchedules(raw); ok {
+			t.Errorf("%q: expected parse to fail", raw)
+		}
+	}
+}
+
+func TestProvideConfig_RejectsMalformedJobSchedules(t *testing.T) {
+	t.Setenv("SCHEDULER_JOB_SCHEDULES", "sla_evaluation=1m;finops_scoring")
+	if _, err := ProvideConfig(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+
+	t.Setenv("SCHEDULER_JOB_SCHEDULES", "sla_evaluation=1m")
+	cfg, err := ProvideConfig()
+	if err != nil {
+		t.Fatalf("provide config: %v", err)
+	}
+	if got := cfg.JobSchedules["sla_evaluation"]; got.Interval != time.Minute {
+		t.Fatalf("expected a one minute interval, got %+v", got)
+	}
+}
+
+func TestCheckJobSchedules_RejectsUnknownJobs(t *testing.T) {
+	s := &Scheduler{cfg: Config{JobSchedules: map[string]JobSchedule{
+		"sla_evaluation": {Interval: time.Minute},
+	}}}
+	if err := s.checkJobSchedules(); err != nil {
+		t.Fatalf("expected a known job to pass, got %v", err)
+	}
+
+	s.cfg.JobSchedules["sla_evalution"] = JobSchedule{Interval: time.Minute}
+	if err := s.checkJobSchedules(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected a misspelled job to be rejected, got %v", err)
+	}
+}
+
+func TestRunDueJobs_FollowsEachJobSchedule(t *testing.T) {
+	schedules, err := compileJobSchedules(map[string]JobSchedule{
+		"sla_evaluation": {Interval: time.Minute},
+		"finops_scoring": {Cron: "0 2 * * *"},
+	})
+	if err != nil {
+		t.Fatalf("compile schedules: %v", err)
+	}
+	s := &Scheduler{cfg: Config{RunInterval: 5 * time.Minute, MaxConcurrentJobs: 1}, schedules: schedules}
+
+	runs := map[string]int{}
+	job := func(name string, enabled bool) scheduledJob {
+		return scheduledJob{Name: name, Enabled: enabled, Run: func(context.Context) error {
+			runs[name]++
+			return nil
+		}}
+	}
+	jobs := []scheduledJob{
+		job("sla_evaluation", true),
+		job("finops_scoring", true),
+		job("invoice", true),
+		job("disabled", false),
+	}
+
+	start := time.Date(2025, 6, 1, 1, 0, 0, 0, time.UTC)
+	nextRuns := make(map[string]time.Time)
+	for now := start; now.Before(start.Add(2 * time.Hour)); now = now.Add(time.Minute) {
+		if err := s.runDueJobs(context.Background(), jobs, now, nextRuns); err != nil {
+			t.Fatalf("run due jobs: %v", err)
+		}
+	}
+
+	if runs["sla_evaluation"] != 120 {
+		t.Fatalf("expected sla_evaluation every minute, got %d runs", runs["sla_evaluation"])
+	}
+	if runs["invoice"] != 24 {
+		t.Fatalf("expected invoice every RunInterval, got %d runs", runs["invoice"])
+	}
+	if runs["finops_scoring"] != 1 {
+		t.Fatalf("expected finops_scoring once at 02:00, got %d runs", runs["finops_scoring"])
+	}
+	if runs["disabled"] != 0 {
+		t.Fatalf("expected disabled job not to run")
+	}
+	if _, ok := nextRuns["disabled"]; ok {
+		t.Fatalf("expected disabled job not to be scheduled")
+	}
+
+	last := start.Add(2*time.Hour - time.Minute)
+	if got, want := s.nextDue(nextRuns, last), last.Add(time.Minute); !got.Equal(want) {
+		t.Fatalf("expected next pass at %s, got %s", want, got)
+	}
+}
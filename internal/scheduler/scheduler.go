@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,10 +18,14 @@ import (
 	billingopsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
 	"github.com/smallbiznis/railzway/internal/clock"
 	"github.com/smallbiznis/railzway/internal/cloudmetrics"
+	emaildeliverydomain "github.com/smallbiznis/railzway/internal/emaildelivery/domain"
+	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
 	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	"github.com/smallbiznis/railzway/internal/ledger"
 	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
 	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
 	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/internal/providers/email"
 	ratingdomain "github.com/smallbiznis/railzway/internal/rating/domain"
 	"github.com/smallbiznis/railzway/internal/scheduler/guard"
 	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
@@ -44,8 +50,11 @@ type Params struct {
 	RollupSvc            *rollup.Service `optional:"true"`
 	GenID                *snowflake.Node
 	Clock                clock.Clock
-	Config               Config                     `optional:"true"`
-	CloudMetrics         *cloudmetrics.CloudMetrics `optional:"true"`
+	Config               Config                      `optional:"true"`
+	CloudMetrics         *cloudmetrics.CloudMetrics  `optional:"true"`
+	Email                email.Provider              `optional:"true"`
+	EmailDeliverySvc     emaildeliverydomain.Service `optional:"true"`
+	Flags                featuredomain.FlagService   `optional:"true"`
 }
 
 type Scheduler struct {
@@ -63,6 +72,10 @@ type Scheduler struct {
 	billingOperationsSvc billingopsdomain.Service
 	rollupSvc            *rollup.Service
 	cloudMetrics         *cloudmetrics.CloudMetrics
+	email                email.Provider
+	emailDeliverySvc     emaildeliverydomain.Service
+	flags                featuredomain.FlagService
+	schedules            map[string]schedule
 }
 
 type auditEvent struct {
@@ -80,7 +93,11 @@ func New(p Params) (*Scheduler, error) {
 		return nil, ErrInvalidConfig
 	}
 	cfg := p.Config.withDefaults()
-	return &Scheduler{
+	schedules, err := compileJobSchedules(cfg.JobSchedules)
+	if err != nil {
+		return nil, err
+	}
+	s := &Scheduler{
 		db:                   p.DB,
 		log:                  p.Log.Named("scheduler").With(zap.String("component", "scheduler")),
 		cfg:                  cfg,
@@ -95,7 +112,30 @@ func New(p Params) (*Scheduler, error) {
 		billingOperationsSvc: p.BillingOperationsSvc,
 		rollupSvc:            p.RollupSvc,
 		cloudMetrics:         p.CloudMetrics,
-	}, nil
+		email:                p.Email,
+		emailDeliverySvc:     p.EmailDeliverySvc,
+		flags:                p.Flags,
+		schedules:            schedules,
+	}
+	if err := s.checkJobSchedules(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// checkJobSchedules rejects JobSchedules entries that name no job, which
+// would otherwise be ignored.
+func (s *Scheduler) checkJobSchedules() error {
+	known := make(map[string]struct{})
+	for _, job := range s.scheduledJobs() {
+		known[job.Name] = struct{}{}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.cfg.JobSchedules)) {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("%w: schedule for unknown job %q", ErrInvalidConfig, name)
+		}
+	}
+	return nil
 }
 
 func (s *Scheduler) runJob(
@@ -105,6 +145,27 @@ func (s *Scheduler) runJob(
 	timeout time.Duration,
 	fn func(ctx context.Context) error,
 ) error {
+	// Only one replica runs a job at a time; the others skip it and its
+	// dependents this pass.
+	lock, leader, err := s.acquireJobLock(parent, name)
+	if err != nil {
+		return fmt.Errorf("%s: acquire job lock: %w", name, err)
+	}
+	if !leader {
+		obsmetrics.Scheduler().IncJobLeaderSkip(name)
+		s.logger(parent).Debug("job held by another node, skipping", zap.String("job", name))
+		return errJobSkipped
+	}
+	if lock != nil {
+		obsmetrics.Scheduler().SetJobLeader(name, s.cfg.NodeID, true)
+		defer func() {
+			obsmetrics.Scheduler().SetJobLeader(name, s.cfg.NodeID, false)
+			if err := lock.release(parent); err != nil {
+				s.logger(parent).Warn("failed to release job lock", zap.String("job", name), zap.Error(err))
+			}
+		}()
+	}
+
 	start := s.clock.Now()
 	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
@@ -121,7 +182,7 @@ func (s *Scheduler) runJob(
 	schedMetrics := obsmetrics.Scheduler()
 	schedMetrics.IncJobRun(name)
 
-	err := fn(ctx)
+	err = fn(ctx)
 	duration := time.Since(start)
 	schedMetrics.ObserveJobDuration(name, duration)
 	if s.cloudMetrics != nil {
@@ -137,6 +198,14 @@ func (s *Scheduler) runJob(
 		return nil
 	}
 
+	// The job context was cancelled from above: the scheduler is shutting
+	// down and the drain window ran out.
+	if errors.Is(err, context.Canceled) && parent.Err() != nil {
+		schedMetrics.IncJobInterrupted(name)
+		log.Warn("job interrupted by shutdown", zap.Error(err))
+		return nil
+	}
+
 	// ✅ treat deadline as soft-timeout
 	isTimeout := errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
 	if isTimeout {
@@ -154,100 +223,177 @@ func (s *Scheduler) runJob(
 	return fmt.Errorf("%s: %w", name, err)
 }
 
+// RunOnce runs every enabled job once, regardless of its schedule.
 func (s *Scheduler) RunOnce(parent context.Context) error {
-	var err error
-
-	jobs := []struct {
-		Name    string
-		Enabled bool
-		Run     func(context.Context) error
-	}{
-		{"ensure_cycles", s.isJobEnabled("ensure_cycles"), func(ctx context.Context) error {
+	return s.runJobs(parent, s.scheduledJobs())
+}
+
+func (s *Scheduler) scheduledJobs() []scheduledJob {
+	return []scheduledJob{
+		// The billing cycle state machine: each step picks up what the
+		// previous one left behind, so they keep their order under
+		// MaxConcurrentJobs.
+		{"ensure_cycles", s.isJobEnabled("ensure_cycles"), nil, func(ctx context.Context) error {
 			return s.runJob(ctx, "ensure_cycles", s.cfg.BatchSize, 30*time.Second, s.EnsureBillingCyclesJob)
 		}},
-		{"close_cycles", s.isJobEnabled("close_cycles"), func(ctx context.Context) error {
+		{"close_cycles", s.isJobEnabled("close_cycles"), []string{"ensure_cycles"}, func(ctx context.Context) error {
 			return s.runJob(ctx, "close_cycles", s.cfg.MaxCloseBatchSize, 30*time.Second, s.CloseCyclesJob)
 		}},
-		{"rating", s.isJobEnabled("rating"), func(ctx context.Context) error {
+		{"rating", s.isJobEnabled("rating"), []string{"close_cycles"}, func(ctx context.Context) error {
 			return s.runJob(ctx, "rating", s.cfg.MaxRatingBatchSize, 30*time.Second, s.RatingJob)
 		}},
-		{"close_after_rating", s.isJobEnabled("close_after_rating"), func(ctx context.Context) error {
+		{"close_after_rating", s.isJobEnabled("close_after_rating"), []string{"rating"}, func(ctx context.Context) error {
 			return s.runJob(ctx, "close_after_rating", s.cfg.MaxCloseBatchSize, 30*time.Second, s.CloseAfterRatingJob)
 		}},
-		{"invoice", s.isJobEnabled("invoice"), func(ctx context.Context) error {
+		{"invoice", s.isJobEnabled("invoice"), []string{"close_after_rating"}, func(ctx context.Context) error {
 			return s.runJob(ctx, "invoice", s.cfg.MaxInvoiceBatchSize, 30*time.Second, s.InvoiceJob)
 		}},
-	}
 
-	for _, job := range jobs {
-		if job.Enabled {
-			err = errors.Join(err, job.Run(parent))
-		}
-	}
-
-	if s.rollupSvc != nil {
-		if s.isJobEnabled("rollup_rebuild") {
-			err = errors.Join(err, s.runJob(parent, "rollup_rebuild", s.cfg.BatchSize, 30*time.Minute, func(ctx context.Context) error {
+		{"rollup_rebuild", s.rollupSvc != nil && s.isJobEnabled("rollup_rebuild"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "rollup_rebuild", s.cfg.BatchSize, 30*time.Minute, func(ctx context.Context) error {
 				return s.rollupSvc.ProcessRebuildRequests(ctx, s.cfg.BatchSize)
-			}))
-		}
-		if s.isJobEnabled("rollup_pending") {
-			err = errors.Join(err, s.runJob(parent, "rollup_pending", s.cfg.BatchSize, 30*time.Second, func(ctx context.Context) error {
+			})
+		}},
+		{"rollup_pending", s.rollupSvc != nil && s.isJobEnabled("rollup_pending"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "rollup_pending", s.cfg.BatchSize, 30*time.Second, func(ctx context.Context) error {
 				return s.rollupSvc.ProcessPending(ctx, s.cfg.BatchSize)
-			}))
-		}
-	}
+			})
+		}},
 
-	otherJobs := []struct {
-		Name    string
-		Enabled bool
-		Run     func(context.Context) error
-	}{
-		{"end_canceled_subs", s.isJobEnabled("end_canceled_subs"), func(ctx context.Context) error {
+		{"end_canceled_subs", s.isJobEnabled("end_canceled_subs"), nil, func(ctx context.Context) error {
 			return s.runJob(ctx, "end_canceled_subs", s.cfg.BatchSize, 30*time.Second, s.EndCanceledSubscriptionsJob)
 		}},
-		{"recovery_sweep", s.isJobEnabled("recovery_sweep"), func(ctx context.Context) error {
+		{"resume_paused_subs", s.isJobEnabled("resume_paused_subs"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "resume_paused_subs", s.cfg.BatchSize, 30*time.Second, s.ResumePausedSubscriptionsJob)
+		}},
+		{"recovery_sweep", s.isJobEnabled("recovery_sweep"), nil, func(ctx context.Context) error {
 			return s.runJob(ctx, "recovery_sweep", maxInt(s.cfg.MaxRatingBatchSize, s.cfg.MaxCloseBatchSize, s.cfg.MaxInvoiceBatchSize), 30*time.Second, s.RecoverySweepJob)
 		}},
-		{"sla_evaluation", s.isJobEnabled("sla_evaluation"), func(ctx context.Context) error {
+		{"sla_evaluation", s.isJobEnabled("sla_evaluation"), nil, func(ctx context.Context) error {
 			return s.runJob(ctx, "sla_evaluation", s.cfg.BatchSize, 30*time.Second, s.SLAEvaluationJob)
 		}},
-		{"finops_scoring", s.isJobEnabled("finops_scoring"), func(ctx context.Context) error {
+		{"finops_scoring", s.isJobEnabled("finops_scoring"), nil, func(ctx context.Context) error {
 			return s.runJob(ctx, "finops_scoring", 1, 24*time.Hour, s.FinOpsScoringJob)
 		}},
+		// Rollups read the daily snapshots, so they wait for the day to be
+		// scored and then cover the latest week or month it completes.
+		{"finops_scoring_weekly", s.isJobEnabled("finops_scoring_weekly"), []string{"finops_scoring"}, func(ctx context.Context) error {
+			return s.runJob(ctx, "finops_scoring_weekly", 1, 24*time.Hour, s.FinOpsWeeklyScoringJob)
+		}},
+		{"finops_scoring_monthly", s.isJobEnabled("finops_scoring_monthly"), []string{"finops_scoring"}, func(ctx context.Context) error {
+			return s.runJob(ctx, "finops_scoring_monthly", 1, 24*time.Hour, s.FinOpsMonthlyScoringJob)
+		}},
+		{"lag_probe", s.isJobEnabled("lag_probe"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "lag_probe", 1, 10*time.Second, s.LagProbeJob)
+		}},
+		{"public_token_probe", s.isJobEnabled("public_token_probe"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "public_token_probe", 1, 30*time.Second, s.PublicTokenProbeJob)
+		}},
+		{"invoice_reminders", s.isJobEnabled("invoice_reminders"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "invoice_reminders", s.cfg.BatchSize, 2*time.Minute, s.InvoiceRemindersJob)
+		}},
+		{"action_retention", s.cfg.ActionRetentionEnabled && s.isJobEnabled("action_retention"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "action_retention", 1, 10*time.Minute, s.ActionRetentionJob)
+		}},
+		{"ar_reconciliation", s.cfg.ARReconciliationEnabled && s.isJobEnabled("ar_reconciliation"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "ar_reconciliation", s.cfg.BatchSize, 10*time.Minute, s.ARReconciliationJob)
+		}},
+		{"idempotency_cleanup", s.isJobEnabled("idempotency_cleanup"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "idempotency_cleanup", idempotencyCleanupBatchSize, 5*time.Minute, s.IdempotencyCleanupJob)
+		}},
+		{"job_run_cleanup", s.isJobEnabled("job_run_cleanup"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "job_run_cleanup", jobRunCleanupBatchSize, 5*time.Minute, s.JobRunCleanupJob)
+		}},
+		{"auto_assign", s.cfg.AutoAssignEnabled && s.isJobEnabled("auto_assign"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "auto_assign", s.cfg.BatchSize, 5*time.Minute, s.AutoAssignJob)
+		}},
+		{"ensure_public_tokens", s.cfg.EnsurePublicTokensEnabled && s.isJobEnabled("ensure_public_tokens"), nil, func(ctx context.Context) error {
+			return s.runJob(ctx, "ensure_public_tokens", s.cfg.BatchSize, 5*time.Minute, s.EnsurePublicTokensJob)
+		}},
 	}
-
-	for _, job := range otherJobs {
-		if job.Enabled {
-			err = errors.Join(err, job.Run(parent))
-		}
-	}
-
-	return err
 }
 
+// RunForever runs each job on its own schedule until ctx is cancelled, and
+// sleeps until the earliest job is next due. Jobs without a JobSchedules
+// entry run every RunInterval.
+// Cancelling ctx stops new jobs from starting; jobs already running keep
+// going for up to ShutdownTimeout and are then cancelled. RunForever returns
+// once the current pass has finished.
 func (s *Scheduler) RunForever(ctx context.Context) {
-	ticker := time.NewTicker(s.cfg.RunInterval)
-	defer ticker.Stop()
-	nextRun := s.clock.Now().Add(s.cfg.RunInterval)
 	schedMetrics := obsmetrics.Scheduler()
+	nextRuns := make(map[string]time.Time)
+
+	jobCtx, cancelJobs := s.drainContext(ctx)
+	defer cancelJobs()
 
 	for {
-		runLag := time.Since(nextRun)
-		if runLag > 0 {
-			schedMetrics.ObserveRunLoopLag(runLag)
-		}
-		if err := s.RunOnce(ctx); err != nil {
+		now := s.clock.Now()
+		if err := s.runDueJobs(jobCtx, s.scheduledJobs(), now, nextRuns); err != nil {
 			s.log.Warn("scheduler run failed", zap.Error(err))
 		}
-		nextRun = nextRun.Add(s.cfg.RunInterval)
+		nextRun := s.nextDue(nextRuns, now)
 
+		timer := time.NewTimer(nextRun.Sub(s.clock.Now()))
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return
-		case <-ticker.C:
+		case <-timer.C:
+		}
+		if runLag := s.clock.Now().Sub(nextRun); runLag > 0 {
+			schedMetrics.ObserveRunLoopLag(runLag)
+		}
+	}
+}
+
+// scheduleFor returns the job's schedule, defaulting to RunInterval.
+func (s *Scheduler) scheduleFor(name string) schedule {
+	if sched, ok := s.schedules[name]; ok {
+		return sched
+	}
+	return intervalSchedule(s.cfg.RunInterval)
+}
+
+// runDueJobs runs the enabled jobs whose next run is at or before now and
+// moves their next run along their schedule. nextRuns holds each enabled
+// job's next run; a job seen for the first time is due now unless its
+// schedule waits for the next match.
+func (s *Scheduler) runDueJobs(ctx context.Context, jobs []scheduledJob, now time.Time, nextRuns map[string]time.Time) error {
+	due := make([]scheduledJob, len(jobs))
+	for i, job := range jobs {
+		if !job.Enabled {
+			delete(nextRuns, job.Name)
+			due[i] = job
+			continue
+		}
+		sched := s.scheduleFor(job.Name)
+		nextRun, ok := nextRuns[job.Name]
+		if !ok {
+			nextRun = now
+			if !sched.runAtStart() {
+				nextRun = sched.next(now)
+			}
+		}
+		job.Enabled = !nextRun.After(now)
+		if job.Enabled {
+			nextRun = sched.next(now)
 		}
+		nextRuns[job.Name] = nextRun
+		due[i] = job
 	}
+	return s.runJobs(ctx, due)
+}
+
+// nextDue returns the earliest next run, or now plus RunInterval when no job
+// is scheduled.
+func (s *Scheduler) nextDue(nextRuns map[string]time.Time, now time.Time) time.Time {
+	earliest := now.Add(s.cfg.RunInterval)
+	for _, nextRun := range nextRuns {
+		if !nextRun.IsZero() && nextRun.Before(earliest) {
+			earliest = nextRun
+		}
+	}
+	return earliest
 }
 
 func (s *Scheduler) isJobEnabled(jobName string) bool {
@@ -301,7 +447,7 @@ func (s *Scheduler) CloseCyclesJob(ctx context.Context) error {
 	var jobErr error
 
 	for {
-		cycles, err := s.fetchBillingCyclesForWork(ctx, `status = ? AND period_end <= ?`, []any{billingcycledomain.BillingCycleStatusOpen, now}, s.cfg.MaxCloseBatchSize)
+		cycles, err := s.fetchBillingCyclesForWork(ctx, cyclesDueForClose, []any{billingcycledomain.BillingCycleStatusOpen, now}, s.cfg.MaxCloseBatchSize)
 		if err != nil {
 			s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "close_cycles", 0, err)
 			return err
@@ -367,7 +513,12 @@ func (s *Scheduler) RatingJob(ctx context.Context) error {
 	var jobErr error
 
 	for {
-		cycles, err := s.fetchBillingCyclesForWork(ctx, `status = ? AND rating_completed_at IS NULL`, []any{billingcycledomain.BillingCycleStatusClosing}, s.cfg.MaxRatingBatchSize)
+		cycles, err := s.fetchBillingCyclesForWork(
+			ctx,
+			cyclesPendingRating,
+			[]any{billingcycledomain.BillingCycleStatusClosing, now},
+			s.cfg.MaxRatingBatchSize,
+		)
 		if err != nil {
 			s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "rating", 0, err)
 			return err
@@ -409,7 +560,10 @@ func (s *Scheduler) RatingJob(ctx context.Context) error {
 				if s.cloudMetrics != nil {
 					go s.cloudMetrics.IncEngineError(cycle.OrgID.String(), "rating")
 				}
-				_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageRating, err)
+				metadata := map[string]any{
+					"error": err.Error(),
+				}
+				s.handleRatingFailure(ctx, cycleCtx, run, "rating", obsmetrics.CycleStageRating, cycle, now, err, metadata)
 				s.emitAuditEvent(cycleCtx, auditEvent{
 					OrgID:          cycle.OrgID,
 					Action:         "rating.failed",
@@ -417,9 +571,7 @@ func (s *Scheduler) RatingJob(ctx context.Context) error {
 					TargetID:       cycle.ID.String(),
 					SubscriptionID: cycle.SubscriptionID.String(),
 					BillingCycleID: cycle.ID.String(),
-					Metadata: map[string]any{
-						"error": err.Error(),
-					},
+					Metadata:       metadata,
 				})
 				continue
 			}
@@ -464,6 +616,56 @@ func (s *Scheduler) RatingJob(ctx context.Context) error {
 	return jobErr
 }
 
+// handleRatingFailure records a failed rating attempt against the cycle and
+// enriches metadata with the retry outcome. When the retry budget is spent it
+// emits billing_cycle.rating_failed so the cycle surfaces for ops follow-up.
+func (s *Scheduler) handleRatingFailure(
+	ctx context.Context,
+	cycleCtx context.Context,
+	run *jobRun,
+	jobName string,
+	stage string,
+	cycle WorkBillingCycle,
+	now time.Time,
+	cause error,
+	metadata map[string]any,
+) {
+	obsmetrics.Scheduler().IncBillingCycleError(stage, cause)
+	failure, err := s.recordRatingFailure(ctx, cycle.ID, now, cause)
+	if err != nil {
+		s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", jobName, cycle.OrgID, err,
+			zap.String("cycle_id", idString(cycle.ID)),
+			zap.String("subscription_id", idString(cycle.SubscriptionID)),
+		)
+		return
+	}
+	if failure == nil {
+		return
+	}
+
+	metadata["attempts"] = failure.Attempts
+	if failure.NextRetryAt != nil {
+		metadata["next_retry_at"] = failure.NextRetryAt.Format(time.RFC3339)
+	}
+	if !failure.Exhausted {
+		return
+	}
+
+	s.emitAuditEvent(cycleCtx, auditEvent{
+		OrgID:          cycle.OrgID,
+		Action:         "billing_cycle.rating_failed",
+		TargetType:     "billing_cycle",
+		TargetID:       cycle.ID.String(),
+		SubscriptionID: cycle.SubscriptionID.String(),
+		BillingCycleID: cycle.ID.String(),
+		Metadata: map[string]any{
+			"attempts":     failure.Attempts,
+			"max_attempts": s.cfg.MaxRatingAttempts,
+			"error":        cause.Error(),
+		},
+	})
+}
+
 func (s *Scheduler) CloseAfterRatingJob(ctx context.Context) error {
 	ctx, run, owner := s.ensureJobRun(ctx, "close_after_rating", s.cfg.MaxCloseBatchSize)
 	if owner {
@@ -474,7 +676,7 @@ func (s *Scheduler) CloseAfterRatingJob(ctx context.Context) error {
 	var jobErr error
 
 	for {
-		cycles, err := s.fetchBillingCyclesForWork(ctx, `status = ? AND rating_completed_at IS NOT NULL`, []any{billingcycledomain.BillingCycleStatusClosing}, s.cfg.MaxCloseBatchSize)
+		cycles, err := s.fetchBillingCyclesForWork(ctx, cyclesRated, []any{billingcycledomain.BillingCycleStatusClosing}, s.cfg.MaxCloseBatchSize)
 		if err != nil {
 			s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "close_after_rating", 0, err)
 			return err
@@ -573,7 +775,7 @@ func (s *Scheduler) InvoiceJob(ctx context.Context) error {
 	schedMetrics := obsmetrics.Scheduler()
 
 	for {
-		cycles, err := s.fetchBillingCyclesForWork(ctx, `status = ? AND invoiced_at IS NULL`, []any{billingcycledomain.BillingCycleStatusClosed}, s.cfg.MaxInvoiceBatchSize)
+		cycles, err := s.fetchBillingCyclesForWork(ctx, cyclesPendingInvoice, []any{billingcycledomain.BillingCycleStatusClosed}, s.cfg.MaxInvoiceBatchSize)
 		if err != nil {
 			s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "invoice", 0, err)
 			return err
@@ -594,7 +796,7 @@ func (s *Scheduler) InvoiceJob(ctx context.Context) error {
 			}
 
 			cycleCtx := s.withAuditContext(ctx, cycle.SubscriptionID.String(), cycle.ID.String())
-			invoice, err := s.invoiceSvc.GenerateInvoice(cycleCtx, cycle.ID.String())
+			generated, err := s.invoiceSvc.GenerateInvoice(cycleCtx, cycle.ID.String(), invoicedomain.GenerationIdempotencyKey(cycle.ID))
 			if err != nil {
 				jobErr = errors.Join(jobErr, err)
 				s.logSchedulerError(ctx, run, "invoice.generate.failed", "invoice", cycle.OrgID, err,
@@ -608,13 +810,38 @@ func (s *Scheduler) InvoiceJob(ctx context.Context) error {
 				continue
 			}
 
-			if invoice == nil {
+			if generated != nil && generated.CarryForward != nil {
+				if !generated.Duplicate {
+					s.logInvoiceCarriedForward(ctx, cycle, generated.CarryForward)
+				}
+				if err := s.markCycleCarriedForward(ctx, cycle.ID, now); err != nil {
+					jobErr = errors.Join(jobErr, err)
+					s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "invoice", cycle.OrgID, err,
+						zap.String("cycle_id", idString(cycle.ID)),
+						zap.String("subscription_id", idString(cycle.SubscriptionID)),
+					)
+					_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageInvoice, err)
+					continue
+				}
+				run.AddProcessed(1)
+				schedMetrics.IncBillingCycleTransition(
+					string(billingcycledomain.BillingCycleStatusClosed),
+					obsmetrics.BillingCycleTransitionInvoiced,
+				)
 				continue
 			}
+			if generated == nil || generated.Invoice == nil {
+				continue
+			}
+			invoice := generated.Invoice
 
-			s.logInvoiceGenerated(ctx, cycle, invoice.ID)
-			if s.cloudMetrics != nil {
-				go s.cloudMetrics.IncInvoiceGenerated(cycle.OrgID.String())
+			// A duplicate hit means an earlier attempt generated the invoice but
+			// did not get to mark the cycle; finish that bookkeeping now.
+			if !generated.Duplicate {
+				s.logInvoiceGenerated(ctx, cycle, invoice.ID)
+				if s.cloudMetrics != nil {
+					go s.cloudMetrics.IncInvoiceGenerated(cycle.OrgID.String())
+				}
 			}
 
 			if err := s.markCycleInvoiced(ctx, cycle.ID, now); err != nil {
@@ -639,34 +866,11 @@ func (s *Scheduler) InvoiceJob(ctx context.Context) error {
 
 			switch invoice.Status {
 			case invoicedomain.InvoiceStatusDraft:
-				if err := s.authorizeSystem(ctx, cycle.OrgID, authorization.ObjectInvoice, authorization.ActionInvoiceFinalize); err != nil {
-					jobErr = errors.Join(jobErr, err)
-					s.logSchedulerError(ctx, run, "scheduler.authorize.failed", "invoice", cycle.OrgID, err,
-						zap.String("cycle_id", idString(cycle.ID)),
-						zap.String("invoice_id", idString(invoice.ID)),
-						zap.String("subscription_id", idString(cycle.SubscriptionID)),
-					)
+				if s.inFinalizeGraceWindow(invoice.CreatedAt, now) {
 					continue
 				}
-				if err := s.invoiceSvc.FinalizeInvoice(cycleCtx, invoice.ID.String()); err != nil {
+				if err := s.finalizeDraftInvoice(ctx, cycleCtx, run, cycle, invoice.ID, now); err != nil {
 					jobErr = errors.Join(jobErr, err)
-					s.logSchedulerError(ctx, run, "invoice.finalize.failed", "invoice", cycle.OrgID, err,
-						zap.String("cycle_id", idString(cycle.ID)),
-						zap.String("invoice_id", idString(invoice.ID)),
-						zap.String("subscription_id", idString(cycle.SubscriptionID)),
-					)
-					_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageInvoice, err)
-					continue
-				}
-				s.logInvoiceFinalized(ctx, cycle, invoice.ID)
-				if err := s.markCycleInvoiceFinalized(ctx, cycle.ID, now); err != nil {
-					jobErr = errors.Join(jobErr, err)
-					s.logSchedulerError(ctx, run, "scheduler.cycle.process.failed", "invoice", cycle.OrgID, err,
-						zap.String("cycle_id", idString(cycle.ID)),
-						zap.String("invoice_id", idString(invoice.ID)),
-						zap.String("subscription_id", idString(cycle.SubscriptionID)),
-					)
-					_ = s.recordCycleErrorWithMetrics(ctx, cycle.ID, obsmetrics.CycleStageInvoice, err)
 				}
 			case invoicedomain.InvoiceStatusFinalized:
 				if err := s.markCycleInvoiceFinalized(ctx, cycle.ID, now); err != nil {
@@ -682,6 +886,12 @@ func (s *Scheduler) InvoiceJob(ctx context.Context) error {
 		}
 	}
 
+	if s.cfg.FinalizeInvoices && s.cfg.FinalizeAfter > 0 {
+		if err := s.finalizeGracedDrafts(ctx, run, now); err != nil {
+			jobErr = errors.Join(jobErr, err)
+		}
+	}
+
 	return jobErr
 }
 
@@ -864,6 +1074,10 @@ func maxInt(values ...int) int {
 func (s *Scheduler) ensureSubscriptionCycle(ctx context.Context, tx *gorm.DB, subscription WorkSubscription, now time.Time, events *[]auditEvent) error {
 	// NO authorize here
 	if err := guard.EnsureSubscriptionCanOpenBillingCycle(subscription.Status, subscription.ActivatedAt, subscription.BillingCycleType); err != nil {
+		if errors.Is(err, guard.ErrSubscriptionPaused) {
+			// Paused subscriptions get no new cycles until they resume.
+			return nil
+		}
 		return err
 	}
 
@@ -911,6 +1125,11 @@ func (s *Scheduler) ensureSubscriptionCycle(ctx context.Context, tx *gorm.DB, su
 	if lastCycle != nil && lastCycle.PeriodEnd.After(periodStart) {
 		periodStart = lastCycle.PeriodEnd
 	}
+	// A subscription resumed after its last cycle ended restarts billing
+	// from the resume time rather than back-filling the paused period.
+	if subscription.ResumedAt != nil && subscription.ResumedAt.After(periodStart) {
+		periodStart = *subscription.ResumedAt
+	}
 	if periodStart.After(now) {
 		return nil
 	}
@@ -1022,6 +1241,52 @@ func (s *Scheduler) SLAEvaluationJob(ctx context.Context) error {
 	return nil
 }
 
+// ActionRetentionJob moves billing operations actions older than the
+// configured retention out of the live actions table.
+func (s *Scheduler) ActionRetentionJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "action_retention", 1)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+
+	moved, err := s.billingOperationsSvc.ArchiveOldActions(ctx, s.clock.Now().UTC().Add(-s.cfg.ActionRetention))
+	run.AddProcessed(moved)
+	if err != nil {
+		s.logSchedulerError(ctx, run, "action_retention.failed", "action_retention", 0, err)
+		return err
+	}
+
+	return nil
+}
+
+// idempotencyCleanupBatchSize bounds how many expired keys one delete
+// removes, so a large backlog is cleared in short statements.
+const idempotencyCleanupBatchSize = 1000
+
+// IdempotencyCleanupJob deletes ledger idempotency keys whose TTL has
+// passed.
+func (s *Scheduler) IdempotencyCleanupJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "idempotency_cleanup", idempotencyCleanupBatchSize)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+
+	now := s.clock.Now().UTC()
+	for {
+		deleted, err := ledger.PurgeExpiredIdempotencyKeys(ctx, s.db, now, idempotencyCleanupBatchSize)
+		run.AddProcessed(int(deleted))
+		if err != nil {
+			s.logSchedulerError(ctx, run, "idempotency_cleanup.failed", "idempotency_cleanup", 0, err)
+			return err
+		}
+		if deleted < idempotencyCleanupBatchSize {
+			return nil
+		}
+	}
+}
+
 func (s *Scheduler) FinOpsScoringJob(ctx context.Context) error {
 	ctx, run, owner := s.ensureJobRun(ctx, "finops_scoring", 1)
 	if owner {
@@ -1036,3 +1301,33 @@ func (s *Scheduler) FinOpsScoringJob(ctx context.Context) error {
 
 	return nil
 }
+
+func (s *Scheduler) FinOpsWeeklyScoringJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "finops_scoring_weekly", 1)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+
+	if err := s.billingOperationsSvc.AggregateWeeklyPerformance(ctx); err != nil {
+		s.logSchedulerError(ctx, run, "finops.scoring_weekly.failed", "finops_scoring_weekly", 0, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *Scheduler) FinOpsMonthlyScoringJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "finops_scoring_monthly", 1)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+
+	if err := s.billingOperationsSvc.AggregateMonthlyPerformance(ctx); err != nil {
+		s.logSchedulerError(ctx, run, "finops.scoring_monthly.failed", "finops_scoring_monthly", 0, err)
+		return err
+	}
+
+	return nil
+}
@@ -37,6 +37,7 @@ func (m *mockRatingSvc) RunRating(ctx context.Context, cycleID string) error {
 type mockInvoiceSvc struct {
 	genFunc func(ctx context.Context, cycleID string) (*invoicedomain.Invoice, error)
 	finFunc func(ctx context.Context, invoiceID string) error
+	genKeys []string
 }
 
 func (m *mockInvoiceSvc) List(context.Context, invoicedomain.ListInvoiceRequest) (invoicedomain.ListInvoiceResponse, error) {
@@ -48,9 +49,20 @@ func (m *mockInvoiceSvc) GetByID(ctx context.Context, id string) (invoicedomain.
 func (m *mockInvoiceSvc) RenderInvoice(ctx context.Context, invoiceID string) (invoicedomain.RenderInvoiceResponse, error) {
 	return invoicedomain.RenderInvoiceResponse{}, nil
 }
-func (m *mockInvoiceSvc) GenerateInvoice(ctx context.Context, billingCycleID string) (*invoicedomain.Invoice, error) {
+func (m *mockInvoiceSvc) GenerateInvoicePDF(ctx context.Context, invoiceID string, templateID *string) ([]byte, error) {
+	return nil, nil
+}
+func (m *mockInvoiceSvc) PreviewInvoice(ctx context.Context, billingCycleID string) (invoicedomain.InvoicePreview, error) {
+	return invoicedomain.InvoicePreview{}, nil
+}
+func (m *mockInvoiceSvc) GenerateInvoice(ctx context.Context, billingCycleID string, idempotencyKey string) (*invoicedomain.GenerateInvoiceResult, error) {
+	m.genKeys = append(m.genKeys, idempotencyKey)
 	if m.genFunc != nil {
-		return m.genFunc(ctx, billingCycleID)
+		invoice, err := m.genFunc(ctx, billingCycleID)
+		if err != nil || invoice == nil {
+			return nil, err
+		}
+		return &invoicedomain.GenerateInvoiceResult{Invoice: invoice}, nil
 	}
 	return nil, nil
 }
@@ -60,9 +72,12 @@ func (m *mockInvoiceSvc) FinalizeInvoice(ctx context.Context, invoiceID string)
 	}
 	return nil
 }
-func (m *mockInvoiceSvc) VoidInvoice(ctx context.Context, invoiceID string, reason string) error {
+func (m *mockInvoiceSvc) VoidInvoice(ctx context.Context, invoiceID string, reason string, idempotencyKey string) error {
 	return nil
 }
+func (m *mockInvoiceSvc) IssueCreditNote(ctx context.Context, invoiceID string, amount int64, reason string, idempotencyKey string) (*invoicedomain.CreditNote, error) {
+	return nil, nil
+}
 
 type mockLedgerSvc struct{}
 
@@ -70,6 +85,14 @@ func (m *mockLedgerSvc) CreateEntry(ctx context.Context, orgID snowflake.ID, sou
 	return nil
 }
 
+func (m *mockLedgerSvc) CreateEntryTx(ctx context.Context, _ *gorm.DB, orgID snowflake.ID, sourceType string, sourceID snowflake.ID, currency string, occurredAt time.Time, lines []ledgerdomain.LedgerEntryLine) error {
+	return m.CreateEntry(ctx, orgID, sourceType, sourceID, currency, occurredAt, lines)
+}
+
+func (m *mockLedgerSvc) ListLedgerEntries(context.Context, ledgerdomain.ListEntriesFilter) (ledgerdomain.ListEntriesResponse, error) {
+	return ledgerdomain.ListEntriesResponse{}, nil
+}
+
 type mockSubscriptionSvc struct{}
 
 func (m *mockSubscriptionSvc) List(context.Context, subscriptiondomain.ListSubscriptionRequest) (subscriptiondomain.ListSubscriptionResponse, error) {
@@ -99,6 +122,12 @@ func (m *mockSubscriptionSvc) ValidateUsageEntitlement(ctx context.Context, subs
 func (m *mockSubscriptionSvc) ChangePlan(ctx context.Context, req subscriptiondomain.ChangePlanRequest) error {
 	return nil
 }
+func (m *mockSubscriptionSvc) PauseSubscription(ctx context.Context, id string, resumeAt *time.Time) error {
+	return nil
+}
+func (m *mockSubscriptionSvc) ResumeSubscription(ctx context.Context, id string) error {
+	return nil
+}
 
 type mockAuditSvc struct{}
 
@@ -106,6 +135,10 @@ func (m *mockAuditSvc) AuditLog(ctx context.Context, orgID *snowflake.ID, userID
 	return nil
 }
 
+func (m *mockAuditSvc) AuditLogTx(ctx context.Context, _ *gorm.DB, orgID *snowflake.ID, actorType string, actorID *string, action string, targetType string, targetID *string, metadata map[string]any) error {
+	return m.AuditLog(ctx, orgID, actorType, actorID, action, targetType, targetID, metadata)
+}
+
 func (m *mockAuditSvc) List(ctx context.Context, req auditdomain.ListAuditLogRequest) (auditdomain.ListAuditLogResponse, error) {
 	return auditdomain.ListAuditLogResponse{}, nil
 }
@@ -158,6 +191,8 @@ func TestScheduler_RunOnce_FakeClock_30Days(t *testing.T) {
 			org_id INTEGER,
 			status TEXT,
 			activated_at DATETIME,
+			resumed_at DATETIME,
+			resume_at DATETIME,
 			billing_cycle_type TEXT
 		)
 	`).Error; err != nil {
@@ -175,6 +210,8 @@ func TestScheduler_RunOnce_FakeClock_30Days(t *testing.T) {
 			opened_at DATETIME,
 			closing_started_at DATETIME,
 			rating_completed_at DATETIME,
+			rating_attempts INTEGER NOT NULL DEFAULT 0,
+			next_rating_retry_at DATETIME,
 			invoiced_at DATETIME,
 			invoice_finalized_at DATETIME,
 			closed_at DATETIME,
@@ -10,6 +10,7 @@ import (
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smallbiznis/railzway/internal/clock"
 	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
 	"go.uber.org/zap"
 )
 
@@ -105,3 +106,63 @@ func labelsMatch(metric *dto.Metric, labels map[string]string) bool {
 	}
 	return true
 }
+
+func TestRatingRetryDelayBacksOffAndCaps(t *testing.T) {
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Minute},
+		{attempts: 1, want: time.Minute},
+		{attempts: 2, want: 5 * time.Minute},
+		{attempts: 3, want: 30 * time.Minute},
+		{attempts: 10, want: 30 * time.Minute},
+	}
+	for _, tc := range cases {
+		if got := ratingRetryDelay(tc.attempts); got != tc.want {
+			t.Fatalf("attempts=%d: expected %v, got %v", tc.attempts, tc.want, got)
+		}
+	}
+}
+
+func TestInFinalizeGraceWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		delay     time.Duration
+		createdAt time.Time
+		want      bool
+	}{
+		{name: "disabled", delay: 0, createdAt: now, want: false},
+		{name: "fresh draft", delay: time.Hour, createdAt: now.Add(-10 * time.Minute), want: true},
+		{name: "at cutoff", delay: time.Hour, createdAt: now.Add(-time.Hour), want: false},
+		{name: "elapsed", delay: time.Hour, createdAt: now.Add(-2 * time.Hour), want: false},
+		{name: "missing created_at", delay: time.Hour, createdAt: time.Time{}, want: false},
+	}
+	for _, tc := range cases {
+		s := &Scheduler{cfg: Config{FinalizeAfter: tc.delay}}
+		if got := s.inFinalizeGraceWindow(tc.createdAt, now); got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestEnsureSubscriptionCycleSkipsPausedSubscription(t *testing.T) {
+	activatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &Scheduler{}
+	events := make([]auditEvent, 0)
+
+	err := s.ensureSubscriptionCycle(context.Background(), nil, WorkSubscription{
+		ID:               1,
+		OrgID:            1,
+		Status:           subscriptiondomain.SubscriptionStatusPaused,
+		ActivatedAt:      &activatedAt,
+		BillingCycleType: "monthly",
+	}, activatedAt.AddDate(0, 2, 0), &events)
+	if err != nil {
+		t.Fatalf("expected paused subscription to be skipped, got %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no cycle events, got %d", len(events))
+	}
+}
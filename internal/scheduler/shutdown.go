@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type stopSignalKey struct{}
+
+// drainContext returns the context jobs run under while stop governs the
+// loop. It is detached from stop's cancellation so a shutdown lets running
+// jobs finish, and is cancelled ShutdownTimeout after stop is, or when
+// cancel is called. stop stays reachable from it so no new job starts once
+// shutdown began.
+func (s *Scheduler) drainContext(stop context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(stop))
+	ctx = context.WithValue(ctx, stopSignalKey{}, stop)
+
+	stopDrain := context.AfterFunc(stop, func() {
+		timer := time.NewTimer(s.cfg.ShutdownTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			s.log.Warn("scheduler shutdown timeout reached, cancelling running jobs",
+				zap.Duration("timeout", s.cfg.ShutdownTimeout),
+			)
+			cancel()
+		case <-ctx.Done():
+		}
+	})
+	return ctx, func() {
+		stopDrain()
+		cancel()
+	}
+}
+
+// stopping reports whether ctx is cancelled or belongs to a scheduler that
+// was asked to stop.
+func stopping(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	stop, ok := ctx.Value(stopSignalKey{}).(context.Context)
+	return ok && stop.Err() != nil
+}
@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smallbiznis/railzway/internal/clock"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"go.uber.org/zap"
+)
+
+func TestRunJobs_StopsStartingJobsOnShutdown(t *testing.T) {
+	s := &Scheduler{log: zap.NewNop(), cfg: Config{ShutdownTimeout: time.Minute}.withDefaults()}
+	stop, shutdown := context.WithCancel(context.Background())
+	ctx, cancel := s.drainContext(stop)
+	defer cancel()
+
+	var ran []string
+	err := s.runJobs(ctx, []scheduledJob{
+		{Name: "rating", Enabled: true, Run: func(ctx context.Context) error {
+			ran = append(ran, "rating")
+			shutdown()
+			// The running job keeps its context through the drain window.
+			if err := ctx.Err(); err != nil {
+				t.Errorf("running job cancelled on shutdown: %v", err)
+			}
+			return nil
+		}},
+		{Name: "invoice", Enabled: true, Run: func(context.Context) error {
+			ran = append(ran, "invoice")
+			return nil
+		}},
+	})
+	if err != nil {
+		t.Fatalf("run jobs: %v", err)
+	}
+	if len(ran) != 1 || ran[0] != "rating" {
+		t.Fatalf("expected only rating to run, got %v", ran)
+	}
+}
+
+func TestDrainContext_CancelsAfterShutdownTimeout(t *testing.T) {
+	s := &Scheduler{log: zap.NewNop(), cfg: Config{ShutdownTimeout: 20 * time.Millisecond}}
+	stop, shutdown := context.WithCancel(context.Background())
+	ctx, cancel := s.drainContext(stop)
+	defer cancel()
+
+	shutdown()
+	if ctx.Err() != nil {
+		t.Fatal("job context cancelled before the shutdown timeout")
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("job context not cancelled after the shutdown timeout")
+	}
+}
+
+func TestRunJob_CountsShutdownInterruption(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	restore := swapPrometheusRegistry(registry)
+	defer restore()
+
+	obsmetrics.ResetSchedulerMetricsForTest()
+	obsmetrics.SchedulerWithConfig(obsmetrics.Config{
+		ServiceName: "railzway",
+		Environment: "test",
+	})
+
+	node, err := snowflake.NewNode(1)
+	if err != nil {
+		t.Fatalf("snowflake node: %v", err)
+	}
+	s := &Scheduler{log: zap.NewNop(), genID: node, clock: clock.NewFakeClock(time.Time{})}
+
+	parent, cancel := context.WithCancel(context.Background())
+	err = s.runJob(parent, "invoice", 0, time.Minute, func(ctx context.Context) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	labels := map[string]string{
+		"service": "railzway",
+		"env":     "test",
+		"job":     "invoice",
+	}
+	if got := getCounterValue(t, registry, "railzway_scheduler_job_interrupted_total", labels); got != 1 {
+		t.Fatalf("expected interrupted count 1, got %v", got)
+	}
+}
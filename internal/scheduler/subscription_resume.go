@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/smallbiznis/railzway/internal/authorization"
+	obsmetrics "github.com/smallbiznis/railzway/internal/observability/metrics"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ResumePausedSubscriptionsJob resumes paused subscriptions whose resume_at
+// has passed. Once resumed, ensure_cycles opens their next billing cycle
+// from the resume time.
+func (s *Scheduler) ResumePausedSubscriptionsJob(ctx context.Context) error {
+	ctx, run, owner := s.ensureJobRun(ctx, "resume_paused_subs", s.cfg.BatchSize)
+	if owner {
+		s.logJobStart(ctx, run)
+		defer s.logJobFinish(ctx, run)
+	}
+	now := s.clock.Now().UTC()
+
+	var subscriptions []WorkSubscription
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		subscriptions, err = s.fetchSubscriptionsDueForResume(ctx, tx, now, s.cfg.BatchSize)
+		return err
+	})
+	if err != nil {
+		s.logSchedulerError(ctx, run, "scheduler.subscription.resume.failed", "resume_paused_subs", 0, err)
+		return err
+	}
+
+	var jobErr error
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			jobErr = errors.Join(jobErr, ctx.Err())
+			break
+		}
+
+		if err := s.authorizeSystem(ctx, subscription.OrgID, authorization.ObjectSubscription, authorization.ActionSubscriptionResume); err != nil {
+			jobErr = errors.Join(jobErr, err)
+			s.logSchedulerError(ctx, run, "scheduler.authorize.failed", "resume_paused_subs", subscription.OrgID, err,
+				zap.String("subscription_id", idString(subscription.ID)),
+			)
+			continue
+		}
+
+		ctxWithOrg := orgcontext.WithOrgID(ctx, int64(subscription.OrgID))
+		ctxWithAudit := s.withAuditContext(ctxWithOrg, subscription.ID.String(), "")
+		if err := s.subscriptionSvc.ResumeSubscription(ctxWithAudit, subscription.ID.String()); err != nil {
+			jobErr = errors.Join(jobErr, err)
+			s.logSchedulerError(ctx, run, "scheduler.subscription.resume.failed", "resume_paused_subs", subscription.OrgID, err,
+				zap.String("subscription_id", idString(subscription.ID)),
+			)
+			continue
+		}
+		run.AddProcessed(1)
+
+		s.emitAuditEvent(ctxWithAudit, auditEvent{
+			OrgID:          subscription.OrgID,
+			Action:         "subscription.resume",
+			TargetType:     "subscription",
+			TargetID:       subscription.ID.String(),
+			SubscriptionID: subscription.ID.String(),
+			Metadata: map[string]any{
+				"reason": "scheduler",
+			},
+		})
+	}
+
+	return jobErr
+}
+
+func (s *Scheduler) fetchSubscriptionsDueForResume(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]WorkSubscription, error) {
+	var subscriptions []WorkSubscription
+	schedMetrics := obsmetrics.Scheduler()
+	lockStart := time.Now()
+	err := tx.WithContext(ctx).Raw(
+		`SELECT id, org_id, status, activated_at, resumed_at, billing_cycle_type
+		 FROM subscriptions
+		 WHERE status = ?
+		   AND resume_at IS NOT NULL
+		   AND resume_at <= ?
+		 ORDER BY resume_at, id
+		 FOR UPDATE SKIP LOCKED
+		 LIMIT ?`,
+		subscriptiondomain.SubscriptionStatusPaused,
+		now,
+		limit,
+	).Scan(&subscriptions).Error
+	schedMetrics.ObserveDBLockWait(obsmetrics.LockResourceSubscriptionsForWork, time.Since(lockStart))
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The billing cycle work indexes are Postgres partial indexes, so these run
+// against a migrated scratch database. Seeded cycles live in a transaction
+// that is rolled back:
+//
+//	SCHEDULER_PG_DSN=postgres://... go test ./internal/scheduler -run WorkQueries
+//	SCHEDULER_PG_DSN=postgres://... go test ./internal/scheduler -run '^$' -bench WorkQueries
+//	SCHEDULER_PG_DSN=postgres://... go test ./internal/scheduler -run WorkQueryTimings -v
+//
+// The last one logs the before/after table for the 0067 migration, taken
+// from the server's own execution times so client latency stays out of it.
+
+const seededWorkCycles = 100_000
+
+// workIndexes are the partial indexes added for the work queries; the
+// benchmark drops them to measure the queries without.
+var workIndexes = []string{
+	"idx_billing_cycles_open_due",
+	"idx_billing_cycles_closing_unrated",
+	"idx_billing_cycles_closing_rated",
+	"idx_billing_cycles_closed_uninvoiced",
+	"idx_billing_cycles_closed_unfinalized",
+}
+
+type workQueryCase struct {
+	name  string
+	where string
+	args  []any
+	// indexes lists the partial indexes the plan may use; the older rating
+	// retry index shares the unrated predicate.
+	indexes []string
+}
+
+func workQueryCases(now time.Time) []workQueryCase {
+	cutoff := now.Add(-time.Hour)
+	unrated := []string{"idx_billing_cycles_closing_unrated", "idx_billing_cycles_rating_retry"}
+	return []workQueryCase{
+		{"due_for_close", cyclesDueForClose, []any{billingcycledomain.BillingCycleStatusOpen, now}, []string{"idx_billing_cycles_open_due"}},
+		{"pending_rating", cyclesPendingRating, []any{billingcycledomain.BillingCycleStatusClosing, now}, unrated},
+		{"stuck_in_rating", cyclesStuckInRating, []any{billingcycledomain.BillingCycleStatusClosing, cutoff, now}, unrated},
+		{"rated", cyclesRated, []any{billingcycledomain.BillingCycleStatusClosing}, []string{"idx_billing_cycles_closing_rated"}},
+		{"pending_invoice", cyclesPendingInvoice, []any{billingcycledomain.BillingCycleStatusClosed}, []string{"idx_billing_cycles_closed_uninvoiced"}},
+		{"stuck_in_invoicing", cyclesStuckInInvoicing, []any{billingcycledomain.BillingCycleStatusClosed, cutoff}, []string{"idx_billing_cycles_closed_uninvoiced"}},
+		{"graced_drafts", cyclesGracedDrafts, []any{billingcycledomain.BillingCycleStatusClosed, invoicedomain.InvoiceStatusDraft, cutoff}, []string{"idx_billing_cycles_closed_unfinalized"}},
+	}
+}
+
+func TestWorkQueriesUsePartialIndexes(t *testing.T) {
+	tx := seedWorkCycles(t)
+
+	for _, tc := range workQueryCases(time.Now().UTC()) {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := tx.Raw("EXPLAIN "+billingCyclesForWorkQuery(tc.where), append(tc.args, 100)...).Rows()
+			if err != nil {
+				t.Fatalf("explain: %v", err)
+			}
+			defer rows.Close()
+			var plan []string
+			for rows.Next() {
+				var line string
+				if err := rows.Scan(&line); err != nil {
+					t.Fatalf("scan plan: %v", err)
+				}
+				plan = append(plan, line)
+			}
+			text := strings.Join(plan, "\n")
+
+			if strings.Contains(text, "Seq Scan on billing_cycles") {
+				t.Fatalf("expected an index scan, got:\n%s", text)
+			}
+			for _, index := range tc.indexes {
+				if strings.Contains(text, index) {
+					return
+				}
+			}
+			t.Fatalf("expected one of %v, got:\n%s", tc.indexes, text)
+		})
+	}
+}
+
+// workTimingRuns is how many times each query is analysed per plan; the
+// median is reported so one slow run doesn't skew the table.
+const workTimingRuns = 7
+
+// TestWorkQueryTimings logs the median execution time of each work query
+// over the seeded cycles with the partial indexes and after dropping them.
+func TestWorkQueryTimings(t *testing.T) {
+	tx := seedWorkCycles(t)
+	cases := workQueryCases(time.Now().UTC())
+
+	measure := func() map[string]float64 {
+		medians := make(map[string]float64, len(cases))
+		for _, tc := range cases {
+			timings := make([]float64, 0, workTimingRuns)
+			for i := 0; i < workTimingRuns; i++ {
+				var raw string
+				if err := tx.Raw("EXPLAIN (ANALYZE, FORMAT JSON) "+billingCyclesForWorkQuery(tc.where), append(append([]any(nil), tc.args...), 100)...).
+					Row().Scan(&raw); err != nil {
+					t.Fatalf("%s: explain analyze: %v", tc.name, err)
+				}
+				var plans []struct {
+					ExecutionTime float64 `json:"Execution Time"`
+				}
+				if err := json.Unmarshal([]byte(raw), &plans); err != nil || len(plans) == 0 {
+					t.Fatalf("%s: parse plan: %v", tc.name, err)
+				}
+				timings = append(timings, plans[0].ExecutionTime)
+			}
+			sort.Float64s(timings)
+			medians[tc.name] = timings[len(timings)/2]
+		}
+		return medians
+	}
+
+	with := measure()
+	for _, index := range workIndexes {
+		if err := tx.Exec("DROP INDEX " + index).Error; err != nil {
+			t.Fatalf("drop %s: %v", index, err)
+		}
+	}
+	without := measure()
+
+	t.Logf("%d seeded cycles, median of %d runs (ms)", seededWorkCycles, workTimingRuns)
+	t.Logf("%-20s %12s %12s", "query", "without", "with")
+	for _, tc := range cases {
+		t.Logf("%-20s %12.3f %12.3f", tc.name, without[tc.name], with[tc.name])
+	}
+}
+
+// BenchmarkWorkQueries times each work query over the seeded cycles with the
+// partial indexes, then again after dropping them.
+func BenchmarkWorkQueries(b *testing.B) {
+	tx := seedWorkCycles(b)
+	s := &Scheduler{db: tx}
+	cases := workQueryCases(time.Now().UTC())
+
+	run := func(b *testing.B) {
+		for _, tc := range cases {
+			b.Run(tc.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := s.fetchBillingCyclesForWork(b.Context(), tc.where, append([]any(nil), tc.args...), 100); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+
+	b.Run("with_indexes", run)
+	for _, index := range workIndexes {
+		if err := tx.Exec("DROP INDEX " + index).Error; err != nil {
+			b.Fatalf("drop %s: %v", index, err)
+		}
+	}
+	b.Run("without_indexes", run)
+}
+
+// seedWorkCycles opens SCHEDULER_PG_DSN and inserts seededWorkCycles cycles
+// in a transaction rolled back at cleanup. Like a mature tenant, 95% of them
+// are finished; the rest are spread over the lifecycle steps the scheduler
+// polls for.
+func seedWorkCycles(tb testing.TB) *gorm.DB {
+	tb.Helper()
+	dsn := strings.TrimSpace(os.Getenv("SCHEDULER_PG_DSN"))
+	if dsn == "" {
+		tb.Skip("SCHEDULER_PG_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		tb.Fatalf("open postgres: %v", err)
+	}
+	tx := db.Begin()
+	if tx.Error != nil {
+		tb.Fatalf("begin: %v", tx.Error)
+	}
+	tb.Cleanup(func() { tx.Rollback() })
+
+	if err := tx.Exec(`
+		INSERT INTO billing_cycles (
+			id, org_id, subscription_id, period_start, period_end, status,
+			closing_started_at, rating_completed_at, invoiced_at, invoice_finalized_at, closed_at
+		)
+		SELECT -g, -1, -g, p.period_end - INTERVAL '1 month', p.period_end,
+		       CASE WHEN g % 100 = 0 THEN 'OPEN' WHEN g % 100 IN (1, 2) THEN 'CLOSING' ELSE 'CLOSED' END,
+		       CASE WHEN g % 100 = 0 THEN NULL ELSE p.period_end END,
+		       CASE WHEN g % 100 IN (0, 1) THEN NULL ELSE p.period_end END,
+		       CASE WHEN g % 100 IN (0, 1, 2, 3) THEN NULL ELSE p.period_end END,
+		       CASE WHEN g % 100 IN (0, 1, 2, 3, 4) THEN NULL ELSE p.period_end END,
+		       CASE WHEN g % 100 IN (0, 1, 2) THEN NULL ELSE p.period_end END
+		FROM generate_series(1, ?) AS g
+		CROSS JOIN LATERAL (SELECT now() - (g % 730) * INTERVAL '1 day' AS period_end) p`,
+		seededWorkCycles,
+	).Error; err != nil {
+		tb.Fatalf("seed billing cycles: %v", err)
+	}
+	if err := tx.Exec("ANALYZE billing_cycles").Error; err != nil {
+		tb.Fatalf("analyze billing cycles: %v", err)
+	}
+	return tx
+}
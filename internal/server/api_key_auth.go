@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"crypto/subtle"
+	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -12,8 +14,10 @@ import (
 	apikeydomain "github.com/smallbiznis/railzway/internal/apikey/domain"
 	auditdomain "github.com/smallbiznis/railzway/internal/audit/domain"
 	auditcontext "github.com/smallbiznis/railzway/internal/auditcontext"
+	authscope "github.com/smallbiznis/railzway/internal/auth/scope"
 	obscontext "github.com/smallbiznis/railzway/internal/observability/context"
 	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/internal/ratelimit"
 )
 
 const (
@@ -21,6 +25,7 @@ const (
 	contextOrgIDKey        = "org_id"
 	contextAPIKeyIDKey     = "api_key_id"
 	contextAPIKeyScopesKey = "api_key_scopes"
+	contextAPIKeyTierKey   = "api_key_rate_limit_tier"
 )
 
 // APIKeyRequired authenticates requests using an API key only.
@@ -52,10 +57,13 @@ func (s *Server) APIKeyRequired() gin.HandlerFunc {
 			OrgID   snowflake.ID   `gorm:"column:org_id"`
 			KeyHash string         `gorm:"column:key_hash"`
 			Scopes  pq.StringArray `gorm:"column:scopes;type:text[]"`
+
+			RateLimitPerMinute *int `gorm:"column:rate_limit_per_minute"`
+			RateLimitUnlimited bool `gorm:"column:rate_limit_unlimited"`
 		}
 
 		if err := s.db.WithContext(c.Request.Context()).Raw(
-			`SELECT id, org_id, key_hash, scopes
+			`SELECT id, org_id, key_hash, scopes, rate_limit_per_minute, rate_limit_unlimited
 			 FROM api_keys
 			 WHERE key_hash = ?
 			   AND is_active = true
@@ -80,6 +88,7 @@ func (s *Server) APIKeyRequired() gin.HandlerFunc {
 		ctx = context.WithValue(ctx, contextOrgIDKey, int64(record.OrgID))
 		ctx = context.WithValue(ctx, contextAPIKeyIDKey, int64(record.ID))
 		ctx = context.WithValue(ctx, contextAPIKeyScopesKey, scopes)
+		ctx = context.WithValue(ctx, contextAPIKeyTierKey, ratelimit.NewTier(record.RateLimitPerMinute, record.RateLimitUnlimited))
 		ctx = orgcontext.WithOrgID(ctx, int64(record.OrgID))
 		ctx = auditcontext.WithActor(ctx, string(auditdomain.ActorTypeAPIKey), record.ID.String())
 		ctx = obscontext.WithActor(ctx, string(auditdomain.ActorTypeAPIKey), record.ID.String())
@@ -90,6 +99,76 @@ func (s *Server) APIKeyRequired() gin.HandlerFunc {
 	}
 }
 
+// RequireAPIKeyScope rejects requests whose API key grants none of scopes.
+// It runs after APIKeyRequired; keys minted without scopes keep full access.
+func (s *Server) RequireAPIKeyScope(scopes ...authscope.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := apikeydomain.APIKey{Scopes: apiKeyScopesFromContext(c.Request.Context())}
+		for _, scope := range scopes {
+			if apikeydomain.HasScope(key, scope) {
+				c.Next()
+				return
+			}
+		}
+		AbortWithError(c, ErrForbidden)
+	}
+}
+
+// apiKeyRouter registers API-key routes on the /api group. Each route names
+// the scopes it accepts and gets APIKeyRequired and RequireAPIKeyScope in
+// front of its handlers, so a route cannot be added without a scope check.
+type apiKeyRouter struct {
+	s     *Server
+	group *gin.RouterGroup
+}
+
+func (s *Server) apiKeyRoutes(group *gin.RouterGroup) apiKeyRouter {
+	if s.apiKeyRouteScopes == nil {
+		s.apiKeyRouteScopes = make(map[string][]authscope.Scope)
+	}
+	return apiKeyRouter{s: s, group: group}
+}
+
+func (r apiKeyRouter) GET(path string, scopes []authscope.Scope, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodGet, path, scopes, handlers)
+}
+
+func (r apiKeyRouter) POST(path string, scopes []authscope.Scope, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodPost, path, scopes, handlers)
+}
+
+func (r apiKeyRouter) PUT(path string, scopes []authscope.Scope, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodPut, path, scopes, handlers)
+}
+
+func (r apiKeyRouter) PATCH(path string, scopes []authscope.Scope, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodPatch, path, scopes, handlers)
+}
+
+func (r apiKeyRouter) DELETE(path string, scopes []authscope.Scope, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodDelete, path, scopes, handlers)
+}
+
+// handle panics when scopes is empty: a missing declaration fails at
+// startup instead of leaving the route open to every key.
+func (r apiKeyRouter) handle(method, path string, scopes []authscope.Scope, handlers []gin.HandlerFunc) {
+	if len(scopes) == 0 {
+		panic(fmt.Sprintf("api route %s %s declares no API key scope", method, path))
+	}
+	chain := make([]gin.HandlerFunc, 0, len(handlers)+2)
+	chain = append(chain, r.s.APIKeyRequired(), r.s.RequireAPIKeyScope(scopes...))
+	chain = append(chain, handlers...)
+	r.group.Handle(method, path, chain...)
+	r.s.apiKeyRouteScopes[method+" "+r.group.BasePath()+path] = scopes
+}
+
+// apiKeyTierFromContext returns the authenticated key's rate-limit tier, or
+// the default tier when the request was not authenticated by APIKeyRequired.
+func apiKeyTierFromContext(ctx context.Context) ratelimit.Tier {
+	tier, _ := ctx.Value(contextAPIKeyTierKey).(ratelimit.Tier)
+	return tier
+}
+
 func requestHasOrgID(c *gin.Context) bool {
 	if strings.TrimSpace(c.GetHeader(HeaderOrg)) != "" {
 		return true
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	authscope "github.com/smallbiznis/railzway/internal/auth/scope"
+	"github.com/smallbiznis/railzway/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireAPIKeyScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(scopes []string) int {
+		s := &Server{engine: gin.New()}
+		s.engine.Use(ErrorHandlingMiddleware())
+		s.engine.POST("/usage",
+			func(c *gin.Context) {
+				ctx := context.WithValue(c.Request.Context(), contextAPIKeyScopesKey, scopes)
+				c.Request = c.Request.WithContext(ctx)
+			},
+			s.RequireAPIKeyScope(authscope.ScopeUsageWrite, authscope.ScopeUsageIngest),
+			func(c *gin.Context) { c.Status(http.StatusNoContent) },
+		)
+		rec := httptest.NewRecorder()
+		s.engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/usage", nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(nil), "unscoped keys keep full access")
+	assert.Equal(t, http.StatusNoContent, serve([]string{}), "unscoped keys keep full access")
+	assert.Equal(t, http.StatusNoContent, serve([]string{"usage:write"}))
+	assert.Equal(t, http.StatusNoContent, serve([]string{"usage:ingest"}))
+	assert.Equal(t, http.StatusNoContent, serve([]string{"usage:*"}))
+	assert.Equal(t, http.StatusForbidden, serve([]string{"invoice:view"}))
+}
+
+func TestAPIRoutesDeclareScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := &Server{engine: gin.New(), cfg: config.Config{Environment: "production"}}
+	s.RegisterAPIRoutes()
+
+	// Providers call these without an API key.
+	unauthenticated := map[string]bool{
+		"POST /api/payments/webhooks/:provider": true,
+		"POST /api/email/webhooks":              true,
+	}
+
+	routes := s.engine.Routes()
+	assert.NotEmpty(t, routes)
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		if unauthenticated[key] {
+			continue
+		}
+		t.Run(key, func(t *testing.T) {
+			scopes := s.apiKeyRouteScopes[key]
+			assert.NotEmpty(t, scopes, "route must be registered through apiKeyRouter")
+
+			// A usage:write key reaches the usage routes and nothing else.
+			usageKey := []string{string(authscope.ScopeUsageWrite)}
+			granted := false
+			for _, scope := range scopes {
+				granted = granted || authscope.Has(usageKey, scope)
+			}
+			assert.Equal(t, strings.HasPrefix(route.Path, "/api/usage"), granted)
+		})
+	}
+}
+
+func TestAPIKeyRouterRejectsUndeclaredScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := &Server{engine: gin.New()}
+	keyed := s.apiKeyRoutes(s.engine.Group("/api"))
+	assert.Panics(t, func() {
+		keyed.GET("/open", nil, func(c *gin.Context) {})
+	})
+}
@@ -67,6 +67,17 @@ func (s *Server) ListAPIKeyScopes(c *gin.Context) {
 	c.JSON(http.StatusOK, scopes)
 }
 
+// GetAPIKeyScopes lists the scopes granted to one key.
+func (s *Server) GetAPIKeyScopes(c *gin.Context) {
+	resp, err := s.apiKeySvc.GetScopes(c.Request.Context(), c.Param("key_id"))
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // RevealAPIKey reveals the API key for a user. It requires the user to confirm their password.
 func (s *Server) RevealAPIKey(c *gin.Context) {
 	userID, ok := s.userIDFromSession(c)
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/gin-gonic/gin"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/internal/scheduler"
+)
+
+type forceCloseBillingCycleRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// POST /billing/cycles/:id/force-close
+//
+// Forces a stuck billing cycle to CLOSED (default) or RATING_FAILED,
+// bypassing the scheduler's lifecycle guards. A reason is required and the
+// override is audited.
+func (s *Server) ForceCloseBillingCycle(c *gin.Context) {
+	if s.scheduler == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	actor, ok := s.actorFromContext(c)
+	if !ok {
+		AbortWithError(c, ErrUnauthorized)
+		return
+	}
+	orgID, ok := orgcontext.OrgIDFromContext(c.Request.Context())
+	if !ok || orgID == 0 {
+		AbortWithError(c, ErrOrgRequired)
+		return
+	}
+
+	cycleID, err := snowflake.ParseString(strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		AbortWithError(c, newValidationError("id", "invalid_id", "invalid id"))
+		return
+	}
+
+	var req forceCloseBillingCycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	result, err := s.scheduler.ForceCloseCycle(c.Request.Context(), scheduler.ForceCloseCycleRequest{
+		OrgID:   orgID,
+		CycleID: cycleID,
+		Actor:   actor.subject(),
+		Status:  billingcycledomain.BillingCycleStatus(strings.ToUpper(strings.TrimSpace(req.Status))),
+		Reason:  req.Reason,
+	})
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/smallbiznis/railzway/internal/auditcontext"
@@ -17,6 +19,10 @@ type billingOperationsActionRequest struct {
 	Metadata       map[string]any `json:"metadata,omitempty"`
 }
 
+type billingOperationsActionsBatchRequest struct {
+	Actions []billingOperationsActionRequest `json:"actions"`
+}
+
 type billingOperationsAssignmentRequest struct {
 	EntityType           string `json:"entity_type"`
 	EntityID             string `json:"entity_id"`
@@ -31,6 +37,24 @@ type billingOperationsReleaseRequest struct {
 	ReleasedBy string `json:"released_by"`
 }
 
+type billingOperationsSnoozeRequest struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	Until      time.Time `json:"until"`
+	Reason     string    `json:"reason"`
+}
+
+type billingOperationsLineDisputeRequest struct {
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+type billingOperationsSLAPauseRequest struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	Until      time.Time `json:"until"`
+}
+
 func (s *Server) GetBillingOperationsOverdueInvoices(c *gin.Context) {
 	if s.billingOperationsSvc == nil {
 		AbortWithError(c, ErrServiceUnavailable)
@@ -43,7 +67,7 @@ func (s *Server) GetBillingOperationsOverdueInvoices(c *gin.Context) {
 		return
 	}
 
-	resp, err := s.billingOperationsSvc.ListOverdueInvoices(c.Request.Context(), limit)
+	resp, err := s.billingOperationsSvc.ListOverdueInvoices(c.Request.Context(), limit, strings.TrimSpace(c.Query("page_token")))
 	if err != nil {
 		AbortWithError(c, err)
 		return
@@ -64,7 +88,50 @@ func (s *Server) GetBillingOperationsOutstandingCustomers(c *gin.Context) {
 		return
 	}
 
-	resp, err := s.billingOperationsSvc.ListOutstandingCustomers(c.Request.Context(), limit)
+	includeDrafts, err := parseOptionalBool(c.Query("include_drafts"))
+	if err != nil {
+		AbortWithError(c, newValidationError("include_drafts", "invalid_include_drafts", "invalid include_drafts"))
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.ListOutstandingCustomers(c.Request.Context(), limit, includeDrafts != nil && *includeDrafts, strings.TrimSpace(c.Query("page_token")))
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) GetBillingOperationsMissingPublicTokens(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	limit, err := parseBillingOperationsLimit(c)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.ListMissingPublicTokens(c.Request.Context(), limit, strings.TrimSpace(c.Query("page_token")))
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// POST /billing-operations/invoices/:id/public-token
+func (s *Server) ReissueBillingOperationsPublicToken(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.ReissuePublicToken(c.Request.Context(), strings.TrimSpace(c.Param("id")))
 	if err != nil {
 		AbortWithError(c, err)
 		return
@@ -142,6 +209,126 @@ func (s *Server) PostBillingOperationsAction(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// POST /billing/operations/actions/batch
+func (s *Server) PostBillingOperationsActionsBatch(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var req billingOperationsActionsBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	actions := make([]billingoperationsdomain.RecordActionRequest, 0, len(req.Actions))
+	for _, action := range req.Actions {
+		actions = append(actions, billingoperationsdomain.RecordActionRequest{
+			ActionType:     strings.TrimSpace(action.ActionType),
+			EntityType:     strings.TrimSpace(action.EntityType),
+			EntityID:       strings.TrimSpace(action.EntityID),
+			IdempotencyKey: strings.TrimSpace(action.IdempotencyKey),
+			Metadata:       action.Metadata,
+		})
+	}
+
+	resp, err := s.billingOperationsSvc.RecordActionsBatch(c.Request.Context(), actions)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+	for i := range resp.Results {
+		if resp.Results[i].Err != nil {
+			_, payload := mapError(resp.Results[i].Err)
+			resp.Results[i].ErrorCode = payload.Code
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// POST /billing-operations/snooze
+func (s *Server) PostBillingOperationsSnooze(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var req billingOperationsSnoozeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	if err := s.billingOperationsSvc.SnoozeEntity(
+		c.Request.Context(),
+		strings.TrimSpace(req.EntityType),
+		strings.TrimSpace(req.EntityID),
+		req.Until,
+		req.Reason,
+	); err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// POST /billing-operations/invoices/:id/lines/:line_id/dispute
+func (s *Server) DisputeBillingOperationsInvoiceLine(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var req billingOperationsLineDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	dispute, err := s.billingOperationsSvc.DisputeInvoiceLine(
+		c.Request.Context(),
+		strings.TrimSpace(c.Param("id")),
+		strings.TrimSpace(c.Param("line_id")),
+		req.Amount,
+		req.Reason,
+	)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// POST /billing-operations/sla-pause
+func (s *Server) PostBillingOperationsSLAPause(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var req billingOperationsSLAPauseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	if err := s.billingOperationsSvc.PauseSLA(
+		c.Request.Context(),
+		strings.TrimSpace(req.EntityType),
+		strings.TrimSpace(req.EntityID),
+		req.Until,
+	); err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (s *Server) PostBillingOperationsAssignment(c *gin.Context) {
 	if s.billingOperationsSvc == nil {
 		AbortWithError(c, ErrServiceUnavailable)
@@ -229,6 +416,50 @@ func (s *Server) ResolveBillingOperationsAssignment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "resolved"})
 }
 
+// POST /admin/billing-operations/reassign
+func (s *Server) ReassignBillingOperationsAssignment(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var req billingoperationsdomain.ReassignAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.ReassignAssignment(c.Request.Context(), req)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// POST /admin/billing-operations/auto-assign
+func (s *Server) AutoAssignBillingOperationsInbox(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var req billingoperationsdomain.AutoAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.AutoAssignInbox(c.Request.Context(), req.Strategy, req.Assignees, req.MaxPerAgent)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 func parseBillingOperationsLimit(c *gin.Context) (int, error) {
 	limitValue, err := parseOptionalInt64(c.Query("limit"))
 	if err != nil {
@@ -295,6 +526,12 @@ func (s *Server) GetBillingOperationsPerformanceTeam(c *gin.Context) {
 		AbortWithError(c, invalidRequestError())
 		return
 	}
+	excluded, err := withExcludedSelf(c, userID, req.ExcludeUserIDs)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+	req.ExcludeUserIDs = excluded
 
 	resp, err := s.billingOperationsSvc.GetTeamPerformance(c.Request.Context(), req)
 	if err != nil {
@@ -305,6 +542,45 @@ func (s *Server) GetBillingOperationsPerformanceTeam(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// GET /finops/performance/team/export
+func (s *Server) ExportBillingOperationsPerformanceTeam(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	_, userID := auditcontext.ActorFromContext(c.Request.Context())
+	if userID == "" {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var req billingoperationsdomain.ExportTeamPerformanceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+	excluded, err := withExcludedSelf(c, userID, req.ExcludeUserIDs)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+	req.ExcludeUserIDs = excluded
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"team_performance_%s.csv\"", req.PeriodType))
+	if err := s.billingOperationsSvc.ExportTeamPerformance(c.Request.Context(), req, c.Writer); err != nil {
+		// Once rows have reached the client the status is already sent, so
+		// the error can only end the download early.
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			AbortWithError(c, err)
+			return
+		}
+		_ = c.Error(err)
+	}
+}
+
 // GET /finops/exposure-analysis
 func (s *Server) GetExposureAnalysis(c *gin.Context) {
 	if s.billingOperationsSvc == nil {
@@ -326,3 +602,82 @@ func (s *Server) GetExposureAnalysis(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+// GET /finops/ar-health
+func (s *Server) GetARHealth(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	from, to, err := parseFinOpsPeriod(c)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.GetARHealth(c.Request.Context(), from, to)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /finops/sla-stats
+func (s *Server) GetSLAStats(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	from, to, err := parseFinOpsPeriod(c)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.GetSLAStats(c.Request.Context(), from, to)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseFinOpsPeriod reads the optional from/to query params. Missing values
+// come back as zero times so the service can apply its defaults.
+func parseFinOpsPeriod(c *gin.Context) (time.Time, time.Time, error) {
+	from, err := parseOptionalTime(c.Query("from"), false)
+	if err != nil {
+		return time.Time{}, time.Time{}, newValidationError("from", "invalid_time", "invalid from time")
+	}
+	to, err := parseOptionalTime(c.Query("to"), true)
+	if err != nil {
+		return time.Time{}, time.Time{}, newValidationError("to", "invalid_time", "invalid to time")
+	}
+
+	var fromValue, toValue time.Time
+	if from != nil {
+		fromValue = *from
+	}
+	if to != nil {
+		toValue = *to
+	}
+	return fromValue, toValue, nil
+}
+
+// withExcludedSelf adds the requesting user to the excluded team members
+// when the request sets exclude_self=true.
+func withExcludedSelf(c *gin.Context, userID string, excluded []string) ([]string, error) {
+	excludeSelf, err := parseOptionalBool(c.Query("exclude_self"))
+	if err != nil {
+		return nil, newValidationError("exclude_self", "invalid_exclude_self", "invalid exclude_self")
+	}
+	if excludeSelf != nil && *excludeSelf {
+		excluded = append(excluded, userID)
+	}
+	return excluded, nil
+}
@@ -1,7 +1,13 @@
 package server
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	auditcontext "github.com/smallbiznis/railzway/internal/auditcontext"
@@ -22,8 +28,19 @@ func (s *Server) GetBillingOperationsInbox(c *gin.Context) {
 		return
 	}
 
+	minAmountDue, err := parseOptionalInt64(c.Query("min_amount_due"))
+	if err != nil {
+		AbortWithError(c, newValidationError("min_amount_due", "invalid_min_amount_due", "invalid min_amount_due"))
+		return
+	}
+
 	req := billingoperationsdomain.InboxRequest{
-		Limit: limit,
+		Limit:        limit,
+		RiskCategory: strings.TrimSpace(c.Query("risk_category")),
+		Currency:     strings.TrimSpace(c.Query("currency")),
+	}
+	if minAmountDue != nil {
+		req.MinAmountDue = *minAmountDue
 	}
 
 	resp, err := s.billingOperationsSvc.GetInbox(c.Request.Context(), req)
@@ -86,8 +103,15 @@ func (s *Server) GetBillingOperationsRecentlyResolved(c *gin.Context) {
 		return
 	}
 
+	detailed, err := parseOptionalBool(c.Query("detailed"))
+	if err != nil {
+		AbortWithError(c, newValidationError("detailed", "invalid_detailed", "invalid detailed"))
+		return
+	}
+
 	req := billingoperationsdomain.RecentlyResolvedRequest{
-		Limit: limit,
+		Limit:    limit,
+		Detailed: detailed != nil && *detailed,
 	}
 
 	resp, err := s.billingOperationsSvc.GetRecentlyResolved(c.Request.Context(), userID, req)
@@ -113,7 +137,17 @@ func (s *Server) GetBillingOperationsTeamView(c *gin.Context) {
 		return
 	}
 
-	req := billingoperationsdomain.TeamViewRequest{}
+	var req billingoperationsdomain.TeamViewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+	excluded, err := withExcludedSelf(c, userID, req.ExcludeUserIDs)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+	req.ExcludeUserIDs = excluded
 
 	resp, err := s.billingOperationsSvc.GetTeamView(c.Request.Context(), req)
 	if err != nil {
@@ -124,6 +158,60 @@ func (s *Server) GetBillingOperationsTeamView(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// GET /admin/billing-operations/assignments
+func (s *Server) ListBillingOperationsAssignments(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var query struct {
+		AssignedTo string `form:"assigned_to"`
+		Status     string `form:"status"`
+		EntityType string `form:"entity_type"`
+		PageToken  string `form:"page_token"`
+		PageSize   int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+	breached, err := parseOptionalBool(c.Query("breached"))
+	if err != nil {
+		AbortWithError(c, newValidationError("breached", "invalid_breached", "invalid breached"))
+		return
+	}
+	from, err := parseOptionalTime(c.Query("from"), false)
+	if err != nil {
+		AbortWithError(c, newValidationError("from", "invalid_time", "invalid from time"))
+		return
+	}
+	to, err := parseOptionalTime(c.Query("to"), true)
+	if err != nil {
+		AbortWithError(c, newValidationError("to", "invalid_time", "invalid to time"))
+		return
+	}
+
+	// Any org_id in the query is ignored; the service scopes to the
+	// request's organization.
+	resp, err := s.billingOperationsSvc.ListAssignments(c.Request.Context(), billingoperationsdomain.AssignmentFilter{
+		AssignedTo:    query.AssignedTo,
+		Status:        query.Status,
+		EntityType:    query.EntityType,
+		Breached:      breached,
+		AssignedFrom:  from,
+		AssignedUntil: to,
+		PageToken:     query.PageToken,
+		PageSize:      query.PageSize,
+	})
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // GET /admin/billing-operations/invoices/:id/payments
 func (s *Server) GetBillingOperationsInvoicePayments(c *gin.Context) {
 	if s.billingOperationsSvc == nil {
@@ -146,3 +234,131 @@ func (s *Server) GetBillingOperationsInvoicePayments(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+// GET /admin/billing-operations/customers/:id/statement
+func (s *Server) GetBillingOperationsCustomerStatement(c *gin.Context) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	customerID := c.Param("id")
+	if customerID == "" {
+		AbortWithError(c, newValidationError("id", "missing_id", "customer id is required"))
+		return
+	}
+
+	from, to, err := parseFinOpsPeriod(c)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.GetCustomerStatement(c.Request.Context(), customerID, from, to)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /admin/billing-operations/invoices/:id/assignment
+func (s *Server) GetBillingOperationsInvoiceAssignment(c *gin.Context) {
+	s.getEntityAssignment(c, billingoperationsdomain.EntityTypeInvoice)
+}
+
+// GET /admin/billing-operations/customers/:id/assignment
+func (s *Server) GetBillingOperationsCustomerAssignment(c *gin.Context) {
+	s.getEntityAssignment(c, billingoperationsdomain.EntityTypeCustomer)
+}
+
+// getEntityAssignment writes the entity's current assignment, with a null
+// assignment when nobody holds it.
+func (s *Server) getEntityAssignment(c *gin.Context, entityType string) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	entityID := c.Param("id")
+	if entityID == "" {
+		AbortWithError(c, newValidationError("id", "missing_id", entityType+" id is required"))
+		return
+	}
+
+	assignment, err := s.billingOperationsSvc.GetAssignmentForEntity(c.Request.Context(), entityType, entityID)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assignment": assignment})
+}
+
+// GET /admin/billing-operations/invoices/:id/audit-trail
+func (s *Server) GetBillingOperationsInvoiceAuditTrail(c *gin.Context) {
+	s.exportEntityAuditTrail(c, billingoperationsdomain.EntityTypeInvoice)
+}
+
+// GET /admin/billing-operations/customers/:id/audit-trail
+func (s *Server) GetBillingOperationsCustomerAuditTrail(c *gin.Context) {
+	s.exportEntityAuditTrail(c, billingoperationsdomain.EntityTypeCustomer)
+}
+
+// exportEntityAuditTrail writes the entity's audit trail as JSON, or as a CSV
+// download with ?format=csv.
+func (s *Server) exportEntityAuditTrail(c *gin.Context, entityType string) {
+	if s.billingOperationsSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	entityID := c.Param("id")
+	if entityID == "" {
+		AbortWithError(c, newValidationError("id", "missing_id", entityType+" id is required"))
+		return
+	}
+
+	resp, err := s.billingOperationsSvc.ExportEntityAuditTrail(c.Request.Context(), entityType, entityID)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s_%s_audit_trail.csv\"", entityType, resp.EntityID))
+		_ = encodeAuditTrailCSV(c.Writer, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// encodeAuditTrailCSV writes one row per event; details are JSON encoded.
+func encodeAuditTrailCSV(w io.Writer, trail billingoperationsdomain.EntityAuditTrail) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"Occurred At", "Source", "ID", "Type", "Actor Type", "Actor ID", "Details"})
+	for _, event := range trail.Events {
+		details := ""
+		if len(event.Details) > 0 {
+			encoded, err := json.Marshal(event.Details)
+			if err != nil {
+				return err
+			}
+			details = string(encoded)
+		}
+		_ = writer.Write([]string{
+			event.OccurredAt.UTC().Format(time.RFC3339Nano),
+			event.Source,
+			event.ID,
+			event.Type,
+			event.ActorType,
+			event.ActorID,
+			details,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
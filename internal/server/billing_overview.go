@@ -3,6 +3,7 @@ package server
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	billingoverviewdomain "github.com/smallbiznis/railzway/internal/billingoverview/domain"
+	"github.com/smallbiznis/railzway/pkg/money"
 )
 
 func (s *Server) GetBillingOverviewMRR(c *gin.Context) {
@@ -231,32 +233,19 @@ func writeCSV(c *gin.Context, filename string, data interface{}) {
 	c.Header("Content-Type", "text/csv")
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
 
-	writer := csv.NewWriter(c.Writer)
-	defer writer.Flush()
+	// Amounts are rendered in major units using the currency's minor-unit
+	// rules; ?locale= picks the decimal separator (defaults to en-US).
+	_ = encodeBillingOverviewCSV(c.Writer, money.NewFormatter(c.Query("locale")), data)
+}
+
+func encodeBillingOverviewCSV(w io.Writer, formatter money.Formatter, data interface{}) error {
+	writer := csv.NewWriter(w)
 
 	switch v := data.(type) {
 	case *billingoverviewdomain.RevenueResponse:
-		_ = writer.Write([]string{"Period", "Revenue", "Previous Revenue"})
-		for i, point := range v.Series {
-			row := []string{point.Period, fmt.Sprintf("%d", point.Value)}
-			if len(v.CompareSeries) > i {
-				row = append(row, fmt.Sprintf("%d", v.CompareSeries[i].Value))
-			} else {
-				row = append(row, "")
-			}
-			_ = writer.Write(row)
-		}
+		writeSeriesCSV(writer, formatter, "Period", amountHeader("Revenue", v.Currency), amountHeader("Previous Revenue", v.Currency), v.Currency, v.Series, v.CompareSeries)
 	case *billingoverviewdomain.MRRResponse:
-		_ = writer.Write([]string{"Period", "MRR", "Previous MRR"})
-		for i, point := range v.Series {
-			row := []string{point.Period, fmt.Sprintf("%d", point.Value)}
-			if len(v.CompareSeries) > i {
-				row = append(row, fmt.Sprintf("%d", v.CompareSeries[i].Value))
-			} else {
-				row = append(row, "")
-			}
-			_ = writer.Write(row)
-		}
+		writeSeriesCSV(writer, formatter, "Period", amountHeader("MRR", v.Currency), amountHeader("Previous MRR", v.Currency), v.Currency, v.Series, v.CompareSeries)
 	case *billingoverviewdomain.SubscribersResponse:
 		_ = writer.Write([]string{"Period", "Subscribers", "Previous Subscribers"})
 		for i, point := range v.Series {
@@ -269,16 +258,16 @@ func writeCSV(c *gin.Context, filename string, data interface{}) {
 			_ = writer.Write(row)
 		}
 	case *billingoverviewdomain.MRRMovementResponse:
-		_ = writer.Write([]string{"Metric", "Value"})
-		_ = writer.Write([]string{"New MRR", fmt.Sprintf("%d", v.NewMRR)})
-		_ = writer.Write([]string{"Expansion MRR", fmt.Sprintf("%d", v.ExpansionMRR)})
-		_ = writer.Write([]string{"Contraction MRR", fmt.Sprintf("%d", v.ContractionMRR)})
-		_ = writer.Write([]string{"Churned MRR", fmt.Sprintf("%d", v.ChurnedMRR)})
-		_ = writer.Write([]string{"Net MRR Change", fmt.Sprintf("%d", v.NetMRRChange)})
+		_ = writer.Write([]string{"Metric", amountHeader("Value", v.Currency)})
+		_ = writer.Write([]string{"New MRR", formatter.FormatDecimal(v.NewMRR, v.Currency)})
+		_ = writer.Write([]string{"Expansion MRR", formatter.FormatDecimal(v.ExpansionMRR, v.Currency)})
+		_ = writer.Write([]string{"Contraction MRR", formatter.FormatDecimal(v.ContractionMRR, v.Currency)})
+		_ = writer.Write([]string{"Churned MRR", formatter.FormatDecimal(v.ChurnedMRR, v.Currency)})
+		_ = writer.Write([]string{"Net MRR Change", formatter.FormatDecimal(v.NetMRRChange, v.Currency)})
 	case *billingoverviewdomain.OutstandingBalanceResponse:
-		_ = writer.Write([]string{"Metric", "Value"})
-		_ = writer.Write([]string{"Outstanding", fmt.Sprintf("%d", v.Outstanding)})
-		_ = writer.Write([]string{"Overdue", fmt.Sprintf("%d", v.Overdue)})
+		_ = writer.Write([]string{"Metric", amountHeader("Value", v.Currency)})
+		_ = writer.Write([]string{"Outstanding", formatter.FormatDecimal(v.Outstanding, v.Currency)})
+		_ = writer.Write([]string{"Overdue", formatter.FormatDecimal(v.Overdue, v.Currency)})
 	case *billingoverviewdomain.CollectionRateResponse:
 		_ = writer.Write([]string{"Metric", "Value"})
 		if v.CollectionRate != nil {
@@ -286,9 +275,39 @@ func writeCSV(c *gin.Context, filename string, data interface{}) {
 		} else {
 			_ = writer.Write([]string{"Collection Rate", "N/A"})
 		}
-		_ = writer.Write([]string{"Collected Amount", fmt.Sprintf("%d", v.CollectedAmount)})
-		_ = writer.Write([]string{"Invoiced Amount", fmt.Sprintf("%d", v.InvoicedAmount)})
+		_ = writer.Write([]string{amountHeader("Collected Amount", v.Currency), formatter.FormatDecimal(v.CollectedAmount, v.Currency)})
+		_ = writer.Write([]string{amountHeader("Invoiced Amount", v.Currency), formatter.FormatDecimal(v.InvoicedAmount, v.Currency)})
 	default:
 		// Fallback for unknown types or just empty CSV
 	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeSeriesCSV(
+	writer *csv.Writer,
+	formatter money.Formatter,
+	periodHeader, valueHeader, compareHeader string,
+	currency string,
+	series, compareSeries []billingoverviewdomain.SeriesPoint,
+) {
+	_ = writer.Write([]string{periodHeader, valueHeader, compareHeader})
+	for i, point := range series {
+		row := []string{point.Period, formatter.FormatDecimal(point.Value, currency)}
+		if len(compareSeries) > i {
+			row = append(row, formatter.FormatDecimal(compareSeries[i].Value, currency))
+		} else {
+			row = append(row, "")
+		}
+		_ = writer.Write(row)
+	}
+}
+
+func amountHeader(label, currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return label
+	}
+	return fmt.Sprintf("%s (%s)", label, currency)
 }
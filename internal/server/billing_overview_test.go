@@ -0,0 +1,44 @@
+package server
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	billingoverviewdomain "github.com/smallbiznis/railzway/internal/billingoverview/domain"
+	"github.com/smallbiznis/railzway/pkg/money"
+)
+
+func TestEncodeBillingOverviewCSVGolden(t *testing.T) {
+	series := []billingoverviewdomain.SeriesPoint{
+		{Period: "2026-01-01", Value: 123456},
+		{Period: "2026-01-02", Value: 7},
+	}
+	compare := []billingoverviewdomain.SeriesPoint{
+		{Period: "2025-12-01", Value: 100005},
+	}
+
+	for _, currency := range []string{"usd", "jpy", "kwd"} {
+		t.Run(currency, func(t *testing.T) {
+			resp := &billingoverviewdomain.RevenueResponse{
+				Currency:      currency,
+				Series:        series,
+				CompareSeries: compare,
+			}
+
+			var buf bytes.Buffer
+			if err := encodeBillingOverviewCSV(&buf, money.NewFormatter("en-US"), resp); err != nil {
+				t.Fatalf("encode csv: %v", err)
+			}
+
+			want, err := os.ReadFile(filepath.Join("testdata", "revenue_"+currency+".csv.golden"))
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Fatalf("csv mismatch\nwant:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -10,8 +11,11 @@ import (
 )
 
 type createCustomerRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name                    string `json:"name"`
+	Email                   string `json:"email"`
+	Phone                   string `json:"phone"`
+	PreferredContactChannel string `json:"preferred_contact_channel"`
+	DoNotContact            bool   `json:"do_not_contact"`
 }
 
 // @Summary      Create Customer
@@ -31,8 +35,11 @@ func (s *Server) CreateCustomer(c *gin.Context) {
 	}
 
 	resp, err := s.customerSvc.Create(c.Request.Context(), customerdomain.CreateCustomerRequest{
-		Name:  strings.TrimSpace(req.Name),
-		Email: strings.TrimSpace(req.Email),
+		Name:                    strings.TrimSpace(req.Name),
+		Email:                   strings.TrimSpace(req.Email),
+		Phone:                   strings.TrimSpace(req.Phone),
+		PreferredContactChannel: strings.TrimSpace(req.PreferredContactChannel),
+		DoNotContact:            req.DoNotContact,
 	})
 	if err != nil {
 		AbortWithError(c, err)
@@ -131,11 +138,122 @@ func (s *Server) GetCustomerByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
+// @Summary      Delete Customer
+// @Description  Soft delete a customer. Customers with an outstanding balance require force=true.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string  true   "Customer ID"
+// @Param        force  query     bool    false  "Delete even with an outstanding balance"
+// @Success      200  {object}  customerdomain.Customer
+// @Router       /customers/{id} [delete]
+func (s *Server) DeleteCustomer(c *gin.Context) {
+	force := false
+	if raw := strings.TrimSpace(c.Query("force")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			AbortWithError(c, newValidationError("force", "invalid_force", "invalid force"))
+			return
+		}
+		force = parsed
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	resp, err := s.customerSvc.SoftDeleteCustomer(c.Request.Context(), id, force)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	if s.auditSvc != nil {
+		targetID := resp.ID.String()
+		_ = s.auditSvc.AuditLog(c.Request.Context(), nil, "", nil, "customer.delete", "customer", &targetID, map[string]any{
+			"customer_id": resp.ID.String(),
+			"force":       force,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// @Summary      Restore Customer
+// @Description  Restore a soft-deleted customer
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Customer ID"
+// @Success      200  {object}  customerdomain.Customer
+// @Router       /customers/{id}/restore [post]
+func (s *Server) RestoreCustomer(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	resp, err := s.customerSvc.RestoreCustomer(c.Request.Context(), id)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	if s.auditSvc != nil {
+		targetID := resp.ID.String()
+		_ = s.auditSvc.AuditLog(c.Request.Context(), nil, "", nil, "customer.restore", "customer", &targetID, map[string]any{
+			"customer_id": resp.ID.String(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+type updateCustomerContactRequest struct {
+	Phone                   *string `json:"phone"`
+	PreferredContactChannel *string `json:"preferred_contact_channel"`
+	DoNotContact            *bool   `json:"do_not_contact"`
+}
+
+// @Summary      Update Customer Contact
+// @Description  Update a customer's phone, preferred contact channel and do-not-contact flag. Omitted fields are unchanged.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                        true  "Customer ID"
+// @Param        request  body      updateCustomerContactRequest  true  "Update Customer Contact Request"
+// @Success      200  {object}  customerdomain.Customer
+// @Router       /customers/{id}/contact [patch]
+func (s *Server) UpdateCustomerContact(c *gin.Context) {
+	var req updateCustomerContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	resp, err := s.customerSvc.UpdateContact(c.Request.Context(), id, customerdomain.UpdateCustomerContactRequest{
+		Phone:                   req.Phone,
+		PreferredContactChannel: req.PreferredContactChannel,
+		DoNotContact:            req.DoNotContact,
+	})
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	if s.auditSvc != nil {
+		targetID := resp.ID.String()
+		_ = s.auditSvc.AuditLog(c.Request.Context(), nil, "", nil, "customer.contact.update", "customer", &targetID, map[string]any{
+			"customer_id":               resp.ID.String(),
+			"preferred_contact_channel": resp.PreferredContactChannel,
+			"do_not_contact":            resp.DoNotContact,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
 func isCustomerValidationError(err error) bool {
 	switch err {
 	case customerdomain.ErrInvalidOrganization,
 		customerdomain.ErrInvalidName,
 		customerdomain.ErrInvalidEmail,
+		customerdomain.ErrInvalidPhone,
+		customerdomain.ErrInvalidContactChannel,
 		customerdomain.ErrInvalidID:
 		return true
 	default:
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// emailWebhookSecretHeader carries the shared secret the email provider is
+// configured to send with its delivery callbacks.
+const emailWebhookSecretHeader = "X-Webhook-Secret"
+
+type emailWebhookRequest struct {
+	MessageID string `json:"message_id"`
+	Status    string `json:"status"`
+}
+
+// HandleEmailWebhook records a delivery status reported by the email
+// provider. Callbacks are refused until EMAIL_WEBHOOK_SECRET is configured.
+func (s *Server) HandleEmailWebhook(c *gin.Context) {
+	if s.emailDeliverySvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	secret := s.cfg.Email.WebhookSecret
+	provided := strings.TrimSpace(c.GetHeader(emailWebhookSecretHeader))
+	if secret == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) != 1 {
+		AbortWithError(c, ErrUnauthorized)
+		return
+	}
+
+	var req emailWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	if err := s.emailDeliverySvc.RecordDeliveryStatus(c.Request.Context(), req.MessageID, req.Status); err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+
+	billingoperationsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	invoicetemplatedomain "github.com/smallbiznis/railzway/internal/invoicetemplate/domain"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+)
+
+// Error codes returned in the "code" field of every error response. They are
+// stable across releases so clients can branch on them instead of on the
+// message. Validation errors raised by a domain use that domain's code, e.g.
+// invalid_entity_type.
+const (
+	ErrorCodeInternal           = "internal_error"
+	ErrorCodeValidation         = "validation_error"
+	ErrorCodeInvalidRequest     = "invalid_request"
+	ErrorCodeUnauthorized       = "unauthorized"
+	ErrorCodeForbidden          = "forbidden"
+	ErrorCodeRateLimited        = "rate_limited"
+	ErrorCodeConflict           = "conflict"
+	ErrorCodeNotFound           = "not_found"
+	ErrorCodeInvoiceUnavailable = "invoice_unavailable"
+	ErrorCodeServiceUnavailable = "service_unavailable"
+	ErrorCodeOrgRequired        = "org_required"
+)
+
+// Billing operations error codes.
+const (
+	ErrorCodeInvalidOrganization   = "invalid_organization"
+	ErrorCodeInvalidEntityType     = "invalid_entity_type"
+	ErrorCodeInvalidEntityID       = "invalid_entity_id"
+	ErrorCodeInvalidActionType     = "invalid_action_type"
+	ErrorCodeInvalidAssignee       = "invalid_assignee"
+	ErrorCodeInvalidIdempotencyKey = "invalid_idempotency_key"
+	ErrorCodeInvalidAssignmentTTL  = "invalid_assignment_ttl"
+	ErrorCodeInvalidPeriod         = "invalid_period"
+	ErrorCodeInvalidExcludeUsers   = "invalid_exclude_user_ids"
+	ErrorCodeInvalidRiskCategory   = "invalid_risk_category"
+	ErrorCodeInvalidMinAmountDue   = "invalid_min_amount_due"
+	ErrorCodeInvalidCurrency       = "invalid_currency"
+	ErrorCodeInvalidSnoozeUntil    = "invalid_snooze_until"
+	ErrorCodeInvalidMetadata       = "invalid_metadata"
+	ErrorCodeMetadataTooLarge      = "metadata_too_large"
+	ErrorCodeInvalidCustomerID     = "invalid_customer_id"
+	ErrorCodeInvalidTop            = "invalid_top"
+	ErrorCodeInvalidStatus         = "invalid_status"
+	ErrorCodeInvalidPageToken      = "invalid_page_token"
+	ErrorCodeAssignmentConflict    = "assignment_conflict"
+	ErrorCodeCustomerNotFound      = "customer_not_found"
+	ErrorCodeEntityNotFound        = "entity_not_found"
+	ErrorCodeTeamViewTimeout       = "team_view_timeout"
+	ErrorCodeQueryTimeout          = "query_timeout"
+	ErrorCodeHandoffNoteRequired   = "handoff_note_required"
+	ErrorCodeAssignmentNotFound    = "assignment_not_found"
+	ErrorCodeInvalidSLAPauseUntil  = "invalid_sla_pause_until"
+	ErrorCodeAssignmentModified    = "assignment_modified"
+	ErrorCodeInvalidBucketEdges    = "invalid_bucket_edges"
+	ErrorCodeInvoiceLineNotFound   = "invoice_line_not_found"
+	ErrorCodeInvalidLineID         = "invalid_line_id"
+	ErrorCodeInvalidDisputeAmount  = "invalid_dispute_amount"
+	ErrorCodeDisputeReasonRequired = "dispute_reason_required"
+	ErrorCodeInvoiceNotDisputable  = "invoice_not_disputable"
+	ErrorCodeFeatureDisabled       = "feature_disabled"
+	ErrorCodeEntityNotPaid         = "entity_not_paid"
+)
+
+// Payment reconciliation error codes.
+const (
+	ErrorCodeInvalidPaymentReference = "invalid_payment_reference"
+	ErrorCodePaymentMatchMismatch    = "payment_match_mismatch"
+	ErrorCodePaymentAlreadyMatched   = "payment_already_matched"
+	ErrorCodeAmbiguousPaymentMatch   = "ambiguous_payment_match"
+	ErrorCodePaymentEventNotFound    = "payment_event_not_found"
+	ErrorCodePaymentMatchNotFound    = "payment_match_not_found"
+	ErrorCodeInvalidPaymentMethod    = "invalid_payment_method"
+	ErrorCodePaymentExceedsDue       = "payment_exceeds_amount_due"
+	ErrorCodeInvoiceNotPayable       = "invoice_not_payable"
+	ErrorCodeInvoiceNotFound         = "invoice_not_found"
+)
+
+// Ledger error codes.
+const (
+	ErrorCodeIdempotencyKeyConflict = "idempotency_key_conflict"
+)
+
+// domainErrorCodes gives conflict and not found errors a code more specific
+// than their type.
+var domainErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{billingoperationsdomain.ErrAssignmentConflict, ErrorCodeAssignmentConflict},
+	{billingoperationsdomain.ErrAssignmentModified, ErrorCodeAssignmentModified},
+	{billingoperationsdomain.ErrCustomerNotFound, ErrorCodeCustomerNotFound},
+	{billingoperationsdomain.ErrEntityNotFound, ErrorCodeEntityNotFound},
+	{billingoperationsdomain.ErrAssignmentNotFound, ErrorCodeAssignmentNotFound},
+	{billingoperationsdomain.ErrInvoiceLineNotFound, ErrorCodeInvoiceLineNotFound},
+	{invoicetemplatedomain.ErrCustomerNotFound, ErrorCodeCustomerNotFound},
+	{ledgerdomain.ErrIdempotencyKeyConflict, ErrorCodeIdempotencyKeyConflict},
+	{paymentdomain.ErrPaymentAlreadyMatched, ErrorCodePaymentAlreadyMatched},
+	{paymentdomain.ErrAmbiguousPaymentMatch, ErrorCodeAmbiguousPaymentMatch},
+	{paymentdomain.ErrPaymentEventNotFound, ErrorCodePaymentEventNotFound},
+	{paymentdomain.ErrPaymentMatchNotFound, ErrorCodePaymentMatchNotFound},
+	{paymentdomain.ErrInvoiceNotPayable, ErrorCodeInvoiceNotPayable},
+	{paymentdomain.ErrInvoiceNotFound, ErrorCodeInvoiceNotFound},
+}
+
+// domainErrorCode returns the code registered for err, or fallback.
+func domainErrorCode(err error, fallback string) string {
+	for _, entry := range domainErrorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return fallback
+}
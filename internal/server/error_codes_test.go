@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	billingoperationsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapError_BillingOperationsCodes(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{billingoperationsdomain.ErrInvalidOrganization, http.StatusUnprocessableEntity, ErrorCodeInvalidOrganization},
+		{billingoperationsdomain.ErrInvalidEntityType, http.StatusUnprocessableEntity, ErrorCodeInvalidEntityType},
+		{billingoperationsdomain.ErrInvalidEntityID, http.StatusUnprocessableEntity, ErrorCodeInvalidEntityID},
+		{billingoperationsdomain.ErrInvalidActionType, http.StatusUnprocessableEntity, ErrorCodeInvalidActionType},
+		{billingoperationsdomain.ErrInvalidAssignee, http.StatusUnprocessableEntity, ErrorCodeInvalidAssignee},
+		{billingoperationsdomain.ErrInvalidIdempotencyKey, http.StatusUnprocessableEntity, ErrorCodeInvalidIdempotencyKey},
+		{billingoperationsdomain.ErrInvalidAssignmentTTL, http.StatusUnprocessableEntity, ErrorCodeInvalidAssignmentTTL},
+		{billingoperationsdomain.ErrInvalidPeriod, http.StatusUnprocessableEntity, ErrorCodeInvalidPeriod},
+		{billingoperationsdomain.ErrInvalidExcludeUsers, http.StatusUnprocessableEntity, ErrorCodeInvalidExcludeUsers},
+		{billingoperationsdomain.ErrInvalidRiskCategory, http.StatusUnprocessableEntity, ErrorCodeInvalidRiskCategory},
+		{billingoperationsdomain.ErrInvalidMinAmountDue, http.StatusUnprocessableEntity, ErrorCodeInvalidMinAmountDue},
+		{billingoperationsdomain.ErrInvalidCurrency, http.StatusUnprocessableEntity, ErrorCodeInvalidCurrency},
+		{billingoperationsdomain.ErrInvalidSnoozeUntil, http.StatusUnprocessableEntity, ErrorCodeInvalidSnoozeUntil},
+		{billingoperationsdomain.ErrInvalidMetadata, http.StatusUnprocessableEntity, ErrorCodeInvalidMetadata},
+		{&billingoperationsdomain.MetadataTooLargeError{}, http.StatusUnprocessableEntity, ErrorCodeMetadataTooLarge},
+		{billingoperationsdomain.ErrInvalidCustomerID, http.StatusUnprocessableEntity, ErrorCodeInvalidCustomerID},
+		{billingoperationsdomain.ErrInvalidTop, http.StatusUnprocessableEntity, ErrorCodeInvalidTop},
+		{billingoperationsdomain.ErrInvalidStatus, http.StatusUnprocessableEntity, ErrorCodeInvalidStatus},
+		{billingoperationsdomain.ErrInvalidPageToken, http.StatusUnprocessableEntity, ErrorCodeInvalidPageToken},
+		{billingoperationsdomain.ErrHandoffNoteRequired, http.StatusUnprocessableEntity, ErrorCodeHandoffNoteRequired},
+		{billingoperationsdomain.ErrInvalidSLAPauseUntil, http.StatusUnprocessableEntity, ErrorCodeInvalidSLAPauseUntil},
+		{billingoperationsdomain.ErrInvalidBucketEdges, http.StatusUnprocessableEntity, ErrorCodeInvalidBucketEdges},
+		{billingoperationsdomain.ErrInvalidLineID, http.StatusUnprocessableEntity, ErrorCodeInvalidLineID},
+		{billingoperationsdomain.ErrInvalidDisputeAmount, http.StatusUnprocessableEntity, ErrorCodeInvalidDisputeAmount},
+		{billingoperationsdomain.ErrDisputeReasonRequired, http.StatusUnprocessableEntity, ErrorCodeDisputeReasonRequired},
+		{billingoperationsdomain.ErrInvoiceNotDisputable, http.StatusUnprocessableEntity, ErrorCodeInvoiceNotDisputable},
+		{billingoperationsdomain.ErrEntityNotPaid, http.StatusUnprocessableEntity, ErrorCodeEntityNotPaid},
+		{billingoperationsdomain.ErrAssignmentConflict, http.StatusConflict, ErrorCodeAssignmentConflict},
+		{&billingoperationsdomain.AssignmentConflictError{}, http.StatusConflict, ErrorCodeAssignmentConflict},
+		{fmt.Errorf("claim: %w", billingoperationsdomain.ErrAssignmentConflict), http.StatusConflict, ErrorCodeAssignmentConflict},
+		{billingoperationsdomain.ErrAssignmentModified, http.StatusConflict, ErrorCodeAssignmentModified},
+		{billingoperationsdomain.ErrCustomerNotFound, http.StatusNotFound, ErrorCodeCustomerNotFound},
+		{billingoperationsdomain.ErrEntityNotFound, http.StatusNotFound, ErrorCodeEntityNotFound},
+		{billingoperationsdomain.ErrAssignmentNotFound, http.StatusNotFound, ErrorCodeAssignmentNotFound},
+		{billingoperationsdomain.ErrInvoiceLineNotFound, http.StatusNotFound, ErrorCodeInvoiceLineNotFound},
+		{billingoperationsdomain.ErrTeamViewTimeout, http.StatusServiceUnavailable, ErrorCodeTeamViewTimeout},
+		{billingoperationsdomain.ErrQueryTimeout, http.StatusServiceUnavailable, ErrorCodeQueryTimeout},
+		{billingoperationsdomain.ErrFeatureDisabled, http.StatusForbidden, ErrorCodeFeatureDisabled},
+	}
+	for _, tc := range cases {
+		t.Run(tc.code, func(t *testing.T) {
+			status, payload := mapError(tc.err)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.code, payload.Code)
+		})
+	}
+}
+
+func TestMapError_PaymentReconciliationCodes(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{paymentdomain.ErrInvalidReference, http.StatusBadRequest, ErrorCodeInvalidPaymentReference},
+		{paymentdomain.ErrPaymentMatchMismatch, http.StatusBadRequest, ErrorCodePaymentMatchMismatch},
+		{paymentdomain.ErrPaymentAlreadyMatched, http.StatusConflict, ErrorCodePaymentAlreadyMatched},
+		{paymentdomain.ErrAmbiguousPaymentMatch, http.StatusConflict, ErrorCodeAmbiguousPaymentMatch},
+		{paymentdomain.ErrPaymentEventNotFound, http.StatusNotFound, ErrorCodePaymentEventNotFound},
+		{paymentdomain.ErrPaymentMatchNotFound, http.StatusNotFound, ErrorCodePaymentMatchNotFound},
+		{paymentdomain.ErrInvalidPaymentMethod, http.StatusBadRequest, ErrorCodeInvalidPaymentMethod},
+		{paymentdomain.ErrPaymentExceedsDue, http.StatusBadRequest, ErrorCodePaymentExceedsDue},
+		{paymentdomain.ErrInvoiceNotPayable, http.StatusConflict, ErrorCodeInvoiceNotPayable},
+		{paymentdomain.ErrInvoiceNotFound, http.StatusNotFound, ErrorCodeInvoiceNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.code, func(t *testing.T) {
+			status, payload := mapError(tc.err)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.code, payload.Code)
+		})
+	}
+}
+
+func TestMapError_LedgerIdempotencyCodes(t *testing.T) {
+	status, payload := mapError(ledgerdomain.ErrIdempotencyKeyConflict)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, ErrorCodeIdempotencyKeyConflict, payload.Code)
+
+	status, payload = mapError(ledgerdomain.ErrInvalidIdempotencyKey)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, ErrorCodeInvalidIdempotencyKey, payload.Code)
+}
+
+func TestMapError_GenericCodes(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{invalidRequestError(), http.StatusBadRequest, ErrorCodeInvalidRequest},
+		{&ValidationErrors{Errors: []ValidationError{{Code: "a"}, {Code: "b"}}}, http.StatusBadRequest, ErrorCodeValidation},
+		{ErrUnauthorized, http.StatusUnauthorized, ErrorCodeUnauthorized},
+		{ErrForbidden, http.StatusForbidden, ErrorCodeForbidden},
+		{ErrConflict, http.StatusConflict, ErrorCodeConflict},
+		{ErrNotFound, http.StatusNotFound, ErrorCodeNotFound},
+		{ErrOrgRequired, http.StatusPreconditionRequired, ErrorCodeOrgRequired},
+		{errors.New("boom"), http.StatusInternalServerError, ErrorCodeInternal},
+	}
+	for _, tc := range cases {
+		status, payload := mapError(tc.err)
+		assert.Equal(t, tc.status, status, tc.err.Error())
+		assert.Equal(t, tc.code, payload.Code, tc.err.Error())
+	}
+}
+
+func TestErrorHandlingMiddleware_WritesCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(ErrorHandlingMiddleware())
+	engine.POST("/claim", func(c *gin.Context) {
+		AbortWithError(c, billingoperationsdomain.ErrInvalidEntityType)
+	})
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/claim", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "validation_error", body.Error.Type)
+	assert.Equal(t, ErrorCodeInvalidEntityType, body.Error.Code)
+	assert.NotEmpty(t, body.Error.Message)
+}
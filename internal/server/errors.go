@@ -11,13 +11,16 @@ import (
 	authdomain "github.com/smallbiznis/railzway/internal/auth/domain"
 	authscope "github.com/smallbiznis/railzway/internal/auth/scope"
 	"github.com/smallbiznis/railzway/internal/authorization"
+	billingcycledomain "github.com/smallbiznis/railzway/internal/billingcycle/domain"
 	billingdashboarddomain "github.com/smallbiznis/railzway/internal/billingdashboard/domain"
 	billingoperationsdomain "github.com/smallbiznis/railzway/internal/billingoperations/domain"
 	billingoverviewdomain "github.com/smallbiznis/railzway/internal/billingoverview/domain"
 	customerdomain "github.com/smallbiznis/railzway/internal/customer/domain"
+	emaildeliverydomain "github.com/smallbiznis/railzway/internal/emaildelivery/domain"
 	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
 	invoicedomain "github.com/smallbiznis/railzway/internal/invoice/domain"
 	invoicetemplatedomain "github.com/smallbiznis/railzway/internal/invoicetemplate/domain"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
 	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
 	organizationdomain "github.com/smallbiznis/railzway/internal/organization/domain"
 	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
@@ -51,8 +54,10 @@ func (v ValidationErrors) Error() string {
 
 type errorPayload struct {
 	Type    string            `json:"type"`
+	Code    string            `json:"code"`
 	Message string            `json:"message"`
 	Errors  []ValidationError `json:"errors,omitempty"`
+	Details any               `json:"details,omitempty"`
 }
 
 type errorResponse struct {
@@ -119,13 +124,19 @@ func mapError(err error) (int, errorPayload) {
 	if err == nil {
 		return http.StatusInternalServerError, errorPayload{
 			Type:    "internal_error",
+			Code:    ErrorCodeInternal,
 			Message: "internal server error",
 		}
 	}
 
 	if vErr := asValidationErrors(err); vErr != nil {
+		code := ErrorCodeValidation
+		if len(vErr.Errors) == 1 && vErr.Errors[0].Code != "" {
+			code = vErr.Errors[0].Code
+		}
 		return http.StatusBadRequest, errorPayload{
 			Type:    "validation_error",
+			Code:    code,
 			Message: "validation error",
 			Errors:  vErr.Errors,
 		}
@@ -133,8 +144,15 @@ func mapError(err error) (int, errorPayload) {
 
 	if isValidationError(err) {
 		code := validationErrorCode(err)
-		return http.StatusBadRequest, errorPayload{
+		// Billing operations and billing cycle errors are semantic checks on
+		// a well-formed request. Other domains keep 400 for existing clients.
+		status := http.StatusBadRequest
+		if isBillingOperationsValidationError(err) || isBillingCycleValidationError(err) {
+			status = http.StatusUnprocessableEntity
+		}
+		return status, errorPayload{
 			Type:    "validation_error",
+			Code:    code,
 			Message: "validation error",
 			Errors: []ValidationError{
 				{
@@ -146,6 +164,16 @@ func mapError(err error) (int, errorPayload) {
 		}
 	}
 
+	var assignmentConflict *billingoperationsdomain.AssignmentConflictError
+	if errors.As(err, &assignmentConflict) {
+		return http.StatusConflict, errorPayload{
+			Type:    "conflict",
+			Code:    ErrorCodeAssignmentConflict,
+			Message: "assignment is held by another operator",
+			Details: assignmentConflict,
+		}
+	}
+
 	switch {
 	case errors.Is(err, ErrUnauthorized),
 		errors.Is(err, authdomain.ErrInvalidCredentials),
@@ -154,63 +182,102 @@ func mapError(err error) (int, errorPayload) {
 		errors.Is(err, authdomain.ErrSessionRevoked):
 		return http.StatusUnauthorized, errorPayload{
 			Type:    "unauthorized",
+			Code:    ErrorCodeUnauthorized,
 			Message: "unauthorized",
 		}
 	case errors.Is(err, ErrForbidden),
 		errors.Is(err, authorization.ErrForbidden):
 		return http.StatusForbidden, errorPayload{
 			Type:    "forbidden",
+			Code:    ErrorCodeForbidden,
 			Message: "forbidden",
 		}
+	case errors.Is(err, billingoperationsdomain.ErrFeatureDisabled):
+		return http.StatusForbidden, errorPayload{
+			Type:    "forbidden",
+			Code:    ErrorCodeFeatureDisabled,
+			Message: "feature not enabled for this organization",
+		}
 	case errors.Is(err, ErrRateLimited):
 		return http.StatusTooManyRequests, errorPayload{
 			Type:    "rate_limited",
+			Code:    ErrorCodeRateLimited,
 			Message: "rate limited",
 		}
 	case errors.Is(err, organizationdomain.ErrForbidden):
 		return http.StatusForbidden, errorPayload{
 			Type:    "forbidden",
+			Code:    ErrorCodeForbidden,
 			Message: "forbidden",
 		}
 	case errors.Is(err, ErrConflict),
-		errors.Is(err, authdomain.ErrUserExists):
+		errors.Is(err, authdomain.ErrUserExists),
+		errors.Is(err, customerdomain.ErrCustomerHasOutstanding),
+		errors.Is(err, invoicedomain.ErrIdempotencyKeyConflict),
+		errors.Is(err, ledgerdomain.ErrIdempotencyKeyConflict),
+		errors.Is(err, billingcycledomain.ErrCycleNotForceClosable),
+		errors.Is(err, billingoperationsdomain.ErrAssignmentConflict),
+		errors.Is(err, billingoperationsdomain.ErrAssignmentModified),
+		errors.Is(err, paymentdomain.ErrPaymentAlreadyMatched),
+		errors.Is(err, paymentdomain.ErrAmbiguousPaymentMatch),
+		errors.Is(err, paymentdomain.ErrInvoiceNotPayable):
 		return http.StatusConflict, errorPayload{
 			Type:    "conflict",
+			Code:    domainErrorCode(err, ErrorCodeConflict),
 			Message: "conflict",
 		}
 	case isNotFoundError(err):
 		return http.StatusNotFound, errorPayload{
 			Type:    "not_found",
+			Code:    domainErrorCode(err, ErrorCodeNotFound),
 			Message: "not found",
 		}
 	case errors.Is(err, ErrInvoiceUnavailable):
 		return http.StatusNotFound, errorPayload{
 			Type:    "not_found",
+			Code:    ErrorCodeInvoiceUnavailable,
 			Message: "invoice not available",
 		}
 	case errors.Is(err, ErrServiceUnavailable):
 		return http.StatusServiceUnavailable, errorPayload{
 			Type:    "service_unavailable",
+			Code:    ErrorCodeServiceUnavailable,
 			Message: "service unavailable",
 		}
+	case errors.Is(err, billingoperationsdomain.ErrTeamViewTimeout):
+		return http.StatusServiceUnavailable, errorPayload{
+			Type:    "service_unavailable",
+			Code:    ErrorCodeTeamViewTimeout,
+			Message: "team view timed out",
+		}
+	case errors.Is(err, billingoperationsdomain.ErrQueryTimeout):
+		return http.StatusServiceUnavailable, errorPayload{
+			Type:    "service_unavailable",
+			Code:    ErrorCodeQueryTimeout,
+			Message: "query timed out",
+		}
 	case errors.Is(err, paymentproviderdomain.ErrEncryptionKeyMissing):
 		return http.StatusServiceUnavailable, errorPayload{
 			Type:    "service_unavailable",
+			Code:    ErrorCodeServiceUnavailable,
 			Message: "service unavailable",
 		}
 	case errors.Is(err, ErrOrgRequired):
 		return http.StatusPreconditionRequired, errorPayload{
 			Type:    "precondition_required",
+			Code:    ErrorCodeOrgRequired,
 			Message: "organization required",
 		}
 	case errors.Is(err, ErrInternal):
 		return http.StatusInternalServerError, errorPayload{
 			Type:    "internal_error",
+			Code:    ErrorCodeInternal,
 			Message: "internal server error",
 		}
 	default:
 		return http.StatusInternalServerError, errorPayload{
 			Type:    "internal_error",
+			Code:    ErrorCodeInternal,
 			Message: "internal server error",
 		}
 	}
@@ -243,14 +310,17 @@ func isValidationError(err error) bool {
 		return true
 	case isOrganizationValidationError(err),
 		isCustomerValidationError(err),
+		isBillingCycleValidationError(err),
 		isBillingDashboardValidationError(err),
 		isBillingOperationsValidationError(err),
 		isBillingOverviewValidationError(err),
+		isEmailDeliveryValidationError(err),
 		isInvoiceValidationError(err),
 		isInvoiceTemplateValidationError(err),
 		isRatingValidationError(err),
 		isUsageValidationError(err),
 		isPaymentValidationError(err),
+		isLedgerValidationError(err),
 		isProductValidationError(err),
 		isFeatureValidationError(err),
 		isPriceValidationError(err),
@@ -282,7 +352,11 @@ func isUsageValidationError(err error) bool {
 		usagedomain.ErrInvalidMeterCode,
 		usagedomain.ErrInvalidValue,
 		usagedomain.ErrInvalidRecordedAt,
-		usagedomain.ErrInvalidIdempotencyKey:
+		usagedomain.ErrInvalidIdempotencyKey,
+		usagedomain.ErrInvalidBatchSize,
+		usagedomain.ErrInvalidGranularity,
+		usagedomain.ErrInvalidPeriod,
+		usagedomain.ErrInvalidPageToken:
 		return true
 	default:
 		return false
@@ -296,7 +370,8 @@ func isFeatureValidationError(err error) bool {
 		featuredomain.ErrInvalidName,
 		featuredomain.ErrInvalidType,
 		featuredomain.ErrInvalidMeterID,
-		featuredomain.ErrInvalidID:
+		featuredomain.ErrInvalidID,
+		featuredomain.ErrInvalidFlag:
 		return true
 	default:
 		return false
@@ -316,6 +391,16 @@ func isProductFeatureValidationError(err error) bool {
 	}
 }
 
+func isBillingCycleValidationError(err error) bool {
+	switch err {
+	case billingcycledomain.ErrInvalidForceCloseReason,
+		billingcycledomain.ErrInvalidForceCloseStatus:
+		return true
+	default:
+		return false
+	}
+}
+
 func isBillingDashboardValidationError(err error) bool {
 	switch err {
 	case billingdashboarddomain.ErrInvalidOrganization:
@@ -333,10 +418,35 @@ func isBillingOperationsValidationError(err error) bool {
 		billingoperationsdomain.ErrInvalidActionType,
 		billingoperationsdomain.ErrInvalidAssignee,
 		billingoperationsdomain.ErrInvalidIdempotencyKey,
-		billingoperationsdomain.ErrInvalidAssignmentTTL:
+		billingoperationsdomain.ErrInvalidAssignmentTTL,
+		billingoperationsdomain.ErrInvalidPeriod,
+		billingoperationsdomain.ErrInvalidExcludeUsers,
+		billingoperationsdomain.ErrInvalidRiskCategory,
+		billingoperationsdomain.ErrInvalidMinAmountDue,
+		billingoperationsdomain.ErrInvalidCurrency,
+		billingoperationsdomain.ErrInvalidTimezone,
+		billingoperationsdomain.ErrInvalidSnoozeUntil,
+		billingoperationsdomain.ErrInvalidMetadata,
+		billingoperationsdomain.ErrInvalidCustomerID,
+		billingoperationsdomain.ErrInvalidTop,
+		billingoperationsdomain.ErrInvalidStatus,
+		billingoperationsdomain.ErrInvalidPageToken,
+		billingoperationsdomain.ErrHandoffNoteRequired,
+		billingoperationsdomain.ErrInvalidSLAPauseUntil,
+		billingoperationsdomain.ErrInvalidBucketEdges,
+		billingoperationsdomain.ErrInvalidStrategy,
+		billingoperationsdomain.ErrInvalidMaxPerAgent,
+		billingoperationsdomain.ErrInvalidLineID,
+		billingoperationsdomain.ErrInvalidDisputeAmount,
+		billingoperationsdomain.ErrDisputeReasonRequired,
+		billingoperationsdomain.ErrInvoiceNotDisputable,
+		billingoperationsdomain.ErrEntityNotPaid,
+		billingoperationsdomain.ErrInvalidBatchSize,
+		billingoperationsdomain.ErrInvalidOrderBy,
+		billingoperationsdomain.ErrInvalidOrderDirection:
 		return true
 	default:
-		return false
+		return errors.Is(err, billingoperationsdomain.ErrMetadataTooLarge)
 	}
 }
 
@@ -367,7 +477,13 @@ func isNotFoundError(err error) bool {
 	switch {
 	case errors.Is(err, ErrNotFound),
 		errors.Is(err, customerdomain.ErrNotFound),
+		errors.Is(err, billingoperationsdomain.ErrCustomerNotFound),
+		errors.Is(err, billingoperationsdomain.ErrEntityNotFound),
+		errors.Is(err, billingoperationsdomain.ErrAssignmentNotFound),
+		errors.Is(err, billingoperationsdomain.ErrInvoiceLineNotFound),
+		errors.Is(err, emaildeliverydomain.ErrNotFound),
 		errors.Is(err, invoicetemplatedomain.ErrNotFound),
+		errors.Is(err, invoicetemplatedomain.ErrCustomerNotFound),
 		errors.Is(err, invoicedomain.ErrInvoiceTemplateNotFound),
 		errors.Is(err, productdomain.ErrNotFound),
 		errors.Is(err, productfeaturedomain.ErrProductNotFound),
@@ -380,11 +496,15 @@ func isNotFoundError(err error) bool {
 		errors.Is(err, priceamountdomain.ErrNotFound),
 		errors.Is(err, pricetierdomain.ErrNotFound),
 		errors.Is(err, invoicedomain.ErrBillingCycleNotFound),
+		errors.Is(err, billingcycledomain.ErrBillingCycleNotFound),
 		errors.Is(err, invoicedomain.ErrInvoiceNotFound),
 		errors.Is(err, ratingdomain.ErrBillingCycleNotFound),
 		errors.Is(err, subscriptiondomain.ErrSubscriptionNotFound),
 		errors.Is(err, subscriptiondomain.ErrSubscriptionItemNotFound),
 		errors.Is(err, paymentdomain.ErrProviderNotFound),
+		errors.Is(err, paymentdomain.ErrPaymentEventNotFound),
+		errors.Is(err, paymentdomain.ErrPaymentMatchNotFound),
+		errors.Is(err, paymentdomain.ErrInvoiceNotFound),
 		errors.Is(err, paymentproviderdomain.ErrNotFound),
 		errors.Is(err, taxdomain.ErrNotFound),
 		errors.Is(err, gorm.ErrRecordNotFound):
@@ -394,6 +514,17 @@ func isNotFoundError(err error) bool {
 	}
 }
 
+func isEmailDeliveryValidationError(err error) bool {
+	switch err {
+	case emaildeliverydomain.ErrInvalidMessageID,
+		emaildeliverydomain.ErrInvalidStatus,
+		emaildeliverydomain.ErrInvalidType:
+		return true
+	default:
+		return false
+	}
+}
+
 func isInvoiceValidationError(err error) bool {
 	switch err {
 	case invoicedomain.ErrInvalidOrganization,
@@ -404,7 +535,9 @@ func isInvoiceValidationError(err error) bool {
 		invoicedomain.ErrCurrencyMismatch,
 		invoicedomain.ErrInvalidInvoiceID,
 		invoicedomain.ErrInvoiceNotDraft,
-		invoicedomain.ErrInvoiceNotFinalized:
+		invoicedomain.ErrInvoiceNotFinalized,
+		invoicedomain.ErrInvalidIdempotencyKey,
+		invoicedomain.ErrInvalidTemplateID:
 		return true
 	default:
 		return false
@@ -417,7 +550,9 @@ func isInvoiceTemplateValidationError(err error) bool {
 		invoicetemplatedomain.ErrInvalidID,
 		invoicetemplatedomain.ErrInvalidName,
 		invoicetemplatedomain.ErrInvalidCurrency,
-		invoicetemplatedomain.ErrInvalidLocale:
+		invoicetemplatedomain.ErrInvalidLocale,
+		invoicetemplatedomain.ErrInvalidLayout,
+		invoicetemplatedomain.ErrInvalidCustomerID:
 		return true
 	default:
 		return false
@@ -478,7 +613,25 @@ func isPaymentValidationError(err error) bool {
 		paymentdomain.ErrInvalidEvent,
 		paymentdomain.ErrInvalidCustomer,
 		paymentdomain.ErrInvalidAmount,
-		paymentdomain.ErrInvalidCurrency:
+		paymentdomain.ErrInvalidCurrency,
+		paymentdomain.ErrInvalidOrganization,
+		paymentdomain.ErrInvalidReference,
+		paymentdomain.ErrPaymentMatchMismatch,
+		paymentdomain.ErrInvalidPaymentMethod,
+		paymentdomain.ErrPaymentExceedsDue:
+		return true
+	default:
+		return false
+	}
+}
+
+func isLedgerValidationError(err error) bool {
+	switch err {
+	case ledgerdomain.ErrInvalidIdempotencyKey,
+		ledgerdomain.ErrInvalidOrganization,
+		ledgerdomain.ErrInvalidSourceID,
+		ledgerdomain.ErrInvalidPeriod,
+		ledgerdomain.ErrInvalidPageToken:
 		return true
 	default:
 		return false
@@ -513,6 +666,8 @@ func validationErrorCode(err error) string {
 	case errors.Is(err, ErrInvalidRequest),
 		errors.Is(err, signupdomain.ErrInvalidRequest):
 		return "invalid_request"
+	case errors.Is(err, billingoperationsdomain.ErrMetadataTooLarge):
+		return billingoperationsdomain.ErrMetadataTooLarge.Error()
 	default:
 		return err.Error()
 	}
@@ -522,6 +677,15 @@ func validationErrorField(code string) string {
 	if code == "invalid_scope" {
 		return "scopes"
 	}
+	if code == "metadata_too_large" {
+		return "metadata"
+	}
+	if code == "invalid_default_limit" {
+		return "default_limits"
+	}
+	if code == "invalid_holiday" {
+		return "overdue_calendar"
+	}
 	if strings.HasPrefix(code, "invalid_") {
 		return strings.TrimPrefix(code, "invalid_")
 	}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
+)
+
+type setFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+func (s *Server) ListFeatureFlags(c *gin.Context) {
+	if s.featureFlagSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	resp, err := s.featureFlagSvc.ListFlags(c.Request.Context())
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+func (s *Server) SetFeatureFlag(c *gin.Context) {
+	if s.featureFlagSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+	userID, ok := s.userIDFromSession(c)
+	if !ok {
+		AbortWithError(c, ErrUnauthorized)
+		return
+	}
+
+	var req setFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	resp, err := s.featureFlagSvc.SetFlag(c.Request.Context(), featuredomain.SetFlagRequest{
+		Flag:      strings.TrimSpace(c.Param("flag")),
+		Enabled:   *req.Enabled,
+		UpdatedBy: userID.String(),
+	})
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	if s.auditSvc != nil {
+		targetID := resp.Flag
+		_ = s.auditSvc.AuditLog(c.Request.Context(), nil, "", nil, "feature_flag.update", "feature_flag", &targetID, map[string]any{
+			"flag":    resp.Flag,
+			"enabled": resp.Enabled,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
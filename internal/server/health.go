@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smallbiznis/railzway/internal/observability/logger"
+	"go.uber.org/zap"
+)
+
+const readinessCheckTimeout = 2 * time.Second
+
+const (
+	readinessOK          = "ok"
+	readinessUnavailable = "unavailable"
+	readinessFailed      = "failed"
+	readinessSkipped     = "skipped"
+)
+
+// RegisterHealthRoutes registers the readiness probe. /health stays a cheap
+// liveness probe on the engine itself.
+func (s *Server) RegisterHealthRoutes() {
+	s.engine.GET("/readyz", s.Readyz)
+}
+
+// Readyz reports whether the service can serve traffic: the database must
+// answer a ping and at least one migration must be applied cleanly. The
+// probe is unauthenticated, so a failed check only reports "failed" and the
+// cause goes to the log.
+func (s *Server) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	checks := map[string]string{
+		"database":   readinessOK,
+		"migrations": readinessOK,
+	}
+	ready := true
+
+	if err := s.pingDatabase(ctx); err != nil {
+		logger.FromContext(ctx).Warn("readiness check failed", zap.String("check", "database"), zap.Error(err))
+		checks["database"] = readinessFailed
+		checks["migrations"] = readinessSkipped
+		ready = false
+	} else if err := s.checkMigrations(ctx); err != nil {
+		logger.FromContext(ctx).Warn("readiness check failed", zap.String("check", "migrations"), zap.Error(err))
+		checks["migrations"] = readinessFailed
+		ready = false
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": readinessUnavailable, "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": readinessOK, "checks": checks})
+}
+
+func (s *Server) pingDatabase(ctx context.Context) error {
+	if s.db == nil {
+		return errors.New("database not configured")
+	}
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (s *Server) checkMigrations(ctx context.Context) error {
+	var rows []struct {
+		Version int64
+		Dirty   bool
+	}
+	if err := s.db.WithContext(ctx).
+		Raw(`SELECT version, dirty FROM schema_migrations LIMIT 1`).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return errors.New("no migrations applied")
+	}
+	if rows[0].Dirty {
+		return errors.New("migration is dirty")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type readyzResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func serveReadyz(t *testing.T, db *gorm.DB) (int, readyzResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	s := &Server{engine: gin.New(), db: db}
+	s.RegisterHealthRoutes()
+
+	rec := httptest.NewRecorder()
+	s.engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var body readyzResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+func TestReadyz(t *testing.T) {
+	t.Run("ready when migrations are applied", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(`CREATE TABLE schema_migrations (version BIGINT PRIMARY KEY, dirty BOOLEAN NOT NULL)`).Error)
+		require.NoError(t, db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (44, false)`).Error)
+
+		code, body := serveReadyz(t, db)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", body.Status)
+		assert.Equal(t, "ok", body.Checks["database"])
+		assert.Equal(t, "ok", body.Checks["migrations"])
+	})
+
+	t.Run("unavailable without migrations", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(`CREATE TABLE schema_migrations (version BIGINT PRIMARY KEY, dirty BOOLEAN NOT NULL)`).Error)
+
+		code, body := serveReadyz(t, db)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unavailable", body.Status)
+		assert.Equal(t, "ok", body.Checks["database"])
+		assert.Equal(t, "failed", body.Checks["migrations"])
+	})
+
+	t.Run("unavailable when the database is down", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+		require.NoError(t, err)
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+
+		code, body := serveReadyz(t, db)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "failed", body.Checks["database"])
+		assert.Equal(t, "skipped", body.Checks["migrations"])
+	})
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -189,6 +190,54 @@ func (s *Server) RenderInvoice(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
+// @Summary      Download Invoice PDF
+// @Description  Render the invoice as a PDF, optionally with a given template for preview
+// @Tags         invoices
+// @Produce      application/pdf
+// @Security     ApiKeyAuth
+// @Param        id           path      string  true   "Invoice ID"
+// @Param        template_id  query     string  false  "Invoice template ID"
+// @Success      200
+// @Router       /invoices/{id}/pdf [get]
+func (s *Server) GetInvoicePDF(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if _, err := snowflake.ParseString(id); err != nil {
+		AbortWithError(c, newValidationError("id", "invalid_id", "invalid id"))
+		return
+	}
+
+	var templateID *string
+	if raw, ok := c.GetQuery("template_id"); ok {
+		templateID = &raw
+	}
+
+	doc, err := s.invoiceSvc.GenerateInvoicePDF(c.Request.Context(), id, templateID)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"invoice-%s.pdf\"", id))
+	c.Data(http.StatusOK, "application/pdf", doc)
+}
+
+// GET /billing/cycles/:id/invoice-preview
+func (s *Server) PreviewInvoice(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	if _, err := snowflake.ParseString(id); err != nil {
+		AbortWithError(c, newValidationError("id", "invalid_id", "invalid id"))
+		return
+	}
+
+	resp, err := s.invoiceSvc.PreviewInvoice(c.Request.Context(), id)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
 func parseInvoiceStatus(value string) (*invoicedomain.InvoiceStatus, error) {
 	status := strings.TrimSpace(value)
 	if status == "" {
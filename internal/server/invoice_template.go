@@ -82,3 +82,26 @@ func (s *Server) SetDefaultInvoiceTemplate(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
+
+type customerInvoiceTemplateRequest struct {
+	TemplateID *string `json:"template_id"`
+}
+
+// SetCustomerInvoiceTemplate sets or, with a null template_id, clears the
+// template the customer's invoices render with.
+func (s *Server) SetCustomerInvoiceTemplate(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+
+	var req customerInvoiceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	if err := s.invoiceTemplateSvc.SetCustomerTemplate(c.Request.Context(), id, req.TemplateID); err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
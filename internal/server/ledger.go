@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
+)
+
+// @Summary      List Ledger Entries
+// @Description  Page through the organization's ledger entries, newest first. Each entry carries all of its lines with account codes, amounts and directions, so callers can check it balances.
+// @Tags         ledger
+// @Produce      json
+// @Param        source_type   query     string  false  "Source Type"
+// @Param        source_id     query     string  false  "Source ID"
+// @Param        account_code  query     string  false  "Entries with a line on this account"
+// @Param        currency      query     string  false  "Currency"
+// @Param        from          query     string  false  "Occurred from (inclusive)"
+// @Param        to            query     string  false  "Occurred to (exclusive)"
+// @Param        page_token    query     string  false  "Page Token"
+// @Param        page_size     query     int     false  "Page Size"
+// @Success      200  {object}  ledgerdomain.ListEntriesResponse
+// @Router       /ledger/entries [get]
+func (s *Server) ListLedgerEntries(c *gin.Context) {
+	if s.ledgerSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var query struct {
+		SourceType  string `form:"source_type"`
+		SourceID    string `form:"source_id"`
+		AccountCode string `form:"account_code"`
+		Currency    string `form:"currency"`
+		From        string `form:"from"`
+		To          string `form:"to"`
+		PageToken   string `form:"page_token"`
+		PageSize    int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	from, err := parseOptionalTime(query.From, false)
+	if err != nil {
+		AbortWithError(c, newValidationError("from", "invalid_from", "invalid from"))
+		return
+	}
+	to, err := parseOptionalTime(query.To, true)
+	if err != nil {
+		AbortWithError(c, newValidationError("to", "invalid_to", "invalid to"))
+		return
+	}
+
+	resp, err := s.ledgerSvc.ListLedgerEntries(c.Request.Context(), ledgerdomain.ListEntriesFilter{
+		SourceType:  strings.TrimSpace(query.SourceType),
+		SourceID:    strings.TrimSpace(query.SourceID),
+		AccountCode: strings.TrimSpace(query.AccountCode),
+		Currency:    strings.TrimSpace(query.Currency),
+		From:        from,
+		To:          to,
+		PageToken:   query.PageToken,
+		PageSize:    int32(query.PageSize),
+	})
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -116,7 +116,15 @@ func isOrganizationValidationError(err error) bool {
 		organizationdomain.ErrInvalidCurrency,
 		organizationdomain.ErrInvalidUser,
 		organizationdomain.ErrInvalidEmail,
-		organizationdomain.ErrInvalidRole:
+		organizationdomain.ErrInvalidRole,
+		organizationdomain.ErrInvalidDefaultLimit,
+		organizationdomain.ErrInvalidHoliday,
+		organizationdomain.ErrInvalidWorkDays,
+		organizationdomain.ErrInvalidWorkHours,
+		organizationdomain.ErrInvalidInvoiceNumberFormat,
+		organizationdomain.ErrInvalidReceivableAccountCode,
+		organizationdomain.ErrInvalidMinInvoiceAmount,
+		organizationdomain.ErrInvalidCollectionQueue:
 		return true
 	default:
 		return false
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	organizationdomain "github.com/smallbiznis/railzway/internal/organization/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+)
+
+// GetOrganizationCalendar returns the working calendar of the current
+// organization, or the default calendar when none is configured.
+func (s *Server) GetOrganizationCalendar(c *gin.Context) {
+	orgID, ok := orgcontext.OrgIDFromContext(c.Request.Context())
+	if !ok || orgID == 0 {
+		AbortWithError(c, ErrOrgRequired)
+		return
+	}
+
+	calendar, err := s.organizationSvc.GetCalendar(c.Request.Context(), orgID.String())
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// SetOrganizationCalendar replaces the working calendar of the current
+// organization.
+func (s *Server) SetOrganizationCalendar(c *gin.Context) {
+	userID, ok := s.userIDFromSession(c)
+	if !ok {
+		AbortWithError(c, ErrUnauthorized)
+		return
+	}
+	orgID, ok := orgcontext.OrgIDFromContext(c.Request.Context())
+	if !ok || orgID == 0 {
+		AbortWithError(c, ErrOrgRequired)
+		return
+	}
+
+	var req organizationdomain.OrgCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	calendar, err := s.organizationSvc.SetCalendar(c.Request.Context(), userID, orgID.String(), req)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// DeleteOrganizationCalendar restores the default calendar for the current
+// organization.
+func (s *Server) DeleteOrganizationCalendar(c *gin.Context) {
+	userID, ok := s.userIDFromSession(c)
+	if !ok {
+		AbortWithError(c, ErrUnauthorized)
+		return
+	}
+	orgID, ok := orgcontext.OrgIDFromContext(c.Request.Context())
+	if !ok || orgID == 0 {
+		AbortWithError(c, ErrOrgRequired)
+		return
+	}
+
+	if err := s.organizationSvc.DeleteCalendar(c.Request.Context(), userID, orgID.String()); err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -19,8 +19,19 @@ type inviteMemberRequest struct {
 }
 
 type billingPreferencesRequest struct {
-	Currency string `json:"currency"`
-	Timezone string `json:"timezone"`
+	Currency                     string                                      `json:"currency"`
+	Timezone                     string                                      `json:"timezone"`
+	DefaultLimits                *organizationdomain.ListDefaultLimits       `json:"default_limits"`
+	OverdueCalendar              *organizationdomain.OverdueCalendar         `json:"overdue_calendar"`
+	InvoiceRemindersOptOut       *bool                                       `json:"invoice_reminders_opt_out"`
+	RequireHandoffNote           *bool                                       `json:"require_handoff_note"`
+	ReleaseOnResolve             *bool                                       `json:"release_on_resolve"`
+	CollectionQueue              *organizationdomain.CollectionQueueSettings `json:"collection_queue"`
+	TeamViewsIncludeSystemActors *bool                                       `json:"team_views_include_system_actors"`
+	AuditReads                   *bool                                       `json:"audit_reads"`
+	InvoiceNumberFormat          *organizationdomain.InvoiceNumberFormat     `json:"invoice_number_format"`
+	ReceivableAccountCodes       *[]string                                   `json:"receivable_account_codes"`
+	MinInvoiceAmount             *int64                                      `json:"min_invoice_amount"`
 }
 
 func (s *Server) InviteOrganizationMembers(c *gin.Context) {
@@ -83,8 +94,19 @@ func (s *Server) SetOrganizationBillingPreferences(c *gin.Context) {
 	}
 
 	if err := s.organizationSvc.SetBillingPreferences(c.Request.Context(), userID, orgID, organizationdomain.BillingPreferencesRequest{
-		Currency: req.Currency,
-		Timezone: req.Timezone,
+		Currency:                     req.Currency,
+		Timezone:                     req.Timezone,
+		ListDefaults:                 req.DefaultLimits,
+		OverdueCalendar:              req.OverdueCalendar,
+		InvoiceRemindersOptOut:       req.InvoiceRemindersOptOut,
+		RequireHandoffNote:           req.RequireHandoffNote,
+		ReleaseOnResolve:             req.ReleaseOnResolve,
+		CollectionQueue:              req.CollectionQueue,
+		TeamViewsIncludeSystemActors: req.TeamViewsIncludeSystemActors,
+		AuditReads:                   req.AuditReads,
+		InvoiceNumberFormat:          req.InvoiceNumberFormat,
+		ReceivableAccountCodes:       req.ReceivableAccountCodes,
+		MinInvoiceAmount:             req.MinInvoiceAmount,
 	}); err != nil {
 		AbortWithError(c, err)
 		return
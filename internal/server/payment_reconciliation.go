@@ -0,0 +1,108 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smallbiznis/railzway/internal/auditcontext"
+	paymentdomain "github.com/smallbiznis/railzway/internal/payment/domain"
+)
+
+type recordManualPaymentRequest struct {
+	Amount         int64  `json:"amount"`
+	Method         string `json:"method"`
+	Reference      string `json:"reference"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+type confirmPaymentMatchRequest struct {
+	InvoiceID      string `json:"invoice_id"`
+	Reference      string `json:"reference"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// GET /admin/billing-operations/payments/match?reference=...&amount=...
+func (s *Server) MatchPaymentByReference(c *gin.Context) {
+	if s.paymentReconciliationSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	amount, err := parseOptionalInt64(c.Query("amount"))
+	if err != nil || amount == nil {
+		AbortWithError(c, newValidationError("amount", "invalid_amount", "amount is required"))
+		return
+	}
+
+	candidates, err := s.paymentReconciliationSvc.MatchPaymentByReference(c.Request.Context(), c.Query("reference"), *amount)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"candidates": candidates,
+		"ambiguous":  len(candidates) > 1,
+	})
+}
+
+// POST /admin/billing-operations/payments/:id/match
+func (s *Server) ConfirmPaymentMatch(c *gin.Context) {
+	if s.paymentReconciliationSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var req confirmPaymentMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	_, userID := auditcontext.ActorFromContext(c.Request.Context())
+	allocation, err := s.paymentReconciliationSvc.ConfirmPaymentMatch(c.Request.Context(), paymentdomain.ConfirmPaymentMatchRequest{
+		PaymentEventID: strings.TrimSpace(c.Param("id")),
+		InvoiceID:      strings.TrimSpace(req.InvoiceID),
+		Reference:      strings.TrimSpace(req.Reference),
+		AllocatedBy:    userID,
+		IdempotencyKey: strings.TrimSpace(req.IdempotencyKey),
+	})
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, allocation)
+}
+
+// POST /admin/billing-operations/invoices/:id/payments
+// POST /api/invoices/:id/payments
+func (s *Server) RecordManualPayment(c *gin.Context) {
+	if s.paymentReconciliationSvc == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+
+	var req recordManualPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	_, userID := auditcontext.ActorFromContext(c.Request.Context())
+	payment, err := s.paymentReconciliationSvc.RecordManualPayment(c.Request.Context(), paymentdomain.RecordManualPaymentRequest{
+		InvoiceID:      strings.TrimSpace(c.Param("id")),
+		Amount:         req.Amount,
+		Method:         strings.TrimSpace(req.Method),
+		Reference:      strings.TrimSpace(req.Reference),
+		RecordedBy:     userID,
+		IdempotencyKey: strings.TrimSpace(req.IdempotencyKey),
+	})
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
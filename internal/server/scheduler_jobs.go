@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /scheduler/job-runs
+//
+// Returns recent scheduler job runs (newest first) together with per-job
+// stats over the same window. Use ?job= to narrow to one job and ?limit= to
+// size the window.
+//
+// Job runs are platform-wide and cover every org, so the history is only
+// served to the operator of a self-hosted install, never to cloud tenants.
+func (s *Server) ListSchedulerJobRuns(c *gin.Context) {
+	if s.scheduler == nil {
+		AbortWithError(c, ErrServiceUnavailable)
+		return
+	}
+	if s.cfg.IsCloud() {
+		AbortWithError(c, ErrForbidden)
+		return
+	}
+
+	limit, err := parseBillingOperationsLimit(c)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+	job := strings.TrimSpace(c.Query("job"))
+
+	runs, err := s.scheduler.ListJobRuns(c.Request.Context(), job, limit)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+	stats, err := s.scheduler.GetJobRunStats(c.Request.Context(), job, limit)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"runs":  runs,
+			"stats": stats,
+		},
+	})
+}
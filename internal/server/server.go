@@ -19,6 +19,7 @@ import (
 	authlocal "github.com/smallbiznis/railzway/internal/auth/local"
 	authoauth "github.com/smallbiznis/railzway/internal/auth/oauth"
 	authoauth2provider "github.com/smallbiznis/railzway/internal/auth/oauth2provider"
+	authscope "github.com/smallbiznis/railzway/internal/auth/scope"
 	"github.com/smallbiznis/railzway/internal/auth/session"
 	"github.com/smallbiznis/railzway/internal/authorization"
 	"github.com/smallbiznis/railzway/internal/billingdashboard"
@@ -32,6 +33,8 @@ import (
 	"github.com/smallbiznis/railzway/internal/config"
 	"github.com/smallbiznis/railzway/internal/customer"
 	customerdomain "github.com/smallbiznis/railzway/internal/customer/domain"
+	"github.com/smallbiznis/railzway/internal/emaildelivery"
+	emaildeliverydomain "github.com/smallbiznis/railzway/internal/emaildelivery/domain"
 	"github.com/smallbiznis/railzway/internal/events"
 	"github.com/smallbiznis/railzway/internal/feature"
 	featuredomain "github.com/smallbiznis/railzway/internal/feature/domain"
@@ -40,6 +43,7 @@ import (
 	"github.com/smallbiznis/railzway/internal/invoicetemplate"
 	invoicetemplatedomain "github.com/smallbiznis/railzway/internal/invoicetemplate/domain"
 	"github.com/smallbiznis/railzway/internal/ledger"
+	ledgerdomain "github.com/smallbiznis/railzway/internal/ledger/domain"
 	"github.com/smallbiznis/railzway/internal/meter"
 	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
 	"github.com/smallbiznis/railzway/internal/observability"
@@ -100,6 +104,7 @@ var Module = fx.Module("http.server",
 	billingdashboard.Module,
 	billingoperations.Module,
 	email.Module,
+	emaildelivery.Module,
 	pdf.Module,
 	billingoverview.Module,
 	invoice.Module,
@@ -150,6 +155,7 @@ func registerGin(obsCfg observability.Config, httpMetrics *obsmetrics.HTTPMetric
 }
 
 func RegisterRoutes(s *Server) {
+	s.RegisterHealthRoutes()
 	s.RegisterAuthRoutes()
 	s.RegisterAPIRoutes()
 	s.RegisterAdminRoutes()
@@ -200,7 +206,9 @@ type Server struct {
 	billingOperationsSvc        billingoperationsdomain.Service
 	billingOverviewSvc          billingoverviewdomain.Service
 	billingRollup               *billingrollup.Service
+	emailDeliverySvc            emaildeliverydomain.Service
 	invoiceSvc                  invoicedomain.Service
+	ledgerSvc                   ledgerdomain.Service
 	meterSvc                    meterdomain.Service
 	organizationSvc             organizationdomain.Service
 	customerSvc                 customerdomain.Service
@@ -210,7 +218,9 @@ type Server struct {
 	productSvc                  productdomain.Service
 	productFeatureSvc           productfeaturedomain.Service
 	featureSvc                  featuredomain.Service
+	featureFlagSvc              featuredomain.FlagService
 	paymentSvc                  paymentdomain.Service
+	paymentReconciliationSvc    paymentdomain.ReconciliationService
 	paymentProviderSvc          paymentproviderdomain.Service
 	invoiceTemplateSvc          invoicetemplatedomain.Service
 	refrepo                     referencedomain.Repository
@@ -221,7 +231,7 @@ type Server struct {
 	taxSvc                      taxdomain.Service
 	liveMeterEvents             *liveevents.Hub
 	obsMetrics                  *obsmetrics.Metrics
-	usageLimiter                *ratelimit.UsageIngestLimiter
+	usageLimiter                usageIngestLimiter
 	publicInvoiceSvc            publicinvoicedomain.Service
 	publicInvoiceLimiter        *rateLimiter
 	publicPaymentIntentLimiter  *rateLimiter
@@ -229,6 +239,10 @@ type Server struct {
 	publicPaymentMethodsCache   *paymentMethodsCache
 
 	scheduler *scheduler.Scheduler `optional:"true"`
+
+	// apiKeyRouteScopes records the scopes each /api route declared, keyed
+	// by "METHOD /path".
+	apiKeyRouteScopes map[string][]authscope.Scope
 }
 
 type ServerParams struct {
@@ -248,7 +262,9 @@ type ServerParams struct {
 	BillingOperationsSvc billingoperationsdomain.Service `optional:"true"`
 	BillingOverviewSvc   billingoverviewdomain.Service   `optional:"true"`
 	BillingRollup        *billingrollup.Service          `optional:"true"`
+	EmailDeliverySvc     emaildeliverydomain.Service     `optional:"true"`
 	InvoiceSvc           invoicedomain.Service           `optional:"true"`
+	LedgerSvc            ledgerdomain.Service            `optional:"true"`
 	MeterSvc             meterdomain.Service             `optional:"true"`
 	OrganizationSvc      organizationdomain.Service      `optional:"true"`
 	CustomerSvc          customerdomain.Service          `optional:"true"`
@@ -258,6 +274,7 @@ type ServerParams struct {
 	ProductSvc           productdomain.Service           `optional:"true"`
 	ProductFeatureSvc    productfeaturedomain.Service    `optional:"true"`
 	FeatureSvc           featuredomain.Service           `optional:"true"`
+	FeatureFlagSvc       featuredomain.FlagService       `optional:"true"`
 	PaymentSvc           paymentdomain.Service           `optional:"true"`
 	PaymentProviderSvc   paymentproviderdomain.Service   `optional:"true"`
 	InvoiceTemplateSvc   invoicetemplatedomain.Service   `optional:"true"`
@@ -271,7 +288,8 @@ type ServerParams struct {
 	ObsMetrics           *obsmetrics.Metrics             `optional:"true"`
 	UsageLimiter         *ratelimit.UsageIngestLimiter   `optional:"true"`
 
-	Scheduler *scheduler.Scheduler `optional:"true"`
+	Scheduler                *scheduler.Scheduler                `optional:"true"`
+	PaymentReconciliationSvc paymentdomain.ReconciliationService `optional:"true"`
 }
 
 func NewServer(p ServerParams) *Server {
@@ -291,7 +309,9 @@ func NewServer(p ServerParams) *Server {
 		billingOperationsSvc:        p.BillingOperationsSvc,
 		billingOverviewSvc:          p.BillingOverviewSvc,
 		billingRollup:               p.BillingRollup,
+		emailDeliverySvc:            p.EmailDeliverySvc,
 		invoiceSvc:                  p.InvoiceSvc,
+		ledgerSvc:                   p.LedgerSvc,
 		meterSvc:                    p.MeterSvc,
 		organizationSvc:             p.OrganizationSvc,
 		customerSvc:                 p.CustomerSvc,
@@ -301,7 +321,9 @@ func NewServer(p ServerParams) *Server {
 		productSvc:                  p.ProductSvc,
 		productFeatureSvc:           p.ProductFeatureSvc,
 		featureSvc:                  p.FeatureSvc,
+		featureFlagSvc:              p.FeatureFlagSvc,
 		paymentSvc:                  p.PaymentSvc,
+		paymentReconciliationSvc:    p.PaymentReconciliationSvc,
 		paymentProviderSvc:          p.PaymentProviderSvc,
 		invoiceTemplateSvc:          p.InvoiceTemplateSvc,
 		refrepo:                     p.Refrepo,
@@ -358,69 +380,76 @@ func (s *Server) RegisterAuthRoutes() {
 
 func (s *Server) RegisterAPIRoutes() {
 	api := s.engine.Group("/api")
+	// Every API-key route declares the scopes it accepts; see apiKeyRouter.
+	keyed := s.apiKeyRoutes(api)
 
-	api.GET("/countries", s.APIKeyRequired(), s.ListCountries)
-	api.GET("/timezones", s.APIKeyRequired(), s.ListTimezones)
-	api.GET("/currencies", s.APIKeyRequired(), s.ListCurrencies)
+	keyed.GET("/countries", []authscope.Scope{authscope.ScopeReferenceView}, s.ListCountries)
+	keyed.GET("/timezones", []authscope.Scope{authscope.ScopeReferenceView}, s.ListTimezones)
+	keyed.GET("/currencies", []authscope.Scope{authscope.ScopeReferenceView}, s.ListCurrencies)
 
 	// -------- Meters --------
-	api.GET("/meters", s.APIKeyRequired(), s.ListMeters)
-	api.POST("/meters", s.APIKeyRequired(), s.CreateMeter)
-	api.GET("/meters/:id", s.APIKeyRequired(), s.GetMeterByID)
-	api.PATCH("/meters/:id", s.APIKeyRequired(), s.UpdateMeter)
-	api.DELETE("/meters/:id", s.APIKeyRequired(), s.DeleteMeter)
+	keyed.GET("/meters", []authscope.Scope{authscope.ScopeMeterView}, s.ListMeters)
+	keyed.POST("/meters", []authscope.Scope{authscope.ScopeMeterCreate}, s.CreateMeter)
+	keyed.GET("/meters/:id", []authscope.Scope{authscope.ScopeMeterView}, s.GetMeterByID)
+	keyed.PATCH("/meters/:id", []authscope.Scope{authscope.ScopeMeterUpdate}, s.UpdateMeter)
+	keyed.DELETE("/meters/:id", []authscope.Scope{authscope.ScopeMeterDelete}, s.DeleteMeter)
 
 	// -------- Product --------
-	api.GET("/products", s.APIKeyRequired(), s.ListProducts)
-	api.POST("/products", s.APIKeyRequired(), s.CreateProduct)
-	api.GET("/products/:id", s.APIKeyRequired(), s.GetProductByID)
-	api.PATCH("/products/:id", s.APIKeyRequired(), s.UpdateProduct)
-	api.POST("/products/:id/archive", s.APIKeyRequired(), s.ArchiveProduct)
+	keyed.GET("/products", []authscope.Scope{authscope.ScopeProductView}, s.ListProducts)
+	keyed.POST("/products", []authscope.Scope{authscope.ScopeProductCreate}, s.CreateProduct)
+	keyed.GET("/products/:id", []authscope.Scope{authscope.ScopeProductView}, s.GetProductByID)
+	keyed.PATCH("/products/:id", []authscope.Scope{authscope.ScopeProductUpdate}, s.UpdateProduct)
+	keyed.POST("/products/:id/archive", []authscope.Scope{authscope.ScopeProductDelete}, s.ArchiveProduct)
 
 	// -------- Pricing --------
-	api.GET("/pricings", s.APIKeyRequired(), s.ListPricings)
-	api.POST("/pricings", s.APIKeyRequired(), s.CreatePricing)
-	api.GET("/pricings/:id", s.APIKeyRequired(), s.GetPricingByID)
+	keyed.GET("/pricings", []authscope.Scope{authscope.ScopePriceView}, s.ListPricings)
+	keyed.POST("/pricings", []authscope.Scope{authscope.ScopePriceCreate}, s.CreatePricing)
+	keyed.GET("/pricings/:id", []authscope.Scope{authscope.ScopePriceView}, s.GetPricingByID)
 
 	// -------- Prices --------
-	api.GET("/prices", s.APIKeyRequired(), s.ListPrices)
-	api.POST("/prices", s.APIKeyRequired(), s.CreatePrice)
-	api.GET("/prices/:id", s.APIKeyRequired(), s.GetPriceByID)
+	keyed.GET("/prices", []authscope.Scope{authscope.ScopePriceView}, s.ListPrices)
+	keyed.POST("/prices", []authscope.Scope{authscope.ScopePriceCreate}, s.CreatePrice)
+	keyed.GET("/prices/:id", []authscope.Scope{authscope.ScopePriceView}, s.GetPriceByID)
 
 	// -------- Price Amounts --------
-	api.GET("/price_amounts", s.APIKeyRequired(), s.ListPriceAmounts)
-	api.POST("/price_amounts", s.APIKeyRequired(), s.CreatePriceAmount)
-	api.GET("/price_amounts/:id", s.APIKeyRequired(), s.GetPriceAmountByID)
+	keyed.GET("/price_amounts", []authscope.Scope{authscope.ScopePriceView}, s.ListPriceAmounts)
+	keyed.POST("/price_amounts", []authscope.Scope{authscope.ScopePriceCreate}, s.CreatePriceAmount)
+	keyed.GET("/price_amounts/:id", []authscope.Scope{authscope.ScopePriceView}, s.GetPriceAmountByID)
 
 	// -------- Tiers ---------
-	api.GET("/price_tiers", s.APIKeyRequired(), s.ListPriceTiers)
-	api.POST("/price_tiers", s.APIKeyRequired(), s.CreatePriceTier)
-	api.GET("/price_tiers/:id", s.APIKeyRequired(), s.GetPriceTierByID)
+	keyed.GET("/price_tiers", []authscope.Scope{authscope.ScopePriceView}, s.ListPriceTiers)
+	keyed.POST("/price_tiers", []authscope.Scope{authscope.ScopePriceCreate}, s.CreatePriceTier)
+	keyed.GET("/price_tiers/:id", []authscope.Scope{authscope.ScopePriceView}, s.GetPriceTierByID)
 
 	// -------- Subscriptions --------
 	// Shared handlers, different gates: API keys use scopes, admin uses RBAC.
-	api.GET("/subscriptions", s.APIKeyRequired(), s.ListSubscriptions)
-	api.POST("/subscriptions", s.APIKeyRequired(), s.CreateSubscription)
-	api.GET("/subscriptions/:id", s.APIKeyRequired(), s.GetSubscriptionByID)
-	api.PUT("/subscriptions/:id/items", s.APIKeyRequired(), s.ReplaceSubscriptionItems)
-	api.POST("/subscriptions/:id/activate", s.APIKeyRequired(), s.ActivateSubscription)
-	api.POST("/subscriptions/:id/pause", s.APIKeyRequired(), s.PauseSubscription)
-	api.POST("/subscriptions/:id/resume", s.APIKeyRequired(), s.ResumeSubscription)
-	api.POST("/subscriptions/:id/cancel", s.APIKeyRequired(), s.CancelSubscription)
+	keyed.GET("/subscriptions", []authscope.Scope{authscope.ScopeSubscriptionView}, s.ListSubscriptions)
+	keyed.POST("/subscriptions", []authscope.Scope{authscope.ScopeSubscriptionCreate}, s.CreateSubscription)
+	keyed.GET("/subscriptions/:id", []authscope.Scope{authscope.ScopeSubscriptionView}, s.GetSubscriptionByID)
+	keyed.PUT("/subscriptions/:id/items", []authscope.Scope{authscope.ScopeSubscriptionUpdate}, s.ReplaceSubscriptionItems)
+	keyed.POST("/subscriptions/:id/activate", []authscope.Scope{authscope.ScopeSubscriptionActivate}, s.ActivateSubscription)
+	keyed.POST("/subscriptions/:id/pause", []authscope.Scope{authscope.ScopeSubscriptionPause}, s.PauseSubscription)
+	keyed.POST("/subscriptions/:id/resume", []authscope.Scope{authscope.ScopeSubscriptionResume}, s.ResumeSubscription)
+	keyed.POST("/subscriptions/:id/cancel", []authscope.Scope{authscope.ScopeSubscriptionCancel}, s.CancelSubscription)
 
 	// -------- Invoices --------
-	api.GET("/invoices", s.APIKeyRequired(), s.ListInvoices)
-	api.GET("/invoices/:id", s.APIKeyRequired(), s.GetInvoiceByID)
+	keyed.GET("/invoices", []authscope.Scope{authscope.ScopeInvoiceView}, s.ListInvoices)
+	keyed.GET("/invoices/:id", []authscope.Scope{authscope.ScopeInvoiceView}, s.GetInvoiceByID)
+	keyed.POST("/invoices/:id/payments", []authscope.Scope{authscope.ScopeInvoiceRecordPayment}, s.RecordManualPayment)
 
 	// -------- Customers --------
-	api.GET("/customers", s.APIKeyRequired(), s.ListCustomers)
-	api.POST("/customers", s.APIKeyRequired(), s.CreateCustomer)
-	api.GET("/customers/:id", s.APIKeyRequired(), s.GetCustomerByID)
+	keyed.GET("/customers", []authscope.Scope{authscope.ScopeCustomerView}, s.ListCustomers)
+	keyed.POST("/customers", []authscope.Scope{authscope.ScopeCustomerCreate}, s.CreateCustomer)
+	keyed.GET("/customers/:id", []authscope.Scope{authscope.ScopeCustomerView}, s.GetCustomerByID)
+	keyed.GET("/customers/:id/usage", []authscope.Scope{authscope.ScopeCustomerView}, s.GetCustomerUsage)
 
 	// -------- Payment Webhooks --------
 	api.POST("/payments/webhooks/:provider", s.HandlePaymentWebhook)
+	api.POST("/email/webhooks", s.HandleEmailWebhook)
 
-	api.POST("/usage", s.APIKeyRequired(), s.UsageIngestRateLimit(), s.IngestUsage)
+	// usage:ingest is the older name for usage:write and is still honoured.
+	keyed.POST("/usage", []authscope.Scope{authscope.ScopeUsageWrite, authscope.ScopeUsageIngest}, s.UsageIngestRateLimit(), s.IngestUsage)
+	keyed.POST("/usage/batch", []authscope.Scope{authscope.ScopeUsageWrite, authscope.ScopeUsageIngest}, s.UsageIngestRateLimit(), s.IngestUsageBatch)
 
 	if s.cfg.Environment != "production" {
 		api.POST("/test/cleanup", s.TestCleanup)
@@ -462,6 +491,8 @@ func (s *Server) RegisterAdminRoutes() {
 	admin.POST("/features", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.CreateFeature)
 	admin.PATCH("/features/:id", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.UpdateFeature)
 	admin.POST("/features/:id/archive", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.ArchiveFeature)
+	admin.GET("/feature-flags", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.ListFeatureFlags)
+	admin.PUT("/feature-flags/:flag", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.SetFeatureFlag)
 
 	// -------- Tax Definitions --------
 	admin.GET("/tax-definitions", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.ListTaxDefinitions)
@@ -503,18 +534,23 @@ func (s *Server) RegisterAdminRoutes() {
 	admin.GET("/invoices", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.ListInvoices)
 	admin.GET("/invoices/:id", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetInvoiceByID)
 	admin.GET("/invoices/:id/render", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.RenderInvoice)
+	admin.GET("/invoices/:id/pdf", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetInvoicePDF)
+	admin.GET("/billing/cycles/:id/invoice-preview", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.PreviewInvoice)
 
 	// -------- Billing Dashboard --------
 	admin.GET("/billing/customers", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingDashboard, authorization.ActionBillingDashboardView), s.ListBillingCustomers)
 	admin.GET("/billing/cycles", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingDashboard, authorization.ActionBillingDashboardView), s.ListBillingCycles)
+	admin.POST("/billing/cycles/:id/force-close", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.ForceCloseBillingCycle)
 	admin.GET("/billing/activity", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingDashboard, authorization.ActionBillingDashboardView), s.ListBillingActivity)
 	admin.GET("/billing/operations", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsView), s.GetBillingOperations)
 	admin.POST("/billing/operations/actions", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.PostBillingOperationsAction)
+	admin.POST("/billing/operations/actions/batch", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.PostBillingOperationsActionsBatch)
 	admin.POST("/billing/operations/assignments", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.PostBillingOperationsAssignment)
 	admin.DELETE("/billing/operations/assignments", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.ReleaseBillingOperationsAssignment)
 	admin.GET("/billing/operations/overdue-invoices", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsView), s.GetBillingOperationsOverdueInvoices)
 	admin.GET("/billing/operations/outstanding-customers", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsView), s.GetBillingOperationsOutstandingCustomers)
 	admin.GET("/billing/operations/payment-issues", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsView), s.GetBillingOperationsPaymentIssues)
+	admin.GET("/billing/operations/missing-public-tokens", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsView), s.GetBillingOperationsMissingPublicTokens)
 	admin.GET("/billing/overview/mrr", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOverview, authorization.ActionBillingOverviewView), s.GetBillingOverviewMRR)
 	admin.GET("/billing/overview/mrr-movement", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOverview, authorization.ActionBillingOverviewView), s.GetBillingOverviewMRRMovement)
 	admin.GET("/billing/overview/revenue", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOverview, authorization.ActionBillingOverviewView), s.GetBillingOverviewRevenue)
@@ -525,21 +561,47 @@ func (s *Server) RegisterAdminRoutes() {
 	// -------- FinOps Performance --------
 	admin.GET("/finops/performance/me", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.GetBillingOperationsPerformanceMe)
 	admin.GET("/finops/performance/team", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetBillingOperationsPerformanceTeam)
+	admin.GET("/finops/performance/team/export", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.ExportBillingOperationsPerformanceTeam)
 	admin.GET("/finops/exposure-analysis", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetExposureAnalysis)
+	admin.GET("/finops/ar-health", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetARHealth)
+	admin.GET("/finops/sla-stats", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetSLAStats)
+
+	// -------- Scheduler --------
+	admin.GET("/scheduler/job-runs", s.RequireRole(organizationdomain.RoleOwner), s.ListSchedulerJobRuns)
 
 	// -------- Billing Operations IA (Task-Centric Views) --------
 	admin.GET("/billing-operations/inbox", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.GetBillingOperationsInbox)
 	admin.GET("/billing-operations/my-work", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.GetBillingOperationsMyWork)
 	admin.GET("/billing-operations/recently-resolved", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.GetBillingOperationsRecentlyResolved)
 	admin.GET("/billing-operations/team", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetBillingOperationsTeamView)
+	admin.GET("/billing-operations/assignments", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.ListBillingOperationsAssignments)
+	admin.POST("/billing-operations/invoices/:id/public-token", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.ReissueBillingOperationsPublicToken)
 	admin.GET("/billing-operations/invoices/:id/payments", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetBillingOperationsInvoicePayments)
+	admin.POST("/billing-operations/invoices/:id/payments", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectInvoice, authorization.ActionInvoiceRecordPayment), s.RecordManualPayment)
+	admin.GET("/billing-operations/payments/match", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsView), s.MatchPaymentByReference)
+	admin.POST("/billing-operations/payments/:id/match", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.ConfirmPaymentMatch)
+	admin.GET("/billing-operations/customers/:id/statement", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.GetBillingOperationsCustomerStatement)
+	admin.GET("/billing-operations/invoices/:id/assignment", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.GetBillingOperationsInvoiceAssignment)
+	admin.GET("/billing-operations/customers/:id/assignment", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.GetBillingOperationsCustomerAssignment)
+	admin.GET("/billing-operations/invoices/:id/audit-trail", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetBillingOperationsInvoiceAuditTrail)
+	admin.GET("/billing-operations/customers/:id/audit-trail", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetBillingOperationsCustomerAuditTrail)
 
 	admin.GET("/organizations/:id/members", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.ListOrganizationMembers)
 
+	// -------- Working calendar --------
+	admin.GET("/calendar", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.GetOrganizationCalendar)
+	admin.PUT("/calendar", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.SetOrganizationCalendar)
+	admin.DELETE("/calendar", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.DeleteOrganizationCalendar)
+
 	// -------- Billing Operations Actions --------
 	admin.POST("/billing-operations/claim", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.PostBillingOperationsAssignment)
 	admin.POST("/billing-operations/release", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.ReleaseBillingOperationsAssignment)
+	admin.POST("/billing-operations/reassign", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.ReassignBillingOperationsAssignment)
+	admin.POST("/billing-operations/auto-assign", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.AutoAssignBillingOperationsInbox)
 	admin.POST("/billing-operations/resolve", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.ResolveBillingOperationsAssignment)
+	admin.POST("/billing-operations/snooze", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.PostBillingOperationsSnooze)
+	admin.POST("/billing-operations/invoices/:id/lines/:line_id/dispute", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.DisputeBillingOperationsInvoiceLine)
+	admin.POST("/billing-operations/sla-pause", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.authorizeOrgAction(authorization.ObjectBillingOperations, authorization.ActionBillingOperationsAct), s.PostBillingOperationsSLAPause)
 	admin.POST("/billing-operations/record-follow-up", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleMember, organizationdomain.RoleFinOps), s.RecordBillingOperationsFollowUp)
 
 	admin.POST("/internal/rebuild-billing-snapshots", s.RequireRole(organizationdomain.RoleOwner), s.RebuildBillingSnapshots)
@@ -561,9 +623,18 @@ func (s *Server) RegisterAdminRoutes() {
 	admin.GET("/customers", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.ListCustomers)
 	admin.POST("/customers", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.CreateCustomer)
 	admin.GET("/customers/:id", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetCustomerByID)
+	admin.GET("/customers/:id/usage", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.GetCustomerUsage)
+	admin.DELETE("/customers/:id", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.DeleteCustomer)
+	admin.POST("/customers/:id/restore", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.RestoreCustomer)
+	admin.PATCH("/customers/:id/contact", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.UpdateCustomerContact)
+	admin.PUT("/customers/:id/invoice-template", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.SetCustomerInvoiceTemplate)
+
+	// -------- Ledger --------
+	admin.GET("/ledger/entries", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin, organizationdomain.RoleFinOps), s.ListLedgerEntries)
 
 	admin.GET("/audit-logs", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.authorizeOrgAction(authorization.ObjectAuditLog, authorization.ActionAuditLogView), s.ListAuditLogs)
 	admin.GET("/api-keys/scopes", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.authorizeOrgAction(authorization.ObjectAPIKey, authorization.ActionAPIKeyView), s.ListAPIKeyScopes)
+	admin.GET("/api-keys/:key_id/scopes", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.authorizeOrgAction(authorization.ObjectAPIKey, authorization.ActionAPIKeyView), s.GetAPIKeyScopes)
 	admin.GET("/api-keys", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.authorizeOrgAction(authorization.ObjectAPIKey, authorization.ActionAPIKeyView), s.ListAPIKeys)
 	admin.POST("/api-keys", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.authorizeOrgAction(authorization.ObjectAPIKey, authorization.ActionAPIKeyCreate), s.CreateAPIKey)
 	admin.POST("/api-keys/:key_id/reveal", s.RequireRole(organizationdomain.RoleOwner, organizationdomain.RoleAdmin), s.authorizeOrgAction(authorization.ObjectAPIKey, authorization.ActionAPIKeyRotate), s.RevealAPIKey)
@@ -147,6 +147,27 @@ func (f *fakeOrgService) SetBillingPreferences(ctx context.Context, userID snowf
 	return nil
 }
 
+func (f *fakeOrgService) GetCalendar(ctx context.Context, orgID string) (*orgdomain.OrgCalendar, error) {
+	_ = ctx
+	_ = orgID
+	return nil, nil
+}
+
+func (f *fakeOrgService) SetCalendar(ctx context.Context, userID snowflake.ID, orgID string, req orgdomain.OrgCalendarRequest) (*orgdomain.OrgCalendar, error) {
+	_ = ctx
+	_ = userID
+	_ = orgID
+	_ = req
+	return nil, nil
+}
+
+func (f *fakeOrgService) DeleteCalendar(ctx context.Context, userID snowflake.ID, orgID string) error {
+	_ = ctx
+	_ = userID
+	_ = orgID
+	return nil
+}
+
 func TestSignupHandlerOSSModeReturns404(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -2,11 +2,14 @@ package server
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/gin-gonic/gin"
+	"github.com/smallbiznis/railzway/internal/scheduler/guard"
 	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
 	"github.com/smallbiznis/railzway/pkg/db/pagination"
 )
@@ -33,6 +36,7 @@ func (s *Server) CreateSubscription(c *gin.Context) {
 		BillingCycleType: strings.TrimSpace(req.BillingCycleType),
 		Items:            normalizeSubscriptionItems(req.Items),
 		Metadata:         req.Metadata,
+		Currency:         strings.TrimSpace(req.Currency),
 	})
 	if err != nil {
 		AbortWithError(c, err)
@@ -52,7 +56,8 @@ func (s *Server) CreateSubscription(c *gin.Context) {
 }
 
 type replaceSubscriptionItemsRequest struct {
-	Items []subscriptiondomain.CreateSubscriptionItemRequest `json:"items"`
+	Items             []subscriptiondomain.CreateSubscriptionItemRequest `json:"items"`
+	ProrationBehavior subscriptiondomain.ProrationBehavior               `json:"proration_behavior"`
 }
 
 // @Summary      Replace Subscription Items
@@ -79,8 +84,9 @@ func (s *Server) ReplaceSubscriptionItems(c *gin.Context) {
 	}
 
 	resp, err := s.subscriptionSvc.ReplaceItems(c.Request.Context(), subscriptiondomain.ReplaceSubscriptionItemsRequest{
-		SubscriptionID: id,
-		Items:          normalizeSubscriptionItems(req.Items),
+		SubscriptionID:    id,
+		Items:             normalizeSubscriptionItems(req.Items),
+		ProrationBehavior: req.ProrationBehavior,
 	})
 	if err != nil {
 		AbortWithError(c, err)
@@ -195,7 +201,7 @@ func (s *Server) CancelSubscription(c *gin.Context) {
 }
 
 // @Summary      Activate Subscription
-// @Description  Activate a subscription
+// @Description  Activate a draft subscription. A paused subscription is resumed through /subscriptions/{id}/resume instead.
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
@@ -211,25 +217,55 @@ func (s *Server) ActivateSubscription(c *gin.Context) {
 	)
 }
 
+type pauseSubscriptionRequest struct {
+	ResumeAt *time.Time `json:"resume_at,omitempty"`
+}
+
 // @Summary      Pause Subscription
-// @Description  Pause a subscription
+// @Description  Pause a subscription. No billing cycles are opened while it is paused. When resume_at is set, the subscription resumes automatically at that time.
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
 // @Param        id   path      string  true  "Subscription ID"
+// @Param        request body pauseSubscriptionRequest false "Pause Subscription Request"
 // @Success      204
 // @Router       /subscriptions/{id}/pause [post]
 func (s *Server) PauseSubscription(c *gin.Context) {
-	s.transitionSubscription(
-		c,
-		subscriptiondomain.SubscriptionStatusPaused,
-		"subscription.pause",
-	)
+	id := strings.TrimSpace(c.Param("id"))
+	if _, err := snowflake.ParseString(id); err != nil {
+		AbortWithError(c, newValidationError("id", "invalid_id", "invalid id"))
+		return
+	}
+
+	var req pauseSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	if err := s.subscriptionSvc.PauseSubscription(c.Request.Context(), id, req.ResumeAt); err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	if s.auditSvc != nil {
+		targetID := id
+		metadata := map[string]any{
+			"subscription_id": id,
+			"status":          string(subscriptiondomain.SubscriptionStatusPaused),
+		}
+		if req.ResumeAt != nil {
+			metadata["resume_at"] = req.ResumeAt.UTC().Format(time.RFC3339)
+		}
+		_ = s.auditSvc.AuditLog(c.Request.Context(), nil, "", nil, "subscription.pause", "subscription", &targetID, metadata)
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 // @Summary      Resume Subscription
-// @Description  Resume a subscription
+// @Description  Resume a paused subscription
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
@@ -238,11 +274,26 @@ func (s *Server) PauseSubscription(c *gin.Context) {
 // @Success      204
 // @Router       /subscriptions/{id}/resume [post]
 func (s *Server) ResumeSubscription(c *gin.Context) {
-	s.transitionSubscription(
-		c,
-		subscriptiondomain.SubscriptionStatusActive,
-		"subscription.resume",
-	)
+	id := strings.TrimSpace(c.Param("id"))
+	if _, err := snowflake.ParseString(id); err != nil {
+		AbortWithError(c, newValidationError("id", "invalid_id", "invalid id"))
+		return
+	}
+
+	if err := s.subscriptionSvc.ResumeSubscription(c.Request.Context(), id); err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	if s.auditSvc != nil {
+		targetID := id
+		_ = s.auditSvc.AuditLog(c.Request.Context(), nil, "", nil, "subscription.resume", "subscription", &targetID, map[string]any{
+			"subscription_id": id,
+			"status":          string(subscriptiondomain.SubscriptionStatusActive),
+		})
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 func (s *Server) transitionSubscription(c *gin.Context, target subscriptiondomain.SubscriptionStatus, auditAction string) {
@@ -293,6 +344,7 @@ func isSubscriptionValidationError(err error) bool {
 	switch {
 	case errors.Is(err, subscriptiondomain.ErrInvalidOrganization),
 		errors.Is(err, subscriptiondomain.ErrInvalidCustomer),
+		errors.Is(err, subscriptiondomain.ErrInvalidCurrency),
 		errors.Is(err, subscriptiondomain.ErrInvalidSubscription),
 		errors.Is(err, subscriptiondomain.ErrInvalidMeterID),
 		errors.Is(err, subscriptiondomain.ErrInvalidMeterCode),
@@ -313,7 +365,11 @@ func isSubscriptionValidationError(err error) bool {
 		errors.Is(err, subscriptiondomain.ErrInvalidPrice),
 		errors.Is(err, subscriptiondomain.ErrInvalidProduct),
 		errors.Is(err, subscriptiondomain.ErrMultipleFlatPrices),
-		errors.Is(err, subscriptiondomain.ErrMissingEntitlements):
+		errors.Is(err, subscriptiondomain.ErrMissingEntitlements),
+		errors.Is(err, subscriptiondomain.ErrInvalidResumeAt),
+		errors.Is(err, subscriptiondomain.ErrInvalidProrationBehavior),
+		errors.Is(err, guard.ErrSubscriptionNotPausable),
+		errors.Is(err, guard.ErrSubscriptionNotPaused):
 		return true
 	default:
 		return false
@@ -36,3 +36,85 @@ func (s *Server) IngestUsage(c *gin.Context) {
 
 	c.JSON(http.StatusOK, usage)
 }
+
+// @Summary      Ingest Usage Batch
+// @Description  Ingest up to 500 usage events. Each event is validated and deduplicated on its own; rejected events are reported per item and do not fail the batch.
+// @Tags         usage
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request body []usagedomain.CreateIngestRequest true "Usage events"
+// @Success      200  {object}  usagedomain.BatchIngestResponse
+// @Router       /usage/batch [post]
+func (s *Server) IngestUsageBatch(c *gin.Context) {
+	var reqs []usagedomain.CreateIngestRequest
+	if err := c.ShouldBindJSON(&reqs); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	resp, err := s.usagesvc.IngestBatch(c.Request.Context(), reqs)
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Get Customer Usage
+// @Description  Aggregate a customer's usage of a meter into hourly or daily buckets. Each point carries the sum, max and count of the bucket's events, and a value computed with the meter's aggregation type. Pages are counted in buckets.
+// @Tags         usage
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id           path      string  true   "Customer ID"
+// @Param        meter_code   query     string  true   "Meter Code"
+// @Param        from         query     string  true   "From (inclusive)"
+// @Param        to           query     string  true   "To (exclusive)"
+// @Param        granularity  query     string  false  "hour or day (default day)"
+// @Param        page_token   query     string  false  "Page Token"
+// @Param        page_size    query     int     false  "Buckets per page"
+// @Success      200  {object}  usagedomain.GetUsageResponse
+// @Router       /customers/{id}/usage [get]
+func (s *Server) GetCustomerUsage(c *gin.Context) {
+	var query struct {
+		MeterCode   string `form:"meter_code"`
+		From        string `form:"from"`
+		To          string `form:"to"`
+		Granularity string `form:"granularity"`
+		PageToken   string `form:"page_token"`
+		PageSize    int    `form:"page_size"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		AbortWithError(c, invalidRequestError())
+		return
+	}
+
+	from, err := parseOptionalTime(query.From, false)
+	if err != nil || from == nil {
+		AbortWithError(c, newValidationError("from", "invalid_from", "invalid from"))
+		return
+	}
+	to, err := parseOptionalTime(query.To, true)
+	if err != nil || to == nil {
+		AbortWithError(c, newValidationError("to", "invalid_to", "invalid to"))
+		return
+	}
+
+	resp, err := s.usagesvc.GetUsage(c.Request.Context(), usagedomain.GetUsageRequest{
+		CustomerID:  strings.TrimSpace(c.Param("id")),
+		MeterCode:   strings.TrimSpace(query.MeterCode),
+		From:        *from,
+		To:          *to,
+		Granularity: strings.TrimSpace(query.Granularity),
+		PageToken:   query.PageToken,
+		PageSize:    int32(query.PageSize),
+	})
+	if err != nil {
+		AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
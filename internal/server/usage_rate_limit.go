@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +20,7 @@ import (
 const (
 	rateLimitReasonOrgRate                  = "org-rate"
 	rateLimitReasonEndpointRate             = "endpoint-rate"
+	rateLimitReasonAPIKeyRate               = "api-key-rate"
 	rateLimitReasonCustomerMeterConcurrency = "customer-meter-concurrency"
 )
 
@@ -27,6 +29,20 @@ type usageIngestRateLimitKey struct {
 	MeterCode  string `json:"meter_code"`
 }
 
+// usageIngestLimiter is the part of ratelimit.UsageIngestLimiter the usage
+// ingest middleware uses.
+type usageIngestLimiter interface {
+	Enabled() bool
+	AllowOrg(ctx context.Context, orgID string, events int) (*ratelimit.RateLimitResult, error)
+	AllowEndpoint(ctx context.Context, orgID string, events int) (*ratelimit.RateLimitResult, error)
+	AllowAPIKey(ctx context.Context, apiKeyID string, tier ratelimit.Tier, events int) (*ratelimit.RateLimitResult, error)
+	TryLockCustomerMeter(ctx context.Context, orgID, customerID, meterCode string) (string, bool, error)
+	ReleaseCustomerMeter(ctx context.Context, orgID, customerID, meterCode, token string) error
+}
+
+// UsageIngestRateLimit limits single and batch usage ingestion alike: every
+// event in the request costs one token, and the request holds the
+// concurrency lock of each distinct customer and meter it writes to.
 func (s *Server) UsageIngestRateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if s.usageLimiter == nil || !s.usageLimiter.Enabled() {
@@ -43,37 +59,38 @@ func (s *Server) UsageIngestRateLimit() gin.HandlerFunc {
 		endpoint := normalizeRateLimitEndpoint(c)
 		ctx := c.Request.Context()
 
-		allowedRes, err := s.usageLimiter.AllowOrg(ctx, orgID.String())
+		events, keys, err := readUsageIngestKeys(c)
 		if err != nil {
-			logger.FromContext(ctx).Warn("usage ingest org rate limit check failed", zap.Error(err))
-			AbortWithError(c, ErrServiceUnavailable)
-			return
-		}
-		if !allowedRes.Allowed {
-			denyUsageIngestRateLimit(c, endpoint, orgID.String(), rateLimitReasonOrgRate, s.obsMetrics, allowedRes)
+			logger.FromContext(ctx).Warn("usage ingest rate limit read body failed", zap.Error(err))
+			AbortWithError(c, invalidRequestError())
 			return
 		}
 
-		allowedRes, err = s.usageLimiter.AllowEndpoint(ctx, orgID.String())
+		tier := apiKeyTierFromContext(ctx)
+		reason, result, err := s.checkUsageIngestRate(ctx, orgID.String(), tier, events)
 		if err != nil {
-			logger.FromContext(ctx).Warn("usage ingest endpoint rate limit check failed", zap.Error(err))
+			logger.FromContext(ctx).Warn("usage ingest rate limit check failed",
+				zap.String("tier", tier.Name()),
+				zap.Error(err),
+			)
 			AbortWithError(c, ErrServiceUnavailable)
 			return
 		}
-		if !allowedRes.Allowed {
-			denyUsageIngestRateLimit(c, endpoint, orgID.String(), rateLimitReasonEndpointRate, s.obsMetrics, allowedRes)
+		if reason != "" {
+			denyUsageIngestRateLimit(c, endpoint, orgID.String(), tier.Name(), reason, s.obsMetrics, result)
 			return
 		}
 
-		customerID, meterCode, err := readUsageIngestKey(c)
-		if err != nil {
-			logger.FromContext(ctx).Warn("usage ingest rate limit read body failed", zap.Error(err))
-			AbortWithError(c, invalidRequestError())
-			return
-		}
-
-		if customerID != "" && meterCode != "" {
-			lockToken, allowed, err := s.usageLimiter.TryLockCustomerMeter(ctx, orgID.String(), customerID, meterCode)
+		lockTokens := make([]string, 0, len(keys))
+		defer func() {
+			for i, token := range lockTokens {
+				if err := s.usageLimiter.ReleaseCustomerMeter(ctx, orgID.String(), keys[i].CustomerID, keys[i].MeterCode, token); err != nil {
+					logger.FromContext(ctx).Warn("usage ingest concurrency unlock failed", zap.Error(err))
+				}
+			}
+		}()
+		for _, key := range keys {
+			lockToken, allowed, err := s.usageLimiter.TryLockCustomerMeter(ctx, orgID.String(), key.CustomerID, key.MeterCode)
 			if err != nil {
 				logger.FromContext(ctx).Warn("usage ingest concurrency lock failed", zap.Error(err))
 				AbortWithError(c, ErrServiceUnavailable)
@@ -81,21 +98,54 @@ func (s *Server) UsageIngestRateLimit() gin.HandlerFunc {
 			}
 			if !allowed {
 				// Concurrency limit doesn't return detailed stats yet, so we use nil or a dummy result
-				denyUsageIngestRateLimit(c, endpoint, orgID.String(), rateLimitReasonCustomerMeterConcurrency, s.obsMetrics, nil)
+				denyUsageIngestRateLimit(c, endpoint, orgID.String(), tier.Name(), rateLimitReasonCustomerMeterConcurrency, s.obsMetrics, nil)
 				return
 			}
-			defer func() {
-				if err := s.usageLimiter.ReleaseCustomerMeter(ctx, orgID.String(), customerID, meterCode, lockToken); err != nil {
-					logger.FromContext(ctx).Warn("usage ingest concurrency unlock failed", zap.Error(err))
-				}
-			}()
+			lockTokens = append(lockTokens, lockToken)
 		}
 
-		recordRateLimitAllowed(ctx, endpoint, orgID.String(), s.obsMetrics)
+		recordRateLimitAllowed(ctx, endpoint, orgID.String(), tier.Name(), s.obsMetrics)
 		c.Next()
 	}
 }
 
+// checkUsageIngestRate charges events tokens against the rate limits for the
+// key's tier. Keys with a custom tier get their own bucket instead of the
+// shared org and endpoint buckets; unlimited keys skip rate checks. It
+// returns the deny reason, or an empty reason when the request is allowed.
+func (s *Server) checkUsageIngestRate(ctx context.Context, orgID string, tier ratelimit.Tier, events int) (string, *ratelimit.RateLimitResult, error) {
+	switch tier.Name() {
+	case ratelimit.TierUnlimited:
+		return "", nil, nil
+	case ratelimit.TierCustom:
+		apiKeyID, _ := apiKeyIDFromContext(ctx)
+		result, err := s.usageLimiter.AllowAPIKey(ctx, apiKeyID.String(), tier, events)
+		if err != nil {
+			return "", nil, err
+		}
+		if !result.Allowed {
+			return rateLimitReasonAPIKeyRate, result, nil
+		}
+		return "", nil, nil
+	}
+
+	result, err := s.usageLimiter.AllowOrg(ctx, orgID, events)
+	if err != nil {
+		return "", nil, err
+	}
+	if !result.Allowed {
+		return rateLimitReasonOrgRate, result, nil
+	}
+	result, err = s.usageLimiter.AllowEndpoint(ctx, orgID, events)
+	if err != nil {
+		return "", nil, err
+	}
+	if !result.Allowed {
+		return rateLimitReasonEndpointRate, result, nil
+	}
+	return "", nil, nil
+}
+
 type usageLimitErrorResponse struct {
 	Error           string `json:"error"`
 	Resource        string `json:"resource"`
@@ -106,14 +156,15 @@ type usageLimitErrorResponse struct {
 	UpgradeRequired bool   `json:"upgrade_required"`
 }
 
-func denyUsageIngestRateLimit(c *gin.Context, endpoint, orgID, reason string, metrics *obsmetrics.Metrics, result *ratelimit.RateLimitResult) {
+func denyUsageIngestRateLimit(c *gin.Context, endpoint, orgID, tier, reason string, metrics *obsmetrics.Metrics, result *ratelimit.RateLimitResult) {
 	ctx := c.Request.Context()
 	log := logger.FromContext(ctx)
 	log.Warn("usage ingest rate limit exceeded",
 		zap.String("reason", reason),
 		zap.String("endpoint", endpoint),
+		zap.String("tier", tier),
 	)
-	recordRateLimitDenied(ctx, endpoint, orgID, reason, metrics)
+	recordRateLimitDenied(ctx, endpoint, orgID, tier, reason, metrics)
 
 	c.Header("Retry-After", "1")
 	c.Header("X-Rate-Limited-Reason", reason)
@@ -135,36 +186,72 @@ func denyUsageIngestRateLimit(c *gin.Context, endpoint, orgID, reason string, me
 	AbortWithError(c, ErrRateLimited)
 }
 
-func recordRateLimitAllowed(ctx context.Context, endpoint, orgID string, metrics *obsmetrics.Metrics) {
+func recordRateLimitAllowed(ctx context.Context, endpoint, orgID, tier string, metrics *obsmetrics.Metrics) {
 	if metrics == nil {
 		return
 	}
-	metrics.RecordRateLimitAllowed(ctx, orgID, endpoint)
+	metrics.RecordRateLimitAllowed(ctx, orgID, endpoint, tier)
 }
 
-func recordRateLimitDenied(ctx context.Context, endpoint, orgID, reason string, metrics *obsmetrics.Metrics) {
+func recordRateLimitDenied(ctx context.Context, endpoint, orgID, tier, reason string, metrics *obsmetrics.Metrics) {
 	if metrics == nil {
 		return
 	}
-	metrics.RecordRateLimitDenied(ctx, orgID, endpoint, reason)
+	metrics.RecordRateLimitDenied(ctx, orgID, endpoint, tier, reason)
 }
 
-func readUsageIngestKey(c *gin.Context) (string, string, error) {
+// readUsageIngestKeys reads the request body, a single event or a batch (a
+// JSON array of events), and returns the number of events and the distinct
+// customer and meter pairs they write to, sorted so concurrent batches take
+// their locks in the same order. A body that does not parse counts as one
+// event and is left for the handler to reject.
+func readUsageIngestKeys(c *gin.Context) (int, []usageIngestRateLimitKey, error) {
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		return "", "", err
+		return 0, nil, err
 	}
 	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+	body = bytes.TrimSpace(body)
 	if len(body) == 0 {
-		return "", "", nil
+		return 1, nil, nil
 	}
 
-	var payload usageIngestRateLimitKey
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return "", "", nil
+	var payloads []usageIngestRateLimitKey
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &payloads); err != nil {
+			return 1, nil, nil
+		}
+	} else {
+		var payload usageIngestRateLimitKey
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return 1, nil, nil
+		}
+		payloads = []usageIngestRateLimitKey{payload}
 	}
 
-	return strings.TrimSpace(payload.CustomerID), strings.TrimSpace(payload.MeterCode), nil
+	seen := make(map[usageIngestRateLimitKey]struct{}, len(payloads))
+	keys := make([]usageIngestRateLimitKey, 0, len(payloads))
+	for _, payload := range payloads {
+		key := usageIngestRateLimitKey{
+			CustomerID: strings.TrimSpace(payload.CustomerID),
+			MeterCode:  strings.TrimSpace(payload.MeterCode),
+		}
+		if key.CustomerID == "" || key.MeterCode == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CustomerID != keys[j].CustomerID {
+			return keys[i].CustomerID < keys[j].CustomerID
+		}
+		return keys[i].MeterCode < keys[j].MeterCode
+	})
+	return max(len(payloads), 1), keys, nil
 }
 
 func normalizeRateLimitEndpoint(c *gin.Context) string {
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeUsageLimiter keeps org and API key token buckets that never refill,
+// and records which buckets were charged and the customer meter locks.
+type fakeUsageLimiter struct {
+	tokens       int
+	apiKeyTokens int
+	charged      []string
+	locked       map[string]bool
+	taken        []string
+}
+
+func (f *fakeUsageLimiter) Enabled() bool { return true }
+
+func (f *fakeUsageLimiter) AllowOrg(_ context.Context, _ string, events int) (*ratelimit.RateLimitResult, error) {
+	f.charged = append(f.charged, "org")
+	if events > f.tokens {
+		return &ratelimit.RateLimitResult{Allowed: false, Limit: f.tokens, Remaining: f.tokens}, nil
+	}
+	f.tokens -= events
+	return &ratelimit.RateLimitResult{Allowed: true}, nil
+}
+
+func (f *fakeUsageLimiter) AllowEndpoint(context.Context, string, int) (*ratelimit.RateLimitResult, error) {
+	f.charged = append(f.charged, "endpoint")
+	return &ratelimit.RateLimitResult{Allowed: true}, nil
+}
+
+func (f *fakeUsageLimiter) AllowAPIKey(_ context.Context, apiKeyID string, tier ratelimit.Tier, events int) (*ratelimit.RateLimitResult, error) {
+	f.charged = append(f.charged, "api_key:"+apiKeyID)
+	if events > f.apiKeyTokens {
+		return &ratelimit.RateLimitResult{Allowed: false, Limit: tier.PerMinute, Remaining: f.apiKeyTokens}, nil
+	}
+	f.apiKeyTokens -= events
+	return &ratelimit.RateLimitResult{Allowed: true}, nil
+}
+
+func (f *fakeUsageLimiter) TryLockCustomerMeter(_ context.Context, _, customerID, meterCode string) (string, bool, error) {
+	key := customerID + "/" + meterCode
+	if f.locked[key] {
+		return "", false, nil
+	}
+	f.locked[key] = true
+	f.taken = append(f.taken, key)
+	return key, true, nil
+}
+
+func (f *fakeUsageLimiter) ReleaseCustomerMeter(_ context.Context, _, _, _, token string) error {
+	delete(f.locked, token)
+	return nil
+}
+
+func TestUsageIngestRateLimit_Batch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(limiter *fakeUsageLimiter, body string) int {
+		s := &Server{engine: gin.New(), usageLimiter: limiter}
+		s.engine.Use(ErrorHandlingMiddleware())
+		s.engine.POST("/usage/batch",
+			func(c *gin.Context) {
+				c.Request = c.Request.WithContext(orgcontext.WithOrgID(c.Request.Context(), 1))
+			},
+			s.UsageIngestRateLimit(),
+			func(c *gin.Context) {
+				assert.Len(t, limiter.locked, len(limiter.taken), "locks are held while the batch is ingested")
+				c.Status(http.StatusNoContent)
+			},
+		)
+		rec := httptest.NewRecorder()
+		s.engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/usage/batch", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	batch := `[
+		{"customer_id": "c2", "meter_code": "api_calls"},
+		{"customer_id": "c1", "meter_code": "api_calls"},
+		{"customer_id": "c1", "meter_code": "api_calls"}
+	]`
+
+	t.Run("a batch costs one token per event", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{tokens: 5, locked: map[string]bool{}}
+		assert.Equal(t, http.StatusNoContent, serve(limiter, batch))
+		assert.Equal(t, 2, limiter.tokens)
+		assert.Equal(t, []string{"c1/api_calls", "c2/api_calls"}, limiter.taken)
+		assert.Empty(t, limiter.locked, "locks are released after the request")
+	})
+
+	t.Run("a batch over the limit is rejected", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{tokens: 2, locked: map[string]bool{}}
+		assert.Equal(t, http.StatusTooManyRequests, serve(limiter, batch))
+		assert.Equal(t, 2, limiter.tokens, "a rejected batch takes no tokens")
+		assert.Empty(t, limiter.taken)
+	})
+
+	t.Run("a batch touching a locked customer meter is rejected", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{tokens: 5, locked: map[string]bool{"c2/api_calls": true}}
+		assert.Equal(t, http.StatusTooManyRequests, serve(limiter, batch))
+		assert.Equal(t, map[string]bool{"c2/api_calls": true}, limiter.locked, "locks taken before the conflict are released")
+	})
+
+	t.Run("a single event costs one token", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{tokens: 1, locked: map[string]bool{}}
+		assert.Equal(t, http.StatusNoContent, serve(limiter, `{"customer_id": "c1", "meter_code": "api_calls"}`))
+		assert.Equal(t, 0, limiter.tokens)
+		assert.Equal(t, []string{"c1/api_calls"}, limiter.taken)
+	})
+}
+
+func TestUsageIngestRateLimit_Tiers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// serve ingests two events with an API key of the given tier and returns
+	// the status and the deny reason header.
+	serve := func(limiter *fakeUsageLimiter, tier ratelimit.Tier) (int, string) {
+		s := &Server{engine: gin.New(), usageLimiter: limiter}
+		s.engine.Use(ErrorHandlingMiddleware())
+		s.engine.POST("/usage/batch",
+			func(c *gin.Context) {
+				ctx := context.WithValue(c.Request.Context(), contextAPIKeyIDKey, int64(42))
+				ctx = context.WithValue(ctx, contextAPIKeyTierKey, tier)
+				c.Request = c.Request.WithContext(orgcontext.WithOrgID(ctx, 1))
+			},
+			s.UsageIngestRateLimit(),
+			func(c *gin.Context) { c.Status(http.StatusNoContent) },
+		)
+		rec := httptest.NewRecorder()
+		body := `[{"customer_id": "c1", "meter_code": "api_calls"}, {"customer_id": "c2", "meter_code": "api_calls"}]`
+		s.engine.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/usage/batch", strings.NewReader(body)))
+		return rec.Code, rec.Header().Get("X-Rate-Limited-Reason")
+	}
+	perMinute := 100
+
+	t.Run("default keys share the org and endpoint buckets", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{tokens: 5, apiKeyTokens: 5, locked: map[string]bool{}}
+		code, _ := serve(limiter, ratelimit.NewTier(nil, false))
+		assert.Equal(t, http.StatusNoContent, code)
+		assert.Equal(t, []string{"org", "endpoint"}, limiter.charged)
+		assert.Equal(t, 3, limiter.tokens)
+	})
+
+	t.Run("custom keys are charged to their own bucket", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{tokens: 0, apiKeyTokens: 5, locked: map[string]bool{}}
+		code, _ := serve(limiter, ratelimit.NewTier(&perMinute, false))
+		assert.Equal(t, http.StatusNoContent, code, "an exhausted org bucket does not limit a custom key")
+		assert.Equal(t, []string{"api_key:42"}, limiter.charged)
+		assert.Equal(t, 3, limiter.apiKeyTokens)
+	})
+
+	t.Run("custom keys over their limit are rejected", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{tokens: 5, apiKeyTokens: 1, locked: map[string]bool{}}
+		code, reason := serve(limiter, ratelimit.NewTier(&perMinute, false))
+		assert.Equal(t, http.StatusTooManyRequests, code)
+		assert.Equal(t, rateLimitReasonAPIKeyRate, reason)
+		assert.Equal(t, 5, limiter.tokens, "the org bucket is not charged")
+		assert.Empty(t, limiter.taken)
+	})
+
+	t.Run("unlimited keys skip rate checks but still take locks", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{locked: map[string]bool{}}
+		code, _ := serve(limiter, ratelimit.NewTier(&perMinute, true))
+		assert.Equal(t, http.StatusNoContent, code)
+		assert.Empty(t, limiter.charged)
+		assert.Equal(t, []string{"c1/api_calls", "c2/api_calls"}, limiter.taken)
+	})
+
+	t.Run("unlimited keys still respect customer meter locks", func(t *testing.T) {
+		limiter := &fakeUsageLimiter{locked: map[string]bool{"c2/api_calls": true}}
+		code, reason := serve(limiter, ratelimit.NewTier(nil, true))
+		assert.Equal(t, http.StatusTooManyRequests, code)
+		assert.Equal(t, rateLimitReasonCustomerMeterConcurrency, reason)
+	})
+}
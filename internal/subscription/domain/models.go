@@ -43,6 +43,7 @@ type Subscription struct {
 	ActivatedAt            *time.Time                 `gorm:"column:activated_at"`
 	PausedAt               *time.Time                 `gorm:"column:paused_at"`
 	ResumedAt              *time.Time                 `gorm:"column:resumed_at"`
+	ResumeAt               *time.Time                 `gorm:"column:resume_at"`
 	EndedAt                *time.Time                 `gorm:"column:ended_at"`
 	PlanChangedAt          *time.Time                 `gorm:"column:plan_changed_at"`
 	BillingAnchorDay       *int16                     `gorm:"type:smallint"`
@@ -67,6 +68,15 @@ func (s *Subscription) SetTrial(trialDurationDays int) *Subscription {
 	return s
 }
 
+// Currency returns the currency the subscription bills in, or "" when it
+// bills in its organization's currency.
+func (s *Subscription) Currency() string {
+	if s.DefaultCurrency == nil {
+		return ""
+	}
+	return *s.DefaultCurrency
+}
+
 // IsTrial checks if the subscription is currently in a trial period.
 func (s *Subscription) IsTrial(now time.Time) bool {
 	return s.TrialEndsAt != nil && now.Before(*s.TrialEndsAt)
@@ -95,3 +105,21 @@ type SubscriptionItem struct {
 
 // TableName sets the database table name.
 func (SubscriptionItem) TableName() string { return "subscription_items" }
+
+// SubscriptionItemChange records the price, meter and quantity an item had
+// over an effective window. Rating splits charges by these windows when a
+// cycle overlaps any of them. EffectiveTo is nil while the version is open.
+type SubscriptionItemChange struct {
+	ID             snowflake.ID  `gorm:"primaryKey"`
+	OrgID          snowflake.ID  `gorm:"not null;index"`
+	SubscriptionID snowflake.ID  `gorm:"not null;index"`
+	PriceID        snowflake.ID  `gorm:"not null"`
+	MeterID        *snowflake.ID `gorm:""`
+	Quantity       int8          `gorm:"not null"`
+	EffectiveFrom  time.Time     `gorm:"not null"`
+	EffectiveTo    *time.Time    `gorm:""`
+	CreatedAt      time.Time     `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName sets the database table name.
+func (SubscriptionItemChange) TableName() string { return "subscription_item_changes" }
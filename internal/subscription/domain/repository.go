@@ -21,5 +21,10 @@ type Repository interface {
 	FindSubscriptionItemByMeterID(ctx context.Context, db *gorm.DB, orgID, subscriptionID, meterID snowflake.ID) (*SubscriptionItem, error)
 	FindSubscriptionItemByMeterIDAt(ctx context.Context, db *gorm.DB, orgID, subscriptionID, meterID snowflake.ID, at time.Time) (*SubscriptionItem, error)
 	FindSubscriptionItemByMeterCode(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID, meterCode string) (*SubscriptionItem, error)
+	ListItems(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) ([]SubscriptionItem, error)
+	ListOpenItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) ([]SubscriptionItemChange, error)
+	InsertItemChanges(ctx context.Context, db *gorm.DB, changes []SubscriptionItemChange) error
+	CloseItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID, at time.Time) error
+	DeleteOpenItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) error
 	FindEntitlement(ctx context.Context, db *gorm.DB, subscriptionID snowflake.ID, meterID snowflake.ID, at time.Time) (*SubscriptionEntitlement, error)
 }
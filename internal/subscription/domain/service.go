@@ -36,11 +36,27 @@ type CreateSubscriptionRequest struct {
 	Items            []CreateSubscriptionItemRequest `json:"items"`
 	TrialDays        *int                            `json:"trial_days,omitempty"`
 	Metadata         map[string]any                  `json:"metadata,omitempty"`
+	// Currency bills the subscription in an ISO 4217 currency other than the
+	// organization's. Every item's price must have an amount in it.
+	Currency string `json:"currency,omitempty"`
 }
 
+// ProrationBehavior controls how an item change made mid-cycle is charged.
+type ProrationBehavior string
+
+const (
+	// ProrationBehaviorNone bills the new items as if they had been in place
+	// since the last recorded change.
+	ProrationBehaviorNone ProrationBehavior = "none"
+	// ProrationBehaviorCreateProrations charges the old and new items for
+	// the part of the cycle each was effective.
+	ProrationBehaviorCreateProrations ProrationBehavior = "create_prorations"
+)
+
 type ReplaceSubscriptionItemsRequest struct {
-	SubscriptionID string                          `json:"subscription_id"`
-	Items          []CreateSubscriptionItemRequest `json:"items"`
+	SubscriptionID    string                          `json:"subscription_id"`
+	Items             []CreateSubscriptionItemRequest `json:"items"`
+	ProrationBehavior ProrationBehavior               `json:"proration_behavior,omitempty"`
 }
 
 type GetActiveByCustomerIDRequest struct {
@@ -64,13 +80,16 @@ type Service interface {
 	GetActiveByCustomerID(context.Context, GetActiveByCustomerIDRequest) (Subscription, error)
 	GetSubscriptionItem(context.Context, GetSubscriptionItemRequest) (SubscriptionItem, error)
 	TransitionSubscription(ctx context.Context, subscriptionID string, targetStatus SubscriptionStatus, reason TransitionReason) error
+	PauseSubscription(ctx context.Context, subscriptionID string, resumeAt *time.Time) error
+	ResumeSubscription(ctx context.Context, subscriptionID string) error
 	ValidateUsageEntitlement(ctx context.Context, subscriptionID, meterID snowflake.ID, at time.Time) error
 	ChangePlan(ctx context.Context, req ChangePlanRequest) error
 }
 
 type ChangePlanRequest struct {
-	SubscriptionID string
-	NewProductID   string
+	SubscriptionID    string
+	NewProductID      string
+	ProrationBehavior ProrationBehavior
 }
 
 type CreateSubscriptionItemResponse struct {
@@ -95,12 +114,14 @@ type CreateSubscriptionResponse struct {
 	StartAt        time.Time                        `json:"start_at"`
 	Items          []CreateSubscriptionItemResponse `json:"items"`
 	Metadata       map[string]any                   `json:"metadata,omitempty"`
+	Currency       string                           `json:"currency,omitempty"`
 }
 
 var (
 	ErrInvalidOrganization       = errors.New("invalid_organization")
 	ErrInvalidCustomer           = errors.New("invalid_customer")
 	ErrInvalidTrialDays          = errors.New("invalid_trial_days")
+	ErrInvalidCurrency           = errors.New("invalid_currency")
 	ErrInvalidSubscription       = errors.New("invalid_subscription")
 	ErrInvalidMeterID            = errors.New("invalid_meter_id")
 	ErrUnsupportedPricingModel   = errors.New("unsupported_pricing_model")
@@ -127,4 +148,6 @@ var (
 	ErrSubscriptionItemNotFound  = errors.New("subscription_item_not_found")
 	ErrFeatureNotEntitled        = errors.New("feature_not_entitled")
 	ErrInvalidSubscriptionStatus = errors.New("invalid_subscription_status")
+	ErrInvalidResumeAt           = errors.New("invalid_resume_at")
+	ErrInvalidProrationBehavior  = errors.New("invalid_proration_behavior")
 )
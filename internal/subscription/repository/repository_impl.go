@@ -128,7 +128,7 @@ func (r *repo) FindByID(ctx context.Context, db *gorm.DB, orgID, id snowflake.ID
 	var subscription subscriptiondomain.Subscription
 	err := db.WithContext(ctx).Raw(
 		`SELECT id, org_id, customer_id, status, collection_mode, start_at, end_at, cancel_at,
-		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, ended_at,
+		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, resume_at, ended_at,
 		 billing_anchor_day, billing_cycle_type, default_payment_term_days, default_currency,
 		 default_tax_behavior, metadata, created_at, updated_at
 		 FROM subscriptions WHERE org_id = ? AND id = ?`,
@@ -148,7 +148,7 @@ func (r *repo) FindByIDForUpdate(ctx context.Context, db *gorm.DB, orgID, id sno
 	var subscription subscriptiondomain.Subscription
 	err := db.WithContext(ctx).Raw(
 		`SELECT id, org_id, customer_id, status, collection_mode, start_at, end_at, cancel_at,
-		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, ended_at,
+		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, resume_at, ended_at,
 		 billing_anchor_day, billing_cycle_type, default_payment_term_days, default_currency,
 		 default_tax_behavior, metadata, created_at, updated_at
 		 FROM subscriptions WHERE org_id = ? AND id = ? FOR UPDATE`,
@@ -168,7 +168,7 @@ func (r *repo) List(ctx context.Context, db *gorm.DB, orgID snowflake.ID) ([]sub
 	var subscriptions []subscriptiondomain.Subscription
 	err := db.WithContext(ctx).Raw(
 		`SELECT id, org_id, customer_id, status, collection_mode, start_at, end_at, cancel_at,
-		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, ended_at,
+		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, resume_at, ended_at,
 		 billing_anchor_day, billing_cycle_type, default_payment_term_days, default_currency,
 		 default_tax_behavior, metadata, created_at, updated_at
 		 FROM subscriptions WHERE org_id = ? ORDER BY created_at ASC`,
@@ -184,7 +184,7 @@ func (r *repo) FindActiveByCustomerID(ctx context.Context, db *gorm.DB, orgID, c
 	var subscription subscriptiondomain.Subscription
 	err := db.WithContext(ctx).Raw(
 		`SELECT id, org_id, customer_id, status, collection_mode, start_at, end_at, cancel_at,
-		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, ended_at,
+		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, resume_at, ended_at,
 		 billing_anchor_day, billing_cycle_type, default_payment_term_days, default_currency,
 		 default_tax_behavior, metadata, created_at, updated_at
 		 FROM subscriptions
@@ -208,7 +208,7 @@ func (r *repo) FindActiveByCustomerIDAt(ctx context.Context, db *gorm.DB, orgID,
 	var subscription subscriptiondomain.Subscription
 	err := db.WithContext(ctx).Raw(
 		`SELECT id, org_id, customer_id, status, collection_mode, start_at, end_at, cancel_at,
-		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, ended_at,
+		 cancel_at_period_end, canceled_at, activated_at, paused_at, resumed_at, resume_at, ended_at,
 		 billing_anchor_day, billing_cycle_type, default_payment_term_days, default_currency,
 		 default_tax_behavior, metadata, created_at, updated_at
 		 FROM subscriptions
@@ -313,6 +313,84 @@ func (r *repo) FindSubscriptionItemByMeterIDAt(ctx context.Context, db *gorm.DB,
 	return &item, nil
 }
 
+func (r *repo) ListItems(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) ([]subscriptiondomain.SubscriptionItem, error) {
+	var items []subscriptiondomain.SubscriptionItem
+	err := db.WithContext(ctx).Raw(
+		`SELECT id, org_id, subscription_id, price_id, price_code, meter_id, meter_code, quantity,
+		 billing_mode, usage_behavior, billing_threshold, proration_behavior, next_period_start,
+		 next_period_end, metadata, created_at, updated_at
+		 FROM subscription_items
+		 WHERE org_id = ? AND subscription_id = ?
+		 ORDER BY created_at ASC, id ASC`,
+		orgID,
+		subscriptionID,
+	).Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *repo) ListOpenItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) ([]subscriptiondomain.SubscriptionItemChange, error) {
+	var changes []subscriptiondomain.SubscriptionItemChange
+	err := db.WithContext(ctx).Raw(
+		`SELECT id, org_id, subscription_id, price_id, meter_id, quantity,
+		 effective_from, effective_to, created_at
+		 FROM subscription_item_changes
+		 WHERE org_id = ? AND subscription_id = ? AND effective_to IS NULL
+		 ORDER BY effective_from ASC, id ASC`,
+		orgID,
+		subscriptionID,
+	).Scan(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *repo) InsertItemChanges(ctx context.Context, db *gorm.DB, changes []subscriptiondomain.SubscriptionItemChange) error {
+	for _, change := range changes {
+		if err := db.WithContext(ctx).Exec(
+			`INSERT INTO subscription_item_changes (
+				id, org_id, subscription_id, price_id, meter_id, quantity,
+				effective_from, effective_to, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			change.ID,
+			change.OrgID,
+			change.SubscriptionID,
+			change.PriceID,
+			change.MeterID,
+			change.Quantity,
+			change.EffectiveFrom,
+			change.EffectiveTo,
+			change.CreatedAt,
+		).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repo) CloseItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID, at time.Time) error {
+	return db.WithContext(ctx).Exec(
+		`UPDATE subscription_item_changes
+		 SET effective_to = ?
+		 WHERE org_id = ? AND subscription_id = ? AND effective_to IS NULL`,
+		at,
+		orgID,
+		subscriptionID,
+	).Error
+}
+
+func (r *repo) DeleteOpenItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) error {
+	return db.WithContext(ctx).Exec(
+		`DELETE FROM subscription_item_changes
+		 WHERE org_id = ? AND subscription_id = ? AND effective_to IS NULL`,
+		orgID,
+		subscriptionID,
+	).Error
+}
+
 func (r *repo) FindEntitlement(ctx context.Context, db *gorm.DB, subscriptionID snowflake.ID, meterID snowflake.ID, at time.Time) (*subscriptiondomain.SubscriptionEntitlement, error) {
 	var entitlement subscriptiondomain.SubscriptionEntitlement
 	err := db.WithContext(ctx).Raw(
@@ -113,6 +113,31 @@ func (m *mockRepository) FindSubscriptionItemByMeterID(ctx context.Context, db *
 func (m *mockRepository) FindSubscriptionItemByMeterIDAt(ctx context.Context, db *gorm.DB, orgID, subscriptionID, meterID snowflake.ID, at time.Time) (*subscriptiondomain.SubscriptionItem, error) {
 	return nil, nil
 }
+func (m *mockRepository) ListItems(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) ([]subscriptiondomain.SubscriptionItem, error) {
+	var items []subscriptiondomain.SubscriptionItem
+	err := db.Where("org_id = ? AND subscription_id = ?", orgID, subscriptionID).Find(&items).Error
+	return items, err
+}
+func (m *mockRepository) ListOpenItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) ([]subscriptiondomain.SubscriptionItemChange, error) {
+	var changes []subscriptiondomain.SubscriptionItemChange
+	err := db.Where("org_id = ? AND subscription_id = ? AND effective_to IS NULL", orgID, subscriptionID).Order("effective_from").Find(&changes).Error
+	return changes, err
+}
+func (m *mockRepository) InsertItemChanges(ctx context.Context, db *gorm.DB, changes []subscriptiondomain.SubscriptionItemChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return db.Create(changes).Error
+}
+func (m *mockRepository) CloseItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID, at time.Time) error {
+	return db.Model(&subscriptiondomain.SubscriptionItemChange{}).
+		Where("org_id = ? AND subscription_id = ? AND effective_to IS NULL", orgID, subscriptionID).
+		Update("effective_to", at).Error
+}
+func (m *mockRepository) DeleteOpenItemChanges(ctx context.Context, db *gorm.DB, orgID, subscriptionID snowflake.ID) error {
+	return db.Where("org_id = ? AND subscription_id = ? AND effective_to IS NULL", orgID, subscriptionID).
+		Delete(&subscriptiondomain.SubscriptionItemChange{}).Error
+}
 func (m *mockRepository) FindEntitlement(ctx context.Context, db *gorm.DB, subscriptionID snowflake.ID, meterID snowflake.ID, at time.Time) (*subscriptiondomain.SubscriptionEntitlement, error) {
 	return nil, nil
 }
@@ -127,6 +152,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&subscriptiondomain.Subscription{},
 		&subscriptiondomain.SubscriptionItem{},
 		&subscriptiondomain.SubscriptionEntitlement{},
+		&subscriptiondomain.SubscriptionItemChange{},
 	)
 	if err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
@@ -282,6 +308,105 @@ func TestChangePlan(t *testing.T) {
 	}
 }
 
+func TestChangePlan_CreateProrations(t *testing.T) {
+	db := setupTestDB(t)
+	node, _ := snowflake.NewNode(1)
+	repo := &mockRepository{
+		subscriptions: make(map[string]*subscriptiondomain.Subscription),
+	}
+
+	orgID := node.Generate()
+	oldPriceID := node.Generate()
+	newProductID := node.Generate()
+	newPriceID := node.Generate()
+
+	svc := NewService(ServiceParam{
+		DB:    db,
+		Log:   zap.NewNop(),
+		GenID: node,
+		Clock: &mockClock{},
+		Repo:  repo,
+		Pricesvc: &mockPriceService{
+			prices: []pricedomain.Response{
+				{
+					ID:              newPriceID,
+					OrganizationID:  orgID,
+					ProductID:       newProductID,
+					BillingInterval: pricedomain.Month,
+					Active:          true,
+					PricingModel:    pricedomain.Flat,
+					BillingMode:     pricedomain.Licensed,
+				},
+			},
+		},
+		ProductFeatureRepo: &mockProductFeatureRepo{
+			features: []productfeaturedomain.FeatureAssignment{
+				{FeatureID: node.Generate(), ProductID: newProductID, Code: "seats", FeatureType: "boolean", Active: true},
+			},
+		},
+		PriceAmountsvc: &mockPriceAmountService{},
+	})
+
+	subID := node.Generate()
+	now := time.Now().UTC()
+	repo.Insert(context.Background(), db, &subscriptiondomain.Subscription{
+		ID:               subID,
+		OrgID:            orgID,
+		CustomerID:       node.Generate(),
+		Status:           subscriptiondomain.SubscriptionStatusActive,
+		BillingCycleType: "monthly",
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	})
+	oldCreatedAt := now.Add(-24 * time.Hour)
+	repo.InsertItems(context.Background(), db, []subscriptiondomain.SubscriptionItem{
+		{
+			ID:             node.Generate(),
+			OrgID:          orgID,
+			SubscriptionID: subID,
+			PriceID:        oldPriceID,
+			Quantity:       3,
+			BillingMode:    string(pricedomain.Licensed),
+			CreatedAt:      oldCreatedAt,
+		},
+	})
+
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	if err := svc.ChangePlan(ctx, subscriptiondomain.ChangePlanRequest{
+		SubscriptionID:    subID.String(),
+		NewProductID:      newProductID.String(),
+		ProrationBehavior: "bogus",
+	}); err != subscriptiondomain.ErrInvalidProrationBehavior {
+		t.Fatalf("expected invalid proration behavior, got %v", err)
+	}
+
+	if err := svc.ChangePlan(ctx, subscriptiondomain.ChangePlanRequest{
+		SubscriptionID:    subID.String(),
+		NewProductID:      newProductID.String(),
+		ProrationBehavior: subscriptiondomain.ProrationBehaviorCreateProrations,
+	}); err != nil {
+		t.Fatalf("ChangePlan failed: %v", err)
+	}
+
+	var changes []subscriptiondomain.SubscriptionItemChange
+	db.Where("subscription_id = ?", subID).Order("effective_from").Find(&changes)
+	if len(changes) != 2 {
+		t.Fatalf("expected the old and new item versions, got %d", len(changes))
+	}
+
+	old, next := changes[0], changes[1]
+	if old.PriceID != oldPriceID || old.Quantity != 3 || !old.EffectiveFrom.Equal(oldCreatedAt) {
+		t.Errorf("unexpected backfilled version: %+v", old)
+	}
+	if old.EffectiveTo == nil || !old.EffectiveTo.Equal(next.EffectiveFrom) {
+		t.Errorf("old version should close when the new one opens: %+v", old)
+	}
+	if next.PriceID != newPriceID || next.Quantity != 1 || next.EffectiveTo != nil {
+		t.Errorf("unexpected new version: %+v", next)
+	}
+}
+
 type mockClock struct{}
 
 func (m *mockClock) Now() time.Time { return time.Now().UTC() }
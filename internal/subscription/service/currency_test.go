@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	pricedomain "github.com/smallbiznis/railzway/internal/price/domain"
+	priceamountdomain "github.com/smallbiznis/railzway/internal/priceamount/domain"
+	productfeaturedomain "github.com/smallbiznis/railzway/internal/productfeature/domain"
+	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
+	"go.uber.org/zap"
+)
+
+// currencyPriceAmounts serves the amounts of a price in each of currencies.
+type currencyPriceAmounts struct {
+	mockPriceAmountService
+	currencies []string
+}
+
+func (m *currencyPriceAmounts) List(ctx context.Context, req priceamountdomain.ListPriceAmountRequest) ([]priceamountdomain.Response, error) {
+	amounts := make([]priceamountdomain.Response, 0, len(m.currencies))
+	for _, currency := range m.currencies {
+		amounts = append(amounts, priceamountdomain.Response{Currency: currency, UnitAmountCents: 1000})
+	}
+	return amounts, nil
+}
+
+func TestCreateSubscription_Currency(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.Exec(`CREATE TABLE products (id INTEGER PRIMARY KEY, org_id INTEGER, active BOOLEAN)`).Error; err != nil {
+		t.Fatalf("create products: %v", err)
+	}
+	node, _ := snowflake.NewNode(1)
+	orgID, productID, priceID := node.Generate(), node.Generate(), node.Generate()
+	if err := db.Exec(`INSERT INTO products (id, org_id, active) VALUES (?, ?, true)`, productID, orgID).Error; err != nil {
+		t.Fatalf("insert product: %v", err)
+	}
+
+	repo := &mockRepository{subscriptions: make(map[string]*subscriptiondomain.Subscription)}
+	svc := NewService(ServiceParam{
+		DB:    db,
+		Log:   zap.NewNop(),
+		GenID: node,
+		Clock: &mockClock{},
+		Repo:  repo,
+		Pricesvc: &mockPriceService{prices: []pricedomain.Response{{
+			ID:              priceID,
+			OrganizationID:  orgID,
+			ProductID:       productID,
+			BillingInterval: pricedomain.Month,
+			Active:          true,
+			PricingModel:    pricedomain.Flat,
+			BillingMode:     pricedomain.Licensed,
+		}}},
+		PriceAmountsvc: &currencyPriceAmounts{currencies: []string{"USD", "EUR"}},
+		ProductFeatureRepo: &mockProductFeatureRepo{features: []productfeaturedomain.FeatureAssignment{{
+			FeatureID:   node.Generate(),
+			ProductID:   productID,
+			Code:        "seats",
+			Name:        "Seats",
+			FeatureType: "boolean",
+			Active:      true,
+		}}},
+	})
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	create := func(currency string) (subscriptiondomain.CreateSubscriptionResponse, error) {
+		return svc.Create(ctx, subscriptiondomain.CreateSubscriptionRequest{
+			CustomerID:       node.Generate().String(),
+			CollectionMode:   subscriptiondomain.SendInvoice,
+			BillingCycleType: "monthly",
+			Items:            []subscriptiondomain.CreateSubscriptionItemRequest{{PriceID: priceID.String(), Quantity: 1}},
+			Currency:         currency,
+		})
+	}
+
+	t.Run("bills in the requested currency", func(t *testing.T) {
+		resp, err := create(" eur ")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if resp.Currency != "EUR" {
+			t.Fatalf("expected EUR in the response, got %q", resp.Currency)
+		}
+		if got := repo.subscriptions[resp.ID].Currency(); got != "EUR" {
+			t.Fatalf("expected EUR to be stored, got %q", got)
+		}
+	})
+
+	t.Run("defaults to the organization currency", func(t *testing.T) {
+		resp, err := create("")
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if repo.subscriptions[resp.ID].DefaultCurrency != nil {
+			t.Fatalf("expected no currency override, got %q", *repo.subscriptions[resp.ID].DefaultCurrency)
+		}
+	})
+
+	t.Run("rejects an unknown currency", func(t *testing.T) {
+		if _, err := create("EURO"); !errors.Is(err, subscriptiondomain.ErrInvalidCurrency) {
+			t.Fatalf("expected ErrInvalidCurrency, got %v", err)
+		}
+	})
+
+	t.Run("rejects a currency the price has no amount in", func(t *testing.T) {
+		if _, err := create("JPY"); !errors.Is(err, subscriptiondomain.ErrMissingPricing) {
+			t.Fatalf("expected ErrMissingPricing, got %v", err)
+		}
+	})
+}
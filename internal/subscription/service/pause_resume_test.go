@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/clock"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	"github.com/smallbiznis/railzway/internal/scheduler/guard"
+	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
+	"go.uber.org/zap"
+)
+
+func TestPauseResumeSubscription(t *testing.T) {
+	db := setupTestDB(t)
+	node, _ := snowflake.NewNode(1)
+	repo := &mockRepository{subscriptions: make(map[string]*subscriptiondomain.Subscription)}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	svc := NewService(ServiceParam{
+		DB:    db,
+		Log:   zap.NewNop(),
+		GenID: node,
+		Clock: clock.NewFakeClock(now),
+		Repo:  repo,
+	})
+
+	orgID := node.Generate()
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+	insert := func(status subscriptiondomain.SubscriptionStatus) snowflake.ID {
+		sub := &subscriptiondomain.Subscription{
+			ID:               node.Generate(),
+			OrgID:            orgID,
+			CustomerID:       node.Generate(),
+			Status:           status,
+			BillingCycleType: "monthly",
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		if err := repo.Insert(ctx, db, sub); err != nil {
+			t.Fatalf("insert subscription: %v", err)
+		}
+		return sub.ID
+	}
+	load := func(id snowflake.ID) subscriptiondomain.Subscription {
+		var sub subscriptiondomain.Subscription
+		if err := db.Where("id = ?", id).First(&sub).Error; err != nil {
+			t.Fatalf("load subscription: %v", err)
+		}
+		return sub
+	}
+
+	t.Run("pause and resume", func(t *testing.T) {
+		id := insert(subscriptiondomain.SubscriptionStatusActive)
+		resumeAt := now.AddDate(0, 0, 14)
+		if err := svc.PauseSubscription(ctx, id.String(), &resumeAt); err != nil {
+			t.Fatalf("pause: %v", err)
+		}
+		paused := load(id)
+		if paused.Status != subscriptiondomain.SubscriptionStatusPaused {
+			t.Fatalf("expected PAUSED, got %s", paused.Status)
+		}
+		if paused.PausedAt == nil || !paused.PausedAt.Equal(now) {
+			t.Fatalf("expected paused_at %v, got %v", now, paused.PausedAt)
+		}
+		if paused.ResumeAt == nil || !paused.ResumeAt.Equal(resumeAt) {
+			t.Fatalf("expected resume_at %v, got %v", resumeAt, paused.ResumeAt)
+		}
+
+		if err := svc.ResumeSubscription(ctx, id.String()); err != nil {
+			t.Fatalf("resume: %v", err)
+		}
+		resumed := load(id)
+		if resumed.Status != subscriptiondomain.SubscriptionStatusActive {
+			t.Fatalf("expected ACTIVE, got %s", resumed.Status)
+		}
+		if resumed.ResumedAt == nil || !resumed.ResumedAt.Equal(now) {
+			t.Fatalf("expected resumed_at %v, got %v", now, resumed.ResumedAt)
+		}
+		if resumed.ResumeAt != nil {
+			t.Fatalf("expected resume_at to be cleared, got %v", resumed.ResumeAt)
+		}
+	})
+
+	t.Run("ended subscription cannot be paused", func(t *testing.T) {
+		id := insert(subscriptiondomain.SubscriptionStatusEnded)
+		if err := svc.PauseSubscription(ctx, id.String(), nil); !errors.Is(err, guard.ErrSubscriptionNotPausable) {
+			t.Fatalf("expected %v, got %v", guard.ErrSubscriptionNotPausable, err)
+		}
+		if got := load(id).Status; got != subscriptiondomain.SubscriptionStatusEnded {
+			t.Fatalf("expected ENDED, got %s", got)
+		}
+	})
+
+	t.Run("resume requires a paused subscription", func(t *testing.T) {
+		id := insert(subscriptiondomain.SubscriptionStatusActive)
+		if err := svc.ResumeSubscription(ctx, id.String()); !errors.Is(err, guard.ErrSubscriptionNotPaused) {
+			t.Fatalf("expected %v, got %v", guard.ErrSubscriptionNotPaused, err)
+		}
+	})
+
+	t.Run("transition does not pause or resume", func(t *testing.T) {
+		active := insert(subscriptiondomain.SubscriptionStatusActive)
+		if err := svc.TransitionSubscription(ctx, active.String(), subscriptiondomain.SubscriptionStatusPaused, ""); !errors.Is(err, subscriptiondomain.ErrInvalidTransition) {
+			t.Fatalf("expected %v, got %v", subscriptiondomain.ErrInvalidTransition, err)
+		}
+		paused := insert(subscriptiondomain.SubscriptionStatusPaused)
+		if err := svc.TransitionSubscription(ctx, paused.String(), subscriptiondomain.SubscriptionStatusActive, ""); !errors.Is(err, subscriptiondomain.ErrInvalidTransition) {
+			t.Fatalf("expected %v, got %v", subscriptiondomain.ErrInvalidTransition, err)
+		}
+		if got := load(paused).Status; got != subscriptiondomain.SubscriptionStatusPaused {
+			t.Fatalf("expected PAUSED, got %s", got)
+		}
+	})
+
+	t.Run("resume_at must be in the future", func(t *testing.T) {
+		id := insert(subscriptiondomain.SubscriptionStatusActive)
+		past := now.Add(-time.Hour)
+		if err := svc.PauseSubscription(ctx, id.String(), &past); !errors.Is(err, subscriptiondomain.ErrInvalidResumeAt) {
+			t.Fatalf("expected %v, got %v", subscriptiondomain.ErrInvalidResumeAt, err)
+		}
+	})
+}
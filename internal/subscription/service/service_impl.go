@@ -13,9 +13,11 @@ import (
 	pricedomain "github.com/smallbiznis/railzway/internal/price/domain"
 	priceamount "github.com/smallbiznis/railzway/internal/priceamount/domain"
 	productfeaturedomain "github.com/smallbiznis/railzway/internal/productfeature/domain"
+	"github.com/smallbiznis/railzway/internal/scheduler/guard"
 	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
 	"github.com/smallbiznis/railzway/pkg/db/option"
 	"github.com/smallbiznis/railzway/pkg/db/pagination"
+	"github.com/smallbiznis/railzway/pkg/money"
 	"github.com/smallbiznis/railzway/pkg/repository"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -258,6 +260,11 @@ func (s *Service) Create(ctx context.Context, req subscriptiondomain.CreateSubsc
 		return subscriptiondomain.CreateSubscriptionResponse{}, err
 	}
 
+	currency, err := normalizeCurrency(req.Currency)
+	if err != nil {
+		return subscriptiondomain.CreateSubscriptionResponse{}, err
+	}
+
 	now := s.clock.Now()
 	subscription := subscriptiondomain.Subscription{
 		ID:               s.genID.Generate(),
@@ -273,8 +280,11 @@ func (s *Service) Create(ctx context.Context, req subscriptiondomain.CreateSubsc
 	if req.Metadata != nil {
 		subscription.Metadata = datatypes.JSONMap(req.Metadata)
 	}
+	if currency != "" {
+		subscription.DefaultCurrency = &currency
+	}
 
-	subscriptionItems, productIDs, err := s.buildSubscriptionItems(ctx, orgID, subscription.ID, req.Items, billingCycleType, now)
+	subscriptionItems, productIDs, err := s.buildSubscriptionItems(ctx, orgID, subscription.ID, req.Items, billingCycleType, currency, now)
 	if err != nil {
 		return subscriptiondomain.CreateSubscriptionResponse{}, err
 	}
@@ -323,6 +333,11 @@ func (s *Service) ReplaceItems(ctx context.Context, req subscriptiondomain.Repla
 		return subscriptiondomain.CreateSubscriptionResponse{}, subscriptiondomain.ErrInvalidItems
 	}
 
+	behavior, err := normalizeProrationBehavior(req.ProrationBehavior)
+	if err != nil {
+		return subscriptiondomain.CreateSubscriptionResponse{}, err
+	}
+
 	subscription, err := s.repo.FindByID(ctx, s.db, orgID, subscriptionID)
 	if err != nil {
 		return subscriptiondomain.CreateSubscriptionResponse{}, err
@@ -335,7 +350,7 @@ func (s *Service) ReplaceItems(ctx context.Context, req subscriptiondomain.Repla
 	}
 
 	now := time.Now().UTC()
-	subscriptionItems, productIDs, err := s.buildSubscriptionItems(ctx, orgID, subscriptionID, req.Items, subscription.BillingCycleType, now)
+	subscriptionItems, productIDs, err := s.buildSubscriptionItems(ctx, orgID, subscriptionID, req.Items, subscription.BillingCycleType, subscription.Currency(), now)
 	if err != nil {
 		return subscriptiondomain.CreateSubscriptionResponse{}, err
 	}
@@ -354,6 +369,9 @@ func (s *Service) ReplaceItems(ctx context.Context, req subscriptiondomain.Repla
 			return err
 		}
 
+		if err := s.recordItemChanges(ctx, tx, orgID, subscriptionID, subscriptionItems, behavior, now); err != nil {
+			return err
+		}
 		if err := s.repo.ReplaceItems(ctx, tx, orgID, subscriptionID, subscriptionItems); err != nil {
 			return err
 		}
@@ -401,6 +419,9 @@ func (s *Service) GetByID(ctx context.Context, id string) (subscriptiondomain.Su
 	return *item, nil
 }
 
+// TransitionSubscription activates, cancels or ends a subscription. It does
+// not pause or resume one, so an actor allowed to activate subscriptions
+// cannot resume a paused subscription through it.
 func (s *Service) TransitionSubscription(
 	ctx context.Context,
 	subscriptionID string,
@@ -451,11 +472,6 @@ func (s *Service) TransitionSubscription(
 					subscription.ActivatedAt = &now
 				}
 			}
-			if subscription.Status == subscriptiondomain.SubscriptionStatusPaused {
-				subscription.ResumedAt = &now
-			}
-		case subscriptiondomain.SubscriptionStatusPaused:
-			subscription.PausedAt = &now
 		case subscriptiondomain.SubscriptionStatusCanceled:
 			subscription.CanceledAt = &now
 		case subscriptiondomain.SubscriptionStatusEnded:
@@ -474,6 +490,88 @@ func (s *Service) TransitionSubscription(
 	})
 }
 
+// PauseSubscription stops an active subscription from opening new billing
+// cycles. When resumeAt is set, the scheduler resumes the subscription at that
+// time; otherwise it stays paused until resumed explicitly.
+func (s *Service) PauseSubscription(ctx context.Context, subscriptionID string, resumeAt *time.Time) error {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return subscriptiondomain.ErrInvalidOrganization
+	}
+
+	id, err := s.parseID(subscriptionID, subscriptiondomain.ErrInvalidSubscription)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now().UTC()
+	if resumeAt != nil {
+		at := resumeAt.UTC()
+		if !at.After(now) {
+			return subscriptiondomain.ErrInvalidResumeAt
+		}
+		resumeAt = &at
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		subscription, err := s.repo.FindByIDForUpdate(ctx, tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if subscription == nil {
+			return subscriptiondomain.ErrSubscriptionNotFound
+		}
+		if err := guard.EnsureSubscriptionCanPause(subscription.Status); err != nil {
+			return err
+		}
+
+		if subscription.Status != subscriptiondomain.SubscriptionStatusPaused {
+			subscription.Status = subscriptiondomain.SubscriptionStatusPaused
+			subscription.PausedAt = &now
+		}
+		subscription.ResumeAt = resumeAt
+		subscription.UpdatedAt = now
+
+		return s.updateLifecycle(ctx, tx, subscription)
+	})
+}
+
+// ResumeSubscription reactivates a paused subscription. The scheduler opens
+// its next billing cycle from the resume time, so the paused period is not
+// billed.
+func (s *Service) ResumeSubscription(ctx context.Context, subscriptionID string) error {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return subscriptiondomain.ErrInvalidOrganization
+	}
+
+	id, err := s.parseID(subscriptionID, subscriptiondomain.ErrInvalidSubscription)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now().UTC()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		subscription, err := s.repo.FindByIDForUpdate(ctx, tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		if subscription == nil {
+			return subscriptiondomain.ErrSubscriptionNotFound
+		}
+		if err := guard.EnsureSubscriptionCanResume(subscription.Status); err != nil {
+			return err
+		}
+
+		subscription.Status = subscriptiondomain.SubscriptionStatusActive
+		subscription.ResumedAt = &now
+		subscription.ResumeAt = nil
+		subscription.UpdatedAt = now
+
+		return s.updateLifecycle(ctx, tx, subscription)
+	})
+}
+
 func (s *Service) parseID(value string, invalidErr error) (snowflake.ID, error) {
 	id, err := snowflake.ParseString(strings.TrimSpace(value))
 	if err != nil || id == 0 {
@@ -545,12 +643,13 @@ func (s *Service) validateEnd(ctx context.Context, tx *gorm.DB, subscription *su
 func (s *Service) updateLifecycle(ctx context.Context, tx *gorm.DB, subscription *subscriptiondomain.Subscription) error {
 	return tx.WithContext(ctx).Exec(
 		`UPDATE subscriptions
-		 SET status = ?, activated_at = ?, paused_at = ?, resumed_at = ?, canceled_at = ?, ended_at = ?, updated_at = ?
+		 SET status = ?, activated_at = ?, paused_at = ?, resumed_at = ?, resume_at = ?, canceled_at = ?, ended_at = ?, updated_at = ?
 		 WHERE org_id = ? AND id = ?`,
 		subscription.Status,
 		subscription.ActivatedAt,
 		subscription.PausedAt,
 		subscription.ResumedAt,
+		subscription.ResumeAt,
 		subscription.CanceledAt,
 		subscription.EndedAt,
 		subscription.UpdatedAt,
@@ -616,6 +715,88 @@ func (s *Service) closeActiveEntitlements(ctx context.Context, tx *gorm.DB, subs
 	return nil
 }
 
+// recordItemChanges versions the item set being replaced by next. With
+// create_prorations the open versions are closed at now, and the items in
+// place before any version was recorded are backfilled from their creation,
+// so rating charges both sides of the change. With none the open versions,
+// if any, are swapped for next without splitting the window.
+func (s *Service) recordItemChanges(
+	ctx context.Context,
+	tx *gorm.DB,
+	orgID, subscriptionID snowflake.ID,
+	next []subscriptiondomain.SubscriptionItem,
+	behavior subscriptiondomain.ProrationBehavior,
+	now time.Time,
+) error {
+	open, err := s.repo.ListOpenItemChanges(ctx, tx, orgID, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	effectiveFrom := now
+	switch behavior {
+	case subscriptiondomain.ProrationBehaviorCreateProrations:
+		if len(open) == 0 {
+			current, err := s.repo.ListItems(ctx, tx, orgID, subscriptionID)
+			if err != nil {
+				return err
+			}
+			closedAt := now
+			backfill := make([]subscriptiondomain.SubscriptionItemChange, 0, len(current))
+			for _, item := range current {
+				if !now.After(item.CreatedAt) {
+					continue
+				}
+				backfill = append(backfill, s.newItemChange(item, item.CreatedAt, &closedAt, now))
+			}
+			if err := s.repo.InsertItemChanges(ctx, tx, backfill); err != nil {
+				return err
+			}
+		} else if err := s.repo.CloseItemChanges(ctx, tx, orgID, subscriptionID, now); err != nil {
+			return err
+		}
+	default:
+		if len(open) == 0 {
+			return nil
+		}
+		effectiveFrom = open[0].EffectiveFrom
+		if err := s.repo.DeleteOpenItemChanges(ctx, tx, orgID, subscriptionID); err != nil {
+			return err
+		}
+	}
+
+	changes := make([]subscriptiondomain.SubscriptionItemChange, 0, len(next))
+	for _, item := range next {
+		changes = append(changes, s.newItemChange(item, effectiveFrom, nil, now))
+	}
+	return s.repo.InsertItemChanges(ctx, tx, changes)
+}
+
+func (s *Service) newItemChange(item subscriptiondomain.SubscriptionItem, from time.Time, to *time.Time, now time.Time) subscriptiondomain.SubscriptionItemChange {
+	return subscriptiondomain.SubscriptionItemChange{
+		ID:             s.genID.Generate(),
+		OrgID:          item.OrgID,
+		SubscriptionID: item.SubscriptionID,
+		PriceID:        item.PriceID,
+		MeterID:        item.MeterID,
+		Quantity:       normalizeSubscriptionQuantity(item.Quantity),
+		EffectiveFrom:  from,
+		EffectiveTo:    to,
+		CreatedAt:      now,
+	}
+}
+
+func normalizeProrationBehavior(value subscriptiondomain.ProrationBehavior) (subscriptiondomain.ProrationBehavior, error) {
+	switch subscriptiondomain.ProrationBehavior(strings.ToLower(strings.TrimSpace(string(value)))) {
+	case "", subscriptiondomain.ProrationBehaviorNone:
+		return subscriptiondomain.ProrationBehaviorNone, nil
+	case subscriptiondomain.ProrationBehaviorCreateProrations:
+		return subscriptiondomain.ProrationBehaviorCreateProrations, nil
+	default:
+		return "", subscriptiondomain.ErrInvalidProrationBehavior
+	}
+}
+
 func (s *Service) countSubscriptionItemsWithMeter(ctx context.Context, tx *gorm.DB, orgID, subscriptionID snowflake.ID) (int64, error) {
 	var count int64
 	if err := tx.WithContext(ctx).Raw(
@@ -710,10 +891,10 @@ func isTransitionAllowed(current, target subscriptiondomain.SubscriptionStatus)
 	switch current {
 	case subscriptiondomain.SubscriptionStatusDraft:
 		return target == subscriptiondomain.SubscriptionStatusActive
-	case subscriptiondomain.SubscriptionStatusActive:
-		return target == subscriptiondomain.SubscriptionStatusPaused || target == subscriptiondomain.SubscriptionStatusCanceled
-	case subscriptiondomain.SubscriptionStatusPaused:
-		return target == subscriptiondomain.SubscriptionStatusActive || target == subscriptiondomain.SubscriptionStatusCanceled
+	case subscriptiondomain.SubscriptionStatusActive, subscriptiondomain.SubscriptionStatusPaused:
+		// Pausing and resuming go through PauseSubscription and
+		// ResumeSubscription, which their own permissions guard.
+		return target == subscriptiondomain.SubscriptionStatusCanceled
 	case subscriptiondomain.SubscriptionStatusCanceled:
 		return target == subscriptiondomain.SubscriptionStatusEnded
 	default:
@@ -794,6 +975,7 @@ func (s *Service) buildSubscriptionItems(
 	subscriptionID snowflake.ID,
 	items []subscriptiondomain.CreateSubscriptionItemRequest,
 	expectedCycleType string,
+	currency string,
 	now time.Time,
 ) ([]subscriptiondomain.SubscriptionItem, []snowflake.ID, error) {
 	priceCache := make(map[string]*pricedomain.Response, len(items))
@@ -839,6 +1021,12 @@ func (s *Service) buildSubscriptionItems(
 			return nil, nil, subscriptiondomain.ErrInvalidBillingCycleType
 		}
 
+		if currency != "" {
+			if err := s.ensurePriceCurrency(ctx, price.ID.String(), currency); err != nil {
+				return nil, nil, err
+			}
+		}
+
 		var (
 			meterID   *snowflake.ID
 			meterCode *string
@@ -992,6 +1180,33 @@ func (s *Service) loadPriceAmount(ctx context.Context, priceID string) ([]pricea
 	})
 }
 
+// ensurePriceCurrency rejects a price that cannot be billed in currency.
+func (s *Service) ensurePriceCurrency(ctx context.Context, priceID string, currency string) error {
+	amounts, err := s.loadPriceAmount(ctx, priceID)
+	if err != nil {
+		return err
+	}
+	for _, amount := range amounts {
+		if strings.EqualFold(amount.Currency, currency) {
+			return nil
+		}
+	}
+	return subscriptiondomain.ErrMissingPricing
+}
+
+// normalizeCurrency upper-cases a subscription currency override, leaving it
+// empty when none was requested.
+func normalizeCurrency(value string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(value))
+	if currency == "" {
+		return "", nil
+	}
+	if _, ok := money.LookupMinorUnits(currency); !ok {
+		return "", subscriptiondomain.ErrInvalidCurrency
+	}
+	return currency, nil
+}
+
 func validateSubscriptionPricingModel(price *pricedomain.Response, flatCount *int) error {
 	switch price.PricingModel {
 	case pricedomain.Flat:
@@ -1072,6 +1287,7 @@ func (s *Service) toCreateResponse(subscription *subscriptiondomain.Subscription
 		StartAt:        subscription.StartAt,
 		Items:          respItems,
 		Metadata:       metadata,
+		Currency:       subscription.Currency(),
 	}
 }
 
@@ -25,6 +25,11 @@ func (s *Service) ChangePlan(ctx context.Context, req subscriptiondomain.ChangeP
 		return subscriptiondomain.ErrInvalidProduct
 	}
 
+	behavior, err := normalizeProrationBehavior(req.ProrationBehavior)
+	if err != nil {
+		return err
+	}
+
 	if !isValidStatus(subscriptiondomain.SubscriptionStatusActive) {
 		return subscriptiondomain.ErrInvalidSubscriptionStatus
 	}
@@ -67,7 +72,7 @@ func (s *Service) ChangePlan(ctx context.Context, req subscriptiondomain.ChangeP
 		}
 
 		// buildSubscriptionItems does not take tx, uses service db/cache, which is safe for read-only static data (prices/meters)
-		subscriptionItems, _, err := s.buildSubscriptionItems(ctx, orgID, subscriptionID, itemReqs, cycleType, now)
+		subscriptionItems, _, err := s.buildSubscriptionItems(ctx, orgID, subscriptionID, itemReqs, cycleType, sub.Currency(), now)
 		if err != nil {
 			return err
 		}
@@ -84,7 +89,10 @@ func (s *Service) ChangePlan(ctx context.Context, req subscriptiondomain.ChangeP
 			return err
 		}
 
-		// Replace items
+		// Version the item set, then replace items
+		if err := s.recordItemChanges(ctx, tx, orgID, subscriptionID, subscriptionItems, behavior, now); err != nil {
+			return err
+		}
 		if err := s.repo.ReplaceItems(ctx, tx, orgID, subscriptionID, subscriptionItems); err != nil {
 			return err
 		}
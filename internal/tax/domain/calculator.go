@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+// TaxCalculator computes the tax lines for an invoice at generation time.
+// The flat-rate calculator is the built-in implementation; an external
+// provider (Avalara, Stripe Tax, ...) plugs in by implementing this
+// interface. Returning no lines leaves the invoice to the org's tax
+// definition at finalization.
+type TaxCalculator interface {
+	CalculateTax(ctx context.Context, lines []TaxableLine, customer TaxContext) ([]TaxLine, error)
+}
+
+// TaxBehavior mirrors a price's tax_behavior: whether its amount excludes or
+// already includes tax.
+type TaxBehavior string
+
+const (
+	TaxBehaviorExclusive TaxBehavior = "EXCLUSIVE"
+	TaxBehaviorInclusive TaxBehavior = "INCLUSIVE"
+)
+
+// TaxableLine is one invoice line, in minor units.
+type TaxableLine struct {
+	Reference   string
+	Description string
+	Amount      int64
+	TaxBehavior TaxBehavior
+}
+
+// TaxContext describes the customer being taxed. Country and Region are
+// upper-cased codes (e.g. "US" and "CA"), empty when unknown.
+type TaxContext struct {
+	OrgID      snowflake.ID
+	CustomerID snowflake.ID
+	Currency   string
+	Country    string
+	Region     string
+}
+
+// TaxLine is a tax applied to part of an invoice. TaxableAmount is the sum of
+// the lines it covers; Amount is the tax itself, in minor units. Inclusive
+// tax is already part of the taxable amount and does not add to the total.
+type TaxLine struct {
+	Code          string
+	Name          string
+	Mode          TaxMode
+	Rate          float64
+	TaxableAmount int64
+	Amount        int64
+}
@@ -9,5 +9,6 @@ import (
 var Module = fx.Module("tax.service",
 	fx.Provide(repository.NewRepository),
 	fx.Provide(service.NewResolver),
+	fx.Provide(service.NewFlatRateCalculator),
 	fx.Provide(service.NewService),
 )
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/smallbiznis/railzway/internal/config"
+	taxdomain "github.com/smallbiznis/railzway/internal/tax/domain"
+	"go.uber.org/fx"
+)
+
+type flatRateParam struct {
+	fx.In
+
+	BillingConfig *config.BillingConfigHolder `optional:"true"`
+}
+
+type flatRateCalculator struct {
+	billingCfg *config.BillingConfigHolder
+}
+
+// NewFlatRateCalculator returns a TaxCalculator applying the billing.tax
+// rates configured for the customer's country or region. Rates are read on
+// every call, so billing.yml reloads apply to the next invoice.
+func NewFlatRateCalculator(p flatRateParam) taxdomain.TaxCalculator {
+	return &flatRateCalculator{billingCfg: p.BillingConfig}
+}
+
+func (c *flatRateCalculator) CalculateTax(ctx context.Context, lines []taxdomain.TaxableLine, customer taxdomain.TaxContext) ([]taxdomain.TaxLine, error) {
+	rate, ok := matchTaxRate(c.billingCfg.Get().Tax.Rates, customer.Country, customer.Region)
+	if !ok || rate.Rate <= 0 {
+		return nil, nil
+	}
+
+	// Lines are summed per mode and rounded once, so the tax matches what a
+	// single rate over the invoice subtotal would give.
+	var exclusive, inclusive int64
+	for _, line := range lines {
+		if line.Amount <= 0 {
+			continue
+		}
+		if line.TaxBehavior == taxdomain.TaxBehaviorInclusive {
+			inclusive += line.Amount
+		} else {
+			exclusive += line.Amount
+		}
+	}
+
+	out := make([]taxdomain.TaxLine, 0, 2)
+	if exclusive > 0 {
+		out = append(out, flatTaxLine(rate, taxdomain.TaxModeExclusive, exclusive, computeTaxExclusive(exclusive, &rate.Rate)))
+	}
+	if inclusive > 0 {
+		out = append(out, flatTaxLine(rate, taxdomain.TaxModeInclusive, inclusive, computeTaxInclusive(inclusive, &rate.Rate)))
+	}
+	return out, nil
+}
+
+// matchTaxRate picks the rate for a country and region, preferring one set
+// for the region over the country-wide rate.
+func matchTaxRate(rates []config.TaxRate, country, region string) (config.TaxRate, bool) {
+	if country == "" {
+		return config.TaxRate{}, false
+	}
+	var countryRate *config.TaxRate
+	for i := range rates {
+		if !strings.EqualFold(strings.TrimSpace(rates[i].Country), country) {
+			continue
+		}
+		rateRegion := strings.TrimSpace(rates[i].Region)
+		if rateRegion == "" {
+			if countryRate == nil {
+				countryRate = &rates[i]
+			}
+			continue
+		}
+		if region != "" && strings.EqualFold(rateRegion, region) {
+			return rates[i], true
+		}
+	}
+	if countryRate == nil {
+		return config.TaxRate{}, false
+	}
+	return *countryRate, true
+}
+
+func flatTaxLine(rate config.TaxRate, mode taxdomain.TaxMode, taxable, amount int64) taxdomain.TaxLine {
+	name := strings.TrimSpace(rate.Name)
+	if name == "" {
+		name = rate.Code
+	}
+	return taxdomain.TaxLine{
+		Code:          strings.TrimSpace(rate.Code),
+		Name:          name,
+		Mode:          mode,
+		Rate:          rate.Rate,
+		TaxableAmount: taxable,
+		Amount:        amount,
+	}
+}
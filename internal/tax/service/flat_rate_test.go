@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallbiznis/railzway/internal/config"
+	taxdomain "github.com/smallbiznis/railzway/internal/tax/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatRateCalculator(t *testing.T) {
+	cfg := config.DefaultBillingConfig()
+	cfg.Tax.Rates = []config.TaxRate{
+		{Country: "US", Region: "CA", Code: taxdomain.TaxCodeUSSalesTax, Name: "California sales tax", Rate: 0.0725},
+		{Country: "US", Code: taxdomain.TaxCodeUSSalesTax, Name: "Sales tax", Rate: 0.05},
+		{Country: "DE", Code: taxdomain.TaxCodeEUVATStandard, Rate: 0.19},
+	}
+	calc := NewFlatRateCalculator(flatRateParam{BillingConfig: config.NewStaticBillingConfigHolder(cfg)})
+	ctx := context.Background()
+
+	lines := []taxdomain.TaxableLine{
+		{Amount: 10000, TaxBehavior: taxdomain.TaxBehaviorExclusive},
+		{Amount: 11900, TaxBehavior: taxdomain.TaxBehaviorInclusive},
+		{Amount: -500, TaxBehavior: taxdomain.TaxBehaviorExclusive},
+	}
+
+	t.Run("region rate wins", func(t *testing.T) {
+		out, err := calc.CalculateTax(ctx, lines[:1], taxdomain.TaxContext{Country: "US", Region: "CA"})
+		require.NoError(t, err)
+		require.Len(t, out, 1)
+		assert.Equal(t, "California sales tax", out[0].Name)
+		assert.Equal(t, int64(725), out[0].Amount)
+	})
+
+	t.Run("country rate for other regions", func(t *testing.T) {
+		out, err := calc.CalculateTax(ctx, lines[:1], taxdomain.TaxContext{Country: "US", Region: "NY"})
+		require.NoError(t, err)
+		require.Len(t, out, 1)
+		assert.Equal(t, int64(500), out[0].Amount)
+	})
+
+	t.Run("exclusive and inclusive lines", func(t *testing.T) {
+		out, err := calc.CalculateTax(ctx, lines, taxdomain.TaxContext{Country: "DE"})
+		require.NoError(t, err)
+		require.Len(t, out, 2)
+		assert.Equal(t, taxdomain.TaxModeExclusive, out[0].Mode)
+		assert.Equal(t, int64(10000), out[0].TaxableAmount)
+		assert.Equal(t, int64(1900), out[0].Amount)
+		assert.Equal(t, taxdomain.TaxModeInclusive, out[1].Mode)
+		assert.Equal(t, int64(1900), out[1].Amount)
+		assert.Equal(t, taxdomain.TaxCodeEUVATStandard, out[1].Name, "name falls back to the code")
+	})
+
+	t.Run("no matching rate", func(t *testing.T) {
+		for _, customer := range []taxdomain.TaxContext{{Country: "FR"}, {}} {
+			out, err := calc.CalculateTax(ctx, lines, customer)
+			require.NoError(t, err)
+			assert.Empty(t, out)
+		}
+	})
+}
@@ -23,6 +23,34 @@ type CreateIngestRequest struct {
 	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
+// MaxIngestBatchSize is the largest number of events IngestBatch accepts in
+// one call.
+const MaxIngestBatchSize = 500
+
+// Outcomes of a single event in a batch.
+const (
+	BatchItemAccepted  = "accepted"
+	BatchItemDuplicate = "duplicate"
+	BatchItemRejected  = "rejected"
+)
+
+// BatchIngestResult is the outcome of one event of a batch. Index is the
+// event's position in the request; Error holds the rejection code.
+type BatchIngestResult struct {
+	Index          int         `json:"index"`
+	IdempotencyKey string      `json:"idempotency_key"`
+	Status         string      `json:"status"`
+	Error          string      `json:"error,omitempty"`
+	UsageEvent     *UsageEvent `json:"usage_event,omitempty"`
+}
+
+type BatchIngestResponse struct {
+	Accepted   int                 `json:"accepted"`
+	Duplicates int                 `json:"duplicates"`
+	Rejected   int                 `json:"rejected"`
+	Results    []BatchIngestResult `json:"results"`
+}
+
 type ListUsageRequest struct {
 	CustomerID     string `json:"customer_id"`
 	SubscriptionID string `json:"subscription_id"`
@@ -36,9 +64,59 @@ type ListUsageResponse struct {
 	UsageEvents []UsageEvent `json:"usage_events"`
 }
 
+// Usage query granularities.
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+// MaxUsageQueryPageSize is the largest number of buckets GetUsage returns
+// in one page.
+const MaxUsageQueryPageSize = 1000
+
+// GetUsageRequest selects one customer's usage of one meter in [From, To).
+// PageSize counts buckets; a page token continues at the first bucket the
+// previous page did not cover.
+type GetUsageRequest struct {
+	CustomerID  string    `json:"customer_id"`
+	MeterCode   string    `json:"meter_code"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Granularity string    `json:"granularity"`
+	PageToken   string    `json:"page_token"`
+	PageSize    int32     `json:"page_size"`
+}
+
+// UsagePoint aggregates the events recorded in one bucket. Value applies
+// the meter's aggregation type, so it matches what rating would bill for
+// the bucket; buckets without events are returned with zero values.
+type UsagePoint struct {
+	BucketStart time.Time `json:"bucket_start"`
+	BucketEnd   time.Time `json:"bucket_end"`
+	Sum         float64   `json:"sum"`
+	Max         float64   `json:"max"`
+	Count       int64     `json:"count"`
+	Value       float64   `json:"value"`
+}
+
+type GetUsageResponse struct {
+	pagination.PageInfo
+	CustomerID  string       `json:"customer_id"`
+	MeterCode   string       `json:"meter_code"`
+	Aggregation string       `json:"aggregation"`
+	Granularity string       `json:"granularity"`
+	Points      []UsagePoint `json:"points"`
+}
+
 type Service interface {
 	Ingest(context.Context, CreateIngestRequest) (*UsageEvent, error)
+	// IngestBatch ingests each event like Ingest. A rejected event does not
+	// fail the batch; it is reported in its result instead.
+	IngestBatch(context.Context, []CreateIngestRequest) (BatchIngestResponse, error)
 	List(context.Context, ListUsageRequest) (ListUsageResponse, error)
+	// GetUsage aggregates a customer's usage of a meter into hourly or
+	// daily buckets, for charts and dispute resolution.
+	GetUsage(context.Context, GetUsageRequest) (GetUsageResponse, error)
 }
 
 var (
@@ -51,4 +129,10 @@ var (
 	ErrInvalidValue            = errors.New("invalid_value")
 	ErrInvalidRecordedAt       = errors.New("invalid_recorded_at")
 	ErrInvalidIdempotencyKey   = errors.New("invalid_idempotency_key")
+	ErrInvalidBatchSize        = errors.New("invalid_batch_size")
+	ErrInvalidGranularity      = errors.New("invalid_granularity")
+	ErrInvalidPeriod           = errors.New("invalid_period")
+	ErrInvalidPageToken        = errors.New("invalid_page_token")
+	ErrFeatureNotEntitled      = errors.New("usage_rejected_feature_not_entitled")
+	ErrGatingUnavailable       = errors.New("usage_ingestion_gating_unavailable")
 )
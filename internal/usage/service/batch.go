@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	usagedomain "github.com/smallbiznis/railzway/internal/usage/domain"
+	"github.com/smallbiznis/railzway/internal/usage/liveevents"
+	"gorm.io/gorm"
+)
+
+// ingestBatchChunkSize is the number of events inserted per transaction.
+const ingestBatchChunkSize = 100
+
+// batchRejections are the errors that reject a single event of a batch.
+// Anything else (database or gating failures) fails the whole batch.
+var batchRejections = []error{
+	usagedomain.ErrInvalidCustomer,
+	usagedomain.ErrInvalidSubscription,
+	usagedomain.ErrInvalidSubscriptionItem,
+	usagedomain.ErrInvalidMeter,
+	usagedomain.ErrInvalidMeterCode,
+	usagedomain.ErrInvalidValue,
+	usagedomain.ErrInvalidRecordedAt,
+	usagedomain.ErrInvalidIdempotencyKey,
+	usagedomain.ErrFeatureNotEntitled,
+}
+
+// IngestBatch validates every event first, then inserts the accepted ones in
+// transactions of ingestBatchChunkSize events. Events are deduplicated by
+// their own idempotency keys, both against stored events and within the
+// batch.
+func (s *Service) IngestBatch(ctx context.Context, reqs []usagedomain.CreateIngestRequest) (usagedomain.BatchIngestResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return usagedomain.BatchIngestResponse{}, usagedomain.ErrInvalidOrganization
+	}
+	if len(reqs) == 0 || len(reqs) > usagedomain.MaxIngestBatchSize {
+		return usagedomain.BatchIngestResponse{}, usagedomain.ErrInvalidBatchSize
+	}
+	if s.subSvc == nil {
+		return usagedomain.BatchIngestResponse{}, usagedomain.ErrGatingUnavailable
+	}
+
+	results := make([]usagedomain.BatchIngestResult, len(reqs))
+	records := make([]*usagedomain.UsageEvent, len(reqs))
+	pending := make([]int, 0, len(reqs))
+	for i, req := range reqs {
+		if err := ctx.Err(); err != nil {
+			return usagedomain.BatchIngestResponse{}, err
+		}
+		results[i] = usagedomain.BatchIngestResult{
+			Index:          i,
+			IdempotencyKey: normalizeIdempotencyKey(req.IdempotencyKey),
+		}
+		record, existing, err := s.prepareUsageEvent(ctx, orgID, req)
+		switch {
+		case err != nil:
+			if !isBatchRejection(err) {
+				return usagedomain.BatchIngestResponse{}, err
+			}
+			results[i].Status = usagedomain.BatchItemRejected
+			results[i].Error = err.Error()
+		case existing != nil:
+			s.emitLiveUsageEvent(existing, liveevents.StatusDeduplicated, liveevents.SourceAPI)
+			results[i].Status = usagedomain.BatchItemDuplicate
+			results[i].UsageEvent = existing
+		default:
+			records[i] = record
+			pending = append(pending, i)
+		}
+	}
+
+	for start := 0; start < len(pending); start += ingestBatchChunkSize {
+		chunk := pending[start:min(start+ingestBatchChunkSize, len(pending))]
+		inserted := make([]bool, len(chunk))
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for j, i := range chunk {
+				ok, err := s.insertUsageEvent(ctx, tx, records[i], records[i].IdempotencyKey)
+				if err != nil {
+					return err
+				}
+				inserted[j] = ok
+			}
+			return nil
+		})
+		if err != nil {
+			return usagedomain.BatchIngestResponse{}, err
+		}
+
+		for j, i := range chunk {
+			if inserted[j] {
+				s.recordUsageAccepted(ctx, records[i])
+				results[i].Status = usagedomain.BatchItemAccepted
+				results[i].UsageEvent = records[i]
+				continue
+			}
+			existing, err := s.findUsageEventByIdempotencyKey(ctx, orgID, records[i].IdempotencyKey)
+			if err != nil {
+				return usagedomain.BatchIngestResponse{}, err
+			}
+			if existing != nil {
+				s.emitLiveUsageEvent(existing, liveevents.StatusDeduplicated, liveevents.SourceAPI)
+			}
+			results[i].Status = usagedomain.BatchItemDuplicate
+			results[i].UsageEvent = existing
+		}
+	}
+
+	resp := usagedomain.BatchIngestResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case usagedomain.BatchItemAccepted:
+			resp.Accepted++
+		case usagedomain.BatchItemDuplicate:
+			resp.Duplicates++
+		default:
+			resp.Rejected++
+		}
+	}
+	return resp, nil
+}
+
+func isBatchRejection(err error) bool {
+	for _, rejection := range batchRejections {
+		if errors.Is(err, rejection) {
+			return true
+		}
+	}
+	return false
+}
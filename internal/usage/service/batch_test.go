@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/glebarez/sqlite"
+	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
+	subscriptiondomain "github.com/smallbiznis/railzway/internal/subscription/domain"
+	usagedomain "github.com/smallbiznis/railzway/internal/usage/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestIngestBatch_PartialFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&usagedomain.UsageEvent{}))
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX ux_usage_events_idempotency ON usage_events(org_id, idempotency_key)").Error)
+
+	node, _ := snowflake.NewNode(1)
+	orgID := node.Generate()
+	customerID := node.Generate()
+	subID := node.Generate()
+	meterID := node.Generate()
+
+	stored := usagedomain.UsageEvent{
+		ID:             node.Generate(),
+		OrgID:          orgID,
+		CustomerID:     customerID,
+		MeterCode:      "m1",
+		IdempotencyKey: "stored",
+		Status:         usagedomain.UsageStatusAccepted,
+		RecordedAt:     time.Now(),
+	}
+	require.NoError(t, db.Create(&stored).Error)
+
+	mockSub := new(subscriptionMock)
+	mockMeter := new(meterMock)
+	mockMeter.On("GetByCode", mock.Anything, "m1").Return(&meterdomain.Response{ID: meterID.String(), Code: "m1"}, nil)
+	mockMeter.On("GetByCode", mock.Anything, "unknown").Return(nil, meterdomain.ErrMeterNotFound)
+	mockSub.On("GetActiveByCustomerID", mock.Anything, mock.Anything).Return(subscriptiondomain.Subscription{ID: subID}, nil)
+	mockSub.On("ValidateUsageEntitlement", mock.Anything, subID, meterID, mock.Anything).Return(nil)
+
+	svc := NewService(ServiceParam{
+		DB:       db,
+		Log:      zap.NewNop(),
+		GenID:    node,
+		MeterSvc: mockMeter,
+		SubSvc:   mockSub,
+	})
+	ctx := WithTestOrgContext(context.Background(), orgID)
+
+	event := func(meterCode, key string) usagedomain.CreateIngestRequest {
+		return usagedomain.CreateIngestRequest{
+			CustomerID:     customerID.String(),
+			MeterCode:      meterCode,
+			Value:          1,
+			RecordedAt:     time.Now(),
+			IdempotencyKey: key,
+		}
+	}
+	resp, err := svc.IngestBatch(ctx, []usagedomain.CreateIngestRequest{
+		event("m1", "a"),
+		event("unknown", "b"),
+		event("m1", "a"),
+		event("m1", "stored"),
+		event("m1", "c"),
+	})
+	require.NoError(t, err)
+
+	statuses := make([]string, 0, len(resp.Results))
+	for i, result := range resp.Results {
+		assert.Equal(t, i, result.Index)
+		statuses = append(statuses, result.Status)
+	}
+	assert.Equal(t, []string{
+		usagedomain.BatchItemAccepted,
+		usagedomain.BatchItemRejected,
+		usagedomain.BatchItemDuplicate,
+		usagedomain.BatchItemDuplicate,
+		usagedomain.BatchItemAccepted,
+	}, statuses)
+	assert.Equal(t, usagedomain.ErrInvalidMeter.Error(), resp.Results[1].Error)
+	assert.Equal(t, resp.Results[0].UsageEvent.ID, resp.Results[2].UsageEvent.ID, "in-batch duplicates resolve to the first event")
+	assert.Equal(t, stored.ID, resp.Results[3].UsageEvent.ID)
+	assert.Equal(t, 2, resp.Accepted)
+	assert.Equal(t, 2, resp.Duplicates)
+	assert.Equal(t, 1, resp.Rejected)
+
+	var count int64
+	require.NoError(t, db.Model(&usagedomain.UsageEvent{}).Where("org_id = ?", orgID).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestIngestBatch_Size(t *testing.T) {
+	svc := NewService(ServiceParam{Log: zap.NewNop(), SubSvc: new(subscriptionMock)})
+	ctx := WithTestOrgContext(context.Background(), 1)
+
+	_, err := svc.IngestBatch(ctx, nil)
+	assert.ErrorIs(t, err, usagedomain.ErrInvalidBatchSize)
+
+	_, err = svc.IngestBatch(ctx, make([]usagedomain.CreateIngestRequest, usagedomain.MaxIngestBatchSize+1))
+	assert.ErrorIs(t, err, usagedomain.ErrInvalidBatchSize)
+}
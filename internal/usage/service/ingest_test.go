@@ -56,6 +56,12 @@ func (m *subscriptionMock) TransitionSubscription(ctx context.Context, id string
 func (m *subscriptionMock) ChangePlan(ctx context.Context, req subscriptiondomain.ChangePlanRequest) error {
 	return nil
 }
+func (m *subscriptionMock) PauseSubscription(ctx context.Context, id string, resumeAt *time.Time) error {
+	return nil
+}
+func (m *subscriptionMock) ResumeSubscription(ctx context.Context, id string) error {
+	return nil
+}
 
 type meterMock struct {
 	mock.Mock
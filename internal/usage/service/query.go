@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	usagedomain "github.com/smallbiznis/railzway/internal/usage/domain"
+	"github.com/smallbiznis/railzway/pkg/db/pagination"
+)
+
+const defaultUsageQueryPageSize = 168
+
+// usageBucketRow is one non-empty bucket read from usage_events. Bucket is
+// the bucket's index since the Unix epoch.
+type usageBucketRow struct {
+	Bucket    int64   `gorm:"column:bucket"`
+	SumValue  float64 `gorm:"column:sum_value"`
+	MaxValue  float64 `gorm:"column:max_value"`
+	Count     int64   `gorm:"column:event_count"`
+	LastValue float64 `gorm:"column:last_value"`
+}
+
+// GetUsage aggregates a customer's usage of a meter into hourly or daily
+// UTC buckets. Buckets are aligned to the granularity, so the first and last
+// bucket only count usage inside [From, To). Invalid and unmatched events
+// are left out, as they are never rated.
+func (s *Service) GetUsage(ctx context.Context, req usagedomain.GetUsageRequest) (usagedomain.GetUsageResponse, error) {
+	orgID, ok := orgcontext.OrgIDFromContext(ctx)
+	if !ok || orgID == 0 {
+		return usagedomain.GetUsageResponse{}, usagedomain.ErrInvalidOrganization
+	}
+
+	customerID, err := s.parseID(req.CustomerID, usagedomain.ErrInvalidCustomer)
+	if err != nil {
+		return usagedomain.GetUsageResponse{}, err
+	}
+	meterCode := strings.TrimSpace(req.MeterCode)
+	if meterCode == "" {
+		return usagedomain.GetUsageResponse{}, usagedomain.ErrInvalidMeterCode
+	}
+
+	granularity := strings.ToLower(strings.TrimSpace(req.Granularity))
+	if granularity == "" {
+		granularity = usagedomain.GranularityDay
+	}
+	var bucketSize time.Duration
+	switch granularity {
+	case usagedomain.GranularityHour:
+		bucketSize = time.Hour
+	case usagedomain.GranularityDay:
+		bucketSize = 24 * time.Hour
+	default:
+		return usagedomain.GetUsageResponse{}, usagedomain.ErrInvalidGranularity
+	}
+
+	from := req.From.UTC()
+	to := req.To.UTC()
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return usagedomain.GetUsageResponse{}, usagedomain.ErrInvalidPeriod
+	}
+
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultUsageQueryPageSize
+	}
+	if pageSize > usagedomain.MaxUsageQueryPageSize {
+		pageSize = usagedomain.MaxUsageQueryPageSize
+	}
+
+	pageStart := from.Truncate(bucketSize)
+	if strings.TrimSpace(req.PageToken) != "" {
+		pageStart, err = decodeUsagePageToken(req.PageToken, from, to, bucketSize)
+		if err != nil {
+			return usagedomain.GetUsageResponse{}, err
+		}
+	}
+	pageEnd := pageStart.Add(time.Duration(pageSize) * bucketSize)
+	hasMore := pageEnd.Before(to)
+	if !hasMore {
+		pageEnd = to
+	}
+
+	if err := s.ensureCustomerExists(ctx, orgID, customerID); err != nil {
+		return usagedomain.GetUsageResponse{}, err
+	}
+	meter, err := s.resolveMeter(ctx, orgID, meterCode)
+	if err != nil {
+		return usagedomain.GetUsageResponse{}, err
+	}
+	if meter == nil {
+		return usagedomain.GetUsageResponse{}, usagedomain.ErrInvalidMeter
+	}
+	meterID, err := s.parseID(meter.ID, usagedomain.ErrInvalidMeter)
+	if err != nil {
+		return usagedomain.GetUsageResponse{}, err
+	}
+	aggregation := meterdomain.NormalizeAggregation(meter.Aggregation)
+	if aggregation != meterdomain.AggregationMax && aggregation != meterdomain.AggregationLast {
+		aggregation = meterdomain.AggregationSum
+	}
+
+	rangeStart := pageStart
+	if rangeStart.Before(from) {
+		rangeStart = from
+	}
+	rows, err := s.queryUsageBuckets(ctx, orgID, customerID, meterID, rangeStart, pageEnd, bucketSize)
+	if err != nil {
+		return usagedomain.GetUsageResponse{}, err
+	}
+
+	resp := usagedomain.GetUsageResponse{
+		CustomerID:  customerID.String(),
+		MeterCode:   meterCode,
+		Aggregation: aggregation,
+		Granularity: granularity,
+		Points:      buildUsagePoints(rows, pageStart, pageEnd, bucketSize, aggregation),
+	}
+	if hasMore {
+		token, err := pagination.EncodeCursor(pagination.Cursor{CreatedAt: pageEnd.Format(time.RFC3339)})
+		if err != nil {
+			return usagedomain.GetUsageResponse{}, err
+		}
+		resp.NextPageToken = token
+		resp.HasMore = true
+	}
+	return resp, nil
+}
+
+// queryUsageBuckets aggregates the usage recorded in [start, end) per
+// bucket. The latest value of each bucket is picked with a window function
+// using the same ordering as rating's LAST aggregation.
+func (s *Service) queryUsageBuckets(ctx context.Context, orgID, customerID, meterID snowflake.ID, start, end time.Time, bucketSize time.Duration) ([]usageBucketRow, error) {
+	bucketExpr := `CAST(FLOOR(EXTRACT(EPOCH FROM recorded_at) / ?) AS BIGINT)`
+	if strings.EqualFold(s.db.Dialector.Name(), "sqlite") {
+		bucketExpr = `CAST(strftime('%s', recorded_at) AS INTEGER) / ?`
+	}
+	seconds := int64(bucketSize / time.Second)
+
+	var rows []usageBucketRow
+	err := s.db.WithContext(ctx).Raw(
+		`SELECT bucket,
+			SUM(value) AS sum_value,
+			MAX(value) AS max_value,
+			COUNT(*) AS event_count,
+			MAX(CASE WHEN rn = 1 THEN value END) AS last_value
+		 FROM (
+			SELECT `+bucketExpr+` AS bucket,
+				value,
+				ROW_NUMBER() OVER (
+					PARTITION BY `+bucketExpr+`
+					ORDER BY recorded_at DESC, id DESC
+				) AS rn
+			FROM usage_events
+			WHERE org_id = ? AND customer_id = ? AND meter_id = ?
+			AND recorded_at >= ? AND recorded_at < ?
+			AND status NOT IN (?, ?, ?)
+		 ) bucketed
+		 GROUP BY bucket
+		 ORDER BY bucket ASC`,
+		seconds,
+		seconds,
+		orgID,
+		customerID,
+		meterID,
+		start,
+		end,
+		usagedomain.UsageStatusInvalid,
+		usagedomain.UsageStatusUnmatchedMeter,
+		usagedomain.UsageStatusUnmatchedSubscription,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+// buildUsagePoints returns one point per bucket in [start, end), filling
+// buckets without events with zeros so charts keep a continuous axis.
+func buildUsagePoints(rows []usageBucketRow, start, end time.Time, bucketSize time.Duration, aggregation string) []usagedomain.UsagePoint {
+	seconds := int64(bucketSize / time.Second)
+	byBucket := make(map[int64]usageBucketRow, len(rows))
+	for _, row := range rows {
+		byBucket[row.Bucket] = row
+	}
+
+	points := make([]usagedomain.UsagePoint, 0, int(end.Sub(start)/bucketSize)+1)
+	for bucketStart := start; bucketStart.Before(end); bucketStart = bucketStart.Add(bucketSize) {
+		point := usagedomain.UsagePoint{
+			BucketStart: bucketStart,
+			BucketEnd:   bucketStart.Add(bucketSize),
+		}
+		if row, ok := byBucket[bucketStart.Unix()/seconds]; ok {
+			point.Sum = row.SumValue
+			point.Max = row.MaxValue
+			point.Count = row.Count
+			switch aggregation {
+			case meterdomain.AggregationMax:
+				point.Value = row.MaxValue
+			case meterdomain.AggregationLast:
+				point.Value = row.LastValue
+			default:
+				point.Value = row.SumValue
+			}
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// decodeUsagePageToken returns the bucket a page token continues at. The
+// token must point at a bucket boundary inside the queried range.
+func decodeUsagePageToken(token string, from, to time.Time, bucketSize time.Duration) (time.Time, error) {
+	cursor, err := pagination.DecodeCursor(strings.TrimSpace(token))
+	if err != nil {
+		return time.Time{}, usagedomain.ErrInvalidPageToken
+	}
+	start, err := time.Parse(time.RFC3339, cursor.CreatedAt)
+	if err != nil {
+		return time.Time{}, usagedomain.ErrInvalidPageToken
+	}
+	start = start.UTC()
+	if !start.Equal(start.Truncate(bucketSize)) || start.Before(from.Truncate(bucketSize)) || !start.Before(to) {
+		return time.Time{}, usagedomain.ErrInvalidPageToken
+	}
+	return start, nil
+}
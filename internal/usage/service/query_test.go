@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+	"github.com/smallbiznis/railzway/internal/cache"
+	meterdomain "github.com/smallbiznis/railzway/internal/meter/domain"
+	"github.com/smallbiznis/railzway/internal/orgcontext"
+	usagedomain "github.com/smallbiznis/railzway/internal/usage/domain"
+	"gorm.io/gorm"
+)
+
+func TestGetUsage(t *testing.T) {
+	node := mustNode(t)
+	orgID := node.Generate()
+	customerID := node.Generate()
+	meterID := node.Generate()
+
+	meter := &meterStub{
+		response: &meterdomain.Response{
+			ID:          meterID.String(),
+			Code:        "seats",
+			Aggregation: meterdomain.AggregationLast,
+		},
+	}
+	service, db := setupUsageService(t, node, meter, cache.NewUsageResolverCache(), orgID, customerID)
+	ctx := orgcontext.WithOrgID(context.Background(), int64(orgID))
+
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	insert := func(customer snowflake.ID, at time.Time, value float64, status string) {
+		seedUsageEvent(t, db, node, orgID, customer, meterID, at, value, status)
+	}
+	insert(customerID, day.Add(10*time.Minute), 4, usagedomain.UsageStatusEnriched)
+	insert(customerID, day.Add(40*time.Minute), 2, usagedomain.UsageStatusRated)
+	insert(customerID, day.Add(2*time.Hour+5*time.Minute), 7, usagedomain.UsageStatusAccepted)
+	insert(customerID, day.Add(2*time.Hour+6*time.Minute), 100, usagedomain.UsageStatusInvalid)
+	insert(node.Generate(), day.Add(15*time.Minute), 50, usagedomain.UsageStatusEnriched)
+
+	t.Run("aggregates hourly buckets with the meter aggregation", func(t *testing.T) {
+		resp, err := service.GetUsage(ctx, usagedomain.GetUsageRequest{
+			CustomerID:  customerID.String(),
+			MeterCode:   "seats",
+			From:        day,
+			To:          day.Add(3 * time.Hour),
+			Granularity: usagedomain.GranularityHour,
+		})
+		if err != nil {
+			t.Fatalf("get usage: %v", err)
+		}
+		if resp.Aggregation != meterdomain.AggregationLast || resp.HasMore {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+		if len(resp.Points) != 3 {
+			t.Fatalf("expected 3 points, got %d", len(resp.Points))
+		}
+		first := resp.Points[0]
+		if first.Sum != 6 || first.Max != 4 || first.Count != 2 || first.Value != 2 {
+			t.Fatalf("unexpected first bucket: %+v", first)
+		}
+		if !first.BucketStart.Equal(day) || !first.BucketEnd.Equal(day.Add(time.Hour)) {
+			t.Fatalf("unexpected first bucket bounds: %+v", first)
+		}
+		if empty := resp.Points[1]; empty.Count != 0 || empty.Value != 0 {
+			t.Fatalf("expected empty second bucket, got %+v", empty)
+		}
+		if last := resp.Points[2]; last.Count != 1 || last.Value != 7 {
+			t.Fatalf("expected invalid events to be excluded, got %+v", last)
+		}
+	})
+
+	t.Run("pages over buckets", func(t *testing.T) {
+		req := usagedomain.GetUsageRequest{
+			CustomerID:  customerID.String(),
+			MeterCode:   "seats",
+			From:        day,
+			To:          day.Add(3 * time.Hour),
+			Granularity: usagedomain.GranularityHour,
+			PageSize:    2,
+		}
+		page, err := service.GetUsage(ctx, req)
+		if err != nil {
+			t.Fatalf("get first page: %v", err)
+		}
+		if !page.HasMore || page.NextPageToken == "" || len(page.Points) != 2 {
+			t.Fatalf("unexpected first page: %+v", page)
+		}
+
+		req.PageToken = page.NextPageToken
+		page, err = service.GetUsage(ctx, req)
+		if err != nil {
+			t.Fatalf("get second page: %v", err)
+		}
+		if page.HasMore || len(page.Points) != 1 || page.Points[0].Value != 7 {
+			t.Fatalf("unexpected second page: %+v", page)
+		}
+	})
+
+	t.Run("aggregates daily buckets", func(t *testing.T) {
+		resp, err := service.GetUsage(ctx, usagedomain.GetUsageRequest{
+			CustomerID: customerID.String(),
+			MeterCode:  "seats",
+			From:       day.Add(30 * time.Minute),
+			To:         day.AddDate(0, 0, 1),
+		})
+		if err != nil {
+			t.Fatalf("get usage: %v", err)
+		}
+		if resp.Granularity != usagedomain.GranularityDay || len(resp.Points) != 1 {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+		if point := resp.Points[0]; point.Count != 2 || point.Sum != 9 || !point.BucketStart.Equal(day) {
+			t.Fatalf("expected usage before from to be excluded, got %+v", point)
+		}
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		valid := usagedomain.GetUsageRequest{
+			CustomerID: customerID.String(),
+			MeterCode:  "seats",
+			From:       day,
+			To:         day.Add(time.Hour),
+		}
+		cases := map[string]struct {
+			mutate func(*usagedomain.GetUsageRequest)
+			want   error
+		}{
+			"granularity": {func(r *usagedomain.GetUsageRequest) { r.Granularity = "minute" }, usagedomain.ErrInvalidGranularity},
+			"period":      {func(r *usagedomain.GetUsageRequest) { r.To = r.From }, usagedomain.ErrInvalidPeriod},
+			"page token":  {func(r *usagedomain.GetUsageRequest) { r.PageToken = "not-a-token" }, usagedomain.ErrInvalidPageToken},
+			"customer":    {func(r *usagedomain.GetUsageRequest) { r.CustomerID = node.Generate().String() }, usagedomain.ErrInvalidCustomer},
+		}
+		for name, tc := range cases {
+			req := valid
+			tc.mutate(&req)
+			if _, err := service.GetUsage(ctx, req); !errors.Is(err, tc.want) {
+				t.Fatalf("%s: expected %v, got %v", name, tc.want, err)
+			}
+		}
+		if _, err := service.GetUsage(context.Background(), valid); !errors.Is(err, usagedomain.ErrInvalidOrganization) {
+			t.Fatalf("expected invalid organization, got %v", err)
+		}
+	})
+}
+
+func seedUsageEvent(t *testing.T, db *gorm.DB, node *snowflake.Node, orgID, customerID, meterID snowflake.ID, recordedAt time.Time, value float64, status string) {
+	t.Helper()
+	now := time.Now().UTC()
+	if err := db.Create(&usagedomain.UsageEvent{
+		ID:             node.Generate(),
+		OrgID:          orgID,
+		CustomerID:     customerID,
+		SubscriptionID: node.Generate(),
+		MeterID:        meterID,
+		MeterCode:      "seats",
+		Value:          value,
+		RecordedAt:     recordedAt,
+		Status:         status,
+		IdempotencyKey: node.Generate().String(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}).Error; err != nil {
+		t.Fatalf("seed usage event: %v", err)
+	}
+}
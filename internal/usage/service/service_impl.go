@@ -84,18 +84,62 @@ func (s *Service) Ingest(
 		return nil, usagedomain.ErrInvalidOrganization
 	}
 
-	customerID, err := s.parseID(req.CustomerID, usagedomain.ErrInvalidCustomer)
+	record, existing, err := s.prepareUsageEvent(ctx, orgID, req)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		s.emitLiveUsageEvent(existing, liveevents.StatusDeduplicated, liveevents.SourceAPI)
+		return existing, nil
+	}
+	idempotencyKey := record.IdempotencyKey
+
+	inserted, err := s.insertUsageEvent(ctx, s.db, record, idempotencyKey)
 	if err != nil {
 		return nil, err
 	}
 
+	// 🔁 Idempotency hit → fetch existing
+	if !inserted && idempotencyKey != "" {
+		existing, err := s.findUsageEventByIdempotencyKey(
+			ctx,
+			orgID,
+			idempotencyKey,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			s.emitLiveUsageEvent(existing, liveevents.StatusDeduplicated, liveevents.SourceAPI)
+			return existing, nil
+		}
+	}
+
+	s.recordUsageAccepted(ctx, record)
+
+	return record, nil
+}
+
+// prepareUsageEvent validates req and resolves its subscription, meter and
+// entitlement into a usage event ready to insert. When the idempotency key
+// was already accepted it returns the stored event instead.
+func (s *Service) prepareUsageEvent(
+	ctx context.Context,
+	orgID snowflake.ID,
+	req usagedomain.CreateIngestRequest,
+) (*usagedomain.UsageEvent, *usagedomain.UsageEvent, error) {
+	customerID, err := s.parseID(req.CustomerID, usagedomain.ErrInvalidCustomer)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	meterCode := strings.TrimSpace(req.MeterCode)
 	if meterCode == "" {
-		return nil, usagedomain.ErrInvalidMeterCode
+		return nil, nil, usagedomain.ErrInvalidMeterCode
 	}
 
 	if err := validateUsageEvent(req); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	idempotencyKey := normalizeIdempotencyKey(req.IdempotencyKey)
@@ -105,28 +149,27 @@ func (s *Service) Ingest(
 	// This prevents "permission drift" on retries (e.g. sub cancelled after Event 1 but before Retry 1).
 	existing, err := s.findUsageEventByIdempotencyKey(ctx, orgID, idempotencyKey)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if existing != nil {
-		s.emitLiveUsageEvent(existing, liveevents.StatusDeduplicated, liveevents.SourceAPI)
-		return existing, nil
+		return nil, existing, nil
 	}
 
 	// ... continue to resolving ...
 	sub, err := s.resolveActiveSubscription(ctx, orgID, req.CustomerID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if sub.ID == 0 {
-		return nil, usagedomain.ErrInvalidSubscription
+		return nil, nil, usagedomain.ErrInvalidSubscription
 	}
 
 	meter, err := s.resolveMeter(ctx, orgID, meterCode)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if meter == nil {
-		return nil, usagedomain.ErrInvalidMeter
+		return nil, nil, usagedomain.ErrInvalidMeter
 	}
 
 	now := time.Now().UTC()
@@ -142,27 +185,24 @@ func (s *Service) Ingest(
 	// Entitlement Check: Validate that this usage is allowed.
 	meterID, err := snowflake.ParseString(meter.ID)
 	if err != nil {
-		return nil, usagedomain.ErrInvalidMeter
+		return nil, nil, usagedomain.ErrInvalidMeter
 	}
 
 	if s.subSvc != nil {
 		if err := s.subSvc.ValidateUsageEntitlement(ctx, sub.ID, meterID, recordedAt); err != nil {
 			// If feature not entitled, we must reject.
 			if errors.Is(err, subscriptiondomain.ErrFeatureNotEntitled) {
-				// Map to a new usage domain error or return explicitly
-				return nil, errors.New("usage_rejected_feature_not_entitled")
+				return nil, nil, usagedomain.ErrFeatureNotEntitled
 			}
 			// For other errors (db issues), return them?
 			// Strict gating -> if we can't validate, we shouldn't accept.
-			return nil, err
+			return nil, nil, err
 		}
 	} else {
 		// Critical: If subSvc is missing, we cannot enforce gating.
-		return nil, errors.New("usage_ingestion_gating_unavailable")
+		return nil, nil, usagedomain.ErrGatingUnavailable
 	}
 
-	// idempotencyKey already normalized above
-
 	record := &usagedomain.UsageEvent{
 		ID:             s.genID.Generate(),
 		OrgID:          orgID,
@@ -179,41 +219,22 @@ func (s *Service) Ingest(
 	if req.Metadata != nil {
 		record.Metadata = datatypes.JSONMap(req.Metadata)
 	}
+	return record, nil, nil
+}
 
-	inserted, err := s.insertUsageEvent(ctx, record, idempotencyKey)
-	if err != nil {
-		return nil, err
-	}
-
-	// 🔁 Idempotency hit → fetch existing
-	if !inserted && idempotencyKey != "" {
-		existing, err := s.findUsageEventByIdempotencyKey(
-			ctx,
-			orgID,
-			idempotencyKey,
-		)
-		if err != nil {
-			return nil, err
-		}
-		if existing != nil {
-			s.emitLiveUsageEvent(existing, liveevents.StatusDeduplicated, liveevents.SourceAPI)
-			return existing, nil
-		}
-	}
-
+// recordUsageAccepted counts a newly inserted event and publishes it.
+func (s *Service) recordUsageAccepted(ctx context.Context, record *usagedomain.UsageEvent) {
 	// async metrics (best effort)
 	if s.metrics != nil {
-		go s.metrics.IncUsageEvent(orgID.String(), meterCode)
+		go s.metrics.IncUsageEvent(record.OrgID.String(), record.MeterCode)
 	}
 
 	if s.obsMetrics != nil {
-		s.obsMetrics.RecordUsageIngest(ctx, meterCode)
+		s.obsMetrics.RecordUsageIngest(ctx, record.MeterCode)
 	}
 
 	s.emitUsageIngested(record)
 	s.emitLiveUsageEvent(record, liveevents.StatusAccepted, liveevents.SourceAPI)
-
-	return record, nil
 }
 
 func (s *Service) List(ctx context.Context, req usagedomain.ListUsageRequest) (usagedomain.ListUsageResponse, error) {
@@ -348,19 +369,22 @@ func (s *Service) ensureCustomerExists(ctx context.Context, orgID, customerID sn
 	return nil
 }
 
-func (s *Service) insertUsageEvent(ctx context.Context, record *usagedomain.UsageEvent, idempotencyKey string) (bool, error) {
+// insertUsageEvent inserts record through db, which may be a transaction. It
+// reports false when the idempotency key already exists.
+func (s *Service) insertUsageEvent(ctx context.Context, db *gorm.DB, record *usagedomain.UsageEvent, idempotencyKey string) (bool, error) {
 	if record == nil {
 		return false, errors.New("missing_usage_event")
 	}
-	if s.db == nil {
+	if db == nil {
 		return false, errors.New("missing_db")
 	}
-	if strings.EqualFold(s.db.Dialector.Name(), "sqlite") {
-		return s.insertUsageEventSQLite(ctx, record, idempotencyKey)
+	if strings.EqualFold(db.Dialector.Name(), "sqlite") {
+		return s.insertUsageEventSQLite(ctx, db, record, idempotencyKey)
 	}
-	db := s.db.WithContext(ctx)
+	conflict := buildIdempotencyConflictClause(db)
+	db = db.WithContext(ctx)
 	if idempotencyKey != "" {
-		db = db.Clauses(buildIdempotencyConflictClause(s.db))
+		db = db.Clauses(conflict)
 	}
 	result := db.Create(record)
 	if result.Error != nil {
@@ -369,7 +393,7 @@ func (s *Service) insertUsageEvent(ctx context.Context, record *usagedomain.Usag
 	return result.RowsAffected > 0, nil
 }
 
-func (s *Service) insertUsageEventSQLite(ctx context.Context, record *usagedomain.UsageEvent, idempotencyKey string) (bool, error) {
+func (s *Service) insertUsageEventSQLite(ctx context.Context, db *gorm.DB, record *usagedomain.UsageEvent, idempotencyKey string) (bool, error) {
 	var subscriptionItemValue any
 	if record.SubscriptionItemID != 0 {
 		subscriptionItemValue = record.SubscriptionItemID
@@ -382,7 +406,7 @@ func (s *Service) insertUsageEventSQLite(ctx context.Context, record *usagedomai
 	if idempotencyKey != "" {
 		query += " ON CONFLICT (org_id, idempotency_key) DO NOTHING"
 	}
-	result := s.db.WithContext(ctx).Exec(
+	result := db.WithContext(ctx).Exec(
 		query,
 		record.ID,
 		record.OrgID,
@@ -266,6 +266,12 @@ func (s *subscriptionStub) TransitionSubscription(ctx context.Context, id string
 func (s *subscriptionStub) ChangePlan(ctx context.Context, req subscriptiondomain.ChangePlanRequest) error {
 	return nil
 }
+func (s *subscriptionStub) PauseSubscription(ctx context.Context, id string, resumeAt *time.Time) error {
+	return nil
+}
+func (s *subscriptionStub) ResumeSubscription(ctx context.Context, id string) error {
+	return nil
+}
 
 func prepareUsageSchema(t *testing.T, db *gorm.DB) {
 	t.Helper()
@@ -24,7 +24,7 @@ var Module = fx.Module("database",
 		Dialect,
 		New,
 	),
-	fx.Invoke(RegisterConnectionPool),
+	fx.Invoke(RegisterConnectionPool, RegisterTenantScope),
 )
 
 func New(cfg config.Config, dialector gorm.Dialector, opts ...gorm.Option) (*gorm.DB, error) {
@@ -0,0 +1,142 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPageToken is returned for a page token that does not belong to
+// the keyset of the query it is applied to.
+var ErrInvalidPageToken = errors.New("invalid_page_token")
+
+// KeysetColumn is one column a raw query is ordered and paged by. Column
+// names an output column of the query and must never be NULL.
+type KeysetColumn struct {
+	Column string
+	Desc   bool
+}
+
+// Keyset is the order of a raw query. Its columns together must identify a
+// row, so the last column is usually the row's id.
+type Keyset []KeysetColumn
+
+// Paginate wraps query, a raw SELECT without ORDER BY or LIMIT, so it returns
+// the rows after page.PageToken in keyset order. One row past page.PageSize
+// is fetched so NextPage can tell whether another page follows; a page size
+// of zero or less fetches every row. Cursor values are bound as text and left
+// to the database to convert to the column's type.
+func Paginate(query string, args []any, keyset Keyset, page Pagination) (string, []any, error) {
+	after, err := decodeKeyset(page.PageToken, len(keyset))
+	if err != nil {
+		return "", nil, err
+	}
+
+	out := append([]any{}, args...)
+	var sb strings.Builder
+	sb.WriteString("SELECT * FROM (")
+	sb.WriteString(query)
+	sb.WriteString("\n\t\t) AS page")
+
+	if after != nil {
+		// (a, b) after (x, y) is a > x OR (a = x AND b > y), written out so
+		// every column can have its own direction.
+		terms := make([]string, 0, len(keyset))
+		for i, col := range keyset {
+			parts := make([]string, 0, i+1)
+			for _, prev := range keyset[:i] {
+				parts = append(parts, fmt.Sprintf("page.%s = ?", prev.Column))
+			}
+			parts = append(parts, fmt.Sprintf("page.%s %s ?", col.Column, col.after()))
+			terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+			for _, value := range after[:i+1] {
+				out = append(out, value)
+			}
+		}
+		sb.WriteString("\n\t\tWHERE ")
+		sb.WriteString(strings.Join(terms, " OR "))
+	}
+
+	order := make([]string, 0, len(keyset))
+	for _, col := range keyset {
+		order = append(order, fmt.Sprintf("page.%s %s", col.Column, col.direction()))
+	}
+	sb.WriteString("\n\t\tORDER BY ")
+	sb.WriteString(strings.Join(order, ", "))
+
+	if page.PageSize > 0 {
+		sb.WriteString("\n\t\tLIMIT ?")
+		out = append(out, page.PageSize+1)
+	}
+	return sb.String(), out, nil
+}
+
+// NextPage trims rows fetched by Paginate to page.PageSize and returns them
+// with the page info. key returns the values of a row's keyset columns, in
+// keyset order; the next page token resumes after the last row returned.
+func NextPage[T any](rows []T, page Pagination, key func(T) []any) ([]T, PageInfo) {
+	if page.PageSize <= 0 || len(rows) <= page.PageSize {
+		return rows, PageInfo{}
+	}
+	rows = rows[:page.PageSize]
+	return rows, PageInfo{
+		HasMore:       true,
+		NextPageToken: encodeKeyset(key(rows[len(rows)-1])),
+	}
+}
+
+func (c KeysetColumn) direction() string {
+	if c.Desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+func (c KeysetColumn) after() string {
+	if c.Desc {
+		return "<"
+	}
+	return ">"
+}
+
+func encodeKeyset(values []any) string {
+	text := make([]string, 0, len(values))
+	for _, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			text = append(text, v.UTC().Format(time.RFC3339Nano))
+		case fmt.Stringer:
+			text = append(text, v.String())
+		default:
+			text = append(text, fmt.Sprint(v))
+		}
+	}
+	b, err := json.Marshal(text)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeKeyset(token string, columns int) ([]any, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var text []string
+	if err := json.Unmarshal(b, &text); err != nil || len(text) != columns {
+		return nil, ErrInvalidPageToken
+	}
+	values := make([]any, 0, len(text))
+	for _, value := range text {
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,108 @@
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+type keysetRow struct {
+	ID     int64
+	Amount int64
+	Name   string
+}
+
+func TestPaginate_WalksEveryPage(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, stmt := range []string{
+		`CREATE TABLE rows (id INTEGER PRIMARY KEY, org_id INTEGER NOT NULL, amount INTEGER NOT NULL, name TEXT NOT NULL)`,
+		`INSERT INTO rows (id, org_id, amount, name) VALUES
+			(1, 1, 500, 'a'), (2, 1, 900, 'b'), (3, 1, 500, 'c'), (4, 1, 100, 'd'),
+			(5, 1, 900, 'e'), (6, 2, 700, 'f'), (7, 1, 500, 'g')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	keyset := Keyset{{Column: "amount", Desc: true}, {Column: "id"}}
+	key := func(row keysetRow) []any { return []any{row.Amount, row.ID} }
+
+	var (
+		got   []int64
+		pages int
+		token string
+	)
+	for {
+		page := Pagination{PageToken: token, PageSize: 2}
+		query, args, err := Paginate(`SELECT id, amount, name FROM rows WHERE org_id = ?`, []any{1}, keyset, page)
+		if err != nil {
+			t.Fatalf("paginate: %v", err)
+		}
+		var rows []keysetRow
+		if err := db.Raw(query, args...).Scan(&rows).Error; err != nil {
+			t.Fatalf("query page %d: %v", pages, err)
+		}
+		rows, info := NextPage(rows, page, key)
+		pages++
+		for _, row := range rows {
+			got = append(got, row.ID)
+		}
+		if !info.HasMore {
+			break
+		}
+		token = info.NextPageToken
+	}
+
+	if want := []int64{2, 5, 1, 3, 7, 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected rows %v, got %v", want, got)
+	}
+	if pages != 3 {
+		t.Fatalf("expected 3 pages, got %d", pages)
+	}
+}
+
+func TestPaginate_UnlimitedFirstPage(t *testing.T) {
+	query, args, err := Paginate(`SELECT id FROM rows WHERE org_id = ?`, []any{1}, Keyset{{Column: "id"}}, Pagination{})
+	if err != nil {
+		t.Fatalf("paginate: %v", err)
+	}
+	want := "SELECT * FROM (SELECT id FROM rows WHERE org_id = ?\n\t\t) AS page\n\t\tORDER BY page.id ASC"
+	if query != want {
+		t.Fatalf("unexpected query %q", query)
+	}
+	if !reflect.DeepEqual(args, []any{1}) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestPaginate_InvalidToken(t *testing.T) {
+	keyset := Keyset{{Column: "amount", Desc: true}, {Column: "id"}}
+	otherKeyset := encodeKeyset([]any{int64(1)})
+
+	for name, token := range map[string]string{
+		"not base64":     "%%%",
+		"not a keyset":   "bm90IGpzb24",
+		"other keyset":   otherKeyset,
+		"legacy cursors": "eyJpZCI6IjEifQ",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := Paginate(`SELECT id, amount FROM rows`, nil, keyset, Pagination{PageToken: token, PageSize: 10})
+			if !errors.Is(err, ErrInvalidPageToken) {
+				t.Fatalf("expected ErrInvalidPageToken, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/smallbiznis/railzway/internal/config"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+)
+
+// Tenant scope check modes, selected by DB_TENANT_SCOPE_CHECK.
+const (
+	TenantScopeCheckOff    = "off"
+	TenantScopeCheckWarn   = "warn"
+	TenantScopeCheckStrict = "strict"
+)
+
+// ErrMissingOrgScope is returned in strict mode for a query on a tenant table
+// that has no org_id predicate.
+var ErrMissingOrgScope = errors.New("query on tenant table is missing an org_id predicate")
+
+// DefaultTenantTables are the org-owned tables the tenant scope check guards.
+// Each has an org_id column. Child tables without one, such as
+// ledger_entry_lines, are reached through a parent that is checked.
+var DefaultTenantTables = []string{
+	"audit_logs",
+	"billing_operation_actions",
+	"billing_operation_assignments",
+	"billing_operation_snoozes",
+	"credit_notes",
+	"customers",
+	"finops_performance_snapshots",
+	"invoice_line_disputes",
+	"invoice_public_tokens",
+	"invoices",
+	"ledger_accounts",
+	"ledger_entries",
+	"organization_billing_preferences",
+	"payment_events",
+}
+
+// tenantScopeSetting marks a handle whose queries are checked. The check is
+// opt-in because schedulers and rollups legitimately read across orgs.
+const tenantScopeSetting = "railzway:tenant_scope"
+
+var (
+	// stringLiteralRe matches single-quoted SQL literals, which are blanked
+	// before the statement is inspected.
+	stringLiteralRe = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// tableRefRe matches a table named after FROM, JOIN or UPDATE and the
+	// alias that may follow it.
+	tableRefRe = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|UPDATE)\s+([a-z_][a-z0-9_]*)(?:\s+(?:AS\s+)?([a-z_][a-z0-9_]*))?`)
+	// derivedAliasRe matches the alias after a closing parenthesis, which
+	// names a subquery when that parenthesis closes one.
+	derivedAliasRe = regexp.MustCompile(`(?i)^\)\s*(?:AS\s+)?([a-z_][a-z0-9_]*)`)
+	// cteNameRe matches a CTE name ahead of its opening parenthesis.
+	cteNameRe = regexp.MustCompile(`(?i)\b([a-z_][a-z0-9_]*)\s+AS\s+(?:NOT\s+)?(?:MATERIALIZED\s+)?$`)
+	// orgPredicateRe only accepts org_id compared with a bound parameter, so
+	// a join predicate such as c.org_id = i.org_id is not taken for a scope.
+	orgPredicateRe = regexp.MustCompile(`(?i)(?:\b([a-z_][a-z0-9_]*)\.)?\borg_id\s*(?:=|IN)\s*\(?\s*(?:\?|\$\d+|@\w+)`)
+	// orgJoinRe matches org_id compared between two references, which
+	// carries the scope of one over to the other.
+	orgJoinRe = regexp.MustCompile(`(?i)\b([a-z_][a-z0-9_]*)\.org_id\s*=\s*([a-z_][a-z0-9_]*)\.org_id\b`)
+	// subqueryStartRe matches what follows an opening parenthesis that
+	// starts a subquery.
+	subqueryStartRe = regexp.MustCompile(`(?i)^\(\s*(?:SELECT|WITH)\b`)
+)
+
+// aliasStopWords are keywords that can follow a table name in place of an
+// alias.
+var aliasStopWords = map[string]struct{}{
+	"and": {}, "as": {}, "asc": {}, "cross": {}, "delete": {}, "desc": {},
+	"else": {}, "end": {}, "except": {}, "fetch": {}, "filter": {}, "for": {},
+	"full": {}, "group": {}, "having": {}, "in": {}, "inner": {}, "insert": {},
+	"intersect": {}, "is": {}, "join": {}, "lateral": {}, "left": {},
+	"limit": {}, "natural": {}, "not": {}, "offset": {}, "on": {}, "or": {},
+	"order": {}, "outer": {}, "over": {}, "returning": {}, "right": {},
+	"select": {}, "set": {}, "then": {}, "union": {}, "update": {}, "using": {},
+	"when": {}, "where": {}, "window": {},
+}
+
+// EnforceTenantScope returns a handle whose queries must filter tenant tables
+// by org_id. Sessions and transactions opened from it inherit the check.
+func EnforceTenantScope(db *gorm.DB) *gorm.DB {
+	return db.Set(tenantScopeSetting, true).Session(&gorm.Session{})
+}
+
+// SkipTenantScope exempts the query built on tx from the tenant scope check.
+// Use it only for deliberate cross-org reads such as scheduled sweeps.
+func SkipTenantScope(tx *gorm.DB) *gorm.DB {
+	return tx.Set(tenantScopeSetting, false)
+}
+
+// RegisterTenantScope installs the tenant scope check configured by
+// cfg.DBTenantScopeCheck.
+func RegisterTenantScope(cfg config.Config, db *gorm.DB, log *zap.Logger) error {
+	return RegisterTenantScopeCheck(db, cfg.DBTenantScopeCheck, log, DefaultTenantTables...)
+}
+
+// RegisterTenantScopeCheck registers callbacks that inspect every query run
+// through a handle from EnforceTenantScope. Every reference a statement makes
+// to one of tables, including those in subqueries and CTEs, needs an org_id
+// predicate of its own; a scoped CTE does not cover the tables it is joined
+// to. A statement that reads, updates or deletes an unscoped reference is
+// logged in warn mode and rejected with ErrMissingOrgScope in strict mode.
+// Inserts are not checked.
+func RegisterTenantScopeCheck(db *gorm.DB, mode string, log *zap.Logger, tables ...string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case TenantScopeCheckOff:
+		return nil
+	case TenantScopeCheckWarn, TenantScopeCheckStrict:
+	default:
+		return fmt.Errorf("invalid tenant scope check mode %q", mode)
+	}
+	if log == nil {
+		log = zap.NewNop()
+	}
+
+	check := &tenantScopeCheck{
+		strict: mode == TenantScopeCheckStrict,
+		tables: make(map[string]struct{}, len(tables)),
+		log:    log.Named("tenant_scope"),
+	}
+	for _, table := range tables {
+		check.tables[strings.ToLower(table)] = struct{}{}
+	}
+
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("tenant_scope:query", check.query); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant_scope:row", check.query); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("tenant_scope:raw", check.raw); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant_scope:update", check.builder); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register("tenant_scope:delete", check.builder)
+}
+
+type tenantScopeCheck struct {
+	strict bool
+	tables map[string]struct{}
+	log    *zap.Logger
+}
+
+func (c *tenantScopeCheck) query(tx *gorm.DB) {
+	if !c.enabled(tx) {
+		return
+	}
+	// Build the SQL now; gorm:query and gorm:row reuse it as is.
+	callbacks.BuildQuerySQL(tx)
+	if tx.Error != nil {
+		return
+	}
+	c.verify(tx, tx.Statement.SQL.String())
+}
+
+func (c *tenantScopeCheck) raw(tx *gorm.DB) {
+	if !c.enabled(tx) {
+		return
+	}
+	c.verify(tx, tx.Statement.SQL.String())
+}
+
+func (c *tenantScopeCheck) builder(tx *gorm.DB) {
+	if !c.enabled(tx) {
+		return
+	}
+	if tx.Statement.SQL.Len() > 0 {
+		c.verify(tx, tx.Statement.SQL.String())
+		return
+	}
+	c.verify(tx, "FROM "+tx.Statement.Table+" "+whereSQL(tx.Statement))
+}
+
+func (c *tenantScopeCheck) enabled(tx *gorm.DB) bool {
+	if tx.Error != nil {
+		return false
+	}
+	v, ok := tx.Get(tenantScopeSetting)
+	return ok && v == true
+}
+
+func (c *tenantScopeCheck) verify(tx *gorm.DB, sql string) {
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sql)), "INSERT") {
+		return
+	}
+	table := c.unscopedTable(sql)
+	if table == "" {
+		return
+	}
+	if c.strict {
+		c.log.Error("query on tenant table without org scope", zap.String("table", table), zap.String("sql", sql))
+		_ = tx.AddError(fmt.Errorf("%w: %s", ErrMissingOrgScope, table))
+		return
+	}
+	c.log.Warn("query on tenant table without org scope", zap.String("table", table), zap.String("sql", sql))
+}
+
+// scopeRef is a table, subquery or CTE a statement reads from, keyed by the
+// name its columns are qualified with.
+type scopeRef struct {
+	table   string
+	name    string
+	block   int
+	tenant  bool
+	derived bool
+	scoped  bool
+}
+
+// unscopedTable returns the first tenant table in sql that no bound org_id
+// predicate reaches, or "" when every one is scoped. Each table is matched
+// to the predicates of its own query block: a predicate qualified with its
+// alias, an unqualified one in the same block, or org_id equality with a
+// reference that is scoped itself. Subqueries and CTEs are checked on their
+// own and may pass their org_id on to the tables joined to them.
+func (c *tenantScopeCheck) unscopedTable(sql string) string {
+	sql = stringLiteralRe.ReplaceAllString(sql, "''")
+	sql = strings.NewReplacer("\"", "", "`", "").Replace(sql)
+
+	// blocks[i] is the query block of sql[i]; parents links a subquery
+	// block to the one it is nested in.
+	blocks := make([]int, len(sql))
+	parents := []int{-1}
+	var refs []*scopeRef
+	stack := []int{0}
+	opened := []bool{}
+	for i := 0; i < len(sql); i++ {
+		switch sql[i] {
+		case '(':
+			subquery := subqueryStartRe.MatchString(sql[i:])
+			if subquery {
+				block := len(parents)
+				parents = append(parents, stack[len(stack)-1])
+				if m := cteNameRe.FindStringSubmatch(sql[:i]); m != nil {
+					refs = append(refs, &scopeRef{name: strings.ToLower(m[1]), block: stack[len(stack)-1], derived: true, scoped: true})
+				}
+				stack = append(stack, block)
+			}
+			opened = append(opened, subquery)
+		case ')':
+			if len(opened) > 0 {
+				subquery := opened[len(opened)-1]
+				opened = opened[:len(opened)-1]
+				if subquery && len(stack) > 1 {
+					stack = stack[:len(stack)-1]
+					if m := derivedAliasRe.FindStringSubmatch(sql[i:]); m != nil && !isAliasStopWord(m[1]) {
+						refs = append(refs, &scopeRef{name: strings.ToLower(m[1]), block: stack[len(stack)-1], derived: true, scoped: true})
+					}
+				}
+			}
+		}
+		blocks[i] = stack[len(stack)-1]
+	}
+
+	for _, m := range tableRefRe.FindAllStringSubmatchIndex(sql, -1) {
+		if m[3] < len(sql) && sql[m[3]] == '.' {
+			// A qualified column, as in EXTRACT(EPOCH FROM i.due_at).
+			continue
+		}
+		table := strings.ToLower(sql[m[2]:m[3]])
+		name := table
+		if m[4] >= 0 && !isAliasStopWord(sql[m[4]:m[5]]) {
+			name = strings.ToLower(sql[m[4]:m[5]])
+		}
+		_, tenant := c.tables[table]
+		refs = append(refs, &scopeRef{table: table, name: name, block: blocks[m[0]], tenant: tenant})
+	}
+
+	// resolve finds the reference a qualifier names, looking in the block the
+	// predicate sits in first and then in the blocks enclosing it. Tenant
+	// tables, subqueries and CTEs win over other tables of the same name.
+	resolve := func(name string, block int) *scopeRef {
+		name = strings.ToLower(name)
+		for ; block >= 0; block = parents[block] {
+			var match *scopeRef
+			for _, ref := range refs {
+				if ref.block != block || ref.name != name {
+					continue
+				}
+				if ref.tenant || ref.derived {
+					return ref
+				}
+				if match == nil {
+					match = ref
+				}
+			}
+			if match != nil {
+				return match
+			}
+		}
+		return nil
+	}
+
+	for _, m := range orgPredicateRe.FindAllStringSubmatchIndex(sql, -1) {
+		block := blocks[m[0]]
+		if m[2] >= 0 {
+			if ref := resolve(sql[m[2]:m[3]], block); ref != nil {
+				ref.scoped = true
+			}
+			continue
+		}
+		for _, ref := range refs {
+			if ref.block == block {
+				ref.scoped = true
+			}
+		}
+	}
+
+	joins := orgJoinRe.FindAllStringSubmatchIndex(sql, -1)
+	for changed := true; changed; {
+		changed = false
+		for _, m := range joins {
+			block := blocks[m[0]]
+			left, right := resolve(sql[m[2]:m[3]], block), resolve(sql[m[4]:m[5]], block)
+			if left == nil || right == nil || left.scoped == right.scoped {
+				continue
+			}
+			left.scoped, right.scoped = true, true
+			changed = true
+		}
+	}
+
+	for _, ref := range refs {
+		if ref.tenant && !ref.scoped {
+			return ref.table
+		}
+	}
+	return ""
+}
+
+func isAliasStopWord(word string) bool {
+	_, ok := aliasStopWords[strings.ToLower(word)]
+	return ok
+}
+
+// whereSQL renders the WHERE clause of a builder statement that has not been
+// compiled to SQL yet.
+func whereSQL(stmt *gorm.Statement) string {
+	where, ok := stmt.Clauses["WHERE"]
+	if !ok {
+		return ""
+	}
+	probe := &gorm.Statement{DB: stmt.DB, Table: stmt.Table, Clauses: map[string]clause.Clause{}}
+	where.Build(probe)
+	return probe.SQL.String()
+}
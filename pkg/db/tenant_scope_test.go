@@ -0,0 +1,121 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+type tenantScopeInvoice struct {
+	ID     int64
+	OrgID  int64
+	Status string
+}
+
+func (tenantScopeInvoice) TableName() string { return "invoices" }
+
+func newTenantScopeDB(t *testing.T, mode string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sql db: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, stmt := range []string{
+		`CREATE TABLE invoices (id BIGINT PRIMARY KEY, org_id BIGINT NOT NULL, status TEXT NOT NULL)`,
+		`CREATE TABLE finops_scoring_checkpoints (period_type TEXT NOT NULL, last_org_id BIGINT)`,
+		`INSERT INTO invoices (id, org_id, status) VALUES (1, 10, 'OPEN'), (2, 20, 'OPEN')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	if err := RegisterTenantScopeCheck(db, mode, zaptest.NewLogger(t), DefaultTenantTables...); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return db
+}
+
+func TestTenantScopeCheck_Strict(t *testing.T) {
+	db := newTenantScopeDB(t, TenantScopeCheckStrict)
+	scoped := EnforceTenantScope(db)
+
+	var count int64
+	var invoices []tenantScopeInvoice
+	unscoped := map[string]error{
+		"raw select":      scoped.Raw(`SELECT COUNT(*) FROM invoices WHERE status = ?`, "OPEN").Scan(&count).Error,
+		"builder find":    scoped.Where("status = ?", "OPEN").Find(&invoices).Error,
+		"builder count":   scoped.Table("invoices").Where("status = ?", "OPEN").Count(&count).Error,
+		"join":            scoped.Raw(`SELECT COUNT(*) FROM finops_scoring_checkpoints c JOIN invoices i ON i.id = c.last_org_id`).Scan(&count).Error,
+		"join predicate":  scoped.Raw(`SELECT COUNT(*) FROM invoices i JOIN invoices o ON o.org_id = i.org_id WHERE i.status = ?`, "OPEN").Scan(&count).Error,
+		"inlined org":     scoped.Raw(`SELECT COUNT(*) FROM invoices WHERE org_id = 10`).Scan(&count).Error,
+		"scoped cte":      scoped.Raw(`WITH s AS (SELECT id FROM invoices WHERE org_id = ?) SELECT COUNT(*) FROM invoices i JOIN s ON s.id = i.id`, 10).Scan(&count).Error,
+		"scoped subquery": scoped.Raw(`SELECT COUNT(*) FROM invoices i WHERE i.id IN (SELECT id FROM invoices WHERE org_id = ?)`, 10).Scan(&count).Error,
+		"exec update":     scoped.Exec(`UPDATE invoices SET status = 'VOID' WHERE id = ?`, 1).Error,
+		"builder update":  scoped.Model(&tenantScopeInvoice{}).Where("id = ?", 1).Update("status", "VOID").Error,
+		"builder delete":  scoped.Where("id = ?", 1).Delete(&tenantScopeInvoice{}).Error,
+		"transaction": scoped.Transaction(func(tx *gorm.DB) error {
+			return tx.Raw(`SELECT COUNT(*) FROM invoices`).Scan(&count).Error
+		}),
+	}
+	for name, err := range unscoped {
+		if !errors.Is(err, ErrMissingOrgScope) {
+			t.Errorf("%s: expected ErrMissingOrgScope, got %v", name, err)
+		}
+	}
+
+	var status string
+	if err := db.Raw(`SELECT status FROM invoices WHERE id = 1`).Scan(&status).Error; err != nil || status != "OPEN" {
+		t.Fatalf("rejected statements must not run, got status %q err %v", status, err)
+	}
+
+	allowed := map[string]error{
+		"raw select":         scoped.Raw(`SELECT COUNT(*) FROM invoices i WHERE i.org_id = ? AND status = ?`, 10, "OPEN").Scan(&count).Error,
+		"builder find":       scoped.Where("org_id = ?", 10).Find(&invoices).Error,
+		"builder org in":     scoped.Where("org_id IN ?", []int64{10, 20}).Find(&invoices).Error,
+		"joined and scoped":  scoped.Raw(`SELECT COUNT(*) FROM invoices i JOIN invoices o ON o.org_id = i.org_id WHERE i.org_id = ?`, 10).Scan(&count).Error,
+		"cte and outer":      scoped.Raw(`WITH s AS (SELECT id FROM invoices WHERE org_id = ?) SELECT COUNT(*) FROM invoices i JOIN s ON s.id = i.id WHERE i.org_id = ?`, 10, 10).Scan(&count).Error,
+		"cte joined on org":  scoped.Raw(`WITH s AS (SELECT id, org_id FROM invoices WHERE org_id = ?) SELECT COUNT(*) FROM invoices i JOIN s ON s.id = i.id AND s.org_id = i.org_id`, 10).Scan(&count).Error,
+		"builder update":     scoped.Model(&tenantScopeInvoice{}).Where("org_id = ? AND id = ?", 10, 1).Update("status", "PAID").Error,
+		"insert":             scoped.Exec(`INSERT INTO invoices (id, org_id, status) VALUES (3, 10, 'DRAFT')`).Error,
+		"non-tenant table":   scoped.Exec(`DELETE FROM finops_scoring_checkpoints WHERE period_type = ?`, "daily").Error,
+		"skipped":            SkipTenantScope(scoped).Raw(`SELECT COUNT(*) FROM invoices`).Scan(&count).Error,
+		"not enforced":       db.Raw(`SELECT COUNT(*) FROM invoices`).Scan(&count).Error,
+		"scoped transaction": scoped.Transaction(func(tx *gorm.DB) error { return tx.Where("org_id = ?", 20).Find(&invoices).Error }),
+	}
+	for name, err := range allowed {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
+
+func TestTenantScopeCheck_WarnOnlyLogs(t *testing.T) {
+	db := newTenantScopeDB(t, TenantScopeCheckWarn)
+
+	var count int64
+	if err := EnforceTenantScope(db).Raw(`SELECT COUNT(*) FROM invoices`).Scan(&count).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 invoices, got %d", count)
+	}
+}
+
+func TestRegisterTenantScopeCheck_InvalidMode(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := RegisterTenantScopeCheck(db, "loud", nil); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,189 @@
+package money
+
+import (
+	"strconv"
+	"strings"
+)
+
+// defaultMinorUnits is used for currencies missing from minorUnits.
+const defaultMinorUnits = 2
+
+// minorUnits holds the number of decimal places for a currency's minor unit.
+// Values follow ISO 4217, except IDR which is billed in whole rupiah.
+var minorUnits = map[string]int{
+	// No minor unit.
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IDR": 0, "ISK": 0, "JPY": 0,
+	"KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0,
+	"VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+
+	// Thousandths.
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+
+	// Ten-thousandths.
+	"CLF": 4, "UYW": 4,
+
+	// Hundredths.
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2,
+	"AUD": 2, "AWG": 2, "AZN": 2, "BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2,
+	"BMD": 2, "BND": 2, "BOB": 2, "BOV": 2, "BRL": 2, "BSD": 2, "BTN": 2,
+	"BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2,
+	"CHW": 2, "CNY": 2, "COP": 2, "COU": 2, "CRC": 2, "CUC": 2, "CUP": 2,
+	"CVE": 2, "CZK": 2, "DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2,
+	"ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2, "GBP": 2, "GEL": 2, "GHS": 2,
+	"GIP": 2, "GMD": 2, "GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2, "HTG": 2,
+	"HUF": 2, "ILS": 2, "INR": 2, "IRR": 2, "JMD": 2, "KES": 2, "KGS": 2,
+	"KHR": 2, "KPW": 2, "KYD": 2, "KZT": 2, "LAK": 2, "LBP": 2, "LKR": 2,
+	"LRD": 2, "LSL": 2, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2, "MMK": 2,
+	"MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2,
+	"MXV": 2, "MYR": 2, "MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2,
+	"NPR": 2, "NZD": 2, "PAB": 2, "PEN": 2, "PGK": 2, "PHP": 2, "PKR": 2,
+	"PLN": 2, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "SAR": 2, "SBD": 2,
+	"SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2, "SLL": 2,
+	"SOS": 2, "SRD": 2, "SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2,
+	"THB": 2, "TJS": 2, "TMT": 2, "TOP": 2, "TRY": 2, "TTD": 2, "TWD": 2,
+	"TZS": 2, "UAH": 2, "USD": 2, "USN": 2, "UYU": 2, "UZS": 2, "VED": 2,
+	"VES": 2, "WST": 2, "XCD": 2, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWL": 2,
+}
+
+// MinorUnits returns the number of decimal places used by currency.
+func MinorUnits(currency string) int {
+	units, _ := LookupMinorUnits(currency)
+	return units
+}
+
+// LookupMinorUnits is MinorUnits that also reports whether currency is
+// known; unknown currencies get the default of 2.
+func LookupMinorUnits(currency string) (int, bool) {
+	if units, ok := minorUnits[normalizeCurrency(currency)]; ok {
+		return units, true
+	}
+	return defaultMinorUnits, false
+}
+
+// Locale controls separators used when rendering amounts.
+type Locale struct {
+	Tag              string
+	DecimalSeparator string
+	GroupSeparator   string
+}
+
+var (
+	LocaleEnUS = Locale{Tag: "en-US", DecimalSeparator: ".", GroupSeparator: ","}
+	LocaleDeDE = Locale{Tag: "de-DE", DecimalSeparator: ",", GroupSeparator: "."}
+	LocaleFrFR = Locale{Tag: "fr-FR", DecimalSeparator: ",", GroupSeparator: " "}
+	LocaleIDID = Locale{Tag: "id-ID", DecimalSeparator: ",", GroupSeparator: "."}
+)
+
+var locales = map[string]Locale{
+	"en":    LocaleEnUS,
+	"en-us": LocaleEnUS,
+	"de":    LocaleDeDE,
+	"de-de": LocaleDeDE,
+	"fr":    LocaleFrFR,
+	"fr-fr": LocaleFrFR,
+	"id":    LocaleIDID,
+	"id-id": LocaleIDID,
+}
+
+// LookupLocale resolves a BCP 47 style tag (e.g. "de-DE") to a Locale,
+// falling back to en-US for empty or unknown tags.
+func LookupLocale(tag string) Locale {
+	key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if locale, ok := locales[key]; ok {
+		return locale
+	}
+	return LocaleEnUS
+}
+
+// Formatter renders integer minor-unit amounts for humans and exports.
+type Formatter struct {
+	Locale Locale
+}
+
+// NewFormatter returns a Formatter for the given locale tag.
+func NewFormatter(tag string) Formatter {
+	return Formatter{Locale: LookupLocale(tag)}
+}
+
+// Format renders amount with the currency code and digit grouping,
+// e.g. "USD 1,234.56" or "JPY 1,235".
+func (f Formatter) Format(amount int64, currency string) string {
+	return normalizeCurrency(currency) + " " + f.format(amount, currency, true)
+}
+
+// FormatDecimal renders amount in major units without currency code or
+// grouping, e.g. "1234.56". It is intended for CSV columns.
+func (f Formatter) FormatDecimal(amount int64, currency string) string {
+	return f.format(amount, currency, false)
+}
+
+func (f Formatter) format(amount int64, currency string, group bool) string {
+	locale := f.Locale
+	if locale.DecimalSeparator == "" {
+		locale = LocaleEnUS
+	}
+
+	negative := amount < 0
+	digits := strconv.FormatInt(amount, 10)
+	if negative {
+		digits = digits[1:]
+	}
+
+	units := MinorUnits(currency)
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+	major := digits[:len(digits)-units]
+	minor := digits[len(digits)-units:]
+
+	if group {
+		major = groupDigits(major, locale.GroupSeparator)
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	b.WriteString(major)
+	if units > 0 {
+		b.WriteString(locale.DecimalSeparator)
+		b.WriteString(minor)
+	}
+	return b.String()
+}
+
+func groupDigits(digits string, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	lead := len(digits) % 3
+	if lead > 0 {
+		b.WriteString(digits[:lead])
+	}
+	for i := lead; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// Format renders amount using the en-US locale.
+func Format(amount int64, currency string) string {
+	return Formatter{Locale: LocaleEnUS}.Format(amount, currency)
+}
+
+// FormatDecimal renders amount in major units using the en-US locale.
+func FormatDecimal(amount int64, currency string) string {
+	return Formatter{Locale: LocaleEnUS}.FormatDecimal(amount, currency)
+}
+
+func normalizeCurrency(currency string) string {
+	c := strings.ToUpper(strings.TrimSpace(currency))
+	if c == "" {
+		return "USD"
+	}
+	return c
+}
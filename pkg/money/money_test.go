@@ -0,0 +1,52 @@
+package money
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		name     string
+		locale   string
+		amount   int64
+		currency string
+		want     string
+		decimal  string
+	}{
+		{name: "usd", amount: 123456, currency: "USD", want: "USD 1,234.56", decimal: "1234.56"},
+		{name: "usd cents only", amount: 5, currency: "usd", want: "USD 0.05", decimal: "0.05"},
+		{name: "usd negative", amount: -100050, currency: "USD", want: "USD -1,000.50", decimal: "-1000.50"},
+		{name: "jpy", amount: 1234567, currency: "JPY", want: "JPY 1,234,567", decimal: "1234567"},
+		{name: "kwd", amount: 1234567, currency: "KWD", want: "KWD 1,234.567", decimal: "1234.567"},
+		{name: "kwd fils only", amount: 7, currency: "KWD", want: "KWD 0.007", decimal: "0.007"},
+		{name: "unknown currency", amount: 100, currency: "XYZ", want: "XYZ 1.00", decimal: "1.00"},
+		{name: "empty currency", amount: 100, currency: "", want: "USD 1.00", decimal: "1.00"},
+		{name: "de locale", locale: "de-DE", amount: 123456789, currency: "EUR", want: "EUR 1.234.567,89", decimal: "1234567,89"},
+		{name: "id locale", locale: "id_ID", amount: 1500000, currency: "IDR", want: "IDR 1.500.000", decimal: "1500000"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFormatter(tc.locale)
+			if got := f.Format(tc.amount, tc.currency); got != tc.want {
+				t.Fatalf("Format: expected %q, got %q", tc.want, got)
+			}
+			if got := f.FormatDecimal(tc.amount, tc.currency); got != tc.decimal {
+				t.Fatalf("FormatDecimal: expected %q, got %q", tc.decimal, got)
+			}
+		})
+	}
+}
+
+func TestLookupLocaleFallsBackToEnUS(t *testing.T) {
+	if got := LookupLocale("xx-YY"); got != LocaleEnUS {
+		t.Fatalf("expected en-US fallback, got %+v", got)
+	}
+}
+
+func TestLookupMinorUnits(t *testing.T) {
+	if units, ok := LookupMinorUnits("idr"); !ok || units != 0 {
+		t.Fatalf("IDR: expected 0 known, got %d %v", units, ok)
+	}
+	if units, ok := LookupMinorUnits("XYZ"); ok || units != 2 {
+		t.Fatalf("XYZ: expected default 2 unknown, got %d %v", units, ok)
+	}
+}